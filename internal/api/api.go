@@ -0,0 +1,796 @@
+// Package api serves a running greenhouse over HTTP. Every endpoint speaks
+// JSON: responses use the types of this package and errors are returned as
+// an Error body with a status code matching the cause.
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/environment"
+	"greenhouse-simulator/internal/greenhouse"
+	"greenhouse-simulator/internal/models"
+	"greenhouse-simulator/internal/sensors"
+	"greenhouse-simulator/internal/service"
+	"greenhouse-simulator/internal/watering"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPageSize is the number of plants of a page of GET /plants when the
+// limit query parameter is left out.
+const DefaultPageSize = 100
+
+// MaxPageSize bounds the limit query parameter of GET /plants.
+const MaxPageSize = 1000
+
+// ShutdownTimeout bounds how long Serve waits for in-flight requests once it
+// is asked to stop.
+const ShutdownTimeout = 5 * time.Second
+
+// FlushTimeout bounds how long POST /exporters/flush waits for the exporters.
+const FlushTimeout = 5 * time.Second
+
+type server struct {
+	svc service.Service
+}
+
+// NewHandler returns the HTTP API of a greenhouse service:
+//
+//	GET    /plants                  list a page of plants, ordered by ID,
+//	                                filtered by the section, alive, type,
+//	                                tag, min_health, max_health, min_growth
+//	                                and max_growth query parameters and
+//	                                paged by limit and page_token, see
+//	                                PlantList
+//	GET    /plants/{id}             get a plant, with its journal when the
+//	                                greenhouse keeps plant journals
+//	GET    /plants/{id}/forecast    project a plant left unwatered, see
+//	                                PlantForecast
+//	GET    /plants/{id}/diagnosis   report what a plant lost health to on
+//	                                the last tick, see PlantDiagnosis
+//	POST   /plants                  add a plant from a config.PlantConfig body
+//	DELETE /plants/{id}             remove a plant
+//	POST   /plants/{id}/flags       exclude a plant from watering or
+//	                                quarantine it, see PlantFlagsRequest
+//	GET    /sections/{id}/readings  read the working sensors of a section
+//	GET    /sections/{id}/stats     report the greenhouse.SectionStats of a
+//	                                section
+//	GET    /sensors                 list sensors, ordered by ID
+//	POST   /sensors                 add a sensor from a config.SensorConfig body
+//	GET    /sensors/{id}/reading    read a sensor, or with the tick query
+//	                                parameter look up the sample it took on
+//	                                that tick, see
+//	                                sensors.SensorManager.GetReadingAt
+//	GET    /sensors/{id}/history    list the recent samples of a sensor,
+//	                                oldest first, the last ones only with
+//	                                the last query parameter
+//	POST   /sensors/{id}/battery    put a full battery in a wireless sensor
+//	GET    /sensors/diagnostics     report the read latencies and counters,
+//	                                see sensors.Diagnostics
+//	GET    /exporters/diagnostics   report the greenhouse.ExporterStats of
+//	                                every exporter
+//	POST   /exporters/flush         wait until every exporter has caught up
+//	                                and flushed, up to FlushTimeout, and
+//	                                report their stats
+//	GET    /sensors/advice          suggest where sensors are missing, see
+//	                                sensors.Advice
+//	POST   /sensors/{id}/anomalies  inject an anomaly into the readings of a
+//	                                sensor, see AnomalyRequest
+//	GET    /sensors/{id}/anomalies  debug the ground truth of the readings
+//	                                of a sensor, see sensors.AnomalyLabel
+//	POST   /sections/{id}/anomalies inject an anomaly into the readings of
+//	                                every sensor of a section
+//	POST   /watering                water a section manually, see
+//	                                WaterRequest, and respond with the
+//	                                queued Watering
+//	POST   /sections/{id}/lights    switch the grow lights of a section, see
+//	                                LightsRequest
+//	POST   /sections/{id}/climate   set the microclimate of a section, see
+//	                                ClimateRequest
+//	POST   /hvac/{actuator}         switch the heater or the vent, see
+//	                                ActuatorRequest
+//	GET    /water/temperature       report the greenhouse.WaterTemperature
+//	                                of the irrigation water
+//	PUT    /water/heater            hold the irrigation water at a
+//	                                temperature, see WaterHeaterRequest
+//	DELETE /water/heater            switch off the heater of the tank
+//	POST   /simulator/pause         pause the simulation
+//	POST   /simulator/resume        resume the simulation
+//	GET    /simulator/status        report the tick, pause state and stats
+//	GET    /costs                   report the greenhouse.CostLedger
+//	GET    /alerts                  list the active greenhouse.Alert values
+//	GET    /world                   describe the whole greenhouse for
+//	                                visualizers, see greenhouse.WorldState
+//	GET    /zones                   list zones, ordered by ID
+//	POST   /zones                   add a zone from a config.ZoneConfig body
+//	GET    /zones/{id}/stats        report the greenhouse.ZoneStats of a zone
+//	GET    /rotations               list the greenhouse.RotationStatus of the
+//	                                rotation plans, ordered by section
+//	PUT    /rotations/{section}     set the rotation plan of a section from a
+//	                                config.RotationConfig body
+//	DELETE /rotations/{section}     drop the rotation plan of a section
+//	POST   /rotations/{section}/pause
+//	                                pause the rotation plan of a section
+//	POST   /rotations/{section}/resume
+//	                                resume the rotation plan of a section
+//	GET    /stream                  stream events as Server-Sent Events,
+//	                                filtered by the type and section query
+//	                                parameters
+//	GET    /admin/read-only         report whether the API is read-only,
+//	                                see ReadOnly
+//	POST   /admin/read-only         make the API read-only or writable again
+//	                                from a ReadOnly body
+//
+// Every request let through counts as a consumption of the simulation, see
+// service.Service.Touch, as does every event a stream delivers; those refused
+// for a missing token or scope, see RequireToken, or because the service is
+// read-only do not. Unknown IDs map to 404, IDs that are already taken and
+// pause state conflicts to 409 and invalid bodies to 400. While the service
+// is read-only, every request other than GET and HEAD is answered with 403,
+// except those to /admin/, see service.Service.SetReadOnly. Pausing requires
+// the simulator to be running and conflicts otherwise, see
+// greenhouse.Greenhouse.Pause.
+func NewHandler(svc service.Service) http.Handler {
+	s := &server{svc: svc}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /plants", s.listPlants)
+	mux.HandleFunc("GET /plants/{id}", s.getPlant)
+	mux.HandleFunc("GET /plants/{id}/forecast", s.plantForecast)
+	mux.HandleFunc("GET /plants/{id}/diagnosis", s.plantDiagnosis)
+	mux.HandleFunc("POST /plants", s.addPlant)
+	mux.HandleFunc("DELETE /plants/{id}", s.removePlant)
+	mux.HandleFunc("POST /plants/{id}/flags", s.setPlantFlags)
+	mux.HandleFunc("GET /sections/{id}/readings", s.sectionReadings)
+	mux.HandleFunc("GET /sections/{id}/stats", s.sectionStats)
+	mux.HandleFunc("GET /sensors", s.listSensors)
+	mux.HandleFunc("POST /sensors", s.addSensor)
+	mux.HandleFunc("GET /sensors/{id}/reading", s.sensorReading)
+	mux.HandleFunc("GET /sensors/{id}/history", s.readingHistory)
+	mux.HandleFunc("POST /sensors/{id}/battery", s.replaceBattery)
+	mux.HandleFunc("GET /sensors/diagnostics", s.sensorDiagnostics)
+	mux.HandleFunc("GET /exporters/diagnostics", s.exporterDiagnostics)
+	mux.HandleFunc("POST /exporters/flush", s.flushExporters)
+	mux.HandleFunc("GET /sensors/advice", s.sensorAdvice)
+	mux.HandleFunc("POST /sensors/{id}/anomalies", s.injectAnomaly)
+	mux.HandleFunc("GET /sensors/{id}/anomalies", s.anomalyLabels)
+	mux.HandleFunc("POST /sections/{id}/anomalies", s.injectAnomaly)
+	mux.HandleFunc("POST /watering", s.water)
+	mux.HandleFunc("POST /sections/{id}/lights", s.setLights)
+	mux.HandleFunc("POST /sections/{id}/climate", s.setClimate)
+	mux.HandleFunc("POST /hvac/{actuator}", s.setActuator)
+	mux.HandleFunc("GET /water/temperature", s.waterTemperature)
+	mux.HandleFunc("PUT /water/heater", s.heatWater)
+	mux.HandleFunc("DELETE /water/heater", s.stopHeatingWater)
+	mux.HandleFunc("POST /simulator/pause", s.pause)
+	mux.HandleFunc("POST /simulator/resume", s.resume)
+	mux.HandleFunc("GET /simulator/status", s.status)
+	mux.HandleFunc("GET /costs", s.costs)
+	mux.HandleFunc("GET /alerts", s.listAlerts)
+	mux.HandleFunc("GET /world", s.world)
+	mux.HandleFunc("GET /zones", s.listZones)
+	mux.HandleFunc("POST /zones", s.addZone)
+	mux.HandleFunc("GET /zones/{id}/stats", s.zoneStats)
+	mux.HandleFunc("GET /rotations", s.listRotations)
+	mux.HandleFunc("PUT /rotations/{section}", s.setRotation)
+	mux.HandleFunc("DELETE /rotations/{section}", s.removeRotation)
+	mux.HandleFunc("POST /rotations/{section}/pause", s.pauseRotation)
+	mux.HandleFunc("POST /rotations/{section}/resume", s.resumeRotation)
+	mux.HandleFunc("GET /stream", s.stream)
+	mux.HandleFunc("GET /admin/read-only", s.readOnly)
+	mux.HandleFunc("POST /admin/read-only", s.setReadOnly)
+	// Requests are touched once handled, so that a resume request finds a
+	// simulation the idle watch paused still paused, see
+	// service.Service.Touch. Refused requests are not touched.
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if svc.ReadOnly() && !isRead(r) && !isAdmin(r) {
+			writeError(w, service.ErrReadOnly)
+			return
+		}
+		defer svc.Touch()
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// isRead reports whether r only queries the greenhouse.
+func isRead(r *http.Request) bool {
+	return r.Method == http.MethodGet || r.Method == http.MethodHead
+}
+
+// isAdmin reports whether r administers the API rather than the greenhouse.
+func isAdmin(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/admin/")
+}
+
+// Serve serves handler on listener until stop is closed, then shuts down
+// gracefully, waiting up to ShutdownTimeout for in-flight requests. Open
+// event streams are ended right away. It returns nil after a graceful
+// shutdown and the serving error otherwise.
+func Serve(listener net.Listener, handler http.Handler, stop <-chan struct{}) error {
+	base, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return base },
+	}
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(listener) }()
+
+	select {
+	case err := <-served:
+		return err
+	case <-stop:
+		cancelRequests()
+		ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+		defer cancel()
+		return server.Shutdown(ctx)
+	}
+}
+
+// listPlants serves a page of the plants. Each page is a snapshot of the
+// tick it was requested on: the plants of a later page may have grown since
+// the previous one, and Total counts the plants matching when the page was
+// taken. Pages resume after the last plant ID of the previous page, so the
+// plants added or removed in between neither shift nor repeat the next ones.
+func (s *server) listPlants(w http.ResponseWriter, r *http.Request) {
+	query, err := plantQuery(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Error{Error: err.Error()})
+		return
+	}
+	page := s.svc.QueryPlants(query)
+	list := PlantList{Plants: make([]Plant, 0, len(page.Plants)), Total: page.Total}
+	for _, plant := range page.Plants {
+		list.Plants = append(list.Plants, plantDTO(plant))
+	}
+	if page.Next != "" {
+		list.NextPageToken = base64.RawURLEncoding.EncodeToString([]byte(page.Next))
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// plantQuery reads the query parameters of GET /plants.
+func plantQuery(r *http.Request) (engine.PlantQuery, error) {
+	params := r.URL.Query()
+	query := engine.PlantQuery{
+		SectionID: params.Get("section"),
+		Filter:    models.PlantFilter{Type: params.Get("type"), Tag: params.Get("tag")},
+		Limit:     DefaultPageSize,
+	}
+	if alive := params.Get("alive"); alive != "" {
+		b, err := strconv.ParseBool(alive)
+		if err != nil {
+			return query, errors.New("alive must be true or false: " + alive)
+		}
+		query.Alive = &b
+	}
+	bounds := []struct {
+		name  string
+		bound **float64
+	}{
+		{"min_health", &query.MinHealth},
+		{"max_health", &query.MaxHealth},
+		{"min_growth", &query.MinGrowth},
+		{"max_growth", &query.MaxGrowth},
+	}
+	for _, b := range bounds {
+		value := params.Get(b.name)
+		if value == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil || f < 0 || f > 1 {
+			return query, fmt.Errorf("%s must be between 0 and 1: %s", b.name, value)
+		}
+		*b.bound = &f
+	}
+	if limit := params.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > MaxPageSize {
+			return query, fmt.Errorf("limit must be between 1 and %d: %s", MaxPageSize, limit)
+		}
+		query.Limit = n
+	}
+	if token := params.Get("page_token"); token != "" {
+		after, err := base64.RawURLEncoding.DecodeString(token)
+		if err != nil || len(after) == 0 {
+			return query, errors.New("invalid page token: " + token)
+		}
+		query.After = string(after)
+	}
+	return query, nil
+}
+
+func (s *server) getPlant(w http.ResponseWriter, r *http.Request) {
+	plant, err := s.svc.Plant(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	dto := plantDTO(plant)
+	journal, err := s.svc.PlantJournal(plant.ID)
+	if err != nil && !errors.Is(err, greenhouse.ErrNoJournal) {
+		writeError(w, err)
+		return
+	}
+	dto.Journal = journal
+	writeJSON(w, http.StatusOK, dto)
+}
+
+func (s *server) plantForecast(w http.ResponseWriter, r *http.Request) {
+	forecast, err := s.svc.PlantForecast(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, forecastDTO(forecast))
+}
+
+func (s *server) plantDiagnosis(w http.ResponseWriter, r *http.Request) {
+	diagnosis, err := s.svc.PlantDiagnosis(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, PlantDiagnosis{LimitingFactor: diagnosis.Limiting(), Losses: diagnosis})
+}
+
+func (s *server) addPlant(w http.ResponseWriter, r *http.Request) {
+	var body config.PlantConfig
+	if !readJSON(w, r, &body) {
+		return
+	}
+	plant, err := s.svc.AddPlant(body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, plantDTO(plant))
+}
+
+func (s *server) removePlant(w http.ResponseWriter, r *http.Request) {
+	if err := s.svc.RemovePlant(r.PathValue("id")); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) setPlantFlags(w http.ResponseWriter, r *http.Request) {
+	var body PlantFlagsRequest
+	if !readJSON(w, r, &body) {
+		return
+	}
+	if body.ExcludeFromWatering == nil && body.Quarantined == nil {
+		writeJSON(w, http.StatusBadRequest, Error{Error: "plant flags require exclude_from_watering or quarantined"})
+		return
+	}
+	plant, err := s.svc.SetPlantFlags(r.PathValue("id"), models.PlantFlags(body))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, plantDTO(plant))
+}
+
+func (s *server) sectionReadings(w http.ResponseWriter, r *http.Request) {
+	readings, err := s.svc.SectionReadings(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	dtos := make([]Reading, 0, len(readings))
+	for _, reading := range readings {
+		dtos = append(dtos, readingDTO(reading))
+	}
+	writeJSON(w, http.StatusOK, dtos)
+}
+
+func (s *server) listSensors(w http.ResponseWriter, r *http.Request) {
+	list := s.svc.Sensors()
+	dtos := make([]Sensor, 0, len(list))
+	for _, sensor := range list {
+		dtos = append(dtos, sensorDTO(sensor))
+	}
+	writeJSON(w, http.StatusOK, dtos)
+}
+
+func (s *server) addSensor(w http.ResponseWriter, r *http.Request) {
+	var body config.SensorConfig
+	if !readJSON(w, r, &body) {
+		return
+	}
+	sensor, err := s.svc.AddSensor(body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, sensorDTO(sensor))
+}
+
+func (s *server) sensorDiagnostics(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.svc.SensorDiagnostics())
+}
+
+func (s *server) exporterDiagnostics(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.svc.ExporterDiagnostics())
+}
+
+// flushExporters answers 504 when an exporter does not catch up in time.
+func (s *server) flushExporters(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), FlushTimeout)
+	defer cancel()
+	if err := s.svc.FlushExporters(ctx); err != nil {
+		writeJSON(w, http.StatusGatewayTimeout, Error{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, s.svc.ExporterDiagnostics())
+}
+
+func (s *server) sensorAdvice(w http.ResponseWriter, r *http.Request) {
+	advice := s.svc.SensorAdvice()
+	if advice == nil {
+		advice = []sensors.Advice{}
+	}
+	writeJSON(w, http.StatusOK, advice)
+}
+
+func (s *server) injectAnomaly(w http.ResponseWriter, r *http.Request) {
+	var body AnomalyRequest
+	if !readJSON(w, r, &body) {
+		return
+	}
+	if err := s.svc.InjectAnomaly(r.PathValue("id"), sensors.Anomaly(body)); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) anomalyLabels(w http.ResponseWriter, r *http.Request) {
+	labels, err := s.svc.AnomalyLabels(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, labels)
+}
+
+func (s *server) sensorReading(w http.ResponseWriter, r *http.Request) {
+	if tick := r.URL.Query().Get("tick"); tick != "" {
+		s.sensorReadingAt(w, r, tick)
+		return
+	}
+	reading, err := s.svc.Reading(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, readingDTO(reading))
+}
+
+func (s *server) sensorReadingAt(w http.ResponseWriter, r *http.Request, tick string) {
+	n, err := strconv.Atoi(tick)
+	if err != nil || n < 0 {
+		writeJSON(w, http.StatusBadRequest, Error{Error: "tick must be a non-negative tick number: " + tick})
+		return
+	}
+	reading, err := s.svc.ReadingAt(r.PathValue("id"), n)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, readingDTO(reading))
+}
+
+func (s *server) readingHistory(w http.ResponseWriter, r *http.Request) {
+	lastN := 0
+	if last := r.URL.Query().Get("last"); last != "" {
+		n, err := strconv.Atoi(last)
+		if err != nil || n <= 0 {
+			writeJSON(w, http.StatusBadRequest, Error{Error: "last must be a positive number of samples: " + last})
+			return
+		}
+		lastN = n
+	}
+	readings, err := s.svc.ReadingHistory(r.PathValue("id"), lastN)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	dtos := make([]Reading, 0, len(readings))
+	for _, reading := range readings {
+		dtos = append(dtos, readingDTO(reading))
+	}
+	writeJSON(w, http.StatusOK, dtos)
+}
+
+func (s *server) replaceBattery(w http.ResponseWriter, r *http.Request) {
+	sensor, err := s.svc.ReplaceBattery(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sensorDTO(sensor))
+}
+
+func (s *server) water(w http.ResponseWriter, r *http.Request) {
+	var body WaterRequest
+	if !readJSON(w, r, &body) {
+		return
+	}
+	event, err := s.svc.Water(body.SectionID, body.Amount, time.Duration(body.Duration), watering.ManualOptions{RequestID: body.RequestID, Label: body.Label})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, Watering{ID: event.ID, SectionID: event.SectionID, Amount: event.Amount, Label: event.Label, QueuedTick: event.QueuedTick})
+}
+
+func (s *server) setLights(w http.ResponseWriter, r *http.Request) {
+	var body LightsRequest
+	if !readJSON(w, r, &body) {
+		return
+	}
+	sectionID := r.PathValue("id")
+	lamp, err := s.svc.SetLights(sectionID, body.On, body.Intensity)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, Lights{SectionID: sectionID, On: lamp.On, Intensity: lamp.Intensity})
+}
+
+func (s *server) setClimate(w http.ResponseWriter, r *http.Request) {
+	var body ClimateRequest
+	if !readJSON(w, r, &body) {
+		return
+	}
+	sectionID := r.PathValue("id")
+	offset, err := s.svc.SetSectionClimateOffset(sectionID, environment.ClimateOffset(body))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, Climate{SectionID: sectionID, Temperature: offset.Temperature, Humidity: offset.Humidity, Light: offset.Light})
+}
+
+func (s *server) setActuator(w http.ResponseWriter, r *http.Request) {
+	var body ActuatorRequest
+	if !readJSON(w, r, &body) {
+		return
+	}
+	state, err := s.svc.SetActuator(environment.Actuator(r.PathValue("actuator")), body.Mode)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, HVAC{
+		Heater:     state.Heater,
+		HeaterMode: state.HeaterMode,
+		Vent:       state.Vent,
+		VentMode:   state.VentMode,
+		Offset:     state.Offset,
+	})
+}
+
+func (s *server) waterTemperature(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.svc.WaterTemperature())
+}
+
+func (s *server) heatWater(w http.ResponseWriter, r *http.Request) {
+	var body WaterHeaterRequest
+	if !readJSON(w, r, &body) {
+		return
+	}
+	state, err := s.svc.HeatWater(body.Temperature)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+func (s *server) stopHeatingWater(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.svc.StopHeatingWater())
+}
+
+func (s *server) pause(w http.ResponseWriter, r *http.Request) {
+	status, err := s.svc.Pause()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, statusDTO(status))
+}
+
+func (s *server) resume(w http.ResponseWriter, r *http.Request) {
+	status, err := s.svc.Resume()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, statusDTO(status))
+}
+
+func (s *server) status(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, statusDTO(s.svc.Status()))
+}
+
+func (s *server) costs(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.svc.Costs())
+}
+
+func (s *server) listAlerts(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.svc.ListActiveAlerts())
+}
+
+func (s *server) world(w http.ResponseWriter, r *http.Request) {
+	state, err := s.svc.WorldState()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+func (s *server) listZones(w http.ResponseWriter, r *http.Request) {
+	list := s.svc.Zones()
+	dtos := make([]Zone, 0, len(list))
+	for _, zone := range list {
+		dtos = append(dtos, zoneDTO(zone))
+	}
+	writeJSON(w, http.StatusOK, dtos)
+}
+
+func (s *server) addZone(w http.ResponseWriter, r *http.Request) {
+	var body config.ZoneConfig
+	if !readJSON(w, r, &body) {
+		return
+	}
+	zone, err := s.svc.AddZone(body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, zoneDTO(zone))
+}
+
+func (s *server) zoneStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := s.svc.ZoneStats(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+func (s *server) sectionStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := s.svc.SectionStats(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+func (s *server) listRotations(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.svc.Rotations())
+}
+
+// setRotation takes the section from the path; a body naming another
+// section is rejected.
+func (s *server) setRotation(w http.ResponseWriter, r *http.Request) {
+	var body config.RotationConfig
+	if !readJSON(w, r, &body) {
+		return
+	}
+	sectionID := r.PathValue("section")
+	if body.SectionID != "" && body.SectionID != sectionID {
+		writeJSON(w, http.StatusBadRequest, Error{Error: "rotation section does not match the path: " + body.SectionID})
+		return
+	}
+	body.SectionID = sectionID
+	status, err := s.svc.SetRotation(body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (s *server) removeRotation(w http.ResponseWriter, r *http.Request) {
+	if err := s.svc.RemoveRotation(r.PathValue("section")); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) pauseRotation(w http.ResponseWriter, r *http.Request) {
+	if err := s.svc.PauseRotation(r.PathValue("section")); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) resumeRotation(w http.ResponseWriter, r *http.Request) {
+	if err := s.svc.ResumeRotation(r.PathValue("section")); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) readOnly(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, ReadOnly{ReadOnly: s.svc.ReadOnly()})
+}
+
+func (s *server) setReadOnly(w http.ResponseWriter, r *http.Request) {
+	var body ReadOnly
+	if !readJSON(w, r, &body) {
+		return
+	}
+	s.svc.SetReadOnly(body.ReadOnly)
+	writeJSON(w, http.StatusOK, ReadOnly{ReadOnly: s.svc.ReadOnly()})
+}
+
+// readJSON decodes the request body into v, rejecting unknown fields, and
+// writes a 400 response when it cannot.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		writeJSON(w, http.StatusBadRequest, Error{Error: "invalid request body: " + err.Error()})
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes err with the status code of its cause. Errors that are
+// not about a missing or conflicting resource are the caller's to fix.
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, statusOf(err), Error{Error: err.Error()})
+}
+
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, engine.ErrPlantNotFound),
+		errors.Is(err, sensors.ErrSensorNotFound),
+		errors.Is(err, sensors.ErrNoSensorsInSection),
+		errors.Is(err, sensors.ErrNoPlantsInSection),
+		errors.Is(err, sensors.ErrTickBeforeHistory),
+		errors.Is(err, sensors.ErrNoSampleAtTick),
+		errors.Is(err, watering.ErrNoPlantsInSection),
+		errors.Is(err, greenhouse.ErrZoneNotFound),
+		errors.Is(err, greenhouse.ErrSectionNotFound),
+		errors.Is(err, greenhouse.ErrRotationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, engine.ErrPlantExists),
+		errors.Is(err, sensors.ErrSensorExists),
+		errors.Is(err, greenhouse.ErrZoneExists),
+		errors.Is(err, greenhouse.ErrAlreadyPaused),
+		errors.Is(err, greenhouse.ErrNotPaused),
+		errors.Is(err, engine.ErrNotStarted),
+		errors.Is(err, engine.ErrAlreadyStopped):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrReadOnly):
+		return http.StatusForbidden
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,1066 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/environment"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/greenhouse"
+	"greenhouse-simulator/internal/models"
+	"greenhouse-simulator/internal/sensors"
+	"greenhouse-simulator/internal/service"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestHandler serves the demo greenhouse with a tick interval long enough
+// that no tick runs during a test.
+func newTestHandler(t *testing.T) (http.Handler, greenhouse.Greenhouse) {
+	t.Helper()
+	cfg := config.Default()
+	cfg.TickInterval = config.Duration(time.Hour)
+	g, err := greenhouse.New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	return NewHandler(service.New(g)), g
+}
+
+func do(t *testing.T, handler http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return recorder
+}
+
+func decode[T any](t *testing.T, recorder *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.NewDecoder(recorder.Body).Decode(&v); err != nil {
+		t.Fatalf("failed to decode response %q: %v", recorder.Body.String(), err)
+	}
+	return v
+}
+
+func TestEndpoints_StatusCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		expected int
+		errorMsg string
+	}{
+		{"list plants", "GET", "/plants", "", http.StatusOK, ""},
+		{"list plants with filters", "GET", "/plants?section=section-A&alive=true&min_health=0.5&limit=1", "", http.StatusOK, ""},
+		{"list plants with a bad alive", "GET", "/plants?alive=maybe", "", http.StatusBadRequest, "alive must be true or false: maybe"},
+		{"list plants with a bad health", "GET", "/plants?max_health=2", "", http.StatusBadRequest, "max_health must be between 0 and 1: 2"},
+		{"list plants with a bad limit", "GET", "/plants?limit=5000", "", http.StatusBadRequest, "limit must be between 1 and 1000: 5000"},
+		{"list plants with a bad page token", "GET", "/plants?page_token=!", "", http.StatusBadRequest, "invalid page token: !"},
+		{"get plant", "GET", "/plants/tomato-1", "", http.StatusOK, ""},
+		{"get unknown plant", "GET", "/plants/cactus-1", "", http.StatusNotFound, "no plant found for the provided ID: cactus-1"},
+		{"add plant", "POST", "/plants", `{"id": "basil-1", "type": "Basil", "section": "section-C", "initial_saturation": 0.5}`, http.StatusCreated, ""},
+		{"add duplicate plant", "POST", "/plants", `{"id": "tomato-1", "type": "Tomato", "section": "section-A", "initial_saturation": 0.5}`, http.StatusConflict, "plant with ID already added to simulator: tomato-1"},
+		{"add plant of unknown type", "POST", "/plants", `{"id": "fern-1", "type": "Fern", "section": "section-A", "initial_saturation": 0.5}`, http.StatusBadRequest, "plant fern-1: unknown plant type: Fern"},
+		{"add plant with unknown field", "POST", "/plants", `{"id": "basil-2", "kind": "Basil"}`, http.StatusBadRequest, `invalid request body: json: unknown field "kind"`},
+		{"add plant with malformed body", "POST", "/plants", `{"id":`, http.StatusBadRequest, "invalid request body: unexpected EOF"},
+		{"forecast plant", "GET", "/plants/tomato-1/forecast", "", http.StatusOK, ""},
+		{"forecast unknown plant", "GET", "/plants/cactus-1/forecast", "", http.StatusNotFound, "no plant found for the provided ID: cactus-1"},
+		{"diagnose plant", "GET", "/plants/tomato-1/diagnosis", "", http.StatusOK, ""},
+		{"diagnose unknown plant", "GET", "/plants/cactus-1/diagnosis", "", http.StatusNotFound, "no plant found for the provided ID: cactus-1"},
+		{"remove plant", "DELETE", "/plants/tomato-2", "", http.StatusNoContent, ""},
+		{"remove unknown plant", "DELETE", "/plants/cactus-1", "", http.StatusNotFound, "no plant found for the provided ID: cactus-1"},
+		{"flag plant", "POST", "/plants/tomato-1/flags", `{"quarantined": true}`, http.StatusOK, ""},
+		{"flag plant without flags", "POST", "/plants/tomato-1/flags", `{}`, http.StatusBadRequest, "plant flags require exclude_from_watering or quarantined"},
+		{"flag unknown plant", "POST", "/plants/cactus-1/flags", `{"quarantined": true}`, http.StatusNotFound, "no plant found for the provided ID: cactus-1"},
+		{"section readings", "GET", "/sections/section-B/readings", "", http.StatusOK, ""},
+		{"readings of a section without sensors", "GET", "/sections/section-A/readings", "", http.StatusNotFound, "no sensors in section: section-A"},
+		{"section stats", "GET", "/sections/section-A/stats", "", http.StatusOK, ""},
+		{"stats of a section without plants", "GET", "/sections/section-Z/stats", "", http.StatusNotFound, "no plants found in the provided section: section-Z"},
+		{"list sensors", "GET", "/sensors", "", http.StatusOK, ""},
+		{"add sensor", "POST", "/sensors", `{"id": "sensor-2", "type": "soil_moisture", "section": "section-A"}`, http.StatusCreated, ""},
+		{"add duplicate sensor", "POST", "/sensors", `{"id": "sensor-1", "type": "soil_moisture", "section": "section-A"}`, http.StatusConflict, "sensor with ID already exists: sensor-1"},
+		{"add sensor without section", "POST", "/sensors", `{"id": "sensor-3", "type": "soil_moisture"}`, http.StatusBadRequest, "sensor section ID cannot be empty"},
+		{"read sensor", "GET", "/sensors/sensor-1/reading", "", http.StatusOK, ""},
+		{"read unknown sensor", "GET", "/sensors/sensor-9/reading", "", http.StatusNotFound, "no sensor found for the provided ID: sensor-9"},
+		{"water section", "POST", "/watering", `{"section": "section-A", "amount": 0.5, "duration": "8s"}`, http.StatusAccepted, ""},
+		{"water empty section", "POST", "/watering", `{"section": "section-Z", "amount": 0.5}`, http.StatusNotFound, "no plants in section: section-Z"},
+		{"water without amount", "POST", "/watering", `{"section": "section-A"}`, http.StatusBadRequest, "amount must be positive"},
+		{"switch lights", "POST", "/sections/section-A/lights", `{"on": true, "intensity": 0.5}`, http.StatusOK, ""},
+		{"lights too bright", "POST", "/sections/section-A/lights", `{"on": true, "intensity": 1.5}`, http.StatusBadRequest, "lights intensity must be between 0.0 and 1.0"},
+		{"set climate", "POST", "/sections/section-A/climate", `{"temperature": -3, "humidity": 0.1}`, http.StatusOK, ""},
+		{"climate too humid", "POST", "/sections/section-A/climate", `{"humidity": 1.5}`, http.StatusBadRequest, "humidity offset must be between -1.0 and 1.0"},
+		{"force heater on", "POST", "/hvac/heater", `{"mode": "on"}`, http.StatusOK, ""},
+		{"unknown actuator", "POST", "/hvac/fan", `{"mode": "on"}`, http.StatusBadRequest, "actuator must be heater or vent: fan"},
+		{"unknown actuator mode", "POST", "/hvac/vent", `{"mode": "max"}`, http.StatusBadRequest, "actuator mode must be auto, on or off: max"},
+		{"water temperature", "GET", "/water/temperature", "", http.StatusOK, ""},
+		{"heat water", "PUT", "/water/heater", `{"temperature": 25}`, http.StatusOK, ""},
+		{"heat water too hot", "PUT", "/water/heater", `{"temperature": 120}`, http.StatusBadRequest, "water temperature must be between 0 and 100"},
+		{"stop heating water", "DELETE", "/water/heater", "", http.StatusOK, ""},
+		{"list zones", "GET", "/zones", "", http.StatusOK, ""},
+		{"add zone", "POST", "/zones", `{"id": "west", "sections": ["section-A", "section-B"]}`, http.StatusCreated, ""},
+		{"add zone of an unknown section", "POST", "/zones", `{"id": "west", "sections": ["section-Z"]}`, http.StatusBadRequest, "zone west has an unknown section: section-Z"},
+		{"stats of an unknown zone", "GET", "/zones/west/stats", "", http.StatusNotFound, "no zone found for the provided ID: west"},
+		{"status", "GET", "/simulator/status", "", http.StatusOK, ""},
+		{"resume while running", "POST", "/simulator/resume", "", http.StatusConflict, "simulation is not paused"},
+		{"unknown route", "GET", "/tanks", "", http.StatusNotFound, ""},
+		{"wrong method", "PUT", "/plants", "", http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := newTestHandler(t)
+			recorder := do(t, handler, tt.method, tt.path, tt.body)
+			if recorder.Code != tt.expected {
+				t.Fatalf("expected status %d, got %d: %s", tt.expected, recorder.Code, recorder.Body.String())
+			}
+			if tt.errorMsg != "" {
+				if got := decode[Error](t, recorder).Error; got != tt.errorMsg {
+					t.Errorf("expected error message '%s', got '%s'", tt.errorMsg, got)
+				}
+			}
+		})
+	}
+}
+
+func TestPlants(t *testing.T) {
+	handler, g := newTestHandler(t)
+
+	list := decode[PlantList](t, do(t, handler, "GET", "/plants", ""))
+	if plants := list.Plants; len(plants) != 3 || plants[0].ID != "lettuce-1" || plants[2].ID != "tomato-2" {
+		t.Fatalf("expected the demo plants ordered by ID, got %+v", plants)
+	}
+	if list.Total != 3 || list.NextPageToken != "" {
+		t.Errorf("expected a single page of 3 plants, got %+v", list)
+	}
+
+	created := decode[Plant](t, do(t, handler, "POST", "/plants", `{"id": "basil-1", "type": "Basil", "section": "section-C", "initial_saturation": 0.4, "tags": ["herbs"]}`))
+	expected := Plant{ID: "basil-1", Type: "Basil", SectionID: "section-C", SoilSaturation: 0.4, Health: 1, Alive: true, Tags: []string{"herbs"}}
+	if created.CreatedAt.IsZero() {
+		t.Error("expected the creation time to be set")
+	}
+	created.CreatedAt = time.Time{}
+	if !reflect.DeepEqual(created, expected) {
+		t.Errorf("expected created plant %+v, got %+v", expected, created)
+	}
+	if got := decode[Plant](t, do(t, handler, "GET", "/plants/basil-1", "")); got.Type != "Basil" {
+		t.Errorf("expected the added plant to be served, got %+v", got)
+	}
+	if len(g.Simulator().GetPlantsBySectionID("section-C")) != 1 {
+		t.Error("expected the plant to be added to the simulator")
+	}
+
+	if code := do(t, handler, "DELETE", "/plants/basil-1", "").Code; code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", code)
+	}
+	if code := do(t, handler, "GET", "/plants/basil-1", "").Code; code != http.StatusNotFound {
+		t.Errorf("expected the removed plant to be gone, got status %d", code)
+	}
+}
+
+func TestPlants_Query(t *testing.T) {
+	handler, g := newTestHandler(t)
+	if err := g.Simulator().RemovePlant("tomato-2"); err != nil {
+		t.Fatalf("failed to remove plant: %v", err)
+	}
+	for _, body := range []string{
+		`{"id": "basil-1", "type": "Basil", "section": "section-C", "initial_saturation": 0.4, "tags": ["herbs"]}`,
+		`{"id": "basil-2", "type": "Basil", "section": "section-C", "initial_saturation": 0.4}`,
+	} {
+		if code := do(t, handler, "POST", "/plants", body).Code; code != http.StatusCreated {
+			t.Fatalf("failed to add plant: status %d", code)
+		}
+	}
+	dead, err := g.Simulator().GetPlant("basil-2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dead.Alive, dead.Health = false, 0
+	if err := g.Simulator().RemovePlant(dead.ID); err != nil {
+		t.Fatalf("failed to remove plant: %v", err)
+	}
+	if err := g.Simulator().AddPlant(dead); err != nil {
+		t.Fatalf("failed to add plant: %v", err)
+	}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"", []string{"basil-1", "basil-2", "lettuce-1", "tomato-1"}},
+		{"section=section-C", []string{"basil-1", "basil-2"}},
+		{"alive=false", []string{"basil-2"}},
+		{"type=Basil&alive=true", []string{"basil-1"}},
+		{"tag=herbs", []string{"basil-1"}},
+		{"min_health=0.5&max_growth=0.5", []string{"basil-1", "lettuce-1", "tomato-1"}},
+		{"section=section-A&type=Basil", []string{}},
+		{"section=section-Z", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			list := decode[PlantList](t, do(t, handler, "GET", "/plants?"+tt.query, ""))
+			ids := make([]string, 0, len(list.Plants))
+			for _, plant := range list.Plants {
+				ids = append(ids, plant.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.expected) || list.Total != len(tt.expected) {
+				t.Errorf("expected plants %v, got %v of %d", tt.expected, ids, list.Total)
+			}
+		})
+	}
+}
+
+func TestPlants_Pages(t *testing.T) {
+	handler, g := newTestHandler(t)
+
+	first := decode[PlantList](t, do(t, handler, "GET", "/plants?limit=2", ""))
+	if len(first.Plants) != 2 || first.Plants[1].ID != "tomato-1" || first.Total != 3 || first.NextPageToken == "" {
+		t.Fatalf("expected the first page of 2 plants out of 3, got %+v", first)
+	}
+
+	// A plant added before the cursor between two pages counts towards the
+	// total but does not shift the next page.
+	if code := do(t, handler, "POST", "/plants", `{"id": "basil-1", "type": "Basil", "section": "section-C", "initial_saturation": 0.4}`).Code; code != http.StatusCreated {
+		t.Fatalf("failed to add plant: status %d", code)
+	}
+	g.Simulator().Step()
+	second := decode[PlantList](t, do(t, handler, "GET", "/plants?limit=2&page_token="+first.NextPageToken, ""))
+	if len(second.Plants) != 1 || second.Plants[0].ID != "tomato-2" || second.Total != 4 || second.NextPageToken != "" {
+		t.Errorf("expected the last page with tomato-2 out of 4 plants, got %+v", second)
+	}
+}
+
+// TestPlants_WhileTicking reads the plants through the API and the service
+// while the simulation ticks and waters them, which must not race, see go
+// test -race.
+func TestPlants_WhileTicking(t *testing.T) {
+	cfg := config.Default()
+	cfg.TickInterval = config.Duration(time.Hour)
+	g, err := greenhouse.New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	svc := service.New(g)
+	handler := NewHandler(svc)
+	if err := g.Watering().WaterSection("section-A", 0.5, 1000*time.Hour); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			for _, path := range []string{"/plants", "/plants/tomato-1", "/plants/cactus-1"} {
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+				if recorder.Code != http.StatusOK && recorder.Code != http.StatusNotFound {
+					t.Errorf("expected %s to be served, got status %d", path, recorder.Code)
+				}
+			}
+			if plants := svc.Plants(); len(plants) != 3 {
+				t.Errorf("expected the 3 demo plants, got %d", len(plants))
+			}
+		}
+	}()
+	for range 1000 {
+		g.Simulator().Step()
+	}
+	close(stop)
+	<-done
+}
+
+func TestPlantFlags(t *testing.T) {
+	handler, g := newTestHandler(t)
+
+	flagged := decode[Plant](t, do(t, handler, "POST", "/plants/tomato-1/flags", `{"exclude_from_watering": true, "quarantined": true}`))
+	if !flagged.ExcludeFromWatering || !flagged.Quarantined {
+		t.Errorf("expected both flags set, got %+v", flagged)
+	}
+	released := decode[Plant](t, do(t, handler, "POST", "/plants/tomato-1/flags", `{"quarantined": false}`))
+	if !released.ExcludeFromWatering || released.Quarantined {
+		t.Errorf("expected the plant released but still excluded from watering, got %+v", released)
+	}
+	if plant, _ := g.Simulator().GetPlant("tomato-1"); !plant.ExcludeFromWatering || plant.Quarantined {
+		t.Errorf("expected the flags on the simulated plant, got %+v", plant)
+	}
+	if got := decode[Plant](t, do(t, handler, "GET", "/plants/tomato-1", "")); !reflect.DeepEqual(got, released) {
+		t.Errorf("expected %+v to be served, got %+v", released, got)
+	}
+}
+
+func TestPlantDiagnosis(t *testing.T) {
+	handler, g := newTestHandler(t)
+	g.Simulator().Step()
+
+	expected, err := g.Simulator().GetPlantDiagnosis("tomato-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := PlantDiagnosis{LimitingFactor: expected.Limiting(), Losses: expected}
+	if got := decode[PlantDiagnosis](t, do(t, handler, "GET", "/plants/tomato-1/diagnosis", "")); got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestPlantForecast(t *testing.T) {
+	handler, g := newTestHandler(t)
+
+	plant, err := g.Simulator().GetPlant("tomato-2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected, err := models.ForecastPlant(plant.Snapshot(), models.ForecastAssumptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := decode[PlantForecast](t, do(t, handler, "GET", "/plants/tomato-2/forecast", "")); got != forecastDTO(expected) {
+		t.Errorf("expected %+v, got %+v", forecastDTO(expected), got)
+	}
+
+	data, err := json.Marshal(PlantForecast{TicksToMaturity: models.Never, TicksToDeath: 12, TicksToIntervention: 0, InterventionSaturation: 0.4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `{"ticks_to_maturity":"never","ticks_to_death":12,"ticks_to_intervention":0,"intervention_saturation":0.4}`; string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+}
+
+func TestPlantJournal(t *testing.T) {
+	cfg := config.Default()
+	cfg.TickInterval = config.Duration(time.Hour)
+	cfg.Journal = &config.JournalConfig{}
+	g, err := greenhouse.New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	handler := NewHandler(service.New(g))
+	if err := g.Watering().WaterPlant("tomato-1", 0.2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g.Simulator().Step()
+
+	expected, err := g.GetPlantJournal("tomato-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.ContainsFunc(expected, func(e greenhouse.JournalEntry) bool { return e.Type == events.PlantWatered }) {
+		t.Fatalf("expected the watering in the journal, got %+v", expected)
+	}
+	if got := decode[Plant](t, do(t, handler, "GET", "/plants/tomato-1", "")); !reflect.DeepEqual(got.Journal, expected) {
+		t.Errorf("expected the journal %+v, got %+v", expected, got.Journal)
+	}
+	if got := decode[PlantList](t, do(t, handler, "GET", "/plants", "")).Plants; got[1].ID != "tomato-1" || got[1].Journal != nil {
+		t.Errorf("expected the plant list without journals, got %+v", got)
+	}
+}
+
+func TestSensorsAndReadings(t *testing.T) {
+	handler, g := newTestHandler(t)
+
+	do(t, handler, "POST", "/sensors", `{"id": "sensor-0", "type": "soil_moisture", "section": "section-B"}`)
+	list := decode[[]Sensor](t, do(t, handler, "GET", "/sensors", ""))
+	if len(list) != 2 || list[0] != (Sensor{ID: "sensor-0", Type: "soil_moisture", SectionID: "section-B"}) {
+		t.Fatalf("expected the added sensor first, got %+v", list)
+	}
+
+	readings := decode[[]Reading](t, do(t, handler, "GET", "/sections/section-B/readings", ""))
+	if len(readings) != 2 || readings[0].SensorID != "sensor-0" || readings[0].Value != 0.6 {
+		t.Fatalf("expected two readings of 0.6, got %+v", readings)
+	}
+
+	g.Sensors().FailSensor("sensor-0")
+	readings = decode[[]Reading](t, do(t, handler, "GET", "/sections/section-B/readings", ""))
+	if len(readings) != 1 || readings[0].SensorID != "sensor-1" {
+		t.Errorf("expected the failed sensor to be left out, got %+v", readings)
+	}
+
+	diagnostics := decode[sensors.Diagnostics](t, do(t, handler, "GET", "/sensors/diagnostics", ""))
+	if diagnostics.Reads != 3 || len(diagnostics.Sensors) != 2 || diagnostics.Sensors[0].Reads != 1 || diagnostics.Sensors[1].Reads != 2 {
+		t.Errorf("expected 3 reads of the two sensors, got %+v", diagnostics)
+	}
+}
+
+func TestSensorAdvice(t *testing.T) {
+	handler, _ := newTestHandler(t)
+	advice := decode[[]sensors.Advice](t, do(t, handler, "GET", "/sensors/advice", ""))
+	if len(advice) != 1 || advice[0].Code != sensors.AdviceUnmonitoredSection || advice[0].SectionID != "section-A" || advice[0].Message == "" {
+		t.Fatalf("expected advice to monitor section-A, got %+v", advice)
+	}
+
+	do(t, handler, "POST", "/sensors", `{"id": "sensor-2", "type": "soil_moisture", "section": "section-A"}`)
+	recorder := do(t, handler, "GET", "/sensors/advice", "")
+	if body := strings.TrimSpace(recorder.Body.String()); body != "[]" {
+		t.Errorf("expected no advice, got %s", body)
+	}
+}
+
+func TestSensorBattery(t *testing.T) {
+	handler, g := newTestHandler(t)
+
+	body := `{"id": "sensor-0", "type": "temperature", "section": "section-B", "battery": {"level": 0.5, "drain": 0.25}, "sample_interval": 2}`
+	if code := do(t, handler, "POST", "/sensors", body).Code; code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", code)
+	}
+	reading := decode[Reading](t, do(t, handler, "GET", "/sensors/sensor-0/reading", ""))
+	if reading.Battery == nil || *reading.Battery != 0.25 {
+		t.Fatalf("expected the sample to leave the battery at 0.25, got %+v", reading)
+	}
+	list := decode[[]Sensor](t, do(t, handler, "GET", "/sensors", ""))
+	if expected := (Battery{Level: 0.25, Drain: 0.25, SamplesLeft: 1}); list[0].Battery == nil || *list[0].Battery != expected || list[0].SampleInterval != 2 {
+		t.Fatalf("expected the battery %+v, got %+v", expected, list[0])
+	}
+
+	g.Simulator().Step()
+	g.Simulator().Step()
+	if code := do(t, handler, "GET", "/sensors/sensor-0/reading", "").Code; code != http.StatusBadRequest {
+		t.Fatalf("expected the depleted sensor to fail with 400, got %d", code)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected int
+	}{
+		{"wireless sensor", "/sensors/sensor-0/battery", http.StatusOK},
+		{"wired sensor", "/sensors/sensor-1/battery", http.StatusBadRequest},
+		{"unknown sensor", "/sensors/sensor-9/battery", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := do(t, handler, "POST", tt.path, "").Code; code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, code)
+			}
+		})
+	}
+	if reading := decode[Reading](t, do(t, handler, "GET", "/sensors/sensor-0/reading", "")); *reading.Battery != 0.75 {
+		t.Errorf("expected the replaced battery to read again, got %+v", reading)
+	}
+}
+
+func TestWatering(t *testing.T) {
+	handler, g := newTestHandler(t)
+
+	if code := do(t, handler, "POST", "/watering", `{"section": "section-B", "amount": 0.3}`).Code; code != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d", code)
+	}
+	events := g.Watering().GetActiveEvents()
+	if len(events) != 1 || events[0].SectionID != "section-B" || events[0].Amount != 0.3 {
+		t.Errorf("expected a manual watering of section-B, got %+v", events)
+	}
+}
+
+func TestWatering_RequestID(t *testing.T) {
+	handler, g := newTestHandler(t)
+
+	body := `{"section": "section-A", "amount": 0.3, "request_id": "%s", "label": "pre-vacation soak"}`
+	var responses []Watering
+	for _, requestID := range []string{"req-1", "req-1", "req-2"} {
+		recorder := do(t, handler, "POST", "/watering", fmt.Sprintf(body, requestID))
+		if recorder.Code != http.StatusAccepted {
+			t.Fatalf("expected status 202, got %d: %s", recorder.Code, recorder.Body)
+		}
+		responses = append(responses, decode[Watering](t, recorder))
+	}
+	expected := Watering{ID: responses[0].ID, SectionID: "section-A", Amount: 0.3, Label: "pre-vacation soak"}
+	if responses[0] != expected || responses[1] != expected {
+		t.Errorf("expected the retry to respond with %+v, got %+v", expected, responses[:2])
+	}
+	if responses[2].ID == expected.ID {
+		t.Errorf("expected another request ID to queue another event, got %+v", responses[2])
+	}
+	if events := g.Watering().GetActiveEvents(); len(events) != 2 || events[0].Label != "pre-vacation soak" {
+		t.Errorf("expected two labeled events, got %+v", events)
+	}
+}
+
+func TestAnomalies(t *testing.T) {
+	handler, _ := newTestHandler(t)
+
+	if recorder := do(t, handler, "POST", "/sections/section-B/anomalies", `{"kind": "spike", "magnitude": 0.2, "ticks": 2}`); recorder.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d: %s", recorder.Code, recorder.Body)
+	}
+	recorder := do(t, handler, "GET", "/sensors/sensor-1/reading", "")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", recorder.Code, recorder.Body)
+	}
+	if strings.Contains(recorder.Body.String(), "anomal") {
+		t.Errorf("expected the reading not to tell it is anomalous, got %s", recorder.Body)
+	}
+	if reading := decode[Reading](t, recorder); math.Abs(reading.Value-0.8) > 1e-9 {
+		t.Errorf("expected the spike on the reading, got %v", reading.Value)
+	}
+	recorder = do(t, handler, "GET", "/sensors/sensor-1/anomalies", "")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", recorder.Code, recorder.Body)
+	}
+	expected := []sensors.AnomalyLabel{{Tick: 0, Kind: sensors.AnomalySpike}}
+	if labels := decode[[]sensors.AnomalyLabel](t, recorder); !reflect.DeepEqual(labels, expected) {
+		t.Errorf("expected labels %v, got %v", expected, labels)
+	}
+
+	for _, tt := range []struct {
+		path, body string
+		status     int
+	}{
+		{"/sensors/sensor-1/anomalies", `{"kind": "glitch", "ticks": 2}`, http.StatusBadRequest},
+		{"/sensors/ghost/anomalies", `{"kind": "dropout", "ticks": 2}`, http.StatusNotFound},
+	} {
+		if recorder := do(t, handler, "POST", tt.path, tt.body); recorder.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.body, tt.status, recorder.Code)
+		}
+	}
+}
+
+func TestLights(t *testing.T) {
+	handler, g := newTestHandler(t)
+
+	recorder := do(t, handler, "POST", "/sections/section-B/lights", `{"on": true}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	expected := Lights{SectionID: "section-B", On: true, Intensity: 1}
+	if got := decode[Lights](t, recorder); got != expected {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+	if lamp := g.Lights().Get("section-B"); !lamp.On || lamp.Intensity != 1 {
+		t.Errorf("expected the section-B lights on at full intensity, got %+v", lamp)
+	}
+
+	do(t, handler, "POST", "/sections/section-B/lights", `{"on": false}`)
+	if g.Lights().Get("section-B").On {
+		t.Error("expected the section-B lights off")
+	}
+}
+
+func TestClimate(t *testing.T) {
+	handler, g := newTestHandler(t)
+
+	recorder := do(t, handler, "POST", "/sections/section-B/climate", `{"temperature": -4, "light": 0.5}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	expected := Climate{SectionID: "section-B", Temperature: -4, Light: 0.5}
+	if got := decode[Climate](t, recorder); got != expected {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+	global, section := g.SectionConditions("section-A"), g.SectionConditions("section-B")
+	if section.Temperature != global.Temperature-4 {
+		t.Errorf("expected section-B 4 degrees colder than section-A, got %.2f and %.2f", section.Temperature, global.Temperature)
+	}
+
+	do(t, handler, "POST", "/sections/section-B/climate", `{}`)
+	if offset := g.SectionClimateOffset("section-B"); offset != (environment.ClimateOffset{}) {
+		t.Errorf("expected the section-B microclimate removed, got %+v", offset)
+	}
+}
+
+func TestHVAC(t *testing.T) {
+	handler, g := newTestHandler(t)
+
+	recorder := do(t, handler, "POST", "/hvac/vent", `{"mode": "on"}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	expected := HVAC{HeaterMode: "auto", VentMode: "on"}
+	if got := decode[HVAC](t, recorder); got != expected {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+	g.Simulator().Step()
+	if !g.HVAC().State().Vent {
+		t.Error("expected the vent forced on after a tick")
+	}
+}
+
+func TestCosts(t *testing.T) {
+	handler, g := newTestHandler(t)
+	ledger := greenhouse.CostLedger{
+		Sections: map[string]greenhouse.Costs{"section-A": {Water: 1.5, Total: 1.5}},
+		Total:    greenhouse.Costs{Water: 1.5, Heating: 2, Total: 3.5},
+	}
+	g.RestoreCosts(ledger)
+
+	recorder := do(t, handler, "GET", "/costs", "")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	if got := decode[greenhouse.CostLedger](t, recorder); !reflect.DeepEqual(got, ledger) {
+		t.Errorf("expected %+v, got %+v", ledger, got)
+	}
+}
+
+func TestListActiveAlerts(t *testing.T) {
+	handler, g := newTestHandler(t)
+	recorder := do(t, handler, "GET", "/alerts", "")
+	if got := decode[[]greenhouse.Alert](t, recorder); recorder.Code != http.StatusOK || len(got) != 0 {
+		t.Fatalf("expected no alerts without an alerts config, got %d: %+v", recorder.Code, got)
+	}
+
+	// Section-A has plants but no sensor.
+	cfg := g.Config()
+	cfg.Alerts = &config.AlertsConfig{}
+	if _, err := g.ReloadConfig(cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g.Simulator().Step()
+
+	recorder = do(t, handler, "GET", "/alerts", "")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	expected := []greenhouse.Alert{{Rule: config.AlertUnmonitoredSection, Severity: config.SeverityWarning, SectionID: "section-A",
+		Message: "section has plants but no sensors: section-A", Notifications: 1}}
+	if got := decode[[]greenhouse.Alert](t, recorder); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestWaterHeater(t *testing.T) {
+	handler, g := newTestHandler(t)
+	recorder := do(t, handler, "PUT", "/water/heater", `{"temperature": 25}`)
+	expected := greenhouse.WaterTemperature{Temperature: 25, Heated: true}
+	if got := decode[greenhouse.WaterTemperature](t, recorder); recorder.Code != http.StatusOK || got != expected {
+		t.Fatalf("expected %+v, got %d: %+v", expected, recorder.Code, got)
+	}
+	if got := g.WaterTemperature(); got != expected {
+		t.Errorf("expected the greenhouse to heat its water, got %+v", got)
+	}
+
+	recorder = do(t, handler, "DELETE", "/water/heater", "")
+	if got := decode[greenhouse.WaterTemperature](t, recorder); recorder.Code != http.StatusOK || got.Heated {
+		t.Errorf("expected the heater off, got %d: %+v", recorder.Code, got)
+	}
+}
+
+func TestWorld(t *testing.T) {
+	handler, g := newTestHandler(t)
+	g.Simulator().Step()
+
+	recorder := do(t, handler, "GET", "/world", "")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	expected, err := g.BuildWorldState()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := decode[greenhouse.WorldState](t, recorder); !reflect.DeepEqual(&got, expected) {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestReadingHistory(t *testing.T) {
+	handler, g := newTestHandler(t)
+	for range 3 {
+		g.Simulator().Step()
+	}
+
+	history := decode[[]Reading](t, do(t, handler, "GET", "/sensors/sensor-1/history?last=2", ""))
+	samples, err := g.Sensors().GetHistory("sensor-1", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected the samples of the last two ticks, got %+v", history)
+	}
+	for i, sample := range samples {
+		if got := history[i]; got.Tick != sample.Tick || got.Value != sample.Value || !got.HasDelta ||
+			got.DeltaPerTick != sample.DeltaPerTick || got.Rate != sample.Rate {
+			t.Errorf("expected %+v, got %+v", readingDTO(sample), got)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected int
+	}{
+		{"every sample", "/sensors/sensor-1/history", http.StatusOK},
+		{"invalid last", "/sensors/sensor-1/history?last=none", http.StatusBadRequest},
+		{"unknown sensor", "/sensors/sensor-9/history", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := do(t, handler, "GET", tt.path, "").Code; code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, code)
+			}
+		})
+	}
+}
+
+func TestReadingAt(t *testing.T) {
+	handler, g := newTestHandler(t)
+	for range 3 {
+		g.Simulator().Step()
+	}
+	samples, err := g.Sensors().GetHistory("sensor-1", 0)
+	if err != nil || len(samples) == 0 {
+		t.Fatalf("expected sensor samples, got %v and %v", samples, err)
+	}
+	first := samples[0]
+
+	recorder := do(t, handler, "GET", fmt.Sprintf("/sensors/sensor-1/reading?tick=%d", first.Tick), "")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if got := decode[Reading](t, recorder); got.Tick != first.Tick || got.Value != first.Value {
+		t.Errorf("expected %+v, got %+v", readingDTO(first), got)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected int
+	}{
+		{"invalid tick", "/sensors/sensor-1/reading?tick=none", http.StatusBadRequest},
+		{"negative tick", "/sensors/sensor-1/reading?tick=-1", http.StatusBadRequest},
+		{"future tick", "/sensors/sensor-1/reading?tick=99", http.StatusBadRequest},
+		{"before the history", fmt.Sprintf("/sensors/sensor-1/reading?tick=%d", first.Tick-1), http.StatusNotFound},
+		{"unknown sensor", "/sensors/sensor-9/reading?tick=1", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := do(t, handler, "GET", tt.path, "").Code; code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, code)
+			}
+		})
+	}
+}
+
+func TestZones(t *testing.T) {
+	handler, g := newTestHandler(t)
+
+	recorder := do(t, handler, "POST", "/zones", `{"id": "west", "name": "West wing", "sections": ["section-A"]}`)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	expected := Zone{ID: "west", Name: "West wing", Sections: []string{"section-A"}}
+	if got := decode[Zone](t, recorder); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+	if code := do(t, handler, "POST", "/zones", `{"id": "west", "sections": ["section-B"]}`).Code; code != http.StatusConflict {
+		t.Errorf("expected a taken zone ID to conflict, got %d", code)
+	}
+	if zones := decode[[]Zone](t, do(t, handler, "GET", "/zones", "")); !reflect.DeepEqual(zones, []Zone{expected}) {
+		t.Errorf("expected [%+v], got %+v", expected, zones)
+	}
+
+	recorder = do(t, handler, "GET", "/zones/west/stats", "")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	stats, err := g.ZoneStats("west")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := decode[greenhouse.ZoneStats](t, recorder); !reflect.DeepEqual(got, stats) || got.Plants != 2 {
+		t.Errorf("expected %+v, got %+v", stats, got)
+	}
+}
+
+func TestExporters(t *testing.T) {
+	handler, g := newTestHandler(t)
+	recorder, err := greenhouse.NewRunRecorder(g, io.Discard, greenhouse.RecordOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := g.Exporters().Register("csv", recorder, 8); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g.Simulator().Step()
+
+	response := do(t, handler, "POST", "/exporters/flush", "")
+	if response.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", response.Code, response.Body.String())
+	}
+	stats := decode[[]greenhouse.ExporterStats](t, response)
+	if len(stats) != 1 || stats[0].Name != "csv" || stats[0].QueueSize != 8 || stats[0].Queued != 0 || stats[0].Handled == 0 {
+		t.Errorf("expected the csv exporter caught up, got %+v", stats)
+	}
+	if got := decode[[]greenhouse.ExporterStats](t, do(t, handler, "GET", "/exporters/diagnostics", "")); !reflect.DeepEqual(got, stats) {
+		t.Errorf("expected %+v, got %+v", stats, got)
+	}
+}
+
+func TestRotations(t *testing.T) {
+	handler, g := newTestHandler(t)
+
+	recorder := do(t, handler, "PUT", "/rotations/section-A", `{"crops": [{"type": "Basil", "count": 2, "initial_saturation": 0.5}]}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	expected := greenhouse.RotationStatus{RotationConfig: config.RotationConfig{
+		SectionID: "section-A",
+		Crops:     []config.CropConfig{{Type: "Basil", Count: 2, InitialSaturation: 0.5}},
+	}}
+	if got := decode[greenhouse.RotationStatus](t, recorder); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+	if code := do(t, handler, "PUT", "/rotations/section-A", `{"crops": [{"type": "Fern", "count": 2, "initial_saturation": 0.5}]}`).Code; code != http.StatusBadRequest {
+		t.Errorf("expected an unknown plant type to be rejected, got %d", code)
+	}
+	if code := do(t, handler, "PUT", "/rotations/section-A", `{"section": "section-B", "crops": [{"type": "Basil", "count": 2, "initial_saturation": 0.5}]}`).Code; code != http.StatusBadRequest {
+		t.Errorf("expected another section in the body to be rejected, got %d", code)
+	}
+
+	if code := do(t, handler, "POST", "/rotations/section-A/pause", "").Code; code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", code)
+	}
+	expected.Paused = true
+	if rotations := decode[[]greenhouse.RotationStatus](t, do(t, handler, "GET", "/rotations", "")); !reflect.DeepEqual(rotations, []greenhouse.RotationStatus{expected}) {
+		t.Errorf("expected [%+v], got %+v", expected, rotations)
+	}
+	if code := do(t, handler, "POST", "/rotations/section-A/resume", "").Code; code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", code)
+	}
+	if rotations := g.Rotations(); len(rotations) != 1 || rotations[0].Paused {
+		t.Errorf("expected the rotation to be resumed, got %+v", rotations)
+	}
+
+	if code := do(t, handler, "DELETE", "/rotations/section-A", "").Code; code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", code)
+	}
+	for _, path := range []string{"/rotations/section-A/pause", "/rotations/section-A/resume"} {
+		if code := do(t, handler, "POST", path, "").Code; code != http.StatusNotFound {
+			t.Errorf("expected %s without a rotation to be 404, got %d", path, code)
+		}
+	}
+	if code := do(t, handler, "DELETE", "/rotations/section-A", "").Code; code != http.StatusNotFound {
+		t.Errorf("expected a missing rotation to be 404, got %d", code)
+	}
+}
+
+func TestSimulatorPauseResume(t *testing.T) {
+	handler, g := newTestHandler(t)
+	sim := g.Simulator()
+	go sim.Start()
+	defer sim.Stop()
+	for sim.State() != engine.Running {
+		time.Sleep(time.Millisecond)
+	}
+
+	status := decode[Status](t, do(t, handler, "GET", "/simulator/status", ""))
+	expected := Status{
+		TickInterval: config.Duration(time.Hour),
+		Plants:       3,
+		AlivePlants:  3,
+		Sensors:      1,
+		Water:        WaterStatus{Remaining: status.Water.Remaining},
+	}
+	if status != expected || status.Water.Remaining == nil || *status.Water.Remaining != 5 {
+		t.Errorf("expected status %+v with 5 water remaining, got %+v", expected, status)
+	}
+
+	recorder := do(t, handler, "POST", "/simulator/pause", "")
+	if recorder.Code != http.StatusOK || !decode[Status](t, recorder).Paused {
+		t.Fatalf("expected a paused status, got %d", recorder.Code)
+	}
+	if code := do(t, handler, "POST", "/simulator/pause", "").Code; code != http.StatusConflict {
+		t.Errorf("expected pausing twice to conflict, got %d", code)
+	}
+	if !decode[Status](t, do(t, handler, "GET", "/simulator/status", "")).Paused {
+		t.Error("expected the status to report the pause")
+	}
+
+	recorder = do(t, handler, "POST", "/simulator/resume", "")
+	if recorder.Code != http.StatusOK || decode[Status](t, recorder).Paused {
+		t.Fatalf("expected a running status, got %d", recorder.Code)
+	}
+}
+
+func TestIdlePause(t *testing.T) {
+	cfg := config.Default()
+	cfg.TickInterval = config.Duration(time.Hour)
+	cfg.IdlePauseTicks = 2
+	g, err := greenhouse.New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	svc := service.New(g)
+	handler := NewHandler(svc)
+	sim := g.Simulator()
+	go sim.Start()
+	defer sim.Stop()
+	idle := func() {
+		t.Helper()
+		sim.Step()
+		sim.Step()
+		for deadline := time.Now().Add(time.Second); !sim.IsPaused(); time.Sleep(time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatal("expected the simulation to pause once idle")
+			}
+		}
+	}
+	for sim.State() != engine.Running {
+		time.Sleep(time.Millisecond)
+	}
+
+	idle()
+	// The request that wakes the simulation up still sees it paused.
+	if !decode[Status](t, do(t, handler, "GET", "/simulator/status", "")).Paused {
+		t.Error("expected the status to report the idle pause")
+	}
+	if sim.IsPaused() {
+		t.Error("expected the request to resume the simulation")
+	}
+
+	idle()
+	recorder := do(t, handler, "POST", "/simulator/resume", "")
+	if recorder.Code != http.StatusOK || decode[Status](t, recorder).Paused {
+		t.Errorf("expected resuming an idle pause to succeed, got %d", recorder.Code)
+	}
+
+	idle()
+	svc.SetReadOnly(true)
+	if code := do(t, handler, "POST", "/simulator/resume", "").Code; code != http.StatusForbidden {
+		t.Errorf("expected the read-only API to refuse resuming, got %d", code)
+	}
+	if !sim.IsPaused() {
+		t.Error("expected a refused request to leave the simulation paused")
+	}
+	svc.SetReadOnly(false)
+	if code := do(t, handler, "POST", "/simulator/pause", "").Code; code != http.StatusOK {
+		t.Errorf("expected pausing an idle pause to succeed, got %d", code)
+	}
+	if !sim.IsPaused() {
+		t.Error("expected the pause to outlast its request")
+	}
+}
+
+// mutatingRoutes is a request to every route that changes the greenhouse.
+var mutatingRoutes = []struct {
+	method string
+	path   string
+	body   string
+}{
+	{"POST", "/plants", `{"id": "basil-1", "type": "Basil", "section": "section-C", "initial_saturation": 0.5}`},
+	{"DELETE", "/plants/tomato-1", ""},
+	{"POST", "/plants/tomato-1/flags", `{"quarantined": true}`},
+	{"POST", "/sensors", `{"id": "sensor-2", "type": "soil_moisture", "section": "section-A"}`},
+	{"POST", "/sensors/sensor-1/battery", ""},
+	{"POST", "/sensors/sensor-1/anomalies", `{"kind": "spike", "magnitude": 0.3, "ticks": 5}`},
+	{"POST", "/sections/section-B/anomalies", `{"kind": "spike", "magnitude": 0.3, "ticks": 5}`},
+	{"POST", "/watering", `{"section": "section-A", "amount": 0.5}`},
+	{"POST", "/sections/section-A/lights", `{"on": true}`},
+	{"POST", "/sections/section-A/climate", `{"temperature": -3}`},
+	{"POST", "/hvac/heater", `{"mode": "on"}`},
+	{"PUT", "/water/heater", `{"temperature": 25}`},
+	{"DELETE", "/water/heater", ""},
+	{"POST", "/simulator/pause", ""},
+	{"POST", "/simulator/resume", ""},
+	{"POST", "/zones", `{"id": "west", "sections": ["section-A"]}`},
+}
+
+func TestReadOnly(t *testing.T) {
+	cfg := config.Default()
+	cfg.TickInterval = config.Duration(time.Hour)
+	cfg.Server = &config.ServerConfig{HTTP: ":8080", ReadOnly: true}
+	g, err := greenhouse.New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	handler := NewHandler(service.New(g))
+
+	for _, route := range mutatingRoutes {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			recorder := do(t, handler, route.method, route.path, route.body)
+			if recorder.Code != http.StatusForbidden {
+				t.Fatalf("expected status 403, got %d: %s", recorder.Code, recorder.Body.String())
+			}
+			if body := decode[Error](t, recorder); body.Error != "the greenhouse is read-only" {
+				t.Errorf("expected a read-only error, got %q", body.Error)
+			}
+		})
+	}
+	if plants := len(g.Simulator().GetAllPlants()); plants != 3 {
+		t.Errorf("expected the plants to be left alone, got %d", plants)
+	}
+	for _, path := range []string{"/plants", "/plants/tomato-1", "/sensors/sensor-1/reading", "/simulator/status", "/zones", "/admin/read-only"} {
+		if code := do(t, handler, "GET", path, "").Code; code != http.StatusOK {
+			t.Errorf("GET %s: expected status 200, got %d", path, code)
+		}
+	}
+	if !decode[ReadOnly](t, do(t, handler, "GET", "/admin/read-only", "")).ReadOnly {
+		t.Error("expected the API to report read-only mode")
+	}
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	response, err := client.Get(server.URL + "/stream")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("expected streams to work, got %d", response.StatusCode)
+	}
+
+	recorder := do(t, handler, "POST", "/admin/read-only", `{"read_only": false}`)
+	if recorder.Code != http.StatusOK || decode[ReadOnly](t, recorder).ReadOnly {
+		t.Fatalf("expected the API to be writable again, got %d", recorder.Code)
+	}
+	for _, route := range mutatingRoutes {
+		if code := do(t, handler, route.method, route.path, route.body).Code; code == http.StatusForbidden {
+			t.Errorf("%s %s: expected the request to be let through, got 403", route.method, route.path)
+		}
+	}
+
+	if code := do(t, handler, "POST", "/admin/read-only", `{"read_only": true}`).Code; code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", code)
+	}
+	if code := do(t, handler, "POST", "/plants", mutatingRoutes[0].body).Code; code != http.StatusForbidden {
+		t.Errorf("expected the API to be read-only again, got %d", code)
+	}
+}
+
+func TestServe_ShutsDownGracefully(t *testing.T) {
+	handler, _ := newTestHandler(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	stop := make(chan struct{})
+	served := make(chan error, 1)
+	go func() { served <- Serve(listener, handler, stop) }()
+
+	response, err := http.Post("http://"+listener.Addr().String()+"/watering", "application/json", bytes.NewBufferString(`{"section": "section-A", "amount": 0.2}`))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusAccepted {
+		t.Errorf("expected status 202, got %d", response.StatusCode)
+	}
+
+	close(stop)
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("expected a graceful shutdown, got %v", err)
+		}
+	case <-time.After(ShutdownTimeout):
+		t.Fatal("server did not shut down")
+	}
+	if _, err := http.Get("http://" + listener.Addr().String() + "/plants"); err == nil {
+		t.Error("expected the server to stop accepting requests")
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/auth"
+	"net/http"
+)
+
+// RequireToken wraps h so that every request must carry a token of a, as an
+// "Authorization: Bearer <token>" header, granting the scope of the request:
+// auth.ScopeAdmin for requests to /admin/, auth.ScopeRead for other GET and
+// HEAD requests and auth.ScopeControl for the rest.
+// Streams are checked once, when the client connects. Requests without a
+// known token are answered with 401, those whose token lacks the scope with
+// 403, both with an Error body that never repeats the token. An authorizer
+// without tokens lets every request through but those that administer the
+// API, such as POST /admin/read-only, which no one could be trusted with:
+// they get 403, so that a public read-only API stays read-only.
+func RequireToken(h http.Handler, a *auth.Authorizer) http.Handler {
+	if !a.Enabled() {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isAdmin(r) && !isRead(r) {
+				writeJSON(w, http.StatusForbidden, Error{Error: "administering the api requires an admin token"})
+				return
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		required := auth.ScopeControl
+		switch {
+		case isAdmin(r):
+			required = auth.ScopeAdmin
+		case isRead(r):
+			required = auth.ScopeRead
+		}
+		if _, err := a.Authorize(auth.BearerToken(r.Header.Get("Authorization")), required); err != nil {
+			status := http.StatusForbidden
+			if errors.Is(err, auth.ErrUnauthenticated) {
+				status = http.StatusUnauthorized
+				w.Header().Set("WWW-Authenticate", `Bearer realm="greenhouse"`)
+			}
+			writeJSON(w, status, Error{Error: err.Error()})
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"greenhouse-simulator/internal/auth"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/greenhouse"
+	"greenhouse-simulator/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	readSecret    = "north-read-0123456789"
+	controlSecret = "north-control-0123456789"
+	adminSecret   = "north-admin-0123456789"
+	southSecret   = "south-control-0123456789"
+)
+
+// newAuthHandler serves a test greenhouse behind the tokens.
+func newAuthHandler(t *testing.T, tokens ...auth.Token) http.Handler {
+	t.Helper()
+	handler, _ := newTestHandler(t)
+	return RequireToken(handler, auth.New(tokens))
+}
+
+func doWithToken(t *testing.T, handler http.Handler, method, path, body, token string) *httptest.ResponseRecorder {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestRequireToken(t *testing.T) {
+	north := newAuthHandler(t,
+		auth.Token{Name: "north-dashboard", Secret: readSecret, Scope: auth.ScopeRead},
+		auth.Token{Name: "north-operator", Secret: controlSecret, Scope: auth.ScopeControl},
+		auth.Token{Name: "north-admin", Secret: adminSecret, Scope: auth.ScopeAdmin})
+	south := newAuthHandler(t, auth.Token{Name: "south-operator", Secret: southSecret, Scope: auth.ScopeControl})
+	addPlant := `{"id":"basil-1","type":"Basil","section":"section-C","initial_saturation":0.5}`
+
+	tests := []struct {
+		name     string
+		handler  http.Handler
+		method   string
+		path     string
+		body     string
+		token    string
+		expected int
+	}{
+		{"missing token", north, "GET", "/plants", "", "", http.StatusUnauthorized},
+		{"unknown token", north, "GET", "/plants", "", "not-a-token-0123456789", http.StatusUnauthorized},
+		{"read token reads", north, "GET", "/simulator/status", "", readSecret, http.StatusOK},
+		{"read token adds a plant", north, "POST", "/plants", addPlant, readSecret, http.StatusForbidden},
+		{"read token waters", north, "POST", "/watering", `{"section":"section-A","amount":1}`, readSecret, http.StatusForbidden},
+		{"read token pauses", north, "POST", "/simulator/pause", "", readSecret, http.StatusForbidden},
+		{"control token reads", north, "GET", "/plants/tomato-1", "", controlSecret, http.StatusOK},
+		{"control token adds a plant", north, "POST", "/plants", addPlant, controlSecret, http.StatusCreated},
+		{"control token reads the mode", north, "GET", "/admin/read-only", "", controlSecret, http.StatusForbidden},
+		{"control token makes read-only", north, "POST", "/admin/read-only", `{"read_only":false}`, controlSecret, http.StatusForbidden},
+		{"admin token reads", north, "GET", "/plants", "", adminSecret, http.StatusOK},
+		{"admin token pauses", north, "POST", "/simulator/pause", "", adminSecret, http.StatusConflict},
+		{"admin token reads the mode", north, "GET", "/admin/read-only", "", adminSecret, http.StatusOK},
+		{"admin token makes read-only", north, "POST", "/admin/read-only", `{"read_only":false}`, adminSecret, http.StatusOK},
+		{"token of another greenhouse", north, "GET", "/plants", "", southSecret, http.StatusUnauthorized},
+		{"token of its greenhouse", south, "POST", "/plants", addPlant, southSecret, http.StatusCreated},
+		{"north token in the south", south, "GET", "/plants", "", controlSecret, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := doWithToken(t, tt.handler, tt.method, tt.path, tt.body, tt.token)
+			if recorder.Code != tt.expected {
+				t.Fatalf("expected status %d, got %d: %s", tt.expected, recorder.Code, recorder.Body.String())
+			}
+			if tt.expected != http.StatusUnauthorized && tt.expected != http.StatusForbidden {
+				return
+			}
+			if recorder.Header().Get("Content-Type") != "application/json" {
+				t.Errorf("expected a JSON error, got %q", recorder.Header().Get("Content-Type"))
+			}
+			body := decode[Error](t, recorder)
+			if body.Error == "" || (tt.token != "" && strings.Contains(body.Error, tt.token)) {
+				t.Errorf("expected an error without the token, got %q", body.Error)
+			}
+			if tt.expected == http.StatusUnauthorized && recorder.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected a WWW-Authenticate challenge")
+			}
+		})
+	}
+}
+
+func TestRequireToken_BasicAuthIsNotABearerToken(t *testing.T) {
+	handler := newAuthHandler(t, auth.Token{Name: "operator", Secret: controlSecret, Scope: auth.ScopeControl})
+	request := httptest.NewRequest("GET", "/plants", nil)
+	request.SetBasicAuth("operator", controlSecret)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401, got %d", recorder.Code)
+	}
+}
+
+func TestRequireToken_WithoutTokens(t *testing.T) {
+	handler := newAuthHandler(t)
+	if recorder := do(t, handler, "POST", "/simulator/pause", ""); recorder.Code == http.StatusUnauthorized || recorder.Code == http.StatusForbidden {
+		t.Errorf("expected requests to be let through, got %d", recorder.Code)
+	}
+}
+
+func TestRequireToken_WithoutTokensKeepsReadOnly(t *testing.T) {
+	cfg := config.Default()
+	cfg.TickInterval = config.Duration(time.Hour)
+	cfg.Server = &config.ServerConfig{HTTP: ":8080", ReadOnly: true}
+	g, err := greenhouse.New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	svc := service.New(g)
+	svc.SetReadOnly(true)
+	handler := RequireToken(NewHandler(svc), auth.New(nil))
+
+	recorder := do(t, handler, "POST", "/admin/read-only", `{"read_only": false}`)
+	if recorder.Code != http.StatusForbidden {
+		t.Fatalf("expected an anonymous client to be refused with 403, got %d", recorder.Code)
+	}
+	if body := decode[Error](t, recorder); body.Error != "administering the api requires an admin token" {
+		t.Errorf("expected an admin token error, got %q", body.Error)
+	}
+	if !svc.ReadOnly() {
+		t.Error("expected the API to stay read-only")
+	}
+	if code := do(t, handler, "POST", "/plants", mutatingRoutes[0].body).Code; code != http.StatusForbidden {
+		t.Errorf("expected the API to refuse changes, got %d", code)
+	}
+	if code := do(t, handler, "GET", "/admin/read-only", "").Code; code != http.StatusOK {
+		t.Errorf("expected the mode to be reported, got %d", code)
+	}
+}
+
+func TestRequireToken_Stream(t *testing.T) {
+	handler := newAuthHandler(t, auth.Token{Name: "dashboard", Secret: readSecret, Scope: auth.ScopeRead})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	tests := []struct {
+		name        string
+		token       string
+		expected    int
+		contentType string
+	}{
+		{"missing token", "", http.StatusUnauthorized, "application/json"},
+		{"unknown token", controlSecret, http.StatusUnauthorized, "application/json"},
+		{"read token", readSecret, http.StatusOK, "text/event-stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest("GET", server.URL+"/stream", nil)
+			if err != nil {
+				t.Fatalf("failed to build request: %v", err)
+			}
+			if tt.token != "" {
+				request.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			response, err := client.Do(request)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer response.Body.Close()
+			if response.StatusCode != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, response.StatusCode)
+			}
+			if got := response.Header.Get("Content-Type"); got != tt.contentType {
+				t.Errorf("expected content type %q, got %q", tt.contentType, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,343 @@
+package api
+
+import (
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/environment"
+	"greenhouse-simulator/internal/greenhouse"
+	"greenhouse-simulator/internal/models"
+	"greenhouse-simulator/internal/sensors"
+	"greenhouse-simulator/internal/service"
+	"greenhouse-simulator/internal/watering"
+	"strconv"
+	"time"
+)
+
+// Plant is the JSON representation of a plant.
+type Plant struct {
+	ID             string    `json:"id"`
+	Type           string    `json:"type"`
+	SectionID      string    `json:"section"`
+	SoilSaturation float64   `json:"soil_saturation"`
+	Health         float64   `json:"health"`
+	GrowthStage    float64   `json:"growth_stage"`
+	Alive          bool      `json:"alive"`
+	CreatedAt      time.Time `json:"created_at"`
+	Tags           []string  `json:"tags,omitempty"`
+	// LimitingFactor is what cost the plant the most health on the last
+	// tick, left out when nothing did, see models.Plant.LimitingFactor.
+	LimitingFactor models.HealthFactor `json:"limiting_factor,omitempty"`
+	// ExcludeFromWatering and Quarantined are the flags of the plant, see
+	// models.PlantFlags.
+	ExcludeFromWatering bool `json:"exclude_from_watering,omitempty"`
+	Quarantined         bool `json:"quarantined,omitempty"`
+	// Journal is the journal of the plant, oldest entry first, only given by
+	// GET /plants/{id} when the greenhouse keeps plant journals.
+	Journal []greenhouse.JournalEntry `json:"journal,omitempty"`
+}
+
+// PlantList is the body of GET /plants: a page of the plants matching the
+// query, the Total number of them on every page, and the token to pass as
+// the page_token query parameter for the next page, left out on the last one.
+type PlantList struct {
+	Plants        []Plant `json:"plants"`
+	Total         int     `json:"total"`
+	NextPageToken string  `json:"next_page_token,omitempty"`
+}
+
+// PlantFlagsRequest is the body of POST /plants/{id}/flags. A flag left out
+// is kept as it is; at least one must be given.
+type PlantFlagsRequest struct {
+	ExcludeFromWatering *bool `json:"exclude_from_watering,omitempty"`
+	Quarantined         *bool `json:"quarantined,omitempty"`
+}
+
+// PlantForecast is the body of GET /plants/{id}/forecast, see
+// models.PlantForecast: the ticks until the plant, left unwatered under the
+// current conditions, matures, dies and needs watering, as its roots' soil
+// saturation drops below InterventionSaturation.
+type PlantForecast struct {
+	TicksToMaturity        ForecastTicks `json:"ticks_to_maturity"`
+	TicksToDeath           ForecastTicks `json:"ticks_to_death"`
+	TicksToIntervention    ForecastTicks `json:"ticks_to_intervention"`
+	InterventionSaturation float64       `json:"intervention_saturation"`
+}
+
+// PlantDiagnosis is the body of GET /plants/{id}/diagnosis: the health the
+// plant lost to each factor on the last tick, see models.HealthDiagnosis,
+// and the factor that cost it the most, empty when none did.
+type PlantDiagnosis struct {
+	LimitingFactor models.HealthFactor    `json:"limiting_factor"`
+	Losses         models.HealthDiagnosis `json:"losses"`
+}
+
+// ForecastTicks is a tick count of a forecast, written as the string "never"
+// for models.Never.
+type ForecastTicks int
+
+func (t ForecastTicks) MarshalJSON() ([]byte, error) {
+	if t == models.Never {
+		return []byte(`"never"`), nil
+	}
+	return []byte(strconv.Itoa(int(t))), nil
+}
+
+func (t *ForecastTicks) UnmarshalJSON(data []byte) error {
+	if string(data) == `"never"` {
+		*t = models.Never
+		return nil
+	}
+	ticks, err := strconv.Atoi(string(data))
+	if err != nil {
+		return fmt.Errorf("invalid forecast ticks: %s", data)
+	}
+	*t = ForecastTicks(ticks)
+	return nil
+}
+
+// Sensor is the JSON representation of a sensor.
+type Sensor struct {
+	ID             string            `json:"id"`
+	Type           models.SensorType `json:"type"`
+	SectionID      string            `json:"section"`
+	SampleInterval int               `json:"sample_interval,omitempty"`
+	RateWindow     int               `json:"rate_window,omitempty"`
+	WindowTicks    int               `json:"window_ticks,omitempty"`
+	Battery        *Battery          `json:"battery,omitempty"`
+}
+
+// Battery is the battery of a wireless sensor: its level, the charge each
+// sample drains and how many samples it has left.
+type Battery struct {
+	Level       float64 `json:"level"`
+	Drain       float64 `json:"drain"`
+	SamplesLeft int     `json:"samples_left"`
+}
+
+// Reading is the JSON representation of a sensor reading. Battery is the
+// level the sample left the battery of a wireless sensor at. DeltaPerTick and
+// Rate are how fast the value changes per tick, see models.SensorReading;
+// the first sample of a sensor has no delta and both are zero.
+type Reading struct {
+	SensorID      string    `json:"sensor_id"`
+	Tick          int       `json:"tick"`
+	Timestamp     time.Time `json:"timestamp"`
+	Value         float64   `json:"value"`
+	Battery       *float64  `json:"battery,omitempty"`
+	HasDelta      bool      `json:"has_delta"`
+	DeltaPerTick  float64   `json:"delta_per_tick"`
+	Rate          float64   `json:"rate"`
+	Instant       *float64  `json:"instant,omitempty"`
+	PartialWindow bool      `json:"partial_window,omitempty"`
+}
+
+// Zone is the JSON representation of a zone.
+type Zone struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name,omitempty"`
+	Sections []string `json:"sections"`
+}
+
+// WaterRequest is the body of POST /watering: a manual watering of a section.
+// A request repeating the RequestID of a recent one waters nothing and
+// responds with the event of the first one, so that clients can retry it,
+// see watering.ManualOptions. Label is a note carried by the event, into the
+// watering history and the event stream.
+type WaterRequest struct {
+	SectionID string          `json:"section"`
+	Amount    float64         `json:"amount"`
+	Duration  config.Duration `json:"duration,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+	Label     string          `json:"label,omitempty"`
+}
+
+// Watering is the JSON representation of a queued manual watering event,
+// the response of POST /watering.
+type Watering struct {
+	ID         string  `json:"id"`
+	SectionID  string  `json:"section"`
+	Amount     float64 `json:"amount"`
+	Label      string  `json:"label,omitempty"`
+	QueuedTick int     `json:"queued_tick"`
+}
+
+// AnomalyRequest is the body of POST /sensors/{id}/anomalies and POST
+// /sections/{id}/anomalies: the kind of anomaly, spike, flatline, drift or
+// dropout, the magnitude of a spike or of the drift per tick, and the ticks
+// it lasts from the current one, see sensors.SensorManager.InjectAnomaly.
+type AnomalyRequest struct {
+	Kind      sensors.AnomalyKind `json:"kind"`
+	Magnitude float64             `json:"magnitude,omitempty"`
+	Ticks     int                 `json:"ticks"`
+}
+
+// LightsRequest is the body of POST /sections/{id}/lights. Switching the
+// lights on without an intensity means full intensity.
+type LightsRequest struct {
+	On        bool    `json:"on"`
+	Intensity float64 `json:"intensity,omitempty"`
+}
+
+// Lights is the JSON representation of the grow lights of a section.
+type Lights struct {
+	SectionID string  `json:"section"`
+	On        bool    `json:"on"`
+	Intensity float64 `json:"intensity"`
+}
+
+// ClimateRequest is the body of POST /sections/{id}/climate: the offsets of
+// the section's air from the greenhouse-wide conditions, see
+// environment.ClimateOffset. An empty body removes the microclimate.
+type ClimateRequest struct {
+	Temperature float64 `json:"temperature,omitempty"`
+	Humidity    float64 `json:"humidity,omitempty"`
+	Light       float64 `json:"light,omitempty"`
+}
+
+// Climate is the JSON representation of the microclimate of a section.
+type Climate struct {
+	SectionID   string  `json:"section"`
+	Temperature float64 `json:"temperature"`
+	Humidity    float64 `json:"humidity"`
+	Light       float64 `json:"light"`
+}
+
+// ActuatorRequest is the body of POST /hvac/{actuator}: the mode to switch
+// the heater or the vent to, auto, on or off.
+type ActuatorRequest struct {
+	Mode environment.ActuatorMode `json:"mode"`
+}
+
+// WaterHeaterRequest is the body of PUT /water/heater: the temperature in
+// Celsius to hold the irrigation water at, 0 to 100.
+type WaterHeaterRequest struct {
+	Temperature float64 `json:"temperature"`
+}
+
+// HVAC is the JSON representation of the heater and the vent. Offset is how
+// far they have moved the temperature away from the outside temperature.
+type HVAC struct {
+	Heater     bool                     `json:"heater"`
+	HeaterMode environment.ActuatorMode `json:"heater_mode"`
+	Vent       bool                     `json:"vent"`
+	VentMode   environment.ActuatorMode `json:"vent_mode"`
+	Offset     float64                  `json:"offset"`
+}
+
+// Status is the body of GET /simulator/status.
+type Status struct {
+	Tick         int             `json:"tick"`
+	Paused       bool            `json:"paused"`
+	TickInterval config.Duration `json:"tick_interval"`
+	Plants       int             `json:"plants"`
+	AlivePlants  int             `json:"alive_plants"`
+	Sensors      int             `json:"sensors"`
+	Water        WaterStatus     `json:"water"`
+	Timing       TickTiming      `json:"timing"`
+}
+
+// WaterStatus is the water accounting part of Status. Remaining is left out
+// when the greenhouse has no tank.
+type WaterStatus struct {
+	Used      float64  `json:"used"`
+	Wasted    float64  `json:"wasted"`
+	Remaining *float64 `json:"remaining,omitempty"`
+}
+
+// TickTiming is the tick duration part of Status, see engine.TickTiming.
+type TickTiming struct {
+	LastTick     config.Duration `json:"last_tick"`
+	P95Tick      config.Duration `json:"p95_tick"`
+	Overruns     int             `json:"overruns"`
+	DroppedTicks int             `json:"dropped_ticks"`
+}
+
+// ReadOnly is the body of GET and POST /admin/read-only: whether the API
+// refuses every request that would change the greenhouse.
+type ReadOnly struct {
+	ReadOnly bool `json:"read_only"`
+}
+
+// Error is the body of every error response.
+type Error struct {
+	Error string `json:"error"`
+}
+
+func plantDTO(p *models.Plant) Plant {
+	return Plant{
+		ID:                  p.ID,
+		Type:                p.Type.Name,
+		SectionID:           p.SectionID,
+		SoilSaturation:      p.SoilSaturation,
+		Health:              p.Health,
+		GrowthStage:         p.GrowthStage,
+		Alive:               p.Alive,
+		CreatedAt:           p.CreatedAt,
+		Tags:                p.Tags,
+		LimitingFactor:      p.LimitingFactor(),
+		ExcludeFromWatering: p.ExcludeFromWatering,
+		Quarantined:         p.Quarantined,
+	}
+}
+
+func forecastDTO(f *models.PlantForecast) PlantForecast {
+	return PlantForecast{
+		TicksToMaturity:        ForecastTicks(f.TicksToMaturity),
+		TicksToDeath:           ForecastTicks(f.TicksToDeath),
+		TicksToIntervention:    ForecastTicks(f.TicksToIntervention),
+		InterventionSaturation: f.InterventionSaturation,
+	}
+}
+
+func sensorDTO(s *models.Sensor) Sensor {
+	dto := Sensor{ID: s.ID, Type: s.Type, SectionID: s.SectionID, SampleInterval: s.SampleInterval, RateWindow: s.RateWindow, WindowTicks: s.WindowTicks}
+	if b := s.Battery; b != nil {
+		dto.Battery = &Battery{Level: b.Level, Drain: b.Drain, SamplesLeft: b.SamplesLeft()}
+	}
+	return dto
+}
+
+func readingDTO(r *models.SensorReading) Reading {
+	return Reading{
+		SensorID:      r.SensorID,
+		Tick:          r.Tick,
+		Timestamp:     r.Timestamp,
+		Value:         r.Value,
+		Battery:       r.Battery,
+		HasDelta:      r.HasDelta,
+		DeltaPerTick:  r.DeltaPerTick,
+		Rate:          r.Rate,
+		Instant:       r.Instant,
+		PartialWindow: r.PartialWindow,
+	}
+}
+
+func zoneDTO(z *models.Zone) Zone {
+	return Zone{ID: z.ID, Name: z.Name, Sections: z.SectionIDs}
+}
+
+func statusDTO(s service.Status) Status {
+	return Status{
+		Tick:         s.Tick,
+		Paused:       s.Paused,
+		TickInterval: config.Duration(s.TickInterval),
+		Plants:       s.Plants,
+		AlivePlants:  s.AlivePlants,
+		Sensors:      s.Sensors,
+		Water:        waterStatus(s.Water),
+		Timing: TickTiming{
+			LastTick:     config.Duration(s.Timing.Last),
+			P95Tick:      config.Duration(s.Timing.P95),
+			Overruns:     s.Timing.Overruns,
+			DroppedTicks: s.Timing.Dropped,
+		},
+	}
+}
+
+func waterStatus(stats watering.WaterStats) WaterStatus {
+	status := WaterStatus{Used: stats.Used, Wasted: stats.Wasted}
+	if !stats.Unlimited {
+		status.Remaining = &stats.Remaining
+	}
+	return status
+}
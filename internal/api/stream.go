@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"greenhouse-simulator/internal/service"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// StreamBuffer is how many events a stream client may fall behind before
+	// it is disconnected.
+	StreamBuffer = 256
+	// StreamWriteTimeout bounds a single write to a stream client, so a
+	// client that stops reading is disconnected instead of holding the
+	// handler forever.
+	StreamWriteTimeout = 5 * time.Second
+)
+
+// StreamEvent is the JSON data of an event sent on /stream. Payload depends
+// on the type: greenhouse.Stats for ticks, a Reading for sensor samples, the
+// watering event for watering events, the greenhouse.Alert for alerts and
+// nothing for plant lifecycle events.
+type StreamEvent struct {
+	Type      events.Type `json:"type"`
+	Tick      int         `json:"tick"`
+	Timestamp time.Time   `json:"timestamp"`
+	SectionID string      `json:"section,omitempty"`
+	PlantID   string      `json:"plant_id,omitempty"`
+	Payload   any         `json:"payload,omitempty"`
+}
+
+// streamFilter reads the type and section query parameters. type may be
+// repeated or hold a comma-separated list.
+func streamFilter(r *http.Request) service.Filter {
+	filter := service.Filter{SectionID: r.URL.Query().Get("section")}
+	for _, value := range r.URL.Query()["type"] {
+		for _, t := range strings.Split(value, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				filter.Types = append(filter.Types, events.Type(t))
+			}
+		}
+	}
+	return filter
+}
+
+// stream sends the greenhouse events as Server-Sent Events until the client
+// disconnects or the server shuts down. Each event is named after its type
+// and carries a StreamEvent as data. The subscription never blocks the
+// simulation: a client that falls StreamBuffer events behind is disconnected.
+func (s *server) stream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, Error{Error: "streaming is not supported"})
+		return
+	}
+	sub := s.svc.Watch(streamFilter(r), StreamBuffer)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	controller := http.NewResponseController(w)
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-sub.Overflowed():
+			return
+		case e := <-sub.Events():
+			// Events still queued after an overflow are not worth sending.
+			select {
+			case <-sub.Overflowed():
+				return
+			default:
+			}
+			data, err := json.Marshal(streamEvent(e))
+			if err != nil {
+				continue
+			}
+			// Recorders used in tests cannot set deadlines, which is harmless.
+			controller.SetWriteDeadline(time.Now().Add(StreamWriteTimeout))
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data); err != nil {
+				return
+			}
+			flusher.Flush()
+			sub.Delivered(e)
+		}
+	}
+}
+
+func streamEvent(e events.Event) StreamEvent {
+	payload := e.Payload
+	if reading, ok := payload.(models.SensorReading); ok {
+		payload = readingDTO(&reading)
+	}
+	return StreamEvent{
+		Type:      e.Type,
+		Tick:      e.Tick,
+		Timestamp: e.Timestamp,
+		SectionID: e.SectionID,
+		PlantID:   e.PlantID,
+		Payload:   payload,
+	}
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"greenhouse-simulator/internal/events"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// readEvents reads n Server-Sent Events from the stream.
+func readEvents(t *testing.T, scanner *bufio.Scanner, n int) []StreamEvent {
+	t.Helper()
+	var received []StreamEvent
+	name := ""
+	for len(received) < n && scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			var e StreamEvent
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e); err != nil {
+				t.Fatalf("failed to decode event data %q: %v", line, err)
+			}
+			if string(e.Type) != name {
+				t.Errorf("expected the event name %q to match its type %q", name, e.Type)
+			}
+			received = append(received, e)
+		}
+	}
+	if len(received) < n {
+		t.Fatalf("stream ended after %d of %d events: %v", len(received), n, scanner.Err())
+	}
+	return received
+}
+
+func TestStream_FiltersEventsAndCleansUp(t *testing.T) {
+	handler, g := newTestHandler(t)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	baseline := runtime.NumGoroutine()
+
+	response, err := client.Get(server.URL + "/stream?type=tick&type=sensor_sample,plant_added&section=section-B")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if response.Header.Get("Content-Type") != "text/event-stream" {
+		t.Errorf("expected an event stream, got %q", response.Header.Get("Content-Type"))
+	}
+
+	// Keep the simulation going until the client has what it needs.
+	stopStepping := make(chan struct{})
+	var stepping sync.WaitGroup
+	stepping.Add(1)
+	go func() {
+		defer stepping.Done()
+		for {
+			select {
+			case <-stopStepping:
+				return
+			case <-time.After(time.Millisecond):
+				g.Simulator().Step()
+			}
+		}
+	}()
+
+	received := readEvents(t, bufio.NewScanner(response.Body), 10)
+	close(stopStepping)
+	stepping.Wait()
+	response.Body.Close()
+
+	types := map[events.Type]int{}
+	for _, e := range received {
+		types[e.Type]++
+		if e.Type == events.SensorSample {
+			if e.SectionID != "section-B" {
+				t.Errorf("expected only section-B samples, got %+v", e)
+			}
+			if reading, ok := e.Payload.(map[string]any); !ok || reading["sensor_id"] != "sensor-1" {
+				t.Errorf("expected a reading of sensor-1, got %+v", e.Payload)
+			}
+		}
+		if e.Type == events.Tick {
+			if stats, ok := e.Payload.(map[string]any); !ok || stats["plants"] != 3.0 {
+				t.Errorf("expected the greenhouse stats, got %+v", e.Payload)
+			}
+		}
+	}
+	if types[events.Tick] == 0 || types[events.SensorSample] == 0 || len(types) != 2 {
+		t.Errorf("expected only ticks and samples, got %v", types)
+	}
+
+	client.CloseIdleConnections()
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > baseline {
+		t.Errorf("expected the stream goroutines to exit, %d goroutines left of a %d baseline", n, baseline)
+	}
+}
+
+// blockingWriter is a ResponseWriter whose body writes block until release
+// is closed, like a client that stopped reading. started is closed when the
+// first write begins.
+type blockingWriter struct {
+	header  http.Header
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+	mu      sync.Mutex
+	writes  int
+}
+
+func (w *blockingWriter) Header() http.Header { return w.header }
+func (w *blockingWriter) WriteHeader(int)     {}
+func (w *blockingWriter) Flush()              {}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.started) })
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes++
+	return len(p), nil
+}
+
+func TestStream_DisconnectsSlowClients(t *testing.T) {
+	handler, g := newTestHandler(t)
+	writer := &blockingWriter{header: http.Header{}, started: make(chan struct{}), release: make(chan struct{})}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(writer, httptest.NewRequest("GET", "/stream", nil))
+	}()
+
+	// Publish until the stream has subscribed and blocked writing an event.
+	for subscribed := false; !subscribed; {
+		g.Bus().Publish(events.Event{Type: events.LowWater})
+		select {
+		case <-writer.started:
+			subscribed = true
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	published := make(chan struct{})
+	go func() {
+		// More events than the buffer holds must not block the publisher.
+		for range 2 * StreamBuffer {
+			g.Bus().Publish(events.Event{Type: events.Tick})
+		}
+		close(published)
+	}()
+	select {
+	case <-published:
+	case <-time.After(2 * time.Second):
+		t.Fatal("a slow stream client blocked the publisher")
+	}
+	close(writer.release)
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the slow client to be disconnected")
+	}
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+	if writer.writes != 1 {
+		t.Errorf("expected the handler to stop after the blocked write, got %d writes", writer.writes)
+	}
+}
@@ -0,0 +1,113 @@
+// Package auth checks the static API tokens of a greenhouse, see
+// config.ServerConfig, for the HTTP API of package api and the gRPC API of
+// package grpcapi alike. Each token carries a scope: read tokens may query
+// the greenhouse and stream its events, control tokens may also change it and
+// admin tokens may also administer the APIs, such as making them read-only.
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Scope is what a token allows its caller to do.
+type Scope string
+
+const (
+	// ScopeRead allows queries and event streams.
+	ScopeRead Scope = "read"
+	// ScopeControl allows everything ScopeRead does, and changing the
+	// greenhouse: adding plants, watering, pausing and the like.
+	ScopeControl Scope = "control"
+	// ScopeAdmin allows everything ScopeControl does, and administering the
+	// APIs themselves.
+	ScopeAdmin Scope = "admin"
+)
+
+// Allows reports whether a token of scope s grants the required scope.
+func (s Scope) Allows(required Scope) bool {
+	switch s {
+	case ScopeAdmin:
+		return true
+	case ScopeControl:
+		return required != ScopeAdmin
+	}
+	return s == required
+}
+
+var (
+	// ErrUnauthenticated is returned for requests without a token or with
+	// one the greenhouse does not know.
+	ErrUnauthenticated = errors.New("missing or unknown API token")
+	// ErrForbidden is returned for requests whose token does not grant the
+	// scope they need.
+	ErrForbidden = errors.New("API token does not grant the scope")
+)
+
+// Token is an API token. Secret is what callers present; it is left out of
+// the String form, so that a logged token does not give it away.
+type Token struct {
+	Name   string
+	Secret string
+	Scope  Scope
+}
+
+func (t Token) String() string {
+	return fmt.Sprintf("%s (%s)", t.Name, t.Scope)
+}
+
+// Authorizer checks the tokens presented to the APIs of a greenhouse.
+type Authorizer struct {
+	tokens []Token
+}
+
+// New returns an authorizer accepting tokens. Without tokens it lets every
+// request through, as the APIs did before tokens were configured.
+func New(tokens []Token) *Authorizer {
+	return &Authorizer{tokens: append([]Token(nil), tokens...)}
+}
+
+// Enabled reports whether the authorizer checks tokens at all.
+func (a *Authorizer) Enabled() bool {
+	return a != nil && len(a.tokens) > 0
+}
+
+// Authorize checks that secret, as presented by a caller, is one of the
+// tokens and grants the required scope, and returns the token. Secrets are
+// compared in constant time. A disabled authorizer returns the zero Token.
+// Returns an error if:
+// - the secret is empty or unknown, wrapping ErrUnauthenticated
+// - its token does not allow the required scope, wrapping ErrForbidden
+func (a *Authorizer) Authorize(secret string, required Scope) (Token, error) {
+	if !a.Enabled() {
+		return Token{}, nil
+	}
+	if secret == "" {
+		return Token{}, ErrUnauthenticated
+	}
+	var found *Token
+	for i := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(a.tokens[i].Secret)) == 1 {
+			found = &a.tokens[i]
+		}
+	}
+	if found == nil {
+		return Token{}, ErrUnauthenticated
+	}
+	if !found.Scope.Allows(required) {
+		return *found, fmt.Errorf("%w: %s", ErrForbidden, required)
+	}
+	return *found, nil
+}
+
+// BearerToken returns the token of an Authorization header or metadata value
+// of the form "Bearer <token>", or "" for any other value.
+func BearerToken(value string) string {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(value), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestAuthorize(t *testing.T) {
+	a := New([]Token{
+		{Name: "dashboard", Secret: "read-secret-0123456789", Scope: ScopeRead},
+		{Name: "operator", Secret: "control-secret-0123456789", Scope: ScopeControl},
+		{Name: "maintainer", Secret: "admin-secret-0123456789", Scope: ScopeAdmin},
+	})
+
+	tests := []struct {
+		name     string
+		secret   string
+		required Scope
+		token    string
+		expected error
+	}{
+		{"read token reads", "read-secret-0123456789", ScopeRead, "dashboard", nil},
+		{"read token controls", "read-secret-0123456789", ScopeControl, "dashboard", ErrForbidden},
+		{"control token reads", "control-secret-0123456789", ScopeRead, "operator", nil},
+		{"control token controls", "control-secret-0123456789", ScopeControl, "operator", nil},
+		{"control token administers", "control-secret-0123456789", ScopeAdmin, "operator", ErrForbidden},
+		{"admin token reads", "admin-secret-0123456789", ScopeRead, "maintainer", nil},
+		{"admin token controls", "admin-secret-0123456789", ScopeControl, "maintainer", nil},
+		{"admin token administers", "admin-secret-0123456789", ScopeAdmin, "maintainer", nil},
+		{"read token administers", "read-secret-0123456789", ScopeAdmin, "dashboard", ErrForbidden},
+		{"missing token", "", ScopeRead, "", ErrUnauthenticated},
+		{"unknown token", "read-secret", ScopeRead, "", ErrUnauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := a.Authorize(tt.secret, tt.required)
+			if !errors.Is(err, tt.expected) || (tt.expected == nil && err != nil) {
+				t.Errorf("expected error %v, got %v", tt.expected, err)
+			}
+			if token.Name != tt.token {
+				t.Errorf("expected token %q, got %q", tt.token, token.Name)
+			}
+			if err != nil && strings.Contains(err.Error(), "secret") {
+				t.Errorf("expected the error to leave the secret out, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthorize_Disabled(t *testing.T) {
+	for _, a := range []*Authorizer{nil, New(nil)} {
+		if a.Enabled() {
+			t.Error("expected an authorizer without tokens to be disabled")
+		}
+		if _, err := a.Authorize("", ScopeControl); err != nil {
+			t.Errorf("expected every request to be let through, got %v", err)
+		}
+	}
+}
+
+func TestToken_String(t *testing.T) {
+	token := Token{Name: "operator", Secret: "control-secret-0123456789", Scope: ScopeControl}
+	for _, format := range []string{"%v", "%+v", "%s"} {
+		if got := fmt.Sprintf(format, token); strings.Contains(got, token.Secret) {
+			t.Errorf("expected %s to leave the secret out, got %s", format, got)
+		}
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"Bearer abc", "abc"},
+		{"bearer  abc ", "abc"},
+		{"Basic abc", ""},
+		{"abc", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := BearerToken(tt.value); got != tt.expected {
+			t.Errorf("BearerToken(%q): expected %q, got %q", tt.value, tt.expected, got)
+		}
+	}
+}
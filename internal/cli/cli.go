@@ -0,0 +1,180 @@
+// Package cli implements the greenhouse command line: running a simulation,
+// validating a config, running headless scenarios, comparing their results,
+// verifying that a scenario is deterministic, watching a simulation on a terminal dashboard, exploring one at an
+// interactive prompt, importing plants into a config and recommending
+// watering schedules. Each command takes its arguments and an output writer
+// so it can be driven from tests.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const usage = `Usage: greenhouse <command> [flags]
+
+Commands:
+  run        run the simulation in real time until interrupted or --ticks is reached
+  validate   check a config file and exit nonzero on errors
+  simulate   run a scenario headless and write the result as JSON
+  watch      run the simulation behind a live terminal dashboard
+  compare    compare the results of two simulate runs
+  verify     run a scenario several times and check that the runs match
+  repl       explore the simulation at a prompt, one tick at a time
+  import     add the plants of a CSV inventory to a config file
+  recommend  print a watering schedule recommended for a plant type
+
+Run 'greenhouse <command> -h' for the flags of a command.
+`
+
+// Main runs the command named by args[0] and returns the process exit code:
+// 0 on success, 1 when the command fails and 2 for usage errors. Closing stop
+// ends a running simulation.
+func Main(args []string, stdout, stderr io.Writer, stop <-chan struct{}) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	var err error
+	switch args[0] {
+	case "run":
+		err = Run(args[1:], stdout, stop)
+	case "validate":
+		err = Validate(args[1:], stdout)
+	case "simulate":
+		err = Simulate(args[1:], stdout)
+	case "watch":
+		err = Watch(args[1:], os.Stdin, stdout, stop)
+	case "compare":
+		err = Compare(args[1:], stdout)
+	case "verify":
+		err = Verify(args[1:], stdout)
+	case "repl":
+		err = Repl(args[1:], os.Stdin, stdout, stop)
+	case "import":
+		err = Import(args[1:], stdout)
+	case "recommend":
+		err = Recommend(args[1:], stdout)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unknown command: %s\n\n%s", args[0], usage)
+		return 2
+	}
+
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		return 2
+	default:
+		fmt.Fprintln(stderr, "error:", err)
+		return 1
+	}
+}
+
+// errUsage is returned when a command's flags cannot be parsed. The flag
+// package has already printed the problem and the usage.
+var errUsage = errors.New("invalid usage")
+
+// commonFlags are the flags shared by every command. Flags that are set
+// override the matching config values.
+type commonFlags struct {
+	configPath   string
+	tickInterval time.Duration
+	seed         int64
+	profile      string
+	sets         setFlags
+	// tickIntervalOverridden, seedOverridden and environmentOverridden are
+	// set by loadConfig when the overrides changed the tick interval, the seed
+	// or the environment of the config file.
+	tickIntervalOverridden bool
+	seedOverridden         bool
+	environmentOverridden  bool
+}
+
+// setFlags collects repeated --set flags.
+type setFlags []string
+
+func (s *setFlags) String() string { return strings.Join(*s, " ") }
+
+func (s *setFlags) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+func newFlagSet(name string, w io.Writer, common *commonFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(w)
+	fs.StringVar(&common.configPath, "config", "", "greenhouse config file (.json, .yaml or .yml); the built-in demo greenhouse when empty")
+	fs.DurationVar(&common.tickInterval, "tick-interval", 0, "override the config tick interval")
+	fs.Int64Var(&common.seed, "seed", 0, "override the config seed")
+	fs.StringVar(&common.profile, "profile", "", "replace the config environment with a built-in profile: "+strings.Join(config.EnvironmentProfiles(), ", "))
+	fs.Var(&common.sets, "set", "override a config value as key.path=value, e.g. tank.capacity=20; may be repeated")
+	return fs
+}
+
+func parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(fs.Output(), "unexpected arguments: %v\n", fs.Args())
+		return errUsage
+	}
+	return nil
+}
+
+// loadConfig loads the config file, or the demo greenhouse when no file was
+// given, applies the overrides and validates the result. Later sources take
+// precedence: the file, then the config.EnvOverrides environment variables,
+// then the --profile environment, then --set flags and finally the dedicated
+// flags set on fs. --profile comes before --set so a profile can be adjusted.
+func (c *commonFlags) loadConfig(fs *flag.FlagSet) (*config.GreenhouseConfig, error) {
+	cfg := config.Default()
+	if c.configPath != "" {
+		loaded, err := config.LoadConfig(c.configPath)
+		if err != nil {
+			return nil, err
+		}
+		cfg = loaded
+	}
+	fileTickInterval, fileSeed, fileEnvironment := cfg.TickInterval, cfg.Seed, cfg.Environment
+	if err := cfg.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	if c.profile != "" {
+		if err := cfg.ApplyProfile(c.profile); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.ApplySets(c.sets); err != nil {
+		return nil, err
+	}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "tick-interval":
+			cfg.TickInterval = config.Duration(c.tickInterval)
+		case "seed":
+			cfg.Seed = c.seed
+		}
+	})
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	c.tickIntervalOverridden = cfg.TickInterval != fileTickInterval
+	c.seedOverridden = cfg.Seed != fileSeed
+	c.environmentOverridden = cfg.Environment != fileEnvironment
+	return cfg, nil
+}
@@ -0,0 +1,664 @@
+package cli
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/greenhouse"
+	"greenhouse-simulator/internal/storage"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testConfigPath = "../config/testdata/greenhouse.yaml"
+
+func TestMain_ExitCodes(t *testing.T) {
+	invalid := filepath.Join(t.TempDir(), "invalid.yaml")
+	if err := os.WriteFile(invalid, []byte("tick_interval: 0s\n"), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected int
+		stderr   string
+	}{
+		{"no command", nil, 2, "Usage: greenhouse"},
+		{"unknown command", []string{"grow"}, 2, "unknown command: grow"},
+		{"help", []string{"help"}, 0, ""},
+		{"unknown flag", []string{"validate", "--colour"}, 2, ""},
+		{"flag help", []string{"simulate", "-h"}, 0, ""},
+		{"valid config", []string{"validate", "--config", testConfigPath}, 0, ""},
+		{"invalid config", []string{"validate", "--config", invalid}, 1, "invalid config: tick interval must be positive"},
+		{"missing config", []string{"validate", "--config", "missing.yaml"}, 1, "missing.yaml"},
+		{"invalid override", []string{"validate", "--tick-interval", "-1s"}, 1, "tick interval must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			code := Main(tt.args, &stdout, &stderr, nil)
+
+			if code != tt.expected {
+				t.Errorf("expected exit code %d, got %d (stderr: %s)", tt.expected, code, stderr.String())
+			}
+			if !strings.Contains(stderr.String(), tt.stderr) {
+				t.Errorf("expected stderr to contain '%s', got '%s'", tt.stderr, stderr.String())
+			}
+		})
+	}
+}
+
+func TestValidate_ReportsSummary(t *testing.T) {
+	var out bytes.Buffer
+	if err := Validate([]string{"--config", testConfigPath}, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := out.String(); got != "config OK: 2 plants, 1 sensors, 2 schedules\n" {
+		t.Errorf("unexpected summary: %q", got)
+	}
+}
+
+func TestValidate_Warnings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "greenhouse.yaml")
+	config := "tick_interval: 1s\nenvironment: {profile: winter}\nplant_types:\n  - {name: Bean, min_saturation: 0.2, max_saturation: 0.9, optimal_saturation: 0.6}\nplants:\n  - {id: p1, type: Basil, section: s1, initial_saturation: 0.5}\n  - {id: p2, type: Bean, section: s1, initial_saturation: 0.5}\n"
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	doomed := "warning: plant type Basil may not survive: the winter environment averages 8 C, below the 10 C minimum of the type\n" +
+		"warning: plant type Bean may not survive: the winter environment averages 8 C, below the 10 C minimum of the type\n"
+	defaulted := "warning: plant type Bean: no temperature range, defaulting to 10 to 30 C\n"
+	advice := "advice: unmonitored_section: section s1 has 2 plants but no soil moisture sensor\n"
+	summary := "config OK: 2 plants, 0 sensors, 0 schedules\n"
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"quiet", []string{"--config", path}, doomed + summary},
+		{"verbose", []string{"--config", path, "--verbose"}, defaulted + doomed + summary},
+		{"advise", []string{"--config", path, "--advise"}, doomed + advice + summary},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := Validate(tt.args, &out); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.String() != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestSimulate_WritesResultWithOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+	var out bytes.Buffer
+	if err := Simulate([]string{"--config", testConfigPath, "--ticks", "20", "--seed", "42", "--out", path}, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected nothing on the output when --out is set, got %q", out.String())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read results: %v", err)
+	}
+	var result greenhouse.ScenarioResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("failed to decode results: %v", err)
+	}
+	if result.Ticks != 20 || result.Seed != 42 {
+		t.Errorf("expected 20 ticks with seed 42, got %d ticks with seed %d", result.Ticks, result.Seed)
+	}
+	if len(result.Plants) != 2 || result.Plants[0].ID != "tomato-1" {
+		t.Errorf("expected both tomatoes in the result, got %+v", result.Plants)
+	}
+
+	// The same run written to the output is identical
+	if err := Simulate([]string{"--config", testConfigPath, "--ticks", "20", "--seed", "42"}, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != string(data) {
+		t.Errorf("expected identical results\nfile:   %s\noutput: %s", data, out.String())
+	}
+}
+
+func TestSimulate_Record(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.csv.gz")
+	args := []string{"--config", testConfigPath, "--ticks", "10", "--out", filepath.Join(t.TempDir(), "results.json"), "--record", path, "--record-columns", "tick,plant_id,health"}
+	if err := Simulate(args, io.Discard); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open the recording: %v", err)
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("expected a gzipped recording: %v", err)
+	}
+	rows, err := csv.NewReader(reader).ReadAll()
+	if err != nil {
+		t.Fatalf("expected a CSV recording: %v", err)
+	}
+	// The test config has two plants.
+	if len(rows) != 21 || strings.Join(rows[0], ",") != "tick,plant_id,health" || rows[20][0] != "9" {
+		t.Errorf("expected a header and 2 rows for each of 10 ticks, got %v", rows)
+	}
+
+	err = Simulate([]string{"--config", testConfigPath, "--record", filepath.Join(t.TempDir(), "run.csv"), "--record-columns", "tick,colour"}, io.Discard)
+	if err == nil || err.Error() != "unknown record column: colour" {
+		t.Errorf("expected an unknown column error, got %v", err)
+	}
+}
+
+func TestSimulate_World(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "world.json")
+	args := []string{"--config", testConfigPath, "--ticks", "10", "--out", filepath.Join(t.TempDir(), "results.json"), "--world", path}
+	if err := Simulate(args, io.Discard); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read the world state: %v", err)
+	}
+	var state greenhouse.WorldState
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatalf("expected a JSON world state: %v", err)
+	}
+	// The test config has two plants.
+	if state.Version != greenhouse.WorldStateVersion || state.Tick != 10 || len(state.Plants) != 2 {
+		t.Errorf("expected the version 1 world state of 2 plants after 10 ticks, got %+v", state)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.json"), filepath.Join(dir, "b.json")
+	if err := Simulate([]string{"--config", testConfigPath, "--ticks", "20", "--out", a}, io.Discard); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := Simulate([]string{"--config", testConfigPath, "--ticks", "10", "--out", b}, io.Discard); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := Compare([]string{a, b}, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{"warning: runs have different lengths, 20 and 10 ticks", "ticks", "-10", "tomato-1"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected the comparison to contain %q, got\n%s", expected, out.String())
+		}
+	}
+
+	if err := Compare([]string{a}, io.Discard); !errors.Is(err, errUsage) {
+		t.Errorf("expected a usage error with one file, got %v", err)
+	}
+	if err := Compare([]string{a, testConfigPath}, io.Discard); err == nil || !strings.Contains(err.Error(), "invalid result file") {
+		t.Errorf("expected a config to be refused as a result file, got %v", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	var out bytes.Buffer
+	if err := Verify([]string{"--config", testConfigPath, "--ticks", "50", "--runs", "3", "--seed", "7"}, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "deterministic: 3 runs of 50 ticks with seed 7 match") || strings.Count(out.String(), "run ") != 3 {
+		t.Errorf("expected the 3 runs to match, got %q", out.String())
+	}
+
+	err := Verify([]string{"--config", testConfigPath, "--runs", "1"}, io.Discard)
+	if err == nil || err.Error() != "determinism runs must be at least 2" {
+		t.Errorf("expected an error for a single run, got %v", err)
+	}
+}
+
+func TestRun_StopsAfterTicks(t *testing.T) {
+	var out bytes.Buffer
+	if err := Run([]string{"--ticks", "3", "--tick-interval", "1ms"}, &out, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "simulation stopped") {
+		t.Errorf("expected a stop message, got %q", out.String())
+	}
+}
+
+func TestRun_SpeedKeepsWatchingConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "greenhouse.yaml")
+	if err := os.WriteFile(path, []byte("tick_interval: 10ms\nplants:\n  - {id: p1, type: Tomato, section: s1, initial_saturation: 0.5}\n"), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	var out bytes.Buffer
+	if err := Run([]string{"--config", path, "--ticks", "3", "--speed", "10"}, &out, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(out.String(), "config watching disabled") {
+		t.Errorf("expected the speed to leave the config watched, got %q", out.String())
+	}
+}
+
+func TestRun_ServesHTTPUntilStopped(t *testing.T) {
+	var out bytes.Buffer
+	if err := Run([]string{"--ticks", "3", "--tick-interval", "1ms", "--http", "127.0.0.1:0"}, &out, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{"serving the HTTP API", "simulation stopped"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in the output, got %q", expected, out.String())
+		}
+	}
+}
+
+func TestRun_ServesConfiguredAPIs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "greenhouse.yaml")
+	if err := os.WriteFile(path, []byte("tick_interval: 1s\nserver:\n  http: 127.0.0.1:0\nplants:\n  - {id: p1, type: Tomato, section: s1, initial_saturation: 0.5}\n"), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	var out bytes.Buffer
+	if err := Run([]string{"--config", path, "--ticks", "3", "--tick-interval", "1ms", "--grpc", "127.0.0.1:0"}, &out, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{"serving the HTTP API", "serving the gRPC API", "simulation stopped"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in the output, got %q", expected, out.String())
+		}
+	}
+}
+
+func TestRun_ExportsToInflux(t *testing.T) {
+	var received bytes.Buffer
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		io.Copy(&received, r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "greenhouse.yaml")
+	cfg := "tick_interval: 1s\ninflux:\n  url: " + server.URL + "\n  bucket: greenhouse\n  timestamps: sim\n" +
+		"plants:\n  - {id: p1, type: Tomato, section: s1, initial_saturation: 0.5}\n" +
+		"sensors:\n  - {id: sensor-1, type: soil_moisture, section: s1}\n"
+	if err := os.WriteFile(path, []byte(cfg), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := Run([]string{"--config", path, "--ticks", "3", "--tick-interval", "1ms"}, &out, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	lines := strings.Split(strings.TrimSpace(received.String()), "\n")
+	if len(lines) < 3 || len(lines) > 4 || !strings.HasPrefix(lines[0], "sensor_reading,section=s1,sensor_id=sensor-1,type=soil_moisture value=") {
+		t.Errorf("expected a sensor-1 reading for each of the 3 ticks, got %q", received.String())
+	}
+}
+
+func TestRun_ExportsTraces(t *testing.T) {
+	var paths []string
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		paths = append(paths, r.URL.Path)
+	}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "greenhouse.yaml")
+	cfg := "tick_interval: 1s\ntracing:\n  enabled: true\n  endpoint: " + server.URL + "\n" +
+		"plants:\n  - {id: p1, type: Tomato, section: s1, initial_saturation: 0.5}\n"
+	if err := os.WriteFile(path, []byte(cfg), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := Run([]string{"--config", path, "--ticks", "3", "--tick-interval", "1ms"}, &out, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	// The spans still queued are flushed before Run returns.
+	if len(paths) == 0 || paths[0] != "/v1/traces" {
+		t.Errorf("expected the spans to be exported, got requests to %v", paths)
+	}
+}
+
+func TestRun_RecordsToStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	var out bytes.Buffer
+	if err := Run([]string{"--ticks", "3", "--tick-interval", "1ms", "--store", path}, &out, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store, err := storage.OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+	cfg := config.Default()
+	readings, err := store.Readings(cfg.Sensors[0].ID, time.Time{}, time.Now())
+	if err != nil {
+		t.Fatalf("failed to query readings: %v", err)
+	}
+	// The simulation may get one more tick in before it stops.
+	if len(readings) < 3 || len(readings) > 4 {
+		t.Errorf("expected a reading for each of the 3 ticks, got %+v", readings)
+	}
+}
+
+func TestRun_ConfiguredExporters(t *testing.T) {
+	dir := t.TempDir()
+	path, rows := filepath.Join(dir, "greenhouse.yaml"), filepath.Join(dir, "rows.csv.gz")
+	cfg := "tick_interval: 1s\nexport:\n  workers: 2\n  exporters:\n" +
+		"    - {name: rows, type: csv, path: " + rows + ", columns: [tick, plant_id]}\n" +
+		"    - {name: store, type: sqlite, path: " + filepath.Join(dir, "history.db") + "}\n" +
+		"plants:\n  - {id: p1, type: Tomato, section: s1, initial_saturation: 0.5}\n"
+	if err := os.WriteFile(path, []byte(cfg), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := Run([]string{"--config", path, "--ticks", "3", "--tick-interval", "1ms"}, &out, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	file, err := os.Open(rows)
+	if err != nil {
+		t.Fatalf("failed to open the rows: %v", err)
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("expected gzip output: %v", err)
+	}
+	records, err := csv.NewReader(reader).ReadAll()
+	if err != nil {
+		t.Fatalf("expected CSV output: %v", err)
+	}
+	// The simulation may get one more tick in before it stops.
+	if len(records) < 4 || len(records) > 5 || strings.Join(records[1], ",") != "0,p1" {
+		t.Errorf("expected a header and a p1 row for each of the 3 ticks, got %v", records)
+	}
+
+	// --store is an exporter named store too.
+	err = Run([]string{"--config", path, "--ticks", "1", "--store", filepath.Join(dir, "store.db")}, &out, nil)
+	if err == nil || err.Error() != "exporter already registered: store" {
+		t.Errorf("expected the store exporter to clash with --store, got %v", err)
+	}
+}
+
+func TestRun_Replay(t *testing.T) {
+	dir := t.TempDir()
+	recording, history := filepath.Join(dir, "run.csv.gz"), filepath.Join(dir, "history.db")
+	if err := Simulate([]string{"--ticks", "5", "--out", filepath.Join(dir, "results.json"), "--record", recording}, io.Discard); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var out bytes.Buffer
+	if err := Run([]string{"--replay", recording, "--tick-interval", "1ms", "--store", history}, &out, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "Type=replay_completed Tick=4") {
+		t.Errorf("expected the replay to complete on tick 4, got %q", out.String())
+	}
+	store, err := storage.OpenSQLite(history)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	readings, err := store.Readings(config.Default().Sensors[0].ID, time.Time{}, time.Now())
+	store.Close()
+	if err != nil {
+		t.Fatalf("failed to query readings: %v", err)
+	}
+	// Unlike a live run, a replay cannot run past its last tick.
+	if len(readings) != 5 {
+		t.Errorf("expected a reading for each of the 5 recorded ticks, got %+v", readings)
+	}
+
+	// The store of the replay can be replayed in turn.
+	out.Reset()
+	if err := Run([]string{"--replay", history, "--gaps", "interpolate", "--tick-interval", "1ms"}, &out, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "Type=replay_completed Tick=0") {
+		t.Errorf("expected the single sample to be replayed, got %q", out.String())
+	}
+	err = Run([]string{"--replay", recording, "--gaps", "fill"}, &out, nil)
+	if err == nil || err.Error() != "gap policy must be skip or interpolate: fill" {
+		t.Errorf("expected a gap policy error, got %v", err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	var out bytes.Buffer
+	if err := Watch([]string{"--speed", "4"}, strings.NewReader("q"), &out, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{"speed x4", "section-A"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in the dashboard, got %q", expected, out.String())
+		}
+	}
+
+	err := Watch([]string{"--speed", "100"}, strings.NewReader("q"), &out, nil)
+	if err == nil || err.Error() != "speed must be between 1/16 and 64" {
+		t.Errorf("expected a speed error, got %v", err)
+	}
+}
+
+func TestRepl(t *testing.T) {
+	var out bytes.Buffer
+	script := "tick 3\nplants section-A\ngrow\nquit\ntick\n"
+	if err := Repl(nil, strings.NewReader(script), &out, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{"greenhouse> tick 3\n", "tomato-2", "error: unknown command: grow"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in the output, got %q", expected, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "tick 4") {
+		t.Errorf("expected quit to end the prompt, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := Repl(nil, strings.NewReader("tick\n"), &out, nil); err != nil {
+		t.Fatalf("expected the end of the input to end the prompt, got %v", err)
+	}
+}
+
+func TestImport(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "greenhouse.yaml")
+	data, err := os.ReadFile(testConfigPath)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	plants := filepath.Join(dir, "plants.csv")
+	inventory := "id,type,section,initial_saturation,tags\ntomato-1,Tomato,section-A,0.5,\nbasil-1,Sweet Basil,section-B,0.6,\"kitchen, sunny\"\nbad-1,Cactus,section-A,0.5,\n"
+	if err := os.WriteFile(plants, []byte(inventory), 0o644); err != nil {
+		t.Fatalf("failed to write plants: %v", err)
+	}
+
+	// The config file is left alone unless it is the --out file.
+	var out, stderr bytes.Buffer
+	if code := Main([]string{"import", "--config", path, "--plants", plants}, &out, &stderr, nil); code != 2 {
+		t.Errorf("expected a usage error without --out, got exit code %d", code)
+	}
+	if saved, err := os.ReadFile(path); err != nil || !bytes.Equal(saved, data) {
+		t.Errorf("expected the config file to be left alone, got %v", err)
+	}
+
+	out.Reset()
+	if err := Import([]string{"--config", path, "--plants", plants, "--out", path}, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "skipped line 4: unknown plant type: Cactus\n" +
+		"skipped plant tomato-1: already in the config\n" +
+		"imported 1 plants, skipped 2, into " + path + "\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("failed to load the saved config: %v", err)
+	}
+	if len(cfg.Plants) != 3 || cfg.Plants[2].ID != "basil-1" || strings.Join(cfg.Plants[2].Tags, " ") != "kitchen sunny" {
+		t.Errorf("expected basil-1 added with its tags, got %+v", cfg.Plants)
+	}
+
+	// Importing the same plants again leaves nothing to import.
+	out.Reset()
+	if err := Import([]string{"--config", path, "--plants", plants, "--out", path}, &out); err == nil || err.Error() != "no plants to import" {
+		t.Errorf("expected nothing to import, got %v", err)
+	}
+
+	if code := Main([]string{"import", "--config", path}, &out, &stderr, nil); code != 2 {
+		t.Errorf("expected a usage error without --plants, got exit code %d", code)
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	var out bytes.Buffer
+	if err := Recommend([]string{"--type", "Tomato", "--tick-interval", "2s"}, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "# water_amount waters a single Tomato; multiply it by the plants the schedule waters.\n" +
+		"schedules:\n" +
+		"  - plant_type: Tomato\n" +
+		"    target_saturation: 0.6\n" +
+		"    check_interval: 4\n" +
+		"    water_amount: 0.2\n" +
+		"    duration: 2s\n" +
+		"    enabled: true\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+
+	if err := Recommend([]string{"--type", "Cactus"}, &out); err == nil || err.Error() != "unknown plant type: Cactus" {
+		t.Errorf("expected an unknown plant type to be refused, got %v", err)
+	}
+	var stderr bytes.Buffer
+	if code := Main([]string{"recommend"}, &out, &stderr, nil); code != 2 {
+		t.Errorf("expected a usage error without --type, got exit code %d", code)
+	}
+}
+
+func TestRun_RejectsInvalidSpeed(t *testing.T) {
+	var out bytes.Buffer
+	err := Run([]string{"--speed", "0"}, &out, nil)
+	if err == nil || err.Error() != "speed must be positive" {
+		t.Errorf("expected a speed error, got %v", err)
+	}
+}
+
+func TestOverridePrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "greenhouse.yaml")
+	if err := os.WriteFile(path, []byte("tick_interval: 1s\nseed: 1\nplants:\n  - {id: p1, type: Tomato, section: s1, initial_saturation: 0.5}\n"), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		env      string
+		args     []string
+		expected int64
+	}{
+		{"file", "", nil, 1},
+		{"env over file", "2", nil, 2},
+		{"set over env", "2", []string{"--set", "seed=3"}, 3},
+		{"flag over set", "2", []string{"--set", "seed=3", "--seed", "4"}, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env != "" {
+				t.Setenv("GREENHOUSE_SEED", tt.env)
+			}
+			var out bytes.Buffer
+			args := append([]string{"--config", path, "--ticks", "0"}, tt.args...)
+			if err := Simulate(args, &out); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var result greenhouse.ScenarioResult
+			if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+				t.Fatalf("failed to decode result: %v", err)
+			}
+			if result.Seed != tt.expected {
+				t.Errorf("expected seed %d, got %d", tt.expected, result.Seed)
+			}
+		})
+	}
+}
+
+func TestOverrideErrorsNameTheKey(t *testing.T) {
+	var out bytes.Buffer
+	err := Validate([]string{"--set", "environment.ticks_per_day=many"}, &out)
+	expected := `invalid config: invalid value "many" for environment.ticks_per_day: expected an integer`
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error '%s', got %v", expected, err)
+	}
+}
+
+func TestProfileFlag(t *testing.T) {
+	winter, _ := config.EnvironmentProfile("winter")
+	tweaked := winter
+	tweaked.RainChance = 0.1
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected config.EnvironmentConfig
+		errorMsg string
+	}{
+		{"profile replaces the file environment", []string{"--profile", "winter"}, winter, ""},
+		{"set adjusts the profile", []string{"--profile", "winter", "--set", "environment.rain_chance=0.1"}, tweaked, ""},
+		{"unknown profile", []string{"--profile", "monsoon"}, config.EnvironmentConfig{}, "unknown environment profile: monsoon (known profiles: summer, winter)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var common commonFlags
+			fs := newFlagSet("test", io.Discard, &common)
+			if err := parse(fs, append([]string{"--config", testConfigPath}, tt.args...)); err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+			cfg, err := common.loadConfig(fs)
+			if tt.errorMsg != "" {
+				if err == nil || err.Error() != tt.errorMsg {
+					t.Errorf("expected error '%s', got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cfg.Environment != tt.expected {
+				t.Errorf("expected environment %+v, got %+v", tt.expected, cfg.Environment)
+			}
+			if !common.environmentOverridden {
+				t.Error("expected the environment to be reported as overridden")
+			}
+		})
+	}
+}
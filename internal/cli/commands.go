@@ -0,0 +1,411 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"greenhouse-simulator/internal/api"
+	"greenhouse-simulator/internal/auth"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/greenhouse"
+	"greenhouse-simulator/internal/grpcapi"
+	"greenhouse-simulator/internal/sensors"
+	"greenhouse-simulator/internal/service"
+	"greenhouse-simulator/internal/tracing"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Run runs the simulation in real time, logging every event to w, until stop
+// is closed or --ticks ticks have run. --speed runs the ticks that many times
+// faster than the tick interval, which keeps the simulated time a tick
+// covers, see engine.Simulator.SetSpeed.
+// When a config file is given and neither the tick interval, the seed nor
+// the environment is overridden, the file is watched and reloaded while the
+// simulation runs. --http and --grpc serve the HTTP API of package api and
+// the gRPC API of package grpcapi until the simulation stops, overriding the
+// config's server section, and --read-only starts both APIs read-only, see
+// service.Service.SetReadOnly. A config with an mqtt section bridges the
+// simulation to that broker, see package mqtt.
+// --store records the run into a SQLite database, see package storage, and a
+// config with an influx section exports the sensor readings to InfluxDB, see
+// package influx. These sinks and those of the config's export section are
+// attached as exporters, see greenhouse.ExportRegistry. A config with tracing
+// enabled traces the ticks, the store writes and the API requests, see
+// package tracing. --replay replays a recording instead of simulating, until
+// its end.
+func Run(args []string, w io.Writer, stop <-chan struct{}) error {
+	var common commonFlags
+	fs := newFlagSet("run", w, &common)
+	ticks := fs.Int("ticks", 0, "stop after this many ticks; 0 runs until interrupted")
+	speed := fs.Float64("speed", 1, "run the ticks this many times faster than the tick interval")
+	httpAddr := fs.String("http", "", "serve the HTTP API on this address, e.g. :8080")
+	grpcAddr := fs.String("grpc", "", "serve the gRPC API on this address, e.g. :9090")
+	storePath := fs.String("store", "", "record readings, events and plant history into this SQLite database")
+	readOnly := fs.Bool("read-only", false, "refuse every API call that would change the greenhouse")
+	var replay replayFlags
+	replay.register(fs)
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if *ticks < 0 {
+		return errors.New("ticks cannot be negative")
+	}
+	if *speed <= 0 {
+		return errors.New("speed must be positive")
+	}
+	cfg, err := common.loadConfig(fs)
+	if err != nil {
+		return err
+	}
+
+	g, err := replay.newGreenhouse(cfg)
+	if err != nil {
+		return err
+	}
+	if err := g.Simulator().SetSpeed(*speed); err != nil {
+		return err
+	}
+	level, _ := cfg.SlogLevel()
+	logger := slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}))
+	// The simulator logs the state of every plant on every tick only at the
+	// debug level of the default logger.
+	defer slog.SetLogLoggerLevel(slog.SetLogLoggerLevel(level))
+	var tracerProvider trace.TracerProvider
+	if cfg.Tracing != nil && cfg.Tracing.Enabled {
+		provider, err := tracing.NewProvider(*cfg.Tracing)
+		if err != nil {
+			return err
+		}
+		// Deferred first so that it runs last, once the store writes are done.
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := provider.Shutdown(ctx); err != nil {
+				logger.Warn("flushing traces failed", "error", err)
+			}
+		}()
+		tracerProvider = provider
+		tracing.Instrument(g, provider)
+	}
+	g.Bus().Subscribe(func(e events.Event) {
+		// Per-tick events would drown out the rest at the info level.
+		eventLevel := slog.LevelInfo
+		if e.Type == events.Tick || e.Type == events.SensorSample {
+			eventLevel = slog.LevelDebug
+		}
+		logger.Log(context.Background(), eventLevel, "event", "Type", e.Type, "Tick", e.Tick, "SectionID", e.SectionID, "PlantID", e.PlantID)
+	})
+	done := make(chan struct{})
+	finish := sync.OnceFunc(func() { close(done) })
+	if *ticks > 0 {
+		g.Simulator().AddTickListener(&tickLimit{ticks: *ticks, done: finish})
+	}
+	g.Bus().Subscribe(func(e events.Event) {
+		if e.Type == events.ReplayCompleted {
+			finish()
+		}
+	})
+
+	stopWatching := make(chan struct{})
+	defer close(stopWatching)
+	switch {
+	case common.configPath == "":
+	case replay.path != "":
+		logger.Warn("config watching disabled while replaying")
+	case common.tickIntervalOverridden:
+		logger.Warn("config watching disabled because the tick interval is overridden")
+	case common.seedOverridden:
+		logger.Warn("config watching disabled because the seed is overridden")
+	case common.environmentOverridden:
+		logger.Warn("config watching disabled because the environment is overridden")
+	default:
+		go g.WatchConfig(common.configPath, time.Second, stopWatching, func(err error) {
+			logger.Warn("config reload failed", "error", err)
+		})
+	}
+
+	sim := g.Simulator()
+	var tracer trace.Tracer
+	if tracerProvider != nil {
+		tracer = tracerProvider.Tracer(tracing.TracerName)
+	}
+	exporters, err := attachExporters(g, exporterConfigs(cfg, *storePath), tracer, logger)
+	if err != nil {
+		return err
+	}
+	defer exporters.close(logger)
+	// The exporters stop after the simulator, so that they keep the last tick.
+	stopWriting := make(chan struct{})
+	written := runLoops(exporters.writers, stopWriting)
+	defer func() {
+		closeExporters(g, logger)
+		close(stopWriting)
+		<-written
+	}()
+
+	// Flags override the addresses of the config's server section, its
+	// tokens apply to whichever address the APIs are served on.
+	httpListen, grpcListen := *httpAddr, *grpcAddr
+	var authorizer *auth.Authorizer
+	if cfg.Server != nil {
+		httpListen = cmp.Or(httpListen, cfg.Server.HTTP)
+		grpcListen = cmp.Or(grpcListen, cfg.Server.GRPC)
+		authorizer = cfg.Server.Authorizer()
+	}
+	var httpListener, grpcListener net.Listener
+	if httpListen != "" {
+		if httpListener, err = net.Listen("tcp", httpListen); err != nil {
+			return err
+		}
+	}
+	if grpcListen != "" {
+		if grpcListener, err = net.Listen("tcp", grpcListen); err != nil {
+			if httpListener != nil {
+				httpListener.Close()
+			}
+			return err
+		}
+	}
+
+	svc := service.New(g)
+	if *readOnly {
+		svc.SetReadOnly(true)
+	}
+	stopServing := make(chan struct{})
+	served := make(chan error, 2) // never ready while nothing is served
+	servers := 0
+	if httpListener != nil {
+		logger.Info("serving the HTTP API", "address", httpListener.Addr().String(), "tokens", authorizer.Enabled())
+		servers++
+		handler := api.RequireToken(api.NewHandler(svc), authorizer)
+		if tracerProvider != nil {
+			handler = tracing.HTTPHandler(handler, tracerProvider)
+		}
+		go func() { served <- api.Serve(httpListener, handler, stopServing) }()
+	}
+	if grpcListener != nil {
+		logger.Info("serving the gRPC API", "address", grpcListener.Addr().String(), "tokens", authorizer.Enabled())
+		servers++
+		opts := grpcapi.RequireToken(authorizer)
+		if tracerProvider != nil {
+			opts = append(opts, tracing.GRPCServerOption(tracerProvider))
+		}
+		go func() { served <- grpcapi.Serve(grpcListener, svc, stopServing, opts...) }()
+	}
+
+	bridged := runLoops(exporters.bridges, stopServing)
+
+	go sim.Start()
+	var serveErr error
+	select {
+	case <-stop:
+	case <-done:
+	case serveErr = <-served:
+		servers--
+	}
+	close(stopServing)
+	for ; servers > 0; servers-- {
+		if err := <-served; serveErr == nil {
+			serveErr = err
+		}
+	}
+	<-bridged
+	sim.Stop()
+	logger.Info("simulation stopped", "ticks", sim.GetCurrentTick())
+	return serveErr
+}
+
+// tickLimit calls done once the given number of ticks has run, and on every
+// tick after that.
+type tickLimit struct {
+	ticks int
+	done  func()
+}
+
+func (l *tickLimit) OnTick(tick int) {
+	if tick+1 >= l.ticks {
+		l.done()
+	}
+}
+
+// Validate checks the config, including its schedules, and reports a short
+// summary to w. It returns the first validation error. A valid config is
+// still warned about when its plant types may not survive its environment,
+// see config.GreenhouseConfig.EnvironmentWarnings, and with --verbose about
+// the defaults filled in for its plant types, see
+// config.GreenhouseConfig.PlantTypeWarnings. --advise also suggests where
+// sensors are missing, each advice prefixed by its code, see
+// sensors.AdviseSensorPlacement.
+func Validate(args []string, w io.Writer) error {
+	var common commonFlags
+	fs := newFlagSet("validate", w, &common)
+	verbose := fs.Bool("verbose", false, "also report the defaults filled in for plant types")
+	advise := fs.Bool("advise", false, "also suggest where sensors are missing")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	cfg, err := common.loadConfig(fs)
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	g, err := greenhouse.New(cfg)
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	var warnings []string
+	if *verbose {
+		warnings = cfg.PlantTypeWarnings()
+	}
+	for _, warning := range append(warnings, cfg.EnvironmentWarnings()...) {
+		fmt.Fprintln(w, "warning:", warning)
+	}
+	if *advise {
+		advice := sensors.AdviseSensorPlacement(g.Simulator().GetAllPlants(), g.Sensors().ListSensors(), cfg.SensorAdvicePolicy())
+		for _, a := range advice {
+			fmt.Fprintf(w, "advice: %s: %s\n", a.Code, a.Message)
+		}
+	}
+	fmt.Fprintf(w, "config OK: %d plants, %d sensors, %d schedules\n", len(cfg.Plants), len(cfg.Sensors), len(cfg.WateringSchedules()))
+	return nil
+}
+
+// Simulate runs the scenario headless for --ticks ticks and writes the
+// greenhouse.ScenarioResult as JSON to --out, or to w when --out is empty.
+// --record writes a CSV row per plant per tick to a file, gzipped when its
+// name ends in .gz, with the columns listed by --record-columns, and --world
+// writes the greenhouse.WorldState at the end of the run to a file as JSON.
+func Simulate(args []string, w io.Writer) error {
+	var common commonFlags
+	fs := newFlagSet("simulate", w, &common)
+	ticks := fs.Int("ticks", 1000, "number of ticks to simulate")
+	out := fs.String("out", "", "result file; the result is written to standard output when empty")
+	record := fs.String("record", "", "record every plant on every tick into this CSV file, gzipped if it ends in .gz")
+	recordColumns := fs.String("record-columns", "", "comma-separated columns to record; all when empty")
+	world := fs.String("world", "", "write the world state at the end of the run into this JSON file")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	cfg, err := common.loadConfig(fs)
+	if err != nil {
+		return err
+	}
+	var opts greenhouse.ScenarioOptions
+	var recordFile *os.File
+	if *record != "" {
+		if recordFile, err = os.Create(*record); err != nil {
+			return err
+		}
+		defer recordFile.Close()
+		opts.Record = recordFile
+		opts.RecordOptions.Gzip = strings.HasSuffix(*record, ".gz")
+		if *recordColumns != "" {
+			opts.RecordOptions.Columns = strings.Split(*recordColumns, ",")
+		}
+	}
+	var worldFile *os.File
+	if *world != "" {
+		if worldFile, err = os.Create(*world); err != nil {
+			return err
+		}
+		defer worldFile.Close()
+		opts.World = worldFile
+	}
+	result, err := greenhouse.RunScenario(cfg, *ticks, opts)
+	if err != nil {
+		return err
+	}
+	for _, file := range []*os.File{recordFile, worldFile} {
+		if file == nil {
+			continue
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if *out == "" {
+		_, err = w.Write(data)
+		return err
+	}
+	return os.WriteFile(*out, data, 0o644)
+}
+
+// Compare compares the results of two simulate runs, A and B, and writes the
+// differences to w as text, see greenhouse.CompareRuns.
+func Compare(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	fs.SetOutput(w)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: greenhouse compare a.json b.json")
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	if fs.NArg() != 2 {
+		fmt.Fprintf(fs.Output(), "expected two result files, got %d\n", fs.NArg())
+		fs.Usage()
+		return errUsage
+	}
+	var results [2]*greenhouse.ScenarioResult
+	for i, path := range fs.Args() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &results[i]); err != nil {
+			return fmt.Errorf("invalid result file %s: %w", path, err)
+		}
+	}
+	comparison, err := greenhouse.CompareRuns(results[0], results[1])
+	if err != nil {
+		return err
+	}
+	return comparison.WriteText(w)
+}
+
+// Verify runs the scenario --runs times for --ticks ticks and writes to w
+// whether the runs match, see greenhouse.VerifyDeterminism. Returns an error
+// after the report when they do not.
+func Verify(args []string, w io.Writer) error {
+	var common commonFlags
+	fs := newFlagSet("verify", w, &common)
+	ticks := fs.Int("ticks", 1000, "number of ticks of each run")
+	runs := fs.Int("runs", 3, "number of runs to compare, at least 2")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	cfg, err := common.loadConfig(fs)
+	if err != nil {
+		return err
+	}
+	report, err := greenhouse.VerifyDeterminism(cfg, cfg.Seed, *ticks, *runs)
+	if err != nil {
+		return err
+	}
+	if err := report.WriteText(w); err != nil {
+		return err
+	}
+	if !report.Deterministic {
+		return fmt.Errorf("runs diverged on tick %d", report.DivergedAt)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/greenhouse"
+	"greenhouse-simulator/internal/influx"
+	"greenhouse-simulator/internal/mqtt"
+	"greenhouse-simulator/internal/storage"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// exportTimeout bounds how long a stopping run waits for the exporters to
+// catch up.
+const exportTimeout = 5 * time.Second
+
+// exporterConfigs returns the exporters of cfg's export section, then those
+// standing for its influx and mqtt sections and for --store.
+func exporterConfigs(cfg *config.GreenhouseConfig, storePath string) []config.ExporterConfig {
+	var exporters []config.ExporterConfig
+	if cfg.Export != nil {
+		exporters = append(exporters, cfg.Export.Exporters...)
+	}
+	if cfg.Influx != nil {
+		exporters = append(exporters, config.ExporterConfig{Name: "influx", Type: "influx", Influx: cfg.Influx})
+	}
+	if cfg.MQTT != nil {
+		exporters = append(exporters, config.ExporterConfig{Name: "mqtt", Type: "mqtt", MQTT: cfg.MQTT})
+	}
+	if storePath != "" {
+		exporters = append(exporters, config.ExporterConfig{Name: "store", Type: "sqlite", Path: storePath})
+	}
+	return exporters
+}
+
+// exporters holds what the registered exporters need besides the registry:
+// the loops writing to InfluxDB, the loops bridging to MQTT brokers, which
+// stop with the APIs since they also take commands, and the files written.
+type exporters struct {
+	writers []func(stop <-chan struct{})
+	bridges []func(stop <-chan struct{})
+	files   []io.Closer
+}
+
+// attachExporters builds the exporters and registers them with g, tracing
+// the store writes with tracer when set. Returns an error if an exporter
+// cannot be built or registered; those already registered are then closed.
+func attachExporters(g greenhouse.Greenhouse, configs []config.ExporterConfig, tracer trace.Tracer, logger *slog.Logger) (*exporters, error) {
+	x := &exporters{}
+	for _, cfg := range configs {
+		exporter, err := x.build(g, cfg, tracer, logger)
+		if err == nil {
+			err = g.Exporters().Register(cfg.Name, exporter, cfg.QueueSize)
+		}
+		if err != nil {
+			g.Exporters().Close(0)
+			x.close(logger)
+			return nil, err
+		}
+	}
+	return x, nil
+}
+
+func (x *exporters) build(g greenhouse.Greenhouse, cfg config.ExporterConfig, tracer trace.Tracer, logger *slog.Logger) (greenhouse.Exporter, error) {
+	switch cfg.Type {
+	case "csv":
+		file, err := os.Create(cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		x.files = append(x.files, file)
+		return greenhouse.NewRunRecorder(g, file, greenhouse.RecordOptions{Columns: cfg.Columns, Gzip: strings.HasSuffix(cfg.Path, ".gz")})
+	case "sqlite":
+		store, err := storage.OpenSQLite(cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		x.files = append(x.files, store)
+		return storage.NewRecorder(store, storage.Config{PlantSampleInterval: cfg.SampleInterval, Tracer: tracer}, logger), nil
+	case "eventlog":
+		log, err := storage.OpenEventLog(g, cfg.Path, storage.EventLogOptions{Sync: storage.SyncPolicy(cfg.Sync)})
+		if err != nil {
+			return nil, err
+		}
+		x.files = append(x.files, log)
+		return log, nil
+	case "influx":
+		exporter := influx.NewExporter(g, influx.NewHTTPWriter(*cfg.Influx), *cfg.Influx, logger)
+		x.writers = append(x.writers, exporter.Run)
+		return exporter, nil
+	case "mqtt":
+		bridge := mqtt.NewBridge(g, mqtt.NewClient(*cfg.MQTT), *cfg.MQTT, logger)
+		x.bridges = append(x.bridges, bridge.Run)
+		return bridge, nil
+	}
+	return nil, errors.New("unknown exporter type: " + cfg.Type)
+}
+
+// runLoops runs loops until stop is closed, and returns a channel closed once
+// they have all returned.
+func runLoops(loops []func(stop <-chan struct{}), stop <-chan struct{}) <-chan struct{} {
+	var wg sync.WaitGroup
+	for _, loop := range loops {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			loop(stop)
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	return done
+}
+
+// close closes the files written, logging failures.
+func (x *exporters) close(logger *slog.Logger) {
+	for _, file := range x.files {
+		if err := file.Close(); err != nil {
+			logger.Warn("closing exporter output failed", "error", err)
+		}
+	}
+}
+
+// closeExporters flushes the exporters of g and closes them, see
+// greenhouse.ExportRegistry.Close, logging those that dropped items, failed
+// or did not catch up in time.
+func closeExporters(g greenhouse.Greenhouse, logger *slog.Logger) {
+	stats := g.Exporters().Stats()
+	if err := g.Exporters().Close(exportTimeout); err != nil {
+		logger.Warn("closing exporters failed", "error", err)
+	}
+	for _, stat := range stats {
+		if stat.Dropped > 0 || stat.Failed > 0 {
+			logger.Warn("exporter fell behind or failed", "exporter", stat.Name, "dropped", stat.Dropped, "high_water", stat.HighWater, "failed", stat.Failed, "last_error", stat.LastError)
+		}
+	}
+}
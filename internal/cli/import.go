@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"io"
+	"os"
+	"slices"
+)
+
+// Import adds the plants of a CSV inventory, see config.ImportPlantsCSV, to
+// the config file given by --config and saves the result to --out. Saving
+// drops the comments of the file and writes it in full, so --out is
+// required rather than defaulting to the config file; given the config file,
+// a simulation running on it reloads it and adds the plants, see
+// greenhouse.Greenhouse.WatchConfig. Rows that cannot be imported, including
+// plants already in the config, are reported to w and skipped; it fails
+// only when no plant could be imported.
+func Import(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(w)
+	configPath := fs.String("config", "", "greenhouse config file (.json, .yaml or .yml) to add the plants to")
+	plantsPath := fs.String("plants", "", "CSV file with the columns id, type, section, initial_saturation and optionally tags")
+	out := fs.String("out", "", "file to save the config with the plants to; may be the config file, which loses its comments")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if *configPath == "" || *plantsPath == "" || *out == "" {
+		fmt.Fprintln(fs.Output(), "--config, --plants and --out are required")
+		return errUsage
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	catalog, err := cfg.PlantTypeCatalog()
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(*plantsPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	plants, importErrors := config.ImportPlantsCSV(file, catalog)
+
+	for _, err := range importErrors {
+		fmt.Fprintf(w, "skipped %s\n", err)
+	}
+	skipped := len(importErrors)
+	imported := 0
+	for _, plant := range plants {
+		if slices.ContainsFunc(cfg.Plants, func(p config.PlantConfig) bool { return p.ID == plant.ID }) {
+			fmt.Fprintf(w, "skipped plant %s: already in the config\n", plant.ID)
+			skipped++
+			continue
+		}
+		cfg.Plants = append(cfg.Plants, config.ImportedPlantConfig(plant))
+		imported++
+	}
+	if imported == 0 {
+		return errors.New("no plants to import")
+	}
+	if err := config.SaveConfig(cfg, *out); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "imported %d plants, skipped %d, into %s\n", imported, skipped, *out)
+	return nil
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/models"
+	"io"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Recommend writes the watering schedule recommended for the plant type
+// named by --type to w, as a schedules entry to paste into a config file, see
+// models.RecommendSchedule. The type is looked up among the presets and the
+// plant types of the config, whose tick interval sizes the events. The
+// recommended water amount is for a single plant.
+func Recommend(args []string, w io.Writer) error {
+	var common commonFlags
+	fs := newFlagSet("recommend", w, &common)
+	typeName := fs.String("type", "", "plant type to recommend a schedule for, e.g. Tomato")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if *typeName == "" {
+		fmt.Fprintln(fs.Output(), "--type is required")
+		return errUsage
+	}
+	cfg, err := common.loadConfig(fs)
+	if err != nil {
+		return err
+	}
+	catalog, err := cfg.PlantTypeCatalog()
+	if err != nil {
+		return err
+	}
+	plantType, ok := catalog[*typeName]
+	if !ok {
+		return errors.New("unknown plant type: " + *typeName)
+	}
+	schedule, err := models.RecommendSchedule(plantType, time.Duration(cfg.TickInterval))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "# water_amount waters a single %s; multiply it by the plants the schedule waters.\n", plantType.Name)
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	entry := struct {
+		Schedules []config.ScheduleConfig `yaml:"schedules"`
+	}{[]config.ScheduleConfig{{
+		PlantType:        schedule.PlantType,
+		TargetSaturation: schedule.TargetSaturation,
+		CheckInterval:    schedule.CheckInterval,
+		WaterAmount:      schedule.WaterAmount,
+		Duration:         config.Duration(schedule.Duration),
+		Enabled:          schedule.Enabled,
+	}}}
+	if err := encoder.Encode(entry); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/greenhouse"
+	"greenhouse-simulator/internal/repl"
+	"io"
+	"log"
+	"os"
+
+	"golang.org/x/term"
+)
+
+const replPrompt = "greenhouse> "
+
+// Repl runs the interactive prompt of package repl on the greenhouse of the
+// config, in manual-tick mode, until quit is entered, in ends or stop is
+// closed. When in is a terminal it is put in raw mode for line editing, and
+// the up and down keys go through the commands entered before; otherwise the
+// lines of in are run one after the other. The simulator's own log lines are
+// discarded so they do not garble the prompt.
+func Repl(args []string, in io.Reader, w io.Writer, stop <-chan struct{}) error {
+	var common commonFlags
+	fs := newFlagSet("repl", w, &common)
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	cfg, err := common.loadConfig(fs)
+	if err != nil {
+		return err
+	}
+	g, err := greenhouse.New(cfg)
+	if err != nil {
+		return err
+	}
+	session := repl.NewSession(g)
+	defer session.Close()
+
+	var lines lineReader
+	if file, ok := in.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+		state, err := term.MakeRaw(int(file.Fd()))
+		if err != nil {
+			return err
+		}
+		defer term.Restore(int(file.Fd()), state)
+		terminal := term.NewTerminal(struct {
+			io.Reader
+			io.Writer
+		}{in, w}, replPrompt)
+		lines, w = terminal, terminal
+	} else {
+		lines = &promptReader{scanner: bufio.NewScanner(in), w: w}
+	}
+	logOutput := log.Writer()
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(logOutput)
+
+	type input struct {
+		line string
+		err  error
+	}
+	inputs := make(chan input)
+	next := make(chan struct{}, 1)
+	go func() {
+		for range next {
+			line, err := lines.ReadLine()
+			inputs <- input{line, err}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	defer close(next)
+	for {
+		next <- struct{}{}
+		var in input
+		select {
+		case in = <-inputs:
+		case <-stop:
+			return nil
+		}
+		if in.err == io.EOF {
+			return nil
+		} else if in.err != nil {
+			return in.err
+		}
+		out, err := session.Execute(in.line)
+		if errors.Is(err, repl.ErrQuit) {
+			return nil
+		}
+		if err != nil {
+			fmt.Fprintln(w, "error:", err)
+			continue
+		}
+		io.WriteString(w, out)
+	}
+}
+
+// lineReader reads the command lines of the prompt.
+type lineReader interface {
+	ReadLine() (string, error)
+}
+
+// promptReader reads lines from input that is not a terminal, writing the
+// prompt before each one.
+type promptReader struct {
+	scanner *bufio.Scanner
+	w       io.Writer
+}
+
+func (r *promptReader) ReadLine() (string, error) {
+	io.WriteString(r.w, replPrompt)
+	if r.scanner.Scan() {
+		return r.scanner.Text(), nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", io.EOF
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/greenhouse"
+	"greenhouse-simulator/internal/storage"
+	"os"
+)
+
+// sqliteHeader starts every SQLite database file.
+const sqliteHeader = "SQLite format 3\x00"
+
+// replayFlags are the flags of the commands that can replay a recording
+// instead of simulating.
+type replayFlags struct {
+	path string
+	gaps string
+}
+
+func (r *replayFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&r.path, "replay", "", "replay a recording instead of simulating: a CSV written by simulate --record, gzipped or not, a database written by --store or an event log")
+	fs.StringVar(&r.gaps, "gaps", string(greenhouse.GapSkip), "how a replay crosses ticks missing from the recording: skip or interpolate")
+}
+
+// newGreenhouse builds the greenhouse of cfg, or with --replay the replay of
+// the recording through the sensors of cfg, see greenhouse.NewReplay.
+func (r *replayFlags) newGreenhouse(cfg *config.GreenhouseConfig) (greenhouse.Greenhouse, error) {
+	if r.path == "" {
+		return greenhouse.New(cfg)
+	}
+	recording, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	return greenhouse.NewReplay(cfg, recording, greenhouse.ReplayOptions{Gaps: greenhouse.GapPolicy(r.gaps)})
+}
+
+// load reads the recording, telling a SQLite database, an event log and a
+// CSV file apart by their header: an event log starts with a JSON object.
+func (r *replayFlags) load() (*greenhouse.Recording, error) {
+	file, err := os.Open(r.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	buffered := bufio.NewReader(file)
+	if first, _ := buffered.Peek(1); bytes.Equal(first, []byte("{")) {
+		return storage.ReadEventLogRecording(buffered)
+	}
+	if header, _ := buffered.Peek(len(sqliteHeader)); !bytes.Equal(header, []byte(sqliteHeader)) {
+		return greenhouse.ReadRecording(buffered)
+	}
+	store, err := storage.OpenSQLite(r.path)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	return storage.LoadRecording(store)
+}
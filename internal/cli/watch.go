@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/dashboard"
+	"io"
+	"log"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// Watch runs the simulation in real time behind the terminal dashboard of
+// package dashboard, reading keys from in, until q or Ctrl-C is pressed or
+// stop is closed. --speed sets the starting speed, which the + and - keys
+// change. When in is a terminal it is put in raw mode for the duration, and
+// the simulator's own log lines are discarded so they do not garble the
+// screen. --replay replays a recording instead of simulating; the replay stays
+// on its last tick once it has ended.
+func Watch(args []string, in io.Reader, w io.Writer, stop <-chan struct{}) error {
+	var common commonFlags
+	fs := newFlagSet("watch", w, &common)
+	speed := fs.Float64("speed", 1, "starting speed-up factor, changed with + and -")
+	waterAmount := fs.Float64("water-amount", dashboard.DefaultWaterAmount, "water given to the selected section by the w key")
+	waterDuration := fs.Duration("water-duration", dashboard.DefaultWaterDuration, "duration of the watering started by the w key")
+	var replay replayFlags
+	replay.register(fs)
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if *speed < dashboard.MinSpeed || *speed > dashboard.MaxSpeed {
+		return errors.New("speed must be between 1/16 and 64")
+	}
+	cfg, err := common.loadConfig(fs)
+	if err != nil {
+		return err
+	}
+	g, err := replay.newGreenhouse(cfg)
+	if err != nil {
+		return err
+	}
+	sim := g.Simulator()
+	sim.SetSpeed(*speed)
+
+	if file, ok := in.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+		state, err := term.MakeRaw(int(file.Fd()))
+		if err != nil {
+			return err
+		}
+		defer term.Restore(int(file.Fd()), state)
+	}
+	logOutput := log.Writer()
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(logOutput)
+
+	go sim.Start()
+	err = dashboard.Run(g, in, w, dashboard.Options{
+		Size:          terminalSize(w),
+		WaterAmount:   *waterAmount,
+		WaterDuration: *waterDuration,
+	}, stop)
+	sim.Stop()
+	return err
+}
+
+// terminalSize returns the size of w when it is a terminal, and 80 by 24
+// otherwise.
+func terminalSize(w io.Writer) func() (int, int) {
+	return func() (int, int) {
+		if file, ok := w.(*os.File); ok {
+			if width, height, err := term.GetSize(int(file.Fd())); err == nil {
+				return width, height
+			}
+		}
+		return 80, 24
+	}
+}
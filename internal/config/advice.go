@@ -0,0 +1,39 @@
+package config
+
+import (
+	"greenhouse-simulator/internal/models"
+	"greenhouse-simulator/internal/sensors"
+)
+
+// SensorAdvicePolicy returns the policy to advise on the sensors of the
+// greenhouse with, see sensors.AdviseSensorPlacement: the default number of
+// plants per soil moisture sensor, and the sensor types the configured
+// features rely on, named after their config keys.
+func (c *GreenhouseConfig) SensorAdvicePolicy() sensors.AdvicePolicy {
+	policy := sensors.AdvicePolicy{MaxPlantsPerSensor: sensors.DefaultMaxPlantsPerSensor}
+	require := func(feature string, sensorType models.SensorType) {
+		policy.Required = append(policy.Required, sensors.SensorRequirement{Feature: feature, Type: sensorType})
+	}
+	if c.HVAC != nil {
+		require("hvac", models.Temperature)
+	}
+	if len(c.Lights) > 0 {
+		require("lights", models.Light)
+	}
+	if c.Disease != nil {
+		require("disease", models.Humidity)
+	}
+	if c.Environment.CO2Baseline > 0 {
+		require("environment.co2_baseline", models.CO2)
+	}
+	if c.Salinity != nil {
+		require("salinity", models.Salinity)
+	}
+	if c.SoilTemperature != nil {
+		require("soil_temperature", models.SoilTemperature)
+	}
+	if c.WaterTemperature != nil {
+		require("water_temperature", models.WaterTemperature)
+	}
+	return policy
+}
@@ -0,0 +1,220 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Alert rules, see AlertsConfig.
+const (
+	// AlertTickOverruns fires when more than Threshold ticks of the window
+	// took longer than the tick interval.
+	AlertTickOverruns = "tick_overruns"
+	// AlertExportDrops fires when the exporters dropped more than Threshold
+	// items over the window.
+	AlertExportDrops = "export_drops"
+	// AlertTankEmpty fires when the water tank holds Threshold or less.
+	AlertTankEmpty = "tank_empty"
+	// AlertDeadPlants fires when more than Threshold percent of the plants
+	// are dead.
+	AlertDeadPlants = "dead_plants"
+	// AlertUnmonitoredSection fires for every section that has plants but no
+	// sensor. It takes no threshold.
+	AlertUnmonitoredSection = "unmonitored_section"
+	// AlertLowBattery fires for every wireless sensor whose battery is at
+	// Threshold or less, from 0.0 to 1.0.
+	AlertLowBattery = "low_battery"
+	// AlertSaturationDrop fires for every soil moisture sensor whose
+	// readings drop faster than Threshold per tick, over the rate window of
+	// the sensor, see models.SensorReading.Rate.
+	AlertSaturationDrop = "saturation_drop"
+)
+
+// AlertRules lists every alert rule, in the order they are evaluated.
+var AlertRules = []string{AlertTickOverruns, AlertExportDrops, AlertTankEmpty, AlertDeadPlants, AlertUnmonitoredSection, AlertLowBattery, AlertSaturationDrop}
+
+// ZoneAlertRules lists the alert rules that can be scoped to a zone, see
+// AlertsConfig.Zones.
+var ZoneAlertRules = []string{AlertDeadPlants}
+
+// Alert severities, see AlertRuleConfig.
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// DefaultAlertWindow is the number of ticks the tick overruns and export
+// drops are counted over when AlertsConfig.Window is zero.
+const DefaultAlertWindow = 60
+
+// defaultAlertRules are the thresholds and severities of the rules Rules
+// does not override.
+var defaultAlertRules = map[string]AlertRuleConfig{
+	AlertTickOverruns:       {Threshold: ptr(5.0), Severity: SeverityWarning},
+	AlertExportDrops:        {Threshold: ptr(0.0), Severity: SeverityWarning},
+	AlertTankEmpty:          {Threshold: ptr(0.0), Severity: SeverityCritical},
+	AlertDeadPlants:         {Threshold: ptr(50.0), Severity: SeverityCritical},
+	AlertUnmonitoredSection: {Severity: SeverityWarning},
+	AlertLowBattery:         {Threshold: ptr(0.2), Severity: SeverityWarning},
+	AlertSaturationDrop:     {Threshold: ptr(0.05), Severity: SeverityWarning},
+}
+
+// AlertsConfig turns on the alerts on the health of the simulator, which are
+// evaluated after every tick and published as events, see
+// greenhouse.Alert. Every rule is on, with its default threshold and
+// severity, unless Rules overrides it by name. Window is the number of ticks
+// the tick overruns and export drops are counted over, DefaultAlertWindow
+// when zero. Zones adds rules scoped to a zone, by zone ID, which only
+// measure the plants of the zone's sections; only the ZoneAlertRules can be
+// scoped, and a zone has none of them unless listed.
+type AlertsConfig struct {
+	Window int                                   `json:"window,omitempty" yaml:"window,omitempty"`
+	Rules  map[string]AlertRuleConfig            `json:"rules,omitempty" yaml:"rules,omitempty"`
+	Zones  map[string]map[string]AlertRuleConfig `json:"zones,omitempty" yaml:"zones,omitempty"`
+}
+
+// AlertRuleConfig overrides an alert rule. Disabled turns the rule off. A nil
+// Threshold and an empty Severity, one of info, warning or critical, keep the
+// defaults of the rule. Escalation, if set, notifies the alerts of the rule
+// again while they stay unresolved, see EscalationConfig.
+type AlertRuleConfig struct {
+	Disabled   bool              `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+	Threshold  *float64          `json:"threshold,omitempty" yaml:"threshold,omitempty"`
+	Severity   string            `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Escalation *EscalationConfig `json:"escalation,omitempty" yaml:"escalation,omitempty"`
+}
+
+// EscalationConfig escalates the alerts of a rule that stay unresolved.
+// RenotifyTicks notifies an alert again every that many ticks since it was
+// last notified. EscalateAfter upgrades its severity to EscalateTo, critical
+// when empty, once it has been firing that many ticks, and notifies it right
+// away; an alert already as severe is left as it is. Zero turns either off,
+// and resolving the alert cancels both.
+type EscalationConfig struct {
+	RenotifyTicks int    `json:"renotify_ticks,omitempty" yaml:"renotify_ticks,omitempty"`
+	EscalateAfter int    `json:"escalate_after,omitempty" yaml:"escalate_after,omitempty"`
+	EscalateTo    string `json:"escalate_to,omitempty" yaml:"escalate_to,omitempty"`
+}
+
+// SeverityRank orders the severities, from 1 for info to 3 for critical, and
+// returns 0 for an unknown one.
+func SeverityRank(severity string) int {
+	return slices.Index(severities, severity) + 1
+}
+
+var severities = []string{SeverityInfo, SeverityWarning, SeverityCritical}
+
+// WithDefaults returns a copy of the config with the default window and
+// every rule in Rules, with the defaults of the rule where it does not
+// override them. The rules of the zones are left as they are, see ZoneRule.
+func (a AlertsConfig) WithDefaults() AlertsConfig {
+	if a.Window == 0 {
+		a.Window = DefaultAlertWindow
+	}
+	rules := make(map[string]AlertRuleConfig, len(AlertRules))
+	for _, name := range AlertRules {
+		rules[name] = withRuleDefaults(name, a.Rules[name])
+	}
+	a.Rules = rules
+	return a
+}
+
+// ZoneRule returns a rule of a zone with the defaults of the rule where it
+// does not override them, and false if the zone does not have the rule.
+func (a AlertsConfig) ZoneRule(zoneID, name string) (AlertRuleConfig, bool) {
+	rule, ok := a.Zones[zoneID][name]
+	if !ok {
+		return AlertRuleConfig{}, false
+	}
+	return withRuleDefaults(name, rule), true
+}
+
+// withRuleDefaults fills in the defaults of a rule where rule does not
+// override them.
+func withRuleDefaults(name string, rule AlertRuleConfig) AlertRuleConfig {
+	defaults := defaultAlertRules[name]
+	if rule.Threshold == nil {
+		rule.Threshold = defaults.Threshold
+	}
+	if rule.Severity == "" {
+		rule.Severity = defaults.Severity
+	}
+	return rule
+}
+
+// validate checks the alert settings. Returns an error if:
+// - the window is negative
+// - a rule is unknown
+// - a severity is not info, warning or critical
+// - a threshold is negative, the dead plants threshold is above 100, the low
+// battery or saturation drop threshold above 1.0 or the unmonitored section
+// rule has one
+// - an escalation has negative ticks, an unknown severity, or a severity
+// without escalate_after
+// - a zone has a rule that is not one of the ZoneAlertRules, or an invalid
+// one
+//
+// Whether the zones exist is checked with the rest of the config.
+func (a AlertsConfig) validate() error {
+	if a.Window < 0 {
+		return errors.New("alert window cannot be negative")
+	}
+	for name, rule := range a.Rules {
+		if !slices.Contains(AlertRules, name) {
+			return errors.New("unknown alert rule: " + name)
+		}
+		if err := validateRule(name, rule); err != nil {
+			return err
+		}
+	}
+	for zoneID, rules := range a.Zones {
+		for name, rule := range rules {
+			if !slices.Contains(ZoneAlertRules, name) {
+				return fmt.Errorf("alert rule of zone %s cannot be scoped to a zone: %s", zoneID, name)
+			}
+			if err := validateRule(name, rule); err != nil {
+				return fmt.Errorf("zone %s: %w", zoneID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateRule checks the severity, escalation and threshold of a known rule.
+func validateRule(name string, rule AlertRuleConfig) error {
+	if rule.Severity != "" && SeverityRank(rule.Severity) == 0 {
+		return fmt.Errorf("alert rule %s: severity must be info, warning or critical: %s", name, rule.Severity)
+	}
+	if e := rule.Escalation; e != nil {
+		switch {
+		case e.RenotifyTicks < 0 || e.EscalateAfter < 0:
+			return fmt.Errorf("alert rule %s: escalation ticks cannot be negative", name)
+		case e.EscalateTo != "" && SeverityRank(e.EscalateTo) == 0:
+			return fmt.Errorf("alert rule %s: escalation severity must be info, warning or critical: %s", name, e.EscalateTo)
+		case e.EscalateTo != "" && e.EscalateAfter == 0:
+			return fmt.Errorf("alert rule %s: escalation severity requires escalate_after", name)
+		}
+	}
+	if rule.Threshold == nil {
+		return nil
+	}
+	switch {
+	case name == AlertUnmonitoredSection:
+		return errors.New("alert rule unmonitored_section takes no threshold")
+	case *rule.Threshold < 0:
+		return errors.New("alert threshold cannot be negative: " + name)
+	case name == AlertDeadPlants && *rule.Threshold > 100:
+		return errors.New("dead plants alert threshold cannot exceed 100 percent")
+	case name == AlertLowBattery && *rule.Threshold > 1:
+		return errors.New("low battery alert threshold cannot exceed 1.0")
+	case name == AlertSaturationDrop && *rule.Threshold > 1:
+		return errors.New("saturation drop alert threshold cannot exceed 1.0 per tick")
+	}
+	return nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,1563 @@
+package config
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/environment"
+	"greenhouse-simulator/internal/models"
+	"greenhouse-simulator/internal/rng"
+	"greenhouse-simulator/internal/sensors"
+	"greenhouse-simulator/internal/watering"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// GreenhouseConfig describes a complete greenhouse: the simulation clock, the
+// plants and their types, the soil, grow lights and microclimates of the
+// sections and the zones grouping them, the sensors, the heater, vent and
+// thermostat, the irrigation schedules and the water tank, the prices of
+// water and energy, plus a timeline of scripted actions and optionally an
+// MQTT broker to connect to, the APIs to serve, an InfluxDB to export
+// readings to, the tracing of ticks and requests, more output sinks and the
+// alerts on the health of the simulator.
+// SchemaVersion is the layout version of the file the config was loaded
+// from, see Load; configs built in code may leave it zero.
+// Seed is the root of every random number of the run, see Random, and is
+// recorded with scenario results so runs can be reproduced. WarmupTicks
+// runs the plants that many ticks, watering schedules included, before the
+// run starts at tick 0, so it skips the transient of plants that all start
+// as seeds; see greenhouse.New. Zero means no warm-up.
+// LogLevel is one of debug, info, warn or error; empty means info.
+// Invariants turns on the checks of the plants after every tick, see
+// engine.WithInvariantChecks: InvariantsPanic makes a broken tick panic and
+// InvariantsEvent reports every violation as an event; empty means off.
+// OverrunPolicy is what the simulator does about ticks that take longer than
+// the tick interval, one of skip, catchup or stretch, see
+// engine.OverrunPolicy; empty means skip.
+// DeadSections is what soil moisture sensors read in a section whose plants
+// are all dead, hold or error, see sensors.DeadSectionPolicy; empty means
+// hold. HistoryLookup is which sample reading a sensor as of a past tick
+// returns, nearest_before or exact, see sensors.HistoryLookup; empty means
+// nearest_before. StrictSensorFilters rejects sensors whose plant filter refers to a
+// plant type, tag or plant that no configured plant has. SlowSensorRead logs
+// the sensor reads that take longer, see
+// sensors.SensorManager.SetSlowReadThreshold; zero means off.
+// WateringDedupWindow is the number of ticks the request ID of a manual
+// watering is remembered, so that a retried request waters only once, see
+// watering.ManualOptions; zero means watering.DefaultDedupWindow.
+// IdlePauseTicks pauses the simulation once that many ticks ran without an
+// API call, an event delivered to a subscriber or an exporter flush, until the
+// next one, see greenhouse.Greenhouse.Touch; zero means never.
+// Every field carries matching json and yaml tags, so a config converted from
+// one format to the other loads into an identical struct.
+type GreenhouseConfig struct {
+	SchemaVersion int      `json:"schema_version,omitempty" yaml:"schema_version,omitempty"`
+	TickInterval  Duration `json:"tick_interval" yaml:"tick_interval"`
+	Seed          int64    `json:"seed,omitempty" yaml:"seed,omitempty"`
+	WarmupTicks   int      `json:"warmup_ticks,omitempty" yaml:"warmup_ticks,omitempty"`
+	LogLevel      string   `json:"log_level,omitempty" yaml:"log_level,omitempty"`
+	Invariants    string   `json:"invariants,omitempty" yaml:"invariants,omitempty"`
+	OverrunPolicy string   `json:"overrun_policy,omitempty" yaml:"overrun_policy,omitempty"`
+	DeadSections  string   `json:"dead_sections,omitempty" yaml:"dead_sections,omitempty"`
+	HistoryLookup string   `json:"history_lookup,omitempty" yaml:"history_lookup,omitempty"`
+
+	StrictSensorFilters bool     `json:"strict_sensor_filters,omitempty" yaml:"strict_sensor_filters,omitempty"`
+	SlowSensorRead      Duration `json:"slow_sensor_read,omitempty" yaml:"slow_sensor_read,omitempty"`
+	WateringDedupWindow int      `json:"watering_dedup_window,omitempty" yaml:"watering_dedup_window,omitempty"`
+	IdlePauseTicks      int      `json:"idle_pause_ticks,omitempty" yaml:"idle_pause_ticks,omitempty"`
+
+	Environment      EnvironmentConfig       `json:"environment" yaml:"environment"`
+	PlantTypes       []PlantTypeConfig       `json:"plant_types,omitempty" yaml:"plant_types,omitempty"`
+	Plants           []PlantConfig           `json:"plants" yaml:"plants"`
+	Sections         []SectionConfig         `json:"sections,omitempty" yaml:"sections,omitempty"`
+	Zones            []ZoneConfig            `json:"zones,omitempty" yaml:"zones,omitempty"`
+	Rotations        []RotationConfig        `json:"rotations,omitempty" yaml:"rotations,omitempty"`
+	Lights           []LightsConfig          `json:"lights,omitempty" yaml:"lights,omitempty"`
+	Microclimates    []MicroclimateConfig    `json:"microclimates,omitempty" yaml:"microclimates,omitempty"`
+	Sensors          []SensorConfig          `json:"sensors,omitempty" yaml:"sensors,omitempty"`
+	SensorTemplates  []SensorTemplateConfig  `json:"sensor_templates,omitempty" yaml:"sensor_templates,omitempty"`
+	HVAC             *HVACConfig             `json:"hvac,omitempty" yaml:"hvac,omitempty"`
+	Disease          *DiseaseConfig          `json:"disease,omitempty" yaml:"disease,omitempty"`
+	Pruning          *PruningConfig          `json:"pruning,omitempty" yaml:"pruning,omitempty"`
+	Salinity         *SalinityConfig         `json:"salinity,omitempty" yaml:"salinity,omitempty"`
+	SoilTemperature  *SoilTemperatureConfig  `json:"soil_temperature,omitempty" yaml:"soil_temperature,omitempty"`
+	WaterTemperature *WaterTemperatureConfig `json:"water_temperature,omitempty" yaml:"water_temperature,omitempty"`
+	LightCompetition *LightCompetitionConfig `json:"light_competition,omitempty" yaml:"light_competition,omitempty"`
+	DeadPlants       *DeadPlantsConfig       `json:"dead_plants,omitempty" yaml:"dead_plants,omitempty"`
+	Journal          *JournalConfig          `json:"journal,omitempty" yaml:"journal,omitempty"`
+	Schedules        ScheduleList            `json:"schedules,omitempty" yaml:"schedules,omitempty"`
+	Tank             *TankConfig             `json:"tank,omitempty" yaml:"tank,omitempty"`
+	Watering         *WateringConfig         `json:"watering,omitempty" yaml:"watering,omitempty"`
+	Prices           *PricesConfig           `json:"prices,omitempty" yaml:"prices,omitempty"`
+	Timeline         []ActionConfig          `json:"timeline,omitempty" yaml:"timeline,omitempty"`
+	MQTT             *MQTTConfig             `json:"mqtt,omitempty" yaml:"mqtt,omitempty"`
+	Server           *ServerConfig           `json:"server,omitempty" yaml:"server,omitempty"`
+	Influx           *InfluxConfig           `json:"influx,omitempty" yaml:"influx,omitempty"`
+	Tracing          *TracingConfig          `json:"tracing,omitempty" yaml:"tracing,omitempty"`
+	Export           *ExportConfig           `json:"export,omitempty" yaml:"export,omitempty"`
+	Alerts           *AlertsConfig           `json:"alerts,omitempty" yaml:"alerts,omitempty"`
+	Chaos            *ChaosConfig            `json:"chaos,omitempty" yaml:"chaos,omitempty"`
+}
+
+// EnvironmentConfig configures the day cycle, the air humidity and the
+// climate model, see environment.Climate. A zero TicksPerDay disables the day
+// cycle, which also requires the weather chances and the seasonal drift to be
+// zero. An environment naming a Profile starts from that built-in profile, so
+// only the fields that differ from it need to be given. The frost and heat
+// wave settings configure the extreme weather, which can also be triggered,
+// see greenhouse.Greenhouse.TriggerWeatherEvent, the season settings the
+// annual cycle, off without ticks per year, see environment.Seasons, and the
+// CO2 settings the CO2 model, off without a CO2 baseline, see
+// environment.CO2Config.
+type EnvironmentConfig struct {
+	Profile             string  `json:"profile,omitempty" yaml:"profile,omitempty"`
+	TicksPerDay         int     `json:"ticks_per_day,omitempty" yaml:"ticks_per_day,omitempty"`
+	AmbientHumidity     float64 `json:"ambient_humidity" yaml:"ambient_humidity"`
+	HumidityDecay       float64 `json:"humidity_decay" yaml:"humidity_decay"`
+	Temperature         float64 `json:"temperature,omitempty" yaml:"temperature,omitempty"`
+	TemperatureSwing    float64 `json:"temperature_swing,omitempty" yaml:"temperature_swing,omitempty"`
+	Light               float64 `json:"light,omitempty" yaml:"light,omitempty"`
+	SeasonalDrift       float64 `json:"seasonal_drift,omitempty" yaml:"seasonal_drift,omitempty"`
+	TicksPerYear        int     `json:"ticks_per_year,omitempty" yaml:"ticks_per_year,omitempty"`
+	SeasonPhase         float64 `json:"season_phase,omitempty" yaml:"season_phase,omitempty"`
+	SeasonalTemperature float64 `json:"seasonal_temperature,omitempty" yaml:"seasonal_temperature,omitempty"`
+	SeasonalDayLength   float64 `json:"seasonal_day_length,omitempty" yaml:"seasonal_day_length,omitempty"`
+	SeasonalWeather     float64 `json:"seasonal_weather,omitempty" yaml:"seasonal_weather,omitempty"`
+	CloudyChance        float64 `json:"cloudy_chance,omitempty" yaml:"cloudy_chance,omitempty"`
+	RainChance          float64 `json:"rain_chance,omitempty" yaml:"rain_chance,omitempty"`
+	FrostChance         float64 `json:"frost_chance,omitempty" yaml:"frost_chance,omitempty"`
+	HeatWaveChance      float64 `json:"heat_wave_chance,omitempty" yaml:"heat_wave_chance,omitempty"`
+	ExtremeTicks        int     `json:"extreme_ticks,omitempty" yaml:"extreme_ticks,omitempty"`
+	FrostTemperature    float64 `json:"frost_temperature,omitempty" yaml:"frost_temperature,omitempty"`
+	FrostDamage         float64 `json:"frost_damage,omitempty" yaml:"frost_damage,omitempty"`
+	HeatWaveRise        float64 `json:"heat_wave_rise,omitempty" yaml:"heat_wave_rise,omitempty"`
+	HeatWaveEvaporation float64 `json:"heat_wave_evaporation,omitempty" yaml:"heat_wave_evaporation,omitempty"`
+	CO2Baseline         float64 `json:"co2_baseline,omitempty" yaml:"co2_baseline,omitempty"`
+	CO2Uptake           float64 `json:"co2_uptake,omitempty" yaml:"co2_uptake,omitempty"`
+	CO2Ventilation      float64 `json:"co2_ventilation,omitempty" yaml:"co2_ventilation,omitempty"`
+	CO2BoostThreshold   float64 `json:"co2_boost_threshold,omitempty" yaml:"co2_boost_threshold,omitempty"`
+	CO2GrowthBoost      float64 `json:"co2_growth_boost,omitempty" yaml:"co2_growth_boost,omitempty"`
+}
+
+// PlantTypeConfig mirrors models.PlantType. A plant type that Extends a
+// preset from the built-in catalog only needs the fields it overrides; the
+// rest are filled in from the preset when the config is decoded. Growth is
+// nil for models.DefaultGrowthParams, and the growth fields it leaves out are
+// those of the preset or the defaults.
+type PlantTypeConfig struct {
+	Name                  string  `json:"name" yaml:"name"`
+	Extends               string  `json:"extends,omitempty" yaml:"extends,omitempty"`
+	OptimalSaturation     float64 `json:"optimal_saturation" yaml:"optimal_saturation"`
+	MinSaturation         float64 `json:"min_saturation" yaml:"min_saturation"`
+	MaxSaturation         float64 `json:"max_saturation" yaml:"max_saturation"`
+	BaseGrowthRate        float64 `json:"base_growth_rate" yaml:"base_growth_rate"`
+	SaturationDepletion   float64 `json:"saturation_depletion" yaml:"saturation_depletion"`
+	HealthDegradationRate float64 `json:"health_degradation_rate" yaml:"health_degradation_rate"`
+	HealthEnhancementRate float64 `json:"health_enhancement_rate" yaml:"health_enhancement_rate"`
+	FrostTolerance        bool    `json:"frost_tolerance,omitempty" yaml:"frost_tolerance,omitempty"`
+	RootDepth             float64 `json:"root_depth,omitempty" yaml:"root_depth,omitempty"`
+	MinTemperature        float64 `json:"min_temperature,omitempty" yaml:"min_temperature,omitempty"`
+	MaxTemperature        float64 `json:"max_temperature,omitempty" yaml:"max_temperature,omitempty"`
+
+	GerminationTicks              int     `json:"germination_ticks,omitempty" yaml:"germination_ticks,omitempty"`
+	GerminationMinSaturation      float64 `json:"germination_min_saturation,omitempty" yaml:"germination_min_saturation,omitempty"`
+	GerminationMaxSaturation      float64 `json:"germination_max_saturation,omitempty" yaml:"germination_max_saturation,omitempty"`
+	GerminationFailure            float64 `json:"germination_failure,omitempty" yaml:"germination_failure,omitempty"`
+	GerminationMinSoilTemperature float64 `json:"germination_min_soil_temperature,omitempty" yaml:"germination_min_soil_temperature,omitempty"`
+
+	Growth                *GrowthConfig `json:"growth,omitempty" yaml:"growth,omitempty"`
+	SalinityTolerance     float64       `json:"salinity_tolerance,omitempty" yaml:"salinity_tolerance,omitempty"`
+	ThermalShockTolerance float64       `json:"thermal_shock_tolerance,omitempty" yaml:"thermal_shock_tolerance,omitempty"`
+}
+
+// GrowthConfig mirrors models.GrowthParams. Nil fields keep the value of
+// models.DefaultGrowthParams.
+type GrowthConfig struct {
+	MinHealth          *float64 `json:"min_health,omitempty" yaml:"min_health,omitempty"`
+	SlowHealth         *float64 `json:"slow_health,omitempty" yaml:"slow_health,omitempty"`
+	SlowFactor         *float64 `json:"slow_factor,omitempty" yaml:"slow_factor,omitempty"`
+	OptimalBonusFactor *float64 `json:"optimal_bonus_factor,omitempty" yaml:"optimal_bonus_factor,omitempty"`
+	OptimalTolerance   *float64 `json:"optimal_tolerance,omitempty" yaml:"optimal_tolerance,omitempty"`
+}
+
+// params returns models.DefaultGrowthParams with the configured fields
+// overridden.
+func (g GrowthConfig) params() models.GrowthParams {
+	params := models.DefaultGrowthParams
+	for _, field := range []struct {
+		value  *float64
+		target *float64
+	}{
+		{g.MinHealth, &params.MinHealth},
+		{g.SlowHealth, &params.SlowHealth},
+		{g.SlowFactor, &params.SlowFactor},
+		{g.OptimalBonusFactor, &params.OptimalBonusFactor},
+		{g.OptimalTolerance, &params.OptimalTolerance},
+	} {
+		if field.value != nil {
+			*field.target = *field.value
+		}
+	}
+	return params
+}
+
+// PlantConfig describes a plant. Type refers to a PlantTypeConfig by name.
+// State is only set for plants that resume from an exported scenario; plants
+// without it start healthy at the seed stage. ExcludeFromWatering and
+// Quarantined are the plant's initial flags, see models.PlantFlags, and
+// Position where it stands in its section, see SectionConfig.
+type PlantConfig struct {
+	ID                  string            `json:"id" yaml:"id"`
+	Type                string            `json:"type" yaml:"type"`
+	SectionID           string            `json:"section" yaml:"section"`
+	InitialSaturation   float64           `json:"initial_saturation" yaml:"initial_saturation"`
+	Tags                []string          `json:"tags,omitempty" yaml:"tags,omitempty"`
+	ExcludeFromWatering bool              `json:"exclude_from_watering,omitempty" yaml:"exclude_from_watering,omitempty"`
+	Quarantined         bool              `json:"quarantined,omitempty" yaml:"quarantined,omitempty"`
+	Position            *PositionConfig   `json:"position,omitempty" yaml:"position,omitempty"`
+	State               *PlantStateConfig `json:"state,omitempty" yaml:"state,omitempty"`
+}
+
+// PlantStateConfig is the part of a plant's runtime state that NewPlant
+// does not take. Disease and DiseaseTicks are the plant's models.Disease,
+// empty for a healthy plant. Germination is nil for a plant that is not
+// germinating, even if its type germinates. DeepSaturation is the deep
+// layer of a layered soil, which otherwise starts at the initial saturation.
+type PlantStateConfig struct {
+	Health         float64             `json:"health" yaml:"health"`
+	DeepSaturation float64             `json:"deep_saturation" yaml:"deep_saturation"`
+	GrowthStage    float64             `json:"growth_stage" yaml:"growth_stage"`
+	Alive          bool                `json:"alive" yaml:"alive"`
+	DeathCause     models.DeathCause   `json:"death_cause,omitempty" yaml:"death_cause,omitempty"`
+	Disease        models.DiseaseStage `json:"disease,omitempty" yaml:"disease,omitempty"`
+	DiseaseTicks   int                 `json:"disease_ticks,omitempty" yaml:"disease_ticks,omitempty"`
+	Modifiers      []ModifierConfig    `json:"modifiers,omitempty" yaml:"modifiers,omitempty"`
+	Germination    *GerminationConfig  `json:"germination,omitempty" yaml:"germination,omitempty"`
+}
+
+// GerminationConfig mirrors models.Germination.
+type GerminationConfig struct {
+	Ticks     int  `json:"ticks" yaml:"ticks"`
+	Favorable int  `json:"favorable" yaml:"favorable"`
+	Dormant   bool `json:"dormant,omitempty" yaml:"dormant,omitempty"`
+}
+
+// ModifierConfig mirrors models.Modifier.
+type ModifierConfig struct {
+	Source      string  `json:"source" yaml:"source"`
+	Depletion   float64 `json:"depletion" yaml:"depletion"`
+	Enhancement float64 `json:"enhancement" yaml:"enhancement"`
+	Ticks       int     `json:"ticks" yaml:"ticks"`
+}
+
+// PruningConfig mirrors models.PruneEffect.
+type PruningConfig struct {
+	Ticks       int     `json:"ticks" yaml:"ticks"`
+	Depletion   float64 `json:"depletion" yaml:"depletion"`
+	Enhancement float64 `json:"enhancement" yaml:"enhancement"`
+}
+
+// Invariant check modes, see GreenhouseConfig.Invariants.
+const (
+	InvariantsPanic = "panic"
+	InvariantsEvent = "event"
+)
+
+// Retention policies of dead plants.
+const (
+	// KeepDeadPlants keeps dead plants in the simulation for good.
+	KeepDeadPlants = "keep"
+	// RemoveDeadPlants removes dead plants from the simulation.
+	RemoveDeadPlants = "remove"
+)
+
+// DeadPlantsConfig is the retention policy of dead plants: Retention is
+// KeepDeadPlants, the default, or RemoveDeadPlants to remove them After ticks
+// after they died, on the tick they are found dead when zero.
+type DeadPlantsConfig struct {
+	Retention string `json:"retention" yaml:"retention"`
+	After     int    `json:"after,omitempty" yaml:"after,omitempty"`
+}
+
+// DefaultJournalEntries is the number of entries kept for each plant when
+// JournalConfig leaves Entries at zero.
+const DefaultJournalEntries = 50
+
+// JournalConfig keeps a journal of the significant changes of each plant,
+// see greenhouse.Greenhouse.GetPlantJournal, holding the last Entries of
+// them, DefaultJournalEntries when zero.
+type JournalConfig struct {
+	Entries int `json:"entries,omitempty" yaml:"entries,omitempty"`
+}
+
+// SectionConfig sets the soil of a section, which every plant in it takes.
+// Soil names a preset soil type, Sand, Loam or Clay, and Retention and
+// Drainage override its coefficients; a section without Soil has a custom soil
+// with the given coefficients. Sections not listed, or listed with none of
+// them, have plain soil. Salinity is the soil salinity the section starts
+// at, see SalinityConfig. Width and Depth are its models.Dimensions, which
+// the positions of its plants and sensors must fall within.
+type SectionConfig struct {
+	ID          string  `json:"id" yaml:"id"`
+	Soil        string  `json:"soil,omitempty" yaml:"soil,omitempty"`
+	Retention   float64 `json:"retention,omitempty" yaml:"retention,omitempty"`
+	Drainage    float64 `json:"drainage,omitempty" yaml:"drainage,omitempty"`
+	Percolation float64 `json:"percolation,omitempty" yaml:"percolation,omitempty"`
+	Salinity    float64 `json:"salinity,omitempty" yaml:"salinity,omitempty"`
+	Width       float64 `json:"width,omitempty" yaml:"width,omitempty"`
+	Depth       float64 `json:"depth,omitempty" yaml:"depth,omitempty"`
+}
+
+// PositionConfig mirrors models.Position.
+type PositionConfig struct {
+	X float64 `json:"x" yaml:"x"`
+	Y float64 `json:"y" yaml:"y"`
+}
+
+// MicroclimateConfig is the environment.ClimateOffset of a section.
+type MicroclimateConfig struct {
+	SectionID   string  `json:"section" yaml:"section"`
+	Temperature float64 `json:"temperature,omitempty" yaml:"temperature,omitempty"`
+	Humidity    float64 `json:"humidity,omitempty" yaml:"humidity,omitempty"`
+	Light       float64 `json:"light,omitempty" yaml:"light,omitempty"`
+}
+
+// LightsConfig mirrors environment.LightsConfig.
+type LightsConfig struct {
+	SectionID     string  `json:"section" yaml:"section"`
+	Intensity     float64 `json:"intensity,omitempty" yaml:"intensity,omitempty"`
+	EnergyPerTick float64 `json:"energy_per_tick,omitempty" yaml:"energy_per_tick,omitempty"`
+	AfterSunset   int     `json:"after_sunset,omitempty" yaml:"after_sunset,omitempty"`
+}
+
+// HVACConfig mirrors environment.HVACConfig.
+type HVACConfig struct {
+	HeaterPower  float64           `json:"heater_power,omitempty" yaml:"heater_power,omitempty"`
+	VentPower    float64           `json:"vent_power,omitempty" yaml:"vent_power,omitempty"`
+	HeatLoss     float64           `json:"heat_loss,omitempty" yaml:"heat_loss,omitempty"`
+	HeaterEnergy float64           `json:"heater_energy,omitempty" yaml:"heater_energy,omitempty"`
+	VentEnergy   float64           `json:"vent_energy,omitempty" yaml:"vent_energy,omitempty"`
+	Thermostat   *ThermostatConfig `json:"thermostat,omitempty" yaml:"thermostat,omitempty"`
+}
+
+// DiseaseConfig mirrors environment.DiseaseConfig.
+type DiseaseConfig struct {
+	Incubation        int     `json:"incubation,omitempty" yaml:"incubation,omitempty"`
+	HealthDecay       float64 `json:"health_decay,omitempty" yaml:"health_decay,omitempty"`
+	TranspirationDrop float64 `json:"transpiration_drop,omitempty" yaml:"transpiration_drop,omitempty"`
+	SpreadRate        float64 `json:"spread_rate,omitempty" yaml:"spread_rate,omitempty"`
+	CureChance        float64 `json:"cure_chance,omitempty" yaml:"cure_chance,omitempty"`
+}
+
+// SalinityConfig mirrors environment.SalinityConfig, with a concentration
+// for each source of water: Concentration for mains water and
+// TankConcentration for the water of the tank, which a greenhouse with a tank
+// waters with.
+type SalinityConfig struct {
+	Concentration     float64 `json:"concentration,omitempty" yaml:"concentration,omitempty"`
+	TankConcentration float64 `json:"tank_concentration,omitempty" yaml:"tank_concentration,omitempty"`
+	FlushAmount       float64 `json:"flush_amount,omitempty" yaml:"flush_amount,omitempty"`
+	FlushRate         float64 `json:"flush_rate,omitempty" yaml:"flush_rate,omitempty"`
+	RainLeach         float64 `json:"rain_leach,omitempty" yaml:"rain_leach,omitempty"`
+	Damage            float64 `json:"damage,omitempty" yaml:"damage,omitempty"`
+}
+
+// SoilTemperatureConfig mirrors environment.SoilTemperatureConfig.
+type SoilTemperatureConfig struct {
+	Inertia          float64 `json:"inertia,omitempty" yaml:"inertia,omitempty"`
+	WaterTemperature float64 `json:"water_temperature,omitempty" yaml:"water_temperature,omitempty"`
+	WaterExchange    float64 `json:"water_exchange,omitempty" yaml:"water_exchange,omitempty"`
+}
+
+// WaterTemperatureConfig mirrors environment.WaterTemperatureConfig, with
+// Temperature the initial temperature of the water. A zero ShockDamage
+// means 0.01.
+type WaterTemperatureConfig struct {
+	Temperature *float64 `json:"temperature,omitempty" yaml:"temperature,omitempty"`
+	Inertia     float64  `json:"inertia,omitempty" yaml:"inertia,omitempty"`
+	ShockDamage float64  `json:"shock_damage,omitempty" yaml:"shock_damage,omitempty"`
+}
+
+// LightCompetitionConfig mirrors models.LightCompetition.
+type LightCompetitionConfig struct {
+	Coefficient float64 `json:"coefficient" yaml:"coefficient"`
+	Floor       float64 `json:"floor,omitempty" yaml:"floor,omitempty"`
+}
+
+// ChaosConfig makes the simulation hostile for resilience testing, see
+// greenhouse.ChaosFault. Each probability is the chance, on every tick, of
+// one fault of its kind: SensorFailure fails a sensor, WateringDrop cancels
+// the watering of a section before it completes, Infection infects a plant,
+// which requires the disease model, and TickJitter holds the tick up for up
+// to MaxJitter. The faults are drawn from the seed, and nothing happens
+// unless Enabled is set.
+type ChaosConfig struct {
+	Enabled       bool     `json:"enabled" yaml:"enabled"`
+	SensorFailure float64  `json:"sensor_failure,omitempty" yaml:"sensor_failure,omitempty"`
+	WateringDrop  float64  `json:"watering_drop,omitempty" yaml:"watering_drop,omitempty"`
+	Infection     float64  `json:"infection,omitempty" yaml:"infection,omitempty"`
+	TickJitter    float64  `json:"tick_jitter,omitempty" yaml:"tick_jitter,omitempty"`
+	MaxJitter     Duration `json:"max_jitter,omitempty" yaml:"max_jitter,omitempty"`
+}
+
+// ThermostatConfig mirrors environment.ThermostatConfig. SensorID must name
+// a temperature sensor.
+type ThermostatConfig struct {
+	SensorID string  `json:"sensor" yaml:"sensor"`
+	Min      float64 `json:"min" yaml:"min"`
+	Max      float64 `json:"max" yaml:"max"`
+}
+
+// SensorConfig mirrors models.Sensor. A sensor without a Filter reads every
+// plant of its section, and one without a Battery is wired.
+type SensorConfig struct {
+	ID             string             `json:"id" yaml:"id"`
+	Type           models.SensorType  `json:"type" yaml:"type"`
+	SectionID      string             `json:"section" yaml:"section"`
+	Noise          float64            `json:"noise,omitempty" yaml:"noise,omitempty"`
+	Depth          models.SoilDepth   `json:"depth,omitempty" yaml:"depth,omitempty"`
+	Filter         *PlantFilterConfig `json:"filter,omitempty" yaml:"filter,omitempty"`
+	Battery        *BatteryConfig     `json:"battery,omitempty" yaml:"battery,omitempty"`
+	SampleInterval int                `json:"sample_interval,omitempty" yaml:"sample_interval,omitempty"`
+	RateWindow     int                `json:"rate_window,omitempty" yaml:"rate_window,omitempty"`
+	WindowTicks    int                `json:"window_ticks,omitempty" yaml:"window_ticks,omitempty"`
+	Position       *PositionConfig    `json:"position,omitempty" yaml:"position,omitempty"`
+}
+
+// BatteryConfig mirrors models.Battery. A nil Level is a full battery.
+type BatteryConfig struct {
+	Level *float64 `json:"level,omitempty" yaml:"level,omitempty"`
+	Drain float64  `json:"drain" yaml:"drain"`
+}
+
+// PlantFilterConfig mirrors models.PlantFilter.
+type PlantFilterConfig struct {
+	Type   string   `json:"type,omitempty" yaml:"type,omitempty"`
+	Tag    string   `json:"tag,omitempty" yaml:"tag,omitempty"`
+	Plants []string `json:"plants,omitempty" yaml:"plants,omitempty"`
+}
+
+// ScheduleConfig mirrors models.WateringSchedule.
+type ScheduleConfig struct {
+	ID               string                      `json:"id,omitempty" yaml:"id,omitempty"`
+	SectionID        string                      `json:"section,omitempty" yaml:"section,omitempty"`
+	ZoneID           string                      `json:"zone,omitempty" yaml:"zone,omitempty"`
+	Tag              string                      `json:"tag,omitempty" yaml:"tag,omitempty"`
+	PlantType        string                      `json:"plant_type,omitempty" yaml:"plant_type,omitempty"`
+	TargetSaturation float64                     `json:"target_saturation" yaml:"target_saturation"`
+	CheckInterval    int                         `json:"check_interval" yaml:"check_interval"`
+	WaterAmount      float64                     `json:"water_amount,omitempty" yaml:"water_amount,omitempty"`
+	Duration         Duration                    `json:"duration,omitempty" yaml:"duration,omitempty"`
+	Enabled          bool                        `json:"enabled" yaml:"enabled"`
+	Proportional     *ProportionalConfig         `json:"proportional,omitempty" yaml:"proportional,omitempty"`
+	Method           models.IrrigationMethod     `json:"method,omitempty" yaml:"method,omitempty"`
+	Distribution     models.DistributionStrategy `json:"distribution,omitempty" yaml:"distribution,omitempty"`
+	AllowedWindows   []WindowConfig              `json:"allowed_windows,omitempty" yaml:"allowed_windows,omitempty"`
+	// Auto stands for the recommended schedules, see ScheduleList.
+	Auto bool `json:"-" yaml:"-"`
+}
+
+// ProportionalConfig mirrors models.ProportionalControl.
+type ProportionalConfig struct {
+	Gain         float64 `json:"gain" yaml:"gain"`
+	IntegralGain float64 `json:"integral_gain,omitempty" yaml:"integral_gain,omitempty"`
+	MinAmount    float64 `json:"min_amount,omitempty" yaml:"min_amount,omitempty"`
+	MaxAmount    float64 `json:"max_amount" yaml:"max_amount"`
+}
+
+// WindowConfig mirrors models.TimeWindow.
+type WindowConfig struct {
+	Start float64 `json:"start" yaml:"start"`
+	End   float64 `json:"end" yaml:"end"`
+}
+
+// TankConfig mirrors watering.SupplyConfig.
+type TankConfig struct {
+	Capacity          float64                 `json:"capacity" yaml:"capacity"`
+	InitialLevel      float64                 `json:"initial_level" yaml:"initial_level"`
+	RefillPerTick     float64                 `json:"refill_per_tick,omitempty" yaml:"refill_per_tick,omitempty"`
+	LowWaterThreshold float64                 `json:"low_water_threshold,omitempty" yaml:"low_water_threshold,omitempty"`
+	ShortagePolicy    watering.ShortagePolicy `json:"shortage_policy,omitempty" yaml:"shortage_policy,omitempty"`
+}
+
+// WateringConfig tunes the watering controller, see watering.Config.
+// MaxConcurrentEvents caps the watering events in progress at once, the
+// others waiting in the queue, and MaxFlowPerTick the water all of them
+// deliver in a tick, shared fairly across the sections; zero means no cap.
+// CancelCooldown is the ticks a section's schedules stay idle after its
+// watering was cancelled, zero meaning the longest check interval among them.
+// Methods tune the irrigation methods, see MethodConfig.
+type WateringConfig struct {
+	MaxConcurrentEvents int            `json:"max_concurrent_events,omitempty" yaml:"max_concurrent_events,omitempty"`
+	MaxFlowPerTick      float64        `json:"max_flow_per_tick,omitempty" yaml:"max_flow_per_tick,omitempty"`
+	CancelCooldown      int            `json:"cancel_cooldown,omitempty" yaml:"cancel_cooldown,omitempty"`
+	Methods             []MethodConfig `json:"methods,omitempty" yaml:"methods,omitempty"`
+}
+
+// MethodConfig mirrors watering.MethodProfile for one irrigation method.
+// Nil fields keep the method's default profile, such as
+// watering.DefaultOverheadProfile.
+type MethodConfig struct {
+	Method          models.IrrigationMethod `json:"method" yaml:"method"`
+	Efficiency      *float64                `json:"efficiency,omitempty" yaml:"efficiency,omitempty"`
+	HumidityPerUnit *float64                `json:"humidity_per_unit,omitempty" yaml:"humidity_per_unit,omitempty"`
+}
+
+// defaultMethodProfiles are the profiles the methods of a watering section
+// start from.
+var defaultMethodProfiles = map[models.IrrigationMethod]watering.MethodProfile{
+	models.MethodDrip:     watering.DefaultDripProfile,
+	models.MethodOverhead: watering.DefaultOverheadProfile,
+	models.MethodMisting:  watering.DefaultMistingProfile,
+}
+
+// profile returns the method's default profile with the configured fields
+// overridden.
+func (m MethodConfig) profile() watering.MethodProfile {
+	profile := defaultMethodProfiles[m.Method]
+	if m.Efficiency != nil {
+		profile.Efficiency = *m.Efficiency
+	}
+	if m.HumidityPerUnit != nil {
+		profile.HumidityPerUnit = *m.HumidityPerUnit
+	}
+	return profile
+}
+
+// validate checks the watering settings. Returns an error if:
+// - the maximum number of concurrent events, the maximum flow per tick or the
+// cancel cooldown is negative
+// - a method is unknown or configured twice
+// - a method's efficiency is not between 0.0 and 1.0 or its humidity per unit
+// is negative
+func (w WateringConfig) validate() error {
+	if w.MaxConcurrentEvents < 0 {
+		return errors.New("max concurrent watering events cannot be negative")
+	}
+	if w.MaxFlowPerTick < 0 {
+		return errors.New("max watering flow per tick cannot be negative")
+	}
+	if w.CancelCooldown < 0 {
+		return errors.New("watering cancel cooldown cannot be negative")
+	}
+	seen := map[models.IrrigationMethod]bool{}
+	for _, m := range w.Methods {
+		if _, ok := defaultMethodProfiles[m.Method]; !ok {
+			return errors.New("unknown irrigation method: " + string(m.Method))
+		}
+		if seen[m.Method] {
+			return errors.New("duplicate irrigation method: " + string(m.Method))
+		}
+		seen[m.Method] = true
+		if m.Efficiency != nil && (*m.Efficiency < 0 || *m.Efficiency > 1) {
+			return fmt.Errorf("irrigation method %s: efficiency must be between 0.0 and 1.0", m.Method)
+		}
+		if m.HumidityPerUnit != nil && *m.HumidityPerUnit < 0 {
+			return fmt.Errorf("irrigation method %s: humidity per unit cannot be negative", m.Method)
+		}
+	}
+	return nil
+}
+
+// PricesConfig sets the unit prices the cost ledger charges for the water
+// used and the energy of the grow lights, heater and vent, see
+// greenhouse.CostLedger. Prices left out are zero.
+type PricesConfig struct {
+	Water  float64 `json:"water,omitempty" yaml:"water,omitempty"`
+	Energy float64 `json:"energy,omitempty" yaml:"energy,omitempty"`
+}
+
+// Validate checks the config for errors that would prevent building the
+// greenhouse. Plant and tank values are checked with the same rules as
+// models.NewPlant and watering.NewWaterSupply. Returns an error if:
+// - the tick interval is not positive
+// - the warm-up ticks are negative
+// - the log level is unknown
+// - the invariant check mode is unknown
+// - the slow sensor read threshold is negative
+// - the watering dedup window is negative
+// - the idle pause ticks are negative
+// - the environment settings are invalid, see environment.Climate.Validate
+// and environment.CO2Config.Validate
+// - a plant type or plant ID is empty or duplicated
+// - a plant type is invalid once merged with the preset it extends
+// - a plant refers to an unknown plant type or is otherwise invalid
+// - a section ID is empty or duplicated, or its soil is unknown or invalid,
+// see models.SoilType.Validate, or its dimensions are invalid, see
+// models.Dimensions.Validate
+// - a plant or sensor position is outside its section, see
+// models.Dimensions.Contains
+// - a zone is invalid, has an unknown section or shares a section with
+// another zone
+// - a rotation is invalid, see ValidateRotation, or a section has two
+// - the grow lights are invalid, see environment.NewLights
+// - a microclimate section is empty or duplicated, or its offset is
+// invalid, see environment.ClimateOffset.Validate
+// - a sensor ID or section is empty, a sensor ID is duplicated, a sensor
+// noise is negative or a sensor depth is unknown or not on a soil moisture
+// sensor
+// - a sensor template is invalid, see validateSensorTemplates
+// - the HVAC settings are invalid, see environment.HVACConfig.Validate, or
+// the thermostat does not read a configured temperature sensor
+// - the disease settings are invalid, see environment.DiseaseConfig.Validate
+// - the pruning effect is invalid, see models.PruneEffect.Validate
+// - the salinity settings are invalid, see environment.NewSalinity, the tank
+// concentration is set without a tank, or a section starts salty without
+// salinity settings
+// - the soil temperature settings are invalid, see
+// environment.SoilTemperatureConfig.Validate
+// - the water temperature settings are invalid, see
+// environment.WaterTemperatureConfig.Validate
+// - the light competition settings are invalid, see
+// models.LightCompetition.Validate, or there is no day cycle
+// - a chaos probability is not between 0.0 and 1.0, the maximum jitter is
+// negative or missing for tick jitter, or infections are injected without
+// the disease model
+// - the dead plant retention is unknown, or its delay is negative or set
+// without removing dead plants
+// - the number of journal entries is negative
+// - the schedules are auto and one cannot be recommended for a section, see
+// models.RecommendSchedule, or an auto schedule is listed with others
+// - the tank is invalid
+// - the watering settings are invalid, see WateringConfig
+// - a price is negative
+// - the MQTT, server, InfluxDB, tracing, export or alert settings are invalid
+// - a timeline action has an unknown type or is missing what its type needs
+// - the schema version is set to another version than SchemaVersion
+func (c *GreenhouseConfig) Validate() error {
+	if c.SchemaVersion != 0 && c.SchemaVersion != SchemaVersion {
+		return fmt.Errorf("config schema version must be %d: %d", SchemaVersion, c.SchemaVersion)
+	}
+	if c.TickInterval <= 0 {
+		return errors.New("tick interval must be positive")
+	}
+	if c.WarmupTicks < 0 {
+		return errors.New("warm-up ticks cannot be negative")
+	}
+	if _, err := c.SlogLevel(); err != nil {
+		return err
+	}
+	if c.Invariants != "" && c.Invariants != InvariantsPanic && c.Invariants != InvariantsEvent {
+		return errors.New("invariants must be panic or event: " + c.Invariants)
+	}
+	if c.SlowSensorRead < 0 {
+		return errors.New("slow sensor read threshold cannot be negative")
+	}
+	if c.WateringDedupWindow < 0 {
+		return errors.New("watering dedup window cannot be negative")
+	}
+	if c.IdlePauseTicks < 0 {
+		return errors.New("idle pause ticks cannot be negative")
+	}
+	switch engine.OverrunPolicy(c.OverrunPolicy) {
+	case "", engine.OverrunSkip, engine.OverrunCatchup, engine.OverrunStretch:
+	default:
+		return errors.New("overrun policy must be skip, catchup or stretch: " + c.OverrunPolicy)
+	}
+	switch sensors.DeadSectionPolicy(c.DeadSections) {
+	case "", sensors.DeadSectionHold, sensors.DeadSectionError:
+	default:
+		return errors.New("dead sections must be hold or error: " + c.DeadSections)
+	}
+	switch sensors.HistoryLookup(c.HistoryLookup) {
+	case "", sensors.HistoryLookupNearestBefore, sensors.HistoryLookupExact:
+	default:
+		return errors.New("history lookup must be nearest_before or exact: " + c.HistoryLookup)
+	}
+	if c.Environment.TicksPerDay < 0 {
+		return errors.New("ticks per day cannot be negative")
+	}
+	if _, err := environment.NewHumidity(c.Environment.AmbientHumidity, c.Environment.HumidityDecay); err != nil {
+		return err
+	}
+	if err := c.Climate().Validate(); err != nil {
+		return err
+	}
+	if err := c.CO2().Validate(); err != nil {
+		return err
+	}
+	plants, err := c.BuildPlants()
+	if err != nil {
+		return err
+	}
+	if _, err := environment.NewLights(c.DayCycle(), c.LightsConfigs()); err != nil {
+		return err
+	}
+	microclimates := map[string]bool{}
+	for _, m := range c.Microclimates {
+		if m.SectionID == "" {
+			return errors.New("microclimate section cannot be empty")
+		}
+		if microclimates[m.SectionID] {
+			return errors.New("duplicate microclimate section: " + m.SectionID)
+		}
+		microclimates[m.SectionID] = true
+		if err := m.ClimateOffset().Validate(); err != nil {
+			return fmt.Errorf("microclimate %s: %w", m.SectionID, err)
+		}
+	}
+	sensorIDs := map[string]bool{}
+	for _, sensor := range c.Sensors {
+		built, err := sensor.Sensor()
+		if err != nil {
+			return err
+		}
+		if c.StrictSensorFilters && !built.Filter.Empty() {
+			if err := built.Filter.Check(plants); err != nil {
+				return fmt.Errorf("sensor %s: %w", sensor.ID, err)
+			}
+		}
+		if sensorIDs[sensor.ID] {
+			return errors.New("duplicate sensor ID: " + sensor.ID)
+		}
+		sensorIDs[sensor.ID] = true
+	}
+	if err := c.validateSensorTemplates(); err != nil {
+		return err
+	}
+	if err := c.validatePositions(); err != nil {
+		return err
+	}
+	if err := c.validateZones(); err != nil {
+		return err
+	}
+	if err := c.validateRotations(); err != nil {
+		return err
+	}
+	if err := c.validateHVAC(); err != nil {
+		return err
+	}
+	if c.Disease != nil {
+		if err := c.DiseaseConfig().Validate(); err != nil {
+			return err
+		}
+	}
+	if err := c.PruneEffect().Validate(); err != nil {
+		return err
+	}
+	if err := c.validateSalinity(); err != nil {
+		return err
+	}
+	if c.SoilTemperature != nil {
+		if err := c.SoilTemperatureConfig().Validate(); err != nil {
+			return err
+		}
+	}
+	if c.WaterTemperature != nil {
+		if err := c.WaterTemperatureConfig().Validate(); err != nil {
+			return err
+		}
+	}
+	if c.LightCompetition != nil {
+		if err := c.LightCompetitionConfig().Validate(); err != nil {
+			return err
+		}
+		if !c.DayCycle().Enabled() {
+			return errors.New("light competition requires a day cycle")
+		}
+	}
+	if err := c.validateChaos(); err != nil {
+		return err
+	}
+	if d := c.DeadPlants; d != nil {
+		if d.Retention != KeepDeadPlants && d.Retention != RemoveDeadPlants {
+			return errors.New("dead plant retention must be keep or remove: " + d.Retention)
+		}
+		if d.After < 0 {
+			return errors.New("dead plant removal delay cannot be negative")
+		}
+		if d.After > 0 && d.Retention != RemoveDeadPlants {
+			return errors.New("dead plant removal delay needs the remove retention")
+		}
+	}
+	if c.Journal != nil && c.Journal.Entries < 0 {
+		return errors.New("journal entries cannot be negative")
+	}
+	if err := c.validateSchedules(); err != nil {
+		return err
+	}
+	if c.Tank != nil {
+		if _, err := watering.NewWaterSupply(c.Tank.SupplyConfig()); err != nil {
+			return err
+		}
+	}
+	if c.Watering != nil {
+		if err := c.Watering.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Prices != nil && (c.Prices.Water < 0 || c.Prices.Energy < 0) {
+		return errors.New("prices cannot be negative")
+	}
+	if c.MQTT != nil {
+		if err := c.MQTT.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Server != nil {
+		if err := c.Server.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Influx != nil {
+		if err := c.Influx.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Tracing != nil {
+		if err := c.Tracing.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Export != nil {
+		if err := c.Export.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Alerts != nil {
+		if err := c.Alerts.validate(); err != nil {
+			return err
+		}
+	}
+	return c.validateTimeline()
+}
+
+func (c *GreenhouseConfig) validateHVAC() error {
+	if c.HVAC == nil {
+		return nil
+	}
+	if err := c.HVACConfig().Validate(); err != nil {
+		return err
+	}
+	if c.HVAC.Thermostat == nil {
+		return nil
+	}
+	id := c.HVAC.Thermostat.SensorID
+	for _, sensor := range c.Sensors {
+		if sensor.ID == id {
+			if sensor.Type != models.Temperature {
+				return errors.New("thermostat sensor is not a temperature sensor: " + id)
+			}
+			return nil
+		}
+	}
+	return errors.New("unknown thermostat sensor: " + id)
+}
+
+// SlogLevel parses LogLevel. Returns an error if it is not empty, debug,
+// info, warn or error.
+func (c *GreenhouseConfig) SlogLevel() (slog.Level, error) {
+	switch c.LogLevel {
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, errors.New("unknown log level: " + c.LogLevel)
+}
+
+// BuildPlants creates the configured plants. Plants may refer to the
+// configured plant types or to the built-in presets; a configured type
+// replaces the preset of the same name. Every plant takes the soil of its
+// section. Returns an error if a plant type, section or plant is invalid,
+// duplicated or refers to an unknown type.
+func (c *GreenhouseConfig) BuildPlants() ([]*models.Plant, error) {
+	types, err := c.plantTypes()
+	if err != nil {
+		return nil, err
+	}
+	soils, err := c.soils()
+	if err != nil {
+		return nil, err
+	}
+	ids := map[string]bool{}
+	var plants []*models.Plant
+	for _, p := range c.Plants {
+		if ids[p.ID] {
+			return nil, errors.New("duplicate plant ID: " + p.ID)
+		}
+		ids[p.ID] = true
+		plant, err := p.build(types, soils)
+		if err != nil {
+			return nil, err
+		}
+		plants = append(plants, plant)
+	}
+	return plants, nil
+}
+
+// plantTypes returns the preset plant types merged with the configured ones,
+// by name, with the defaults of models.PlantType.Normalize filled in.
+// Returns an error if a configured type is unnamed, duplicated or invalid.
+func (c *GreenhouseConfig) plantTypes() (map[string]models.PlantType, error) {
+	types := map[string]models.PlantType{}
+	for _, preset := range models.PresetPlantTypes() {
+		types[preset.Name] = preset
+	}
+	configured := map[string]bool{}
+	for _, t := range c.PlantTypes {
+		if t.Name == "" {
+			return nil, errors.New("plant type must have a name")
+		}
+		if configured[t.Name] {
+			return nil, errors.New("duplicate plant type: " + t.Name)
+		}
+		configured[t.Name] = true
+		plantType := t.PlantType()
+		plantType.Normalize()
+		if err := plantType.Validate(); err != nil {
+			return nil, fmt.Errorf("plant type %s: %w", t.Name, err)
+		}
+		types[t.Name] = plantType
+	}
+	return types, nil
+}
+
+// PlantTypeWarnings returns a warning for every default that
+// models.PlantType.Normalize fills in the configured plant types, in the
+// order they are configured. Presets have every field set.
+func (c *GreenhouseConfig) PlantTypeWarnings() []string {
+	var warnings []string
+	for _, t := range c.PlantTypes {
+		plantType := t.PlantType()
+		for _, warning := range plantType.Normalize() {
+			warnings = append(warnings, fmt.Sprintf("plant type %s: %s", t.Name, warning))
+		}
+	}
+	return warnings
+}
+
+// EnvironmentWarnings returns a warning for every reason the plant types of
+// the plants may not survive the environment, see
+// models.PlantType.CompatibleWithEnvironment, in the order the plants use
+// them. It returns nil for a config whose plant types do not build.
+func (c *GreenhouseConfig) EnvironmentWarnings() []string {
+	types, err := c.plantTypes()
+	if err != nil {
+		return nil
+	}
+	env := c.Environment.PlantProfile()
+	checked := map[string]bool{}
+	var warnings []string
+	for _, p := range c.Plants {
+		plantType, ok := types[p.Type]
+		if !ok || checked[p.Type] {
+			continue
+		}
+		checked[p.Type] = true
+		_, reasons := plantType.CompatibleWithEnvironment(env)
+		for _, reason := range reasons {
+			warnings = append(warnings, fmt.Sprintf("plant type %s may not survive: %s", p.Type, reason))
+		}
+	}
+	return warnings
+}
+
+// PlantProfile sums up the environment for
+// models.PlantType.CompatibleWithEnvironment, named after its profile. The
+// environment has a temperature when it comes from a profile or sets a
+// temperature or temperature swing; without, the greenhouse sits at 0 C,
+// which plants are not harmed by.
+func (e EnvironmentConfig) PlantProfile() models.EnvironmentProfile {
+	profile := models.EnvironmentProfile{Name: "configured", FrostChance: e.FrostChance}
+	if e.Profile != "" {
+		profile.Name = e.Profile
+	}
+	if e.Profile != "" || e.Temperature != 0 || e.TemperatureSwing != 0 {
+		temperature := e.Temperature
+		profile.Temperature = &temperature
+	}
+	return profile
+}
+
+// soils returns the soil type of every configured section that sets one, by
+// section ID. Returns an error if a section ID is empty or duplicated or its
+// soil is invalid.
+func (c *GreenhouseConfig) soils() (map[string]models.SoilType, error) {
+	soils := map[string]models.SoilType{}
+	seen := map[string]bool{}
+	for _, section := range c.Sections {
+		if section.ID == "" {
+			return nil, errors.New("section ID cannot be empty")
+		}
+		if seen[section.ID] {
+			return nil, errors.New("duplicate section ID: " + section.ID)
+		}
+		seen[section.ID] = true
+		if !section.HasSoil() {
+			continue
+		}
+		soil, err := section.SoilType()
+		if err != nil {
+			return nil, fmt.Errorf("section %s: %w", section.ID, err)
+		}
+		soils[section.ID] = soil
+	}
+	return soils, nil
+}
+
+// build creates the plant from the given plant types, in the soil of its
+// section among soils.
+func (p PlantConfig) build(types map[string]models.PlantType, soils map[string]models.SoilType) (*models.Plant, error) {
+	plantType, ok := types[p.Type]
+	if !ok {
+		return nil, fmt.Errorf("plant %s: unknown plant type: %s", p.ID, p.Type)
+	}
+	plant, err := models.NewPlant(p.ID, plantType, p.SectionID, p.InitialSaturation)
+	if err != nil {
+		return nil, fmt.Errorf("plant %s: %w", p.ID, err)
+	}
+	plant.Tags = append([]string(nil), p.Tags...)
+	plant.ExcludeFromWatering = p.ExcludeFromWatering
+	plant.Quarantined = p.Quarantined
+	if p.Position != nil {
+		plant.Position = &models.Position{X: p.Position.X, Y: p.Position.Y}
+	}
+	if soil, ok := soils[p.SectionID]; ok {
+		plant.Soil = &soil
+		if soil.Layered() {
+			plant.DeepSaturation = p.InitialSaturation
+		}
+	}
+	if p.State != nil {
+		if p.State.Health < 0 || p.State.Health > 1 {
+			return nil, fmt.Errorf("plant %s: health must be between 0.0 and 1.0", p.ID)
+		}
+		if p.State.GrowthStage < 0 || p.State.GrowthStage > 1 {
+			return nil, fmt.Errorf("plant %s: growth stage must be between 0.0 and 1.0", p.ID)
+		}
+		if p.State.DeepSaturation < 0 || p.State.DeepSaturation > 1 {
+			return nil, fmt.Errorf("plant %s: deep saturation must be between 0.0 and 1.0", p.ID)
+		}
+		if err := p.State.Disease.Validate(); err != nil {
+			return nil, fmt.Errorf("plant %s: %w", p.ID, err)
+		}
+		plant.Health = p.State.Health
+		plant.GrowthStage = p.State.GrowthStage
+		plant.Alive = p.State.Alive
+		if !plant.Alive {
+			plant.DeathCause = p.State.DeathCause
+		}
+		if plant.Soil != nil && plant.Soil.Layered() {
+			plant.DeepSaturation = p.State.DeepSaturation
+		}
+		plant.Disease = models.Disease{Stage: p.State.Disease, Ticks: p.State.DiseaseTicks}
+		for _, m := range p.State.Modifiers {
+			plant.Modifiers = append(plant.Modifiers, models.Modifier(m))
+		}
+		plant.Germination = nil
+		if g := p.State.Germination; g != nil {
+			plant.Germination = &models.Germination{Ticks: g.Ticks, Favorable: g.Favorable, Dormant: g.Dormant}
+		}
+	}
+	return plant, nil
+}
+
+// WateringSchedules converts the configured schedules, or recommends one for
+// each section when they are auto, see ScheduleList. They are validated when
+// added to a watering controller.
+func (c *GreenhouseConfig) WateringSchedules() []models.WateringSchedule {
+	if c.Schedules.Auto() {
+		// Validate checks that every section gets a schedule.
+		schedules, _ := c.autoSchedules()
+		return schedules
+	}
+	var schedules []models.WateringSchedule
+	for _, s := range c.Schedules {
+		schedules = append(schedules, s.WateringSchedule())
+	}
+	return schedules
+}
+
+// Sensor converts the config into a models.Sensor, see models.NewSensor.
+// Returns an error if the sensor is invalid.
+func (s SensorConfig) Sensor() (*models.Sensor, error) {
+	return models.NewSensor(s.ID, s.Type, s.SectionID, s.options()...)
+}
+
+// options returns the options of the sensor, all but its ID, type and
+// section.
+func (s SensorConfig) options() []models.SensorOption {
+	opts := []models.SensorOption{models.WithNoise(s.Noise), models.WithDepth(s.Depth)}
+	if s.Filter != nil {
+		opts = append(opts, models.WithPlantFilter(models.PlantFilter{Type: s.Filter.Type, Tag: s.Filter.Tag, PlantIDs: slices.Clone(s.Filter.Plants)}))
+	}
+	if s.Battery != nil {
+		level := 1.0
+		if s.Battery.Level != nil {
+			level = *s.Battery.Level
+		}
+		opts = append(opts, models.WithBattery(level, s.Battery.Drain))
+	}
+	if s.SampleInterval != 0 {
+		opts = append(opts, models.WithSampleInterval(s.SampleInterval))
+	}
+	if s.RateWindow != 0 {
+		opts = append(opts, models.WithRateWindow(s.RateWindow))
+	}
+	if s.WindowTicks != 0 {
+		opts = append(opts, models.WithWindowTicks(s.WindowTicks))
+	}
+	if s.Position != nil {
+		opts = append(opts, models.WithPosition(models.Position{X: s.Position.X, Y: s.Position.Y}))
+	}
+	return opts
+}
+
+// Random returns the root random source of the seed. The subsystems draw
+// from their own streams split off it, see package rng.
+func (c *GreenhouseConfig) Random() rng.Source {
+	return rng.New(c.Seed)
+}
+
+// DayCycle returns the configured day cycle.
+func (c *GreenhouseConfig) DayCycle() environment.DayCycle {
+	return environment.DayCycle{TicksPerDay: c.Environment.TicksPerDay}
+}
+
+// Climate returns the configured climate model, seeded with the config seed.
+func (c *GreenhouseConfig) Climate() environment.Climate {
+	e := c.Environment
+	return environment.Climate{
+		DayCycle:         c.DayCycle(),
+		Seed:             c.Seed,
+		Temperature:      e.Temperature,
+		TemperatureSwing: e.TemperatureSwing,
+		Humidity:         e.AmbientHumidity,
+		Light:            e.Light,
+		SeasonalDrift:    e.SeasonalDrift,
+		Seasons: environment.Seasons{
+			TicksPerYear:         e.TicksPerYear,
+			Phase:                e.SeasonPhase,
+			TemperatureAmplitude: e.SeasonalTemperature,
+			DayLengthAmplitude:   e.SeasonalDayLength,
+			WeatherAmplitude:     e.SeasonalWeather,
+		},
+		CloudyChance:        e.CloudyChance,
+		RainChance:          e.RainChance,
+		FrostChance:         e.FrostChance,
+		HeatWaveChance:      e.HeatWaveChance,
+		ExtremeTicks:        e.ExtremeTicks,
+		FrostTemperature:    e.FrostTemperature,
+		FrostDamage:         e.FrostDamage,
+		HeatWaveRise:        e.HeatWaveRise,
+		HeatWaveEvaporation: e.HeatWaveEvaporation,
+	}
+}
+
+// LightsConfigs converts the configured grow lights.
+func (c *GreenhouseConfig) LightsConfigs() []environment.LightsConfig {
+	var lights []environment.LightsConfig
+	for _, l := range c.Lights {
+		lights = append(lights, environment.LightsConfig{
+			SectionID:     l.SectionID,
+			Intensity:     l.Intensity,
+			EnergyPerTick: l.EnergyPerTick,
+			AfterSunset:   l.AfterSunset,
+		})
+	}
+	return lights
+}
+
+// HVACConfig returns the configured heater, vent and thermostat settings,
+// zero without an hvac section.
+func (c *GreenhouseConfig) HVACConfig() environment.HVACConfig {
+	if c.HVAC == nil {
+		return environment.HVACConfig{}
+	}
+	h := c.HVAC
+	cfg := environment.HVACConfig{
+		HeaterPower:  h.HeaterPower,
+		VentPower:    h.VentPower,
+		HeatLoss:     h.HeatLoss,
+		HeaterEnergy: h.HeaterEnergy,
+		VentEnergy:   h.VentEnergy,
+	}
+	if t := h.Thermostat; t != nil {
+		cfg.Thermostat = &environment.ThermostatConfig{SensorID: t.SensorID, Min: t.Min, Max: t.Max}
+	}
+	return cfg
+}
+
+// DiseaseConfig returns the configured disease settings, zero without a
+// disease section.
+func (c *GreenhouseConfig) DiseaseConfig() environment.DiseaseConfig {
+	if c.Disease == nil {
+		return environment.DiseaseConfig{}
+	}
+	d := c.Disease
+	return environment.DiseaseConfig{
+		Incubation:        d.Incubation,
+		HealthDecay:       d.HealthDecay,
+		TranspirationDrop: d.TranspirationDrop,
+		SpreadRate:        d.SpreadRate,
+		CureChance:        d.CureChance,
+	}
+}
+
+// WateringConfig returns the configured watering settings, zero without a
+// watering section. The settings that do not come from the section, such as
+// the tick interval and the tank, are left for the greenhouse to fill in.
+func (c *GreenhouseConfig) WateringConfig() watering.Config {
+	if c.Watering == nil {
+		return watering.Config{}
+	}
+	wateringConfig := watering.Config{
+		MaxConcurrentEvents: c.Watering.MaxConcurrentEvents,
+		MaxFlowPerTick:      c.Watering.MaxFlowPerTick,
+		CancelCooldown:      c.Watering.CancelCooldown,
+	}
+	if len(c.Watering.Methods) > 0 {
+		wateringConfig.Methods = map[models.IrrigationMethod]watering.MethodProfile{}
+		for _, m := range c.Watering.Methods {
+			wateringConfig.Methods[m.Method] = m.profile()
+		}
+	}
+	return wateringConfig
+}
+
+// SalinityConfig returns the configured salinity settings, zero without
+// them, with the concentration of the water the greenhouse waters with: that
+// of the tank when there is one, of mains water otherwise.
+func (c *GreenhouseConfig) SalinityConfig() environment.SalinityConfig {
+	if c.Salinity == nil {
+		return environment.SalinityConfig{}
+	}
+	s := c.Salinity
+	concentration := s.Concentration
+	if c.Tank != nil {
+		concentration = s.TankConcentration
+	}
+	return environment.SalinityConfig{
+		Concentration: concentration,
+		FlushAmount:   s.FlushAmount,
+		FlushRate:     s.FlushRate,
+		RainLeach:     s.RainLeach,
+		Damage:        s.Damage,
+	}
+}
+
+// SalinityLevels returns the salinity the configured sections start at, by
+// section ID, leaving out those that start at 0.
+func (c *GreenhouseConfig) SalinityLevels() map[string]float64 {
+	levels := map[string]float64{}
+	for _, section := range c.Sections {
+		if section.Salinity != 0 {
+			levels[section.ID] = section.Salinity
+		}
+	}
+	return levels
+}
+
+// validateSalinity checks the salinity settings and the salinity the
+// sections start at.
+func (c *GreenhouseConfig) validateSalinity() error {
+	if c.Salinity == nil {
+		if len(c.SalinityLevels()) > 0 {
+			return errors.New("section salinity requires salinity settings")
+		}
+		return nil
+	}
+	if c.Salinity.Concentration < 0 || c.Salinity.TankConcentration < 0 {
+		return errors.New("salinity concentrations cannot be negative")
+	}
+	if c.Salinity.TankConcentration > 0 && c.Tank == nil {
+		return errors.New("salinity tank concentration requires a tank")
+	}
+	_, err := environment.NewSalinity(c.SalinityConfig(), c.SalinityLevels())
+	return err
+}
+
+// validatePositions checks the dimensions of the sections and that the
+// plants and sensors placed in them stand within them.
+func (c *GreenhouseConfig) validatePositions() error {
+	dimensions := map[string]models.Dimensions{}
+	for _, section := range c.Sections {
+		if err := section.Dimensions().Validate(); err != nil {
+			return fmt.Errorf("section %s: %w", section.ID, err)
+		}
+		dimensions[section.ID] = section.Dimensions()
+	}
+	for _, plant := range c.Plants {
+		if plant.Position == nil {
+			continue
+		}
+		if err := dimensions[plant.SectionID].Contains(models.Position(*plant.Position)); err != nil {
+			return fmt.Errorf("plant %s: %w", plant.ID, err)
+		}
+	}
+	for _, sensor := range c.Sensors {
+		if sensor.Position == nil {
+			continue
+		}
+		if err := dimensions[sensor.SectionID].Contains(models.Position(*sensor.Position)); err != nil {
+			return fmt.Errorf("sensor %s: %w", sensor.ID, err)
+		}
+	}
+	return nil
+}
+
+// validateChaos checks the chaos probabilities and jitter.
+func (c *GreenhouseConfig) validateChaos() error {
+	if c.Chaos == nil {
+		return nil
+	}
+	for _, p := range []float64{c.Chaos.SensorFailure, c.Chaos.WateringDrop, c.Chaos.Infection, c.Chaos.TickJitter} {
+		if p < 0 || p > 1 {
+			return errors.New("chaos probabilities must be between 0.0 and 1.0")
+		}
+	}
+	if c.Chaos.MaxJitter < 0 {
+		return errors.New("chaos max jitter cannot be negative")
+	}
+	if c.Chaos.TickJitter > 0 && c.Chaos.MaxJitter == 0 {
+		return errors.New("chaos tick jitter requires a max jitter")
+	}
+	if c.Chaos.Infection > 0 && c.Disease == nil {
+		return errors.New("chaos infections require disease settings")
+	}
+	return nil
+}
+
+// ChaosEnabled reports whether faults are injected, see ChaosConfig.
+func (c *GreenhouseConfig) ChaosEnabled() bool {
+	return c.Chaos != nil && c.Chaos.Enabled
+}
+
+// SoilTemperatureConfig returns the configured soil temperature settings,
+// zero without them.
+func (c *GreenhouseConfig) SoilTemperatureConfig() environment.SoilTemperatureConfig {
+	if c.SoilTemperature == nil {
+		return environment.SoilTemperatureConfig{}
+	}
+	return environment.SoilTemperatureConfig{
+		Inertia:          c.SoilTemperature.Inertia,
+		WaterTemperature: c.SoilTemperature.WaterTemperature,
+		WaterExchange:    c.SoilTemperature.WaterExchange,
+	}
+}
+
+// WaterTemperatureConfig returns the configured water temperature settings,
+// the water at the air temperature without them, with the default shock
+// damage where unset.
+func (c *GreenhouseConfig) WaterTemperatureConfig() environment.WaterTemperatureConfig {
+	if c.WaterTemperature == nil {
+		return environment.WaterTemperatureConfig{ShockDamage: 0.01}
+	}
+	return environment.WaterTemperatureConfig{
+		Initial:     c.WaterTemperature.Temperature,
+		Inertia:     c.WaterTemperature.Inertia,
+		ShockDamage: cmp.Or(c.WaterTemperature.ShockDamage, 0.01),
+	}
+}
+
+// LightCompetitionConfig returns the configured light competition settings,
+// zero without them.
+func (c *GreenhouseConfig) LightCompetitionConfig() models.LightCompetition {
+	if c.LightCompetition == nil {
+		return models.LightCompetition{}
+	}
+	return models.LightCompetition{Coefficient: c.LightCompetition.Coefficient, Floor: c.LightCompetition.Floor}
+}
+
+// ClimateOffset converts the config into an environment.ClimateOffset.
+func (m MicroclimateConfig) ClimateOffset() environment.ClimateOffset {
+	return environment.ClimateOffset{Temperature: m.Temperature, Humidity: m.Humidity, Light: m.Light}
+}
+
+// ClimateOffsets returns the configured microclimates, by section ID.
+func (c *GreenhouseConfig) ClimateOffsets() map[string]environment.ClimateOffset {
+	offsets := map[string]environment.ClimateOffset{}
+	for _, m := range c.Microclimates {
+		offsets[m.SectionID] = m.ClimateOffset()
+	}
+	return offsets
+}
+
+// Germinates reports whether one of the configured plant types germinates.
+// None of the presets does.
+func (c *GreenhouseConfig) Germinates() bool {
+	return slices.ContainsFunc(c.PlantTypes, func(t PlantTypeConfig) bool { return t.GerminationTicks > 0 })
+}
+
+// PruneEffect returns the configured effect of pruning,
+// models.DefaultPruneEffect without a pruning section.
+func (c *GreenhouseConfig) PruneEffect() models.PruneEffect {
+	if c.Pruning == nil {
+		return models.DefaultPruneEffect
+	}
+	return models.PruneEffect(*c.Pruning)
+}
+
+// TickOverrunPolicy returns the configured overrun policy, engine.OverrunSkip
+// when none is.
+func (c *GreenhouseConfig) TickOverrunPolicy() engine.OverrunPolicy {
+	if c.OverrunPolicy == "" {
+		return engine.OverrunSkip
+	}
+	return engine.OverrunPolicy(c.OverrunPolicy)
+}
+
+// DeadSectionPolicy returns what soil moisture sensors read in a section
+// whose plants are all dead, sensors.DeadSectionHold when nothing is
+// configured.
+func (c *GreenhouseConfig) DeadSectionPolicy() sensors.DeadSectionPolicy {
+	if c.DeadSections == "" {
+		return sensors.DeadSectionHold
+	}
+	return sensors.DeadSectionPolicy(c.DeadSections)
+}
+
+// SensorHistoryLookup returns which sample reading a sensor as of a past tick
+// returns, sensors.HistoryLookupNearestBefore when nothing is configured.
+func (c *GreenhouseConfig) SensorHistoryLookup() sensors.HistoryLookup {
+	if c.HistoryLookup == "" {
+		return sensors.HistoryLookupNearestBefore
+	}
+	return sensors.HistoryLookup(c.HistoryLookup)
+}
+
+// RemovesDeadPlants reports whether dead plants are removed, and how many
+// ticks after they died.
+func (c *GreenhouseConfig) RemovesDeadPlants() (bool, int) {
+	if c.DeadPlants == nil || c.DeadPlants.Retention != RemoveDeadPlants {
+		return false, 0
+	}
+	return true, c.DeadPlants.After
+}
+
+// JournalEntries returns the number of entries kept in the journal of each
+// plant, 0 without a journal.
+func (c *GreenhouseConfig) JournalEntries() int {
+	if c.Journal == nil {
+		return 0
+	}
+	return cmp.Or(c.Journal.Entries, DefaultJournalEntries)
+}
+
+// CO2 returns the configured CO2 model settings.
+func (c *GreenhouseConfig) CO2() environment.CO2Config {
+	e := c.Environment
+	return environment.CO2Config{
+		Baseline:       e.CO2Baseline,
+		Uptake:         e.CO2Uptake,
+		Ventilation:    e.CO2Ventilation,
+		BoostThreshold: e.CO2BoostThreshold,
+		GrowthBoost:    e.CO2GrowthBoost,
+	}
+}
+
+// PlantType converts the config into a models.PlantType.
+func (t PlantTypeConfig) PlantType() models.PlantType {
+	plantType := models.PlantType{
+		Name:                  t.Name,
+		OptimalSaturation:     t.OptimalSaturation,
+		MinSaturation:         t.MinSaturation,
+		MaxSaturation:         t.MaxSaturation,
+		BaseGrowthRate:        t.BaseGrowthRate,
+		SaturationDepletion:   t.SaturationDepletion,
+		HealthDegradationRate: t.HealthDegradationRate,
+		HealthEnhancementRate: t.HealthEnhancementRate,
+		FrostTolerance:        t.FrostTolerance,
+		RootDepth:             t.RootDepth,
+		MinTemperature:        t.MinTemperature,
+		MaxTemperature:        t.MaxTemperature,
+
+		GerminationTicks:              t.GerminationTicks,
+		GerminationMinSaturation:      t.GerminationMinSaturation,
+		GerminationMaxSaturation:      t.GerminationMaxSaturation,
+		GerminationFailure:            t.GerminationFailure,
+		GerminationMinSoilTemperature: t.GerminationMinSoilTemperature,
+
+		SalinityTolerance:     t.SalinityTolerance,
+		ThermalShockTolerance: t.ThermalShockTolerance,
+	}
+	if t.Growth != nil {
+		plantType.Growth = t.Growth.params()
+	}
+	return plantType
+}
+
+// HasSoil reports whether the config sets the soil of the section, rather
+// than only its salinity.
+func (s SectionConfig) HasSoil() bool {
+	return s.Soil != "" || s.Retention != 0 || s.Drainage != 0 || s.Percolation != 0
+}
+
+// Dimensions returns the dimensions of the section, zero without them.
+func (s SectionConfig) Dimensions() models.Dimensions {
+	return models.Dimensions{Width: s.Width, Depth: s.Depth}
+}
+
+// SoilType converts the config into a models.SoilType, starting from the
+// preset it names. A custom soil is named Custom. Returns an error if the
+// preset is unknown or the soil type is invalid.
+func (s SectionConfig) SoilType() (models.SoilType, error) {
+	soil := models.SoilType{Name: "Custom"}
+	if s.Soil != "" {
+		preset, ok := models.PresetSoilType(s.Soil)
+		if !ok {
+			return models.SoilType{}, errors.New("unknown soil type: " + s.Soil)
+		}
+		soil = preset
+	}
+	if s.Retention != 0 {
+		soil.Retention = s.Retention
+	}
+	if s.Drainage != 0 {
+		soil.Drainage = s.Drainage
+	}
+	soil.Percolation = s.Percolation
+	if err := soil.Validate(); err != nil {
+		return models.SoilType{}, err
+	}
+	return soil, nil
+}
+
+// WateringSchedule converts the config into a models.WateringSchedule.
+func (s ScheduleConfig) WateringSchedule() models.WateringSchedule {
+	schedule := models.WateringSchedule{
+		ID:               s.ID,
+		SectionID:        s.SectionID,
+		ZoneID:           s.ZoneID,
+		Tag:              s.Tag,
+		PlantType:        s.PlantType,
+		TargetSaturation: s.TargetSaturation,
+		CheckInterval:    s.CheckInterval,
+		WaterAmount:      s.WaterAmount,
+		Duration:         time.Duration(s.Duration),
+		Enabled:          s.Enabled,
+		Method:           s.Method,
+		Distribution:     s.Distribution,
+	}
+	if s.Proportional != nil {
+		schedule.Proportional = &models.ProportionalControl{
+			Gain:         s.Proportional.Gain,
+			IntegralGain: s.Proportional.IntegralGain,
+			MinAmount:    s.Proportional.MinAmount,
+			MaxAmount:    s.Proportional.MaxAmount,
+		}
+	}
+	for _, w := range s.AllowedWindows {
+		schedule.AllowedWindows = append(schedule.AllowedWindows, models.TimeWindow{Start: w.Start, End: w.End})
+	}
+	return schedule
+}
+
+// SupplyConfig converts the config into a watering.SupplyConfig.
+func (t TankConfig) SupplyConfig() watering.SupplyConfig {
+	return watering.SupplyConfig{
+		Capacity:          t.Capacity,
+		InitialLevel:      t.InitialLevel,
+		RefillPerTick:     t.RefillPerTick,
+		LowWaterThreshold: t.LowWaterThreshold,
+		ShortagePolicy:    t.ShortagePolicy,
+	}
+}
@@ -0,0 +1,1509 @@
+package config
+
+import (
+	"fmt"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/environment"
+	"greenhouse-simulator/internal/models"
+	"greenhouse-simulator/internal/sensors"
+	"greenhouse-simulator/internal/watering"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig_FormatsAreEquivalent(t *testing.T) {
+	fromYAML, err := LoadConfig("testdata/greenhouse.yaml")
+	if err != nil {
+		t.Fatalf("failed to load yaml config: %v", err)
+	}
+	fromJSON, err := LoadConfig("testdata/greenhouse.json")
+	if err != nil {
+		t.Fatalf("failed to load json config: %v", err)
+	}
+
+	if !reflect.DeepEqual(fromYAML, fromJSON) {
+		t.Errorf("expected identical configs\nyaml: %+v\njson: %+v", fromYAML, fromJSON)
+	}
+	if time.Duration(fromYAML.Schedules[0].Duration) != 8*time.Second {
+		t.Errorf("expected schedule duration 8s, got %v", time.Duration(fromYAML.Schedules[0].Duration))
+	}
+}
+
+func TestSaveConfig_RoundTrip(t *testing.T) {
+	original, err := LoadConfig("testdata/greenhouse.yaml")
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	for _, name := range []string{"saved.json", "saved.yaml", "saved.yml"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := SaveConfig(original, path); err != nil {
+				t.Fatalf("failed to save config: %v", err)
+			}
+			loaded, err := LoadConfig(path)
+			if err != nil {
+				t.Fatalf("failed to reload config: %v", err)
+			}
+			if !reflect.DeepEqual(loaded, original) {
+				t.Errorf("expected reloaded config to match\nwant: %+v\ngot:  %+v", original, loaded)
+			}
+		})
+	}
+}
+
+func TestLoad_ValidationMatchesAcrossFormats(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		json     string
+		errorMsg string
+	}{
+		{
+			"missing tick interval",
+			`plants: []`,
+			`{"plants": []}`,
+			"tick interval must be positive",
+		},
+		{
+			"negative warm-up",
+			"tick_interval: 1s\nwarmup_ticks: -10\nplants: []",
+			`{"tick_interval": "1s", "warmup_ticks": -10, "plants": []}`,
+			"warm-up ticks cannot be negative",
+		},
+		{
+			"negative watering dedup window",
+			"tick_interval: 1s\nwatering_dedup_window: -1\nplants: []",
+			`{"tick_interval": "1s", "watering_dedup_window": -1, "plants": []}`,
+			"watering dedup window cannot be negative",
+		},
+		{
+			"negative max concurrent watering events",
+			"tick_interval: 1s\nwatering: {max_concurrent_events: -1}\nplants: []",
+			`{"tick_interval": "1s", "watering": {"max_concurrent_events": -1}, "plants": []}`,
+			"max concurrent watering events cannot be negative",
+		},
+		{
+			"negative max watering flow",
+			"tick_interval: 1s\nwatering: {max_flow_per_tick: -0.5}\nplants: []",
+			`{"tick_interval": "1s", "watering": {"max_flow_per_tick": -0.5}, "plants": []}`,
+			"max watering flow per tick cannot be negative",
+		},
+		{
+			"negative watering cancel cooldown",
+			"tick_interval: 1s\nwatering: {cancel_cooldown: -1}\nplants: []",
+			`{"tick_interval": "1s", "watering": {"cancel_cooldown": -1}, "plants": []}`,
+			"watering cancel cooldown cannot be negative",
+		},
+		{
+			"negative idle pause ticks",
+			"tick_interval: 1s\nidle_pause_ticks: -1\nplants: []",
+			`{"tick_interval": "1s", "idle_pause_ticks": -1, "plants": []}`,
+			"idle pause ticks cannot be negative",
+		},
+		{
+			"auto schedules for thirsty plants",
+			"tick_interval: 1s\nplant_types: [{extends: Mint, saturation_depletion: 0.2}]\nplants: [{id: m1, type: Mint, section: s1, initial_saturation: 0.7}]\nschedules: auto",
+			`{"tick_interval": "1s", "plant_types": [{"extends": "Mint", "saturation_depletion": 0.2}], "plants": [{"id": "m1", "type": "Mint", "section": "s1", "initial_saturation": 0.7}], "schedules": "auto"}`,
+			"section s1: plant type depletes its soil too fast to recommend a schedule: Mint",
+		},
+		{
+			"frozen soil temperature",
+			"tick_interval: 1s\nsoil_temperature: {inertia: 1}\nplants: []",
+			`{"tick_interval": "1s", "soil_temperature": {"inertia": 1}, "plants": []}`,
+			"soil temperature inertia must be between 0.0 and below 1.0",
+		},
+		{
+			"negative thermal shock damage",
+			"tick_interval: 1s\nwater_temperature: {temperature: 8, shock_damage: -0.1}\nplants: []",
+			`{"tick_interval": "1s", "water_temperature": {"temperature": 8, "shock_damage": -0.1}, "plants": []}`,
+			"water temperature shock damage cannot be negative",
+		},
+		{
+			"negative thermal shock tolerance",
+			"tick_interval: 1s\nplant_types: [{extends: Mint, thermal_shock_tolerance: -5}]\nplants: []",
+			`{"tick_interval": "1s", "plant_types": [{"extends": "Mint", "thermal_shock_tolerance": -5}], "plants": []}`,
+			"plant type Mint: plant type thermal shock tolerance cannot be negative",
+		},
+		{
+			"light competition without a day cycle",
+			"tick_interval: 1s\nlight_competition: {coefficient: 0.5}\nplants: []",
+			`{"tick_interval": "1s", "light_competition": {"coefficient": 0.5}, "plants": []}`,
+			"light competition requires a day cycle",
+		},
+		{
+			"light competition floor above 1",
+			"tick_interval: 1s\nenvironment: {ticks_per_day: 24}\nlight_competition: {coefficient: 0.5, floor: 1.5}\nplants: []",
+			`{"tick_interval": "1s", "environment": {"ticks_per_day": 24}, "light_competition": {"coefficient": 0.5, "floor": 1.5}, "plants": []}`,
+			"light competition floor must be between 0.0 and 1.0",
+		},
+		{
+			"plant outside its section",
+			"tick_interval: 1s\nsections: [{id: s1, width: 10, depth: 5}]\nplants: [{id: m1, type: Mint, section: s1, initial_saturation: 0.7, position: {x: 12, y: 1}}]",
+			`{"tick_interval": "1s", "sections": [{"id": "s1", "width": 10, "depth": 5}], "plants": [{"id": "m1", "type": "Mint", "section": "s1", "initial_saturation": 0.7, "position": {"x": 12, "y": 1}}]}`,
+			"plant m1: position (12, 1) is outside the 10 by 5 section",
+		},
+		{
+			"sensor at a negative position",
+			"tick_interval: 1s\nsensors: [{id: t1, type: temperature, section: s1, position: {x: -1, y: 1}}]\nplants: []",
+			`{"tick_interval": "1s", "sensors": [{"id": "t1", "type": "temperature", "section": "s1", "position": {"x": -1, "y": 1}}], "plants": []}`,
+			"sensor t1: position (-1, 1) cannot be negative",
+		},
+		{
+			"sensor template neither per section nor per zone",
+			"tick_interval: 1s\nsensor_templates: [{template: {type: light}, id_pattern: 'light-{n}'}]\nplants: []",
+			`{"tick_interval": "1s", "sensor_templates": [{"template": {"type": "light"}, "id_pattern": "light-{n}"}], "plants": []}`,
+			"sensor template must be either per_section or per_zone: light-{n}",
+		},
+		{
+			"sensor template with a section",
+			"tick_interval: 1s\nsensor_templates: [{template: {type: light, section: s1}, per_section: true, id_pattern: 'light-{n}'}]\nplants: []",
+			`{"tick_interval": "1s", "sensor_templates": [{"template": {"type": "light", "section": "s1"}, "per_section": true, "id_pattern": "light-{n}"}], "plants": []}`,
+			"sensor template cannot have an id or a section: light-{n}",
+		},
+		{
+			"sensor template with an unknown placeholder",
+			"tick_interval: 1s\nsensor_templates: [{template: {type: light}, per_section: true, id_pattern: 'light-{row}'}]\nplants: []",
+			`{"tick_interval": "1s", "sensor_templates": [{"template": {"type": "light"}, "per_section": true, "id_pattern": "light-{row}"}], "plants": []}`,
+			"sensor id pattern has an unknown placeholder {row}: light-{row}",
+		},
+		{
+			"sensor template giving duplicate IDs",
+			"tick_interval: 1s\nsensors: [{id: t1, type: temperature, section: s1}, {id: t2, type: temperature, section: s2}]\nsensor_templates: [{template: {type: light}, per_section: true, id_pattern: light}]\nplants: []",
+			`{"tick_interval": "1s", "sensors": [{"id": "t1", "type": "temperature", "section": "s1"}, {"id": "t2", "type": "temperature", "section": "s2"}], "sensor_templates": [{"template": {"type": "light"}, "per_section": true, "id_pattern": "light"}], "plants": []}`,
+			"sensor id pattern light gives a duplicate sensor ID: light",
+		},
+		{
+			"section with a width only",
+			"tick_interval: 1s\nsections: [{id: s1, width: 10}]\nplants: []",
+			`{"tick_interval": "1s", "sections": [{"id": "s1", "width": 10}], "plants": []}`,
+			"section s1: section dimensions need both a width and a depth",
+		},
+		{
+			"chaos probability above 1",
+			"tick_interval: 1s\nchaos: {enabled: true, sensor_failure: 1.5}\nplants: []",
+			`{"tick_interval": "1s", "chaos": {"enabled": true, "sensor_failure": 1.5}, "plants": []}`,
+			"chaos probabilities must be between 0.0 and 1.0",
+		},
+		{
+			"chaos tick jitter without a max jitter",
+			"tick_interval: 1s\nchaos: {enabled: true, tick_jitter: 0.1}\nplants: []",
+			`{"tick_interval": "1s", "chaos": {"enabled": true, "tick_jitter": 0.1}, "plants": []}`,
+			"chaos tick jitter requires a max jitter",
+		},
+		{
+			"chaos infections without disease settings",
+			"tick_interval: 1s\nchaos: {enabled: true, infection: 0.1}\nplants: []",
+			`{"tick_interval": "1s", "chaos": {"enabled": true, "infection": 0.1}, "plants": []}`,
+			"chaos infections require disease settings",
+		},
+		{
+			"rotation with an unknown plant type",
+			"tick_interval: 1s\nrotations: [{section: s1, crops: [{type: Fern, count: 2, initial_saturation: 0.5}]}]\nplants: []",
+			`{"tick_interval": "1s", "rotations": [{"section": "s1", "crops": [{"type": "Fern", "count": 2, "initial_saturation": 0.5}]}], "plants": []}`,
+			"rotation of section s1: unknown plant type: Fern",
+		},
+		{
+			"rotation with an unknown overlap",
+			"tick_interval: 1s\nrotations: [{section: s1, overlap: swap, crops: [{type: Basil, count: 2, initial_saturation: 0.5}]}]\nplants: []",
+			`{"tick_interval": "1s", "rotations": [{"section": "s1", "overlap": "swap", "crops": [{"type": "Basil", "count": 2, "initial_saturation": 0.5}]}], "plants": []}`,
+			"rotation overlap must be delay or interplant: swap",
+		},
+		{
+			"rotation crop with a tick after harvest",
+			"tick_interval: 1s\nrotations: [{section: s1, crops: [{type: Basil, count: 2, tick: 5, after_harvest: true, initial_saturation: 0.5}]}]\nplants: []",
+			`{"tick_interval": "1s", "rotations": [{"section": "s1", "crops": [{"type": "Basil", "count": 2, "tick": 5, "after_harvest": true, "initial_saturation": 0.5}]}], "plants": []}`,
+			"rotation of section s1: crop cannot have both a tick and after_harvest",
+		},
+		{
+			"growth thresholds out of order",
+			"tick_interval: 1s\nplant_types:\n  - {extends: Basil, growth: {min_health: 0.6, slow_health: 0.4, slow_factor: 1.35, optimal_bonus_factor: 1.25, optimal_tolerance: 0.15}}",
+			`{"tick_interval": "1s", "plant_types": [{"extends": "Basil", "growth": {"min_health": 0.6, "slow_health": 0.4, "slow_factor": 1.35, "optimal_bonus_factor": 1.25, "optimal_tolerance": 0.15}}]}`,
+			"plant type Basil: plant type growth health thresholds must be between 0.0 and 1.0, the minimum not above the slow one",
+		},
+		{
+			"unknown plant type",
+			"tick_interval: 1s\nplants:\n  - {id: p1, type: Fern, section: s1, initial_saturation: 0.5}",
+			`{"tick_interval": "1s", "plants": [{"id": "p1", "type": "Fern", "section": "s1", "initial_saturation": 0.5}]}`,
+			"plant p1: unknown plant type: Fern",
+		},
+		{
+			"invalid tank",
+			"tick_interval: 1s\ntank: {capacity: 0, initial_level: 0}",
+			`{"tick_interval": "1s", "tank": {"capacity": 0, "initial_level": 0}}`,
+			"water supply capacity must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, yamlErr := Load(strings.NewReader(tt.yaml), FormatYAML)
+			_, jsonErr := Load(strings.NewReader(tt.json), FormatJSON)
+			if yamlErr == nil || jsonErr == nil {
+				t.Fatalf("expected errors from both formats, got yaml=%v json=%v", yamlErr, jsonErr)
+			}
+			if yamlErr.Error() != tt.errorMsg || jsonErr.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got yaml='%s' json='%s'", tt.errorMsg, yamlErr, jsonErr)
+			}
+		})
+	}
+}
+
+func TestLoad_RejectsUnknownFields(t *testing.T) {
+	if _, err := Load(strings.NewReader("tick_interval: 1s\ntick_rate: 2"), FormatYAML); err == nil {
+		t.Error("expected error for an unknown yaml field, got nil")
+	}
+	if _, err := Load(strings.NewReader(`{"tick_interval": "1s", "tick_rate": 2}`), FormatJSON); err == nil {
+		t.Error("expected error for an unknown json field, got nil")
+	}
+}
+
+func TestLoad_SchemaVersions(t *testing.T) {
+	state := "tick_interval: 1s\nsections: [{id: s1, soil: Loam, percolation: 0.05}]\n" +
+		"plant_types: [{name: Fern, optimal_saturation: 0.6, min_saturation: 0.3, max_saturation: 0.8}]\n" +
+		"plants:\n  - {id: p1, type: Fern, section: s1, initial_saturation: 0.4, state: {health: 1, growth_stage: 0.2, alive: true%s}}\n"
+	tests := []struct {
+		name     string
+		yaml     string
+		deep     float64
+		errorMsg string
+	}{
+		{"unversioned deep layer starts at the initial saturation", fmt.Sprintf(state, ""), 0.4, ""},
+		{"version 1 keeps a written deep layer", "schema_version: 1\n" + fmt.Sprintf(state, ", deep_saturation: 0.1"), 0.1, ""},
+		{"current version is not migrated", "schema_version: 2\n" + fmt.Sprintf(state, ", deep_saturation: 0"), 0, ""},
+		{"current version reads a missing deep layer as dry", "schema_version: 2\n" + fmt.Sprintf(state, ""), 0, ""},
+		{"newer version", "schema_version: 3\n" + fmt.Sprintf(state, ""), 0, "config schema version 3 is newer than the supported version 2"},
+		{"negative version", "schema_version: -1\n" + fmt.Sprintf(state, ""), 0, "config schema version must be positive: -1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load(strings.NewReader(tt.yaml), FormatYAML)
+			if tt.errorMsg != "" {
+				if err == nil || err.Error() != tt.errorMsg {
+					t.Fatalf("expected error '%s', got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cfg.SchemaVersion != SchemaVersion {
+				t.Errorf("expected schema version %d, got %d", SchemaVersion, cfg.SchemaVersion)
+			}
+			if deep := cfg.Plants[0].State.DeepSaturation; deep != tt.deep {
+				t.Errorf("expected deep saturation %v, got %v", tt.deep, deep)
+			}
+		})
+	}
+}
+
+func TestLoad_MigratesJSON(t *testing.T) {
+	cfg, err := Load(strings.NewReader(`{"tick_interval": "1s", "plants": [{"id": "p1", "type": "Fern", "section": "s1", "initial_saturation": 0.4, "state": {"health": 1, "growth_stage": 0.2, "alive": true}}],
+		"plant_types": [{"name": "Fern", "optimal_saturation": 0.6, "min_saturation": 0.3, "max_saturation": 0.8}]}`), FormatJSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.SchemaVersion != SchemaVersion || cfg.Plants[0].State.DeepSaturation != 0.4 || time.Duration(cfg.TickInterval) != time.Second {
+		t.Errorf("expected the json config migrated to version %d, got %+v", SchemaVersion, cfg)
+	}
+}
+
+func TestLoad_MigratingKeepsLargeIntegers(t *testing.T) {
+	// 2^53 + 1 is the first integer a float64 cannot hold.
+	const seed = 9007199254740993
+	tests := []struct {
+		name   string
+		format Format
+		data   string
+	}{
+		{"json", FormatJSON, `{"tick_interval": "1s", "seed": 9007199254740993}`},
+		{"yaml", FormatYAML, "tick_interval: 1s\nseed: 9007199254740993\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load(strings.NewReader(tt.data), tt.format)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cfg.SchemaVersion != SchemaVersion || cfg.Seed != seed {
+				t.Errorf("expected seed %d migrated to version %d, got seed %d at version %d", int64(seed), SchemaVersion, cfg.Seed, cfg.SchemaVersion)
+			}
+		})
+	}
+
+	if _, err := Load(strings.NewReader(`{"tick_interval": "1s"} {}`), FormatJSON); err == nil {
+		t.Error("expected an error for data after the config")
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected Format
+		wantErr  bool
+	}{
+		{"greenhouse.json", FormatJSON, false},
+		{"greenhouse.YAML", FormatYAML, false},
+		{"dir/greenhouse.yml", FormatYAML, false},
+		{"greenhouse.toml", "", true},
+	}
+
+	for _, tt := range tests {
+		format, err := FormatFromPath(tt.path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error=%v, got %v", tt.path, tt.wantErr, err)
+		}
+		if format != tt.expected {
+			t.Errorf("%s: expected format %q, got %q", tt.path, tt.expected, format)
+		}
+	}
+}
+
+func TestDefault_IsValid(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Errorf("expected the default config to be valid, got %v", err)
+	}
+}
+
+func TestLoad_PlantTypePresets(t *testing.T) {
+	tomato, _ := models.PresetPlantType("Tomato")
+	roma := tomato
+	roma.Name = "Roma"
+	roma.BaseGrowthRate = 0.07
+	fastTomato := tomato
+	fastTomato.BaseGrowthRate = 0.09
+	hardy := tomato
+	hardy.Name = "Hardy"
+	hardy.MinTemperature = 4
+	tuned := tomato
+	tuned.Name = "Tuned"
+	tuned.Growth = models.GrowthParams{MinHealth: 0.1, SlowHealth: 0.6, SlowFactor: 2, OptimalBonusFactor: 1.5, OptimalTolerance: 0.1}
+	slow := tomato
+	slow.Name = "Slow"
+	slow.Growth = models.DefaultGrowthParams
+	slow.Growth.SlowFactor = 2
+
+	tests := []struct {
+		name     string
+		yaml     string
+		json     string
+		expected map[string]models.PlantType
+	}{
+		{
+			"extend a preset under a new name",
+			"tick_interval: 1s\nplant_types:\n  - {name: Roma, extends: Tomato, base_growth_rate: 0.07}\nplants:\n  - {id: p1, type: Roma, section: s1, initial_saturation: 0.5}\n  - {id: p2, type: Tomato, section: s1, initial_saturation: 0.5}",
+			`{"tick_interval": "1s", "plant_types": [{"name": "Roma", "extends": "Tomato", "base_growth_rate": 0.07}], "plants": [{"id": "p1", "type": "Roma", "section": "s1", "initial_saturation": 0.5}, {"id": "p2", "type": "Tomato", "section": "s1", "initial_saturation": 0.5}]}`,
+			map[string]models.PlantType{"p1": roma, "p2": tomato},
+		},
+		{
+			"override a single field of a preset",
+			"tick_interval: 1s\nplant_types:\n  - {extends: Tomato, base_growth_rate: 0.09}\nplants:\n  - {id: p1, type: Tomato, section: s1, initial_saturation: 0.5}",
+			`{"tick_interval": "1s", "plant_types": [{"extends": "Tomato", "base_growth_rate": 0.09}], "plants": [{"id": "p1", "type": "Tomato", "section": "s1", "initial_saturation": 0.5}]}`,
+			map[string]models.PlantType{"p1": fastTomato},
+		},
+		{
+			"inherit the rest of a temperature range",
+			"tick_interval: 1s\nplant_types:\n  - {name: Hardy, extends: Tomato, min_temperature: 4}\nplants:\n  - {id: p1, type: Hardy, section: s1, initial_saturation: 0.5}",
+			`{"tick_interval": "1s", "plant_types": [{"name": "Hardy", "extends": "Tomato", "min_temperature": 4}], "plants": [{"id": "p1", "type": "Hardy", "section": "s1", "initial_saturation": 0.5}]}`,
+			map[string]models.PlantType{"p1": hardy},
+		},
+		{
+			"tune growth",
+			"tick_interval: 1s\nplant_types:\n  - name: Tuned\n    extends: Tomato\n    growth: {min_health: 0.1, slow_health: 0.6, slow_factor: 2, optimal_bonus_factor: 1.5, optimal_tolerance: 0.1}\nplants:\n  - {id: p1, type: Tuned, section: s1, initial_saturation: 0.5}",
+			`{"tick_interval": "1s", "plant_types": [{"name": "Tuned", "extends": "Tomato", "growth": {"min_health": 0.1, "slow_health": 0.6, "slow_factor": 2, "optimal_bonus_factor": 1.5, "optimal_tolerance": 0.1}}], "plants": [{"id": "p1", "type": "Tuned", "section": "s1", "initial_saturation": 0.5}]}`,
+			map[string]models.PlantType{"p1": tuned},
+		},
+		{
+			"tune one growth parameter",
+			"tick_interval: 1s\nplant_types:\n  - {name: Slow, extends: Tomato, growth: {slow_factor: 2}}\nplants:\n  - {id: p1, type: Slow, section: s1, initial_saturation: 0.5}",
+			`{"tick_interval": "1s", "plant_types": [{"name": "Slow", "extends": "Tomato", "growth": {"slow_factor": 2}}], "plants": [{"id": "p1", "type": "Slow", "section": "s1", "initial_saturation": 0.5}]}`,
+			map[string]models.PlantType{"p1": slow},
+		},
+		{
+			"presets only",
+			"tick_interval: 1s\nplants:\n  - {id: p1, type: Tomato, section: s1, initial_saturation: 0.5}",
+			`{"tick_interval": "1s", "plants": [{"id": "p1", "type": "Tomato", "section": "s1", "initial_saturation": 0.5}]}`,
+			map[string]models.PlantType{"p1": tomato},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for format, input := range map[Format]string{FormatYAML: tt.yaml, FormatJSON: tt.json} {
+				cfg, err := Load(strings.NewReader(input), format)
+				if err != nil {
+					t.Fatalf("%s: unexpected error: %v", format, err)
+				}
+				plants, err := cfg.BuildPlants()
+				if err != nil {
+					t.Fatalf("%s: failed to build plants: %v", format, err)
+				}
+				for _, plant := range plants {
+					if *plant.Type != tt.expected[plant.ID] {
+						t.Errorf("%s: %s: expected type %+v, got %+v", format, plant.ID, tt.expected[plant.ID], plant.Type)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPlantTypeWarnings(t *testing.T) {
+	cfg := &GreenhouseConfig{PlantTypes: []PlantTypeConfig{
+		{Name: "Bean", MinSaturation: 0.2, MaxSaturation: 0.9, GerminationTicks: 5},
+		{Name: "Roma", MinTemperature: 10, MaxTemperature: 35},
+		{Name: "Pea", MinSaturation: 0.3, MaxSaturation: 0.8, MinTemperature: 4, MaxTemperature: 24, GerminationTicks: 5},
+	}}
+	expected := []string{
+		"plant type Bean: no temperature range, defaulting to 10 to 30 C",
+		"plant type Bean: no germination saturation range, defaulting to the saturation range 0.2 to 0.9",
+		"plant type Pea: no germination saturation range, defaulting to the saturation range 0.3 to 0.8",
+	}
+	if warnings := cfg.PlantTypeWarnings(); !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("expected warnings %q, got %q", expected, warnings)
+	}
+	if cfg.PlantTypes[0].MinTemperature != 0 {
+		t.Error("expected the warnings to leave the config as it is")
+	}
+
+	plants, err := (&GreenhouseConfig{
+		PlantTypes: cfg.PlantTypes[:1],
+		Plants:     []PlantConfig{{ID: "p1", Type: "Bean", SectionID: "s1", InitialSaturation: 0.5}},
+	}).BuildPlants()
+	if err != nil {
+		t.Fatalf("failed to build plants: %v", err)
+	}
+	if got := *plants[0].Type; got.MinTemperature != 10 || got.MaxTemperature != 30 || got.GerminationMinSaturation != 0.2 || got.GerminationMaxSaturation != 0.9 {
+		t.Errorf("expected the plants to get the defaults, got %+v", got)
+	}
+}
+
+func TestEnvironmentWarnings(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment string
+		plants      []string
+		expected    []string
+	}{
+		{"no temperature", "{ambient_humidity: 0.6, humidity_decay: 0.1}", []string{"Basil", "Tomato"}, nil},
+		{"summer", "{profile: summer}", []string{"Basil", "Lettuce", "Kale"}, nil},
+		{"winter", "{profile: winter}", []string{"Basil", "Kale", "Basil", "Tomato"}, []string{
+			"plant type Basil may not survive: the winter environment averages 8 C, below the 10 C minimum of the type",
+			"plant type Tomato may not survive: the winter environment averages 8 C, below the 10 C minimum of the type",
+		}},
+		{"frosts", "{ambient_humidity: 0.6, humidity_decay: 0.1, ticks_per_day: 24, frost_chance: 0.1, extreme_ticks: 4}", []string{"Kale", "Basil"}, []string{
+			"plant type Basil may not survive: the configured environment has frosts on 10% of days and the type is not frost tolerant",
+		}},
+		{"configured temperature", "{ambient_humidity: 0.6, humidity_decay: 0.1, temperature: 30}", []string{"Lettuce", "Mint"}, []string{
+			"plant type Lettuce may not survive: the configured environment averages 30 C, above the 28 C maximum of the type",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var plants strings.Builder
+			for i, plantType := range tt.plants {
+				fmt.Fprintf(&plants, "  - {id: p%d, type: %s, section: s1, initial_saturation: 0.5}\n", i, plantType)
+			}
+			cfg, err := Load(strings.NewReader("tick_interval: 1s\nenvironment: "+tt.environment+"\nplants:\n"+plants.String()), FormatYAML)
+			if err != nil {
+				t.Fatalf("failed to load config: %v", err)
+			}
+			if warnings := cfg.EnvironmentWarnings(); !reflect.DeepEqual(warnings, tt.expected) {
+				t.Errorf("expected warnings %q, got %q", tt.expected, warnings)
+			}
+		})
+	}
+}
+
+func TestLoad_PlantTypePresetErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		json     string
+		errorMsg string
+	}{
+		{
+			"invalid override",
+			"tick_interval: 1s\nplant_types:\n  - {name: Soggy, extends: Tomato, min_saturation: 1.5}",
+			`{"tick_interval": "1s", "plant_types": [{"name": "Soggy", "extends": "Tomato", "min_saturation": 1.5}]}`,
+			"plant type Soggy: plant type min saturation must be between 0.0 and 1.0",
+		},
+		{
+			"misspelled preset",
+			"tick_interval: 1s\nplant_types:\n  - {name: Roma, extends: Tomatoe}",
+			`{"tick_interval": "1s", "plant_types": [{"name": "Roma", "extends": "Tomatoe"}]}`,
+			"unknown plant type preset: Tomatoe (did you mean Tomato? known presets: Basil, Kale, Lettuce, Mint, Pepper, Strawberry, Tomato)",
+		},
+		{
+			"unknown preset",
+			"tick_interval: 1s\nplant_types:\n  - {name: Spiky, extends: Cactus}",
+			`{"tick_interval": "1s", "plant_types": [{"name": "Spiky", "extends": "Cactus"}]}`,
+			"unknown plant type preset: Cactus (known presets: Basil, Kale, Lettuce, Mint, Pepper, Strawberry, Tomato)",
+		},
+		{
+			"unknown override field",
+			"tick_interval: 1s\nplant_types:\n  - {name: Roma, extends: Tomato, growth_rate: 0.1}",
+			`{"tick_interval": "1s", "plant_types": [{"name": "Roma", "extends": "Tomato", "growth_rate": 0.1}]}`,
+			"growth_rate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, yamlErr := Load(strings.NewReader(tt.yaml), FormatYAML)
+			_, jsonErr := Load(strings.NewReader(tt.json), FormatJSON)
+			if yamlErr == nil || jsonErr == nil {
+				t.Fatalf("expected errors from both formats, got yaml=%v json=%v", yamlErr, jsonErr)
+			}
+			if !strings.Contains(yamlErr.Error(), tt.errorMsg) || !strings.Contains(jsonErr.Error(), tt.errorMsg) {
+				t.Errorf("expected error message containing '%s', got yaml='%s' json='%s'", tt.errorMsg, yamlErr, jsonErr)
+			}
+		})
+	}
+}
+
+func TestLoad_SensorTemplates(t *testing.T) {
+	yaml := `
+tick_interval: 1s
+plants:
+  - {id: p1, type: Tomato, section: section-A, initial_saturation: 0.5}
+  - {id: p2, type: Tomato, section: section-B, initial_saturation: 0.5}
+  - {id: p3, type: Tomato, section: section-C, initial_saturation: 0.5}
+zones:
+  - {id: west, sections: [section-B, section-A]}
+sensors:
+  - {id: moist-section-B, type: soil_moisture, section: section-B, noise: 0.1}
+sensor_templates:
+  - {template: {type: soil_moisture, noise: 0.02}, per_section: true, id_pattern: "moist-{section}"}
+  - {template: {type: temperature}, per_zone: true, id_pattern: "temp-{zone}-{n}"}
+`
+	cfg, err := Load(strings.NewReader(yaml), FormatYAML)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The listed moist-section-B wins over the one of the template, and the
+	// zone sensor goes to the first section of the zone.
+	expected := []SensorConfig{
+		{ID: "moist-section-B", Type: models.SoilMoisture, SectionID: "section-B", Noise: 0.1},
+		{ID: "moist-section-A", Type: models.SoilMoisture, SectionID: "section-A", Noise: 0.02},
+		{ID: "moist-section-C", Type: models.SoilMoisture, SectionID: "section-C", Noise: 0.02},
+		{ID: "temp-west-1", Type: models.Temperature, SectionID: "section-B"},
+	}
+	if !reflect.DeepEqual(cfg.Sensors, expected) {
+		t.Errorf("expected sensors %+v, got %+v", expected, cfg.Sensors)
+	}
+
+	// Expanding again adds nothing.
+	if err := cfg.ExpandSensorTemplates(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Sensors, expected) {
+		t.Errorf("expected a second expansion to keep the sensors, got %+v", cfg.Sensors)
+	}
+}
+
+func TestValidate_Sections(t *testing.T) {
+	tests := []struct {
+		name     string
+		sections []SectionConfig
+		errorMsg string
+	}{
+		{"preset", []SectionConfig{{ID: "section-A", Soil: "Clay"}}, ""},
+		{"preset override", []SectionConfig{{ID: "section-A", Soil: "Sand", Drainage: 2}}, ""},
+		{"custom", []SectionConfig{{ID: "section-A", Retention: 0.8, Drainage: 1.2}}, ""},
+		{"no ID", []SectionConfig{{Soil: "Clay"}}, "section ID cannot be empty"},
+		{"duplicate", []SectionConfig{{ID: "section-A", Soil: "Clay"}, {ID: "section-A", Soil: "Sand"}}, "duplicate section ID: section-A"},
+		{"unknown soil", []SectionConfig{{ID: "section-A", Soil: "Peat"}}, "section section-A: unknown soil type: Peat"},
+		{"custom without drainage", []SectionConfig{{ID: "section-A", Retention: 0.8}}, "section section-A: soil drainage must be above 0.0 and at most 5.0: Custom"},
+		{"retention above 1", []SectionConfig{{ID: "section-A", Soil: "Loam", Retention: 1.5}}, "section section-A: soil retention must be above 0.0 and at most 1.0: Loam"},
+		{"negative drainage", []SectionConfig{{ID: "section-A", Soil: "Loam", Drainage: -1}}, "section section-A: soil drainage must be above 0.0 and at most 5.0: Loam"},
+		{"drainage too high", []SectionConfig{{ID: "section-A", Retention: 0.8, Drainage: 6}}, "section section-A: soil drainage must be above 0.0 and at most 5.0: Custom"},
+		{"layered", []SectionConfig{{ID: "section-A", Soil: "Loam", Percolation: 0.1}}, ""},
+		{"percolation above 1", []SectionConfig{{ID: "section-A", Soil: "Loam", Percolation: 2}}, "section section-A: soil percolation must be between 0.0 and 1.0: Loam"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Sections = tt.sections
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestValidate_Lights(t *testing.T) {
+	tests := []struct {
+		name        string
+		ticksPerDay int
+		lights      []LightsConfig
+		errorMsg    string
+	}{
+		{"manual", 0, []LightsConfig{{SectionID: "section-A", EnergyPerTick: 3}}, ""},
+		{"after sunset", 24, []LightsConfig{{SectionID: "section-A", Intensity: 0.5, AfterSunset: 4}}, ""},
+		{"no section", 0, []LightsConfig{{Intensity: 0.5}}, "lights section ID cannot be empty"},
+		{"duplicate", 0, []LightsConfig{{SectionID: "section-A"}, {SectionID: "section-A"}}, "duplicate lights section: section-A"},
+		{"too bright", 0, []LightsConfig{{SectionID: "section-A", Intensity: 1.5}}, "lights intensity must be between 0.0 and 1.0"},
+		{"after sunset without day cycle", 0, []LightsConfig{{SectionID: "section-A", Intensity: 0.5, AfterSunset: 4}}, "lights on after sunset require a day cycle: section-A"},
+		{"on all day", 24, []LightsConfig{{SectionID: "section-A", Intensity: 0.5, AfterSunset: 24}}, "lights on after sunset must go off within a day: section-A"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Environment.TicksPerDay = tt.ticksPerDay
+			cfg.Lights = tt.lights
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestValidate_Disease(t *testing.T) {
+	cfg := Default()
+	cfg.Disease = &DiseaseConfig{Incubation: 5, HealthDecay: 0.05, SpreadRate: 0.1}
+	cfg.Plants[0].State = &PlantStateConfig{Health: 1, Alive: true, Disease: models.Infected, DiseaseTicks: 2}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	plants, _ := cfg.BuildPlants()
+	if expected := (models.Disease{Stage: models.Infected, Ticks: 2}); plants[0].Disease != expected {
+		t.Errorf("expected the plant to start with %+v, got %+v", expected, plants[0].Disease)
+	}
+
+	cfg.Disease.SpreadRate = 2
+	if err := cfg.Validate(); err == nil || err.Error() != "disease rates and chances must be between 0.0 and 1.0" {
+		t.Errorf("expected invalid disease settings to be refused, got %v", err)
+	}
+	cfg.Disease.SpreadRate = 0.1
+	cfg.Plants[0].State.Disease = "blight"
+	expected := "plant " + cfg.Plants[0].ID + ": disease stage must be infected or symptomatic: blight"
+	if err := cfg.Validate(); err == nil || err.Error() != expected {
+		t.Errorf("expected error message '%s', got '%v'", expected, err)
+	}
+}
+
+func TestValidate_Pruning(t *testing.T) {
+	cfg := Default()
+	if cfg.PruneEffect() != models.DefaultPruneEffect {
+		t.Errorf("expected the default prune effect, got %+v", cfg.PruneEffect())
+	}
+	cfg.Pruning = &PruningConfig{Ticks: 10, Depletion: 0.5, Enhancement: 2}
+	cfg.Plants[0].State = &PlantStateConfig{Health: 1, GrowthStage: 0.3, Alive: true, Modifiers: []ModifierConfig{
+		{Source: models.Pruned, Depletion: 0.5, Enhancement: 2, Ticks: 4},
+	}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	plants, _ := cfg.BuildPlants()
+	expected := []models.Modifier{{Source: models.Pruned, Depletion: 0.5, Enhancement: 2, Ticks: 4}}
+	if !reflect.DeepEqual(plants[0].Modifiers, expected) {
+		t.Errorf("expected the plant to start with %+v, got %+v", expected, plants[0].Modifiers)
+	}
+
+	cfg.Pruning.Enhancement = 0.5
+	if err := cfg.Validate(); err == nil || err.Error() != "prune enhancement factor must be at least 1.0" {
+		t.Errorf("expected an invalid pruning effect to be refused, got %v", err)
+	}
+}
+
+func TestValidate_Salinity(t *testing.T) {
+	tests := []struct {
+		name     string
+		salinity *SalinityConfig
+		tank     *TankConfig
+		sections []SectionConfig
+		errorMsg string
+	}{
+		{"off", nil, nil, nil, ""},
+		{"mains water", &SalinityConfig{Concentration: 0.01, FlushAmount: 2, FlushRate: 0.5, RainLeach: 0.1}, nil, nil, ""},
+		{"tank water", &SalinityConfig{TankConcentration: 0.02}, &TankConfig{Capacity: 10, InitialLevel: 10}, nil, ""},
+		{"salty section", &SalinityConfig{}, nil, []SectionConfig{{ID: "section-A", Salinity: 0.4}}, ""},
+		{"negative concentration", &SalinityConfig{Concentration: -0.01}, nil, nil, "salinity concentrations cannot be negative"},
+		{"tank concentration without a tank", &SalinityConfig{TankConcentration: 0.02}, nil, nil, "salinity tank concentration requires a tank"},
+		{"flush rate without amount", &SalinityConfig{FlushRate: 0.5}, nil, nil, "salinity flush rate requires a flush amount"},
+		{"rain leach above 1", &SalinityConfig{RainLeach: 1.5}, nil, nil, "salinity rain leach must be between 0.0 and 1.0"},
+		{"section salinity above 1", &SalinityConfig{}, nil, []SectionConfig{{ID: "section-A", Salinity: 1.5}}, "section salinity must be between 0.0 and 1.0: section-A"},
+		{"salty section without settings", nil, nil, []SectionConfig{{ID: "section-A", Salinity: 0.4}}, "section salinity requires salinity settings"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Salinity, cfg.Tank, cfg.Sections = tt.salinity, tt.tank, tt.sections
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+
+	// A greenhouse with a tank waters with the water of the tank.
+	cfg := Default()
+	cfg.Tank = nil
+	cfg.Salinity = &SalinityConfig{Concentration: 0.01, TankConcentration: 0.03}
+	if got := cfg.SalinityConfig().Concentration; got != 0.01 {
+		t.Errorf("expected the mains concentration without a tank, got %v", got)
+	}
+	cfg.Tank = &TankConfig{Capacity: 10, InitialLevel: 10}
+	if got := cfg.SalinityConfig().Concentration; got != 0.03 {
+		t.Errorf("expected the tank concentration with a tank, got %v", got)
+	}
+}
+
+func TestValidate_Invariants(t *testing.T) {
+	cfg := Default()
+	for _, mode := range []string{"", InvariantsPanic, InvariantsEvent} {
+		cfg.Invariants = mode
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("expected the %q mode to be valid, got %v", mode, err)
+		}
+	}
+	cfg.Invariants = "log"
+	if err := cfg.Validate(); err == nil || err.Error() != "invariants must be panic or event: log" {
+		t.Errorf("expected an unknown mode to be refused, got %v", err)
+	}
+}
+
+func TestValidate_DeadSections(t *testing.T) {
+	tests := []struct {
+		policy   string
+		expected sensors.DeadSectionPolicy
+		errorMsg string
+	}{
+		{"", sensors.DeadSectionHold, ""},
+		{"hold", sensors.DeadSectionHold, ""},
+		{"error", sensors.DeadSectionError, ""},
+		{"zero", "", "dead sections must be hold or error: zero"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			cfg := Default()
+			cfg.DeadSections = tt.policy
+			err := cfg.Validate()
+			if tt.errorMsg != "" {
+				if err == nil || err.Error() != tt.errorMsg {
+					t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got := cfg.DeadSectionPolicy(); got != tt.expected {
+				t.Errorf("expected the %s policy, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestValidate_HistoryLookup(t *testing.T) {
+	tests := []struct {
+		lookup   string
+		expected sensors.HistoryLookup
+		errorMsg string
+	}{
+		{"", sensors.HistoryLookupNearestBefore, ""},
+		{"nearest_before", sensors.HistoryLookupNearestBefore, ""},
+		{"exact", sensors.HistoryLookupExact, ""},
+		{"latest", "", "history lookup must be nearest_before or exact: latest"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lookup, func(t *testing.T) {
+			cfg := Default()
+			cfg.HistoryLookup = tt.lookup
+			err := cfg.Validate()
+			if tt.errorMsg != "" {
+				if err == nil || err.Error() != tt.errorMsg {
+					t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got := cfg.SensorHistoryLookup(); got != tt.expected {
+				t.Errorf("expected the %s lookup, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestValidate_OverrunPolicy(t *testing.T) {
+	tests := []struct {
+		policy   string
+		expected engine.OverrunPolicy
+		errorMsg string
+	}{
+		{"", engine.OverrunSkip, ""},
+		{"skip", engine.OverrunSkip, ""},
+		{"catchup", engine.OverrunCatchup, ""},
+		{"stretch", engine.OverrunStretch, ""},
+		{"rewind", "", "overrun policy must be skip, catchup or stretch: rewind"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			cfg := Default()
+			cfg.OverrunPolicy = tt.policy
+			err := cfg.Validate()
+			if tt.errorMsg != "" {
+				if err == nil || err.Error() != tt.errorMsg {
+					t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got := cfg.TickOverrunPolicy(); got != tt.expected {
+				t.Errorf("expected the %s policy, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestValidate_DeadPlants(t *testing.T) {
+	tests := []struct {
+		name       string
+		deadPlants *DeadPlantsConfig
+		remove     bool
+		after      int
+		errorMsg   string
+	}{
+		{"default", nil, false, 0, ""},
+		{"keep", &DeadPlantsConfig{Retention: KeepDeadPlants}, false, 0, ""},
+		{"remove", &DeadPlantsConfig{Retention: RemoveDeadPlants}, true, 0, ""},
+		{"remove after", &DeadPlantsConfig{Retention: RemoveDeadPlants, After: 5}, true, 5, ""},
+		{"unknown retention", &DeadPlantsConfig{Retention: "bury"}, false, 0, "dead plant retention must be keep or remove: bury"},
+		{"negative delay", &DeadPlantsConfig{Retention: RemoveDeadPlants, After: -1}, false, 0, "dead plant removal delay cannot be negative"},
+		{"delay when kept", &DeadPlantsConfig{Retention: KeepDeadPlants, After: 5}, false, 0, "dead plant removal delay needs the remove retention"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.DeadPlants = tt.deadPlants
+			err := cfg.Validate()
+			if tt.errorMsg != "" {
+				if err == nil || err.Error() != tt.errorMsg {
+					t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if remove, after := cfg.RemovesDeadPlants(); remove != tt.remove || after != tt.after {
+				t.Errorf("expected remove=%v after %d ticks, got remove=%v after %d", tt.remove, tt.after, remove, after)
+			}
+		})
+	}
+}
+
+func TestValidate_Journal(t *testing.T) {
+	tests := []struct {
+		name     string
+		journal  *JournalConfig
+		entries  int
+		errorMsg string
+	}{
+		{"off", nil, 0, ""},
+		{"default entries", &JournalConfig{}, DefaultJournalEntries, ""},
+		{"entries", &JournalConfig{Entries: 10}, 10, ""},
+		{"negative entries", &JournalConfig{Entries: -1}, 0, "journal entries cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Journal = tt.journal
+			err := cfg.Validate()
+			if tt.errorMsg != "" {
+				if err == nil || err.Error() != tt.errorMsg {
+					t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if entries := cfg.JournalEntries(); entries != tt.entries {
+				t.Errorf("expected %d entries, got %d", tt.entries, entries)
+			}
+		})
+	}
+}
+
+func TestValidate_Microclimates(t *testing.T) {
+	tests := []struct {
+		name          string
+		microclimates []MicroclimateConfig
+		errorMsg      string
+	}{
+		{"valid", []MicroclimateConfig{{SectionID: "section-A", Temperature: -4}, {SectionID: "section-B", Light: 0.5}}, ""},
+		{"no section", []MicroclimateConfig{{Temperature: -4}}, "microclimate section cannot be empty"},
+		{"duplicate section", []MicroclimateConfig{{SectionID: "section-A"}, {SectionID: "section-A"}}, "duplicate microclimate section: section-A"},
+		{"invalid offset", []MicroclimateConfig{{SectionID: "section-A", Humidity: 2}}, "microclimate section-A: humidity offset must be between -1.0 and 1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Microclimates = tt.microclimates
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				expected := map[string]environment.ClimateOffset{"section-A": {Temperature: -4}, "section-B": {Light: 0.5}}
+				if got := cfg.ClimateOffsets(); !reflect.DeepEqual(got, expected) {
+					t.Errorf("expected %+v, got %+v", expected, got)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestValidate_Zones(t *testing.T) {
+	deadPlants := map[string]AlertRuleConfig{AlertDeadPlants: {}}
+	tests := []struct {
+		name     string
+		zones    []ZoneConfig
+		alerts   map[string]map[string]AlertRuleConfig
+		errorMsg string
+	}{
+		{"valid", []ZoneConfig{{ID: "west", Name: "West wing", Sections: []string{"section-A", "section-B"}}}, nil, ""},
+		{"two zones", []ZoneConfig{{ID: "west", Sections: []string{"section-A"}}, {ID: "east", Sections: []string{"section-B"}}}, nil, ""},
+		{"sensor section", []ZoneConfig{{ID: "west", Sections: []string{"section-B"}}}, nil, ""},
+		{"no ID", []ZoneConfig{{Sections: []string{"section-A"}}}, nil, "zone ID cannot be empty"},
+		{"no sections", []ZoneConfig{{ID: "west"}}, nil, "zone must have at least one section: west"},
+		{"duplicate ID", []ZoneConfig{{ID: "west", Sections: []string{"section-A"}}, {ID: "west", Sections: []string{"section-B"}}}, nil, "duplicate zone ID: west"},
+		{"section twice", []ZoneConfig{{ID: "west", Sections: []string{"section-A", "section-A"}}}, nil, "duplicate section in zone west: section-A"},
+		{"unknown section", []ZoneConfig{{ID: "west", Sections: []string{"section-Z"}}}, nil, "zone west has an unknown section: section-Z"},
+		{"section in two zones", []ZoneConfig{{ID: "west", Sections: []string{"section-A"}}, {ID: "east", Sections: []string{"section-B", "section-A"}}}, nil,
+			"section section-A is in zones west and east"},
+		{"zone alerts", []ZoneConfig{{ID: "west", Sections: []string{"section-A"}}}, map[string]map[string]AlertRuleConfig{"west": deadPlants}, ""},
+		{"alerts of an unknown zone", nil, map[string]map[string]AlertRuleConfig{"west": deadPlants}, "alert rules of an unknown zone: west"},
+		{"alert rule not scoped to zones", []ZoneConfig{{ID: "west", Sections: []string{"section-A"}}},
+			map[string]map[string]AlertRuleConfig{"west": {AlertTankEmpty: {}}}, "alert rule of zone west cannot be scoped to a zone: tank_empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Zones = tt.zones
+			if tt.alerts != nil {
+				cfg.Alerts = &AlertsConfig{Zones: tt.alerts}
+			}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestValidate_Germination(t *testing.T) {
+	cfg := Default()
+	cfg.PlantTypes = []PlantTypeConfig{
+		{Name: "Bean", OptimalSaturation: 0.6, MinSaturation: 0.2, MaxSaturation: 0.9, GerminationTicks: 5, GerminationMinSaturation: 0.5, GerminationMaxSaturation: 0.8},
+	}
+	cfg.Plants = []PlantConfig{
+		{ID: "seed", Type: "Bean", SectionID: "s1", InitialSaturation: 0.6},
+		{ID: "sprouted", Type: "Bean", SectionID: "s1", InitialSaturation: 0.6, State: &PlantStateConfig{Health: 1, Alive: true}},
+		{ID: "resumed", Type: "Bean", SectionID: "s1", InitialSaturation: 0.6, State: &PlantStateConfig{Health: 1, Alive: true, Germination: &GerminationConfig{Ticks: 3, Favorable: 2}}},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !cfg.Germinates() {
+		t.Error("expected the config to germinate")
+	}
+	plants, _ := cfg.BuildPlants()
+	if plants[0].Germination == nil || *plants[0].Germination != (models.Germination{}) {
+		t.Errorf("expected a new seed to start germinating, got %+v", plants[0].Germination)
+	}
+	if plants[1].Germinating() {
+		t.Errorf("expected a resumed plant without germination to have sprouted, got %+v", plants[1].Germination)
+	}
+	if plants[2].Germination == nil || *plants[2].Germination != (models.Germination{Ticks: 3, Favorable: 2}) {
+		t.Errorf("expected the resumed seed to carry its germination, got %+v", plants[2].Germination)
+	}
+
+	cfg.PlantTypes[0].GerminationFailure = 2
+	expected := "plant type Bean: plant type germination failure must be between 0.0 and 1.0"
+	if err := cfg.Validate(); err == nil || err.Error() != expected {
+		t.Errorf("expected error message '%s', got '%v'", expected, err)
+	}
+}
+
+func TestValidate_Prices(t *testing.T) {
+	cfg := Default()
+	cfg.Prices = &PricesConfig{Water: 2}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	cfg.Prices.Energy = -0.5
+	if err := cfg.Validate(); err == nil || err.Error() != "prices cannot be negative" {
+		t.Errorf("expected error message 'prices cannot be negative', got '%v'", err)
+	}
+}
+
+func TestValidate_HVAC(t *testing.T) {
+	thermostat := func(sensorID string) *ThermostatConfig {
+		return &ThermostatConfig{SensorID: sensorID, Min: 18, Max: 24}
+	}
+	tests := []struct {
+		name     string
+		hvac     *HVACConfig
+		errorMsg string
+	}{
+		{"manual", &HVACConfig{HeaterPower: 2, VentPower: 1}, ""},
+		{"thermostat", &HVACConfig{HeaterPower: 2, Thermostat: thermostat("temp")}, ""},
+		{"invalid", &HVACConfig{HeatLoss: 2}, "heat loss must be between 0.0 and 1.0"},
+		{"unknown sensor", &HVACConfig{Thermostat: thermostat("temp-9")}, "unknown thermostat sensor: temp-9"},
+		{"not a temperature sensor", &HVACConfig{Thermostat: thermostat("sensor-1")}, "thermostat sensor is not a temperature sensor: sensor-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Sensors = append(cfg.Sensors, SensorConfig{ID: "temp", Type: models.Temperature, SectionID: "section-A"})
+			cfg.HVAC = tt.hvac
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestValidate_WateringMethods(t *testing.T) {
+	ratio := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name     string
+		methods  []MethodConfig
+		errorMsg string
+	}{
+		{"overridden", []MethodConfig{{Method: models.MethodOverhead, Efficiency: ratio(0.6)}, {Method: models.MethodMisting, HumidityPerUnit: ratio(0.3)}}, ""},
+		{"unknown", []MethodConfig{{Method: "flood"}}, "unknown irrigation method: flood"},
+		{"duplicate", []MethodConfig{{Method: models.MethodDrip}, {Method: models.MethodDrip}}, "duplicate irrigation method: drip"},
+		{"efficiency above one", []MethodConfig{{Method: models.MethodDrip, Efficiency: ratio(1.2)}}, "irrigation method drip: efficiency must be between 0.0 and 1.0"},
+		{"negative humidity", []MethodConfig{{Method: models.MethodMisting, HumidityPerUnit: ratio(-0.1)}}, "irrigation method misting: humidity per unit cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Watering = &WateringConfig{Methods: tt.methods}
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestWateringConfig_MethodsKeepTheirDefaults(t *testing.T) {
+	efficiency := 0.6
+	cfg := Default()
+	cfg.Watering = &WateringConfig{Methods: []MethodConfig{{Method: models.MethodOverhead, Efficiency: &efficiency}}}
+
+	methods := cfg.WateringConfig().Methods
+
+	expected := map[models.IrrigationMethod]watering.MethodProfile{
+		models.MethodOverhead: {Efficiency: 0.6, HumidityPerUnit: watering.DefaultOverheadProfile.HumidityPerUnit},
+	}
+	if !reflect.DeepEqual(methods, expected) {
+		t.Errorf("expected methods %+v, got %+v", expected, methods)
+	}
+}
+
+func TestBuildPlants_TakeTheSoilOfTheirSection(t *testing.T) {
+	cfg := Default()
+	cfg.Sections = []SectionConfig{{ID: "section-A", Soil: "Clay"}}
+	plants, err := cfg.BuildPlants()
+	if err != nil {
+		t.Fatalf("failed to build plants: %v", err)
+	}
+	clay, _ := models.PresetSoilType("Clay")
+	for _, plant := range plants {
+		if plant.SectionID == "section-A" && (plant.Soil == nil || *plant.Soil != clay) {
+			t.Errorf("%s: expected clay soil, got %+v", plant.ID, plant.Soil)
+		}
+		if plant.SectionID != "section-A" && plant.Soil != nil {
+			t.Errorf("%s: expected plain soil, got %+v", plant.ID, plant.Soil)
+		}
+	}
+}
+
+func TestValidate_Server(t *testing.T) {
+	tests := []struct {
+		name     string
+		server   ServerConfig
+		errorMsg string
+	}{
+		{"http only", ServerConfig{HTTP: ":8080"}, ""},
+		{"both", ServerConfig{HTTP: "localhost:8080", GRPC: ":9090"}, ""},
+		{"no address", ServerConfig{}, "server config needs an http or grpc address"},
+		{"port only", ServerConfig{GRPC: "9090"}, "invalid server address: 9090"},
+		{"tokens", ServerConfig{HTTP: ":8080", Tokens: []TokenConfig{
+			{Name: "dashboard", Token: "read-secret-0123456789", Scope: "read"},
+			{Name: "operator", Token: "control-secret-0123456789", Scope: "control"},
+		}}, ""},
+		{"unnamed token", ServerConfig{HTTP: ":8080", Tokens: []TokenConfig{{Token: "read-secret-0123456789", Scope: "read"}}},
+			"api token name cannot be empty"},
+		{"duplicate token name", ServerConfig{HTTP: ":8080", Tokens: []TokenConfig{
+			{Name: "dashboard", Token: "read-secret-0123456789", Scope: "read"},
+			{Name: "dashboard", Token: "control-secret-0123456789", Scope: "control"},
+		}}, "duplicate api token name: dashboard"},
+		{"short token", ServerConfig{HTTP: ":8080", Tokens: []TokenConfig{{Name: "dashboard", Token: "secret", Scope: "read"}}},
+			"api token dashboard must be at least 16 characters long"},
+		{"reused token", ServerConfig{HTTP: ":8080", Tokens: []TokenConfig{
+			{Name: "dashboard", Token: "read-secret-0123456789", Scope: "read"},
+			{Name: "operator", Token: "read-secret-0123456789", Scope: "control"},
+		}}, "api token operator reuses the token of another"},
+		{"unknown scope", ServerConfig{HTTP: ":8080", Tokens: []TokenConfig{{Name: "dashboard", Token: "read-secret-0123456789", Scope: "owner"}}},
+			"invalid scope of api token dashboard: owner"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Server = &tt.server
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestValidate_Influx(t *testing.T) {
+	valid := InfluxConfig{URL: "http://localhost:8086", Bucket: "greenhouse"}
+	with := func(change func(*InfluxConfig)) InfluxConfig {
+		influx := valid
+		change(&influx)
+		return influx
+	}
+	tests := []struct {
+		name     string
+		influx   InfluxConfig
+		errorMsg string
+	}{
+		{"defaults", valid, ""},
+		{"sim timestamps", with(func(i *InfluxConfig) { i.Timestamps = "sim" }), ""},
+		{"no scheme", with(func(i *InfluxConfig) { i.URL = "localhost:8086" }), "invalid influx url: localhost:8086"},
+		{"no bucket", with(func(i *InfluxConfig) { i.Bucket = "" }), "influx bucket cannot be empty"},
+		{"unknown timestamps", with(func(i *InfluxConfig) { i.Timestamps = "tick" }), "influx timestamps must be wall or sim: tick"},
+		{"unknown drop policy", with(func(i *InfluxConfig) { i.Drop = "random" }), "influx drop policy must be oldest or newest: random"},
+		{"negative batch size", with(func(i *InfluxConfig) { i.BatchSize = -1 }), "influx batch and buffer sizes cannot be negative"},
+		{"negative flush interval", with(func(i *InfluxConfig) { i.FlushInterval = -1 }), "influx flush interval and retry delays cannot be negative"},
+		{"batch above buffer", with(func(i *InfluxConfig) { i.BatchSize = 100; i.BufferSize = 10 }), "influx batch size cannot exceed the buffer size"},
+		{"min above default max", with(func(i *InfluxConfig) { i.RetryMin = Duration(time.Minute) }), "influx minimum retry delay cannot exceed the maximum"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Influx = &tt.influx
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestValidate_Tracing(t *testing.T) {
+	tests := []struct {
+		name     string
+		tracing  TracingConfig
+		errorMsg string
+	}{
+		{"defaults", TracingConfig{Enabled: true}, ""},
+		{"endpoint and ratio", TracingConfig{Enabled: true, Endpoint: "https://collector:4318", SampleRatio: 0.1}, ""},
+		{"no scheme", TracingConfig{Endpoint: "collector:4318"}, "invalid tracing endpoint: collector:4318"},
+		{"ratio above one", TracingConfig{SampleRatio: 1.5}, "tracing sample ratio must be between 0.0 and 1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Tracing = &tt.tracing
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestValidate_Export(t *testing.T) {
+	influx := &InfluxConfig{URL: "http://localhost:8086", Bucket: "greenhouse"}
+	tests := []struct {
+		name     string
+		export   ExportConfig
+		errorMsg string
+	}{
+		{"every type", ExportConfig{Workers: 2, Exporters: []ExporterConfig{
+			{Name: "rows", Type: "csv", Path: "run.csv.gz", Columns: []string{"tick", "health"}},
+			{Name: "history", Type: "sqlite", Path: "history.db", SampleInterval: 5, QueueSize: 100},
+			{Name: "log", Type: "eventlog", Path: "events.jsonl", Sync: "event"},
+			{Name: "influx", Type: "influx", Influx: influx},
+			{Name: "mqtt", Type: "mqtt", MQTT: &MQTTConfig{Broker: "tcp://localhost:1883"}},
+		}}, ""},
+		{"negative workers", ExportConfig{Workers: -1}, "export workers cannot be negative"},
+		{"no name", ExportConfig{Exporters: []ExporterConfig{{Type: "csv", Path: "run.csv"}}}, "exporter name cannot be empty"},
+		{"duplicate name", ExportConfig{Exporters: []ExporterConfig{{Name: "a", Type: "csv", Path: "a.csv"}, {Name: "a", Type: "csv", Path: "b.csv"}}}, "duplicate exporter name: a"},
+		{"unknown type", ExportConfig{Exporters: []ExporterConfig{{Name: "a", Type: "kafka"}}}, "exporter type must be csv, sqlite, eventlog, influx or mqtt: a"},
+		{"negative queue", ExportConfig{Exporters: []ExporterConfig{{Name: "a", Type: "csv", Path: "a.csv", QueueSize: -1}}}, "exporter queue size and sample interval cannot be negative: a"},
+		{"no path", ExportConfig{Exporters: []ExporterConfig{{Name: "a", Type: "sqlite"}}}, "exporter path cannot be empty: a"},
+		{"no influx settings", ExportConfig{Exporters: []ExporterConfig{{Name: "a", Type: "influx"}}}, "influx exporter needs influx settings: a"},
+		{"invalid influx settings", ExportConfig{Exporters: []ExporterConfig{{Name: "a", Type: "influx", Influx: &InfluxConfig{URL: "http://localhost:8086"}}}}, "influx bucket cannot be empty"},
+		{"no mqtt settings", ExportConfig{Exporters: []ExporterConfig{{Name: "a", Type: "mqtt"}}}, "mqtt exporter needs mqtt settings: a"},
+		{"event log without path", ExportConfig{Exporters: []ExporterConfig{{Name: "a", Type: "eventlog"}}}, "exporter path cannot be empty: a"},
+		{"unknown event log sync", ExportConfig{Exporters: []ExporterConfig{{Name: "a", Type: "eventlog", Path: "a.jsonl", Sync: "always"}}}, "event log sync must be never, tick or event: a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Export = &tt.export
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestValidate_MQTT(t *testing.T) {
+	tests := []struct {
+		name     string
+		mqtt     MQTTConfig
+		errorMsg string
+	}{
+		{"defaults", MQTTConfig{Broker: "tcp://localhost:1883"}, ""},
+		{"no broker", MQTTConfig{}, "mqtt broker cannot be empty"},
+		{"qos above 2", MQTTConfig{Broker: "tcp://b:1883", QoS: 3}, "mqtt qos must be 0, 1 or 2"},
+		{"wildcard prefix", MQTTConfig{Broker: "tcp://b:1883", TopicPrefix: "farm/#"}, "mqtt topic prefix cannot contain wildcards: farm/#"},
+		{"nested greenhouse name", MQTTConfig{Broker: "tcp://b:1883", Greenhouse: "north/1"}, "mqtt greenhouse name cannot contain wildcards or slashes: north/1"},
+		{"negative buffer", MQTTConfig{Broker: "tcp://b:1883", BufferSize: -1}, "mqtt buffer size cannot be negative"},
+		{"min above default max", MQTTConfig{Broker: "tcp://b:1883", ReconnectMin: Duration(time.Minute)}, "mqtt minimum reconnect delay cannot exceed the maximum"},
+		{"unknown alert topic severity", MQTTConfig{Broker: "tcp://b:1883", AlertTopics: map[string]string{"fatal": "alerts/fatal"}}, "mqtt alert topic severity must be info, warning or critical: fatal"},
+		{"wildcard alert topic", MQTTConfig{Broker: "tcp://b:1883", AlertTopics: map[string]string{SeverityCritical: "alerts/#"}}, "mqtt alert topic cannot be empty or contain wildcards: critical"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.MQTT = &tt.mqtt
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestValidate_Alerts(t *testing.T) {
+	threshold := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name     string
+		alerts   AlertsConfig
+		errorMsg string
+	}{
+		{"defaults", AlertsConfig{}, ""},
+		{"overrides", AlertsConfig{Window: 10, Rules: map[string]AlertRuleConfig{
+			AlertTickOverruns:       {Threshold: threshold(0), Severity: SeverityCritical},
+			AlertDeadPlants:         {Threshold: threshold(100)},
+			AlertUnmonitoredSection: {Disabled: true},
+		}}, ""},
+		{"negative window", AlertsConfig{Window: -1}, "alert window cannot be negative"},
+		{"unknown rule", AlertsConfig{Rules: map[string]AlertRuleConfig{"frost": {}}}, "unknown alert rule: frost"},
+		{"unknown severity", AlertsConfig{Rules: map[string]AlertRuleConfig{AlertTankEmpty: {Severity: "fatal"}}}, "alert rule tank_empty: severity must be info, warning or critical: fatal"},
+		{"negative threshold", AlertsConfig{Rules: map[string]AlertRuleConfig{AlertExportDrops: {Threshold: threshold(-1)}}}, "alert threshold cannot be negative: export_drops"},
+		{"dead plants above 100", AlertsConfig{Rules: map[string]AlertRuleConfig{AlertDeadPlants: {Threshold: threshold(101)}}}, "dead plants alert threshold cannot exceed 100 percent"},
+		{"unmonitored section threshold", AlertsConfig{Rules: map[string]AlertRuleConfig{AlertUnmonitoredSection: {Threshold: threshold(1)}}}, "alert rule unmonitored_section takes no threshold"},
+		{"low battery above 1.0", AlertsConfig{Rules: map[string]AlertRuleConfig{AlertLowBattery: {Threshold: threshold(1.5)}}}, "low battery alert threshold cannot exceed 1.0"},
+		{"saturation drop above 1.0", AlertsConfig{Rules: map[string]AlertRuleConfig{AlertSaturationDrop: {Threshold: threshold(2)}}}, "saturation drop alert threshold cannot exceed 1.0 per tick"},
+		{"escalation", AlertsConfig{Rules: map[string]AlertRuleConfig{
+			AlertSaturationDrop: {Escalation: &EscalationConfig{RenotifyTicks: 10, EscalateAfter: 30}},
+			AlertTankEmpty:      {Escalation: &EscalationConfig{RenotifyTicks: 5}},
+		}}, ""},
+		{"negative escalation ticks", AlertsConfig{Rules: map[string]AlertRuleConfig{AlertTankEmpty: {Escalation: &EscalationConfig{RenotifyTicks: -1}}}}, "alert rule tank_empty: escalation ticks cannot be negative"},
+		{"unknown escalation severity", AlertsConfig{Rules: map[string]AlertRuleConfig{AlertTankEmpty: {Escalation: &EscalationConfig{EscalateAfter: 5, EscalateTo: "fatal"}}}}, "alert rule tank_empty: escalation severity must be info, warning or critical: fatal"},
+		{"escalation severity without delay", AlertsConfig{Rules: map[string]AlertRuleConfig{AlertTankEmpty: {Escalation: &EscalationConfig{EscalateTo: SeverityCritical}}}}, "alert rule tank_empty: escalation severity requires escalate_after"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Alerts = &tt.alerts
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestAlertsConfig_WithDefaults(t *testing.T) {
+	threshold := 10.0
+	alerts := AlertsConfig{Rules: map[string]AlertRuleConfig{
+		AlertDeadPlants:  {Threshold: &threshold},
+		AlertExportDrops: {Severity: SeverityInfo},
+		AlertTankEmpty:   {Disabled: true},
+	}}.WithDefaults()
+	if alerts.Window != DefaultAlertWindow || len(alerts.Rules) != len(AlertRules) {
+		t.Fatalf("expected the default window and every rule, got %+v", alerts)
+	}
+	if rule := alerts.Rules[AlertDeadPlants]; *rule.Threshold != 10 || rule.Severity != SeverityCritical {
+		t.Errorf("expected the dead plants threshold overridden, got %+v", rule)
+	}
+	if rule := alerts.Rules[AlertExportDrops]; *rule.Threshold != 0 || rule.Severity != SeverityInfo {
+		t.Errorf("expected the export drops severity overridden, got %+v", rule)
+	}
+	if rule := alerts.Rules[AlertTankEmpty]; !rule.Disabled || rule.Severity != SeverityCritical {
+		t.Errorf("expected the tank rule disabled with its default severity, got %+v", rule)
+	}
+	if rule := alerts.Rules[AlertUnmonitoredSection]; rule.Threshold != nil || rule.Disabled {
+		t.Errorf("expected the unmonitored section rule on without a threshold, got %+v", rule)
+	}
+
+	alerts.Zones = map[string]map[string]AlertRuleConfig{"west": {AlertDeadPlants: {Severity: SeverityWarning}}}
+	if rule, ok := alerts.ZoneRule("west", AlertDeadPlants); !ok || *rule.Threshold != 50 || rule.Severity != SeverityWarning {
+		t.Errorf("expected the zone rule with the default threshold, got %+v", rule)
+	}
+	if _, ok := alerts.ZoneRule("east", AlertDeadPlants); ok {
+		t.Error("expected no rule for a zone without rules")
+	}
+}
+
+func TestValidate_StrictSensorFilters(t *testing.T) {
+	cfg := Default()
+	plant := cfg.Plants[0]
+	cfg.Sensors = append(cfg.Sensors, SensorConfig{ID: "filtered", Type: models.SoilMoisture, SectionID: plant.SectionID, Filter: &PlantFilterConfig{Type: plant.Type, Plants: []string{plant.ID}}})
+	cfg.StrictSensorFilters = true
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected a filter on configured plants to be valid, got %v", err)
+	}
+
+	cfg.Sensors[len(cfg.Sensors)-1].Filter = &PlantFilterConfig{Tag: "no-such-tag"}
+	if err := cfg.Validate(); err == nil || err.Error() != "sensor filtered: filter refers to an unknown tag: no-such-tag" {
+		t.Errorf("expected the unknown tag to be refused, got %v", err)
+	}
+	cfg.StrictSensorFilters = false
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected unknown tags to be allowed without strict filters, got %v", err)
+	}
+}
+
+func TestSensorAdvicePolicy(t *testing.T) {
+	cfg := Default()
+	if policy := cfg.SensorAdvicePolicy(); policy.MaxPlantsPerSensor != sensors.DefaultMaxPlantsPerSensor || len(policy.Required) != 0 {
+		t.Errorf("expected the default policy without required sensors, got %+v", policy)
+	}
+
+	cfg.HVAC = &HVACConfig{HeaterPower: 1}
+	cfg.Disease = &DiseaseConfig{Incubation: 5, HealthDecay: 0.05, SpreadRate: 0.1}
+	cfg.Environment.CO2Baseline = 400
+	cfg.SoilTemperature = &SoilTemperatureConfig{Inertia: 0.9}
+	cfg.WaterTemperature = &WaterTemperatureConfig{Inertia: 0.9}
+	expected := []sensors.SensorRequirement{
+		{Feature: "hvac", Type: models.Temperature},
+		{Feature: "disease", Type: models.Humidity},
+		{Feature: "environment.co2_baseline", Type: models.CO2},
+		{Feature: "soil_temperature", Type: models.SoilTemperature},
+		{Feature: "water_temperature", Type: models.WaterTemperature},
+	}
+	if required := cfg.SensorAdvicePolicy().Required; !reflect.DeepEqual(required, expected) {
+		t.Errorf("expected %+v, got %+v", expected, required)
+	}
+}
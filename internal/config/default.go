@@ -0,0 +1,46 @@
+package config
+
+import (
+	"greenhouse-simulator/internal/models"
+	"greenhouse-simulator/internal/watering"
+	"time"
+)
+
+// Default returns the demo greenhouse used when no config file is given: two
+// preset tomatoes in section-A watered by a schedule, a preset lettuce in
+// section-B watched by a soil moisture sensor and a small tank that queues
+// events when it runs low.
+func Default() *GreenhouseConfig {
+	return &GreenhouseConfig{
+		TickInterval: Duration(4 * time.Second),
+		Environment: EnvironmentConfig{
+			AmbientHumidity: 0.6,
+			HumidityDecay:   0.1,
+		},
+		Plants: []PlantConfig{
+			{ID: "tomato-1", Type: "Tomato", SectionID: "section-A", InitialSaturation: 0.5},
+			{ID: "tomato-2", Type: "Tomato", SectionID: "section-A", InitialSaturation: 0.3},
+			{ID: "lettuce-1", Type: "Lettuce", SectionID: "section-B", InitialSaturation: 0.6},
+		},
+		Sensors: []SensorConfig{
+			{ID: "sensor-1", Type: models.SoilMoisture, SectionID: "section-B"},
+		},
+		Schedules: []ScheduleConfig{
+			{
+				SectionID:        "section-A",
+				TargetSaturation: 0.5,
+				CheckInterval:    3,
+				WaterAmount:      0.4,
+				Duration:         Duration(8 * time.Second),
+				Enabled:          true,
+			},
+		},
+		Tank: &TankConfig{
+			Capacity:          5,
+			InitialLevel:      5,
+			RefillPerTick:     0.05,
+			LowWaterThreshold: 1,
+			ShortagePolicy:    watering.ShortageQueue,
+		},
+	}
+}
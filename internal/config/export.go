@@ -0,0 +1,207 @@
+package config
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/models"
+	"greenhouse-simulator/internal/sensors"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ExportOptions controls what ExportScenario captures.
+type ExportOptions struct {
+	// ExactResume also records each plant's health, growth stage, disease,
+	// modifiers, germination and whether it is alive, so the loaded plants
+	// match the running ones field for field, but for the diagnosis of their
+	// last tick, which their next tick starts over. Without it, plants
+	// restart healthy at the seed stage with their current soil saturation.
+	ExactResume bool
+}
+
+// ExportScenario captures a running greenhouse as a config: the plant types
+// in use, every plant with its current soil saturation as its initial
+// saturation, the soil of their sections, the registered sensors and the
+// given schedules, usually taken from a watering controller snapshot. Entries are ordered by ID so exports
+// of the same greenhouse are identical. The config is stamped with the
+// current SchemaVersion, so that Load can migrate it once the layout changes.
+//
+// The simulator does not track environment or tank settings; callers that
+// use them should copy them onto the returned config. The tick counter is
+// not captured either, so a loaded scenario starts again at tick 0.
+//
+// Returns an error if:
+// - two plants use different definitions under the same plant type name
+// - the captured config does not validate
+func ExportScenario(sim engine.Simulator, sensorMgr sensors.SensorManager, schedules []models.WateringSchedule, opts ExportOptions) (*GreenhouseConfig, error) {
+	cfg := &GreenhouseConfig{SchemaVersion: SchemaVersion, TickInterval: Duration(sim.GetTickInterval())}
+
+	plants := sim.GetAllPlants()
+	slices.SortFunc(plants, func(a, b *models.Plant) int { return strings.Compare(a.ID, b.ID) })
+	types := map[string]*models.PlantType{}
+	for _, plant := range plants {
+		if existing, ok := types[plant.Type.Name]; ok {
+			if !reflect.DeepEqual(existing, plant.Type) {
+				return nil, errors.New("conflicting definitions for plant type: " + plant.Type.Name)
+			}
+		} else {
+			types[plant.Type.Name] = plant.Type
+			cfg.PlantTypes = append(cfg.PlantTypes, plantTypeConfig(*plant.Type))
+		}
+
+		plantCfg := PlantConfig{
+			ID:                  plant.ID,
+			Type:                plant.Type.Name,
+			SectionID:           plant.SectionID,
+			InitialSaturation:   plant.SoilSaturation,
+			Tags:                slices.Clone(plant.Tags),
+			ExcludeFromWatering: plant.ExcludeFromWatering,
+			Quarantined:         plant.Quarantined,
+		}
+		if plant.Position != nil {
+			plantCfg.Position = &PositionConfig{X: plant.Position.X, Y: plant.Position.Y}
+		}
+		if opts.ExactResume {
+			plantCfg.State = &PlantStateConfig{
+				Health:         plant.Health,
+				DeepSaturation: plant.DeepSaturation,
+				GrowthStage:    plant.GrowthStage,
+				Alive:          plant.Alive,
+				DeathCause:     plant.DeathCause,
+				Disease:        plant.Disease.Stage,
+				DiseaseTicks:   plant.Disease.Ticks,
+			}
+			for _, m := range plant.Modifiers {
+				plantCfg.State.Modifiers = append(plantCfg.State.Modifiers, ModifierConfig(m))
+			}
+			if g := plant.Germination; g != nil {
+				plantCfg.State.Germination = &GerminationConfig{Ticks: g.Ticks, Favorable: g.Favorable, Dormant: g.Dormant}
+			}
+		}
+		cfg.Plants = append(cfg.Plants, plantCfg)
+
+		if plant.Soil != nil && !slices.ContainsFunc(cfg.Sections, func(s SectionConfig) bool { return s.ID == plant.SectionID }) {
+			cfg.Sections = append(cfg.Sections, sectionConfig(plant.SectionID, *plant.Soil))
+		}
+	}
+	slices.SortFunc(cfg.PlantTypes, func(a, b PlantTypeConfig) int { return strings.Compare(a.Name, b.Name) })
+	slices.SortFunc(cfg.Sections, func(a, b SectionConfig) int { return strings.Compare(a.ID, b.ID) })
+
+	for _, sensor := range sensorMgr.ListSensors() {
+		sensorCfg := SensorConfig{ID: sensor.ID, Type: sensor.Type, SectionID: sensor.SectionID, Noise: sensor.Noise, Depth: sensor.Depth, SampleInterval: sensor.SampleInterval, RateWindow: sensor.RateWindow, WindowTicks: sensor.WindowTicks}
+		if f := sensor.Filter; !f.Empty() {
+			sensorCfg.Filter = &PlantFilterConfig{Type: f.Type, Tag: f.Tag, Plants: slices.Clone(f.PlantIDs)}
+		}
+		if b := sensor.Battery; b != nil {
+			sensorCfg.Battery = &BatteryConfig{Level: &b.Level, Drain: b.Drain}
+		}
+		if p := sensor.Position; p != nil {
+			sensorCfg.Position = &PositionConfig{X: p.X, Y: p.Y}
+		}
+		cfg.Sensors = append(cfg.Sensors, sensorCfg)
+	}
+	for _, schedule := range schedules {
+		cfg.Schedules = append(cfg.Schedules, scheduleConfig(schedule))
+	}
+	slices.SortFunc(cfg.Schedules, func(a, b ScheduleConfig) int { return strings.Compare(a.ID, b.ID) })
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// WriteScenario validates the config and writes it to w in the given format,
+// ready to be loaded with Load or LoadConfig.
+func (c *GreenhouseConfig) WriteScenario(w io.Writer, format Format) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	return Encode(w, c, format)
+}
+
+// plantTypeConfig converts a models.PlantType into its config form.
+func plantTypeConfig(t models.PlantType) PlantTypeConfig {
+	plantType := PlantTypeConfig{
+		Name:                  t.Name,
+		OptimalSaturation:     t.OptimalSaturation,
+		MinSaturation:         t.MinSaturation,
+		MaxSaturation:         t.MaxSaturation,
+		BaseGrowthRate:        t.BaseGrowthRate,
+		SaturationDepletion:   t.SaturationDepletion,
+		HealthDegradationRate: t.HealthDegradationRate,
+		HealthEnhancementRate: t.HealthEnhancementRate,
+		FrostTolerance:        t.FrostTolerance,
+		RootDepth:             t.RootDepth,
+		MinTemperature:        t.MinTemperature,
+		MaxTemperature:        t.MaxTemperature,
+
+		GerminationTicks:              t.GerminationTicks,
+		GerminationMinSaturation:      t.GerminationMinSaturation,
+		GerminationMaxSaturation:      t.GerminationMaxSaturation,
+		GerminationFailure:            t.GerminationFailure,
+		GerminationMinSoilTemperature: t.GerminationMinSoilTemperature,
+
+		SalinityTolerance:     t.SalinityTolerance,
+		ThermalShockTolerance: t.ThermalShockTolerance,
+	}
+	if t.Growth != (models.GrowthParams{}) {
+		plantType.Growth = &GrowthConfig{
+			MinHealth:          &t.Growth.MinHealth,
+			SlowHealth:         &t.Growth.SlowHealth,
+			SlowFactor:         &t.Growth.SlowFactor,
+			OptimalBonusFactor: &t.Growth.OptimalBonusFactor,
+			OptimalTolerance:   &t.Growth.OptimalTolerance,
+		}
+	}
+	return plantType
+}
+
+// sectionConfig converts the soil of a section into its config form, naming
+// the preset it comes from, if any, and only the coefficients that differ
+// from it.
+func sectionConfig(sectionID string, soil models.SoilType) SectionConfig {
+	section := SectionConfig{ID: sectionID, Retention: soil.Retention, Drainage: soil.Drainage, Percolation: soil.Percolation}
+	if preset, ok := models.PresetSoilType(soil.Name); ok {
+		section.Soil = soil.Name
+		if soil.Retention == preset.Retention {
+			section.Retention = 0
+		}
+		if soil.Drainage == preset.Drainage {
+			section.Drainage = 0
+		}
+	}
+	return section
+}
+
+// scheduleConfig converts a models.WateringSchedule into its config form.
+func scheduleConfig(s models.WateringSchedule) ScheduleConfig {
+	schedule := ScheduleConfig{
+		ID:               s.ID,
+		SectionID:        s.SectionID,
+		ZoneID:           s.ZoneID,
+		Tag:              s.Tag,
+		PlantType:        s.PlantType,
+		TargetSaturation: s.TargetSaturation,
+		CheckInterval:    s.CheckInterval,
+		WaterAmount:      s.WaterAmount,
+		Duration:         Duration(s.Duration),
+		Enabled:          s.Enabled,
+		Method:           s.Method,
+		Distribution:     s.Distribution,
+	}
+	if s.Proportional != nil {
+		schedule.Proportional = &ProportionalConfig{
+			Gain:         s.Proportional.Gain,
+			IntegralGain: s.Proportional.IntegralGain,
+			MinAmount:    s.Proportional.MinAmount,
+			MaxAmount:    s.Proportional.MaxAmount,
+		}
+	}
+	for _, w := range s.AllowedWindows {
+		schedule.AllowedWindows = append(schedule.AllowedWindows, WindowConfig{Start: w.Start, End: w.End})
+	}
+	return schedule
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"bytes"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/models"
+	"greenhouse-simulator/internal/sensors"
+	"greenhouse-simulator/internal/watering"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+var exportBasil = models.PlantType{
+	Name:                  "Basil",
+	OptimalSaturation:     0.6,
+	MinSaturation:         0.3,
+	MaxSaturation:         0.8,
+	BaseGrowthRate:        0.05,
+	SaturationDepletion:   0.02,
+	HealthDegradationRate: 0.04,
+	HealthEnhancementRate: 0.01,
+	MinTemperature:        12,
+	MaxTemperature:        32,
+	SalinityTolerance:     0.3,
+}
+
+// exportSand is the sand preset draining faster, and exportSoil a custom soil.
+var (
+	exportSand = models.SoilType{Name: "Sand", Retention: 0.7, Drainage: 2}
+	exportSoil = models.SoilType{Name: "Custom", Retention: 0.9, Drainage: 0.8}
+)
+
+// newExportedGreenhouse builds a small greenhouse, runs it for a few ticks so
+// the plants drift away from their initial state, and returns its parts.
+func newExportedGreenhouse(t *testing.T) (engine.Simulator, sensors.SensorManager, []models.WateringSchedule) {
+	t.Helper()
+	sim := engine.NewSimulator(2 * time.Second)
+	for _, p := range []struct {
+		id, section string
+		saturation  float64
+		tags        []string
+		soil        models.SoilType
+	}{
+		{"basil-2", "section-A", 0.2, nil, exportSand},
+		{"basil-1", "section-A", 0.5, []string{"seedling"}, exportSand},
+		{"basil-3", "section-B", 0.7, nil, exportSoil},
+	} {
+		plant, err := models.NewPlant(p.id, exportBasil, p.section, p.saturation)
+		if err != nil {
+			t.Fatalf("failed to create plant: %v", err)
+		}
+		plant.Tags = p.tags
+		plant.Soil = &p.soil
+		plant.ExcludeFromWatering = p.id == "basil-2"
+		plant.Quarantined = p.id == "basil-3"
+		if err := sim.AddPlant(plant); err != nil {
+			t.Fatalf("failed to add plant: %v", err)
+		}
+	}
+	for range 6 {
+		sim.Step()
+	}
+
+	sensorMgr := sensors.NewSensorManager(sim, nil, nil)
+	if err := sensorMgr.AddSensor(&models.Sensor{ID: "sensor-1", Type: models.SoilMoisture, SectionID: "section-A"}); err != nil {
+		t.Fatalf("failed to add sensor: %v", err)
+	}
+	if err := sensorMgr.AddSensor(&models.Sensor{ID: "sensor-2", Type: models.Temperature, SectionID: "section-B",
+		Battery: &models.Battery{Level: 0.75, Drain: 0.01}, SampleInterval: 5}); err != nil {
+		t.Fatalf("failed to add sensor: %v", err)
+	}
+
+	ctrl := watering.NewController(nil, nil, watering.Config{})
+	for _, schedule := range []models.WateringSchedule{
+		{SectionID: "section-A", TargetSaturation: 0.5, CheckInterval: 3, WaterAmount: 0.1, Enabled: true, Method: models.MethodDrip},
+		{Tag: "seedling", TargetSaturation: 0.6, CheckInterval: 2, Enabled: true,
+			Proportional: &models.ProportionalControl{Gain: 0.5, MaxAmount: 0.2}},
+	} {
+		if err := ctrl.AddSchedule(schedule); err != nil {
+			t.Fatalf("failed to add schedule: %v", err)
+		}
+	}
+	return sim, sensorMgr, ctrl.Snapshot().Schedules
+}
+
+// plantState strips the fields a scenario does not carry.
+func plantState(plants []*models.Plant) []models.Plant {
+	var state []models.Plant
+	for _, plant := range plants {
+		clone := *plant.Clone()
+		clone.CreatedAt = time.Time{}
+		clone.Diagnosis = models.HealthDiagnosis{}
+		state = append(state, clone)
+	}
+	slices.SortFunc(state, func(a, b models.Plant) int { return strings.Compare(a.ID, b.ID) })
+	return state
+}
+
+func TestExportScenario_ExactResumeRoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatYAML, FormatJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			sim, sensorMgr, schedules := newExportedGreenhouse(t)
+			exported, err := ExportScenario(sim, sensorMgr, schedules, ExportOptions{ExactResume: true})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := exported.WriteScenario(&buf, format); err != nil {
+				t.Fatalf("failed to write scenario: %v", err)
+			}
+			loaded, err := Load(&buf, format)
+			if err != nil {
+				t.Fatalf("failed to load scenario: %v", err)
+			}
+			if !reflect.DeepEqual(loaded, exported) {
+				t.Fatalf("expected the loaded scenario to match the export\nwant: %+v\ngot:  %+v", exported, loaded)
+			}
+
+			resumed := engine.NewSimulator(time.Duration(loaded.TickInterval))
+			plants, err := loaded.BuildPlants()
+			if err != nil {
+				t.Fatalf("failed to build plants: %v", err)
+			}
+			for _, plant := range plants {
+				if err := resumed.AddPlant(plant); err != nil {
+					t.Fatalf("failed to add plant: %v", err)
+				}
+			}
+			if resumed.GetTickInterval() != 2*time.Second {
+				t.Errorf("expected tick interval 2s, got %v", resumed.GetTickInterval())
+			}
+
+			// Both greenhouses keep evolving identically
+			for range 4 {
+				if got, want := plantState(resumed.GetAllPlants()), plantState(sim.GetAllPlants()); !reflect.DeepEqual(got, want) {
+					t.Fatalf("expected resumed plants to match\nwant: %+v\ngot:  %+v", want, got)
+				}
+				sim.Step()
+				resumed.Step()
+			}
+
+			ctrl := watering.NewController(nil, nil, watering.Config{})
+			for _, schedule := range loaded.WateringSchedules() {
+				if err := ctrl.AddSchedule(schedule); err != nil {
+					t.Fatalf("failed to add schedule: %v", err)
+				}
+			}
+			if got := ctrl.Snapshot().Schedules; !reflect.DeepEqual(got, schedules) {
+				t.Errorf("expected schedules to round-trip\nwant: %+v\ngot:  %+v", schedules, got)
+			}
+			level := 0.75
+			expectedSensors := []SensorConfig{
+				{ID: "sensor-1", Type: models.SoilMoisture, SectionID: "section-A"},
+				{ID: "sensor-2", Type: models.Temperature, SectionID: "section-B", Battery: &BatteryConfig{Level: &level, Drain: 0.01}, SampleInterval: 5},
+			}
+			if !reflect.DeepEqual(loaded.Sensors, expectedSensors) {
+				t.Errorf("expected the sensors to round-trip, got %+v", loaded.Sensors)
+			}
+			expectedSections := []SectionConfig{
+				{ID: "section-A", Soil: "Sand", Drainage: 2},
+				{ID: "section-B", Retention: 0.9, Drainage: 0.8},
+			}
+			if !reflect.DeepEqual(loaded.Sections, expectedSections) {
+				t.Errorf("expected the section soils %+v, got %+v", expectedSections, loaded.Sections)
+			}
+		})
+	}
+}
+
+func TestExportScenario_FreshStartKeepsSaturation(t *testing.T) {
+	sim, sensorMgr, schedules := newExportedGreenhouse(t)
+	exported, err := ExportScenario(sim, sensorMgr, schedules, ExportOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	plants, err := exported.BuildPlants()
+	if err != nil {
+		t.Fatalf("failed to build plants: %v", err)
+	}
+
+	original := plantState(sim.GetAllPlants())
+	for i, plant := range plants {
+		if plant.ID != original[i].ID {
+			t.Fatalf("expected plants ordered by ID, got %s at %d", plant.ID, i)
+		}
+		if plant.SoilSaturation != original[i].SoilSaturation {
+			t.Errorf("%s: expected saturation %.2f, got %.2f", plant.ID, original[i].SoilSaturation, plant.SoilSaturation)
+		}
+		if plant.Health != 1 || plant.GrowthStage != 0 || !plant.Alive {
+			t.Errorf("%s: expected a fresh plant, got %v", plant.ID, plant)
+		}
+	}
+	if len(exported.PlantTypes) != 1 || exported.PlantTypes[0].PlantType() != exportBasil {
+		t.Errorf("expected only the Basil type to be exported, got %+v", exported.PlantTypes)
+	}
+}
+
+func TestExportScenario_ConflictingPlantTypes(t *testing.T) {
+	sim := engine.NewSimulator(time.Second)
+	tweaked := exportBasil
+	tweaked.BaseGrowthRate = 0.1
+	for i, plantType := range []models.PlantType{exportBasil, tweaked} {
+		plant, _ := models.NewPlant("basil-"+string(rune('1'+i)), plantType, "section-A", 0.5)
+		if err := sim.AddPlant(plant); err != nil {
+			t.Fatalf("failed to add plant: %v", err)
+		}
+	}
+
+	_, err := ExportScenario(sim, sensors.NewSensorManager(sim, nil, nil), nil, ExportOptions{})
+
+	if err == nil || err.Error() != "conflicting definitions for plant type: Basil" {
+		t.Errorf("expected a conflicting plant type error, got %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"errors"
+	"slices"
+)
+
+// ExporterTypes are the known ExporterConfig types.
+var ExporterTypes = []string{"csv", "sqlite", "eventlog", "influx", "mqtt"}
+
+// ExportConfig attaches output sinks to the greenhouse, see
+// greenhouse.ExportRegistry. Workers is the size of the worker pool the
+// exporters share, 4 when zero.
+type ExportConfig struct {
+	Workers   int              `json:"workers,omitempty" yaml:"workers,omitempty"`
+	Exporters []ExporterConfig `json:"exporters" yaml:"exporters"`
+}
+
+// ExporterConfig is an output sink, named uniquely. Type is one of:
+//   - csv, writing a row per plant per tick to Path, gzipped when it ends in
+//     .gz, with the given Columns (all of them when empty)
+//   - sqlite, recording the run into the database at Path and sampling every
+//     plant every SampleInterval ticks (10 when zero)
+//   - eventlog, appending every event to the JSON Lines log at Path and
+//     syncing it as Sync says: never, on every tick (the default when empty)
+//     or after every event
+//   - influx, exporting the readings with the Influx settings
+//   - mqtt, publishing the readings and stats with the MQTT settings
+//
+// QueueSize is the number of items queued for the exporter before new ones
+// are dropped, 4096 when zero.
+type ExporterConfig struct {
+	Name           string        `json:"name" yaml:"name"`
+	Type           string        `json:"type" yaml:"type"`
+	QueueSize      int           `json:"queue_size,omitempty" yaml:"queue_size,omitempty"`
+	Path           string        `json:"path,omitempty" yaml:"path,omitempty"`
+	Columns        []string      `json:"columns,omitempty" yaml:"columns,omitempty"`
+	SampleInterval int           `json:"sample_interval,omitempty" yaml:"sample_interval,omitempty"`
+	Sync           string        `json:"sync,omitempty" yaml:"sync,omitempty"`
+	Influx         *InfluxConfig `json:"influx,omitempty" yaml:"influx,omitempty"`
+	MQTT           *MQTTConfig   `json:"mqtt,omitempty" yaml:"mqtt,omitempty"`
+}
+
+// validate checks the export settings. Returns an error if:
+// - the worker count is negative
+// - an exporter name is empty or duplicated
+// - an exporter type is unknown, or its queue size or sample interval is
+// negative
+// - a csv, sqlite or eventlog exporter has no path
+// - an eventlog exporter syncs other than never, tick or event
+// - an influx or mqtt exporter is missing its settings, or they are invalid
+func (e ExportConfig) validate() error {
+	if e.Workers < 0 {
+		return errors.New("export workers cannot be negative")
+	}
+	names := map[string]bool{}
+	for _, exporter := range e.Exporters {
+		if exporter.Name == "" {
+			return errors.New("exporter name cannot be empty")
+		}
+		if names[exporter.Name] {
+			return errors.New("duplicate exporter name: " + exporter.Name)
+		}
+		names[exporter.Name] = true
+		if err := exporter.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e ExporterConfig) validate() error {
+	if !slices.Contains(ExporterTypes, e.Type) {
+		return errors.New("exporter type must be csv, sqlite, eventlog, influx or mqtt: " + e.Name)
+	}
+	if e.QueueSize < 0 || e.SampleInterval < 0 {
+		return errors.New("exporter queue size and sample interval cannot be negative: " + e.Name)
+	}
+	switch e.Type {
+	case "csv", "sqlite", "eventlog":
+		if e.Path == "" {
+			return errors.New("exporter path cannot be empty: " + e.Name)
+		}
+		if e.Type == "eventlog" && !slices.Contains([]string{"", "never", "tick", "event"}, e.Sync) {
+			return errors.New("event log sync must be never, tick or event: " + e.Name)
+		}
+	case "influx":
+		if e.Influx == nil {
+			return errors.New("influx exporter needs influx settings: " + e.Name)
+		}
+		return e.Influx.validate()
+	case "mqtt":
+		if e.MQTT == nil {
+			return errors.New("mqtt exporter needs mqtt settings: " + e.Name)
+		}
+		return e.MQTT.validate()
+	}
+	return nil
+}
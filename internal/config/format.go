@@ -0,0 +1,167 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is a config file encoding.
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+// FormatFromPath detects the config format from a file extension: .json,
+// .yaml or .yml. Returns an error for any other extension.
+func FormatFromPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON, nil
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	}
+	return "", errors.New("unsupported config file extension: " + path)
+}
+
+// LoadConfig reads and validates a config file, detecting its format from
+// the file extension.
+func LoadConfig(path string) (*GreenhouseConfig, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f, format)
+}
+
+// Load decodes and validates a config in the given format. Unknown fields
+// are rejected in both formats. A config of an older schema version is
+// migrated to SchemaVersion first, see schemaMigrations, and one of a newer
+// version is refused. The sensor templates are expanded before validation,
+// see GreenhouseConfig.ExpandSensorTemplates.
+func Load(r io.Reader, format Format) (*GreenhouseConfig, error) {
+	if format != FormatJSON && format != FormatYAML {
+		return nil, errors.New("unsupported config format: " + string(format))
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	version, err := schemaVersion(data, format)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = migrate(data, format, version); err != nil {
+		return nil, err
+	}
+
+	var cfg GreenhouseConfig
+	switch format {
+	case FormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&cfg); err != nil {
+			return nil, fmt.Errorf("decoding json config: %w", err)
+		}
+	case FormatYAML:
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&cfg); err != nil {
+			return nil, fmt.Errorf("decoding yaml config: %w", err)
+		}
+	}
+	if err := cfg.ExpandSensorTemplates(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// SaveConfig validates cfg and writes it to path in the format matching the
+// file extension.
+func SaveConfig(cfg *GreenhouseConfig, path string) error {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := Encode(&buf, cfg, format); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// Encode writes cfg to w in the given format.
+func Encode(w io.Writer, cfg *GreenhouseConfig, format Format) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(cfg)
+	case FormatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(cfg); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+	return errors.New("unsupported config format: " + string(format))
+}
+
+// Duration is a time.Duration written as a string such as "4s" in both
+// JSON and YAML.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return errors.New("duration must be a string such as \"4s\"")
+	}
+	return d.parse(s)
+}
+
+func (d Duration) MarshalYAML() (any, error) {
+	return time.Duration(d).String(), nil
+}
+
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	var s string
+	if err := value.Decode(&s); err != nil {
+		return errors.New("duration must be a string such as \"4s\"")
+	}
+	return d.parse(s)
+}
+
+func (d *Duration) parse(s string) error {
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
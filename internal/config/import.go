@@ -0,0 +1,172 @@
+package config
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/models"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ImportError is a row of a plant CSV that could not be imported, with the
+// line it starts on. Line 1 is the header.
+type ImportError struct {
+	Line int
+	Err  error
+}
+
+func (e ImportError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e ImportError) Unwrap() error {
+	return e.Err
+}
+
+// importColumns are the columns a plant CSV must have. A tags column is
+// optional.
+var importColumns = []string{"id", "type", "section", "initial_saturation"}
+
+// ImportPlantsCSV reads plants from a CSV with a header row naming the
+// columns id, type, section, initial_saturation and optionally tags, in any
+// order, as nursery inventories export them. A leading byte order mark is
+// skipped, and quoted fields may hold commas and line breaks. Tags are
+// separated by commas, so a row with several quotes them. Each row is built
+// with models.NewPlant from the type of that name in typeCatalog, such as the
+// one PlantTypeCatalog returns.
+//
+// A row that cannot be imported does not stop the import: it is reported as
+// an ImportError and the valid plants, in file order, are returned ready to
+// be added in one batch. A row is invalid if:
+// - it cannot be parsed as CSV or misses a column
+// - its initial saturation is not a number
+// - its type is not in typeCatalog
+// - models.NewPlant rejects it
+// - its ID was used by an earlier row
+//
+// An input without a usable header imports nothing and is reported as an
+// error on line 1.
+func ImportPlantsCSV(r io.Reader, typeCatalog map[string]models.PlantType) ([]*models.Plant, []ImportError) {
+	buffered := bufio.NewReader(r)
+	if bom, _ := buffered.Peek(3); string(bom) == "\ufeff" {
+		buffered.Discard(3)
+	}
+	reader := csv.NewReader(buffered)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, []ImportError{{Line: 1, Err: fmt.Errorf("plants header: %w", err)}}
+	}
+	index := map[string]int{}
+	for i, column := range header {
+		index[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, column := range importColumns {
+		if _, ok := index[column]; !ok {
+			return nil, []ImportError{{Line: 1, Err: errors.New("plants are missing the column: " + column)}}
+		}
+	}
+
+	var plants []*models.Plant
+	var importErrors []ImportError
+	seen := map[string]bool{}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			importErrors = append(importErrors, ImportError{Line: parseErr.StartLine, Err: parseErr.Err})
+			continue
+		}
+		if err != nil {
+			importErrors = append(importErrors, ImportError{Line: importLine(reader), Err: err})
+			break
+		}
+		line := importLine(reader)
+		plant, err := importPlant(row, index, typeCatalog)
+		if err == nil && seen[plant.ID] {
+			err = errors.New("duplicate plant ID: " + plant.ID)
+		}
+		if err != nil {
+			importErrors = append(importErrors, ImportError{Line: line, Err: err})
+			continue
+		}
+		seen[plant.ID] = true
+		plants = append(plants, plant)
+	}
+	return plants, importErrors
+}
+
+// importLine returns the line the record last read starts on.
+func importLine(reader *csv.Reader) int {
+	line, _ := reader.FieldPos(0)
+	return line
+}
+
+// importPlant builds the plant of a row given the index of each header
+// column.
+func importPlant(row []string, index map[string]int, typeCatalog map[string]models.PlantType) (*models.Plant, error) {
+	field := func(column string) (string, bool) {
+		i, ok := index[column]
+		if !ok || i >= len(row) {
+			return "", false
+		}
+		return strings.TrimSpace(row[i]), true
+	}
+	for _, column := range importColumns {
+		if _, ok := field(column); !ok {
+			return nil, errors.New("missing " + column)
+		}
+	}
+
+	id, _ := field("id")
+	typeName, _ := field("type")
+	sectionID, _ := field("section")
+	text, _ := field("initial_saturation")
+	saturation, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil, errors.New("invalid initial_saturation: " + text)
+	}
+	plantType, ok := typeCatalog[typeName]
+	if !ok {
+		return nil, errors.New("unknown plant type: " + typeName)
+	}
+	plant, err := models.NewPlant(id, plantType, sectionID, saturation)
+	if err != nil {
+		return nil, err
+	}
+	if text, _ := field("tags"); text != "" {
+		for _, tag := range strings.Split(text, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				plant.Tags = append(plant.Tags, tag)
+			}
+		}
+	}
+	return plant, nil
+}
+
+// PlantTypeCatalog returns the plant types plants of the config can use, by
+// name: the presets and the configured types, with the defaults of
+// models.PlantType.Normalize filled in. Returns an error if a configured type
+// is unnamed, duplicated or invalid.
+func (c *GreenhouseConfig) PlantTypeCatalog() (map[string]models.PlantType, error) {
+	return c.plantTypes()
+}
+
+// ImportedPlantConfig returns the config of a plant built by ImportPlantsCSV,
+// to add it to a config file.
+func ImportedPlantConfig(plant *models.Plant) PlantConfig {
+	return PlantConfig{
+		ID:                plant.ID,
+		Type:              plant.Type.Name,
+		SectionID:         plant.SectionID,
+		InitialSaturation: plant.SoilSaturation,
+		Tags:              append([]string(nil), plant.Tags...),
+	}
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestImportPlantsCSV_MixedRows(t *testing.T) {
+	cfg, err := LoadConfig("testdata/greenhouse.yaml")
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	catalog, err := cfg.PlantTypeCatalog()
+	if err != nil {
+		t.Fatalf("failed to resolve the plant types: %v", err)
+	}
+	// The file starts with a byte order mark and quotes an ID and the tags.
+	file, err := os.Open("testdata/plants.csv")
+	if err != nil {
+		t.Fatalf("failed to open plants: %v", err)
+	}
+	defer file.Close()
+
+	plants, importErrors := ImportPlantsCSV(file, catalog)
+
+	expected := []PlantConfig{
+		{ID: "tomato-3", Type: "Tomato", SectionID: "section-A", InitialSaturation: 0.5, Tags: []string{"north", "heirloom"}},
+		{ID: "basil-1", Type: "Sweet Basil", SectionID: "section-B", InitialSaturation: 0.6},
+		{ID: "kale, curly", Type: "Kale", SectionID: "section-B", InitialSaturation: 0.4, Tags: []string{"kitchen"}},
+	}
+	if len(plants) != len(expected) {
+		t.Fatalf("expected %d plants, got %d", len(expected), len(plants))
+	}
+	for i, plant := range plants {
+		if got := ImportedPlantConfig(plant); got.ID != expected[i].ID || got.Type != expected[i].Type || got.SectionID != expected[i].SectionID ||
+			got.InitialSaturation != expected[i].InitialSaturation || !slices.Equal(got.Tags, expected[i].Tags) {
+			t.Errorf("plant %d: expected %+v, got %+v", i, expected[i], got)
+		}
+		if plant.Health != 1 || !plant.Alive {
+			t.Errorf("expected %s to start healthy, got %+v", plant.ID, plant)
+		}
+	}
+	if plants[1].Type.BaseGrowthRate != 0.08 {
+		t.Errorf("expected the configured Sweet Basil, got %+v", plants[1].Type)
+	}
+
+	expectedErrors := []struct {
+		line    int
+		message string
+	}{
+		{5, "unknown plant type: Cactus"},
+		{6, "initial saturation must be between 0.0 and 1.0"},
+		{7, "invalid initial_saturation: wet"},
+		{8, "duplicate plant ID: tomato-3"},
+		{9, "missing section"},
+		{10, "extraneous or missing \" in quoted-field"},
+	}
+	if len(importErrors) != len(expectedErrors) {
+		t.Fatalf("expected %d errors, got %v", len(expectedErrors), importErrors)
+	}
+	for i, want := range expectedErrors {
+		if got := importErrors[i]; got.Line != want.line || got.Err.Error() != want.message {
+			t.Errorf("expected line %d: %s, got %v", want.line, want.message, got)
+		}
+	}
+}
+
+func TestImportPlantsCSV_Header(t *testing.T) {
+	catalog, err := (&GreenhouseConfig{}).PlantTypeCatalog()
+	if err != nil {
+		t.Fatalf("failed to resolve the plant types: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		plants   int
+		errorMsg string
+	}{
+		{"columns in any order", "Section,ID,Initial_Saturation,Type\nsection-A,basil-1,0.5,Basil\n", 1, ""},
+		{"without tags", "id,type,section,initial_saturation\nbasil-1,Basil,section-A,0.5\nbasil-2,Basil,section-A,0.5\n", 2, ""},
+		{"empty", "", 0, "line 1: plants header: EOF"},
+		{"missing column", "id,type,section\nbasil-1,Basil,section-A\n", 0, "line 1: plants are missing the column: initial_saturation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plants, importErrors := ImportPlantsCSV(strings.NewReader(tt.input), catalog)
+			if len(plants) != tt.plants {
+				t.Errorf("expected %d plants, got %d", tt.plants, len(plants))
+			}
+			if tt.errorMsg == "" {
+				if len(importErrors) != 0 {
+					t.Errorf("expected no errors, got %v", importErrors)
+				}
+				return
+			}
+			if len(importErrors) != 1 || importErrors[0].Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got %v", tt.errorMsg, importErrors)
+			}
+		})
+	}
+}
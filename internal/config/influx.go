@@ -0,0 +1,92 @@
+package config
+
+import (
+	"errors"
+	"net/url"
+	"time"
+)
+
+// InfluxConfig exports sensor readings to an InfluxDB HTTP endpoint, see
+// package influx. Timestamps is "wall" to stamp points with the time they were
+// read, or "sim" to stamp them with the simulated time, counted from SimStart
+// (by default the moment the export starts). Drop is "oldest" or "newest" and
+// picks which points go once BufferSize points wait. Zero values mean the
+// defaults: wall clock timestamps, batches of 500 points flushed at least every
+// second, a buffer of 10000 points dropping the oldest, and retries backing off
+// from 1s to 30s.
+type InfluxConfig struct {
+	URL           string    `json:"url" yaml:"url"`
+	Org           string    `json:"org,omitempty" yaml:"org,omitempty"`
+	Bucket        string    `json:"bucket" yaml:"bucket"`
+	Token         string    `json:"token,omitempty" yaml:"token,omitempty"`
+	Timestamps    string    `json:"timestamps,omitempty" yaml:"timestamps,omitempty"`
+	SimStart      time.Time `json:"sim_start,omitzero" yaml:"sim_start,omitempty"`
+	BatchSize     int       `json:"batch_size,omitempty" yaml:"batch_size,omitempty"`
+	FlushInterval Duration  `json:"flush_interval,omitempty" yaml:"flush_interval,omitempty"`
+	BufferSize    int       `json:"buffer_size,omitempty" yaml:"buffer_size,omitempty"`
+	Drop          string    `json:"drop,omitempty" yaml:"drop,omitempty"`
+	RetryMin      Duration  `json:"retry_min,omitempty" yaml:"retry_min,omitempty"`
+	RetryMax      Duration  `json:"retry_max,omitempty" yaml:"retry_max,omitempty"`
+}
+
+// WithDefaults returns a copy of the config with zero values replaced by
+// their defaults. SimStart stays zero; the exporter fills it in.
+func (i InfluxConfig) WithDefaults() InfluxConfig {
+	if i.Timestamps == "" {
+		i.Timestamps = "wall"
+	}
+	if i.BatchSize == 0 {
+		i.BatchSize = 500
+	}
+	if i.FlushInterval == 0 {
+		i.FlushInterval = Duration(time.Second)
+	}
+	if i.BufferSize == 0 {
+		i.BufferSize = 10000
+	}
+	if i.Drop == "" {
+		i.Drop = "oldest"
+	}
+	if i.RetryMin == 0 {
+		i.RetryMin = Duration(time.Second)
+	}
+	if i.RetryMax == 0 {
+		i.RetryMax = Duration(30 * time.Second)
+	}
+	return i
+}
+
+// validate checks the InfluxDB settings. Returns an error if:
+// - the URL is not an http or https URL, or the bucket is empty
+// - the timestamps or drop policy are unknown
+// - a size, the flush interval or a retry delay is negative
+// - the batch size exceeds the buffer size, or the minimum retry delay
+// exceeds the maximum
+func (i InfluxConfig) validate() error {
+	if u, err := url.Parse(i.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("invalid influx url: " + i.URL)
+	}
+	if i.Bucket == "" {
+		return errors.New("influx bucket cannot be empty")
+	}
+	if i.Timestamps != "" && i.Timestamps != "wall" && i.Timestamps != "sim" {
+		return errors.New("influx timestamps must be wall or sim: " + i.Timestamps)
+	}
+	if i.Drop != "" && i.Drop != "oldest" && i.Drop != "newest" {
+		return errors.New("influx drop policy must be oldest or newest: " + i.Drop)
+	}
+	if i.BatchSize < 0 || i.BufferSize < 0 {
+		return errors.New("influx batch and buffer sizes cannot be negative")
+	}
+	if i.FlushInterval < 0 || i.RetryMin < 0 || i.RetryMax < 0 {
+		return errors.New("influx flush interval and retry delays cannot be negative")
+	}
+	defaults := i.WithDefaults()
+	if defaults.BatchSize > defaults.BufferSize {
+		return errors.New("influx batch size cannot exceed the buffer size")
+	}
+	if defaults.RetryMin > defaults.RetryMax {
+		return errors.New("influx minimum retry delay cannot exceed the maximum")
+	}
+	return nil
+}
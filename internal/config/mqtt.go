@@ -0,0 +1,93 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// MQTTConfig connects the simulation to an MQTT broker, see package mqtt.
+// Zero values mean the defaults: topic prefix "greenhouse", greenhouse name
+// "default", client ID "greenhouse-simulator", a buffer of 1000 messages and
+// reconnect attempts backing off from 1s to 30s. AlertTopics routes the
+// alerts of a severity, by severity, to a topic of their own under
+// {prefix}/{greenhouse} instead of alerts.
+type MQTTConfig struct {
+	Broker       string            `json:"broker" yaml:"broker"`
+	Username     string            `json:"username,omitempty" yaml:"username,omitempty"`
+	Password     string            `json:"password,omitempty" yaml:"password,omitempty"`
+	ClientID     string            `json:"client_id,omitempty" yaml:"client_id,omitempty"`
+	QoS          byte              `json:"qos,omitempty" yaml:"qos,omitempty"`
+	TopicPrefix  string            `json:"topic_prefix,omitempty" yaml:"topic_prefix,omitempty"`
+	Greenhouse   string            `json:"greenhouse,omitempty" yaml:"greenhouse,omitempty"`
+	BufferSize   int               `json:"buffer_size,omitempty" yaml:"buffer_size,omitempty"`
+	ReconnectMin Duration          `json:"reconnect_min,omitempty" yaml:"reconnect_min,omitempty"`
+	ReconnectMax Duration          `json:"reconnect_max,omitempty" yaml:"reconnect_max,omitempty"`
+	AlertTopics  map[string]string `json:"alert_topics,omitempty" yaml:"alert_topics,omitempty"`
+}
+
+// WithDefaults returns a copy of the config with zero values replaced by
+// their defaults.
+func (m MQTTConfig) WithDefaults() MQTTConfig {
+	if m.TopicPrefix == "" {
+		m.TopicPrefix = "greenhouse"
+	}
+	if m.Greenhouse == "" {
+		m.Greenhouse = "default"
+	}
+	if m.ClientID == "" {
+		m.ClientID = "greenhouse-simulator"
+	}
+	if m.BufferSize == 0 {
+		m.BufferSize = 1000
+	}
+	if m.ReconnectMin == 0 {
+		m.ReconnectMin = Duration(time.Second)
+	}
+	if m.ReconnectMax == 0 {
+		m.ReconnectMax = Duration(30 * time.Second)
+	}
+	return m
+}
+
+// validate checks the MQTT settings. Returns an error if:
+// - the broker URL is empty
+// - the QoS is above 2
+// - the topic prefix or greenhouse name contains an MQTT wildcard, or the
+// greenhouse name a topic separator
+// - the buffer size or a reconnect delay is negative, or the minimum
+// reconnect delay exceeds the maximum
+// - an alert topic is for an unknown severity, is empty or contains an MQTT
+// wildcard
+func (m MQTTConfig) validate() error {
+	if m.Broker == "" {
+		return errors.New("mqtt broker cannot be empty")
+	}
+	if m.QoS > 2 {
+		return errors.New("mqtt qos must be 0, 1 or 2")
+	}
+	if strings.ContainsAny(m.TopicPrefix, "+#") {
+		return errors.New("mqtt topic prefix cannot contain wildcards: " + m.TopicPrefix)
+	}
+	if strings.ContainsAny(m.Greenhouse, "+#/") {
+		return errors.New("mqtt greenhouse name cannot contain wildcards or slashes: " + m.Greenhouse)
+	}
+	if m.BufferSize < 0 {
+		return errors.New("mqtt buffer size cannot be negative")
+	}
+	if m.ReconnectMin < 0 || m.ReconnectMax < 0 {
+		return errors.New("mqtt reconnect delays cannot be negative")
+	}
+	if defaults := m.WithDefaults(); defaults.ReconnectMin > defaults.ReconnectMax {
+		return errors.New("mqtt minimum reconnect delay cannot exceed the maximum")
+	}
+	for severity, topic := range m.AlertTopics {
+		if SeverityRank(severity) == 0 {
+			return errors.New("mqtt alert topic severity must be info, warning or critical: " + severity)
+		}
+		if topic == "" || strings.ContainsAny(topic, "+#") {
+			return errors.New("mqtt alert topic cannot be empty or contain wildcards: " + severity)
+		}
+	}
+	return nil
+}
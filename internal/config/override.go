@@ -0,0 +1,156 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvOverrides maps the environment variables read by ApplyEnv to the config
+// keys they set:
+//
+//	GREENHOUSE_TICK_INTERVAL  tick_interval, a duration such as 500ms
+//	GREENHOUSE_SEED           seed, an integer
+//	GREENHOUSE_LOG_LEVEL      log_level, one of debug, info, warn or error
+var EnvOverrides = map[string]string{
+	"GREENHOUSE_TICK_INTERVAL": "tick_interval",
+	"GREENHOUSE_SEED":          "seed",
+	"GREENHOUSE_LOG_LEVEL":     "log_level",
+}
+
+// ApplyEnv applies the EnvOverrides that are set, reading them with lookup,
+// usually os.LookupEnv. The config is not validated.
+func (c *GreenhouseConfig) ApplyEnv(lookup func(string) (string, bool)) error {
+	for _, name := range slices.Sorted(maps.Keys(EnvOverrides)) {
+		if value, ok := lookup(name); ok {
+			if err := c.Set(EnvOverrides[name], value); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ApplySets applies overrides written as key.path=value, in order, as given
+// to the --set flag. The config is not validated.
+func (c *GreenhouseConfig) ApplySets(sets []string) error {
+	for _, set := range sets {
+		path, value, ok := strings.Cut(set, "=")
+		if !ok {
+			return errors.New("override must be written as key.path=value: " + set)
+		}
+		if err := c.Set(path, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Set assigns value to the config field at path. Path segments are the keys
+// used in config files, separated by dots; list elements are addressed by
+// index, as in plants.0.initial_saturation. A missing optional block such as
+// tank is created. The config is not validated.
+// Returns an error if:
+// - a segment of the path does not exist or an index is out of range
+// - value cannot be converted to the type of the field
+func (c *GreenhouseConfig) Set(path, value string) error {
+	field := reflect.ValueOf(c).Elem()
+	for _, segment := range strings.Split(path, ".") {
+		if field.Kind() == reflect.Pointer {
+			if field.IsNil() {
+				field.Set(reflect.New(field.Type().Elem()))
+			}
+			field = field.Elem()
+		}
+		next, ok := child(field, segment)
+		if !ok {
+			return errors.New("unknown config key: " + path)
+		}
+		field = next
+	}
+	return setValue(field, path, value)
+}
+
+// child returns the struct field with the given key, or the slice element
+// with the given index.
+func child(v reflect.Value, segment string) (reflect.Value, bool) {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := range v.NumField() {
+			key, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("yaml"), ",")
+			if key == segment {
+				return v.Field(i), true
+			}
+		}
+	case reflect.Slice:
+		index, err := strconv.Atoi(segment)
+		if err == nil && index >= 0 && index < v.Len() {
+			return v.Index(index), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+var durationType = reflect.TypeOf(Duration(0))
+
+// setValue converts value to the type of field and assigns it.
+func setValue(field reflect.Value, path, value string) error {
+	invalid := func(expected string) error {
+		return fmt.Errorf("invalid value %q for %s: expected %s", value, path, expected)
+	}
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		field = field.Elem()
+	}
+
+	switch {
+	case field.Type() == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return invalid("a duration such as 4s")
+		}
+		field.SetInt(int64(d))
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+	case field.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return invalid("true or false")
+		}
+		field.SetBool(b)
+	case field.CanInt():
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return invalid("an integer")
+		}
+		field.SetInt(n)
+	case field.CanUint():
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return invalid("a non-negative integer")
+		}
+		field.SetUint(n)
+	case field.CanFloat():
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return invalid("a number")
+		}
+		field.SetFloat(f)
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		var items []string
+		if value != "" {
+			items = strings.Split(value, ",")
+		}
+		field.Set(reflect.ValueOf(items).Convert(field.Type()))
+	default:
+		return errors.New("config key cannot be set from a single value: " + path)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"greenhouse-simulator/internal/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		path  string
+		value string
+		check func(cfg *GreenhouseConfig) bool
+	}{
+		{"tick_interval", "500ms", func(cfg *GreenhouseConfig) bool { return time.Duration(cfg.TickInterval) == 500*time.Millisecond }},
+		{"seed", "-3", func(cfg *GreenhouseConfig) bool { return cfg.Seed == -3 }},
+		{"log_level", "debug", func(cfg *GreenhouseConfig) bool { return cfg.LogLevel == "debug" }},
+		{"environment.ticks_per_day", "48", func(cfg *GreenhouseConfig) bool { return cfg.Environment.TicksPerDay == 48 }},
+		{"plants.1.initial_saturation", "0.25", func(cfg *GreenhouseConfig) bool { return cfg.Plants[1].InitialSaturation == 0.25 }},
+		{"plants.0.tags", "north,east", func(cfg *GreenhouseConfig) bool { return slices.Equal(cfg.Plants[0].Tags, []string{"north", "east"}) }},
+		{"schedules.0.enabled", "false", func(cfg *GreenhouseConfig) bool { return !cfg.Schedules[0].Enabled }},
+		{"schedules.0.method", "misting", func(cfg *GreenhouseConfig) bool { return cfg.Schedules[0].Method == models.MethodMisting }},
+		{"tank.capacity", "20", func(cfg *GreenhouseConfig) bool { return cfg.Tank != nil && cfg.Tank.Capacity == 20 }},
+		{"mqtt.qos", "1", func(cfg *GreenhouseConfig) bool { return cfg.MQTT != nil && cfg.MQTT.QoS == 1 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			cfg := Default()
+			cfg.Tank = nil
+			if err := cfg.Set(tt.path, tt.value); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.check(cfg) {
+				t.Errorf("expected %s to be set to %s, got %+v", tt.path, tt.value, cfg)
+			}
+		})
+	}
+}
+
+func TestSet_Errors(t *testing.T) {
+	tests := []struct {
+		path     string
+		value    string
+		errorMsg string
+	}{
+		{"tick_rate", "1s", "unknown config key: tick_rate"},
+		{"plants.9.initial_saturation", "0.5", "unknown config key: plants.9.initial_saturation"},
+		{"plants", "p1", "config key cannot be set from a single value: plants"},
+		{"seed", "abc", `invalid value "abc" for seed: expected an integer`},
+		{"tick_interval", "fast", `invalid value "fast" for tick_interval: expected a duration such as 4s`},
+		{"environment.ambient_humidity", "humid", `invalid value "humid" for environment.ambient_humidity: expected a number`},
+		{"schedules.0.enabled", "yes please", `invalid value "yes please" for schedules.0.enabled: expected true or false`},
+		{"mqtt.qos", "-1", `invalid value "-1" for mqtt.qos: expected a non-negative integer`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			err := Default().Set(tt.path, tt.value)
+			if err == nil {
+				t.Fatal("expected error but got nil")
+			}
+			if err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestApplyEnv_ThenSets(t *testing.T) {
+	env := map[string]string{
+		"GREENHOUSE_TICK_INTERVAL": "1s",
+		"GREENHOUSE_SEED":          "7",
+		"GREENHOUSE_LOG_LEVEL":     "warn",
+		"UNRELATED":                "x",
+	}
+	cfg := Default()
+	if err := cfg.ApplyEnv(func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cfg.ApplySets([]string{"seed=8", "seed=9"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if time.Duration(cfg.TickInterval) != time.Second || cfg.LogLevel != "warn" {
+		t.Errorf("expected the environment to apply, got %+v", cfg)
+	}
+	if cfg.Seed != 9 {
+		t.Errorf("expected the last --set to win with seed 9, got %d", cfg.Seed)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected a valid config, got %v", err)
+	}
+}
+
+func TestApplyEnv_NamesTheVariable(t *testing.T) {
+	err := Default().ApplyEnv(func(name string) (string, bool) {
+		return "soon", name == "GREENHOUSE_TICK_INTERVAL"
+	})
+	expected := `GREENHOUSE_TICK_INTERVAL: invalid value "soon" for tick_interval: expected a duration such as 4s`
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error '%s', got %v", expected, err)
+	}
+}
+
+func TestApplySets_RequiresKeyValue(t *testing.T) {
+	err := Default().ApplySets([]string{"seed"})
+	if err == nil || err.Error() != "override must be written as key.path=value: seed" {
+		t.Errorf("expected a malformed override error, got %v", err)
+	}
+}
+
+func TestValidate_LogLevel(t *testing.T) {
+	cfg := Default()
+	cfg.LogLevel = "loud"
+	if err := cfg.Validate(); err == nil || err.Error() != "unknown log level: loud" {
+		t.Errorf("expected an unknown log level error, got %v", err)
+	}
+}
+
+func TestValidate_SensorNoise(t *testing.T) {
+	cfg := Default()
+	cfg.Sensors[0].Noise = -0.1
+	if err := cfg.Validate(); err == nil || err.Error() != "sensor noise cannot be negative: sensor-1" {
+		t.Errorf("expected a negative noise error, got %v", err)
+	}
+}
+
+func TestValidate_SensorDepth(t *testing.T) {
+	cfg := Default()
+	cfg.Sensors[0].Depth = models.Deep
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	cfg.Sensors[0].Depth = "bedrock"
+	if err := cfg.Validate(); err == nil || err.Error() != "sensor sensor-1: soil depth must be surface or deep: bedrock" {
+		t.Errorf("expected an unknown depth error, got %v", err)
+	}
+	cfg.Sensors[0].Depth = models.Deep
+	cfg.Sensors[0].Type = models.Temperature
+	if err := cfg.Validate(); err == nil || err.Error() != "only soil moisture sensors have a depth: sensor-1" {
+		t.Errorf("expected a depth on a temperature sensor to be refused, got %v", err)
+	}
+}
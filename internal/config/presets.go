@@ -0,0 +1,125 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"greenhouse-simulator/internal/models"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// plantTypeFields has the fields of PlantTypeConfig without its decoding
+// methods, so it can be decoded with the default behaviour.
+type plantTypeFields PlantTypeConfig
+
+// UnmarshalJSON decodes a plant type, starting from its preset when it
+// extends one so that only the overridden fields need to be given.
+func (t *PlantTypeConfig) UnmarshalJSON(data []byte) error {
+	return t.decode(strictJSON(data))
+}
+
+// UnmarshalYAML decodes a plant type, starting from its preset when it
+// extends one so that only the overridden fields need to be given.
+func (t *PlantTypeConfig) UnmarshalYAML(value *yaml.Node) error {
+	decode, err := strictYAML(value)
+	if err != nil {
+		return err
+	}
+	return t.decode(decode)
+}
+
+// strictJSON returns a function decoding data into v, rejecting unknown
+// fields. It can be called repeatedly to decode on top of earlier values.
+func strictJSON(data []byte) func(v any) error {
+	return func(v any) error {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		return decoder.Decode(v)
+	}
+}
+
+// strictYAML is strictJSON for a YAML node. Node.Decode ignores the
+// KnownFields setting of the outer decoder, so it decodes a re-encoded copy
+// of the node with a strict decoder instead.
+func strictYAML(value *yaml.Node) (func(v any) error, error) {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return func(v any) error {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		return decoder.Decode(v)
+	}, nil
+}
+
+// decode runs decode once to find the preset to extend, then again on top
+// of the preset so the given fields override it. A plant type that extends
+// a preset without a name of its own replaces the preset.
+func (t *PlantTypeConfig) decode(decode func(v any) error) error {
+	var fields plantTypeFields
+	if err := decode(&fields); err != nil {
+		return err
+	}
+	if fields.Extends != "" {
+		base, err := presetPlantType(fields.Extends)
+		if err != nil {
+			return err
+		}
+		fields = plantTypeFields(plantTypeConfig(base))
+		if err := decode(&fields); err != nil {
+			return err
+		}
+	}
+	*t = PlantTypeConfig(fields)
+	return nil
+}
+
+// presetPlantType looks up a preset by name. The error for an unknown name
+// lists the known presets and suggests the closest one.
+func presetPlantType(name string) (models.PlantType, error) {
+	if preset, ok := models.PresetPlantType(name); ok {
+		return preset, nil
+	}
+	var names []string
+	for _, preset := range models.PresetPlantTypes() {
+		names = append(names, preset.Name)
+	}
+	return models.PlantType{}, unknownName("plant type preset", "presets", name, names)
+}
+
+// unknownName reports a name missing from a built-in catalog, listing the
+// known names and suggesting the first one within two edits of it.
+func unknownName(kind, plural, name string, known []string) error {
+	suggestion := ""
+	for _, candidate := range known {
+		if editDistance(strings.ToLower(name), strings.ToLower(candidate)) <= 2 {
+			suggestion = "did you mean " + candidate + "? "
+			break
+		}
+	}
+	return fmt.Errorf("unknown %s: %s (%sknown %s: %s)", kind, name, suggestion, plural, strings.Join(known, ", "))
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"bytes"
+	_ "embed"
+	"maps"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed profiles.yaml
+var profilesYAML []byte
+
+// environmentFields has the fields of EnvironmentConfig without its decoding
+// methods, so it can be decoded with the default behaviour.
+type environmentFields EnvironmentConfig
+
+// environmentProfiles decodes the embedded profiles. They are checked by the
+// tests, so a decoding error is a build mistake.
+func environmentProfiles() map[string]environmentFields {
+	var profiles map[string]environmentFields
+	decoder := yaml.NewDecoder(bytes.NewReader(profilesYAML))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&profiles); err != nil {
+		panic("config: invalid embedded environment profiles: " + err.Error())
+	}
+	return profiles
+}
+
+// EnvironmentProfile returns the built-in environment profile with the given
+// name. Returns an error listing the known profiles if there is none.
+func EnvironmentProfile(name string) (EnvironmentConfig, error) {
+	profiles := environmentProfiles()
+	profile, ok := profiles[name]
+	if !ok {
+		return EnvironmentConfig{}, unknownName("environment profile", "profiles", name, EnvironmentProfiles())
+	}
+	profile.Profile = name
+	return EnvironmentConfig(profile), nil
+}
+
+// EnvironmentProfiles returns the names of the built-in environment profiles
+// in alphabetical order.
+func EnvironmentProfiles() []string {
+	return slices.Sorted(maps.Keys(environmentProfiles()))
+}
+
+// ApplyProfile replaces the environment with the named built-in profile, so
+// the profile alone determines the climate. Returns an error if there is no
+// such profile.
+func (c *GreenhouseConfig) ApplyProfile(name string) error {
+	profile, err := EnvironmentProfile(name)
+	if err != nil {
+		return err
+	}
+	c.Environment = profile
+	return nil
+}
+
+// UnmarshalJSON decodes an environment, starting from its profile when it
+// names one so that only the overridden fields need to be given.
+func (e *EnvironmentConfig) UnmarshalJSON(data []byte) error {
+	return e.decode(strictJSON(data))
+}
+
+// UnmarshalYAML decodes an environment, starting from its profile when it
+// names one so that only the overridden fields need to be given.
+func (e *EnvironmentConfig) UnmarshalYAML(value *yaml.Node) error {
+	decode, err := strictYAML(value)
+	if err != nil {
+		return err
+	}
+	return e.decode(decode)
+}
+
+// decode runs decode once to find the profile, then again on top of the
+// profile so the given fields override it.
+func (e *EnvironmentConfig) decode(decode func(v any) error) error {
+	var fields environmentFields
+	if err := decode(&fields); err != nil {
+		return err
+	}
+	if fields.Profile != "" {
+		profile, err := EnvironmentProfile(fields.Profile)
+		if err != nil {
+			return err
+		}
+		fields = environmentFields(profile)
+		if err := decode(&fields); err != nil {
+			return err
+		}
+	}
+	*e = EnvironmentConfig(fields)
+	return nil
+}
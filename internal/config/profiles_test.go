@@ -0,0 +1,169 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnvironmentProfiles_AreValid(t *testing.T) {
+	names := EnvironmentProfiles()
+	if strings.Join(names, ",") != "summer,winter" {
+		t.Errorf("expected the summer and winter profiles, got %v", names)
+	}
+	for _, name := range names {
+		cfg := Default()
+		if err := cfg.ApplyProfile(name); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("%s: expected a valid profile, got %v", name, err)
+		}
+		if cfg.Environment.Profile != name {
+			t.Errorf("%s: expected the profile name to be recorded, got %q", name, cfg.Environment.Profile)
+		}
+	}
+}
+
+func TestLoad_EnvironmentProfiles(t *testing.T) {
+	summer, _ := EnvironmentProfile("summer")
+	rainySummer := summer
+	rainySummer.RainChance = 0.3
+
+	tests := []struct {
+		name     string
+		yaml     string
+		json     string
+		expected EnvironmentConfig
+	}{
+		{
+			"profile",
+			"tick_interval: 1s\nenvironment:\n  profile: summer\nplants: []",
+			`{"tick_interval": "1s", "environment": {"profile": "summer"}, "plants": []}`,
+			summer,
+		},
+		{
+			"override a single field of a profile",
+			"tick_interval: 1s\nenvironment:\n  profile: summer\n  rain_chance: 0.3\nplants: []",
+			`{"tick_interval": "1s", "environment": {"profile": "summer", "rain_chance": 0.3}, "plants": []}`,
+			rainySummer,
+		},
+		{
+			"no profile",
+			"tick_interval: 1s\nenvironment:\n  ambient_humidity: 0.5\n  temperature: 20\nplants: []",
+			`{"tick_interval": "1s", "environment": {"ambient_humidity": 0.5, "temperature": 20}, "plants": []}`,
+			EnvironmentConfig{AmbientHumidity: 0.5, Temperature: 20},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for format, input := range map[Format]string{FormatYAML: tt.yaml, FormatJSON: tt.json} {
+				cfg, err := Load(strings.NewReader(input), format)
+				if err != nil {
+					t.Fatalf("%s: unexpected error: %v", format, err)
+				}
+				if cfg.Environment != tt.expected {
+					t.Errorf("%s: expected environment %+v, got %+v", format, tt.expected, cfg.Environment)
+				}
+			}
+		})
+	}
+}
+
+func TestLoad_EnvironmentErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		json     string
+		errorMsg string
+	}{
+		{
+			"misspelled profile",
+			"tick_interval: 1s\nenvironment: {profile: sumer}",
+			`{"tick_interval": "1s", "environment": {"profile": "sumer"}}`,
+			"unknown environment profile: sumer (did you mean summer? known profiles: summer, winter)",
+		},
+		{
+			"humidity above 1",
+			"tick_interval: 1s\nenvironment: {profile: winter, ambient_humidity: 1.5}",
+			`{"tick_interval": "1s", "environment": {"profile": "winter", "ambient_humidity": 1.5}}`,
+			"ambient humidity must be between 0.0 and 1.0",
+		},
+		{
+			"co2 settings without a baseline",
+			"tick_interval: 1s\nenvironment: {co2_uptake: 0.5}",
+			`{"tick_interval": "1s", "environment": {"co2_uptake": 0.5}}`,
+			"co2 settings require a co2 baseline",
+		},
+		{
+			"negative day length",
+			"tick_interval: 1s\nenvironment: {ticks_per_day: -1}",
+			`{"tick_interval": "1s", "environment": {"ticks_per_day": -1}}`,
+			"ticks per day cannot be negative",
+		},
+		{
+			"weather without a day length",
+			"tick_interval: 1s\nenvironment: {profile: summer, ticks_per_day: 0}",
+			`{"tick_interval": "1s", "environment": {"profile": "summer", "ticks_per_day": 0}}`,
+			"weather and seasonal drift need a day length of at least 1 tick",
+		},
+		{
+			"rain chance above 1",
+			"tick_interval: 1s\nenvironment: {ticks_per_day: 24, rain_chance: 2}",
+			`{"tick_interval": "1s", "environment": {"ticks_per_day": 24, "rain_chance": 2}}`,
+			"rain chance must be between 0.0 and 1.0",
+		},
+		{
+			"light above 1",
+			"tick_interval: 1s\nenvironment: {light: 1.2}",
+			`{"tick_interval": "1s", "environment": {"light": 1.2}}`,
+			"light must be between 0.0 and 1.0",
+		},
+		{
+			"unknown field",
+			"tick_interval: 1s\nenvironment: {profile: summer, wind: 3}",
+			`{"tick_interval": "1s", "environment": {"profile": "summer", "wind": 3}}`,
+			"wind",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, yamlErr := Load(strings.NewReader(tt.yaml), FormatYAML)
+			_, jsonErr := Load(strings.NewReader(tt.json), FormatJSON)
+			if yamlErr == nil || jsonErr == nil {
+				t.Fatalf("expected errors from both formats, got yaml=%v json=%v", yamlErr, jsonErr)
+			}
+			if !strings.Contains(yamlErr.Error(), tt.errorMsg) || !strings.Contains(jsonErr.Error(), tt.errorMsg) {
+				t.Errorf("expected error message containing '%s', got yaml='%s' json='%s'", tt.errorMsg, yamlErr, jsonErr)
+			}
+		})
+	}
+}
+
+func TestApplyProfile_DeterminesClimate(t *testing.T) {
+	build := func(seed int64, ambient float64) *GreenhouseConfig {
+		cfg := Default()
+		cfg.Seed = seed
+		cfg.Environment.AmbientHumidity = ambient
+		if err := cfg.ApplyProfile("winter"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return cfg
+	}
+
+	// The environment the config had before does not leak into the profile.
+	first, second := build(3, 0.2).Climate(), build(3, 0.9).Climate()
+	if first != second {
+		t.Fatalf("expected the same profile and seed to give the same climate, got %+v and %+v", first, second)
+	}
+	for tick := range 2000 {
+		if first.At(tick) != second.At(tick) {
+			t.Fatalf("tick %d: expected identical conditions", tick)
+		}
+	}
+
+	if err := build(3, 0.2).ApplyProfile("autumn"); err == nil {
+		t.Error("expected an error for an unknown profile")
+	}
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Rotation overlap policies, see RotationConfig.
+const (
+	RotationDelay      = "delay"
+	RotationInterplant = "interplant"
+)
+
+// RotationConfig is the crop rotation plan of a section: the crops planted in
+// it one after the other, see CropConfig. While the rotation runs, the mature
+// plants of the section are harvested and its dead ones cleared every tick,
+// those of the config included. Overlap decides what happens to a crop due
+// at its tick while plants of the previous crops are still growing: delay,
+// the default, waits for the section to be cleared, and interplant plants it
+// among them. A paused rotation neither harvests nor plants, see
+// greenhouse.Greenhouse.PauseRotation.
+type RotationConfig struct {
+	SectionID string       `json:"section" yaml:"section"`
+	Crops     []CropConfig `json:"crops" yaml:"crops"`
+	Overlap   string       `json:"overlap,omitempty" yaml:"overlap,omitempty"`
+	Paused    bool         `json:"paused,omitempty" yaml:"paused,omitempty"`
+}
+
+// CropConfig is a crop of a rotation: Count plants of the plant type Type,
+// planted at InitialSaturation once the simulation reaches Tick or, with
+// AfterHarvest, once the previous crops are harvested and the section is
+// empty.
+type CropConfig struct {
+	Type              string  `json:"type" yaml:"type"`
+	Count             int     `json:"count" yaml:"count"`
+	Tick              int     `json:"tick,omitempty" yaml:"tick,omitempty"`
+	AfterHarvest      bool    `json:"after_harvest,omitempty" yaml:"after_harvest,omitempty"`
+	InitialSaturation float64 `json:"initial_saturation" yaml:"initial_saturation"`
+}
+
+// Interplants reports whether due crops are planted among the plants still
+// growing, see RotationConfig.
+func (r RotationConfig) Interplants() bool {
+	return r.Overlap == RotationInterplant
+}
+
+// ValidateRotation checks a rotation against the plant types of the config
+// and the presets. Returns an error if:
+// - the section is empty or the overlap is unknown
+// - the rotation has no crops
+// - a crop refers to an unknown plant type
+// - a crop count is not positive, a tick is negative or set with
+// after_harvest, or an initial saturation is not between 0.0 and 1.0
+func (c *GreenhouseConfig) ValidateRotation(r RotationConfig) error {
+	if r.SectionID == "" {
+		return errors.New("rotation section cannot be empty")
+	}
+	if r.Overlap != "" && r.Overlap != RotationDelay && r.Overlap != RotationInterplant {
+		return errors.New("rotation overlap must be delay or interplant: " + r.Overlap)
+	}
+	if len(r.Crops) == 0 {
+		return fmt.Errorf("rotation of section %s has no crops", r.SectionID)
+	}
+	types, err := c.plantTypes()
+	if err != nil {
+		return err
+	}
+	for _, crop := range r.Crops {
+		if _, ok := types[crop.Type]; !ok {
+			return fmt.Errorf("rotation of section %s: unknown plant type: %s", r.SectionID, crop.Type)
+		}
+		if crop.Count <= 0 {
+			return fmt.Errorf("rotation of section %s: crop count must be positive", r.SectionID)
+		}
+		if crop.Tick < 0 {
+			return fmt.Errorf("rotation of section %s: crop tick cannot be negative", r.SectionID)
+		}
+		if crop.Tick > 0 && crop.AfterHarvest {
+			return fmt.Errorf("rotation of section %s: crop cannot have both a tick and after_harvest", r.SectionID)
+		}
+		if crop.InitialSaturation < 0 || crop.InitialSaturation > 1 {
+			return fmt.Errorf("rotation of section %s: crop initial saturation must be between 0.0 and 1.0", r.SectionID)
+		}
+	}
+	return nil
+}
+
+// validateRotations checks every rotation, see ValidateRotation, and that no
+// section has two.
+func (c *GreenhouseConfig) validateRotations() error {
+	sections := map[string]bool{}
+	for _, r := range c.Rotations {
+		if err := c.ValidateRotation(r); err != nil {
+			return err
+		}
+		if sections[r.SectionID] {
+			return errors.New("duplicate rotation section: " + r.SectionID)
+		}
+		sections[r.SectionID] = true
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/models"
+	"maps"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AutoSchedules is written in place of the schedule list for a schedule
+// recommended for each section, see ScheduleList.
+const AutoSchedules = "auto"
+
+// ScheduleList is the watering schedules of a config. It is written as a
+// list of schedules, or as "auto" for a schedule recommended for each section
+// from its dominant plant type, see GreenhouseConfig.WateringSchedules; the
+// latter decodes to a single schedule with Auto set.
+type ScheduleList []ScheduleConfig
+
+// Auto reports whether the list stands for the recommended schedules.
+func (l ScheduleList) Auto() bool {
+	return len(l) == 1 && l[0].Auto
+}
+
+func (l ScheduleList) MarshalJSON() ([]byte, error) {
+	if l.Auto() {
+		return json.Marshal(AutoSchedules)
+	}
+	return json.Marshal([]ScheduleConfig(l))
+}
+
+func (l *ScheduleList) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var name string
+	if json.Unmarshal(data, &name) == nil {
+		return l.setAuto(name)
+	}
+	var schedules []ScheduleConfig
+	if err := strictJSON(data)(&schedules); err != nil {
+		return err
+	}
+	*l = schedules
+	return nil
+}
+
+func (l ScheduleList) MarshalYAML() (any, error) {
+	if l.Auto() {
+		return AutoSchedules, nil
+	}
+	return []ScheduleConfig(l), nil
+}
+
+func (l *ScheduleList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return l.setAuto(value.Value)
+	}
+	decode, err := strictYAML(value)
+	if err != nil {
+		return err
+	}
+	var schedules []ScheduleConfig
+	if err := decode(&schedules); err != nil {
+		return err
+	}
+	*l = schedules
+	return nil
+}
+
+// setAuto sets the list to the recommended schedules. Returns an error if
+// name is not AutoSchedules.
+func (l *ScheduleList) setAuto(name string) error {
+	if name != AutoSchedules {
+		return fmt.Errorf("schedules must be a list or %s: %s", AutoSchedules, name)
+	}
+	*l = ScheduleList{{Auto: true}}
+	return nil
+}
+
+// autoSchedules recommends a schedule for each section with plants, see
+// models.RecommendSchedule, for the plant type most of its plants have, the
+// first by name on a tie. The schedule waters the whole section, with the
+// recommended amount for each of its plants. Returns an error if a plant type
+// is invalid or unknown, or no schedule can be recommended for one.
+func (c *GreenhouseConfig) autoSchedules() ([]models.WateringSchedule, error) {
+	types, err := c.plantTypes()
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]map[string]int{}
+	for _, p := range c.Plants {
+		if counts[p.SectionID] == nil {
+			counts[p.SectionID] = map[string]int{}
+		}
+		counts[p.SectionID][p.Type]++
+	}
+	var schedules []models.WateringSchedule
+	for _, sectionID := range slices.Sorted(maps.Keys(counts)) {
+		byType := counts[sectionID]
+		names := slices.SortedFunc(maps.Keys(byType), func(a, b string) int {
+			return cmp.Or(cmp.Compare(byType[b], byType[a]), cmp.Compare(a, b))
+		})
+		plantType, ok := types[names[0]]
+		if !ok {
+			return nil, errors.New("unknown plant type: " + names[0])
+		}
+		schedule, err := models.RecommendSchedule(plantType, time.Duration(c.TickInterval))
+		if err != nil {
+			return nil, fmt.Errorf("section %s: %w", sectionID, err)
+		}
+		plants := 0
+		for _, count := range byType {
+			plants += count
+		}
+		schedule.SectionID, schedule.PlantType = sectionID, ""
+		schedule.WaterAmount *= float64(plants)
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+// validateSchedules checks that the schedule list is either auto or a list
+// of schedules without Auto set, and that a schedule can be recommended for
+// each section when it is auto.
+func (c *GreenhouseConfig) validateSchedules() error {
+	if c.Schedules.Auto() {
+		_, err := c.autoSchedules()
+		return err
+	}
+	for _, s := range c.Schedules {
+		if s.Auto {
+			return errors.New("auto schedules cannot be listed with other schedules")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"bytes"
+	"greenhouse-simulator/internal/models"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad_AutoSchedules(t *testing.T) {
+	yamlConfig := `tick_interval: 2s
+plants:
+  - {id: tomato-1, type: Tomato, section: section-A, initial_saturation: 0.6}
+  - {id: tomato-2, type: Tomato, section: section-A, initial_saturation: 0.6}
+  - {id: basil-1, type: Basil, section: section-A, initial_saturation: 0.6}
+  - {id: pepper-1, type: Pepper, section: section-B, initial_saturation: 0.6}
+  - {id: mint-1, type: Mint, section: section-B, initial_saturation: 0.6}
+schedules: auto
+`
+	jsonConfig := `{"tick_interval": "2s", "plants": [
+		{"id": "tomato-1", "type": "Tomato", "section": "section-A", "initial_saturation": 0.6},
+		{"id": "tomato-2", "type": "Tomato", "section": "section-A", "initial_saturation": 0.6},
+		{"id": "basil-1", "type": "Basil", "section": "section-A", "initial_saturation": 0.6},
+		{"id": "pepper-1", "type": "Pepper", "section": "section-B", "initial_saturation": 0.6},
+		{"id": "mint-1", "type": "Mint", "section": "section-B", "initial_saturation": 0.6}],
+		"schedules": "auto"}`
+	fromYAML, err := Load(strings.NewReader(yamlConfig), FormatYAML)
+	if err != nil {
+		t.Fatalf("failed to load yaml config: %v", err)
+	}
+	fromJSON, err := Load(strings.NewReader(jsonConfig), FormatJSON)
+	if err != nil {
+		t.Fatalf("failed to load json config: %v", err)
+	}
+	if !reflect.DeepEqual(fromYAML, fromJSON) || !fromYAML.Schedules.Auto() {
+		t.Fatalf("expected identical auto schedules, got yaml %+v and json %+v", fromYAML.Schedules, fromJSON.Schedules)
+	}
+
+	// Tomatoes outnumber the basil of section-A; Mint comes before Pepper on
+	// the tie of section-B. Each schedule waters every plant of its section.
+	tomato, _ := models.PresetPlantType("Tomato")
+	mint, _ := models.PresetPlantType("Mint")
+	expected := make([]models.WateringSchedule, 2)
+	for i, tt := range []struct {
+		section   string
+		plantType models.PlantType
+		plants    float64
+	}{{"section-A", tomato, 3}, {"section-B", mint, 2}} {
+		schedule, err := models.RecommendSchedule(tt.plantType, 2*time.Second)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		schedule.SectionID, schedule.PlantType = tt.section, ""
+		schedule.WaterAmount *= tt.plants
+		expected[i] = schedule
+	}
+	if got := fromYAML.WateringSchedules(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected schedules %+v, got %+v", expected, got)
+	}
+
+	for _, format := range []Format{FormatYAML, FormatJSON} {
+		var buf bytes.Buffer
+		if err := Encode(&buf, fromYAML, format); err != nil {
+			t.Fatalf("failed to encode %s config: %v", format, err)
+		}
+		if !strings.Contains(buf.String(), `"auto"`) && !strings.Contains(buf.String(), "schedules: auto") {
+			t.Errorf("expected the %s config to keep the auto schedules, got %s", format, buf.String())
+		}
+		loaded, err := Load(&buf, format)
+		if err != nil {
+			t.Fatalf("failed to reload %s config: %v", format, err)
+		}
+		if !reflect.DeepEqual(loaded, fromYAML) {
+			t.Errorf("expected the %s config to round trip, got %+v", format, loaded.Schedules)
+		}
+	}
+}
+
+func TestLoad_ScheduleListErrors(t *testing.T) {
+	if _, err := Load(strings.NewReader("tick_interval: 1s\nschedules: manual"), FormatYAML); err == nil || !strings.Contains(err.Error(), "schedules must be a list or auto: manual") {
+		t.Errorf("expected unknown yaml schedules to be refused, got %v", err)
+	}
+	if _, err := Load(strings.NewReader(`{"tick_interval": "1s", "schedules": "manual"}`), FormatJSON); err == nil || !strings.Contains(err.Error(), "schedules must be a list or auto: manual") {
+		t.Errorf("expected unknown json schedules to be refused, got %v", err)
+	}
+	if _, err := Load(strings.NewReader("tick_interval: 1s\nschedules: [{section: s1, target_saturation: 0.5, check_interval: 1, every: 2}]"), FormatYAML); err == nil {
+		t.Error("expected an unknown schedule field to be refused, got nil")
+	}
+
+	cfg := Default()
+	cfg.Schedules = append(cfg.Schedules, ScheduleConfig{Auto: true})
+	if err := cfg.Validate(); err == nil || err.Error() != "auto schedules cannot be listed with other schedules" {
+		t.Errorf("expected auto schedules among others to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaVersion is the version of the config layout this simulator writes.
+// Files without a schema_version are version 1, the layout from before
+// configs were versioned.
+const SchemaVersion = 2
+
+// schemaMigrations bring a decoded config from one schema version to the
+// next: schemaMigrations[i] upgrades version i+1 to i+2. Released
+// migrations must never change; new ones are appended and SchemaVersion
+// raised with them.
+var schemaMigrations = []func(doc map[string]any) error{
+	migrateDeepSaturation,
+}
+
+// migrateDeepSaturation upgrades version 1 to 2. Version 1 left the deep
+// saturation of an exported plant state out when it was zero, and states
+// written by hand before layered soils never had one; both now start the
+// deep layer at the plant's initial saturation, like a plant without state.
+// Version 2 always writes it.
+func migrateDeepSaturation(doc map[string]any) error {
+	plants, _ := doc["plants"].([]any)
+	for _, p := range plants {
+		plant, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		state, ok := plant["state"].(map[string]any)
+		if !ok {
+			continue
+		}
+		if _, ok := state["deep_saturation"]; !ok {
+			state["deep_saturation"] = plant["initial_saturation"]
+		}
+	}
+	return nil
+}
+
+// schemaVersion reads the schema version of an encoded config, 1 when it
+// has none.
+func schemaVersion(data []byte, format Format) (int, error) {
+	var header struct {
+		SchemaVersion int `json:"schema_version" yaml:"schema_version"`
+	}
+	var err error
+	switch format {
+	case FormatJSON:
+		err = json.Unmarshal(data, &header)
+	case FormatYAML:
+		err = yaml.Unmarshal(data, &header)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("decoding %s config: %w", format, err)
+	}
+	if header.SchemaVersion == 0 {
+		return 1, nil
+	}
+	return header.SchemaVersion, nil
+}
+
+// migrate upgrades an encoded config of the given schema version to
+// SchemaVersion and returns it in the same format. Returns an error if:
+// - the version is newer than SchemaVersion or not positive
+// - the config cannot be decoded or a migration fails
+func migrate(data []byte, format Format, version int) ([]byte, error) {
+	if version > SchemaVersion {
+		return nil, fmt.Errorf("config schema version %d is newer than the supported version %d", version, SchemaVersion)
+	}
+	if version < 1 {
+		return nil, fmt.Errorf("config schema version must be positive: %d", version)
+	}
+	if version == SchemaVersion {
+		return data, nil
+	}
+
+	var doc map[string]any
+	var err error
+	switch format {
+	case FormatJSON:
+		err = decodeJSONDoc(data, &doc)
+	case FormatYAML:
+		err = yaml.Unmarshal(data, &doc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s config: %w", format, err)
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+	for ; version < SchemaVersion; version++ {
+		if err := schemaMigrations[version-1](doc); err != nil {
+			return nil, fmt.Errorf("migrating the config schema to version %d: %w", version+1, err)
+		}
+	}
+	doc["schema_version"] = SchemaVersion
+
+	var buf bytes.Buffer
+	switch format {
+	case FormatJSON:
+		err = json.NewEncoder(&buf).Encode(doc)
+	case FormatYAML:
+		err = yaml.NewEncoder(&buf).Encode(doc)
+	}
+	return buf.Bytes(), err
+}
+
+// decodeJSONDoc decodes a JSON config into doc like json.Unmarshal, but keeps
+// its numbers as json.Number, so that integers beyond the 53 bits of a
+// float64, such as a seed, are encoded back unchanged.
+func decodeJSONDoc(data []byte, doc *map[string]any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(doc); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("invalid data after the top-level value")
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/sensors"
+	"slices"
+	"strings"
+)
+
+// SensorTemplateConfig registers a sensor like Template in every section the
+// config knows of with PerSection, see GreenhouseConfig.SectionIDs, or in
+// the first section of every zone with PerZone, ordered by ID. IDPattern
+// builds the ID of each sensor, see sensors.Template; the template itself
+// has no ID or section. A sensor whose ID is taken by a sensor of the config,
+// listed or from an earlier template, is left out, see
+// GreenhouseConfig.ExpandSensorTemplates.
+type SensorTemplateConfig struct {
+	Template   SensorConfig `json:"template" yaml:"template"`
+	PerSection bool         `json:"per_section,omitempty" yaml:"per_section,omitempty"`
+	PerZone    bool         `json:"per_zone,omitempty" yaml:"per_zone,omitempty"`
+	IDPattern  string       `json:"id_pattern" yaml:"id_pattern"`
+}
+
+// SensorTemplate converts the config into a sensors.Template.
+func (t SensorTemplateConfig) SensorTemplate() sensors.Template {
+	return sensors.Template{IDPattern: t.IDPattern, Type: t.Template.Type, Options: t.Template.options()}
+}
+
+// templateSections returns the sections of the config a template expands
+// into, with their zones.
+func (c *GreenhouseConfig) templateSections(t SensorTemplateConfig) []sensors.TemplateSection {
+	var sections []sensors.TemplateSection
+	if t.PerZone {
+		zones := slices.SortedFunc(slices.Values(c.Zones), func(a, b ZoneConfig) int { return strings.Compare(a.ID, b.ID) })
+		for _, z := range zones {
+			if len(z.Sections) > 0 {
+				sections = append(sections, sensors.TemplateSection{ID: z.Sections[0], Zone: z.ID})
+			}
+		}
+		return sections
+	}
+	zones := map[string]string{}
+	for _, z := range c.Zones {
+		for _, sectionID := range z.Sections {
+			zones[sectionID] = z.ID
+		}
+	}
+	for _, sectionID := range c.SectionIDs() {
+		sections = append(sections, sensors.TemplateSection{ID: sectionID, Zone: zones[sectionID]})
+	}
+	return sections
+}
+
+// expandSensorTemplate returns the sensors of a template in the sections,
+// see sensors.ExpandTemplate.
+func (c *GreenhouseConfig) expandSensorTemplate(t SensorTemplateConfig, sections []sensors.TemplateSection) ([]SensorConfig, error) {
+	expanded, err := sensors.ExpandTemplate(t.SensorTemplate(), sections)
+	if err != nil {
+		return nil, err
+	}
+	configs := make([]SensorConfig, 0, len(expanded))
+	for _, sensor := range expanded {
+		cfg := t.Template
+		cfg.ID, cfg.SectionID = sensor.ID, sensor.SectionID
+		configs = append(configs, cfg)
+	}
+	return configs, nil
+}
+
+// ExpandSensorTemplates adds the sensors of the sensor templates to the
+// listed sensors, in order, leaving out those whose ID is taken, so that
+// expanding a config twice adds nothing. The sections are those of the
+// config before the expansion. Load expands the configs it loads; a config
+// built in code has to expand its own. Returns an error if a template is
+// invalid, see validateSensorTemplates.
+func (c *GreenhouseConfig) ExpandSensorTemplates() error {
+	if err := c.validateSensorTemplates(); err != nil {
+		return err
+	}
+	taken := map[string]bool{}
+	for _, sensor := range c.Sensors {
+		taken[sensor.ID] = true
+	}
+	sections := make([][]sensors.TemplateSection, len(c.SensorTemplates))
+	for i, t := range c.SensorTemplates {
+		sections[i] = c.templateSections(t)
+	}
+	for i, t := range c.SensorTemplates {
+		expanded, err := c.expandSensorTemplate(t, sections[i])
+		if err != nil {
+			return err
+		}
+		for _, sensor := range expanded {
+			if !taken[sensor.ID] {
+				taken[sensor.ID] = true
+				c.Sensors = append(c.Sensors, sensor)
+			}
+		}
+	}
+	return nil
+}
+
+// validateSensorTemplates checks the sensor templates against the sections
+// of the config. Returns an error if:
+// - a template is neither per section nor per zone, or both
+// - a template has an ID or a section
+// - a template cannot be expanded, see sensors.ExpandTemplate, for one
+// because its ID pattern is invalid
+func (c *GreenhouseConfig) validateSensorTemplates() error {
+	for _, t := range c.SensorTemplates {
+		if t.PerSection == t.PerZone {
+			return errors.New("sensor template must be either per_section or per_zone: " + t.IDPattern)
+		}
+		if t.Template.ID != "" || t.Template.SectionID != "" {
+			return errors.New("sensor template cannot have an id or a section: " + t.IDPattern)
+		}
+		if _, err := c.expandSensorTemplate(t, c.templateSections(t)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/auth"
+	"net"
+)
+
+// MinTokenLength is the shortest API token a config may grant.
+const MinTokenLength = 16
+
+// ServerConfig starts the APIs of a running simulation: the HTTP API of
+// package api on HTTP and the gRPC API of package grpcapi on GRPC. Each is a
+// listen address such as ":8080"; an empty address leaves that API off.
+// Tokens are the API tokens of the greenhouse; when there are any, every
+// request to either API must carry one, see package auth. ReadOnly starts
+// both APIs read-only, refusing every call that would change the greenhouse,
+// see service.Service.SetReadOnly.
+type ServerConfig struct {
+	HTTP     string        `json:"http,omitempty" yaml:"http,omitempty"`
+	GRPC     string        `json:"grpc,omitempty" yaml:"grpc,omitempty"`
+	Tokens   []TokenConfig `json:"tokens,omitempty" yaml:"tokens,omitempty"`
+	ReadOnly bool          `json:"read_only,omitempty" yaml:"read_only,omitempty"`
+}
+
+// TokenConfig is an API token. Name identifies it wherever the token itself
+// must not appear, such as errors and logs. Scope is read, control or admin,
+// see auth.Scope.
+type TokenConfig struct {
+	Name  string `json:"name" yaml:"name"`
+	Token string `json:"token" yaml:"token"`
+	Scope string `json:"scope" yaml:"scope"`
+}
+
+// Authorizer returns the authorizer of the configured tokens, which lets
+// every request through when there are none.
+func (s ServerConfig) Authorizer() *auth.Authorizer {
+	tokens := make([]auth.Token, 0, len(s.Tokens))
+	for _, token := range s.Tokens {
+		tokens = append(tokens, auth.Token{Name: token.Name, Secret: token.Token, Scope: auth.Scope(token.Scope)})
+	}
+	return auth.New(tokens)
+}
+
+// validate checks the server settings. Errors name tokens, never give them
+// away. Returns an error if:
+// - both addresses are empty
+// - an address is not a host and port
+// - a token is unnamed, or its name or token is used twice
+// - a token is shorter than MinTokenLength
+// - a scope is not read, control or admin
+func (s ServerConfig) validate() error {
+	if s.HTTP == "" && s.GRPC == "" {
+		return errors.New("server config needs an http or grpc address")
+	}
+	for _, address := range []string{s.HTTP, s.GRPC} {
+		if address == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			return errors.New("invalid server address: " + address)
+		}
+	}
+	names := map[string]bool{}
+	secrets := map[string]bool{}
+	for _, token := range s.Tokens {
+		if token.Name == "" {
+			return errors.New("api token name cannot be empty")
+		}
+		if names[token.Name] {
+			return errors.New("duplicate api token name: " + token.Name)
+		}
+		names[token.Name] = true
+		if len(token.Token) < MinTokenLength {
+			return fmt.Errorf("api token %s must be at least %d characters long", token.Name, MinTokenLength)
+		}
+		if secrets[token.Token] {
+			return errors.New("api token " + token.Name + " reuses the token of another")
+		}
+		secrets[token.Token] = true
+		if scope := auth.Scope(token.Scope); scope != auth.ScopeRead && scope != auth.ScopeControl && scope != auth.ScopeAdmin {
+			return errors.New("invalid scope of api token " + token.Name + ": " + token.Scope)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/environment"
+	"greenhouse-simulator/internal/models"
+	"greenhouse-simulator/internal/sensors"
+	"strconv"
+)
+
+// ActionType names a scripted timeline action.
+type ActionType string
+
+const (
+	ActionAddPlant       ActionType = "add_plant"
+	ActionRemovePlant    ActionType = "remove_plant"
+	ActionWater          ActionType = "water"
+	ActionFailSensor     ActionType = "fail_sensor"
+	ActionSetEnvironment ActionType = "set_environment"
+	ActionPause          ActionType = "pause"
+	ActionResume         ActionType = "resume"
+	ActionWeatherEvent   ActionType = "weather_event"
+	ActionSetLights      ActionType = "set_lights"
+	ActionSetActuator    ActionType = "set_actuator"
+	ActionInfectPlant    ActionType = "infect_plant"
+	ActionTreatDisease   ActionType = "treat_disease"
+	ActionPrunePlant     ActionType = "prune_plant"
+	ActionThinSection    ActionType = "thin_section"
+	ActionSetPlantFlags  ActionType = "set_plant_flags"
+	ActionInjectAnomaly  ActionType = "inject_anomaly"
+)
+
+// ActionConfig is a timeline action run once the simulation reaches Tick.
+// Which fields apply depends on the Action:
+//   - add_plant: Plant, added Count times; when Count is above 1 the IDs are
+//     suffixed with -1, -2, ...
+//   - remove_plant: PlantID
+//   - water: SectionID, Amount and optionally Duration, as a manual watering
+//   - fail_sensor: SensorID
+//   - set_environment: AmbientHumidity and/or HumidityDecay
+//   - pause, resume: ScheduleID, the watering schedule to disable or re-enable
+//   - weather_event: Extreme, frost or heat_wave, lasting Ticks ticks
+//   - set_lights: SectionID, On and the Intensity, 1.0 when omitted
+//   - set_actuator: Actuator, heater or vent, switched to Mode, auto, on or
+//     off
+//   - infect_plant: PlantID
+//   - treat_disease: SectionID, whose diseased plants are treated
+//   - prune_plant: PlantID, pruned back by Fraction of its growth
+//   - thin_section: SectionID, thinned out to its Keep healthiest plants
+//   - set_plant_flags: PlantID, excluded from watering or let back in with
+//     ExcludeFromWatering, quarantined or released with Quarantined; a
+//     flag left out is kept
+//   - inject_anomaly: SensorID, or else SectionID for all of its sensors,
+//     whose readings get an Anomaly, spike, flatline, drift or dropout, of
+//     Magnitude lasting Ticks ticks, see sensors.SensorManager.InjectAnomaly
+type ActionConfig struct {
+	Tick                int                      `json:"tick" yaml:"tick"`
+	Action              ActionType               `json:"action" yaml:"action"`
+	Plant               *PlantConfig             `json:"plant,omitempty" yaml:"plant,omitempty"`
+	Count               int                      `json:"count,omitempty" yaml:"count,omitempty"`
+	PlantID             string                   `json:"plant_id,omitempty" yaml:"plant_id,omitempty"`
+	SectionID           string                   `json:"section,omitempty" yaml:"section,omitempty"`
+	Amount              float64                  `json:"amount,omitempty" yaml:"amount,omitempty"`
+	Duration            Duration                 `json:"duration,omitempty" yaml:"duration,omitempty"`
+	SensorID            string                   `json:"sensor_id,omitempty" yaml:"sensor_id,omitempty"`
+	AmbientHumidity     *float64                 `json:"ambient_humidity,omitempty" yaml:"ambient_humidity,omitempty"`
+	HumidityDecay       *float64                 `json:"humidity_decay,omitempty" yaml:"humidity_decay,omitempty"`
+	ScheduleID          string                   `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+	Extreme             environment.Extreme      `json:"extreme,omitempty" yaml:"extreme,omitempty"`
+	Ticks               int                      `json:"ticks,omitempty" yaml:"ticks,omitempty"`
+	On                  bool                     `json:"on,omitempty" yaml:"on,omitempty"`
+	Intensity           float64                  `json:"intensity,omitempty" yaml:"intensity,omitempty"`
+	Actuator            environment.Actuator     `json:"actuator,omitempty" yaml:"actuator,omitempty"`
+	Mode                environment.ActuatorMode `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Fraction            float64                  `json:"fraction,omitempty" yaml:"fraction,omitempty"`
+	Keep                int                      `json:"keep,omitempty" yaml:"keep,omitempty"`
+	ExcludeFromWatering *bool                    `json:"exclude_from_watering,omitempty" yaml:"exclude_from_watering,omitempty"`
+	Quarantined         *bool                    `json:"quarantined,omitempty" yaml:"quarantined,omitempty"`
+	Anomaly             sensors.AnomalyKind      `json:"anomaly,omitempty" yaml:"anomaly,omitempty"`
+	Magnitude           float64                  `json:"magnitude,omitempty" yaml:"magnitude,omitempty"`
+}
+
+// Plants expands an add_plant action into the plants it adds.
+func (a ActionConfig) Plants() []PlantConfig {
+	if a.Plant == nil {
+		return nil
+	}
+	if a.Count <= 1 {
+		return []PlantConfig{*a.Plant}
+	}
+	plants := make([]PlantConfig, a.Count)
+	for i := range plants {
+		plants[i] = *a.Plant
+		plants[i].ID = a.Plant.ID + "-" + strconv.Itoa(i+1)
+	}
+	return plants
+}
+
+// Flags returns the plant flags a set_plant_flags action sets.
+func (a ActionConfig) Flags() models.PlantFlags {
+	return models.PlantFlags{ExcludeFromWatering: a.ExcludeFromWatering, Quarantined: a.Quarantined}
+}
+
+// AnomalySpec returns the anomaly an inject_anomaly action injects.
+func (a ActionConfig) AnomalySpec() sensors.Anomaly {
+	return sensors.Anomaly{Kind: a.Anomaly, Magnitude: a.Magnitude, Ticks: a.Ticks}
+}
+
+// validateTimeline checks every action for the fields its type needs. Plants
+// added by the timeline are built to check them, and their IDs must not clash
+// with the configured plants or each other.
+func (c *GreenhouseConfig) validateTimeline() error {
+	types, err := c.plantTypes()
+	if err != nil {
+		return err
+	}
+	soils, err := c.soils()
+	if err != nil {
+		return err
+	}
+	ids := map[string]bool{}
+	for _, p := range c.Plants {
+		ids[p.ID] = true
+	}
+	for i, action := range c.Timeline {
+		if err := action.validate(types, soils, ids); err != nil {
+			return fmt.Errorf("timeline action %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (a ActionConfig) validate(types map[string]models.PlantType, soils map[string]models.SoilType, ids map[string]bool) error {
+	if a.Tick < 0 {
+		return errors.New("tick cannot be negative")
+	}
+	switch a.Action {
+	case ActionAddPlant:
+		if a.Plant == nil {
+			return errors.New("add_plant requires a plant")
+		}
+		if a.Count < 0 {
+			return errors.New("plant count cannot be negative")
+		}
+		for _, p := range a.Plants() {
+			if ids[p.ID] {
+				return errors.New("duplicate plant ID: " + p.ID)
+			}
+			ids[p.ID] = true
+			if _, err := p.build(types, soils); err != nil {
+				return err
+			}
+		}
+	case ActionRemovePlant:
+		if a.PlantID == "" {
+			return errors.New("remove_plant requires a plant_id")
+		}
+	case ActionWater:
+		if a.SectionID == "" {
+			return errors.New("water requires a section")
+		}
+		if a.Amount <= 0 {
+			return errors.New("water amount must be positive")
+		}
+		if a.Duration < 0 {
+			return errors.New("water duration cannot be negative")
+		}
+	case ActionFailSensor:
+		if a.SensorID == "" {
+			return errors.New("fail_sensor requires a sensor_id")
+		}
+	case ActionSetEnvironment:
+		if a.AmbientHumidity == nil && a.HumidityDecay == nil {
+			return errors.New("set_environment requires ambient_humidity or humidity_decay")
+		}
+		if a.AmbientHumidity != nil && (*a.AmbientHumidity < 0 || *a.AmbientHumidity > 1) {
+			return errors.New("ambient humidity must be between 0.0 and 1.0")
+		}
+		if a.HumidityDecay != nil && (*a.HumidityDecay < 0 || *a.HumidityDecay > 1) {
+			return errors.New("humidity decay rate must be between 0.0 and 1.0")
+		}
+	case ActionPause, ActionResume:
+		if a.ScheduleID == "" {
+			return fmt.Errorf("%s requires a schedule", a.Action)
+		}
+	case ActionWeatherEvent:
+		if err := a.Extreme.Validate(); err != nil {
+			return err
+		}
+		if a.Ticks < 1 {
+			return errors.New("weather_event requires ticks of at least 1")
+		}
+	case ActionSetLights:
+		if a.SectionID == "" {
+			return errors.New("set_lights requires a section")
+		}
+		if a.Intensity < 0 || a.Intensity > 1 {
+			return errors.New("lights intensity must be between 0.0 and 1.0")
+		}
+	case ActionSetActuator:
+		if err := a.Actuator.Validate(); err != nil {
+			return err
+		}
+		if err := a.Mode.Validate(); err != nil {
+			return err
+		}
+	case ActionInfectPlant:
+		if a.PlantID == "" {
+			return errors.New("infect_plant requires a plant_id")
+		}
+	case ActionTreatDisease:
+		if a.SectionID == "" {
+			return errors.New("treat_disease requires a section")
+		}
+	case ActionPrunePlant:
+		if a.PlantID == "" {
+			return errors.New("prune_plant requires a plant_id")
+		}
+		if a.Fraction <= 0 || a.Fraction > models.MaxPruneFraction {
+			return errors.New("prune fraction must be above 0.0 and at most 0.5")
+		}
+	case ActionThinSection:
+		if a.SectionID == "" {
+			return errors.New("thin_section requires a section")
+		}
+		if a.Keep < 0 {
+			return errors.New("plants to keep cannot be negative")
+		}
+	case ActionSetPlantFlags:
+		if a.PlantID == "" {
+			return errors.New("set_plant_flags requires a plant_id")
+		}
+		if a.ExcludeFromWatering == nil && a.Quarantined == nil {
+			return errors.New("set_plant_flags requires exclude_from_watering or quarantined")
+		}
+	case ActionInjectAnomaly:
+		if a.SensorID == "" && a.SectionID == "" {
+			return errors.New("inject_anomaly requires a sensor_id or a section")
+		}
+		if err := a.AnomalySpec().Validate(); err != nil {
+			return err
+		}
+	default:
+		return errors.New("unknown action type: " + string(a.Action))
+	}
+	return nil
+}
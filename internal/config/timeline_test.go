@@ -0,0 +1,176 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoad_TimelineValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		json     string
+		errorMsg string
+	}{
+		{
+			"unknown action type",
+			"tick_interval: 1s\ntimeline:\n  - {tick: 5, action: explode}",
+			`{"tick_interval": "1s", "timeline": [{"tick": 5, "action": "explode"}]}`,
+			"timeline action 0: unknown action type: explode",
+		},
+		{
+			"negative tick",
+			"tick_interval: 1s\ntimeline:\n  - {tick: -1, action: fail_sensor, sensor_id: s1}",
+			`{"tick_interval": "1s", "timeline": [{"tick": -1, "action": "fail_sensor", "sensor_id": "s1"}]}`,
+			"timeline action 0: tick cannot be negative",
+		},
+		{
+			"water without amount",
+			"tick_interval: 1s\ntimeline:\n  - {tick: 1, action: water, section: s1}",
+			`{"tick_interval": "1s", "timeline": [{"tick": 1, "action": "water", "section": "s1"}]}`,
+			"timeline action 0: water amount must be positive",
+		},
+		{
+			"added plant of unknown type",
+			"tick_interval: 1s\ntimeline:\n  - {tick: 1, action: add_plant, plant: {id: p1, type: Fern, section: s1, initial_saturation: 0.5}}",
+			`{"tick_interval": "1s", "timeline": [{"tick": 1, "action": "add_plant", "plant": {"id": "p1", "type": "Fern", "section": "s1", "initial_saturation": 0.5}}]}`,
+			"timeline action 0: plant p1: unknown plant type: Fern",
+		},
+		{
+			"added plant clashes with configured one",
+			"tick_interval: 1s\nplants:\n  - {id: p-2, type: Tomato, section: s1, initial_saturation: 0.5}\ntimeline:\n  - {tick: 1, action: add_plant, count: 3, plant: {id: p, type: Tomato, section: s1, initial_saturation: 0.5}}",
+			`{"tick_interval": "1s", "plants": [{"id": "p-2", "type": "Tomato", "section": "s1", "initial_saturation": 0.5}], "timeline": [{"tick": 1, "action": "add_plant", "count": 3, "plant": {"id": "p", "type": "Tomato", "section": "s1", "initial_saturation": 0.5}}]}`,
+			"timeline action 0: duplicate plant ID: p-2",
+		},
+		{
+			"environment change without values",
+			"tick_interval: 1s\ntimeline:\n  - {tick: 1, action: set_environment}",
+			`{"tick_interval": "1s", "timeline": [{"tick": 1, "action": "set_environment"}]}`,
+			"timeline action 0: set_environment requires ambient_humidity or humidity_decay",
+		},
+		{
+			"pause without schedule",
+			"tick_interval: 1s\ntimeline:\n  - {tick: 1, action: remove_plant, plant_id: p1}\n  - {tick: 2, action: pause}",
+			`{"tick_interval": "1s", "timeline": [{"tick": 1, "action": "remove_plant", "plant_id": "p1"}, {"tick": 2, "action": "pause"}]}`,
+			"timeline action 1: pause requires a schedule",
+		},
+		{
+			"unknown extreme weather",
+			"tick_interval: 1s\ntimeline:\n  - {tick: 1, action: weather_event, extreme: hail, ticks: 3}",
+			`{"tick_interval": "1s", "timeline": [{"tick": 1, "action": "weather_event", "extreme": "hail", "ticks": 3}]}`,
+			"timeline action 0: extreme weather must be frost or heat_wave: hail",
+		},
+		{
+			"weather event without ticks",
+			"tick_interval: 1s\ntimeline:\n  - {tick: 1, action: weather_event, extreme: frost}",
+			`{"tick_interval": "1s", "timeline": [{"tick": 1, "action": "weather_event", "extreme": "frost"}]}`,
+			"timeline action 0: weather_event requires ticks of at least 1",
+		},
+		{
+			"lights without section",
+			"tick_interval: 1s\ntimeline:\n  - {tick: 1, action: set_lights, on: true}",
+			`{"tick_interval": "1s", "timeline": [{"tick": 1, "action": "set_lights", "on": true}]}`,
+			"timeline action 0: set_lights requires a section",
+		},
+		{
+			"lights too bright",
+			"tick_interval: 1s\ntimeline:\n  - {tick: 1, action: set_lights, section: section-A, on: true, intensity: 1.5}",
+			`{"tick_interval": "1s", "timeline": [{"tick": 1, "action": "set_lights", "section": "section-A", "on": true, "intensity": 1.5}]}`,
+			"timeline action 0: lights intensity must be between 0.0 and 1.0",
+		},
+		{
+			"unknown actuator",
+			"tick_interval: 1s\ntimeline:\n  - {tick: 1, action: set_actuator, actuator: fan, mode: on}",
+			`{"tick_interval": "1s", "timeline": [{"tick": 1, "action": "set_actuator", "actuator": "fan", "mode": "on"}]}`,
+			"timeline action 0: actuator must be heater or vent: fan",
+		},
+		{
+			"actuator without mode",
+			"tick_interval: 1s\ntimeline:\n  - {tick: 1, action: set_actuator, actuator: heater}",
+			`{"tick_interval": "1s", "timeline": [{"tick": 1, "action": "set_actuator", "actuator": "heater"}]}`,
+			"timeline action 0: actuator mode must be auto, on or off: ",
+		},
+		{
+			"infection without plant",
+			"tick_interval: 1s\ntimeline:\n  - {tick: 1, action: infect_plant}",
+			`{"tick_interval": "1s", "timeline": [{"tick": 1, "action": "infect_plant"}]}`,
+			"timeline action 0: infect_plant requires a plant_id",
+		},
+		{
+			"treatment without section",
+			"tick_interval: 1s\ntimeline:\n  - {tick: 1, action: treat_disease}",
+			`{"tick_interval": "1s", "timeline": [{"tick": 1, "action": "treat_disease"}]}`,
+			"timeline action 0: treat_disease requires a section",
+		},
+		{
+			"pruning without plant",
+			"tick_interval: 1s\ntimeline:\n  - {tick: 1, action: prune_plant, fraction: 0.2}",
+			`{"tick_interval": "1s", "timeline": [{"tick": 1, "action": "prune_plant", "fraction": 0.2}]}`,
+			"timeline action 0: prune_plant requires a plant_id",
+		},
+		{
+			"pruning more than half",
+			"tick_interval: 1s\ntimeline:\n  - {tick: 1, action: prune_plant, plant_id: p1, fraction: 0.6}",
+			`{"tick_interval": "1s", "timeline": [{"tick": 1, "action": "prune_plant", "plant_id": "p1", "fraction": 0.6}]}`,
+			"timeline action 0: prune fraction must be above 0.0 and at most 0.5",
+		},
+		{
+			"thinning without section",
+			"tick_interval: 1s\ntimeline:\n  - {tick: 1, action: thin_section, keep: 2}",
+			`{"tick_interval": "1s", "timeline": [{"tick": 1, "action": "thin_section", "keep": 2}]}`,
+			"timeline action 0: thin_section requires a section",
+		},
+		{
+			"thinning to a negative count",
+			"tick_interval: 1s\ntimeline:\n  - {tick: 1, action: thin_section, section: section-A, keep: -1}",
+			`{"tick_interval": "1s", "timeline": [{"tick": 1, "action": "thin_section", "section": "section-A", "keep": -1}]}`,
+			"timeline action 0: plants to keep cannot be negative",
+		},
+		{
+			"plant flags without a flag",
+			"tick_interval: 1s\ntimeline:\n  - {tick: 1, action: set_plant_flags, plant_id: p1}",
+			`{"tick_interval": "1s", "timeline": [{"tick": 1, "action": "set_plant_flags", "plant_id": "p1"}]}`,
+			"timeline action 0: set_plant_flags requires exclude_from_watering or quarantined",
+		},
+		{
+			"anomaly without target",
+			"tick_interval: 1s\ntimeline:\n  - {tick: 1, action: inject_anomaly, anomaly: flatline, ticks: 5}",
+			`{"tick_interval": "1s", "timeline": [{"tick": 1, "action": "inject_anomaly", "anomaly": "flatline", "ticks": 5}]}`,
+			"timeline action 0: inject_anomaly requires a sensor_id or a section",
+		},
+		{
+			"spike without magnitude",
+			"tick_interval: 1s\ntimeline:\n  - {tick: 1, action: inject_anomaly, sensor_id: s1, anomaly: spike, ticks: 5}",
+			`{"tick_interval": "1s", "timeline": [{"tick": 1, "action": "inject_anomaly", "sensor_id": "s1", "anomaly": "spike", "ticks": 5}]}`,
+			"timeline action 0: spike anomaly requires a magnitude",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, yamlErr := Load(strings.NewReader(tt.yaml), FormatYAML)
+			_, jsonErr := Load(strings.NewReader(tt.json), FormatJSON)
+			if yamlErr == nil || jsonErr == nil {
+				t.Fatalf("expected errors from both formats, got yaml=%v json=%v", yamlErr, jsonErr)
+			}
+			if yamlErr.Error() != tt.errorMsg || jsonErr.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got yaml='%s' json='%s'", tt.errorMsg, yamlErr, jsonErr)
+			}
+		})
+	}
+}
+
+func TestActionConfig_Plants(t *testing.T) {
+	template := &PlantConfig{ID: "lettuce", Type: "Lettuce", SectionID: "s1"}
+
+	if plants := (ActionConfig{Plant: template}).Plants(); len(plants) != 1 || plants[0].ID != "lettuce" {
+		t.Errorf("expected a single plant keeping its ID, got %+v", plants)
+	}
+	plants := (ActionConfig{Plant: template, Count: 3}).Plants()
+	if len(plants) != 3 || plants[0].ID != "lettuce-1" || plants[2].ID != "lettuce-3" {
+		t.Errorf("expected three numbered plants, got %+v", plants)
+	}
+	if template.ID != "lettuce" {
+		t.Errorf("expected the template to be left untouched, got %s", template.ID)
+	}
+}
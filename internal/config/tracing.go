@@ -0,0 +1,46 @@
+package config
+
+import (
+	"errors"
+	"net/url"
+)
+
+// TracingConfig traces the ticks and the API requests with OpenTelemetry,
+// see package tracing. Nothing is traced unless Enabled is set. Spans are
+// exported over OTLP/HTTP to Endpoint, by default the endpoint of the
+// standard OTEL_EXPORTER_OTLP_* environment variables or http://localhost:4318.
+// SampleRatio is the fraction of ticks and requests traced, all of them when
+// zero. ServiceName defaults to greenhouse-simulator.
+type TracingConfig struct {
+	Enabled     bool    `json:"enabled" yaml:"enabled"`
+	Endpoint    string  `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	ServiceName string  `json:"service_name,omitempty" yaml:"service_name,omitempty"`
+	SampleRatio float64 `json:"sample_ratio,omitempty" yaml:"sample_ratio,omitempty"`
+}
+
+// WithDefaults returns a copy of the config with zero values replaced by
+// their defaults. Endpoint stays empty; the exporter resolves it.
+func (t TracingConfig) WithDefaults() TracingConfig {
+	if t.ServiceName == "" {
+		t.ServiceName = "greenhouse-simulator"
+	}
+	if t.SampleRatio == 0 {
+		t.SampleRatio = 1
+	}
+	return t
+}
+
+// validate checks the tracing settings. Returns an error if the endpoint is
+// set but not an http or https URL, or the sample ratio is not between 0.0
+// and 1.0.
+func (t TracingConfig) validate() error {
+	if t.Endpoint != "" {
+		if u, err := url.Parse(t.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("invalid tracing endpoint: " + t.Endpoint)
+		}
+	}
+	if t.SampleRatio < 0 || t.SampleRatio > 1 {
+		return errors.New("tracing sample ratio must be between 0.0 and 1.0")
+	}
+	return nil
+}
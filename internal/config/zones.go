@@ -0,0 +1,85 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/models"
+	"maps"
+	"slices"
+)
+
+// ZoneConfig mirrors models.Zone: a named group of sections, such as the
+// sections of one wing. Zones can be given their own alert rules, see
+// AlertsConfig.Zones, and watered by schedules selecting the zone.
+type ZoneConfig struct {
+	ID       string   `json:"id" yaml:"id"`
+	Name     string   `json:"name,omitempty" yaml:"name,omitempty"`
+	Sections []string `json:"sections" yaml:"sections"`
+}
+
+// Zone converts the config into a models.Zone.
+func (z ZoneConfig) Zone() *models.Zone {
+	return &models.Zone{ID: z.ID, Name: z.Name, SectionIDs: slices.Clone(z.Sections)}
+}
+
+// SectionIDs returns the sorted IDs of the sections the config knows of:
+// those of its plants, section soils, grow lights, microclimates and sensors.
+func (c *GreenhouseConfig) SectionIDs() []string {
+	sections := map[string]bool{}
+	for _, p := range c.Plants {
+		sections[p.SectionID] = true
+	}
+	for _, s := range c.Sections {
+		sections[s.ID] = true
+	}
+	for _, l := range c.Lights {
+		sections[l.SectionID] = true
+	}
+	for _, m := range c.Microclimates {
+		sections[m.SectionID] = true
+	}
+	for _, s := range c.Sensors {
+		sections[s.SectionID] = true
+	}
+	delete(sections, "")
+	return slices.Sorted(maps.Keys(sections))
+}
+
+// validateZones checks the zones against the sections of the config. Returns
+// an error if:
+// - a zone is invalid, see models.Zone.Validate
+// - a zone ID is duplicated
+// - a zone has a section the config does not know of, see SectionIDs
+// - a section is in more than one zone
+// - the alert rules of a zone refer to an unknown zone
+func (c *GreenhouseConfig) validateZones() error {
+	known := c.SectionIDs()
+	zones := map[string]bool{}
+	owners := map[string]string{}
+	for _, z := range c.Zones {
+		if err := z.Zone().Validate(); err != nil {
+			return err
+		}
+		if zones[z.ID] {
+			return errors.New("duplicate zone ID: " + z.ID)
+		}
+		zones[z.ID] = true
+		for _, sectionID := range z.Sections {
+			if !slices.Contains(known, sectionID) {
+				return fmt.Errorf("zone %s has an unknown section: %s", z.ID, sectionID)
+			}
+			if owner, ok := owners[sectionID]; ok {
+				return fmt.Errorf("section %s is in zones %s and %s", sectionID, owner, z.ID)
+			}
+			owners[sectionID] = z.ID
+		}
+	}
+	if c.Alerts != nil {
+		for _, zoneID := range slices.Sorted(maps.Keys(c.Alerts.Zones)) {
+			if !zones[zoneID] {
+				return errors.New("alert rules of an unknown zone: " + zoneID)
+			}
+		}
+	}
+	return nil
+}
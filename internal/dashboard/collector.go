@@ -0,0 +1,228 @@
+// Package dashboard renders a live view of a running greenhouse in the
+// terminal. A Collector keeps the state shown, built from the event stream,
+// and Render turns that state into a frame, so the two can be tested apart;
+// Run ties them to a terminal and its keyboard.
+package dashboard
+
+import (
+	"fmt"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/environment"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/greenhouse"
+	"greenhouse-simulator/internal/watering"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAlerts is the number of recent alerts a Collector keeps when asked
+// for none.
+const DefaultAlerts = 5
+
+// Section summarizes the plants of one greenhouse section.
+type Section struct {
+	ID                string
+	Plants            int
+	AlivePlants       int
+	AverageHealth     float64
+	AverageSaturation float64
+	// Salinity is the soil salinity of the section, 0 without salinity
+	// settings.
+	Salinity float64
+	// Watering is true while a watering of the section is queued or running.
+	Watering bool
+}
+
+// Alert is a notable event, such as a plant dying or the tank running low.
+type Alert struct {
+	Tick    int
+	Message string
+}
+
+// State is what the dashboard shows.
+type State struct {
+	Tick      int
+	SimTime   time.Duration
+	Simulator engine.State
+	Speed     float64
+	// Sections are ordered by ID.
+	Sections []Section
+	// Alerts are the most recent alerts, oldest first.
+	Alerts []Alert
+	Water  watering.WaterStats
+}
+
+// Collector keeps the State of a greenhouse up to date from its events.
+type Collector interface {
+	// State returns the current state.
+	State() State
+	// Updates receives a value whenever the state changed. Changes that
+	// happen before the last one was received are coalesced.
+	Updates() <-chan struct{}
+	// Close stops collecting.
+	Close()
+}
+
+type collector struct {
+	g           greenhouse.Greenhouse
+	maxAlerts   int
+	updates     chan struct{}
+	unsubscribe func()
+
+	state State
+	mu    sync.Mutex
+}
+
+// NewCollector returns a collector for g keeping the last maxAlerts alerts,
+// DefaultAlerts when zero. The sections are summarized right away and again
+// after every tick and watering change.
+func NewCollector(g greenhouse.Greenhouse, maxAlerts int) Collector {
+	if maxAlerts == 0 {
+		maxAlerts = DefaultAlerts
+	}
+	c := &collector{
+		g:         g,
+		maxAlerts: maxAlerts,
+		updates:   make(chan struct{}, 1),
+	}
+	c.state.Tick = g.Simulator().GetCurrentTick()
+	c.state.SimTime = time.Duration(c.state.Tick) * g.Simulator().GetTickInterval()
+	c.refresh()
+	c.unsubscribe = g.Bus().Subscribe(c.handle)
+	return c
+}
+
+// State returns the current state, with the simulator state and speed read
+// live.
+// This method is safe for concurrent use.
+func (c *collector) State() State {
+	c.mu.Lock()
+	state := c.state
+	state.Sections = slices.Clone(c.state.Sections)
+	state.Alerts = slices.Clone(c.state.Alerts)
+	c.mu.Unlock()
+	state.Simulator = c.g.Simulator().State()
+	state.Speed = c.g.Simulator().GetSpeed()
+	return state
+}
+
+func (c *collector) Updates() <-chan struct{} {
+	return c.updates
+}
+
+func (c *collector) Close() {
+	c.unsubscribe()
+}
+
+// handle runs on the tick goroutine, or on the goroutine of a manual
+// watering.
+func (c *collector) handle(e events.Event) {
+	switch e.Type {
+	case events.Tick:
+		c.mu.Lock()
+		c.state.Tick = e.Tick + 1
+		c.state.SimTime += c.g.Simulator().GetTickInterval()
+		c.mu.Unlock()
+		c.refresh()
+	case events.WateringStarted, events.WateringCompleted, events.WateringCancelled:
+		c.refresh()
+	}
+	if message := alertMessage(e); message != "" {
+		c.mu.Lock()
+		c.state.Alerts = append(c.state.Alerts, Alert{Tick: e.Tick, Message: message})
+		if excess := len(c.state.Alerts) - c.maxAlerts; excess > 0 {
+			c.state.Alerts = c.state.Alerts[excess:]
+		}
+		c.mu.Unlock()
+	}
+	c.notify()
+}
+
+// refresh summarizes the sections and the water use.
+func (c *collector) refresh() {
+	watered := map[string]bool{}
+	for _, event := range c.g.Watering().GetActiveEvents() {
+		watered[event.SectionID] = true
+	}
+	bySection := map[string]*Section{}
+	for _, plant := range c.g.Simulator().GetAllPlants() {
+		section := bySection[plant.SectionID]
+		if section == nil {
+			section = &Section{ID: plant.SectionID}
+			bySection[plant.SectionID] = section
+		}
+		section.Plants++
+		if plant.Alive {
+			section.AlivePlants++
+		}
+		section.AverageHealth += plant.Health
+		section.AverageSaturation += plant.SoilSaturation
+	}
+	for id := range watered {
+		if bySection[id] == nil {
+			bySection[id] = &Section{ID: id}
+		}
+	}
+	sections := make([]Section, 0, len(bySection))
+	for _, section := range bySection {
+		if section.Plants > 0 {
+			section.AverageHealth /= float64(section.Plants)
+			section.AverageSaturation /= float64(section.Plants)
+		}
+		section.Watering = watered[section.ID]
+		section.Salinity = c.g.SectionConditions(section.ID).Salinity
+		sections = append(sections, *section)
+	}
+	slices.SortFunc(sections, func(a, b Section) int { return strings.Compare(a.ID, b.ID) })
+	water := c.g.Watering().GetWaterStats()
+
+	c.mu.Lock()
+	c.state.Sections = sections
+	c.state.Water = water
+	c.mu.Unlock()
+}
+
+func (c *collector) notify() {
+	select {
+	case c.updates <- struct{}{}:
+	default:
+	}
+}
+
+// alertMessage describes the events worth an alert, and returns "" for the
+// others.
+func alertMessage(e events.Event) string {
+	switch e.Type {
+	case events.PlantDied:
+		return fmt.Sprintf("%s died in %s", e.PlantID, e.SectionID)
+	case events.LowWater:
+		return "water tank low"
+	case events.WateringSkipped:
+		return fmt.Sprintf("watering of %s skipped, the tank is too low", e.SectionID)
+	case events.WateringCancelled:
+		return fmt.Sprintf("watering of %s cancelled", e.SectionID)
+	case events.ExtremeWeatherStarted:
+		if event, ok := e.Payload.(environment.ExtremeEvent); ok {
+			return fmt.Sprintf("%s for %d ticks", strings.ReplaceAll(string(event.Kind), "_", " "), event.Ticks)
+		}
+	case events.ExtremeWeatherEnded:
+		if event, ok := e.Payload.(environment.ExtremeEvent); ok {
+			return strings.ReplaceAll(string(event.Kind), "_", " ") + " over"
+		}
+	case events.TimelineAction:
+		if result, ok := e.Payload.(greenhouse.ActionResult); ok && result.Error != "" {
+			return fmt.Sprintf("timeline %s failed: %s", result.Action, result.Error)
+		}
+	case events.Alert:
+		if alert, ok := e.Payload.(greenhouse.Alert); ok {
+			return fmt.Sprintf("%s: %s", alert.Severity, alert.Message)
+		}
+	case events.AlertResolved:
+		if alert, ok := e.Payload.(greenhouse.Alert); ok {
+			return "resolved: " + alert.Message
+		}
+	}
+	return ""
+}
@@ -0,0 +1,108 @@
+package dashboard
+
+import (
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/environment"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/greenhouse"
+	"testing"
+	"time"
+)
+
+// newTestGreenhouse builds the demo greenhouse: tomato-1 and tomato-2 in
+// section-A, lettuce-1 in section-B, ticking every 4s, with a tank of 5.
+func newTestGreenhouse(t *testing.T) greenhouse.Greenhouse {
+	t.Helper()
+	g, err := greenhouse.New(config.Default())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	return g
+}
+
+func TestCollector_State(t *testing.T) {
+	g := newTestGreenhouse(t)
+	c := NewCollector(g, 0)
+	defer c.Close()
+
+	state := c.State()
+	if state.Tick != 0 || state.SimTime != 0 || state.Simulator != engine.Created || state.Speed != 1 {
+		t.Errorf("expected a fresh simulation at speed 1, got %+v", state)
+	}
+	if len(state.Sections) != 2 {
+		t.Fatalf("expected 2 sections, got %+v", state.Sections)
+	}
+	a, b := state.Sections[0], state.Sections[1]
+	if a.ID != "section-A" || a.Plants != 2 || a.AlivePlants != 2 || a.AverageSaturation != 0.4 || a.AverageHealth != 1 {
+		t.Errorf("expected both tomatoes in section-A at 0.4 saturation, got %+v", a)
+	}
+	if b.ID != "section-B" || b.Plants != 1 || b.AverageSaturation != 0.6 || b.Watering {
+		t.Errorf("expected the lettuce in section-B, got %+v", b)
+	}
+	if state.Water.Remaining != 5 {
+		t.Errorf("expected a full tank, got %+v", state.Water)
+	}
+
+	if err := g.Watering().WaterSection("section-B", 0.5, time.Minute); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g.Simulator().Step()
+	g.Simulator().Step()
+	select {
+	case <-c.Updates():
+	default:
+		t.Error("expected an update after the ticks")
+	}
+	state = c.State()
+	if state.Tick != 2 || state.SimTime != 8*time.Second {
+		t.Errorf("expected 2 ticks and 8s of simulated time, got tick %d at %s", state.Tick, state.SimTime)
+	}
+	if !state.Sections[1].Watering || state.Sections[0].Watering {
+		t.Errorf("expected only section-B to be watered, got %+v", state.Sections)
+	}
+}
+
+func TestCollector_Alerts(t *testing.T) {
+	g := newTestGreenhouse(t)
+	c := NewCollector(g, 2)
+
+	bus := g.Bus()
+	bus.Publish(events.Event{Type: events.PlantDied, Tick: 3, SectionID: "section-A", PlantID: "tomato-2"})
+	bus.Publish(events.Event{Type: events.SensorSample, Tick: 3, SectionID: "section-B"})
+	bus.Publish(events.Event{Type: events.LowWater, Tick: 4})
+	bus.Publish(events.Event{Type: events.TimelineAction, Tick: 5, Payload: greenhouse.ActionResult{Tick: 5, Action: config.ActionFailSensor, Error: "no sensor found: sensor-9"}})
+	bus.Publish(events.Event{Type: events.TimelineAction, Tick: 6, Payload: greenhouse.ActionResult{Tick: 6, Action: config.ActionFailSensor}})
+
+	expected := []Alert{
+		{Tick: 4, Message: "water tank low"},
+		{Tick: 5, Message: "timeline fail_sensor failed: no sensor found: sensor-9"},
+	}
+	if alerts := c.State().Alerts; len(alerts) != 2 || alerts[0] != expected[0] || alerts[1] != expected[1] {
+		t.Errorf("expected the last 2 alerts %+v, got %+v", expected, alerts)
+	}
+
+	c.Close()
+	bus.Publish(events.Event{Type: events.PlantDied, Tick: 7, SectionID: "section-B", PlantID: "lettuce-1"})
+	if alerts := c.State().Alerts; alerts[1] != expected[1] {
+		t.Errorf("expected no alerts after Close, got %+v", alerts)
+	}
+}
+
+func TestCollector_ExtremeWeatherAlerts(t *testing.T) {
+	g := newTestGreenhouse(t)
+	c := NewCollector(g, 2)
+	defer c.Close()
+
+	heatWave := environment.ExtremeEvent{Kind: environment.HeatWave, Start: 3, Ticks: 4}
+	g.Bus().Publish(events.Event{Type: events.ExtremeWeatherStarted, Tick: 3, Payload: heatWave})
+	g.Bus().Publish(events.Event{Type: events.ExtremeWeatherEnded, Tick: 7, Payload: heatWave})
+
+	expected := []Alert{
+		{Tick: 3, Message: "heat wave for 4 ticks"},
+		{Tick: 7, Message: "heat wave over"},
+	}
+	if alerts := c.State().Alerts; len(alerts) != 2 || alerts[0] != expected[0] || alerts[1] != expected[1] {
+		t.Errorf("expected the alerts %+v, got %+v", expected, alerts)
+	}
+}
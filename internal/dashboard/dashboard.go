@@ -0,0 +1,194 @@
+package dashboard
+
+import (
+	"fmt"
+	"greenhouse-simulator/internal/greenhouse"
+	"greenhouse-simulator/internal/models"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultWaterAmount and DefaultWaterDuration make up the manual watering
+	// of the w key when Options leaves them at zero.
+	DefaultWaterAmount   = 0.5
+	DefaultWaterDuration = 8 * time.Second
+	// MinSpeed and MaxSpeed bound the speed the + and - keys set.
+	MinSpeed = 1.0 / 16
+	MaxSpeed = 64
+)
+
+// Options configures Run.
+type Options struct {
+	// Size returns the terminal width and height; 80 by 24 when nil.
+	Size func() (width, height int)
+	// WaterAmount and WaterDuration make up the manual watering of the w key.
+	WaterAmount   float64
+	WaterDuration time.Duration
+}
+
+// Terminal control sequences.
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l" // alternate screen, hidden cursor
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+	home        = "\x1b[H"
+	clearLine   = "\x1b[K"
+	clearBelow  = "\x1b[J"
+)
+
+// Run shows the dashboard of g on out, which should be a terminal in raw
+// mode, and reads keys from in until q or Ctrl-C is pressed, in ends or stop
+// is closed:
+//
+//	space       pause or resume the simulation
+//	n           run one tick while paused
+//	+ and -     double or halve the speed
+//	up and down select a section (also k and j)
+//	w           water the selected section
+//
+// The frame is redrawn whenever the greenhouse changes, a key is pressed, and
+// twice a second to follow terminal resizes. The screen is restored and the
+// event subscription stopped before Run returns. Run leaves the simulation
+// as it is, possibly paused.
+func Run(g greenhouse.Greenhouse, in io.Reader, out io.Writer, opts Options, stop <-chan struct{}) error {
+	if opts.Size == nil {
+		opts.Size = func() (int, int) { return 80, 24 }
+	}
+	if opts.WaterAmount == 0 {
+		opts.WaterAmount = DefaultWaterAmount
+	}
+	if opts.WaterDuration == 0 {
+		opts.WaterDuration = DefaultWaterDuration
+	}
+	collector := NewCollector(g, 0)
+	defer collector.Close()
+	done := make(chan struct{})
+	defer close(done)
+	keys := readKeys(in, done)
+
+	if _, err := io.WriteString(out, enterScreen); err != nil {
+		return err
+	}
+	defer io.WriteString(out, leaveScreen)
+	redraw := time.NewTicker(500 * time.Millisecond)
+	defer redraw.Stop()
+
+	d := &dashboard{g: g, opts: opts}
+	for {
+		view := View{State: collector.State(), Selected: d.selected, Message: d.message}
+		d.sections = view.Sections
+		view.Selected = d.clampSelection()
+		view.Forecasts = d.forecasts()
+		width, height := opts.Size()
+		if err := draw(out, Render(view, width, height)); err != nil {
+			return err
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-collector.Updates():
+		case <-redraw.C:
+		case key, ok := <-keys:
+			if !ok || key == keyQuit {
+				return nil
+			}
+			d.press(key)
+		}
+	}
+}
+
+// draw writes the lines over the previous frame.
+func draw(out io.Writer, lines []string) error {
+	var frame strings.Builder
+	frame.WriteString(home)
+	for i, line := range lines {
+		if i > 0 {
+			frame.WriteString("\r\n")
+		}
+		frame.WriteString(line)
+		frame.WriteString(clearLine)
+	}
+	frame.WriteString(clearBelow)
+	_, err := io.WriteString(out, frame.String())
+	return err
+}
+
+// dashboard holds what the keys change.
+type dashboard struct {
+	g        greenhouse.Greenhouse
+	opts     Options
+	sections []Section
+	selected int
+	message  string
+}
+
+func (d *dashboard) clampSelection() int {
+	d.selected = min(max(d.selected, 0), max(len(d.sections)-1, 0))
+	return d.selected
+}
+
+// forecasts returns the forecasts of the plants of the selected section,
+// with their journals.
+func (d *dashboard) forecasts() []PlantForecast {
+	if len(d.sections) == 0 {
+		return nil
+	}
+	plants := d.g.Simulator().GetPlantsBySectionID(d.sections[d.selected].ID)
+	slices.SortFunc(plants, func(a, b *models.Plant) int { return strings.Compare(a.ID, b.ID) })
+	var forecasts []PlantForecast
+	for _, plant := range plants {
+		forecast, err := d.g.PlantForecast(plant.ID)
+		if err != nil {
+			continue // removed since
+		}
+		// Without plant journals, the plant has none.
+		journal, _ := d.g.GetPlantJournal(plant.ID)
+		forecasts = append(forecasts, PlantForecast{PlantID: plant.ID, ExcludeFromWatering: plant.ExcludeFromWatering,
+			Quarantined: plant.Quarantined, Journal: journal, PlantForecast: *forecast})
+	}
+	return forecasts
+}
+
+func (d *dashboard) press(k key) {
+	d.message = ""
+	sim := d.g.Simulator()
+	switch k {
+	case keyPause:
+		var err error
+		if d.g.Paused() {
+			err = d.g.Resume()
+		} else {
+			err = d.g.Pause()
+		}
+		if err != nil {
+			d.message = err.Error()
+		}
+	case keyStep:
+		if !d.g.Paused() {
+			d.message = "pause to step one tick at a time"
+			return
+		}
+		sim.Step()
+	case keyFaster:
+		sim.SetSpeed(min(sim.GetSpeed()*2, MaxSpeed))
+	case keySlower:
+		sim.SetSpeed(max(sim.GetSpeed()/2, MinSpeed))
+	case keyUp:
+		d.selected--
+	case keyDown:
+		d.selected++
+	case keyWater:
+		if len(d.sections) == 0 {
+			d.message = "no section to water"
+			return
+		}
+		section := d.sections[d.clampSelection()].ID
+		if err := d.g.Watering().WaterSection(section, d.opts.WaterAmount, d.opts.WaterDuration); err != nil {
+			d.message = err.Error()
+			return
+		}
+		d.message = fmt.Sprintf("watering %s with %g over %s", section, d.opts.WaterAmount, d.opts.WaterDuration)
+	}
+}
@@ -0,0 +1,76 @@
+package dashboard
+
+import (
+	"bytes"
+	"greenhouse-simulator/internal/engine"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// eventually polls until condition holds, failing the test after a second.
+func eventually(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRun_Keys(t *testing.T) {
+	g := newTestGreenhouse(t)
+	sim := g.Simulator()
+	go sim.Start()
+	defer sim.Stop()
+	eventually(t, "the start", func() bool { return sim.State() == engine.Running })
+
+	keys, typing := io.Pipe()
+	var out bytes.Buffer
+	done := make(chan error)
+	go func() { done <- Run(g, keys, &out, Options{WaterDuration: time.Minute}, nil) }()
+
+	// Faster twice, slower once, then down to section-B and water it.
+	io.WriteString(typing, "++-\x1b[Bw")
+	eventually(t, "section-B to be watered", func() bool {
+		active := g.Watering().GetActiveEvents()
+		return len(active) == 1 && active[0].SectionID == "section-B" && active[0].IsManual
+	})
+	if speed := sim.GetSpeed(); speed != 2 {
+		t.Errorf("expected speed 2, got %v", speed)
+	}
+	io.WriteString(typing, " ")
+	eventually(t, "the pause", g.Paused)
+	// Paused, n steps one tick at a time.
+	tick := sim.GetCurrentTick()
+	io.WriteString(typing, "n")
+	eventually(t, "a step", func() bool { return sim.GetCurrentTick() == tick+1 })
+	io.WriteString(typing, "q")
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g.Resume()
+
+	frame := out.String()
+	if !strings.HasPrefix(frame, enterScreen) || !strings.HasSuffix(frame, leaveScreen) {
+		t.Errorf("expected the screen to be set up and restored, got %q", frame)
+	}
+	for _, expected := range []string{"> section-B", "Forecast for section-B without water", "  lettuce-1  mature", "watering section-B with 0.5 over 1m0s", "PAUSED"} {
+		if !strings.Contains(frame, expected) {
+			t.Errorf("expected %q in the output", expected)
+		}
+	}
+}
+
+func TestRun_StopsWithInput(t *testing.T) {
+	g := newTestGreenhouse(t)
+	// Ctrl-C in raw mode, and the end of the input, both end the dashboard.
+	for _, input := range []string{"\x03", ""} {
+		if err := Run(g, strings.NewReader(input), io.Discard, Options{}, nil); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+}
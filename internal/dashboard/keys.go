@@ -0,0 +1,91 @@
+package dashboard
+
+import "io"
+
+// key is a key press the dashboard reacts to.
+type key int
+
+const (
+	keyQuit key = iota
+	keyPause
+	keyFaster
+	keySlower
+	keyUp
+	keyDown
+	keyWater
+	keyStep
+)
+
+// readKeys decodes the key presses read from in, raw terminal input, until
+// it fails or ends; the channel is closed then. Other keys are ignored. Once
+// done is closed, keys are no longer delivered, though a pending read of in
+// cannot be interrupted.
+func readKeys(in io.Reader, done <-chan struct{}) <-chan key {
+	keys := make(chan key)
+	go func() {
+		defer close(keys)
+		buf := make([]byte, 64)
+		// escape counts the bytes of an ESC [ sequence read so far.
+		escape := 0
+		for {
+			n, err := in.Read(buf)
+			for _, b := range buf[:n] {
+				k, ok := decode(b, &escape)
+				if !ok {
+					continue
+				}
+				select {
+				case keys <- k:
+				case <-done:
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return keys
+}
+
+// decode decodes one input byte, keeping track of arrow key sequences.
+func decode(b byte, escape *int) (key, bool) {
+	switch *escape {
+	case 1:
+		*escape = 0
+		if b == '[' {
+			*escape = 2
+		}
+		return 0, false
+	case 2:
+		*escape = 0
+		switch b {
+		case 'A':
+			return keyUp, true
+		case 'B':
+			return keyDown, true
+		}
+		return 0, false
+	}
+	switch b {
+	case 0x1b:
+		*escape = 1
+	case 'q', 'Q', 0x03: // 0x03 is Ctrl-C in raw mode
+		return keyQuit, true
+	case ' ':
+		return keyPause, true
+	case '+', '=':
+		return keyFaster, true
+	case '-', '_':
+		return keySlower, true
+	case 'k':
+		return keyUp, true
+	case 'j':
+		return keyDown, true
+	case 'w', 'W':
+		return keyWater, true
+	case 'n', 'N':
+		return keyStep, true
+	}
+	return 0, false
+}
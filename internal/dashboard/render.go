@@ -0,0 +1,284 @@
+package dashboard
+
+import (
+	"fmt"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/greenhouse"
+	"greenhouse-simulator/internal/models"
+	"greenhouse-simulator/internal/watering"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Widths below which Render leaves parts of the frame out.
+const (
+	// BarWidth is the narrowest terminal that shows health and saturation
+	// bars; narrower ones get the numbers only.
+	BarWidth = 72
+	// CompactWidth is the narrowest terminal with column headers and key
+	// help; narrower ones get one terse line per section.
+	CompactWidth = 44
+)
+
+// JournalRows is the number of the latest journal entries shown under the
+// forecast of each plant.
+const JournalRows = 3
+
+// View is a State with what the user did to it: the selected section and
+// the outcome of the last key pressed.
+type View struct {
+	State
+	// Selected is the index of the selected section.
+	Selected int
+	// Message is shown under the alerts, e.g. when watering failed.
+	Message string
+	// Forecasts are the forecasts of the plants of the selected section,
+	// ordered by plant ID.
+	Forecasts []PlantForecast
+}
+
+// PlantForecast is the forecast of a plant, see
+// greenhouse.Greenhouse.PlantForecast, with the flags of the plant and its
+// journal, none when the greenhouse keeps no plant journals.
+type PlantForecast struct {
+	PlantID             string
+	ExcludeFromWatering bool
+	Quarantined         bool
+	Journal             []greenhouse.JournalEntry
+	models.PlantForecast
+}
+
+// Render lays v out for a terminal of the given size and returns the lines,
+// none longer than width runes and no more than height of them. Narrow
+// terminals lose the bars, then the headers and forecasts; short ones lose
+// the alerts, then the forecasts, then the sections furthest from the
+// selected one.
+func Render(v View, width, height int) []string {
+	compact := width < CompactWidth
+	header := []string{status(v.State, compact), ""}
+	if !compact {
+		header = append(header, sectionHeader(v, width))
+	}
+	var footer []string
+	footer = append(footer, "", water(v.Water))
+	if v.Message != "" {
+		footer = append(footer, v.Message)
+	}
+	if compact {
+		footer = append(footer, "spc n +/- j/k w q")
+	} else {
+		footer = append(footer, "space pause/resume  n step  +/- speed  up/down select  w water  q quit")
+	}
+	alerts := []string{"", "Alerts"}
+	if len(v.Alerts) == 0 {
+		alerts = append(alerts, "  none")
+	}
+	for _, alert := range v.Alerts {
+		alerts = append(alerts, fmt.Sprintf("  #%d %s", alert.Tick, alert.Message))
+	}
+
+	var forecasts []string
+	if !compact && len(v.Forecasts) > 0 && v.Selected < len(v.Sections) {
+		forecasts = forecastRows(v)
+	}
+
+	rows := sectionRows(v, width)
+	if len(header)+len(rows)+len(forecasts)+len(alerts)+len(footer) > height {
+		alerts = nil
+	}
+	if len(header)+len(rows)+len(forecasts)+len(footer) > height {
+		forecasts = nil
+	}
+	if room := max(height-len(header)-len(footer), 1); len(rows) > room {
+		first := min(max(v.Selected-room/2, 0), len(rows)-room)
+		rows = rows[first : first+room]
+	}
+
+	lines := append(append(append(append(header, rows...), forecasts...), alerts...), footer...)
+	lines = lines[:min(len(lines), height)]
+	for i, line := range lines {
+		lines[i] = truncate(line, width)
+	}
+	return lines
+}
+
+func status(s State, compact bool) string {
+	state := string(s.Simulator)
+	if s.Simulator == engine.Paused {
+		state = "PAUSED"
+	}
+	simTime := s.SimTime.Truncate(time.Second).String()
+	speed := "x" + strconv.FormatFloat(s.Speed, 'g', -1, 64)
+	if compact {
+		return fmt.Sprintf("#%d %s %s %s", s.Tick, simTime, state, speed)
+	}
+	return fmt.Sprintf("Greenhouse  tick %d  sim time %s  %s  speed %s", s.Tick, simTime, state, speed)
+}
+
+// nameWidth is the width of the section column.
+func nameWidth(v View) int {
+	width := len("SECTION")
+	for _, section := range v.Sections {
+		width = max(width, len(section.ID))
+	}
+	return min(width, 20)
+}
+
+// barWidth is the width of each bar, or 0 when the terminal is too narrow.
+func barWidth(v View, width int) int {
+	if width < BarWidth {
+		return 0
+	}
+	// Two bars and their values next to the cursor, name, plant and water
+	// columns.
+	return min((width-nameWidth(v)-34)/2, 30)
+}
+
+func sectionHeader(v View, width int) string {
+	names := fmt.Sprintf("  %-*s  %-6s", nameWidth(v), "SECTION", "PLANTS")
+	if bars := barWidth(v, width); bars > 0 {
+		return names + fmt.Sprintf("  %-*s  %-*s  WATER", bars+5, "HEALTH", bars+5, "SATURATION")
+	}
+	return names + "  HEALTH  SATUR.  WATER"
+}
+
+func sectionRows(v View, width int) []string {
+	if len(v.Sections) == 0 {
+		return []string{"  no plants"}
+	}
+	names := nameWidth(v)
+	bars := barWidth(v, width)
+	rows := make([]string, len(v.Sections))
+	for i, section := range v.Sections {
+		cursor := "  "
+		if i == v.Selected {
+			cursor = "> "
+		}
+		plants := fmt.Sprintf("%d/%d", section.AlivePlants, section.Plants)
+		switch {
+		case width < CompactWidth:
+			watering := ""
+			if section.Watering {
+				watering = " ~"
+			}
+			rows[i] = fmt.Sprintf("%s%s %s h%.2f s%.2f%s", cursor, section.ID, plants, section.AverageHealth, section.AverageSaturation, watering)
+		case bars > 0:
+			rows[i] = fmt.Sprintf("%s%-*s  %-6s  %s %.2f  %s %.2f  %s", cursor, names, section.ID, plants,
+				bar(section.AverageHealth, bars), section.AverageHealth,
+				bar(section.AverageSaturation, bars), section.AverageSaturation,
+				wateringLabel(section)) + salinityLabel(section)
+		default:
+			rows[i] = fmt.Sprintf("%s%-*s  %-6s  %-6.2f  %-6.2f  %s", cursor, names, section.ID, plants,
+				section.AverageHealth, section.AverageSaturation, wateringLabel(section)) + salinityLabel(section)
+		}
+	}
+	return rows
+}
+
+// forecastRows lists the forecasts of the plants of the selected section, as
+// they would go on unwatered, each followed by the last JournalRows entries
+// of the plant's journal.
+func forecastRows(v View) []string {
+	rows := []string{"", "Forecast for " + v.Sections[v.Selected].ID + " without water"}
+	for _, f := range v.Forecasts {
+		plant := f.PlantID + flagsLabel(f)
+		if f.TicksToDeath == 0 {
+			rows = append(rows, fmt.Sprintf("  %s  dead", plant))
+		} else {
+			rows = append(rows, fmt.Sprintf("  %s  mature %s  water %s, below %.2f  dies %s", plant,
+				ticksLabel(f.TicksToMaturity), ticksLabel(f.TicksToIntervention), f.InterventionSaturation, ticksLabel(f.TicksToDeath)))
+		}
+		for _, entry := range f.Journal[max(len(f.Journal)-JournalRows, 0):] {
+			rows = append(rows, fmt.Sprintf("    #%d %s", entry.Tick, journalLabel(entry)))
+		}
+	}
+	return rows
+}
+
+// journalLabel describes an entry of a plant journal.
+func journalLabel(e greenhouse.JournalEntry) string {
+	switch e.Type {
+	case events.PlantStageChanged:
+		return fmt.Sprintf("stage %s -> %s", e.From, e.To)
+	case events.PlantHealthChanged:
+		return fmt.Sprintf("health %s -> %s (%.2f)", e.From, e.To, e.Value)
+	case events.PlantStressStarted:
+		return fmt.Sprintf("%s stress (saturation %.2f)", e.To, e.Value)
+	case events.PlantStressEnded:
+		return fmt.Sprintf("%s stress over (saturation %.2f)", e.From, e.Value)
+	case events.PlantWatered:
+		return fmt.Sprintf("watered %.2f by %s", e.Value, e.To)
+	case events.PlantInfected:
+		return "infected"
+	case events.PlantCured:
+		return "cured"
+	case events.PlantDied:
+		return "died of " + e.To
+	}
+	return string(e.Type)
+}
+
+// flagsLabel marks a plant that is excluded from watering or quarantined.
+func flagsLabel(f PlantForecast) string {
+	var flags []string
+	if f.ExcludeFromWatering {
+		flags = append(flags, "unwatered")
+	}
+	if f.Quarantined {
+		flags = append(flags, "quarantined")
+	}
+	if len(flags) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(flags, ", ") + "]"
+}
+
+// ticksLabel describes a tick count of a forecast.
+func ticksLabel(ticks int) string {
+	switch ticks {
+	case models.Never:
+		return "never"
+	case 0:
+		return "now"
+	}
+	return fmt.Sprintf("in %d", ticks)
+}
+
+func wateringLabel(s Section) string {
+	if s.Watering {
+		return "watering"
+	}
+	return "-"
+}
+
+// salinityLabel shows the soil salinity of a section that has any.
+func salinityLabel(s Section) string {
+	if s.Salinity == 0 {
+		return ""
+	}
+	return fmt.Sprintf("  salt %.2f", s.Salinity)
+}
+
+// bar draws a fraction between 0 and 1 as width cells.
+func bar(fraction float64, width int) string {
+	filled := int(min(max(fraction, 0), 1)*float64(width) + 0.5)
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+func water(w watering.WaterStats) string {
+	if w.Unlimited {
+		return fmt.Sprintf("Water used %.1f, wasted %.1f", w.Used, w.Wasted)
+	}
+	return fmt.Sprintf("Tank %.1f left, used %.1f, wasted %.1f", w.Remaining, w.Used, w.Wasted)
+}
+
+// truncate cuts line to width runes.
+func truncate(line string, width int) string {
+	runes := []rune(line)
+	if len(runes) <= width {
+		return line
+	}
+	return string(runes[:max(width, 0)])
+}
@@ -0,0 +1,176 @@
+package dashboard
+
+import (
+	"fmt"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/greenhouse"
+	"greenhouse-simulator/internal/models"
+	"greenhouse-simulator/internal/watering"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func testView() View {
+	return View{
+		State: State{
+			Tick:      42,
+			SimTime:   168*time.Second + 500*time.Millisecond,
+			Simulator: engine.Running,
+			Speed:     2,
+			Sections: []Section{
+				{ID: "section-A", Plants: 2, AlivePlants: 2, AverageHealth: 0.9, AverageSaturation: 0.45, Watering: true},
+				{ID: "section-B", Plants: 1, AlivePlants: 0, AverageHealth: 0, AverageSaturation: 0.1},
+			},
+			Alerts: []Alert{{Tick: 40, Message: "lettuce-1 died in section-B"}},
+			Water:  watering.WaterStats{Used: 1.25, Remaining: 3.75},
+		},
+		Selected: 1,
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		view     func(v *View)
+		width    int
+		height   int
+		expected []string
+		missing  []string
+	}{
+		{
+			name:  "wide",
+			width: 100, height: 30,
+			expected: []string{
+				"Greenhouse  tick 42  sim time 2m48s  running  speed x2",
+				"  SECTION    PLANTS  HEALTH",
+				"  section-A  2/2     " + strings.Repeat("█", 25) + "░░░ 0.90",
+				"> section-B  0/1     " + strings.Repeat("░", 28) + " 0.00",
+				"watering",
+				"  #40 lettuce-1 died in section-B",
+				"Tank 3.8 left, used 1.2, wasted 0.0",
+				"space pause/resume",
+			},
+		},
+		{
+			name:  "no bars",
+			view:  func(v *View) { v.Simulator = engine.Paused },
+			width: 60, height: 30,
+			expected: []string{"PAUSED", "  SECTION    PLANTS  HEALTH  SATUR.  WATER", "> section-B  0/1     0.00    0.10    -"},
+			missing:  []string{"█"},
+		},
+		{
+			name:  "salty",
+			view:  func(v *View) { v.Sections[1].Salinity = 0.35 },
+			width: 60, height: 30,
+			expected: []string{"> section-B  0/1     0.00    0.10    -  salt 0.35"},
+		},
+		{
+			name:  "compact",
+			view:  func(v *View) { v.Message = "no such section" },
+			width: 30, height: 30,
+			expected: []string{"#42 2m48s running x2", "  section-A 2/2 h0.90 s0.45 ~", "no such section", "spc n +/- j/k w q"},
+			missing:  []string{"SECTION", "space pause/resume"},
+		},
+		{
+			name: "forecasts",
+			view: func(v *View) {
+				v.Selected = 0
+				v.Forecasts = []PlantForecast{
+					{PlantID: "tomato-1", PlantForecast: models.PlantForecast{TicksToMaturity: 12, TicksToDeath: 30, TicksToIntervention: 0, InterventionSaturation: 0.3}},
+					{PlantID: "tomato-2", PlantForecast: models.PlantForecast{TicksToMaturity: models.Never, TicksToDeath: 0, TicksToIntervention: models.Never}},
+				}
+			},
+			width: 100, height: 30,
+			expected: []string{
+				"Forecast for section-A without water",
+				"  tomato-1  mature in 12  water now, below 0.30  dies in 30",
+				"  tomato-2  dead",
+				"  #40 lettuce-1 died in section-B",
+			},
+		},
+		{
+			name: "flagged forecasts",
+			view: func(v *View) {
+				v.Selected = 0
+				v.Forecasts = []PlantForecast{
+					{PlantID: "tomato-1", ExcludeFromWatering: true, PlantForecast: models.PlantForecast{TicksToMaturity: 12, TicksToDeath: 30, InterventionSaturation: 0.3}},
+					{PlantID: "tomato-2", ExcludeFromWatering: true, Quarantined: true, PlantForecast: models.PlantForecast{TicksToDeath: 0}},
+				}
+			},
+			width: 100, height: 30,
+			expected: []string{
+				"  tomato-1 [unwatered]  mature in 12  water now, below 0.30  dies in 30",
+				"  tomato-2 [unwatered, quarantined]  dead",
+			},
+		},
+		{
+			name: "journal",
+			view: func(v *View) {
+				v.Selected = 0
+				v.Forecasts = []PlantForecast{{PlantID: "tomato-1", PlantForecast: models.PlantForecast{TicksToDeath: 0}, Journal: []greenhouse.JournalEntry{
+					{Tick: 3, Type: events.PlantStageChanged, From: "seedling", To: "vegetative", Value: 0.25},
+					{Tick: 5, Type: events.PlantStressStarted, To: "drought", Value: 0.25},
+					{Tick: 7, Type: events.PlantHealthChanged, From: "0.75 and above", To: "0.50-0.75", Value: 0.6},
+					{Tick: 11, Type: events.PlantDied, To: "drought"},
+				}}}
+			},
+			width: 100, height: 30,
+			expected: []string{
+				"  tomato-1  dead\n    #5 drought stress (saturation 0.25)\n    #7 health 0.75 and above -> 0.50-0.75 (0.60)\n    #11 died of drought",
+			},
+			missing: []string{"#3 stage"},
+		},
+		{
+			name: "forecasts short",
+			view: func(v *View) {
+				v.Forecasts = []PlantForecast{{PlantID: "lettuce-1", PlantForecast: models.PlantForecast{TicksToDeath: 0}}}
+			},
+			width: 80, height: 9,
+			expected: []string{"> section-B"},
+			missing:  []string{"Alerts", "Forecast"},
+		},
+		{
+			name: "short",
+			view: func(v *View) {
+				for i := range 10 {
+					v.Sections = append(v.Sections, Section{ID: fmt.Sprintf("bed-%d", i)})
+				}
+				v.Selected = 8
+			},
+			width: 80, height: 9,
+			expected: []string{"> bed-6"},
+			missing:  []string{"Alerts", "section-A"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := testView()
+			if tt.view != nil {
+				tt.view(&v)
+			}
+			lines := Render(v, tt.width, tt.height)
+			if len(lines) > tt.height {
+				t.Errorf("expected at most %d lines, got %d", tt.height, len(lines))
+			}
+			for _, line := range lines {
+				if utf8.RuneCountInString(line) > tt.width {
+					t.Errorf("expected lines of at most %d runes, got %q", tt.width, line)
+				}
+			}
+			frame := strings.Join(lines, "\n")
+			for _, expected := range tt.expected {
+				if !strings.Contains(frame, expected) {
+					t.Errorf("expected %q in the frame:\n%s", expected, frame)
+				}
+			}
+			for _, missing := range tt.missing {
+				if strings.Contains(frame, missing) {
+					t.Errorf("expected no %q in the frame:\n%s", missing, frame)
+				}
+			}
+		})
+	}
+}
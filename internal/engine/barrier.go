@@ -0,0 +1,35 @@
+package engine
+
+import "sync"
+
+// TickBarrier keeps work off the ticks of a simulator. A tick holds the
+// barrier from the plant updates to the last tick listener, and BetweenTicks
+// runs its function while no tick does, so that what it reads of the plants,
+// the sensors and the rest of the greenhouse is all from the same tick.
+type TickBarrier struct {
+	mu sync.Mutex
+}
+
+// NewTickBarrier returns a barrier no tick holds.
+func NewTickBarrier() *TickBarrier {
+	return &TickBarrier{}
+}
+
+// HoldTick holds the barrier for a tick, waiting for a running BetweenTicks,
+// and returns the func that releases it once the tick is over.
+// This method is safe for concurrent use.
+func (b *TickBarrier) HoldTick() (release func()) {
+	b.mu.Lock()
+	return b.mu.Unlock
+}
+
+// BetweenTicks runs fn once the running tick, if any, is over, and holds the
+// next tick off until fn returns. fn must not step the simulator, and tick
+// hooks and listeners must not call BetweenTicks, which would wait for their
+// own tick to end.
+// This method is safe for concurrent use.
+func (b *TickBarrier) BetweenTicks(fn func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	fn()
+}
@@ -0,0 +1,29 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBetweenTicks_WaitsForTheRunningTick(t *testing.T) {
+	s := NewSimulator(time.Hour)
+	listening, release := make(chan struct{}), make(chan struct{})
+	s.AddTickListener(tickListenerFunc(func(int) {
+		close(listening)
+		<-release
+	}))
+	go s.Step()
+	<-listening
+
+	ran := make(chan int)
+	go s.BetweenTicks(func() { ran <- s.GetCurrentTick() })
+	select {
+	case <-ran:
+		t.Fatal("expected BetweenTicks to wait for the tick listeners")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	if tick := <-ran; tick != 1 {
+		t.Errorf("expected BetweenTicks to run after tick 0, got %d", tick)
+	}
+}
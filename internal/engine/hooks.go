@@ -0,0 +1,227 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/models"
+	"log"
+	"slices"
+	"sync"
+)
+
+var (
+	// ErrHookExists is returned when adding a tick hook under a name taken
+	// by another.
+	ErrHookExists = errors.New("tick hook already added")
+	// ErrHookNotFound is returned when removing a tick hook that was not
+	// added.
+	ErrHookNotFound = errors.New("no tick hook found for the provided name")
+	// ErrNoEnvironment is returned when a tick hook sets the environment of a
+	// simulator that has none, see Simulator.SetHookEnvironment.
+	ErrNoEnvironment = errors.New("simulator has no environment")
+)
+
+// TickHook is custom logic run on every tick, see Simulator.AddTickHook. An
+// error fails the hook for this tick only.
+type TickHook func(ctx TickContext) error
+
+// Environment is the environment around a simulator that tick hooks can
+// change, as the set_environment timeline action does. environment.Humidity
+// implements it.
+type Environment interface {
+	SetAmbient(ambient, decay float64) error
+}
+
+// TickContext is what a tick hook sees of the tick it runs on, and the
+// changes it is allowed to make.
+type TickContext struct {
+	tick   int
+	plants func() []*models.Plant
+	water  func(plantID string, amount float64) error
+	env    Environment
+}
+
+// NewTickContext returns the context of tick for the tick hooks of a
+// simulator: plants snapshots its plants, water waters one of them and env is
+// its environment, nil if it has none.
+func NewTickContext(tick int, plants func() []*models.Plant, water func(plantID string, amount float64) error, env Environment) TickContext {
+	return TickContext{tick: tick, plants: plants, water: water, env: env}
+}
+
+// Tick returns the tick being processed.
+func (c TickContext) Tick() int {
+	return c.tick
+}
+
+// Plants returns snapshots of the plants after their update on this tick, in
+// the order they were added. Changing them changes nothing in the simulation.
+func (c TickContext) Plants() []*models.Plant {
+	return c.plants()
+}
+
+// WaterPlant adds amount of water to the soil of a plant, straight from the
+// hook: no tank is drawn from and the water stats do not count it.
+// Returns an error if:
+// - amount is not positive
+// - no plant has the given ID (ErrPlantNotFound)
+func (c TickContext) WaterPlant(plantID string, amount float64) error {
+	if amount <= 0 {
+		return errors.New("water amount must be positive")
+	}
+	return c.water(plantID, amount)
+}
+
+// SetEnvironment sets the ambient humidity sections return to, and how fast
+// they do, from this tick on.
+// Returns ErrNoEnvironment if the simulator has no environment, or the error
+// of the environment if the values are invalid.
+func (c TickContext) SetEnvironment(ambientHumidity, humidityDecay float64) error {
+	if c.env == nil {
+		return ErrNoEnvironment
+	}
+	return c.env.SetAmbient(ambientHumidity, humidityDecay)
+}
+
+// TickHookStats counts the runs of a tick hook. Failed counts the runs that
+// returned an error or panicked, and LastError describes the last of them.
+type TickHookStats struct {
+	Name      string
+	Runs      int
+	Failed    int
+	LastError string
+}
+
+type tickHook struct {
+	name  string
+	run   TickHook
+	stats *TickHookStats
+}
+
+// TickHooks is the chain of tick hooks of a simulator. The simulator runs it
+// after the plant updates of every tick and before the tick listeners, the
+// exporters included. Hooks run in the order they were added; a hook that
+// fails or panics is logged and counted in its TickHookStats, and the next
+// one runs as if nothing happened.
+type TickHooks struct {
+	// hooks is replaced rather than changed, so that a tick can run it
+	// without holding mu.
+	hooks []tickHook
+	env   Environment
+	mu    sync.Mutex
+}
+
+// NewTickHooks returns an empty chain of tick hooks.
+func NewTickHooks() *TickHooks {
+	return &TickHooks{}
+}
+
+// AddTickHook adds hook to the end of the chain under name, from the next
+// tick on.
+// Returns an error if:
+// - name is empty or hook is nil
+// - a hook was added under name already (ErrHookExists)
+// This method is safe for concurrent use.
+func (h *TickHooks) AddTickHook(name string, hook TickHook) error {
+	if name == "" {
+		return errors.New("tick hook name cannot be empty")
+	}
+	if hook == nil {
+		return errors.New("tick hook cannot be nil: " + name)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if slices.ContainsFunc(h.hooks, func(other tickHook) bool { return other.name == name }) {
+		return fmt.Errorf("%w: %s", ErrHookExists, name)
+	}
+	h.hooks = append(slices.Clip(h.hooks), tickHook{name: name, run: hook, stats: &TickHookStats{Name: name}})
+	return nil
+}
+
+// RemoveTickHook removes the hook added under name. A tick running the
+// chain may still run it.
+// Returns an error wrapping ErrHookNotFound if no hook has that name.
+// This method is safe for concurrent use.
+func (h *TickHooks) RemoveTickHook(name string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hooks := slices.DeleteFunc(slices.Clone(h.hooks), func(hook tickHook) bool { return hook.name == name })
+	if len(hooks) == len(h.hooks) {
+		return fmt.Errorf("%w: %s", ErrHookNotFound, name)
+	}
+	h.hooks = hooks
+	return nil
+}
+
+// TickHookStats returns the stats of every hook, in chain order.
+// This method is safe for concurrent use.
+func (h *TickHooks) TickHookStats() []TickHookStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	stats := make([]TickHookStats, len(h.hooks))
+	for i, hook := range h.hooks {
+		stats[i] = *hook.stats
+	}
+	return stats
+}
+
+// SetHookEnvironment sets the environment tick hooks change through
+// TickContext.SetEnvironment.
+// This method is safe for concurrent use.
+func (h *TickHooks) SetHookEnvironment(env Environment) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.env = env
+}
+
+// HasTickHooks reports whether the chain has hooks, so that simulators only
+// build a TickContext for ticks that run some.
+// This method is safe for concurrent use.
+func (h *TickHooks) HasTickHooks() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.hooks) > 0
+}
+
+// HookEnvironment returns the environment set by SetHookEnvironment, nil if
+// none was.
+// This method is safe for concurrent use.
+func (h *TickHooks) HookEnvironment() Environment {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.env
+}
+
+// RunTickHooks runs the chain on ctx, in a HookPhase of trace.
+func (h *TickHooks) RunTickHooks(trace *TickTrace, ctx TickContext) {
+	h.mu.Lock()
+	hooks := h.hooks
+	h.mu.Unlock()
+	if len(hooks) == 0 {
+		return
+	}
+	end := trace.Phase(HookPhase)
+	defer end()
+	for _, hook := range hooks {
+		err := hook.call(ctx)
+		h.mu.Lock()
+		hook.stats.Runs++
+		if err != nil {
+			hook.stats.Failed++
+			hook.stats.LastError = err.Error()
+		}
+		h.mu.Unlock()
+		if err != nil {
+			log.Printf("Tick hook %s failed on tick %d: %v", hook.name, ctx.tick, err)
+		}
+	}
+}
+
+// call runs the hook, turning a panic into an error.
+func (hook tickHook) call(ctx TickContext) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return hook.run(ctx)
+}
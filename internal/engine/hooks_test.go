@@ -0,0 +1,177 @@
+package engine
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// ambient records the ambient humidity set by tick hooks.
+type ambient struct {
+	humidity, decay float64
+}
+
+func (a *ambient) SetAmbient(humidity, decay float64) error {
+	if humidity < 0 || humidity > 1 {
+		return errors.New("ambient humidity must be between 0.0 and 1.0")
+	}
+	a.humidity, a.decay = humidity, decay
+	return nil
+}
+
+func TestTickHooks_RunInOrder(t *testing.T) {
+	s := newTestSimulator(t, 1)
+	var ran []string
+	for _, name := range []string{"first", "second", "third"} {
+		if err := s.AddTickHook(name, func(ctx TickContext) error {
+			ran = append(ran, name)
+			return nil
+		}); err != nil {
+			t.Fatalf("failed to add hook %s: %v", name, err)
+		}
+	}
+	s.AddTickListener(tickListenerFunc(func(int) { ran = append(ran, "listener") }))
+
+	s.Step()
+	expected := []string{"first", "second", "third", "listener"}
+	if !reflect.DeepEqual(ran, expected) {
+		t.Errorf("expected %v, got %v", expected, ran)
+	}
+}
+
+// tickListenerFunc adapts a function to a TickListener.
+type tickListenerFunc func(tick int)
+
+func (f tickListenerFunc) OnTick(tick int) { f(tick) }
+
+func TestTickHooks_Remove(t *testing.T) {
+	s := newTestSimulator(t, 1)
+	runs := map[string]int{}
+	for _, name := range []string{"kept", "removed"} {
+		if err := s.AddTickHook(name, func(TickContext) error {
+			runs[name]++
+			return nil
+		}); err != nil {
+			t.Fatalf("failed to add hook %s: %v", name, err)
+		}
+	}
+	s.Step()
+	if err := s.RemoveTickHook("removed"); err != nil {
+		t.Fatalf("failed to remove hook: %v", err)
+	}
+	s.Step()
+
+	if runs["kept"] != 2 || runs["removed"] != 1 {
+		t.Errorf("expected the removed hook to stop running, got %v", runs)
+	}
+	if err := s.RemoveTickHook("removed"); !errors.Is(err, ErrHookNotFound) {
+		t.Errorf("expected removing twice to fail with ErrHookNotFound, got %v", err)
+	}
+	if stats := s.TickHookStats(); len(stats) != 1 || stats[0].Name != "kept" {
+		t.Errorf("expected only the kept hook left, got %+v", stats)
+	}
+}
+
+func TestTickHooks_AddErrors(t *testing.T) {
+	s := newTestSimulator(t, 0)
+	noop := func(TickContext) error { return nil }
+	if err := s.AddTickHook("noop", noop); err != nil {
+		t.Fatalf("failed to add hook: %v", err)
+	}
+	tests := []struct {
+		name     string
+		hookName string
+		hook     TickHook
+		errorMsg string
+	}{
+		{"empty name", "", noop, "tick hook name cannot be empty"},
+		{"nil hook", "nil", nil, "tick hook cannot be nil: nil"},
+		{"taken name", "noop", noop, "tick hook already added: noop"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.AddTickHook(tt.hookName, tt.hook); err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestTickHooks_IsolateFailures(t *testing.T) {
+	s := newTestSimulator(t, 1)
+	after := 0
+	hooks := []struct {
+		name string
+		hook TickHook
+	}{
+		{"failing", func(TickContext) error { return errors.New("pests got away") }},
+		{"panicking", func(TickContext) error { panic("invariant broken") }},
+		{"after", func(TickContext) error { after++; return nil }},
+	}
+	for _, h := range hooks {
+		if err := s.AddTickHook(h.name, h.hook); err != nil {
+			t.Fatalf("failed to add hook %s: %v", h.name, err)
+		}
+	}
+	for range 3 {
+		s.Step()
+	}
+
+	if after != 3 || s.GetCurrentTick() != 3 {
+		t.Errorf("expected failing hooks not to stop the ticks or the next hook, got %d runs by tick %d", after, s.GetCurrentTick())
+	}
+	expected := []TickHookStats{
+		{Name: "failing", Runs: 3, Failed: 3, LastError: "pests got away"},
+		{Name: "panicking", Runs: 3, Failed: 3, LastError: "panic: invariant broken"},
+		{Name: "after", Runs: 3},
+	}
+	if got := s.TickHookStats(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected the stats %+v, got %+v", expected, got)
+	}
+}
+
+func TestTickContext(t *testing.T) {
+	s := newTestSimulator(t, 2)
+	env := &ambient{}
+	s.SetHookEnvironment(env)
+	var errs []error
+	if err := s.AddTickHook("gardener", func(ctx TickContext) error {
+		if ctx.Tick() != 0 {
+			t.Errorf("expected tick 0, got %d", ctx.Tick())
+		}
+		plants := ctx.Plants()
+		if got := plantIDs(plants); !reflect.DeepEqual(got, []string{"tomato-0", "tomato-1"}) {
+			t.Errorf("expected snapshots of both plants, got %v", got)
+		}
+		plants[0].SoilSaturation = 0
+		errs = append(errs,
+			ctx.WaterPlant("tomato-1", 0.2),
+			ctx.WaterPlant("cactus-1", 0.2),
+			ctx.WaterPlant("tomato-1", -0.2),
+			ctx.SetEnvironment(0.8, 0.2),
+			ctx.SetEnvironment(1.5, 0.2),
+		)
+		return nil
+	}); err != nil {
+		t.Fatalf("failed to add hook: %v", err)
+	}
+	s.Step()
+
+	if errs[0] != nil || !errors.Is(errs[1], ErrPlantNotFound) || errs[2] == nil || errs[3] != nil || errs[4] == nil {
+		t.Errorf("expected watering an unknown plant, a negative amount and an invalid humidity to fail, got %v", errs)
+	}
+	plants := s.GetAllPlants()
+	if plants[0].SoilSaturation == 0 {
+		t.Error("expected changing a snapshot to leave the plant alone")
+	}
+	if plants[1].SoilSaturation-plants[0].SoilSaturation < 0.2-1e-9 {
+		t.Errorf("expected tomato-1 watered 0.2 more than tomato-0, got %.2f and %.2f", plants[1].SoilSaturation, plants[0].SoilSaturation)
+	}
+	if *env != (ambient{humidity: 0.8, decay: 0.2}) {
+		t.Errorf("expected the ambient humidity set by the hook, got %+v", *env)
+	}
+
+	if err := NewTickContext(0, nil, nil, nil).SetEnvironment(0.5, 0.1); !errors.Is(err, ErrNoEnvironment) {
+		t.Errorf("expected ErrNoEnvironment without an environment, got %v", err)
+	}
+}
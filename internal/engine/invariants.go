@@ -0,0 +1,184 @@
+package engine
+
+import (
+	"fmt"
+	"greenhouse-simulator/internal/models"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+)
+
+// statsTolerance bounds how far the floating point running sums of a
+// section, see SectionStats, may drift from the sums of its plants.
+const statsTolerance = 1e-6
+
+// Option configures a simulator built by NewSimulator.
+type Option func(s *simulator)
+
+// WithInvariantChecks checks the state of every plant at the end of every
+// tick, once the tick listeners have run:
+// - Health, GrowthStage, SoilSaturation and DeepSaturation are between 0 and
+// 1, and neither NaN nor -0
+// - a plant is alive if and only if its health is above 0
+// - the growth stage of an alive plant does not go down, unless the plant
+// was pruned since the last tick
+// - the tick and the disease, germination and modifier tick counters are not
+// negative
+// - the running sums of every section match the sums of its plants, see
+// SectionStats, up to the rounding of the floating point sums
+//
+// The violations of a tick are handed to onViolation, on the tick goroutine,
+// or with a nil onViolation make the tick panic with an *InvariantError.
+// Simulators built without the option do not check anything.
+func WithInvariantChecks(onViolation func(violations []InvariantViolation)) Option {
+	return func(s *simulator) {
+		s.invariants = &invariants{onViolation: onViolation, growth: map[string]float64{}}
+	}
+}
+
+// InvariantViolation is a value found in an impossible state at the end of a
+// tick. PlantID is empty for the counters of the simulator itself.
+type InvariantViolation struct {
+	Tick    int
+	PlantID string
+	Field   string
+	Value   float64
+	Rule    string
+}
+
+func (v InvariantViolation) String() string {
+	target := "simulator"
+	if v.PlantID != "" {
+		target = "plant " + v.PlantID
+	}
+	return fmt.Sprintf("tick %d: %s: %s %v %s", v.Tick, target, v.Field, v.Value, v.Rule)
+}
+
+// InvariantError is the panic of a tick that broke invariants, see
+// WithInvariantChecks.
+type InvariantError struct {
+	Violations []InvariantViolation
+}
+
+func (e *InvariantError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		msgs[i] = violation.String()
+	}
+	return fmt.Sprintf("%d invariant violations: %s", len(e.Violations), strings.Join(msgs, "; "))
+}
+
+// invariants checks the plants of a simulator after every tick. growth holds
+// the growth stage of every alive plant at the last check.
+type invariants struct {
+	onViolation func(violations []InvariantViolation)
+	growth      map[string]float64
+}
+
+// check returns the violations of the plants in slots of store at the end of
+// tick. The caller must hold the simulator's lock.
+func (c *invariants) check(tick int, slots []int32, store *plantStore) []InvariantViolation {
+	var violations []InvariantViolation
+	violate := func(plantID, field string, value float64, rule string) {
+		violations = append(violations, InvariantViolation{Tick: tick, PlantID: plantID, Field: field, Value: value, Rule: rule})
+	}
+	if tick < 0 {
+		violate("", "Tick", float64(tick), "cannot be negative")
+	}
+	for _, slot := range slots {
+		plant := store.at(slot)
+		for _, field := range []struct {
+			name  string
+			value float64
+		}{
+			{"Health", plant.Health},
+			{"GrowthStage", plant.GrowthStage},
+			{"SoilSaturation", plant.SoilSaturation},
+			{"DeepSaturation", plant.DeepSaturation},
+		} {
+			if !(field.value >= 0 && field.value <= 1) || math.Signbit(field.value) {
+				violate(plant.ID, field.name, field.value, "must be between 0 and 1")
+			}
+		}
+		if plant.Alive != (plant.Health > 0) {
+			violate(plant.ID, "Health", plant.Health, fmt.Sprintf("must be above 0 exactly when the plant is alive, alive is %v", plant.Alive))
+		}
+		if previous, ok := c.growth[plant.ID]; ok && plant.GrowthStage < previous {
+			violate(plant.ID, "GrowthStage", plant.GrowthStage, fmt.Sprintf("cannot go down from %v without pruning", previous))
+		}
+		if plant.Alive {
+			c.growth[plant.ID] = plant.GrowthStage
+		} else {
+			delete(c.growth, plant.ID)
+		}
+
+		if plant.Disease.Ticks < 0 {
+			violate(plant.ID, "Disease.Ticks", float64(plant.Disease.Ticks), "cannot be negative")
+		}
+		if g := plant.Germination; g != nil && (g.Ticks < 0 || g.Favorable < 0) {
+			violate(plant.ID, "Germination.Ticks", float64(min(g.Ticks, g.Favorable)), "cannot be negative")
+		}
+		for _, modifier := range plant.Modifiers {
+			if modifier.Ticks < 0 {
+				violate(plant.ID, "Modifiers.Ticks", float64(modifier.Ticks), "cannot be negative")
+			}
+		}
+	}
+	return violations
+}
+
+// checkStats returns the violations of the running sums of the sections at
+// the end of tick, against their sums recomputed from the plants. The
+// sections are reported in order, as simulator counters.
+func (c *invariants) checkStats(tick int, running, recomputed map[string]PlantStats) []InvariantViolation {
+	var violations []InvariantViolation
+	sections := slices.AppendSeq(slices.Collect(maps.Keys(running)), maps.Keys(recomputed))
+	slices.Sort(sections)
+	sections = slices.Compact(sections)
+	for _, sectionID := range sections {
+		got, want := running[sectionID], recomputed[sectionID]
+		type field struct {
+			name      string
+			got, want float64
+		}
+		fields := []field{
+			{"Plants", float64(got.Plants), float64(want.Plants)},
+			{"AlivePlants", float64(got.AlivePlants), float64(want.AlivePlants)},
+			{"Health", got.Health, want.Health},
+			{"Saturation", got.Saturation, want.Saturation},
+			{"Growth", got.Growth, want.Growth},
+		}
+		for i, factor := range models.HealthFactors {
+			fields = append(fields, field{fmt.Sprintf("LimitingFactors[%s]", factor), float64(got.LimitingFactors[i]), float64(want.LimitingFactors[i])})
+		}
+		for _, field := range fields {
+			if !(math.Abs(field.got-field.want) <= statsTolerance) {
+				violations = append(violations, InvariantViolation{
+					Tick:  tick,
+					Field: fmt.Sprintf("SectionStats[%s].%s", sectionID, field.name),
+					Value: field.got,
+					Rule:  fmt.Sprintf("must match the %v summed from the plants", field.want),
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// forget drops the growth stage recorded for a plant, which may go down
+// before the next check: it was pruned, or removed and maybe added back.
+func (c *invariants) forget(plantID string) {
+	delete(c.growth, plantID)
+}
+
+// report hands the violations to onViolation, or panics with them.
+func (c *invariants) report(violations []InvariantViolation) {
+	if len(violations) == 0 {
+		return
+	}
+	if c.onViolation == nil {
+		panic(&InvariantError{Violations: violations})
+	}
+	c.onViolation(violations)
+}
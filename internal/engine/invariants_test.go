@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInvariantChecks(t *testing.T) {
+	tests := []struct {
+		name     string
+		corrupt  func(s Simulator)
+		expected []InvariantViolation
+	}{
+		{"healthy", func(Simulator) {}, nil},
+		{
+			"health above 1",
+			func(s Simulator) { s.GetAllPlants()[0].Health = 1.0000002 },
+			[]InvariantViolation{{Tick: 1, PlantID: "tomato-0", Field: "Health", Value: 1.0000002, Rule: "must be between 0 and 1"}},
+		},
+		{
+			"negative zero saturation",
+			func(s Simulator) { s.GetAllPlants()[1].SoilSaturation = math.Copysign(0, -1) },
+			[]InvariantViolation{{Tick: 1, PlantID: "tomato-1", Field: "SoilSaturation", Value: math.Copysign(0, -1), Rule: "must be between 0 and 1"}},
+		},
+		{
+			"alive without health",
+			func(s Simulator) { s.GetAllPlants()[0].Health = 0 },
+			[]InvariantViolation{{Tick: 1, PlantID: "tomato-0", Field: "Health", Value: 0, Rule: "must be above 0 exactly when the plant is alive, alive is true"}},
+		},
+		{
+			"growth going down",
+			func(s Simulator) { s.GetAllPlants()[1].GrowthStage = 0 },
+			[]InvariantViolation{{Tick: 1, PlantID: "tomato-1", Field: "GrowthStage", Value: 0, Rule: "cannot go down from 0.0125 without pruning"}},
+		},
+		{
+			"growth going down after pruning",
+			func(s Simulator) {
+				if err := s.PrunePlant("tomato-1", 0.5); err != nil {
+					t.Fatalf("failed to prune: %v", err)
+				}
+			},
+			nil,
+		},
+		{
+			"negative counter",
+			func(s Simulator) { s.GetAllPlants()[0].Disease.Ticks = -1 },
+			[]InvariantViolation{{Tick: 1, PlantID: "tomato-0", Field: "Disease.Ticks", Value: -1, Rule: "cannot be negative"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reported []InvariantViolation
+			s := NewSimulator(time.Hour, WithInvariantChecks(func(violations []InvariantViolation) {
+				reported = append(reported, violations...)
+			}))
+			for _, id := range []string{"tomato-0", "tomato-1"} {
+				if err := s.AddPlant(testPlant(t, id)); err != nil {
+					t.Fatalf("failed to add plant: %v", err)
+				}
+			}
+			s.Step()
+			// The listener corrupts the plants on the second tick, after
+			// their update, as a buggy growth model would.
+			s.AddTickListener(tickListenerFunc(func(int) { tt.corrupt(s) }))
+			s.Step()
+
+			if !reflect.DeepEqual(reported, tt.expected) {
+				t.Errorf("expected the violations %v, got %v", tt.expected, reported)
+			}
+		})
+	}
+}
+
+func TestInvariantChecks_Panic(t *testing.T) {
+	s := NewSimulator(time.Hour, WithInvariantChecks(nil))
+	if err := s.AddPlant(testPlant(t, "tomato-0")); err != nil {
+		t.Fatalf("failed to add plant: %v", err)
+	}
+	s.AddTickListener(tickListenerFunc(func(int) { s.GetAllPlants()[0].GrowthStage = 2 }))
+
+	defer func() {
+		err, _ := recover().(error)
+		var invariantErr *InvariantError
+		if !errors.As(err, &invariantErr) || err.Error() != "1 invariant violations: tick 0: plant tomato-0: GrowthStage 2 must be between 0 and 1" {
+			t.Errorf("expected the tick to panic with an InvariantError, got %v", err)
+		}
+	}()
+	s.Step()
+	t.Error("expected the tick to panic")
+}
+
+func TestInvariantChecks_OffByDefault(t *testing.T) {
+	s := newTestSimulator(t, 1)
+	s.AddTickListener(tickListenerFunc(func(int) { s.GetAllPlants()[0].Health = 2 }))
+	s.Step()
+	if allocs := testing.AllocsPerRun(100, newTestSimulator(t, 10).Step); allocs != 0 {
+		t.Errorf("expected a tick without checks not to allocate, got %.0f allocations", allocs)
+	}
+}
@@ -0,0 +1,152 @@
+package engine
+
+import (
+	"errors"
+	"log"
+	"slices"
+	"sync"
+	"time"
+)
+
+// OverrunPolicy is what a running simulator does about the ticks that came
+// due while a tick took longer than the tick interval.
+type OverrunPolicy string
+
+const (
+	// OverrunSkip drops the ticks that came due, logging how many, so that
+	// the next tick runs on schedule. It is the default.
+	OverrunSkip OverrunPolicy = "skip"
+	// OverrunCatchup runs the ticks that came due back to back, until the
+	// simulation is on schedule again or stops running.
+	OverrunCatchup OverrunPolicy = "catchup"
+	// OverrunStretch drops nothing and starts the tick interval over once
+	// the tick is done, so that the simulation slows down instead.
+	OverrunStretch OverrunPolicy = "stretch"
+)
+
+// tickWindow is the number of ticks TickTiming.P95 is worked out over.
+const tickWindow = 128
+
+// TickTiming is how long the ticks run by Start take. Last is the duration
+// of the last tick and P95 the 95th percentile of the last 128. Overruns
+// counts the ticks that took longer than the tick interval, and Dropped the
+// ticks OverrunSkip dropped.
+type TickTiming struct {
+	Last     time.Duration
+	P95      time.Duration
+	Overruns int
+	Dropped  int
+}
+
+// Pacer times the ticks of a simulator's loop and applies its overrun policy.
+type Pacer struct {
+	policy    OverrunPolicy
+	durations [tickWindow]time.Duration
+	ticks     int
+	timing    TickTiming
+	mu        sync.Mutex
+}
+
+// NewPacer returns a pacer with the OverrunSkip policy.
+func NewPacer() *Pacer {
+	return &Pacer{policy: OverrunSkip}
+}
+
+// SetOverrunPolicy sets the overrun policy from the next tick on.
+// Returns an error if the policy is unknown.
+// This method is safe for concurrent use.
+func (p *Pacer) SetOverrunPolicy(policy OverrunPolicy) error {
+	if policy != OverrunSkip && policy != OverrunCatchup && policy != OverrunStretch {
+		return errors.New("overrun policy must be skip, catchup or stretch: " + string(policy))
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.policy = policy
+	return nil
+}
+
+// OverrunPolicy returns the overrun policy.
+// This method is safe for concurrent use.
+func (p *Pacer) OverrunPolicy() OverrunPolicy {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.policy
+}
+
+// TickTiming returns the timing of the ticks run so far.
+// This method is safe for concurrent use.
+func (p *Pacer) TickTiming() TickTiming {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	timing := p.timing
+	if n := min(p.ticks, tickWindow); n > 0 {
+		durations := slices.Clone(p.durations[:n])
+		slices.Sort(durations)
+		timing.P95 = durations[(n*95+99)/100-1]
+	}
+	return timing
+}
+
+// resetTiming forgets the ticks timed so far.
+func (p *Pacer) resetTiming() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.durations = [tickWindow]time.Duration{}
+	p.ticks = 0
+	p.timing = TickTiming{}
+}
+
+// Pace runs step for a tick of ticker, which ticks every interval, and
+// applies the overrun policy if the tick took longer than interval. The
+// catch up stops as soon as state is no longer Running, so that Pause and
+// Stop are served.
+func (p *Pacer) Pace(step func(), ticker *time.Ticker, interval time.Duration, state interface{ State() State }) {
+	missed := p.run(step, interval)
+	if missed == 0 {
+		return
+	}
+	switch p.OverrunPolicy() {
+	case OverrunSkip:
+		drain(ticker)
+		p.mu.Lock()
+		p.timing.Dropped += missed
+		p.mu.Unlock()
+		log.Printf("Tick took longer than the tick interval, dropped %d ticks", missed)
+	case OverrunCatchup:
+		drain(ticker)
+		for ; missed > 0 && state.State() == Running; missed-- {
+			missed += p.run(step, interval)
+		}
+		drain(ticker)
+	case OverrunStretch:
+		drain(ticker)
+		ticker.Reset(interval)
+	}
+}
+
+// run runs and times step, and returns the number of ticks of interval that
+// came due while it ran.
+func (p *Pacer) run(step func(), interval time.Duration) int {
+	start := time.Now()
+	step()
+	duration := time.Since(start)
+	missed := int(duration / interval)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.durations[p.ticks%tickWindow] = duration
+	p.ticks++
+	p.timing.Last = duration
+	if missed > 0 {
+		p.timing.Overruns++
+	}
+	return missed
+}
+
+// drain drops the tick the ticker holds, if any.
+func drain(ticker *time.Ticker) {
+	select {
+	case <-ticker.C:
+	default:
+	}
+}
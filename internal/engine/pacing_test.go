@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTickTiming_P95(t *testing.T) {
+	tests := []struct {
+		name     string
+		ticks    int
+		expected time.Duration
+	}{
+		{"none", 0, 0},
+		{"one", 1, time.Millisecond},
+		{"twenty", 20, 19 * time.Millisecond},
+		{"hundred", 100, 95 * time.Millisecond},
+		{"past the window", 228, 222 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPacer()
+			// The ticks take 1ms, 2ms and so on; the window keeps the last 128.
+			for i := range tt.ticks {
+				p.durations[i%tickWindow] = time.Duration(i+1) * time.Millisecond
+				p.ticks++
+			}
+			if got := p.TickTiming().P95; got != tt.expected {
+				t.Errorf("expected p95 %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSetOverrunPolicy(t *testing.T) {
+	s := newTestSimulator(t, 0)
+	if err := s.SetOverrunPolicy("rewind"); err == nil || err.Error() != "overrun policy must be skip, catchup or stretch: rewind" {
+		t.Errorf("expected an unknown policy to be refused, got %v", err)
+	}
+	if err := s.SetOverrunPolicy(OverrunStretch); err != nil {
+		t.Errorf("expected the stretch policy to be accepted, got %v", err)
+	}
+}
+
+func TestOverrunPolicies(t *testing.T) {
+	const interval = 10 * time.Millisecond
+	tests := []struct {
+		policy OverrunPolicy
+		// check is handed the ticks that came due while the simulator ran
+		// and the ticks it ran.
+		check func(t *testing.T, due, ran int, timing TickTiming)
+	}{
+		{OverrunCatchup, func(t *testing.T, due, ran int, timing TickTiming) {
+			if ran < due-4 {
+				t.Errorf("expected the missed ticks caught up, got %d of %d due", ran, due)
+			}
+			if timing.Dropped != 0 {
+				t.Errorf("expected no dropped tick, got %d", timing.Dropped)
+			}
+		}},
+		{OverrunSkip, func(t *testing.T, due, ran int, timing TickTiming) {
+			if ran > due-6 {
+				t.Errorf("expected the missed ticks dropped, got %d of %d due", ran, due)
+			}
+			if timing.Dropped < 8 {
+				t.Errorf("expected the ticks missed by the slow tick counted as dropped, got %d", timing.Dropped)
+			}
+		}},
+		{OverrunStretch, func(t *testing.T, due, ran int, timing TickTiming) {
+			if ran > due-6 {
+				t.Errorf("expected the simulation slowed down, got %d of %d due", ran, due)
+			}
+			if timing.Dropped != 0 {
+				t.Errorf("expected no dropped tick, got %d", timing.Dropped)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			s := NewSimulator(interval)
+			if err := s.SetOverrunPolicy(tt.policy); err != nil {
+				t.Fatalf("failed to set the overrun policy: %v", err)
+			}
+			if err := s.AddTickHook("slow", func(ctx TickContext) error {
+				if ctx.Tick() == 0 {
+					time.Sleep(10 * interval)
+				}
+				return nil
+			}); err != nil {
+				t.Fatalf("failed to add hook: %v", err)
+			}
+
+			start := time.Now()
+			go s.Start()
+			time.Sleep(25 * interval)
+			if err := s.Stop(); err != nil {
+				t.Fatalf("failed to stop: %v", err)
+			}
+			due := int(time.Since(start) / interval)
+
+			timing := s.TickTiming()
+			if timing.Overruns < 1 || timing.P95 <= 0 {
+				t.Errorf("expected the slow tick timed as an overrun, got %+v", timing)
+			}
+			if s.Status().Timing != timing {
+				t.Errorf("expected the status to carry the timing %+v, got %+v", timing, s.Status().Timing)
+			}
+			tt.check(t, due, s.GetCurrentTick(), timing)
+		})
+	}
+}
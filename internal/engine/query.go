@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"greenhouse-simulator/internal/models"
+	"slices"
+	"strings"
+)
+
+// PlantQuery selects plants for QueryPlants. A plant must match every
+// criterion that is set: its SectionID, whether it is Alive, the plant type
+// and tag of Filter, and a health and growth stage within the ranges, bounds
+// included. The plants are ordered by ID; a page holds the first Limit plants,
+// all of them when Limit is zero, whose ID comes after After, the Next of the
+// previous page.
+type PlantQuery struct {
+	SectionID string
+	Alive     *bool
+	Filter    models.PlantFilter
+	MinHealth *float64
+	MaxHealth *float64
+	MinGrowth *float64
+	MaxGrowth *float64
+	After     string
+	Limit     int
+}
+
+// Matches reports whether a plant matches every criterion of the query.
+func (q PlantQuery) Matches(p *models.Plant) bool {
+	switch {
+	case q.SectionID != "" && p.SectionID != q.SectionID,
+		q.Alive != nil && p.Alive != *q.Alive,
+		q.MinHealth != nil && p.Health < *q.MinHealth,
+		q.MaxHealth != nil && p.Health > *q.MaxHealth,
+		q.MinGrowth != nil && p.GrowthStage < *q.MinGrowth,
+		q.MaxGrowth != nil && p.GrowthStage > *q.MaxGrowth:
+		return false
+	}
+	return q.Filter.Matches(p)
+}
+
+// PlantPage is a page of plants, see Simulator.QueryPlants. Total is the
+// number of plants matching the query on every page, and Next the ID to
+// query the next page after, empty on the last page.
+type PlantPage struct {
+	Plants []*models.Plant
+	Total  int
+	Next   string
+}
+
+// QueryPlants returns a page of snapshots of the plants matching query,
+// ordered by ID. Plants are matched where they are and only those of the
+// page are copied. Pages are cut by ID rather than position, so that the
+// plants added or removed between two queries do not shift the plants of
+// the next page; each page is a snapshot of the tick it was queried on.
+// This method is safe for concurrent use.
+func (s *simulator) QueryPlants(query PlantQuery) PlantPage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	slots := s.plants
+	if query.SectionID != "" {
+		slots = s.plantsBySectionID[query.SectionID]
+	}
+	var page PlantPage
+	var matching []*models.Plant
+	for _, slot := range slots {
+		plant := s.store.at(slot)
+		if !query.Matches(plant) {
+			continue
+		}
+		page.Total++
+		if plant.ID > query.After {
+			matching = append(matching, plant)
+		}
+	}
+	slices.SortFunc(matching, func(a, b *models.Plant) int { return strings.Compare(a.ID, b.ID) })
+	if query.Limit > 0 && len(matching) > query.Limit {
+		matching = matching[:query.Limit]
+		page.Next = matching[len(matching)-1].ID
+	}
+	page.Plants = make([]*models.Plant, len(matching))
+	for i, plant := range matching {
+		page.Plants[i] = plant.Clone()
+	}
+	return page
+}
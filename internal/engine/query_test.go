@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"greenhouse-simulator/internal/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestQueryPlants_Filters(t *testing.T) {
+	s := NewSimulator(time.Millisecond)
+	for i, id := range []string{"tomato-3", "tomato-1", "tomato-2", "tomato-0"} {
+		plant := testPlant(t, id)
+		plant.Health = 0.25 * float64(i+1)
+		plant.GrowthStage = 0.1 * float64(i)
+		if i%2 == 0 {
+			plant.SectionID = "section-B"
+			plant.Tags = []string{"north"}
+		}
+		if id == "tomato-0" {
+			plant.Alive = false
+		}
+		if err := s.AddPlant(plant); err != nil {
+			t.Fatalf("failed to add plant: %v", err)
+		}
+	}
+	alive, dead := true, false
+	half, tenth := 0.5, 0.1
+
+	tests := []struct {
+		name     string
+		query    PlantQuery
+		expected []string
+		total    int
+	}{
+		{"everything", PlantQuery{}, []string{"tomato-0", "tomato-1", "tomato-2", "tomato-3"}, 4},
+		{"section", PlantQuery{SectionID: "section-B"}, []string{"tomato-2", "tomato-3"}, 2},
+		{"alive", PlantQuery{Alive: &alive}, []string{"tomato-1", "tomato-2", "tomato-3"}, 3},
+		{"dead", PlantQuery{Alive: &dead}, []string{"tomato-0"}, 1},
+		{"tag", PlantQuery{Filter: models.PlantFilter{Tag: "north"}}, []string{"tomato-2", "tomato-3"}, 2},
+		{"type", PlantQuery{Filter: models.PlantFilter{Type: "Tomato"}}, []string{"tomato-0", "tomato-1", "tomato-2", "tomato-3"}, 4},
+		{"health range", PlantQuery{MinHealth: &half, MaxHealth: &half}, []string{"tomato-1"}, 1},
+		{"growth range", PlantQuery{MinGrowth: &tenth}, []string{"tomato-0", "tomato-1", "tomato-2"}, 3},
+		{"combined", PlantQuery{SectionID: "section-B", Alive: &alive, MinHealth: &half}, []string{"tomato-2"}, 1},
+		{"limit", PlantQuery{Alive: &alive, Limit: 2}, []string{"tomato-1", "tomato-2"}, 3},
+		{"no match", PlantQuery{SectionID: "section-B", Alive: &dead}, []string{}, 0},
+		{"unknown section", PlantQuery{SectionID: "section-C"}, []string{}, 0},
+		{"unknown type", PlantQuery{Filter: models.PlantFilter{Type: "Basil"}}, []string{}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := s.QueryPlants(tt.query)
+			if got := plantIDs(page.Plants); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected plants %v, got %v", tt.expected, got)
+			}
+			if page.Total != tt.total {
+				t.Errorf("expected a total of %d, got %d", tt.total, page.Total)
+			}
+		})
+	}
+}
+
+func TestQueryPlants_Pages(t *testing.T) {
+	s := newTestSimulator(t, 7)
+	var got []string
+	query := PlantQuery{Limit: 3}
+	for range 5 {
+		page := s.QueryPlants(query)
+		if page.Total != 7 {
+			t.Errorf("expected a total of 7, got %d", page.Total)
+		}
+		got = append(got, plantIDs(page.Plants)...)
+		if page.Next == "" {
+			break
+		}
+		query.After = page.Next
+	}
+	expected := []string{"tomato-0", "tomato-1", "tomato-2", "tomato-3", "tomato-4", "tomato-5", "tomato-6"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected pages of %v, got %v", expected, got)
+	}
+}
+
+func TestQueryPlants_PagesAcrossTicks(t *testing.T) {
+	s := newTestSimulator(t, 6)
+	first := s.QueryPlants(PlantQuery{Limit: 3})
+	if got := plantIDs(first.Plants); !reflect.DeepEqual(got, []string{"tomato-0", "tomato-1", "tomato-2"}) {
+		t.Fatalf("unexpected first page %v", got)
+	}
+
+	// Plants removed before the cursor, or added after it, between two pages
+	// neither repeat nor skip the plants of the next page.
+	s.Step()
+	for _, id := range []string{"tomato-0", "tomato-4"} {
+		if err := s.RemovePlant(id); err != nil {
+			t.Fatalf("failed to remove plant: %v", err)
+		}
+	}
+	if err := s.AddPlant(testPlant(t, "tomato-9")); err != nil {
+		t.Fatalf("failed to add plant: %v", err)
+	}
+	second := s.QueryPlants(PlantQuery{After: first.Next, Limit: 3})
+	if got := plantIDs(second.Plants); !reflect.DeepEqual(got, []string{"tomato-3", "tomato-5", "tomato-9"}) {
+		t.Errorf("expected the second page to resume after %s, got %v", first.Next, got)
+	}
+	if second.Total != 5 || second.Next != "" {
+		t.Errorf("expected the last page of 5 plants, got a total of %d and next %q", second.Total, second.Next)
+	}
+
+	// The second page reflects the tick it was queried on.
+	plant, err := s.GetPlant("tomato-3")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if second.Plants[0].SoilSaturation != plant.SoilSaturation || plant.SoilSaturation == 0.6 {
+		t.Errorf("expected the second page to hold the plants as of tick %d", s.GetCurrentTick())
+	}
+
+	// The pages are snapshots.
+	second.Plants[0].Health = 0
+	if plant, _ := s.GetPlant("tomato-3"); plant.Health == 0 {
+		t.Error("expected the page to hold copies of the plants")
+	}
+}
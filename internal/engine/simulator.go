@@ -1,169 +1,799 @@
 package engine
 
 import (
+	"cmp"
+	"context"
 	"errors"
+	"fmt"
 	"greenhouse-simulator/internal/models"
 	"log"
+	"log/slog"
 	"maps"
 	"slices"
+	"strings"
 	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	// ErrPlantNotFound is returned for a plant ID the simulator does not know.
+	ErrPlantNotFound = errors.New("no plant found for the provided ID")
+	// ErrPlantExists is returned when adding a plant whose ID is taken.
+	ErrPlantExists = errors.New("plant with ID already added to simulator")
+	// ErrNilPlant is returned when adding a nil plant.
+	ErrNilPlant = errors.New("plant cannot be nil")
 )
 
+// PlantError is why a plant of a batch passed to AddPlants was refused.
+type PlantError struct {
+	// Index is the position of the plant in the batch.
+	Index int
+	// PlantID is empty for a nil plant.
+	PlantID string
+	Err     error
+}
+
+func (e *PlantError) Error() string {
+	return fmt.Sprintf("plant %d: %v", e.Index, e.Err)
+}
+
+func (e *PlantError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError lists every plant AddPlants refused, in batch order. It
+// unwraps to their errors, so errors.Is reports whether any plant was
+// refused for a given reason.
+type BatchError struct {
+	Plants []*PlantError
+}
+
+func (e *BatchError) Error() string {
+	messages := make([]string, len(e.Plants))
+	for i, plant := range e.Plants {
+		messages[i] = plant.Error()
+	}
+	return fmt.Sprintf("%d invalid plants: %s", len(e.Plants), strings.Join(messages, "; "))
+}
+
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Plants))
+	for i, plant := range e.Plants {
+		errs[i] = plant
+	}
+	return errs
+}
+
+// Status is a summary of a simulator taken at one point in time. Tick is the
+// next tick to run, UptimeTicks the number of ticks run so far and Paused
+// whether State is Paused.
+type Status struct {
+	State        State
+	Paused       bool
+	Tick         int
+	UptimeTicks  int
+	TickInterval time.Duration
+	Plants       int
+	AlivePlants  int
+	Timing       TickTiming
+}
+
+// SectionActivity tells when the plants of a section last changed, so that
+// readers of the section, such as its sensors, can tell whether what they
+// worked out from its plants still holds. Ticks count as GetCurrentTick
+// counts them: a change made while it returns n is recorded as n, so that
+// what was read from the section while it returned r still holds as long as
+// ChangedAt is before r.
+//
+// The simulator records the changes it makes: running a tick with a living
+// plant in the section, adding, removing, moving and pruning plants, and
+// watering them from tick hooks. It cannot see changes made to plants through
+// the pointers GetAllPlants and GetPlantsBySectionID return, but ticks change
+// every living plant anyway, so only changes to dead plants go unseen.
+type SectionActivity struct {
+	ChangedAt int
+	// Dead is true when no plant of the section was alive on the last tick,
+	// and none was added or moved in since.
+	Dead bool
+}
+
 // Simulator defines the interface for controlling a greenhouse simulation.
 // It provides methods to start, pause, resume, and stop the simulation,
-// as well as manage plants within the greenhouse.
+// moving it through the states of a Lifecycle, as well as manage plants
+// within the greenhouse.
+//
+// IsPaused, GetTickInterval and Status are part of the interface so that
+// holders of a Simulator can tell what it is doing without knowing its
+// implementation; implementations outside this package must provide them.
+//
+// AddPlant and AddPlants copy the plants they are given into the simulator:
+// the ticks change the copies, never the plants the caller holds, which stay
+// as they were added. The live plants are those GetAllPlants and
+// GetPlantsBySectionID return. The copies of plants of equal types share one
+// PlantType, which must not be changed.
 type Simulator interface {
-	Start()
-	Pause()
-	Resume()
-	Stop()
+	Start() error
+	Pause() error
+	Resume() error
+	Stop() error
+	State() State
+	IsPaused() bool
+	Status() Status
+	AddStateListener(l StateListener)
 	AddPlant(p *models.Plant) error
+	AddPlants(plants []*models.Plant) error
+	RemovePlant(plantID string) error
+	TransplantPlant(plantID, sectionID string) error
+	PrunePlant(plantID string, fraction float64) error
+	SetPlantFlags(plantID string, flags models.PlantFlags) error
+	SetPlantTags(plantID string, tags []string) error
+	UpdatePlants(fn func())
+	SetPruneEffect(effect models.PruneEffect) error
+	ThinSection(sectionID string, keepN int) ([]string, error)
 	GetAllPlants() []*models.Plant
+	GetPlant(plantID string) (*models.Plant, error)
+	GetPlantDiagnosis(plantID string) (models.HealthDiagnosis, error)
+	GetPlantsByIDs(plantIDs []string) ([]*models.Plant, []string)
 	GetPlantsBySectionID(sectionID string) []*models.Plant
+	QueryPlants(query PlantQuery) PlantPage
+	SectionStats(sectionID string) (PlantStats, bool)
+	PlantStats() PlantStats
+	ListSectionIDs() []string
+	SectionActivity(sectionID string) (SectionActivity, bool)
 	GetCurrentTick() int
+	GetTickInterval() time.Duration
+	SetSpeed(speed float64) error
+	GetSpeed() float64
+	SetOverrunPolicy(policy OverrunPolicy) error
+	TickTiming() TickTiming
+	Step()
+	BetweenTicks(fn func())
+	AddTickListener(l TickListener)
+	SetTracer(tracer trace.Tracer)
+	TickContext() context.Context
+	AddTickHook(name string, hook TickHook) error
+	RemoveTickHook(name string) error
+	TickHookStats() []TickHookStats
+	SetHookEnvironment(env Environment)
+	Reset(keepConfig bool) error
+}
+
+// TickListener is notified after every simulation tick, once all plants
+// have been updated. Listeners run on the simulation goroutine, without the
+// lock of the plants, and change plants through UpdatePlants.
+type TickListener interface {
+	OnTick(tick int)
 }
 
+// simulator keeps its plants by value in a plantStore, and lists their slots
+// in the order they were added, besides indexing them by ID and section, so
+// that every run of the same greenhouse updates and lists them in the same
+// order. It keeps running sums of the plants of every section besides, see
+// SectionStats.
 type simulator struct {
+	*Lifecycle
+	*TickHooks
+	*Pacer
+	*TickBarrier
 	ticker            *time.Ticker
-	pause             chan struct{}
-	resume            chan struct{}
-	stop              chan struct{}
 	tickInterval      time.Duration
+	speed             float64
 	currentTick       int
-	isPaused          bool
 	mu                sync.RWMutex
-	plantsById        map[string]*models.Plant
-	plantsBySectionID map[string][]*models.Plant
+	store             plantStore
+	types             models.PlantTypes
+	plants            []int32 // slots in the order the plants were added
+	plantsById        map[string]int32
+	plantsBySectionID map[string][]int32
+	sections          map[string]sectionTicks
+	sectionStats      map[string]PlantStats
+	shares            []plantShare // by slot
+	tickListeners     []TickListener
+	pruneEffect       models.PruneEffect
+	tracer            trace.Tracer
+	tickCtx           context.Context
+	invariants        *invariants // nil without WithInvariantChecks
+	// initial holds copies of the plants as the first tick found them, in
+	// order, for Reset; nil until the first tick.
+	initial []*models.Plant
+	// dirtyMu guards the sections whose plants were handed out since the end
+	// of the last tick, see SectionStats, which readers holding mu for
+	// reading record.
+	dirtyMu  sync.Mutex
+	dirty    map[string]bool
+	allDirty bool
 }
 
 // NewSimulator creates a new simulator instance with the specified tick interval.
 // The tick interval determines how frequently the simulation updates.
-func NewSimulator(tickInterval time.Duration) Simulator {
-	return &simulator{
+func NewSimulator(tickInterval time.Duration, opts ...Option) Simulator {
+	s := &simulator{
+		Lifecycle:         NewLifecycle(),
+		TickHooks:         NewTickHooks(),
+		Pacer:             NewPacer(),
+		TickBarrier:       NewTickBarrier(),
 		ticker:            time.NewTicker(tickInterval),
-		pause:             make(chan struct{}),
-		resume:            make(chan struct{}),
-		stop:              make(chan struct{}),
 		tickInterval:      tickInterval,
+		speed:             1,
 		currentTick:       0,
-		isPaused:          false,
-		plantsById:        map[string]*models.Plant{},
-		plantsBySectionID: map[string][]*models.Plant{},
+		plantsById:        map[string]int32{},
+		plantsBySectionID: map[string][]int32{},
+		sections:          map[string]sectionTicks{},
+		sectionStats:      map[string]PlantStats{},
+		pruneEffect:       models.DefaultPruneEffect,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // Start begins the simulation loop and runs until Stop is called.
 // The simulation will process ticks at the configured interval,
-// updating all plants and handling pause/resume/stop signals.
-func (s *simulator) Start() {
-	log.Println("Starting...")
-	for {
-		select {
-		case <-s.ticker.C:
-			log.Print("\n---------------------------------------------------------------------------\n")
-			log.Printf("Tick %d\n", s.currentTick)
-			s.mu.RLock()
-			plantSlice := slices.Collect(maps.Values(s.plantsById))
-			for _, plant := range plantSlice {
-				plant.OnTick()
-				log.Println(plant)
-			}
-			s.mu.RUnlock()
-
-			s.currentTick++
-		case <-s.pause:
-			log.Println("Pausing...")
-			<-s.resume
-			s.mu.Lock()
-			s.isPaused = false
-			s.mu.Unlock()
-			log.Println("Resumed!")
-		case <-s.stop:
-			log.Println("Stopping...")
-			return
-		}
-	}
+// updating all plants and handling pause/resume/stop signals. Ticks are
+// timed, and ticks that take longer than the interval are handled by the
+// overrun policy, see Pacer.
+// Returns an error if the simulator was started or stopped before, see
+// Lifecycle.Run.
+func (s *simulator) Start() error {
+	return s.Run(s.ticker.C, func() { s.Pace(s.Step, s.ticker, s.period(), s.Lifecycle) })
 }
 
-// Pause temporarily halts the simulation.
-// If the simulation is already paused, this method does nothing.
-// The simulation can be resumed using the Resume method.
-func (s *simulator) Pause() {
+// period returns the wall-clock interval between ticks at the current speed.
+func (s *simulator) period() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return max(time.Duration(float64(s.tickInterval)/s.speed), time.Nanosecond)
+}
+
+// Step advances the simulation by exactly one tick: every plant is updated,
+// the tick counter is incremented, the tick hooks run, see TickHooks, and then
+// the registered tick listeners are notified with the number of the tick that
+// was just processed. The change of every plant is added to the sums of its
+// section as it updates, and the changes made by the listeners are folded in
+// once they have run, see SectionStats. With WithInvariantChecks, the plants
+// and the sums are checked last.
+// With a tracer set, the tick is traced as described by TickTrace. The state
+// of every plant is logged only when the default slog logger is enabled at
+// the debug level, see slog.SetLogLoggerLevel, so that large greenhouses do
+// not pay for formatting it. The first tick keeps a copy of the plants for
+// Reset. The whole tick holds the TickBarrier.
+// Start calls Step on every ticker event; tests and headless runs may call it directly.
+func (s *simulator) Step() {
+	defer s.HoldTick()()
 	s.mu.Lock()
-	if s.isPaused {
-		s.mu.Unlock()
-		log.Println("Already paused, ignoring")
-		return
+	tick := s.currentTick
+	if s.initial == nil {
+		s.initial = make([]*models.Plant, len(s.plants))
+		for i, slot := range s.plants {
+			s.initial[i] = s.store.at(slot).Clone()
+		}
+	}
+	tickTrace := StartTick(s.tracer, tick)
+	endUpdate := tickTrace.Phase(PlantUpdatePhase)
+	logPlants := slog.Default().Enabled(context.Background(), slog.LevelDebug)
+	if logPlants {
+		log.Print("\n---------------------------------------------------------------------------\n")
+		log.Printf("Tick %d\n", tick)
+	}
+	for sectionID, slots := range s.plantsBySectionID {
+		for _, slot := range slots {
+			if s.store.at(slot).Alive {
+				s.sections[sectionID] = sectionTicks{changedAt: tick + 1, livingAt: tick + 1}
+				break
+			}
+		}
+	}
+	for _, slot := range s.plants {
+		plant := s.store.at(slot)
+		plant.OnTick()
+		s.account(slot)
+		if logPlants {
+			log.Println(plant)
+		}
+	}
+	plants := len(s.plants)
+	endUpdate()
+	s.currentTick++
+	// AddTickListener never appends in place, so the slice can be shared.
+	listeners := s.tickListeners
+	if tickTrace != nil {
+		s.tickCtx = tickTrace.Context()
 	}
-	s.isPaused = true
 	s.mu.Unlock()
-	s.pause <- struct{}{}
-}
 
-// Resume continues a paused simulation.
-// If the simulation is not paused, this method does nothing.
-func (s *simulator) Resume() {
+	if s.HasTickHooks() {
+		s.RunTickHooks(tickTrace, NewTickContext(tick, s.snapshotPlants, s.waterPlant, s.HookEnvironment()))
+	}
+	for _, l := range listeners {
+		tickTrace.Notify(l, tick)
+	}
+	var violations []InvariantViolation
 	s.mu.Lock()
-	if !s.isPaused {
-		s.mu.Unlock()
-		log.Println("Already running, ignoring")
-		return
+	s.settleStats()
+	if s.invariants != nil {
+		violations = s.invariants.check(tick, s.plants, &s.store)
+		violations = append(violations, s.invariants.checkStats(tick, s.sectionStats, s.recomputeStats())...)
 	}
 	s.mu.Unlock()
-	s.resume <- struct{}{}
+	if tickTrace != nil {
+		s.mu.Lock()
+		s.tickCtx = nil
+		s.mu.Unlock()
+		tickTrace.End(plants)
+	}
+	if s.invariants != nil {
+		s.invariants.report(violations)
+	}
 }
 
-// Stop terminates the simulation.
-// Once stopped, the simulation cannot be resumed and must be restarted.
-func (s *simulator) Stop() {
-	s.stop <- struct{}{}
+// SetTracer traces every tick from the next one on with tracer, see
+// TickTrace; nil turns tracing off.
+// This method is safe for concurrent use.
+func (s *simulator) SetTracer(tracer trace.Tracer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tracer = tracer
 }
 
-// IsPaused returns true if the simulation is currently paused, false otherwise.
+// TickContext returns a context carrying the span of the tick whose
+// listeners are running, so that they can trace work done on its behalf, and
+// context.Background() between ticks or without a tracer.
 // This method is safe for concurrent use.
-func (s *simulator) IsPaused() bool {
+func (s *simulator) TickContext() context.Context {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.isPaused
+	if s.tickCtx == nil {
+		return context.Background()
+	}
+	return s.tickCtx
+}
+
+// AddTickListener registers a listener to be notified after every tick.
+// Listeners are called in registration order.
+// This method is safe for concurrent use.
+func (s *simulator) AddTickListener(l TickListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tickListeners = append(slices.Clip(s.tickListeners), l)
 }
 
 // AddPlant adds a new plant to the greenhouse simulator.
 // The plant will be included in the simulation starting from the next tick.
+// The simulator keeps a copy of the plant in its own storage, so p is not
+// updated by the ticks: the live plant is the one GetAllPlants and
+// GetPlantsBySectionID return.
+// Returns an error wrapping ErrPlantExists if the plant ID is taken.
 // This method is safe for concurrent use.
 func (s *simulator) AddPlant(p *models.Plant) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	exists := s.plantsById[p.ID]
-	if exists != nil {
-		return errors.New("plant with ID already added to simulator: " + p.ID)
+	if _, exists := s.plantsById[p.ID]; exists {
+		return fmt.Errorf("%w: %s", ErrPlantExists, p.ID)
+	}
+	s.insertPlant(p)
+	return nil
+}
+
+// insertPlant copies p into the store, pointing the copy to the simulator's
+// shared copy of its type, and indexes it. The caller must hold s.mu.
+func (s *simulator) insertPlant(p *models.Plant) {
+	slot := s.store.add(p)
+	if p.Type != nil {
+		s.store.at(slot).Type = s.types.Intern(*p.Type)
+	}
+	s.plants = append(s.plants, slot)
+	s.plantsById[p.ID] = slot
+	s.plantsBySectionID[p.SectionID] = append(s.plantsBySectionID[p.SectionID], slot)
+	s.touch(p.SectionID, p.Alive)
+	s.account(slot)
+}
+
+// AddPlants adds a batch of plants at once: either all of them, in order, or
+// none. The whole batch is checked before any plant is added, and every plant
+// that cannot be added is reported.
+// Returns a *BatchError if any plant is nil (ErrNilPlant) or has an ID that
+// is taken or comes earlier in the batch (ErrPlantExists).
+// The plants are copied as by AddPlant.
+// This method is safe for concurrent use.
+func (s *simulator) AddPlants(plants []*models.Plant) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.plantsById) == 0 {
+		s.plantsById = make(map[string]int32, len(plants))
+	}
+	// The ID index doubles as the set of IDs seen in the batch, and is rolled
+	// back if any plant is refused. The slots it holds until then are not
+	// real ones.
+	var invalid []*PlantError
+	var indexed []string
+	for i, p := range plants {
+		if p == nil {
+			invalid = append(invalid, &PlantError{Index: i, Err: ErrNilPlant})
+			continue
+		}
+		if _, exists := s.plantsById[p.ID]; exists {
+			invalid = append(invalid, &PlantError{Index: i, PlantID: p.ID, Err: fmt.Errorf("%w: %s", ErrPlantExists, p.ID)})
+			continue
+		}
+		s.plantsById[p.ID] = -1
+		indexed = append(indexed, p.ID)
+	}
+	if len(invalid) > 0 {
+		for _, id := range indexed {
+			delete(s.plantsById, id)
+		}
+		return &BatchError{Plants: invalid}
+	}
+	s.plants = slices.Grow(s.plants, len(plants))
+	for _, p := range plants {
+		s.insertPlant(p)
+	}
+	return nil
+}
+
+// RemovePlant removes a plant from the greenhouse simulator. It is no longer
+// updated from the next tick on.
+// Returns an error wrapping ErrPlantNotFound if no plant has the given ID.
+// This method is safe for concurrent use.
+func (s *simulator) RemovePlant(plantID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	slot, ok := s.plantsById[plantID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrPlantNotFound, plantID)
+	}
+	s.removePlant(slot)
+	return nil
+}
+
+// removePlant removes the plant in slot from the simulator's indexes and
+// frees the slot. The caller must hold s.mu.
+func (s *simulator) removePlant(slot int32) {
+	plant := s.store.at(slot)
+	delete(s.plantsById, plant.ID)
+	if s.invariants != nil {
+		s.invariants.forget(plant.ID)
+	}
+	s.plants = slices.DeleteFunc(s.plants, func(other int32) bool { return other == slot })
+	s.touch(plant.SectionID, false)
+	s.unaccount(slot)
+	s.unindexSection(slot)
+	s.store.release(slot)
+}
+
+// unindexSection removes the plant in slot from the index of its section,
+// and the section from the index once it has no plant left. The caller must
+// hold s.mu.
+func (s *simulator) unindexSection(slot int32) {
+	sectionID := s.store.at(slot).SectionID
+	sectionPlants := slices.DeleteFunc(s.plantsBySectionID[sectionID], func(other int32) bool { return other == slot })
+	if len(sectionPlants) == 0 {
+		delete(s.plantsBySectionID, sectionID)
+		delete(s.sections, sectionID)
+		return
+	}
+	s.plantsBySectionID[sectionID] = sectionPlants
+}
+
+// plant returns the plant with the given ID, nil if there is none. The
+// caller must hold s.mu.
+func (s *simulator) plant(plantID string) *models.Plant {
+	slot, ok := s.plantsById[plantID]
+	if !ok {
+		return nil
+	}
+	return s.store.at(slot)
+}
+
+// livePlants returns the plants in slots. The caller must hold s.mu.
+func (s *simulator) livePlants(slots []int32) []*models.Plant {
+	plants := make([]*models.Plant, len(slots))
+	for i, slot := range slots {
+		plants[i] = s.store.at(slot)
+	}
+	return plants
+}
+
+// TransplantPlant moves a plant to another section, at the end of its plants.
+// The plant keeps its place among all plants, its state and its soil, and
+// loses its position, which was within its old section.
+// Returns an error if:
+// - sectionID is empty
+// - no plant has the given ID (ErrPlantNotFound)
+// This method is safe for concurrent use.
+func (s *simulator) TransplantPlant(plantID, sectionID string) error {
+	if sectionID == "" {
+		return errors.New("section ID cannot be empty")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	slot, ok := s.plantsById[plantID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrPlantNotFound, plantID)
+	}
+	plant := s.store.at(slot)
+	if plant.SectionID == sectionID {
+		return nil
+	}
+	s.touch(plant.SectionID, false)
+	s.unindexSection(slot)
+	plant.SectionID = sectionID
+	plant.Position = nil
+	s.plantsBySectionID[sectionID] = append(s.plantsBySectionID[sectionID], slot)
+	s.touch(sectionID, plant.Alive)
+	s.account(slot)
+	return nil
+}
+
+// PrunePlant cuts a plant's growth stage back by fraction of itself, in
+// exchange for the modifier of the prune effect, see models.Plant.Prune.
+// Returns an error wrapping ErrPlantNotFound if no plant has the given ID,
+// or if the plant cannot be pruned by fraction.
+// This method is safe for concurrent use.
+func (s *simulator) PrunePlant(plantID string, fraction float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	plant := s.plant(plantID)
+	if plant == nil {
+		return fmt.Errorf("%w: %s", ErrPlantNotFound, plantID)
+	}
+	if s.invariants != nil {
+		s.invariants.forget(plantID)
+	}
+	s.touch(plant.SectionID, false)
+	err := plant.Prune(fraction, s.pruneEffect)
+	s.account(s.plantsById[plantID])
+	return err
+}
+
+// SetPlantFlags excludes a plant from watering or quarantines it, or undoes
+// either, see models.Plant.SetFlags. Returns an error wrapping
+// ErrPlantNotFound if no plant has the given ID.
+// This method is safe for concurrent use.
+func (s *simulator) SetPlantFlags(plantID string, flags models.PlantFlags) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	plant := s.plant(plantID)
+	if plant == nil {
+		return fmt.Errorf("%w: %s", ErrPlantNotFound, plantID)
 	}
-	s.plantsById[p.ID] = p
-	s.plantsBySectionID[p.SectionID] = append(s.plantsBySectionID[p.SectionID], p)
+	plant.SetFlags(flags)
 	return nil
 }
 
-// GetPlants returns a snapshot of all plants in the greenhouse.
-// The returned slice is a copy and safe to iterate, but the plants
-// themselves are shared with the simulator.
+// SetPlantTags replaces the tags of a plant with a copy of tags. Returns an
+// error wrapping ErrPlantNotFound if no plant has the given ID.
+// This method is safe for concurrent use.
+func (s *simulator) SetPlantTags(plantID string, tags []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	plant := s.plant(plantID)
+	if plant == nil {
+		return fmt.Errorf("%w: %s", ErrPlantNotFound, plantID)
+	}
+	plant.Tags = slices.Clone(tags)
+	return nil
+}
+
+// SetPruneEffect sets the effect of pruning from the next PrunePlant on,
+// models.DefaultPruneEffect until then. Plants pruned before keep theirs.
+// Returns an error if the effect is invalid, see models.PruneEffect.Validate.
+// This method is safe for concurrent use.
+func (s *simulator) SetPruneEffect(effect models.PruneEffect) error {
+	if err := effect.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruneEffect = effect
+	return nil
+}
+
+// ThinSection removes the weakest plants of a section beyond the keepN
+// healthiest, dead plants first, and returns their IDs in the order they
+// were removed: by health, ties broken by ID. Returns an error if keepN is
+// negative.
+// This method is safe for concurrent use.
+func (s *simulator) ThinSection(sectionID string, keepN int) ([]string, error) {
+	if keepN < 0 {
+		return nil, errors.New("plants to keep cannot be negative")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	slots := slices.Clone(s.plantsBySectionID[sectionID])
+	if len(slots) <= keepN {
+		return nil, nil
+	}
+	slices.SortFunc(slots, func(a, b int32) int {
+		plantA, plantB := s.store.at(a), s.store.at(b)
+		return cmp.Or(cmp.Compare(plantA.Health, plantB.Health), strings.Compare(plantA.ID, plantB.ID))
+	})
+	var removed []string
+	for _, slot := range slots[:len(slots)-keepN] {
+		removed = append(removed, s.store.at(slot).ID)
+		s.removePlant(slot)
+	}
+	return removed, nil
+}
+
+// GetPlants returns a snapshot of all plants in the greenhouse, in the order
+// they were added. The returned slice is a copy and safe to iterate, but the
+// plants themselves are shared with the simulator, and every section is
+// dirty until the end of the tick, see SectionStats.
 func (s *simulator) GetAllPlants() []*models.Plant {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	plantCopy := make([]*models.Plant, len(s.plantsById))
-	copy(plantCopy, slices.Collect(maps.Values(s.plantsById)))
-	return plantCopy
+	s.markDirty("")
+	return s.livePlants(s.plants)
+}
+
+// snapshotPlants returns snapshots of every plant, for tick hooks.
+func (s *simulator) snapshotPlants() []*models.Plant {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	plants := make([]*models.Plant, len(s.plants))
+	for i, slot := range s.plants {
+		plants[i] = s.store.at(slot).Clone()
+	}
+	return plants
+}
+
+// waterPlant adds water to the soil of a plant, for tick hooks.
+func (s *simulator) waterPlant(plantID string, amount float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	plant := s.plant(plantID)
+	if plant == nil {
+		return fmt.Errorf("%w: %s", ErrPlantNotFound, plantID)
+	}
+	s.touch(plant.SectionID, false)
+	plant.AddWater(amount)
+	s.account(s.plantsById[plantID])
+	return nil
+}
+
+// UpdatePlants runs fn holding the lock of the plants, for tick listeners
+// that change the plants handed out by GetAllPlants, GetPlantsBySectionID and
+// GetPlantsByIDs, so that readers copying them, such as GetPlant and
+// QueryPlants, see every change whole. Every section is dirty until the end
+// of the tick, see SectionStats. fn must not call back into the simulator,
+// nor publish events whose subscribers may.
+// This method is safe for concurrent use.
+func (s *simulator) UpdatePlants(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.markDirty("")
+	fn()
+}
+
+// Status returns a summary of the simulator. The ticks it has run are its
+// current tick, as it starts from tick 0.
+// This method is safe for concurrent use.
+func (s *simulator) Status() Status {
+	state := s.State()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	status := Status{
+		State:        state,
+		Paused:       state == Paused,
+		Tick:         s.currentTick,
+		UptimeTicks:  s.currentTick,
+		TickInterval: s.tickInterval,
+		Plants:       len(s.plants),
+		Timing:       s.TickTiming(),
+	}
+	for _, slot := range s.plants {
+		if s.store.at(slot).Alive {
+			status.AlivePlants++
+		}
+	}
+	return status
+}
+
+// GetPlant returns a snapshot of a plant: a copy taken between ticks, which
+// later ticks do not change.
+// Returns an error wrapping ErrPlantNotFound if no plant has the given ID.
+// This method is safe for concurrent use.
+func (s *simulator) GetPlant(plantID string) (*models.Plant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	plant := s.plant(plantID)
+	if plant == nil {
+		return nil, fmt.Errorf("%w: %s", ErrPlantNotFound, plantID)
+	}
+	return plant.Clone(), nil
+}
+
+// GetPlantDiagnosis returns the health a plant lost to each factor on the
+// last tick, see models.HealthDiagnosis.
+// Returns an error wrapping ErrPlantNotFound if no plant has the given ID.
+// This method is safe for concurrent use.
+func (s *simulator) GetPlantDiagnosis(plantID string) (models.HealthDiagnosis, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	plant := s.plant(plantID)
+	if plant == nil {
+		return models.HealthDiagnosis{}, fmt.Errorf("%w: %s", ErrPlantNotFound, plantID)
+	}
+	return plant.Diagnosis, nil
+}
+
+// GetPlantsByIDs returns snapshots of the plants with the given IDs, as
+// GetPlant does, in the order of plantIDs, all taken between the same two
+// ticks. The IDs no plant has are returned in their order rather than
+// failing the lookup.
+// This method is safe for concurrent use.
+func (s *simulator) GetPlantsByIDs(plantIDs []string) ([]*models.Plant, []string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	plants := make([]*models.Plant, 0, len(plantIDs))
+	var missing []string
+	for _, plantID := range plantIDs {
+		plant := s.plant(plantID)
+		if plant == nil {
+			missing = append(missing, plantID)
+			continue
+		}
+		plants = append(plants, plant.Clone())
+	}
+	return plants, missing
 }
 
 // GetPlantsBySectionID returns a snapshot of all plants in the specified greenhouse section.
-// The returned slice is a copy and safe to iterate, but the plants themselves are shared with the simulator.
+// The returned slice is a copy and safe to iterate, but the plants themselves are shared with the simulator,
+// and the section is dirty until the end of the tick, see SectionStats.
 // This method is safe for concurrent use.
 func (s *simulator) GetPlantsBySectionID(sectionID string) []*models.Plant {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	sectionPlants := s.plantsBySectionID[sectionID]
-	plantCopy := make([]*models.Plant, len(sectionPlants))
-	copy(plantCopy, sectionPlants)
-	return plantCopy
+	s.markDirty(sectionID)
+	return s.livePlants(s.plantsBySectionID[sectionID])
+}
+
+// ListSectionIDs returns the sorted IDs of the sections that have plants,
+// which may be sections no config declares.
+// This method is safe for concurrent use.
+func (s *simulator) ListSectionIDs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Sorted(maps.Keys(s.plantsBySectionID))
+}
+
+// sectionTicks is when the plants of a section last changed and last had a
+// living plant among them, counted as SectionActivity counts, -1 for never.
+type sectionTicks struct {
+	changedAt int
+	livingAt  int
+}
+
+// touch records a change to the plants of a section at the current tick,
+// which gave it a living plant if living is true. The caller must hold s.mu.
+func (s *simulator) touch(sectionID string, living bool) {
+	ticks, ok := s.sections[sectionID]
+	if !ok {
+		ticks.livingAt = -1
+	}
+	ticks.changedAt = s.currentTick
+	if living {
+		ticks.livingAt = s.currentTick
+	}
+	s.sections[sectionID] = ticks
+}
+
+// SectionActivity returns when the plants of a section last changed, see
+// SectionActivity. Reports false if the section has no plants.
+// This method is safe for concurrent use.
+func (s *simulator) SectionActivity(sectionID string) (SectionActivity, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ticks, ok := s.sections[sectionID]
+	if !ok {
+		return SectionActivity{}, false
+	}
+	return SectionActivity{ChangedAt: ticks.changedAt, Dead: ticks.livingAt < s.currentTick}, true
 }
 
 // GetCurrentTick returns the current simulation tick count.
@@ -173,3 +803,86 @@ func (s *simulator) GetCurrentTick() int {
 	defer s.mu.RUnlock()
 	return s.currentTick
 }
+
+// GetTickInterval returns the wall-clock interval between ticks at speed 1,
+// which is also the simulated time a tick covers.
+func (s *simulator) GetTickInterval() time.Duration {
+	return s.tickInterval
+}
+
+// SetSpeed changes how fast Start runs ticks: at speed 2 they come every half
+// tick interval. The simulated time a tick covers does not change.
+// Returns an error if speed is not positive.
+// This method is safe for concurrent use.
+func (s *simulator) SetSpeed(speed float64) error {
+	if speed <= 0 {
+		return errors.New("speed must be positive")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.speed = speed
+	s.ticker.Reset(max(time.Duration(float64(s.tickInterval)/speed), time.Nanosecond))
+	return nil
+}
+
+// GetSpeed returns the speed set by SetSpeed, 1 by default.
+// This method is safe for concurrent use.
+func (s *simulator) GetSpeed() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.speed
+}
+
+// Reset takes the simulator back to tick 0 for another run, as if it had
+// just been built with its plants: they are put back as the first tick found
+// them, the plants added since are dropped and those removed since come
+// back, and the tick timing and invariant checks start over. A stopped
+// simulator can be started again. The plant storage and indexes are reused
+// rather than allocated afresh, so pointers to plants taken before the reset
+// must not be kept past it. A simulator that has not run a tick keeps its
+// plants as they are.
+//
+// With keepConfig the tick and state listeners stay registered; without it
+// they are forgotten, for the owner of the simulator to register afresh. The
+// tick interval, speed, overrun policy, prune effect, tracer and tick hooks
+// are kept either way.
+// Returns ErrResetWhileRunning if the simulator is running or paused.
+// This method is safe for concurrent use, but not with Start.
+func (s *simulator) Reset(keepConfig bool) error {
+	if err := s.rewind(keepConfig); err != nil {
+		return err
+	}
+	s.resetTiming()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.currentTick = 0
+	s.tickCtx = nil
+	if !keepConfig {
+		s.tickListeners = nil
+	}
+	if s.invariants != nil {
+		clear(s.invariants.growth)
+	}
+	if s.initial == nil {
+		return nil
+	}
+	s.store.reset()
+	s.types = models.PlantTypes{}
+	s.plants = s.plants[:0]
+	clear(s.plantsById)
+	for sectionID, slots := range s.plantsBySectionID {
+		s.plantsBySectionID[sectionID] = slots[:0]
+	}
+	clear(s.sections)
+	clear(s.sectionStats)
+	clear(s.shares)
+	for _, plant := range s.initial {
+		s.insertPlant(plant.Clone())
+	}
+	for sectionID, slots := range s.plantsBySectionID {
+		if len(slots) == 0 {
+			delete(s.plantsBySectionID, sectionID)
+		}
+	}
+	return nil
+}
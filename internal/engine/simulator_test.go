@@ -0,0 +1,590 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/models"
+	"maps"
+	"math"
+	"reflect"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testPlant returns a tomato plant in section-A.
+func testPlant(tb testing.TB, id string) *models.Plant {
+	tb.Helper()
+	tomato := models.PlantType{
+		Name:                  "Tomato",
+		OptimalSaturation:     0.6,
+		MinSaturation:         0.2,
+		MaxSaturation:         0.9,
+		BaseGrowthRate:        0.01,
+		SaturationDepletion:   0.01,
+		HealthEnhancementRate: 0.01,
+	}
+	plant, err := models.NewPlant(id, tomato, "section-A", 0.6)
+	if err != nil {
+		tb.Fatalf("failed to create plant: %v", err)
+	}
+	return plant
+}
+
+// newTestSimulator returns a simulator with the plants tomato-0 to
+// tomato-(n-1), ticking every millisecond once started.
+func newTestSimulator(t *testing.T, n int) Simulator {
+	t.Helper()
+	s := NewSimulator(time.Millisecond)
+	for i := range n {
+		if err := s.AddPlant(testPlant(t, fmt.Sprintf("tomato-%d", i))); err != nil {
+			t.Fatalf("failed to add plant: %v", err)
+		}
+	}
+	return s
+}
+
+// plantIDs returns the IDs of plants, in order.
+func plantIDs(plants []*models.Plant) []string {
+	ids := make([]string, 0, len(plants))
+	for _, plant := range plants {
+		ids = append(ids, plant.ID)
+	}
+	return ids
+}
+
+func TestGetPlant(t *testing.T) {
+	s := newTestSimulator(t, 2)
+
+	plant, err := s.GetPlant("tomato-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plant.ID != "tomato-1" {
+		t.Errorf("expected tomato-1, got %s", plant.ID)
+	}
+	s.Step()
+	if plant.GrowthStage != 0 {
+		t.Errorf("expected the snapshot not to change with the tick, got growth %.2f", plant.GrowthStage)
+	}
+
+	_, err = s.GetPlant("cactus-1")
+	if !errors.Is(err, ErrPlantNotFound) || err.Error() != "no plant found for the provided ID: cactus-1" {
+		t.Errorf("expected an error wrapping ErrPlantNotFound, got %v", err)
+	}
+}
+
+func TestGetPlantsByIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		ids      []string
+		expected []string
+		missing  []string
+	}{
+		{"found", []string{"tomato-2", "tomato-0"}, []string{"tomato-2", "tomato-0"}, nil},
+		{"not found", []string{"cactus-1"}, []string{}, []string{"cactus-1"}},
+		{"mixed", []string{"tomato-1", "cactus-1", "tomato-2", "fern-1"}, []string{"tomato-1", "tomato-2"}, []string{"cactus-1", "fern-1"}},
+		{"none", nil, []string{}, nil},
+	}
+
+	s := newTestSimulator(t, 3)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plants, missing := s.GetPlantsByIDs(tt.ids)
+			if got := plantIDs(plants); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected plants %v, got %v", tt.expected, got)
+			}
+			if !reflect.DeepEqual(missing, tt.missing) {
+				t.Errorf("expected missing %v, got %v", tt.missing, missing)
+			}
+		})
+	}
+}
+
+func TestGetPlant_WhileTicking(t *testing.T) {
+	s := newTestSimulator(t, 10)
+	go s.Start()
+	defer s.Stop()
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 200 {
+				if plant, err := s.GetPlant("tomato-3"); err != nil || plant.ID != "tomato-3" {
+					t.Errorf("expected tomato-3, got %v, %v", plant, err)
+					return
+				}
+				plants, missing := s.GetPlantsByIDs([]string{"tomato-0", "cactus-1", "tomato-9"})
+				if len(plants) != 2 || len(missing) != 1 {
+					t.Errorf("expected 2 plants and 1 missing ID, got %d and %v", len(plants), missing)
+					return
+				}
+				if math.Abs(plants[0].GrowthStage-plants[1].GrowthStage) > 1e-9 {
+					t.Errorf("expected plants snapshotted between the same ticks, got growth %.4f and %.4f", plants[0].GrowthStage, plants[1].GrowthStage)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestAddPlants(t *testing.T) {
+	tests := []struct {
+		name     string
+		ids      []string
+		invalid  []PlantError
+		errorMsg string
+	}{
+		{"valid", []string{"tomato-2", "tomato-3"}, nil, ""},
+		{"empty", nil, nil, ""},
+		{"nil plant", []string{"tomato-2", ""}, []PlantError{{Index: 1, Err: ErrNilPlant}}, "1 invalid plants: plant 1: plant cannot be nil"},
+		{
+			"taken and repeated IDs", []string{"tomato-0", "tomato-2", "", "tomato-2"},
+			[]PlantError{{Index: 0, PlantID: "tomato-0"}, {Index: 2, Err: ErrNilPlant}, {Index: 3, PlantID: "tomato-2"}},
+			"3 invalid plants: plant 0: plant with ID already added to simulator: tomato-0; plant 2: plant cannot be nil; plant 3: plant with ID already added to simulator: tomato-2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSimulator(t, 2)
+			var batch []*models.Plant
+			for _, id := range tt.ids {
+				var plant *models.Plant
+				if id != "" {
+					plant = testPlant(t, id)
+				}
+				batch = append(batch, plant)
+			}
+
+			err := s.AddPlants(batch)
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				expected := append([]string{"tomato-0", "tomato-1"}, tt.ids...)
+				if got := plantIDs(s.GetAllPlants()); !reflect.DeepEqual(got, expected) {
+					t.Errorf("expected the plants %v, got %v", expected, got)
+				}
+				return
+			}
+
+			var batchErr *BatchError
+			if !errors.As(err, &batchErr) || err.Error() != tt.errorMsg {
+				t.Fatalf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+			if len(batchErr.Plants) != len(tt.invalid) {
+				t.Fatalf("expected %d invalid plants, got %d", len(tt.invalid), len(batchErr.Plants))
+			}
+			for i, expected := range tt.invalid {
+				got := batchErr.Plants[i]
+				if got.Index != expected.Index || got.PlantID != expected.PlantID {
+					t.Errorf("expected plant %d of the batch to be refused, got %+v", expected.Index, got)
+				}
+				reason := ErrPlantExists
+				if expected.Err != nil {
+					reason = expected.Err
+				}
+				if !errors.Is(got, reason) || !errors.Is(err, reason) {
+					t.Errorf("expected plant %d to be refused for %v, got %v", expected.Index, reason, got.Err)
+				}
+			}
+
+			// Nothing of a refused batch is added, not even its valid plants.
+			if got := plantIDs(s.GetAllPlants()); !reflect.DeepEqual(got, []string{"tomato-0", "tomato-1"}) {
+				t.Errorf("expected a refused batch to add no plant, got %v", got)
+			}
+			if _, missing := s.GetPlantsByIDs([]string{"tomato-2"}); len(missing) != 1 {
+				t.Error("expected a refused batch to leave the ID index unchanged")
+			}
+			if got := s.GetPlantsBySectionID("section-A"); len(got) != 2 {
+				t.Errorf("expected a refused batch to leave the section index unchanged, got %d plants", len(got))
+			}
+		})
+	}
+}
+
+func TestAddPlant_CopiesPlantsAndSharesTypes(t *testing.T) {
+	first, second := NewSimulator(time.Hour), NewSimulator(time.Hour)
+	added := []*models.Plant{testPlant(t, "tomato-0"), testPlant(t, "tomato-1")}
+	for _, plant := range added {
+		if err := first.AddPlant(plant); err != nil {
+			t.Fatalf("failed to add plant: %v", err)
+		}
+	}
+	if err := second.AddPlant(testPlant(t, "tomato-0")); err != nil {
+		t.Fatalf("failed to add plant: %v", err)
+	}
+
+	first.Step()
+
+	if added[0].SoilSaturation != 0.6 {
+		t.Errorf("expected the added plant to keep its saturation of 0.6, got %.4f", added[0].SoilSaturation)
+	}
+	live := first.GetAllPlants()
+	if live[0].SoilSaturation == 0.6 {
+		t.Error("expected the tick to change the simulator's copy of the plant")
+	}
+	if live[0].Type != live[1].Type {
+		t.Error("expected plants of one type to share it")
+	}
+	if other := second.GetAllPlants()[0].Type; other == live[0].Type {
+		t.Error("expected each simulator to hold its own copy of the type")
+	}
+}
+
+// benchmarkPlants returns n plants with distinct IDs.
+func benchmarkPlants(b *testing.B, n int) []*models.Plant {
+	plants := make([]*models.Plant, n)
+	for i := range plants {
+		plants[i] = testPlant(b, fmt.Sprintf("tomato-%06d", i))
+	}
+	return plants
+}
+
+func BenchmarkAddPlant_Loop5000(b *testing.B) {
+	plants := benchmarkPlants(b, 5000)
+	b.ReportAllocs()
+	for b.Loop() {
+		s := NewSimulator(time.Hour)
+		for _, plant := range plants {
+			if err := s.AddPlant(plant); err != nil {
+				b.Fatalf("failed to add plant: %v", err)
+			}
+		}
+	}
+}
+
+func BenchmarkAddPlants_5000(b *testing.B) {
+	plants := benchmarkPlants(b, 5000)
+	b.ReportAllocs()
+	for b.Loop() {
+		s := NewSimulator(time.Hour)
+		if err := s.AddPlants(plants); err != nil {
+			b.Fatalf("failed to add plants: %v", err)
+		}
+	}
+}
+
+func TestStatus(t *testing.T) {
+	s := newTestSimulator(t, 2)
+	dead := testPlant(t, "tomato-dead")
+	dead.Alive = false
+	if err := s.AddPlant(dead); err != nil {
+		t.Fatalf("failed to add plant: %v", err)
+	}
+	for range 2 {
+		s.Step()
+	}
+
+	expected := Status{State: Created, Tick: 2, UptimeTicks: 2, TickInterval: time.Millisecond, Plants: 3, AlivePlants: 2}
+	if got := s.Status(); got != expected {
+		t.Errorf("expected the status %+v, got %+v", expected, got)
+	}
+	if s.IsPaused() || s.GetTickInterval() != time.Millisecond {
+		t.Errorf("expected a created simulator ticking every millisecond, got paused=%v and %v", s.IsPaused(), s.GetTickInterval())
+	}
+
+	go s.Start()
+	defer s.Stop()
+	waitForState(t, s, Running)
+	if err := s.Pause(); err != nil {
+		t.Fatalf("failed to pause: %v", err)
+	}
+	status := s.Status()
+	if !s.IsPaused() || !status.Paused || status.State != Paused {
+		t.Errorf("expected a paused simulator, got IsPaused=%v and %+v", s.IsPaused(), status)
+	}
+	if status.Tick != status.UptimeTicks || status.Tick < 2 {
+		t.Errorf("expected the ticks run to follow the tick, got %+v", status)
+	}
+}
+
+// checkSectionIndex fails t unless the section index of s holds exactly the
+// plants of every section, in the order they joined it.
+func checkSectionIndex(t *testing.T, s Simulator, expected map[string][]string) {
+	t.Helper()
+	if got := s.ListSectionIDs(); !reflect.DeepEqual(got, slices.Sorted(maps.Keys(expected))) {
+		t.Errorf("expected the sections %v, got %v", slices.Sorted(maps.Keys(expected)), got)
+	}
+	for sectionID, ids := range expected {
+		plants := s.GetPlantsBySectionID(sectionID)
+		if got := plantIDs(plants); !reflect.DeepEqual(got, ids) {
+			t.Errorf("expected %s to hold %v, got %v", sectionID, ids, got)
+		}
+		for _, plant := range plants {
+			if plant.SectionID != sectionID {
+				t.Errorf("expected %s in %s, got %s", plant.ID, sectionID, plant.SectionID)
+			}
+		}
+	}
+}
+
+func TestSectionIndex(t *testing.T) {
+	s := newTestSimulator(t, 4)
+	checkSectionIndex(t, s, map[string][]string{"section-A": {"tomato-0", "tomato-1", "tomato-2", "tomato-3"}})
+
+	for _, move := range []struct{ plantID, sectionID string }{
+		{"tomato-1", "section-B"},
+		{"tomato-3", "section-C"},
+		{"tomato-0", "section-B"},
+		{"tomato-0", "section-B"},
+	} {
+		if err := s.TransplantPlant(move.plantID, move.sectionID); err != nil {
+			t.Fatalf("failed to transplant %s: %v", move.plantID, err)
+		}
+	}
+	checkSectionIndex(t, s, map[string][]string{
+		"section-A": {"tomato-2"},
+		"section-B": {"tomato-1", "tomato-0"},
+		"section-C": {"tomato-3"},
+	})
+	if got := plantIDs(s.GetAllPlants()); !reflect.DeepEqual(got, []string{"tomato-0", "tomato-1", "tomato-2", "tomato-3"}) {
+		t.Errorf("expected transplants to keep the plant order, got %v", got)
+	}
+
+	// Sections disappear with their last plant, whether removed or moved out.
+	if err := s.RemovePlant("tomato-2"); err != nil {
+		t.Fatalf("failed to remove plant: %v", err)
+	}
+	if err := s.TransplantPlant("tomato-3", "section-B"); err != nil {
+		t.Fatalf("failed to transplant: %v", err)
+	}
+	if err := s.RemovePlant("tomato-1"); err != nil {
+		t.Fatalf("failed to remove plant: %v", err)
+	}
+	checkSectionIndex(t, s, map[string][]string{"section-B": {"tomato-0", "tomato-3"}})
+
+	if err := s.TransplantPlant("cactus-1", "section-A"); !errors.Is(err, ErrPlantNotFound) {
+		t.Errorf("expected ErrPlantNotFound for an unknown plant, got %v", err)
+	}
+	if err := s.TransplantPlant("tomato-0", ""); err == nil || err.Error() != "section ID cannot be empty" {
+		t.Errorf("expected an empty section to be refused, got %v", err)
+	}
+}
+
+func TestGetPlantsBySectionID_ReturnsCopy(t *testing.T) {
+	s := newTestSimulator(t, 3)
+	plants := s.GetPlantsBySectionID("section-A")
+	plants[0] = nil
+	if err := s.RemovePlant("tomato-1"); err != nil {
+		t.Fatalf("failed to remove plant: %v", err)
+	}
+	if got := plantIDs(plants[1:]); !reflect.DeepEqual(got, []string{"tomato-1", "tomato-2"}) {
+		t.Errorf("expected the returned slice to outlive the removal, got %v", got)
+	}
+	checkSectionIndex(t, s, map[string][]string{"section-A": {"tomato-0", "tomato-2"}})
+}
+
+func TestSectionActivity(t *testing.T) {
+	s := newTestSimulator(t, 2)
+	checkActivity := func(sectionID string, expected SectionActivity, tracked bool) {
+		t.Helper()
+		got, ok := s.SectionActivity(sectionID)
+		if got != expected || ok != tracked {
+			t.Errorf("expected the activity of %s to be %+v, %v, got %+v, %v", sectionID, expected, tracked, got, ok)
+		}
+	}
+	checkActivity("section-A", SectionActivity{ChangedAt: 0}, true)
+	checkActivity("section-B", SectionActivity{}, false)
+
+	s.Step()
+	checkActivity("section-A", SectionActivity{ChangedAt: 1}, true)
+
+	// Ticks leave dead plants as they are.
+	for _, plant := range s.GetAllPlants() {
+		plant.Alive = false
+	}
+	s.Step()
+	checkActivity("section-A", SectionActivity{ChangedAt: 1, Dead: true}, true)
+	s.Step()
+	checkActivity("section-A", SectionActivity{ChangedAt: 1, Dead: true}, true)
+
+	plant := testPlant(t, "tomato-2")
+	plant.SectionID = "section-B"
+	if err := s.AddPlant(plant); err != nil {
+		t.Fatalf("failed to add plant: %v", err)
+	}
+	checkActivity("section-B", SectionActivity{ChangedAt: 3}, true)
+	if err := s.TransplantPlant("tomato-2", "section-A"); err != nil {
+		t.Fatalf("failed to transplant: %v", err)
+	}
+	checkActivity("section-A", SectionActivity{ChangedAt: 3}, true)
+	checkActivity("section-B", SectionActivity{}, false)
+
+	if err := s.RemovePlant("tomato-2"); err != nil {
+		t.Fatalf("failed to remove plant: %v", err)
+	}
+	s.Step()
+	checkActivity("section-A", SectionActivity{ChangedAt: 3, Dead: true}, true)
+}
+
+// BenchmarkGetPlantsBySectionID looks up sections of 100 plants among more
+// and more sections: the lookup takes as long however many plants there are.
+func BenchmarkGetPlantsBySectionID(b *testing.B) {
+	for _, sections := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("%d plants", sections*100), func(b *testing.B) {
+			s := NewSimulator(time.Hour)
+			plants := benchmarkPlants(b, sections*100)
+			for i, plant := range plants {
+				plant.SectionID = fmt.Sprintf("section-%04d", i%sections)
+			}
+			if err := s.AddPlants(plants); err != nil {
+				b.Fatalf("failed to add plants: %v", err)
+			}
+			b.ReportAllocs()
+			i := 0
+			for b.Loop() {
+				if got := s.GetPlantsBySectionID(fmt.Sprintf("section-%04d", i%sections)); len(got) != 100 {
+					b.Fatalf("expected 100 plants, got %d", len(got))
+				}
+				i++
+			}
+		})
+	}
+}
+
+// BenchmarkStep ticks large greenhouses. Besides the time of a tick, tick-ns,
+// it reports how long a full collection of the garbage they leave takes,
+// gc-ns, and how long it stopped the program, gc-pause-ns: the rest of a
+// greenhouse allocates on every tick, which makes the collector run and scan
+// the plants.
+func BenchmarkStep(b *testing.B) {
+	for _, n := range []int{100_000, 500_000} {
+		b.Run(fmt.Sprintf("%d plants", n), func(b *testing.B) {
+			s := NewSimulator(time.Hour)
+			plants := benchmarkPlants(b, n)
+			for i, plant := range plants {
+				plant.SectionID = fmt.Sprintf("section-%04d", i%1000)
+			}
+			if err := s.AddPlants(plants); err != nil {
+				b.Fatalf("failed to add plants: %v", err)
+			}
+			plants = nil
+			runtime.GC()
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			var ticking, collecting time.Duration
+			for b.Loop() {
+				start := time.Now()
+				s.Step()
+				ticking += time.Since(start)
+				start = time.Now()
+				runtime.GC()
+				collecting += time.Since(start)
+			}
+			runtime.ReadMemStats(&after)
+			b.ReportMetric(float64(ticking.Nanoseconds())/float64(b.N), "tick-ns/op")
+			b.ReportMetric(float64(collecting.Nanoseconds())/float64(b.N), "gc-ns/op")
+			b.ReportMetric(float64(after.PauseTotalNs-before.PauseTotalNs)/float64(b.N), "gc-pause-ns/op")
+		})
+	}
+}
+
+func TestReset(t *testing.T) {
+	s := newTestSimulator(t, 3)
+	var ticks []int
+	s.AddTickListener(tickListenerFunc(func(tick int) { ticks = append(ticks, tick) }))
+	for range 5 {
+		s.Step()
+	}
+	fresh := newTestSimulator(t, 3)
+	first := fresh.GetAllPlants()
+
+	// Changes made during the run are undone.
+	if err := s.RemovePlant("tomato-1"); err != nil {
+		t.Fatalf("failed to remove plant: %v", err)
+	}
+	if err := s.AddPlant(testPlant(t, "tomato-9")); err != nil {
+		t.Fatalf("failed to add plant: %v", err)
+	}
+	if err := s.TransplantPlant("tomato-2", "section-B"); err != nil {
+		t.Fatalf("failed to transplant plant: %v", err)
+	}
+	if err := s.Reset(true); err != nil {
+		t.Fatalf("failed to reset: %v", err)
+	}
+	if tick := s.GetCurrentTick(); tick != 0 {
+		t.Errorf("expected tick 0 after a reset, got %d", tick)
+	}
+	plants := s.GetAllPlants()
+	if !reflect.DeepEqual(plantIDs(plants), plantIDs(first)) {
+		t.Fatalf("expected the plants %v back, got %v", plantIDs(first), plantIDs(plants))
+	}
+	for i, plant := range plants {
+		plant.CreatedAt = first[i].CreatedAt
+		if !reflect.DeepEqual(plant, first[i]) {
+			t.Errorf("expected %+v as the first tick found it, got %+v", first[i], plant)
+		}
+	}
+	if sections := s.ListSectionIDs(); !reflect.DeepEqual(sections, []string{"section-A"}) {
+		t.Errorf("expected the plants back in section-A only, got %v", sections)
+	}
+
+	// A reset run is the run of a new simulator.
+	for range 5 {
+		s.Step()
+		fresh.Step()
+	}
+	for i, plant := range s.GetAllPlants() {
+		if other := fresh.GetAllPlants()[i]; plant.SoilSaturation != other.SoilSaturation || plant.GrowthStage != other.GrowthStage {
+			t.Errorf("expected the reset run to match a new one, got %+v and %+v", plant, other)
+		}
+	}
+	if expected := []int{0, 1, 2, 3, 4, 0, 1, 2, 3, 4}; !reflect.DeepEqual(ticks, expected) {
+		t.Errorf("expected the kept listener to see ticks %v, got %v", expected, ticks)
+	}
+	if err := s.Reset(false); err != nil {
+		t.Fatalf("failed to reset: %v", err)
+	}
+	s.Step()
+	if len(ticks) != 10 {
+		t.Errorf("expected the listener to be forgotten, got ticks %v", ticks)
+	}
+}
+
+func TestReset_States(t *testing.T) {
+	tests := []struct {
+		state    State
+		expected error
+	}{
+		{Created, nil},
+		{Running, ErrResetWhileRunning},
+		{Paused, ErrResetWhileRunning},
+		{Stopped, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.state), func(t *testing.T) {
+			s, wait := simulatorIn(t, tt.state)
+			if err := s.Reset(true); !errors.Is(err, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, err)
+			}
+			if tt.expected != nil {
+				if err := s.Stop(); err != nil {
+					t.Fatalf("failed to stop: %v", err)
+				}
+				wait()
+				return
+			}
+			wait()
+			if state := s.State(); state != Created {
+				t.Errorf("expected a reset simulator to be created, got %s", state)
+			}
+			ended := make(chan error, 1)
+			go func() { ended <- s.Start() }()
+			waitForState(t, s, Running)
+			if err := s.Stop(); err != nil {
+				t.Fatalf("failed to stop: %v", err)
+			}
+			if err := <-ended; err != nil {
+				t.Errorf("expected the restarted loop to end without error, got %v", err)
+			}
+		})
+	}
+}
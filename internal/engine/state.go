@@ -0,0 +1,244 @@
+package engine
+
+import (
+	"errors"
+	"log"
+	"slices"
+	"sync"
+	"time"
+)
+
+// State is where a simulator is in its lifecycle. A simulator is Created,
+// Running once started, Paused and Running again as it is paused and
+// resumed, and Stopped for good once stopped.
+type State string
+
+const (
+	// Created is the state of a simulator that has not been started.
+	Created State = "created"
+	// Running is the state of a started simulator that runs ticks.
+	Running State = "running"
+	// Paused is the state of a started simulator that holds its ticks.
+	Paused State = "paused"
+	// Stopped is the state of a stopped simulator, which cannot be started
+	// again.
+	Stopped State = "stopped"
+)
+
+var (
+	// ErrNotStarted is returned when pausing or resuming a simulator that
+	// has not been started.
+	ErrNotStarted = errors.New("simulator is not started")
+	// ErrAlreadyStarted is returned when starting a simulator twice.
+	ErrAlreadyStarted = errors.New("simulator is already started")
+	// ErrAlreadyStopped is returned by every transition of a stopped
+	// simulator.
+	ErrAlreadyStopped = errors.New("simulator is already stopped")
+	// ErrResetWhileRunning is returned when resetting a running or paused
+	// simulator.
+	ErrResetWhileRunning = errors.New("simulator cannot be reset while running")
+)
+
+// StateChange is a transition of a simulator from one state to another.
+type StateChange struct {
+	From State
+	To   State
+}
+
+// StateListener is notified of every state change of a simulator, after the
+// change took effect. Listeners run on the goroutine that changed the state.
+type StateListener interface {
+	OnStateChange(change StateChange)
+}
+
+// Lifecycle is the state machine behind the Start, Pause, Resume and Stop of
+// a simulator. Run is the loop of Start: Pause and Resume wait for it to take
+// the request, so that no tick runs once Pause has returned, and Stop ends it,
+// paused or not. A simulator that was never started is stopped right away.
+type Lifecycle struct {
+	pause     chan struct{}
+	resume    chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+	state     State
+	listeners []StateListener
+	mu        sync.Mutex
+}
+
+// NewLifecycle returns the lifecycle of a Created simulator.
+func NewLifecycle() *Lifecycle {
+	return &Lifecycle{
+		pause:  make(chan struct{}),
+		resume: make(chan struct{}),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+		state:  Created,
+	}
+}
+
+// State returns the current state.
+// This method is safe for concurrent use.
+func (l *Lifecycle) State() State {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.state
+}
+
+// IsPaused reports whether the state is Paused.
+// This method is safe for concurrent use.
+func (l *Lifecycle) IsPaused() bool {
+	return l.State() == Paused
+}
+
+// AddStateListener registers a listener to be notified of every state change
+// from now on. Listeners are called in registration order.
+// This method is safe for concurrent use.
+func (l *Lifecycle) AddStateListener(listener StateListener) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.listeners = append(slices.Clip(l.listeners), listener)
+}
+
+// Run moves a Created simulator to Running and calls step on every value of
+// ticks until Stop is called, holding them while paused.
+// Returns ErrAlreadyStarted if the simulator is running or paused, and
+// ErrAlreadyStopped if it is stopped.
+func (l *Lifecycle) Run(ticks <-chan time.Time, step func()) error {
+	if _, err := l.transition(Running, Created); err != nil {
+		return err
+	}
+	defer close(l.done)
+	log.Println("Starting...")
+	for {
+		select {
+		case <-ticks:
+			step()
+		case <-l.pause:
+			log.Println("Pausing...")
+			select {
+			case <-l.resume:
+				log.Println("Resumed!")
+			case <-l.stop:
+				log.Println("Stopping...")
+				return nil
+			}
+		case <-l.stop:
+			log.Println("Stopping...")
+			return nil
+		}
+	}
+}
+
+// Pause moves a Running simulator to Paused and waits for its loop to hold
+// the ticks.
+// Pausing a paused simulator does nothing.
+// Returns ErrNotStarted if the simulator was never started, and
+// ErrAlreadyStopped if it is stopped.
+// This method is safe for concurrent use.
+func (l *Lifecycle) Pause() error {
+	from, err := l.transition(Paused, Running, Paused)
+	if err != nil || from == Paused {
+		return err
+	}
+	l.signal(l.pause)
+	return nil
+}
+
+// Resume moves a Paused simulator back to Running. Resuming a running
+// simulator does nothing but log a warning.
+// Returns ErrNotStarted if the simulator was never started, and
+// ErrAlreadyStopped if it is stopped.
+// This method is safe for concurrent use.
+func (l *Lifecycle) Resume() error {
+	from, err := l.transition(Running, Running, Paused)
+	if err != nil || from == Running {
+		return err
+	}
+	l.signal(l.resume)
+	return nil
+}
+
+// Stop moves the simulator to Stopped, ending the loop of Run if it was
+// started. Returns ErrAlreadyStopped if it is stopped already.
+// This method is safe for concurrent use.
+func (l *Lifecycle) Stop() error {
+	from, err := l.transition(Stopped, Created, Running, Paused)
+	if err != nil {
+		return err
+	}
+	if from != Created {
+		l.signal(l.stop)
+	}
+	return nil
+}
+
+// transition moves the simulator to state to if it is in one of the states
+// from, notifies the listeners and returns the state it was in. Moving to the
+// current state does nothing but log a warning.
+func (l *Lifecycle) transition(to State, from ...State) (State, error) {
+	l.mu.Lock()
+	current := l.state
+	if !slices.Contains(from, current) {
+		l.mu.Unlock()
+		switch current {
+		case Stopped:
+			return current, ErrAlreadyStopped
+		case Created:
+			return current, ErrNotStarted
+		}
+		return current, ErrAlreadyStarted
+	}
+	if current == to {
+		l.mu.Unlock()
+		log.Printf("Already %s, ignoring", to)
+		return current, nil
+	}
+	l.state = to
+	listeners := l.listeners
+	l.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener.OnStateChange(StateChange{From: current, To: to})
+	}
+	return current, nil
+}
+
+// rewind moves a Stopped lifecycle back to Created, so that Run can start it
+// again, and notifies the listeners; a Created one stays as it is. The state
+// listeners are forgotten first unless keepListeners.
+// Returns ErrResetWhileRunning if the simulator is running or paused.
+func (l *Lifecycle) rewind(keepListeners bool) error {
+	l.mu.Lock()
+	current := l.state
+	if current == Running || current == Paused {
+		l.mu.Unlock()
+		return ErrResetWhileRunning
+	}
+	if !keepListeners {
+		l.listeners = nil
+	}
+	if current == Created {
+		l.mu.Unlock()
+		return nil
+	}
+	l.pause = make(chan struct{})
+	l.resume = make(chan struct{})
+	l.stop = make(chan struct{})
+	l.done = make(chan struct{})
+	l.state = Created
+	listeners := l.listeners
+	l.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener.OnStateChange(StateChange{From: current, To: Created})
+	}
+	return nil
+}
+
+// signal hands a request to the loop of Run, unless it has ended.
+func (l *Lifecycle) signal(request chan struct{}) {
+	select {
+	case request <- struct{}{}:
+	case <-l.done:
+	}
+}
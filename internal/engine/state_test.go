@@ -0,0 +1,181 @@
+package engine
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stateRecorder records the state changes of a simulator.
+type stateRecorder struct {
+	changes []StateChange
+	mu      sync.Mutex
+}
+
+func (r *stateRecorder) OnStateChange(change StateChange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.changes = append(r.changes, change)
+}
+
+func (r *stateRecorder) Changes() []StateChange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.changes
+}
+
+// waitForState waits up to a second for s to be in state.
+func waitForState(t *testing.T, s Simulator, state State) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for s.State() != state {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for the %s state, got %s", state, s.State())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// simulatorIn returns a simulator that does not tick on its own, brought to
+// state, and a function waiting for its loop to end.
+func simulatorIn(t *testing.T, state State) (Simulator, func()) {
+	t.Helper()
+	s := NewSimulator(time.Hour)
+	ended := make(chan error, 1)
+	start := func() {
+		go func() { ended <- s.Start() }()
+		waitForState(t, s, Running)
+	}
+	wait := func() {
+		select {
+		case err := <-ended:
+			if err != nil {
+				t.Errorf("expected the loop to end without error, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Error("timed out waiting for the loop to end")
+		}
+	}
+	switch state {
+	case Created:
+		wait = func() {}
+	case Running:
+		start()
+	case Paused:
+		start()
+		if err := s.Pause(); err != nil {
+			t.Fatalf("failed to pause: %v", err)
+		}
+	case Stopped:
+		start()
+		if err := s.Stop(); err != nil {
+			t.Fatalf("failed to stop: %v", err)
+		}
+		wait()
+		wait = func() {}
+	}
+	return s, wait
+}
+
+func TestLifecycle_Transitions(t *testing.T) {
+	methods := map[string]func(Simulator) error{
+		"start": func(s Simulator) error {
+			// A successful start runs the loop until the simulator stops.
+			started := make(chan error, 1)
+			go func() { started <- s.Start() }()
+			select {
+			case err := <-started:
+				return err
+			case <-time.After(50 * time.Millisecond):
+				return nil
+			}
+		},
+		"pause":  Simulator.Pause,
+		"resume": Simulator.Resume,
+		"stop":   Simulator.Stop,
+	}
+	tests := []struct {
+		from     State
+		method   string
+		expected State
+		err      error
+	}{
+		{Created, "start", Running, nil},
+		{Created, "pause", Created, ErrNotStarted},
+		{Created, "resume", Created, ErrNotStarted},
+		{Created, "stop", Stopped, nil},
+		{Running, "start", Running, ErrAlreadyStarted},
+		{Running, "pause", Paused, nil},
+		{Running, "resume", Running, nil},
+		{Running, "stop", Stopped, nil},
+		{Paused, "start", Paused, ErrAlreadyStarted},
+		{Paused, "pause", Paused, nil},
+		{Paused, "resume", Running, nil},
+		{Paused, "stop", Stopped, nil},
+		{Stopped, "start", Stopped, ErrAlreadyStopped},
+		{Stopped, "pause", Stopped, ErrAlreadyStopped},
+		{Stopped, "resume", Stopped, ErrAlreadyStopped},
+		{Stopped, "stop", Stopped, ErrAlreadyStopped},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+" "+tt.method, func(t *testing.T) {
+			s, wait := simulatorIn(t, tt.from)
+			recorder := &stateRecorder{}
+			s.AddStateListener(recorder)
+
+			err := methods[tt.method](s)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected error %v, got %v", tt.err, err)
+			}
+			if got := s.State(); got != tt.expected {
+				t.Errorf("expected the %s state, got %s", tt.expected, got)
+			}
+			var expected []StateChange
+			if tt.expected != tt.from {
+				expected = []StateChange{{From: tt.from, To: tt.expected}}
+			}
+			if got := recorder.Changes(); !reflect.DeepEqual(got, expected) {
+				t.Errorf("expected the state changes %v, got %v", expected, got)
+			}
+
+			if state := s.State(); state != Stopped {
+				if err := s.Stop(); err != nil {
+					t.Fatalf("failed to stop: %v", err)
+				}
+				if state == Created {
+					return
+				}
+			}
+			wait()
+		})
+	}
+}
+
+func TestLifecycle_PauseHoldsTicks(t *testing.T) {
+	s := NewSimulator(time.Millisecond)
+	go s.Start()
+	defer s.Stop()
+	waitForState(t, s, Running)
+
+	if err := s.Pause(); err != nil {
+		t.Fatalf("failed to pause: %v", err)
+	}
+	tick := s.GetCurrentTick()
+	time.Sleep(20 * time.Millisecond)
+	if got := s.GetCurrentTick(); got != tick {
+		t.Errorf("expected no tick while paused, went from %d to %d", tick, got)
+	}
+	if err := s.Resume(); err != nil {
+		t.Fatalf("failed to resume: %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for s.GetCurrentTick() == tick {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for a tick after resuming")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,229 @@
+package engine
+
+import (
+	"greenhouse-simulator/internal/models"
+	"maps"
+	"slices"
+)
+
+// PlantStats sums up a set of plants: how many there are and are alive, and
+// the sums of their health, soil saturation and growth stage, dead plants
+// included. LimitingFactors counts the plants by the factor that cost them
+// the most health on the last tick, see models.Plant.LimitingFactor.
+type PlantStats struct {
+	Plants          int
+	AlivePlants     int
+	Health          float64
+	Saturation      float64
+	Growth          float64
+	LimitingFactors models.FactorCounts
+}
+
+// SumPlants sums up plants, in order.
+func SumPlants(plants []*models.Plant) PlantStats {
+	var stats PlantStats
+	for _, plant := range plants {
+		stats = stats.Plus(statsOf(plant))
+	}
+	return stats
+}
+
+// AverageHealth returns the average health of the plants, 0 without plants.
+func (s PlantStats) AverageHealth() float64 {
+	return s.average(s.Health)
+}
+
+// AverageSaturation returns the average soil saturation of the plants, 0
+// without plants.
+func (s PlantStats) AverageSaturation() float64 {
+	return s.average(s.Saturation)
+}
+
+// AverageGrowth returns the average growth stage of the plants, 0 without
+// plants.
+func (s PlantStats) AverageGrowth() float64 {
+	return s.average(s.Growth)
+}
+
+func (s PlantStats) average(sum float64) float64 {
+	if s.Plants == 0 {
+		return 0
+	}
+	return sum / float64(s.Plants)
+}
+
+// Plus sums up the plants of s and other together.
+func (s PlantStats) Plus(other PlantStats) PlantStats {
+	sum := PlantStats{
+		Plants:      s.Plants + other.Plants,
+		AlivePlants: s.AlivePlants + other.AlivePlants,
+		Health:      s.Health + other.Health,
+		Saturation:  s.Saturation + other.Saturation,
+		Growth:      s.Growth + other.Growth,
+	}
+	for i := range sum.LimitingFactors {
+		sum.LimitingFactors[i] = s.LimitingFactors[i] + other.LimitingFactors[i]
+	}
+	return sum
+}
+
+func (s PlantStats) minus(other PlantStats) PlantStats {
+	negated := PlantStats{
+		Plants:      -other.Plants,
+		AlivePlants: -other.AlivePlants,
+		Health:      -other.Health,
+		Saturation:  -other.Saturation,
+		Growth:      -other.Growth,
+	}
+	for i, n := range other.LimitingFactors {
+		negated.LimitingFactors[i] = -n
+	}
+	return s.Plus(negated)
+}
+
+// statsOf sums up a single plant.
+func statsOf(p *models.Plant) PlantStats {
+	stats := PlantStats{Plants: 1, Health: p.Health, Saturation: p.SoilSaturation, Growth: p.GrowthStage}
+	if p.Alive {
+		stats.AlivePlants = 1
+	}
+	stats.LimitingFactors.Add(p.LimitingFactor(), 1)
+	return stats
+}
+
+// plantShare is what the plant in a slot last added to the running sums of
+// its section, see simulator.account. A zero share was not added anywhere.
+type plantShare struct {
+	sectionID string
+	stats     PlantStats
+}
+
+// SectionStats sums up the plants of a section, see PlantStats. Reports
+// false if the section has no plants.
+//
+// The simulator keeps running sums for every section, which it adjusts by
+// the change of each plant as it updates, adds, removes, moves, prunes and
+// waters it, so that reading them does not go over the plants. Plants handed
+// out as pointers, by GetAllPlants and GetPlantsBySectionID, may be changed
+// by their holder though, such as by the tick listeners that water them: the
+// sections they belong to are dirty until the end of the tick, and summed up
+// from their plants when read in the meantime. The end of every tick folds
+// the changes to the plants of dirty sections into the sums.
+// This method is safe for concurrent use.
+func (s *simulator) SectionStats(sectionID string) (PlantStats, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	slots, ok := s.plantsBySectionID[sectionID]
+	if !ok {
+		return PlantStats{}, false
+	}
+	if s.isDirty(sectionID) {
+		return SumPlants(s.livePlants(slots)), true
+	}
+	return s.sectionStats[sectionID], true
+}
+
+// PlantStats sums up every plant from the sums of their sections, in section
+// order, see SectionStats.
+// This method is safe for concurrent use.
+func (s *simulator) PlantStats() PlantStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var total PlantStats
+	for _, sectionID := range slices.Sorted(maps.Keys(s.plantsBySectionID)) {
+		if s.isDirty(sectionID) {
+			total = total.Plus(SumPlants(s.livePlants(s.plantsBySectionID[sectionID])))
+			continue
+		}
+		total = total.Plus(s.sectionStats[sectionID])
+	}
+	return total
+}
+
+// account replaces what the plant in slot last added to the sums of its
+// section with its current state, moving it to another section if it was
+// transplanted since. The caller must hold s.mu.
+func (s *simulator) account(slot int32) {
+	if n := int(slot) + 1 - len(s.shares); n > 0 {
+		s.shares = append(s.shares, make([]plantShare, n)...)
+	}
+	s.unaccount(slot)
+	plant := s.store.at(slot)
+	share := plantShare{sectionID: plant.SectionID, stats: statsOf(plant)}
+	s.sectionStats[share.sectionID] = s.sectionStats[share.sectionID].Plus(share.stats)
+	s.shares[slot] = share
+}
+
+// unaccount takes what the plant in slot last added to the sums of its
+// section back out. The sums of a section left without plants are dropped
+// rather than kept at whatever rounding left in them. The caller must hold
+// s.mu.
+func (s *simulator) unaccount(slot int32) {
+	if int(slot) >= len(s.shares) || s.shares[slot].stats.Plants == 0 {
+		return
+	}
+	share := s.shares[slot]
+	stats := s.sectionStats[share.sectionID].minus(share.stats)
+	if stats.Plants == 0 {
+		delete(s.sectionStats, share.sectionID)
+	} else {
+		s.sectionStats[share.sectionID] = stats
+	}
+	s.shares[slot] = plantShare{}
+}
+
+// markDirty records that the plants of a section were handed out, or those
+// of every section with an empty sectionID, see SectionStats.
+// This method is safe for concurrent use.
+func (s *simulator) markDirty(sectionID string) {
+	s.dirtyMu.Lock()
+	defer s.dirtyMu.Unlock()
+	if sectionID == "" {
+		s.allDirty = true
+		return
+	}
+	if s.dirty == nil {
+		s.dirty = map[string]bool{}
+	}
+	s.dirty[sectionID] = true
+}
+
+// isDirty reports whether the plants of a section were handed out since the
+// last settleStats.
+// This method is safe for concurrent use.
+func (s *simulator) isDirty(sectionID string) bool {
+	s.dirtyMu.Lock()
+	defer s.dirtyMu.Unlock()
+	return s.allDirty || s.dirty[sectionID]
+}
+
+// settleStats folds the changes to the plants of the dirty sections into the
+// running sums, and marks every section clean. The caller must hold s.mu.
+func (s *simulator) settleStats() {
+	s.dirtyMu.Lock()
+	all, dirty := s.allDirty, s.dirty
+	s.allDirty, s.dirty = false, nil
+	s.dirtyMu.Unlock()
+	if all {
+		for _, slot := range s.plants {
+			s.account(slot)
+		}
+		return
+	}
+	for sectionID := range dirty {
+		for _, slot := range s.plantsBySectionID[sectionID] {
+			s.account(slot)
+		}
+	}
+}
+
+// recomputeStats sums up the plants of every section afresh, for the
+// invariant checks to compare the running sums with. The caller must hold
+// s.mu.
+func (s *simulator) recomputeStats() map[string]PlantStats {
+	stats := make(map[string]PlantStats, len(s.plantsBySectionID))
+	for sectionID, slots := range s.plantsBySectionID {
+		stats[sectionID] = SumPlants(s.livePlants(slots))
+	}
+	return stats
+}
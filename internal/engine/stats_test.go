@@ -0,0 +1,175 @@
+package engine
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
+
+// checkStats compares the running sums of s, as the end of the last tick
+// settled them, with the sums of its plants recomputed from scratch, and the
+// stats read through the Simulator with both.
+func checkStats(t *testing.T, s Simulator, tick int) {
+	t.Helper()
+	sim := s.(*simulator)
+	sim.mu.Lock()
+	sim.settleStats()
+	running, recomputed := maps.Clone(sim.sectionStats), sim.recomputeStats()
+	sim.mu.Unlock()
+
+	if len(running) != len(recomputed) {
+		t.Fatalf("tick %d: expected sums for the sections %v, got %v", tick, slices.Sorted(maps.Keys(recomputed)), slices.Sorted(maps.Keys(running)))
+	}
+	var total PlantStats
+	for _, sectionID := range s.ListSectionIDs() {
+		if !statsClose(running[sectionID], recomputed[sectionID]) {
+			t.Fatalf("tick %d: expected the sums of %s to be %+v, got %+v", tick, sectionID, recomputed[sectionID], running[sectionID])
+		}
+		if stats, ok := s.SectionStats(sectionID); !ok || stats != running[sectionID] {
+			t.Fatalf("tick %d: expected the stats of %s to be the running sums %+v, got %+v", tick, sectionID, running[sectionID], stats)
+		}
+		total = total.Plus(recomputed[sectionID])
+	}
+	if stats := s.PlantStats(); !statsClose(stats, total) {
+		t.Fatalf("tick %d: expected the stats of the greenhouse to be %+v, got %+v", tick, total, stats)
+	}
+}
+
+// statsClose reports whether two sums count the same plants and add up to
+// the same values up to their rounding.
+func statsClose(a, b PlantStats) bool {
+	return a.Plants == b.Plants && a.AlivePlants == b.AlivePlants &&
+		math.Abs(a.Health-b.Health) < 1e-9 && math.Abs(a.Saturation-b.Saturation) < 1e-9 && math.Abs(a.Growth-b.Growth) < 1e-9
+}
+
+// TestPlantStats_RandomMutations adds, removes, moves, prunes, thins and
+// waters plants at random for 1000 ticks, through the simulator and through
+// the pointers it hands out to tick listeners, and checks the running sums
+// against the plants every 50 ticks, with the invariant checks comparing
+// them on every tick besides.
+func TestPlantStats_RandomMutations(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	sections := []string{"section-A", "section-B", "section-C", "section-D"}
+	s := NewSimulator(time.Hour, WithInvariantChecks(func(violations []InvariantViolation) {
+		t.Fatalf("unexpected violations: %v", violations)
+	}))
+	ids := []string{}
+	added := 0
+	addPlant := func() {
+		plant := testPlant(t, fmt.Sprintf("plant-%d", added))
+		plant.SectionID = sections[random.IntN(len(sections))]
+		plant.SoilSaturation = random.Float64()
+		added++
+		if err := s.AddPlant(plant); err != nil {
+			t.Fatalf("failed to add plant: %v", err)
+		}
+		ids = append(ids, plant.ID)
+	}
+	forget := func(removed ...string) {
+		for _, id := range removed {
+			for i, other := range ids {
+				if other == id {
+					ids = append(ids[:i], ids[i+1:]...)
+					break
+				}
+			}
+		}
+	}
+	for range 40 {
+		addPlant()
+	}
+	// Tick listeners water and stress the plants through their pointers, as
+	// the watering controller and the weather do.
+	s.AddTickListener(tickListenerFunc(func(int) {
+		plants := s.GetPlantsBySectionID(sections[random.IntN(len(sections))])
+		for _, plant := range plants {
+			if random.IntN(3) == 0 {
+				plant.AddWater(random.Float64() / 4)
+			}
+		}
+	}))
+	if err := s.AddTickHook("water", func(ctx TickContext) error {
+		if len(ids) > 0 {
+			return ctx.WaterPlant(ids[random.IntN(len(ids))], 0.1)
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("failed to add tick hook: %v", err)
+	}
+
+	for tick := range 1000 {
+		switch op := random.IntN(10); {
+		case op < 3:
+			addPlant()
+		case op < 5 && len(ids) > 0:
+			id := ids[random.IntN(len(ids))]
+			if err := s.RemovePlant(id); err != nil {
+				t.Fatalf("failed to remove plant: %v", err)
+			}
+			forget(id)
+		case op < 7 && len(ids) > 0:
+			if err := s.TransplantPlant(ids[random.IntN(len(ids))], sections[random.IntN(len(sections))]); err != nil {
+				t.Fatalf("failed to transplant plant: %v", err)
+			}
+		case op < 8 && len(ids) > 0:
+			// Seedlings and dead plants cannot be pruned.
+			s.PrunePlant(ids[random.IntN(len(ids))], 0.2)
+		case op < 9:
+			removed, err := s.ThinSection(sections[random.IntN(len(sections))], 8)
+			if err != nil {
+				t.Fatalf("failed to thin section: %v", err)
+			}
+			forget(removed...)
+		case len(ids) > 0:
+			// A plant stressed to death between ticks.
+			plant := s.GetPlantsBySectionID(sections[random.IntN(len(sections))])
+			if len(plant) > 0 {
+				plant[0].Health, plant[0].Alive = 0, false
+			}
+		}
+		s.Step()
+		if tick%50 == 49 {
+			checkStats(t, s, tick)
+		}
+	}
+}
+
+func TestPlantStats(t *testing.T) {
+	s := newTestSimulator(t, 2)
+	moved := testPlant(t, "basil-0")
+	moved.SectionID = "section-B"
+	moved.Health = 0.5
+	if err := s.AddPlant(moved); err != nil {
+		t.Fatalf("failed to add plant: %v", err)
+	}
+
+	stats, ok := s.SectionStats("section-A")
+	if !ok || stats.Plants != 2 || stats.AlivePlants != 2 || stats.Health != 2 || stats.AverageSaturation() != 0.6 {
+		t.Errorf("expected two healthy plants at 0.6, got %+v", stats)
+	}
+	if _, ok := s.SectionStats("section-Z"); ok {
+		t.Error("expected no stats for a section without plants")
+	}
+	if err := s.TransplantPlant("basil-0", "section-A"); err != nil {
+		t.Fatalf("failed to transplant plant: %v", err)
+	}
+	if _, ok := s.SectionStats("section-B"); ok {
+		t.Error("expected the sums of an emptied section to be dropped")
+	}
+	if stats := s.PlantStats(); stats.Plants != 3 || stats.AverageHealth() != 2.5/3 {
+		t.Errorf("expected the average health of three plants, got %+v", stats)
+	}
+	if err := s.RemovePlant("basil-0"); err != nil {
+		t.Fatalf("failed to remove plant: %v", err)
+	}
+	if stats := s.PlantStats(); stats.Plants != 2 || stats.Health != 2 {
+		t.Errorf("expected two healthy plants left, got %+v", stats)
+	}
+	if stats := (PlantStats{}); stats.AverageHealth() != 0 || stats.AverageGrowth() != 0 {
+		t.Errorf("expected the averages of no plants to be 0, got %+v", stats)
+	}
+}
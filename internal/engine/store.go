@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"greenhouse-simulator/internal/models"
+	"math/bits"
+)
+
+// firstBlock is the number of plants in the first block of a plantStore;
+// every next block holds twice as many as the one before.
+const firstBlock = 64
+
+// plantStore keeps the plants of a simulator by value, in blocks that double
+// in size, and hands out slots to address them by. A large greenhouse is
+// then a few dozen objects for the garbage collector to track rather than
+// one per plant, a small one does not pay for room it does not use, and the
+// indexes of the simulator hold slots rather than pointers for it to scan.
+//
+// A slot never moves, so pointers to the plant in it stay valid while the
+// plant is in the simulator. The slots of removed plants are reused by the
+// plants added next: pointers to a removed plant must not be kept past the
+// next AddPlant.
+type plantStore struct {
+	blocks [][]models.Plant
+	used   int32   // slots handed out so far, free ones included
+	free   []int32 // slots of removed plants, reused last in first out
+}
+
+// add copies p into a free slot and returns the slot.
+func (st *plantStore) add(p *models.Plant) int32 {
+	var slot int32
+	if n := len(st.free); n > 0 {
+		slot = st.free[n-1]
+		st.free = st.free[:n-1]
+	} else {
+		slot = st.used
+		st.used++
+		if block, _ := locate(slot); block == len(st.blocks) {
+			st.blocks = append(st.blocks, make([]models.Plant, firstBlock<<block))
+		}
+	}
+	*st.at(slot) = *p
+	return slot
+}
+
+// at returns the plant in slot.
+func (st *plantStore) at(slot int32) *models.Plant {
+	block, offset := locate(slot)
+	return &st.blocks[block][offset]
+}
+
+// locate returns the block of slot and its offset in the block. Block k
+// starts at slot firstBlock * (2^k - 1).
+func locate(slot int32) (block, offset int) {
+	block = bits.Len32(uint32(slot)/firstBlock+1) - 1
+	return block, int(slot) - firstBlock*(1<<block-1)
+}
+
+// release frees slot for the plants added next. The plant stays in it until
+// then, so that callers can still read what they just removed.
+func (st *plantStore) release(slot int32) {
+	st.free = append(st.free, slot)
+}
+
+// reset frees every slot, keeping the blocks for the plants added next, which
+// fill the slots in order as in a new store.
+func (st *plantStore) reset() {
+	st.used = 0
+	st.free = st.free[:0]
+}
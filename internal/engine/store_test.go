@@ -0,0 +1,46 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLocate(t *testing.T) {
+	tests := []struct {
+		slot          int32
+		block, offset int
+	}{
+		{0, 0, 0},
+		{63, 0, 63},
+		{64, 1, 0},
+		{191, 1, 127},
+		{192, 2, 0},
+		{448, 3, 0},
+	}
+	for _, tt := range tests {
+		if block, offset := locate(tt.slot); block != tt.block || offset != tt.offset {
+			t.Errorf("expected slot %d at %d/%d, got %d/%d", tt.slot, tt.block, tt.offset, block, offset)
+		}
+	}
+}
+
+func TestPlantStore(t *testing.T) {
+	var st plantStore
+	first := st.at(st.add(testPlant(t, "tomato-0")))
+	for i := 1; i < 1000; i++ {
+		if slot := st.add(testPlant(t, fmt.Sprintf("tomato-%d", i))); st.at(slot).ID != fmt.Sprintf("tomato-%d", i) {
+			t.Fatalf("expected tomato-%d in slot %d, got %s", i, slot, st.at(slot).ID)
+		}
+	}
+	if first != st.at(0) || first.ID != "tomato-0" {
+		t.Errorf("expected the first plant to stay in place as the store grew, got %s", st.at(0).ID)
+	}
+
+	st.release(10)
+	if st.at(10).ID != "tomato-10" {
+		t.Errorf("expected a released plant to stay readable, got %s", st.at(10).ID)
+	}
+	if slot := st.add(testPlant(t, "basil-1")); slot != 10 || st.used != 1000 {
+		t.Errorf("expected the released slot to be reused, got slot %d of %d", slot, st.used)
+	}
+}
@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Span and attribute names of tick traces.
+const (
+	TickSpan         = "tick"
+	PlantUpdatePhase = "plants.update"
+	ListenerPhase    = "listener"
+	HookPhase        = "tick.hooks"
+	TickAttribute    = "greenhouse.tick"
+	PlantsAttribute  = "greenhouse.plants"
+)
+
+// PhaseNamer is implemented by tick listeners that name their phase in tick
+// traces. Other listeners show up as ListenerPhase.
+type PhaseNamer interface {
+	TickPhase() string
+}
+
+// TickTrace traces one tick: a TickSpan with a child span per phase, the
+// plant updates, the tick hooks if there are any and then each tick listener. The tick span carries the tick
+// number, the plant count and the duration of every phase in milliseconds.
+// Every method of a nil TickTrace does nothing, so that simulators without a
+// tracer pay nothing for tracing.
+type TickTrace struct {
+	tracer     trace.Tracer
+	ctx        context.Context
+	span       trace.Span
+	attributes []attribute.KeyValue
+}
+
+// StartTick starts the trace of a tick, or returns nil when tracer is nil.
+func StartTick(tracer trace.Tracer, tick int) *TickTrace {
+	if tracer == nil {
+		return nil
+	}
+	ctx, span := tracer.Start(context.Background(), TickSpan, trace.WithAttributes(attribute.Int(TickAttribute, tick)))
+	return &TickTrace{tracer: tracer, ctx: ctx, span: span}
+}
+
+// Context returns a context carrying the tick span, for spans started on
+// behalf of the tick elsewhere, and context.Background() without a trace.
+func (t *TickTrace) Context() context.Context {
+	if t == nil {
+		return context.Background()
+	}
+	return t.ctx
+}
+
+// noop ends the phases of a nil TickTrace.
+var noop = func() {}
+
+// Phase starts the span of a phase and returns the function ending it, which
+// also records the phase duration on the tick span.
+func (t *TickTrace) Phase(name string) (end func()) {
+	if t == nil {
+		return noop
+	}
+	_, span := t.tracer.Start(t.ctx, name)
+	start := time.Now()
+	return func() {
+		span.End()
+		t.attributes = append(t.attributes, attribute.Float64(
+			PhaseDurationAttribute(name), float64(time.Since(start))/float64(time.Millisecond)))
+	}
+}
+
+// Notify calls l with the tick, in a phase named after l.
+func (t *TickTrace) Notify(l TickListener, tick int) {
+	if t == nil {
+		l.OnTick(tick)
+		return
+	}
+	name := ListenerPhase
+	if namer, ok := l.(PhaseNamer); ok {
+		name = namer.TickPhase()
+	}
+	end := t.Phase(name)
+	defer end()
+	l.OnTick(tick)
+}
+
+// End ends the tick span, recording the plant count and the phase durations.
+func (t *TickTrace) End(plants int) {
+	if t == nil {
+		return
+	}
+	t.span.SetAttributes(attribute.Int(PlantsAttribute, plants))
+	t.span.SetAttributes(t.attributes...)
+	t.span.End()
+}
+
+// PhaseDurationAttribute returns the tick span attribute holding the duration
+// of a phase.
+func PhaseDurationAttribute(phase string) string {
+	return "greenhouse.phase." + phase + ".duration_ms"
+}
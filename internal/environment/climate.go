@@ -0,0 +1,228 @@
+package environment
+
+import (
+	"cmp"
+	"errors"
+	"greenhouse-simulator/internal/rng"
+	"math"
+)
+
+// Weather is the weather of a simulated day.
+type Weather string
+
+const (
+	Clear  Weather = "clear"
+	Cloudy Weather = "cloudy"
+	Rain   Weather = "rain"
+)
+
+// Conditions are the greenhouse-wide air conditions at a tick.
+type Conditions struct {
+	// Temperature in Celsius.
+	Temperature float64
+	// Humidity is the relative air humidity, 0.0 to 1.0.
+	Humidity float64
+	// Light is the light intensity, 0.0 (dark) to 1.0 (full sun).
+	Light   float64
+	Weather Weather
+	// Extreme is the extreme weather going on, empty when there is none.
+	Extreme Extreme
+	// Evaporation is how many times faster than normal the soil dries out.
+	Evaporation float64
+	// CO2 is the CO2 level in ppm, 0 without a CO2 model, see CO2. The
+	// climate leaves it at 0.
+	CO2 float64
+	// Salinity is the soil salinity of a section, 0.0 to 1.0, see Salinity;
+	// 0 in the greenhouse-wide conditions.
+	Salinity float64
+	// SoilTemperature is the root-zone temperature of a section in Celsius,
+	// see SoilTemperature; the air Temperature in the greenhouse-wide
+	// conditions and without soil temperature settings.
+	SoilTemperature float64
+	// WaterTemperature is the temperature of the irrigation water in
+	// Celsius, see WaterTemperature; set for a section only.
+	WaterTemperature float64
+	// Season and DayOfYear place the tick in the simulated year, see
+	// Climate.Season and Climate.DayOfYear; empty and 0 without seasons.
+	Season    Season
+	DayOfYear int
+}
+
+// Climate models the temperature, humidity, light and weather outside the
+// plants' own influence. It is a pure function of the tick and the seed, so
+// two climates with the same parameters report the same conditions.
+//
+// Temperature follows a daily curve around the baseline, lowest at 03:00 and
+// highest at 15:00, and drifts by SeasonalDrift per simulated day. Light rises
+// at sunrise, 06:00, peaks at noon and is gone by sunset, 18:00. Each day's
+// weather is drawn from the seed: cloudy days halve the light, rainy days cut
+// it to a third, cool the air by 2 degrees and add 0.2 to the humidity.
+// Seasons move the daily mean temperature, the day length around noon and
+// the weather chances over the year, see Seasons. Without a day cycle the
+// conditions stay at the baseline.
+//
+// On top of that, a day may start with a frost or a heat wave, see
+// ExtremeOn, lasting ExtremeTicks ticks. A frost holds the temperature at
+// or below FrostTemperature and damages the plants that are not frost
+// tolerant by FrostDamage per tick, 0.1 when zero. A heat wave raises the
+// temperature by HeatWaveRise, 8 degrees when zero, and dries the soil out
+// HeatWaveEvaporation times as fast, twice when zero.
+type Climate struct {
+	DayCycle DayCycle
+	Seed     int64
+	// Temperature is the baseline daily mean in Celsius.
+	Temperature float64
+	// TemperatureSwing is how far the temperature moves above and below the
+	// daily mean.
+	TemperatureSwing float64
+	// Humidity is the baseline relative humidity.
+	Humidity float64
+	// Light is the midday light intensity on a clear day.
+	Light float64
+	// SeasonalDrift is the change of the daily mean temperature per day.
+	SeasonalDrift float64
+	Seasons       Seasons
+	// CloudyChance and RainChance are the probabilities of a day being
+	// cloudy or rainy; the remaining days are clear.
+	CloudyChance float64
+	RainChance   float64
+	// FrostChance and HeatWaveChance are the probabilities of a day starting
+	// with a frost or a heat wave.
+	FrostChance         float64
+	HeatWaveChance      float64
+	ExtremeTicks        int
+	FrostTemperature    float64
+	FrostDamage         float64
+	HeatWaveRise        float64
+	HeatWaveEvaporation float64
+}
+
+// Validate checks the climate parameters. Returns an error if:
+// - the humidity or light is outside 0.0-1.0
+// - the temperature swing is negative
+// - a weather chance is outside 0.0-1.0 or the chances add up to more than 1.0
+// - an extreme weather chance is outside 0.0-1.0, the chances add up to more
+// than 1.0 or they are set without a positive ExtremeTicks
+// - the frost damage is outside 0.0-1.0, the heat wave rise is negative or
+// the heat wave evaporation is below 1.0 without being zero
+// - weather, extremes or seasonal drift are configured without a day cycle
+// - the seasons are invalid, see Seasons
+func (c Climate) Validate() error {
+	if c.Humidity < 0 || c.Humidity > 1 {
+		return errors.New("ambient humidity must be between 0.0 and 1.0")
+	}
+	if c.Light < 0 || c.Light > 1 {
+		return errors.New("light must be between 0.0 and 1.0")
+	}
+	if c.TemperatureSwing < 0 {
+		return errors.New("temperature swing cannot be negative")
+	}
+	if c.CloudyChance < 0 || c.CloudyChance > 1 {
+		return errors.New("cloudy chance must be between 0.0 and 1.0")
+	}
+	if c.RainChance < 0 || c.RainChance > 1 {
+		return errors.New("rain chance must be between 0.0 and 1.0")
+	}
+	if c.CloudyChance+c.RainChance > 1 {
+		return errors.New("cloudy and rain chances cannot add up to more than 1.0")
+	}
+	if c.FrostChance < 0 || c.FrostChance > 1 || c.HeatWaveChance < 0 || c.HeatWaveChance > 1 {
+		return errors.New("frost and heat wave chances must be between 0.0 and 1.0")
+	}
+	if c.FrostChance+c.HeatWaveChance > 1 {
+		return errors.New("frost and heat wave chances cannot add up to more than 1.0")
+	}
+	if c.ExtremeTicks < 0 || (c.ExtremeTicks == 0 && c.FrostChance+c.HeatWaveChance > 0) {
+		return errors.New("frost and heat wave chances need extreme ticks of at least 1")
+	}
+	if c.FrostDamage < 0 || c.FrostDamage > 1 {
+		return errors.New("frost damage must be between 0.0 and 1.0")
+	}
+	if c.HeatWaveRise < 0 {
+		return errors.New("heat wave rise cannot be negative")
+	}
+	if c.HeatWaveEvaporation != 0 && c.HeatWaveEvaporation < 1 {
+		return errors.New("heat wave evaporation must be at least 1.0")
+	}
+	if !c.DayCycle.Enabled() && (c.CloudyChance > 0 || c.RainChance > 0 || c.FrostChance > 0 || c.HeatWaveChance > 0 || c.SeasonalDrift != 0) {
+		return errors.New("weather and seasonal drift need a day length of at least 1 tick")
+	}
+	return c.Seasons.validate(c.DayCycle)
+}
+
+// At returns the conditions at the given tick, with the extreme weather
+// drawn for it.
+func (c Climate) At(tick int) Conditions {
+	return c.With(tick, c.ExtremeAt(tick))
+}
+
+// With returns the conditions at the given tick during the given extreme,
+// or without one when it is empty, whatever extreme was drawn for the tick.
+func (c Climate) With(tick int, extreme Extreme) Conditions {
+	conditions := Conditions{
+		Temperature: c.Temperature,
+		Humidity:    c.Humidity,
+		Light:       c.Light,
+		Weather:     Clear,
+		Evaporation: 1,
+	}
+	if c.DayCycle.Enabled() {
+		c.applyDay(tick, &conditions)
+	}
+	switch extreme {
+	case Frost:
+		conditions.Temperature = math.Min(conditions.Temperature, c.FrostTemperature)
+	case HeatWave:
+		conditions.Temperature += cmp.Or(c.HeatWaveRise, 8)
+		conditions.Evaporation = cmp.Or(c.HeatWaveEvaporation, 2)
+	}
+	conditions.Extreme = extreme
+	conditions.Season = c.Season(tick)
+	conditions.DayOfYear = c.DayOfYear(tick)
+	return conditions
+}
+
+// applyDay applies the time of day, the season and the weather.
+func (c Climate) applyDay(tick int, conditions *Conditions) {
+	timeOfDay := c.DayCycle.TimeOfDay(tick)
+	day := c.DayCycle.Day(tick)
+	conditions.Temperature += c.SeasonalDrift*float64(day) + c.Seasons.TemperatureAmplitude*c.seasonal(day) -
+		c.TemperatureSwing*math.Cos(2*math.Pi*(timeOfDay-0.125))
+	dayLength := c.DayLength(tick)
+	if daylight := timeOfDay - (0.5 - dayLength/2); daylight > 0 && daylight < dayLength {
+		conditions.Light *= math.Sin(math.Pi * daylight / dayLength)
+	} else {
+		conditions.Light = 0
+	}
+
+	conditions.Weather = c.WeatherOn(day)
+	switch conditions.Weather {
+	case Cloudy:
+		conditions.Light /= 2
+	case Rain:
+		conditions.Light /= 3
+		conditions.Temperature -= 2
+		conditions.Humidity = math.Min(1, conditions.Humidity+0.2)
+	}
+}
+
+// WeatherOn returns the weather of a zero-based simulated day. The draw comes
+// from the seed's rng.Weather stream, split by day, so it does not change
+// with the order in which days are asked for. With seasons, the cloudy and
+// rain chances are scaled for the day, and scaled down together should they
+// add up to more than 1.0.
+func (c Climate) WeatherOn(day int) Weather {
+	draw := rng.New(c.Seed).Split(rng.Weather).SplitN(day).Float64()
+	scale := 1 - c.Seasons.WeatherAmplitude*c.seasonal(day)
+	rain, cloudy := c.RainChance*scale, c.CloudyChance*scale
+	if total := rain + cloudy; total > 1 {
+		rain, cloudy = rain/total, cloudy/total
+	}
+	switch {
+	case draw < rain:
+		return Rain
+	case draw < rain+cloudy:
+		return Cloudy
+	}
+	return Clear
+}
@@ -0,0 +1,145 @@
+package environment
+
+import (
+	"math"
+	"testing"
+)
+
+func TestClimate_At(t *testing.T) {
+	climate := Climate{
+		DayCycle:         DayCycle{TicksPerDay: 24},
+		Temperature:      20,
+		TemperatureSwing: 5,
+		Humidity:         0.5,
+		Light:            0.8,
+		SeasonalDrift:    1,
+	}
+
+	tests := []struct {
+		tick                int
+		expectedTemperature float64
+		expectedLight       float64
+	}{
+		{3, 15, 0}, // 03:00, the coldest hour of day 0
+		{12, 20 + 5/math.Sqrt2, 0.8},
+		{15, 25, 0.8 / math.Sqrt2},
+		{27, 16, 0}, // 03:00 on day 1, one degree of drift later
+		{39, 26, 0.8 / math.Sqrt2},
+	}
+
+	for _, tt := range tests {
+		got := climate.At(tt.tick)
+		if math.Abs(got.Temperature-tt.expectedTemperature) > 1e-9 {
+			t.Errorf("tick %d: expected temperature %.2f, got %.2f", tt.tick, tt.expectedTemperature, got.Temperature)
+		}
+		if math.Abs(got.Light-tt.expectedLight) > 1e-9 {
+			t.Errorf("tick %d: expected light %.2f, got %.2f", tt.tick, tt.expectedLight, got.Light)
+		}
+		if got.Humidity != 0.5 || got.Weather != Clear {
+			t.Errorf("tick %d: expected clear weather at the baseline humidity, got %+v", tt.tick, got)
+		}
+	}
+}
+
+func TestClimate_WithoutDayCycle(t *testing.T) {
+	climate := Climate{Temperature: 18, TemperatureSwing: 4, Humidity: 0.6, Light: 0.7}
+	expected := Conditions{Temperature: 18, Humidity: 0.6, Light: 0.7, Weather: Clear, Evaporation: 1}
+	if got := climate.At(100); got != expected {
+		t.Errorf("expected baseline conditions %+v, got %+v", expected, got)
+	}
+}
+
+func TestClimate_Weather(t *testing.T) {
+	climate := Climate{DayCycle: DayCycle{TicksPerDay: 10}, Seed: 7, Humidity: 0.7, Light: 0.9, CloudyChance: 0.3, RainChance: 0.2}
+
+	counts := map[Weather]int{}
+	for day := range 1000 {
+		counts[climate.WeatherOn(day)]++
+	}
+	for weather, expected := range map[Weather]int{Clear: 500, Cloudy: 300, Rain: 200} {
+		if math.Abs(float64(counts[weather]-expected)) > 60 {
+			t.Errorf("expected about %d %s days, got %d", expected, weather, counts[weather])
+		}
+	}
+
+	same := climate
+	other := climate
+	other.Seed = 8
+	differs := false
+	for tick := range 500 {
+		if climate.At(tick) != same.At(tick) {
+			t.Fatalf("tick %d: expected the same seed to give the same conditions", tick)
+		}
+		differs = differs || climate.At(tick) != other.At(tick)
+	}
+	if !differs {
+		t.Error("expected another seed to give different weather")
+	}
+
+	for day := range 100 {
+		if climate.WeatherOn(day) == Rain {
+			noon := climate.At(day*10 + 5)
+			if math.Abs(noon.Humidity-0.9) > 1e-9 || math.Abs(noon.Light-0.3) > 1e-9 {
+				t.Errorf("expected a rainy noon to be humid and dim, got %+v", noon)
+			}
+			return
+		}
+	}
+	t.Error("expected a rainy day within 100 days")
+}
+
+func TestClimate_Validate(t *testing.T) {
+	valid := Climate{DayCycle: DayCycle{TicksPerDay: 24}, Humidity: 0.5, Light: 1, CloudyChance: 0.5, RainChance: 0.5}
+
+	tests := []struct {
+		name     string
+		modify   func(c *Climate)
+		errorMsg string
+	}{
+		{"valid", func(c *Climate) {}, ""},
+		{"humidity above 1", func(c *Climate) { c.Humidity = 1.2 }, "ambient humidity must be between 0.0 and 1.0"},
+		{"negative light", func(c *Climate) { c.Light = -0.1 }, "light must be between 0.0 and 1.0"},
+		{"negative swing", func(c *Climate) { c.TemperatureSwing = -1 }, "temperature swing cannot be negative"},
+		{"cloudy chance above 1", func(c *Climate) { c.CloudyChance = 1.5 }, "cloudy chance must be between 0.0 and 1.0"},
+		{"negative rain chance", func(c *Climate) { c.RainChance = -0.5 }, "rain chance must be between 0.0 and 1.0"},
+		{"chances above 1", func(c *Climate) { c.RainChance = 0.6 }, "cloudy and rain chances cannot add up to more than 1.0"},
+		{"weather without day cycle", func(c *Climate) { c.DayCycle = DayCycle{} }, "weather and seasonal drift need a day length of at least 1 tick"},
+		{"frost chance above 1", func(c *Climate) { c.FrostChance, c.ExtremeTicks = 1.5, 10 }, "frost and heat wave chances must be between 0.0 and 1.0"},
+		{"extreme chances above 1", func(c *Climate) { c.FrostChance, c.HeatWaveChance, c.ExtremeTicks = 0.6, 0.6, 10 }, "frost and heat wave chances cannot add up to more than 1.0"},
+		{"extremes without ticks", func(c *Climate) { c.HeatWaveChance = 0.1 }, "frost and heat wave chances need extreme ticks of at least 1"},
+		{"frost damage above 1", func(c *Climate) { c.FrostDamage = 2 }, "frost damage must be between 0.0 and 1.0"},
+		{"negative heat wave rise", func(c *Climate) { c.HeatWaveRise = -1 }, "heat wave rise cannot be negative"},
+		{"heat wave evaporation below 1", func(c *Climate) { c.HeatWaveEvaporation = 0.5 }, "heat wave evaporation must be at least 1.0"},
+		{"seasons", func(c *Climate) {
+			c.Seasons = Seasons{TicksPerYear: 24 * 360, Phase: 0.5, TemperatureAmplitude: 8, DayLengthAmplitude: 0.2, WeatherAmplitude: 1}
+		}, ""},
+		{"negative ticks per year", func(c *Climate) { c.Seasons.TicksPerYear = -1 }, "ticks per year cannot be negative"},
+		{"year of part days", func(c *Climate) { c.Seasons.TicksPerYear = 24*360 + 1 }, "ticks per year must be a whole number of at least 4 days"},
+		{"year under 4 days", func(c *Climate) { c.Seasons.TicksPerYear = 24 * 3 }, "ticks per year must be a whole number of at least 4 days"},
+		{"seasons without day cycle", func(c *Climate) {
+			c.DayCycle, c.CloudyChance, c.RainChance, c.Seasons.TicksPerYear = DayCycle{}, 0, 0, 360
+		}, "ticks per year must be a whole number of at least 4 days"},
+		{"season phase of 1", func(c *Climate) { c.Seasons = Seasons{TicksPerYear: 24 * 360, Phase: 1} }, "season phase must be between 0.0 and 1.0"},
+		{"negative seasonal temperature", func(c *Climate) { c.Seasons = Seasons{TicksPerYear: 24 * 360, TemperatureAmplitude: -1} }, "seasonal amplitudes cannot be negative"},
+		{"seasonal day length of 0.5", func(c *Climate) { c.Seasons = Seasons{TicksPerYear: 24 * 360, DayLengthAmplitude: 0.5} }, "seasonal day length amplitude must be below 0.5"},
+		{"seasonal weather above 1", func(c *Climate) { c.Seasons = Seasons{TicksPerYear: 24 * 360, WeatherAmplitude: 1.5} }, "seasonal weather amplitude cannot be above 1.0"},
+		{"amplitude without year", func(c *Climate) { c.Seasons.TemperatureAmplitude = 5 }, "seasons need ticks per year"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			climate := valid
+			tt.modify(&climate)
+			err := climate.Validate()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
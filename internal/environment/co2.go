@@ -0,0 +1,147 @@
+package environment
+
+import (
+	"errors"
+	"math"
+	"sync"
+)
+
+// MaxCO2GrowthBoost bounds CO2Config.GrowthBoost.
+const MaxCO2GrowthBoost = 0.5
+
+// CO2Config configures the CO2 model. A zero Baseline turns it off, which
+// requires every other setting to be zero too.
+type CO2Config struct {
+	// Baseline is the outside CO2 level in ppm the greenhouse starts at.
+	Baseline float64
+	// Uptake is the CO2 in ppm each alive plant draws per tick in full light.
+	Uptake float64
+	// Ventilation is the fraction of its distance to the baseline the level
+	// closes per tick, 0.0 to 1.0.
+	Ventilation float64
+	// BoostThreshold is the level in ppm above which plants grow faster.
+	BoostThreshold float64
+	// GrowthBoost is the extra growth, as a fraction of a plant's base
+	// growth rate, at twice the threshold and above, up to
+	// MaxCO2GrowthBoost.
+	GrowthBoost float64
+}
+
+// CO2 tracks the greenhouse-wide CO2 level in ppm. Photosynthesis draws it
+// down during light hours, an injector enriches it and ventilation pulls it
+// back toward the outside baseline.
+type CO2 interface {
+	// Get returns the current CO2 level in ppm.
+	Get() float64
+	// SetInjection sets the CO2 the injector adds per tick, in ppm.
+	SetInjection(rate float64) error
+	// Injection returns the CO2 the injector adds per tick, in ppm.
+	Injection() float64
+	// Update moves the level one tick on.
+	Update(photosynthesis float64)
+	// GrowthBoost returns the extra growth the current level gives plants.
+	GrowthBoost() float64
+}
+
+type co2 struct {
+	config    CO2Config
+	level     float64
+	injection float64
+	mu        sync.Mutex
+}
+
+// NewCO2 creates a CO2 tracker starting at the baseline, with the injector
+// off. Returns an error if cfg is invalid, see CO2Config.Validate.
+func NewCO2(cfg CO2Config) (CO2, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &co2{config: cfg, level: cfg.Baseline}, nil
+}
+
+// Validate checks the CO2 settings. Returns an error if:
+// - any setting is negative
+// - the ventilation is above 1.0
+// - the growth boost is above MaxCO2GrowthBoost
+// - a growth boost is set without a boost threshold
+// - any setting is set without a baseline
+func (c CO2Config) Validate() error {
+	if c.Baseline < 0 || c.Uptake < 0 || c.BoostThreshold < 0 {
+		return errors.New("co2 baseline, uptake and boost threshold cannot be negative")
+	}
+	if c.Ventilation < 0 || c.Ventilation > 1 {
+		return errors.New("co2 ventilation must be between 0.0 and 1.0")
+	}
+	if c.GrowthBoost < 0 || c.GrowthBoost > MaxCO2GrowthBoost {
+		return errors.New("co2 growth boost must be between 0.0 and 0.5")
+	}
+	if c.GrowthBoost > 0 && c.BoostThreshold == 0 {
+		return errors.New("co2 growth boost requires a boost threshold")
+	}
+	if c.Baseline == 0 && c != (CO2Config{}) {
+		return errors.New("co2 settings require a co2 baseline")
+	}
+	return nil
+}
+
+// Get returns the current CO2 level in ppm, 0 when the model is off.
+// This method is safe for concurrent use.
+func (c *co2) Get() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.level
+}
+
+// SetInjection sets the CO2 the injector adds per tick, in ppm, from the next
+// update on; 0 turns it off. Returns an error if:
+// - rate is negative
+// - rate is positive and the model is off
+//
+// This method is safe for concurrent use.
+func (c *co2) SetInjection(rate float64) error {
+	if rate < 0 {
+		return errors.New("co2 injection rate cannot be negative")
+	}
+	if rate > 0 && c.config.Baseline == 0 {
+		return errors.New("co2 injection requires a co2 baseline")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.injection = rate
+	return nil
+}
+
+// Injection returns the CO2 the injector adds per tick, in ppm.
+// This method is safe for concurrent use.
+func (c *co2) Injection() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.injection
+}
+
+// Update moves the level one tick on: the injection is added, Uptake times
+// photosynthesis, the number of alive plants weighted by the light, is drawn
+// down and ventilation closes part of the distance to the baseline. The level
+// never drops below 0.
+// This method is safe for concurrent use.
+func (c *co2) Update(photosynthesis float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	level := c.level + c.injection - c.config.Uptake*photosynthesis
+	level += c.config.Ventilation * (c.config.Baseline - level)
+	c.level = math.Max(level, 0)
+}
+
+// GrowthBoost returns the extra growth the current level gives plants, as a
+// fraction of their base growth rate: none up to the threshold, rising
+// linearly to the configured boost at twice the threshold and capped there.
+// This method is safe for concurrent use.
+func (c *co2) GrowthBoost() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.config.GrowthBoost == 0 || c.level <= c.config.BoostThreshold {
+		return 0
+	}
+	excess := (c.level - c.config.BoostThreshold) / c.config.BoostThreshold
+	return c.config.GrowthBoost * math.Min(excess, 1)
+}
@@ -0,0 +1,92 @@
+package environment
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCO2_UpdateAndBoost(t *testing.T) {
+	c, err := NewCO2(CO2Config{Baseline: 400, Uptake: 2, Ventilation: 0.1, BoostThreshold: 600, GrowthBoost: 0.2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := c.Get(); got != 400 {
+		t.Errorf("expected to start at the baseline 400, got %.2f", got)
+	}
+
+	// 10 plants in half light draw 10 ppm, and ventilation closes a tenth
+	// of the 10 ppm gap: 400 -> 391
+	c.Update(5)
+	if got := c.Get(); math.Abs(got-391) > 1e-9 {
+		t.Errorf("expected 391 ppm after a tick of photosynthesis, got %.2f", got)
+	}
+	if got := c.GrowthBoost(); got != 0 {
+		t.Errorf("expected no boost below the threshold, got %.2f", got)
+	}
+
+	// 391 + 509 = 900, ventilated to 850: halfway to twice the threshold
+	if err := c.SetInjection(509); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.Update(0)
+	if got := c.Get(); math.Abs(got-850) > 1e-9 {
+		t.Errorf("expected 850 ppm after a tick of injection, got %.2f", got)
+	}
+	if got := c.GrowthBoost(); math.Abs(got-0.2*250.0/600) > 1e-9 {
+		t.Errorf("expected a partial boost, got %.4f", got)
+	}
+	for range 20 {
+		c.Update(0)
+	}
+	if got := c.GrowthBoost(); got != 0.2 {
+		t.Errorf("expected the boost to be capped at 0.2, got %.4f", got)
+	}
+
+	if err := c.SetInjection(0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.Update(1e6)
+	if got := c.Get(); got != 0 {
+		t.Errorf("expected the level to stop at 0, got %.2f", got)
+	}
+}
+
+func TestCO2_Off(t *testing.T) {
+	c, err := NewCO2(CO2Config{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.Update(10)
+	if c.Get() != 0 || c.GrowthBoost() != 0 {
+		t.Errorf("expected the model to stay off, got %.2f ppm and boost %.2f", c.Get(), c.GrowthBoost())
+	}
+	if err := c.SetInjection(10); err == nil || err.Error() != "co2 injection requires a co2 baseline" {
+		t.Errorf("expected injection to require a baseline, got %v", err)
+	}
+}
+
+func TestNewCO2_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   CO2Config
+		errorMsg string
+	}{
+		{"negative uptake", CO2Config{Baseline: 400, Uptake: -1}, "co2 baseline, uptake and boost threshold cannot be negative"},
+		{"ventilation above 1", CO2Config{Baseline: 400, Ventilation: 1.5}, "co2 ventilation must be between 0.0 and 1.0"},
+		{"boost too high", CO2Config{Baseline: 400, BoostThreshold: 600, GrowthBoost: 0.8}, "co2 growth boost must be between 0.0 and 0.5"},
+		{"boost without threshold", CO2Config{Baseline: 400, GrowthBoost: 0.2}, "co2 growth boost requires a boost threshold"},
+		{"settings without baseline", CO2Config{Uptake: 2}, "co2 settings require a co2 baseline"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCO2(tt.config)
+			if err == nil {
+				t.Fatal("expected error but got nil")
+			}
+			if err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package environment
+
+// DayCycle maps simulation ticks onto a repeating simulated day, with tick 0
+// at midnight. A zero TicksPerDay disables the cycle.
+type DayCycle struct {
+	TicksPerDay int
+}
+
+// Enabled reports whether the cycle has a day length configured.
+func (d DayCycle) Enabled() bool {
+	return d.TicksPerDay > 0
+}
+
+// TimeOfDay returns the fraction of the simulated day elapsed at the given
+// tick, from 0.0 (midnight) up to but excluding 1.0. It returns 0 when the
+// cycle is disabled.
+func (d DayCycle) TimeOfDay(tick int) float64 {
+	if !d.Enabled() {
+		return 0
+	}
+	return float64(tick%d.TicksPerDay) / float64(d.TicksPerDay)
+}
+
+// Day returns the zero-based simulated day the tick falls in.
+func (d DayCycle) Day(tick int) int {
+	if !d.Enabled() {
+		return 0
+	}
+	return tick / d.TicksPerDay
+}
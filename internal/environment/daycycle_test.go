@@ -0,0 +1,39 @@
+package environment
+
+import "testing"
+
+func TestDayCycle(t *testing.T) {
+	cycle := DayCycle{TicksPerDay: 20}
+
+	tests := []struct {
+		tick              int
+		expectedTimeOfDay float64
+		expectedDay       int
+	}{
+		{0, 0, 0},
+		{5, 0.25, 0},
+		{10, 0.5, 0},
+		{19, 0.95, 0},
+		{20, 0, 1},
+		{45, 0.25, 2},
+	}
+
+	for _, tt := range tests {
+		if got := cycle.TimeOfDay(tt.tick); got != tt.expectedTimeOfDay {
+			t.Errorf("tick %d: expected time of day %.2f, got %.2f", tt.tick, tt.expectedTimeOfDay, got)
+		}
+		if got := cycle.Day(tt.tick); got != tt.expectedDay {
+			t.Errorf("tick %d: expected day %d, got %d", tt.tick, tt.expectedDay, got)
+		}
+	}
+}
+
+func TestDayCycle_Disabled(t *testing.T) {
+	cycle := DayCycle{}
+	if cycle.Enabled() {
+		t.Error("expected zero day cycle to be disabled")
+	}
+	if cycle.TimeOfDay(42) != 0 || cycle.Day(42) != 0 {
+		t.Error("expected disabled day cycle to report midnight of day 0")
+	}
+}
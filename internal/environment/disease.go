@@ -0,0 +1,45 @@
+package environment
+
+import (
+	"errors"
+	"math"
+)
+
+// DiseaseConfig configures the plant disease model. An infected plant
+// incubates the disease without symptoms for Incubation ticks, then turns
+// symptomatic: it loses HealthDecay health per tick and transpires
+// TranspirationDrop of its normal depletion less, which leaves its soil
+// wetter than that of its neighbours. Every tick, each diseased plant
+// infects each healthy plant of its section with a chance of SpreadRate
+// times the humidity of the section, so wetter air spreads it faster.
+// Treating a plant cures it with a chance of CureChance.
+type DiseaseConfig struct {
+	Incubation        int
+	HealthDecay       float64
+	TranspirationDrop float64
+	SpreadRate        float64
+	CureChance        float64
+}
+
+// Validate checks the disease settings. Returns an error if:
+// - the incubation is negative
+// - the health decay, transpiration drop, spread rate or cure chance is
+// outside 0.0-1.0
+func (c DiseaseConfig) Validate() error {
+	if c.Incubation < 0 {
+		return errors.New("disease incubation cannot be negative")
+	}
+	for _, rate := range []float64{c.HealthDecay, c.TranspirationDrop, c.SpreadRate, c.CureChance} {
+		if rate < 0 || rate > 1 {
+			return errors.New("disease rates and chances must be between 0.0 and 1.0")
+		}
+	}
+	return nil
+}
+
+// SpreadChance returns the chance that a healthy plant catches the disease
+// on a tick from the diseased plants of its section, at the section's
+// humidity: the chance that at least one of them infects it.
+func (c DiseaseConfig) SpreadChance(humidity float64, diseased int) float64 {
+	return 1 - math.Pow(1-c.SpreadRate*humidity, float64(diseased))
+}
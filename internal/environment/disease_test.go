@@ -0,0 +1,55 @@
+package environment
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDiseaseConfig_SpreadChance(t *testing.T) {
+	cfg := DiseaseConfig{SpreadRate: 0.2}
+	tests := []struct {
+		humidity float64
+		diseased int
+		expected float64
+	}{
+		{0, 3, 0},
+		{0.5, 0, 0},
+		{0.5, 1, 0.1},
+		{1, 1, 0.2},
+		// Each of 2 diseased plants misses with 0.9: 1 - 0.81.
+		{0.5, 2, 0.19},
+	}
+	for _, tt := range tests {
+		if got := cfg.SpreadChance(tt.humidity, tt.diseased); math.Abs(got-tt.expected) > 1e-9 {
+			t.Errorf("humidity %.1f, %d diseased: expected %.2f, got %.4f", tt.humidity, tt.diseased, tt.expected, got)
+		}
+	}
+}
+
+func TestDiseaseConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   DiseaseConfig
+		errorMsg string
+	}{
+		{"valid", DiseaseConfig{Incubation: 5, HealthDecay: 0.05, TranspirationDrop: 0.5, SpreadRate: 0.1, CureChance: 1}, ""},
+		{"negative incubation", DiseaseConfig{Incubation: -1}, "disease incubation cannot be negative"},
+		{"spread rate above 1", DiseaseConfig{SpreadRate: 1.5}, "disease rates and chances must be between 0.0 and 1.0"},
+		{"negative cure chance", DiseaseConfig{CureChance: -0.1}, "disease rates and chances must be between 0.0 and 1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
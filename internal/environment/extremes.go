@@ -0,0 +1,86 @@
+package environment
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/rng"
+)
+
+// Extreme is a kind of extreme weather.
+type Extreme string
+
+const (
+	Frost    Extreme = "frost"
+	HeatWave Extreme = "heat_wave"
+)
+
+// Validate returns an error if e is not a known extreme.
+func (e Extreme) Validate() error {
+	if e != Frost && e != HeatWave {
+		return errors.New("extreme weather must be frost or heat_wave: " + string(e))
+	}
+	return nil
+}
+
+// ExtremeEvent is a spell of extreme weather lasting Ticks ticks from the
+// tick Start.
+type ExtremeEvent struct {
+	Kind  Extreme `json:"kind"`
+	Start int     `json:"start"`
+	Ticks int     `json:"ticks"`
+}
+
+// ActiveAt reports whether the event is going on at the given tick.
+func (e ExtremeEvent) ActiveAt(tick int) bool {
+	return tick >= e.Start && tick < e.Start+e.Ticks
+}
+
+// ExtremeOn returns the extreme weather starting at the beginning of a
+// zero-based simulated day, empty when there is none. Like WeatherOn, the
+// draw depends only on the seed and the day, from the seed's rng.Weather
+// stream but apart from the daily weather. With seasons, frosts are only
+// drawn in the cold half of the year and heat waves in the warm half.
+func (c Climate) ExtremeOn(day int) Extreme {
+	if c.FrostChance == 0 && c.HeatWaveChance == 0 {
+		return ""
+	}
+	draw := rng.New(c.Seed).Split(rng.Weather).Split("extremes").SplitN(day).Float64()
+	seasonal := c.seasonal(day)
+	switch {
+	case draw < c.FrostChance:
+		if c.Seasons.Enabled() && seasonal >= 0 {
+			return ""
+		}
+		return Frost
+	case draw < c.FrostChance+c.HeatWaveChance:
+		if c.Seasons.Enabled() && seasonal <= 0 {
+			return ""
+		}
+		return HeatWave
+	}
+	return ""
+}
+
+// ExtremeEventAt returns the drawn extreme weather going on at the given
+// tick, the latest one when they overlap, and whether there is one.
+func (c Climate) ExtremeEventAt(tick int) (ExtremeEvent, bool) {
+	if !c.DayCycle.Enabled() || c.ExtremeTicks <= 0 {
+		return ExtremeEvent{}, false
+	}
+	for day := c.DayCycle.Day(tick); day >= 0; day-- {
+		event := ExtremeEvent{Kind: c.ExtremeOn(day), Start: day * c.DayCycle.TicksPerDay, Ticks: c.ExtremeTicks}
+		if !event.ActiveAt(tick) {
+			break
+		}
+		if event.Kind != "" {
+			return event, true
+		}
+	}
+	return ExtremeEvent{}, false
+}
+
+// ExtremeAt returns the drawn extreme weather going on at the given tick,
+// empty when there is none.
+func (c Climate) ExtremeAt(tick int) Extreme {
+	event, _ := c.ExtremeEventAt(tick)
+	return event.Kind
+}
@@ -0,0 +1,92 @@
+package environment
+
+import (
+	"math"
+	"testing"
+)
+
+func TestClimate_Extremes(t *testing.T) {
+	climate := Climate{
+		DayCycle:         DayCycle{TicksPerDay: 10},
+		Seed:             7,
+		Temperature:      10,
+		TemperatureSwing: 4,
+		Humidity:         0.6,
+		Light:            0.8,
+		FrostChance:      0.2,
+		HeatWaveChance:   0.1,
+		ExtremeTicks:     15,
+		FrostTemperature: -3,
+	}
+
+	counts := map[Extreme]int{}
+	for day := range 1000 {
+		counts[climate.ExtremeOn(day)]++
+	}
+	for extreme, expected := range map[Extreme]int{"": 700, Frost: 200, HeatWave: 100} {
+		if math.Abs(float64(counts[extreme]-expected)) > 50 {
+			t.Errorf("expected about %d days starting with %q, got %d", expected, extreme, counts[extreme])
+		}
+	}
+
+	frosts, heatWaves := 0, 0
+	for tick := range 2000 {
+		conditions := climate.At(tick)
+		event, ok := climate.ExtremeEventAt(tick)
+		if ok != (conditions.Extreme != "") || event.Kind != conditions.Extreme || (ok && !event.ActiveAt(tick)) {
+			t.Fatalf("tick %d: expected the conditions to carry the extreme going on, got %+v and %+v", tick, conditions, event)
+		}
+		plain := climate.With(tick, "")
+		switch conditions.Extreme {
+		case Frost:
+			frosts++
+			if conditions.Temperature > -3 || conditions.Evaporation != 1 {
+				t.Errorf("tick %d: expected a frost to hold the air at -3 or below, got %+v", tick, conditions)
+			}
+		case HeatWave:
+			heatWaves++
+			if conditions.Temperature != plain.Temperature+8 || conditions.Evaporation != 2 {
+				t.Errorf("tick %d: expected a heat wave to add 8 degrees and double the evaporation, got %+v", tick, conditions)
+			}
+		default:
+			if conditions != plain {
+				t.Errorf("tick %d: expected the plain conditions, got %+v", tick, conditions)
+			}
+		}
+	}
+	if frosts == 0 || heatWaves == 0 {
+		t.Errorf("expected both extremes within 200 days, got %d frost and %d heat wave ticks", frosts, heatWaves)
+	}
+
+	// An extreme running past midnight is still going on the next day.
+	for day := range 200 {
+		if climate.ExtremeOn(day) != "" {
+			if extreme := climate.ExtremeAt(day*10 + 14); extreme == "" {
+				t.Errorf("expected the extreme of day %d to last 15 ticks", day)
+			}
+			return
+		}
+	}
+}
+
+func TestClimate_With(t *testing.T) {
+	climate := Climate{Temperature: 30, Humidity: 0.5, Light: 1, FrostTemperature: 2, HeatWaveRise: 5, HeatWaveEvaporation: 3}
+	tests := []struct {
+		extreme  Extreme
+		expected Conditions
+	}{
+		{"", Conditions{Temperature: 30, Humidity: 0.5, Light: 1, Weather: Clear, Evaporation: 1}},
+		{Frost, Conditions{Temperature: 2, Humidity: 0.5, Light: 1, Weather: Clear, Extreme: Frost, Evaporation: 1}},
+		{HeatWave, Conditions{Temperature: 35, Humidity: 0.5, Light: 1, Weather: Clear, Extreme: HeatWave, Evaporation: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.extreme), func(t *testing.T) {
+			if got := climate.With(100, tt.extreme); got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+	if extreme := climate.At(100).Extreme; extreme != "" {
+		t.Errorf("expected no extremes without chances, got %q", extreme)
+	}
+}
@@ -0,0 +1,107 @@
+package environment
+
+import (
+	"errors"
+	"math"
+	"sync"
+)
+
+// Humidity tracks the relative air humidity (0.0 to 1.0) of each greenhouse
+// section. Sections start at the ambient level and drift back toward it on
+// every tick, so a burst of moisture fades over time.
+type Humidity interface {
+	// Get returns the current humidity of a section.
+	Get(sectionID string) float64
+	// Add raises (or lowers, for a negative delta) the humidity of a section.
+	Add(sectionID string, delta float64)
+	// SetAmbient changes the ambient level and the decay rate.
+	SetAmbient(ambient, decayRate float64) error
+	// OnTick moves every section one step back toward the ambient level.
+	OnTick(tick int)
+}
+
+type humidity struct {
+	ambient  float64
+	decay    float64
+	sections map[string]float64
+	mu       sync.Mutex
+}
+
+// NewHumidity creates a humidity tracker where every section starts at
+// ambient. On each tick a section closes decayRate of its distance to the
+// ambient level. Returns an error if:
+// - ambient is outside 0.0-1.0
+// - decayRate is outside 0.0-1.0
+func NewHumidity(ambient, decayRate float64) (Humidity, error) {
+	if err := validateAmbient(ambient, decayRate); err != nil {
+		return nil, err
+	}
+	return &humidity{
+		ambient:  ambient,
+		decay:    decayRate,
+		sections: map[string]float64{},
+	}, nil
+}
+
+func validateAmbient(ambient, decayRate float64) error {
+	if ambient < 0 || ambient > 1 {
+		return errors.New("ambient humidity must be between 0.0 and 1.0")
+	}
+	if decayRate < 0 || decayRate > 1 {
+		return errors.New("humidity decay rate must be between 0.0 and 1.0")
+	}
+	return nil
+}
+
+// SetAmbient changes the ambient level sections drift toward and the rate at
+// which they do, from the next tick on. Sections that were never changed
+// follow the new ambient level immediately. Returns an error under the same
+// conditions as NewHumidity.
+// This method is safe for concurrent use.
+func (h *humidity) SetAmbient(ambient, decayRate float64) error {
+	if err := validateAmbient(ambient, decayRate); err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.ambient = ambient
+	h.decay = decayRate
+	return nil
+}
+
+// Get returns the current humidity of a section, the ambient level for
+// sections that were never changed.
+// This method is safe for concurrent use.
+func (h *humidity) Get(sectionID string) float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if level, ok := h.sections[sectionID]; ok {
+		return level
+	}
+	return h.ambient
+}
+
+// Add changes the humidity of a section by delta, clamped to 0.0-1.0.
+// This method is safe for concurrent use.
+func (h *humidity) Add(sectionID string, delta float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	level, ok := h.sections[sectionID]
+	if !ok {
+		level = h.ambient
+	}
+	h.sections[sectionID] = math.Max(0, math.Min(1, level+delta))
+}
+
+// TickPhase names the humidity decay in tick traces.
+func (h *humidity) TickPhase() string { return "humidity" }
+
+// OnTick decays every section toward the ambient level.
+// This method is safe for concurrent use.
+func (h *humidity) OnTick(tick int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sectionID, level := range h.sections {
+		h.sections[sectionID] = level + (h.ambient-level)*h.decay
+	}
+}
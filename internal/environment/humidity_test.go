@@ -0,0 +1,80 @@
+package environment
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHumidity_AddAndDecay(t *testing.T) {
+	h, err := NewHumidity(0.5, 0.25)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := h.Get("section-A"); got != 0.5 {
+		t.Errorf("expected ambient humidity 0.5, got %.2f", got)
+	}
+	h.Add("section-A", 0.3)
+	if got := h.Get("section-A"); math.Abs(got-0.8) > 1e-9 {
+		t.Errorf("expected humidity 0.8 after adding 0.3, got %.2f", got)
+	}
+	h.Add("section-A", 0.5)
+	if got := h.Get("section-A"); got != 1 {
+		t.Errorf("expected humidity to be capped at 1.0, got %.2f", got)
+	}
+
+	// Each tick closes a quarter of the gap to ambient: 1.0 -> 0.875 -> 0.78125
+	h.OnTick(0)
+	h.OnTick(1)
+	if got := h.Get("section-A"); math.Abs(got-0.78125) > 1e-9 {
+		t.Errorf("expected humidity 0.78125 after two ticks, got %.5f", got)
+	}
+	if got := h.Get("section-B"); got != 0.5 {
+		t.Errorf("expected untouched section to stay at ambient, got %.2f", got)
+	}
+}
+
+func TestNewHumidity_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		ambient  float64
+		decay    float64
+		errorMsg string
+	}{
+		{"ambient above 1", 1.2, 0.1, "ambient humidity must be between 0.0 and 1.0"},
+		{"negative decay", 0.5, -0.1, "humidity decay rate must be between 0.0 and 1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewHumidity(tt.ambient, tt.decay)
+			if err == nil {
+				t.Fatal("expected error but got nil")
+			}
+			if err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestHumidity_SetAmbient(t *testing.T) {
+	h, _ := NewHumidity(0.5, 0.5)
+	h.Add("section-A", 0.3)
+
+	if err := h.SetAmbient(0.2, 1.5); err == nil {
+		t.Fatal("expected error for an invalid decay rate, got nil")
+	}
+	if err := h.SetAmbient(0.2, 0.5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := h.Get("section-B"); got != 0.2 {
+		t.Errorf("expected untouched section to follow the new ambient 0.2, got %.2f", got)
+	}
+	// Half the gap from 0.8 to the new ambient 0.2 closes in one tick
+	h.OnTick(0)
+	if got := h.Get("section-A"); math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("expected humidity 0.5 after one tick, got %.2f", got)
+	}
+}
@@ -0,0 +1,249 @@
+package environment
+
+import (
+	"cmp"
+	"errors"
+	"sync"
+)
+
+// Actuator names a climate control actuator.
+type Actuator string
+
+const (
+	Heater Actuator = "heater"
+	Vent   Actuator = "vent"
+)
+
+// Validate checks that the actuator is a heater or a vent.
+func (a Actuator) Validate() error {
+	if a != Heater && a != Vent {
+		return errors.New("actuator must be heater or vent: " + string(a))
+	}
+	return nil
+}
+
+// ActuatorMode is how an actuator is switched: by the thermostat, or forced
+// on or off by a manual override.
+type ActuatorMode string
+
+const (
+	ModeAuto ActuatorMode = "auto"
+	ModeOn   ActuatorMode = "on"
+	ModeOff  ActuatorMode = "off"
+)
+
+// Validate checks that the mode is auto, on or off.
+func (m ActuatorMode) Validate() error {
+	if m != ModeAuto && m != ModeOn && m != ModeOff {
+		return errors.New("actuator mode must be auto, on or off: " + string(m))
+	}
+	return nil
+}
+
+// ThermostatConfig configures the thermostat, which switches the actuators in
+// auto mode on the readings of a temperature sensor. It switches the heater on
+// below Min and the vent on above Max, and each off again once the reading is
+// back at the middle of the range, so that they do not toggle every tick.
+type ThermostatConfig struct {
+	SensorID string
+	Min      float64
+	Max      float64
+}
+
+// HVACConfig configures the greenhouse-wide heater and vent. While on, the
+// heater raises the temperature by HeaterPower degrees per tick and the vent
+// lowers it by VentPower. Every tick, the greenhouse loses HeatLoss of its
+// difference to the outside temperature, 0.2 when zero. The actuators use
+// HeaterEnergy and VentEnergy per tick while on, 1 when zero. Without a
+// Thermostat, auto mode leaves them off.
+type HVACConfig struct {
+	HeaterPower  float64
+	VentPower    float64
+	HeatLoss     float64
+	HeaterEnergy float64
+	VentEnergy   float64
+	Thermostat   *ThermostatConfig
+}
+
+// HVACState is the state of the heater and the vent. Offset is how far they
+// have moved the temperature away from the outside temperature.
+type HVACState struct {
+	Heater     bool         `json:"heater"`
+	HeaterMode ActuatorMode `json:"heater_mode"`
+	Vent       bool         `json:"vent"`
+	VentMode   ActuatorMode `json:"vent_mode"`
+	Offset     float64      `json:"offset"`
+}
+
+// HVAC is the climate control of the greenhouse: a heater and a vent pushing
+// the temperature away from the outside temperature, switched by a
+// thermostat or by hand.
+type HVAC interface {
+	// SetMode switches an actuator to auto, or forces it on or off.
+	SetMode(actuator Actuator, mode ActuatorMode) error
+	// State returns the state of the actuators.
+	State() HVACState
+	// Offset returns how far the actuators have moved the temperature.
+	Offset() float64
+	// Energy returns the energy the actuators have used.
+	Energy() float64
+	// ActuatorEnergy returns the energy one of the actuators has used.
+	ActuatorEnergy(actuator Actuator) float64
+	// Update runs the thermostat on a temperature reading and moves the
+	// temperature one tick on.
+	Update(reading float64, ok bool)
+}
+
+type hvac struct {
+	config HVACConfig
+	state  HVACState
+	// heating and venting are what the thermostat asks for.
+	heating bool
+	venting bool
+	energy  map[Actuator]float64
+	mu      sync.Mutex
+}
+
+// NewHVAC creates the climate control with both actuators in auto mode and
+// off. Returns an error if cfg is invalid, see HVACConfig.Validate.
+func NewHVAC(cfg HVACConfig) (HVAC, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &hvac{
+		config: cfg,
+		state:  HVACState{HeaterMode: ModeAuto, VentMode: ModeAuto},
+		energy: map[Actuator]float64{},
+	}, nil
+}
+
+// Validate checks the climate control settings. Returns an error if:
+// - a power or energy per tick is negative
+// - the heat loss is outside 0.0-1.0
+// - the thermostat has no sensor or its Max is not above its Min
+func (c HVACConfig) Validate() error {
+	if c.HeaterPower < 0 || c.VentPower < 0 {
+		return errors.New("heater and vent power cannot be negative")
+	}
+	if c.HeatLoss < 0 || c.HeatLoss > 1 {
+		return errors.New("heat loss must be between 0.0 and 1.0")
+	}
+	if c.HeaterEnergy < 0 || c.VentEnergy < 0 {
+		return errors.New("heater and vent energy cannot be negative")
+	}
+	if t := c.Thermostat; t != nil {
+		if t.SensorID == "" {
+			return errors.New("thermostat sensor ID cannot be empty")
+		}
+		if t.Max <= t.Min {
+			return errors.New("thermostat max must be above its min")
+		}
+	}
+	return nil
+}
+
+// SetMode switches an actuator to auto, handing it back to the thermostat,
+// or forces it on or off, from the next update on. Returns an error if the
+// actuator or the mode is unknown.
+// This method is safe for concurrent use.
+func (h *hvac) SetMode(actuator Actuator, mode ActuatorMode) error {
+	if err := actuator.Validate(); err != nil {
+		return err
+	}
+	if err := mode.Validate(); err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if actuator == Heater {
+		h.state.HeaterMode = mode
+	} else {
+		h.state.VentMode = mode
+	}
+	return nil
+}
+
+// State returns the state of the actuators.
+// This method is safe for concurrent use.
+func (h *hvac) State() HVACState {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.state
+}
+
+// Offset returns how far the actuators have moved the temperature away from
+// the outside temperature.
+// This method is safe for concurrent use.
+func (h *hvac) Offset() float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.state.Offset
+}
+
+// Energy returns the energy the actuators have used: every tick they are on,
+// their energy per tick.
+// This method is safe for concurrent use.
+func (h *hvac) Energy() float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.energy[Heater] + h.energy[Vent]
+}
+
+// ActuatorEnergy returns the energy the heater or the vent has used, 0 for
+// an unknown actuator.
+// This method is safe for concurrent use.
+func (h *hvac) ActuatorEnergy(actuator Actuator) float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.energy[actuator]
+}
+
+// Update runs the thermostat on a temperature reading, unless ok is false
+// because there is none, in which case it keeps what it asked for before.
+// It then switches the actuators by their modes, lets part of the offset
+// leak away, adds the heating and venting of the tick and accounts for their
+// energy.
+// This method is safe for concurrent use.
+func (h *hvac) Update(reading float64, ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if t := h.config.Thermostat; t != nil && ok {
+		middle := (t.Min + t.Max) / 2
+		switch {
+		case reading < t.Min:
+			h.heating = true
+		case reading >= middle:
+			h.heating = false
+		}
+		switch {
+		case reading > t.Max:
+			h.venting = true
+		case reading <= middle:
+			h.venting = false
+		}
+	}
+	h.state.Heater = switched(h.state.HeaterMode, h.heating)
+	h.state.Vent = switched(h.state.VentMode, h.venting)
+
+	h.state.Offset *= 1 - cmp.Or(h.config.HeatLoss, 0.2)
+	if h.state.Heater {
+		h.state.Offset += h.config.HeaterPower
+		h.energy[Heater] += cmp.Or(h.config.HeaterEnergy, 1)
+	}
+	if h.state.Vent {
+		h.state.Offset -= h.config.VentPower
+		h.energy[Vent] += cmp.Or(h.config.VentEnergy, 1)
+	}
+}
+
+// switched reports whether an actuator in the given mode is on, given what
+// the thermostat asks for.
+func switched(mode ActuatorMode, auto bool) bool {
+	switch mode {
+	case ModeOn:
+		return true
+	case ModeOff:
+		return false
+	}
+	return auto
+}
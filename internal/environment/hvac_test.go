@@ -0,0 +1,114 @@
+package environment
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHVAC_ThermostatHysteresis(t *testing.T) {
+	h, err := NewHVAC(HVACConfig{HeaterPower: 2, VentPower: 1, HeatLoss: 0.5, Thermostat: &ThermostatConfig{SensorID: "temp", Min: 18, Max: 24}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The heater goes on below 18 and stays on until the reading is back at
+	// 21, the middle of the range; the vent likewise above 24.
+	steps := []struct {
+		reading float64
+		heater  bool
+		vent    bool
+	}{
+		{20, false, false},
+		{17.5, true, false},
+		{19, true, false},
+		{21, false, false},
+		{19, false, false},
+		{25, false, true},
+		{22, false, true},
+		{21, false, false},
+	}
+	for i, step := range steps {
+		h.Update(step.reading, true)
+		if state := h.State(); state.Heater != step.heater || state.Vent != step.vent {
+			t.Errorf("step %d: expected heater %v and vent %v at %.1f, got %+v", i, step.heater, step.vent, step.reading, state)
+		}
+	}
+	// The heater ran 2 ticks and the vent 2, at the default energy of 1.
+	if got := h.Energy(); got != 4 {
+		t.Errorf("expected 4 energy used, got %.2f", got)
+	}
+
+	h.Update(17, true)
+	h.Update(0, false)
+	if !h.State().Heater {
+		t.Error("expected the heater to stay on without a reading")
+	}
+}
+
+func TestHVAC_OffsetAndOverride(t *testing.T) {
+	h, err := NewHVAC(HVACConfig{HeaterPower: 2, VentPower: 1, HeatLoss: 0.5, HeaterEnergy: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	h.Update(0, true)
+	if state := h.State(); state.Heater || state.Vent {
+		t.Errorf("expected auto mode without a thermostat to leave the actuators off, got %+v", state)
+	}
+
+	if err := h.SetMode(Heater, ModeOn); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 0 -> 2 -> 3: half the offset leaks away before each tick of heating.
+	h.Update(0, true)
+	h.Update(0, true)
+	if got := h.Offset(); math.Abs(got-3) > 1e-9 {
+		t.Errorf("expected an offset of 3 after 2 ticks of heating, got %.2f", got)
+	}
+	if got := h.Energy(); got != 6 {
+		t.Errorf("expected 6 energy used, got %.2f", got)
+	}
+
+	if err := h.SetMode(Heater, ModeAuto); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := h.SetMode(Vent, ModeOn); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	h.Update(0, true)
+	if got := h.Offset(); math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("expected an offset of 0.5 after a tick of venting, got %.2f", got)
+	}
+
+	if err := h.SetMode("fan", ModeOn); err == nil || err.Error() != "actuator must be heater or vent: fan" {
+		t.Errorf("expected an unknown actuator to be refused, got %v", err)
+	}
+	if err := h.SetMode(Vent, "max"); err == nil || err.Error() != "actuator mode must be auto, on or off: max" {
+		t.Errorf("expected an unknown mode to be refused, got %v", err)
+	}
+}
+
+func TestNewHVAC_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   HVACConfig
+		errorMsg string
+	}{
+		{"negative power", HVACConfig{HeaterPower: -1}, "heater and vent power cannot be negative"},
+		{"heat loss above 1", HVACConfig{HeatLoss: 1.5}, "heat loss must be between 0.0 and 1.0"},
+		{"negative energy", HVACConfig{VentEnergy: -1}, "heater and vent energy cannot be negative"},
+		{"thermostat without sensor", HVACConfig{Thermostat: &ThermostatConfig{Min: 18, Max: 24}}, "thermostat sensor ID cannot be empty"},
+		{"empty thermostat range", HVACConfig{Thermostat: &ThermostatConfig{SensorID: "temp", Min: 20, Max: 20}}, "thermostat max must be above its min"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewHVAC(tt.config)
+			if err == nil {
+				t.Fatal("expected error but got nil")
+			}
+			if err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+			}
+		})
+	}
+}
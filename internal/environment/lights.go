@@ -0,0 +1,199 @@
+package environment
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Lamp is the state of the grow lights of a section.
+type Lamp struct {
+	On        bool    `json:"on"`
+	Intensity float64 `json:"intensity"`
+}
+
+// LightsConfig configures the grow lights of a section. EnergyPerTick is the
+// energy they use per tick at full intensity, 1 when zero. A positive
+// AfterSunset switches them on at Intensity at sunset, 18:00, and off again
+// that many ticks later, every day.
+type LightsConfig struct {
+	SectionID     string
+	Intensity     float64
+	EnergyPerTick float64
+	AfterSunset   int
+}
+
+// Lights are the grow lights of the greenhouse sections. Every section has
+// lights, off until switched on; they add their intensity to the natural
+// light of the section.
+type Lights interface {
+	// Set switches the lights of a section on or off at an intensity.
+	Set(sectionID string, on bool, intensity float64) error
+	// Get returns the state of the lights of a section.
+	Get(sectionID string) Lamp
+	// Light returns the light in a section given the natural light.
+	Light(sectionID string, natural float64) float64
+	// Energy returns the energy the lights have used.
+	Energy() float64
+	// EnergyBySection returns the energy the lights of each section have used.
+	EnergyBySection() map[string]float64
+	// OnTick runs the schedules and accounts for the energy of the tick.
+	OnTick(tick int)
+}
+
+type lights struct {
+	dayCycle DayCycle
+	configs  map[string]LightsConfig
+	lamps    map[string]Lamp
+	energy   float64
+	sections map[string]float64
+	ticked   bool
+	mu       sync.Mutex
+}
+
+// NewLights creates the grow lights of the sections, all off. Returns an
+// error if:
+// - a section ID is empty or configured twice
+// - an intensity is outside 0.0-1.0
+// - an energy per tick or ticks after sunset is negative
+// - lights on after sunset have no intensity, there is no day cycle or they
+// stay on for a whole day
+func NewLights(dayCycle DayCycle, configs []LightsConfig) (Lights, error) {
+	l := &lights{
+		dayCycle: dayCycle,
+		configs:  map[string]LightsConfig{},
+		lamps:    map[string]Lamp{},
+		sections: map[string]float64{},
+	}
+	for _, cfg := range configs {
+		if cfg.SectionID == "" {
+			return nil, errors.New("lights section ID cannot be empty")
+		}
+		if _, ok := l.configs[cfg.SectionID]; ok {
+			return nil, errors.New("duplicate lights section: " + cfg.SectionID)
+		}
+		if err := validateIntensity(cfg.Intensity); err != nil {
+			return nil, err
+		}
+		if cfg.EnergyPerTick < 0 {
+			return nil, errors.New("lights energy per tick cannot be negative: " + cfg.SectionID)
+		}
+		if cfg.AfterSunset < 0 {
+			return nil, errors.New("lights ticks after sunset cannot be negative: " + cfg.SectionID)
+		}
+		if cfg.AfterSunset > 0 {
+			switch {
+			case cfg.Intensity == 0:
+				return nil, errors.New("lights on after sunset require an intensity: " + cfg.SectionID)
+			case !dayCycle.Enabled():
+				return nil, errors.New("lights on after sunset require a day cycle: " + cfg.SectionID)
+			case cfg.AfterSunset >= dayCycle.TicksPerDay:
+				return nil, errors.New("lights on after sunset must go off within a day: " + cfg.SectionID)
+			}
+		}
+		l.configs[cfg.SectionID] = cfg
+	}
+	return l, nil
+}
+
+func validateIntensity(intensity float64) error {
+	if intensity < 0 || intensity > 1 {
+		return errors.New("lights intensity must be between 0.0 and 1.0")
+	}
+	return nil
+}
+
+// Set switches the lights of a section on or off at an intensity. A schedule
+// switches them again at its next start or end. Returns an error if the
+// intensity is outside 0.0-1.0.
+// This method is safe for concurrent use.
+func (l *lights) Set(sectionID string, on bool, intensity float64) error {
+	if err := validateIntensity(intensity); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lamps[sectionID] = Lamp{On: on, Intensity: intensity}
+	return nil
+}
+
+// Get returns the state of the lights of a section.
+// This method is safe for concurrent use.
+func (l *lights) Get(sectionID string) Lamp {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lamps[sectionID]
+}
+
+// Light returns the natural light plus the intensity of the section's lights
+// while they are on, capped at 1.0.
+// This method is safe for concurrent use.
+func (l *lights) Light(sectionID string, natural float64) float64 {
+	lamp := l.Get(sectionID)
+	if !lamp.On {
+		return natural
+	}
+	return min(1, natural+lamp.Intensity)
+}
+
+// Energy returns the energy the lights have used: every tick they are on,
+// their intensity times their energy per tick.
+// This method is safe for concurrent use.
+func (l *lights) Energy() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.energy
+}
+
+// EnergyBySection returns the energy the lights of each section have used,
+// leaving out those that never were on.
+// This method is safe for concurrent use.
+func (l *lights) EnergyBySection() map[string]float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return maps.Clone(l.sections)
+}
+
+// OnTick switches scheduled lights on at sunset and off once their time is
+// up, or to where their schedule has them on the first tick, then adds the
+// energy of the lights that are on during the tick.
+// This method is safe for concurrent use.
+func (l *lights) OnTick(tick int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for sectionID, cfg := range l.configs {
+		if cfg.AfterSunset == 0 {
+			continue
+		}
+		on := l.scheduled(cfg, tick)
+		if !l.ticked || on != l.scheduled(cfg, tick-1) {
+			l.lamps[sectionID] = Lamp{On: on, Intensity: cfg.Intensity}
+		}
+	}
+	l.ticked = true
+	// Sections are summed in order so that runs stay reproducible.
+	for _, sectionID := range slices.Sorted(maps.Keys(l.lamps)) {
+		lamp := l.lamps[sectionID]
+		if !lamp.On {
+			continue
+		}
+		perTick := l.configs[sectionID].EnergyPerTick
+		if perTick == 0 {
+			perTick = 1
+		}
+		l.energy += lamp.Intensity * perTick
+		l.sections[sectionID] += lamp.Intensity * perTick
+	}
+}
+
+// TickPhase names the grow lights in tick traces.
+func (l *lights) TickPhase() string { return "lights" }
+
+// scheduled reports whether the schedule of cfg has the lights on at tick:
+// from the first tick at or after 18:00 for AfterSunset ticks.
+func (l *lights) scheduled(cfg LightsConfig, tick int) bool {
+	ticksPerDay := l.dayCycle.TicksPerDay
+	sunset := (3*ticksPerDay + 3) / 4
+	return (tick%ticksPerDay-sunset+ticksPerDay)%ticksPerDay < cfg.AfterSunset
+}
@@ -0,0 +1,111 @@
+package environment
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLights_SetAndEnergy(t *testing.T) {
+	l, err := NewLights(DayCycle{}, []LightsConfig{{SectionID: "section-A", EnergyPerTick: 2}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lamp := l.Get("section-A"); lamp.On {
+		t.Errorf("expected the lights to start off, got %+v", lamp)
+	}
+	if err := l.Set("section-A", true, 0.5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := l.Set("section-B", true, 0.25); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := l.Light("section-A", 0.3); got != 0.8 {
+		t.Errorf("expected 0.3 natural and 0.5 supplemental light to make 0.8, got %.2f", got)
+	}
+	if got := l.Light("section-A", 0.7); got != 1 {
+		t.Errorf("expected the light to be capped at 1.0, got %.2f", got)
+	}
+	if got := l.Light("section-C", 0.3); got != 0.3 {
+		t.Errorf("expected only natural light without lights, got %.2f", got)
+	}
+
+	// section-A uses 0.5*2 and section-B 0.25*1, the default, per tick
+	for tick := range 3 {
+		l.OnTick(tick)
+	}
+	if err := l.Set("section-A", false, 0.5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	l.OnTick(3)
+	if got := l.Energy(); math.Abs(got-(3*1+4*0.25)) > 1e-9 {
+		t.Errorf("expected 4 energy used, got %.2f", got)
+	}
+	if got := l.Light("section-A", 0.3); got != 0.3 {
+		t.Errorf("expected lights off to add nothing, got %.2f", got)
+	}
+}
+
+func TestLights_AfterSunset(t *testing.T) {
+	l, err := NewLights(DayCycle{TicksPerDay: 24}, []LightsConfig{{SectionID: "section-A", Intensity: 0.6, AfterSunset: 4}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var on []int
+	for tick := range 48 {
+		if tick == 20 {
+			// A manual switch holds until the schedule's next end.
+			if err := l.Set("section-A", false, 0); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		l.OnTick(tick)
+		if l.Get("section-A").On {
+			on = append(on, tick)
+		}
+	}
+	expected := []int{18, 19, 42, 43, 44, 45}
+	if len(on) != len(expected) {
+		t.Fatalf("expected the lights on at ticks %v, got %v", expected, on)
+	}
+	for i := range expected {
+		if on[i] != expected[i] {
+			t.Fatalf("expected the lights on at ticks %v, got %v", expected, on)
+		}
+	}
+	if got := l.Energy(); math.Abs(got-6*0.6) > 1e-9 {
+		t.Errorf("expected 6 ticks at 0.6 to use 3.6 energy, got %.2f", got)
+	}
+}
+
+func TestNewLights_Validation(t *testing.T) {
+	day := DayCycle{TicksPerDay: 24}
+	tests := []struct {
+		name     string
+		dayCycle DayCycle
+		config   LightsConfig
+		errorMsg string
+	}{
+		{"no section", day, LightsConfig{Intensity: 1}, "lights section ID cannot be empty"},
+		{"intensity above 1", day, LightsConfig{SectionID: "a", Intensity: 1.5}, "lights intensity must be between 0.0 and 1.0"},
+		{"negative energy", day, LightsConfig{SectionID: "a", EnergyPerTick: -1}, "lights energy per tick cannot be negative: a"},
+		{"negative ticks", day, LightsConfig{SectionID: "a", AfterSunset: -1}, "lights ticks after sunset cannot be negative: a"},
+		{"schedule without intensity", day, LightsConfig{SectionID: "a", AfterSunset: 4}, "lights on after sunset require an intensity: a"},
+		{"schedule without day cycle", DayCycle{}, LightsConfig{SectionID: "a", Intensity: 1, AfterSunset: 4}, "lights on after sunset require a day cycle: a"},
+		{"schedule all day", day, LightsConfig{SectionID: "a", Intensity: 1, AfterSunset: 24}, "lights on after sunset must go off within a day: a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewLights(tt.dayCycle, []LightsConfig{tt.config})
+			if err == nil {
+				t.Fatal("expected error but got nil")
+			}
+			if err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+			}
+		})
+	}
+	if _, err := NewLights(day, []LightsConfig{{SectionID: "a"}, {SectionID: "a"}}); err == nil || err.Error() != "duplicate lights section: a" {
+		t.Errorf("expected a duplicate section error, got %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package environment
+
+import (
+	"cmp"
+	"errors"
+)
+
+// ClimateOffset is the microclimate of a section: how its air differs from
+// the greenhouse-wide conditions. Temperature, in degrees, and Humidity are
+// added to the greenhouse's, and its natural light is multiplied by Light,
+// which leaves it as it is when zero. The zero value is no offset.
+type ClimateOffset struct {
+	Temperature float64
+	Humidity    float64
+	Light       float64
+}
+
+// Validate checks that the humidity offset is between -1.0 and 1.0 and that
+// the light factor is not negative.
+func (o ClimateOffset) Validate() error {
+	if o.Humidity < -1 || o.Humidity > 1 {
+		return errors.New("humidity offset must be between -1.0 and 1.0")
+	}
+	if o.Light < 0 {
+		return errors.New("light factor cannot be negative")
+	}
+	return nil
+}
+
+// Apply returns the conditions of a section with the offset, keeping the
+// humidity and light within 0.0-1.0.
+func (o ClimateOffset) Apply(c Conditions) Conditions {
+	c.Temperature += o.Temperature
+	c.Humidity = min(1, max(0, c.Humidity+o.Humidity))
+	c.Light = o.ApplyLight(c.Light)
+	return c
+}
+
+// ApplyLight returns the natural light of a section with the offset, at most
+// 1.0.
+func (o ClimateOffset) ApplyLight(light float64) float64 {
+	return min(1, light*cmp.Or(o.Light, 1))
+}
@@ -0,0 +1,52 @@
+package environment
+
+import "testing"
+
+func TestClimateOffset_Apply(t *testing.T) {
+	conditions := Conditions{Temperature: 12, Humidity: 0.6, Light: 0.5}
+	tests := []struct {
+		name     string
+		offset   ClimateOffset
+		expected Conditions
+	}{
+		{"none", ClimateOffset{}, conditions},
+		{"cold and dry", ClimateOffset{Temperature: -4, Humidity: -0.25, Light: 0.5}, Conditions{Temperature: 8, Humidity: 0.35, Light: 0.25}},
+		{"clamped", ClimateOffset{Humidity: 0.6, Light: 3}, Conditions{Temperature: 12, Humidity: 1, Light: 1}},
+		{"clamped dry", ClimateOffset{Humidity: -0.8}, Conditions{Temperature: 12, Humidity: 0, Light: 0.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.offset.Apply(conditions); got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestClimateOffset_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		offset   ClimateOffset
+		errorMsg string
+	}{
+		{"valid", ClimateOffset{Temperature: -10, Humidity: -1, Light: 0.2}, ""},
+		{"humidity above 1", ClimateOffset{Humidity: 1.1}, "humidity offset must be between -1.0 and 1.0"},
+		{"negative light", ClimateOffset{Light: -1}, "light factor cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.offset.Validate()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package environment
+
+import (
+	"errors"
+	"maps"
+	"sync"
+)
+
+// SalinityConfig configures the salt build-up in the soil of the sections.
+// Irrigation water carries salts that stay behind in the soil as the plants
+// take the water up, so a section grows saltier with every watering until a
+// flush or rain washes the salts out. A zero Concentration adds no salt.
+type SalinityConfig struct {
+	// Concentration is the salinity a unit of water adds to the soil of the
+	// section it is applied to.
+	Concentration float64
+	// FlushAmount is the water a section must take within a tick for the
+	// watering to flush the soil instead of salting it; 0 for no flushes.
+	FlushAmount float64
+	// FlushRate is the fraction of its salinity a flush washes out, 0.0 to
+	// 1.0.
+	FlushRate float64
+	// RainLeach is the fraction of its salinity a tick of rain washes out,
+	// 0.0 to 1.0.
+	RainLeach float64
+	// Damage is the health a plant loses per tick for each unit of salinity
+	// above its tolerance, see models.PlantType.SalinityTolerance.
+	Damage float64
+}
+
+// Validate checks the salinity settings. Returns an error if:
+// - the concentration, flush amount or damage is negative
+// - the flush rate or rain leach is outside 0.0-1.0
+// - a flush rate is set without a flush amount
+func (c SalinityConfig) Validate() error {
+	if c.Concentration < 0 || c.FlushAmount < 0 || c.Damage < 0 {
+		return errors.New("salinity concentration, flush amount and damage cannot be negative")
+	}
+	if c.FlushRate < 0 || c.FlushRate > 1 {
+		return errors.New("salinity flush rate must be between 0.0 and 1.0")
+	}
+	if c.RainLeach < 0 || c.RainLeach > 1 {
+		return errors.New("salinity rain leach must be between 0.0 and 1.0")
+	}
+	if c.FlushRate > 0 && c.FlushAmount == 0 {
+		return errors.New("salinity flush rate requires a flush amount")
+	}
+	return nil
+}
+
+// Salinity tracks the soil salinity (0.0 to 1.0) of each greenhouse section.
+// Sections start at their initial level, 0 unless given one.
+type Salinity interface {
+	// Get returns the current salinity of a section.
+	Get(sectionID string) float64
+	// Levels returns the salinity of every section that has any, by section
+	// ID.
+	Levels() map[string]float64
+	// Water salts a section for the water it took on a tick, or flushes it
+	// when that is at least the flush amount, and reports whether it did.
+	Water(sectionID string, amount float64) bool
+	// Rain leaches every section for a tick of rain.
+	Rain()
+}
+
+type salinity struct {
+	config   SalinityConfig
+	sections map[string]float64
+	mu       sync.Mutex
+}
+
+// NewSalinity creates a salinity tracker starting every section at its
+// level in initial, 0 for sections not in it. Returns an error if:
+// - cfg is invalid, see SalinityConfig.Validate
+// - an initial level is outside 0.0-1.0
+func NewSalinity(cfg SalinityConfig, initial map[string]float64) (Salinity, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	for sectionID, level := range initial {
+		if level < 0 || level > 1 {
+			return nil, errors.New("section salinity must be between 0.0 and 1.0: " + sectionID)
+		}
+	}
+	sections := maps.Clone(initial)
+	if sections == nil {
+		sections = map[string]float64{}
+	}
+	return &salinity{config: cfg, sections: sections}, nil
+}
+
+// Get returns the current salinity of a section.
+// This method is safe for concurrent use.
+func (s *salinity) Get(sectionID string) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sections[sectionID]
+}
+
+// Levels returns a copy of the salinity of every section that has any.
+// This method is safe for concurrent use.
+func (s *salinity) Levels() map[string]float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	levels := make(map[string]float64, len(s.sections))
+	for sectionID, level := range s.sections {
+		if level > 0 {
+			levels[sectionID] = level
+		}
+	}
+	return levels
+}
+
+// Water raises the salinity of a section by the concentration for every unit
+// of amount, up to 1.0. With a flush amount set, an amount of at least it
+// washes out the flush rate of the salinity instead.
+// This method is safe for concurrent use.
+func (s *salinity) Water(sectionID string, amount float64) bool {
+	if amount <= 0 {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	level := s.sections[sectionID]
+	flushed := s.config.FlushAmount > 0 && amount >= s.config.FlushAmount
+	if flushed {
+		level -= level * s.config.FlushRate
+	} else {
+		level = min(1, level+amount*s.config.Concentration)
+	}
+	if level > 0 {
+		s.sections[sectionID] = level
+	} else {
+		delete(s.sections, sectionID)
+	}
+	return flushed
+}
+
+// Rain washes the rain leach out of the salinity of every section.
+// This method is safe for concurrent use.
+func (s *salinity) Rain() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sectionID, level := range s.sections {
+		s.sections[sectionID] = level - level*s.config.RainLeach
+	}
+}
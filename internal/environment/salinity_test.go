@@ -0,0 +1,75 @@
+package environment
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSalinity_WaterFlushAndRain(t *testing.T) {
+	s, err := NewSalinity(SalinityConfig{Concentration: 0.1, FlushAmount: 2, FlushRate: 0.5, RainLeach: 0.25}, map[string]float64{"section-B": 0.4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := s.Get("section-B"); got != 0.4 {
+		t.Errorf("expected section-B to start at 0.4, got %.3f", got)
+	}
+
+	// 0.5 and 1.5 units of water add 0.05 and 0.15.
+	if s.Water("section-A", 0.5) || s.Water("section-A", 1.5) {
+		t.Error("expected no flush below the flush amount")
+	}
+	if got := s.Get("section-A"); math.Abs(got-0.2) > 1e-9 {
+		t.Errorf("expected 0.2 after two waterings, got %.3f", got)
+	}
+	if !s.Water("section-A", 2) {
+		t.Error("expected a flush at the flush amount")
+	}
+	if got := s.Get("section-A"); math.Abs(got-0.1) > 1e-9 {
+		t.Errorf("expected the flush to wash half the salt out, got %.3f", got)
+	}
+
+	s.Rain()
+	if a, b := s.Get("section-A"), s.Get("section-B"); math.Abs(a-0.075) > 1e-9 || math.Abs(b-0.3) > 1e-9 {
+		t.Errorf("expected the rain to leach a quarter of every section, got %.3f and %.3f", a, b)
+	}
+	if levels := s.Levels(); len(levels) != 2 {
+		t.Errorf("expected the levels of two sections, got %v", levels)
+	}
+
+	for range 20 {
+		s.Water("section-C", 1)
+	}
+	if got := s.Get("section-C"); got != 1 {
+		t.Errorf("expected the salinity to stop at 1.0, got %.3f", got)
+	}
+}
+
+func TestSalinity_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   SalinityConfig
+		initial  map[string]float64
+		errorMsg string
+	}{
+		{"off", SalinityConfig{}, nil, ""},
+		{"negative damage", SalinityConfig{Damage: -1}, nil, "salinity concentration, flush amount and damage cannot be negative"},
+		{"flush rate above 1", SalinityConfig{FlushAmount: 1, FlushRate: 2}, nil, "salinity flush rate must be between 0.0 and 1.0"},
+		{"flush rate without amount", SalinityConfig{FlushRate: 0.5}, nil, "salinity flush rate requires a flush amount"},
+		{"initial level above 1", SalinityConfig{}, map[string]float64{"section-A": 2}, "section salinity must be between 0.0 and 1.0: section-A"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSalinity(tt.config, tt.initial)
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package environment
+
+import (
+	"errors"
+	"math"
+)
+
+// Season is a quarter of the simulated year.
+type Season string
+
+const (
+	Spring Season = "spring"
+	Summer Season = "summer"
+	Autumn Season = "autumn"
+	Winter Season = "winter"
+)
+
+// Seasons is an annual cycle over the simulated days. The year starts at the
+// spring equinox, is warmest with the longest days a quarter of a year later,
+// at midsummer, and coldest with the shortest days at midwinter, three
+// quarters in. Phase is the fraction of the year already elapsed at tick 0,
+// so 0.25 starts a simulation at midsummer. Each season is the quarter of
+// the year centered on its equinox or solstice. A zero TicksPerYear disables
+// the cycle.
+//
+// Over the year, the daily mean temperature moves up to TemperatureAmplitude
+// degrees above and below the baseline, and the daylight, half of the day at
+// the equinoxes, lengthens and shortens by up to DayLengthAmplitude of a day.
+// The chances of cloudy and rainy days are scaled by up to WeatherAmplitude:
+// down in summer and up in winter. Frosts are only drawn in the cold half of
+// the year and heat waves in the warm half.
+type Seasons struct {
+	TicksPerYear         int
+	Phase                float64
+	TemperatureAmplitude float64
+	DayLengthAmplitude   float64
+	WeatherAmplitude     float64
+}
+
+// Enabled reports whether the cycle has a year length configured.
+func (s Seasons) Enabled() bool {
+	return s.TicksPerYear > 0
+}
+
+// validate checks the seasons against the day cycle. Returns an error if:
+// - the year length is negative, or is not a whole number of at least 4 days
+// - the phase is outside 0.0-1.0
+// - an amplitude is negative, the day length amplitude reaches 0.5 or the
+// weather amplitude is above 1.0
+// - amplitudes are set without a year length
+func (s Seasons) validate(dayCycle DayCycle) error {
+	if s.TicksPerYear < 0 {
+		return errors.New("ticks per year cannot be negative")
+	}
+	if s.Enabled() && (!dayCycle.Enabled() || s.TicksPerYear%dayCycle.TicksPerDay != 0 || s.TicksPerYear < 4*dayCycle.TicksPerDay) {
+		return errors.New("ticks per year must be a whole number of at least 4 days")
+	}
+	if s.Phase < 0 || s.Phase >= 1 {
+		return errors.New("season phase must be between 0.0 and 1.0")
+	}
+	if s.TemperatureAmplitude < 0 || s.DayLengthAmplitude < 0 || s.WeatherAmplitude < 0 {
+		return errors.New("seasonal amplitudes cannot be negative")
+	}
+	if s.DayLengthAmplitude >= 0.5 {
+		return errors.New("seasonal day length amplitude must be below 0.5")
+	}
+	if s.WeatherAmplitude > 1 {
+		return errors.New("seasonal weather amplitude cannot be above 1.0")
+	}
+	if !s.Enabled() && (s.Phase != 0 || s.TemperatureAmplitude != 0 || s.DayLengthAmplitude != 0 || s.WeatherAmplitude != 0) {
+		return errors.New("seasons need ticks per year")
+	}
+	return nil
+}
+
+// DayOfYear returns the one-based day of the simulated year the tick falls
+// in, counted from the spring equinox, or 0 without seasons.
+func (c Climate) DayOfYear(tick int) int {
+	if !c.Seasons.Enabled() {
+		return 0
+	}
+	days := c.daysPerYear()
+	offset := int(math.Floor(c.Seasons.Phase * float64(days)))
+	return (c.DayCycle.Day(tick)+offset)%days + 1
+}
+
+// Season returns the season of the tick, empty without seasons.
+func (c Climate) Season(tick int) Season {
+	if !c.Seasons.Enabled() {
+		return ""
+	}
+	switch position := c.yearPosition(c.DayCycle.Day(tick)); {
+	case position < 0.125 || position >= 0.875:
+		return Spring
+	case position < 0.375:
+		return Summer
+	case position < 0.625:
+		return Autumn
+	}
+	return Winter
+}
+
+// DayLength returns the fraction of the tick's day that has daylight: 0.5
+// without seasons, when the sun rises at 06:00 and sets at 18:00.
+func (c Climate) DayLength(tick int) float64 {
+	return 0.5 + c.Seasons.DayLengthAmplitude*c.seasonal(c.DayCycle.Day(tick))
+}
+
+// daysPerYear returns the number of days of a year, with seasons.
+func (c Climate) daysPerYear() int {
+	return c.Seasons.TicksPerYear / c.DayCycle.TicksPerDay
+}
+
+// yearPosition returns the fraction of the year elapsed at the start of a
+// zero-based day, from 0.0 (spring equinox) up to but excluding 1.0.
+func (c Climate) yearPosition(day int) float64 {
+	position := float64(day%c.daysPerYear())/float64(c.daysPerYear()) + c.Seasons.Phase
+	return position - math.Floor(position)
+}
+
+// seasonal returns how far into summer or winter a zero-based day is, from
+// 1.0 at midsummer to -1.0 at midwinter, and 0 without seasons.
+func (c Climate) seasonal(day int) float64 {
+	if !c.Seasons.Enabled() {
+		return 0
+	}
+	return math.Sin(2 * math.Pi * c.yearPosition(day))
+}
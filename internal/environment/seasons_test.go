@@ -0,0 +1,146 @@
+package environment
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSeasons_OneYear(t *testing.T) {
+	climate := Climate{
+		DayCycle:         DayCycle{TicksPerDay: 24},
+		Temperature:      15,
+		TemperatureSwing: 4,
+		Humidity:         0.5,
+		Light:            1,
+		Seasons:          Seasons{TicksPerYear: 24 * 100, TemperatureAmplitude: 10, DayLengthAmplitude: 0.25},
+	}
+
+	// Sum up every day of the year at hourly resolution.
+	means := make([]float64, 100)
+	lit := make([]int, 100)
+	seasons := map[Season]int{}
+	for tick := range climate.Seasons.TicksPerYear {
+		conditions := climate.At(tick)
+		day := tick / 24
+		means[day] += conditions.Temperature / 24
+		if conditions.Light > 0 {
+			lit[day]++
+		}
+		if conditions.DayOfYear != day+1 {
+			t.Fatalf("tick %d: expected day %d of the year, got %d", tick, day+1, conditions.DayOfYear)
+		}
+		seasons[conditions.Season]++
+	}
+
+	tests := []struct {
+		name              string
+		day               int
+		expectedMean      float64
+		expectedDayLength float64
+		expectedSeason    Season
+	}{
+		{"spring equinox", 0, 15, 0.5, Spring},
+		{"midsummer", 25, 25, 0.75, Summer},
+		{"autumn equinox", 50, 15, 0.5, Autumn},
+		{"midwinter", 75, 5, 0.25, Winter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if math.Abs(means[tt.day]-tt.expectedMean) > 1e-9 {
+				t.Errorf("expected a daily mean of %.2f, got %.2f", tt.expectedMean, means[tt.day])
+			}
+			if got := climate.DayLength(tt.day * 24); math.Abs(got-tt.expectedDayLength) > 1e-9 {
+				t.Errorf("expected a day length of %.2f, got %.2f", tt.expectedDayLength, got)
+			}
+			// Light at the hours strictly between sunrise and sunset.
+			if hours := float64(lit[tt.day]); math.Abs(hours-tt.expectedDayLength*24) > 1 {
+				t.Errorf("expected about %.0f hours of light, got %.0f", tt.expectedDayLength*24, hours)
+			}
+			if got := climate.Season(tt.day * 24); got != tt.expectedSeason {
+				t.Errorf("expected %s, got %s", tt.expectedSeason, got)
+			}
+		})
+	}
+	for _, season := range []Season{Spring, Summer, Autumn, Winter} {
+		if seasons[season] != 25*24 {
+			t.Errorf("expected %s to last a quarter of the year, got %d ticks", season, seasons[season])
+		}
+	}
+
+	// The second year repeats the first.
+	if got, expected := climate.At(climate.Seasons.TicksPerYear+25*24+12), climate.At(25*24+12); got != expected {
+		t.Errorf("expected the year to repeat, got %+v and %+v", got, expected)
+	}
+}
+
+func TestSeasons_Phase(t *testing.T) {
+	climate := Climate{
+		DayCycle: DayCycle{TicksPerDay: 10},
+		Seasons:  Seasons{TicksPerYear: 10 * 40, Phase: 0.25},
+	}
+	if season, day := climate.Season(0), climate.DayOfYear(0); season != Summer || day != 11 {
+		t.Errorf("expected a quarter year in to start at midsummer on day 11, got %s on day %d", season, day)
+	}
+	if season, day := climate.Season(30*10), climate.DayOfYear(30*10); season != Spring || day != 1 {
+		t.Errorf("expected the year to wrap to the spring equinox, got %s on day %d", season, day)
+	}
+
+	var without Climate
+	if season, day := without.Season(100), without.DayOfYear(100); season != "" || day != 0 {
+		t.Errorf("expected no season without seasons, got %q on day %d", season, day)
+	}
+}
+
+func TestSeasons_Weather(t *testing.T) {
+	climate := Climate{
+		DayCycle:       DayCycle{TicksPerDay: 10},
+		Seed:           7,
+		Humidity:       0.6,
+		Light:          0.8,
+		CloudyChance:   0.3,
+		RainChance:     0.3,
+		FrostChance:    0.1,
+		HeatWaveChance: 0.1,
+		ExtremeTicks:   5,
+		Seasons:        Seasons{TicksPerYear: 10 * 100, WeatherAmplitude: 1},
+	}
+
+	// Ten years of weather: clear at midsummer, twice the chances at
+	// midwinter, and extremes only in their half of the year.
+	counts := map[Season]map[Weather]int{}
+	frosts, heatWaves := 0, 0
+	for day := range 1000 {
+		season := climate.Season(day * 10)
+		if counts[season] == nil {
+			counts[season] = map[Weather]int{}
+		}
+		counts[season][climate.WeatherOn(day)]++
+		seasonal := climate.seasonal(day)
+		switch climate.ExtremeOn(day) {
+		case Frost:
+			frosts++
+			if seasonal >= 0 {
+				t.Errorf("day %d: expected frosts only in the cold half of the year, got one at %.2f", day, seasonal)
+			}
+		case HeatWave:
+			heatWaves++
+			if seasonal <= 0 {
+				t.Errorf("day %d: expected heat waves only in the warm half of the year, got one at %.2f", day, seasonal)
+			}
+		}
+	}
+	if frosts == 0 || heatWaves == 0 {
+		t.Errorf("expected both extremes within ten years, got %d frosts and %d heat waves", frosts, heatWaves)
+	}
+	if clear := counts[Summer][Clear]; clear < 200 {
+		t.Errorf("expected mostly clear summer days, got %d of 250", clear)
+	}
+	if clear := counts[Winter][Clear]; clear > 30 {
+		t.Errorf("expected hardly a clear winter day, got %d of 250", clear)
+	}
+	for day := range 100 {
+		if climate.seasonal(day) == 1 && climate.WeatherOn(day) != Clear {
+			t.Errorf("day %d: expected midsummer to be clear", day)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package environment
+
+import (
+	"errors"
+	"sync"
+)
+
+// SoilTemperatureConfig configures the root-zone temperature of the
+// sections. The soil lags behind the air above it: on every tick it keeps
+// Inertia of its temperature and takes the rest from the air, so a step in
+// the air temperature reaches the roots over about 1/(1-Inertia) ticks. The
+// zero config keeps the soil at the air temperature.
+type SoilTemperatureConfig struct {
+	// Inertia is the share of its temperature the soil keeps on a tick, 0.0
+	// to below 1.0.
+	Inertia float64
+	// WaterTemperature is the temperature of the irrigation water in
+	// Celsius. The soil of a section moves WaterExchange of the way to it
+	// for each unit of water it takes on a tick, all the way at most; a zero
+	// WaterExchange leaves the watering out.
+	WaterTemperature float64
+	WaterExchange    float64
+}
+
+// Validate checks the soil temperature settings. Returns an error if:
+// - the inertia is outside 0.0 to below 1.0
+// - the water exchange is outside 0.0-1.0
+func (c SoilTemperatureConfig) Validate() error {
+	if c.Inertia < 0 || c.Inertia >= 1 {
+		return errors.New("soil temperature inertia must be between 0.0 and below 1.0")
+	}
+	if c.WaterExchange < 0 || c.WaterExchange > 1 {
+		return errors.New("soil temperature water exchange must be between 0.0 and 1.0")
+	}
+	return nil
+}
+
+// SoilTemperature tracks the root-zone temperature in Celsius of each
+// greenhouse section. A section starts at the air temperature of its first
+// update.
+type SoilTemperature interface {
+	// Get returns the current soil temperature of a section, and whether
+	// the section was updated yet.
+	Get(sectionID string) (float64, bool)
+	// Update moves the soil temperature of a section for a tick with the
+	// given air temperature and the water the section took.
+	Update(sectionID string, air, water float64)
+}
+
+type soilTemperature struct {
+	config   SoilTemperatureConfig
+	sections map[string]float64
+	mu       sync.Mutex
+}
+
+// NewSoilTemperature creates a soil temperature tracker. Returns an error if
+// cfg is invalid, see SoilTemperatureConfig.Validate.
+func NewSoilTemperature(cfg SoilTemperatureConfig) (SoilTemperature, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &soilTemperature{config: cfg, sections: map[string]float64{}}, nil
+}
+
+// Get returns the current soil temperature of a section, and whether the
+// section was updated yet.
+// This method is safe for concurrent use.
+func (s *soilTemperature) Get(sectionID string) (float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	temperature, ok := s.sections[sectionID]
+	return temperature, ok
+}
+
+// Update keeps the inertia of the soil temperature of a section and takes
+// the rest from air, then moves it towards the water temperature for the
+// water the section took.
+// This method is safe for concurrent use.
+func (s *soilTemperature) Update(sectionID string, air, water float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	temperature, ok := s.sections[sectionID]
+	if !ok {
+		temperature = air
+	}
+	temperature = s.config.Inertia*temperature + (1-s.config.Inertia)*air
+	if water > 0 && s.config.WaterExchange > 0 {
+		temperature += min(1, water*s.config.WaterExchange) * (s.config.WaterTemperature - temperature)
+	}
+	s.sections[sectionID] = temperature
+}
@@ -0,0 +1,93 @@
+package environment
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSoilTemperature_LagsAirStep(t *testing.T) {
+	s, err := NewSoilTemperature(SoilTemperatureConfig{Inertia: 0.9})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := s.Get("section-A"); ok {
+		t.Error("expected no soil temperature before the first update")
+	}
+	s.Update("section-A", 10, 0)
+	if got, _ := s.Get("section-A"); got != 10 {
+		t.Errorf("expected the soil to start at the air temperature, got %.3f", got)
+	}
+
+	// The air steps from 10 to 20: the soil closes a tenth of the gap on
+	// every tick, so 10 ticks later it is 10*0.9^10 short of the air.
+	previous := 10.0
+	for tick := 1; tick <= 10; tick++ {
+		s.Update("section-A", 20, 0)
+		got, _ := s.Get("section-A")
+		if got <= previous || got >= 20 {
+			t.Fatalf("tick %d: expected the soil to warm towards the air, got %.3f after %.3f", tick, got, previous)
+		}
+		previous = got
+	}
+	if expected := 20 - 10*math.Pow(0.9, 10); math.Abs(previous-expected) > 1e-9 {
+		t.Errorf("expected %.3f after 10 ticks, got %.3f", expected, previous)
+	}
+}
+
+func TestSoilTemperature_NoInertiaFollowsAir(t *testing.T) {
+	s, err := NewSoilTemperature(SoilTemperatureConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, air := range []float64{10, 25, -3} {
+		s.Update("section-A", air, 1)
+		if got, _ := s.Get("section-A"); got != air {
+			t.Errorf("expected the soil at the air temperature %.1f, got %.3f", air, got)
+		}
+	}
+}
+
+func TestSoilTemperature_IrrigationWater(t *testing.T) {
+	s, err := NewSoilTemperature(SoilTemperatureConfig{WaterTemperature: 20, WaterExchange: 0.2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Half a unit of water at 20 moves soil at 10 a tenth of the way.
+	s.Update("section-A", 10, 0.5)
+	if got, _ := s.Get("section-A"); math.Abs(got-11) > 1e-9 {
+		t.Errorf("expected 11 after watering, got %.3f", got)
+	}
+	// Plenty of water takes the soil all the way, no further.
+	s.Update("section-B", 10, 100)
+	if got, _ := s.Get("section-B"); got != 20 {
+		t.Errorf("expected the water temperature, got %.3f", got)
+	}
+}
+
+func TestSoilTemperature_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   SoilTemperatureConfig
+		errorMsg string
+	}{
+		{"off", SoilTemperatureConfig{}, ""},
+		{"negative inertia", SoilTemperatureConfig{Inertia: -0.1}, "soil temperature inertia must be between 0.0 and below 1.0"},
+		{"frozen soil", SoilTemperatureConfig{Inertia: 1}, "soil temperature inertia must be between 0.0 and below 1.0"},
+		{"water exchange above 1", SoilTemperatureConfig{WaterExchange: 1.5}, "soil temperature water exchange must be between 0.0 and 1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package environment
+
+import (
+	"errors"
+	"sync"
+)
+
+// WaterTemperatureConfig configures the temperature of the irrigation water
+// in the water supply, the tank or the mains. The water drifts towards the
+// air of the greenhouse: on every tick it keeps Inertia of its temperature
+// and takes the rest from the air. The zero config keeps the water at the
+// air temperature.
+type WaterTemperatureConfig struct {
+	// Initial is the temperature of the water in Celsius until the first
+	// update, nil for water at the air temperature of the first update.
+	Initial *float64
+	// Inertia is the share of its temperature the water keeps on a tick, 0.0
+	// to 1.0, 1.0 for water that never drifts.
+	Inertia float64
+	// ShockDamage is the health a plant loses when watered for each degree
+	// the water differs from its soil beyond its tolerance, see
+	// models.PlantType.ThermalShockTolerance.
+	ShockDamage float64
+}
+
+// Validate checks the water temperature settings. Returns an error if:
+// - the inertia is outside 0.0-1.0
+// - the shock damage is negative
+func (c WaterTemperatureConfig) Validate() error {
+	if c.Inertia < 0 || c.Inertia > 1 {
+		return errors.New("water temperature inertia must be between 0.0 and 1.0")
+	}
+	if c.ShockDamage < 0 {
+		return errors.New("water temperature shock damage cannot be negative")
+	}
+	return nil
+}
+
+// WaterTemperature tracks the temperature in Celsius of the irrigation
+// water. A heater, as in a heated tank, holds the water at its setpoint
+// while on.
+type WaterTemperature interface {
+	// Get returns the current water temperature, and whether it is known
+	// yet: the setpoint of the heater while on, and otherwise the initial
+	// temperature until the first update.
+	Get() (float64, bool)
+	// Update moves the water temperature for a tick with the given air
+	// temperature, unless the heater is on.
+	Update(air float64)
+	// Heat switches the heater on, holding the water at temperature.
+	Heat(temperature float64)
+	// StopHeating switches the heater off, so that the water drifts
+	// towards the air again from its setpoint.
+	StopHeating()
+	// Heater returns the setpoint of the heater, and whether it is on.
+	Heater() (float64, bool)
+}
+
+type waterTemperature struct {
+	config      WaterTemperatureConfig
+	temperature float64
+	known       bool
+	heated      bool
+	mu          sync.Mutex
+}
+
+// NewWaterTemperature creates a water temperature tracker. Returns an error
+// if cfg is invalid, see WaterTemperatureConfig.Validate.
+func NewWaterTemperature(cfg WaterTemperatureConfig) (WaterTemperature, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	w := &waterTemperature{config: cfg}
+	if cfg.Initial != nil {
+		w.temperature, w.known = *cfg.Initial, true
+	}
+	return w, nil
+}
+
+// Get returns the current water temperature, and whether it is known yet.
+// This method is safe for concurrent use.
+func (w *waterTemperature) Get() (float64, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.temperature, w.known
+}
+
+// Update keeps the inertia of the water temperature and takes the rest from
+// air, unless the heater is on. Water of unknown temperature starts at air.
+// This method is safe for concurrent use.
+func (w *waterTemperature) Update(air float64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.heated {
+		return
+	}
+	if !w.known {
+		w.temperature, w.known = air, true
+	}
+	w.temperature = w.config.Inertia*w.temperature + (1-w.config.Inertia)*air
+}
+
+// Heat switches the heater on at temperature.
+// This method is safe for concurrent use.
+func (w *waterTemperature) Heat(temperature float64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.temperature, w.known, w.heated = temperature, true, true
+}
+
+// StopHeating switches the heater off. Switching it off twice does nothing.
+// This method is safe for concurrent use.
+func (w *waterTemperature) StopHeating() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.heated = false
+}
+
+// Heater returns the setpoint of the heater, and whether it is on.
+// This method is safe for concurrent use.
+func (w *waterTemperature) Heater() (float64, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.temperature, w.heated
+}
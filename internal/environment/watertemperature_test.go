@@ -0,0 +1,53 @@
+package environment
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWaterTemperature_DriftsTowardsAir(t *testing.T) {
+	initial := 8.0
+	w, err := NewWaterTemperature(WaterTemperatureConfig{Initial: &initial, Inertia: 0.5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, ok := w.Get(); !ok || got != 8 {
+		t.Fatalf("expected the water at its initial 8°C, got %.3f", got)
+	}
+	// The water closes half the gap to the air on every tick.
+	for tick, expected := range []float64{14, 17, 18.5} {
+		w.Update(20)
+		if got, _ := w.Get(); math.Abs(got-expected) > 1e-9 {
+			t.Errorf("tick %d: expected the water at %.3f, got %.3f", tick, expected, got)
+		}
+	}
+
+	w.Heat(30)
+	w.Update(20)
+	if got, heated := w.Heater(); got != 30 || !heated {
+		t.Errorf("expected the heater to hold the water at 30°C, got %.3f and %v", got, heated)
+	}
+	w.StopHeating()
+	w.Update(20)
+	if got, _ := w.Get(); got != 25 {
+		t.Errorf("expected the water to drift from the setpoint once the heater is off, got %.3f", got)
+	}
+}
+
+func TestWaterTemperature_DefaultsToAir(t *testing.T) {
+	w, err := NewWaterTemperature(WaterTemperatureConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := w.Get(); ok {
+		t.Error("expected no water temperature before the first update")
+	}
+	w.Update(12)
+	if got, _ := w.Get(); got != 12 {
+		t.Errorf("expected the water at the air temperature, got %.3f", got)
+	}
+
+	if _, err := NewWaterTemperature(WaterTemperatureConfig{Inertia: 1.5}); err == nil || err.Error() != "water temperature inertia must be between 0.0 and 1.0" {
+		t.Errorf("expected an inertia error, got %v", err)
+	}
+}
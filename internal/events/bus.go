@@ -0,0 +1,154 @@
+package events
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Type identifies the kind of an Event.
+type Type string
+
+const (
+	// WateringStarted is emitted when a watering event begins applying water.
+	WateringStarted Type = "watering_started"
+	// WateringCompleted is emitted once a watering event has applied all of its water.
+	WateringCompleted Type = "watering_completed"
+	// WateringCancelled is emitted when an active watering event is aborted before completing.
+	WateringCancelled Type = "watering_cancelled"
+	// WateringPaused is emitted when an active watering event is suspended.
+	WateringPaused Type = "watering_paused"
+	// WateringResumed is emitted when a paused watering event continues.
+	WateringResumed Type = "watering_resumed"
+	// WateringSkipped is emitted when a scheduled event is dropped because the tank cannot cover it.
+	WateringSkipped Type = "watering_skipped"
+	// LowWater is emitted when the water tank level drops below its low water threshold.
+	LowWater Type = "low_water"
+	// ConfigReloaded is emitted when a new greenhouse config has been applied to the running simulation.
+	ConfigReloaded Type = "config_reloaded"
+	// TimelineAction is emitted after a scripted timeline action has run, whether or not it succeeded.
+	TimelineAction Type = "timeline_action"
+	// Tick is emitted once every listener has handled a tick, with a summary of the greenhouse.
+	Tick Type = "tick"
+	// SensorSample is emitted after every tick for each sensor that can be read, with its reading.
+	SensorSample Type = "sensor_sample"
+	// PlantAdded is emitted when a plant joins the running simulation.
+	PlantAdded Type = "plant_added"
+	// PlantRemoved is emitted when a plant is taken out of the running simulation, with its final *models.Plant when the dead plant retention removed it.
+	PlantRemoved Type = "plant_removed"
+	// PlantDied is emitted on the first tick a plant is found dead, with its models.DeathCause.
+	PlantDied Type = "plant_died"
+	// ReplayCompleted is emitted once a replay has played its last recorded tick.
+	ReplayCompleted Type = "replay_completed"
+	// ExtremeWeatherStarted is emitted on the first tick of a frost or heat wave, with the environment.ExtremeEvent.
+	ExtremeWeatherStarted Type = "extreme_weather_started"
+	// ExtremeWeatherEnded is emitted on the first tick after a frost or heat wave, with the environment.ExtremeEvent.
+	ExtremeWeatherEnded Type = "extreme_weather_ended"
+	// PlantInfected is emitted when a plant catches a disease, with its models.Disease.
+	PlantInfected Type = "plant_infected"
+	// PlantSymptomatic is emitted on the first tick a diseased plant shows symptoms, with its models.Disease.
+	PlantSymptomatic Type = "plant_symptomatic"
+	// PlantCured is emitted when a treatment cures a diseased plant.
+	PlantCured Type = "plant_cured"
+	// Germinated is emitted when a seed sprouts at the end of its germination, with its models.Germination.
+	Germinated Type = "germinated"
+	// GerminationFailed is emitted when a seed dies at the end of its germination, with its models.Germination.
+	GerminationFailed Type = "germination_failed"
+	// InvariantViolated is emitted at the end of a tick for each invariant it broke, with the engine.InvariantViolation, when invariants are checked with events.
+	InvariantViolated Type = "invariant_violated"
+	// SimulatorStateChanged is emitted when the simulator is started, paused, resumed or stopped, with the engine.StateChange.
+	SimulatorStateChanged Type = "simulator_state_changed"
+	// PlantStageChanged is emitted when a plant enters a new stage of life, with a models.PlantChange from and to the models.PlantStage and the growth stage, when the plant journal is kept.
+	PlantStageChanged Type = "plant_stage_changed"
+	// PlantHealthChanged is emitted when the health of a plant crosses one of the models.HealthBands, with a models.PlantChange from and to the band labels and the health, when the plant journal is kept.
+	PlantHealthChanged Type = "plant_health_changed"
+	// PlantStressStarted is emitted when the roots of a living plant leave the saturation range of its type, with a models.PlantChange to the models.Stress and the root saturation, when the plant journal is kept.
+	PlantStressStarted Type = "plant_stress_started"
+	// PlantStressEnded is emitted when the roots of a stressed plant are back in range, with a models.PlantChange from the models.Stress and the root saturation, when the plant journal is kept.
+	PlantStressEnded Type = "plant_stress_ended"
+	// PlantWatered is emitted for each plant a watering event watered once the event completes or is cancelled, with a models.PlantChange to the event ID and the water the soil took, when the plant journal is kept.
+	PlantWatered Type = "plant_watered"
+	// Alert is emitted on the first tick an alert rule on the health of the simulator fires, and whenever its escalation notifies it again, with the greenhouse.Alert.
+	Alert Type = "alert"
+	// AlertResolved is emitted on the first tick a fired alert rule no longer holds, with the greenhouse.Alert.
+	AlertResolved Type = "alert_resolved"
+	// IdlePaused is emitted on the tick the idle watch pauses the simulation because nothing consumed it, with the number of idle ticks, see greenhouse.Greenhouse.Touch.
+	IdlePaused Type = "idle_paused"
+	// ChaosFault is emitted for every fault chaos mode injects, with the greenhouse.ChaosFault, see config.ChaosConfig.
+	ChaosFault Type = "chaos_fault"
+	// PlantingScheduled is emitted when the next crop of a section's rotation is scheduled, with the greenhouse.RotationPlanting, see config.RotationConfig.
+	PlantingScheduled Type = "planting_scheduled"
+	// Planted is emitted when a crop of a rotation is planted, with the greenhouse.RotationPlanting listing its plants.
+	Planted Type = "planted"
+)
+
+// Event is a notification emitted by the simulation. SectionID and PlantID are
+// set when the event concerns a specific section or plant, and Payload carries
+// the event-specific data (for watering events, the models.WateringEvent).
+type Event struct {
+	Type      Type
+	Tick      int
+	Timestamp time.Time
+	SectionID string
+	PlantID   string
+	Payload   any
+}
+
+// Handler receives published events.
+type Handler func(Event)
+
+// Bus delivers published events to every subscribed handler.
+type Bus interface {
+	// Publish delivers the event synchronously to all current subscribers,
+	// in subscription order.
+	Publish(e Event)
+	// Subscribe registers a handler and returns a function that removes it.
+	Subscribe(h Handler) (unsubscribe func())
+}
+
+// subscription is a handler with the ID its unsubscribe function removes.
+type subscription struct {
+	id      int
+	handler Handler
+}
+
+// bus keeps its subscriptions in subscription order. The slice is replaced,
+// never changed in place, so that Publish can iterate it without copying.
+type bus struct {
+	subscriptions []subscription
+	nextID        int
+	mu            sync.RWMutex
+}
+
+// NewBus creates an empty event bus that is safe for concurrent use.
+func NewBus() Bus {
+	return &bus{}
+}
+
+// Publish delivers the event to every subscriber in the order they subscribed.
+// Handlers are called without holding the bus lock, so they may subscribe or
+// unsubscribe while handling an event.
+func (b *bus) Publish(e Event) {
+	b.mu.RLock()
+	subscriptions := b.subscriptions
+	b.mu.RUnlock()
+
+	for _, s := range subscriptions {
+		s.handler(e)
+	}
+}
+
+// Subscribe registers h to receive every subsequently published event.
+// Calling the returned function more than once has no further effect.
+func (b *bus) Subscribe(h Handler) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.nextID
+	b.nextID++
+	b.subscriptions = append(slices.Clip(b.subscriptions), subscription{id: id, handler: h})
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.subscriptions = slices.DeleteFunc(slices.Clone(b.subscriptions), func(s subscription) bool { return s.id == id })
+	}
+}
@@ -0,0 +1,373 @@
+package greenhouse
+
+import (
+	"cmp"
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Alert is an alert on the health of the simulator rather than on the
+// plants, see config.AlertsConfig, and the payload of the Alert and
+// AlertResolved events. Rule names the config.AlertRules entry that fired,
+// SectionID the section for the alerts of a section, SensorID the sensor, in
+// its section, for the alerts of a sensor, and ZoneID the zone for the rules
+// scoped to a zone, see config.AlertsConfig.Zones. Since is the tick the
+// alert fired, LastNotified the tick it was last notified and Notifications
+// the number of times it was, see config.EscalationConfig. Severity is its
+// current severity, Value what the rule measured on the tick it was last
+// notified, and Threshold the threshold it crossed.
+type Alert struct {
+	Rule          string  `json:"rule"`
+	Severity      string  `json:"severity"`
+	SectionID     string  `json:"section,omitempty"`
+	SensorID      string  `json:"sensor,omitempty"`
+	ZoneID        string  `json:"zone,omitempty"`
+	Message       string  `json:"message"`
+	Value         float64 `json:"value"`
+	Threshold     float64 `json:"threshold"`
+	Since         int     `json:"since"`
+	LastNotified  int     `json:"last_notified"`
+	Notifications int     `json:"notifications"`
+}
+
+func (a Alert) key() string {
+	return a.Rule + "/" + a.ZoneID + "/" + a.SectionID + "/" + a.SensorID
+}
+
+// alertCounters are the cumulative counters the windowed rules look at.
+type alertCounters struct {
+	overruns int
+	dropped  int
+}
+
+// alerts evaluates the alert rules of the current config after every tick,
+// publishing an Alert event when a rule starts to hold and an AlertResolved
+// event when it stops, so a rule that keeps holding alerts once unless its
+// escalation notifies it again, with another Alert event. Reloaded rules
+// apply from the next tick on, and the alerts of a rule that is disabled or
+// removed are resolved.
+type alerts struct {
+	g *greenhouse
+	// counters reads the tick overruns and export drops so far.
+	counters func() alertCounters
+	// history holds the counters of the ticks of the window, oldest first,
+	// after the counters of the tick before it.
+	history []alertCounters
+	// active holds the alerts that fired and are not resolved, by key.
+	active map[string]Alert
+	mu     sync.Mutex
+}
+
+func newAlerts(g *greenhouse) *alerts {
+	a := &alerts{g: g, active: map[string]Alert{}}
+	a.counters = g.alertCounters
+	a.history = []alertCounters{a.counters()}
+	return a
+}
+
+// alertCounters sums the tick overruns of the simulator and the items
+// dropped by every exporter.
+func (g *greenhouse) alertCounters() alertCounters {
+	counters := alertCounters{overruns: g.sim.TickTiming().Overruns}
+	for _, stats := range g.export.Stats() {
+		counters.dropped += stats.Dropped
+	}
+	return counters
+}
+
+// TickPhase names the alerts in tick traces.
+func (a *alerts) TickPhase() string { return "alerts" }
+
+func (a *alerts) OnTick(tick int) {
+	cfg := config.AlertsConfig{}
+	enabled := a.g.Config().Alerts
+	if enabled != nil {
+		cfg = *enabled
+	}
+	cfg = cfg.WithDefaults()
+	a.history = append(a.history, a.counters())
+	if excess := len(a.history) - cfg.Window - 1; excess > 0 {
+		a.history = slices.Delete(a.history, 0, excess)
+	}
+	a.mu.Lock()
+	idle := enabled == nil && len(a.active) == 0
+	a.mu.Unlock()
+	if idle {
+		return
+	}
+
+	var firing []Alert
+	if enabled != nil {
+		firing = a.evaluate(cfg, tick)
+	}
+	// The events are published once the alerts are updated, so that the
+	// subscribers see them in ActiveAlerts.
+	type notification struct {
+		eventType events.Type
+		alert     Alert
+	}
+	var notifications []notification
+	a.mu.Lock()
+	fired := map[string]bool{}
+	for _, alert := range firing {
+		key := alert.key()
+		fired[key] = true
+		active, ok := a.active[key]
+		if !ok {
+			alert.Since, alert.LastNotified, alert.Notifications = tick, tick, 1
+			a.active[key] = alert
+			notifications = append(notifications, notification{events.Alert, alert})
+			continue
+		}
+		if a.escalate(&active, alert, ruleOf(cfg, alert), tick) {
+			a.active[key] = active
+			notifications = append(notifications, notification{events.Alert, active})
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(a.active)) {
+		if fired[key] {
+			continue
+		}
+		alert := a.active[key]
+		delete(a.active, key)
+		notifications = append(notifications, notification{events.AlertResolved, alert})
+	}
+	a.mu.Unlock()
+	for _, n := range notifications {
+		a.publish(n.eventType, tick, n.alert)
+	}
+}
+
+// ruleOf returns the rule of the config that raised an alert, that of its
+// zone for a zone alert.
+func ruleOf(cfg config.AlertsConfig, alert Alert) config.AlertRuleConfig {
+	if alert.ZoneID != "" {
+		rule, _ := cfg.ZoneRule(alert.ZoneID, alert.Rule)
+		return rule
+	}
+	return cfg.Rules[alert.Rule]
+}
+
+// escalate applies the escalation of its rule to an active alert that still
+// holds on tick, as current measures it, and reports whether the alert is to
+// be notified again: it is upgraded once it has been firing for
+// EscalateAfter ticks, and notified every RenotifyTicks ticks since it was
+// last notified.
+func (a *alerts) escalate(active *Alert, current Alert, rule config.AlertRuleConfig, tick int) bool {
+	escalation := rule.Escalation
+	if escalation == nil {
+		return false
+	}
+	notify := escalation.RenotifyTicks > 0 && tick-active.LastNotified >= escalation.RenotifyTicks
+	if escalation.EscalateAfter > 0 && tick-active.Since >= escalation.EscalateAfter {
+		severity := cmp.Or(escalation.EscalateTo, config.SeverityCritical)
+		if config.SeverityRank(severity) > config.SeverityRank(active.Severity) {
+			active.Severity = severity
+			notify = true
+		}
+	}
+	if !notify {
+		return false
+	}
+	active.Message, active.Value, active.Threshold = current.Message, current.Value, current.Threshold
+	active.LastNotified = tick
+	active.Notifications++
+	return true
+}
+
+// list returns the active alerts, ordered by key.
+// This method is safe for concurrent use.
+func (a *alerts) list() []Alert {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	list := make([]Alert, 0, len(a.active))
+	for _, key := range slices.Sorted(maps.Keys(a.active)) {
+		list = append(list, a.active[key])
+	}
+	return list
+}
+
+// ActiveAlerts returns the alerts that fired and are not resolved yet,
+// ordered by rule, zone, section and sensor.
+// This method is safe for concurrent use.
+func (g *greenhouse) ActiveAlerts() []Alert {
+	return g.alerts.list()
+}
+
+// evaluate returns the alerts of the enabled rules that hold on tick, in rule
+// order, followed by those of the zones, ordered by zone ID.
+func (a *alerts) evaluate(cfg config.AlertsConfig, tick int) []Alert {
+	plants := a.g.sim.GetAllPlants()
+	stats := a.g.statsOf(engine.SumPlants(plants))
+	latest, oldest := a.history[len(a.history)-1], a.history[0]
+	ticks := len(a.history) - 1
+
+	var firing []Alert
+	for _, name := range config.AlertRules {
+		rule := cfg.Rules[name]
+		if rule.Disabled {
+			continue
+		}
+		alert := Alert{Rule: name, Severity: rule.Severity}
+		if rule.Threshold != nil {
+			alert.Threshold = *rule.Threshold
+		}
+		switch name {
+		case config.AlertTickOverruns:
+			alert.Value = float64(latest.overruns - oldest.overruns)
+			alert.Message = fmt.Sprintf("%.0f of the last %d ticks overran the tick interval", alert.Value, ticks)
+			if alert.Value > alert.Threshold {
+				firing = append(firing, alert)
+			}
+		case config.AlertExportDrops:
+			alert.Value = float64(latest.dropped - oldest.dropped)
+			alert.Message = fmt.Sprintf("exporters dropped %.0f items over the last %d ticks", alert.Value, ticks)
+			if alert.Value > alert.Threshold {
+				firing = append(firing, alert)
+			}
+		case config.AlertTankEmpty:
+			if stats.TankRemaining == nil {
+				continue
+			}
+			alert.Value = *stats.TankRemaining
+			alert.Message = fmt.Sprintf("water tank is empty, %.2f left", alert.Value)
+			if alert.Value <= alert.Threshold {
+				firing = append(firing, alert)
+			}
+		case config.AlertDeadPlants:
+			if stats.Plants == 0 {
+				continue
+			}
+			alert.Value = 100 * float64(stats.Plants-stats.AlivePlants) / float64(stats.Plants)
+			alert.Message = fmt.Sprintf("%.0f%% of the plants are dead", alert.Value)
+			if alert.Value > alert.Threshold {
+				firing = append(firing, alert)
+			}
+		case config.AlertUnmonitoredSection:
+			for _, sectionID := range a.unmonitoredSections(plants) {
+				alert.SectionID = sectionID
+				alert.Message = "section has plants but no sensors: " + sectionID
+				firing = append(firing, alert)
+			}
+		case config.AlertLowBattery:
+			for _, sensor := range a.g.sensors.ListSensors() {
+				if sensor.Battery == nil || sensor.Battery.Level > alert.Threshold {
+					continue
+				}
+				alert.SectionID, alert.SensorID = sensor.SectionID, sensor.ID
+				alert.Value = sensor.Battery.Level
+				alert.Message = fmt.Sprintf("battery of sensor %s is at %.0f%%", sensor.ID, 100*alert.Value)
+				if sensor.Battery.Depleted() {
+					alert.Message = fmt.Sprintf("battery of sensor %s is depleted", sensor.ID)
+				}
+				firing = append(firing, alert)
+			}
+		case config.AlertSaturationDrop:
+			for _, sensor := range a.g.sensors.ListSensors() {
+				if sensor.Type != models.SoilMoisture {
+					continue
+				}
+				sample, ok := a.latestSample(sensor, tick)
+				if !ok || !sample.HasDelta || -sample.Rate <= alert.Threshold {
+					continue
+				}
+				alert.SectionID, alert.SensorID = sensor.SectionID, sensor.ID
+				alert.Value = -sample.Rate
+				alert.Message = fmt.Sprintf("saturation read by sensor %s drops %.3f per tick", sensor.ID, alert.Value)
+				firing = append(firing, alert)
+			}
+		}
+	}
+	for _, zoneID := range slices.Sorted(maps.Keys(cfg.Zones)) {
+		firing = append(firing, a.evaluateZone(cfg, zoneID)...)
+	}
+	return firing
+}
+
+// evaluateZone returns the alerts of the enabled rules of a zone that hold on
+// this tick, in rule order. A zone the greenhouse no longer has raises none.
+func (a *alerts) evaluateZone(cfg config.AlertsConfig, zoneID string) []Alert {
+	zone, err := a.g.zones.get(zoneID)
+	if err != nil {
+		return nil
+	}
+	plants := a.g.zonePlants(zone)
+
+	var firing []Alert
+	for _, name := range config.ZoneAlertRules {
+		rule, ok := cfg.ZoneRule(zoneID, name)
+		if !ok || rule.Disabled {
+			continue
+		}
+		alert := Alert{Rule: name, Severity: rule.Severity, ZoneID: zoneID}
+		if rule.Threshold != nil {
+			alert.Threshold = *rule.Threshold
+		}
+		switch name {
+		case config.AlertDeadPlants:
+			if len(plants) == 0 {
+				continue
+			}
+			dead := 0
+			for _, plant := range plants {
+				if !plant.Alive {
+					dead++
+				}
+			}
+			alert.Value = 100 * float64(dead) / float64(len(plants))
+			alert.Message = fmt.Sprintf("%.0f%% of the plants of zone %s are dead", alert.Value, zoneID)
+			if alert.Value > alert.Threshold {
+				firing = append(firing, alert)
+			}
+		}
+	}
+	return firing
+}
+
+// latestSample returns the last sample of a sensor, unless the sensor missed
+// the sample it was due since, as a failed sensor does. The alerts run before
+// the sensors are sampled, so the last sample is that of an earlier tick; as
+// samples carry the ticks completed when they were taken, that of the
+// previous tick carries this one.
+func (a *alerts) latestSample(sensor *models.Sensor, tick int) (*models.SensorReading, bool) {
+	history, err := a.g.sensors.GetHistory(sensor.ID, 1)
+	if err != nil || len(history) == 0 {
+		return nil, false
+	}
+	sample := history[0]
+	return sample, tick-sample.Tick < max(sensor.SampleInterval, 1)
+}
+
+// unmonitoredSections returns the sections of the plants that no sensor
+// watches, ordered by ID.
+func (a *alerts) unmonitoredSections(plants []*models.Plant) []string {
+	monitored := map[string]bool{}
+	for _, sensor := range a.g.sensors.ListSensors() {
+		monitored[sensor.SectionID] = true
+	}
+	var sections []string
+	for _, plant := range plants {
+		if !monitored[plant.SectionID] && !slices.Contains(sections, plant.SectionID) {
+			sections = append(sections, plant.SectionID)
+		}
+	}
+	slices.Sort(sections)
+	return sections
+}
+
+func (a *alerts) publish(eventType events.Type, tick int, alert Alert) {
+	a.g.bus.Publish(events.Event{
+		Type:      eventType,
+		Tick:      tick,
+		Timestamp: time.Now(),
+		SectionID: alert.SectionID,
+		Payload:   alert,
+	})
+}
@@ -0,0 +1,333 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"reflect"
+	"testing"
+)
+
+// alertsOnly turns on the given rule alone, with the given threshold.
+func alertsOnly(rule string, window int, threshold *float64) *config.AlertsConfig {
+	alerts := &config.AlertsConfig{Window: window, Rules: map[string]config.AlertRuleConfig{}}
+	for _, name := range config.AlertRules {
+		alerts.Rules[name] = config.AlertRuleConfig{Disabled: name != rule}
+	}
+	alerts.Rules[rule] = config.AlertRuleConfig{Threshold: threshold}
+	return alerts
+}
+
+func TestAlerts_FireAndResolveOnce(t *testing.T) {
+	threshold := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name     string
+		rule     string
+		window   int
+		limit    *float64
+		setup    func(cfg *config.GreenhouseConfig)
+		script   func(t *testing.T, g *greenhouse, counters *alertCounters, tick int)
+		severity string
+		section  string
+		firedOn  int
+		resolved int
+	}{
+		{
+			name: "tick overruns", rule: config.AlertTickOverruns, window: 3, limit: threshold(1),
+			script: func(t *testing.T, g *greenhouse, counters *alertCounters, tick int) {
+				// Ticks 1 and 2 overrun, and leave the window after tick 4.
+				if tick == 1 || tick == 2 {
+					counters.overruns++
+				}
+			},
+			severity: config.SeverityWarning, firedOn: 2, resolved: 4,
+		},
+		{
+			name: "export drops", rule: config.AlertExportDrops, window: 2,
+			script: func(t *testing.T, g *greenhouse, counters *alertCounters, tick int) {
+				if tick == 1 {
+					counters.dropped += 5
+				}
+			},
+			severity: config.SeverityWarning, firedOn: 1, resolved: 3,
+		},
+		{
+			name: "tank empty", rule: config.AlertTankEmpty, limit: threshold(0.15),
+			setup: func(cfg *config.GreenhouseConfig) {
+				cfg.Tank = &config.TankConfig{Capacity: 1, RefillPerTick: 0.1}
+			},
+			severity: config.SeverityCritical, firedOn: 0, resolved: 1,
+		},
+		{
+			name: "dead plants", rule: config.AlertDeadPlants, limit: threshold(40),
+			setup: func(cfg *config.GreenhouseConfig) {
+				cfg.PlantTypes[0].HealthDegradationRate = 0.5
+				cfg.Plants[0].InitialSaturation = 0
+				cfg.Plants[0].State = &config.PlantStateConfig{Health: 0.2, Alive: true}
+			},
+			script: func(t *testing.T, g *greenhouse, counters *alertCounters, tick int) {
+				if tick == 3 {
+					if err := g.RemovePlant("basil-1"); err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+				}
+			},
+			severity: config.SeverityCritical, firedOn: 0, resolved: 3,
+		},
+		{
+			name: "unmonitored section", rule: config.AlertUnmonitoredSection,
+			script: func(t *testing.T, g *greenhouse, counters *alertCounters, tick int) {
+				var err error
+				switch tick {
+				case 1:
+					_, err = g.AddPlant(config.PlantConfig{ID: "mint-1", Type: "Mint", SectionID: "section-B", InitialSaturation: 0.5})
+				case 3:
+					err = g.Sensors().AddSensor(&models.Sensor{ID: "sensor-2", Type: models.SoilMoisture, SectionID: "section-B"})
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			},
+			severity: config.SeverityWarning, section: "section-B", firedOn: 1, resolved: 3,
+		},
+		{
+			name: "low battery", rule: config.AlertLowBattery,
+			setup: func(cfg *config.GreenhouseConfig) {
+				level := 0.3
+				cfg.Sensors = append(cfg.Sensors, config.SensorConfig{ID: "sensor-2", Type: models.Temperature, SectionID: "section-B",
+					Battery: &config.BatteryConfig{Level: &level, Drain: 0.05}})
+			},
+			script: func(t *testing.T, g *greenhouse, counters *alertCounters, tick int) {
+				// The samples of ticks 0 and 1 drain the battery to 0.2.
+				if tick == 3 {
+					if err := g.Sensors().ReplaceBattery("sensor-2"); err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+				}
+			},
+			severity: config.SeverityWarning, section: "section-B", firedOn: 2, resolved: 3,
+		},
+		{
+			name: "saturation drop", rule: config.AlertSaturationDrop,
+			setup: func(cfg *config.GreenhouseConfig) {
+				cfg.Sensors[0].RateWindow = 1
+			},
+			script: func(t *testing.T, g *greenhouse, counters *alertCounters, tick int) {
+				// A dry plant pulls the reading of tick 1 down by about 0.13,
+				// which the alerts see on the next tick; the plants then dry
+				// out at 0.01 a tick, below the threshold.
+				if tick == 1 {
+					if _, err := g.AddPlant(config.PlantConfig{ID: "basil-3", Type: "Basil", SectionID: "section-A"}); err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+				}
+			},
+			severity: config.SeverityWarning, section: "section-A", firedOn: 2, resolved: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Schedules = nil
+			cfg.Alerts = alertsOnly(tt.rule, tt.window, tt.limit)
+			if tt.setup != nil {
+				tt.setup(cfg)
+			}
+			built, err := New(cfg)
+			if err != nil {
+				t.Fatalf("failed to build greenhouse: %v", err)
+			}
+			g := built.(*greenhouse)
+			var counters alertCounters
+			g.alerts.counters = func() alertCounters { return counters }
+			var fired, resolved []events.Event
+			g.Bus().Subscribe(func(e events.Event) {
+				switch e.Type {
+				case events.Alert:
+					fired = append(fired, e)
+				case events.AlertResolved:
+					resolved = append(resolved, e)
+				}
+			})
+
+			for tick := range 6 {
+				if tt.script != nil {
+					tt.script(t, g, &counters, tick)
+				}
+				g.Simulator().Step()
+			}
+
+			if len(fired) != 1 || len(resolved) != 1 {
+				t.Fatalf("expected one alert and one resolution, got %+v and %+v", fired, resolved)
+			}
+			alert := fired[0].Payload.(Alert)
+			if alert.Rule != tt.rule || alert.Severity != tt.severity || alert.SectionID != tt.section || fired[0].SectionID != tt.section {
+				t.Errorf("expected a %s %s alert for %q, got %+v", tt.severity, tt.rule, tt.section, alert)
+			}
+			if fired[0].Tick != tt.firedOn || alert.Since != tt.firedOn || alert.Message == "" {
+				t.Errorf("expected the alert to fire on tick %d, got %+v on tick %d", tt.firedOn, alert, fired[0].Tick)
+			}
+			if resolved[0].Tick != tt.resolved || resolved[0].Payload.(Alert) != alert {
+				t.Errorf("expected the alert resolved on tick %d, got %+v on tick %d", tt.resolved, resolved[0].Payload, resolved[0].Tick)
+			}
+		})
+	}
+}
+
+func TestAlerts_ReloadResolvesRemovedRules(t *testing.T) {
+	cfg := testConfig()
+	cfg.Plants = append(cfg.Plants, config.PlantConfig{ID: "mint-1", Type: "Mint", SectionID: "section-B", InitialSaturation: 0.5})
+	g, published := newTestGreenhouse(t)
+	if _, err := g.ReloadConfig(cfg); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	g.Simulator().Step()
+	if countEvents(*published, events.Alert) != 0 {
+		t.Fatal("expected no alerts without alert settings")
+	}
+
+	cfg.Alerts = &config.AlertsConfig{}
+	if _, err := g.ReloadConfig(cfg); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	g.Simulator().Step()
+	g.Simulator().Step()
+	if countEvents(*published, events.Alert) != 1 {
+		t.Fatalf("expected section-B to be reported once as unmonitored, got %+v", *published)
+	}
+
+	cfg.Alerts = alertsOnly(config.AlertDeadPlants, 0, nil)
+	if _, err := g.ReloadConfig(cfg); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	g.Simulator().Step()
+	if countEvents(*published, events.AlertResolved) != 1 {
+		t.Fatal("expected the alert of the disabled rule to be resolved")
+	}
+}
+
+func countEvents(published []events.Event, eventType events.Type) int {
+	n := 0
+	for _, e := range published {
+		if e.Type == eventType {
+			n++
+		}
+	}
+	return n
+}
+
+func TestAlerts_WindowedSensorSmoothsDrop(t *testing.T) {
+	// The drop that fires the saturation drop alert in
+	// TestAlerts_FireAndResolveOnce, spread over a full window of 10 ticks.
+	cfg := testConfig()
+	cfg.Schedules = nil
+	cfg.Alerts = alertsOnly(config.AlertSaturationDrop, 0, nil)
+	cfg.Sensors[0].RateWindow = 1
+	cfg.Sensors[0].WindowTicks = 10
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	var fired []events.Event
+	g.Bus().Subscribe(func(e events.Event) {
+		if e.Type == events.Alert {
+			fired = append(fired, e)
+		}
+	})
+
+	for tick := range 16 {
+		if tick == 12 {
+			if _, err := g.AddPlant(config.PlantConfig{ID: "basil-3", Type: "Basil", SectionID: "section-A"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		g.Simulator().Step()
+	}
+	if len(fired) != 0 {
+		t.Errorf("expected the window to smooth the drop out, got %+v", fired)
+	}
+	reading, err := g.Sensors().GetReading("sensor-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reading.Instant == nil || *reading.Instant >= reading.Value {
+		t.Errorf("expected the windowed value to lag behind the drop, got %+v", reading)
+	}
+}
+
+func TestAlerts_Escalation(t *testing.T) {
+	// A plant drying out at 0.01 a tick from full saturation keeps the
+	// saturation drop alert unresolved over 100 ticks.
+	cfg := testConfig()
+	cfg.Schedules = nil
+	cfg.Plants = []config.PlantConfig{{ID: "basil-1", Type: "Basil", SectionID: "section-A", InitialSaturation: 1}}
+	cfg.Sensors[0].RateWindow = 1
+	limit := 0.005
+	cfg.Alerts = alertsOnly(config.AlertSaturationDrop, 0, nil)
+	cfg.Alerts.Rules[config.AlertSaturationDrop] = config.AlertRuleConfig{
+		Threshold:  &limit,
+		Escalation: &config.EscalationConfig{RenotifyTicks: 15, EscalateAfter: 40},
+	}
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	type notification struct {
+		tick, notifications int
+		severity            string
+		active              int
+	}
+	var got []notification
+	resolved := -1
+	g.Bus().Subscribe(func(e events.Event) {
+		switch e.Type {
+		case events.Alert:
+			alert := e.Payload.(Alert)
+			if alert.Since != 2 || alert.LastNotified != e.Tick {
+				t.Errorf("expected an alert firing since tick 2 and notified on %d, got %+v", e.Tick, alert)
+			}
+			got = append(got, notification{e.Tick, alert.Notifications, alert.Severity, len(g.ActiveAlerts())})
+		case events.AlertResolved:
+			resolved = e.Tick
+		}
+	})
+
+	for range 100 {
+		g.Simulator().Step()
+	}
+	// The alerts see the first drop on tick 2. From there the alert is
+	// notified every 15 ticks, and upgraded to critical on tick 42, which
+	// starts the renotify interval over.
+	expected := []notification{
+		{2, 1, config.SeverityWarning, 1},
+		{17, 2, config.SeverityWarning, 1},
+		{32, 3, config.SeverityWarning, 1},
+		{42, 4, config.SeverityCritical, 1},
+		{57, 5, config.SeverityCritical, 1},
+		{72, 6, config.SeverityCritical, 1},
+		{87, 7, config.SeverityCritical, 1},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected notifications %+v, got %+v", expected, got)
+	}
+	if resolved != -1 {
+		t.Errorf("expected the alert to stay unresolved, resolved on tick %d", resolved)
+	}
+	active := g.ActiveAlerts()
+	if len(active) != 1 || active[0].Severity != config.SeverityCritical || active[0].LastNotified != 87 || active[0].Notifications != 7 {
+		t.Errorf("expected the escalated alert to be active, got %+v", active)
+	}
+
+	// The plant dries out on tick 100, which resolves the alert on the next
+	// and cancels its escalation.
+	for range 30 {
+		g.Simulator().Step()
+	}
+	if resolved != 101 || len(got) != len(expected) {
+		t.Errorf("expected the alert to be resolved on tick 101 and notified no more, got %d and %+v", resolved, got[len(expected):])
+	}
+	if active := g.ActiveAlerts(); len(active) != 0 {
+		t.Errorf("expected no active alerts, got %+v", active)
+	}
+}
@@ -0,0 +1,127 @@
+package greenhouse
+
+import (
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"testing"
+	"time"
+)
+
+// benchSections is the number of sections the plants of a benchmark are
+// spread over; with sensors or schedules, each section has one.
+const benchSections = 100
+
+// benchConfig returns a greenhouse of the given number of basil plants,
+// optionally with a soil moisture sensor and a watering schedule per section.
+func benchConfig(plants int, sensors, schedules bool) *config.GreenhouseConfig {
+	cfg := &config.GreenhouseConfig{
+		TickInterval: config.Duration(time.Second),
+		PlantTypes: []config.PlantTypeConfig{
+			{Name: "Basil", OptimalSaturation: 0.6, MinSaturation: 0.3, MaxSaturation: 0.8, SaturationDepletion: 0.001},
+		},
+	}
+	for i := range plants {
+		cfg.Plants = append(cfg.Plants, config.PlantConfig{
+			ID:                fmt.Sprintf("basil-%06d", i),
+			Type:              "Basil",
+			SectionID:         fmt.Sprintf("section-%03d", i%benchSections),
+			InitialSaturation: 0.6,
+		})
+	}
+	for i := range benchSections {
+		section := fmt.Sprintf("section-%03d", i)
+		if sensors {
+			cfg.Sensors = append(cfg.Sensors, config.SensorConfig{ID: "sensor-" + section, Type: "soil_moisture", SectionID: section})
+		}
+		if schedules {
+			cfg.Schedules = append(cfg.Schedules, config.ScheduleConfig{SectionID: section, TargetSaturation: 0.5, CheckInterval: 10, WaterAmount: 0.1, Enabled: true})
+		}
+	}
+	return cfg
+}
+
+// benchmarkTick steps greenhouses of the given size, with and without
+// sensors and schedules.
+func benchmarkTick(b *testing.B, plants int) {
+	for _, sensors := range []bool{false, true} {
+		for _, schedules := range []bool{false, true} {
+			b.Run(fmt.Sprintf("sensors=%t/schedules=%t", sensors, schedules), func(b *testing.B) {
+				g, err := New(benchConfig(plants, sensors, schedules))
+				if err != nil {
+					b.Fatalf("failed to build greenhouse: %v", err)
+				}
+				sim := g.Simulator()
+				b.ReportAllocs()
+				b.ResetTimer()
+				for b.Loop() {
+					sim.Step()
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkTick_1k(b *testing.B)   { benchmarkTick(b, 1_000) }
+func BenchmarkTick_10k(b *testing.B)  { benchmarkTick(b, 10_000) }
+func BenchmarkTick_100k(b *testing.B) { benchmarkTick(b, 100_000) }
+
+// BenchmarkRerun_10k compares the two ways of running an experiment again: a
+// new greenhouse from the config, or a reset of the one that ran it.
+func BenchmarkRerun_10k(b *testing.B) {
+	const ticks = 10
+	cfg := benchConfig(10_000, true, true)
+	b.Run("new", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			g, err := New(cfg)
+			if err != nil {
+				b.Fatalf("failed to build greenhouse: %v", err)
+			}
+			for range ticks {
+				g.Simulator().Step()
+			}
+		}
+	})
+	b.Run("reset", func(b *testing.B) {
+		g, err := New(cfg)
+		if err != nil {
+			b.Fatalf("failed to build greenhouse: %v", err)
+		}
+		b.ReportAllocs()
+		for b.Loop() {
+			if err := g.Reset(true); err != nil {
+				b.Fatalf("failed to reset: %v", err)
+			}
+			for range ticks {
+				g.Simulator().Step()
+			}
+		}
+	})
+}
+
+// TestTick_AllocationBudget guards the hot path: what a tick allocates may
+// depend on the sensors and schedules, but not on the number of plants, and
+// a tick of plants alone stays within a small fixed budget.
+func TestTick_AllocationBudget(t *testing.T) {
+	const budget = 16
+	for _, sensors := range []bool{false, true} {
+		for _, schedules := range []bool{false, true} {
+			t.Run(fmt.Sprintf("sensors=%t/schedules=%t", sensors, schedules), func(t *testing.T) {
+				allocs := map[int]float64{}
+				for _, plants := range []int{1_000, 10_000} {
+					g, err := New(benchConfig(plants, sensors, schedules))
+					if err != nil {
+						t.Fatalf("failed to build greenhouse: %v", err)
+					}
+					allocs[plants] = testing.AllocsPerRun(20, g.Simulator().Step)
+				}
+				if allocs[10_000] > allocs[1_000] {
+					t.Errorf("expected allocations not to grow with the plants, got %v per tick for 1k plants and %v for 10k", allocs[1_000], allocs[10_000])
+				}
+				if !sensors && !schedules && allocs[10_000] > budget {
+					t.Errorf("expected at most %d allocations per tick, got %v", budget, allocs[10_000])
+				}
+			})
+		}
+	}
+}
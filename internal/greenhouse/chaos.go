@@ -0,0 +1,125 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"greenhouse-simulator/internal/rng"
+	"greenhouse-simulator/internal/sensors"
+	"time"
+)
+
+// ChaosKind is a kind of fault chaos mode injects, see config.ChaosConfig.
+type ChaosKind string
+
+const (
+	// ChaosSensorFailure fails a sensor, see sensors.SensorManager.FailSensor.
+	ChaosSensorFailure ChaosKind = "sensor_failure"
+	// ChaosWateringDrop cancels the watering of a section before its events
+	// complete, see watering.Controller.CancelWatering.
+	ChaosWateringDrop ChaosKind = "watering_drop"
+	// ChaosInfection infects a plant, see Greenhouse.InfectPlant.
+	ChaosInfection ChaosKind = "infection"
+	// ChaosTickJitter holds the tick up.
+	ChaosTickJitter ChaosKind = "tick_jitter"
+)
+
+// ChaosFault is a fault chaos mode injected, the payload of a ChaosFault
+// event. Target is the sensor, section or plant it hit, empty for tick
+// jitter, and Delay how long tick jitter held the tick up.
+type ChaosFault struct {
+	Kind   ChaosKind     `json:"kind"`
+	Target string        `json:"target,omitempty"`
+	Delay  time.Duration `json:"delay,omitempty"`
+}
+
+// chaos injects the faults of chaos mode through the hooks the timeline
+// uses: on every tick, each kind of fault strikes with its probability, and
+// hits a sensor that still reads, an active watering event or a healthy
+// living plant drawn at random, if there is one. The draws of each tick are
+// split off by tick number, so the same seed injects the same faults.
+type chaos struct {
+	g      *greenhouse
+	config config.ChaosConfig
+	random rng.Source
+	sleep  func(time.Duration)
+}
+
+func newChaos(g *greenhouse, cfg config.ChaosConfig, random rng.Source) *chaos {
+	return &chaos{g: g, config: cfg, random: random, sleep: time.Sleep}
+}
+
+// TickPhase names chaos mode in tick traces.
+func (c *chaos) TickPhase() string { return "chaos" }
+
+func (c *chaos) OnTick(tick int) {
+	random := c.random.SplitN(tick)
+	if random.Float64() < c.config.SensorFailure {
+		c.failSensor(tick, random)
+	}
+	if random.Float64() < c.config.WateringDrop {
+		c.dropWatering(tick, random)
+	}
+	if random.Float64() < c.config.Infection {
+		c.infect(tick, random)
+	}
+	if random.Float64() < c.config.TickJitter {
+		delay := time.Duration(random.Float64() * float64(c.config.MaxJitter))
+		c.sleep(delay)
+		c.publish(tick, ChaosFault{Kind: ChaosTickJitter, Delay: delay}, "", "")
+	}
+}
+
+func (c *chaos) failSensor(tick int, random rng.Source) {
+	var reading []*models.Sensor
+	for _, sensor := range c.g.sensors.ListSensors() {
+		if status, err := c.g.sensors.GetStatus(sensor.ID); err == nil && status != sensors.SensorFailed {
+			reading = append(reading, sensor)
+		}
+	}
+	if len(reading) == 0 {
+		return
+	}
+	sensor := reading[random.IntN(len(reading))]
+	if c.g.sensors.FailSensor(sensor.ID) == nil {
+		c.publish(tick, ChaosFault{Kind: ChaosSensorFailure, Target: sensor.ID}, sensor.SectionID, "")
+	}
+}
+
+func (c *chaos) dropWatering(tick int, random rng.Source) {
+	active := c.g.watering.GetActiveEvents()
+	if len(active) == 0 {
+		return
+	}
+	event := active[random.IntN(len(active))]
+	if c.g.watering.CancelWatering(event.SectionID) == nil {
+		c.publish(tick, ChaosFault{Kind: ChaosWateringDrop, Target: event.SectionID}, event.SectionID, "")
+	}
+}
+
+func (c *chaos) infect(tick int, random rng.Source) {
+	var healthy []*models.Plant
+	for _, plant := range c.g.sim.GetAllPlants() {
+		if plant.Alive && !plant.Diseased() {
+			healthy = append(healthy, plant)
+		}
+	}
+	if len(healthy) == 0 {
+		return
+	}
+	plant := healthy[random.IntN(len(healthy))]
+	if c.g.InfectPlant(plant.ID) == nil {
+		c.publish(tick, ChaosFault{Kind: ChaosInfection, Target: plant.ID}, plant.SectionID, plant.ID)
+	}
+}
+
+func (c *chaos) publish(tick int, fault ChaosFault, sectionID, plantID string) {
+	c.g.bus.Publish(events.Event{
+		Type:      events.ChaosFault,
+		Tick:      tick,
+		Timestamp: time.Now(),
+		SectionID: sectionID,
+		PlantID:   plantID,
+		Payload:   fault,
+	})
+}
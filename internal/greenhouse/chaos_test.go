@@ -0,0 +1,130 @@
+package greenhouse
+
+import (
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/sensors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func chaosConfig() *config.GreenhouseConfig {
+	cfg := testConfig()
+	cfg.Invariants = config.InvariantsPanic
+	cfg.Disease = &config.DiseaseConfig{Incubation: 5, HealthDecay: 0.01, CureChance: 0.5}
+	for i := range 8 {
+		section := []string{"section-A", "section-B"}[i%2]
+		cfg.Plants = append(cfg.Plants, config.PlantConfig{ID: fmt.Sprintf("mint-%d", i), Type: "Mint", SectionID: section, InitialSaturation: 0.5})
+		cfg.Sensors = append(cfg.Sensors, config.SensorConfig{ID: fmt.Sprintf("sensor-%d", i+2), Type: "soil_moisture", SectionID: section})
+	}
+	cfg.Schedules = []config.ScheduleConfig{
+		{SectionID: "section-A", TargetSaturation: 0.9, CheckInterval: 3, WaterAmount: 0.2, Duration: config.Duration(3 * time.Second), Enabled: true},
+		{SectionID: "section-B", TargetSaturation: 0.9, CheckInterval: 4, WaterAmount: 0.2, Duration: config.Duration(3 * time.Second), Enabled: true},
+	}
+	cfg.Chaos = &config.ChaosConfig{
+		Enabled:       true,
+		SensorFailure: 0.02,
+		WateringDrop:  0.2,
+		Infection:     0.05,
+		TickJitter:    0.1,
+		MaxJitter:     config.Duration(time.Millisecond),
+	}
+	return cfg
+}
+
+// runChaos runs 500 ticks of chaos mode while reading the world state on
+// another goroutine, and returns the greenhouse with the ChaosFault events it
+// published. It fails the test if a tick panics, which a broken invariant
+// does, or the run does not finish in time.
+func runChaos(t *testing.T, cfg *config.GreenhouseConfig) (Greenhouse, []events.Event) {
+	t.Helper()
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	var faults []events.Event
+	g.Bus().Subscribe(func(e events.Event) {
+		if e.Type == events.ChaosFault {
+			faults = append(faults, e)
+		}
+	})
+
+	done := make(chan any)
+	go func() {
+		defer func() { done <- recover() }()
+		for range 500 {
+			g.Simulator().Step()
+		}
+	}()
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				g.BuildWorldState()
+			}
+		}
+	}()
+	select {
+	case panicked := <-done:
+		if panicked != nil {
+			t.Fatalf("unexpected panic: %v", panicked)
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("expected 500 ticks of chaos to finish, deadlocked")
+	}
+	return g, faults
+}
+
+func TestChaos_500Ticks(t *testing.T) {
+	g, faults := runChaos(t, chaosConfig())
+
+	kinds := map[ChaosKind]int{}
+	for _, e := range faults {
+		fault := e.Payload.(ChaosFault)
+		kinds[fault.Kind]++
+		switch fault.Kind {
+		case ChaosSensorFailure:
+			if status, err := g.Sensors().GetStatus(fault.Target); err != nil || status != sensors.SensorFailed {
+				t.Errorf("expected sensor %s failed at tick %d, got %s, %v", fault.Target, e.Tick, status, err)
+			}
+		case ChaosInfection:
+			if plant, err := g.Simulator().GetPlant(fault.Target); err != nil || plant.Disease.Stage == "" {
+				t.Errorf("expected plant %s infected at tick %d, got %+v, %v", fault.Target, e.Tick, plant, err)
+			}
+		case ChaosTickJitter:
+			if fault.Delay < 0 || fault.Delay > time.Millisecond {
+				t.Errorf("expected a jitter of up to 1ms, got %v", fault.Delay)
+			}
+		}
+	}
+	for _, kind := range []ChaosKind{ChaosSensorFailure, ChaosWateringDrop, ChaosInfection, ChaosTickJitter} {
+		if kinds[kind] == 0 {
+			t.Errorf("expected %s faults in 500 ticks, got %v", kind, kinds)
+		}
+	}
+
+	_, again := runChaos(t, chaosConfig())
+	if len(again) != len(faults) {
+		t.Fatalf("expected the same seed to inject the same %d faults, got %d", len(faults), len(again))
+	}
+	for i := range faults {
+		if faults[i].Tick != again[i].Tick || !reflect.DeepEqual(faults[i].Payload, again[i].Payload) {
+			t.Fatalf("expected fault %d to be %+v at tick %d, got %+v at tick %d", i, faults[i].Payload, faults[i].Tick, again[i].Payload, again[i].Tick)
+		}
+	}
+}
+
+func TestChaos_Off(t *testing.T) {
+	cfg := chaosConfig()
+	cfg.Chaos = &config.ChaosConfig{SensorFailure: 1, WateringDrop: 1, Infection: 1}
+	_, faults := runChaos(t, cfg)
+	if len(faults) != 0 {
+		t.Errorf("expected no faults without enabling chaos mode, got %d", len(faults))
+	}
+}
@@ -0,0 +1,99 @@
+package greenhouse
+
+import (
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/models"
+	"testing"
+	"time"
+)
+
+// co2Config has the given number of basil plants in section-A, next to a CO2
+// sensor, in constant light and a CO2 model starting at 400 ppm.
+func co2Config(plants int, light float64) *config.GreenhouseConfig {
+	cfg := &config.GreenhouseConfig{
+		TickInterval: config.Duration(time.Second),
+		Environment: config.EnvironmentConfig{
+			Light:             light,
+			CO2Baseline:       400,
+			CO2Uptake:         0.5,
+			CO2Ventilation:    0.1,
+			CO2BoostThreshold: 600,
+			CO2GrowthBoost:    0.3,
+		},
+		Sensors: []config.SensorConfig{
+			{ID: "co2", Type: models.CO2, SectionID: "section-A"},
+		},
+	}
+	for i := range plants {
+		cfg.Plants = append(cfg.Plants, config.PlantConfig{ID: fmt.Sprintf("basil-%d", i), Type: "Basil", SectionID: "section-A", InitialSaturation: 0.6})
+	}
+	return cfg
+}
+
+func TestCO2_DrawdownInTheLight(t *testing.T) {
+	level := func(plants int, light float64) float64 {
+		g, err := New(co2Config(plants, light))
+		if err != nil {
+			t.Fatalf("failed to build greenhouse: %v", err)
+		}
+		for range 10 {
+			g.Simulator().Step()
+		}
+		reading, err := g.Sensors().GetReading("co2")
+		if err != nil {
+			t.Fatalf("failed to read the CO2 sensor: %v", err)
+		}
+		if reading.Value != g.Conditions().CO2 {
+			t.Errorf("expected the sensor to read the CO2 level %.2f, got %.2f", g.Conditions().CO2, reading.Value)
+		}
+		return reading.Value
+	}
+
+	dark, few, many := level(50, 0), level(5, 1), level(50, 1)
+	if dark != 400 {
+		t.Errorf("expected the CO2 level to stay at 400 in the dark, got %.2f", dark)
+	}
+	// Ventilation settles the level where it closes the drawdown:
+	// 400 - 10*0.5*plants at most.
+	if few >= 400 || few < 375 {
+		t.Errorf("expected 5 plants to draw the CO2 down a little, got %.2f", few)
+	}
+	if many >= few || many < 150 {
+		t.Errorf("expected 50 plants to draw the CO2 down further than 5, got %.2f and %.2f", many, few)
+	}
+}
+
+func TestCO2_InjectionBoostsGrowth(t *testing.T) {
+	growth := func(injection float64) float64 {
+		g, err := New(co2Config(1, 0))
+		if err != nil {
+			t.Fatalf("failed to build greenhouse: %v", err)
+		}
+		if err := g.SetCO2Injection(injection); err != nil {
+			t.Fatalf("failed to set the CO2 injection: %v", err)
+		}
+		for range 5 {
+			g.Simulator().Step()
+		}
+		return g.Simulator().GetAllPlants()[0].GrowthStage
+	}
+
+	normal, boosted := growth(0), growth(500)
+	if boosted <= normal {
+		t.Fatalf("expected CO2 injection to boost growth, got %.3f without and %.3f with", normal, boosted)
+	}
+	// Basil grows 0.06 a tick near optimal saturation, 0.075 with the bonus;
+	// the boost adds at most 0.3*0.06 a tick.
+	if boosted-normal > 5*0.3*0.06+1e-9 {
+		t.Errorf("expected the boost to stay capped at 0.3, got %.3f without and %.3f with", normal, boosted)
+	}
+
+	g, err := New(co2Config(1, 0))
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	if err := g.SetCO2Injection(-1); err == nil {
+		t.Error("expected a negative injection to be refused")
+	}
+}
@@ -0,0 +1,218 @@
+package greenhouse
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// RunComparison is the difference between two scenario runs, A and B, such
+// as the same scenario with two watering strategies. Every change is B minus
+// A.
+type RunComparison struct {
+	TicksA int
+	TicksB int
+	// Horizon is the number of ticks both runs cover, over which the times
+	// to maturity are compared.
+	Horizon int
+	// Warnings describe what makes the runs hard to compare, such as
+	// different lengths or seeds.
+	Warnings []string
+	// Plants are the final states of the plants of both runs, ordered by ID.
+	Plants []PlantDelta
+	// Missing are the IDs of the plants of A missing from B, and Added those
+	// of the plants of B missing from A.
+	Missing []string
+	Added   []string
+	// Survival is the share of the plants of each run alive at its end.
+	Survival    Delta
+	WaterUsed   Delta
+	WaterWasted Delta
+	// Cost is the total cost, nil unless both runs have a cost summary.
+	Cost      *Delta
+	MaturityA MaturityStats
+	MaturityB MaturityStats
+}
+
+// Delta is a value in both runs and how much it changed from A to B.
+type Delta struct {
+	A      float64
+	B      float64
+	Change float64
+}
+
+func newDelta(a, b float64) Delta {
+	return Delta{A: a, B: b, Change: b - a}
+}
+
+// PlantDelta is the final state of a plant in both runs.
+type PlantDelta struct {
+	ID             string
+	SoilSaturation Delta
+	Health         Delta
+	GrowthStage    Delta
+	AliveA         bool
+	AliveB         bool
+}
+
+// MaturityStats is the distribution of the ticks the plants of a run took to
+// mature, over the Matured of its Plants that matured within the horizon of
+// the comparison. Min, Median, Max and Mean are 0 when none did.
+type MaturityStats struct {
+	Plants  int
+	Matured int
+	Min     int
+	Median  int
+	Max     int
+	Mean    float64
+}
+
+// CompareRuns compares two scenario results. Plants are matched by ID, and
+// those in one run only are listed as missing or added. Runs of different
+// lengths compare their times to maturity over the shorter one, with a
+// warning; their final states are still those at the end of each run.
+// Returns an error if either result is nil.
+func CompareRuns(a, b *ScenarioResult) (*RunComparison, error) {
+	if a == nil || b == nil {
+		return nil, errors.New("scenario results to compare cannot be nil")
+	}
+	c := &RunComparison{
+		TicksA:      a.Ticks,
+		TicksB:      b.Ticks,
+		Horizon:     min(a.Ticks, b.Ticks),
+		Survival:    newDelta(survival(a), survival(b)),
+		WaterUsed:   newDelta(a.WaterUsed, b.WaterUsed),
+		WaterWasted: newDelta(a.WaterWasted, b.WaterWasted),
+	}
+	if a.Ticks != b.Ticks {
+		c.Warnings = append(c.Warnings, fmt.Sprintf("runs have different lengths, %d and %d ticks: times to maturity are compared over the first %d ticks, final states at the end of each run", a.Ticks, b.Ticks, c.Horizon))
+	}
+	if a.Seed != b.Seed {
+		c.Warnings = append(c.Warnings, fmt.Sprintf("runs have different seeds, %d and %d: differences may come from chance", a.Seed, b.Seed))
+	}
+	if a.Costs != nil && b.Costs != nil {
+		cost := newDelta(a.Costs.Total.Total, b.Costs.Total.Total)
+		c.Cost = &cost
+	}
+	c.MaturityA = maturity(a.Plants, c.Horizon)
+	c.MaturityB = maturity(b.Plants, c.Horizon)
+
+	plantsB := make(map[string]PlantResult, len(b.Plants))
+	for _, plant := range b.Plants {
+		plantsB[plant.ID] = plant
+	}
+	for _, plantA := range a.Plants {
+		plantB, ok := plantsB[plantA.ID]
+		if !ok {
+			c.Missing = append(c.Missing, plantA.ID)
+			continue
+		}
+		delete(plantsB, plantA.ID)
+		c.Plants = append(c.Plants, PlantDelta{
+			ID:             plantA.ID,
+			SoilSaturation: newDelta(plantA.SoilSaturation, plantB.SoilSaturation),
+			Health:         newDelta(plantA.Health, plantB.Health),
+			GrowthStage:    newDelta(plantA.GrowthStage, plantB.GrowthStage),
+			AliveA:         plantA.Alive,
+			AliveB:         plantB.Alive,
+		})
+	}
+	for id := range plantsB {
+		c.Added = append(c.Added, id)
+	}
+	slices.SortFunc(c.Plants, func(x, y PlantDelta) int { return strings.Compare(x.ID, y.ID) })
+	slices.Sort(c.Missing)
+	slices.Sort(c.Added)
+	return c, nil
+}
+
+// survival returns the share of the plants of a run alive at its end, 0
+// without plants.
+func survival(result *ScenarioResult) float64 {
+	if len(result.Plants) == 0 {
+		return 0
+	}
+	alive := 0
+	for _, plant := range result.Plants {
+		if plant.Alive {
+			alive++
+		}
+	}
+	return float64(alive) / float64(len(result.Plants))
+}
+
+// maturity returns the distribution of the ticks plants took to mature,
+// ignoring those that matured on or after horizon.
+func maturity(plants []PlantResult, horizon int) MaturityStats {
+	stats := MaturityStats{Plants: len(plants)}
+	var ticks []int
+	for _, plant := range plants {
+		if plant.MaturedAt != nil && *plant.MaturedAt < horizon {
+			ticks = append(ticks, *plant.MaturedAt)
+		}
+	}
+	if len(ticks) == 0 {
+		return stats
+	}
+	slices.Sort(ticks)
+	total := 0
+	for _, tick := range ticks {
+		total += tick
+	}
+	stats.Matured = len(ticks)
+	stats.Min = ticks[0]
+	stats.Median = ticks[(len(ticks)-1)/2]
+	stats.Max = ticks[len(ticks)-1]
+	stats.Mean = float64(total) / float64(len(ticks))
+	return stats
+}
+
+// WriteText writes the comparison as text tables for a terminal: the
+// warnings, the run totals, the plants in one run only and the final state
+// of every plant in both.
+func (c *RunComparison) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, warning := range c.Warnings {
+		fmt.Fprintf(tw, "warning: %s\n", warning)
+	}
+	fmt.Fprintf(tw, "\t A\t B\t change\n")
+	fmt.Fprintf(tw, "ticks\t %d\t %d\t %+d\n", c.TicksA, c.TicksB, c.TicksB-c.TicksA)
+	writeDelta(tw, "survival", c.Survival)
+	writeDelta(tw, "water used", c.WaterUsed)
+	writeDelta(tw, "water wasted", c.WaterWasted)
+	if c.Cost != nil {
+		writeDelta(tw, "cost", *c.Cost)
+	}
+	fmt.Fprintf(tw, "matured by tick %d\t %d/%d\t %d/%d\t %+d\n", c.Horizon, c.MaturityA.Matured, c.MaturityA.Plants, c.MaturityB.Matured, c.MaturityB.Plants, c.MaturityB.Matured-c.MaturityA.Matured)
+	if c.MaturityA.Matured > 0 && c.MaturityB.Matured > 0 {
+		fmt.Fprintf(tw, "maturity min\t %d\t %d\t %+d\n", c.MaturityA.Min, c.MaturityB.Min, c.MaturityB.Min-c.MaturityA.Min)
+		fmt.Fprintf(tw, "maturity median\t %d\t %d\t %+d\n", c.MaturityA.Median, c.MaturityB.Median, c.MaturityB.Median-c.MaturityA.Median)
+		fmt.Fprintf(tw, "maturity max\t %d\t %d\t %+d\n", c.MaturityA.Max, c.MaturityB.Max, c.MaturityB.Max-c.MaturityA.Max)
+		writeDelta(tw, "maturity mean", newDelta(c.MaturityA.Mean, c.MaturityB.Mean))
+	}
+	if len(c.Missing)+len(c.Added) > 0 {
+		fmt.Fprintln(tw)
+	}
+	if len(c.Missing) > 0 {
+		fmt.Fprintf(tw, "missing from B: %s\n", strings.Join(c.Missing, ", "))
+	}
+	if len(c.Added) > 0 {
+		fmt.Fprintf(tw, "added in B: %s\n", strings.Join(c.Added, ", "))
+	}
+	if len(c.Plants) > 0 {
+		fmt.Fprintf(tw, "\nplant\t health\t growth\t saturation\t alive\n")
+		for _, plant := range c.Plants {
+			fmt.Fprintf(tw, "%s\t %.3f -> %.3f\t %.3f -> %.3f\t %.3f -> %.3f\t %v -> %v\n", plant.ID,
+				plant.Health.A, plant.Health.B, plant.GrowthStage.A, plant.GrowthStage.B,
+				plant.SoilSaturation.A, plant.SoilSaturation.B, plant.AliveA, plant.AliveB)
+		}
+	}
+	return tw.Flush()
+}
+
+func writeDelta(w io.Writer, name string, d Delta) {
+	fmt.Fprintf(w, "%s\t %.3f\t %.3f\t %+.3f\n", name, d.A, d.B, d.Change)
+}
@@ -0,0 +1,159 @@
+package greenhouse
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// tick returns a pointer to a tick, for PlantResult.MaturedAt.
+func tick(t int) *int {
+	return &t
+}
+
+// comparedRuns returns two hand-built results of a scenario watered two
+// ways: B runs 80 ticks instead of 100, loses tomato-3 and gains tomato-5.
+func comparedRuns() (*ScenarioResult, *ScenarioResult) {
+	a := &ScenarioResult{
+		Ticks: 100,
+		Seed:  42,
+		Plants: []PlantResult{
+			{ID: "tomato-1", SoilSaturation: 0.5, Health: 0.8, GrowthStage: 1, Alive: true, MaturedAt: tick(60)},
+			{ID: "tomato-2", SoilSaturation: 0.4, Health: 0, GrowthStage: 0.3, Alive: false},
+			{ID: "tomato-3", SoilSaturation: 0.6, Health: 0.9, GrowthStage: 1, Alive: true, MaturedAt: tick(90)},
+			{ID: "tomato-4", SoilSaturation: 0.5, Health: 0.7, GrowthStage: 1, Alive: true, MaturedAt: tick(70)},
+		},
+		WaterUsed:   12,
+		WaterWasted: 2,
+		Costs:       &CostSummary{CostLedger: CostLedger{Total: Costs{Total: 30}}},
+	}
+	b := &ScenarioResult{
+		Ticks: 80,
+		Seed:  42,
+		Plants: []PlantResult{
+			{ID: "tomato-1", SoilSaturation: 0.6, Health: 0.9, GrowthStage: 1, Alive: true, MaturedAt: tick(50)},
+			{ID: "tomato-2", SoilSaturation: 0.5, Health: 0.6, GrowthStage: 0.9, Alive: true},
+			{ID: "tomato-4", SoilSaturation: 0.6, Health: 0.8, GrowthStage: 1, Alive: true, MaturedAt: tick(56)},
+			{ID: "tomato-5", SoilSaturation: 0.6, Health: 0.8, GrowthStage: 1, Alive: true, MaturedAt: tick(40)},
+		},
+		WaterUsed:   9,
+		WaterWasted: 0.5,
+		Costs:       &CostSummary{CostLedger: CostLedger{Total: Costs{Total: 24}}},
+	}
+	return a, b
+}
+
+// closeDelta reports whether d has the given values, up to rounding.
+func closeDelta(d Delta, a, b, change float64) bool {
+	return math.Abs(d.A-a) < 1e-9 && math.Abs(d.B-b) < 1e-9 && math.Abs(d.Change-change) < 1e-9
+}
+
+func TestCompareRuns(t *testing.T) {
+	c, err := CompareRuns(comparedRuns())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if c.TicksA != 100 || c.TicksB != 80 || c.Horizon != 80 {
+		t.Errorf("expected 100 and 80 ticks compared over 80, got %d, %d and %d", c.TicksA, c.TicksB, c.Horizon)
+	}
+	if len(c.Warnings) != 1 || !strings.Contains(c.Warnings[0], "different lengths, 100 and 80 ticks") {
+		t.Errorf("expected a warning about the lengths only, got %v", c.Warnings)
+	}
+	if !reflect.DeepEqual(c.Missing, []string{"tomato-3"}) || !reflect.DeepEqual(c.Added, []string{"tomato-5"}) {
+		t.Errorf("expected tomato-3 missing and tomato-5 added, got %v and %v", c.Missing, c.Added)
+	}
+	totals := []struct {
+		name         string
+		got          Delta
+		a, b, change float64
+	}{
+		{"survival", c.Survival, 0.75, 1, 0.25},
+		{"water used", c.WaterUsed, 12, 9, -3},
+		{"water wasted", c.WaterWasted, 2, 0.5, -1.5},
+	}
+	for _, total := range totals {
+		if !closeDelta(total.got, total.a, total.b, total.change) {
+			t.Errorf("expected the %s to go from %v to %v, got %+v", total.name, total.a, total.b, total.got)
+		}
+	}
+	if c.Cost == nil || !closeDelta(*c.Cost, 30, 24, -6) {
+		t.Errorf("expected the cost to go from 30 to 24, got %+v", c.Cost)
+	}
+
+	// tomato-3 matured on tick 90, after the horizon of 80.
+	expectedA := MaturityStats{Plants: 4, Matured: 2, Min: 60, Median: 60, Max: 70, Mean: 65}
+	expectedB := MaturityStats{Plants: 4, Matured: 3, Min: 40, Median: 50, Max: 56, Mean: 146.0 / 3}
+	if c.MaturityA != expectedA || c.MaturityB.Plants != 4 || c.MaturityB.Matured != 3 || c.MaturityB.Min != 40 ||
+		c.MaturityB.Median != 50 || c.MaturityB.Max != 56 || math.Abs(c.MaturityB.Mean-expectedB.Mean) > 1e-9 {
+		t.Errorf("expected the maturity stats %+v and %+v, got %+v and %+v", expectedA, expectedB, c.MaturityA, c.MaturityB)
+	}
+
+	if got := len(c.Plants); got != 3 {
+		t.Fatalf("expected the 3 plants of both runs, got %d", got)
+	}
+	plant := c.Plants[1]
+	if plant.ID != "tomato-2" || plant.AliveA || !plant.AliveB || !closeDelta(plant.Health, 0, 0.6, 0.6) ||
+		!closeDelta(plant.GrowthStage, 0.3, 0.9, 0.6) || !closeDelta(plant.SoilSaturation, 0.4, 0.5, 0.1) {
+		t.Errorf("expected tomato-2 to survive in B, got %+v", plant)
+	}
+}
+
+func TestCompareRuns_Identical(t *testing.T) {
+	a, _ := comparedRuns()
+	c, err := CompareRuns(a, a)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(c.Warnings)+len(c.Missing)+len(c.Added) != 0 || c.Survival.Change != 0 || c.Cost.Change != 0 {
+		t.Errorf("expected no difference, got %+v", c)
+	}
+	if c.MaturityA != c.MaturityB || c.MaturityA.Matured != 3 {
+		t.Errorf("expected 3 plants matured in both runs, got %+v and %+v", c.MaturityA, c.MaturityB)
+	}
+}
+
+func TestCompareRuns_Warnings(t *testing.T) {
+	a, b := comparedRuns()
+	b.Ticks, b.Seed, b.Costs = 100, 7, nil
+	c, err := CompareRuns(a, b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(c.Warnings) != 1 || c.Warnings[0] != "runs have different seeds, 42 and 7: differences may come from chance" {
+		t.Errorf("expected a warning about the seeds only, got %v", c.Warnings)
+	}
+	if c.Cost != nil {
+		t.Errorf("expected no cost without the costs of both runs, got %+v", c.Cost)
+	}
+
+	if _, err := CompareRuns(a, nil); err == nil || err.Error() != "scenario results to compare cannot be nil" {
+		t.Errorf("expected a nil result to be refused, got %v", err)
+	}
+}
+
+func TestRunComparison_WriteText(t *testing.T) {
+	c, err := CompareRuns(comparedRuns())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var out bytes.Buffer
+	if err := c.WriteText(&out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{
+		"warning: runs have different lengths",
+		"survival             0.750    1.000    +0.250",
+		"cost                 30.000   24.000   -6.000",
+		"matured by tick 80   2/4      3/4      +1",
+		"maturity median      60       50       -10",
+		"missing from B: tomato-3\nadded in B: tomato-5",
+		"tomato-2   0.000 -> 0.600   0.300 -> 0.900   0.400 -> 0.500   false -> true",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected the text to contain %q, got\n%s", expected, out.String())
+		}
+	}
+}
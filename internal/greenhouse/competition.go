@@ -0,0 +1,21 @@
+package greenhouse
+
+import "greenhouse-simulator/internal/models"
+
+// lightCompetition shades the plants of each section on every tick by the
+// plants taller than them, for the growth of the next tick, see
+// models.LightCompetition. A greenhouse without light competition settings
+// leaves its plants unshaded.
+type lightCompetition struct {
+	g     *greenhouse
+	model models.LightCompetition
+}
+
+// TickPhase names the light competition in tick traces.
+func (c *lightCompetition) TickPhase() string { return "light_competition" }
+
+func (c *lightCompetition) OnTick(tick int) {
+	for _, sectionID := range c.g.sim.ListSectionIDs() {
+		c.model.ShadeSection(c.g.sim.GetPlantsBySectionID(sectionID))
+	}
+}
@@ -0,0 +1,71 @@
+package greenhouse
+
+import (
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"testing"
+	"time"
+)
+
+// competitionConfig is a day cycle with a basil seedling alone in section-B
+// and an identical one under five mature basil plants in section-A.
+func competitionConfig() *config.GreenhouseConfig {
+	cfg := &config.GreenhouseConfig{
+		TickInterval:     config.Duration(time.Second),
+		Environment:      config.EnvironmentConfig{TicksPerDay: 24},
+		LightCompetition: &config.LightCompetitionConfig{Coefficient: 0.2, Floor: 0.1},
+		Plants: []config.PlantConfig{
+			{ID: "seedling-A", Type: "Basil", SectionID: "section-A", InitialSaturation: 0.6},
+			{ID: "seedling-B", Type: "Basil", SectionID: "section-B", InitialSaturation: 0.6},
+		},
+	}
+	for i := range 5 {
+		cfg.Plants = append(cfg.Plants, config.PlantConfig{
+			ID: fmt.Sprintf("mature-%d", i), Type: "Basil", SectionID: "section-A", InitialSaturation: 0.6,
+			State: &config.PlantStateConfig{Health: 1, DeepSaturation: 0.6, GrowthStage: 1, Alive: true},
+		})
+	}
+	return cfg
+}
+
+func TestLightCompetition_ShadedSeedlingGrowsSlower(t *testing.T) {
+	g, err := New(competitionConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	for range 10 {
+		g.Simulator().Step()
+	}
+	shaded, err := g.Simulator().GetPlant("seedling-A")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	alone, err := g.Simulator().GetPlant("seedling-B")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if alone.GrowthStage == 0 || shaded.GrowthStage >= alone.GrowthStage {
+		t.Errorf("expected the shaded seedling to grow slower than the one alone, got %.4f and %.4f", shaded.GrowthStage, alone.GrowthStage)
+	}
+	// Five mature plants take all but the floor of the seedling's light.
+	if shaded.Shade != 0.9 || alone.Shade != 0 {
+		t.Errorf("expected the seedlings shaded by 0.9 and 0, got %v and %v", shaded.Shade, alone.Shade)
+	}
+}
+
+func TestLightCompetition_Off(t *testing.T) {
+	cfg := competitionConfig()
+	cfg.LightCompetition = nil
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	for range 10 {
+		g.Simulator().Step()
+	}
+	shaded, _ := g.Simulator().GetPlant("seedling-A")
+	alone, _ := g.Simulator().GetPlant("seedling-B")
+	if shaded.GrowthStage != alone.GrowthStage {
+		t.Errorf("expected the seedlings to grow alike without light competition, got %.4f and %.4f", shaded.GrowthStage, alone.GrowthStage)
+	}
+}
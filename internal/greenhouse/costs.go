@@ -0,0 +1,131 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/environment"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Costs is what running the greenhouse has cost: the water used and the
+// energy of the grow lights, the heater and the vent, at the configured
+// prices.
+type Costs struct {
+	Water    float64 `json:"water"`
+	Lighting float64 `json:"lighting"`
+	Heating  float64 `json:"heating"`
+	Venting  float64 `json:"venting"`
+	Total    float64 `json:"total"`
+}
+
+// CostLedger is what running the greenhouse has cost so far, by section and
+// in total. The heater and the vent serve the whole greenhouse, so their
+// costs only count towards the Total.
+type CostLedger struct {
+	Sections map[string]Costs `json:"sections,omitempty"`
+	Total    Costs            `json:"total"`
+}
+
+func (l CostLedger) clone() CostLedger {
+	l.Sections = maps.Clone(l.Sections)
+	return l
+}
+
+// costs keeps the cost ledger. At the end of every tick, it charges what was
+// used since the tick before at the current prices, so prices changed by a
+// config reload apply from the next tick on.
+type costs struct {
+	g      *greenhouse
+	ledger CostLedger
+	// water, lighting, heating and venting are the consumption charged so
+	// far, waterUsed and lightingUsed the totals of the first two.
+	water        map[string]float64
+	lighting     map[string]float64
+	waterUsed    float64
+	lightingUsed float64
+	heating      float64
+	venting      float64
+	mu           sync.Mutex
+}
+
+func newCosts(g *greenhouse) *costs {
+	return &costs{g: g, water: map[string]float64{}, lighting: map[string]float64{}}
+}
+
+// TickPhase names the cost ledger in tick traces.
+func (c *costs) TickPhase() string { return "costs" }
+
+func (c *costs) OnTick(tick int) {
+	var prices config.PricesConfig
+	if p := c.g.Config().Prices; p != nil {
+		prices = *p
+	}
+	stats := c.g.watering.GetWaterStats()
+	lighting := c.g.lights.Energy()
+	heating := c.g.hvac.ActuatorEnergy(environment.Heater)
+	venting := c.g.hvac.ActuatorEnergy(environment.Vent)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// The split by section is only looked at once something was used, which
+	// keeps ticks without any water or light from allocating.
+	if stats.Used != c.waterUsed {
+		c.waterUsed = stats.Used
+		for _, sectionID := range slices.Sorted(maps.Keys(stats.BySection)) {
+			used := max(0, stats.BySection[sectionID]-c.water[sectionID])
+			c.water[sectionID] = stats.BySection[sectionID]
+			c.charge(sectionID, func(costs *Costs) *float64 { return &costs.Water }, used*prices.Water)
+		}
+	}
+	if lighting != c.lightingUsed {
+		c.lightingUsed = lighting
+		bySection := c.g.lights.EnergyBySection()
+		for _, sectionID := range slices.Sorted(maps.Keys(bySection)) {
+			used := max(0, bySection[sectionID]-c.lighting[sectionID])
+			c.lighting[sectionID] = bySection[sectionID]
+			c.charge(sectionID, func(costs *Costs) *float64 { return &costs.Lighting }, used*prices.Energy)
+		}
+	}
+	c.charge("", func(costs *Costs) *float64 { return &costs.Heating }, max(0, heating-c.heating)*prices.Energy)
+	c.charge("", func(costs *Costs) *float64 { return &costs.Venting }, max(0, venting-c.venting)*prices.Energy)
+	c.heating, c.venting = heating, venting
+}
+
+// charge adds cost to the entry field picks of the total and, unless
+// sectionID is empty, of the section.
+func (c *costs) charge(sectionID string, field func(*Costs) *float64, cost float64) {
+	if cost == 0 {
+		return
+	}
+	*field(&c.ledger.Total) += cost
+	c.ledger.Total.Total += cost
+	if sectionID == "" {
+		return
+	}
+	if c.ledger.Sections == nil {
+		c.ledger.Sections = map[string]Costs{}
+	}
+	section := c.ledger.Sections[sectionID]
+	*field(&section) += cost
+	section.Total += cost
+	c.ledger.Sections[sectionID] = section
+}
+
+// Costs returns the cost ledger of the run so far.
+// This method is safe for concurrent use.
+func (g *greenhouse) Costs() CostLedger {
+	g.costs.mu.Lock()
+	defer g.costs.mu.Unlock()
+	return g.costs.ledger.clone()
+}
+
+// RestoreCosts replaces the cost ledger with one returned by Costs, such as
+// that of a saved run being resumed. What is used from then on is charged on
+// top of it.
+// This method is safe for concurrent use.
+func (g *greenhouse) RestoreCosts(ledger CostLedger) {
+	g.costs.mu.Lock()
+	defer g.costs.mu.Unlock()
+	g.costs.ledger = ledger.clone()
+}
@@ -0,0 +1,112 @@
+package greenhouse
+
+import (
+	"encoding/json"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/environment"
+	"reflect"
+	"testing"
+)
+
+// costsConfig runs lightsConfig with prices, a watering of each section and
+// the heater forced on for 5 ticks.
+func costsConfig() *config.GreenhouseConfig {
+	cfg := lightsConfig()
+	cfg.HVAC = &config.HVACConfig{HeaterPower: 1, HeaterEnergy: 2}
+	cfg.Prices = &config.PricesConfig{Water: 2, Energy: 0.5}
+	cfg.Timeline = []config.ActionConfig{
+		{Tick: 1, Action: config.ActionWater, SectionID: "section-A", Amount: 0.5},
+		{Tick: 3, Action: config.ActionWater, SectionID: "section-B", Amount: 0.25},
+		{Tick: 10, Action: config.ActionSetActuator, Actuator: environment.Heater, Mode: environment.ModeOn},
+		{Tick: 15, Action: config.ActionSetActuator, Actuator: environment.Heater, Mode: environment.ModeAuto},
+	}
+	return cfg
+}
+
+func TestRunScenario_Costs(t *testing.T) {
+	result, err := RunScenario(costsConfig(), 24, ScenarioOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Costs == nil {
+		t.Fatal("expected a cost summary when prices are set")
+	}
+
+	// 0.5 and 0.25 water at 2, the 4 ticks of 0.75 intensity at 2 energy of
+	// the section-A lights and the 5 ticks at 2 energy of the heater at 0.5.
+	expected := CostLedger{
+		Sections: map[string]Costs{
+			"section-A": {Water: 1, Lighting: 3, Total: 4},
+			"section-B": {Water: 0.5, Total: 0.5},
+		},
+		Total: Costs{Water: 1.5, Lighting: 3, Heating: 5, Total: 9.5},
+	}
+	if !reflect.DeepEqual(result.Costs.CostLedger, expected) {
+		t.Errorf("expected ledger %+v, got %+v", expected, result.Costs.CostLedger)
+	}
+
+	yield := 0.0
+	for _, plant := range result.Plants {
+		yield += plant.GrowthStage
+	}
+	if result.Costs.Yield != yield || yield == 0 {
+		t.Errorf("expected the yield to be the growth of the plants, %.4f, got %.4f", yield, result.Costs.Yield)
+	}
+	if result.Costs.CostPerYield != 9.5/yield {
+		t.Errorf("expected %.4f cost per yield, got %.4f", 9.5/yield, result.Costs.CostPerYield)
+	}
+}
+
+func TestRunScenario_NoPricesNoCosts(t *testing.T) {
+	cfg := costsConfig()
+	cfg.Prices = nil
+	result, err := RunScenario(cfg, 24, ScenarioOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Costs != nil {
+		t.Errorf("expected no cost summary without prices, got %+v", result.Costs)
+	}
+}
+
+func TestCosts_RestoreAndReload(t *testing.T) {
+	g, err := New(costsConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	for range 12 {
+		g.Simulator().Step()
+	}
+	// Saved and restored into a new greenhouse, the ledger carries on.
+	data, err := json.Marshal(g.Costs())
+	if err != nil {
+		t.Fatalf("failed to save the ledger: %v", err)
+	}
+	var saved CostLedger
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("failed to load the ledger: %v", err)
+	}
+	restored, err := New(costsConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	restored.RestoreCosts(saved)
+	if !reflect.DeepEqual(restored.Costs(), g.Costs()) {
+		t.Errorf("expected the restored ledger %+v, got %+v", g.Costs(), restored.Costs())
+	}
+
+	// Water doubles in price from the next tick on; the heater's ticks 10
+	// and 11 were charged at the old price.
+	cfg := costsConfig()
+	cfg.Prices = &config.PricesConfig{Water: 4, Energy: 0.5}
+	if _, err := g.ReloadConfig(cfg); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	if err := g.Watering().WaterSection("section-B", 0.25, 0); err != nil {
+		t.Fatalf("failed to water: %v", err)
+	}
+	g.Simulator().Step()
+	if got := g.Costs().Sections["section-B"].Water; got != 0.5+1 {
+		t.Errorf("expected 1.5 spent on section-B water, got %.4f", got)
+	}
+}
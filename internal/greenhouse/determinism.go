@@ -0,0 +1,208 @@
+package greenhouse
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"hash"
+	"io"
+	"strings"
+	"time"
+)
+
+// DeterminismReport is the outcome of VerifyDeterminism: the fingerprint of
+// each run and, if they differ, where the runs first went apart.
+type DeterminismReport struct {
+	Ticks int
+	Seed  int64
+	// Hashes are the fingerprints of the runs, in order: every event they
+	// published and their state after every tick, wall-clock timestamps left
+	// out.
+	Hashes        []string
+	Deterministic bool
+	// DivergedAt is the first tick on which a run differed from the first
+	// one, numbered like the ticks of events, and DivergingRun the index of
+	// that run, both -1 when the runs are deterministic.
+	DivergedAt   int
+	DivergingRun int
+	// Diff lists the events and state lines of that tick found in the first
+	// run only, prefixed by "- ", then those found in the diverging run only,
+	// prefixed by "+ ".
+	Diff []string
+}
+
+// VerifyDeterminism builds runs greenhouses from cfg with the given seed and
+// steps them ticks times side by side, hashing the events each publishes and
+// its state after each tick, see DeterminismReport. A run that does not
+// match the first one points at state shared between simulations or at an
+// order that is left to chance, such as that of a map. cfg is not changed.
+// Returns an error if ticks is negative, runs is below 2 or a greenhouse
+// cannot be built.
+func VerifyDeterminism(cfg *config.GreenhouseConfig, seed int64, ticks, runs int) (*DeterminismReport, error) {
+	return verifyDeterminism(cfg, seed, ticks, runs, nil)
+}
+
+// verifyDeterminism is VerifyDeterminism with setup called on the greenhouse
+// of each run, by index, before its first tick.
+func verifyDeterminism(cfg *config.GreenhouseConfig, seed int64, ticks, runs int, setup func(run int, g Greenhouse) error) (*DeterminismReport, error) {
+	if ticks < 0 {
+		return nil, errors.New("scenario ticks cannot be negative")
+	}
+	if runs < 2 {
+		return nil, errors.New("determinism runs must be at least 2")
+	}
+	seeded := *cfg
+	seeded.Seed = seed
+
+	greenhouses := make([]Greenhouse, runs)
+	traces := make([]*bytes.Buffer, runs)
+	hashes := make([]hash.Hash, runs)
+	for i := range runs {
+		g, err := New(&seeded)
+		if err != nil {
+			return nil, err
+		}
+		if setup != nil {
+			if err := setup(i, g); err != nil {
+				return nil, err
+			}
+		}
+		trace := &bytes.Buffer{}
+		g.Bus().Subscribe(func(e events.Event) {
+			writeEvent(trace, e)
+		})
+		greenhouses[i], traces[i], hashes[i] = g, trace, sha256.New()
+	}
+
+	report := &DeterminismReport{Ticks: ticks, Seed: seed, DivergedAt: -1, DivergingRun: -1}
+	for range ticks {
+		tick := greenhouses[0].Simulator().GetCurrentTick()
+		for i, g := range greenhouses {
+			traces[i].Reset()
+			g.Simulator().Step()
+			g.Exporters().Drain()
+			writeState(traces[i], g)
+			hashes[i].Write(traces[i].Bytes())
+		}
+		if report.DivergingRun >= 0 {
+			continue
+		}
+		for i := 1; i < runs; i++ {
+			if !bytes.Equal(traces[0].Bytes(), traces[i].Bytes()) {
+				report.DivergedAt = tick
+				report.DivergingRun = i
+				report.Diff = diffLines(traces[0].String(), traces[i].String())
+				break
+			}
+		}
+	}
+	for _, g := range greenhouses {
+		if err := g.Exporters().Close(0); err != nil {
+			return nil, err
+		}
+	}
+
+	report.Deterministic = report.DivergingRun < 0
+	for _, h := range hashes {
+		report.Hashes = append(report.Hashes, hex.EncodeToString(h.Sum(nil)))
+	}
+	return report, nil
+}
+
+// writeEvent writes an event on a line, its payload included and its
+// wall-clock timestamps left out.
+func writeEvent(w io.Writer, e events.Event) {
+	fmt.Fprintf(w, "%s %d %s %s ", e.Type, e.Tick, e.SectionID, e.PlantID)
+	writePayload(w, e.Payload)
+}
+
+// writePayload writes an event payload on a line, with the wall-clock
+// timestamps it holds zeroed and the values behind its pointers written out.
+func writePayload(w io.Writer, payload any) {
+	switch p := payload.(type) {
+	case models.SensorReading:
+		p.Timestamp = time.Time{}
+		if p.Battery != nil {
+			fmt.Fprintf(w, "battery %v ", *p.Battery)
+			p.Battery = nil
+		}
+		if p.Instant != nil {
+			fmt.Fprintf(w, "instant %v ", *p.Instant)
+			p.Instant = nil
+		}
+		payload = p
+	case models.WateringEvent:
+		p.StartTime = time.Time{}
+		payload = p
+	case Stats:
+		if p.TankRemaining != nil {
+			fmt.Fprintf(w, "tank %v ", *p.TankRemaining)
+			p.TankRemaining = nil
+		}
+		payload = p
+	}
+	fmt.Fprintf(w, "%+v\n", payload)
+}
+
+// writeState writes the conditions of the greenhouse on a line, then each
+// plant on a line, in the order of the simulator.
+func writeState(w io.Writer, g Greenhouse) {
+	fmt.Fprintf(w, "%+v\n", g.Conditions())
+	for _, plant := range g.Simulator().GetAllPlants() {
+		fmt.Fprintf(w, "%s %v %v %v %v\n", plant.ID, plant.SoilSaturation, plant.Health, plant.GrowthStage, plant.Alive)
+	}
+}
+
+// diffLines returns the lines of a missing from b, prefixed by "- ", then
+// those of b missing from a, prefixed by "+ ", each in its order. A line
+// repeated counts as many times as it appears.
+func diffLines(a, b string) []string {
+	linesA := strings.Split(strings.TrimSuffix(a, "\n"), "\n")
+	linesB := strings.Split(strings.TrimSuffix(b, "\n"), "\n")
+	var diff []string
+	for _, side := range []struct {
+		prefix string
+		lines  []string
+		other  []string
+	}{{"- ", linesA, linesB}, {"+ ", linesB, linesA}} {
+		counts := map[string]int{}
+		for _, line := range side.other {
+			counts[line]++
+		}
+		for _, line := range side.lines {
+			if counts[line] > 0 {
+				counts[line]--
+				continue
+			}
+			diff = append(diff, side.prefix+line)
+		}
+	}
+	return diff
+}
+
+// WriteText writes the report as text: whether the runs match, the hash of
+// each run and, when they do not, the diff of the first tick they differ on.
+func (r *DeterminismReport) WriteText(w io.Writer) error {
+	if r.Deterministic {
+		fmt.Fprintf(w, "deterministic: %d runs of %d ticks with seed %d match\n", len(r.Hashes), r.Ticks, r.Seed)
+	} else {
+		fmt.Fprintf(w, "not deterministic: run %d diverged from run 0 on tick %d of %d with seed %d\n", r.DivergingRun, r.DivergedAt, r.Ticks, r.Seed)
+	}
+	for i, h := range r.Hashes {
+		fmt.Fprintf(w, "run %d: %s\n", i, h)
+	}
+	if len(r.Diff) > 0 {
+		fmt.Fprintf(w, "\ndiff of tick %d, run 0 (-) against run %d (+):\n", r.DivergedAt, r.DivergingRun)
+	}
+	for _, line := range r.Diff {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
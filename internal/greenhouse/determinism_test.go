@@ -0,0 +1,90 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/engine"
+	"strings"
+	"testing"
+)
+
+func TestVerifyDeterminism_Deterministic(t *testing.T) {
+	report, err := VerifyDeterminism(goldenConfig(0), 42, 200, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.Deterministic || report.DivergedAt != -1 || report.DivergingRun != -1 || len(report.Diff) != 0 {
+		t.Fatalf("expected the golden config to be deterministic, got %+v", report)
+	}
+	// The runs hash what the golden run does, with the seed given.
+	expected := fingerprintRun(t, goldenConfig(42), 200)
+	if len(report.Hashes) != 3 {
+		t.Fatalf("expected a hash per run, got %v", report.Hashes)
+	}
+	for i, h := range report.Hashes {
+		if h != expected {
+			t.Errorf("expected run %d to hash to %s, got %s", i, expected, h)
+		}
+	}
+}
+
+func TestVerifyDeterminism_DetectsDivergence(t *testing.T) {
+	// The hook keeps its state across simulations, like a package variable
+	// would: only the first run to reach tick 10 gets the extra water.
+	watered := -1
+	setup := func(run int, g Greenhouse) error {
+		return g.Simulator().AddTickHook("leaky", func(ctx engine.TickContext) error {
+			if ctx.Tick() < 10 || watered >= 0 {
+				return nil
+			}
+			watered = ctx.Tick()
+			return ctx.WaterPlant("basil-1", 0.3)
+		})
+	}
+	report, err := verifyDeterminism(testConfig(), 1, 30, 3, setup)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Deterministic || report.DivergedAt != watered || report.DivergingRun != 1 {
+		t.Fatalf("expected run 1 to diverge on tick %d, got %+v", watered, report)
+	}
+	if report.Hashes[0] == report.Hashes[1] || report.Hashes[1] != report.Hashes[2] {
+		t.Errorf("expected only the first run to hash differently, got %v", report.Hashes)
+	}
+	var removed, added bool
+	for _, line := range report.Diff {
+		removed = removed || strings.HasPrefix(line, "- basil-1 ")
+		added = added || strings.HasPrefix(line, "+ basil-1 ")
+		if strings.Contains(line, "basil-2") {
+			t.Errorf("expected the plant left alone out of the diff, got %q", line)
+		}
+	}
+	if !removed || !added {
+		t.Errorf("expected the diff to show both states of the watered plant, got %v", report.Diff)
+	}
+
+	var text strings.Builder
+	if err := report.WriteText(&text); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(text.String(), "not deterministic: run 1 diverged from run 0 on tick") {
+		t.Errorf("expected the text to report the divergence, got %q", text.String())
+	}
+}
+
+func TestVerifyDeterminism_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		ticks    int
+		runs     int
+		errorMsg string
+	}{
+		{"negative ticks", -1, 2, "scenario ticks cannot be negative"},
+		{"single run", 10, 1, "determinism runs must be at least 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := VerifyDeterminism(testConfig(), 1, tt.ticks, tt.runs); err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package greenhouse
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/models"
+	"testing"
+	"time"
+)
+
+// diagnosisConfig has ferns kept too dry, too wet and just right in
+// section-A, and another too dry in section-B, both sections in one zone.
+func diagnosisConfig() *config.GreenhouseConfig {
+	return &config.GreenhouseConfig{
+		TickInterval: config.Duration(time.Second),
+		Environment:  config.EnvironmentConfig{Temperature: 20},
+		PlantTypes: []config.PlantTypeConfig{
+			{Name: "Fern", OptimalSaturation: 0.5, MinSaturation: 0.3, MaxSaturation: 0.7, HealthDegradationRate: 0.01},
+		},
+		Plants: []config.PlantConfig{
+			{ID: "dry", Type: "Fern", SectionID: "section-A", InitialSaturation: 0.1},
+			{ID: "wet", Type: "Fern", SectionID: "section-A", InitialSaturation: 0.9},
+			{ID: "fine", Type: "Fern", SectionID: "section-A", InitialSaturation: 0.5},
+			{ID: "parched", Type: "Fern", SectionID: "section-B", InitialSaturation: 0.1},
+		},
+		Zones: []config.ZoneConfig{{ID: "all", Sections: []string{"section-A", "section-B"}}},
+	}
+}
+
+func TestSectionStats_LimitingFactors(t *testing.T) {
+	g, err := New(diagnosisConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	g.Simulator().Step()
+
+	tests := []struct {
+		section string
+		dry     int
+		wet     int
+	}{
+		{"section-A", 1, 1},
+		{"section-B", 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.section, func(t *testing.T) {
+			stats, err := g.SectionStats(tt.section)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			factors := stats.LimitingFactors
+			if factors.Count(models.FactorTooDry) != tt.dry || factors.Count(models.FactorTooWet) != tt.wet || factors.Count(models.FactorSalinity) != 0 {
+				t.Errorf("expected %d plants too dry and %d too wet, got %v", tt.dry, tt.wet, factors)
+			}
+		})
+	}
+
+	stats, err := g.ZoneStats("all")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.LimitingFactors.Count(models.FactorTooDry) != 2 || stats.LimitingFactors.Count(models.FactorTooWet) != 1 {
+		t.Errorf("expected the zone to count the plants of both sections, got %v", stats.LimitingFactors)
+	}
+
+	// The watering runs on the next tick, after the plant has ticked, so the
+	// plant is no longer too dry the tick after.
+	if err := g.Watering().WaterSection("section-B", 0.4, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g.Simulator().Step()
+	g.Simulator().Step()
+	if stats, _ := g.SectionStats("section-B"); stats.LimitingFactors.Count(models.FactorTooDry) != 0 {
+		t.Errorf("expected the watered plant to be no longer too dry, got %v", stats.LimitingFactors)
+	}
+
+	if _, err := g.SectionStats("section-Z"); !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("expected %v, got %v", ErrSectionNotFound, err)
+	}
+}
+
+func TestGetPlantDiagnosis(t *testing.T) {
+	g, err := New(diagnosisConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	g.Simulator().Step()
+
+	diagnosis, err := g.Simulator().GetPlantDiagnosis("dry")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diagnosis.Limiting() != models.FactorTooDry || diagnosis.Loss(models.FactorTooDry) <= 0 {
+		t.Errorf("expected the dry plant to lose health to drought, got %+v", diagnosis)
+	}
+	if diagnosis, _ := g.Simulator().GetPlantDiagnosis("fine"); diagnosis.Limiting() != "" {
+		t.Errorf("expected nothing to limit the fine plant, got %s", diagnosis.Limiting())
+	}
+	if _, err := g.Simulator().GetPlantDiagnosis("cactus"); err == nil {
+		t.Error("expected an error for an unknown plant")
+	}
+}
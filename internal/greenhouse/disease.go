@@ -0,0 +1,160 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/environment"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"greenhouse-simulator/internal/rng"
+	"slices"
+	"sync"
+	"time"
+)
+
+// diseases runs the disease model once the humidity of a tick is known: the
+// diseases of the plants progress, the scripted infections and treatments
+// are applied and the diseases spread within their sections at the humidity
+// of the tick, that of their microclimate included. Quarantined plants
+// neither spread a disease nor catch one from their section. It publishes a PlantInfected, PlantSymptomatic or PlantCured
+// event for each plant that catches a disease, shows its first symptoms or
+// is cured. The draws of each tick are split off by tick number, so a run
+// resumed from an export draws what the original run would have.
+type diseases struct {
+	g         *greenhouse
+	config    environment.DiseaseConfig
+	spread    rng.Source
+	treatment rng.Source
+	// infect and treat are the plants to infect and the sections to treat on
+	// the next tick.
+	infect []string
+	treat  []string
+	mu     sync.Mutex
+}
+
+func newDiseases(g *greenhouse, cfg environment.DiseaseConfig, random rng.Source) *diseases {
+	return &diseases{
+		g:         g,
+		config:    cfg,
+		spread:    random.Split("spread"),
+		treatment: random.Split("treatment"),
+	}
+}
+
+// TickPhase names the disease model in tick traces.
+func (d *diseases) TickPhase() string { return "disease" }
+
+func (d *diseases) OnTick(tick int) {
+	d.mu.Lock()
+	infect, treat := d.infect, d.treat
+	d.infect, d.treat = nil, nil
+	d.mu.Unlock()
+
+	plants := d.g.sim.GetAllPlants()
+	var published []events.Event
+	d.g.sim.UpdatePlants(func() {
+		published = d.progress(tick, plants, infect, treat)
+	})
+	for _, e := range published {
+		d.g.bus.Publish(e)
+	}
+}
+
+// progress runs the disease model over the plants of a tick, infecting the
+// plants of infect and treating the sections of treat, and returns the
+// events to publish. The caller must hold the lock of the plants, see
+// engine.Simulator.UpdatePlants.
+func (d *diseases) progress(tick int, plants []*models.Plant, infect, treat []string) []events.Event {
+	var published []events.Event
+	for _, plant := range plants {
+		if plant.Sicken(d.config.Incubation, d.config.HealthDecay, d.config.TranspirationDrop) {
+			published = append(published, d.event(events.PlantSymptomatic, tick, plant))
+		}
+	}
+	for _, plant := range plants {
+		if slices.Contains(infect, plant.ID) && plant.Infect() {
+			published = append(published, d.event(events.PlantInfected, tick, plant))
+		}
+	}
+	if len(treat) > 0 {
+		random := d.treatment.SplitN(tick)
+		for _, plant := range plants {
+			if plant.Alive && plant.Diseased() && slices.Contains(treat, plant.SectionID) && random.Float64() < d.config.CureChance {
+				plant.Cure()
+				published = append(published, d.event(events.PlantCured, tick, plant))
+			}
+		}
+	}
+
+	diseased := map[string]int{}
+	for _, plant := range plants {
+		if plant.Alive && plant.Diseased() && !plant.Quarantined {
+			diseased[plant.SectionID]++
+		}
+	}
+	if len(diseased) == 0 {
+		return published
+	}
+	// Plants catching the disease on this tick spread it from the next on.
+	random := d.spread.SplitN(tick)
+	var caught []*models.Plant
+	for _, plant := range plants {
+		n := diseased[plant.SectionID]
+		if n == 0 || !plant.Alive || plant.Diseased() || plant.Quarantined {
+			continue
+		}
+		if random.Float64() < d.config.SpreadChance(d.g.sectionHumidity(plant.SectionID), n) {
+			caught = append(caught, plant)
+		}
+	}
+	for _, plant := range caught {
+		plant.Infect()
+		published = append(published, d.event(events.PlantInfected, tick, plant))
+	}
+	return published
+}
+
+func (d *diseases) event(t events.Type, tick int, plant *models.Plant) events.Event {
+	return events.Event{
+		Type:      t,
+		Tick:      tick,
+		Timestamp: time.Now(),
+		SectionID: plant.SectionID,
+		PlantID:   plant.ID,
+		Payload:   plant.Disease,
+	}
+}
+
+// InfectPlant infects a plant with the disease on the next tick the disease
+// model runs, which is the tick being processed when called from the
+// timeline. The plant incubates it for the configured incubation ticks
+// before showing symptoms. Plants that are dead or already diseased by then
+// are left as they are. Returns an error wrapping engine.ErrPlantNotFound if
+// there is no such plant, or ErrNoDiseaseModel without a disease model.
+// This method is safe for concurrent use.
+func (g *greenhouse) InfectPlant(plantID string) error {
+	if g.disease == nil {
+		return ErrNoDiseaseModel
+	}
+	if _, err := g.sim.GetPlant(plantID); err != nil {
+		return err
+	}
+	g.disease.mu.Lock()
+	defer g.disease.mu.Unlock()
+	g.disease.infect = append(g.disease.infect, plantID)
+	return nil
+}
+
+// TreatDisease treats the diseased plants of a section on the next tick the
+// disease model runs, curing each with the configured cure chance. Returns
+// ErrNoDiseaseModel without a disease model.
+// This method is safe for concurrent use.
+func (g *greenhouse) TreatDisease(sectionID string) error {
+	if g.disease == nil {
+		return ErrNoDiseaseModel
+	}
+	g.disease.mu.Lock()
+	defer g.disease.mu.Unlock()
+	if !slices.Contains(g.disease.treat, sectionID) {
+		g.disease.treat = append(g.disease.treat, sectionID)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package greenhouse
+
+import (
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// diseaseConfig has a plant in each of section-A and section-B, each with a
+// soil moisture sensor, and a disease incubating for 3 ticks that does not
+// spread.
+func diseaseConfig() *config.GreenhouseConfig {
+	return &config.GreenhouseConfig{
+		TickInterval: config.Duration(time.Second),
+		Environment:  config.EnvironmentConfig{AmbientHumidity: 0.8},
+		PlantTypes: []config.PlantTypeConfig{
+			{Name: "Sprout", OptimalSaturation: 0.6, MinSaturation: 0.2, MaxSaturation: 0.9, BaseGrowthRate: 0.01, SaturationDepletion: 0.02, HealthEnhancementRate: 0.01},
+		},
+		Plants: []config.PlantConfig{
+			{ID: "sick", Type: "Sprout", SectionID: "section-A", InitialSaturation: 0.6},
+			{ID: "well", Type: "Sprout", SectionID: "section-B", InitialSaturation: 0.6},
+		},
+		Sensors: []config.SensorConfig{
+			{ID: "moisture-A", Type: models.SoilMoisture, SectionID: "section-A"},
+			{ID: "moisture-B", Type: models.SoilMoisture, SectionID: "section-B"},
+		},
+		Disease: &config.DiseaseConfig{Incubation: 3, HealthDecay: 0.05, TranspirationDrop: 0.5, CureChance: 1},
+	}
+}
+
+// diseaseEvents collects the disease events published by g as
+// "type tick plant" strings.
+func diseaseEvents(g Greenhouse) *[]string {
+	var published []string
+	g.Bus().Subscribe(func(e events.Event) {
+		switch e.Type {
+		case events.PlantInfected, events.PlantSymptomatic, events.PlantCured:
+			published = append(published, fmt.Sprintf("%s %d %s", e.Type, e.Tick, e.PlantID))
+		}
+	})
+	return &published
+}
+
+func TestDisease_Incubation(t *testing.T) {
+	cfg := diseaseConfig()
+	cfg.Timeline = []config.ActionConfig{{Tick: 2, Action: config.ActionInfectPlant, PlantID: "sick"}}
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	published := diseaseEvents(g)
+
+	// Infected on tick 2, the plant incubates the disease on ticks 3 to 5
+	// just like its healthy twin, and shows symptoms on tick 6.
+	for tick := 0; tick <= 5; tick++ {
+		g.Simulator().Step()
+		plants := g.Simulator().GetAllPlants()
+		if plants[0].Health != plants[1].Health || plants[0].SoilSaturation != plants[1].SoilSaturation {
+			t.Fatalf("tick %d: expected no symptoms yet, got %v and %v", tick, plants[0], plants[1])
+		}
+	}
+	g.Simulator().Step()
+	expected := []string{"plant_infected 2 sick", "plant_symptomatic 6 sick"}
+	if !reflect.DeepEqual(*published, expected) {
+		t.Errorf("expected the events %v, got %v", expected, *published)
+	}
+
+	for range 4 {
+		g.Simulator().Step()
+	}
+	plants := g.Simulator().GetAllPlants()
+	if plants[0].Health >= plants[1].Health {
+		t.Errorf("expected the symptomatic plant to lose health, got %.2f against %.2f", plants[0].Health, plants[1].Health)
+	}
+	// Transpiring half as much, its soil reads wetter.
+	sick, _ := g.Sensors().GetReading("moisture-A")
+	well, _ := g.Sensors().GetReading("moisture-B")
+	if sick.Value <= well.Value {
+		t.Errorf("expected the sick plant's soil to read wetter, got %.3f against %.3f", sick.Value, well.Value)
+	}
+}
+
+// spreadConfig has 20 sections of 5 plants each, one of which is infected on
+// the first tick, at the given ambient humidity.
+func spreadConfig(humidity float64) *config.GreenhouseConfig {
+	cfg := diseaseConfig()
+	cfg.Environment.AmbientHumidity = humidity
+	cfg.Disease = &config.DiseaseConfig{Incubation: 100, SpreadRate: 0.1}
+	cfg.Plants, cfg.Sensors = nil, nil
+	for section := range 20 {
+		sectionID := fmt.Sprintf("section-%d", section)
+		for i := range 5 {
+			cfg.Plants = append(cfg.Plants, config.PlantConfig{ID: fmt.Sprintf("%s-%d", sectionID, i), Type: "Sprout", SectionID: sectionID, InitialSaturation: 0.6})
+		}
+		cfg.Timeline = append(cfg.Timeline, config.ActionConfig{Action: config.ActionInfectPlant, PlantID: sectionID + "-0"})
+	}
+	return cfg
+}
+
+func TestDisease_SpreadRisesWithHumidity(t *testing.T) {
+	run := func(humidity float64) []string {
+		g, err := New(spreadConfig(humidity))
+		if err != nil {
+			t.Fatalf("failed to build greenhouse: %v", err)
+		}
+		published := diseaseEvents(g)
+		for range 10 {
+			g.Simulator().Step()
+		}
+		return (*published)[20:]
+	}
+
+	// A healthy plant next to a diseased one catches it with a chance of
+	// 0.02 per tick in dry air and 0.08 in humid air.
+	dry, humid := run(0.2), run(0.8)
+	if len(dry) == 0 || len(humid) < 2*len(dry) {
+		t.Errorf("expected the disease to spread much faster in humid air, got %d infections in dry air and %d in humid air", len(dry), len(humid))
+	}
+	if again := run(0.8); !reflect.DeepEqual(again, humid) {
+		t.Errorf("expected the same seed to spread the same way, got %v and %v", humid, again)
+	}
+	if none := run(0); len(none) != 0 {
+		t.Errorf("expected no spread in dry air, got %v", none)
+	}
+}
+
+func TestTreatDisease(t *testing.T) {
+	cfg := diseaseConfig()
+	cfg.Timeline = []config.ActionConfig{
+		{Tick: 0, Action: config.ActionInfectPlant, PlantID: "sick"},
+		{Tick: 5, Action: config.ActionTreatDisease, SectionID: "section-A"},
+	}
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	published := diseaseEvents(g)
+	for range 7 {
+		g.Simulator().Step()
+	}
+	expected := []string{"plant_infected 0 sick", "plant_symptomatic 4 sick", "plant_cured 5 sick"}
+	if !reflect.DeepEqual(*published, expected) {
+		t.Errorf("expected the events %v, got %v", expected, *published)
+	}
+	if plant := g.Simulator().GetAllPlants()[0]; plant.Diseased() {
+		t.Errorf("expected the plant to be cured, got %+v", plant.Disease)
+	}
+
+	if err := g.InfectPlant("ghost"); !errors.Is(err, engine.ErrPlantNotFound) {
+		t.Errorf("expected an unknown plant to be refused, got %v", err)
+	}
+	cfg = diseaseConfig()
+	cfg.Disease = nil
+	if g, err = New(cfg); err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	if err := g.InfectPlant("sick"); !errors.Is(err, ErrNoDiseaseModel) {
+		t.Errorf("expected infecting without a disease model to be refused, got %v", err)
+	}
+	if err := g.TreatDisease("section-A"); !errors.Is(err, ErrNoDiseaseModel) {
+		t.Errorf("expected treating without a disease model to be refused, got %v", err)
+	}
+}
+
+func TestDisease_ExportResumesInfection(t *testing.T) {
+	cfg := diseaseConfig()
+	cfg.Timeline = []config.ActionConfig{{Tick: 0, Action: config.ActionInfectPlant, PlantID: "sick"}}
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	for range 5 {
+		g.Simulator().Step()
+	}
+	exported, err := g.ExportScenario(config.ExportOptions{ExactResume: true})
+	if err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	resumed, err := New(exported)
+	if err != nil {
+		t.Fatalf("failed to load the export: %v", err)
+	}
+	expected := models.Disease{Stage: models.Symptomatic, Ticks: 4}
+	if got := resumed.Simulator().GetAllPlants()[0].Disease; got != expected {
+		t.Errorf("expected the resumed plant to carry %+v, got %+v", expected, got)
+	}
+	if !reflect.DeepEqual(exported.Disease, cfg.Disease) {
+		t.Errorf("expected the disease settings to be exported, got %+v", exported.Disease)
+	}
+}
+
+func TestDisease_QuarantineStopsSpread(t *testing.T) {
+	released := false
+	cfg := diseaseConfig()
+	cfg.Environment.AmbientHumidity = 1
+	cfg.Disease = &config.DiseaseConfig{Incubation: 100, SpreadRate: 1}
+	cfg.Sensors = nil
+	cfg.Plants = append(cfg.Plants,
+		config.PlantConfig{ID: "exposed", Type: "Sprout", SectionID: "section-A", InitialSaturation: 0.6},
+		config.PlantConfig{ID: "isolated", Type: "Sprout", SectionID: "section-A", InitialSaturation: 0.6, Quarantined: true},
+		config.PlantConfig{ID: "carrier", Type: "Sprout", SectionID: "section-B", InitialSaturation: 0.6, Quarantined: true})
+	cfg.Timeline = []config.ActionConfig{
+		{Action: config.ActionInfectPlant, PlantID: "sick"},
+		{Action: config.ActionInfectPlant, PlantID: "carrier"},
+		{Tick: 5, Action: config.ActionSetPlantFlags, PlantID: "isolated", Quarantined: &released},
+	}
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	diseased := func() []string {
+		var ids []string
+		for _, plant := range g.Simulator().GetAllPlants() {
+			if plant.Diseased() {
+				ids = append(ids, plant.ID)
+			}
+		}
+		return ids
+	}
+
+	// Every plant next to a diseased one catches it, but the quarantined
+	// plant of section-A neither catches it nor does the quarantined carrier
+	// pass it on in section-B.
+	for range 5 {
+		g.Simulator().Step()
+	}
+	if expected := []string{"sick", "exposed", "carrier"}; !reflect.DeepEqual(diseased(), expected) {
+		t.Errorf("expected %v to be diseased, got %v", expected, diseased())
+	}
+	g.Simulator().Step()
+	if expected := []string{"sick", "exposed", "isolated", "carrier"}; !reflect.DeepEqual(diseased(), expected) {
+		t.Errorf("expected the released plant to catch it, got %v", diseased())
+	}
+}
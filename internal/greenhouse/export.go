@@ -0,0 +1,562 @@
+package greenhouse
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// DefaultExportWorkers is the size of the worker pool exporters share
+	// when the config does not set it.
+	DefaultExportWorkers = 4
+	// DefaultExportQueue is the number of items queued for an exporter
+	// before new ones are dropped, when Register is given no queue size.
+	DefaultExportQueue = 4096
+	// exportBatch is how many queued items a worker hands an exporter before
+	// giving the other exporters a turn.
+	exportBatch = 64
+)
+
+// ErrExporterExists is returned when registering an exporter under a taken
+// name.
+var ErrExporterExists = errors.New("exporter already registered")
+
+// ErrExporterNotFound is returned for an exporter name that is not
+// registered.
+var ErrExporterNotFound = errors.New("no exporter registered with the provided name")
+
+// ExportedTick is what exporters learn of a tick, once every tick listener
+// has handled it.
+type ExportedTick struct {
+	Tick      int
+	Timestamp time.Time
+	Stats     Stats
+	// Plants is the state of every plant after the tick, ordered by ID. It
+	// is shared by every exporter, which must not change it.
+	Plants []models.Plant
+	// Span is the span context of the tick when ticks are traced.
+	Span trace.SpanContext
+}
+
+// ExportedReading is a sensor reading taken on a tick.
+type ExportedReading struct {
+	Tick      int
+	SectionID string
+	Reading   models.SensorReading
+}
+
+// Exporter is an output sink attached to a greenhouse through its
+// ExportRegistry. The registry calls an exporter from a shared pool of
+// workers, one call at a time and in the order things happened: for every
+// tick, the readings and the other events published during the tick, then
+// the tick itself. Errors are counted in the exporter's ExporterStats.
+type Exporter interface {
+	// HandleTick handles a tick.
+	HandleTick(t ExportedTick) error
+	// HandleReading handles a sensor reading.
+	HandleReading(r ExportedReading) error
+	// HandleEvent handles any other event, e.g. a watering or a dead plant.
+	HandleEvent(e events.Event) error
+	// Flush is called whenever the exporter has caught up with its queue
+	// after handling a tick, so that it can write what it holds. Exporters
+	// writing on their own schedule may ignore it.
+	Flush() error
+	// Close is called once the exporter has been removed and flushed.
+	Close() error
+}
+
+// ExporterStats counts what happened to the items queued for an exporter.
+// Queued is the depth of its queue and HighWater the deepest it has been, to
+// be compared with QueueSize, beyond which items are Dropped. Failed counts
+// the calls that returned an error or panicked, LastError describes the last
+// of them, and LastFlush is how long the last flush took.
+type ExporterStats struct {
+	Name      string        `json:"name"`
+	QueueSize int           `json:"queue_size"`
+	Queued    int           `json:"queued"`
+	HighWater int           `json:"high_water"`
+	Handled   int           `json:"handled"`
+	Dropped   int           `json:"dropped"`
+	Failed    int           `json:"failed"`
+	LastError string        `json:"last_error,omitempty"`
+	LastFlush time.Duration `json:"last_flush_ns"`
+}
+
+// ExportRegistry attaches exporters to a greenhouse. Every exporter has its
+// own bounded queue, filled on the tick goroutine and emptied by the worker
+// pool, so a slow, stuck or panicking exporter never holds up the ticks or
+// the other exporters: once its queue is full, new items are dropped for it
+// alone. A stuck exporter does hold one worker.
+type ExportRegistry interface {
+	// Register attaches an exporter under a unique name.
+	Register(name string, x Exporter, queueSize int) error
+	// Remove detaches an exporter once it has caught up, then closes it.
+	Remove(name string) error
+	// Drain waits until every exporter has caught up with its queue.
+	Drain()
+	// Flush waits until every exporter has caught up and flushed, or ctx is done.
+	Flush(ctx context.Context) error
+	// Stats returns the stats of every exporter, in registration order.
+	Stats() []ExporterStats
+	// Close removes every exporter and stops the worker pool.
+	Close(timeout time.Duration) error
+}
+
+// exportItem is a tick, a reading or an event queued for an exporter.
+type exportItem struct {
+	tick    *ExportedTick
+	reading *ExportedReading
+	event   events.Event
+}
+
+// sink is a registered exporter with its queue. scheduled is set while the
+// sink waits for a worker, a worker runs it or Flush flushes it; idle is
+// closed when the sink is removing and no longer scheduled. ticked is set
+// once a tick is handled and dirty once anything is, until the next flush,
+// and flushed called after each flush that succeeded.
+type sink struct {
+	name      string
+	exporter  Exporter
+	queueSize int
+	queue     []exportItem
+	scheduled bool
+	ticked    bool
+	dirty     bool
+	flushed   func()
+	removing  bool
+	idle      chan struct{}
+	stats     ExporterStats
+	mu        sync.Mutex
+}
+
+type exportRegistry struct {
+	g       *greenhouse
+	workers int
+	started bool
+	stopped bool
+	sinks   []*sink
+	ready   []*sink
+	// wake is signalled when a sink is ready or the pool stops; caught up is
+	// broadcast whenever a sink stops being scheduled.
+	wake     *sync.Cond
+	caughtUp *sync.Cond
+	mu       sync.Mutex
+}
+
+// newExportRegistry returns the registry of g, whose pool runs the given
+// number of workers once the first exporter is registered.
+func newExportRegistry(g *greenhouse, workers int) *exportRegistry {
+	if workers <= 0 {
+		workers = DefaultExportWorkers
+	}
+	r := &exportRegistry{g: g, workers: workers}
+	r.wake = sync.NewCond(&r.mu)
+	r.caughtUp = sync.NewCond(&r.mu)
+	g.bus.Subscribe(r.publish)
+	return r
+}
+
+// Register attaches an exporter under a unique name, with a queue of
+// queueSize items, DefaultExportQueue when zero. It is handed everything
+// published from now on. Returns an error if the name is empty, the queue
+// size is negative, the registry is closed, or the name is taken
+// (ErrExporterExists).
+// This method is safe for concurrent use.
+func (r *exportRegistry) Register(name string, x Exporter, queueSize int) error {
+	if name == "" {
+		return errors.New("exporter name cannot be empty")
+	}
+	if queueSize < 0 {
+		return errors.New("exporter queue size cannot be negative: " + name)
+	}
+	if queueSize == 0 {
+		queueSize = DefaultExportQueue
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopped {
+		return errors.New("exporters are closed")
+	}
+	if slices.ContainsFunc(r.sinks, func(s *sink) bool { return s.name == name }) {
+		return fmt.Errorf("%w: %s", ErrExporterExists, name)
+	}
+	r.sinks = append(r.sinks, &sink{name: name, exporter: x, queueSize: queueSize, flushed: r.g.Touch, stats: ExporterStats{Name: name, QueueSize: queueSize}})
+	if !r.started {
+		r.started = true
+		for range r.workers {
+			go r.work()
+		}
+	}
+	return nil
+}
+
+// Remove stops queueing for the named exporter, waits until it has handled
+// what is queued, then closes it and returns the error of Close. Returns an
+// error wrapping ErrExporterNotFound if no exporter has that name.
+// This method is safe for concurrent use.
+func (r *exportRegistry) Remove(name string) error {
+	r.mu.Lock()
+	i := slices.IndexFunc(r.sinks, func(s *sink) bool { return s.name == name })
+	if i < 0 {
+		r.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrExporterNotFound, name)
+	}
+	s := r.sinks[i]
+	r.sinks = slices.Delete(r.sinks, i, i+1)
+	r.mu.Unlock()
+
+	<-s.remove()
+	return s.call(s.exporter.Close)
+}
+
+// Drain waits until every exporter has handled what is queued for it. A
+// stuck exporter makes it wait forever.
+// This method is safe for concurrent use.
+func (r *exportRegistry) Drain() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for slices.ContainsFunc(r.sinks, (*sink).busy) {
+		r.caughtUp.Wait()
+	}
+}
+
+// Flush waits until every exporter has handled what is queued for it, then
+// flushes those that handled anything since their last flush, so that what
+// they hold is written, as before shutting down. An exporter that keeps
+// getting items, as while the simulation runs, is flushed whenever it
+// catches up after a tick. Returns an error wrapping the error of ctx and
+// naming the exporters that did not catch up before ctx was done; they are
+// not flushed.
+// This method is safe for concurrent use.
+func (r *exportRegistry) Flush(ctx context.Context) error {
+	stop := context.AfterFunc(ctx, func() {
+		r.mu.Lock()
+		r.caughtUp.Broadcast()
+		r.mu.Unlock()
+	})
+	defer stop()
+
+	r.mu.Lock()
+	for ctx.Err() == nil && slices.ContainsFunc(r.sinks, (*sink).busy) {
+		r.caughtUp.Wait()
+	}
+	sinks := slices.Clone(r.sinks)
+	r.mu.Unlock()
+
+	var behind []string
+	for _, s := range sinks {
+		if !s.claim() {
+			behind = append(behind, s.name)
+			continue
+		}
+		s.mu.Lock()
+		dirty := s.dirty
+		s.mu.Unlock()
+		if dirty {
+			s.flush()
+		}
+		if s.settle() {
+			r.schedule(s)
+		}
+	}
+	r.mu.Lock()
+	r.caughtUp.Broadcast()
+	r.mu.Unlock()
+	if len(behind) > 0 {
+		return fmt.Errorf("exporters did not catch up in time: %s: %w", strings.Join(behind, ", "), cmp.Or(ctx.Err(), context.DeadlineExceeded))
+	}
+	return nil
+}
+
+// Stats returns the stats of every exporter, in registration order.
+// This method is safe for concurrent use.
+func (r *exportRegistry) Stats() []ExporterStats {
+	r.mu.Lock()
+	sinks := slices.Clone(r.sinks)
+	r.mu.Unlock()
+	stats := make([]ExporterStats, 0, len(sinks))
+	for _, s := range sinks {
+		s.mu.Lock()
+		stat := s.stats
+		stat.Queued = len(s.queue)
+		s.mu.Unlock()
+		stats = append(stats, stat)
+	}
+	return stats
+}
+
+// Close flushes every exporter, see Flush, then stops queueing for them and
+// waits for them to handle what was queued meanwhile, up to timeout in all,
+// then closes them and stops the worker pool. Exporters still busy after the
+// timeout are left behind without being closed. Returns the errors of Close
+// and one naming the exporters left behind, if any. Nothing can be
+// registered afterwards.
+// This method is safe for concurrent use.
+func (r *exportRegistry) Close(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	// The exporters left behind are named below.
+	r.Flush(ctx)
+
+	r.mu.Lock()
+	sinks := r.sinks
+	r.sinks = nil
+	r.stopped = true
+	r.mu.Unlock()
+
+	deadline := ctx.Done()
+	var errs []error
+	var stuck []string
+	for _, s := range sinks {
+		idle := s.remove()
+		select {
+		case <-idle:
+		default:
+			select {
+			case <-idle:
+			case <-deadline:
+				stuck = append(stuck, s.name)
+				// The other exporters have had the same time to catch up.
+				deadline = closedChannel
+				continue
+			}
+		}
+		errs = append(errs, s.call(s.exporter.Close))
+	}
+	if len(stuck) > 0 {
+		errs = append(errs, errors.New("exporters did not catch up in time: "+strings.Join(stuck, ", ")))
+	}
+	r.mu.Lock()
+	r.wake.Broadcast()
+	r.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// closedChannel is always ready.
+var closedChannel = func() <-chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
+// publish queues what an event means for the exporters. It runs on the tick
+// goroutine, and only builds the tick's plant snapshot when exporters are
+// registered.
+func (r *exportRegistry) publish(e events.Event) {
+	r.mu.Lock()
+	sinks := slices.Clone(r.sinks)
+	r.mu.Unlock()
+	if len(sinks) == 0 {
+		return
+	}
+
+	var item exportItem
+	switch e.Type {
+	case events.SensorSample:
+		item.reading = &ExportedReading{Tick: e.Tick, SectionID: e.SectionID, Reading: e.Payload.(models.SensorReading)}
+	case events.Tick:
+		stats, _ := e.Payload.(Stats)
+		tick := &ExportedTick{Tick: e.Tick, Timestamp: e.Timestamp, Stats: stats}
+		for _, plant := range r.g.sim.GetAllPlants() {
+			tick.Plants = append(tick.Plants, *plant)
+		}
+		slices.SortFunc(tick.Plants, func(a, b models.Plant) int { return strings.Compare(a.ID, b.ID) })
+		tick.Span = trace.SpanContextFromContext(r.g.sim.TickContext())
+		item.tick = tick
+	default:
+		item.event = e
+	}
+	for _, s := range sinks {
+		if s.enqueue(item) {
+			r.schedule(s)
+		}
+	}
+}
+
+// schedule hands a sink to the worker pool.
+func (r *exportRegistry) schedule(s *sink) {
+	r.mu.Lock()
+	r.ready = append(r.ready, s)
+	r.wake.Signal()
+	r.mu.Unlock()
+}
+
+// work runs sinks that have queued items until the registry is closed.
+func (r *exportRegistry) work() {
+	for {
+		r.mu.Lock()
+		for len(r.ready) == 0 && !r.stopped {
+			r.wake.Wait()
+		}
+		if len(r.ready) == 0 {
+			r.mu.Unlock()
+			return
+		}
+		s := r.ready[0]
+		r.ready = r.ready[1:]
+		r.mu.Unlock()
+
+		if s.run() {
+			r.schedule(s)
+		} else {
+			r.mu.Lock()
+			r.caughtUp.Broadcast()
+			r.mu.Unlock()
+		}
+	}
+}
+
+// enqueue queues an item, dropping it when the queue is full, and reports
+// whether the sink must be scheduled.
+func (s *sink) enqueue(item exportItem) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.removing {
+		return false
+	}
+	if len(s.queue) >= s.queueSize {
+		s.stats.Dropped++
+		return false
+	}
+	s.queue = append(s.queue, item)
+	s.stats.HighWater = max(s.stats.HighWater, len(s.queue))
+	if s.scheduled {
+		return false
+	}
+	s.scheduled = true
+	return true
+}
+
+// run hands the exporter up to exportBatch queued items, flushing it once
+// the queue is empty after a tick, and reports whether items are left.
+func (s *sink) run() bool {
+	for range exportBatch {
+		s.mu.Lock()
+		if len(s.queue) == 0 {
+			s.mu.Unlock()
+			break
+		}
+		item := s.queue[0]
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+		s.handle(item)
+	}
+
+	s.mu.Lock()
+	empty := len(s.queue) == 0
+	s.mu.Unlock()
+	if !empty {
+		return true
+	}
+	if s.ticked {
+		s.flush()
+	}
+	return s.settle()
+}
+
+// flush flushes the exporter and times it. The caller must have the sink
+// scheduled.
+func (s *sink) flush() {
+	s.ticked = false
+	start := time.Now()
+	err := s.call(s.exporter.Flush)
+	s.mu.Lock()
+	s.dirty = false
+	s.stats.LastFlush = time.Since(start)
+	s.mu.Unlock()
+	if err == nil {
+		s.flushed()
+	}
+}
+
+// claim schedules the sink unless it is scheduled already, for the caller to
+// run it, and reports whether it did.
+func (s *sink) claim() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.scheduled {
+		return false
+	}
+	s.scheduled = true
+	return true
+}
+
+// settle ends a run of the sink: it reports whether items were queued
+// meanwhile, leaving the sink scheduled for them, and otherwise unschedules
+// it, closing idle if the sink is removing.
+func (s *sink) settle() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queue) > 0 {
+		return true
+	}
+	s.scheduled = false
+	if s.removing && s.idle != nil {
+		close(s.idle)
+		s.idle = nil
+	}
+	return false
+}
+
+func (s *sink) handle(item exportItem) {
+	switch {
+	case item.tick != nil:
+		s.call(func() error { return s.exporter.HandleTick(*item.tick) })
+		s.ticked = true
+	case item.reading != nil:
+		s.call(func() error { return s.exporter.HandleReading(*item.reading) })
+	default:
+		s.call(func() error { return s.exporter.HandleEvent(item.event) })
+	}
+	s.mu.Lock()
+	s.stats.Handled++
+	s.dirty = true
+	s.mu.Unlock()
+}
+
+// call calls the exporter, recovering from panics, and counts failures.
+func (s *sink) call(f func() error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("exporter %s panicked: %v", s.name, p)
+		}
+		if err != nil {
+			s.mu.Lock()
+			s.stats.Failed++
+			s.stats.LastError = err.Error()
+			s.mu.Unlock()
+		}
+	}()
+	return f()
+}
+
+// remove stops queueing and returns a channel closed once the sink is idle.
+func (s *sink) remove() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removing = true
+	idle := make(chan struct{})
+	if !s.scheduled {
+		close(idle)
+		return idle
+	}
+	s.idle = idle
+	return idle
+}
+
+// busy reports whether the sink has queued items or is being run.
+func (s *sink) busy() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.scheduled
+}
@@ -0,0 +1,285 @@
+package greenhouse
+
+import (
+	"context"
+	"errors"
+	"greenhouse-simulator/internal/events"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeExporter records what it handles. Until release is closed, it blocks
+// in HandleTick when block is set; it panics on readings when panics is set.
+type fakeExporter struct {
+	block    bool
+	panics   bool
+	release  chan struct{}
+	ticks    []int
+	readings int
+	flushes  int
+	closed   bool
+	mu       sync.Mutex
+}
+
+func newFakeExporter() *fakeExporter {
+	return &fakeExporter{release: make(chan struct{})}
+}
+
+func (f *fakeExporter) HandleTick(t ExportedTick) error {
+	if f.block {
+		<-f.release
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ticks = append(f.ticks, t.Tick)
+	return nil
+}
+
+func (f *fakeExporter) HandleReading(ExportedReading) error {
+	if f.panics {
+		panic("broken exporter")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.readings++
+	return nil
+}
+
+func (f *fakeExporter) HandleEvent(e events.Event) error {
+	if e.Type == events.WateringStarted {
+		return errors.New("cannot export waterings")
+	}
+	return nil
+}
+
+func (f *fakeExporter) Flush() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.flushes++
+	return nil
+}
+
+func (f *fakeExporter) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+func (f *fakeExporter) handledTicks() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]int(nil), f.ticks...)
+}
+
+func (f *fakeExporter) flushCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.flushes
+}
+
+func TestExportRegistry_BlockingExporterDoesNotDelayTicks(t *testing.T) {
+	g, _ := newTestGreenhouse(t)
+	blocking := newFakeExporter()
+	blocking.block = true
+	fine := newFakeExporter()
+	if err := g.Exporters().Register("blocking", blocking, 4); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := g.Exporters().Register("fine", fine, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stepped := make(chan time.Duration)
+	go func() {
+		start := time.Now()
+		for range 100 {
+			g.Simulator().Step()
+		}
+		stepped <- time.Since(start)
+	}()
+	select {
+	case elapsed := <-stepped:
+		// Ticks waiting for the exporter would never finish, but stay well
+		// clear of slow test machines.
+		if elapsed > time.Second {
+			t.Errorf("expected 100 ticks to run quickly, took %v", elapsed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ticks blocked on the exporter")
+	}
+
+	// The other exporter still gets every tick, in order.
+	deadline := time.After(2 * time.Second)
+	for len(fine.handledTicks()) < 100 {
+		select {
+		case <-deadline:
+			t.Fatalf("expected the other exporter to get every tick, got %d", len(fine.handledTicks()))
+		case <-time.After(time.Millisecond):
+		}
+	}
+	for i, tick := range fine.handledTicks() {
+		if tick != i {
+			t.Fatalf("expected the ticks in order, got %v", fine.handledTicks())
+		}
+	}
+
+	stats := g.Exporters().Stats()
+	if stats[0].Name != "blocking" || stats[0].Dropped == 0 || stats[0].Queued > 4 {
+		t.Errorf("expected the blocked exporter to queue up to 4 items and drop the rest, got %+v", stats[0])
+	}
+	if stats[1].Name != "fine" || stats[1].Dropped != 0 {
+		t.Errorf("expected nothing dropped for the other exporter, got %+v", stats[1])
+	}
+
+	// Closing gives up on the blocked exporter without closing it.
+	err := g.Exporters().Close(10 * time.Millisecond)
+	if err == nil || err.Error() != "exporters did not catch up in time: blocking" {
+		t.Errorf("expected the blocked exporter to be left behind, got %v", err)
+	}
+	if blocking.closed || !fine.closed {
+		t.Errorf("expected only the other exporter to be closed")
+	}
+	close(blocking.release)
+}
+
+func TestExportRegistry_FailuresAreIsolated(t *testing.T) {
+	g, _ := newTestGreenhouse(t)
+	broken := newFakeExporter()
+	broken.panics = true
+	fine := newFakeExporter()
+	for name, x := range map[string]*fakeExporter{"broken": broken, "fine": fine} {
+		if err := g.Exporters().Register(name, x, 0); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := g.Watering().WaterSection("section-A", 0.1, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for range 3 {
+		g.Simulator().Step()
+	}
+	g.Exporters().Drain()
+
+	stats := map[string]ExporterStats{}
+	for _, stat := range g.Exporters().Stats() {
+		stats[stat.Name] = stat
+	}
+	// One reading per tick panics, and the watering start fails.
+	if stat := stats["broken"]; stat.Failed != 4 || !strings.Contains(stat.LastError, "exporter broken panicked: broken exporter") {
+		t.Errorf("expected 3 panics and a failed event, got %+v", stat)
+	}
+	if stat := stats["fine"]; stat.Failed != 1 || stat.LastError != "cannot export waterings" {
+		t.Errorf("expected a failed event, got %+v", stat)
+	}
+	if ticks := broken.handledTicks(); !reflect.DeepEqual(ticks, []int{0, 1, 2}) {
+		t.Errorf("expected the panicking exporter to keep getting ticks, got %v", ticks)
+	}
+	// Flushes come once the exporter catches up, at most once per tick.
+	if fine.readings != 3 || fine.flushes < 1 || fine.flushes > 3 {
+		t.Errorf("expected 3 readings and 1 to 3 flushes, got %d readings and %d flushes", fine.readings, fine.flushes)
+	}
+}
+
+func TestExportRegistry_RegisterAndRemove(t *testing.T) {
+	g, _ := newTestGreenhouse(t)
+	x := newFakeExporter()
+	registry := g.Exporters()
+	if err := registry.Register("x", x, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		name     string
+		err      error
+		errorMsg string
+	}{
+		{"taken name", registry.Register("x", newFakeExporter(), 0), "exporter already registered: x"},
+		{"empty name", registry.Register("", newFakeExporter(), 0), "exporter name cannot be empty"},
+		{"negative queue size", registry.Register("y", newFakeExporter(), -1), "exporter queue size cannot be negative: y"},
+		{"unknown name", registry.Remove("y"), "no exporter registered with the provided name: y"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err == nil || tt.err.Error() != tt.errorMsg {
+				t.Errorf("expected error %q, got %v", tt.errorMsg, tt.err)
+			}
+		})
+	}
+
+	g.Simulator().Step()
+	if err := registry.Remove("x"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g.Simulator().Step()
+	if ticks := x.handledTicks(); !reflect.DeepEqual(ticks, []int{0}) || !x.closed {
+		t.Errorf("expected the tick before the removal and a closed exporter, got %v", ticks)
+	}
+	if err := registry.Close(time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := registry.Register("x", x, 0); err == nil || err.Error() != "exporters are closed" {
+		t.Errorf("expected registering after Close to fail, got %v", err)
+	}
+}
+
+func TestExportRegistry_QueueDiagnosticsAndFlush(t *testing.T) {
+	g, _ := newTestGreenhouse(t)
+	blocking := newFakeExporter()
+	blocking.block = true
+	if err := g.Exporters().Register("blocking", blocking, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for range 5 {
+		g.Simulator().Step()
+	}
+
+	stat := g.Exporters().Stats()[0]
+	if stat.QueueSize != 2 || stat.HighWater != 2 || stat.Queued != 2 || stat.Dropped == 0 {
+		t.Errorf("expected a full queue of 2 with drops, got %+v", stat)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := g.Exporters().Flush(ctx); !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "blocking") {
+		t.Errorf("expected the blocked exporter to miss the deadline, got %v", err)
+	}
+
+	close(blocking.release)
+	flushes := blocking.flushCount()
+	if err := g.Exporters().Flush(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stat = g.Exporters().Stats()[0]
+	if stat.Queued != 0 || stat.HighWater != 2 || stat.Handled+stat.Dropped < 5 {
+		t.Errorf("expected an empty queue that was 2 deep, got %+v", stat)
+	}
+	if blocking.flushCount() <= flushes {
+		t.Error("expected the exporter to be flushed once caught up")
+	}
+}
+
+func TestExportRegistry_CloseFlushes(t *testing.T) {
+	g, _ := newTestGreenhouse(t)
+	x := newFakeExporter()
+	if err := g.Exporters().Register("x", x, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g.Simulator().Step()
+	g.Exporters().Drain()
+	flushes := x.flushCount()
+
+	// An event after the last tick is only flushed on the way out.
+	g.Bus().Publish(events.Event{Type: events.ConfigReloaded, Tick: 1, Timestamp: time.Now()})
+	g.Exporters().Drain()
+	if x.flushCount() != flushes {
+		t.Fatalf("expected no flush without a tick, got %d", x.flushCount()-flushes)
+	}
+	if err := g.Exporters().Close(time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if x.flushCount() != flushes+1 || !x.closed {
+		t.Errorf("expected Close to flush then close the exporter, got %d flushes", x.flushCount()-flushes)
+	}
+}
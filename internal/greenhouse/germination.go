@@ -0,0 +1,56 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/rng"
+	"time"
+)
+
+// germination ends the germination of the seeds that have germinated for the
+// ticks of their type: each sprouts with its germination chance and dies
+// otherwise, publishing a Germinated or GerminationFailed event. The draws
+// of each tick are split off by tick number, so a run resumed from an export
+// draws what the original run would have.
+type germination struct {
+	g      *greenhouse
+	random rng.Source
+}
+
+func newGermination(g *greenhouse, random rng.Source) *germination {
+	return &germination{g: g, random: random}
+}
+
+// TickPhase names the germination in tick traces.
+func (gm *germination) TickPhase() string { return "germination" }
+
+func (gm *germination) OnTick(tick int) {
+	plants := gm.g.sim.GetAllPlants()
+	var published []events.Event
+	gm.g.sim.UpdatePlants(func() {
+		var random rng.Source
+		for _, plant := range plants {
+			if !plant.Germinated() {
+				continue
+			}
+			if random == nil {
+				random = gm.random.SplitN(tick)
+			}
+			germinated := *plant.Germination
+			eventType := events.Germinated
+			if !plant.Sprout(random.Float64()) {
+				eventType = events.GerminationFailed
+			}
+			published = append(published, events.Event{
+				Type:      eventType,
+				Tick:      tick,
+				Timestamp: time.Now(),
+				SectionID: plant.SectionID,
+				PlantID:   plant.ID,
+				Payload:   germinated,
+			})
+		}
+	})
+	for _, e := range published {
+		gm.g.bus.Publish(e)
+	}
+}
@@ -0,0 +1,129 @@
+package greenhouse
+
+import (
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// germinationConfig has 100 bean seeds germinating for 5 ticks in soil at the
+// given saturation, failing with the given chance in perfect conditions.
+func germinationConfig(saturation, failure float64) *config.GreenhouseConfig {
+	cfg := &config.GreenhouseConfig{
+		TickInterval: config.Duration(time.Second),
+		PlantTypes: []config.PlantTypeConfig{
+			{Name: "Bean", OptimalSaturation: 0.6, MinSaturation: 0.2, MaxSaturation: 0.9, BaseGrowthRate: 0.01, SaturationDepletion: 0.02, HealthEnhancementRate: 0.01,
+				GerminationTicks: 5, GerminationMinSaturation: 0.5, GerminationMaxSaturation: 0.8, GerminationFailure: failure},
+		},
+	}
+	for i := range 100 {
+		cfg.Plants = append(cfg.Plants, config.PlantConfig{ID: fmt.Sprintf("bean-%02d", i), Type: "Bean", SectionID: "section-A", InitialSaturation: saturation})
+	}
+	return cfg
+}
+
+// germinations runs a greenhouse built from cfg through its seeds'
+// germination and returns the IDs of the seeds that sprouted and failed.
+func germinations(t *testing.T, cfg *config.GreenhouseConfig) (sprouted, failed []string) {
+	t.Helper()
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	g.Bus().Subscribe(func(e events.Event) {
+		if e.Type != events.Germinated && e.Type != events.GerminationFailed {
+			return
+		}
+		if e.Tick != 4 {
+			t.Errorf("expected the seeds to end their germination on tick 4, got %s on tick %d", e.Type, e.Tick)
+		}
+		if e.Type == events.Germinated {
+			sprouted = append(sprouted, e.PlantID)
+		} else {
+			failed = append(failed, e.PlantID)
+		}
+	})
+	for range 6 {
+		g.Simulator().Step()
+	}
+	return sprouted, failed
+}
+
+func TestGermination_PerfectConditions(t *testing.T) {
+	sprouted, failed := germinations(t, germinationConfig(0.6, 0.02))
+	if len(sprouted) < 95 || len(sprouted)+len(failed) != 100 {
+		t.Errorf("expected nearly every seed to sprout, got %d sprouted and %d failed", len(sprouted), len(failed))
+	}
+	again, _ := germinations(t, germinationConfig(0.6, 0.02))
+	if !reflect.DeepEqual(again, sprouted) {
+		t.Error("expected the same seed to sprout the same plants")
+	}
+}
+
+func TestGermination_DrySoil(t *testing.T) {
+	cfg := germinationConfig(0, 0)
+	sprouted, failed := germinations(t, cfg)
+	if len(sprouted) != 0 || len(failed) != 100 {
+		t.Errorf("expected every seed to fail in dry soil, got %d sprouted and %d failed", len(sprouted), len(failed))
+	}
+}
+
+func TestGermination_SproutedPlantsGrow(t *testing.T) {
+	g, err := New(germinationConfig(0.6, 0))
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	for range 5 {
+		g.Simulator().Step()
+	}
+	plant := g.Simulator().GetAllPlants()[0]
+	if plant.Germinating() || plant.GrowthStage != 0 || plant.SoilSaturation != 0.6 {
+		t.Fatalf("expected a sprouted seed that has not grown yet, got %+v", plant)
+	}
+	g.Simulator().Step()
+	if plant.GrowthStage == 0 || plant.SoilSaturation == 0.6 {
+		t.Errorf("expected the sprouted plant to grow and draw water, got %+v", plant)
+	}
+}
+
+func TestGermination_ExportResumes(t *testing.T) {
+	g, err := New(germinationConfig(0.6, 0))
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	for range 2 {
+		g.Simulator().Step()
+	}
+	exported, err := g.ExportScenario(config.ExportOptions{ExactResume: true})
+	if err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	resumed, err := New(exported)
+	if err != nil {
+		t.Fatalf("failed to load the export: %v", err)
+	}
+	expected := models.Germination{Ticks: 2, Favorable: 2}
+	if got := resumed.Simulator().GetAllPlants()[0].Germination; got == nil || *got != expected {
+		t.Errorf("expected the resumed seed to carry %+v, got %+v", expected, got)
+	}
+}
+
+func TestGermination_ReloadRefusesGerminatingTypes(t *testing.T) {
+	cfg := germinationConfig(0.6, 0)
+	cfg.PlantTypes[0].GerminationTicks = 0
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	if plant := g.Simulator().GetAllPlants()[0]; plant.Germinating() {
+		t.Fatalf("expected a type without germination to start growing at once, got %+v", plant.Germination)
+	}
+	reloaded := germinationConfig(0.6, 0)
+	if _, err := g.ReloadConfig(reloaded); err == nil || err.Error() != "germinating plant types cannot be added while the simulation runs" {
+		t.Errorf("expected germination to be refused on a reload, got %v", err)
+	}
+}
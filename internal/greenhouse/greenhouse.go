@@ -0,0 +1,692 @@
+package greenhouse
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/environment"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"greenhouse-simulator/internal/rng"
+	"greenhouse-simulator/internal/sensors"
+	"greenhouse-simulator/internal/watering"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrAlreadyPaused is returned when pausing a paused simulation.
+	ErrAlreadyPaused = errors.New("simulation is already paused")
+	// ErrNotPaused is returned when resuming a simulation that is not paused.
+	ErrNotPaused = errors.New("simulation is not paused")
+	// ErrNoDiseaseModel is returned when infecting or treating plants in a
+	// greenhouse configured without diseases.
+	ErrNoDiseaseModel = errors.New("no disease model configured")
+	// ErrZoneNotFound is returned for a zone ID the greenhouse does not know.
+	ErrZoneNotFound = errors.New("no zone found for the provided ID")
+	// ErrZoneExists is returned when adding a zone whose ID is taken.
+	ErrZoneExists = errors.New("zone with ID already exists")
+	// ErrRotationNotFound is returned for a section without a rotation plan.
+	ErrRotationNotFound = errors.New("no rotation found for the provided section")
+	// ErrSectionNotFound is returned for a section without plants.
+	ErrSectionNotFound = errors.New("no plants found in the provided section")
+)
+
+// Greenhouse is a running simulation built from a GreenhouseConfig: the
+// simulator with its plants, the sensors, the irrigation system and the
+// environment, all publishing to one event bus.
+type Greenhouse interface {
+	// Simulator returns the simulation engine.
+	Simulator() engine.Simulator
+	// Sensors returns the sensor manager.
+	Sensors() sensors.SensorManager
+	// Watering returns the irrigation controller.
+	Watering() watering.Controller
+	// Humidity returns the section humidity tracker.
+	Humidity() environment.Humidity
+	// Lights returns the grow lights of the sections.
+	Lights() environment.Lights
+	// SetLights switches the grow lights of a section on or off.
+	SetLights(sectionID string, on bool, intensity float64) error
+	// HVAC returns the heater, vent and thermostat.
+	HVAC() environment.HVAC
+	// SetActuator switches the heater or the vent to auto, on or off.
+	SetActuator(actuator environment.Actuator, mode environment.ActuatorMode) error
+	// Climate returns the greenhouse-wide climate model.
+	Climate() environment.Climate
+	// Conditions returns the current air conditions, extreme weather included.
+	Conditions() environment.Conditions
+	// GetSeason returns the current season, empty without seasons.
+	GetSeason() environment.Season
+	// GetDayOfYear returns the current day of the year, 0 without seasons.
+	GetDayOfYear() int
+	// SectionConditions returns the current air conditions in a section.
+	SectionConditions(sectionID string) environment.Conditions
+	// SectionClimateOffset returns the microclimate of a section.
+	SectionClimateOffset(sectionID string) environment.ClimateOffset
+	// SetSectionClimateOffset sets the microclimate of a section.
+	SetSectionClimateOffset(sectionID string, offset environment.ClimateOffset) error
+	// TriggerWeatherEvent starts a frost or a heat wave.
+	TriggerWeatherEvent(extreme environment.Extreme, ticks int) error
+	// SetCO2Injection sets the CO2 the injector adds per tick, in ppm.
+	SetCO2Injection(rate float64) error
+	// WaterTemperature returns the current temperature of the irrigation water.
+	WaterTemperature() WaterTemperature
+	// HeatWater holds the irrigation water at a temperature.
+	HeatWater(temperature float64) error
+	// StopHeatingWater lets the irrigation water drift towards the air again.
+	StopHeatingWater()
+	// InfectPlant infects a plant with the disease.
+	InfectPlant(plantID string) error
+	// TreatDisease treats the diseased plants of a section.
+	TreatDisease(sectionID string) error
+	// Bus returns the event bus every component publishes to.
+	Bus() events.Bus
+	// Exporters returns the registry of the output sinks.
+	Exporters() ExportRegistry
+	// Config returns the config currently applied.
+	Config() *config.GreenhouseConfig
+	// ReloadConfig applies the safe differences between cfg and the running state.
+	ReloadConfig(cfg *config.GreenhouseConfig) (ReloadSummary, error)
+	// WatchConfig reloads the config file whenever it changes.
+	WatchConfig(path string, interval time.Duration, stop <-chan struct{}, onError func(error))
+	// ExportScenario captures the running greenhouse as a loadable config.
+	ExportScenario(opts config.ExportOptions) (*config.GreenhouseConfig, error)
+	// BuildWorldState describes the whole greenhouse between two ticks.
+	BuildWorldState() (*WorldState, error)
+	// AddPlant builds a plant from its config and adds it to the simulation.
+	AddPlant(plant config.PlantConfig) (*models.Plant, error)
+	// RemovePlant removes a plant from the simulation.
+	RemovePlant(plantID string) error
+	// PlantForecast projects a plant left unwatered under the current conditions.
+	PlantForecast(plantID string) (*models.PlantForecast, error)
+	// GetPlantJournal returns the significant changes of a plant, oldest first.
+	GetPlantJournal(plantID string) ([]JournalEntry, error)
+	// ThinSection removes the weakest plants of a section beyond keepN.
+	ThinSection(sectionID string, keepN int) ([]string, error)
+	// AddZone groups sections into a new zone.
+	AddZone(zone config.ZoneConfig) (*models.Zone, error)
+	// Zones returns the zones, ordered by ID.
+	Zones() []*models.Zone
+	// ZoneStats summarises the sections of a zone.
+	ZoneStats(zoneID string) (ZoneStats, error)
+	// SectionStats summarises the plants of a section.
+	SectionStats(sectionID string) (SectionStats, error)
+	// Rotations returns the rotation plans with their progress, ordered by section.
+	Rotations() []RotationStatus
+	// SetRotation sets the rotation plan of a section.
+	SetRotation(plan config.RotationConfig) (RotationStatus, error)
+	// RemoveRotation drops the rotation plan of a section.
+	RemoveRotation(sectionID string) error
+	// PauseRotation pauses the rotation plan of a section.
+	PauseRotation(sectionID string) error
+	// ResumeRotation resumes a paused rotation plan.
+	ResumeRotation(sectionID string) error
+	// Stats returns a summary of the current plants and water use.
+	Stats() Stats
+	// ActiveAlerts returns the alerts that fired and are not resolved yet.
+	ActiveAlerts() []Alert
+	// Costs returns the cost ledger of the run so far.
+	Costs() CostLedger
+	// RestoreCosts replaces the cost ledger, as when resuming a saved run.
+	RestoreCosts(ledger CostLedger)
+	// Pause pauses the running simulation.
+	Pause() error
+	// Resume resumes a simulation paused with Pause.
+	Resume() error
+	// Paused reports whether the simulation is paused.
+	Paused() bool
+	// Reset takes the greenhouse back to tick 0 for another run.
+	Reset(keepConfig bool) error
+	// Touch records that a consumer used the simulation.
+	Touch()
+}
+
+type greenhouse struct {
+	sim       engine.Simulator
+	sensors   sensors.SensorManager
+	watering  watering.Controller
+	humidity  environment.Humidity
+	lights    environment.Lights
+	hvac      environment.HVAC
+	weather   *weather
+	costs     *costs
+	alerts    *alerts
+	zones     *zones
+	rotations *rotations
+	disease   *diseases                   // nil without a disease model
+	salinity  environment.Salinity        // nil without salinity settings
+	soilTemp  environment.SoilTemperature // nil without soil temperature settings
+	waterTemp *waterTemperature           // the irrigation water, even without settings
+	journal   *journal                    // nil without plant journals
+	// idle holds nil without an idle pause; exporters touch it while the
+	// greenhouse is reset.
+	idle   atomic.Pointer[idleWatch]
+	bus    events.Bus
+	export *exportRegistry
+	config *config.GreenhouseConfig
+	// plants added or removed at runtime, which reloads must not undo
+	runtimeAdded   map[string]bool
+	runtimeRemoved map[string]bool
+	// plants found dead so far, in total and by cause, see Stats
+	died   int
+	diedBy map[models.DeathCause]int
+	mu     sync.Mutex
+	// pauseMu is separate from mu because pausing waits for the current tick,
+	// whose listeners may need mu.
+	pauseMu sync.Mutex
+}
+
+// New validates cfg and builds a greenhouse from it. The simulator is not
+// started; call Simulator().Start() or Step() to run it. The simulator checks
+// its invariants as cfg.Invariants says. With cfg.WarmupTicks, the plants
+// are run that many ticks first, with the watering schedules but without the
+// timeline, and start the run at tick 0 as the warm-up left them. Only the
+// plants are carried over: the sensors, the watering history, the tank, the
+// costs, the environment and the random streams start afresh, and no event
+// of the warm-up is published.
+func New(cfg *config.GreenhouseConfig) (Greenhouse, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	var g *greenhouse
+	var opts []engine.Option
+	switch cfg.Invariants {
+	case config.InvariantsPanic:
+		opts = append(opts, engine.WithInvariantChecks(nil))
+	case config.InvariantsEvent:
+		opts = append(opts, engine.WithInvariantChecks(func(violations []engine.InvariantViolation) {
+			g.publishViolations(violations)
+		}))
+	}
+	sim := engine.NewSimulator(time.Duration(cfg.TickInterval), opts...)
+	var plants []*models.Plant
+	var err error
+	if cfg.WarmupTicks > 0 {
+		plants, err = warmUp(cfg)
+	} else {
+		plants, err = cfg.BuildPlants()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := sim.AddPlants(plants); err != nil {
+		return nil, err
+	}
+	g, err = newGreenhouse(cfg, sim)
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// publishViolations publishes an InvariantViolated event for each violation
+// the invariant checks found on a tick.
+func (g *greenhouse) publishViolations(violations []engine.InvariantViolation) {
+	for _, violation := range violations {
+		g.bus.Publish(events.Event{
+			Type:      events.InvariantViolated,
+			Tick:      violation.Tick,
+			Timestamp: time.Now(),
+			PlantID:   violation.PlantID,
+			Payload:   violation,
+		})
+	}
+}
+
+// newGreenhouse builds the rest of a greenhouse from cfg around sim, which
+// already holds the plants.
+func newGreenhouse(cfg *config.GreenhouseConfig, sim engine.Simulator) (*greenhouse, error) {
+	g := &greenhouse{sim: sim, bus: events.NewBus(), config: cfg}
+	workers := 0
+	if cfg.Export != nil {
+		workers = cfg.Export.Workers
+	}
+	g.export = newExportRegistry(g, workers)
+	if err := g.build(true); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// build sets up everything of the greenhouse but the simulator, the bus and
+// the exporters from its config, and registers the tick and state listeners
+// with the simulator, which holds the plants and has no listeners yet. The
+// sensors and watering schedules of the config are added only with
+// withSensors.
+func (g *greenhouse) build(withSensors bool) error {
+	cfg, sim, bus := g.config, g.sim, g.bus
+	tickInterval := time.Duration(cfg.TickInterval)
+	var tank *watering.WaterSupply
+	if cfg.Tank != nil {
+		var err error
+		tank, err = watering.NewWaterSupply(cfg.Tank.SupplyConfig())
+		if err != nil {
+			return err
+		}
+	}
+	humidity, err := environment.NewHumidity(cfg.Environment.AmbientHumidity, cfg.Environment.HumidityDecay)
+	if err != nil {
+		return err
+	}
+	co2, err := environment.NewCO2(cfg.CO2())
+	if err != nil {
+		return err
+	}
+	lights, err := environment.NewLights(cfg.DayCycle(), cfg.LightsConfigs())
+	if err != nil {
+		return err
+	}
+	hvac, err := environment.NewHVAC(cfg.HVACConfig())
+	if err != nil {
+		return err
+	}
+	waterTemp, err := newWaterTemperature(g, cfg.WaterTemperatureConfig())
+	if err != nil {
+		return err
+	}
+	zones := newZones()
+	g.waterTemp = waterTemp
+	g.humidity = humidity
+	g.lights = lights
+	g.hvac = hvac
+	g.runtimeAdded = map[string]bool{}
+	g.runtimeRemoved = map[string]bool{}
+	g.died, g.diedBy = 0, nil
+	g.zones = zones
+	wateringConfig := cfg.WateringConfig()
+	wateringConfig.TickInterval = tickInterval
+	wateringConfig.Supply = tank
+	wateringConfig.DayCycle = cfg.DayCycle()
+	wateringConfig.Humidity = humidity
+	wateringConfig.Zones = zones
+	wateringConfig.PlantEvents = cfg.Journal != nil
+	wateringConfig.DedupWindow = cfg.WateringDedupWindow
+	wateringConfig.ThermalShock = waterTemp.shock
+	g.watering = watering.NewController(sim, bus, wateringConfig)
+
+	if err := sim.SetPruneEffect(cfg.PruneEffect()); err != nil {
+		return err
+	}
+	if err := sim.SetOverrunPolicy(cfg.TickOverrunPolicy()); err != nil {
+		return err
+	}
+	g.sensors = sensors.NewSensorManager(sim, g, cfg.Random().Split(rng.Sensors))
+	if err := g.sensors.SetDeadSectionPolicy(cfg.DeadSectionPolicy()); err != nil {
+		return err
+	}
+	if err := g.sensors.SetHistoryLookup(cfg.SensorHistoryLookup()); err != nil {
+		return err
+	}
+	g.sensors.SetStrictFilters(cfg.StrictSensorFilters)
+	g.sensors.SetSlowReadThreshold(time.Duration(cfg.SlowSensorRead), nil)
+	g.weather = newWeather(g, co2)
+	g.costs = newCosts(g)
+	g.disease = nil
+	if cfg.Disease != nil {
+		g.disease = newDiseases(g, cfg.DiseaseConfig(), cfg.Random().Split(rng.Pests))
+	}
+	g.salinity = nil
+	if cfg.Salinity != nil {
+		g.salinity, err = environment.NewSalinity(cfg.SalinityConfig(), cfg.SalinityLevels())
+		if err != nil {
+			return err
+		}
+	}
+	g.soilTemp = nil
+	if cfg.SoilTemperature != nil {
+		g.soilTemp, err = environment.NewSoilTemperature(cfg.SoilTemperatureConfig())
+		if err != nil {
+			return err
+		}
+	}
+	if g.journal != nil {
+		g.journal.unsubscribe()
+		g.journal = nil
+	}
+	if entries := cfg.JournalEntries(); entries > 0 {
+		g.journal = newJournal(bus, entries)
+	}
+
+	if withSensors {
+		for _, sensor := range cfg.Sensors {
+			added, err := sensor.Sensor()
+			if err != nil {
+				return err
+			}
+			if err := g.sensors.AddSensor(added); err != nil {
+				return err
+			}
+		}
+	}
+	// The zones go before the schedules, which may select them.
+	for _, zone := range cfg.Zones {
+		if _, err := g.AddZone(zone); err != nil {
+			return err
+		}
+	}
+	if withSensors {
+		for _, schedule := range cfg.WateringSchedules() {
+			if err := g.watering.AddSchedule(schedule); err != nil {
+				return err
+			}
+		}
+	}
+	// The timeline runs first so that actions due on a tick, such as a
+	// manual watering, take effect on that tick, and chaos mode strikes
+	// right after it, like one more action. The rotations harvest and plant
+	// next, so that a crop grows from the tick it is planted on. Seeds done
+	// germinating sprout or fail, then the grow lights and the weather so
+	// that the sensors read the conditions of the tick, the plants shade
+	// each other for the next one, and the thermostat comes right after
+	// them. Diseases spread at the humidity of the tick, the irrigation
+	// water takes the temperature of the tick before it is applied, and the
+	// soil is salted and warmed once the water of the tick is applied. The
+	// cost ledger charges the tick once everything has been used, and the
+	// alert rules see the tick before the plant watch and the monitor
+	// report it.
+	if len(cfg.Timeline) > 0 {
+		sim.AddTickListener(newTimeline(g, cfg))
+	}
+	if cfg.ChaosEnabled() {
+		sim.AddTickListener(newChaos(g, *cfg.Chaos, cfg.Random().Split(rng.Chaos)))
+	}
+	g.rotations = newRotations(g, cfg.Rotations)
+	sim.AddTickListener(g.rotations)
+	if cfg.Germinates() {
+		sim.AddTickListener(newGermination(g, cfg.Random().Split(rng.Germination)))
+	}
+	sim.AddTickListener(lights)
+	sim.AddTickListener(g.weather)
+	if cfg.LightCompetition != nil {
+		sim.AddTickListener(&lightCompetition{g: g, model: cfg.LightCompetitionConfig()})
+	}
+	sim.AddTickListener(newThermostat(g, cfg.HVACConfig()))
+	sim.AddTickListener(humidity)
+	if g.disease != nil {
+		sim.AddTickListener(g.disease)
+	}
+	sim.AddTickListener(waterTemp)
+	sim.AddTickListener(g.watering)
+	if g.salinity != nil {
+		sim.AddTickListener(newSoilSalinity(g, g.salinity, cfg.SalinityConfig()))
+	}
+	if g.soilTemp != nil || cfg.Germinates() {
+		sim.AddTickListener(newSoilTemperature(g, g.soilTemp))
+	}
+	sim.AddTickListener(g.costs)
+	g.alerts = newAlerts(g)
+	sim.AddTickListener(g.alerts)
+	if g.journal != nil {
+		sim.AddTickListener(newPlantWatch(g))
+	}
+	sim.AddTickListener(newMonitor(g))
+	// The idle watch goes last, so that a tick it pauses on is reported.
+	g.idle.Store(nil)
+	if cfg.IdlePauseTicks > 0 {
+		idle := newIdleWatch(g, cfg.IdlePauseTicks)
+		g.idle.Store(idle)
+		sim.AddTickListener(idle)
+	}
+	sim.AddStateListener(g)
+	sim.SetHookEnvironment(humidity)
+	return nil
+}
+
+// Reset takes the greenhouse back to where New left it, for another run of
+// the same config without building it again: the simulator is reset, see
+// engine.Simulator.Reset, and the sensors, irrigation system, environment,
+// costs, alerts, journals and random streams start afresh from the current
+// config, so that a run after Reset is identical to the run of a new
+// greenhouse. The bus keeps its subscribers and the exporters stay
+// registered. With keepConfig the sensors and watering schedules of the
+// config are set up again; without it the greenhouse starts without any,
+// for the caller to add its own. The sensors, schedules and zones added at
+// runtime are dropped either way; the plants are those the first tick found.
+// Returns an error if the simulation is running or paused
+// (engine.ErrResetWhileRunning), or is a replay (ErrReplay).
+// Reset must not be called concurrently with other methods.
+func (g *greenhouse) Reset(keepConfig bool) error {
+	if err := g.sim.Reset(false); err != nil {
+		return err
+	}
+	return g.build(keepConfig)
+}
+
+func (g *greenhouse) Simulator() engine.Simulator    { return g.sim }
+func (g *greenhouse) Sensors() sensors.SensorManager { return g.sensors }
+func (g *greenhouse) Watering() watering.Controller  { return g.watering }
+func (g *greenhouse) Humidity() environment.Humidity { return g.humidity }
+func (g *greenhouse) Lights() environment.Lights     { return g.lights }
+func (g *greenhouse) HVAC() environment.HVAC         { return g.hvac }
+func (g *greenhouse) Bus() events.Bus                { return g.bus }
+func (g *greenhouse) Exporters() ExportRegistry      { return g.export }
+
+// Climate returns the climate model of the current config.
+// This method is safe for concurrent use.
+func (g *greenhouse) Climate() environment.Climate {
+	return g.Config().Climate()
+}
+
+// AddPlant builds a plant from its config, resolving its type among the
+// plant types of the current config and the presets, in the soil the current
+// config gives its section, and adds it to the simulation. Config reloads
+// keep the plant even though the config file does not list it. A PlantAdded
+// event is published. Returns an error if the plant config is invalid or the
+// plant ID is taken (engine.ErrPlantExists).
+// This method is safe for concurrent use.
+func (g *greenhouse) AddPlant(plant config.PlantConfig) (*models.Plant, error) {
+	current := g.Config()
+	cfg := &config.GreenhouseConfig{PlantTypes: current.PlantTypes, Sections: current.Sections, Plants: []config.PlantConfig{plant}}
+	plants, err := cfg.BuildPlants()
+	if err != nil {
+		return nil, err
+	}
+	if err := g.sim.AddPlant(plants[0]); err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	g.runtimeAdded[plant.ID] = true
+	delete(g.runtimeRemoved, plant.ID)
+	g.mu.Unlock()
+	g.publishPlantEvent(events.PlantAdded, plants[0])
+	return plants[0], nil
+}
+
+// RemovePlant removes a plant from the simulation. Config reloads do not add
+// it back. A PlantRemoved event is published. Returns an error wrapping
+// engine.ErrPlantNotFound if there is no such plant.
+// This method is safe for concurrent use.
+func (g *greenhouse) RemovePlant(plantID string) error {
+	var removed *models.Plant
+	for _, plant := range g.sim.GetAllPlants() {
+		if plant.ID == plantID {
+			removed = plant
+		}
+	}
+	if err := g.sim.RemovePlant(plantID); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	g.runtimeRemoved[plantID] = true
+	delete(g.runtimeAdded, plantID)
+	g.mu.Unlock()
+	g.publishPlantEvent(events.PlantRemoved, removed)
+	return nil
+}
+
+// ThinSection removes the weakest plants of a section beyond the keepN
+// healthiest and returns their IDs, see engine.Simulator.ThinSection. Config
+// reloads do not add them back. A PlantRemoved event is published for each.
+// Returns an error if keepN is negative.
+// This method is safe for concurrent use.
+func (g *greenhouse) ThinSection(sectionID string, keepN int) ([]string, error) {
+	plants := map[string]*models.Plant{}
+	for _, plant := range g.sim.GetPlantsBySectionID(sectionID) {
+		plants[plant.ID] = plant
+	}
+	removed, err := g.sim.ThinSection(sectionID, keepN)
+	if err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	for _, id := range removed {
+		g.runtimeRemoved[id] = true
+		delete(g.runtimeAdded, id)
+	}
+	g.mu.Unlock()
+	for _, id := range removed {
+		g.publishPlantEvent(events.PlantRemoved, plants[id])
+	}
+	return removed, nil
+}
+
+func (g *greenhouse) publishPlantEvent(eventType events.Type, plant *models.Plant) {
+	g.bus.Publish(events.Event{
+		Type:      eventType,
+		Tick:      g.sim.GetCurrentTick(),
+		Timestamp: time.Now(),
+		SectionID: plant.SectionID,
+		PlantID:   plant.ID,
+	})
+}
+
+// Pause pauses the simulation, waiting for its loop to take the request.
+// A simulation the idle watch paused stays paused until Resume, see Touch.
+// Returns ErrAlreadyPaused if the simulation is paused already, and
+// engine.ErrNotStarted or engine.ErrAlreadyStopped if it is not running.
+// This method is safe for concurrent use.
+func (g *greenhouse) Pause() error {
+	g.pauseMu.Lock()
+	defer g.pauseMu.Unlock()
+	idlePaused := g.idle.Load().takeOver()
+	if g.sim.IsPaused() {
+		if idlePaused {
+			return nil
+		}
+		return ErrAlreadyPaused
+	}
+	return g.sim.Pause()
+}
+
+// Resume resumes a paused simulation. Returns ErrNotPaused if it is not
+// paused.
+// This method is safe for concurrent use.
+func (g *greenhouse) Resume() error {
+	g.pauseMu.Lock()
+	defer g.pauseMu.Unlock()
+	if g.sim.State() != engine.Paused {
+		return ErrNotPaused
+	}
+	g.idle.Load().takeOver()
+	return g.sim.Resume()
+}
+
+// Paused reports whether the simulation is paused.
+// This method is safe for concurrent use.
+func (g *greenhouse) Paused() bool {
+	return g.sim.IsPaused()
+}
+
+// OnStateChange publishes a SimulatorStateChanged event for every state
+// change of the simulator, with the engine.StateChange.
+func (g *greenhouse) OnStateChange(change engine.StateChange) {
+	g.bus.Publish(events.Event{
+		Type:      events.SimulatorStateChanged,
+		Tick:      g.sim.GetCurrentTick(),
+		Timestamp: time.Now(),
+		Payload:   change,
+	})
+}
+
+// withDimensions sets the dimensions of the sections to those of current,
+// adding the sections missing from sections and keeping them ordered by ID.
+func withDimensions(sections, current []config.SectionConfig) []config.SectionConfig {
+	for _, section := range current {
+		if section.Dimensions() == (models.Dimensions{}) {
+			continue
+		}
+		i := slices.IndexFunc(sections, func(s config.SectionConfig) bool { return s.ID == section.ID })
+		if i < 0 {
+			sections = append(sections, config.SectionConfig{ID: section.ID})
+			i = len(sections) - 1
+		}
+		sections[i].Width, sections[i].Depth = section.Width, section.Depth
+	}
+	slices.SortFunc(sections, func(a, b config.SectionConfig) int { return strings.Compare(a.ID, b.ID) })
+	return sections
+}
+
+// Config returns the config currently applied.
+// This method is safe for concurrent use.
+func (g *greenhouse) Config() *config.GreenhouseConfig {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.config
+}
+
+// ExportScenario captures the running greenhouse as a loadable config, see
+// config.ExportScenario. The seed, overrun and dead section policies, sensor
+// history lookup, watering dedup window, environment, disease, pruning,
+// salinity, soil and water temperature, light competition, chaos, dead
+// plant, journal, watering and tank settings are carried over from the
+// current config; with ExactResume the tank and the soil salinity of the
+// sections start at their current levels, and the water at its current
+// temperature. The microclimates are the live ones, the rotation plans are
+// the crops of the live plans left to plant, their ticks counted from the
+// current tick, and the sections keep their dimensions.
+// This method is safe for concurrent use.
+func (g *greenhouse) ExportScenario(opts config.ExportOptions) (*config.GreenhouseConfig, error) {
+	state := g.watering.Snapshot()
+	cfg, err := config.ExportScenario(g.sim, g.sensors, state.Schedules, opts)
+	if err != nil {
+		return nil, err
+	}
+	current := g.Config()
+	cfg.Seed = current.Seed
+	cfg.OverrunPolicy = current.OverrunPolicy
+	cfg.DeadSections = current.DeadSections
+	cfg.HistoryLookup = current.HistoryLookup
+	cfg.WateringDedupWindow = current.WateringDedupWindow
+	cfg.Watering = current.Watering
+	cfg.Environment = current.Environment
+	cfg.Disease = current.Disease
+	cfg.Pruning = current.Pruning
+	cfg.Salinity = current.Salinity
+	cfg.SoilTemperature = current.SoilTemperature
+	cfg.WaterTemperature = current.WaterTemperature
+	if opts.ExactResume && cfg.WaterTemperature != nil {
+		water, temperature := *cfg.WaterTemperature, g.WaterTemperature().Temperature
+		water.Temperature = &temperature
+		cfg.WaterTemperature = &water
+	}
+	cfg.LightCompetition = current.LightCompetition
+	cfg.Chaos = current.Chaos
+	cfg.DeadPlants = current.DeadPlants
+	cfg.Journal = current.Journal
+	cfg.Microclimates = g.microclimates()
+	cfg.Rotations = g.rotations.remaining(g.sim.GetCurrentTick())
+	for _, zone := range g.Zones() {
+		cfg.Zones = append(cfg.Zones, config.ZoneConfig{ID: zone.ID, Name: zone.Name, Sections: zone.SectionIDs})
+	}
+	if g.salinity != nil {
+		levels := current.SalinityLevels()
+		if opts.ExactResume {
+			levels = g.salinity.Levels()
+		}
+		cfg.Sections = withSalinity(cfg.Sections, levels)
+	}
+	cfg.Sections = withDimensions(cfg.Sections, current.Sections)
+	if current.Tank != nil {
+		tank := *current.Tank
+		if opts.ExactResume {
+			tank.InitialLevel = state.SupplyLevel
+		}
+		cfg.Tank = &tank
+	}
+	return cfg, nil
+}
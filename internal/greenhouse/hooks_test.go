@@ -0,0 +1,45 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/events"
+	"math"
+	"testing"
+)
+
+func TestTickHooks_RunBeforeExporters(t *testing.T) {
+	averages := map[bool]float64{}
+	for _, hooked := range []bool{false, true} {
+		g, err := New(testConfig())
+		if err != nil {
+			t.Fatalf("failed to build greenhouse: %v", err)
+		}
+		if hooked {
+			err := g.Simulator().AddTickHook("extra-water", func(ctx engine.TickContext) error {
+				if err := ctx.SetEnvironment(0.9, 0.5); err != nil {
+					return err
+				}
+				return ctx.WaterPlant("basil-1", 0.3)
+			})
+			if err != nil {
+				t.Fatalf("failed to add hook: %v", err)
+			}
+		}
+		g.Bus().Subscribe(func(e events.Event) {
+			if e.Type == events.Tick {
+				averages[hooked] = e.Payload.(Stats).AverageSaturation
+			}
+		})
+		g.Simulator().Step()
+
+		if humidity := g.Humidity().Get("section-A"); hooked && humidity <= 0 {
+			t.Errorf("expected the hook to raise the ambient humidity, got %.2f", humidity)
+		}
+		if stats := g.Simulator().TickHookStats(); hooked && (len(stats) != 1 || stats[0].Failed != 0) {
+			t.Errorf("expected the hook to run without failing, got %+v", stats)
+		}
+	}
+	if got := averages[true] - averages[false]; math.Abs(got-0.15) > 1e-9 {
+		t.Errorf("expected the tick event to see the hook's water, 0.15 more on average, got %.4f", got)
+	}
+}
@@ -0,0 +1,41 @@
+package greenhouse
+
+import "greenhouse-simulator/internal/environment"
+
+// thermostat runs the climate control once the weather has worked out the
+// temperature of a tick: it hands the reading of the thermostat sensor to the
+// HVAC, whose heating and venting set the temperature of the next tick. A
+// failed sensor leaves the actuators as the thermostat last had them.
+type thermostat struct {
+	g        *greenhouse
+	sensorID string
+}
+
+func newThermostat(g *greenhouse, cfg environment.HVACConfig) *thermostat {
+	t := &thermostat{g: g}
+	if cfg.Thermostat != nil {
+		t.sensorID = cfg.Thermostat.SensorID
+	}
+	return t
+}
+
+// TickPhase names the climate control in tick traces.
+func (t *thermostat) TickPhase() string { return "hvac" }
+
+func (t *thermostat) OnTick(tick int) {
+	reading, ok := 0.0, false
+	if t.sensorID != "" {
+		if r, err := t.g.sensors.GetReading(t.sensorID); err == nil {
+			reading, ok = r.Value, true
+		}
+	}
+	t.g.hvac.Update(reading, ok)
+}
+
+// SetActuator switches the heater or the vent to auto mode, handing it back
+// to the thermostat, or forces it on or off, from the current tick on.
+// Returns an error if the actuator or the mode is unknown.
+// This method is safe for concurrent use.
+func (g *greenhouse) SetActuator(actuator environment.Actuator, mode environment.ActuatorMode) error {
+	return g.hvac.SetMode(actuator, mode)
+}
@@ -0,0 +1,117 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/environment"
+	"greenhouse-simulator/internal/models"
+	"testing"
+	"time"
+)
+
+// hvacConfig has nights down to 6 degrees and afternoons up to 22, with a
+// noisy temperature sensor driving a thermostat set to 18-24.
+func hvacConfig() *config.GreenhouseConfig {
+	return &config.GreenhouseConfig{
+		TickInterval: config.Duration(time.Second),
+		Seed:         7,
+		Environment:  config.EnvironmentConfig{TicksPerDay: 24, Temperature: 14, TemperatureSwing: 8},
+		Sensors: []config.SensorConfig{
+			{ID: "temp", Type: models.Temperature, SectionID: "section-A", Noise: 0.3},
+		},
+		HVAC: &config.HVACConfig{
+			HeaterPower:  3.2,
+			VentPower:    1.5,
+			HeatLoss:     0.15,
+			HeaterEnergy: 2,
+			Thermostat:   &config.ThermostatConfig{SensorID: "temp", Min: 18, Max: 24},
+		},
+	}
+}
+
+func TestThermostat_HoldsTheBandThroughColdNights(t *testing.T) {
+	g, err := New(hvacConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	// The first day warms the greenhouse up from the outside temperature.
+	for range 24 {
+		g.Simulator().Step()
+	}
+
+	previous := g.HVAC().State()
+	energy := g.Stats().HVACEnergy
+	coldest, toggles, heating, venting := 100.0, 0, 0, 0
+	for range 48 {
+		g.Simulator().Step()
+		state := g.HVAC().State()
+		if state.Heater != previous.Heater {
+			toggles++
+		}
+		if state.Vent != previous.Vent {
+			toggles++
+		}
+		if state.Heater {
+			heating++
+		}
+		if state.Vent {
+			venting++
+		}
+		previous = state
+
+		tick := g.Simulator().GetCurrentTick() - 1
+		coldest = min(coldest, g.Climate().At(tick).Temperature)
+		// The thermostat reacts a tick late to a noisy reading, so the
+		// temperature may overshoot the band by up to a degree.
+		if temperature := g.Conditions().Temperature; temperature < 17 || temperature > 25 {
+			t.Errorf("tick %d: expected the temperature to stay within 17-25, got %.2f", tick, temperature)
+		}
+	}
+	if coldest > 7 {
+		t.Fatalf("expected nights below 7 degrees outside, the coldest was %.2f", coldest)
+	}
+	// Switching back only at the middle of the band keeps the actuators from
+	// toggling every tick: at most once every 3 ticks.
+	if toggles == 0 || toggles > 16 {
+		t.Errorf("expected the actuators to switch between 1 and 16 times in 2 days, got %d", toggles)
+	}
+	if got := g.Stats().HVACEnergy - energy; got != float64(2*heating+venting) {
+		t.Errorf("expected %d heater and %d vent ticks to use %d energy, got %.2f", heating, venting, 2*heating+venting, got)
+	}
+}
+
+func TestHVAC_ManualOverride(t *testing.T) {
+	cfg := hvacConfig()
+	cfg.Timeline = []config.ActionConfig{
+		{Tick: 0, Action: config.ActionSetActuator, Actuator: environment.Heater, Mode: environment.ModeOff},
+		{Tick: 2, Action: config.ActionSetActuator, Actuator: environment.Heater, Mode: environment.ModeAuto},
+	}
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	// The night starts cold, but the override keeps the heater off.
+	for range 2 {
+		g.Simulator().Step()
+		if state := g.HVAC().State(); state.Heater || state.HeaterMode != environment.ModeOff {
+			t.Errorf("expected the heater forced off, got %+v", state)
+		}
+	}
+	if got := g.Conditions().Temperature; got != g.Climate().At(1).Temperature {
+		t.Errorf("expected the outside temperature without heating, got %.2f", got)
+	}
+	g.Simulator().Step()
+	if state := g.HVAC().State(); !state.Heater || state.HeaterMode != environment.ModeAuto {
+		t.Errorf("expected the thermostat to switch the heater on once back in auto, got %+v", state)
+	}
+
+	if err := g.SetActuator(environment.Vent, environment.ModeOn); err != nil {
+		t.Fatalf("failed to force the vent on: %v", err)
+	}
+	g.Simulator().Step()
+	if !g.HVAC().State().Vent {
+		t.Error("expected the vent forced on")
+	}
+	if err := g.SetActuator("fan", environment.ModeOn); err == nil {
+		t.Error("expected an unknown actuator to be refused")
+	}
+}
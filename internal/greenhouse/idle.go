@@ -0,0 +1,112 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/events"
+	"sync/atomic"
+	"time"
+)
+
+// idleWatch pauses the simulation once nothing consumed it for a number of
+// ticks, see config.GreenhouseConfig.IdlePauseTicks, and resumes it on the
+// next consumption, see Greenhouse.Touch. Ticks are counted as
+// GetCurrentTick counts them: ran is the number of ticks run and consumed
+// what it was on the last consumption. Touch is called from the API and
+// exporter goroutines on every request, so it only stores an atomic unless
+// the simulation is idle.
+type idleWatch struct {
+	g        *greenhouse
+	ticks    int
+	ran      atomic.Int64
+	consumed atomic.Int64
+	// paused is set from the tick the watch decides to pause the simulation
+	// until a consumption or a Pause or Resume call takes it over.
+	paused atomic.Bool
+}
+
+func newIdleWatch(g *greenhouse, ticks int) *idleWatch {
+	w := &idleWatch{g: g, ticks: ticks}
+	tick := int64(g.sim.GetCurrentTick())
+	w.ran.Store(tick)
+	w.consumed.Store(tick)
+	return w
+}
+
+// TickPhase names the idle watch in tick traces.
+func (w *idleWatch) TickPhase() string { return "idle" }
+
+// OnTick pauses the simulation when the tick is the last one the watch lets
+// run without a consumption. The simulator cannot pause from within a tick,
+// which it would wait for, so the pause is left to a goroutine.
+func (w *idleWatch) OnTick(tick int) {
+	w.ran.Store(int64(tick + 1))
+	if !w.idle() || !w.paused.CompareAndSwap(false, true) {
+		return
+	}
+	go w.pause(tick)
+}
+
+// idle reports whether the watch has let as many ticks as it may run without
+// a consumption.
+func (w *idleWatch) idle() bool {
+	return w.ran.Load()-w.consumed.Load() >= int64(w.ticks)
+}
+
+// pause pauses the running simulation and publishes an IdlePaused event for
+// tick, unless it was consumed, paused or stopped since the watch decided to.
+func (w *idleWatch) pause(tick int) {
+	w.g.pauseMu.Lock()
+	defer w.g.pauseMu.Unlock()
+	if !w.paused.Load() {
+		return
+	}
+	if !w.idle() || w.g.sim.State() != engine.Running || w.g.sim.Pause() != nil {
+		w.paused.Store(false)
+		return
+	}
+	w.g.bus.Publish(events.Event{
+		Type:      events.IdlePaused,
+		Tick:      tick,
+		Timestamp: time.Now(),
+		Payload:   w.ticks,
+	})
+}
+
+// touch records a consumption, and resumes the simulation if the watch
+// paused it.
+func (w *idleWatch) touch() {
+	w.consumed.Store(w.ran.Load())
+	if !w.paused.CompareAndSwap(true, false) {
+		return
+	}
+	w.g.pauseMu.Lock()
+	defer w.g.pauseMu.Unlock()
+	if w.g.sim.State() == engine.Paused {
+		w.g.sim.Resume()
+	}
+}
+
+// takeOver clears the pause of the watch, for a Pause or Resume call that
+// takes it over, and reports whether the watch had paused the simulation.
+// Callers must hold g.pauseMu.
+func (w *idleWatch) takeOver() bool {
+	if w == nil {
+		return false
+	}
+	w.consumed.Store(w.ran.Load())
+	return w.paused.Swap(false)
+}
+
+// Touch records that a consumer used the simulation: an API call, an event
+// delivered to a subscriber or an exporter flush. With
+// config.GreenhouseConfig.IdlePauseTicks, the simulation pauses once that
+// many ticks ran without a call to Touch, publishing an IdlePaused event,
+// and the next call resumes it. Pausing a simulation the idle watch paused
+// keeps it paused until it is resumed, and resuming it works as usual.
+// Touch does nothing without an idle watch.
+// This method is safe for concurrent use.
+func (g *greenhouse) Touch() {
+	if idle := g.idle.Load(); idle != nil {
+		idle.touch()
+	}
+}
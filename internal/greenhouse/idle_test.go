@@ -0,0 +1,151 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/events"
+	"sync"
+	"testing"
+	"time"
+)
+
+// startIdle starts a greenhouse that pauses after the given number of idle
+// ticks, with a tick interval long enough that only Step runs ticks, and
+// returns it with the ticks of the IdlePaused events it publishes.
+func startIdle(t *testing.T, ticks int) (Greenhouse, func() []int) {
+	t.Helper()
+	cfg := weatherConfig()
+	cfg.TickInterval = config.Duration(time.Hour)
+	cfg.IdlePauseTicks = ticks
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	var paused []int
+	var mu sync.Mutex
+	g.Bus().Subscribe(func(e events.Event) {
+		if e.Type == events.IdlePaused {
+			mu.Lock()
+			paused = append(paused, e.Tick)
+			mu.Unlock()
+		}
+	})
+	sim := g.Simulator()
+	go sim.Start()
+	t.Cleanup(func() { sim.Stop() })
+	waitForState(t, sim, engine.Running)
+	return g, func() []int {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]int(nil), paused...)
+	}
+}
+
+// waitForState waits until the simulator is in state, for a second at most.
+func waitForState(t *testing.T, sim engine.Simulator, state engine.State) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); sim.State() != state; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the simulator to be %s, got %s", state, sim.State())
+		}
+	}
+}
+
+func TestIdleWatch_PausesAndResumes(t *testing.T) {
+	g, paused := startIdle(t, 5)
+	sim := g.Simulator()
+
+	// Ticks 0 to 2, then a consumption: ticks 3 to 7 are the 5 idle ones.
+	for range 3 {
+		sim.Step()
+	}
+	g.Touch()
+	for range 4 {
+		sim.Step()
+	}
+	if sim.State() != engine.Running || len(paused()) != 0 {
+		t.Fatalf("expected the simulation to run after 4 idle ticks, got %s and pauses on %v", sim.State(), paused())
+	}
+	sim.Step()
+	waitForState(t, sim, engine.Paused)
+	if got := paused(); len(got) != 1 || got[0] != 7 {
+		t.Errorf("expected an idle pause on tick 7, got %v", got)
+	}
+
+	g.Touch()
+	if sim.State() != engine.Running {
+		t.Fatalf("expected the next consumption to resume the simulation, got %s", sim.State())
+	}
+	for range 4 {
+		sim.Step()
+	}
+	if sim.State() != engine.Running {
+		t.Errorf("expected the idle ticks to count from the resume, got %s", sim.State())
+	}
+	sim.Step()
+	waitForState(t, sim, engine.Paused)
+	if got := paused(); len(got) != 2 || got[1] != 12 {
+		t.Errorf("expected a second idle pause on tick 12, got %v", got)
+	}
+}
+
+func TestIdleWatch_PauseAndResumeTakeOver(t *testing.T) {
+	g, _ := startIdle(t, 1)
+	sim := g.Simulator()
+	sim.Step()
+	waitForState(t, sim, engine.Paused)
+
+	if err := g.Pause(); err != nil {
+		t.Fatalf("expected pausing an idle pause to keep it paused, got %v", err)
+	}
+	g.Touch()
+	if sim.State() != engine.Paused {
+		t.Fatalf("expected a consumption not to resume a pause, got %s", sim.State())
+	}
+	if err := g.Resume(); err != nil {
+		t.Fatalf("failed to resume: %v", err)
+	}
+	if err := g.Pause(); err != nil {
+		t.Fatalf("failed to pause: %v", err)
+	}
+	g.Touch()
+	if sim.State() != engine.Paused {
+		t.Errorf("expected a consumption not to resume a pause, got %s", sim.State())
+	}
+}
+
+func TestIdleWatch_ExporterFlushesConsume(t *testing.T) {
+	g, paused := startIdle(t, 2)
+	sim := g.Simulator()
+	exporter := newFakeExporter()
+	if err := g.Exporters().Register("fake", exporter, 0); err != nil {
+		t.Fatalf("failed to register exporter: %v", err)
+	}
+	for range 10 {
+		sim.Step()
+		g.Exporters().Drain()
+	}
+	if sim.State() != engine.Running || len(paused()) != 0 {
+		t.Errorf("expected the exporter flushes to keep the simulation running, got %s and pauses on %v", sim.State(), paused())
+	}
+}
+
+func TestIdleWatch_Off(t *testing.T) {
+	cfg := weatherConfig()
+	cfg.TickInterval = config.Duration(time.Hour)
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	sim := g.Simulator()
+	go sim.Start()
+	defer sim.Stop()
+	waitForState(t, sim, engine.Running)
+	for range 50 {
+		sim.Step()
+	}
+	time.Sleep(10 * time.Millisecond)
+	if sim.State() != engine.Running {
+		t.Errorf("expected the simulation to run without an idle pause, got %s", sim.State())
+	}
+}
@@ -0,0 +1,103 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"path/filepath"
+	"testing"
+)
+
+// corruptor sets the growth of the first plant back to a seed on a given
+// tick, as a buggy growth model would.
+type corruptor struct {
+	g    Greenhouse
+	tick int
+}
+
+func (c corruptor) OnTick(tick int) {
+	if tick == c.tick {
+		c.g.Simulator().GetAllPlants()[0].GrowthStage = 0
+	}
+}
+
+func TestInvariantChecks_PublishViolations(t *testing.T) {
+	cfg := goldenConfig(42)
+	cfg.Invariants = config.InvariantsEvent
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	var violations []events.Event
+	g.Bus().Subscribe(func(e events.Event) {
+		if e.Type == events.InvariantViolated {
+			violations = append(violations, e)
+		}
+	})
+	g.Simulator().AddTickListener(corruptor{g: g, tick: 30})
+
+	for range 500 {
+		g.Simulator().Step()
+	}
+	// A sound run breaks nothing until the corruption.
+	if len(violations) != 1 {
+		t.Fatalf("expected 1 violation, got %+v", violations)
+	}
+	violation, ok := violations[0].Payload.(engine.InvariantViolation)
+	if !ok || violations[0].Tick != 30 || violation.PlantID != "tomato-1" || violation.Field != "GrowthStage" || violation.Value != 0 {
+		t.Errorf("expected the growth of tomato-1 reported on tick 30, got %+v", violations[0])
+	}
+
+	reloaded := goldenConfig(42)
+	if _, err := g.ReloadConfig(reloaded); err == nil || err.Error() != "invariant checks cannot change while the simulation runs" {
+		t.Errorf("expected a reload turning the checks off to be refused, got %v", err)
+	}
+}
+
+// TestInvariantChecks_SchemaFixtures loads a snapshot of every released
+// config schema version, as ExportScenario writes them, and checks that the
+// migrated greenhouse resumes soundly. Add a fixture with each new version.
+func TestInvariantChecks_SchemaFixtures(t *testing.T) {
+	paths, err := filepath.Glob("testdata/schema/v*")
+	if err != nil {
+		t.Fatalf("failed to list the fixtures: %v", err)
+	}
+	if len(paths) != config.SchemaVersion {
+		t.Fatalf("expected a fixture for each of the %d schema versions, got %v", config.SchemaVersion, paths)
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			cfg, err := config.LoadConfig(path)
+			if err != nil {
+				t.Fatalf("failed to load the fixture: %v", err)
+			}
+			if cfg.SchemaVersion != config.SchemaVersion {
+				t.Errorf("expected schema version %d, got %d", config.SchemaVersion, cfg.SchemaVersion)
+			}
+			cfg.Invariants = config.InvariantsEvent
+			g, err := New(cfg)
+			if err != nil {
+				t.Fatalf("failed to build greenhouse: %v", err)
+			}
+			var violations []events.Event
+			g.Bus().Subscribe(func(e events.Event) {
+				if e.Type == events.InvariantViolated {
+					violations = append(violations, e)
+				}
+			})
+
+			plant, err := g.Simulator().GetPlant("basil-2")
+			if err != nil || plant.Alive || plant.DeathCause != models.DeathByDrought {
+				t.Errorf("expected basil-2 to resume dead of drought, got %+v, %v", plant, err)
+			}
+			for range 50 {
+				g.Simulator().Step()
+			}
+			if len(violations) != 0 {
+				t.Errorf("expected no violations, got %+v", violations)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package greenhouse
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrNoJournal is returned when reading the journal of a plant in a
+// greenhouse configured without plant journals.
+var ErrNoJournal = errors.New("no plant journal configured")
+
+// JournalEntry is a significant change of a plant, see
+// Greenhouse.GetPlantJournal: the event that reported it on Tick, what
+// changed From and To, and the value that goes with it. What they hold
+// depends on the event type:
+//   - PlantStageChanged: the stages of life, and the growth stage
+//   - PlantHealthChanged: the health bands, and the health
+//   - PlantStressStarted and PlantStressEnded: the stress in To or From,
+//     and the saturation of the roots
+//   - PlantWatered: the watering event ID in To, and the water the soil took
+//   - PlantInfected: the disease stage in To
+//   - PlantCured: nothing
+//   - PlantDied: the death cause in To
+type JournalEntry struct {
+	Tick  int         `json:"tick"`
+	Type  events.Type `json:"type"`
+	From  string      `json:"from,omitempty"`
+	To    string      `json:"to,omitempty"`
+	Value float64     `json:"value,omitempty"`
+}
+
+// journal keeps the last entries of each plant, made from the plant events
+// published on the bus, the same stream the exporters see. A plant added
+// with the ID of one that was removed starts a new journal; the journals of
+// removed plants are kept for post-mortems.
+type journal struct {
+	size    int
+	entries map[string][]JournalEntry
+	mu      sync.Mutex
+	// unsubscribe stops the journal, when the greenhouse is reset.
+	unsubscribe func()
+}
+
+// newJournal returns a journal keeping size entries for each plant,
+// subscribed to bus.
+func newJournal(bus events.Bus, size int) *journal {
+	j := &journal{size: size, entries: map[string][]JournalEntry{}}
+	j.unsubscribe = bus.Subscribe(j.handle)
+	return j
+}
+
+func (j *journal) handle(e events.Event) {
+	entry := JournalEntry{Tick: e.Tick, Type: e.Type}
+	switch e.Type {
+	case events.PlantStageChanged, events.PlantHealthChanged, events.PlantStressStarted, events.PlantStressEnded, events.PlantWatered:
+		change, _ := e.Payload.(models.PlantChange)
+		entry.From, entry.To, entry.Value = change.From, change.To, change.Value
+	case events.PlantInfected:
+		disease, _ := e.Payload.(models.Disease)
+		entry.To = string(disease.Stage)
+	case events.PlantCured:
+	case events.PlantDied:
+		cause, _ := e.Payload.(models.DeathCause)
+		entry.To = string(cause)
+	case events.PlantAdded:
+		j.mu.Lock()
+		delete(j.entries, e.PlantID)
+		j.mu.Unlock()
+		return
+	default:
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entries := j.entries[e.PlantID]
+	if len(entries) == j.size {
+		entries = slices.Delete(entries, 0, 1)
+	}
+	j.entries[e.PlantID] = append(entries, entry)
+}
+
+// get returns a copy of the entries of a plant, oldest first, and whether
+// it has any.
+func (j *journal) get(plantID string) ([]JournalEntry, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entries, ok := j.entries[plantID]
+	return slices.Clone(entries), ok
+}
+
+// GetPlantJournal returns the journal of a plant, oldest entry first: its
+// stage of life changes, health band crossings, stress episodes starting and
+// ending, the waterings it took, its infections and cures, and its death, at
+// most the configured number of entries, see config.JournalConfig. Removed
+// plants keep their journal. Returns an error if:
+// - the greenhouse keeps no journals (ErrNoJournal)
+// - there is no such plant, nor a journal of a removed one
+// (engine.ErrPlantNotFound)
+//
+// This method is safe for concurrent use.
+func (g *greenhouse) GetPlantJournal(plantID string) ([]JournalEntry, error) {
+	if g.journal == nil {
+		return nil, ErrNoJournal
+	}
+	if entries, ok := g.journal.get(plantID); ok {
+		return entries, nil
+	}
+	if _, err := g.sim.GetPlant(plantID); err != nil {
+		return nil, err
+	}
+	return []JournalEntry{}, nil
+}
+
+// plantWatch publishes the changes of the living plants the journal records
+// and no other component reports: a PlantStressStarted or PlantStressEnded
+// event when a plant's stress changes, a PlantHealthChanged event when its
+// health crosses into another band and a PlantStageChanged event when it
+// enters another stage of life, in that order. Plants are compared to how
+// the previous tick left them; those seen for the first time, such as plants
+// just added, have nothing to compare to. It runs right before the monitor,
+// which reports the deaths.
+type plantWatch struct {
+	g *greenhouse
+	// marks holds how the last tick left each living plant.
+	marks map[string]plantMarks
+}
+
+// plantMarks is the state of a plant plantWatch compares.
+type plantMarks struct {
+	stage  models.PlantStage
+	band   int
+	stress models.Stress
+}
+
+func marksOf(plant *models.Plant) plantMarks {
+	return plantMarks{stage: plant.Stage(), band: plant.HealthBand(), stress: plant.Stress()}
+}
+
+func newPlantWatch(g *greenhouse) *plantWatch {
+	w := &plantWatch{g: g, marks: map[string]plantMarks{}}
+	for _, plant := range g.sim.GetAllPlants() {
+		if plant.Alive {
+			w.marks[plant.ID] = marksOf(plant)
+		}
+	}
+	return w
+}
+
+// TickPhase names the plant watch in tick traces.
+func (w *plantWatch) TickPhase() string { return "journal" }
+
+func (w *plantWatch) OnTick(tick int) {
+	for _, plant := range w.g.sim.GetAllPlants() {
+		if !plant.Alive {
+			delete(w.marks, plant.ID)
+			continue
+		}
+		marks := marksOf(plant)
+		last, seen := w.marks[plant.ID]
+		w.marks[plant.ID] = marks
+		if !seen || marks == last {
+			continue
+		}
+		if marks.stress != last.stress {
+			if last.stress != "" {
+				w.publish(events.PlantStressEnded, tick, plant, models.PlantChange{From: string(last.stress), Value: plant.RootSaturation()})
+			}
+			if marks.stress != "" {
+				w.publish(events.PlantStressStarted, tick, plant, models.PlantChange{To: string(marks.stress), Value: plant.RootSaturation()})
+			}
+		}
+		if marks.band != last.band {
+			w.publish(events.PlantHealthChanged, tick, plant, models.PlantChange{
+				From: models.HealthBandLabel(last.band), To: models.HealthBandLabel(marks.band), Value: plant.Health})
+		}
+		if marks.stage != last.stage {
+			w.publish(events.PlantStageChanged, tick, plant, models.PlantChange{
+				From: string(last.stage), To: string(marks.stage), Value: plant.GrowthStage})
+		}
+	}
+}
+
+func (w *plantWatch) publish(t events.Type, tick int, plant *models.Plant, change models.PlantChange) {
+	w.g.bus.Publish(events.Event{
+		Type:      t,
+		Tick:      tick,
+		Timestamp: time.Now(),
+		SectionID: plant.SectionID,
+		PlantID:   plant.ID,
+		Payload:   change,
+	})
+}
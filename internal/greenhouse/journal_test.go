@@ -0,0 +1,142 @@
+package greenhouse
+
+import (
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/events"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// journalConfig has a sprout in each of section-A and section-B, both
+// starting a little dry, losing 0.05 of saturation and, once their roots are
+// below 0.3, 0.2 of health a tick. The sprout of section-A is infected and
+// watered on tick 1 and then left to die; that of section-B is watered back
+// into range on tick 4 and matures before it dries out again.
+func journalConfig() *config.GreenhouseConfig {
+	return &config.GreenhouseConfig{
+		TickInterval: config.Duration(time.Second),
+		PlantTypes: []config.PlantTypeConfig{
+			{Name: "Sprout", OptimalSaturation: 0.5, MinSaturation: 0.3, MaxSaturation: 0.9, BaseGrowthRate: 0.1,
+				SaturationDepletion: 0.05, HealthDegradationRate: 0.2, HealthEnhancementRate: 0.1},
+		},
+		Plants: []config.PlantConfig{
+			{ID: "declining", Type: "Sprout", SectionID: "section-A", InitialSaturation: 0.45},
+			{ID: "rescued", Type: "Sprout", SectionID: "section-B", InitialSaturation: 0.4},
+		},
+		Disease: &config.DiseaseConfig{Incubation: 100},
+		Journal: &config.JournalConfig{},
+		Timeline: []config.ActionConfig{
+			{Tick: 1, Action: config.ActionInfectPlant, PlantID: "declining"},
+			{Tick: 1, Action: config.ActionWater, SectionID: "section-A", Amount: 0.1},
+			{Tick: 4, Action: config.ActionWater, SectionID: "section-B", Amount: 0.4},
+		},
+	}
+}
+
+// journalLines formats a journal as "tick type from>to value" lines.
+func journalLines(journal []JournalEntry) []string {
+	lines := []string{}
+	for _, e := range journal {
+		lines = append(lines, fmt.Sprintf("%d %s %s>%s %.2f", e.Tick, e.Type, e.From, e.To, e.Value))
+	}
+	return lines
+}
+
+func TestJournal_Decline(t *testing.T) {
+	g, err := New(journalConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	for range 15 {
+		g.Simulator().Step()
+	}
+
+	tests := []struct {
+		plantID  string
+		expected []string
+	}{
+		{"rescued", []string{
+			"1 plant_stage_changed seedling>vegetative 0.25",
+			"2 plant_stress_started >drought 0.25",
+			"4 plant_watered >watering-2 0.40",
+			"4 plant_stress_ended drought> 0.55",
+			"4 plant_health_changed 0.75 and above>0.50-0.75 0.60",
+			"6 plant_health_changed 0.50-0.75>0.75 and above 0.80",
+			"8 plant_stage_changed vegetative>mature 1.00",
+			"10 plant_stress_started >drought 0.25",
+			"12 plant_health_changed 0.75 and above>0.50-0.75 0.60",
+			"13 plant_health_changed 0.50-0.75>0.25-0.50 0.40",
+			"14 plant_health_changed 0.25-0.50>below 0.25 0.20",
+		}},
+		{"declining", []string{
+			"1 plant_infected >infected 0.00",
+			"1 plant_watered >watering-1 0.10",
+			"1 plant_stage_changed seedling>vegetative 0.25",
+			"5 plant_stress_started >drought 0.25",
+			"7 plant_health_changed 0.75 and above>0.50-0.75 0.60",
+			"8 plant_health_changed 0.50-0.75>0.25-0.50 0.40",
+			"9 plant_health_changed 0.25-0.50>below 0.25 0.20",
+			"11 plant_died >drought 0.00",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.plantID, func(t *testing.T) {
+			journal, err := g.GetPlantJournal(tt.plantID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := journalLines(journal); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected the journal\n%v\ngot\n%v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestJournal_Capped(t *testing.T) {
+	cfg := journalConfig()
+	cfg.Journal.Entries = 3
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	for range 15 {
+		g.Simulator().Step()
+	}
+	journal, err := g.GetPlantJournal("rescued")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{
+		"12 plant_health_changed 0.75 and above>0.50-0.75 0.60",
+		"13 plant_health_changed 0.50-0.75>0.25-0.50 0.40",
+		"14 plant_health_changed 0.25-0.50>below 0.25 0.20",
+	}
+	if got := journalLines(journal); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected the last 3 entries %v, got %v", expected, got)
+	}
+
+	// A removed plant keeps its journal for post-mortems.
+	if err := g.RemovePlant("declining"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if journal, err := g.GetPlantJournal("declining"); err != nil || len(journal) != 3 || journal[2].Type != events.PlantDied {
+		t.Errorf("expected the journal of the removed plant to end with its death, got %v and %v", journal, err)
+	}
+	if _, err := g.GetPlantJournal("ghost"); !errors.Is(err, engine.ErrPlantNotFound) {
+		t.Errorf("expected ErrPlantNotFound, got %v", err)
+	}
+
+	cfg = journalConfig()
+	cfg.Journal = nil
+	without, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	if _, err := without.GetPlantJournal("rescued"); !errors.Is(err, ErrNoJournal) {
+		t.Errorf("expected ErrNoJournal, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/models"
+	"testing"
+	"time"
+)
+
+// lightsConfig has a slow-growing plant in each of section-A, whose grow
+// lights stay on for 4 ticks after sunset, and section-B, without lights, on
+// a 24 tick day.
+func lightsConfig() *config.GreenhouseConfig {
+	return &config.GreenhouseConfig{
+		TickInterval: config.Duration(time.Second),
+		Environment:  config.EnvironmentConfig{Light: 0.8, TicksPerDay: 24},
+		PlantTypes: []config.PlantTypeConfig{
+			{Name: "Sprout", OptimalSaturation: 0.6, MinSaturation: 0.2, MaxSaturation: 0.9, BaseGrowthRate: 0.01, HealthEnhancementRate: 0.01},
+		},
+		Plants: []config.PlantConfig{
+			{ID: "lit", Type: "Sprout", SectionID: "section-A", InitialSaturation: 0.6},
+			{ID: "unlit", Type: "Sprout", SectionID: "section-B", InitialSaturation: 0.6},
+		},
+		Sensors: []config.SensorConfig{
+			{ID: "light-A", Type: models.Light, SectionID: "section-A"},
+			{ID: "light-B", Type: models.Light, SectionID: "section-B"},
+		},
+		Lights: []config.LightsConfig{
+			{SectionID: "section-A", Intensity: 0.75, EnergyPerTick: 2, AfterSunset: 4},
+		},
+	}
+}
+
+func TestLights_ExtendedLightingGrowsMore(t *testing.T) {
+	g, err := New(lightsConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	for range 24 {
+		g.Simulator().Step()
+	}
+
+	plants := g.Simulator().GetAllPlants()
+	lit, unlit := plants[0].GrowthStage, plants[1].GrowthStage
+	if lit <= unlit {
+		t.Fatalf("expected the lit plant to grow more in a day, got %.3f lit and %.3f unlit", lit, unlit)
+	}
+	// Each of the 4 ticks adds the lamp's 0.75 light, times the 0.5 light
+	// boost of the 0.01 base growth rate.
+	if extra := lit - unlit; extra < 4*0.75*0.5*0.01-1e-9 || extra > 4*0.75*0.5*0.01+1e-9 {
+		t.Errorf("expected 0.015 extra growth from the lights, got %.4f", extra)
+	}
+	// 4 ticks on at 0.75 intensity and 2 energy a tick.
+	if energy := g.Stats().LightingEnergy; energy < 6-1e-9 || energy > 6+1e-9 {
+		t.Errorf("expected 6 energy used by the lights, got %.4f", energy)
+	}
+	if g.Lights().Get("section-A").On {
+		t.Error("expected the scheduled lights to be off again after 4 ticks")
+	}
+}
+
+func TestLights_SensorsReadSupplementalLight(t *testing.T) {
+	g, err := New(lightsConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	// Noon: 0.8 natural light, topped up by the lights and capped at 1.0.
+	for range 13 {
+		g.Simulator().Step()
+	}
+	if err := g.SetLights("section-A", true, 0.5); err != nil {
+		t.Fatalf("failed to switch the lights on: %v", err)
+	}
+
+	natural := g.Conditions().Light
+	readings := map[string]float64{}
+	for _, id := range []string{"light-A", "light-B"} {
+		reading, err := g.Sensors().GetReading(id)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", id, err)
+		}
+		readings[id] = reading.Value
+	}
+	if readings["light-B"] != natural {
+		t.Errorf("expected the unlit section to read the natural light %.2f, got %.2f", natural, readings["light-B"])
+	}
+	if readings["light-A"] != 1 {
+		t.Errorf("expected the lit section to read %.2f + 0.5 capped at 1.0, got %.2f", natural, readings["light-A"])
+	}
+
+	if err := g.SetLights("section-A", true, 2); err == nil {
+		t.Error("expected an intensity above 1.0 to be refused")
+	}
+}
+
+func TestTimeline_SetLights(t *testing.T) {
+	cfg := lightsConfig()
+	cfg.Lights = nil
+	cfg.Timeline = []config.ActionConfig{
+		{Tick: 2, Action: config.ActionSetLights, SectionID: "section-B", On: true, Intensity: 0.5},
+		{Tick: 4, Action: config.ActionSetLights, SectionID: "section-B"},
+	}
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	for range 3 {
+		g.Simulator().Step()
+	}
+	if lamp := g.Lights().Get("section-B"); !lamp.On || lamp.Intensity != 0.5 {
+		t.Errorf("expected the timeline to switch the section-B lights on at 0.5, got %+v", lamp)
+	}
+	for range 2 {
+		g.Simulator().Step()
+	}
+	if g.Lights().Get("section-B").On {
+		t.Error("expected the timeline to switch the section-B lights off")
+	}
+	// Ticks 2 and 3 at 0.5 intensity and the default 1 energy a tick.
+	if energy := g.Stats().LightingEnergy; energy != 1 {
+		t.Errorf("expected 1 energy used by the lights, got %.4f", energy)
+	}
+}
@@ -0,0 +1,197 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/environment"
+	"greenhouse-simulator/internal/models"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// microclimateConfig is weatherConfig with a lettuce and sensors in a cold,
+// dry and shaded section by the door and in a warm, humid and bright one in
+// the middle of the greenhouse.
+func microclimateConfig() *config.GreenhouseConfig {
+	cfg := weatherConfig()
+	cfg.Environment.AmbientHumidity = 0.5
+	cfg.Environment.Light = 0.6
+	cfg.Plants = []config.PlantConfig{
+		{ID: "lettuce-door", Type: "Lettuce", SectionID: "door", InitialSaturation: 0.7},
+		{ID: "lettuce-middle", Type: "Lettuce", SectionID: "middle", InitialSaturation: 0.7},
+	}
+	cfg.Sensors = nil
+	for _, section := range []string{"door", "middle"} {
+		cfg.Sensors = append(cfg.Sensors,
+			config.SensorConfig{ID: section + "-temperature", Type: models.Temperature, SectionID: section},
+			config.SensorConfig{ID: section + "-humidity", Type: models.Humidity, SectionID: section},
+			config.SensorConfig{ID: section + "-light", Type: models.Light, SectionID: section},
+		)
+	}
+	cfg.Microclimates = []config.MicroclimateConfig{
+		{SectionID: "door", Temperature: -4, Humidity: -0.2, Light: 0.5},
+		{SectionID: "middle", Temperature: 4, Humidity: 0.2, Light: 1.5},
+	}
+	return cfg
+}
+
+func read(t *testing.T, g Greenhouse, sensorID string) float64 {
+	t.Helper()
+	reading, err := g.Sensors().GetReading(sensorID)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", sensorID, err)
+	}
+	return reading.Value
+}
+
+func TestMicroclimate_SensorsDiverge(t *testing.T) {
+	g, err := New(microclimateConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	g.Simulator().Step()
+
+	global := g.Conditions()
+	tests := []struct {
+		kind           string
+		door, middle   float64
+		expectedSpread float64
+	}{
+		{"temperature", read(t, g, "door-temperature"), read(t, g, "middle-temperature"), 8},
+		{"humidity", read(t, g, "door-humidity"), read(t, g, "middle-humidity"), 0.4},
+		{"light", read(t, g, "door-light"), read(t, g, "middle-light"), global.Light},
+	}
+	for _, tt := range tests {
+		if math.Abs(tt.middle-tt.door-tt.expectedSpread) > 1e-9 {
+			t.Errorf("expected the %s to read %.2f more in the middle than by the door, got %.2f and %.2f", tt.kind, tt.expectedSpread, tt.middle, tt.door)
+		}
+	}
+	if got := g.SectionConditions("door").Temperature; got != global.Temperature-4 {
+		t.Errorf("expected the door section 4 degrees below %.2f, got %.2f", global.Temperature, got)
+	}
+}
+
+func TestMicroclimate_FrostSparesWarmSection(t *testing.T) {
+	g, err := New(microclimateConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	if err := g.TriggerWeatherEvent(environment.Frost, 2); err != nil {
+		t.Fatalf("failed to trigger a frost: %v", err)
+	}
+	for range 2 {
+		g.Simulator().Step()
+	}
+	door, middle := g.Simulator().GetAllPlants()[0], g.Simulator().GetAllPlants()[1]
+	if door.Health >= 1 {
+		t.Errorf("expected the frost to damage the lettuce by the door, got %.2f", door.Health)
+	}
+	if middle.Health < 1 {
+		t.Errorf("expected the warm section to be spared the frost, got %.2f", middle.Health)
+	}
+}
+
+func TestMicroclimate_ThermostatByTheDoorHeatsMore(t *testing.T) {
+	heating := func(offset float64) (int, float64) {
+		cfg := hvacConfig()
+		cfg.Microclimates = []config.MicroclimateConfig{{SectionID: "section-A", Temperature: offset}}
+		g, err := New(cfg)
+		if err != nil {
+			t.Fatalf("failed to build greenhouse: %v", err)
+		}
+		ticks, total := 0, 0.0
+		for range 48 {
+			g.Simulator().Step()
+			if g.HVAC().State().Heater {
+				ticks++
+			}
+			total += g.Conditions().Temperature
+		}
+		return ticks, total / 48
+	}
+	middleTicks, middleAverage := heating(1)
+	doorTicks, doorAverage := heating(-4)
+	if doorTicks <= middleTicks {
+		t.Errorf("expected a thermostat by the door to heat more than %d ticks, got %d", middleTicks, doorTicks)
+	}
+	if doorAverage <= middleAverage+2 {
+		t.Errorf("expected a thermostat by the door to keep the greenhouse warmer than %.2f, got %.2f", middleAverage, doorAverage)
+	}
+}
+
+func TestSetSectionClimateOffset(t *testing.T) {
+	g, err := New(weatherConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	tests := []struct {
+		name     string
+		offset   environment.ClimateOffset
+		errorMsg string
+	}{
+		{"valid", environment.ClimateOffset{Temperature: -5, Humidity: 0.1}, ""},
+		{"humidity below -1", environment.ClimateOffset{Humidity: -1.5}, "humidity offset must be between -1.0 and 1.0"},
+		{"negative light", environment.ClimateOffset{Light: -0.5}, "light factor cannot be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := g.SetSectionClimateOffset("section-A", tt.offset)
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+
+	if got := read(t, g, "thermometer"); got != 7 {
+		t.Errorf("expected the thermometer to read the offset right away, got %.2f", got)
+	}
+	if err := g.SetSectionClimateOffset("section-A", environment.ClimateOffset{}); err != nil {
+		t.Fatalf("failed to remove the microclimate: %v", err)
+	}
+	if got := read(t, g, "thermometer"); got != 12 {
+		t.Errorf("expected the thermometer to read 12 without the microclimate, got %.2f", got)
+	}
+}
+
+func TestMicroclimate_ExportAndReload(t *testing.T) {
+	cfg := microclimateConfig()
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	middle := environment.ClimateOffset{Temperature: 1}
+	if err := g.SetSectionClimateOffset("middle", middle); err != nil {
+		t.Fatalf("failed to set the microclimate: %v", err)
+	}
+	exported, err := g.ExportScenario(config.ExportOptions{})
+	if err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	expected := []config.MicroclimateConfig{cfg.Microclimates[0], {SectionID: "middle", Temperature: 1}}
+	if !reflect.DeepEqual(exported.Microclimates, expected) {
+		t.Errorf("expected the export to carry the live microclimates %+v, got %+v", expected, exported.Microclimates)
+	}
+
+	// A reload leaves the runtime change alone until the microclimates
+	// change in the config.
+	if _, err := g.ReloadConfig(microclimateConfig()); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	if got := g.SectionClimateOffset("middle"); got != middle {
+		t.Errorf("expected the runtime microclimate %+v to survive the reload, got %+v", middle, got)
+	}
+	reloaded := microclimateConfig()
+	reloaded.Microclimates = reloaded.Microclimates[:1]
+	if _, err := g.ReloadConfig(reloaded); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	if got := g.SectionClimateOffset("middle"); got != (environment.ClimateOffset{}) {
+		t.Errorf("expected the reload to remove the middle microclimate, got %+v", got)
+	}
+}
@@ -0,0 +1,191 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/environment"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"maps"
+	"time"
+)
+
+// Stats summarises the state of the greenhouse. TankRemaining is nil when the
+// greenhouse has no tank. LightingEnergy and HVACEnergy are the energy the
+// grow lights and the heater and vent have used. Died counts every plant
+// found dead since the greenhouse was built, removed or not, and DiedBy
+// splits it by cause. Season and DayOfYear place the last tick in the year
+// when the climate has seasons, so exporters can tag their data.
+type Stats struct {
+	Plants            int                       `json:"plants"`
+	AlivePlants       int                       `json:"alive_plants"`
+	Died              int                       `json:"died"`
+	DiedBy            map[models.DeathCause]int `json:"died_by,omitempty"`
+	AverageHealth     float64                   `json:"average_health"`
+	AverageSaturation float64                   `json:"average_saturation"`
+	WaterUsed         float64                   `json:"water_used"`
+	WaterWasted       float64                   `json:"water_wasted"`
+	TankRemaining     *float64                  `json:"tank_remaining,omitempty"`
+	LightingEnergy    float64                   `json:"lighting_energy"`
+	HVACEnergy        float64                   `json:"hvac_energy"`
+	Season            environment.Season        `json:"season,omitempty"`
+	DayOfYear         int                       `json:"day_of_year,omitempty"`
+}
+
+// Stats returns a summary of the current plants, water and energy use. The averages
+// are over every plant, dead or alive, taken from the running sums of the
+// sections rather than the plants, see engine.Simulator.SectionStats.
+// This method is safe for concurrent use.
+func (g *greenhouse) Stats() Stats {
+	return g.statsOf(g.sim.PlantStats())
+}
+
+// statsOf summarises the plants of the greenhouse, summed up as plants, and
+// its water and energy use.
+func (g *greenhouse) statsOf(plants engine.PlantStats) Stats {
+	stats := Stats{
+		Plants:            plants.Plants,
+		AlivePlants:       plants.AlivePlants,
+		AverageHealth:     plants.AverageHealth(),
+		AverageSaturation: plants.AverageSaturation(),
+	}
+	g.mu.Lock()
+	stats.Died, stats.DiedBy = g.died, g.diedBy
+	g.mu.Unlock()
+	water := g.watering.GetWaterStats()
+	stats.WaterUsed = water.Used
+	stats.WaterWasted = water.Wasted
+	if !water.Unlimited {
+		stats.TankRemaining = &water.Remaining
+	}
+	stats.LightingEnergy = g.lights.Energy()
+	stats.HVACEnergy = g.hvac.Energy()
+	conditions := g.Conditions()
+	stats.Season, stats.DayOfYear = conditions.Season, conditions.DayOfYear
+	return stats
+}
+
+// monitor publishes what happened on a tick once every other listener has
+// handled it: a PlantDied event for each plant found dead for the first time,
+// a PlantRemoved event for each dead plant the retention policy removes, a
+// SensorSample event for each sensor that can be read and is due a sample,
+// see models.Sensor.SampleInterval, and finally a Tick event carrying the
+// greenhouse Stats.
+type monitor struct {
+	g *greenhouse
+	// dead holds the tick each dead plant was first found dead on.
+	dead map[string]int
+}
+
+// newMonitor returns the monitor of g, counting the plants that are dead
+// already as if they had died on the current tick.
+func newMonitor(g *greenhouse) *monitor {
+	m := &monitor{g: g, dead: map[string]int{}}
+	for _, plant := range g.sim.GetAllPlants() {
+		if !plant.Alive {
+			m.dead[plant.ID] = g.sim.GetCurrentTick()
+			g.countDeath(plant.DeathCause)
+		}
+	}
+	return m
+}
+
+// TickPhase names the monitor in tick traces: its time goes to reading the
+// sensors and to the event handlers, exporters included.
+func (m *monitor) TickPhase() string { return "sensors.sample" }
+
+func (m *monitor) OnTick(tick int) {
+	bus := m.g.bus
+	plants := m.g.sim.GetAllPlants()
+	remove, after := m.g.Config().RemovesDeadPlants()
+	removed := false
+	for _, plant := range plants {
+		if plant.Alive {
+			continue
+		}
+		diedAt, seen := m.dead[plant.ID]
+		if !seen {
+			diedAt = tick
+			m.dead[plant.ID] = tick
+			m.g.countDeath(plant.DeathCause)
+			bus.Publish(events.Event{
+				Type:      events.PlantDied,
+				Tick:      tick,
+				Timestamp: time.Now(),
+				SectionID: plant.SectionID,
+				PlantID:   plant.ID,
+				Payload:   plant.DeathCause,
+			})
+		}
+		if remove && tick-diedAt >= after && m.g.compost(plant, tick) {
+			delete(m.dead, plant.ID)
+			removed = true
+		}
+	}
+	if removed {
+		plants = m.g.sim.GetAllPlants()
+	}
+	// Failed sensors, sensors of empty sections and, with the error dead
+	// section policy, sensors of dead sections have nothing to report.
+	m.g.sensors.ObserveWindows()
+	for _, sensor := range m.g.sensors.ListSensors() {
+		if !sensor.Samples(tick) {
+			continue
+		}
+		reading, err := m.g.sensors.GetReading(sensor.ID)
+		if err != nil {
+			continue
+		}
+		bus.Publish(events.Event{
+			Type:      events.SensorSample,
+			Tick:      tick,
+			Timestamp: reading.Timestamp,
+			SectionID: sensor.SectionID,
+			Payload:   *reading,
+		})
+	}
+	bus.Publish(events.Event{
+		Type:      events.Tick,
+		Tick:      tick,
+		Timestamp: time.Now(),
+		Payload:   m.g.statsOf(engine.SumPlants(plants)),
+	})
+}
+
+// countDeath adds a plant that died of cause to the Stats. diedBy is
+// replaced rather than changed, so that the Stats of past ticks can share it.
+func (g *greenhouse) countDeath(cause models.DeathCause) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.died++
+	if cause == "" {
+		return
+	}
+	diedBy := maps.Clone(g.diedBy)
+	if diedBy == nil {
+		diedBy = map[models.DeathCause]int{}
+	}
+	diedBy[cause]++
+	g.diedBy = diedBy
+}
+
+// compost removes a dead plant for the retention policy, as RemovePlant does,
+// and publishes a PlantRemoved event carrying its final state. Reports
+// whether the plant was removed.
+func (g *greenhouse) compost(plant *models.Plant, tick int) bool {
+	if err := g.sim.RemovePlant(plant.ID); err != nil {
+		return false
+	}
+	g.mu.Lock()
+	g.runtimeRemoved[plant.ID] = true
+	delete(g.runtimeAdded, plant.ID)
+	g.mu.Unlock()
+	g.bus.Publish(events.Event{
+		Type:      events.PlantRemoved,
+		Tick:      tick,
+		Timestamp: time.Now(),
+		SectionID: plant.SectionID,
+		PlantID:   plant.ID,
+		Payload:   plant.Clone(),
+	})
+	return true
+}
@@ -0,0 +1,135 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestMonitor_PublishesTickEvents(t *testing.T) {
+	cfg := testConfig()
+	cfg.PlantTypes[0].HealthDegradationRate = 0.5
+	cfg.Plants[0].InitialSaturation = 0
+	cfg.Plants[0].State = &config.PlantStateConfig{Health: 0.2, Alive: true}
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	var published []events.Event
+	g.Bus().Subscribe(func(e events.Event) { published = append(published, e) })
+
+	for range 3 {
+		g.Simulator().Step()
+	}
+	if _, err := g.AddPlant(config.PlantConfig{ID: "mint-1", Type: "Mint", SectionID: "section-B", InitialSaturation: 0.5}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := g.RemovePlant("basil-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	counts := map[events.Type]int{}
+	for _, e := range published {
+		counts[e.Type]++
+		switch e.Type {
+		case events.PlantDied:
+			if e.PlantID != "basil-1" || e.Tick != 0 {
+				t.Errorf("expected basil-1 to die on tick 0, got %+v", e)
+			}
+		case events.SensorSample:
+			if reading, ok := e.Payload.(models.SensorReading); !ok || reading.SensorID != "sensor-1" || e.SectionID != "section-A" {
+				t.Errorf("expected a sensor-1 reading for section-A, got %+v", e)
+			}
+		case events.Tick:
+			stats := e.Payload.(Stats)
+			if stats.Plants != 2 || stats.AlivePlants != 1 || stats.TankRemaining != nil {
+				t.Errorf("expected 1 of 2 plants alive and no tank, got %+v", stats)
+			}
+		case events.PlantAdded, events.PlantRemoved:
+			if e.Tick != 3 || (e.PlantID != "mint-1" && e.PlantID != "basil-1") {
+				t.Errorf("unexpected plant event %+v", e)
+			}
+		}
+	}
+	expected := map[events.Type]int{events.PlantDied: 1, events.SensorSample: 3, events.Tick: 3, events.PlantAdded: 1, events.PlantRemoved: 1}
+	for eventType, n := range expected {
+		if counts[eventType] != n {
+			t.Errorf("expected %d %s events, got %d", n, eventType, counts[eventType])
+		}
+	}
+}
+
+func TestMonitor_DeadPlantRetention(t *testing.T) {
+	tests := []struct {
+		name       string
+		deadPlants *config.DeadPlantsConfig
+		removedOn  int // -1 when the dead plant is kept
+	}{
+		{"keep by default", nil, -1},
+		{"keep", &config.DeadPlantsConfig{Retention: config.KeepDeadPlants}, -1},
+		{"remove", &config.DeadPlantsConfig{Retention: config.RemoveDeadPlants}, 0},
+		{"remove after 2 ticks", &config.DeadPlantsConfig{Retention: config.RemoveDeadPlants, After: 2}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.PlantTypes[0].HealthDegradationRate = 0.5
+			cfg.Plants[0].InitialSaturation = 0
+			cfg.Plants[0].State = &config.PlantStateConfig{Health: 0.2, Alive: true}
+			cfg.DeadPlants = tt.deadPlants
+			g, err := New(cfg)
+			if err != nil {
+				t.Fatalf("failed to build greenhouse: %v", err)
+			}
+			var removed []events.Event
+			var stats Stats
+			g.Bus().Subscribe(func(e events.Event) {
+				switch e.Type {
+				case events.PlantRemoved:
+					removed = append(removed, e)
+				case events.Tick:
+					stats = e.Payload.(Stats)
+				}
+			})
+
+			for range 4 {
+				g.Simulator().Step()
+			}
+			if _, err := g.ReloadConfig(cfg); err != nil {
+				t.Fatalf("failed to reload: %v", err)
+			}
+
+			_, err = g.Simulator().GetPlant("basil-1")
+			if tt.removedOn < 0 {
+				if err != nil || len(removed) != 0 {
+					t.Errorf("expected basil-1 to be kept, got %v and %d removals", err, len(removed))
+				}
+			} else {
+				if err == nil {
+					t.Error("expected basil-1 to be removed and not to come back on reload")
+				}
+				if len(removed) != 1 || removed[0].PlantID != "basil-1" || removed[0].Tick != tt.removedOn {
+					t.Fatalf("expected basil-1 removed on tick %d, got %+v", tt.removedOn, removed)
+				}
+				if plant, ok := removed[0].Payload.(*models.Plant); !ok || plant.Alive || plant.DeathCause != models.DeathByDrought {
+					t.Errorf("expected the removal to carry the dead plant, got %+v", removed[0].Payload)
+				}
+			}
+			// The cumulative counts survive the removal.
+			expected := map[models.DeathCause]int{models.DeathByDrought: 1}
+			if stats.Died != 1 || !reflect.DeepEqual(stats.DiedBy, expected) {
+				t.Errorf("expected 1 plant died of drought, got %d and %v", stats.Died, stats.DiedBy)
+			}
+			plants := 2
+			if tt.removedOn >= 0 {
+				plants = 1
+			}
+			if stats.Plants != plants || stats.AlivePlants != 1 {
+				t.Errorf("expected 1 of %d plants alive, got %+v", plants, stats)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/config"
+	"reflect"
+	"testing"
+)
+
+func TestPositions_ExportAndTransplant(t *testing.T) {
+	cfg := testConfig()
+	cfg.Sections = []config.SectionConfig{{ID: "section-A", Width: 4, Depth: 2}}
+	cfg.Plants[0].Position = &config.PositionConfig{X: 1, Y: 0.5}
+	cfg.Sensors[0].Position = &config.PositionConfig{X: 1.5, Y: 0.5}
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	nearest, err := g.Sensors().AssignNearestSensor("basil-1")
+	if err != nil || nearest.ID != "sensor-1" {
+		t.Fatalf("expected sensor-1 nearest to basil-1, got %+v, %v", nearest, err)
+	}
+	plants, err := g.Sensors().GetPlantsNearSensor("sensor-1", 1)
+	if err != nil || len(plants) != 1 || plants[0].ID != "basil-1" {
+		t.Fatalf("expected basil-1 alone near sensor-1, basil-2 has no position, got %v, %v", plants, err)
+	}
+
+	exported, err := g.ExportScenario(config.ExportOptions{})
+	if err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	if !reflect.DeepEqual(exported.Sections, cfg.Sections) {
+		t.Errorf("expected the export to keep the section dimensions %+v, got %+v", cfg.Sections, exported.Sections)
+	}
+	if got := exported.Plants[0].Position; got == nil || *got != *cfg.Plants[0].Position || exported.Plants[1].Position != nil {
+		t.Errorf("expected the export to keep the plant positions, got %+v and %+v", got, exported.Plants[1].Position)
+	}
+	if got := exported.Sensors[0].Position; got == nil || *got != *cfg.Sensors[0].Position {
+		t.Errorf("expected the export to keep the sensor position, got %+v", got)
+	}
+
+	if err := g.Simulator().TransplantPlant("basil-1", "section-B"); err != nil {
+		t.Fatalf("failed to transplant: %v", err)
+	}
+	if plant, _ := g.Simulator().GetPlant("basil-1"); plant.Position != nil {
+		t.Errorf("expected a transplanted plant to lose its position, got %+v", plant.Position)
+	}
+}
@@ -0,0 +1,207 @@
+package greenhouse
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// pruneConfig has 5 plants in section-A, at the health given for each, and
+// a pruning effect lasting 3 ticks.
+func pruneConfig(health map[string]float64) *config.GreenhouseConfig {
+	cfg := &config.GreenhouseConfig{
+		TickInterval: config.Duration(time.Second),
+		PlantTypes: []config.PlantTypeConfig{
+			{Name: "Sprout", OptimalSaturation: 0.6, MinSaturation: 0.2, MaxSaturation: 0.9, BaseGrowthRate: 0.01, SaturationDepletion: 0.02, HealthEnhancementRate: 0.01},
+		},
+		Pruning: &config.PruningConfig{Ticks: 3, Depletion: 0.5, Enhancement: 2},
+	}
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		plant := config.PlantConfig{ID: id, Type: "Sprout", SectionID: "section-A", InitialSaturation: 0.6}
+		if h, ok := health[id]; ok {
+			plant.State = &config.PlantStateConfig{Health: h, GrowthStage: 0.4, Alive: h > 0}
+		}
+		cfg.Plants = append(cfg.Plants, plant)
+	}
+	return cfg
+}
+
+func TestPrunePlant_ModifierExpires(t *testing.T) {
+	g, err := New(pruneConfig(map[string]float64{"a": 0.5, "b": 0.5}))
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	sim := g.Simulator()
+	if err := sim.PrunePlant("a", 0.5); err != nil {
+		t.Fatalf("failed to prune: %v", err)
+	}
+	pruned, twin := sim.GetAllPlants()[0], sim.GetAllPlants()[1]
+	if pruned.GrowthStage != 0.2 {
+		t.Errorf("expected half the 0.4 growth cut back, got %.3f", pruned.GrowthStage)
+	}
+	expected := []models.Modifier{{Source: models.Pruned, Depletion: 0.5, Enhancement: 2, Ticks: 3}}
+	if !reflect.DeepEqual(pruned.Modifiers, expected) {
+		t.Errorf("expected the modifier %v, got %v", expected, pruned.Modifiers)
+	}
+
+	for tick := 1; tick <= 3; tick++ {
+		sim.Step()
+		if got := len(pruned.Modifiers); (tick < 3) != (got == 1) {
+			t.Errorf("tick %d: expected the modifier to last 3 ticks, got %v", tick, pruned.Modifiers)
+		}
+	}
+	// 3 ticks of half the depletion and twice the recovery.
+	if pruned.SoilSaturation-twin.SoilSaturation < 0.029 || pruned.Health <= twin.Health {
+		t.Errorf("expected the pruned plant to keep wetter and recover faster, got %+v against %+v", pruned, twin)
+	}
+	saturation, health := pruned.SoilSaturation-twin.SoilSaturation, pruned.Health-twin.Health
+	sim.Step()
+	if pruned.SoilSaturation-twin.SoilSaturation != saturation || pruned.Health-twin.Health != health {
+		t.Errorf("expected the pruned plant back at its own rates once the modifier ran out")
+	}
+}
+
+func TestPrunePlant_Refused(t *testing.T) {
+	g, err := New(pruneConfig(map[string]float64{"a": 0, "b": 0.5}))
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	sim := g.Simulator()
+	if err := sim.PrunePlant("a", 0.2); err == nil || err.Error() != "cannot prune a dead plant: a" {
+		t.Errorf("expected a dead plant to be refused, got %v", err)
+	}
+	if err := sim.PrunePlant("c", 0.2); err == nil || err.Error() != "cannot prune a plant at the seed stage: c" {
+		t.Errorf("expected a seed to be refused, got %v", err)
+	}
+	if err := sim.PrunePlant("b", 0.6); err == nil {
+		t.Error("expected pruning more than half to be refused")
+	}
+	if err := sim.PrunePlant("ghost", 0.2); !errors.Is(err, engine.ErrPlantNotFound) {
+		t.Errorf("expected an unknown plant to be refused, got %v", err)
+	}
+	if err := sim.SetPruneEffect(models.PruneEffect{}); err == nil {
+		t.Error("expected an invalid prune effect to be refused")
+	}
+}
+
+func TestThinSection(t *testing.T) {
+	health := map[string]float64{"a": 0.9, "b": 0.3, "c": 0.6, "d": 0.3, "e": 0}
+	cfg := pruneConfig(health)
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	var published []string
+	g.Bus().Subscribe(func(e events.Event) {
+		if e.Type == events.PlantRemoved {
+			published = append(published, e.PlantID)
+		}
+	})
+
+	// The dead plant first, then the tie at 0.3 broken by ID.
+	removed, err := g.ThinSection("section-A", 2)
+	if err != nil {
+		t.Fatalf("failed to thin: %v", err)
+	}
+	expected := []string{"e", "b", "d"}
+	if !reflect.DeepEqual(removed, expected) {
+		t.Errorf("expected %v to be removed, got %v", expected, removed)
+	}
+	if !reflect.DeepEqual(published, expected) {
+		t.Errorf("expected a PlantRemoved event for each of %v, got %v", expected, published)
+	}
+	var kept []string
+	for _, plant := range g.Simulator().GetPlantsBySectionID("section-A") {
+		kept = append(kept, plant.ID)
+	}
+	if !reflect.DeepEqual(kept, []string{"a", "c"}) {
+		t.Errorf("expected a and c to be kept, got %v", kept)
+	}
+
+	if removed, err := g.ThinSection("section-A", 2); err != nil || removed != nil {
+		t.Errorf("expected nothing left to thin, got %v, %v", removed, err)
+	}
+	if _, err := g.ThinSection("section-A", -1); err == nil {
+		t.Error("expected a negative number of plants to keep to be refused")
+	}
+	if _, err := g.ReloadConfig(cfg); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	if plants := g.Simulator().GetAllPlants(); len(plants) != 2 {
+		t.Errorf("expected a reload not to add the thinned plants back, got %d plants", len(plants))
+	}
+}
+
+func TestThinSection_Deterministic(t *testing.T) {
+	health := map[string]float64{"a": 0.5, "b": 0.5, "c": 0.5, "d": 0.5, "e": 0.5}
+	for range 10 {
+		g, err := New(pruneConfig(health))
+		if err != nil {
+			t.Fatalf("failed to build greenhouse: %v", err)
+		}
+		if removed, _ := g.ThinSection("section-A", 3); !reflect.DeepEqual(removed, []string{"a", "b"}) {
+			t.Fatalf("expected ties to be broken by ID, got %v", removed)
+		}
+	}
+}
+
+func TestTimeline_PruneAndThin(t *testing.T) {
+	cfg := pruneConfig(map[string]float64{"a": 0.9, "b": 0.5, "c": 0.5, "d": 0.5, "e": 0.5})
+	cfg.Timeline = []config.ActionConfig{
+		{Tick: 1, Action: config.ActionPrunePlant, PlantID: "a", Fraction: 0.25},
+		{Tick: 2, Action: config.ActionThinSection, SectionID: "section-A", Keep: 1},
+	}
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	var results []ActionResult
+	g.Bus().Subscribe(func(e events.Event) {
+		if result, ok := e.Payload.(ActionResult); ok {
+			results = append(results, result)
+		}
+	})
+	for range 3 {
+		g.Simulator().Step()
+	}
+	plants := g.Simulator().GetAllPlants()
+	if len(plants) != 1 || plants[0].ID != "a" || len(plants[0].Modifiers) != 1 {
+		t.Fatalf("expected only the pruned plant a to be left, got %v", plants)
+	}
+	expected := []ActionResult{
+		{Tick: 1, Action: config.ActionPrunePlant, Target: "a"},
+		{Tick: 2, Action: config.ActionThinSection, Target: "b,c,d,e"},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("expected the timeline %v, got %v", expected, results)
+	}
+}
+
+func TestPrunePlant_ExportResumesModifier(t *testing.T) {
+	g, err := New(pruneConfig(map[string]float64{"a": 0.5}))
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	g.Simulator().PrunePlant("a", 0.5)
+	g.Simulator().Step()
+	exported, err := g.ExportScenario(config.ExportOptions{ExactResume: true})
+	if err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	resumed, err := New(exported)
+	if err != nil {
+		t.Fatalf("failed to load the export: %v", err)
+	}
+	expected := []models.Modifier{{Source: models.Pruned, Depletion: 0.5, Enhancement: 2, Ticks: 2}}
+	if got := resumed.Simulator().GetAllPlants()[0].Modifiers; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected the resumed plant to carry %v, got %v", expected, got)
+	}
+	if !reflect.DeepEqual(exported.Pruning, g.Config().Pruning) {
+		t.Errorf("expected the pruning effect to be exported, got %+v", exported.Pruning)
+	}
+}
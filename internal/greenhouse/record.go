@@ -0,0 +1,183 @@
+package greenhouse
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"io"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RecordColumns are the columns a RunRecorder can write, in their default
+// order. sim_time is the simulated time in seconds at the start of the tick.
+var RecordColumns = []string{"tick", "sim_time", "plant_id", "section", "type", "health", "growth", "saturation", "alive"}
+
+// RecordOptions configures a RunRecorder.
+type RecordOptions struct {
+	// Columns selects and orders the columns, all of RecordColumns when empty.
+	Columns []string
+	// Gzip compresses the output.
+	Gzip bool
+}
+
+// RunRecorder is an Exporter writing one CSV row per plant per tick, after a
+// header row.
+type RunRecorder interface {
+	Exporter
+	// Rows returns how many data rows were written.
+	Rows() int
+}
+
+type runRecorder struct {
+	columns  []string
+	interval time.Duration
+	gzip     *gzip.Writer
+	buffer   *bufio.Writer
+	csv      *csv.Writer
+	simTime  time.Duration
+	rows     int
+	err      error
+	closed   bool
+	mu       sync.Mutex
+}
+
+// NewRunRecorder writes the header row to w and returns an exporter recording
+// the ticks of g once registered with g.Exporters(), streaming each row to w
+// so that memory use does not grow with the run. The output is only complete
+// once the recorder is closed. w is not closed.
+// Returns an error if a column is unknown or selected twice, or the header
+// cannot be written.
+func NewRunRecorder(g Greenhouse, w io.Writer, opts RecordOptions) (RunRecorder, error) {
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = RecordColumns
+	}
+	for i, column := range columns {
+		if !slices.Contains(RecordColumns, column) {
+			return nil, errors.New("unknown record column: " + column)
+		}
+		if slices.Contains(columns[:i], column) {
+			return nil, errors.New("record column selected twice: " + column)
+		}
+	}
+
+	r := &runRecorder{columns: slices.Clone(columns), interval: g.Simulator().GetTickInterval()}
+	if opts.Gzip {
+		r.gzip = gzip.NewWriter(w)
+		w = r.gzip
+	}
+	r.buffer = bufio.NewWriter(w)
+	r.csv = csv.NewWriter(r.buffer)
+	if err := r.csv.Write(r.columns); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// HandleTick writes a row for every plant. After a write error nothing more
+// is written and Close reports the error.
+func (r *runRecorder) HandleTick(t ExportedTick) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed || r.err != nil {
+		return r.err
+	}
+	row := make([]string, len(r.columns))
+	for i := range t.Plants {
+		for j, column := range r.columns {
+			row[j] = r.field(column, t.Tick, &t.Plants[i])
+		}
+		if err := r.csv.Write(row); err != nil {
+			r.err = err
+			return err
+		}
+		r.rows++
+	}
+	r.simTime += r.interval
+	return nil
+}
+
+// HandleReading does nothing: only plants are recorded.
+func (r *runRecorder) HandleReading(ExportedReading) error { return nil }
+
+// HandleEvent does nothing: only plants are recorded.
+func (r *runRecorder) HandleEvent(events.Event) error { return nil }
+
+func (r *runRecorder) field(column string, tick int, plant *models.Plant) string {
+	switch column {
+	case "tick":
+		return strconv.Itoa(tick)
+	case "sim_time":
+		return formatFloat(r.simTime.Seconds())
+	case "plant_id":
+		return plant.ID
+	case "section":
+		return plant.SectionID
+	case "type":
+		return plant.Type.Name
+	case "health":
+		return formatFloat(plant.Health)
+	case "growth":
+		return formatFloat(plant.GrowthStage)
+	case "saturation":
+		return formatFloat(plant.SoilSaturation)
+	case "alive":
+		return strconv.FormatBool(plant.Alive)
+	}
+	return ""
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// Flush hands the buffered rows to the CSV writer's buffer. They reach the
+// underlying writer as the buffer fills and on Close.
+func (r *runRecorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.csv.Flush()
+	return r.csv.Error()
+}
+
+// Close flushes the rows and finishes the gzip stream. Returns the first error
+// met while recording or flushing. Calling Close again does nothing.
+// This method is safe for concurrent use.
+func (r *runRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	r.csv.Flush()
+	if err := r.csv.Error(); err != nil && r.err == nil {
+		r.err = err
+	}
+	if err := r.buffer.Flush(); err != nil && r.err == nil {
+		r.err = err
+	}
+	if r.gzip != nil {
+		if err := r.gzip.Close(); err != nil && r.err == nil {
+			r.err = err
+		}
+	}
+	return r.err
+}
+
+// Rows returns how many data rows were written.
+// This method is safe for concurrent use.
+func (r *runRecorder) Rows() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rows
+}
@@ -0,0 +1,129 @@
+package greenhouse
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"reflect"
+	"testing"
+)
+
+func TestRunScenario_Record(t *testing.T) {
+	cfg := testConfig()
+	cfg.Plants = nil
+	for i := range 50 {
+		cfg.Plants = append(cfg.Plants, config.PlantConfig{ID: fmt.Sprintf("basil-%02d", i), Type: "Basil", SectionID: "section-A", InitialSaturation: 0.5})
+	}
+	var out bytes.Buffer
+	if _, err := RunScenario(cfg, 100, ScenarioOptions{Record: &out, RecordOptions: RecordOptions{Gzip: true}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reader, err := gzip.NewReader(&out)
+	if err != nil {
+		t.Fatalf("expected gzip output: %v", err)
+	}
+	rows, err := csv.NewReader(reader).ReadAll()
+	if err != nil {
+		t.Fatalf("expected CSV output: %v", err)
+	}
+	if len(rows) != 5001 {
+		t.Fatalf("expected a header and 5000 rows, got %d rows", len(rows))
+	}
+	if !reflect.DeepEqual(rows[0], RecordColumns) {
+		t.Errorf("expected header %v, got %v", RecordColumns, rows[0])
+	}
+	first, last := rows[1], rows[5000]
+	if first[0] != "0" || first[1] != "0" || first[2] != "basil-00" || first[3] != "section-A" || first[4] != "Basil" {
+		t.Errorf("expected basil-00 on tick 0 first, got %v", first)
+	}
+	// The test config ticks every second.
+	if last[0] != "99" || last[1] != "99" || last[2] != "basil-49" {
+		t.Errorf("expected basil-49 on tick 99 at 99s last, got %v", last)
+	}
+}
+
+func TestRunRecorder_Columns(t *testing.T) {
+	tests := []struct {
+		name     string
+		columns  []string
+		expected [][]string
+		errorMsg string
+	}{
+		{
+			name:     "selected columns in the given order",
+			columns:  []string{"alive", "plant_id", "tick"},
+			expected: [][]string{{"alive", "plant_id", "tick"}, {"true", "basil-1", "0"}, {"true", "basil-2", "0"}},
+		},
+		{name: "unknown column", columns: []string{"tick", "colour"}, errorMsg: "unknown record column: colour"},
+		{name: "duplicate column", columns: []string{"tick", "tick"}, errorMsg: "record column selected twice: tick"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, _ := newTestGreenhouse(t)
+			var out bytes.Buffer
+			recorder, err := NewRunRecorder(g, &out, RecordOptions{Columns: tt.columns})
+			if tt.errorMsg != "" {
+				if err == nil || err.Error() != tt.errorMsg {
+					t.Fatalf("expected error %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := g.Exporters().Register("record", recorder, 0); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			g.Simulator().Step()
+			if err := g.Exporters().Remove("record"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// Ticks after the recorder is removed are not recorded.
+			g.Simulator().Step()
+			rows, err := csv.NewReader(&out).ReadAll()
+			if err != nil {
+				t.Fatalf("expected CSV output: %v", err)
+			}
+			if !reflect.DeepEqual(rows, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, rows)
+			}
+			if recorder.Rows() != 2 {
+				t.Errorf("expected 2 rows, got %d", recorder.Rows())
+			}
+		})
+	}
+}
+
+// failingWriter accepts limit bytes, then fails.
+type failingWriter struct {
+	limit int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		return 0, errors.New("disk full")
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+func TestRunRecorder_ReportsWriteErrors(t *testing.T) {
+	g, _ := newTestGreenhouse(t)
+	recorder, err := NewRunRecorder(g, &failingWriter{limit: 100}, RecordOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := g.Exporters().Register("record", recorder, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for range 100 {
+		g.Simulator().Step()
+	}
+	if err := g.Exporters().Remove("record"); err == nil || err.Error() != "disk full" {
+		t.Errorf("expected the write error, got %v", err)
+	}
+}
@@ -0,0 +1,394 @@
+package greenhouse
+
+import (
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"greenhouse-simulator/internal/sensors"
+	"greenhouse-simulator/internal/watering"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// ReloadSummary lists what a config reload changed, by ID.
+type ReloadSummary struct {
+	AddedPlants      []string
+	AddedSensors     []string
+	RemovedSensors   []string
+	AddedSchedules   []string
+	UpdatedSchedules []string
+	RemovedSchedules []string
+}
+
+// Empty reports whether the reload changed nothing.
+func (s ReloadSummary) Empty() bool {
+	return len(s.AddedPlants)+len(s.AddedSensors)+len(s.RemovedSensors)+
+		len(s.AddedSchedules)+len(s.UpdatedSchedules)+len(s.RemovedSchedules) == 0
+}
+
+// ReloadConfig applies cfg to the running greenhouse without losing plant
+// state. Safe changes are applied live:
+//   - new plants and sensors are added
+//   - plant tags are updated; the watering and quarantine flags of live
+//     plants are kept, since they are changed at runtime
+//   - schedules are added, updated or removed to match cfg; the config is the
+//     source of truth, so schedules added at runtime are removed too
+//   - sensors missing from cfg are removed
+//   - plant type definitions apply to plants added from now on
+//   - prices apply to what is used from the next tick on; the cost ledger
+//     keeps what was charged before
+//   - the pruning effect applies to plants pruned from now on
+//   - the dead plant retention applies from the next tick on
+//   - the overrun policy applies from the next tick on
+//   - the dead section policy applies from the next sensor reading on
+//   - the sensor history lookup applies from the next lookup on
+//   - strict sensor filters apply to the sensors added from now on
+//   - the slow sensor read threshold applies from the next reading on
+//   - changed microclimates replace the live ones, runtime changes included
+//   - changed rotation plans replace the live ones and start over from their
+//     first crop; plans missing from cfg are dropped
+//   - the alert rules apply from the next tick on
+//
+// Plants added or removed at runtime, by the timeline or through AddPlant and
+// RemovePlant, stay that way.
+//
+// Destructive changes are refused before anything is applied, leaving the
+// running state untouched. Returns an error if:
+//   - cfg is invalid, or one of its schedules or policies is
+//   - a live plant is missing from cfg, or its type or section changed
+//   - cfg has germinating plant types and the running config has none
+//   - the tick interval, seed, invariant checks, environment, section soils, zones, grow lights,
+//     HVAC, disease, salinity, soil temperature, light competition, chaos, journal, tank, MQTT, server,
+//     InfluxDB, tracing or export settings or the timeline changed
+//
+// A ConfigReloaded event carrying the summary is published on success.
+// This method is safe for concurrent use.
+func (g *greenhouse) ReloadConfig(cfg *config.GreenhouseConfig) (ReloadSummary, error) {
+	var summary ReloadSummary
+	if err := cfg.Validate(); err != nil {
+		return summary, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if cfg.TickInterval != g.config.TickInterval {
+		return summary, errors.New("tick interval cannot change while the simulation runs")
+	}
+	if cfg.Seed != g.config.Seed {
+		return summary, errors.New("seed cannot change while the simulation runs")
+	}
+	if cfg.Invariants != g.config.Invariants {
+		return summary, errors.New("invariant checks cannot change while the simulation runs")
+	}
+	if cfg.Environment != g.config.Environment {
+		return summary, errors.New("environment settings cannot change while the simulation runs")
+	}
+	if !reflect.DeepEqual(cfg.Sections, g.config.Sections) {
+		return summary, errors.New("section soils cannot change while the simulation runs")
+	}
+	if !reflect.DeepEqual(cfg.Zones, g.config.Zones) {
+		return summary, errors.New("zones cannot change while the simulation runs")
+	}
+	if !reflect.DeepEqual(cfg.Lights, g.config.Lights) {
+		return summary, errors.New("grow lights cannot change while the simulation runs")
+	}
+	if !reflect.DeepEqual(cfg.HVAC, g.config.HVAC) {
+		return summary, errors.New("hvac settings cannot change while the simulation runs")
+	}
+	if cfg.Germinates() && !g.config.Germinates() {
+		return summary, errors.New("germinating plant types cannot be added while the simulation runs")
+	}
+	if !reflect.DeepEqual(cfg.Disease, g.config.Disease) {
+		return summary, errors.New("disease settings cannot change while the simulation runs")
+	}
+	if !reflect.DeepEqual(cfg.Salinity, g.config.Salinity) {
+		return summary, errors.New("salinity settings cannot change while the simulation runs")
+	}
+	if !reflect.DeepEqual(cfg.SoilTemperature, g.config.SoilTemperature) {
+		return summary, errors.New("soil temperature settings cannot change while the simulation runs")
+	}
+	if !reflect.DeepEqual(cfg.WaterTemperature, g.config.WaterTemperature) {
+		return summary, errors.New("water temperature settings cannot change while the simulation runs")
+	}
+	if !reflect.DeepEqual(cfg.LightCompetition, g.config.LightCompetition) {
+		return summary, errors.New("light competition settings cannot change while the simulation runs")
+	}
+	if !reflect.DeepEqual(cfg.Chaos, g.config.Chaos) {
+		return summary, errors.New("chaos settings cannot change while the simulation runs")
+	}
+	if !reflect.DeepEqual(cfg.Journal, g.config.Journal) {
+		return summary, errors.New("journal settings cannot change while the simulation runs")
+	}
+	if cfg.WateringDedupWindow != g.config.WateringDedupWindow {
+		return summary, errors.New("watering dedup window cannot change while the simulation runs")
+	}
+	if cfg.IdlePauseTicks != g.config.IdlePauseTicks {
+		return summary, errors.New("idle pause ticks cannot change while the simulation runs")
+	}
+	if !reflect.DeepEqual(cfg.Tank, g.config.Tank) {
+		return summary, errors.New("tank settings cannot change while the simulation runs")
+	}
+	if !reflect.DeepEqual(cfg.Watering, g.config.Watering) {
+		return summary, errors.New("watering settings cannot change while the simulation runs")
+	}
+	if !reflect.DeepEqual(cfg.MQTT, g.config.MQTT) {
+		return summary, errors.New("mqtt settings cannot change while the simulation runs")
+	}
+	if !reflect.DeepEqual(cfg.Server, g.config.Server) {
+		return summary, errors.New("server settings cannot change while the simulation runs")
+	}
+	if !reflect.DeepEqual(cfg.Influx, g.config.Influx) {
+		return summary, errors.New("influx settings cannot change while the simulation runs")
+	}
+	if !reflect.DeepEqual(cfg.Tracing, g.config.Tracing) {
+		return summary, errors.New("tracing settings cannot change while the simulation runs")
+	}
+	if !reflect.DeepEqual(cfg.Export, g.config.Export) {
+		return summary, errors.New("export settings cannot change while the simulation runs")
+	}
+	if !reflect.DeepEqual(cfg.Timeline, g.config.Timeline) {
+		return summary, errors.New("timeline cannot change while the simulation runs")
+	}
+
+	plants, err := cfg.BuildPlants()
+	if err != nil {
+		return summary, err
+	}
+	configured := map[string]*models.Plant{}
+	for _, plant := range plants {
+		configured[plant.ID] = plant
+	}
+	live := map[string]*models.Plant{}
+	for _, plant := range g.sim.GetAllPlants() {
+		live[plant.ID] = plant
+	}
+	for _, id := range slices.Sorted(maps.Keys(live)) {
+		plant, next := live[id], configured[id]
+		switch {
+		case next == nil && g.runtimeAdded[id]:
+			continue
+		case next == nil:
+			return summary, errors.New("cannot remove live plant: " + id)
+		case next.Type.Name != plant.Type.Name:
+			return summary, fmt.Errorf("cannot change the type of live plant %s from %s to %s", id, plant.Type.Name, next.Type.Name)
+		case next.SectionID != plant.SectionID:
+			return summary, fmt.Errorf("cannot move live plant %s from %s to %s", id, plant.SectionID, next.SectionID)
+		}
+	}
+
+	// Validate every schedule on a scratch controller, which also derives
+	// their IDs, before touching the live one, and the policies on scratch
+	// pacer and sensor manager, so that nothing below fails halfway through.
+	probe := watering.NewController(nil, nil, watering.Config{DayCycle: cfg.DayCycle()})
+	for _, schedule := range cfg.WateringSchedules() {
+		if err := probe.AddSchedule(schedule); err != nil {
+			return summary, fmt.Errorf("invalid schedule: %w", err)
+		}
+	}
+	if err := cfg.PruneEffect().Validate(); err != nil {
+		return summary, err
+	}
+	if err := engine.NewPacer().SetOverrunPolicy(cfg.TickOverrunPolicy()); err != nil {
+		return summary, err
+	}
+	policies := sensors.NewSensorManager(nil, nil, nil)
+	if err := policies.SetDeadSectionPolicy(cfg.DeadSectionPolicy()); err != nil {
+		return summary, err
+	}
+	if err := policies.SetHistoryLookup(cfg.SensorHistoryLookup()); err != nil {
+		return summary, err
+	}
+
+	var added []*models.Plant
+	for _, plant := range plants {
+		if live[plant.ID] == nil && !g.runtimeRemoved[plant.ID] {
+			added = append(added, plant)
+		}
+	}
+	if err := g.sim.AddPlants(added); err != nil {
+		return summary, err
+	}
+	// A live plant removed since its snapshot was taken has no tags to update.
+	for _, plant := range plants {
+		if live[plant.ID] == nil {
+			continue
+		}
+		if err := g.sim.SetPlantTags(plant.ID, plant.Tags); err != nil && !errors.Is(err, engine.ErrPlantNotFound) {
+			return summary, err
+		}
+	}
+	for _, plant := range added {
+		summary.AddedPlants = append(summary.AddedPlants, plant.ID)
+	}
+	g.sensors.SetStrictFilters(cfg.StrictSensorFilters)
+	g.sensors.SetSlowReadThreshold(time.Duration(cfg.SlowSensorRead), nil)
+	g.reloadSensors(cfg, &summary)
+	if !reflect.DeepEqual(cfg.Microclimates, g.config.Microclimates) {
+		g.weather.mu.Lock()
+		g.weather.offsets = cfg.ClimateOffsets()
+		g.weather.mu.Unlock()
+	}
+	g.reloadRotations(cfg)
+	if err := g.sim.SetPruneEffect(cfg.PruneEffect()); err != nil {
+		return summary, err
+	}
+	if err := g.sim.SetOverrunPolicy(cfg.TickOverrunPolicy()); err != nil {
+		return summary, err
+	}
+	if err := g.sensors.SetDeadSectionPolicy(cfg.DeadSectionPolicy()); err != nil {
+		return summary, err
+	}
+	if err := g.sensors.SetHistoryLookup(cfg.SensorHistoryLookup()); err != nil {
+		return summary, err
+	}
+	if err := g.reloadSchedules(probe.Snapshot().Schedules, &summary); err != nil {
+		return summary, err
+	}
+	g.config = cfg
+
+	g.bus.Publish(events.Event{
+		Type:      events.ConfigReloaded,
+		Tick:      g.sim.GetCurrentTick(),
+		Timestamp: time.Now(),
+		Payload:   summary,
+	})
+	return summary, nil
+}
+
+// reloadRotations replaces the rotation plans that changed in cfg and drops
+// those it no longer has. Callers must hold g.mu.
+func (g *greenhouse) reloadRotations(cfg *config.GreenhouseConfig) {
+	previous := map[string]config.RotationConfig{}
+	for _, plan := range g.config.Rotations {
+		previous[plan.SectionID] = plan
+	}
+	for _, plan := range cfg.Rotations {
+		if old, ok := previous[plan.SectionID]; !ok || !reflect.DeepEqual(old, plan) {
+			g.rotations.set(plan)
+		}
+		delete(previous, plan.SectionID)
+	}
+	for sectionID := range previous {
+		g.rotations.remove(sectionID)
+	}
+}
+
+// reloadSensors replaces sensors that were removed or changed in cfg and adds
+// new ones. Callers must hold g.mu.
+func (g *greenhouse) reloadSensors(cfg *config.GreenhouseConfig, summary *ReloadSummary) {
+	next := map[string]config.SensorConfig{}
+	for _, sensor := range cfg.Sensors {
+		next[sensor.ID] = sensor
+	}
+	current := map[string]config.SensorConfig{}
+	for _, sensor := range g.config.Sensors {
+		current[sensor.ID] = sensor
+		if updated, ok := next[sensor.ID]; !ok || !reflect.DeepEqual(updated, sensor) {
+			if g.sensors.RemoveSensor(sensor.ID) == nil {
+				summary.RemovedSensors = append(summary.RemovedSensors, sensor.ID)
+			}
+		}
+	}
+	for _, sensor := range cfg.Sensors {
+		if existing, ok := current[sensor.ID]; ok && reflect.DeepEqual(existing, sensor) {
+			continue
+		}
+		added, err := sensor.Sensor()
+		if err != nil {
+			continue
+		}
+		if g.sensors.AddSensor(added) == nil {
+			summary.AddedSensors = append(summary.AddedSensors, sensor.ID)
+		}
+	}
+}
+
+// reloadSchedules makes the live schedules match the validated schedules.
+// Callers must hold g.mu.
+func (g *greenhouse) reloadSchedules(schedules []models.WateringSchedule, summary *ReloadSummary) error {
+	next := map[string]models.WateringSchedule{}
+	for _, schedule := range schedules {
+		next[schedule.ID] = schedule
+	}
+	current := map[string]models.WateringSchedule{}
+	for _, schedule := range g.watering.Snapshot().Schedules {
+		current[schedule.ID] = schedule
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(current)) {
+		if _, ok := next[id]; ok {
+			continue
+		}
+		if err := g.watering.RemoveSchedule(id); err != nil {
+			return err
+		}
+		summary.RemovedSchedules = append(summary.RemovedSchedules, id)
+	}
+	for _, id := range slices.Sorted(maps.Keys(next)) {
+		schedule := next[id]
+		existing, ok := current[id]
+		switch {
+		case !ok:
+			if err := g.watering.AddSchedule(schedule); err != nil {
+				return err
+			}
+			summary.AddedSchedules = append(summary.AddedSchedules, id)
+		case !reflect.DeepEqual(existing, schedule):
+			if err := g.watering.UpdateSchedule(schedule); err != nil {
+				return err
+			}
+			summary.UpdatedSchedules = append(summary.UpdatedSchedules, id)
+		}
+	}
+	return nil
+}
+
+// WatchConfig polls the config file every interval and reloads it when its
+// modification time changes, until stop is closed. Load and reload errors are
+// passed to onError, which may be nil, and the running state is kept.
+// It blocks, so callers usually run it on its own goroutine.
+func (g *greenhouse) WatchConfig(path string, interval time.Duration, stop <-chan struct{}, onError func(error)) {
+	report := func(err error) {
+		if onError != nil {
+			onError(err)
+		}
+	}
+	var lastModified time.Time
+	if info, err := os.Stat(path); err == nil {
+		lastModified = info.ModTime()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			info, err := os.Stat(path)
+			if err != nil {
+				report(err)
+				continue
+			}
+			if !info.ModTime().After(lastModified) {
+				continue
+			}
+			lastModified = info.ModTime()
+			cfg, err := config.LoadConfig(path)
+			if err != nil {
+				report(err)
+				continue
+			}
+			if _, err := g.ReloadConfig(cfg); err != nil {
+				report(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,359 @@
+package greenhouse
+
+import (
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/events"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// testConfig returns a greenhouse with two dry plants in section-A and a
+// schedule whose target is too low to ever trigger.
+func testConfig() *config.GreenhouseConfig {
+	return &config.GreenhouseConfig{
+		TickInterval: config.Duration(time.Second),
+		PlantTypes: []config.PlantTypeConfig{
+			{Name: "Basil", OptimalSaturation: 0.6, MinSaturation: 0.3, MaxSaturation: 0.8, SaturationDepletion: 0.01},
+			{Name: "Mint", OptimalSaturation: 0.7, MinSaturation: 0.4, MaxSaturation: 0.9, SaturationDepletion: 0.01},
+		},
+		Plants: []config.PlantConfig{
+			{ID: "basil-1", Type: "Basil", SectionID: "section-A", InitialSaturation: 0.4},
+			{ID: "basil-2", Type: "Basil", SectionID: "section-A", InitialSaturation: 0.4},
+		},
+		Sensors: []config.SensorConfig{
+			{ID: "sensor-1", Type: "soil_moisture", SectionID: "section-A"},
+		},
+		Schedules: []config.ScheduleConfig{
+			{SectionID: "section-A", TargetSaturation: 0.1, CheckInterval: 5, WaterAmount: 0.2, Enabled: true},
+		},
+	}
+}
+
+func newTestGreenhouse(t *testing.T) (Greenhouse, *[]events.Event) {
+	t.Helper()
+	g, err := New(testConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	var published []events.Event
+	g.Bus().Subscribe(func(e events.Event) {
+		published = append(published, e)
+	})
+	return g, &published
+}
+
+func startTicks(published []events.Event) []int {
+	var ticks []int
+	for _, e := range published {
+		if e.Type == events.WateringStarted {
+			ticks = append(ticks, e.Tick)
+		}
+	}
+	return ticks
+}
+
+func TestReloadConfig_UpdatedTargetAppliesAtNextCheck(t *testing.T) {
+	g, published := newTestGreenhouse(t)
+	for range 7 {
+		g.Simulator().Step()
+	}
+
+	cfg := testConfig()
+	cfg.Schedules[0].TargetSaturation = 0.9
+	summary, err := g.ReloadConfig(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(summary.UpdatedSchedules, []string{"section-A"}) {
+		t.Errorf("expected section-A schedule to be updated, got %+v", summary)
+	}
+
+	// Ticks 7 to 9 run; the next check is at tick 10
+	for range 4 {
+		g.Simulator().Step()
+	}
+	if got := startTicks(*published); !slices.Equal(got, []int{10}) {
+		t.Errorf("expected watering to start at the next check on tick 10, got %v", got)
+	}
+	if g.Config() != cfg {
+		t.Errorf("expected the reloaded config to become current")
+	}
+}
+
+func TestReloadConfig_AddsAndRemoves(t *testing.T) {
+	g, published := newTestGreenhouse(t)
+	g.Simulator().Step()
+
+	cfg := testConfig()
+	cfg.PlantTypes[0].BaseGrowthRate = 0.2
+	cfg.Plants = append(cfg.Plants, config.PlantConfig{ID: "mint-1", Type: "Mint", SectionID: "section-B", InitialSaturation: 0.5})
+	cfg.Sensors = []config.SensorConfig{{ID: "sensor-2", Type: "soil_moisture", SectionID: "section-B"}}
+	cfg.Schedules = []config.ScheduleConfig{{SectionID: "section-B", TargetSaturation: 0.5, CheckInterval: 2, WaterAmount: 0.1, Enabled: true}}
+
+	summary, err := g.ReloadConfig(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := ReloadSummary{
+		AddedPlants:      []string{"mint-1"},
+		AddedSensors:     []string{"sensor-2"},
+		RemovedSensors:   []string{"sensor-1"},
+		AddedSchedules:   []string{"section-B"},
+		RemovedSchedules: []string{"section-A"},
+	}
+	if !slices.Equal(summary.AddedPlants, expected.AddedPlants) ||
+		!slices.Equal(summary.AddedSensors, expected.AddedSensors) ||
+		!slices.Equal(summary.RemovedSensors, expected.RemovedSensors) ||
+		!slices.Equal(summary.AddedSchedules, expected.AddedSchedules) ||
+		!slices.Equal(summary.RemovedSchedules, expected.RemovedSchedules) {
+		t.Errorf("expected summary %+v, got %+v", expected, summary)
+	}
+	if _, err := g.Sensors().GetReading("sensor-2"); err != nil {
+		t.Errorf("expected the new sensor to read the new plant, got %v", err)
+	}
+	if _, err := g.Sensors().GetReading("sensor-1"); err == nil {
+		t.Error("expected the removed sensor to be gone")
+	}
+	// The live basil plants keep the type definition they were created with
+	for _, plant := range g.Simulator().GetPlantsBySectionID("section-A") {
+		if plant.Type.BaseGrowthRate != 0 {
+			t.Errorf("%s: expected live plant type to be unchanged, got growth rate %.2f", plant.ID, plant.Type.BaseGrowthRate)
+		}
+	}
+	if (*published)[len(*published)-1].Type != events.ConfigReloaded {
+		t.Errorf("expected a config reloaded event")
+	}
+}
+
+func TestReloadConfig_RefusesDestructiveChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(cfg *config.GreenhouseConfig)
+		errorMsg string
+	}{
+		{"remove plant", func(cfg *config.GreenhouseConfig) { cfg.Plants = cfg.Plants[:1] }, "cannot remove live plant: basil-2"},
+		{"change plant type", func(cfg *config.GreenhouseConfig) { cfg.Plants[0].Type = "Mint" }, "cannot change the type of live plant basil-1 from Basil to Mint"},
+		{"move plant", func(cfg *config.GreenhouseConfig) { cfg.Plants[1].SectionID = "section-B" }, "cannot move live plant basil-2 from section-A to section-B"},
+		{"tick interval", func(cfg *config.GreenhouseConfig) { cfg.TickInterval = config.Duration(time.Minute) }, "tick interval cannot change while the simulation runs"},
+		{"seed", func(cfg *config.GreenhouseConfig) { cfg.Seed++ }, "seed cannot change while the simulation runs"},
+		{"section soil", func(cfg *config.GreenhouseConfig) {
+			cfg.Sections = append(cfg.Sections, config.SectionConfig{ID: "section-A", Soil: "Clay"})
+		}, "section soils cannot change while the simulation runs"},
+		{"grow lights", func(cfg *config.GreenhouseConfig) {
+			cfg.Lights = append(cfg.Lights, config.LightsConfig{SectionID: "section-A", Intensity: 0.5})
+		}, "grow lights cannot change while the simulation runs"},
+		{"hvac", func(cfg *config.GreenhouseConfig) { cfg.HVAC = &config.HVACConfig{HeaterPower: 1} }, "hvac settings cannot change while the simulation runs"},
+		{"soil temperature", func(cfg *config.GreenhouseConfig) {
+			cfg.SoilTemperature = &config.SoilTemperatureConfig{Inertia: 0.9}
+		}, "soil temperature settings cannot change while the simulation runs"},
+		{"water temperature", func(cfg *config.GreenhouseConfig) {
+			cfg.WaterTemperature = &config.WaterTemperatureConfig{Inertia: 0.9}
+		}, "water temperature settings cannot change while the simulation runs"},
+		{"journal", func(cfg *config.GreenhouseConfig) { cfg.Journal = &config.JournalConfig{} }, "journal settings cannot change while the simulation runs"},
+		{"watering dedup window", func(cfg *config.GreenhouseConfig) { cfg.WateringDedupWindow = 5 }, "watering dedup window cannot change while the simulation runs"},
+		{"watering settings", func(cfg *config.GreenhouseConfig) {
+			cfg.Watering = &config.WateringConfig{MaxConcurrentEvents: 1}
+		}, "watering settings cannot change while the simulation runs"},
+		{"idle pause ticks", func(cfg *config.GreenhouseConfig) { cfg.IdlePauseTicks = 100 }, "idle pause ticks cannot change while the simulation runs"},
+		{"invalid schedule", func(cfg *config.GreenhouseConfig) { cfg.Schedules[0].CheckInterval = 0 }, "invalid schedule: schedule check interval must be at least one tick"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, _ := newTestGreenhouse(t)
+			before := g.Watering().Snapshot()
+			cfg := testConfig()
+			cfg.Sensors = nil
+			tt.modify(cfg)
+
+			_, err := g.ReloadConfig(cfg)
+
+			if err == nil {
+				t.Fatal("expected error but got nil")
+			}
+			if err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+			}
+			if _, err := g.Sensors().GetReading("sensor-1"); err != nil {
+				t.Errorf("expected a refused reload to leave sensors untouched, got %v", err)
+			}
+			if len(g.Watering().Snapshot().Schedules) != len(before.Schedules) {
+				t.Errorf("expected a refused reload to leave schedules untouched")
+			}
+		})
+	}
+}
+
+func TestWatchConfig_ReloadsOnChange(t *testing.T) {
+	g, _ := newTestGreenhouse(t)
+	path := filepath.Join(t.TempDir(), "greenhouse.yaml")
+	if err := config.SaveConfig(testConfig(), path); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	errs := make(chan error, 10)
+	go func() {
+		g.WatchConfig(path, 5*time.Millisecond, stop, func(err error) { errs <- err })
+		close(done)
+	}()
+
+	// Let the watcher record the initial modification time
+	time.Sleep(50 * time.Millisecond)
+
+	cfg := testConfig()
+	cfg.Plants = append(cfg.Plants, config.PlantConfig{ID: "basil-3", Type: "Basil", SectionID: "section-A", InitialSaturation: 0.5})
+	if err := config.SaveConfig(cfg, path); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	// Make sure the change is visible even with a coarse modification time
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("failed to touch config: %v", err)
+	}
+
+	deadline := time.After(2 * time.Second)
+	for len(g.Simulator().GetAllPlants()) != 3 {
+		select {
+		case err := <-errs:
+			t.Fatalf("unexpected reload error: %v", err)
+		case <-deadline:
+			t.Fatal("timed out waiting for the config to be reloaded")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	close(stop)
+	<-done
+
+	if len(g.Config().Plants) != 3 {
+		t.Errorf("expected the watched config to become current")
+	}
+}
+
+func TestExportScenario_ReloadsIntoFreshGreenhouse(t *testing.T) {
+	cfg := testConfig()
+	cfg.Environment = config.EnvironmentConfig{TicksPerDay: 24, AmbientHumidity: 0.4, HumidityDecay: 0.1}
+	cfg.Tank = &config.TankConfig{Capacity: 10, InitialLevel: 8}
+	cfg.Schedules[0].TargetSaturation = 0.9
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	for range 12 {
+		g.Simulator().Step()
+	}
+
+	exported, err := g.ExportScenario(config.ExportOptions{ExactResume: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "scenario.yaml")
+	if err := config.SaveConfig(exported, path); err != nil {
+		t.Fatalf("failed to save scenario: %v", err)
+	}
+	loaded, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("failed to load scenario: %v", err)
+	}
+	resumed, err := New(loaded)
+	if err != nil {
+		t.Fatalf("failed to build resumed greenhouse: %v", err)
+	}
+
+	if loaded.Environment != cfg.Environment {
+		t.Errorf("expected environment %+v, got %+v", cfg.Environment, loaded.Environment)
+	}
+	if level := g.Watering().Snapshot().SupplyLevel; level >= 8 || resumed.Watering().Snapshot().SupplyLevel != level {
+		t.Errorf("expected the tank to resume at the drawn level %.2f, got %.2f", level, resumed.Watering().Snapshot().SupplyLevel)
+	}
+	for _, plant := range g.Simulator().GetAllPlants() {
+		for _, other := range resumed.Simulator().GetAllPlants() {
+			if other.ID == plant.ID && (other.SoilSaturation != plant.SoilSaturation || other.Health != plant.Health || other.GrowthStage != plant.GrowthStage) {
+				t.Errorf("%s: expected resumed plant %v to match %v", plant.ID, other, plant)
+			}
+		}
+	}
+}
+
+func TestReloadConfig_KeepsRuntimePlantChanges(t *testing.T) {
+	g, _ := newTestGreenhouse(t)
+
+	plant, err := g.AddPlant(config.PlantConfig{ID: "mint-1", Type: "Mint", SectionID: "section-B", InitialSaturation: 0.5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plant.Type.Name != "Mint" || plant.SoilSaturation != 0.5 {
+		t.Errorf("expected a Mint plant built from the config types, got %+v", plant)
+	}
+	if _, err := g.AddPlant(config.PlantConfig{ID: "mint-1", Type: "Mint", SectionID: "section-B", InitialSaturation: 0.5}); !errors.Is(err, engine.ErrPlantExists) {
+		t.Errorf("expected ErrPlantExists, got %v", err)
+	}
+	if err := g.RemovePlant("basil-2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := g.RemovePlant("basil-2"); !errors.Is(err, engine.ErrPlantNotFound) {
+		t.Errorf("expected ErrPlantNotFound, got %v", err)
+	}
+
+	summary, err := g.ReloadConfig(testConfig())
+	if err != nil {
+		t.Fatalf("expected the reload to keep the runtime changes, got %v", err)
+	}
+	if !summary.Empty() {
+		t.Errorf("expected nothing to change, got %+v", summary)
+	}
+	var ids []string
+	for _, plant := range g.Simulator().GetAllPlants() {
+		ids = append(ids, plant.ID)
+	}
+	slices.Sort(ids)
+	if !slices.Equal(ids, []string{"basil-1", "mint-1"}) {
+		t.Errorf("expected basil-1 and mint-1, got %v", ids)
+	}
+}
+
+// The reader stands for the API and exporters, which read plants without
+// going through the greenhouse.
+func TestReloadConfig_UpdatesTagsWhileRead(t *testing.T) {
+	g, _ := newTestGreenhouse(t)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			g.Simulator().GetPlant("basil-1")
+		}
+	}()
+	var cfg *config.GreenhouseConfig
+	for i := range 500 {
+		cfg = testConfig()
+		cfg.Plants[0].Tags = []string{fmt.Sprintf("herbs-%d", i)}
+		if _, err := g.ReloadConfig(cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	close(stop)
+	<-done
+
+	cfg.Plants[0].Tags[0] = "changed"
+	plant, err := g.Simulator().GetPlant("basil-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(plant.Tags, []string{"herbs-499"}) {
+		t.Errorf("expected the last reloaded tags on the plant, got %v", plant.Tags)
+	}
+}
@@ -0,0 +1,738 @@
+package greenhouse
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrReplay is returned when changing the plants of a replayed greenhouse.
+var ErrReplay = errors.New("plants cannot change during a replay")
+
+// PlantState is the recorded state of a plant on a tick. Type is the plant
+// type name, empty when the recording does not have it.
+type PlantState struct {
+	ID         string  `json:"id"`
+	SectionID  string  `json:"section"`
+	Type       string  `json:"type,omitempty"`
+	Health     float64 `json:"health"`
+	Growth     float64 `json:"growth"`
+	Saturation float64 `json:"saturation"`
+	Alive      bool    `json:"alive"`
+}
+
+// Frame is the recorded state of every plant on a tick.
+type Frame struct {
+	Tick   int
+	Plants []PlantState
+}
+
+// Recording is a recorded run: its frames ordered by tick. Ticks missing from
+// a recording are gaps, see GapPolicy.
+type Recording struct {
+	Frames []Frame
+}
+
+// ReadRecording reads a recording from the CSV a RunRecorder writes, gzipped
+// or not. Rows may come in any order; the rows of a tick make up its frame,
+// with its plants ordered by ID. Without the health, growth and alive
+// columns, plants are recorded alive and in full health, with no growth.
+// Returns an error if:
+// - the input is not CSV, or not valid gzip when it starts like gzip
+// - the tick, plant_id, section or saturation column is missing
+// - a number or boolean cannot be parsed
+// - a plant is recorded twice on the same tick
+func ReadRecording(r io.Reader) (*Recording, error) {
+	buffered := bufio.NewReader(r)
+	if magic, _ := buffered.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = buffered
+	}
+
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("recording header: %w", err)
+	}
+	index := map[string]int{}
+	for i, column := range header {
+		index[column] = i
+	}
+	for _, column := range []string{"tick", "plant_id", "section", "saturation"} {
+		if _, ok := index[column]; !ok {
+			return nil, errors.New("recording is missing the column: " + column)
+		}
+	}
+
+	frames := map[int]*Frame{}
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		tick, state, err := parseRecordRow(row, index)
+		if err != nil {
+			return nil, fmt.Errorf("recording line %d: %w", line, err)
+		}
+		frame := frames[tick]
+		if frame == nil {
+			frame = &Frame{Tick: tick}
+			frames[tick] = frame
+		}
+		if slices.ContainsFunc(frame.Plants, func(other PlantState) bool { return other.ID == state.ID }) {
+			return nil, fmt.Errorf("recording line %d: plant %s recorded twice on tick %d", line, state.ID, tick)
+		}
+		frame.Plants = append(frame.Plants, state)
+	}
+
+	recording := &Recording{}
+	for _, frame := range frames {
+		slices.SortFunc(frame.Plants, func(a, b PlantState) int { return strings.Compare(a.ID, b.ID) })
+		recording.Frames = append(recording.Frames, *frame)
+	}
+	slices.SortFunc(recording.Frames, func(a, b Frame) int { return a.Tick - b.Tick })
+	return recording, nil
+}
+
+// parseRecordRow parses a data row given the index of each header column.
+func parseRecordRow(row []string, index map[string]int) (int, PlantState, error) {
+	field := func(column string) (string, bool) {
+		i, ok := index[column]
+		if !ok || i >= len(row) {
+			return "", false
+		}
+		return row[i], true
+	}
+	number := func(column string, value *float64) error {
+		text, ok := field(column)
+		if !ok {
+			return nil
+		}
+		parsed, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return errors.New("invalid " + column + ": " + text)
+		}
+		*value = parsed
+		return nil
+	}
+
+	text, _ := field("tick")
+	tick, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, PlantState{}, errors.New("invalid tick: " + text)
+	}
+	state := PlantState{Health: 1, Alive: true}
+	state.ID, _ = field("plant_id")
+	state.SectionID, _ = field("section")
+	state.Type, _ = field("type")
+	if state.ID == "" || state.SectionID == "" {
+		return 0, PlantState{}, errors.New("plant ID and section cannot be empty")
+	}
+	if err := errors.Join(number("saturation", &state.Saturation), number("health", &state.Health), number("growth", &state.Growth)); err != nil {
+		return 0, PlantState{}, err
+	}
+	if text, ok := field("alive"); ok {
+		if state.Alive, err = strconv.ParseBool(text); err != nil {
+			return 0, PlantState{}, errors.New("invalid alive: " + text)
+		}
+	}
+	return tick, state, nil
+}
+
+// GapPolicy decides how a replay crosses ticks missing from the recording.
+type GapPolicy string
+
+const (
+	// GapSkip jumps straight to the next recorded tick.
+	GapSkip GapPolicy = "skip"
+	// GapInterpolate replays every missing tick, moving each plant's health,
+	// growth and saturation linearly between the recorded ticks around the gap.
+	// Whether a plant is alive, and which plants there are, only change on
+	// recorded ticks.
+	GapInterpolate GapPolicy = "interpolate"
+)
+
+// ReplayOptions configures a replay.
+type ReplayOptions struct {
+	// Gaps is the gap policy, GapSkip when empty.
+	Gaps GapPolicy
+}
+
+// NewReplay builds a greenhouse whose plants follow rec instead of being
+// simulated. Each Step of the simulator replays one tick: the plants take
+// their recorded state and the tick listeners run, so the sensors are read
+// from the recorded plants and the same PlantDied, SensorSample and Tick
+// events as in a live run are published. After the last recorded tick a
+// ReplayCompleted event is published and further steps do nothing.
+//
+// cfg supplies the tick interval, the environment and the sensors; its plants
+// only supply the types of recorded plants without one, and its schedules,
+// tank, disease model, timeline and dead plant retention are left out. Plant
+// types are otherwise resolved by name among cfg's plant types and the
+// presets, and unknown ones only keep their name. The simulator keeps the
+// tick interval of cfg even when skipping over gaps. Adding, removing,
+// transplanting, pruning or thinning plants fails with ErrReplay.
+//
+// Returns an error if cfg is invalid, rec has no frames, or the gap policy
+// is unknown.
+func NewReplay(cfg *config.GreenhouseConfig, rec *Recording, opts ReplayOptions) (Greenhouse, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if len(rec.Frames) == 0 {
+		return nil, errors.New("recording has no ticks")
+	}
+	gaps := opts.Gaps
+	if gaps == "" {
+		gaps = GapSkip
+	}
+	if gaps != GapSkip && gaps != GapInterpolate {
+		return nil, errors.New("gap policy must be skip or interpolate: " + string(gaps))
+	}
+	types, err := replayPlantTypes(cfg, rec)
+	if err != nil {
+		return nil, err
+	}
+
+	replayCfg := *cfg
+	replayCfg.Plants, replayCfg.Schedules, replayCfg.Tank, replayCfg.Timeline = nil, nil, nil, nil
+	replayCfg.Disease, replayCfg.DeadPlants = nil, nil
+	sim := newReplaySimulator(time.Duration(cfg.TickInterval), rec.Frames, gaps, types)
+	g, err := newGreenhouse(&replayCfg, sim)
+	if err != nil {
+		return nil, err
+	}
+	g.weather.affectPlants = false
+	sim.onEnd = func(tick int) {
+		g.bus.Publish(events.Event{Type: events.ReplayCompleted, Tick: tick, Timestamp: time.Now()})
+	}
+	return g, nil
+}
+
+// replayPlantTypes resolves the type of every recorded plant, by plant ID.
+func replayPlantTypes(cfg *config.GreenhouseConfig, rec *Recording) (map[string]*models.PlantType, error) {
+	configured, err := cfg.BuildPlants()
+	if err != nil {
+		return nil, err
+	}
+	types := map[string]*models.PlantType{}
+	var interned models.PlantTypes
+	for _, frame := range rec.Frames {
+		for _, state := range frame.Plants {
+			if _, ok := types[state.ID]; ok {
+				continue
+			}
+			if state.Type == "" {
+				types[state.ID] = interned.Intern(models.PlantType{})
+				for _, plant := range configured {
+					if plant.ID == state.ID {
+						types[state.ID] = interned.Intern(*plant.Type)
+					}
+				}
+				continue
+			}
+			lookup := &config.GreenhouseConfig{
+				PlantTypes: cfg.PlantTypes,
+				Plants:     []config.PlantConfig{{ID: state.ID, Type: state.Type, SectionID: state.SectionID}},
+			}
+			if plants, err := lookup.BuildPlants(); err == nil {
+				types[state.ID] = interned.Intern(*plants[0].Type)
+			} else {
+				types[state.ID] = interned.Intern(models.PlantType{Name: state.Type})
+			}
+		}
+	}
+	return types, nil
+}
+
+// replaySimulator is an engine.Simulator whose plants take the states of
+// recorded frames instead of growing. tick is the next tick to replay, next
+// the index of the next frame to reach and played the number of ticks
+// replayed so far.
+type replaySimulator struct {
+	*engine.Lifecycle
+	*engine.TickHooks
+	*engine.Pacer
+	*engine.TickBarrier
+	ticker            *time.Ticker
+	tickInterval      time.Duration
+	speed             float64
+	frames            []Frame
+	gaps              GapPolicy
+	types             map[string]*models.PlantType
+	tick              int
+	next              int
+	played            int
+	plants            []*models.Plant
+	plantsBySectionID map[string][]*models.Plant
+	tickListeners     []engine.TickListener
+	tracer            trace.Tracer
+	tickCtx           context.Context
+	onEnd             func(tick int)
+	mu                sync.RWMutex
+}
+
+// newReplaySimulator creates a replay simulator whose plants start in the
+// state of the first frame.
+func newReplaySimulator(tickInterval time.Duration, frames []Frame, gaps GapPolicy, types map[string]*models.PlantType) *replaySimulator {
+	s := &replaySimulator{
+		Lifecycle:    engine.NewLifecycle(),
+		TickHooks:    engine.NewTickHooks(),
+		Pacer:        engine.NewPacer(),
+		TickBarrier:  engine.NewTickBarrier(),
+		ticker:       time.NewTicker(tickInterval),
+		tickInterval: tickInterval,
+		speed:        1,
+		frames:       frames,
+		gaps:         gaps,
+		types:        types,
+		tick:         frames[0].Tick,
+	}
+	s.apply(frames[0].Plants)
+	return s
+}
+
+// Start replays a tick on every ticker event until Stop is called. Once the
+// recording has ended the loop keeps serving Pause, Resume and Stop.
+// Returns an error if the replay was started or stopped before, see
+// engine.Lifecycle.Run. Ticks are timed and overruns handled as for the
+// engine simulator.
+func (s *replaySimulator) Start() error {
+	return s.Run(s.ticker.C, func() { s.Pace(s.Step, s.ticker, s.period(), s.Lifecycle) })
+}
+
+// period returns the wall-clock interval between ticks at the current speed.
+func (s *replaySimulator) period() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return max(time.Duration(float64(s.tickInterval)/s.speed), time.Nanosecond)
+}
+
+// Step replays the next tick: the next recorded one, or with GapInterpolate
+// the tick after the last one replayed. The tick hooks run on that tick, their
+// watering failing with ErrReplay, then the tick listeners are notified with
+// it, and after the last recorded tick onEnd is called. Once the
+// recording has ended Step does nothing. Ticks are traced as by the engine
+// simulator, the plant update phase being the replay of the plant states,
+// and hold the engine.TickBarrier as they do.
+func (s *replaySimulator) Step() {
+	defer s.HoldTick()()
+	s.mu.Lock()
+	if s.next == len(s.frames) {
+		s.mu.Unlock()
+		return
+	}
+	tick, frame := s.tick, s.frames[s.next]
+	if s.gaps == GapSkip || tick >= frame.Tick {
+		tick = frame.Tick
+	}
+	tickTrace := engine.StartTick(s.tracer, tick)
+	endUpdate := tickTrace.Phase(engine.PlantUpdatePhase)
+	if tick == frame.Tick {
+		s.apply(frame.Plants)
+		s.next++
+	} else {
+		s.apply(interpolate(s.frames[s.next-1], frame, tick))
+	}
+	endUpdate()
+	s.tick = tick + 1
+	s.played++
+	ended := s.next == len(s.frames)
+	listeners := slices.Clone(s.tickListeners)
+	onEnd := s.onEnd
+	plants := len(s.plants)
+	if tickTrace != nil {
+		s.tickCtx = tickTrace.Context()
+	}
+	s.mu.Unlock()
+
+	if s.HasTickHooks() {
+		s.RunTickHooks(tickTrace, engine.NewTickContext(tick, s.snapshotPlants, s.waterPlant, s.HookEnvironment()))
+	}
+	for _, l := range listeners {
+		tickTrace.Notify(l, tick)
+	}
+	if tickTrace != nil {
+		s.mu.Lock()
+		s.tickCtx = nil
+		s.mu.Unlock()
+		tickTrace.End(plants)
+	}
+	if ended && onEnd != nil {
+		onEnd(tick)
+	}
+}
+
+// apply replaces the plants with new ones in the given states, so that
+// plants handed out earlier keep the state they had. Callers hold mu.
+func (s *replaySimulator) apply(states []PlantState) {
+	created := map[string]time.Time{}
+	for _, plant := range s.plants {
+		created[plant.ID] = plant.CreatedAt
+	}
+	s.plants = make([]*models.Plant, 0, len(states))
+	s.plantsBySectionID = map[string][]*models.Plant{}
+	for _, state := range states {
+		createdAt, ok := created[state.ID]
+		if !ok {
+			createdAt = time.Now()
+		}
+		plant := &models.Plant{
+			ID:             state.ID,
+			Type:           s.types[state.ID],
+			SectionID:      state.SectionID,
+			SoilSaturation: state.Saturation,
+			Health:         state.Health,
+			GrowthStage:    state.Growth,
+			Alive:          state.Alive,
+			CreatedAt:      createdAt,
+		}
+		s.plants = append(s.plants, plant)
+		s.plantsBySectionID[plant.SectionID] = append(s.plantsBySectionID[plant.SectionID], plant)
+	}
+}
+
+// interpolate returns the plants of from on a tick between from and to, with
+// the values of those also in to moved linearly towards them.
+func interpolate(from, to Frame, tick int) []PlantState {
+	fraction := float64(tick-from.Tick) / float64(to.Tick-from.Tick)
+	lerp := func(a, b float64) float64 { return a + (b-a)*fraction }
+	states := slices.Clone(from.Plants)
+	for i, state := range states {
+		j := slices.IndexFunc(to.Plants, func(other PlantState) bool { return other.ID == state.ID })
+		if j < 0 {
+			continue
+		}
+		target := to.Plants[j]
+		states[i].Health = lerp(state.Health, target.Health)
+		states[i].Growth = lerp(state.Growth, target.Growth)
+		states[i].Saturation = lerp(state.Saturation, target.Saturation)
+	}
+	return states
+}
+
+// SetTracer traces every replayed tick from the next one on with tracer; nil
+// turns tracing off.
+// This method is safe for concurrent use.
+func (s *replaySimulator) SetTracer(tracer trace.Tracer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tracer = tracer
+}
+
+// TickContext returns a context carrying the span of the tick whose
+// listeners are running, and context.Background() between ticks or without a
+// tracer.
+// This method is safe for concurrent use.
+func (s *replaySimulator) TickContext() context.Context {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.tickCtx == nil {
+		return context.Background()
+	}
+	return s.tickCtx
+}
+
+// AddTickListener registers a listener to be notified after every replayed
+// tick. Listeners are called in registration order.
+// This method is safe for concurrent use.
+func (s *replaySimulator) AddTickListener(l engine.TickListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tickListeners = append(s.tickListeners, l)
+}
+
+// snapshotPlants returns snapshots of the replayed plants, for tick hooks.
+func (s *replaySimulator) snapshotPlants() []*models.Plant {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	plants := make([]*models.Plant, len(s.plants))
+	for i, plant := range s.plants {
+		plants[i] = plant.Clone()
+	}
+	return plants
+}
+
+// waterPlant fails: the soil of a replayed plant comes from the recording.
+func (s *replaySimulator) waterPlant(plantID string, amount float64) error {
+	return fmt.Errorf("%w: %s", ErrReplay, plantID)
+}
+
+// AddPlant fails: the plants of a replay come from the recording.
+func (s *replaySimulator) AddPlant(p *models.Plant) error {
+	return fmt.Errorf("%w: %s", ErrReplay, p.ID)
+}
+
+// AddPlants fails: the plants of a replay come from the recording.
+func (s *replaySimulator) AddPlants(plants []*models.Plant) error {
+	return ErrReplay
+}
+
+// RemovePlant fails: the plants of a replay come from the recording.
+func (s *replaySimulator) RemovePlant(plantID string) error {
+	return fmt.Errorf("%w: %s", ErrReplay, plantID)
+}
+
+// TransplantPlant fails: the plants of a replay come from the recording.
+func (s *replaySimulator) TransplantPlant(plantID, sectionID string) error {
+	return fmt.Errorf("%w: %s", ErrReplay, plantID)
+}
+
+// PrunePlant fails: the plants of a replay come from the recording.
+func (s *replaySimulator) PrunePlant(plantID string, fraction float64) error {
+	return fmt.Errorf("%w: %s", ErrReplay, plantID)
+}
+
+// SetPlantFlags fails: the plants of a replay come from the recording.
+func (s *replaySimulator) SetPlantFlags(plantID string, flags models.PlantFlags) error {
+	return fmt.Errorf("%w: %s", ErrReplay, plantID)
+}
+
+// SetPlantTags fails: the plants of a replay come from the recording.
+func (s *replaySimulator) SetPlantTags(plantID string, tags []string) error {
+	return fmt.Errorf("%w: %s", ErrReplay, plantID)
+}
+
+// UpdatePlants runs fn holding the lock of the replayed plants, which later
+// ticks replace rather than change.
+// This method is safe for concurrent use.
+func (s *replaySimulator) UpdatePlants(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn()
+}
+
+// SetPruneEffect does nothing: the plants of a replay are never pruned.
+func (s *replaySimulator) SetPruneEffect(effect models.PruneEffect) error {
+	return nil
+}
+
+// ThinSection fails: the plants of a replay come from the recording.
+func (s *replaySimulator) ThinSection(sectionID string, keepN int) ([]string, error) {
+	return nil, fmt.Errorf("%w: %s", ErrReplay, sectionID)
+}
+
+// Reset fails: a replay plays its recording once.
+func (s *replaySimulator) Reset(keepConfig bool) error {
+	return ErrReplay
+}
+
+// GetAllPlants returns the plants in their replayed state, ordered by ID.
+// The plants are replaced, not changed, on the next replayed tick.
+// This method is safe for concurrent use.
+func (s *replaySimulator) GetAllPlants() []*models.Plant {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.plants)
+}
+
+// GetPlant returns a plant in its replayed state, which later ticks replace
+// rather than change.
+// Returns an error wrapping engine.ErrPlantNotFound if no plant has the given
+// ID.
+// This method is safe for concurrent use.
+func (s *replaySimulator) GetPlant(plantID string) (*models.Plant, error) {
+	plants, missing := s.GetPlantsByIDs([]string{plantID})
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", engine.ErrPlantNotFound, plantID)
+	}
+	return plants[0], nil
+}
+
+// GetPlantDiagnosis returns the diagnosis of a replayed plant, which is
+// always empty: recordings do not keep what the plants lost health to.
+// Returns an error wrapping engine.ErrPlantNotFound if no plant has the given
+// ID.
+// This method is safe for concurrent use.
+func (s *replaySimulator) GetPlantDiagnosis(plantID string) (models.HealthDiagnosis, error) {
+	plant, err := s.GetPlant(plantID)
+	if err != nil {
+		return models.HealthDiagnosis{}, err
+	}
+	return plant.Diagnosis, nil
+}
+
+// GetPlantsByIDs returns the plants with the given IDs in their replayed
+// state, in the order of plantIDs, and the IDs no plant has.
+// This method is safe for concurrent use.
+func (s *replaySimulator) GetPlantsByIDs(plantIDs []string) ([]*models.Plant, []string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	plants := make([]*models.Plant, 0, len(plantIDs))
+	var missing []string
+	for _, plantID := range plantIDs {
+		i, found := slices.BinarySearchFunc(s.plants, plantID, func(p *models.Plant, id string) int { return strings.Compare(p.ID, id) })
+		if !found {
+			missing = append(missing, plantID)
+			continue
+		}
+		plants = append(plants, s.plants[i])
+	}
+	return plants, missing
+}
+
+// GetPlantsBySectionID returns the plants of a section in their replayed
+// state, ordered by ID.
+// This method is safe for concurrent use.
+func (s *replaySimulator) GetPlantsBySectionID(sectionID string) []*models.Plant {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.plantsBySectionID[sectionID])
+}
+
+// QueryPlants returns a page of the plants matching query in their replayed
+// state, ordered by ID, see engine.Simulator.QueryPlants.
+// This method is safe for concurrent use.
+func (s *replaySimulator) QueryPlants(query engine.PlantQuery) engine.PlantPage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	plants := s.plants
+	if query.SectionID != "" {
+		plants = s.plantsBySectionID[query.SectionID]
+	}
+	page := engine.PlantPage{Plants: []*models.Plant{}}
+	for _, plant := range plants {
+		if !query.Matches(plant) {
+			continue
+		}
+		page.Total++
+		if plant.ID <= query.After {
+			continue
+		}
+		if query.Limit > 0 && len(page.Plants) == query.Limit {
+			page.Next = page.Plants[len(page.Plants)-1].ID
+			continue
+		}
+		page.Plants = append(page.Plants, plant)
+	}
+	return page
+}
+
+// SectionStats sums up the replayed plants of a section, see
+// engine.PlantStats, from the plants themselves: a replayed tick replaces
+// every plant, so there are no sums to keep running. Reports false if the
+// section has no plants.
+// This method is safe for concurrent use.
+func (s *replaySimulator) SectionStats(sectionID string) (engine.PlantStats, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	plants, ok := s.plantsBySectionID[sectionID]
+	return engine.SumPlants(plants), ok
+}
+
+// PlantStats sums up the replayed plants, see engine.PlantStats.
+// This method is safe for concurrent use.
+func (s *replaySimulator) PlantStats() engine.PlantStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return engine.SumPlants(s.plants)
+}
+
+// ListSectionIDs returns the sorted IDs of the sections that have replayed
+// plants.
+// This method is safe for concurrent use.
+func (s *replaySimulator) ListSectionIDs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Sorted(maps.Keys(s.plantsBySectionID))
+}
+
+// SectionActivity reports every section with plants as changed on the
+// current tick, as a replayed tick replaces every plant, and dead when none
+// of its plants is alive. Reports false if the section has no plants.
+// This method is safe for concurrent use.
+func (s *replaySimulator) SectionActivity(sectionID string) (engine.SectionActivity, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	plants := s.plantsBySectionID[sectionID]
+	if len(plants) == 0 {
+		return engine.SectionActivity{}, false
+	}
+	dead := !slices.ContainsFunc(plants, func(plant *models.Plant) bool { return plant.Alive })
+	return engine.SectionActivity{ChangedAt: s.tick, Dead: dead}, true
+}
+
+// GetCurrentTick returns the next tick to replay, or one past the last
+// recorded tick once the recording has ended.
+// This method is safe for concurrent use.
+func (s *replaySimulator) GetCurrentTick() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tick
+}
+
+// Status returns a summary of the replay. Its ticks run are the ticks
+// replayed so far, which skipped gaps leave out of the tick numbers.
+// This method is safe for concurrent use.
+func (s *replaySimulator) Status() engine.Status {
+	state := s.State()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	status := engine.Status{
+		State:        state,
+		Paused:       state == engine.Paused,
+		Tick:         s.tick,
+		UptimeTicks:  s.played,
+		TickInterval: s.tickInterval,
+		Plants:       len(s.plants),
+		Timing:       s.TickTiming(),
+	}
+	for _, plant := range s.plants {
+		if plant.Alive {
+			status.AlivePlants++
+		}
+	}
+	return status
+}
+
+// GetTickInterval returns the tick interval of the replayed config.
+func (s *replaySimulator) GetTickInterval() time.Duration {
+	return s.tickInterval
+}
+
+// SetSpeed changes how fast Start replays ticks, as for the engine simulator.
+// Returns an error if speed is not positive.
+// This method is safe for concurrent use.
+func (s *replaySimulator) SetSpeed(speed float64) error {
+	if speed <= 0 {
+		return errors.New("speed must be positive")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.speed = speed
+	s.ticker.Reset(max(time.Duration(float64(s.tickInterval)/speed), time.Nanosecond))
+	return nil
+}
+
+// GetSpeed returns the speed set by SetSpeed, 1 by default.
+// This method is safe for concurrent use.
+func (s *replaySimulator) GetSpeed() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.speed
+}
@@ -0,0 +1,192 @@
+package greenhouse
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// sample is a SensorSample event reduced to what a replay must reproduce.
+type sample struct {
+	tick   int
+	sensor string
+	value  float64
+}
+
+// collectSamples records the SensorSample events of g, and the ticks of its
+// Tick and ReplayCompleted events in order.
+func collectSamples(g Greenhouse) (*[]sample, *[]string) {
+	var samples []sample
+	var ticks []string
+	g.Bus().Subscribe(func(e events.Event) {
+		switch e.Type {
+		case events.SensorSample:
+			reading := e.Payload.(models.SensorReading)
+			samples = append(samples, sample{e.Tick, reading.SensorID, reading.Value})
+		case events.Tick, events.ReplayCompleted:
+			ticks = append(ticks, fmt.Sprintf("%s %d", e.Type, e.Tick))
+		}
+	})
+	return &samples, &ticks
+}
+
+func TestReplay_ReproducesRecordedRun(t *testing.T) {
+	cfg := testConfig()
+	cfg.Plants = append(cfg.Plants, config.PlantConfig{ID: "mint-1", Type: "Mint", SectionID: "section-B", InitialSaturation: 0.8})
+	cfg.Sensors = append(cfg.Sensors, config.SensorConfig{ID: "sensor-2", Type: "soil_moisture", SectionID: "section-B"})
+	live, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	var recorded bytes.Buffer
+	recorder, err := NewRunRecorder(live, &recorded, RecordOptions{Gzip: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := live.Exporters().Register("record", recorder, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	liveSamples, _ := collectSamples(live)
+	for range 20 {
+		live.Simulator().Step()
+	}
+	if err := live.Exporters().Remove("record"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	recording, err := ReadRecording(&recorded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recording.Frames) != 20 || len(recording.Frames[0].Plants) != 3 {
+		t.Fatalf("expected 20 frames of 3 plants, got %d frames", len(recording.Frames))
+	}
+	replay, err := NewReplay(cfg, recording, ReplayOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	replaySamples, ticks := collectSamples(replay)
+	for range 25 {
+		replay.Simulator().Step()
+	}
+
+	if len(*replaySamples) != len(*liveSamples) {
+		t.Fatalf("expected %d samples, got %d", len(*liveSamples), len(*replaySamples))
+	}
+	for i, expected := range *liveSamples {
+		got := (*replaySamples)[i]
+		if got.tick != expected.tick || got.sensor != expected.sensor || math.Abs(got.value-expected.value) > 1e-9 {
+			t.Errorf("sample %d: expected %+v, got %+v", i, expected, got)
+		}
+	}
+	if n := len(*ticks); n != 21 || (*ticks)[n-2] != "tick 19" || (*ticks)[n-1] != "replay_completed 19" {
+		t.Errorf("expected 20 ticks and then the completion, got %v", *ticks)
+	}
+	if plants := replay.Simulator().GetPlantsBySectionID("section-A"); len(plants) != 2 || plants[0].Type.Name != "Basil" {
+		t.Errorf("expected the basil plants in section-A, got %v", plants)
+	}
+	if sections := replay.Simulator().ListSectionIDs(); len(sections) == 0 || sections[0] != "section-A" || !slices.IsSorted(sections) {
+		t.Errorf("expected the sorted sections of the replayed plants, got %v", sections)
+	}
+	if err := replay.Simulator().TransplantPlant("basil-1", "section-B"); !errors.Is(err, ErrReplay) {
+		t.Errorf("expected ErrReplay, got %v", err)
+	}
+	if _, err := replay.AddPlant(config.PlantConfig{ID: "basil-3", Type: "Basil", SectionID: "section-A", InitialSaturation: 0.5}); !errors.Is(err, ErrReplay) {
+		t.Errorf("expected ErrReplay, got %v", err)
+	}
+}
+
+func TestReplay_Gaps(t *testing.T) {
+	recorded := "tick,plant_id,section,saturation\n0,basil-1,section-A,0.2\n4,basil-1,section-A,0.6\n"
+	tests := []struct {
+		gaps     GapPolicy
+		expected []sample
+	}{
+		{GapSkip, []sample{{0, "sensor-1", 0.2}, {4, "sensor-1", 0.6}}},
+		{GapInterpolate, []sample{{0, "sensor-1", 0.2}, {1, "sensor-1", 0.3}, {2, "sensor-1", 0.4}, {3, "sensor-1", 0.5}, {4, "sensor-1", 0.6}}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.gaps), func(t *testing.T) {
+			recording, err := ReadRecording(strings.NewReader(recorded))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			g, err := NewReplay(testConfig(), recording, ReplayOptions{Gaps: tt.gaps})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			samples, _ := collectSamples(g)
+			for range 10 {
+				g.Simulator().Step()
+			}
+			if len(*samples) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, *samples)
+			}
+			for i, expected := range tt.expected {
+				got := (*samples)[i]
+				if got.tick != expected.tick || math.Abs(got.value-expected.value) > 1e-9 {
+					t.Errorf("expected %v, got %v", tt.expected, *samples)
+					break
+				}
+			}
+			status := g.Simulator().Status()
+			if status.Tick != 5 || status.UptimeTicks != len(tt.expected) {
+				t.Errorf("expected the replay to end before tick 5 after %d ticks, got %+v", len(tt.expected), status)
+			}
+		})
+	}
+}
+
+func TestReadRecording(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected *Recording
+		errorMsg string
+	}{
+		{
+			name:  "rows out of order with defaults",
+			input: "plant_id,tick,section,saturation,type\nmint-1,3,section-B,0.5,Mint\nbasil-1,3,section-A,0.4,\nbasil-1,1,section-A,0.3,\n",
+			expected: &Recording{Frames: []Frame{
+				{Tick: 1, Plants: []PlantState{{ID: "basil-1", SectionID: "section-A", Health: 1, Saturation: 0.3, Alive: true}}},
+				{Tick: 3, Plants: []PlantState{
+					{ID: "basil-1", SectionID: "section-A", Health: 1, Saturation: 0.4, Alive: true},
+					{ID: "mint-1", SectionID: "section-B", Type: "Mint", Health: 1, Saturation: 0.5, Alive: true},
+				}},
+			}},
+		},
+		{name: "missing column", input: "tick,plant_id,saturation\n", errorMsg: "recording is missing the column: section"},
+		{name: "invalid tick", input: "tick,plant_id,section,saturation\nx,basil-1,section-A,0.4\n", errorMsg: "recording line 2: invalid tick: x"},
+		{name: "invalid alive", input: "tick,plant_id,section,saturation,alive\n0,basil-1,section-A,0.4,maybe\n", errorMsg: "recording line 2: invalid alive: maybe"},
+		{
+			name:     "plant twice on a tick",
+			input:    "tick,plant_id,section,saturation\n0,basil-1,section-A,0.4\n0,basil-1,section-A,0.5\n",
+			errorMsg: "recording line 3: plant basil-1 recorded twice on tick 0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recording, err := ReadRecording(strings.NewReader(tt.input))
+			if tt.errorMsg != "" {
+				if err == nil || err.Error() != tt.errorMsg {
+					t.Fatalf("expected error %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(recording, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, recording)
+			}
+		})
+	}
+}
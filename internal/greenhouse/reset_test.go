@@ -0,0 +1,95 @@
+package greenhouse
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/engine"
+	"testing"
+	"time"
+)
+
+func TestReset_MatchesNewRun(t *testing.T) {
+	const ticks = 500
+	cfg := goldenConfig(42)
+	expected := fingerprintRun(t, cfg, ticks)
+
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	g.Simulator().Step()
+	if _, err := g.AddPlant(config.PlantConfig{ID: "extra", Type: "Tomato", SectionID: "section-A", InitialSaturation: 0.5}); err != nil {
+		t.Fatalf("failed to add plant: %v", err)
+	}
+	if first := fingerprint(g, ticks); first == expected {
+		t.Fatal("expected the added plant to change the first run")
+	}
+	for run := range 2 {
+		if err := g.Reset(true); err != nil {
+			t.Fatalf("failed to reset: %v", err)
+		}
+		if got := fingerprint(g, ticks); got != expected {
+			t.Errorf("expected run %d after a reset to match a new run, got %s, want %s", run+1, got, expected)
+		}
+	}
+}
+
+func TestReset_KeepConfig(t *testing.T) {
+	cfg := goldenConfig(42)
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	for range 10 {
+		g.Simulator().Step()
+	}
+
+	if err := g.Reset(true); err != nil {
+		t.Fatalf("failed to reset: %v", err)
+	}
+	if got := len(g.Sensors().ListSensors()); got != len(cfg.Sensors) {
+		t.Errorf("expected the %d configured sensors to be kept, got %d", len(cfg.Sensors), got)
+	}
+	if tick := g.Simulator().GetCurrentTick(); tick != 0 {
+		t.Errorf("expected tick 0 after a reset, got %d", tick)
+	}
+	if stats := g.Stats(); stats.Plants != len(cfg.Plants) || stats.WaterUsed != 0 || stats.Died != 0 {
+		t.Errorf("expected the stats of a new greenhouse, got %+v", stats)
+	}
+
+	if err := g.Reset(false); err != nil {
+		t.Fatalf("failed to reset: %v", err)
+	}
+	if got := g.Sensors().ListSensors(); len(got) != 0 {
+		t.Errorf("expected the sensors to be cleared, got %d", len(got))
+	}
+	if scenario, err := g.ExportScenario(config.ExportOptions{}); err != nil || len(scenario.Schedules) != 0 {
+		t.Errorf("expected the schedules to be cleared, got %v (%v)", scenario, err)
+	}
+	g.Simulator().Step()
+}
+
+func TestReset_WhileRunning(t *testing.T) {
+	g, err := New(goldenConfig(42))
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	sim := g.Simulator()
+	ended := make(chan error, 1)
+	go func() { ended <- sim.Start() }()
+	for sim.State() != engine.Running {
+		time.Sleep(time.Millisecond)
+	}
+	if err := g.Reset(true); !errors.Is(err, engine.ErrResetWhileRunning) {
+		t.Errorf("expected resetting a running simulation to fail, got %v", err)
+	}
+	if err := sim.Stop(); err != nil {
+		t.Fatalf("failed to stop: %v", err)
+	}
+	if err := <-ended; err != nil {
+		t.Fatalf("expected the loop to end without error, got %v", err)
+	}
+	if err := g.Reset(true); err != nil {
+		t.Errorf("expected a stopped simulation to reset, got %v", err)
+	}
+}
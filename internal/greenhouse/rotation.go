@@ -0,0 +1,299 @@
+package greenhouse
+
+import (
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// RotationStatus is a rotation plan and how far it got: Planted is the number
+// of its crops planted so far, and Delayed reports whether the next one is
+// due but waits for the section to be cleared, see config.RotationConfig.
+type RotationStatus struct {
+	config.RotationConfig
+	Planted int  `json:"planted"`
+	Delayed bool `json:"delayed,omitempty"`
+}
+
+// RotationPlanting is the payload of the PlantingScheduled and Planted
+// events: a crop of the rotation of a section, by its index in the plan, and
+// once planted the IDs of its plants and the error that stopped the planting,
+// if any.
+type RotationPlanting struct {
+	SectionID string            `json:"section"`
+	Index     int               `json:"index"`
+	Crop      config.CropConfig `json:"crop"`
+	Plants    []string          `json:"plants,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// rotation is a live rotation plan. version changes whenever the plan is
+// replaced, so that a tick does not record its progress on a newer plan.
+type rotation struct {
+	config.RotationConfig
+	version   int
+	next      int // index of the next crop to plant
+	scheduled bool
+	delayed   bool
+}
+
+func (r *rotation) status() RotationStatus {
+	plan := r.RotationConfig
+	plan.Crops = slices.Clone(plan.Crops)
+	return RotationStatus{RotationConfig: plan, Planted: r.next, Delayed: r.delayed}
+}
+
+// rotations runs the rotation plans of the sections, those of the config and
+// those set at runtime. Every tick, each running plan harvests the mature
+// plants of its section, clears the dead ones and plants the crops that are
+// due. The plans are copied out for the tick, since planting goes through
+// the greenhouse, whose reloads replace plans.
+type rotations struct {
+	g        *greenhouse
+	plans    map[string]*rotation
+	versions int
+	// plants planted in each section so far, which number the plant IDs;
+	// only the tick uses it
+	counts map[string]int
+	mu     sync.Mutex
+}
+
+func newRotations(g *greenhouse, plans []config.RotationConfig) *rotations {
+	r := &rotations{g: g, plans: map[string]*rotation{}, counts: map[string]int{}}
+	for _, plan := range plans {
+		r.set(plan)
+	}
+	return r
+}
+
+// TickPhase names the rotations in tick traces.
+func (r *rotations) TickPhase() string { return "rotations" }
+
+func (r *rotations) OnTick(tick int) {
+	r.mu.Lock()
+	if len(r.plans) == 0 {
+		r.mu.Unlock()
+		return
+	}
+	var plans []rotation
+	for _, sectionID := range slices.Sorted(maps.Keys(r.plans)) {
+		if plan := r.plans[sectionID]; !plan.Paused {
+			plans = append(plans, *plan)
+		}
+	}
+	r.mu.Unlock()
+
+	for i := range plans {
+		r.run(&plans[i], tick)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, plan := range plans {
+		if live := r.plans[plan.SectionID]; live != nil && live.version == plan.version {
+			live.next, live.scheduled, live.delayed = plan.next, plan.scheduled, plan.delayed
+		}
+	}
+}
+
+// run harvests the section of a plan and plants its crops that are due,
+// publishing a PlantingScheduled event for every crop that becomes the next
+// one and a Planted event for every crop planted.
+func (r *rotations) run(plan *rotation, tick int) {
+	growing := r.harvest(plan.SectionID)
+	for plan.next < len(plan.Crops) {
+		crop := plan.Crops[plan.next]
+		planting := RotationPlanting{SectionID: plan.SectionID, Index: plan.next, Crop: crop}
+		if !plan.scheduled {
+			r.publish(events.PlantingScheduled, tick, planting)
+			plan.scheduled = true
+		}
+		due := tick >= crop.Tick
+		if crop.AfterHarvest {
+			due = growing == 0
+		}
+		plan.delayed = due && growing > 0 && !plan.Interplants()
+		if !due || plan.delayed {
+			return
+		}
+		var err error
+		planting.Plants, err = r.plant(plan.SectionID, crop)
+		if err != nil {
+			planting.Error = err.Error()
+		}
+		r.publish(events.Planted, tick, planting)
+		growing += len(planting.Plants)
+		plan.next++
+		plan.scheduled = false
+	}
+}
+
+// harvest removes the mature and dead plants of a section and returns the
+// number of plants still growing in it.
+func (r *rotations) harvest(sectionID string) int {
+	growing := 0
+	for _, plant := range r.g.sim.GetPlantsBySectionID(sectionID) {
+		if plant.Alive && plant.Stage() != models.StageMature {
+			growing++
+			continue
+		}
+		if err := r.g.RemovePlant(plant.ID); err != nil && !errors.Is(err, engine.ErrPlantNotFound) {
+			growing++
+		}
+	}
+	return growing
+}
+
+// plant adds the plants of a crop, numbered after those the rotations
+// planted in the section so far and skipping the IDs that are taken. Returns
+// the IDs of the plants added before an error.
+func (r *rotations) plant(sectionID string, crop config.CropConfig) ([]string, error) {
+	var ids []string
+	for len(ids) < crop.Count {
+		r.counts[sectionID]++
+		id := fmt.Sprintf("%s-crop-%d", sectionID, r.counts[sectionID])
+		_, err := r.g.AddPlant(config.PlantConfig{ID: id, Type: crop.Type, SectionID: sectionID, InitialSaturation: crop.InitialSaturation})
+		if errors.Is(err, engine.ErrPlantExists) {
+			continue
+		}
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (r *rotations) publish(eventType events.Type, tick int, planting RotationPlanting) {
+	r.g.bus.Publish(events.Event{
+		Type:      eventType,
+		Tick:      tick,
+		Timestamp: time.Now(),
+		SectionID: planting.SectionID,
+		Payload:   planting,
+	})
+}
+
+// set replaces the plan of a section with a copy of plan, starting it from
+// its first crop, and returns its status.
+// This method is safe for concurrent use.
+func (r *rotations) set(plan config.RotationConfig) RotationStatus {
+	plan.Crops = slices.Clone(plan.Crops)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.versions++
+	live := &rotation{RotationConfig: plan, version: r.versions}
+	r.plans[plan.SectionID] = live
+	return live.status()
+}
+
+// remove drops the plan of a section, or returns an error wrapping
+// ErrRotationNotFound.
+// This method is safe for concurrent use.
+func (r *rotations) remove(sectionID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.plans[sectionID] == nil {
+		return fmt.Errorf("%w: %s", ErrRotationNotFound, sectionID)
+	}
+	delete(r.plans, sectionID)
+	return nil
+}
+
+// setPaused pauses or resumes the plan of a section, or returns an error
+// wrapping ErrRotationNotFound.
+// This method is safe for concurrent use.
+func (r *rotations) setPaused(sectionID string, paused bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	plan := r.plans[sectionID]
+	if plan == nil {
+		return fmt.Errorf("%w: %s", ErrRotationNotFound, sectionID)
+	}
+	plan.Paused = paused
+	return nil
+}
+
+// list returns the status of the plans, ordered by section.
+// This method is safe for concurrent use.
+func (r *rotations) list() []RotationStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := make([]RotationStatus, 0, len(r.plans))
+	for _, sectionID := range slices.Sorted(maps.Keys(r.plans)) {
+		list = append(list, r.plans[sectionID].status())
+	}
+	return list
+}
+
+// remaining returns the plans with crops left to plant, those crops only,
+// with their ticks counted from tick, for a run starting over from there.
+// This method is safe for concurrent use.
+func (r *rotations) remaining(tick int) []config.RotationConfig {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var plans []config.RotationConfig
+	for _, sectionID := range slices.Sorted(maps.Keys(r.plans)) {
+		live := r.plans[sectionID]
+		if live.next == len(live.Crops) {
+			continue
+		}
+		plan := live.RotationConfig
+		plan.Crops = slices.Clone(plan.Crops[live.next:])
+		for i := range plan.Crops {
+			plan.Crops[i].Tick = max(0, plan.Crops[i].Tick-tick)
+		}
+		plans = append(plans, plan)
+	}
+	return plans
+}
+
+// Rotations returns the rotation plans of the sections with their progress,
+// ordered by section.
+// This method is safe for concurrent use.
+func (g *greenhouse) Rotations() []RotationStatus {
+	return g.rotations.list()
+}
+
+// SetRotation sets the rotation plan of a section, replacing its current
+// plan, which stops where it got, and starts it from its first crop on the
+// next tick. The plan may be paused from the start. Returns an error if the
+// plan is invalid, see config.GreenhouseConfig.ValidateRotation.
+// This method is safe for concurrent use.
+func (g *greenhouse) SetRotation(plan config.RotationConfig) (RotationStatus, error) {
+	if err := g.Config().ValidateRotation(plan); err != nil {
+		return RotationStatus{}, err
+	}
+	return g.rotations.set(plan), nil
+}
+
+// RemoveRotation drops the rotation plan of a section; its plants stay.
+// Returns an error wrapping ErrRotationNotFound if the section has no plan.
+// This method is safe for concurrent use.
+func (g *greenhouse) RemoveRotation(sectionID string) error {
+	return g.rotations.remove(sectionID)
+}
+
+// PauseRotation pauses the rotation plan of a section: it neither harvests
+// nor plants until ResumeRotation, and a crop due meanwhile is planted once
+// resumed. Pausing a paused plan does nothing. Returns an error wrapping
+// ErrRotationNotFound if the section has no plan.
+// This method is safe for concurrent use.
+func (g *greenhouse) PauseRotation(sectionID string) error {
+	return g.rotations.setPaused(sectionID, true)
+}
+
+// ResumeRotation resumes a rotation plan paused with PauseRotation. Resuming
+// a running plan does nothing. Returns an error wrapping ErrRotationNotFound
+// if the section has no plan.
+// This method is safe for concurrent use.
+func (g *greenhouse) ResumeRotation(sectionID string) error {
+	return g.rotations.setPaused(sectionID, false)
+}
@@ -0,0 +1,185 @@
+package greenhouse
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/events"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// rotationConfig has two fast growing types, maturing 4 ticks after they are
+// planted. section-A rotates radishes, turnips due while the radishes grow,
+// and radishes again after the turnip harvest; section-B interplants turnips
+// among its radish.
+func rotationConfig() *config.GreenhouseConfig {
+	fast := func(name string) config.PlantTypeConfig {
+		return config.PlantTypeConfig{Name: name, OptimalSaturation: 0.5, MinSaturation: 0.2, MaxSaturation: 0.9, BaseGrowthRate: 0.2,
+			HealthEnhancementRate: 0.05, MinTemperature: -50, MaxTemperature: 60}
+	}
+	return &config.GreenhouseConfig{
+		TickInterval: config.Duration(time.Second),
+		PlantTypes:   []config.PlantTypeConfig{fast("Radish"), fast("Turnip")},
+		Rotations: []config.RotationConfig{
+			{SectionID: "section-A", Crops: []config.CropConfig{
+				{Type: "Radish", Count: 3, InitialSaturation: 0.5},
+				{Type: "Turnip", Count: 2, Tick: 1, InitialSaturation: 0.5},
+				{Type: "Radish", Count: 4, AfterHarvest: true, InitialSaturation: 0.5},
+			}},
+			{SectionID: "section-B", Overlap: config.RotationInterplant, Crops: []config.CropConfig{
+				{Type: "Radish", Count: 1, InitialSaturation: 0.5},
+				{Type: "Turnip", Count: 2, Tick: 2, InitialSaturation: 0.5},
+			}},
+		},
+	}
+}
+
+// planting is what a test sees of a PlantingScheduled or Planted event.
+type planting struct {
+	eventType events.Type
+	tick      int
+	section   string
+	index     int
+	plantType string
+	plants    int
+	growing   int // plants of the section once planted
+}
+
+func TestRotation_ThreeCropsInOrder(t *testing.T) {
+	g, err := New(rotationConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	var got []planting
+	g.Bus().Subscribe(func(e events.Event) {
+		if e.Type != events.PlantingScheduled && e.Type != events.Planted {
+			return
+		}
+		p := e.Payload.(RotationPlanting)
+		if p.Error != "" {
+			t.Errorf("unexpected planting error: %s", p.Error)
+		}
+		got = append(got, planting{e.Type, e.Tick, e.SectionID, p.Index, p.Crop.Type, len(p.Plants), len(g.Simulator().GetPlantsBySectionID(e.SectionID))})
+	})
+	for range 20 {
+		g.Simulator().Step()
+		if g.Simulator().GetCurrentTick() == 2 && !g.Rotations()[0].Delayed {
+			t.Error("expected the turnips of section-A to be delayed while the radishes grow")
+		}
+	}
+
+	// The radishes planted on tick 0 are harvested on tick 4, where the
+	// delayed turnips take their place, and harvested in turn on tick 8.
+	expected := []planting{
+		{events.PlantingScheduled, 0, "section-A", 0, "Radish", 0, 0},
+		{events.Planted, 0, "section-A", 0, "Radish", 3, 3},
+		{events.PlantingScheduled, 0, "section-A", 1, "Turnip", 0, 3},
+		{events.PlantingScheduled, 0, "section-B", 0, "Radish", 0, 0},
+		{events.Planted, 0, "section-B", 0, "Radish", 1, 1},
+		{events.PlantingScheduled, 0, "section-B", 1, "Turnip", 0, 1},
+		{events.Planted, 2, "section-B", 1, "Turnip", 2, 3},
+		{events.Planted, 4, "section-A", 1, "Turnip", 2, 2},
+		{events.PlantingScheduled, 4, "section-A", 2, "Radish", 0, 2},
+		{events.Planted, 8, "section-A", 2, "Radish", 4, 4},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected plantings %+v, got %+v", expected, got)
+	}
+	for _, status := range g.Rotations() {
+		if status.Planted != len(status.Crops) || status.Delayed {
+			t.Errorf("expected every crop of %s to be planted, got %+v", status.SectionID, status)
+		}
+	}
+	if plants := g.Simulator().GetAllPlants(); len(plants) != 0 {
+		t.Errorf("expected the last crops to be harvested, got %d plants", len(plants))
+	}
+}
+
+func TestRotation_Pause(t *testing.T) {
+	g, err := New(rotationConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	if err := g.PauseRotation("section-A"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for range 3 {
+		g.Simulator().Step()
+	}
+	if plants := g.Simulator().GetPlantsBySectionID("section-A"); len(plants) != 0 {
+		t.Errorf("expected a paused rotation to plant nothing, got %d plants", len(plants))
+	}
+	if err := g.ResumeRotation("section-A"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g.Simulator().Step()
+	if plants := g.Simulator().GetPlantsBySectionID("section-A"); len(plants) != 3 {
+		t.Errorf("expected the resumed rotation to plant its first crop, got %d plants", len(plants))
+	}
+	if err := g.PauseRotation("section-C"); !errors.Is(err, ErrRotationNotFound) {
+		t.Errorf("expected ErrRotationNotFound, got %v", err)
+	}
+}
+
+func TestSetRotation(t *testing.T) {
+	g, err := New(rotationConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	plan := config.RotationConfig{SectionID: "section-C", Crops: []config.CropConfig{{Type: "Turnip", Count: 2, InitialSaturation: 0.5}}}
+	status, err := g.SetRotation(plan)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(status, RotationStatus{RotationConfig: plan}) {
+		t.Errorf("expected the status of the new plan, got %+v", status)
+	}
+	g.Simulator().Step()
+	if plants := g.Simulator().GetPlantsBySectionID("section-C"); len(plants) != 2 {
+		t.Errorf("expected the plan set at runtime to plant, got %d plants", len(plants))
+	}
+	if rotations := g.Rotations(); len(rotations) != 3 || rotations[2].Planted != 1 {
+		t.Errorf("expected the third plan to have planted its crop, got %+v", rotations)
+	}
+
+	plan.Crops[0].Type = "Fern"
+	if _, err := g.SetRotation(plan); err == nil || err.Error() != "rotation of section section-C: unknown plant type: Fern" {
+		t.Errorf("expected an unknown plant type error, got %v", err)
+	}
+}
+
+func TestRotation_ReloadAndExport(t *testing.T) {
+	g, err := New(rotationConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	for range 3 {
+		g.Simulator().Step()
+	}
+
+	// The turnips of section-A wait for the radishes: the export keeps them,
+	// due right away, and the radishes after them.
+	exported, err := g.ExportScenario(config.ExportOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	plans := rotationConfig().Rotations[:1]
+	plans[0].Crops = plans[0].Crops[1:]
+	plans[0].Crops[0].Tick = 0
+	if !reflect.DeepEqual(exported.Rotations, plans) {
+		t.Errorf("expected the crops left to plant %+v, got %+v", plans, exported.Rotations)
+	}
+
+	cfg := rotationConfig()
+	cfg.Plants = exported.Plants
+	cfg.Rotations = cfg.Rotations[1:]
+	cfg.Rotations[0].Crops = cfg.Rotations[0].Crops[:1]
+	if _, err := g.ReloadConfig(cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []RotationStatus{{RotationConfig: cfg.Rotations[0]}}
+	if rotations := g.Rotations(); !reflect.DeepEqual(rotations, expected) {
+		t.Errorf("expected the changed plan to start over alone, got %+v", rotations)
+	}
+}
@@ -0,0 +1,68 @@
+package greenhouse
+
+import (
+	"cmp"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/environment"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// soilSalinity salts the soil of each section for the water the watering
+// controller applied to it on a tick, once it has, or flushes the section
+// when that water reaches the flush amount, and leaches every section on a
+// rainy tick. Plants then take damage for the salinity of their section
+// above what their type tolerates, see models.Plant.Salt. A greenhouse
+// without salinity settings has no soil salinity.
+type soilSalinity struct {
+	g      *greenhouse
+	model  environment.Salinity
+	damage float64
+	// water is the water applied to each section up to the last tick.
+	water map[string]float64
+}
+
+func newSoilSalinity(g *greenhouse, model environment.Salinity, cfg environment.SalinityConfig) *soilSalinity {
+	return &soilSalinity{
+		g:      g,
+		model:  model,
+		damage: cmp.Or(cfg.Damage, 0.1),
+		water:  g.watering.GetWaterStats().BySection,
+	}
+}
+
+// TickPhase names the soil salinity in tick traces.
+func (s *soilSalinity) TickPhase() string { return "salinity" }
+
+func (s *soilSalinity) OnTick(tick int) {
+	water := s.g.watering.GetWaterStats().BySection
+	for sectionID, used := range water {
+		s.model.Water(sectionID, used-s.water[sectionID])
+	}
+	s.water = water
+	if s.g.Conditions().Weather == environment.Rain {
+		s.model.Rain()
+	}
+	plants := s.g.sim.GetAllPlants()
+	s.g.sim.UpdatePlants(func() {
+		for _, plant := range plants {
+			plant.Salt(s.model.Get(plant.SectionID), s.damage)
+		}
+	})
+}
+
+// withSalinity sets the salinity the sections start at to levels, adding the
+// sections missing from sections and keeping them ordered by ID.
+func withSalinity(sections []config.SectionConfig, levels map[string]float64) []config.SectionConfig {
+	for _, sectionID := range slices.Sorted(maps.Keys(levels)) {
+		i := slices.IndexFunc(sections, func(s config.SectionConfig) bool { return s.ID == sectionID })
+		if i < 0 {
+			sections = append(sections, config.SectionConfig{ID: sectionID})
+			i = len(sections) - 1
+		}
+		sections[i].Salinity = levels[sectionID]
+	}
+	slices.SortFunc(sections, func(a, b config.SectionConfig) int { return strings.Compare(a.ID, b.ID) })
+	return sections
+}
@@ -0,0 +1,134 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/models"
+	"math"
+	"testing"
+	"time"
+)
+
+// salinityConfig has a salt sensitive lettuce and a salt tolerant sprout in
+// section-A, both happy at any saturation, a salinity sensor there, and mains
+// water that salts the soil by 0.2 per unit, with a flush of 1 unit washing
+// out 80% of the salt.
+func salinityConfig() *config.GreenhouseConfig {
+	return &config.GreenhouseConfig{
+		TickInterval: config.Duration(time.Second),
+		PlantTypes: []config.PlantTypeConfig{
+			{Name: "Lettuce", OptimalSaturation: 0.5, MaxSaturation: 1, SalinityTolerance: 0.1},
+			{Name: "Sprout", OptimalSaturation: 0.5, MaxSaturation: 1},
+		},
+		Plants: []config.PlantConfig{
+			{ID: "lettuce", Type: "Lettuce", SectionID: "section-A", InitialSaturation: 0.2},
+			{ID: "sprout", Type: "Sprout", SectionID: "section-A", InitialSaturation: 0.2},
+		},
+		Sensors: []config.SensorConfig{
+			{ID: "salinity-A", Type: models.Salinity, SectionID: "section-A"},
+		},
+		Salinity: &config.SalinityConfig{Concentration: 0.2, FlushAmount: 1, FlushRate: 0.8, Damage: 0.5},
+	}
+}
+
+func TestSalinity_AccumulatesAndFlushes(t *testing.T) {
+	g, err := New(salinityConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	plant := func(id string) *models.Plant {
+		for _, p := range g.Simulator().GetAllPlants() {
+			if p.ID == id {
+				return p
+			}
+		}
+		t.Fatalf("no plant %s", id)
+		return nil
+	}
+
+	// Small waterings salt the soil a little more each time.
+	previous := 0.0
+	for range 8 {
+		if err := g.Watering().WaterSection("section-A", 0.1, 0); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		g.Simulator().Step()
+		salinity := g.SectionConditions("section-A").Salinity
+		if salinity <= previous {
+			t.Fatalf("expected the salinity to rise above %.3f, got %.3f", previous, salinity)
+		}
+		previous = salinity
+	}
+	water := g.Watering().GetWaterStats().BySection["section-A"]
+	if expected := 0.2 * water; math.Abs(previous-expected) > 1e-9 {
+		t.Errorf("expected a salinity of %.3f for %.2f water, got %.3f", expected, water, previous)
+	}
+	if reading, err := g.Sensors().GetReading("salinity-A"); err != nil || reading.Value != previous {
+		t.Errorf("expected the sensor to read %.3f, got %+v and %v", previous, reading, err)
+	}
+	if lettuce, sprout := plant("lettuce"), plant("sprout"); lettuce.Health >= sprout.Health || sprout.Health != 1 {
+		t.Errorf("expected the salt to stress only the lettuce, got health %.3f and %.3f", lettuce.Health, sprout.Health)
+	}
+
+	// A flush washes most of the salt out, and the lettuce stops suffering
+	// once the soil is within its tolerance.
+	if err := g.Watering().WaterSection("section-A", 1.2, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g.Simulator().Step()
+	flushed := g.SectionConditions("section-A").Salinity
+	if math.Abs(flushed-previous*0.2) > 1e-9 {
+		t.Errorf("expected the flush to leave %.3f, got %.3f", previous*0.2, flushed)
+	}
+	health := plant("lettuce").Health
+	g.Simulator().Step()
+	if plant("lettuce").Health != health {
+		t.Errorf("expected the lettuce to stop losing health at %.3f, got %.3f", health, plant("lettuce").Health)
+	}
+}
+
+func TestSalinity_ExactResume(t *testing.T) {
+	cfg := salinityConfig()
+	cfg.Sections = []config.SectionConfig{{ID: "section-B", Salinity: 0.3}}
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	if err := g.Watering().WaterSection("section-A", 0.1, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g.Simulator().Step()
+	salinity := g.SectionConditions("section-A").Salinity
+
+	tests := []struct {
+		name     string
+		opts     config.ExportOptions
+		expected []config.SectionConfig
+	}{
+		{"as configured", config.ExportOptions{}, []config.SectionConfig{{ID: "section-B", Salinity: 0.3}}},
+		{"exact resume", config.ExportOptions{ExactResume: true},
+			[]config.SectionConfig{{ID: "section-A", Salinity: salinity}, {ID: "section-B", Salinity: 0.3}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exported, err := g.ExportScenario(tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(exported.Sections) != len(tt.expected) {
+				t.Fatalf("expected sections %+v, got %+v", tt.expected, exported.Sections)
+			}
+			for i, section := range exported.Sections {
+				if section != tt.expected[i] {
+					t.Errorf("expected sections %+v, got %+v", tt.expected, exported.Sections)
+				}
+			}
+			resumed, err := New(exported)
+			if err != nil {
+				t.Fatalf("failed to resume: %v", err)
+			}
+			if got := resumed.SectionConditions("section-B").Salinity; got != 0.3 {
+				t.Errorf("expected section-B to resume at 0.3, got %.3f", got)
+			}
+		})
+	}
+}
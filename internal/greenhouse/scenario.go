@@ -0,0 +1,161 @@
+package greenhouse
+
+import (
+	"encoding/json"
+	"errors"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/events"
+	"io"
+	"slices"
+	"strings"
+)
+
+// ScenarioResult summarises a headless scenario run.
+type ScenarioResult struct {
+	Ticks         int                 `json:"ticks"`
+	Seed          int64               `json:"seed"`
+	Plants        []PlantResult       `json:"plants"`
+	WaterUsed     float64             `json:"water_used"`
+	WaterWasted   float64             `json:"water_wasted"`
+	TankRemaining *float64            `json:"tank_remaining,omitempty"`
+	Events        map[events.Type]int `json:"events"`
+	Timeline      []ActionResult      `json:"timeline,omitempty"`
+	Costs         *CostSummary        `json:"costs,omitempty"`
+}
+
+// CostSummary is the cost ledger at the end of a scenario run with the yield
+// it paid for: the growth stage of the plants still alive, which is what
+// harvesting them would give. CostPerYield is the total cost divided by the
+// yield, or 0 without any yield.
+type CostSummary struct {
+	CostLedger
+	Yield        float64 `json:"yield"`
+	CostPerYield float64 `json:"cost_per_yield"`
+}
+
+// PlantResult is a plant's state at the end of a scenario run. MaturedAt is
+// the tick on which its growth stage reached 1, nil if it never did.
+type PlantResult struct {
+	ID             string  `json:"id"`
+	Type           string  `json:"type"`
+	SectionID      string  `json:"section"`
+	SoilSaturation float64 `json:"soil_saturation"`
+	Health         float64 `json:"health"`
+	GrowthStage    float64 `json:"growth_stage"`
+	Alive          bool    `json:"alive"`
+	MaturedAt      *int    `json:"matured_at,omitempty"`
+}
+
+// ScenarioRecorder is the name RunScenario registers its RunRecorder under.
+const ScenarioRecorder = "record"
+
+// ScenarioOptions configures RunScenario.
+type ScenarioOptions struct {
+	// Record receives a CSV row per plant per tick when set, see RunRecorder.
+	Record        io.Writer
+	RecordOptions RecordOptions
+	// World receives the WorldState at the end of the run as indented JSON
+	// when set.
+	World io.Writer
+}
+
+// RunScenario builds a greenhouse from cfg and steps it ticks times without
+// waiting for the tick interval, then reports the final plant states with
+// the tick each plant matured on, the water accounting, how many events of
+// each type were published and which timeline actions ran, plus a cost
+// summary when cfg sets prices.
+// Returns an error if ticks is negative, the greenhouse cannot be built or
+// the run or its world state cannot be recorded.
+func RunScenario(cfg *config.GreenhouseConfig, ticks int, opts ScenarioOptions) (*ScenarioResult, error) {
+	if ticks < 0 {
+		return nil, errors.New("scenario ticks cannot be negative")
+	}
+	g, err := New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	result := &ScenarioResult{
+		Ticks:  ticks,
+		Seed:   cfg.Seed,
+		Events: map[events.Type]int{},
+	}
+	g.Bus().Subscribe(func(e events.Event) {
+		result.Events[e.Type]++
+		if action, ok := e.Payload.(ActionResult); ok {
+			result.Timeline = append(result.Timeline, action)
+		}
+	})
+	if opts.Record != nil {
+		recorder, err := NewRunRecorder(g, opts.Record, opts.RecordOptions)
+		if err != nil {
+			return nil, err
+		}
+		if err := g.Exporters().Register(ScenarioRecorder, recorder, 0); err != nil {
+			return nil, err
+		}
+	}
+	matured := map[string]int{}
+	for tick := range ticks {
+		g.Simulator().Step()
+		// Nothing waits for real time here, so the run waits for the
+		// exporters instead of letting their queues overflow.
+		g.Exporters().Drain()
+		for _, plant := range g.Simulator().GetAllPlants() {
+			if _, ok := matured[plant.ID]; !ok && plant.GrowthStage >= 1 {
+				matured[plant.ID] = tick
+			}
+		}
+	}
+	if err := g.Exporters().Close(0); err != nil {
+		return nil, err
+	}
+	if opts.World != nil {
+		state, err := g.BuildWorldState()
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.MarshalIndent(state, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if _, err := opts.World.Write(append(data, '\n')); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, plant := range g.Simulator().GetAllPlants() {
+		plantResult := PlantResult{
+			ID:             plant.ID,
+			Type:           plant.Type.Name,
+			SectionID:      plant.SectionID,
+			SoilSaturation: plant.SoilSaturation,
+			Health:         plant.Health,
+			GrowthStage:    plant.GrowthStage,
+			Alive:          plant.Alive,
+		}
+		if tick, ok := matured[plant.ID]; ok {
+			plantResult.MaturedAt = &tick
+		}
+		result.Plants = append(result.Plants, plantResult)
+	}
+	slices.SortFunc(result.Plants, func(a, b PlantResult) int { return strings.Compare(a.ID, b.ID) })
+	stats := g.Watering().GetWaterStats()
+	result.WaterUsed = stats.Used
+	result.WaterWasted = stats.Wasted
+	if !stats.Unlimited {
+		result.TankRemaining = &stats.Remaining
+	}
+	if cfg.Prices != nil {
+		summary := &CostSummary{CostLedger: g.Costs()}
+		for _, plant := range result.Plants {
+			if plant.Alive {
+				summary.Yield += plant.GrowthStage
+			}
+		}
+		if summary.Yield > 0 {
+			summary.CostPerYield = summary.Total.Total / summary.Yield
+		}
+		result.Costs = summary
+	}
+	return result, nil
+}
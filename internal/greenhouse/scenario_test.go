@@ -0,0 +1,103 @@
+package greenhouse
+
+import (
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRunScenario(t *testing.T) {
+	cfg := testConfig()
+	cfg.Seed = 7
+	cfg.Schedules[0].TargetSaturation = 0.9
+
+	result, err := RunScenario(cfg, 12, ScenarioOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Ticks != 12 || result.Seed != 7 {
+		t.Errorf("expected 12 ticks with seed 7, got %d ticks with seed %d", result.Ticks, result.Seed)
+	}
+	if len(result.Plants) != 2 || result.Plants[0].ID != "basil-1" || result.Plants[1].ID != "basil-2" {
+		t.Errorf("expected plants ordered by ID, got %+v", result.Plants)
+	}
+	if result.Events[events.WateringStarted] == 0 || result.WaterUsed == 0 {
+		t.Errorf("expected the schedule to water, got events %v and %.2f used", result.Events, result.WaterUsed)
+	}
+	if result.TankRemaining != nil {
+		t.Errorf("expected no tank, got %.2f remaining", *result.TankRemaining)
+	}
+
+	again, err := RunScenario(cfg, 12, ScenarioOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, again) {
+		t.Errorf("expected identical runs\nfirst:  %+v\nsecond: %+v", result, again)
+	}
+}
+
+func TestRunScenario_NegativeTicks(t *testing.T) {
+	if _, err := RunScenario(testConfig(), -1, ScenarioOptions{}); err == nil || err.Error() != "scenario ticks cannot be negative" {
+		t.Errorf("expected a negative ticks error, got %v", err)
+	}
+}
+
+func TestRunScenario_MaturedAt(t *testing.T) {
+	cfg := testConfig()
+	cfg.PlantTypes[0].BaseGrowthRate = 0.2
+	cfg.PlantTypes = append(cfg.PlantTypes, config.PlantTypeConfig{Name: "Slow", OptimalSaturation: 0.6, MinSaturation: 0.3, MaxSaturation: 0.8, BaseGrowthRate: 0.001})
+	cfg.Plants[0].InitialSaturation = 0.6
+	cfg.Plants[1].Type = "Slow"
+
+	result, err := RunScenario(cfg, 20, ScenarioOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fast, slow := result.Plants[0], result.Plants[1]
+	if fast.MaturedAt == nil || *fast.MaturedAt >= 20 || fast.GrowthStage < 1 {
+		t.Errorf("expected basil-1 to mature within 20 ticks, got %+v", fast)
+	}
+	if slow.MaturedAt != nil {
+		t.Errorf("expected basil-2 not to mature, got tick %d", *slow.MaturedAt)
+	}
+}
+
+func TestAutoSchedules_KeepSaturationInRange(t *testing.T) {
+	cfg := &config.GreenhouseConfig{
+		TickInterval: config.Duration(time.Second),
+		Environment:  config.EnvironmentConfig{Temperature: 20},
+		Schedules:    config.ScheduleList{{Auto: true}},
+	}
+	for _, plantType := range models.PresetPlantTypes() {
+		for i := range 3 {
+			cfg.Plants = append(cfg.Plants, config.PlantConfig{
+				ID:                fmt.Sprintf("%s-%d", plantType.Name, i),
+				Type:              plantType.Name,
+				SectionID:         plantType.Name,
+				InitialSaturation: plantType.OptimalSaturation,
+			})
+		}
+	}
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	for tick := range 1000 {
+		g.Simulator().Step()
+		for _, plant := range g.Simulator().GetAllPlants() {
+			if plant.SoilSaturation < plant.Type.MinSaturation {
+				t.Fatalf("tick %d: expected %s never to drop below %.2f, got %.3f", tick, plant.ID, plant.Type.MinSaturation, plant.SoilSaturation)
+			}
+		}
+	}
+	for _, plant := range g.Simulator().GetAllPlants() {
+		if !plant.Alive || plant.Health != 1 {
+			t.Errorf("expected %s to thrive on its recommended schedule, got %v", plant.ID, plant)
+		}
+	}
+}
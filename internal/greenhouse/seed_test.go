@@ -0,0 +1,76 @@
+package greenhouse
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"runtime"
+	"testing"
+)
+
+// goldenRunHash is the fingerprint of 500 ticks of goldenConfig. A change to
+// the simulation that alters any state, event or reading changes it; update
+// it when that is intended, with the value the failing test reports.
+const goldenRunHash = "2778efce4aec45b88e338d3a1549bd2aa4f685654044c67ed0bb9a1acda703f5"
+
+// goldenConfig is the demo greenhouse with weather and noisy sensors, so that
+// every random stream feeds into the run.
+func goldenConfig(seed int64) *config.GreenhouseConfig {
+	cfg := config.Default()
+	cfg.Seed = seed
+	cfg.Environment.TicksPerDay = 24
+	cfg.Environment.Temperature = 20
+	cfg.Environment.TemperatureSwing = 4
+	cfg.Environment.Light = 0.8
+	cfg.Environment.CloudyChance = 0.3
+	cfg.Environment.RainChance = 0.2
+	cfg.Environment.FrostChance = 0.1
+	cfg.Environment.HeatWaveChance = 0.1
+	cfg.Environment.ExtremeTicks = 4
+	cfg.Sensors[0].Noise = 0.05
+	cfg.Sensors = append(cfg.Sensors, config.SensorConfig{ID: "sensor-2", Type: models.SoilMoisture, SectionID: "section-A", Noise: 0.1})
+	return cfg
+}
+
+// fingerprintRun runs cfg for the given number of ticks and hashes every
+// published event, the conditions and the state of every plant after each
+// tick. Wall-clock timestamps are left out.
+func fingerprintRun(t *testing.T, cfg *config.GreenhouseConfig, ticks int) string {
+	t.Helper()
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	return fingerprint(g, ticks)
+}
+
+// fingerprint hashes the next ticks of g the way fingerprintRun does.
+func fingerprint(g Greenhouse, ticks int) string {
+	h := sha256.New()
+	unsubscribe := g.Bus().Subscribe(func(e events.Event) {
+		writeEvent(h, e)
+	})
+	for range ticks {
+		g.Simulator().Step()
+		writeState(h, g)
+	}
+	unsubscribe()
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func TestSeed_GoldenRun(t *testing.T) {
+	first := fingerprintRun(t, goldenConfig(42), 500)
+	if second := fingerprintRun(t, goldenConfig(42), 500); first != second {
+		t.Fatalf("expected the same config and seed to give identical runs, got %s and %s", first, second)
+	}
+	if other := fingerprintRun(t, goldenConfig(43), 500); other == first {
+		t.Error("expected another seed to give another run")
+	}
+	// Other architectures may fuse floating point operations, which changes
+	// the last bits of the state but not the reproducibility checked above.
+	if runtime.GOARCH == "amd64" && first != goldenRunHash {
+		t.Errorf("expected the golden run to hash to %s, got %s", goldenRunHash, first)
+	}
+}
@@ -0,0 +1,134 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/models"
+	"testing"
+	"time"
+)
+
+func TestSoil_SandDriesFasterThanClay(t *testing.T) {
+	cfg := &config.GreenhouseConfig{
+		TickInterval: config.Duration(time.Second),
+		Plants: []config.PlantConfig{
+			{ID: "sand-1", Type: "Tomato", SectionID: "sand", InitialSaturation: 0.8},
+			{ID: "clay-1", Type: "Tomato", SectionID: "clay", InitialSaturation: 0.8},
+			{ID: "plain-1", Type: "Tomato", SectionID: "plain", InitialSaturation: 0.8},
+		},
+		Sections: []config.SectionConfig{
+			{ID: "sand", Soil: "Sand"},
+			{ID: "clay", Soil: "Clay"},
+		},
+	}
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	for range 5 {
+		g.Simulator().Step()
+	}
+
+	saturation := map[string]float64{}
+	for _, plant := range g.Simulator().GetAllPlants() {
+		saturation[plant.SectionID] = plant.SoilSaturation
+	}
+	// Tomatoes deplete 0.04 a tick: sand drains 1.6 times as fast, clay 0.6
+	// times, and plain soil keeps the plant's own rate.
+	expected := map[string]float64{"sand": 0.48, "clay": 0.68, "plain": 0.6}
+	for section, want := range expected {
+		if got := saturation[section]; got < want-0.001 || got > want+0.001 {
+			t.Errorf("expected the %s plant at saturation %.2f after 5 ticks, got %.3f", section, want, got)
+		}
+	}
+
+	for _, section := range []string{"sand", "clay"} {
+		if err := g.Watering().WaterSection(section, 0.2, 0); err != nil {
+			t.Fatalf("failed to water %s: %v", section, err)
+		}
+	}
+	g.Simulator().Step()
+	for _, plant := range g.Simulator().GetAllPlants() {
+		saturation[plant.SectionID] = plant.SoilSaturation
+	}
+	// Sand only retains 70% of the water, clay all of it.
+	expected = map[string]float64{"sand": 0.48 + 0.14 - 0.064, "clay": 0.68 + 0.2 - 0.024}
+	for section, want := range expected {
+		if got := saturation[section]; got < want-0.001 || got > want+0.001 {
+			t.Errorf("expected the %s plant at saturation %.3f after watering, got %.3f", section, want, got)
+		}
+	}
+}
+
+// layeredConfig has a seedling and a mature plant of a deep rooted type in a
+// layered section whose surface is bone dry and whose deep layer is wet.
+func layeredConfig() *config.GreenhouseConfig {
+	deep := func(growth float64) *config.PlantStateConfig {
+		return &config.PlantStateConfig{Health: 1, GrowthStage: growth, Alive: true, DeepSaturation: 0.7}
+	}
+	return &config.GreenhouseConfig{
+		TickInterval: config.Duration(time.Second),
+		PlantTypes: []config.PlantTypeConfig{
+			{Name: "Oak", OptimalSaturation: 0.6, MinSaturation: 0.3, MaxSaturation: 0.9, BaseGrowthRate: 0.001, SaturationDepletion: 0.01, HealthDegradationRate: 0.1, HealthEnhancementRate: 0.01, RootDepth: 0.9},
+		},
+		Plants: []config.PlantConfig{
+			{ID: "mature", Type: "Oak", SectionID: "grove", State: deep(1)},
+			{ID: "seedling", Type: "Oak", SectionID: "grove", State: deep(0)},
+		},
+		Sections: []config.SectionConfig{{ID: "grove", Soil: "Loam", Percolation: 0.1}},
+		Sensors: []config.SensorConfig{
+			{ID: "surface", Type: models.SoilMoisture, SectionID: "grove"},
+			{ID: "deep", Type: models.SoilMoisture, SectionID: "grove", Depth: models.Deep},
+		},
+	}
+}
+
+func TestSoil_DeepRootsSurviveSurfaceDrought(t *testing.T) {
+	g, err := New(layeredConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	for range 20 {
+		g.Simulator().Step()
+	}
+	mature, seedling := g.Simulator().GetAllPlants()[0], g.Simulator().GetAllPlants()[1]
+	if !mature.Alive || mature.Health != 1 {
+		t.Errorf("expected the mature plant to live off the deep layer, got %v", mature)
+	}
+	if seedling.Alive {
+		t.Errorf("expected the seedling to die of the dry surface, got %v", seedling)
+	}
+	// The mature plant draws 0.9 of its 0.01 a tick from the deep layer.
+	if got := mature.DeepSaturation; got < 0.7-20*0.009-0.001 || got > 0.7-20*0.009+0.001 {
+		t.Errorf("expected the mature plant's deep layer at %.2f, got %.3f", 0.7-20*0.009, got)
+	}
+	surface, _ := g.Sensors().GetReading("surface")
+	deep, _ := g.Sensors().GetReading("deep")
+	if surface.Value != 0 || deep.Value <= 0.5 {
+		t.Errorf("expected a dry surface over a wet deep layer, got %.2f and %.2f", surface.Value, deep.Value)
+	}
+}
+
+func TestSoil_WaterPercolates(t *testing.T) {
+	cfg := layeredConfig()
+	cfg.Plants = cfg.Plants[1:]
+	cfg.Plants[0].State.DeepSaturation = 0.2
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	if err := g.Watering().WaterSection("grove", 0.5, 0); err != nil {
+		t.Fatalf("failed to water: %v", err)
+	}
+	g.Simulator().Step()
+	g.Simulator().Step()
+	// Watered after the first tick's update, the seedling draws its 0.01 off
+	// the surface on the second, a tenth of which then seeps down.
+	plant := g.Simulator().GetAllPlants()[0]
+	surface := 0.5 - 0.01
+	if want := surface * 0.9; plant.SoilSaturation < want-0.001 || plant.SoilSaturation > want+0.001 {
+		t.Errorf("expected the surface at %.3f, got %.3f", want, plant.SoilSaturation)
+	}
+	if want := 0.2 + surface*0.1; plant.DeepSaturation < want-0.001 || plant.DeepSaturation > want+0.001 {
+		t.Errorf("expected the deep layer at %.3f, got %.3f", want, plant.DeepSaturation)
+	}
+}
@@ -0,0 +1,53 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/environment"
+)
+
+// soilTemperature warms or cools the soil of each section towards the air of
+// its microclimate on a tick, and towards the irrigation water for the water
+// the watering controller applied to it, see environment.SoilTemperature.
+// Germinating seeds then learn the temperature of their soil, see
+// models.Plant.SetSoilTemperature. A greenhouse without soil temperature
+// settings has soil at the air temperature.
+type soilTemperature struct {
+	g     *greenhouse
+	model environment.SoilTemperature // nil without soil temperature settings
+	// water is the water applied to each section up to the last tick.
+	water map[string]float64
+}
+
+func newSoilTemperature(g *greenhouse, model environment.SoilTemperature) *soilTemperature {
+	return &soilTemperature{g: g, model: model, water: g.watering.GetWaterStats().BySection}
+}
+
+// TickPhase names the soil temperature in tick traces.
+func (s *soilTemperature) TickPhase() string { return "soil_temperature" }
+
+func (s *soilTemperature) OnTick(tick int) {
+	conditions := s.g.Conditions()
+	if s.model != nil {
+		water := s.g.watering.GetWaterStats().BySection
+		for _, sectionID := range s.g.sim.ListSectionIDs() {
+			air := s.g.SectionClimateOffset(sectionID).Apply(conditions).Temperature
+			s.model.Update(sectionID, air, water[sectionID]-s.water[sectionID])
+		}
+		s.water = water
+	}
+	plants := s.g.sim.GetAllPlants()
+	s.g.sim.UpdatePlants(func() {
+		for _, plant := range plants {
+			if !plant.Germinating() {
+				continue
+			}
+			temperature, ok := 0.0, false
+			if s.model != nil {
+				temperature, ok = s.model.Get(plant.SectionID)
+			}
+			if !ok {
+				temperature = s.g.SectionClimateOffset(plant.SectionID).Apply(conditions).Temperature
+			}
+			plant.SetSoilTemperature(temperature)
+		}
+	})
+}
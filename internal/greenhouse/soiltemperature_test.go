@@ -0,0 +1,101 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/environment"
+	"greenhouse-simulator/internal/models"
+	"math"
+	"testing"
+)
+
+// soilTemperatureConfig is weatherConfig, at a steady 12°C, with a soil
+// temperature sensor next to the thermometer.
+func soilTemperatureConfig() *config.GreenhouseConfig {
+	cfg := weatherConfig()
+	cfg.Sensors = append(cfg.Sensors, config.SensorConfig{ID: "soil-thermometer", Type: models.SoilTemperature, SectionID: "section-A"})
+	return cfg
+}
+
+func TestSoilTemperature_LagsAirStep(t *testing.T) {
+	cfg := soilTemperatureConfig()
+	cfg.SoilTemperature = &config.SoilTemperatureConfig{Inertia: 0.8}
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	for range 3 {
+		g.Simulator().Step()
+	}
+	if got := read(t, g, "soil-thermometer"); got != 12 {
+		t.Fatalf("expected the soil at the steady air temperature, got %.3f", got)
+	}
+
+	// The air of the section steps up by 10°C: the thermometer follows at
+	// once, the soil closes a fifth of the gap on every tick.
+	if err := g.SetSectionClimateOffset("section-A", environment.ClimateOffset{Temperature: 10}); err != nil {
+		t.Fatalf("failed to set the microclimate: %v", err)
+	}
+	for tick := 1; tick <= 10; tick++ {
+		g.Simulator().Step()
+		if air := read(t, g, "thermometer"); air != 22 {
+			t.Fatalf("tick %d: expected the air at 22°C, got %.3f", tick, air)
+		}
+		expected := 22 - 10*math.Pow(0.8, float64(tick))
+		if got := read(t, g, "soil-thermometer"); math.Abs(got-expected) > 1e-9 {
+			t.Errorf("tick %d: expected the soil at %.3f, got %.3f", tick, expected, got)
+		}
+	}
+}
+
+func TestSoilTemperature_DefaultsToAir(t *testing.T) {
+	g, err := New(soilTemperatureConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	g.Simulator().Step()
+	if err := g.SetSectionClimateOffset("section-A", environment.ClimateOffset{Temperature: 10}); err != nil {
+		t.Fatalf("failed to set the microclimate: %v", err)
+	}
+	g.Simulator().Step()
+	if air, soil := read(t, g, "thermometer"), read(t, g, "soil-thermometer"); soil != air {
+		t.Errorf("expected the soil at the air temperature %.3f without settings, got %.3f", air, soil)
+	}
+}
+
+func TestSoilTemperature_ColdSoilKeepsSeedsDormant(t *testing.T) {
+	cfg := germinationConfig(0.6, 0)
+	cfg.Environment.Temperature = 20
+	cfg.PlantTypes[0].GerminationMinSoilTemperature = 15
+	cfg.Microclimates = []config.MicroclimateConfig{{SectionID: "section-A", Temperature: -10}}
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	for range 10 {
+		g.Simulator().Step()
+	}
+	plant, err := g.Simulator().GetPlant("bean-00")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The first tick runs before the soil temperature is known.
+	if !plant.Germinating() || plant.Germination.Ticks != 1 || !plant.Germination.Dormant {
+		t.Fatalf("expected the seed to lie dormant in soil at 10°C, got %+v", plant.Germination)
+	}
+
+	if err := g.SetSectionClimateOffset("section-A", environment.ClimateOffset{}); err != nil {
+		t.Fatalf("failed to clear the microclimate: %v", err)
+	}
+	for range 6 {
+		g.Simulator().Step()
+	}
+	sprouted := 0
+	for _, plant := range g.Simulator().GetAllPlants() {
+		if !plant.Germinating() {
+			sprouted++
+		}
+	}
+	if sprouted != 100 {
+		t.Errorf("expected every seed to sprout once the soil warms up, got %d", sprouted)
+	}
+}
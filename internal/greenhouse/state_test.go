@@ -0,0 +1,70 @@
+package greenhouse
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/events"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPauseResume_PublishesStateChanges(t *testing.T) {
+	g, err := New(weatherConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	var changes []engine.StateChange
+	var mu sync.Mutex
+	g.Bus().Subscribe(func(e events.Event) {
+		if change, ok := e.Payload.(engine.StateChange); ok && e.Type == events.SimulatorStateChanged {
+			mu.Lock()
+			changes = append(changes, change)
+			mu.Unlock()
+		}
+	})
+
+	if err := g.Pause(); !errors.Is(err, engine.ErrNotStarted) {
+		t.Errorf("expected pausing a simulation that was not started to fail, got %v", err)
+	}
+	sim := g.Simulator()
+	ended := make(chan error, 1)
+	go func() { ended <- sim.Start() }()
+	for sim.State() != engine.Running {
+		time.Sleep(time.Millisecond)
+	}
+	if err := g.Pause(); err != nil {
+		t.Fatalf("failed to pause: %v", err)
+	}
+	if !g.Paused() {
+		t.Error("expected the simulation to be paused")
+	}
+	if err := g.Pause(); !errors.Is(err, ErrAlreadyPaused) {
+		t.Errorf("expected pausing twice to fail, got %v", err)
+	}
+	if err := g.Resume(); err != nil {
+		t.Fatalf("failed to resume: %v", err)
+	}
+	if err := sim.Stop(); err != nil {
+		t.Fatalf("failed to stop: %v", err)
+	}
+	if err := <-ended; err != nil {
+		t.Fatalf("expected the loop to end without error, got %v", err)
+	}
+	if err := g.Resume(); !errors.Is(err, ErrNotPaused) {
+		t.Errorf("expected resuming a stopped simulation to fail, got %v", err)
+	}
+
+	expected := []engine.StateChange{
+		{From: engine.Created, To: engine.Running},
+		{From: engine.Running, To: engine.Paused},
+		{From: engine.Paused, To: engine.Running},
+		{From: engine.Running, To: engine.Stopped},
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("expected the state changes %v, got %v", expected, changes)
+	}
+}
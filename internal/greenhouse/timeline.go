@@ -0,0 +1,145 @@
+package greenhouse
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/events"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ActionResult records a timeline action that ran. Target names what the
+// action applied to, and Error is set when it failed.
+type ActionResult struct {
+	Tick   int               `json:"tick"`
+	Action config.ActionType `json:"action"`
+	Target string            `json:"target"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// timeline runs the scripted actions of the config as the simulation reaches
+// their ticks. Actions sharing a tick run in config order.
+type timeline struct {
+	g       *greenhouse
+	actions []config.ActionConfig
+	next    int
+	ambient float64
+	decay   float64
+}
+
+func newTimeline(g *greenhouse, cfg *config.GreenhouseConfig) *timeline {
+	actions := slices.Clone(cfg.Timeline)
+	slices.SortStableFunc(actions, func(a, b config.ActionConfig) int { return a.Tick - b.Tick })
+	return &timeline{
+		g:       g,
+		actions: actions,
+		ambient: cfg.Environment.AmbientHumidity,
+		decay:   cfg.Environment.HumidityDecay,
+	}
+}
+
+// TickPhase names the timeline in tick traces.
+func (t *timeline) TickPhase() string { return "timeline" }
+
+// OnTick runs the actions that are due and publishes a TimelineAction event
+// carrying the ActionResult of each. A failed action does not stop the
+// timeline.
+func (t *timeline) OnTick(tick int) {
+	for t.next < len(t.actions) && t.actions[t.next].Tick <= tick {
+		action := t.actions[t.next]
+		t.next++
+		target, err := t.run(action)
+		result := ActionResult{Tick: tick, Action: action.Action, Target: target}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		t.g.bus.Publish(events.Event{
+			Type:      events.TimelineAction,
+			Tick:      tick,
+			Timestamp: time.Now(),
+			SectionID: action.SectionID,
+			PlantID:   action.PlantID,
+			Payload:   result,
+		})
+	}
+}
+
+func (t *timeline) run(action config.ActionConfig) (string, error) {
+	g := t.g
+	switch action.Action {
+	case config.ActionAddPlant:
+		var ids []string
+		for _, p := range action.Plants() {
+			ids = append(ids, p.ID)
+		}
+		return strings.Join(ids, ","), g.addTimelinePlants(action)
+	case config.ActionRemovePlant:
+		return action.PlantID, g.RemovePlant(action.PlantID)
+	case config.ActionWater:
+		return action.SectionID, g.watering.WaterSection(action.SectionID, action.Amount, time.Duration(action.Duration))
+	case config.ActionFailSensor:
+		return action.SensorID, g.sensors.FailSensor(action.SensorID)
+	case config.ActionSetEnvironment:
+		ambient, decay := t.ambient, t.decay
+		if action.AmbientHumidity != nil {
+			ambient = *action.AmbientHumidity
+		}
+		if action.HumidityDecay != nil {
+			decay = *action.HumidityDecay
+		}
+		if err := g.humidity.SetAmbient(ambient, decay); err != nil {
+			return "environment", err
+		}
+		t.ambient, t.decay = ambient, decay
+		return "environment", nil
+	case config.ActionPause, config.ActionResume:
+		return action.ScheduleID, g.setScheduleEnabled(action.ScheduleID, action.Action == config.ActionResume)
+	case config.ActionWeatherEvent:
+		return string(action.Extreme), g.TriggerWeatherEvent(action.Extreme, action.Ticks)
+	case config.ActionSetLights:
+		return action.SectionID, g.SetLights(action.SectionID, action.On, action.Intensity)
+	case config.ActionSetActuator:
+		return string(action.Actuator), g.SetActuator(action.Actuator, action.Mode)
+	case config.ActionInfectPlant:
+		return action.PlantID, g.InfectPlant(action.PlantID)
+	case config.ActionTreatDisease:
+		return action.SectionID, g.TreatDisease(action.SectionID)
+	case config.ActionPrunePlant:
+		return action.PlantID, g.sim.PrunePlant(action.PlantID, action.Fraction)
+	case config.ActionThinSection:
+		removed, err := g.ThinSection(action.SectionID, action.Keep)
+		return strings.Join(removed, ","), err
+	case config.ActionSetPlantFlags:
+		return action.PlantID, g.sim.SetPlantFlags(action.PlantID, action.Flags())
+	case config.ActionInjectAnomaly:
+		target := action.SensorID
+		if target == "" {
+			target = action.SectionID
+		}
+		return target, g.sensors.InjectAnomaly(target, action.AnomalySpec())
+	}
+	return "", errors.New("unknown action type: " + string(action.Action))
+}
+
+// addTimelinePlants adds the plants of an add_plant action, stopping at the
+// first one that cannot be added.
+func (g *greenhouse) addTimelinePlants(action config.ActionConfig) error {
+	for _, plant := range action.Plants() {
+		if _, err := g.AddPlant(plant); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setScheduleEnabled enables or disables a live watering schedule.
+func (g *greenhouse) setScheduleEnabled(scheduleID string, enabled bool) error {
+	for _, schedule := range g.watering.Snapshot().Schedules {
+		if schedule.ID == scheduleID {
+			schedule.Enabled = enabled
+			return g.watering.UpdateSchedule(schedule)
+		}
+	}
+	return errors.New("no schedule found for the provided ID: " + scheduleID)
+}
@@ -0,0 +1,209 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"greenhouse-simulator/internal/sensors"
+	"reflect"
+	"testing"
+)
+
+func loadTimelineGreenhouse(t *testing.T) (*config.GreenhouseConfig, Greenhouse) {
+	t.Helper()
+	cfg, err := config.LoadConfig("testdata/timeline.yaml")
+	if err != nil {
+		t.Fatalf("failed to load scenario: %v", err)
+	}
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	return cfg, g
+}
+
+func scheduleEnabled(t *testing.T, g Greenhouse, scheduleID string) bool {
+	t.Helper()
+	for _, schedule := range g.Watering().Snapshot().Schedules {
+		if schedule.ID == scheduleID {
+			return schedule.Enabled
+		}
+	}
+	t.Fatalf("schedule %s not found", scheduleID)
+	return false
+}
+
+func TestTimeline_RunsActionsAtTheirTicks(t *testing.T) {
+	_, g := loadTimelineGreenhouse(t)
+	step := func(until int) {
+		for g.Simulator().GetCurrentTick() <= until {
+			g.Simulator().Step()
+		}
+	}
+
+	step(2)
+	if !scheduleEnabled(t, g, "section-A") {
+		t.Error("expected the schedule to run before tick 3")
+	}
+	step(3)
+	if scheduleEnabled(t, g, "section-A") {
+		t.Error("expected the schedule to be paused at tick 3")
+	}
+
+	if _, err := g.Sensors().GetReading("sensor-3"); err != nil {
+		t.Fatalf("expected sensor-3 to read before tick 4, got %v", err)
+	}
+	step(4)
+	if _, err := g.Sensors().GetReading("sensor-3"); err == nil {
+		t.Error("expected sensor-3 to fail at tick 4")
+	}
+
+	before := g.Simulator().GetPlantsBySectionID("section-B")[0].SoilSaturation
+	step(5)
+	if after := g.Simulator().GetPlantsBySectionID("section-B")[0].SoilSaturation; after <= before {
+		t.Errorf("expected section-B to be watered at tick 5, saturation went from %.2f to %.2f", before, after)
+	}
+
+	step(6)
+	if added := g.Simulator().GetPlantsBySectionID("section-C"); len(added) != 5 {
+		t.Errorf("expected 5 lettuces in section-C, got %d", len(added))
+	}
+	step(7)
+	if plants := g.Simulator().GetPlantsBySectionID("section-A"); len(plants) != 1 || plants[0].ID != "tomato-1" {
+		t.Errorf("expected only tomato-1 left in section-A, got %v", plants)
+	}
+
+	step(8)
+	if got := g.Humidity().Get("section-Z"); got != 0.8 {
+		t.Errorf("expected the ambient humidity to be 0.8, got %.2f", got)
+	}
+	step(9)
+	if !scheduleEnabled(t, g, "section-A") {
+		t.Error("expected the schedule to resume at tick 9")
+	}
+}
+
+func TestTimeline_ScenarioReportsActions(t *testing.T) {
+	cfg, _ := loadTimelineGreenhouse(t)
+
+	result, err := RunScenario(cfg, 12, ScenarioOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []ActionResult{
+		{Tick: 3, Action: config.ActionPause, Target: "section-A"},
+		{Tick: 4, Action: config.ActionFailSensor, Target: "sensor-3"},
+		{Tick: 5, Action: config.ActionWater, Target: "section-B"},
+		{Tick: 6, Action: config.ActionAddPlant, Target: "new-lettuce-1,new-lettuce-2,new-lettuce-3,new-lettuce-4,new-lettuce-5"},
+		{Tick: 7, Action: config.ActionRemovePlant, Target: "tomato-2"},
+		{Tick: 8, Action: config.ActionSetEnvironment, Target: "environment"},
+		{Tick: 9, Action: config.ActionResume, Target: "section-A"},
+		{Tick: 9, Action: config.ActionRemovePlant, Target: "ghost", Error: "no plant found for the provided ID: ghost"},
+	}
+	if len(result.Timeline) != len(expected) {
+		t.Fatalf("expected %d timeline results, got %+v", len(expected), result.Timeline)
+	}
+	for i := range expected {
+		if result.Timeline[i] != expected[i] {
+			t.Errorf("action %d: expected %+v, got %+v", i, expected[i], result.Timeline[i])
+		}
+	}
+	if len(result.Plants) != 7 {
+		t.Errorf("expected 7 plants at the end of the run, got %d", len(result.Plants))
+	}
+}
+
+func TestTimeline_ReloadKeepsTimelineChanges(t *testing.T) {
+	cfg, g := loadTimelineGreenhouse(t)
+	for range 8 {
+		g.Simulator().Step()
+	}
+
+	reloaded := *cfg
+	summary, err := g.ReloadConfig(&reloaded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(summary.AddedPlants) != 0 {
+		t.Errorf("expected the removed tomato to stay removed, got %v", summary.AddedPlants)
+	}
+
+	changed := *cfg
+	changed.Timeline = changed.Timeline[:1]
+	if _, err := g.ReloadConfig(&changed); err == nil || err.Error() != "timeline cannot change while the simulation runs" {
+		t.Errorf("expected a timeline change to be refused, got %v", err)
+	}
+}
+
+func TestTimeline_ExcludedPlantDriesOut(t *testing.T) {
+	excluded := true
+	cfg := testConfig()
+	cfg.Plants = append(cfg.Plants, config.PlantConfig{ID: "basil-3", Type: "Basil", SectionID: "section-A", InitialSaturation: 0.4})
+	cfg.Schedules[0].TargetSaturation, cfg.Schedules[0].CheckInterval = 0.5, 1
+	cfg.Timeline = []config.ActionConfig{{Action: config.ActionSetPlantFlags, PlantID: "basil-2", ExcludeFromWatering: &excluded}}
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	for range 20 {
+		g.Simulator().Step()
+	}
+
+	for _, plant := range g.Simulator().GetPlantsBySectionID("section-A") {
+		if plant.ID == "basil-2" {
+			if !plant.ExcludeFromWatering || plant.SoilSaturation >= 0.4 {
+				t.Errorf("expected the excluded plant to dry out, got %+v", plant)
+			}
+		} else if plant.SoilSaturation <= 0.4 {
+			t.Errorf("expected %s to be watered, got saturation %.2f", plant.ID, plant.SoilSaturation)
+		}
+	}
+}
+
+func TestTimeline_InjectsAnomalies(t *testing.T) {
+	cfg := testConfig()
+	cfg.Timeline = []config.ActionConfig{
+		{Tick: 5, Action: config.ActionInjectAnomaly, SensorID: "sensor-1", Anomaly: sensors.AnomalyDropout, Ticks: 3},
+		{Tick: 10, Action: config.ActionInjectAnomaly, SectionID: "section-A", Anomaly: sensors.AnomalySpike, Magnitude: 0.3, Ticks: 2},
+	}
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	samples := map[int]float64{}
+	g.Bus().Subscribe(func(e events.Event) {
+		if e.Type == events.SensorSample {
+			reading := e.Payload.(models.SensorReading)
+			samples[reading.Tick] = reading.Value
+		}
+	})
+	for range 15 {
+		g.Simulator().Step()
+	}
+
+	// The actions of a tick apply to the samples taken at its end.
+	labels, err := g.Sensors().GetAnomalyLabels("sensor-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []sensors.AnomalyLabel{{Tick: 6, Kind: sensors.AnomalyDropout}, {Tick: 7, Kind: sensors.AnomalyDropout}, {Tick: 8, Kind: sensors.AnomalyDropout}, {Tick: 11, Kind: sensors.AnomalySpike}, {Tick: 12, Kind: sensors.AnomalySpike}}
+	if !reflect.DeepEqual(labels, expected) {
+		t.Errorf("expected labels %v, got %v", expected, labels)
+	}
+	for tick := 1; tick <= 15; tick++ {
+		_, sampled := samples[tick]
+		if dropped := tick >= 6 && tick <= 8; sampled == dropped {
+			t.Errorf("tick %d: expected a sample %t, got %t", tick, !dropped, sampled)
+		}
+	}
+	// The saturation of section-A barely changes from one tick to the next.
+	for _, tick := range []int{11, 12} {
+		if jump := samples[tick] - samples[10]; jump < 0.25 {
+			t.Errorf("tick %d: expected a spike of 0.3, got %.3f", tick, jump)
+		}
+	}
+	if jump := samples[13] - samples[10]; jump > 0.05 {
+		t.Errorf("expected the spike to be over at tick 13, got %.3f", jump)
+	}
+}
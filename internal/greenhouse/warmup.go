@@ -0,0 +1,29 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/models"
+)
+
+// warmUp runs a throwaway greenhouse built from cfg for cfg.WarmupTicks ticks
+// and returns copies of its plants as they are then. The throwaway
+// greenhouse has no exporters and no subscribers, so none of its events
+// reach those of the real one. The timeline is left out, as
+// the real run plays it from tick 0.
+func warmUp(cfg *config.GreenhouseConfig) ([]*models.Plant, error) {
+	warm := *cfg
+	warm.WarmupTicks = 0
+	warm.Timeline = nil
+	g, err := New(&warm)
+	if err != nil {
+		return nil, err
+	}
+	for range cfg.WarmupTicks {
+		g.Simulator().Step()
+	}
+	var plants []*models.Plant
+	for _, plant := range g.Simulator().GetAllPlants() {
+		plants = append(plants, plant.Clone())
+	}
+	return plants, nil
+}
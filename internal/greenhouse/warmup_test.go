@@ -0,0 +1,97 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/events"
+	"reflect"
+	"testing"
+)
+
+// warmupConfig waters section-A back up to 0.35 every 5 ticks, so the
+// warm-up has watering to do.
+func warmupConfig() *config.GreenhouseConfig {
+	cfg := testConfig()
+	cfg.Schedules[0].TargetSaturation = 0.35
+	return cfg
+}
+
+func TestWarmup_StartsWhereAnUnwarmedRunIsAfterIt(t *testing.T) {
+	const warmup = 40
+	cold, err := New(warmupConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	for range warmup {
+		cold.Simulator().Step()
+	}
+	if len(cold.Watering().GetWateringHistory("", 0)) == 0 {
+		t.Fatal("expected the schedule to water during the first ticks")
+	}
+
+	cfg := warmupConfig()
+	cfg.WarmupTicks = warmup
+	warm, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	if tick := warm.Simulator().GetCurrentTick(); tick != 0 {
+		t.Errorf("expected the run to start at tick 0, got %d", tick)
+	}
+	expected, got := cold.Stats(), warm.Stats()
+	if got.Plants != expected.Plants || got.AlivePlants != expected.AlivePlants || got.Died != expected.Died ||
+		got.AverageHealth != expected.AverageHealth || got.AverageSaturation != expected.AverageSaturation {
+		t.Errorf("expected the plants of tick %d, %+v, got %+v", warmup, expected, got)
+	}
+	for i, plant := range warm.Simulator().GetAllPlants() {
+		other := cold.Simulator().GetAllPlants()[i]
+		if plant.ID != other.ID || plant.GrowthStage != other.GrowthStage || plant.Health != other.Health ||
+			plant.SoilSaturation != other.SoilSaturation {
+			t.Errorf("expected %+v, got %+v", other, plant)
+		}
+	}
+
+	// The sensors, the watering and the water used start clean.
+	if history, err := warm.Sensors().GetHistory("sensor-1", 0); err != nil || len(history) != 0 {
+		t.Errorf("expected no sensor samples, got %+v, %v", history, err)
+	}
+	if history := warm.Watering().GetWateringHistory("", 0); len(history) != 0 {
+		t.Errorf("expected no watering history, got %+v", history)
+	}
+	if got.WaterUsed != 0 {
+		t.Errorf("expected no water used yet, got %.2f", got.WaterUsed)
+	}
+}
+
+func TestWarmup_EventsDoNotLeak(t *testing.T) {
+	cfg := warmupConfig()
+	cfg.WarmupTicks = 20
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	exporter := newFakeExporter()
+	if err := g.Exporters().Register("fake", exporter, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var ticks []int
+	g.Bus().Subscribe(func(e events.Event) {
+		ticks = append(ticks, e.Tick)
+	})
+	for range 3 {
+		g.Simulator().Step()
+	}
+	g.Exporters().Drain()
+
+	if handled := exporter.handledTicks(); !reflect.DeepEqual(handled, []int{0, 1, 2}) || exporter.readings != 3 {
+		t.Errorf("expected the exporter to get ticks 0 to 2 and their readings, got %v and %d readings", handled, exporter.readings)
+	}
+	for _, tick := range ticks {
+		if tick > 2 {
+			t.Fatalf("expected the events of ticks 0 to 2, got one of tick %d", tick)
+		}
+	}
+	history, err := g.Sensors().GetHistory("sensor-1", 0)
+	if err != nil || len(history) != 3 || history[0].HasDelta {
+		t.Errorf("expected three samples, the first without a delta, got %+v, %v", history, err)
+	}
+}
@@ -0,0 +1,88 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"math"
+	"testing"
+	"time"
+)
+
+// wateringConfig returns the test config with a plant in a second section and
+// the given watering settings.
+func wateringConfig(watering *config.WateringConfig) *config.GreenhouseConfig {
+	cfg := testConfig()
+	cfg.Plants = append(cfg.Plants, config.PlantConfig{ID: "mint-1", Type: "Mint", SectionID: "section-B", InitialSaturation: 0.4})
+	cfg.Watering = watering
+	return cfg
+}
+
+func TestWateringConfig_CapsConcurrentEvents(t *testing.T) {
+	g, err := New(wateringConfig(&config.WateringConfig{MaxConcurrentEvents: 1}))
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+
+	for _, sectionID := range []string{"section-A", "section-B"} {
+		if err := g.Watering().WaterSection(sectionID, 0.2, 5*time.Second); err != nil {
+			t.Fatalf("failed to water %s: %v", sectionID, err)
+		}
+	}
+	g.Simulator().Step()
+
+	if pending := g.Watering().ListPendingEvents(); len(pending) != 1 {
+		t.Errorf("expected 1 of the 2 watering events to wait in the queue, got %d", len(pending))
+	}
+}
+
+func TestWateringConfig_CancelCooldown(t *testing.T) {
+	cfg := wateringConfig(&config.WateringConfig{CancelCooldown: 3})
+	cfg.Schedules[0].TargetSaturation = 0.6
+	cfg.Schedules[0].CheckInterval = 1
+	cfg.Schedules[0].Duration = config.Duration(time.Hour)
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	var started []int
+	g.Bus().Subscribe(func(e events.Event) {
+		if e.Type == events.WateringStarted {
+			started = append(started, e.Tick)
+		}
+	})
+
+	g.Simulator().Step()
+	if err := g.Watering().CancelWatering("section-A"); err != nil {
+		t.Fatalf("failed to cancel the watering: %v", err)
+	}
+	for range 5 {
+		g.Simulator().Step()
+	}
+
+	// Cancelled at tick 0, the schedule checking every tick stays idle
+	// through tick 3
+	if len(started) < 2 || started[1] != 4 {
+		t.Errorf("expected the schedule to water again on tick 4, got starts on %v", started)
+	}
+}
+
+func TestWateringConfig_Methods(t *testing.T) {
+	efficiency := 0.5
+	g, err := New(wateringConfig(&config.WateringConfig{
+		Methods: []config.MethodConfig{{Method: models.MethodDrip, Efficiency: &efficiency}},
+	}))
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+
+	if err := g.Watering().WaterSection("section-A", 0.2, time.Second); err != nil {
+		t.Fatalf("failed to water: %v", err)
+	}
+	g.Simulator().Step()
+
+	drip := g.Watering().GetWaterStats().ByMethod[models.MethodDrip]
+	if math.Abs(drip.Wasted-0.1) > 1e-9 {
+		t.Errorf("expected half of the 0.2 drip water to be wasted, got %.4f", drip.Wasted)
+	}
+}
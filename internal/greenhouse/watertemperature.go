@@ -0,0 +1,90 @@
+package greenhouse
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/environment"
+	"greenhouse-simulator/internal/models"
+	"math"
+)
+
+// WaterTemperature is the temperature of the irrigation water in Celsius,
+// and whether the heater of the tank holds it there, see
+// Greenhouse.HeatWater.
+type WaterTemperature struct {
+	Temperature float64 `json:"temperature"`
+	Heated      bool    `json:"heated"`
+}
+
+// waterTemperature moves the irrigation water towards the air temperature
+// of the greenhouse on a tick, see environment.WaterTemperature, and shocks
+// the plants the watering waters with water much colder or warmer than
+// their soil, see models.Plant.ThermalShock. A greenhouse without water
+// temperature settings waters at the air temperature unless heated.
+type waterTemperature struct {
+	g      *greenhouse
+	model  environment.WaterTemperature
+	damage float64
+}
+
+func newWaterTemperature(g *greenhouse, cfg environment.WaterTemperatureConfig) (*waterTemperature, error) {
+	model, err := environment.NewWaterTemperature(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &waterTemperature{g: g, model: model, damage: cfg.ShockDamage}, nil
+}
+
+// TickPhase names the water temperature in tick traces.
+func (w *waterTemperature) TickPhase() string { return "water_temperature" }
+
+func (w *waterTemperature) OnTick(tick int) {
+	w.model.Update(w.g.Conditions().Temperature)
+}
+
+// temperature returns the current temperature of the water, that of the air
+// before the first tick unless set.
+func (w *waterTemperature) temperature() float64 {
+	if temperature, ok := w.model.Get(); ok {
+		return temperature
+	}
+	return w.g.Conditions().Temperature
+}
+
+// shock shocks a plant watered for the difference between the water and
+// its soil. It is called by the watering controller, see
+// watering.Config.ThermalShock.
+func (w *waterTemperature) shock(plant *models.Plant) {
+	if plant.Type.ThermalShockTolerance == 0 {
+		return
+	}
+	soil := w.g.SectionConditions(plant.SectionID).SoilTemperature
+	plant.ThermalShock(math.Abs(w.temperature()-soil), w.damage)
+}
+
+// WaterTemperature returns the current temperature of the irrigation water.
+// This method is safe for concurrent use.
+func (g *greenhouse) WaterTemperature() WaterTemperature {
+	_, heated := g.waterTemp.model.Heater()
+	return WaterTemperature{Temperature: g.waterTemp.temperature(), Heated: heated}
+}
+
+// HeatWater switches on the heater of the tank, holding the irrigation
+// water at temperature in Celsius from now on, until StopHeatingWater.
+// Returns an error if the temperature is not between 0 and 100.
+// This method is safe for concurrent use.
+func (g *greenhouse) HeatWater(temperature float64) error {
+	if !(temperature >= 0 && temperature <= 100) {
+		return errors.New("water temperature must be between 0 and 100")
+	}
+	g.waterTemp.model.Heat(temperature)
+	return nil
+}
+
+// StopHeatingWater switches off the heater of the tank: the irrigation
+// water drifts from its setpoint towards the air again, all the way on the
+// next tick without water temperature settings. Stopping a heater that is
+// off does nothing.
+// This method is safe for concurrent use.
+func (g *greenhouse) StopHeatingWater() {
+	g.waterTemp.model.StopHeating()
+}
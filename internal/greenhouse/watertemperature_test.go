@@ -0,0 +1,123 @@
+package greenhouse
+
+import (
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/models"
+	"math"
+	"testing"
+	"time"
+)
+
+// waterTemperatureConfig has a lettuce that tolerates water 5°C off its soil
+// and a sprout that takes any water, both happy at any saturation, in a
+// greenhouse at 20°C watered from a tank at 5°C that never warms up.
+func waterTemperatureConfig() *config.GreenhouseConfig {
+	cold := 5.0
+	return &config.GreenhouseConfig{
+		TickInterval: config.Duration(time.Second),
+		Environment:  config.EnvironmentConfig{Temperature: 20},
+		PlantTypes: []config.PlantTypeConfig{
+			{Name: "Lettuce", OptimalSaturation: 0.5, MaxSaturation: 1, ThermalShockTolerance: 5},
+			{Name: "Sprout", OptimalSaturation: 0.5, MaxSaturation: 1},
+		},
+		Plants: []config.PlantConfig{
+			{ID: "lettuce", Type: "Lettuce", SectionID: "section-A", InitialSaturation: 0.5},
+			{ID: "sprout", Type: "Sprout", SectionID: "section-A", InitialSaturation: 0.5},
+		},
+		Sensors: []config.SensorConfig{
+			{ID: "water-A", Type: models.WaterTemperature, SectionID: "section-A"},
+		},
+		WaterTemperature: &config.WaterTemperatureConfig{Temperature: &cold, Inertia: 1, ShockDamage: 0.02},
+	}
+}
+
+func health(t *testing.T, g Greenhouse, id string) float64 {
+	t.Helper()
+	for _, plant := range g.Simulator().GetAllPlants() {
+		if plant.ID == id {
+			return plant.Health
+		}
+	}
+	t.Fatalf("no plant %s", id)
+	return 0
+}
+
+func TestWaterTemperature_ShocksOncePerWatering(t *testing.T) {
+	g, err := New(waterTemperatureConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	// A watering spread over 3 ticks shocks once, like an instant one.
+	if err := g.Watering().WaterSection("section-A", 0.3, 3*time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for range 4 {
+		g.Simulator().Step()
+	}
+	if err := g.Watering().WaterSection("section-A", 0.1, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g.Simulator().Step()
+
+	// The water is 15°C off the soil, 10°C beyond the tolerance of the
+	// lettuce: two waterings cost it 2 × 10 × 0.02 of its health.
+	if got := health(t, g, "lettuce"); math.Abs(got-0.6) > 1e-9 {
+		t.Errorf("expected the lettuce at health 0.6, got %.3f", got)
+	}
+	if got := health(t, g, "sprout"); got != 1 {
+		t.Errorf("expected the sprout unharmed, got health %.3f", got)
+	}
+	if got := read(t, g, "water-A"); got != 5 {
+		t.Errorf("expected the sensor to read the water at 5°C, got %.3f", got)
+	}
+}
+
+func TestWaterTemperature_HeatedTank(t *testing.T) {
+	g, err := New(waterTemperatureConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	if err := g.HeatWater(101); err == nil || err.Error() != "water temperature must be between 0 and 100" {
+		t.Errorf("expected a setpoint error, got %v", err)
+	}
+	if err := g.HeatWater(20); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := g.Watering().WaterSection("section-A", 0.1, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g.Simulator().Step()
+	if got := health(t, g, "lettuce"); got != 1 {
+		t.Errorf("expected water at the soil temperature to spare the lettuce, got health %.3f", got)
+	}
+	if state := g.WaterTemperature(); state != (WaterTemperature{Temperature: 20, Heated: true}) {
+		t.Errorf("expected the heated water at 20°C, got %+v", state)
+	}
+
+	// Once the heater is off, the water keeps its temperature: it has all
+	// the inertia.
+	g.StopHeatingWater()
+	g.Simulator().Step()
+	if state := g.WaterTemperature(); state != (WaterTemperature{Temperature: 20}) {
+		t.Errorf("expected the water to stay at 20°C, got %+v", state)
+	}
+}
+
+func TestWaterTemperature_DefaultsToAir(t *testing.T) {
+	cfg := waterTemperatureConfig()
+	cfg.WaterTemperature = nil
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	if err := g.Watering().WaterSection("section-A", 0.1, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g.Simulator().Step()
+	if got := health(t, g, "lettuce"); got != 1 {
+		t.Errorf("expected water at the air temperature to spare the lettuce, got health %.3f", got)
+	}
+	if got := read(t, g, "water-A"); got != 20 {
+		t.Errorf("expected the sensor to read the water at 20°C, got %.3f", got)
+	}
+}
@@ -0,0 +1,335 @@
+package greenhouse
+
+import (
+	"cmp"
+	"errors"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/environment"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// weather works out the air conditions on every tick, extreme weather, the
+// heating and venting and CO2 included. It publishes an ExtremeWeatherStarted and ExtremeWeatherEnded
+// event around every frost and heat wave, and while one goes on, frost
+// damages the plants that are not frost tolerant and heat dries the soil out
+// faster. The alive plants draw the CO2 down in the light, grow lights
+// included, and a level above the boost threshold makes them grow faster, as
+// does the light the grow lights add. The microclimate of a section applies
+// to its plants: a section warmed above the frost temperature is spared the
+// frost, and the light of a section is that of its microclimate.
+type weather struct {
+	g   *greenhouse
+	co2 environment.CO2
+	// affectPlants is false for replays, whose plants already show the
+	// effects of the recorded weather.
+	affectPlants bool
+	conditions   environment.Conditions
+	// triggered is the extreme started by TriggerWeatherEvent, with a
+	// negative Start until its first tick.
+	triggered *environment.ExtremeEvent
+	current   *environment.ExtremeEvent
+	// offsets are the microclimates of the sections, replaced rather than
+	// changed so that a tick can read them without holding mu.
+	offsets map[string]environment.ClimateOffset
+	mu      sync.Mutex
+	// effects is what the weather of the tick being processed does to the
+	// plants, applied by applyEffects under the lock of the plants. It is
+	// only used by OnTick, and applyEffects is bound once so that a tick
+	// does not allocate it.
+	effects      plantEffects
+	applyEffects func()
+}
+
+// plantEffects is what the weather of a tick does to the plants.
+type plantEffects struct {
+	plants     []*models.Plant
+	offsets    map[string]environment.ClimateOffset
+	conditions environment.Conditions
+	// frost is the temperature outside the heating, which frost is worked
+	// out from.
+	frost            float64
+	frostTemperature float64
+	frostDamage      float64
+	boost            float64
+}
+
+// lightGrowthBoost is the extra growth, as a fraction of the base growth rate,
+// a plant gets per tick from grow lights adding full light. Natural light is
+// already part of the plant types' growth rates.
+const lightGrowthBoost = 0.5
+
+func newWeather(g *greenhouse, co2 environment.CO2) *weather {
+	conditions := g.config.Climate().At(g.sim.GetCurrentTick())
+	conditions.CO2 = co2.Get()
+	w := &weather{
+		g:            g,
+		co2:          co2,
+		affectPlants: true,
+		conditions:   conditions,
+		offsets:      g.config.ClimateOffsets(),
+	}
+	w.applyEffects = w.apply
+	return w
+}
+
+// TickPhase names the weather in tick traces.
+func (w *weather) TickPhase() string { return "weather" }
+
+func (w *weather) OnTick(tick int) {
+	climate := w.g.Climate()
+	plants := w.g.sim.GetAllPlants()
+	w.mu.Lock()
+	if w.triggered != nil && w.triggered.Start < 0 {
+		w.triggered.Start = tick
+	}
+	if w.triggered != nil && !w.triggered.ActiveAt(tick) {
+		w.triggered = nil
+	}
+	event, ok := climate.ExtremeEventAt(tick)
+	if w.triggered != nil {
+		event, ok = *w.triggered, true
+	}
+	w.conditions = climate.With(tick, event.Kind)
+	// The frost is worked out from the temperature outside the heating.
+	frost := w.conditions.Temperature
+	w.conditions.Temperature += w.g.hvac.Offset()
+	natural := w.conditions.Light
+	offsets := w.offsets
+	alive, supplemental := 0, 0.0
+	for _, plant := range plants {
+		if plant.Alive {
+			alive++
+			supplemental += w.g.lights.Light(plant.SectionID, offsets[plant.SectionID].ApplyLight(natural)) - natural
+		}
+	}
+	w.co2.Update(float64(alive)*natural + supplemental)
+	w.conditions.CO2 = w.co2.Get()
+	conditions := w.conditions
+	previous := w.current
+	w.current = nil
+	if ok {
+		w.current = &event
+	}
+	w.mu.Unlock()
+
+	if previous != nil && (!ok || *previous != event) {
+		w.publish(events.ExtremeWeatherEnded, tick, *previous)
+	}
+	if ok && (previous == nil || *previous != event) {
+		w.publish(events.ExtremeWeatherStarted, tick, event)
+	}
+	if !w.affectPlants {
+		return
+	}
+	w.effects = plantEffects{
+		plants:           plants,
+		offsets:          offsets,
+		conditions:       conditions,
+		frost:            frost,
+		frostTemperature: climate.FrostTemperature,
+		frostDamage:      cmp.Or(climate.FrostDamage, 0.1),
+		boost:            w.co2.GrowthBoost(),
+	}
+	w.g.sim.UpdatePlants(w.applyEffects)
+	w.effects = plantEffects{}
+}
+
+// apply applies the effects of the tick to the plants. The caller must hold
+// the lock of the plants, see engine.Simulator.UpdatePlants.
+func (w *weather) apply() {
+	e := w.effects
+	for _, plant := range e.plants {
+		offset := e.offsets[plant.SectionID]
+		if e.conditions.Extreme == environment.Frost && e.frost+offset.Temperature <= e.frostTemperature {
+			plant.Frost(e.frostDamage)
+		}
+		plant.Evaporate(e.conditions.Evaporation - 1)
+		light := offset.ApplyLight(e.conditions.Light)
+		added := w.g.lights.Light(plant.SectionID, light) - light
+		plant.BoostGrowth(e.boost + lightGrowthBoost*added)
+	}
+}
+
+// assumptions are the conditions of a section on the last tick, as a plant
+// forecast assumes them to persist: the frost damage while a frost reaches
+// the section, the extra evaporation of a heat wave and the growth boost of
+// the CO2 level and the grow lights, as OnTick applies them. Replayed plants
+// are not affected by the weather and get none.
+func (w *weather) assumptions(sectionID string) models.ForecastAssumptions {
+	var assumptions models.ForecastAssumptions
+	if !w.affectPlants {
+		return assumptions
+	}
+	climate := w.g.Climate()
+	w.mu.Lock()
+	conditions, offset := w.conditions, w.offsets[sectionID]
+	w.mu.Unlock()
+	frost := conditions.Temperature - w.g.hvac.Offset()
+	if conditions.Extreme == environment.Frost && frost+offset.Temperature <= climate.FrostTemperature {
+		assumptions.FrostDamage = cmp.Or(climate.FrostDamage, 0.1)
+	}
+	assumptions.Evaporation = max(conditions.Evaporation-1, 0)
+	light := offset.ApplyLight(conditions.Light)
+	added := w.g.lights.Light(sectionID, light) - light
+	assumptions.GrowthBoost = w.co2.GrowthBoost() + lightGrowthBoost*added
+	return assumptions
+}
+
+func (w *weather) publish(t events.Type, tick int, event environment.ExtremeEvent) {
+	w.g.bus.Publish(events.Event{Type: t, Tick: tick, Timestamp: time.Now(), Payload: event})
+}
+
+// Conditions returns the air conditions of the last tick, extreme weather
+// included, or of the first tick before the simulation ran.
+// This method is safe for concurrent use.
+func (g *greenhouse) Conditions() environment.Conditions {
+	g.weather.mu.Lock()
+	defer g.weather.mu.Unlock()
+	return g.weather.conditions
+}
+
+// GetSeason returns the season of the last tick, see environment.Seasons,
+// or empty when the climate has no seasons.
+// This method is safe for concurrent use.
+func (g *greenhouse) GetSeason() environment.Season {
+	return g.Conditions().Season
+}
+
+// GetDayOfYear returns the one-based day of the year of the last tick, or 0
+// when the climate has no seasons.
+// This method is safe for concurrent use.
+func (g *greenhouse) GetDayOfYear() int {
+	return g.Conditions().DayOfYear
+}
+
+// PlantForecast projects a plant from its current state, left unwatered,
+// assuming the conditions of its section on the last tick persist, see
+// models.ForecastPlant. Returns an error wrapping engine.ErrPlantNotFound if
+// there is no such plant.
+// This method is safe for concurrent use.
+func (g *greenhouse) PlantForecast(plantID string) (*models.PlantForecast, error) {
+	plant, err := g.sim.GetPlant(plantID)
+	if err != nil {
+		return nil, err
+	}
+	return models.ForecastPlant(plant.Snapshot(), g.weather.assumptions(plant.SectionID))
+}
+
+// TriggerWeatherEvent starts a frost or a heat wave lasting the given number
+// of ticks from the next tick the weather is worked out, which is the tick
+// being processed when called from the timeline. It replaces an extreme
+// triggered before that is still going on, and takes precedence over those
+// drawn by the climate. Returns an error if:
+// - extreme is not frost or heat_wave
+// - ticks is below 1
+//
+// This method is safe for concurrent use.
+func (g *greenhouse) TriggerWeatherEvent(extreme environment.Extreme, ticks int) error {
+	if err := extreme.Validate(); err != nil {
+		return err
+	}
+	if ticks < 1 {
+		return errors.New("extreme weather must last at least 1 tick")
+	}
+	g.weather.mu.Lock()
+	defer g.weather.mu.Unlock()
+	g.weather.triggered = &environment.ExtremeEvent{Kind: extreme, Start: -1, Ticks: ticks}
+	return nil
+}
+
+// SetCO2Injection sets the CO2 the injector adds per tick, in ppm, from the
+// next tick on; 0 turns it off. Returns an error if rate is negative, or
+// positive without a CO2 model.
+// This method is safe for concurrent use.
+func (g *greenhouse) SetCO2Injection(rate float64) error {
+	return g.weather.co2.SetInjection(rate)
+}
+
+// SectionConditions returns the air conditions of the last tick in a
+// section: the greenhouse-wide Conditions with its microclimate applied and
+// the light of its grow lights added, along with its soil salinity and
+// temperature and the temperature of the irrigation water.
+// This method is safe for concurrent use.
+func (g *greenhouse) SectionConditions(sectionID string) environment.Conditions {
+	conditions := g.SectionClimateOffset(sectionID).Apply(g.Conditions())
+	conditions.Light = g.lights.Light(sectionID, conditions.Light)
+	if g.salinity != nil {
+		conditions.Salinity = g.salinity.Get(sectionID)
+	}
+	conditions.SoilTemperature = conditions.Temperature
+	if g.soilTemp != nil {
+		if temperature, ok := g.soilTemp.Get(sectionID); ok {
+			conditions.SoilTemperature = temperature
+		}
+	}
+	conditions.WaterTemperature = g.waterTemp.temperature()
+	return conditions
+}
+
+// SectionClimateOffset returns the microclimate of a section, the zero
+// ClimateOffset for a section without one.
+// This method is safe for concurrent use.
+func (g *greenhouse) SectionClimateOffset(sectionID string) environment.ClimateOffset {
+	g.weather.mu.Lock()
+	defer g.weather.mu.Unlock()
+	return g.weather.offsets[sectionID]
+}
+
+// SetSectionClimateOffset sets the microclimate of a section from the next
+// tick on, or from now on for its sensors; the zero ClimateOffset removes
+// it. Returns an error if the offset is invalid, see
+// environment.ClimateOffset.Validate.
+// This method is safe for concurrent use.
+func (g *greenhouse) SetSectionClimateOffset(sectionID string, offset environment.ClimateOffset) error {
+	if err := offset.Validate(); err != nil {
+		return err
+	}
+	g.weather.mu.Lock()
+	defer g.weather.mu.Unlock()
+	offsets := maps.Clone(g.weather.offsets)
+	if offsets == nil {
+		offsets = map[string]environment.ClimateOffset{}
+	}
+	if offset == (environment.ClimateOffset{}) {
+		delete(offsets, sectionID)
+	} else {
+		offsets[sectionID] = offset
+	}
+	g.weather.offsets = offsets
+	return nil
+}
+
+// microclimates returns the live microclimates in their config form, ordered
+// by section ID.
+func (g *greenhouse) microclimates() []config.MicroclimateConfig {
+	g.weather.mu.Lock()
+	defer g.weather.mu.Unlock()
+	var microclimates []config.MicroclimateConfig
+	for _, sectionID := range slices.Sorted(maps.Keys(g.weather.offsets)) {
+		o := g.weather.offsets[sectionID]
+		microclimates = append(microclimates, config.MicroclimateConfig{SectionID: sectionID, Temperature: o.Temperature, Humidity: o.Humidity, Light: o.Light})
+	}
+	return microclimates
+}
+
+// sectionHumidity returns the humidity of a section, its microclimate
+// included.
+func (g *greenhouse) sectionHumidity(sectionID string) float64 {
+	return min(1, max(0, g.humidity.Get(sectionID)+g.SectionClimateOffset(sectionID).Humidity))
+}
+
+// SetLights switches the grow lights of a section on or off at an intensity,
+// from the current tick on; switching them on at 0 means full intensity.
+// Returns an error if the intensity is outside 0.0-1.0.
+// This method is safe for concurrent use.
+func (g *greenhouse) SetLights(sectionID string, on bool, intensity float64) error {
+	if on && intensity == 0 {
+		intensity = 1
+	}
+	return g.lights.Set(sectionID, on, intensity)
+}
@@ -0,0 +1,248 @@
+package greenhouse
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/environment"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"testing"
+	"time"
+)
+
+// weatherConfig has a frost-intolerant lettuce and a frost tolerant kale in
+// section-A, next to an air temperature sensor, at a steady 12°C.
+func weatherConfig() *config.GreenhouseConfig {
+	return &config.GreenhouseConfig{
+		TickInterval: config.Duration(time.Second),
+		Environment: config.EnvironmentConfig{
+			Temperature:      12,
+			FrostTemperature: -3,
+			FrostDamage:      0.25,
+		},
+		Plants: []config.PlantConfig{
+			{ID: "lettuce-1", Type: "Lettuce", SectionID: "section-A", InitialSaturation: 0.7},
+			{ID: "kale-1", Type: "Kale", SectionID: "section-A", InitialSaturation: 0.7},
+		},
+		Sensors: []config.SensorConfig{
+			{ID: "thermometer", Type: models.Temperature, SectionID: "section-A"},
+		},
+	}
+}
+
+func TestTriggerWeatherEvent_FrostKillsIntolerantPlants(t *testing.T) {
+	g, err := New(weatherConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	var published []events.Event
+	g.Bus().Subscribe(func(e events.Event) {
+		published = append(published, e)
+	})
+
+	if err := g.TriggerWeatherEvent(environment.Frost, 6); err != nil {
+		t.Fatalf("failed to trigger a frost: %v", err)
+	}
+	for range 6 {
+		g.Simulator().Step()
+		if got := g.Conditions().Temperature; got != -3 {
+			t.Errorf("expected the frost to pin the temperature at -3, got %.2f", got)
+		}
+		reading, err := g.Sensors().GetReading("thermometer")
+		if err != nil {
+			t.Fatalf("failed to read the thermometer: %v", err)
+		}
+		if reading.Value != -3 {
+			t.Errorf("expected the thermometer to read -3, got %.2f", reading.Value)
+		}
+	}
+	g.Simulator().Step()
+	if got := g.Conditions(); got.Temperature != 12 || got.Extreme != "" {
+		t.Errorf("expected the frost to be over after 6 ticks, got %+v", got)
+	}
+
+	plants := g.Simulator().GetAllPlants()
+	lettuce, kale := plants[0], plants[1]
+	if lettuce.Alive {
+		t.Errorf("expected the lettuce to die in the frost, got %v", lettuce)
+	}
+	if !kale.Alive || kale.Health < 1 {
+		t.Errorf("expected the kale to be unharmed by the frost, got %v", kale)
+	}
+
+	frost := environment.ExtremeEvent{Kind: environment.Frost, Start: 0, Ticks: 6}
+	var started, ended, died []int
+	for _, e := range published {
+		switch e.Type {
+		case events.ExtremeWeatherStarted:
+			started = append(started, e.Tick)
+		case events.ExtremeWeatherEnded:
+			ended = append(ended, e.Tick)
+		case events.PlantDied:
+			died = append(died, e.Tick)
+			if e.PlantID != "lettuce-1" {
+				t.Errorf("expected only the lettuce to die, got %s", e.PlantID)
+			}
+		}
+		if e.Type == events.ExtremeWeatherStarted || e.Type == events.ExtremeWeatherEnded {
+			if e.Payload != frost {
+				t.Errorf("expected the %s payload %+v, got %+v", e.Type, frost, e.Payload)
+			}
+		}
+	}
+	if len(started) != 1 || started[0] != 0 || len(ended) != 1 || ended[0] != 6 {
+		t.Errorf("expected the frost to start at tick 0 and end at tick 6, got %v and %v", started, ended)
+	}
+	if len(died) != 1 || died[0] > 5 {
+		t.Errorf("expected the lettuce to die during the frost, got deaths at %v", died)
+	}
+}
+
+func TestTriggerWeatherEvent_HeatWaveDriesSoil(t *testing.T) {
+	saturation := func(heatWave bool) float64 {
+		g, err := New(weatherConfig())
+		if err != nil {
+			t.Fatalf("failed to build greenhouse: %v", err)
+		}
+		if heatWave {
+			if err := g.TriggerWeatherEvent(environment.HeatWave, 3); err != nil {
+				t.Fatalf("failed to trigger a heat wave: %v", err)
+			}
+		}
+		for range 3 {
+			g.Simulator().Step()
+		}
+		if heatWave && g.Conditions().Temperature != 20 {
+			t.Errorf("expected the heat wave to raise the temperature to 20, got %.2f", g.Conditions().Temperature)
+		}
+		return g.Simulator().GetAllPlants()[1].SoilSaturation
+	}
+
+	normal, hot := saturation(false), saturation(true)
+	// Kale depletes 0.04 a tick, and twice as much again in a heat wave.
+	if diff := normal - hot; diff < 0.119 || diff > 0.121 {
+		t.Errorf("expected the heat wave to dry the soil by 0.12 more, got %.2f and %.2f", normal, hot)
+	}
+}
+
+func TestTriggerWeatherEvent_Validation(t *testing.T) {
+	g, err := New(weatherConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	tests := []struct {
+		name    string
+		extreme environment.Extreme
+		ticks   int
+	}{
+		{"unknown extreme", "hail", 3},
+		{"no ticks", environment.Frost, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := g.TriggerWeatherEvent(tt.extreme, tt.ticks); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestTimeline_WeatherEvent(t *testing.T) {
+	cfg := weatherConfig()
+	cfg.Timeline = []config.ActionConfig{
+		{Tick: 2, Action: config.ActionWeatherEvent, Extreme: environment.Frost, Ticks: 2},
+	}
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	var extremes []environment.Extreme
+	for range 5 {
+		g.Simulator().Step()
+		extremes = append(extremes, g.Conditions().Extreme)
+	}
+	expected := []environment.Extreme{"", "", environment.Frost, environment.Frost, ""}
+	for i := range expected {
+		if extremes[i] != expected[i] {
+			t.Fatalf("expected the extremes %v, got %v", expected, extremes)
+		}
+	}
+}
+
+func TestSeasons_TagTickEvents(t *testing.T) {
+	cfg := weatherConfig()
+	cfg.Environment.TicksPerDay = 2
+	cfg.Environment.TicksPerYear = 8
+	cfg.Environment.SeasonPhase = 0.25
+	cfg.Environment.SeasonalTemperature = 10
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	var stats []Stats
+	g.Bus().Subscribe(func(e events.Event) {
+		if e.Type == events.Tick {
+			stats = append(stats, e.Payload.(Stats))
+		}
+	})
+
+	// Four days of two ticks, starting at midsummer.
+	expected := []environment.Season{environment.Summer, environment.Autumn, environment.Winter, environment.Spring}
+	for tick := range 8 {
+		g.Simulator().Step()
+		season, day := expected[tick/2], (tick/2+1)%4+1
+		if g.GetSeason() != season || g.GetDayOfYear() != day {
+			t.Errorf("tick %d: expected %s on day %d, got %s on day %d", tick, season, day, g.GetSeason(), g.GetDayOfYear())
+		}
+		if got := stats[tick]; got.Season != season || got.DayOfYear != day {
+			t.Errorf("tick %d: expected the tick event to carry %s on day %d, got %+v", tick, season, day, got)
+		}
+	}
+}
+
+func TestPlantForecast_AssumesCurrentConditions(t *testing.T) {
+	g, err := New(weatherConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	forecast := func(plantID string, assumptions models.ForecastAssumptions) (got, expected models.PlantForecast) {
+		t.Helper()
+		plant, err := g.Simulator().GetPlant(plantID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want, err := models.ForecastPlant(plant.Snapshot(), assumptions)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		forecast, err := g.PlantForecast(plantID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return *forecast, *want
+	}
+
+	if got, expected := forecast("kale-1", models.ForecastAssumptions{}); got != expected {
+		t.Errorf("expected the forecast in calm weather %+v, got %+v", expected, got)
+	}
+	if err := g.TriggerWeatherEvent(environment.HeatWave, 3); err != nil {
+		t.Fatalf("failed to trigger a heat wave: %v", err)
+	}
+	g.Simulator().Step()
+	if got, expected := forecast("kale-1", models.ForecastAssumptions{Evaporation: 1}); got != expected {
+		t.Errorf("expected the forecast in a heat wave %+v, got %+v", expected, got)
+	}
+
+	if err := g.TriggerWeatherEvent(environment.Frost, 3); err != nil {
+		t.Fatalf("failed to trigger a frost: %v", err)
+	}
+	g.Simulator().Step()
+	if got, expected := forecast("lettuce-1", models.ForecastAssumptions{FrostDamage: 0.25}); got != expected {
+		t.Errorf("expected the forecast in a frost %+v, got %+v", expected, got)
+	}
+
+	if _, err := g.PlantForecast("cactus-1"); !errors.Is(err, engine.ErrPlantNotFound) {
+		t.Errorf("expected a plant not found error, got %v", err)
+	}
+}
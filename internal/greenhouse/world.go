@@ -0,0 +1,278 @@
+package greenhouse
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/environment"
+	"greenhouse-simulator/internal/sensors"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// WorldStateVersion is the version of the WorldState document. It changes
+// whenever a field is renamed or removed or its meaning changes, so that a
+// reader can refuse a document it does not understand; new fields keep it.
+const WorldStateVersion = 1
+
+// WorldState describes the whole greenhouse at the end of a tick, for
+// external visualizers: its sections, plants, sensors and the watering
+// events that have not completed, with the greenhouse-wide conditions. Tick
+// is the next tick to run. The document is versioned, see
+// WorldStateVersion, and every list is ordered by ID.
+type WorldState struct {
+	Version     int              `json:"version"`
+	Tick        int              `json:"tick"`
+	Environment WorldEnvironment `json:"environment"`
+	Sections    []WorldSection   `json:"sections"`
+	Plants      []WorldPlant     `json:"plants"`
+	Sensors     []WorldSensor    `json:"sensors"`
+	Watering    []WorldWatering  `json:"watering"`
+}
+
+// WorldEnvironment is the greenhouse-wide conditions of the last tick, with
+// the season and day of the year, empty and 0 without seasons.
+type WorldEnvironment struct {
+	Conditions WorldConditions `json:"conditions"`
+	Season     string          `json:"season,omitempty"`
+	DayOfYear  int             `json:"day_of_year,omitempty"`
+}
+
+// WorldConditions mirrors environment.Conditions. Salinity and
+// SoilTemperature are only set for a section.
+type WorldConditions struct {
+	Temperature     float64 `json:"temperature"`
+	Humidity        float64 `json:"humidity"`
+	Light           float64 `json:"light"`
+	Weather         string  `json:"weather"`
+	Extreme         string  `json:"extreme,omitempty"`
+	CO2             float64 `json:"co2"`
+	Salinity        float64 `json:"salinity,omitempty"`
+	SoilTemperature float64 `json:"soil_temperature,omitempty"`
+}
+
+// WorldSection is a section with plants, sensors, a configured soil or a
+// zone. Soil is the name of its soil, empty for plain soil, and Zone the ID
+// of its zone, empty outside zones. Microclimate is its offset from the
+// greenhouse-wide climate, see environment.ClimateOffset, and Conditions the
+// conditions in the section.
+type WorldSection struct {
+	ID           string            `json:"id"`
+	Soil         string            `json:"soil,omitempty"`
+	Zone         string            `json:"zone,omitempty"`
+	Microclimate WorldMicroclimate `json:"microclimate"`
+	Conditions   WorldConditions   `json:"conditions"`
+}
+
+// WorldMicroclimate mirrors environment.ClimateOffset.
+type WorldMicroclimate struct {
+	Temperature float64 `json:"temperature"`
+	Humidity    float64 `json:"humidity"`
+	Light       float64 `json:"light"`
+}
+
+// WorldPlant is a plant with its stage of life, see models.Plant.Stage, and
+// its state.
+type WorldPlant struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Section string          `json:"section"`
+	Stage   string          `json:"stage"`
+	Tags    []string        `json:"tags,omitempty"`
+	State   WorldPlantState `json:"state"`
+}
+
+// WorldPlantState mirrors the state of a models.Plant. Disease is empty for
+// a healthy plant and DeathCause for a living one.
+type WorldPlantState struct {
+	SoilSaturation float64 `json:"soil_saturation"`
+	DeepSaturation float64 `json:"deep_saturation"`
+	Health         float64 `json:"health"`
+	GrowthStage    float64 `json:"growth_stage"`
+	Alive          bool    `json:"alive"`
+	DeathCause     string  `json:"death_cause,omitempty"`
+	Disease        string  `json:"disease,omitempty"`
+	Germinating    bool    `json:"germinating,omitempty"`
+}
+
+// WorldSensor is a sensor with its status, see sensors.SensorStatus, and the
+// last sample it took, nil before its first.
+type WorldSensor struct {
+	ID         string        `json:"id"`
+	Type       string        `json:"type"`
+	Section    string        `json:"section"`
+	Status     string        `json:"status"`
+	LastSample *WorldReading `json:"last_sample,omitempty"`
+}
+
+// WorldReading is the tick and value of a sensor sample.
+type WorldReading struct {
+	Tick  int     `json:"tick"`
+	Value float64 `json:"value"`
+}
+
+// WorldWatering is a watering event that has not completed: Started once it
+// delivers water, from StartedTick on, which is 0 until then. Plant is set
+// for an event watering a single plant and Schedule for one a schedule
+// triggered.
+type WorldWatering struct {
+	ID          string  `json:"id"`
+	Section     string  `json:"section"`
+	Plant       string  `json:"plant,omitempty"`
+	Amount      float64 `json:"amount"`
+	Manual      bool    `json:"manual"`
+	Schedule    string  `json:"schedule,omitempty"`
+	QueuedTick  int     `json:"queued_tick"`
+	Started     bool    `json:"started"`
+	StartedTick int     `json:"started_tick"`
+}
+
+// BuildWorldState describes the greenhouse as it is between two ticks, see
+// WorldState. It holds the next tick off while it reads, see
+// engine.TickBarrier, so that every part of the document is from the same
+// tick; it must not be called from a tick listener. Returns an error if the
+// soil of a section or the history of a sensor cannot be read.
+// This method is safe for concurrent use.
+func (g *greenhouse) BuildWorldState() (*WorldState, error) {
+	var state *WorldState
+	var err error
+	g.sim.BetweenTicks(func() {
+		state, err = g.buildWorldState()
+	})
+	return state, err
+}
+
+func (g *greenhouse) buildWorldState() (*WorldState, error) {
+	conditions := g.Conditions()
+	// The greenhouse-wide soil temperature is only the air temperature.
+	conditions.SoilTemperature = 0
+	state := &WorldState{
+		Version: WorldStateVersion,
+		Tick:    g.sim.GetCurrentTick(),
+		Environment: WorldEnvironment{
+			Conditions: worldConditions(conditions),
+			Season:     string(conditions.Season),
+			DayOfYear:  conditions.DayOfYear,
+		},
+		Sections: []WorldSection{},
+		Plants:   []WorldPlant{},
+		Sensors:  []WorldSensor{},
+		Watering: []WorldWatering{},
+	}
+
+	soils := map[string]string{}
+	for _, section := range g.Config().Sections {
+		if !section.HasSoil() {
+			continue
+		}
+		soil, err := section.SoilType()
+		if err != nil {
+			return nil, err
+		}
+		soils[section.ID] = soil.Name
+	}
+	sectionIDs := map[string]bool{}
+	for sectionID := range soils {
+		sectionIDs[sectionID] = true
+	}
+	for _, sectionID := range g.sim.ListSectionIDs() {
+		sectionIDs[sectionID] = true
+	}
+	for _, zone := range g.Zones() {
+		for _, sectionID := range zone.SectionIDs {
+			sectionIDs[sectionID] = true
+		}
+	}
+	sensorList := g.sensors.ListSensors()
+	for _, sensor := range sensorList {
+		sectionIDs[sensor.SectionID] = true
+	}
+	for _, sectionID := range slices.Sorted(maps.Keys(sectionIDs)) {
+		offset := g.SectionClimateOffset(sectionID)
+		state.Sections = append(state.Sections, WorldSection{
+			ID:           sectionID,
+			Soil:         soils[sectionID],
+			Zone:         g.zones.SectionZone(sectionID),
+			Microclimate: WorldMicroclimate{Temperature: offset.Temperature, Humidity: offset.Humidity, Light: offset.Light},
+			Conditions:   worldConditions(g.SectionConditions(sectionID)),
+		})
+	}
+
+	for _, plant := range g.sim.GetAllPlants() {
+		state.Plants = append(state.Plants, WorldPlant{
+			ID:      plant.ID,
+			Type:    plant.Type.Name,
+			Section: plant.SectionID,
+			Stage:   string(plant.Stage()),
+			Tags:    slices.Clone(plant.Tags),
+			State: WorldPlantState{
+				SoilSaturation: plant.SoilSaturation,
+				DeepSaturation: plant.DeepSaturation,
+				Health:         plant.Health,
+				GrowthStage:    plant.GrowthStage,
+				Alive:          plant.Alive,
+				DeathCause:     string(plant.DeathCause),
+				Disease:        string(plant.Disease.Stage),
+				Germinating:    plant.Germinating(),
+			},
+		})
+	}
+	slices.SortFunc(state.Plants, func(a, b WorldPlant) int { return strings.Compare(a.ID, b.ID) })
+
+	for _, sensor := range sensorList {
+		status, err := g.sensors.GetStatus(sensor.ID)
+		if errors.Is(err, sensors.ErrSensorNotFound) {
+			// Removed since it was listed.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		world := WorldSensor{ID: sensor.ID, Type: string(sensor.Type), Section: sensor.SectionID, Status: string(status)}
+		history, err := g.sensors.GetHistory(sensor.ID, 1)
+		switch {
+		case errors.Is(err, sensors.ErrSensorNotFound):
+			continue
+		case err != nil:
+			return nil, err
+		case len(history) > 0:
+			world.LastSample = &WorldReading{Tick: history[0].Tick, Value: history[0].Value}
+		}
+		state.Sensors = append(state.Sensors, world)
+	}
+
+	pending := map[string]bool{}
+	for _, event := range g.watering.ListPendingEvents() {
+		pending[event.ID] = true
+	}
+	for _, event := range g.watering.GetActiveEvents() {
+		world := WorldWatering{
+			ID:         event.ID,
+			Section:    event.SectionID,
+			Plant:      event.PlantID,
+			Amount:     event.Amount,
+			Manual:     event.IsManual,
+			Schedule:   event.ScheduleID,
+			QueuedTick: event.QueuedTick,
+			Started:    !pending[event.ID],
+		}
+		if world.Started {
+			world.StartedTick = event.StartedTick
+		}
+		state.Watering = append(state.Watering, world)
+	}
+	slices.SortFunc(state.Watering, func(a, b WorldWatering) int { return strings.Compare(a.ID, b.ID) })
+	return state, nil
+}
+
+func worldConditions(c environment.Conditions) WorldConditions {
+	return WorldConditions{
+		Temperature:     c.Temperature,
+		Humidity:        c.Humidity,
+		Light:           c.Light,
+		Weather:         string(c.Weather),
+		Extreme:         string(c.Extreme),
+		CO2:             c.CO2,
+		Salinity:        c.Salinity,
+		SoilTemperature: c.SoilTemperature,
+	}
+}
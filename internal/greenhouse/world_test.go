@@ -0,0 +1,72 @@
+package greenhouse
+
+import (
+	"bytes"
+	"encoding/json"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/models"
+	"os"
+	"testing"
+	"time"
+)
+
+// worldConfig is two sections, one of clay in a zone and warmed by a
+// microclimate, with a plant each, a soil moisture sensor and a failing
+// thermometer, so that every part of the world state is filled in.
+func worldConfig() *config.GreenhouseConfig {
+	return &config.GreenhouseConfig{
+		TickInterval: config.Duration(time.Second),
+		Environment:  config.EnvironmentConfig{Temperature: 20, Light: 0.5, AmbientHumidity: 0.5},
+		Plants: []config.PlantConfig{
+			{ID: "basil-1", Type: "Basil", SectionID: "section-A", InitialSaturation: 0.5, Tags: []string{"herbs"}},
+			{ID: "tomato-1", Type: "Tomato", SectionID: "section-B", InitialSaturation: 0.75},
+		},
+		Sections:      []config.SectionConfig{{ID: "section-A", Soil: "Clay"}},
+		Zones:         []config.ZoneConfig{{ID: "zone-1", Name: "Herbs", Sections: []string{"section-A"}}},
+		Microclimates: []config.MicroclimateConfig{{SectionID: "section-A", Temperature: 2}},
+		Sensors: []config.SensorConfig{
+			{ID: "moisture-A", Type: models.SoilMoisture, SectionID: "section-A"},
+			{ID: "thermometer-B", Type: models.Temperature, SectionID: "section-B"},
+		},
+	}
+}
+
+// TestBuildWorldState_Golden checks the world state document against
+// testdata/world/v1.json, so that renaming or dropping a field fails here
+// rather than in the visualizers reading it. A deliberate change to the
+// document bumps WorldStateVersion and adds a fixture for the new version.
+func TestBuildWorldState_Golden(t *testing.T) {
+	g, err := New(worldConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	if _, err := g.Sensors().GetReading("moisture-A"); err != nil {
+		t.Fatalf("failed to read sensor: %v", err)
+	}
+	if err := g.Sensors().FailSensor("thermometer-B"); err != nil {
+		t.Fatalf("failed to fail sensor: %v", err)
+	}
+	if err := g.Watering().WaterSection("section-B", 2, time.Second); err != nil {
+		t.Fatalf("failed to water section: %v", err)
+	}
+
+	state, err := g.BuildWorldState()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state.Version != WorldStateVersion {
+		t.Errorf("expected version %d, got %d", WorldStateVersion, state.Version)
+	}
+	got, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal the world state: %v", err)
+	}
+	got = append(got, '\n')
+	expected, err := os.ReadFile("testdata/world/v1.json")
+	if err != nil {
+		t.Fatalf("failed to read the golden file: %v", err)
+	}
+	if !bytes.Equal(got, expected) {
+		t.Errorf("the world state does not match testdata/world/v1.json, got:\n%s", got)
+	}
+}
@@ -0,0 +1,225 @@
+package greenhouse
+
+import (
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/models"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// ZoneStats summarises the sections of a zone the way Stats summarises the
+// greenhouse: the averages are over every plant of the zone's sections, dead
+// or alive, WaterUsed is the water used on them and Costs what their water
+// and grow lights cost, see CostLedger. The heater and the vent serve the
+// whole greenhouse, so no zone is charged for them.
+type ZoneStats struct {
+	ZoneID            string   `json:"zone"`
+	Name              string   `json:"name,omitempty"`
+	Sections          []string `json:"sections"`
+	Plants            int      `json:"plants"`
+	AlivePlants       int      `json:"alive_plants"`
+	AverageHealth     float64  `json:"average_health"`
+	AverageSaturation float64  `json:"average_saturation"`
+	WaterUsed         float64  `json:"water_used"`
+	Costs             Costs    `json:"costs"`
+	// LimitingFactors counts the plants by what cost them the most health
+	// on the last tick, see models.Plant.LimitingFactor.
+	LimitingFactors models.FactorCounts `json:"limiting_factors"`
+}
+
+// SectionStats sums up the plants of a section, like ZoneStats.
+type SectionStats struct {
+	SectionID         string              `json:"section"`
+	Plants            int                 `json:"plants"`
+	AlivePlants       int                 `json:"alive_plants"`
+	AverageHealth     float64             `json:"average_health"`
+	AverageSaturation float64             `json:"average_saturation"`
+	LimitingFactors   models.FactorCounts `json:"limiting_factors"`
+}
+
+// zones holds the zones of the greenhouse, those of the config and those
+// added at runtime, and tells the watering controller which zone a section
+// belongs to.
+type zones struct {
+	byID      map[string]*models.Zone
+	bySection map[string]string // section ID to zone ID
+	mu        sync.RWMutex
+}
+
+func newZones() *zones {
+	return &zones{byID: map[string]*models.Zone{}, bySection: map[string]string{}}
+}
+
+// SectionZone returns the ID of the zone of a section, empty for a section in
+// no zone.
+// This method is safe for concurrent use.
+func (z *zones) SectionZone(sectionID string) string {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	return z.bySection[sectionID]
+}
+
+// HasZone reports whether a zone exists.
+// This method is safe for concurrent use.
+func (z *zones) HasZone(zoneID string) bool {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	return z.byID[zoneID] != nil
+}
+
+// add validates a zone against the zones so far and adds a copy of it. known
+// are the sections the greenhouse knows of. Returns an error if:
+// - the zone is invalid, see models.Zone.Validate
+// - a zone with the same ID exists (ErrZoneExists)
+// - a section is unknown or in another zone
+//
+// This method is safe for concurrent use.
+func (z *zones) add(zone *models.Zone, known []string) error {
+	if err := zone.Validate(); err != nil {
+		return err
+	}
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	if z.byID[zone.ID] != nil {
+		return fmt.Errorf("%w: %s", ErrZoneExists, zone.ID)
+	}
+	for _, sectionID := range zone.SectionIDs {
+		if !slices.Contains(known, sectionID) {
+			return fmt.Errorf("zone %s has an unknown section: %s", zone.ID, sectionID)
+		}
+		if owner, ok := z.bySection[sectionID]; ok {
+			return fmt.Errorf("section %s is in zones %s and %s", sectionID, owner, zone.ID)
+		}
+	}
+	zone = zone.Clone()
+	z.byID[zone.ID] = zone
+	for _, sectionID := range zone.SectionIDs {
+		z.bySection[sectionID] = zone.ID
+	}
+	return nil
+}
+
+// get returns a copy of a zone, or an error wrapping ErrZoneNotFound.
+// This method is safe for concurrent use.
+func (z *zones) get(zoneID string) (*models.Zone, error) {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	zone := z.byID[zoneID]
+	if zone == nil {
+		return nil, fmt.Errorf("%w: %s", ErrZoneNotFound, zoneID)
+	}
+	return zone.Clone(), nil
+}
+
+// list returns copies of the zones, ordered by ID.
+// This method is safe for concurrent use.
+func (z *zones) list() []*models.Zone {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	list := make([]*models.Zone, 0, len(z.byID))
+	for _, id := range slices.Sorted(maps.Keys(z.byID)) {
+		list = append(list, z.byID[id].Clone())
+	}
+	return list
+}
+
+// AddZone groups sections into a new zone. The sections must be known to the
+// greenhouse, through its config, its plants or its sensors. Returns an
+// error if:
+// - the zone is invalid, see models.Zone.Validate
+// - a zone with the same ID exists (ErrZoneExists)
+// - a section is unknown or already in another zone
+//
+// This method is safe for concurrent use.
+func (g *greenhouse) AddZone(zone config.ZoneConfig) (*models.Zone, error) {
+	added := zone.Zone()
+	if err := g.zones.add(added, g.sectionIDs()); err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// Zones returns the zones, ordered by ID.
+// This method is safe for concurrent use.
+func (g *greenhouse) Zones() []*models.Zone {
+	return g.zones.list()
+}
+
+// ZoneStats summarises the sections of a zone, from the running sums of
+// their plants, see engine.Simulator.SectionStats. Returns an error wrapping
+// ErrZoneNotFound if there is no such zone.
+// This method is safe for concurrent use.
+func (g *greenhouse) ZoneStats(zoneID string) (ZoneStats, error) {
+	zone, err := g.zones.get(zoneID)
+	if err != nil {
+		return ZoneStats{}, err
+	}
+	var plants engine.PlantStats
+	for _, sectionID := range zone.SectionIDs {
+		sectionStats, _ := g.sim.SectionStats(sectionID)
+		plants = plants.Plus(sectionStats)
+	}
+	stats := ZoneStats{
+		ZoneID:            zone.ID,
+		Name:              zone.Name,
+		Sections:          zone.SectionIDs,
+		Plants:            plants.Plants,
+		AlivePlants:       plants.AlivePlants,
+		AverageHealth:     plants.AverageHealth(),
+		AverageSaturation: plants.AverageSaturation(),
+		LimitingFactors:   plants.LimitingFactors,
+	}
+	water := g.watering.GetWaterStats()
+	ledger := g.Costs()
+	for _, sectionID := range zone.SectionIDs {
+		stats.WaterUsed += water.BySection[sectionID]
+		costs := ledger.Sections[sectionID]
+		stats.Costs.Water += costs.Water
+		stats.Costs.Lighting += costs.Lighting
+		stats.Costs.Total += costs.Total
+	}
+	return stats, nil
+}
+
+// SectionStats summarises the plants of a section, from their running sums,
+// see engine.Simulator.SectionStats, with what holds them back. Returns an
+// error wrapping ErrSectionNotFound if the section has no plants.
+// This method is safe for concurrent use.
+func (g *greenhouse) SectionStats(sectionID string) (SectionStats, error) {
+	plants, ok := g.sim.SectionStats(sectionID)
+	if !ok {
+		return SectionStats{}, fmt.Errorf("%w: %s", ErrSectionNotFound, sectionID)
+	}
+	return SectionStats{
+		SectionID:         sectionID,
+		Plants:            plants.Plants,
+		AlivePlants:       plants.AlivePlants,
+		AverageHealth:     plants.AverageHealth(),
+		AverageSaturation: plants.AverageSaturation(),
+		LimitingFactors:   plants.LimitingFactors,
+	}, nil
+}
+
+// zonePlants returns the plants of the sections of a zone.
+func (g *greenhouse) zonePlants(zone *models.Zone) []*models.Plant {
+	var plants []*models.Plant
+	for _, sectionID := range zone.SectionIDs {
+		plants = append(plants, g.sim.GetPlantsBySectionID(sectionID)...)
+	}
+	return plants
+}
+
+// sectionIDs returns the sorted IDs of the sections the greenhouse knows of:
+// those of its config, its plants and its sensors.
+func (g *greenhouse) sectionIDs() []string {
+	sections := g.Config().SectionIDs()
+	sections = append(sections, g.sim.ListSectionIDs()...)
+	for _, sensor := range g.sensors.ListSensors() {
+		sections = append(sections, sensor.SectionID)
+	}
+	slices.Sort(sections)
+	return slices.Compact(sections)
+}
@@ -0,0 +1,171 @@
+package greenhouse
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// zonesConfig runs costsConfig with a third section, section-C, grouped with
+// section-A into the west zone, and section-B alone in the east zone.
+func zonesConfig() *config.GreenhouseConfig {
+	cfg := costsConfig()
+	cfg.Plants = append(cfg.Plants, config.PlantConfig{ID: "shaded", Type: "Sprout", SectionID: "section-C", InitialSaturation: 0.3,
+		State: &config.PlantStateConfig{Health: 0.5, GrowthStage: 0.2, Alive: true}})
+	cfg.Zones = []config.ZoneConfig{
+		{ID: "west", Name: "West wing", Sections: []string{"section-A", "section-C"}},
+		{ID: "east", Sections: []string{"section-B"}},
+	}
+	return cfg
+}
+
+func TestZoneStats_AggregatesTheirSections(t *testing.T) {
+	g, err := New(zonesConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	for range 24 {
+		g.Simulator().Step()
+	}
+
+	// The water and costs of the sections, see TestRunScenario_Costs.
+	tests := []struct {
+		zone     string
+		sections []string
+		water    float64
+		costs    Costs
+	}{
+		{"west", []string{"section-A", "section-C"}, 0.5, Costs{Water: 1, Lighting: 3, Total: 4}},
+		{"east", []string{"section-B"}, 0.25, Costs{Water: 0.5, Total: 0.5}},
+	}
+
+	plants := 0
+	for _, tt := range tests {
+		t.Run(tt.zone, func(t *testing.T) {
+			stats, err := g.ZoneStats(tt.zone)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var expected ZoneStats
+			for _, sectionID := range tt.sections {
+				for _, plant := range g.Simulator().GetPlantsBySectionID(sectionID) {
+					expected.Plants++
+					if plant.Alive {
+						expected.AlivePlants++
+					}
+					expected.AverageHealth += plant.Health
+					expected.AverageSaturation += plant.SoilSaturation
+				}
+			}
+			expected.AverageHealth /= float64(expected.Plants)
+			expected.AverageSaturation /= float64(expected.Plants)
+			plants += stats.Plants
+
+			if stats.ZoneID != tt.zone || !reflect.DeepEqual(stats.Sections, tt.sections) {
+				t.Errorf("expected zone %s of %v, got %s of %v", tt.zone, tt.sections, stats.ZoneID, stats.Sections)
+			}
+			if stats.Plants != expected.Plants || stats.AlivePlants != expected.AlivePlants ||
+				math.Abs(stats.AverageHealth-expected.AverageHealth) > 1e-9 ||
+				math.Abs(stats.AverageSaturation-expected.AverageSaturation) > 1e-9 {
+				t.Errorf("expected the plants of the zone summarised as %+v, got %+v", expected, stats)
+			}
+			if math.Abs(stats.WaterUsed-tt.water) > 1e-9 || stats.Costs != tt.costs {
+				t.Errorf("expected %.2f water costing %+v, got %.2f costing %+v", tt.water, tt.costs, stats.WaterUsed, stats.Costs)
+			}
+		})
+	}
+	if total := g.Stats().Plants; plants != total {
+		t.Errorf("expected the zones to cover the %d plants once, got %d", total, plants)
+	}
+	if stats, _ := g.ZoneStats("west"); stats.Name != "West wing" || stats.AlivePlants != 2 || stats.AverageHealth == 1 {
+		t.Errorf("expected the shaded plant to weigh on the west wing, got %+v", stats)
+	}
+}
+
+func TestAddZone(t *testing.T) {
+	g, err := New(zonesConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		zone     config.ZoneConfig
+		expected error
+		errorMsg string
+	}{
+		{"taken ID", config.ZoneConfig{ID: "west", Sections: []string{"section-D"}}, ErrZoneExists, ""},
+		{"no sections", config.ZoneConfig{ID: "north"}, nil, "zone must have at least one section: north"},
+		{"unknown section", config.ZoneConfig{ID: "north", Sections: []string{"section-D"}}, nil, "zone north has an unknown section: section-D"},
+		{"section of another zone", config.ZoneConfig{ID: "north", Sections: []string{"section-B"}}, nil, "section section-B is in zones east and north"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := g.AddZone(tt.zone)
+			if tt.expected != nil && !errors.Is(err, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+			if tt.errorMsg != "" && (err == nil || err.Error() != tt.errorMsg) {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+
+	// A section gets known once a plant is added to it.
+	if _, err := g.AddPlant(config.PlantConfig{ID: "new", Type: "Sprout", SectionID: "section-D", InitialSaturation: 0.2}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	added, err := g.AddZone(config.ZoneConfig{ID: "north", Sections: []string{"section-D"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ids := []string{"east", "north", "west"}; len(g.Zones()) != 3 || g.Zones()[1].ID != ids[1] || added.ID != "north" {
+		t.Errorf("expected zones %v, got %+v", ids, g.Zones())
+	}
+	if _, err := g.ZoneStats("south"); !errors.Is(err, ErrZoneNotFound) {
+		t.Errorf("expected %v, got %v", ErrZoneNotFound, err)
+	}
+
+	// The new zone can be watered by a schedule.
+	err = g.Watering().AddSchedule(models.WateringSchedule{ZoneID: "north", TargetSaturation: 0.5, CheckInterval: 1, WaterAmount: 0.3, Enabled: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g.Simulator().Step()
+	if stats, _ := g.ZoneStats("north"); stats.WaterUsed == 0 {
+		t.Errorf("expected the zone schedule to water section-D, got %+v", stats)
+	}
+}
+
+func TestAlerts_ZoneDeadPlants(t *testing.T) {
+	cfg := testConfig()
+	cfg.Schedules = nil
+	cfg.Plants = append(cfg.Plants, config.PlantConfig{ID: "mint-1", Type: "Mint", SectionID: "section-B", InitialSaturation: 0.5,
+		State: &config.PlantStateConfig{Health: 0, Alive: true}})
+	cfg.Zones = []config.ZoneConfig{{ID: "east", Sections: []string{"section-B"}}}
+	cfg.Alerts = alertsOnly(config.AlertDeadPlants, 0, nil)
+	cfg.Alerts.Zones = map[string]map[string]config.AlertRuleConfig{"east": {config.AlertDeadPlants: {Severity: config.SeverityWarning}}}
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	var fired []Alert
+	g.Bus().Subscribe(func(e events.Event) {
+		if e.Type == events.Alert {
+			fired = append(fired, e.Payload.(Alert))
+		}
+	})
+	g.Simulator().Step()
+
+	// One plant in three is dead: below the greenhouse-wide threshold of 50%,
+	// but all of the east zone.
+	expected := []Alert{{Rule: config.AlertDeadPlants, Severity: config.SeverityWarning, ZoneID: "east",
+		Message: "100% of the plants of zone east are dead", Value: 100, Threshold: 50, Notifications: 1}}
+	if !reflect.DeepEqual(fired, expected) {
+		t.Errorf("expected %+v, got %+v", expected, fired)
+	}
+}
@@ -0,0 +1,71 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"greenhouse-simulator/internal/auth"
+	pb "greenhouse-simulator/internal/grpcapi/greenhousev1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// readMethods are the calls auth.ScopeRead allows and a read-only service
+// serves. Every other call needs auth.ScopeControl.
+var readMethods = map[string]bool{
+	pb.SimulatorService_GetStatus_FullMethodName:   true,
+	pb.SimulatorService_ListPlants_FullMethodName:  true,
+	pb.SimulatorService_GetReading_FullMethodName:  true,
+	pb.SimulatorService_WatchEvents_FullMethodName: true,
+}
+
+// RequireToken returns the server options that require every call to carry
+// a token of a, as "Bearer <token>" authorization metadata, granting the
+// scope of the call: auth.ScopeRead for queries and WatchEvents, checked
+// once when the stream opens, and auth.ScopeControl for the rest. Calls
+// without a known token fail with UNAUTHENTICATED, those whose token lacks
+// the scope with PERMISSION_DENIED. An authorizer without tokens lets every
+// call through and adds no options.
+func RequireToken(a *auth.Authorizer) []grpc.ServerOption {
+	if !a.Enabled() {
+		return nil
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := authorize(ctx, a, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := authorize(stream.Context(), a, info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, stream)
+		}),
+	}
+}
+
+// authorize checks the token of a call to method.
+func authorize(ctx context.Context, a *auth.Authorizer, method string) error {
+	required := auth.ScopeControl
+	if readMethods[method] {
+		required = auth.ScopeRead
+	}
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			token = auth.BearerToken(values[0])
+		}
+	}
+	_, err := a.Authorize(token, required)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, auth.ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	return status.Error(codes.PermissionDenied, err.Error())
+}
@@ -0,0 +1,74 @@
+package grpcapi
+
+import (
+	"context"
+	"greenhouse-simulator/internal/auth"
+	pb "greenhouse-simulator/internal/grpcapi/greenhousev1"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRequireToken(t *testing.T) {
+	const readSecret, controlSecret = "read-secret-0123456789", "control-secret-0123456789"
+	client, g := newTestClient(t, RequireToken(auth.New([]auth.Token{
+		{Name: "dashboard", Secret: readSecret, Scope: auth.ScopeRead},
+		{Name: "operator", Secret: controlSecret, Scope: auth.ScopeControl},
+	}))...)
+	withToken := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	}
+	getStatus := func(ctx context.Context) error {
+		_, err := client.GetStatus(ctx, &pb.GetStatusRequest{})
+		return err
+	}
+	pause := func(ctx context.Context) error {
+		_, err := client.Pause(ctx, &pb.PauseRequest{})
+		return err
+	}
+	watch := func(ctx context.Context) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stream, err := client.WatchEvents(ctx, &pb.WatchEventsRequest{})
+		if err != nil {
+			return err
+		}
+		// A refused stream ends before its headers; an accepted one gets the
+		// events of the next tick.
+		if _, err := stream.Header(); err != nil {
+			return err
+		}
+		g.Simulator().Step()
+		_, err = stream.Recv()
+		return err
+	}
+
+	tests := []struct {
+		name     string
+		call     func(context.Context) error
+		ctx      context.Context
+		expected codes.Code
+	}{
+		{"missing token", getStatus, context.Background(), codes.Unauthenticated},
+		{"unknown token", getStatus, withToken("unknown-secret-0123456789"), codes.Unauthenticated},
+		{"read token reads", getStatus, withToken(readSecret), codes.OK},
+		{"read token pauses", pause, withToken(readSecret), codes.PermissionDenied},
+		{"control token pauses", pause, withToken(controlSecret), codes.FailedPrecondition},
+		{"watch without token", watch, context.Background(), codes.Unauthenticated},
+		{"watch with read token", watch, withToken(readSecret), codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call(tt.ctx)
+			if code := status.Code(err); code != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+			if err != nil && (strings.Contains(err.Error(), readSecret) || strings.Contains(err.Error(), controlSecret)) {
+				t.Errorf("expected the error to leave the token out, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,1625 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: greenhouse/v1/simulator.proto
+
+package greenhousev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatusRequest) Reset() {
+	*x = GetStatusRequest{}
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusRequest) ProtoMessage() {}
+
+func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_greenhouse_v1_simulator_proto_rawDescGZIP(), []int{0}
+}
+
+type GetStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        *Status                `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatusResponse) Reset() {
+	*x = GetStatusResponse{}
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusResponse) ProtoMessage() {}
+
+func (x *GetStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetStatusResponse) Descriptor() ([]byte, []int) {
+	return file_greenhouse_v1_simulator_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetStatusResponse) GetStatus() *Status {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+type Status struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tick          int64                  `protobuf:"varint,1,opt,name=tick,proto3" json:"tick,omitempty"`
+	Paused        bool                   `protobuf:"varint,2,opt,name=paused,proto3" json:"paused,omitempty"`
+	TickInterval  *durationpb.Duration   `protobuf:"bytes,3,opt,name=tick_interval,json=tickInterval,proto3" json:"tick_interval,omitempty"`
+	Plants        int32                  `protobuf:"varint,4,opt,name=plants,proto3" json:"plants,omitempty"`
+	AlivePlants   int32                  `protobuf:"varint,5,opt,name=alive_plants,json=alivePlants,proto3" json:"alive_plants,omitempty"`
+	Sensors       int32                  `protobuf:"varint,6,opt,name=sensors,proto3" json:"sensors,omitempty"`
+	Water         *WaterStatus           `protobuf:"bytes,7,opt,name=water,proto3" json:"water,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Status) Reset() {
+	*x = Status{}
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Status) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Status) ProtoMessage() {}
+
+func (x *Status) ProtoReflect() protoreflect.Message {
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Status.ProtoReflect.Descriptor instead.
+func (*Status) Descriptor() ([]byte, []int) {
+	return file_greenhouse_v1_simulator_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Status) GetTick() int64 {
+	if x != nil {
+		return x.Tick
+	}
+	return 0
+}
+
+func (x *Status) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *Status) GetTickInterval() *durationpb.Duration {
+	if x != nil {
+		return x.TickInterval
+	}
+	return nil
+}
+
+func (x *Status) GetPlants() int32 {
+	if x != nil {
+		return x.Plants
+	}
+	return 0
+}
+
+func (x *Status) GetAlivePlants() int32 {
+	if x != nil {
+		return x.AlivePlants
+	}
+	return 0
+}
+
+func (x *Status) GetSensors() int32 {
+	if x != nil {
+		return x.Sensors
+	}
+	return 0
+}
+
+func (x *Status) GetWater() *WaterStatus {
+	if x != nil {
+		return x.Water
+	}
+	return nil
+}
+
+// WaterStatus is the water accounting of the greenhouse. remaining is unset
+// when the greenhouse has no tank.
+type WaterStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Used          float64                `protobuf:"fixed64,1,opt,name=used,proto3" json:"used,omitempty"`
+	Wasted        float64                `protobuf:"fixed64,2,opt,name=wasted,proto3" json:"wasted,omitempty"`
+	Remaining     *float64               `protobuf:"fixed64,3,opt,name=remaining,proto3,oneof" json:"remaining,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WaterStatus) Reset() {
+	*x = WaterStatus{}
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WaterStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WaterStatus) ProtoMessage() {}
+
+func (x *WaterStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WaterStatus.ProtoReflect.Descriptor instead.
+func (*WaterStatus) Descriptor() ([]byte, []int) {
+	return file_greenhouse_v1_simulator_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *WaterStatus) GetUsed() float64 {
+	if x != nil {
+		return x.Used
+	}
+	return 0
+}
+
+func (x *WaterStatus) GetWasted() float64 {
+	if x != nil {
+		return x.Wasted
+	}
+	return 0
+}
+
+func (x *WaterStatus) GetRemaining() float64 {
+	if x != nil && x.Remaining != nil {
+		return *x.Remaining
+	}
+	return 0
+}
+
+type ListPlantsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPlantsRequest) Reset() {
+	*x = ListPlantsRequest{}
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPlantsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPlantsRequest) ProtoMessage() {}
+
+func (x *ListPlantsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPlantsRequest.ProtoReflect.Descriptor instead.
+func (*ListPlantsRequest) Descriptor() ([]byte, []int) {
+	return file_greenhouse_v1_simulator_proto_rawDescGZIP(), []int{4}
+}
+
+type ListPlantsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Plants        []*Plant               `protobuf:"bytes,1,rep,name=plants,proto3" json:"plants,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPlantsResponse) Reset() {
+	*x = ListPlantsResponse{}
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPlantsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPlantsResponse) ProtoMessage() {}
+
+func (x *ListPlantsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPlantsResponse.ProtoReflect.Descriptor instead.
+func (*ListPlantsResponse) Descriptor() ([]byte, []int) {
+	return file_greenhouse_v1_simulator_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListPlantsResponse) GetPlants() []*Plant {
+	if x != nil {
+		return x.Plants
+	}
+	return nil
+}
+
+type Plant struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type           string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Section        string                 `protobuf:"bytes,3,opt,name=section,proto3" json:"section,omitempty"`
+	SoilSaturation float64                `protobuf:"fixed64,4,opt,name=soil_saturation,json=soilSaturation,proto3" json:"soil_saturation,omitempty"`
+	Health         float64                `protobuf:"fixed64,5,opt,name=health,proto3" json:"health,omitempty"`
+	GrowthStage    float64                `protobuf:"fixed64,6,opt,name=growth_stage,json=growthStage,proto3" json:"growth_stage,omitempty"`
+	Alive          bool                   `protobuf:"varint,7,opt,name=alive,proto3" json:"alive,omitempty"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Tags           []string               `protobuf:"bytes,9,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Plant) Reset() {
+	*x = Plant{}
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Plant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Plant) ProtoMessage() {}
+
+func (x *Plant) ProtoReflect() protoreflect.Message {
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Plant.ProtoReflect.Descriptor instead.
+func (*Plant) Descriptor() ([]byte, []int) {
+	return file_greenhouse_v1_simulator_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Plant) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Plant) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Plant) GetSection() string {
+	if x != nil {
+		return x.Section
+	}
+	return ""
+}
+
+func (x *Plant) GetSoilSaturation() float64 {
+	if x != nil {
+		return x.SoilSaturation
+	}
+	return 0
+}
+
+func (x *Plant) GetHealth() float64 {
+	if x != nil {
+		return x.Health
+	}
+	return 0
+}
+
+func (x *Plant) GetGrowthStage() float64 {
+	if x != nil {
+		return x.GrowthStage
+	}
+	return 0
+}
+
+func (x *Plant) GetAlive() bool {
+	if x != nil {
+		return x.Alive
+	}
+	return false
+}
+
+func (x *Plant) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Plant) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+// AddPlantRequest mirrors a plant entry of the config file.
+type AddPlantRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type              string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Section           string                 `protobuf:"bytes,3,opt,name=section,proto3" json:"section,omitempty"`
+	InitialSaturation float64                `protobuf:"fixed64,4,opt,name=initial_saturation,json=initialSaturation,proto3" json:"initial_saturation,omitempty"`
+	Tags              []string               `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *AddPlantRequest) Reset() {
+	*x = AddPlantRequest{}
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddPlantRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddPlantRequest) ProtoMessage() {}
+
+func (x *AddPlantRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddPlantRequest.ProtoReflect.Descriptor instead.
+func (*AddPlantRequest) Descriptor() ([]byte, []int) {
+	return file_greenhouse_v1_simulator_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *AddPlantRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AddPlantRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *AddPlantRequest) GetSection() string {
+	if x != nil {
+		return x.Section
+	}
+	return ""
+}
+
+func (x *AddPlantRequest) GetInitialSaturation() float64 {
+	if x != nil {
+		return x.InitialSaturation
+	}
+	return 0
+}
+
+func (x *AddPlantRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type AddPlantResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Plant         *Plant                 `protobuf:"bytes,1,opt,name=plant,proto3" json:"plant,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddPlantResponse) Reset() {
+	*x = AddPlantResponse{}
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddPlantResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddPlantResponse) ProtoMessage() {}
+
+func (x *AddPlantResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddPlantResponse.ProtoReflect.Descriptor instead.
+func (*AddPlantResponse) Descriptor() ([]byte, []int) {
+	return file_greenhouse_v1_simulator_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *AddPlantResponse) GetPlant() *Plant {
+	if x != nil {
+		return x.Plant
+	}
+	return nil
+}
+
+type WaterSectionRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Section string                 `protobuf:"bytes,1,opt,name=section,proto3" json:"section,omitempty"`
+	Amount  float64                `protobuf:"fixed64,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	// duration spreads the water over several ticks; unset applies it on the
+	// next tick.
+	Duration      *durationpb.Duration `protobuf:"bytes,3,opt,name=duration,proto3" json:"duration,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WaterSectionRequest) Reset() {
+	*x = WaterSectionRequest{}
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WaterSectionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WaterSectionRequest) ProtoMessage() {}
+
+func (x *WaterSectionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WaterSectionRequest.ProtoReflect.Descriptor instead.
+func (*WaterSectionRequest) Descriptor() ([]byte, []int) {
+	return file_greenhouse_v1_simulator_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *WaterSectionRequest) GetSection() string {
+	if x != nil {
+		return x.Section
+	}
+	return ""
+}
+
+func (x *WaterSectionRequest) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *WaterSectionRequest) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+type WaterSectionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WaterSectionResponse) Reset() {
+	*x = WaterSectionResponse{}
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WaterSectionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WaterSectionResponse) ProtoMessage() {}
+
+func (x *WaterSectionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WaterSectionResponse.ProtoReflect.Descriptor instead.
+func (*WaterSectionResponse) Descriptor() ([]byte, []int) {
+	return file_greenhouse_v1_simulator_proto_rawDescGZIP(), []int{10}
+}
+
+type PauseRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PauseRequest) Reset() {
+	*x = PauseRequest{}
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PauseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PauseRequest) ProtoMessage() {}
+
+func (x *PauseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PauseRequest.ProtoReflect.Descriptor instead.
+func (*PauseRequest) Descriptor() ([]byte, []int) {
+	return file_greenhouse_v1_simulator_proto_rawDescGZIP(), []int{11}
+}
+
+type PauseResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        *Status                `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PauseResponse) Reset() {
+	*x = PauseResponse{}
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PauseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PauseResponse) ProtoMessage() {}
+
+func (x *PauseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PauseResponse.ProtoReflect.Descriptor instead.
+func (*PauseResponse) Descriptor() ([]byte, []int) {
+	return file_greenhouse_v1_simulator_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *PauseResponse) GetStatus() *Status {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+type ResumeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumeRequest) Reset() {
+	*x = ResumeRequest{}
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeRequest) ProtoMessage() {}
+
+func (x *ResumeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumeRequest.ProtoReflect.Descriptor instead.
+func (*ResumeRequest) Descriptor() ([]byte, []int) {
+	return file_greenhouse_v1_simulator_proto_rawDescGZIP(), []int{13}
+}
+
+type ResumeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        *Status                `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumeResponse) Reset() {
+	*x = ResumeResponse{}
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeResponse) ProtoMessage() {}
+
+func (x *ResumeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumeResponse.ProtoReflect.Descriptor instead.
+func (*ResumeResponse) Descriptor() ([]byte, []int) {
+	return file_greenhouse_v1_simulator_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ResumeResponse) GetStatus() *Status {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+type GetReadingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SensorId      string                 `protobuf:"bytes,1,opt,name=sensor_id,json=sensorId,proto3" json:"sensor_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetReadingRequest) Reset() {
+	*x = GetReadingRequest{}
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetReadingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetReadingRequest) ProtoMessage() {}
+
+func (x *GetReadingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetReadingRequest.ProtoReflect.Descriptor instead.
+func (*GetReadingRequest) Descriptor() ([]byte, []int) {
+	return file_greenhouse_v1_simulator_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetReadingRequest) GetSensorId() string {
+	if x != nil {
+		return x.SensorId
+	}
+	return ""
+}
+
+type GetReadingResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reading       *Reading               `protobuf:"bytes,1,opt,name=reading,proto3" json:"reading,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetReadingResponse) Reset() {
+	*x = GetReadingResponse{}
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetReadingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetReadingResponse) ProtoMessage() {}
+
+func (x *GetReadingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetReadingResponse.ProtoReflect.Descriptor instead.
+func (*GetReadingResponse) Descriptor() ([]byte, []int) {
+	return file_greenhouse_v1_simulator_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetReadingResponse) GetReading() *Reading {
+	if x != nil {
+		return x.Reading
+	}
+	return nil
+}
+
+type Reading struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SensorId      string                 `protobuf:"bytes,1,opt,name=sensor_id,json=sensorId,proto3" json:"sensor_id,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Value         float64                `protobuf:"fixed64,3,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Reading) Reset() {
+	*x = Reading{}
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reading) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reading) ProtoMessage() {}
+
+func (x *Reading) ProtoReflect() protoreflect.Message {
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reading.ProtoReflect.Descriptor instead.
+func (*Reading) Descriptor() ([]byte, []int) {
+	return file_greenhouse_v1_simulator_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Reading) GetSensorId() string {
+	if x != nil {
+		return x.SensorId
+	}
+	return ""
+}
+
+func (x *Reading) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Reading) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+// WatchEventsRequest filters the streamed events. Empty fields let
+// everything through; a section keeps greenhouse-wide events such as ticks.
+type WatchEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Types         []string               `protobuf:"bytes,1,rep,name=types,proto3" json:"types,omitempty"`
+	Section       string                 `protobuf:"bytes,2,opt,name=section,proto3" json:"section,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEventsRequest) Reset() {
+	*x = WatchEventsRequest{}
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEventsRequest) ProtoMessage() {}
+
+func (x *WatchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_greenhouse_v1_simulator_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *WatchEventsRequest) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+func (x *WatchEventsRequest) GetSection() string {
+	if x != nil {
+		return x.Section
+	}
+	return ""
+}
+
+type WatchEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *Event                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEventsResponse) Reset() {
+	*x = WatchEventsResponse{}
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEventsResponse) ProtoMessage() {}
+
+func (x *WatchEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEventsResponse.ProtoReflect.Descriptor instead.
+func (*WatchEventsResponse) Descriptor() ([]byte, []int) {
+	return file_greenhouse_v1_simulator_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *WatchEventsResponse) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+type Event struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Type      string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Tick      int64                  `protobuf:"varint,2,opt,name=tick,proto3" json:"tick,omitempty"`
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Section   string                 `protobuf:"bytes,4,opt,name=section,proto3" json:"section,omitempty"`
+	PlantId   string                 `protobuf:"bytes,5,opt,name=plant_id,json=plantId,proto3" json:"plant_id,omitempty"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*Event_Stats
+	//	*Event_Reading
+	//	*Event_Watering
+	Payload       isEvent_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_greenhouse_v1_simulator_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetTick() int64 {
+	if x != nil {
+		return x.Tick
+	}
+	return 0
+}
+
+func (x *Event) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Event) GetSection() string {
+	if x != nil {
+		return x.Section
+	}
+	return ""
+}
+
+func (x *Event) GetPlantId() string {
+	if x != nil {
+		return x.PlantId
+	}
+	return ""
+}
+
+func (x *Event) GetPayload() isEvent_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Event) GetStats() *Stats {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_Stats); ok {
+			return x.Stats
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetReading() *Reading {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_Reading); ok {
+			return x.Reading
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetWatering() *WateringEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_Watering); ok {
+			return x.Watering
+		}
+	}
+	return nil
+}
+
+type isEvent_Payload interface {
+	isEvent_Payload()
+}
+
+type Event_Stats struct {
+	Stats *Stats `protobuf:"bytes,6,opt,name=stats,proto3,oneof"`
+}
+
+type Event_Reading struct {
+	Reading *Reading `protobuf:"bytes,7,opt,name=reading,proto3,oneof"`
+}
+
+type Event_Watering struct {
+	Watering *WateringEvent `protobuf:"bytes,8,opt,name=watering,proto3,oneof"`
+}
+
+func (*Event_Stats) isEvent_Payload() {}
+
+func (*Event_Reading) isEvent_Payload() {}
+
+func (*Event_Watering) isEvent_Payload() {}
+
+// Stats is the greenhouse summary carried by tick events. tank_remaining is
+// unset when the greenhouse has no tank.
+type Stats struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Plants            int32                  `protobuf:"varint,1,opt,name=plants,proto3" json:"plants,omitempty"`
+	AlivePlants       int32                  `protobuf:"varint,2,opt,name=alive_plants,json=alivePlants,proto3" json:"alive_plants,omitempty"`
+	AverageHealth     float64                `protobuf:"fixed64,3,opt,name=average_health,json=averageHealth,proto3" json:"average_health,omitempty"`
+	AverageSaturation float64                `protobuf:"fixed64,4,opt,name=average_saturation,json=averageSaturation,proto3" json:"average_saturation,omitempty"`
+	WaterUsed         float64                `protobuf:"fixed64,5,opt,name=water_used,json=waterUsed,proto3" json:"water_used,omitempty"`
+	WaterWasted       float64                `protobuf:"fixed64,6,opt,name=water_wasted,json=waterWasted,proto3" json:"water_wasted,omitempty"`
+	TankRemaining     *float64               `protobuf:"fixed64,7,opt,name=tank_remaining,json=tankRemaining,proto3,oneof" json:"tank_remaining,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Stats) Reset() {
+	*x = Stats{}
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Stats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Stats) ProtoMessage() {}
+
+func (x *Stats) ProtoReflect() protoreflect.Message {
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Stats.ProtoReflect.Descriptor instead.
+func (*Stats) Descriptor() ([]byte, []int) {
+	return file_greenhouse_v1_simulator_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *Stats) GetPlants() int32 {
+	if x != nil {
+		return x.Plants
+	}
+	return 0
+}
+
+func (x *Stats) GetAlivePlants() int32 {
+	if x != nil {
+		return x.AlivePlants
+	}
+	return 0
+}
+
+func (x *Stats) GetAverageHealth() float64 {
+	if x != nil {
+		return x.AverageHealth
+	}
+	return 0
+}
+
+func (x *Stats) GetAverageSaturation() float64 {
+	if x != nil {
+		return x.AverageSaturation
+	}
+	return 0
+}
+
+func (x *Stats) GetWaterUsed() float64 {
+	if x != nil {
+		return x.WaterUsed
+	}
+	return 0
+}
+
+func (x *Stats) GetWaterWasted() float64 {
+	if x != nil {
+		return x.WaterWasted
+	}
+	return 0
+}
+
+func (x *Stats) GetTankRemaining() float64 {
+	if x != nil && x.TankRemaining != nil {
+		return *x.TankRemaining
+	}
+	return 0
+}
+
+type WateringEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Section       string                 `protobuf:"bytes,2,opt,name=section,proto3" json:"section,omitempty"`
+	PlantId       string                 `protobuf:"bytes,3,opt,name=plant_id,json=plantId,proto3" json:"plant_id,omitempty"`
+	Amount        float64                `protobuf:"fixed64,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	Duration      *durationpb.Duration   `protobuf:"bytes,5,opt,name=duration,proto3" json:"duration,omitempty"`
+	Manual        bool                   `protobuf:"varint,6,opt,name=manual,proto3" json:"manual,omitempty"`
+	Method        string                 `protobuf:"bytes,7,opt,name=method,proto3" json:"method,omitempty"`
+	ScheduleId    string                 `protobuf:"bytes,8,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WateringEvent) Reset() {
+	*x = WateringEvent{}
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WateringEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WateringEvent) ProtoMessage() {}
+
+func (x *WateringEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_greenhouse_v1_simulator_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WateringEvent.ProtoReflect.Descriptor instead.
+func (*WateringEvent) Descriptor() ([]byte, []int) {
+	return file_greenhouse_v1_simulator_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *WateringEvent) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *WateringEvent) GetSection() string {
+	if x != nil {
+		return x.Section
+	}
+	return ""
+}
+
+func (x *WateringEvent) GetPlantId() string {
+	if x != nil {
+		return x.PlantId
+	}
+	return ""
+}
+
+func (x *WateringEvent) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *WateringEvent) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *WateringEvent) GetManual() bool {
+	if x != nil {
+		return x.Manual
+	}
+	return false
+}
+
+func (x *WateringEvent) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *WateringEvent) GetScheduleId() string {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return ""
+}
+
+var File_greenhouse_v1_simulator_proto protoreflect.FileDescriptor
+
+const file_greenhouse_v1_simulator_proto_rawDesc = "" +
+	"\n" +
+	"\x1dgreenhouse/v1/simulator.proto\x12\rgreenhouse.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x12\n" +
+	"\x10GetStatusRequest\"B\n" +
+	"\x11GetStatusResponse\x12-\n" +
+	"\x06status\x18\x01 \x01(\v2\x15.greenhouse.v1.StatusR\x06status\"\xfb\x01\n" +
+	"\x06Status\x12\x12\n" +
+	"\x04tick\x18\x01 \x01(\x03R\x04tick\x12\x16\n" +
+	"\x06paused\x18\x02 \x01(\bR\x06paused\x12>\n" +
+	"\rtick_interval\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\ftickInterval\x12\x16\n" +
+	"\x06plants\x18\x04 \x01(\x05R\x06plants\x12!\n" +
+	"\falive_plants\x18\x05 \x01(\x05R\valivePlants\x12\x18\n" +
+	"\asensors\x18\x06 \x01(\x05R\asensors\x120\n" +
+	"\x05water\x18\a \x01(\v2\x1a.greenhouse.v1.WaterStatusR\x05water\"j\n" +
+	"\vWaterStatus\x12\x12\n" +
+	"\x04used\x18\x01 \x01(\x01R\x04used\x12\x16\n" +
+	"\x06wasted\x18\x02 \x01(\x01R\x06wasted\x12!\n" +
+	"\tremaining\x18\x03 \x01(\x01H\x00R\tremaining\x88\x01\x01B\f\n" +
+	"\n" +
+	"_remaining\"\x13\n" +
+	"\x11ListPlantsRequest\"B\n" +
+	"\x12ListPlantsResponse\x12,\n" +
+	"\x06plants\x18\x01 \x03(\v2\x14.greenhouse.v1.PlantR\x06plants\"\x8e\x02\n" +
+	"\x05Plant\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x18\n" +
+	"\asection\x18\x03 \x01(\tR\asection\x12'\n" +
+	"\x0fsoil_saturation\x18\x04 \x01(\x01R\x0esoilSaturation\x12\x16\n" +
+	"\x06health\x18\x05 \x01(\x01R\x06health\x12!\n" +
+	"\fgrowth_stage\x18\x06 \x01(\x01R\vgrowthStage\x12\x14\n" +
+	"\x05alive\x18\a \x01(\bR\x05alive\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x12\n" +
+	"\x04tags\x18\t \x03(\tR\x04tags\"\x92\x01\n" +
+	"\x0fAddPlantRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x18\n" +
+	"\asection\x18\x03 \x01(\tR\asection\x12-\n" +
+	"\x12initial_saturation\x18\x04 \x01(\x01R\x11initialSaturation\x12\x12\n" +
+	"\x04tags\x18\x05 \x03(\tR\x04tags\">\n" +
+	"\x10AddPlantResponse\x12*\n" +
+	"\x05plant\x18\x01 \x01(\v2\x14.greenhouse.v1.PlantR\x05plant\"~\n" +
+	"\x13WaterSectionRequest\x12\x18\n" +
+	"\asection\x18\x01 \x01(\tR\asection\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x01R\x06amount\x125\n" +
+	"\bduration\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\bduration\"\x16\n" +
+	"\x14WaterSectionResponse\"\x0e\n" +
+	"\fPauseRequest\">\n" +
+	"\rPauseResponse\x12-\n" +
+	"\x06status\x18\x01 \x01(\v2\x15.greenhouse.v1.StatusR\x06status\"\x0f\n" +
+	"\rResumeRequest\"?\n" +
+	"\x0eResumeResponse\x12-\n" +
+	"\x06status\x18\x01 \x01(\v2\x15.greenhouse.v1.StatusR\x06status\"0\n" +
+	"\x11GetReadingRequest\x12\x1b\n" +
+	"\tsensor_id\x18\x01 \x01(\tR\bsensorId\"F\n" +
+	"\x12GetReadingResponse\x120\n" +
+	"\areading\x18\x01 \x01(\v2\x16.greenhouse.v1.ReadingR\areading\"v\n" +
+	"\aReading\x12\x1b\n" +
+	"\tsensor_id\x18\x01 \x01(\tR\bsensorId\x128\n" +
+	"\ttimestamp\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\x01R\x05value\"D\n" +
+	"\x12WatchEventsRequest\x12\x14\n" +
+	"\x05types\x18\x01 \x03(\tR\x05types\x12\x18\n" +
+	"\asection\x18\x02 \x01(\tR\asection\"A\n" +
+	"\x13WatchEventsResponse\x12*\n" +
+	"\x05event\x18\x01 \x01(\v2\x14.greenhouse.v1.EventR\x05event\"\xc7\x02\n" +
+	"\x05Event\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x12\n" +
+	"\x04tick\x18\x02 \x01(\x03R\x04tick\x128\n" +
+	"\ttimestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x18\n" +
+	"\asection\x18\x04 \x01(\tR\asection\x12\x19\n" +
+	"\bplant_id\x18\x05 \x01(\tR\aplantId\x12,\n" +
+	"\x05stats\x18\x06 \x01(\v2\x14.greenhouse.v1.StatsH\x00R\x05stats\x122\n" +
+	"\areading\x18\a \x01(\v2\x16.greenhouse.v1.ReadingH\x00R\areading\x12:\n" +
+	"\bwatering\x18\b \x01(\v2\x1c.greenhouse.v1.WateringEventH\x00R\bwateringB\t\n" +
+	"\apayload\"\x99\x02\n" +
+	"\x05Stats\x12\x16\n" +
+	"\x06plants\x18\x01 \x01(\x05R\x06plants\x12!\n" +
+	"\falive_plants\x18\x02 \x01(\x05R\valivePlants\x12%\n" +
+	"\x0eaverage_health\x18\x03 \x01(\x01R\raverageHealth\x12-\n" +
+	"\x12average_saturation\x18\x04 \x01(\x01R\x11averageSaturation\x12\x1d\n" +
+	"\n" +
+	"water_used\x18\x05 \x01(\x01R\twaterUsed\x12!\n" +
+	"\fwater_wasted\x18\x06 \x01(\x01R\vwaterWasted\x12*\n" +
+	"\x0etank_remaining\x18\a \x01(\x01H\x00R\rtankRemaining\x88\x01\x01B\x11\n" +
+	"\x0f_tank_remaining\"\xf4\x01\n" +
+	"\rWateringEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\asection\x18\x02 \x01(\tR\asection\x12\x19\n" +
+	"\bplant_id\x18\x03 \x01(\tR\aplantId\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\x01R\x06amount\x125\n" +
+	"\bduration\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\bduration\x12\x16\n" +
+	"\x06manual\x18\x06 \x01(\bR\x06manual\x12\x16\n" +
+	"\x06method\x18\a \x01(\tR\x06method\x12\x1f\n" +
+	"\vschedule_id\x18\b \x01(\tR\n" +
+	"scheduleId2\x91\x05\n" +
+	"\x10SimulatorService\x12N\n" +
+	"\tGetStatus\x12\x1f.greenhouse.v1.GetStatusRequest\x1a .greenhouse.v1.GetStatusResponse\x12Q\n" +
+	"\n" +
+	"ListPlants\x12 .greenhouse.v1.ListPlantsRequest\x1a!.greenhouse.v1.ListPlantsResponse\x12K\n" +
+	"\bAddPlant\x12\x1e.greenhouse.v1.AddPlantRequest\x1a\x1f.greenhouse.v1.AddPlantResponse\x12W\n" +
+	"\fWaterSection\x12\".greenhouse.v1.WaterSectionRequest\x1a#.greenhouse.v1.WaterSectionResponse\x12B\n" +
+	"\x05Pause\x12\x1b.greenhouse.v1.PauseRequest\x1a\x1c.greenhouse.v1.PauseResponse\x12E\n" +
+	"\x06Resume\x12\x1c.greenhouse.v1.ResumeRequest\x1a\x1d.greenhouse.v1.ResumeResponse\x12Q\n" +
+	"\n" +
+	"GetReading\x12 .greenhouse.v1.GetReadingRequest\x1a!.greenhouse.v1.GetReadingResponse\x12V\n" +
+	"\vWatchEvents\x12!.greenhouse.v1.WatchEventsRequest\x1a\".greenhouse.v1.WatchEventsResponse0\x01BAZ?greenhouse-simulator/internal/grpcapi/greenhousev1;greenhousev1b\x06proto3"
+
+var (
+	file_greenhouse_v1_simulator_proto_rawDescOnce sync.Once
+	file_greenhouse_v1_simulator_proto_rawDescData []byte
+)
+
+func file_greenhouse_v1_simulator_proto_rawDescGZIP() []byte {
+	file_greenhouse_v1_simulator_proto_rawDescOnce.Do(func() {
+		file_greenhouse_v1_simulator_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_greenhouse_v1_simulator_proto_rawDesc), len(file_greenhouse_v1_simulator_proto_rawDesc)))
+	})
+	return file_greenhouse_v1_simulator_proto_rawDescData
+}
+
+var file_greenhouse_v1_simulator_proto_msgTypes = make([]protoimpl.MessageInfo, 23)
+var file_greenhouse_v1_simulator_proto_goTypes = []any{
+	(*GetStatusRequest)(nil),      // 0: greenhouse.v1.GetStatusRequest
+	(*GetStatusResponse)(nil),     // 1: greenhouse.v1.GetStatusResponse
+	(*Status)(nil),                // 2: greenhouse.v1.Status
+	(*WaterStatus)(nil),           // 3: greenhouse.v1.WaterStatus
+	(*ListPlantsRequest)(nil),     // 4: greenhouse.v1.ListPlantsRequest
+	(*ListPlantsResponse)(nil),    // 5: greenhouse.v1.ListPlantsResponse
+	(*Plant)(nil),                 // 6: greenhouse.v1.Plant
+	(*AddPlantRequest)(nil),       // 7: greenhouse.v1.AddPlantRequest
+	(*AddPlantResponse)(nil),      // 8: greenhouse.v1.AddPlantResponse
+	(*WaterSectionRequest)(nil),   // 9: greenhouse.v1.WaterSectionRequest
+	(*WaterSectionResponse)(nil),  // 10: greenhouse.v1.WaterSectionResponse
+	(*PauseRequest)(nil),          // 11: greenhouse.v1.PauseRequest
+	(*PauseResponse)(nil),         // 12: greenhouse.v1.PauseResponse
+	(*ResumeRequest)(nil),         // 13: greenhouse.v1.ResumeRequest
+	(*ResumeResponse)(nil),        // 14: greenhouse.v1.ResumeResponse
+	(*GetReadingRequest)(nil),     // 15: greenhouse.v1.GetReadingRequest
+	(*GetReadingResponse)(nil),    // 16: greenhouse.v1.GetReadingResponse
+	(*Reading)(nil),               // 17: greenhouse.v1.Reading
+	(*WatchEventsRequest)(nil),    // 18: greenhouse.v1.WatchEventsRequest
+	(*WatchEventsResponse)(nil),   // 19: greenhouse.v1.WatchEventsResponse
+	(*Event)(nil),                 // 20: greenhouse.v1.Event
+	(*Stats)(nil),                 // 21: greenhouse.v1.Stats
+	(*WateringEvent)(nil),         // 22: greenhouse.v1.WateringEvent
+	(*durationpb.Duration)(nil),   // 23: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil), // 24: google.protobuf.Timestamp
+}
+var file_greenhouse_v1_simulator_proto_depIdxs = []int32{
+	2,  // 0: greenhouse.v1.GetStatusResponse.status:type_name -> greenhouse.v1.Status
+	23, // 1: greenhouse.v1.Status.tick_interval:type_name -> google.protobuf.Duration
+	3,  // 2: greenhouse.v1.Status.water:type_name -> greenhouse.v1.WaterStatus
+	6,  // 3: greenhouse.v1.ListPlantsResponse.plants:type_name -> greenhouse.v1.Plant
+	24, // 4: greenhouse.v1.Plant.created_at:type_name -> google.protobuf.Timestamp
+	6,  // 5: greenhouse.v1.AddPlantResponse.plant:type_name -> greenhouse.v1.Plant
+	23, // 6: greenhouse.v1.WaterSectionRequest.duration:type_name -> google.protobuf.Duration
+	2,  // 7: greenhouse.v1.PauseResponse.status:type_name -> greenhouse.v1.Status
+	2,  // 8: greenhouse.v1.ResumeResponse.status:type_name -> greenhouse.v1.Status
+	17, // 9: greenhouse.v1.GetReadingResponse.reading:type_name -> greenhouse.v1.Reading
+	24, // 10: greenhouse.v1.Reading.timestamp:type_name -> google.protobuf.Timestamp
+	20, // 11: greenhouse.v1.WatchEventsResponse.event:type_name -> greenhouse.v1.Event
+	24, // 12: greenhouse.v1.Event.timestamp:type_name -> google.protobuf.Timestamp
+	21, // 13: greenhouse.v1.Event.stats:type_name -> greenhouse.v1.Stats
+	17, // 14: greenhouse.v1.Event.reading:type_name -> greenhouse.v1.Reading
+	22, // 15: greenhouse.v1.Event.watering:type_name -> greenhouse.v1.WateringEvent
+	23, // 16: greenhouse.v1.WateringEvent.duration:type_name -> google.protobuf.Duration
+	0,  // 17: greenhouse.v1.SimulatorService.GetStatus:input_type -> greenhouse.v1.GetStatusRequest
+	4,  // 18: greenhouse.v1.SimulatorService.ListPlants:input_type -> greenhouse.v1.ListPlantsRequest
+	7,  // 19: greenhouse.v1.SimulatorService.AddPlant:input_type -> greenhouse.v1.AddPlantRequest
+	9,  // 20: greenhouse.v1.SimulatorService.WaterSection:input_type -> greenhouse.v1.WaterSectionRequest
+	11, // 21: greenhouse.v1.SimulatorService.Pause:input_type -> greenhouse.v1.PauseRequest
+	13, // 22: greenhouse.v1.SimulatorService.Resume:input_type -> greenhouse.v1.ResumeRequest
+	15, // 23: greenhouse.v1.SimulatorService.GetReading:input_type -> greenhouse.v1.GetReadingRequest
+	18, // 24: greenhouse.v1.SimulatorService.WatchEvents:input_type -> greenhouse.v1.WatchEventsRequest
+	1,  // 25: greenhouse.v1.SimulatorService.GetStatus:output_type -> greenhouse.v1.GetStatusResponse
+	5,  // 26: greenhouse.v1.SimulatorService.ListPlants:output_type -> greenhouse.v1.ListPlantsResponse
+	8,  // 27: greenhouse.v1.SimulatorService.AddPlant:output_type -> greenhouse.v1.AddPlantResponse
+	10, // 28: greenhouse.v1.SimulatorService.WaterSection:output_type -> greenhouse.v1.WaterSectionResponse
+	12, // 29: greenhouse.v1.SimulatorService.Pause:output_type -> greenhouse.v1.PauseResponse
+	14, // 30: greenhouse.v1.SimulatorService.Resume:output_type -> greenhouse.v1.ResumeResponse
+	16, // 31: greenhouse.v1.SimulatorService.GetReading:output_type -> greenhouse.v1.GetReadingResponse
+	19, // 32: greenhouse.v1.SimulatorService.WatchEvents:output_type -> greenhouse.v1.WatchEventsResponse
+	25, // [25:33] is the sub-list for method output_type
+	17, // [17:25] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
+}
+
+func init() { file_greenhouse_v1_simulator_proto_init() }
+func file_greenhouse_v1_simulator_proto_init() {
+	if File_greenhouse_v1_simulator_proto != nil {
+		return
+	}
+	file_greenhouse_v1_simulator_proto_msgTypes[3].OneofWrappers = []any{}
+	file_greenhouse_v1_simulator_proto_msgTypes[20].OneofWrappers = []any{
+		(*Event_Stats)(nil),
+		(*Event_Reading)(nil),
+		(*Event_Watering)(nil),
+	}
+	file_greenhouse_v1_simulator_proto_msgTypes[21].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_greenhouse_v1_simulator_proto_rawDesc), len(file_greenhouse_v1_simulator_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   23,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_greenhouse_v1_simulator_proto_goTypes,
+		DependencyIndexes: file_greenhouse_v1_simulator_proto_depIdxs,
+		MessageInfos:      file_greenhouse_v1_simulator_proto_msgTypes,
+	}.Build()
+	File_greenhouse_v1_simulator_proto = out.File
+	file_greenhouse_v1_simulator_proto_goTypes = nil
+	file_greenhouse_v1_simulator_proto_depIdxs = nil
+}
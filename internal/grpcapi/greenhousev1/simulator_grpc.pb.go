@@ -0,0 +1,419 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: greenhouse/v1/simulator.proto
+
+package greenhousev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SimulatorService_GetStatus_FullMethodName    = "/greenhouse.v1.SimulatorService/GetStatus"
+	SimulatorService_ListPlants_FullMethodName   = "/greenhouse.v1.SimulatorService/ListPlants"
+	SimulatorService_AddPlant_FullMethodName     = "/greenhouse.v1.SimulatorService/AddPlant"
+	SimulatorService_WaterSection_FullMethodName = "/greenhouse.v1.SimulatorService/WaterSection"
+	SimulatorService_Pause_FullMethodName        = "/greenhouse.v1.SimulatorService/Pause"
+	SimulatorService_Resume_FullMethodName       = "/greenhouse.v1.SimulatorService/Resume"
+	SimulatorService_GetReading_FullMethodName   = "/greenhouse.v1.SimulatorService/GetReading"
+	SimulatorService_WatchEvents_FullMethodName  = "/greenhouse.v1.SimulatorService/WatchEvents"
+)
+
+// SimulatorServiceClient is the client API for SimulatorService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SimulatorService controls and observes a running greenhouse simulation. It mirrors
+// the HTTP API: unknown IDs fail with NOT_FOUND, taken IDs with
+// ALREADY_EXISTS, pause state conflicts with FAILED_PRECONDITION and invalid
+// requests with INVALID_ARGUMENT.
+type SimulatorServiceClient interface {
+	// GetStatus reports the tick, pause state, plant counts and water use.
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error)
+	// ListPlants lists plants, ordered by ID.
+	ListPlants(ctx context.Context, in *ListPlantsRequest, opts ...grpc.CallOption) (*ListPlantsResponse, error)
+	// AddPlant adds a plant to the running simulation.
+	AddPlant(ctx context.Context, in *AddPlantRequest, opts ...grpc.CallOption) (*AddPlantResponse, error)
+	// WaterSection waters a section manually.
+	WaterSection(ctx context.Context, in *WaterSectionRequest, opts ...grpc.CallOption) (*WaterSectionResponse, error)
+	// Pause pauses the simulation.
+	Pause(ctx context.Context, in *PauseRequest, opts ...grpc.CallOption) (*PauseResponse, error)
+	// Resume resumes the simulation.
+	Resume(ctx context.Context, in *ResumeRequest, opts ...grpc.CallOption) (*ResumeResponse, error)
+	// GetReading reads a sensor.
+	GetReading(ctx context.Context, in *GetReadingRequest, opts ...grpc.CallOption) (*GetReadingResponse, error)
+	// WatchEvents streams simulation events until the client cancels. Clients
+	// that fall too far behind are cut off with RESOURCE_EXHAUSTED.
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchEventsResponse], error)
+}
+
+type simulatorServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSimulatorServiceClient(cc grpc.ClientConnInterface) SimulatorServiceClient {
+	return &simulatorServiceClient{cc}
+}
+
+func (c *simulatorServiceClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStatusResponse)
+	err := c.cc.Invoke(ctx, SimulatorService_GetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorServiceClient) ListPlants(ctx context.Context, in *ListPlantsRequest, opts ...grpc.CallOption) (*ListPlantsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPlantsResponse)
+	err := c.cc.Invoke(ctx, SimulatorService_ListPlants_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorServiceClient) AddPlant(ctx context.Context, in *AddPlantRequest, opts ...grpc.CallOption) (*AddPlantResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddPlantResponse)
+	err := c.cc.Invoke(ctx, SimulatorService_AddPlant_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorServiceClient) WaterSection(ctx context.Context, in *WaterSectionRequest, opts ...grpc.CallOption) (*WaterSectionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WaterSectionResponse)
+	err := c.cc.Invoke(ctx, SimulatorService_WaterSection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorServiceClient) Pause(ctx context.Context, in *PauseRequest, opts ...grpc.CallOption) (*PauseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PauseResponse)
+	err := c.cc.Invoke(ctx, SimulatorService_Pause_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorServiceClient) Resume(ctx context.Context, in *ResumeRequest, opts ...grpc.CallOption) (*ResumeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResumeResponse)
+	err := c.cc.Invoke(ctx, SimulatorService_Resume_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorServiceClient) GetReading(ctx context.Context, in *GetReadingRequest, opts ...grpc.CallOption) (*GetReadingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetReadingResponse)
+	err := c.cc.Invoke(ctx, SimulatorService_GetReading_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorServiceClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchEventsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SimulatorService_ServiceDesc.Streams[0], SimulatorService_WatchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchEventsRequest, WatchEventsResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SimulatorService_WatchEventsClient = grpc.ServerStreamingClient[WatchEventsResponse]
+
+// SimulatorServiceServer is the server API for SimulatorService service.
+// All implementations must embed UnimplementedSimulatorServiceServer
+// for forward compatibility.
+//
+// SimulatorService controls and observes a running greenhouse simulation. It mirrors
+// the HTTP API: unknown IDs fail with NOT_FOUND, taken IDs with
+// ALREADY_EXISTS, pause state conflicts with FAILED_PRECONDITION and invalid
+// requests with INVALID_ARGUMENT.
+type SimulatorServiceServer interface {
+	// GetStatus reports the tick, pause state, plant counts and water use.
+	GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error)
+	// ListPlants lists plants, ordered by ID.
+	ListPlants(context.Context, *ListPlantsRequest) (*ListPlantsResponse, error)
+	// AddPlant adds a plant to the running simulation.
+	AddPlant(context.Context, *AddPlantRequest) (*AddPlantResponse, error)
+	// WaterSection waters a section manually.
+	WaterSection(context.Context, *WaterSectionRequest) (*WaterSectionResponse, error)
+	// Pause pauses the simulation.
+	Pause(context.Context, *PauseRequest) (*PauseResponse, error)
+	// Resume resumes the simulation.
+	Resume(context.Context, *ResumeRequest) (*ResumeResponse, error)
+	// GetReading reads a sensor.
+	GetReading(context.Context, *GetReadingRequest) (*GetReadingResponse, error)
+	// WatchEvents streams simulation events until the client cancels. Clients
+	// that fall too far behind are cut off with RESOURCE_EXHAUSTED.
+	WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[WatchEventsResponse]) error
+	mustEmbedUnimplementedSimulatorServiceServer()
+}
+
+// UnimplementedSimulatorServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSimulatorServiceServer struct{}
+
+func (UnimplementedSimulatorServiceServer) GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedSimulatorServiceServer) ListPlants(context.Context, *ListPlantsRequest) (*ListPlantsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPlants not implemented")
+}
+func (UnimplementedSimulatorServiceServer) AddPlant(context.Context, *AddPlantRequest) (*AddPlantResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddPlant not implemented")
+}
+func (UnimplementedSimulatorServiceServer) WaterSection(context.Context, *WaterSectionRequest) (*WaterSectionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WaterSection not implemented")
+}
+func (UnimplementedSimulatorServiceServer) Pause(context.Context, *PauseRequest) (*PauseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Pause not implemented")
+}
+func (UnimplementedSimulatorServiceServer) Resume(context.Context, *ResumeRequest) (*ResumeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resume not implemented")
+}
+func (UnimplementedSimulatorServiceServer) GetReading(context.Context, *GetReadingRequest) (*GetReadingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReading not implemented")
+}
+func (UnimplementedSimulatorServiceServer) WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[WatchEventsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedSimulatorServiceServer) mustEmbedUnimplementedSimulatorServiceServer() {}
+func (UnimplementedSimulatorServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeSimulatorServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SimulatorServiceServer will
+// result in compilation errors.
+type UnsafeSimulatorServiceServer interface {
+	mustEmbedUnimplementedSimulatorServiceServer()
+}
+
+func RegisterSimulatorServiceServer(s grpc.ServiceRegistrar, srv SimulatorServiceServer) {
+	// If the following call pancis, it indicates UnimplementedSimulatorServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SimulatorService_ServiceDesc, srv)
+}
+
+func _SimulatorService_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServiceServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimulatorService_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServiceServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimulatorService_ListPlants_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPlantsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServiceServer).ListPlants(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimulatorService_ListPlants_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServiceServer).ListPlants(ctx, req.(*ListPlantsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimulatorService_AddPlant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddPlantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServiceServer).AddPlant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimulatorService_AddPlant_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServiceServer).AddPlant(ctx, req.(*AddPlantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimulatorService_WaterSection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WaterSectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServiceServer).WaterSection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimulatorService_WaterSection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServiceServer).WaterSection(ctx, req.(*WaterSectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimulatorService_Pause_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServiceServer).Pause(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimulatorService_Pause_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServiceServer).Pause(ctx, req.(*PauseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimulatorService_Resume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServiceServer).Resume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimulatorService_Resume_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServiceServer).Resume(ctx, req.(*ResumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimulatorService_GetReading_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReadingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServiceServer).GetReading(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimulatorService_GetReading_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServiceServer).GetReading(ctx, req.(*GetReadingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimulatorService_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SimulatorServiceServer).WatchEvents(m, &grpc.GenericServerStream[WatchEventsRequest, WatchEventsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SimulatorService_WatchEventsServer = grpc.ServerStreamingServer[WatchEventsResponse]
+
+// SimulatorService_ServiceDesc is the grpc.ServiceDesc for SimulatorService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SimulatorService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "greenhouse.v1.SimulatorService",
+	HandlerType: (*SimulatorServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetStatus",
+			Handler:    _SimulatorService_GetStatus_Handler,
+		},
+		{
+			MethodName: "ListPlants",
+			Handler:    _SimulatorService_ListPlants_Handler,
+		},
+		{
+			MethodName: "AddPlant",
+			Handler:    _SimulatorService_AddPlant_Handler,
+		},
+		{
+			MethodName: "WaterSection",
+			Handler:    _SimulatorService_WaterSection_Handler,
+		},
+		{
+			MethodName: "Pause",
+			Handler:    _SimulatorService_Pause_Handler,
+		},
+		{
+			MethodName: "Resume",
+			Handler:    _SimulatorService_Resume_Handler,
+		},
+		{
+			MethodName: "GetReading",
+			Handler:    _SimulatorService_GetReading_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _SimulatorService_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "greenhouse/v1/simulator.proto",
+}
@@ -0,0 +1,279 @@
+// Package grpcapi serves a running greenhouse over gRPC, with the service
+// defined in proto/greenhouse/v1/simulator.proto. It exposes the same
+// operations as package api, through the same service layer, and maps their
+// errors to gRPC status codes.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/greenhouse"
+	pb "greenhouse-simulator/internal/grpcapi/greenhousev1"
+	"greenhouse-simulator/internal/models"
+	"greenhouse-simulator/internal/sensors"
+	"greenhouse-simulator/internal/service"
+	"greenhouse-simulator/internal/watering"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// ShutdownTimeout bounds how long Serve waits for in-flight calls once it
+	// is asked to stop.
+	ShutdownTimeout = 5 * time.Second
+	// WatchBuffer is how many events a WatchEvents client may fall behind
+	// before its stream is ended.
+	WatchBuffer = 256
+)
+
+type server struct {
+	pb.UnimplementedSimulatorServiceServer
+	svc  service.Service
+	stop <-chan struct{}
+}
+
+// Serve serves the gRPC API of svc on listener until stop is closed, then
+// shuts down gracefully, waiting up to ShutdownTimeout for in-flight calls.
+// Open event streams are ended right away. It returns nil after a shutdown
+// and the serving error otherwise. opts configure the gRPC server, e.g. to
+// trace the calls. While svc is read-only, every call other than the queries
+// and WatchEvents fails with PERMISSION_DENIED, see
+// service.Service.SetReadOnly.
+func Serve(listener net.Listener, svc service.Service, stop <-chan struct{}, opts ...grpc.ServerOption) error {
+	// Calls are touched once handled, so that a resume call finds a
+	// simulation the idle watch paused still paused, see
+	// service.Service.Touch; opts and the read-only check come first so that
+	// rejected calls are not.
+	opts = append(opts, grpc.ChainUnaryInterceptor(
+		func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if svc.ReadOnly() && !readMethods[info.FullMethod] {
+				return nil, status.Error(codes.PermissionDenied, service.ErrReadOnly.Error())
+			}
+			return handler(ctx, req)
+		},
+		func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			defer svc.Touch()
+			return handler(ctx, req)
+		},
+	))
+	s := grpc.NewServer(opts...)
+	pb.RegisterSimulatorServiceServer(s, &server{svc: svc, stop: stop})
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(listener) }()
+
+	select {
+	case err := <-served:
+		return err
+	case <-stop:
+		stopped := make(chan struct{})
+		go func() {
+			s.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(ShutdownTimeout):
+			s.Stop()
+		}
+		return nil
+	}
+}
+
+func (s *server) GetStatus(ctx context.Context, req *pb.GetStatusRequest) (*pb.GetStatusResponse, error) {
+	return &pb.GetStatusResponse{Status: statusMessage(s.svc.Status())}, nil
+}
+
+func (s *server) ListPlants(ctx context.Context, req *pb.ListPlantsRequest) (*pb.ListPlantsResponse, error) {
+	resp := &pb.ListPlantsResponse{}
+	for _, plant := range s.svc.Plants() {
+		resp.Plants = append(resp.Plants, plantMessage(plant))
+	}
+	return resp, nil
+}
+
+func (s *server) AddPlant(ctx context.Context, req *pb.AddPlantRequest) (*pb.AddPlantResponse, error) {
+	plant, err := s.svc.AddPlant(config.PlantConfig{
+		ID:                req.GetId(),
+		Type:              req.GetType(),
+		SectionID:         req.GetSection(),
+		InitialSaturation: req.GetInitialSaturation(),
+		Tags:              req.GetTags(),
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.AddPlantResponse{Plant: plantMessage(plant)}, nil
+}
+
+func (s *server) WaterSection(ctx context.Context, req *pb.WaterSectionRequest) (*pb.WaterSectionResponse, error) {
+	if _, err := s.svc.Water(req.GetSection(), req.GetAmount(), req.GetDuration().AsDuration(), watering.ManualOptions{}); err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.WaterSectionResponse{}, nil
+}
+
+func (s *server) Pause(ctx context.Context, req *pb.PauseRequest) (*pb.PauseResponse, error) {
+	current, err := s.svc.Pause()
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.PauseResponse{Status: statusMessage(current)}, nil
+}
+
+func (s *server) Resume(ctx context.Context, req *pb.ResumeRequest) (*pb.ResumeResponse, error) {
+	current, err := s.svc.Resume()
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.ResumeResponse{Status: statusMessage(current)}, nil
+}
+
+func (s *server) GetReading(ctx context.Context, req *pb.GetReadingRequest) (*pb.GetReadingResponse, error) {
+	reading, err := s.svc.Reading(req.GetSensorId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.GetReadingResponse{Reading: readingMessage(reading)}, nil
+}
+
+// WatchEvents streams the events matching the request until the client
+// cancels or the server stops. Headers are sent once the subscription is in
+// place, so clients waiting for them miss no later event. A client that falls
+// WatchBuffer events behind is cut off with RESOURCE_EXHAUSTED.
+func (s *server) WatchEvents(req *pb.WatchEventsRequest, stream grpc.ServerStreamingServer[pb.WatchEventsResponse]) error {
+	filter := service.Filter{SectionID: req.GetSection()}
+	for _, t := range req.GetTypes() {
+		filter.Types = append(filter.Types, events.Type(t))
+	}
+	sub := s.svc.Watch(filter, WatchBuffer)
+	defer sub.Close()
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+
+	overflowed := status.Error(codes.ResourceExhausted, "event stream fell too far behind")
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.stop:
+			return nil
+		case <-sub.Overflowed():
+			return overflowed
+		case e := <-sub.Events():
+			select {
+			case <-sub.Overflowed():
+				return overflowed
+			default:
+			}
+			if err := stream.Send(&pb.WatchEventsResponse{Event: eventMessage(e)}); err != nil {
+				return err
+			}
+			sub.Delivered(e)
+		}
+	}
+}
+
+// statusError wraps err in a gRPC status with the code of its cause. Errors
+// that are not about a missing or conflicting resource are the caller's to
+// fix.
+func statusError(err error) error {
+	code := codes.InvalidArgument
+	switch {
+	case errors.Is(err, engine.ErrPlantNotFound),
+		errors.Is(err, sensors.ErrSensorNotFound),
+		errors.Is(err, sensors.ErrNoSensorsInSection),
+		errors.Is(err, sensors.ErrNoPlantsInSection),
+		errors.Is(err, watering.ErrNoPlantsInSection):
+		code = codes.NotFound
+	case errors.Is(err, engine.ErrPlantExists),
+		errors.Is(err, sensors.ErrSensorExists):
+		code = codes.AlreadyExists
+	case errors.Is(err, greenhouse.ErrAlreadyPaused),
+		errors.Is(err, greenhouse.ErrNotPaused),
+		errors.Is(err, engine.ErrNotStarted),
+		errors.Is(err, engine.ErrAlreadyStopped),
+		errors.Is(err, sensors.ErrSensorFailed):
+		code = codes.FailedPrecondition
+	}
+	return status.Error(code, err.Error())
+}
+
+func statusMessage(s service.Status) *pb.Status {
+	water := &pb.WaterStatus{Used: s.Water.Used, Wasted: s.Water.Wasted}
+	if !s.Water.Unlimited {
+		water.Remaining = &s.Water.Remaining
+	}
+	return &pb.Status{
+		Tick:         int64(s.Tick),
+		Paused:       s.Paused,
+		TickInterval: durationpb.New(s.TickInterval),
+		Plants:       int32(s.Plants),
+		AlivePlants:  int32(s.AlivePlants),
+		Sensors:      int32(s.Sensors),
+		Water:        water,
+	}
+}
+
+func plantMessage(p *models.Plant) *pb.Plant {
+	return &pb.Plant{
+		Id:             p.ID,
+		Type:           p.Type.Name,
+		Section:        p.SectionID,
+		SoilSaturation: p.SoilSaturation,
+		Health:         p.Health,
+		GrowthStage:    p.GrowthStage,
+		Alive:          p.Alive,
+		CreatedAt:      timestamppb.New(p.CreatedAt),
+		Tags:           p.Tags,
+	}
+}
+
+func readingMessage(r *models.SensorReading) *pb.Reading {
+	return &pb.Reading{SensorId: r.SensorID, Timestamp: timestamppb.New(r.Timestamp), Value: r.Value}
+}
+
+func eventMessage(e events.Event) *pb.Event {
+	event := &pb.Event{
+		Type:      string(e.Type),
+		Tick:      int64(e.Tick),
+		Timestamp: timestamppb.New(e.Timestamp),
+		Section:   e.SectionID,
+		PlantId:   e.PlantID,
+	}
+	switch payload := e.Payload.(type) {
+	case greenhouse.Stats:
+		event.Payload = &pb.Event_Stats{Stats: &pb.Stats{
+			Plants:            int32(payload.Plants),
+			AlivePlants:       int32(payload.AlivePlants),
+			AverageHealth:     payload.AverageHealth,
+			AverageSaturation: payload.AverageSaturation,
+			WaterUsed:         payload.WaterUsed,
+			WaterWasted:       payload.WaterWasted,
+			TankRemaining:     payload.TankRemaining,
+		}}
+	case models.SensorReading:
+		event.Payload = &pb.Event_Reading{Reading: readingMessage(&payload)}
+	case models.WateringEvent:
+		event.Payload = &pb.Event_Watering{Watering: &pb.WateringEvent{
+			Id:         payload.ID,
+			Section:    payload.SectionID,
+			PlantId:    payload.PlantID,
+			Amount:     payload.Amount,
+			Duration:   durationpb.New(payload.Duration),
+			Manual:     payload.IsManual,
+			Method:     string(payload.Method),
+			ScheduleId: payload.ScheduleID,
+		}}
+	}
+	return event
+}
@@ -0,0 +1,248 @@
+package grpcapi
+
+import (
+	"context"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/greenhouse"
+	pb "greenhouse-simulator/internal/grpcapi/greenhousev1"
+	"greenhouse-simulator/internal/service"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// newTestClient serves the demo greenhouse over an in-process connection,
+// with a tick interval long enough that no tick runs during a test. opts
+// configure the gRPC server.
+func newTestClient(t *testing.T, opts ...grpc.ServerOption) (pb.SimulatorServiceClient, greenhouse.Greenhouse) {
+	t.Helper()
+	cfg := config.Default()
+	cfg.TickInterval = config.Duration(time.Hour)
+	g, err := greenhouse.New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	return serveTestClient(t, service.New(g), opts...), g
+}
+
+// serveTestClient serves svc over an in-process connection until the test
+// ends.
+func serveTestClient(t *testing.T, svc service.Service, opts ...grpc.ServerOption) pb.SimulatorServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	stop := make(chan struct{})
+	served := make(chan error, 1)
+	go func() { served <- Serve(listener, svc, stop, opts...) }()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		close(stop)
+		if err := <-served; err != nil {
+			t.Errorf("unexpected serving error: %v", err)
+		}
+	})
+	return pb.NewSimulatorServiceClient(conn)
+}
+
+func TestServer_ErrorCodes(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		call     func() error
+		expected codes.Code
+	}{
+		{"add plant", func() error {
+			_, err := client.AddPlant(ctx, &pb.AddPlantRequest{Id: "basil-1", Type: "Basil", Section: "section-C", InitialSaturation: 0.5})
+			return err
+		}, codes.OK},
+		{"add duplicate plant", func() error {
+			_, err := client.AddPlant(ctx, &pb.AddPlantRequest{Id: "tomato-1", Type: "Tomato", Section: "section-A", InitialSaturation: 0.5})
+			return err
+		}, codes.AlreadyExists},
+		{"add plant of unknown type", func() error {
+			_, err := client.AddPlant(ctx, &pb.AddPlantRequest{Id: "fern-1", Type: "Fern", Section: "section-A", InitialSaturation: 0.5})
+			return err
+		}, codes.InvalidArgument},
+		{"read sensor", func() error {
+			_, err := client.GetReading(ctx, &pb.GetReadingRequest{SensorId: "sensor-1"})
+			return err
+		}, codes.OK},
+		{"read unknown sensor", func() error {
+			_, err := client.GetReading(ctx, &pb.GetReadingRequest{SensorId: "sensor-9"})
+			return err
+		}, codes.NotFound},
+		{"water section", func() error {
+			_, err := client.WaterSection(ctx, &pb.WaterSectionRequest{Section: "section-A", Amount: 0.2, Duration: durationpb.New(time.Minute)})
+			return err
+		}, codes.OK},
+		{"water unknown section", func() error {
+			_, err := client.WaterSection(ctx, &pb.WaterSectionRequest{Section: "section-Z", Amount: 0.2})
+			return err
+		}, codes.NotFound},
+		{"water without amount", func() error {
+			_, err := client.WaterSection(ctx, &pb.WaterSectionRequest{Section: "section-A"})
+			return err
+		}, codes.InvalidArgument},
+		{"resume without pause", func() error {
+			_, err := client.Resume(ctx, &pb.ResumeRequest{})
+			return err
+		}, codes.FailedPrecondition},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := status.Code(tt.call()); code != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, code)
+			}
+		})
+	}
+}
+
+func TestServer_PlantsAndStatus(t *testing.T) {
+	client, g := newTestClient(t)
+	ctx := context.Background()
+	sim := g.Simulator()
+	go sim.Start()
+	defer sim.Stop()
+	for sim.State() != engine.Running {
+		time.Sleep(time.Millisecond)
+	}
+
+	plants, err := client.ListPlants(ctx, &pb.ListPlantsRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plants.Plants) != 3 || plants.Plants[0].Id != "lettuce-1" || plants.Plants[1].Section != "section-A" {
+		t.Errorf("expected the demo plants ordered by ID, got %v", plants.Plants)
+	}
+
+	paused, err := client.Pause(ctx, &pb.PauseRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !paused.Status.Paused || paused.Status.Plants != 3 || paused.Status.TickInterval.AsDuration() != time.Hour {
+		t.Errorf("expected a paused status with 3 plants, got %v", paused.Status)
+	}
+	if _, err := client.Pause(ctx, &pb.PauseRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected pausing twice to fail, got %v", err)
+	}
+	if _, err := client.Resume(ctx, &pb.ResumeRequest{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	current, err := client.GetStatus(ctx, &pb.GetStatusRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if current.Status.Paused || current.Status.Water.Remaining == nil {
+		t.Errorf("expected a running simulation reporting its tank, got %v", current.Status)
+	}
+}
+
+func TestServer_WatchEvents(t *testing.T) {
+	client, g := newTestClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.WatchEvents(ctx, &pb.WatchEventsRequest{Types: []string{string(events.SensorSample), string(events.Tick)}, Section: "section-B"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Headers arrive once the server subscribed.
+	if _, err := stream.Header(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g.Simulator().Step()
+
+	first, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.Event.Type != string(events.SensorSample) || first.Event.GetReading().GetSensorId() != "sensor-1" {
+		t.Errorf("expected the sensor-1 sample first, got %v", first.Event)
+	}
+	second, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if second.Event.Type != string(events.Tick) || second.Event.GetStats().GetPlants() != 3 {
+		t.Errorf("expected the tick with stats next, got %v", second.Event)
+	}
+}
+
+func TestServer_ReadOnly(t *testing.T) {
+	cfg := config.Default()
+	cfg.TickInterval = config.Duration(time.Hour)
+	g, err := greenhouse.New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	svc := service.New(g)
+	svc.SetReadOnly(true)
+	client := serveTestClient(t, svc)
+	ctx := context.Background()
+
+	mutating := map[string]func() error{
+		"AddPlant": func() error {
+			_, err := client.AddPlant(ctx, &pb.AddPlantRequest{Id: "basil-1", Type: "Basil", Section: "section-C", InitialSaturation: 0.5})
+			return err
+		},
+		"WaterSection": func() error {
+			_, err := client.WaterSection(ctx, &pb.WaterSectionRequest{Section: "section-A", Amount: 0.2})
+			return err
+		},
+		"Pause": func() error {
+			_, err := client.Pause(ctx, &pb.PauseRequest{})
+			return err
+		},
+		"Resume": func() error {
+			_, err := client.Resume(ctx, &pb.ResumeRequest{})
+			return err
+		},
+	}
+	for name, call := range mutating {
+		if err := call(); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("%s: expected PermissionDenied, got %v", name, err)
+		}
+	}
+	if _, err := client.GetStatus(ctx, &pb.GetStatusRequest{}); err != nil {
+		t.Errorf("GetStatus: unexpected error: %v", err)
+	}
+	plants, err := client.ListPlants(ctx, &pb.ListPlantsRequest{})
+	if err != nil || len(plants.Plants) != 3 {
+		t.Errorf("ListPlants: expected the 3 plants left alone, got %v, %v", plants, err)
+	}
+	if _, err := client.GetReading(ctx, &pb.GetReadingRequest{SensorId: "sensor-1"}); err != nil {
+		t.Errorf("GetReading: unexpected error: %v", err)
+	}
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := client.WatchEvents(streamCtx, &pb.WatchEventsRequest{})
+	if err == nil {
+		_, err = stream.Header()
+	}
+	if err != nil {
+		t.Errorf("WatchEvents: unexpected error: %v", err)
+	}
+
+	svc.SetReadOnly(false)
+	for name, call := range mutating {
+		if err := call(); status.Code(err) == codes.PermissionDenied {
+			t.Errorf("%s: expected the call to be let through, got %v", name, err)
+		}
+	}
+}
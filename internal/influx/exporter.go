@@ -0,0 +1,228 @@
+package influx
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/greenhouse"
+	"greenhouse-simulator/internal/models"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Exporter writes the sensor readings of a greenhouse to InfluxDB. Readings
+// are buffered as the exporter handles them, once registered with the
+// exporters of the greenhouse, and written by Run.
+type Exporter interface {
+	greenhouse.Exporter
+	// Run exports until stop is closed.
+	Run(stop <-chan struct{})
+	// Dropped returns how many points were dropped, because the buffer
+	// overflowed or InfluxDB rejected them.
+	Dropped() int
+}
+
+type exporter struct {
+	g      greenhouse.Greenhouse
+	writer Writer
+	cfg    config.InfluxConfig
+	logger *slog.Logger
+	wake   chan struct{}
+
+	// Only touched by the calls of the export registry, one at a time.
+	types   map[string]models.SensorType
+	simTime time.Duration
+
+	buffer  [][]byte
+	dropped int
+	mu      sync.Mutex
+}
+
+// NewExporter returns an exporter writing the readings of g through writer,
+// configured by cfg.
+func NewExporter(g greenhouse.Greenhouse, writer Writer, cfg config.InfluxConfig, logger *slog.Logger) Exporter {
+	cfg = cfg.WithDefaults()
+	if cfg.SimStart.IsZero() {
+		cfg.SimStart = time.Now()
+	}
+	return &exporter{
+		g:      g,
+		writer: writer,
+		cfg:    cfg,
+		logger: logger,
+		wake:   make(chan struct{}, 1),
+		types:  map[string]models.SensorType{},
+	}
+}
+
+// Run writes the buffered readings in batches of BatchSize points, and
+// whatever is buffered every FlushInterval, until stop is closed. A failed
+// write is retried with a delay doubling from RetryMin up to RetryMax, while
+// new readings keep being buffered; once BufferSize points wait, the oldest
+// or the newest are dropped, as the Drop policy says. Batches InfluxDB
+// rejects are dropped. Readings buffered when stop is closed get one last
+// write attempt before Run returns.
+func (x *exporter) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Duration(x.cfg.FlushInterval))
+	defer ticker.Stop()
+	delay := time.Duration(x.cfg.RetryMin)
+	retrying := false
+	for {
+		all := retrying
+		if !retrying {
+			select {
+			case <-stop:
+				x.stop()
+				return
+			case <-x.wake:
+			case <-ticker.C:
+				all = true
+			}
+		}
+		if err := x.flush(all); err != nil {
+			x.logger.Warn("influx write failed", "error", err, "retry_in", delay)
+			select {
+			case <-stop:
+				x.stop()
+				return
+			case <-time.After(delay):
+			}
+			delay = min(2*delay, time.Duration(x.cfg.RetryMax))
+			retrying = true
+			continue
+		}
+		delay = time.Duration(x.cfg.RetryMin)
+		retrying = false
+	}
+}
+
+// Dropped returns how many points were dropped, because the buffer
+// overflowed or InfluxDB rejected them.
+// This method is safe for concurrent use.
+func (x *exporter) Dropped() int {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return x.dropped
+}
+
+// stop makes a last attempt at writing the buffer.
+func (x *exporter) stop() {
+	if err := x.flush(true); err != nil {
+		x.mu.Lock()
+		unsent := len(x.buffer)
+		x.mu.Unlock()
+		x.logger.Warn("influx export stopped with unsent readings", "error", err, "readings", unsent)
+	}
+}
+
+// flush writes full batches, and with all the last partial one too. A batch
+// that fails to write goes back to the front of the buffer.
+func (x *exporter) flush(all bool) error {
+	for {
+		x.mu.Lock()
+		n := min(len(x.buffer), x.cfg.BatchSize)
+		if n == 0 || (n < x.cfg.BatchSize && !all) {
+			x.mu.Unlock()
+			return nil
+		}
+		batch := x.buffer[:n:n]
+		x.buffer = x.buffer[n:]
+		x.mu.Unlock()
+
+		err := x.writer.Write(slices.Concat(batch...))
+		switch {
+		case errors.Is(err, ErrRejected):
+			x.logger.Warn("influx rejected readings, dropping them", "error", err, "readings", n)
+			x.mu.Lock()
+			x.dropped += n
+			x.mu.Unlock()
+		case err != nil:
+			x.mu.Lock()
+			x.buffer = append(batch, x.buffer...)
+			x.trim()
+			x.mu.Unlock()
+			return err
+		}
+	}
+}
+
+// HandleReading turns a reading into a point and buffers it.
+func (x *exporter) HandleReading(r greenhouse.ExportedReading) error {
+	timestamp := r.Reading.Timestamp
+	if x.cfg.Timestamps == "sim" {
+		timestamp = x.cfg.SimStart.Add(x.simTime)
+	}
+	line, ok := Point{
+		SensorID:  r.Reading.SensorID,
+		SectionID: r.SectionID,
+		Type:      x.sensorType(r.Reading.SensorID),
+		Value:     r.Reading.Value,
+		Battery:   r.Reading.Battery,
+		Timestamp: timestamp,
+	}.AppendLine(nil)
+	if ok {
+		x.enqueue(line)
+	}
+	return nil
+}
+
+// HandleTick keeps the simulated clock.
+func (x *exporter) HandleTick(greenhouse.ExportedTick) error {
+	x.simTime += x.g.Simulator().GetTickInterval()
+	return nil
+}
+
+// HandleEvent does nothing: only readings are exported.
+func (x *exporter) HandleEvent(events.Event) error { return nil }
+
+// Flush does nothing: Run writes on its own schedule.
+func (x *exporter) Flush() error { return nil }
+
+// Close does nothing: Run makes a last write attempt once stopped.
+func (x *exporter) Close() error { return nil }
+
+// sensorType looks a sensor's type up, refreshing the known types when the
+// sensor is new. Removed sensors have no type.
+func (x *exporter) sensorType(sensorID string) models.SensorType {
+	if t, ok := x.types[sensorID]; ok {
+		return t
+	}
+	for _, sensor := range x.g.Sensors().ListSensors() {
+		x.types[sensor.ID] = sensor.Type
+	}
+	return x.types[sensorID]
+}
+
+func (x *exporter) enqueue(line []byte) {
+	x.mu.Lock()
+	x.buffer = append(x.buffer, line)
+	x.trim()
+	full := len(x.buffer) >= x.cfg.BatchSize
+	x.mu.Unlock()
+	if full {
+		select {
+		case x.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// trim drops points beyond BufferSize as the Drop policy says. It must be
+// called with mu held.
+func (x *exporter) trim() {
+	excess := len(x.buffer) - x.cfg.BufferSize
+	if excess <= 0 {
+		return
+	}
+	if x.dropped == 0 {
+		x.logger.Warn("influx buffer full, dropping readings", "size", x.cfg.BufferSize, "drop", x.cfg.Drop)
+	}
+	if x.cfg.Drop == "newest" {
+		x.buffer = x.buffer[:x.cfg.BufferSize]
+	} else {
+		x.buffer = x.buffer[excess:]
+	}
+	x.dropped += excess
+}
@@ -0,0 +1,234 @@
+package influx
+
+import (
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/greenhouse"
+	"greenhouse-simulator/internal/models"
+	"io"
+	"log/slog"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeWriter records the batches written and fails the first failures writes
+// with err.
+type fakeWriter struct {
+	failures int
+	err      error
+	batches  []string
+	attempts int
+	mu       sync.Mutex
+}
+
+func (f *fakeWriter) Write(lines []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.attempts++
+	if f.failures > 0 {
+		f.failures--
+		return f.err
+	}
+	f.batches = append(f.batches, string(lines))
+	return nil
+}
+
+func (f *fakeWriter) written() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.batches...)
+}
+
+// eventually polls until condition holds, failing the test after a second.
+func eventually(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// newTestGreenhouse builds the demo greenhouse, ticking every second, with a
+// temperature sensor added next to its soil moisture sensor-1.
+func newTestGreenhouse(t *testing.T) greenhouse.Greenhouse {
+	t.Helper()
+	cfg := config.Default()
+	cfg.TickInterval = config.Duration(time.Second)
+	g, err := greenhouse.New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	if err := g.Sensors().AddSensor(&models.Sensor{ID: "temp-1", Type: models.Temperature, SectionID: "north bed"}); err != nil {
+		t.Fatalf("failed to add sensor: %v", err)
+	}
+	return g
+}
+
+// attach registers exporter with the exporters of g.
+func attach(t *testing.T, g greenhouse.Greenhouse, exporter Exporter) Exporter {
+	t.Helper()
+	if err := g.Exporters().Register("influx", exporter, 0); err != nil {
+		t.Fatalf("failed to register exporter: %v", err)
+	}
+	return exporter
+}
+
+// publish publishes an event and waits for the exporters to handle it.
+func publish(g greenhouse.Greenhouse, e events.Event) {
+	g.Bus().Publish(e)
+	g.Exporters().Drain()
+}
+
+// sample publishes a reading the way the greenhouse monitor does.
+func sample(g greenhouse.Greenhouse, sensorID, sectionID string, value float64, at time.Time) {
+	publish(g, events.Event{
+		Type:      events.SensorSample,
+		SectionID: sectionID,
+		Payload:   models.SensorReading{SensorID: sensorID, Timestamp: at, Value: value},
+	})
+}
+
+// run runs exporter until the returned function is called, which closes the
+// exporters of g first.
+func run(g greenhouse.Greenhouse, exporter Exporter) (stop func()) {
+	stopping := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		exporter.Run(stopping)
+		close(done)
+	}()
+	return func() {
+		g.Exporters().Close(time.Second)
+		close(stopping)
+		<-done
+	}
+}
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestExporter_WritesLineProtocol(t *testing.T) {
+	wall := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	simStart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		timestamps string
+		expected   []string
+	}{
+		{
+			name:       "wall clock",
+			timestamps: "wall",
+			expected: []string{
+				"sensor_reading,section=section-B,sensor_id=sensor-1,type=soil_moisture value=0.5 1748779200000000000\n" +
+					"sensor_reading,section=north\\ bed,sensor_id=temp-1,type=temperature value=21.5 1748779200000000000\n",
+				"sensor_reading,section=section-B,sensor_id=sensor-1,type=soil_moisture value=0.48 1748779205000000000\n",
+			},
+		},
+		{
+			name:       "simulated clock",
+			timestamps: "sim",
+			expected: []string{
+				"sensor_reading,section=section-B,sensor_id=sensor-1,type=soil_moisture value=0.5 1704067200000000000\n" +
+					"sensor_reading,section=north\\ bed,sensor_id=temp-1,type=temperature value=21.5 1704067200000000000\n",
+				"sensor_reading,section=section-B,sensor_id=sensor-1,type=soil_moisture value=0.48 1704067201000000000\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGreenhouse(t)
+			writer := &fakeWriter{}
+			cfg := config.InfluxConfig{Timestamps: tt.timestamps, SimStart: simStart, BatchSize: 2, FlushInterval: config.Duration(time.Hour)}
+			stop := run(g, attach(t, g, NewExporter(g, writer, cfg, discard)))
+
+			sample(g, "sensor-1", "section-B", 0.5, wall)
+			sample(g, "temp-1", "north bed", 21.5, wall)
+			publish(g, events.Event{Type: events.Tick, Payload: greenhouse.Stats{}})
+			// A full batch is written right away, the rest on stop.
+			eventually(t, "the first batch", func() bool { return len(writer.written()) == 1 })
+			sample(g, "sensor-1", "section-B", 0.48, wall.Add(5*time.Second))
+			stop()
+
+			if batches := writer.written(); !reflect.DeepEqual(batches, tt.expected) {
+				t.Errorf("expected batches\n%q\ngot\n%q", tt.expected, batches)
+			}
+		})
+	}
+}
+
+func TestExporter_RetriesFailedWrites(t *testing.T) {
+	g := newTestGreenhouse(t)
+	writer := &fakeWriter{failures: 3, err: errors.New("connection refused")}
+	exporter := attach(t, g, NewExporter(g, writer, config.InfluxConfig{BatchSize: 1, RetryMin: config.Duration(time.Millisecond)}, discard))
+	stop := run(g, exporter)
+	defer stop()
+
+	at := time.Unix(0, 0)
+	sample(g, "sensor-1", "section-B", 0.5, at)
+	sample(g, "sensor-1", "section-B", 0.6, at)
+	eventually(t, "both readings to be written", func() bool { return len(writer.written()) == 2 })
+
+	expected := []string{
+		"sensor_reading,section=section-B,sensor_id=sensor-1,type=soil_moisture value=0.5 0\n",
+		"sensor_reading,section=section-B,sensor_id=sensor-1,type=soil_moisture value=0.6 0\n",
+	}
+	if batches := writer.written(); !reflect.DeepEqual(batches, expected) {
+		t.Errorf("expected the readings once each and in order, got %q", batches)
+	}
+	writer.mu.Lock()
+	attempts := writer.attempts
+	writer.mu.Unlock()
+	if attempts != 5 || exporter.Dropped() != 0 {
+		t.Errorf("expected 5 attempts and nothing dropped, got %d attempts and %d dropped", attempts, exporter.Dropped())
+	}
+}
+
+func TestExporter_DropPolicy(t *testing.T) {
+	tests := []struct {
+		drop     string
+		expected string
+	}{
+		{"oldest", "sensor_reading value=2 0\nsensor_reading value=3 0\n"},
+		{"newest", "sensor_reading value=1 0\nsensor_reading value=2 0\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.drop, func(t *testing.T) {
+			g := newTestGreenhouse(t)
+			writer := &fakeWriter{}
+			exporter := attach(t, g, NewExporter(g, writer, config.InfluxConfig{BatchSize: 2, BufferSize: 2, Drop: tt.drop}, discard))
+			// Nothing is written before Run, so the buffer overflows.
+			for i := 1; i <= 3; i++ {
+				sample(g, "", "", float64(i), time.Unix(0, 0))
+			}
+			run(g, exporter)()
+
+			if batches := writer.written(); len(batches) != 1 || batches[0] != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, batches)
+			}
+			if exporter.Dropped() != 1 {
+				t.Errorf("expected 1 dropped reading, got %d", exporter.Dropped())
+			}
+		})
+	}
+}
+
+func TestExporter_DropsRejectedBatches(t *testing.T) {
+	g := newTestGreenhouse(t)
+	writer := &fakeWriter{failures: 1, err: fmt.Errorf("%w: 400 Bad Request", ErrRejected)}
+	exporter := attach(t, g, NewExporter(g, writer, config.InfluxConfig{BatchSize: 1, RetryMin: config.Duration(time.Hour)}, discard))
+	stop := run(g, exporter)
+	defer stop()
+
+	sample(g, "sensor-1", "section-B", 0.5, time.Unix(0, 0))
+	sample(g, "sensor-1", "section-B", 0.6, time.Unix(0, 0))
+	eventually(t, "the second reading to be written", func() bool { return len(writer.written()) == 1 })
+	if exporter.Dropped() != 1 {
+		t.Errorf("expected the rejected reading to be dropped, got %d dropped", exporter.Dropped())
+	}
+}
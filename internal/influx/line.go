@@ -0,0 +1,60 @@
+// Package influx exports the sensor readings of a running greenhouse to
+// InfluxDB, written as line protocol to its HTTP write endpoint.
+package influx
+
+import (
+	"greenhouse-simulator/internal/models"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Measurement is the measurement every reading is written to.
+const Measurement = "sensor_reading"
+
+// Point is one sensor reading as exported to InfluxDB. Battery is the
+// battery level of a wireless sensor, nil for a wired one.
+type Point struct {
+	SensorID  string
+	SectionID string
+	Type      models.SensorType
+	Value     float64
+	Battery   *float64
+	Timestamp time.Time
+}
+
+var tagEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+
+// AppendLine appends the point to b as a line of line protocol, with the
+// tags section, sensor_id and type, the field value, the field battery for a
+// wireless sensor and a timestamp in nanoseconds:
+//
+//	sensor_reading,section=section-B,sensor_id=sensor-1,type=soil_moisture value=0.42,battery=0.8 1700000000000000000
+//
+// Empty tags are left out. Points whose value is NaN or infinite cannot be
+// written and are not appended; AppendLine reports whether the point was.
+func (p Point) AppendLine(b []byte) ([]byte, bool) {
+	if math.IsNaN(p.Value) || math.IsInf(p.Value, 0) {
+		return b, false
+	}
+	b = append(b, Measurement...)
+	for _, tag := range [][2]string{{"section", p.SectionID}, {"sensor_id", p.SensorID}, {"type", string(p.Type)}} {
+		if tag[1] == "" {
+			continue
+		}
+		b = append(b, ',')
+		b = append(b, tag[0]...)
+		b = append(b, '=')
+		b = append(b, tagEscaper.Replace(tag[1])...)
+	}
+	b = append(b, " value="...)
+	b = strconv.AppendFloat(b, p.Value, 'f', -1, 64)
+	if p.Battery != nil {
+		b = append(b, ",battery="...)
+		b = strconv.AppendFloat(b, *p.Battery, 'f', -1, 64)
+	}
+	b = append(b, ' ')
+	b = strconv.AppendInt(b, p.Timestamp.UnixNano(), 10)
+	return append(b, '\n'), true
+}
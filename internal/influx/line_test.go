@@ -0,0 +1,52 @@
+package influx
+
+import (
+	"greenhouse-simulator/internal/models"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPoint_AppendLine(t *testing.T) {
+	at := time.Unix(1700000000, 5)
+	battery := 0.8
+	tests := []struct {
+		name     string
+		point    Point
+		expected string
+	}{
+		{
+			name:     "all tags",
+			point:    Point{SensorID: "sensor-1", SectionID: "section-B", Type: models.SoilMoisture, Value: 0.42, Timestamp: at},
+			expected: "sensor_reading,section=section-B,sensor_id=sensor-1,type=soil_moisture value=0.42 1700000000000000005\n",
+		},
+		{
+			name:     "escaped tag values",
+			point:    Point{SensorID: "a=b", SectionID: "north, bed 1", Value: 21, Timestamp: at},
+			expected: `sensor_reading,section=north\,\ bed\ 1,sensor_id=a\=b value=21 1700000000000000005` + "\n",
+		},
+		{
+			name:     "no exponent",
+			point:    Point{SensorID: "light-1", Value: 1.5e7, Timestamp: at},
+			expected: "sensor_reading,sensor_id=light-1 value=15000000 1700000000000000005\n",
+		},
+		{
+			name:     "battery",
+			point:    Point{SensorID: "sensor-1", Value: 21.5, Battery: &battery, Timestamp: at},
+			expected: "sensor_reading,sensor_id=sensor-1 value=21.5,battery=0.8 1700000000000000005\n",
+		},
+		{name: "NaN", point: Point{SensorID: "sensor-1", Value: math.NaN(), Timestamp: at}},
+		{name: "infinite", point: Point{SensorID: "sensor-1", Value: math.Inf(1), Timestamp: at}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, ok := tt.point.AppendLine([]byte("prefix\n"))
+			if ok != (tt.expected != "") {
+				t.Fatalf("expected appended to be %v, got %v", tt.expected != "", ok)
+			}
+			if string(line) != "prefix\n"+tt.expected {
+				t.Errorf("expected %q, got %q", "prefix\n"+tt.expected, line)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package influx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrRejected is wrapped by write errors that retrying cannot fix, such as a
+// malformed batch or a missing bucket.
+var ErrRejected = errors.New("influx rejected the batch")
+
+// Writer writes batches of line protocol.
+type Writer interface {
+	// Write writes lines, one point per line. Returns an error wrapping
+	// ErrRejected if the batch will never be accepted.
+	Write(lines []byte) error
+}
+
+type httpWriter struct {
+	endpoint string
+	token    string
+	client   *http.Client
+}
+
+// NewHTTPWriter returns a writer posting to the /api/v2/write endpoint of the
+// InfluxDB at cfg.URL, into cfg.Bucket of cfg.Org, authenticated with
+// cfg.Token when set.
+func NewHTTPWriter(cfg config.InfluxConfig) Writer {
+	query := url.Values{"bucket": {cfg.Bucket}, "precision": {"ns"}}
+	if cfg.Org != "" {
+		query.Set("org", cfg.Org)
+	}
+	return &httpWriter{
+		endpoint: strings.TrimSuffix(cfg.URL, "/") + "/api/v2/write?" + query.Encode(),
+		token:    cfg.Token,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Write posts lines. Returns an error if the request fails or InfluxDB
+// answers with an error status; client errors other than 429 Too Many
+// Requests wrap ErrRejected.
+func (w *httpWriter) Write(lines []byte) error {
+	req, err := http.NewRequest(http.MethodPost, w.endpoint, bytes.NewReader(lines))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if w.token != "" {
+		req.Header.Set("Authorization", "Token "+w.token)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode < 300 {
+		return nil
+	}
+	message := strings.TrimSpace(resp.Status + " " + string(body))
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %s", ErrRejected, message)
+	}
+	return errors.New("influx write failed: " + message)
+}
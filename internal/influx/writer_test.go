@@ -0,0 +1,51 @@
+package influx
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/config"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPWriter_Write(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		rejected bool
+		failed   bool
+	}{
+		{name: "accepted", status: http.StatusNoContent},
+		{name: "malformed", status: http.StatusBadRequest, rejected: true, failed: true},
+		{name: "rate limited", status: http.StatusTooManyRequests, failed: true},
+		{name: "unavailable", status: http.StatusServiceUnavailable, failed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request *http.Request
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				request = r
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			writer := NewHTTPWriter(config.InfluxConfig{URL: server.URL + "/", Org: "farm", Bucket: "greenhouse", Token: "secret"})
+			err := writer.Write([]byte("sensor_reading value=1 0\n"))
+			if (err != nil) != tt.failed || errors.Is(err, ErrRejected) != tt.rejected {
+				t.Errorf("expected failed %v and rejected %v, got %v", tt.failed, tt.rejected, err)
+			}
+			if request.URL.String() != "/api/v2/write?bucket=greenhouse&org=farm&precision=ns" {
+				t.Errorf("unexpected request URL %s", request.URL)
+			}
+			if request.Header.Get("Authorization") != "Token secret" {
+				t.Errorf("expected the token, got %q", request.Header.Get("Authorization"))
+			}
+			if string(body) != "sensor_reading value=1 0\n" {
+				t.Errorf("expected the lines as the body, got %q", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+)
+
+// LightCompetition configures how the plants of a section compete for light:
+// taller plants, those grown further, shade the smaller ones. A plant loses
+// Coefficient of its light for every unit of growth stage of the living
+// plants of its section taller than it, keeping at least Floor of it, and
+// grows that much slower, see Plant.Shade. A zero Coefficient shades no
+// plant.
+type LightCompetition struct {
+	Coefficient float64
+	Floor       float64
+}
+
+// Validate checks that the coefficient is not negative and that the floor is
+// between 0.0 and 1.0.
+func (c LightCompetition) Validate() error {
+	if c.Coefficient < 0 {
+		return errors.New("light competition coefficient cannot be negative")
+	}
+	if c.Floor < 0 || c.Floor > 1 {
+		return errors.New("light competition floor must be between 0.0 and 1.0")
+	}
+	return nil
+}
+
+// ShadeSection sets the Shade of the plants of a section. The plants are
+// ordered by growth stage once, tallest first, so that the growth above each
+// plant is a running sum rather than every plant going over the others.
+// Plants as tall as each other do not shade each other, and dead plants and
+// germinating seeds cast no shade.
+func (c LightCompetition) ShadeSection(plants []*Plant) {
+	byHeight := slices.SortedFunc(slices.Values(plants), func(a, b *Plant) int {
+		return cmp.Compare(b.GrowthStage, a.GrowthStage)
+	})
+	above := 0.0
+	for i := 0; i < len(byHeight); {
+		stage, height := byHeight[i].GrowthStage, 0.0
+		for ; i < len(byHeight) && byHeight[i].GrowthStage == stage; i++ {
+			byHeight[i].Shade = min(c.Coefficient*above, 1-c.Floor)
+			if byHeight[i].Alive && byHeight[i].Germination == nil {
+				height += stage
+			}
+		}
+		above += height
+	}
+}
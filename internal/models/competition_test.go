@@ -0,0 +1,55 @@
+package models
+
+import "testing"
+
+func TestLightCompetition_ShadeSection(t *testing.T) {
+	plant := func(id string, stage float64) *Plant {
+		return &Plant{ID: id, GrowthStage: stage, Alive: true}
+	}
+	tests := []struct {
+		name        string
+		competition LightCompetition
+		plants      []*Plant
+		expected    map[string]float64
+	}{
+		{"plant alone", LightCompetition{Coefficient: 0.5}, []*Plant{plant("a", 0.2)}, map[string]float64{"a": 0}},
+		{"taller plants shade smaller ones", LightCompetition{Coefficient: 0.5}, []*Plant{plant("seedling", 0.1), plant("tall", 0.8), plant("mid", 0.4)},
+			map[string]float64{"tall": 0, "mid": 0.4, "seedling": 0.6}},
+		{"plants as tall do not shade each other", LightCompetition{Coefficient: 0.5}, []*Plant{plant("a", 0.5), plant("b", 0.5), plant("c", 0.2)},
+			map[string]float64{"a": 0, "b": 0, "c": 0.5}},
+		{"floor", LightCompetition{Coefficient: 1, Floor: 0.25}, []*Plant{plant("a", 1), plant("b", 1), plant("c", 0)},
+			map[string]float64{"a": 0, "b": 0, "c": 0.75}},
+		{"dead plants cast no shade", LightCompetition{Coefficient: 0.5}, []*Plant{{ID: "dead", GrowthStage: 1}, plant("a", 0.2)},
+			map[string]float64{"dead": 0, "a": 0}},
+		{"no competition", LightCompetition{}, []*Plant{plant("a", 1), plant("b", 0)}, map[string]float64{"a": 0, "b": 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.competition.ShadeSection(tt.plants)
+			for _, p := range tt.plants {
+				if !almostEqual(p.Shade, tt.expected[p.ID]) {
+					t.Errorf("expected %s shaded by %.2f, got %.2f", p.ID, tt.expected[p.ID], p.Shade)
+				}
+			}
+		})
+	}
+}
+
+func TestPlant_OnTick_Shade(t *testing.T) {
+	newPlant := func(shade float64) *Plant {
+		return &Plant{
+			Type:           &PlantType{OptimalSaturation: 0.9, MinSaturation: 0.2, MaxSaturation: 0.9, BaseGrowthRate: 0.1},
+			Health:         1,
+			SoilSaturation: 0.5,
+			Alive:          true,
+			Shade:          shade,
+		}
+	}
+	sunny, shaded := newPlant(0), newPlant(0.75)
+	sunny.OnTick()
+	shaded.OnTick()
+	if !almostEqual(sunny.GrowthStage, 0.1) || !almostEqual(shaded.GrowthStage, 0.025) {
+		t.Errorf("expected growth of 0.1 in the sun and 0.025 in the shade, got %.3f and %.3f", sunny.GrowthStage, shaded.GrowthStage)
+	}
+}
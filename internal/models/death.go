@@ -0,0 +1,28 @@
+package models
+
+// DeathCause is what killed a plant.
+type DeathCause string
+
+const (
+	// DeathByDrought is the death of a plant whose roots were too dry.
+	DeathByDrought DeathCause = "drought"
+	// DeathByWaterlogging is the death of a plant whose roots were too wet.
+	DeathByWaterlogging DeathCause = "waterlogging"
+	// DeathByFrost is the death of a plant frost damaged.
+	DeathByFrost DeathCause = "frost"
+	// DeathBySalinity is the death of a plant salty soil damaged.
+	DeathBySalinity DeathCause = "salinity"
+	// DeathByThermalShock is the death of a plant irrigation water much
+	// colder or warmer than its soil shocked.
+	DeathByThermalShock DeathCause = "thermal_shock"
+	// DeathByDisease is the death of a plant a disease wore down.
+	DeathByDisease DeathCause = "disease"
+	// DeathByGermination is the death of a seed that failed to sprout.
+	DeathByGermination DeathCause = "germination"
+)
+
+// die marks the plant dead of cause.
+func (p *Plant) die(cause DeathCause) {
+	p.Alive = false
+	p.DeathCause = cause
+}
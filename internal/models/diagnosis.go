@@ -0,0 +1,159 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// HealthFactor is a stressor that costs a plant health.
+type HealthFactor string
+
+const (
+	// FactorTooDry is soil too dry for the roots of a plant.
+	FactorTooDry HealthFactor = "too_dry"
+	// FactorTooWet is soil too wet for the roots of a plant.
+	FactorTooWet HealthFactor = "too_wet"
+	// FactorTemperature is frost, and irrigation water much colder or
+	// warmer than the soil.
+	FactorTemperature HealthFactor = "temperature"
+	// FactorSalinity is salty soil.
+	FactorSalinity HealthFactor = "salinity"
+	// FactorDisease is a symptomatic disease.
+	FactorDisease HealthFactor = "disease"
+)
+
+// HealthFactors lists every health factor, in the order that breaks a tie
+// between factors that cost a plant as much health as each other.
+var HealthFactors = [...]HealthFactor{FactorTooDry, FactorTooWet, FactorTemperature, FactorSalinity, FactorDisease}
+
+// factorIndex returns the index of a factor in HealthFactors, -1 for an
+// unknown one.
+func factorIndex(factor HealthFactor) int {
+	for i, f := range HealthFactors {
+		if f == factor {
+			return i
+		}
+	}
+	return -1
+}
+
+// HealthDiagnosis is the health a plant lost to each factor on its last
+// tick, in the order of HealthFactors: from the start of its last OnTick on,
+// so that the tick listeners that damage it count. A dead plant keeps the
+// diagnosis of the tick it died on.
+type HealthDiagnosis [len(HealthFactors)]float64
+
+// Loss returns the health the plant lost to a factor, 0 for an unknown one.
+func (d HealthDiagnosis) Loss(factor HealthFactor) float64 {
+	if i := factorIndex(factor); i >= 0 {
+		return d[i]
+	}
+	return 0
+}
+
+// Limiting returns the factor that cost the plant the most health, empty if
+// none cost it any.
+func (d HealthDiagnosis) Limiting() HealthFactor {
+	limiting, most := HealthFactor(""), 0.0
+	for i, loss := range d {
+		if loss > most {
+			limiting, most = HealthFactors[i], loss
+		}
+	}
+	return limiting
+}
+
+// MarshalJSON writes the diagnosis as an object with the loss of every
+// factor, such as {"too_dry": 0.05, "too_wet": 0, ...}.
+func (d HealthDiagnosis) MarshalJSON() ([]byte, error) {
+	losses := make(map[HealthFactor]float64, len(d))
+	for i, loss := range d {
+		losses[HealthFactors[i]] = loss
+	}
+	return json.Marshal(losses)
+}
+
+// UnmarshalJSON reads a diagnosis written by MarshalJSON. Factors left out
+// lost nothing. Returns an error for an unknown factor.
+func (d *HealthDiagnosis) UnmarshalJSON(data []byte) error {
+	var losses map[HealthFactor]float64
+	if err := json.Unmarshal(data, &losses); err != nil {
+		return err
+	}
+	*d = HealthDiagnosis{}
+	for factor, loss := range losses {
+		i := factorIndex(factor)
+		if i < 0 {
+			return fmt.Errorf("unknown health factor: %s", factor)
+		}
+		d[i] = loss
+	}
+	return nil
+}
+
+// FactorCounts counts plants by their limiting factor, in the order of
+// HealthFactors, for a histogram of what holds a set of plants back.
+type FactorCounts [len(HealthFactors)]int
+
+// Add counts n more plants limited by a factor. Plants limited by no factor
+// or an unknown one are not counted.
+func (c *FactorCounts) Add(factor HealthFactor, n int) {
+	if i := factorIndex(factor); i >= 0 {
+		c[i] += n
+	}
+}
+
+// Count returns the number of plants limited by a factor.
+func (c FactorCounts) Count(factor HealthFactor) int {
+	if i := factorIndex(factor); i >= 0 {
+		return c[i]
+	}
+	return 0
+}
+
+// MarshalJSON writes the counts as an object with the factors that limit at
+// least one plant, such as {"too_dry": 3}.
+func (c FactorCounts) MarshalJSON() ([]byte, error) {
+	counts := map[HealthFactor]int{}
+	for i, n := range c {
+		if n != 0 {
+			counts[HealthFactors[i]] = n
+		}
+	}
+	return json.Marshal(counts)
+}
+
+// UnmarshalJSON reads counts written by MarshalJSON. Returns an error for an
+// unknown factor.
+func (c *FactorCounts) UnmarshalJSON(data []byte) error {
+	var counts map[HealthFactor]int
+	if err := json.Unmarshal(data, &counts); err != nil {
+		return err
+	}
+	*c = FactorCounts{}
+	for factor, n := range counts {
+		i := factorIndex(factor)
+		if i < 0 {
+			return fmt.Errorf("unknown health factor: %s", factor)
+		}
+		c[i] = n
+	}
+	return nil
+}
+
+// LimitingFactor returns the factor that cost the plant the most health on
+// its last tick, empty if none did, see HealthDiagnosis.
+func (p *Plant) LimitingFactor() HealthFactor {
+	return p.Diagnosis.Limiting()
+}
+
+// loseHealth takes damage off the plant's health, down to 0, and records
+// what it lost to factor in its diagnosis.
+func (p *Plant) loseHealth(factor HealthFactor, damage float64) {
+	health := math.Max(p.Health-damage, 0)
+	if i := factorIndex(factor); i >= 0 {
+		p.Diagnosis[i] += p.Health - health
+	}
+	p.Health = health
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestHealthDiagnosis_LimitingFactor(t *testing.T) {
+	plantType := PlantType{
+		OptimalSaturation:     0.5,
+		MinSaturation:         0.3,
+		MaxSaturation:         0.7,
+		HealthDegradationRate: 0.05,
+		HealthEnhancementRate: 0.02,
+		SalinityTolerance:     0.2,
+		ThermalShockTolerance: 5,
+	}
+	tests := []struct {
+		name       string
+		saturation float64
+		stress     func(p *Plant)
+		expected   HealthFactor
+		losses     map[HealthFactor]float64
+	}{
+		{"healthy", 0.5, func(p *Plant) {}, "", nil},
+		{"too dry", 0.1, func(p *Plant) {}, FactorTooDry, map[HealthFactor]float64{FactorTooDry: 0.05}},
+		{"too wet", 0.9, func(p *Plant) {}, FactorTooWet, map[HealthFactor]float64{FactorTooWet: 0.05}},
+		{"frost", 0.5, func(p *Plant) { p.Frost(0.1) }, FactorTemperature, map[HealthFactor]float64{FactorTemperature: 0.1}},
+		{"salt", 0.5, func(p *Plant) { p.Salt(0.4, 0.5) }, FactorSalinity, map[HealthFactor]float64{FactorSalinity: 0.1}},
+		{
+			"dry and salty, the salt costing more", 0.1, func(p *Plant) { p.Salt(0.6, 0.5) }, FactorSalinity,
+			map[HealthFactor]float64{FactorTooDry: 0.05, FactorSalinity: 0.2},
+		},
+		{
+			"dry and shocked, the drought costing more", 0.1, func(p *Plant) { p.ThermalShock(7, 0.01) }, FactorTooDry,
+			map[HealthFactor]float64{FactorTooDry: 0.05, FactorTemperature: 0.02},
+		},
+		{
+			"frost and thermal shock add up", 0.5, func(p *Plant) { p.Frost(0.03); p.ThermalShock(8, 0.01) }, FactorTemperature,
+			map[HealthFactor]float64{FactorTemperature: 0.06},
+		},
+		{
+			"tie broken by the order of the factors", 0.9, func(p *Plant) { p.Salt(0.3, 0.5) }, FactorTooWet,
+			map[HealthFactor]float64{FactorTooWet: 0.05, FactorSalinity: 0.05},
+		},
+		{
+			"disease", 0.5, func(p *Plant) { p.Disease = Disease{Stage: Symptomatic}; p.Sicken(0, 0.04, 0) }, FactorDisease,
+			map[HealthFactor]float64{FactorDisease: 0.04},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plant := &Plant{Type: &plantType, SoilSaturation: tt.saturation, Health: 0.8, Alive: true}
+			// A previous tick's losses do not carry over.
+			plant.Diagnosis[0] = 1
+			plant.OnTick()
+			tt.stress(plant)
+			if got := plant.LimitingFactor(); got != tt.expected {
+				t.Errorf("expected limiting factor %q, got %q", tt.expected, got)
+			}
+			for _, factor := range HealthFactors {
+				if got := plant.Diagnosis.Loss(factor); math.Abs(got-tt.losses[factor]) > 1e-9 {
+					t.Errorf("expected a loss of %.3f to %s, got %.3f", tt.losses[factor], factor, got)
+				}
+			}
+		})
+	}
+}
+
+func TestHealthDiagnosis_KeptByDeadPlants(t *testing.T) {
+	plantType := PlantType{MinSaturation: 0.3, MaxSaturation: 0.7, HealthDegradationRate: 0.05}
+	plant := &Plant{Type: &plantType, SoilSaturation: 0.5, Health: 0.03, Alive: true}
+	plant.OnTick()
+	plant.Frost(0.1)
+	plant.OnTick()
+	if plant.Alive || plant.LimitingFactor() != FactorTemperature || plant.Diagnosis.Loss(FactorTemperature) != 0.03 {
+		t.Errorf("expected the dead plant to keep the frost that killed it, got %+v", plant.Diagnosis)
+	}
+}
+
+func TestFactorCounts(t *testing.T) {
+	var counts FactorCounts
+	counts.Add(FactorTooDry, 3)
+	counts.Add(FactorDisease, 1)
+	counts.Add("", 5)
+	if counts.Count(FactorTooDry) != 3 || counts.Count(FactorDisease) != 1 || counts.Count(FactorTooWet) != 0 {
+		t.Errorf("unexpected counts %v", counts)
+	}
+	data, err := json.Marshal(counts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != `{"disease":1,"too_dry":3}` {
+		t.Errorf("expected the factors that limit a plant, got %s", data)
+	}
+	var decoded FactorCounts
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded != counts {
+		t.Errorf("expected %v back, got %v (%v)", counts, decoded, err)
+	}
+	if err := json.Unmarshal([]byte(`{"pests":2}`), &decoded); err == nil || err.Error() != "unknown health factor: pests" {
+		t.Errorf("expected an error for an unknown factor, got %v", err)
+	}
+}
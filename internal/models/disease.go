@@ -0,0 +1,78 @@
+package models
+
+import (
+	"errors"
+	"math"
+)
+
+// DiseaseStage is how far a disease has progressed in a plant.
+type DiseaseStage string
+
+const (
+	// Infected plants incubate the disease without symptoms.
+	Infected DiseaseStage = "infected"
+	// Symptomatic plants lose health and transpire less.
+	Symptomatic DiseaseStage = "symptomatic"
+)
+
+// Validate checks that the stage is infected, symptomatic or empty, for a
+// healthy plant.
+func (s DiseaseStage) Validate() error {
+	if s != "" && s != Infected && s != Symptomatic {
+		return errors.New("disease stage must be infected or symptomatic: " + string(s))
+	}
+	return nil
+}
+
+// Disease is the disease of a plant, the zero value for a healthy plant.
+// Ticks counts the ticks the disease has progressed since the infection.
+type Disease struct {
+	Stage DiseaseStage
+	Ticks int
+}
+
+// Diseased reports whether the plant carries a disease, with or without
+// symptoms.
+func (p *Plant) Diseased() bool {
+	return p.Disease.Stage != ""
+}
+
+// Infect infects a healthy, alive plant and reports whether it did.
+func (p *Plant) Infect() bool {
+	if !p.Alive || p.Diseased() {
+		return false
+	}
+	p.Disease = Disease{Stage: Infected}
+	return true
+}
+
+// Cure rids the plant of its disease.
+func (p *Plant) Cure() {
+	p.Disease = Disease{}
+}
+
+// Sicken moves the plant's disease one tick on. An infected plant turns
+// symptomatic once it has incubated the disease for incubation ticks, and
+// Sicken reports whether it did on this tick. A symptomatic plant loses decay
+// health, dying when its health runs out, and transpires drop times its
+// normal depletion less, which gives that water back to its soil. Dead and
+// healthy plants are left as they are.
+func (p *Plant) Sicken(incubation int, decay, drop float64) (symptomatic bool) {
+	if !p.Alive || !p.Diseased() {
+		return false
+	}
+	p.Disease.Ticks++
+	if p.Disease.Stage == Infected && p.Disease.Ticks > incubation {
+		p.Disease.Stage = Symptomatic
+		symptomatic = true
+	}
+	if p.Disease.Stage != Symptomatic {
+		return symptomatic
+	}
+	p.SoilSaturation = math.Min(p.SoilSaturation+drop*p.depletion(), 1)
+	p.loseHealth(FactorDisease, decay)
+	if p.Health <= 0 {
+		p.die(DeathByDisease)
+	}
+	return symptomatic
+}
@@ -0,0 +1,65 @@
+package models
+
+import "testing"
+
+func TestPlant_DiseaseIncubation(t *testing.T) {
+	plant := &Plant{Type: &PlantType{SaturationDepletion: 0.02}, Health: 1, SoilSaturation: 0.5, Alive: true}
+	if plant.Sicken(2, 0.1, 0.5) || plant.Diseased() {
+		t.Fatal("expected a healthy plant to stay healthy")
+	}
+	if !plant.Infect() || plant.Infect() {
+		t.Fatal("expected a healthy plant to be infected once")
+	}
+
+	// 2 ticks of incubation without any effect, then symptoms.
+	for tick := 1; tick <= 2; tick++ {
+		if plant.Sicken(2, 0.1, 0.5) {
+			t.Fatalf("tick %d: expected no symptoms during the incubation", tick)
+		}
+		if plant.Health != 1 || plant.SoilSaturation != 0.5 || plant.Disease.Stage != Infected {
+			t.Fatalf("tick %d: expected no effect during the incubation, got %+v", tick, plant)
+		}
+	}
+	if !plant.Sicken(2, 0.1, 0.5) {
+		t.Fatal("expected symptoms after 2 ticks of incubation")
+	}
+	if plant.Disease != (Disease{Stage: Symptomatic, Ticks: 3}) {
+		t.Errorf("expected a symptomatic plant 3 ticks into the disease, got %+v", plant.Disease)
+	}
+	// Half of the 0.02 depletion is not transpired.
+	if !almostEqual(plant.Health, 0.9) || !almostEqual(plant.SoilSaturation, 0.51) {
+		t.Errorf("expected 0.9 health and 0.51 saturation, got %+v", plant)
+	}
+	if plant.Sicken(2, 0.1, 0.5) {
+		t.Error("expected the symptoms to be reported once")
+	}
+
+	plant.Cure()
+	if plant.Diseased() {
+		t.Error("expected the plant to be cured")
+	}
+}
+
+func TestPlant_DiseaseKills(t *testing.T) {
+	plant := &Plant{Type: &PlantType{}, Health: 0.15, Alive: true}
+	plant.Infect()
+	plant.Sicken(0, 0.1, 0)
+	plant.Sicken(0, 0.1, 0)
+	if plant.Alive || plant.Health != 0 {
+		t.Errorf("expected the disease to kill the plant, got %+v", plant)
+	}
+	if plant.Cure(); plant.Infect() {
+		t.Error("expected a dead plant not to be infected")
+	}
+}
+
+func TestDiseaseStage_Validate(t *testing.T) {
+	for _, stage := range []DiseaseStage{"", Infected, Symptomatic} {
+		if err := stage.Validate(); err != nil {
+			t.Errorf("%q: unexpected error: %v", stage, err)
+		}
+	}
+	if err := DiseaseStage("blight").Validate(); err == nil || err.Error() != "disease stage must be infected or symptomatic: blight" {
+		t.Errorf("expected an unknown stage to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package models
+
+import "errors"
+
+// Never is the tick count of a PlantForecast for what the plant does not get
+// to within the forecast horizon, or at all once it has settled.
+const Never = -1
+
+// DefaultForecastHorizon is the number of ticks ForecastPlant looks ahead when
+// ForecastAssumptions leaves the horizon at zero.
+const DefaultForecastHorizon = 10000
+
+// PlantSnapshot is the state of a plant its future depends on, copied so that
+// forecasting from it leaves the plant as it is.
+type PlantSnapshot struct {
+	Type           *PlantType
+	SoilSaturation float64
+	DeepSaturation float64
+	Health         float64
+	GrowthStage    float64
+	Alive          bool
+	Soil           *SoilType
+	Modifiers      []Modifier
+	Germination    *Germination
+}
+
+// Snapshot returns the current state of the plant for ForecastPlant.
+func (p *Plant) Snapshot() PlantSnapshot {
+	clone := p.Clone()
+	return PlantSnapshot{
+		Type:           clone.Type,
+		SoilSaturation: clone.SoilSaturation,
+		DeepSaturation: clone.DeepSaturation,
+		Health:         clone.Health,
+		GrowthStage:    clone.GrowthStage,
+		Alive:          clone.Alive,
+		Soil:           clone.Soil,
+		Modifiers:      clone.Modifiers,
+		Germination:    clone.Germination,
+	}
+}
+
+// ForecastAssumptions are the conditions a forecast assumes to persist, on
+// top of the plant's own OnTick: each tick, FrostDamage is taken off its
+// health, see Plant.Frost, the soil dries out Evaporation times its normal
+// depletion more, see Plant.Evaporate, and the plant grows GrowthBoost times
+// its base growth rate more, see Plant.BoostGrowth. Horizon is the number of
+// ticks looked ahead, DefaultForecastHorizon when zero.
+type ForecastAssumptions struct {
+	FrostDamage float64
+	Evaporation float64
+	GrowthBoost float64
+	Horizon     int
+}
+
+// PlantForecast projects a plant left unwatered under unchanging conditions.
+// Tick counts are relative to the snapshot, 0 for what is already the case,
+// and Never for what does not happen within the horizon or, once the plant
+// has settled into a steady state, ever.
+//
+// InterventionSaturation is the saturation its roots reach, see
+// Plant.RootSaturation, below which the plant's health starts to degrade:
+// the latest a scheduler must water it. TicksToIntervention is how long its
+// soil takes to get there.
+type PlantForecast struct {
+	TicksToMaturity        int
+	TicksToDeath           int
+	TicksToIntervention    int
+	InterventionSaturation float64
+}
+
+// ForecastPlant projects the snapshot of a plant, stepping a copy of it the
+// way the simulation does, tick by tick, without any watering. A germinating
+// seed is assumed to sprout once it has germinated. The stepping stops early
+// once the plant is dead, or settles: its state no longer changes from one
+// tick to the next, so what has not happened yet never will. A dead plant
+// has its TicksToDeath at 0, and a mature one its TicksToMaturity.
+// Returns an error if:
+// - the snapshot has no plant type
+// - an assumption or the horizon is negative
+func ForecastPlant(p PlantSnapshot, assumptions ForecastAssumptions) (*PlantForecast, error) {
+	if p.Type == nil {
+		return nil, errors.New("plant snapshot must have a plant type")
+	}
+	if assumptions.FrostDamage < 0 || assumptions.Evaporation < 0 || assumptions.GrowthBoost < 0 {
+		return nil, errors.New("forecast assumptions cannot be negative")
+	}
+	if assumptions.Horizon < 0 {
+		return nil, errors.New("forecast horizon cannot be negative")
+	}
+	horizon := assumptions.Horizon
+	if horizon == 0 {
+		horizon = DefaultForecastHorizon
+	}
+
+	// Clone takes the modifiers and germination apart from the snapshot.
+	plant := (&Plant{
+		Type:           p.Type,
+		SoilSaturation: p.SoilSaturation,
+		DeepSaturation: p.DeepSaturation,
+		Health:         p.Health,
+		GrowthStage:    p.GrowthStage,
+		Alive:          p.Alive,
+		Soil:           p.Soil,
+		Modifiers:      p.Modifiers,
+		Germination:    p.Germination,
+	}).Clone()
+	forecast := &PlantForecast{
+		TicksToMaturity:        Never,
+		TicksToDeath:           Never,
+		TicksToIntervention:    Never,
+		InterventionSaturation: p.Type.MinSaturation,
+	}
+	for tick := 0; tick <= horizon; tick++ {
+		if forecast.TicksToMaturity == Never && plant.GrowthStage >= 1 {
+			forecast.TicksToMaturity = tick
+		}
+		if !plant.Alive {
+			forecast.TicksToDeath = tick
+			return forecast, nil
+		}
+		if forecast.TicksToIntervention == Never && plant.Germination == nil && plant.RootSaturation() < p.Type.MinSaturation {
+			forecast.TicksToIntervention = tick
+		}
+		if tick == horizon {
+			break
+		}
+		before := *plant
+		settled := len(plant.Modifiers) == 0 && plant.Germination == nil
+		plant.forecastTick(assumptions)
+		if settled && plant.Alive && plant.SoilSaturation == before.SoilSaturation && plant.DeepSaturation == before.DeepSaturation &&
+			plant.Health == before.Health && plant.GrowthStage == before.GrowthStage {
+			break
+		}
+	}
+	return forecast, nil
+}
+
+// forecastTick steps the plant one tick under the assumptions, in the order
+// the simulation does: its own update, then the weather.
+func (p *Plant) forecastTick(assumptions ForecastAssumptions) {
+	p.OnTick()
+	if p.Germinated() {
+		p.Germination = nil
+	}
+	if assumptions.FrostDamage > 0 {
+		p.Frost(assumptions.FrostDamage)
+	}
+	p.Evaporate(assumptions.Evaporation)
+	p.BoostGrowth(assumptions.GrowthBoost)
+}
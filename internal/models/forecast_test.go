@@ -0,0 +1,147 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+// forecastType is a plant type that matures in about 20 ticks and runs dry in
+// about 10.
+var forecastType = PlantType{
+	Name:                  "Forecast",
+	OptimalSaturation:     0.6,
+	MinSaturation:         0.3,
+	MaxSaturation:         0.8,
+	BaseGrowthRate:        0.05,
+	SaturationDepletion:   0.03,
+	HealthDegradationRate: 0.1,
+	HealthEnhancementRate: 0.02,
+}
+
+// stepUntil steps a clone of the plant until done holds, and returns the
+// number of ticks that took, or Never if it did not within limit.
+func stepUntil(p *Plant, limit int, done func(*Plant) bool) int {
+	clone := p.Clone()
+	for tick := 0; tick <= limit; tick++ {
+		if done(clone) {
+			return tick
+		}
+		clone.OnTick()
+	}
+	return Never
+}
+
+func TestForecastPlant_MatchesStepping(t *testing.T) {
+	layered := SoilType{Name: "Layered", Retention: 1, Drainage: 1, Percolation: 0.1}
+	deepRooted := forecastType
+	deepRooted.RootDepth = 0.5
+
+	tests := []struct {
+		name  string
+		plant *Plant
+	}{
+		{"seedling", &Plant{Type: &forecastType, SoilSaturation: 0.9, Health: 1, Alive: true}},
+		{"dry", &Plant{Type: &forecastType, SoilSaturation: 0.35, Health: 0.6, GrowthStage: 0.4, Alive: true}},
+		{"pruned", &Plant{Type: &forecastType, SoilSaturation: 0.7, Health: 1, GrowthStage: 0.5, Alive: true,
+			Modifiers: []Modifier{{Source: Pruned, Depletion: 0.5, Enhancement: 1.5, Ticks: 5}}}},
+		{"layered soil", &Plant{Type: &deepRooted, SoilSaturation: 0.8, DeepSaturation: 0.8, Health: 1, GrowthStage: 0.2, Alive: true, Soil: &layered}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := tt.plant.Clone()
+			forecast, err := ForecastPlant(tt.plant.Snapshot(), ForecastAssumptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tt.plant, before) {
+				t.Errorf("expected the plant to be left as it is, got %+v", tt.plant)
+			}
+
+			limit := DefaultForecastHorizon
+			mature := stepUntil(tt.plant, limit, func(p *Plant) bool { return p.GrowthStage >= 1 })
+			dead := stepUntil(tt.plant, limit, func(p *Plant) bool { return !p.Alive })
+			dry := stepUntil(tt.plant, limit, func(p *Plant) bool { return p.RootSaturation() < forecastType.MinSaturation })
+			if dead == Never {
+				t.Fatal("expected the unwatered plant to die")
+			}
+			// Nothing happens to a dead plant.
+			if mature > dead {
+				mature = Never
+			}
+			expected := PlantForecast{TicksToMaturity: mature, TicksToDeath: dead, TicksToIntervention: dry, InterventionSaturation: 0.3}
+			if *forecast != expected {
+				t.Errorf("expected %+v, got %+v", expected, *forecast)
+			}
+		})
+	}
+}
+
+func TestForecastPlant_EdgeCases(t *testing.T) {
+	steady := forecastType
+	steady.SaturationDepletion = 0
+	stunted := steady
+	stunted.BaseGrowthRate = 0
+
+	tests := []struct {
+		name        string
+		plant       *Plant
+		assumptions ForecastAssumptions
+		expected    PlantForecast
+	}{
+		{"already mature", &Plant{Type: &steady, SoilSaturation: 0.6, Health: 1, GrowthStage: 1, Alive: true},
+			ForecastAssumptions{}, PlantForecast{TicksToMaturity: 0, TicksToDeath: Never, TicksToIntervention: Never, InterventionSaturation: 0.3}},
+		{"already dead", &Plant{Type: &forecastType, SoilSaturation: 0.1, GrowthStage: 0.5, DeathCause: DeathByDrought},
+			ForecastAssumptions{}, PlantForecast{TicksToMaturity: Never, TicksToDeath: 0, TicksToIntervention: Never, InterventionSaturation: 0.3}},
+		{"dead when mature", &Plant{Type: &forecastType, GrowthStage: 1},
+			ForecastAssumptions{}, PlantForecast{TicksToMaturity: 0, TicksToDeath: 0, TicksToIntervention: Never, InterventionSaturation: 0.3}},
+		{"steady saturation", &Plant{Type: &steady, SoilSaturation: 0.6, Health: 1, Alive: true},
+			ForecastAssumptions{}, PlantForecast{TicksToMaturity: 16, TicksToDeath: Never, TicksToIntervention: Never, InterventionSaturation: 0.3}},
+		{"never grows", &Plant{Type: &stunted, SoilSaturation: 0.6, Health: 1, Alive: true},
+			ForecastAssumptions{}, PlantForecast{TicksToMaturity: Never, TicksToDeath: Never, TicksToIntervention: Never, InterventionSaturation: 0.3}},
+		{"beyond the horizon", &Plant{Type: &forecastType, SoilSaturation: 0.9, Health: 1, Alive: true},
+			ForecastAssumptions{Horizon: 5}, PlantForecast{TicksToMaturity: Never, TicksToDeath: Never, TicksToIntervention: Never, InterventionSaturation: 0.3}},
+		{"heat wave", &Plant{Type: &steady, SoilSaturation: 0.6, Health: 1, Alive: true},
+			ForecastAssumptions{Evaporation: 1}, PlantForecast{TicksToMaturity: 16, TicksToDeath: Never, TicksToIntervention: Never, InterventionSaturation: 0.3}},
+		{"frost", &Plant{Type: &steady, SoilSaturation: 0.6, Health: 1, Alive: true},
+			ForecastAssumptions{FrostDamage: 0.25}, PlantForecast{TicksToMaturity: Never, TicksToDeath: 5, TicksToIntervention: Never, InterventionSaturation: 0.3}},
+		{"growth boost", &Plant{Type: &steady, SoilSaturation: 0.6, Health: 1, Alive: true},
+			ForecastAssumptions{GrowthBoost: 1.5}, PlantForecast{TicksToMaturity: 8, TicksToDeath: Never, TicksToIntervention: Never, InterventionSaturation: 0.3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forecast, err := ForecastPlant(tt.plant.Snapshot(), tt.assumptions)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *forecast != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, *forecast)
+			}
+		})
+	}
+}
+
+func TestForecastPlant_Validation(t *testing.T) {
+	plant := &Plant{Type: &forecastType, SoilSaturation: 0.6, Health: 1, Alive: true}
+
+	tests := []struct {
+		name        string
+		snapshot    PlantSnapshot
+		assumptions ForecastAssumptions
+		errorMsg    string
+	}{
+		{"no type", PlantSnapshot{Health: 1, Alive: true}, ForecastAssumptions{}, "plant snapshot must have a plant type"},
+		{"negative evaporation", plant.Snapshot(), ForecastAssumptions{Evaporation: -1}, "forecast assumptions cannot be negative"},
+		{"negative horizon", plant.Snapshot(), ForecastAssumptions{Horizon: -1}, "forecast horizon cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ForecastPlant(tt.snapshot, tt.assumptions)
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package models
+
+// Germination is the progress of a seed germinating: the ticks it has
+// germinated for and how many of them its soil saturation was within the
+// germination range of its type. Dormant is set while its soil is too cold
+// for it to germinate, see Plant.SetSoilTemperature.
+type Germination struct {
+	Ticks     int
+	Favorable int
+	Dormant   bool
+}
+
+// Germinating reports whether the plant is a seed still germinating.
+func (p *Plant) Germinating() bool {
+	return p.Germination != nil
+}
+
+// Germinated reports whether the plant is an alive seed that has germinated
+// for the GerminationTicks of its type and waits for Sprout.
+func (p *Plant) Germinated() bool {
+	return p.Alive && p.Germination != nil && p.Germination.Ticks >= p.Type.GerminationTicks
+}
+
+// GerminationChance returns the chance of the seed to sprout: one minus the
+// GerminationFailure of its type, scaled by the share of its germination
+// ticks its soil was within the germination range. It is 0 before the first
+// tick of germination and 1 for plants that are not germinating.
+func (p *Plant) GerminationChance() float64 {
+	if p.Germination == nil {
+		return 1
+	}
+	if p.Germination.Ticks == 0 {
+		return 0
+	}
+	favorable := float64(p.Germination.Favorable) / float64(p.Germination.Ticks)
+	return (1 - p.Type.GerminationFailure) * favorable
+}
+
+// Sprout ends the germination of a germinated seed, see Germinated, and
+// reports whether it sprouted. The seed sprouts into the normal lifecycle
+// when draw, in [0.0, 1.0), is below its GerminationChance, and dies
+// otherwise. Plants that are not germinated are left as they are.
+func (p *Plant) Sprout(draw float64) bool {
+	if !p.Germinated() {
+		return false
+	}
+	sprouted := draw < p.GerminationChance()
+	p.Germination = nil
+	if !sprouted {
+		p.Health = 0
+		p.die(DeathByGermination)
+	}
+	return sprouted
+}
+
+// SetSoilTemperature tells a germinating seed the temperature of its soil in
+// Celsius: below the GerminationMinSoilTemperature of its type the seed lies
+// dormant from its next tick on, and the ticks it lies dormant do not count
+// towards its germination. Plants that are not germinating are left as they
+// are.
+func (p *Plant) SetSoilTemperature(temperature float64) {
+	if p.Germination == nil {
+		return
+	}
+	minimum := p.Type.GerminationMinSoilTemperature
+	p.Germination.Dormant = minimum != 0 && temperature < minimum
+}
+
+// germinate counts a tick of germination, unless the seed lies dormant. The
+// seed neither grows, changes health nor draws water from its soil.
+func (p *Plant) germinate() {
+	if p.Germination.Dormant {
+		return
+	}
+	p.Germination.Ticks++
+	if p.SoilSaturation >= p.Type.GerminationMinSaturation && p.SoilSaturation <= p.Type.GerminationMaxSaturation {
+		p.Germination.Favorable++
+	}
+}
@@ -0,0 +1,104 @@
+package models
+
+import "testing"
+
+func germinatingType() PlantType {
+	return PlantType{
+		Name:                     "Bean",
+		OptimalSaturation:        0.6,
+		MinSaturation:            0.2,
+		MaxSaturation:            0.9,
+		BaseGrowthRate:           0.05,
+		SaturationDepletion:      0.02,
+		HealthEnhancementRate:    0.01,
+		GerminationTicks:         4,
+		GerminationMinSaturation: 0.5,
+		GerminationMaxSaturation: 0.8,
+		GerminationFailure:       0.2,
+	}
+}
+
+func TestPlant_Germination(t *testing.T) {
+	plant, err := NewPlant("bean", germinatingType(), "section-A", 0.6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !plant.Germinating() || plant.GerminationChance() != 0 {
+		t.Fatalf("expected a new seed to germinate, got %+v", plant.Germination)
+	}
+
+	// 3 ticks in range and 1 too dry, without growing or drawing water.
+	for tick := 1; tick <= 4; tick++ {
+		if tick == 4 {
+			plant.SoilSaturation = 0.3
+		}
+		if plant.Germinated() {
+			t.Fatalf("tick %d: expected the seed to still germinate", tick)
+		}
+		plant.OnTick()
+	}
+	if *plant.Germination != (Germination{Ticks: 4, Favorable: 3}) {
+		t.Errorf("expected 3 favorable ticks out of 4, got %+v", plant.Germination)
+	}
+	if plant.GrowthStage != 0 || plant.Health != 1 || plant.SoilSaturation != 0.3 {
+		t.Errorf("expected the seed not to grow nor draw water, got %+v", plant)
+	}
+	if plant.BoostGrowth(1); plant.GrowthStage != 0 {
+		t.Errorf("expected a seed not to be boosted, got %.2f", plant.GrowthStage)
+	}
+	if clone := plant.Clone(); clone.Germination == plant.Germination {
+		t.Error("expected the clone not to share the germination")
+	}
+
+	// 0.8 of a 0.75 share.
+	if !plant.Germinated() || !almostEqual(plant.GerminationChance(), 0.6) {
+		t.Fatalf("expected a germinated seed with a 0.6 chance, got %.2f", plant.GerminationChance())
+	}
+	failed := plant.Clone()
+	if !plant.Sprout(0.5) || plant.Germinating() || !plant.Alive {
+		t.Errorf("expected the seed to sprout, got %+v", plant)
+	}
+	if failed.Sprout(0.7) || failed.Germinating() || failed.Alive || failed.Health != 0 {
+		t.Errorf("expected the seed to fail, got %+v", failed)
+	}
+	if plant.Sprout(0) {
+		t.Error("expected a sprouted plant not to sprout again")
+	}
+
+	plant.OnTick()
+	if plant.GrowthStage == 0 {
+		t.Error("expected the sprouted plant to grow")
+	}
+}
+
+func TestPlantType_ValidateGermination(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   func(*PlantType)
+		errorMsg string
+	}{
+		{"valid", func(*PlantType) {}, ""},
+		{"no germination", func(t *PlantType) { *t = PlantType{Name: "Bean"} }, ""},
+		{"negative ticks", func(t *PlantType) { t.GerminationTicks = -1 }, "plant type germination ticks cannot be negative"},
+		{"range above 1", func(t *PlantType) { t.GerminationMaxSaturation = 1.2 }, "plant type germination saturation range must be within 0.0 and 1.0"},
+		{"inverted range", func(t *PlantType) { t.GerminationMinSaturation = 0.9 }, "plant type germination saturation range must be within 0.0 and 1.0"},
+		{"failure above 1", func(t *PlantType) { t.GerminationFailure = 1.5 }, "plant type germination failure must be between 0.0 and 1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plantType := germinatingType()
+			tt.change(&plantType)
+			err := plantType.Validate()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
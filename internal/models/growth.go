@@ -0,0 +1,48 @@
+package models
+
+import "errors"
+
+// GrowthParams decide how a plant's health and soil saturation speed up or
+// slow down its growth: below MinHealth it does not grow, below SlowHealth
+// it grows SlowFactor times slower, and within OptimalTolerance of its
+// optimal saturation it grows OptimalBonusFactor times faster.
+type GrowthParams struct {
+	MinHealth          float64
+	SlowHealth         float64
+	SlowFactor         float64
+	OptimalBonusFactor float64
+	OptimalTolerance   float64
+}
+
+// DefaultGrowthParams are the growth parameters of plant types that do not
+// set their own.
+var DefaultGrowthParams = GrowthParams{MinHealth: 0.3, SlowHealth: 0.5, SlowFactor: GROWTH_SLOW_FACTOR, OptimalBonusFactor: GROWTH_OPTIMAL_FACTOR, OptimalTolerance: 0.15}
+
+// Validate checks that the health thresholds are between 0.0 and 1.0, with
+// MinHealth not above SlowHealth, that both factors are at least 1.0, so
+// that slow growth is never faster and the bonus never a penalty, and that
+// OptimalTolerance is between 0.0 and 1.0.
+func (g GrowthParams) Validate() error {
+	if g.MinHealth < 0 || g.SlowHealth > 1 || g.MinHealth > g.SlowHealth {
+		return errors.New("growth health thresholds must be between 0.0 and 1.0, the minimum not above the slow one")
+	}
+	if g.SlowFactor < 1 {
+		return errors.New("slow growth factor must be at least 1.0")
+	}
+	if g.OptimalBonusFactor < 1 {
+		return errors.New("optimal growth bonus factor must be at least 1.0")
+	}
+	if g.OptimalTolerance < 0 || g.OptimalTolerance > 1 {
+		return errors.New("optimal growth tolerance must be between 0.0 and 1.0")
+	}
+	return nil
+}
+
+// growth returns the growth parameters of the type, DefaultGrowthParams for
+// a type that leaves Growth unset.
+func (t *PlantType) growth() GrowthParams {
+	if t.Growth == (GrowthParams{}) {
+		return DefaultGrowthParams
+	}
+	return t.Growth
+}
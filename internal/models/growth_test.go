@@ -0,0 +1,101 @@
+package models
+
+import "testing"
+
+func TestGrowthParams_DefaultsMatchUnset(t *testing.T) {
+	unset := PlantType{Name: "Sprout", OptimalSaturation: 0.6, MinSaturation: 0.3, MaxSaturation: 0.8,
+		BaseGrowthRate: 0.02, SaturationDepletion: 0.01, HealthDegradationRate: 0.05, HealthEnhancementRate: 0.01}
+	defaulted := unset
+	defaulted.Growth = DefaultGrowthParams
+
+	for _, health := range []float64{0.2, 0.3, 0.45, 0.5, 0.9} {
+		for _, saturation := range []float64{0.2, 0.5, 0.6, 0.74} {
+			a := &Plant{Type: &unset, Health: health, SoilSaturation: saturation, Alive: true}
+			b := &Plant{Type: &defaulted, Health: health, SoilSaturation: saturation, Alive: true}
+			for range 10 {
+				a.OnTick()
+				b.OnTick()
+			}
+			if a.GrowthStage != b.GrowthStage || a.Health != b.Health {
+				t.Errorf("health %.2f, saturation %.2f: expected the defaults to grow as an unset type, got %+v and %+v", health, saturation, a, b)
+			}
+		}
+	}
+}
+
+func TestGrowthParams_Tuned(t *testing.T) {
+	tuned := GrowthParams{MinHealth: 0.1, SlowHealth: 0.7, SlowFactor: 2, OptimalBonusFactor: 3, OptimalTolerance: 0.05}
+	tests := []struct {
+		name       string
+		health     float64
+		saturation float64
+		expected   float64
+	}{
+		// 0.1 per tick, with the optimal saturation at 0.6
+		{"below the minimum", 0.05, 0.4, 0},
+		{"slow where the defaults stop", 0.2, 0.4, 0.05},
+		{"slow where the defaults are normal", 0.6, 0.4, 0.05},
+		{"normal", 0.8, 0.4, 0.1},
+		{"bonus", 0.8, 0.62, 0.3},
+		{"outside the narrower tolerance", 0.8, 0.7, 0.1},
+		{"slow with bonus", 0.6, 0.6, 0.15},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plant := &Plant{
+				Type:           &PlantType{BaseGrowthRate: 0.1, OptimalSaturation: 0.6, MinSaturation: 0.1, MaxSaturation: 0.9, Growth: tuned},
+				Health:         tt.health,
+				SoilSaturation: tt.saturation,
+				Alive:          true,
+			}
+
+			plant.OnTick()
+
+			if !almostEqual(plant.GrowthStage, tt.expected) {
+				t.Errorf("expected growth of %.2f, got %.4f", tt.expected, plant.GrowthStage)
+			}
+		})
+	}
+
+	boosted := &Plant{Type: &PlantType{BaseGrowthRate: 0.1, Growth: tuned}, Health: 0.2, Alive: true}
+	boosted.BoostGrowth(1)
+	if !almostEqual(boosted.GrowthStage, 0.1) {
+		t.Errorf("expected the tuned minimum health to allow a boost, got %.4f", boosted.GrowthStage)
+	}
+}
+
+func TestGrowthParams_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   func(*GrowthParams)
+		errorMsg string
+	}{
+		{"defaults", func(g *GrowthParams) {}, ""},
+		{"no thresholds", func(g *GrowthParams) { g.MinHealth, g.SlowHealth = 0, 0 }, ""},
+		{"thresholds out of order", func(g *GrowthParams) { g.MinHealth = 0.6 }, "growth health thresholds must be between 0.0 and 1.0, the minimum not above the slow one"},
+		{"slow threshold above 1", func(g *GrowthParams) { g.SlowHealth = 1.2 }, "growth health thresholds must be between 0.0 and 1.0, the minimum not above the slow one"},
+		{"slow factor speeding up", func(g *GrowthParams) { g.SlowFactor = 0.8 }, "slow growth factor must be at least 1.0"},
+		{"bonus factor slowing down", func(g *GrowthParams) { g.OptimalBonusFactor = 0.9 }, "optimal growth bonus factor must be at least 1.0"},
+		{"negative tolerance", func(g *GrowthParams) { g.OptimalTolerance = -0.1 }, "optimal growth tolerance must be between 0.0 and 1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := DefaultGrowthParams
+			tt.change(&params)
+			err := params.Validate()
+			if tt.errorMsg == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.errorMsg != "" && (err == nil || err.Error() != tt.errorMsg) {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+
+	plantType := PlantType{Name: "Sprout", Growth: GrowthParams{SlowFactor: 1}}
+	if err := plantType.Validate(); err == nil || err.Error() != "plant type optimal growth bonus factor must be at least 1.0" {
+		t.Errorf("expected the plant type to check its growth parameters, got %v", err)
+	}
+}
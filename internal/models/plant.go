@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"slices"
 	"time"
 )
 
@@ -19,20 +20,89 @@ type PlantType struct {
 	SaturationDepletion   float64 // per tick
 	HealthDegradationRate float64 // per tick if not in optimal saturation range
 	HealthEnhancementRate float64 // per tick if in the optimal saturation range
+	FrostTolerance        bool    // frost does not damage the plant
+	RootDepth             float64 // share of its water a mature plant draws from a deep soil layer
+	// MinTemperature and MaxTemperature bound the daily mean temperature in
+	// Celsius plants of the type survive, both 0 when unset, see Normalize
+	// and CompatibleWithEnvironment.
+	MinTemperature float64
+	MaxTemperature float64
+	// Seeds germinate for GerminationTicks, 0 to skip germination, needing
+	// their soil saturation between GerminationMinSaturation and
+	// GerminationMaxSaturation. GerminationFailure is the chance of a seed
+	// to fail even when it had that the whole time. Seeds lie dormant in
+	// soil colder than GerminationMinSoilTemperature in Celsius, 0 for seeds
+	// that germinate in any soil, see Plant.SetSoilTemperature.
+	GerminationTicks              int
+	GerminationMinSaturation      float64
+	GerminationMaxSaturation      float64
+	GerminationFailure            float64
+	GerminationMinSoilTemperature float64
+	// Growth tunes how health and saturation change the growth rate, the
+	// zero value for DefaultGrowthParams. Any other value is used as is, so
+	// changing a few parameters starts from a copy of DefaultGrowthParams.
+	Growth GrowthParams
+	// SalinityTolerance is the soil salinity, 0.0 to 1.0, above which plants
+	// of the type take damage, 0 for plants salt does not harm.
+	SalinityTolerance float64
+	// ThermalShockTolerance is the difference in Celsius between the
+	// irrigation water and their soil above which watering shocks plants of
+	// the type, 0 for plants no water temperature shocks.
+	ThermalShockTolerance float64
+}
+
+// PlantTypes interns plant types: every Intern of an equal plant type
+// returns the same pointer, so that plants of a type all point to one
+// PlantType instead of each holding its own. The shared copies must not be
+// changed. A table keeps every type it interned for as long as it lives, so
+// each simulator holds its own rather than sharing one for the process.
+// The zero value is an empty table ready to use.
+type PlantTypes struct {
+	shared map[PlantType]*PlantType
+}
+
+// Intern returns the shared copy of t, adding it to the table on first use.
+func (p *PlantTypes) Intern(t PlantType) *PlantType {
+	if shared, ok := p.shared[t]; ok {
+		return shared
+	}
+	if p.shared == nil {
+		p.shared = map[PlantType]*PlantType{}
+	}
+	p.shared[t] = &t
+	return &t
 }
 
 // Plant represents an individual plant instance in the simulation.
 // Each plant has its own state that changes over time based on environmental
-// conditions and the characteristics defined by its PlantType.
+// conditions and the characteristics defined by its PlantType, which plants
+// of the same type in a simulator share, see PlantTypes.
 type Plant struct {
 	ID             string
-	Type           PlantType
+	Type           *PlantType
 	SectionID      string
-	SoilSaturation float64 // 0.0 to 1.0
+	SoilSaturation float64 // 0.0 to 1.0, the surface layer of a layered soil
+	DeepSaturation float64 // 0.0 to 1.0, the deep layer of a layered soil
 	Health         float64 // 0.0 (dead) to 1.0 (perfect)
 	GrowthStage    float64 // 0.0 (seed) to 1.0 (mature)
 	Alive          bool
+	DeathCause     DeathCause // empty while the plant is alive
 	CreatedAt      time.Time
+	Tags           []string     // free-form labels used to group plants across sections
+	Soil           *SoilType    // the soil of the plant's section, nil for plain soil
+	Disease        Disease      // the zero value for a healthy plant
+	Modifiers      []Modifier   // temporary changes to the plant's rates, e.g. after pruning
+	Germination    *Germination // nil once sprouted, or for types without germination
+	Shade          float64      // 0.0 to 1.0, the share of its light taller plants take, see LightCompetition
+	Position       *Position    // where it stands in its section, nil when not placed
+	// Diagnosis is the health the plant lost to each factor on its last
+	// tick, see LimitingFactor.
+	Diagnosis HealthDiagnosis
+	// ExcludeFromWatering has watering events pass the plant by, and
+	// Quarantined keeps it from spreading or catching diseases, see
+	// PlantFlags.
+	ExcludeFromWatering bool
+	Quarantined         bool
 }
 
 // NewPlant creates a new Plant instance with the specified parameters and validates all inputs.
@@ -53,6 +123,7 @@ type Plant struct {
 //   - GrowthStage: 0.0 (seed stage)
 //   - Alive: true
 //   - CreatedAt: current timestamp
+//   - Germination: started if the plant type has GerminationTicks
 //   - error: An error if any validation fails, including:
 //   - Empty id or sectionID
 //   - Invalid saturation values (outside 0.0-1.0 range)
@@ -75,34 +146,13 @@ func NewPlant(id string, plantType PlantType, sectionID string, initialSaturatio
 	if initialSaturation < 0 || initialSaturation > 1 {
 		return nil, errors.New("initial saturation must be between 0.0 and 1.0")
 	}
-	if plantType.Name == "" {
-		return nil, errors.New("plant type must have a name")
-	}
-	if plantType.OptimalSaturation < 0 || plantType.OptimalSaturation > 1 {
-		return nil, errors.New("plant type optimal saturation must be between 0.0 and 1.0")
-	}
-	if plantType.MinSaturation < 0 || plantType.MinSaturation > 1 {
-		return nil, errors.New("plant type min saturation must be between 0.0 and 1.0")
-	}
-	if plantType.MaxSaturation < 0 || plantType.MaxSaturation > 1 {
-		return nil, errors.New("plant type max saturation must be between 0.0 and 1.0")
-	}
-	if plantType.BaseGrowthRate < 0 || plantType.BaseGrowthRate > 1 {
-		return nil, errors.New("plant type base growth rate must be between 0.0 and 1.0")
-	}
-	if plantType.SaturationDepletion < 0 || plantType.SaturationDepletion > 1 {
-		return nil, errors.New("plant type saturation depletion rate must be between 0.0 and 1.0")
-	}
-	if plantType.HealthDegradationRate < 0 || plantType.HealthDegradationRate > 1 {
-		return nil, errors.New("plant type health degradation rate must be between 0.0 and 1.0")
-	}
-	if plantType.HealthEnhancementRate < 0 || plantType.HealthEnhancementRate > 1 {
-		return nil, errors.New("plant type health enhancement rate must be between 0.0 and 1.0")
+	if err := plantType.Validate(); err != nil {
+		return nil, err
 	}
 
 	plant := Plant{
 		ID:             id,
-		Type:           plantType,
+		Type:           &plantType,
 		SectionID:      sectionID,
 		SoilSaturation: initialSaturation,
 		Health:         1.0,
@@ -110,31 +160,103 @@ func NewPlant(id string, plantType PlantType, sectionID string, initialSaturatio
 		Alive:          true,
 		CreatedAt:      time.Now(),
 	}
+	if plantType.GerminationTicks > 0 {
+		plant.Germination = &Germination{}
+	}
 
 	return &plant, nil
 }
 
+// Validate checks that the plant type has a name, that every rate, chance
+// and saturation level is between 0.0 and 1.0, that a germinating type has a
+// germination range, that a temperature range, if set, is not empty and that
+// the growth parameters are valid, see GrowthParams.Validate.
+func (t PlantType) Validate() error {
+	if t.Name == "" {
+		return errors.New("plant type must have a name")
+	}
+	if t.OptimalSaturation < 0 || t.OptimalSaturation > 1 {
+		return errors.New("plant type optimal saturation must be between 0.0 and 1.0")
+	}
+	if t.MinSaturation < 0 || t.MinSaturation > 1 {
+		return errors.New("plant type min saturation must be between 0.0 and 1.0")
+	}
+	if t.MaxSaturation < 0 || t.MaxSaturation > 1 {
+		return errors.New("plant type max saturation must be between 0.0 and 1.0")
+	}
+	if t.BaseGrowthRate < 0 || t.BaseGrowthRate > 1 {
+		return errors.New("plant type base growth rate must be between 0.0 and 1.0")
+	}
+	if t.SaturationDepletion < 0 || t.SaturationDepletion > 1 {
+		return errors.New("plant type saturation depletion rate must be between 0.0 and 1.0")
+	}
+	if t.HealthDegradationRate < 0 || t.HealthDegradationRate > 1 {
+		return errors.New("plant type health degradation rate must be between 0.0 and 1.0")
+	}
+	if t.HealthEnhancementRate < 0 || t.HealthEnhancementRate > 1 {
+		return errors.New("plant type health enhancement rate must be between 0.0 and 1.0")
+	}
+	if t.RootDepth < 0 || t.RootDepth > 1 {
+		return errors.New("plant type root depth must be between 0.0 and 1.0")
+	}
+	if (t.MinTemperature != 0 || t.MaxTemperature != 0) && t.MinTemperature >= t.MaxTemperature {
+		return errors.New("plant type min temperature must be below max temperature")
+	}
+	if t.GerminationTicks < 0 {
+		return errors.New("plant type germination ticks cannot be negative")
+	}
+	if t.GerminationMinSaturation < 0 || t.GerminationMaxSaturation > 1 || t.GerminationMinSaturation > t.GerminationMaxSaturation {
+		return errors.New("plant type germination saturation range must be within 0.0 and 1.0")
+	}
+	if t.GerminationFailure < 0 || t.GerminationFailure > 1 {
+		return errors.New("plant type germination failure must be between 0.0 and 1.0")
+	}
+	if t.SalinityTolerance < 0 || t.SalinityTolerance > 1 {
+		return errors.New("plant type salinity tolerance must be between 0.0 and 1.0")
+	}
+	if t.ThermalShockTolerance < 0 {
+		return errors.New("plant type thermal shock tolerance cannot be negative")
+	}
+	if err := t.growth().Validate(); err != nil {
+		return errors.New("plant type " + err.Error())
+	}
+	return nil
+}
+
+// Deprecated: use DefaultGrowthParams.SlowFactor, or the Growth of the plant
+// type.
 const GROWTH_SLOW_FACTOR = 1.35
+
+// Deprecated: use DefaultGrowthParams.OptimalBonusFactor, or the Growth of
+// the plant type.
 const GROWTH_OPTIMAL_FACTOR = 1.25
 
 // OnTick simulates one time step in the plant's lifecycle.
 // This method is called periodically to update the plant's state based on its current conditions.
 //
 // The tick process follows this sequence:
-// 1. Skip processing if the plant is already dead
-// 2. Update health based on soil saturation:
+// 1. Skip processing if the plant is already dead, start a new diagnosis, see
+// HealthDiagnosis, and only count a tick of germination for a germinating seed
+// 2. Update health based on the soil saturation its roots reach, see RootSaturation:
 //   - Degrades health if soil saturation is outside the optimal range (MinSaturation to MaxSaturation)
 //   - Enhances health if soil saturation is within the optimal range
 //
 // 3. Check if plant dies (health <= 0) and mark as not alive if so
-// 4. Update growth stage based on health and soil conditions
-// 5. Deplete soil saturation based on the plant's consumption rate
+// 4. Update growth stage based on health, soil conditions and the light other plants leave it, see Shade
+// 5. Deplete soil saturation based on the plant's consumption rate and the drainage of its soil,
+// from the layers of a layered soil as its roots reach them, then let the surface percolate
+// 6. Count the update off the plant's modifiers, dropping those that ran out
 //
-// This method modifies the plant's Health, GrowthStage, SoilSaturation, and potentially Alive fields.
+// This method modifies the plant's Health, Diagnosis, GrowthStage, SoilSaturation, DeepSaturation, Modifiers and potentially Alive and DeathCause fields.
 func (p *Plant) OnTick() {
 	if !p.Alive {
 		return
 	}
+	p.Diagnosis = HealthDiagnosis{}
+	if p.Germination != nil {
+		p.germinate()
+		return
+	}
 	if outOfOptimalSaturationRange(p) {
 		degradeHealth(p)
 	} else {
@@ -142,11 +264,187 @@ func (p *Plant) OnTick() {
 	}
 
 	if p.Health <= 0 {
-		p.Alive = false
+		cause := DeathByDrought
+		if p.RootSaturation() > p.Type.MaxSaturation {
+			cause = DeathByWaterlogging
+		}
+		p.die(cause)
 		return
 	}
 	updateGrowthStage(p)
 	updateSoilSaturation(p)
+	p.expireModifiers()
+}
+
+// Clone returns a deep copy of the plant that shares no state with it. The
+// clone shares the plant type, which never changes.
+func (p *Plant) Clone() *Plant {
+	clone := *p
+	clone.Tags = slices.Clone(p.Tags)
+	clone.Modifiers = slices.Clone(p.Modifiers)
+	if p.Germination != nil {
+		germination := *p.Germination
+		clone.Germination = &germination
+	}
+	if p.Soil != nil {
+		soil := *p.Soil
+		clone.Soil = &soil
+	}
+	if p.Position != nil {
+		position := *p.Position
+		clone.Position = &position
+	}
+	return &clone
+}
+
+// PlantFlags changes the flags of a plant: those that are set replace the
+// plant's, those that are nil are left as they are.
+type PlantFlags struct {
+	ExcludeFromWatering *bool
+	Quarantined         *bool
+}
+
+// SetFlags sets the flags of the plant that flags sets.
+func (p *Plant) SetFlags(flags PlantFlags) {
+	if flags.ExcludeFromWatering != nil {
+		p.ExcludeFromWatering = *flags.ExcludeFromWatering
+	}
+	if flags.Quarantined != nil {
+		p.Quarantined = *flags.Quarantined
+	}
+}
+
+// HasTag reports whether the plant carries the given tag.
+func (p *Plant) HasTag(tag string) bool {
+	return slices.Contains(p.Tags, tag)
+}
+
+// AddWater increases the plant's soil saturation by amount, capping it at 1.0.
+// It returns the runoff: the part of amount the soil could not absorb because
+// it was already saturated. With a soil type, only its retention of amount
+// reaches the soil and the rest drains away, which is not runoff.
+// Non-positive amounts are ignored.
+func (p *Plant) AddWater(amount float64) float64 {
+	if amount <= 0 {
+		return 0
+	}
+	if p.Soil != nil {
+		amount *= p.Soil.Retention
+	}
+	saturation := p.SoilSaturation + amount
+	if saturation > 1 {
+		p.SoilSaturation = 1
+		return saturation - 1
+	}
+	p.SoilSaturation = saturation
+	return 0
+}
+
+// Frost takes damage off the plant's health for a tick of frost, killing it
+// when its health runs out. Dead and frost tolerant plants are unharmed.
+func (p *Plant) Frost(damage float64) {
+	if !p.Alive || p.Type.FrostTolerance {
+		return
+	}
+	p.loseHealth(FactorTemperature, damage)
+	if p.Health <= 0 {
+		p.die(DeathByFrost)
+	}
+}
+
+// Salt takes damage off the plant's health for a tick in soil of the given
+// salinity: damage for each unit of salinity above the tolerance of its
+// type, killing it when its health runs out. Dead plants and plants of types
+// without a tolerance are unharmed.
+func (p *Plant) Salt(salinity, damage float64) {
+	tolerance := p.Type.SalinityTolerance
+	if !p.Alive || tolerance == 0 || salinity <= tolerance {
+		return
+	}
+	p.loseHealth(FactorSalinity, (salinity-tolerance)*damage)
+	if p.Health <= 0 {
+		p.die(DeathBySalinity)
+	}
+}
+
+// ThermalShock takes damage off the plant's health once for a watering with
+// water difference degrees Celsius warmer or colder than its soil: damage
+// for each degree above the tolerance of its type, killing it when its
+// health runs out. Dead plants and plants of types without a tolerance are
+// unharmed.
+func (p *Plant) ThermalShock(difference, damage float64) {
+	tolerance := p.Type.ThermalShockTolerance
+	if !p.Alive || tolerance == 0 || difference <= tolerance {
+		return
+	}
+	p.loseHealth(FactorTemperature, (difference-tolerance)*damage)
+	if p.Health <= 0 {
+		p.die(DeathByThermalShock)
+	}
+}
+
+// Evaporate dries the soil out by factor times the plant's normal depletion
+// per tick, on top of what OnTick depletes. Dead plants are left as they are.
+func (p *Plant) Evaporate(factor float64) {
+	if !p.Alive || factor <= 0 {
+		return
+	}
+	p.SoilSaturation = math.Max(p.SoilSaturation-factor*p.depletion(), 0)
+}
+
+// BoostGrowth adds boost times the plant's base growth rate to its growth
+// stage, on top of what OnTick grows, capping it at 1.0. Like OnTick, it
+// leaves dead plants, germinating seeds and plants too unhealthy to grow as
+// they are.
+func (p *Plant) BoostGrowth(boost float64) {
+	if !p.Alive || boost <= 0 || p.Health < p.Type.growth().MinHealth || p.Germination != nil {
+		return
+	}
+	p.GrowthStage = math.Min(p.GrowthStage+boost*p.Type.BaseGrowthRate, 1)
+}
+
+// RootShare returns the share of its water the plant draws from the deep
+// layer of a layered soil: the RootDepth of its type, reached as it grows.
+// It is 0 in a single layered soil.
+func (p *Plant) RootShare() float64 {
+	if p.Soil == nil || !p.Soil.Layered() {
+		return 0
+	}
+	return p.Type.RootDepth * p.GrowthStage
+}
+
+// RootSaturation returns the soil saturation the plant's roots reach: the
+// saturation of its layers weighted by its RootShare. It is the
+// SoilSaturation of a single layered soil.
+func (p *Plant) RootSaturation() float64 {
+	share := p.RootShare()
+	if share == 0 {
+		return p.SoilSaturation
+	}
+	return (1-share)*p.SoilSaturation + share*p.DeepSaturation
+}
+
+// SaturationAt returns the saturation of the given soil layer. A single
+// layered soil has its SoilSaturation at every depth.
+func (p *Plant) SaturationAt(depth SoilDepth) float64 {
+	if depth == Deep && p.Soil != nil && p.Soil.Layered() {
+		return p.DeepSaturation
+	}
+	return p.SoilSaturation
+}
+
+// depletion returns how much the soil saturation depletes per tick: the
+// plant type's depletion, scaled by the drainage of its soil type and the
+// plant's modifiers.
+func (p *Plant) depletion() float64 {
+	depletion := p.Type.SaturationDepletion
+	if p.Soil != nil {
+		depletion *= p.Soil.Drainage
+	}
+	for _, m := range p.Modifiers {
+		depletion *= m.Depletion
+	}
+	return depletion
 }
 
 func (p *Plant) String() string {
@@ -156,32 +454,53 @@ func (p *Plant) String() string {
 }
 
 func outOfOptimalSaturationRange(p *Plant) bool {
-	return (p.SoilSaturation < p.Type.MinSaturation) || (p.SoilSaturation > p.Type.MaxSaturation)
+	saturation := p.RootSaturation()
+	return (saturation < p.Type.MinSaturation) || (saturation > p.Type.MaxSaturation)
 }
 
 func degradeHealth(p *Plant) {
-	p.Health = math.Max(p.Health-p.Type.HealthDegradationRate, 0)
+	factor := FactorTooDry
+	if p.RootSaturation() > p.Type.MaxSaturation {
+		factor = FactorTooWet
+	}
+	p.loseHealth(factor, p.Type.HealthDegradationRate)
 }
 
 func enhanceHealth(p *Plant) {
-	p.Health = math.Min(p.Health+p.Type.HealthEnhancementRate, 1)
+	rate := p.Type.HealthEnhancementRate
+	for _, m := range p.Modifiers {
+		rate *= m.Enhancement
+	}
+	p.Health = math.Min(p.Health+rate, 1)
 }
 
 func updateGrowthStage(p *Plant) {
-	if p.Health < 0.3 {
+	params := p.Type.growth()
+	if p.Health < params.MinHealth {
 		return // no growth
 	}
 
 	growthRate := p.Type.BaseGrowthRate // start with base rate
-	if p.Health < 0.5 {
-		growthRate /= GROWTH_SLOW_FACTOR // SLOWER growth
+	if p.Health < params.SlowHealth {
+		growthRate /= params.SlowFactor // SLOWER growth
 	}
-	if math.Abs(p.SoilSaturation-p.Type.OptimalSaturation) < 0.15 {
-		growthRate *= GROWTH_OPTIMAL_FACTOR // BONUS growth (near optimal)
+	if math.Abs(p.RootSaturation()-p.Type.OptimalSaturation) < params.OptimalTolerance {
+		growthRate *= params.OptimalBonusFactor // BONUS growth (near optimal)
+	}
+	if p.Shade > 0 {
+		growthRate *= 1 - p.Shade // SLOWER growth in the shade
 	}
 	p.GrowthStage = math.Min(p.GrowthStage+growthRate, 1) // Cap at 1.0
 }
 
 func updateSoilSaturation(p *Plant) {
-	p.SoilSaturation = math.Max(p.SoilSaturation-p.Type.SaturationDepletion, 0)
+	depletion, share := p.depletion(), p.RootShare()
+	p.SoilSaturation = math.Max(p.SoilSaturation-(1-share)*depletion, 0)
+	if p.Soil == nil || !p.Soil.Layered() {
+		return
+	}
+	p.DeepSaturation = math.Max(p.DeepSaturation-share*depletion, 0)
+	seepage := math.Min(p.Soil.Percolation*p.SoilSaturation, 1-p.DeepSaturation)
+	p.SoilSaturation -= seepage
+	p.DeepSaturation += seepage
 }
@@ -34,7 +34,7 @@ func TestHealthDegradation(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			plant := &Plant{
-				Type: PlantType{
+				Type: &PlantType{
 					MinSaturation:         0.3,
 					MaxSaturation:         0.7,
 					HealthDegradationRate: 0.05,
@@ -71,7 +71,7 @@ func TestHealthEnhancement(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			plant := &Plant{
-				Type: PlantType{
+				Type: &PlantType{
 					MinSaturation:         0.3,
 					MaxSaturation:         0.7,
 					HealthEnhancementRate: 0.05,
@@ -105,7 +105,7 @@ func TestGrowthStages_NoGrowthBelowThreshold(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			plant := &Plant{
-				Type: PlantType{
+				Type: &PlantType{
 					BaseGrowthRate: 0.1,
 					MinSaturation:  0.3,
 					MaxSaturation:  0.7,
@@ -140,7 +140,7 @@ func TestGrowthStages_ReducedGrowthAtLowHealth(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			plant := &Plant{
-				Type: PlantType{
+				Type: &PlantType{
 					BaseGrowthRate: tt.baseGrowthRate,
 					MinSaturation:  0.3,
 					MaxSaturation:  0.7,
@@ -178,7 +178,7 @@ func TestGrowthStages_NormalGrowth(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			plant := &Plant{
-				Type: PlantType{
+				Type: &PlantType{
 					BaseGrowthRate:    tt.baseGrowthRate,
 					OptimalSaturation: tt.optimalSaturation,
 					MinSaturation:     0.2,
@@ -219,7 +219,7 @@ func TestGrowthStages_BonusGrowth(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			plant := &Plant{
-				Type: PlantType{
+				Type: &PlantType{
 					BaseGrowthRate:    tt.baseGrowthRate,
 					OptimalSaturation: tt.optimalSaturation,
 					MinSaturation:     0.2,
@@ -259,7 +259,7 @@ func TestGrowthStages_CapAt1(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			plant := &Plant{
-				Type: PlantType{
+				Type: &PlantType{
 					BaseGrowthRate:    0.2,
 					OptimalSaturation: tt.optimalSaturation,
 					MinSaturation:     0.2,
@@ -309,7 +309,7 @@ func TestDeath_HealthDegradesToZero(t *testing.T) {
 	plant := &Plant{
 		Health:         0.01,
 		SoilSaturation: 0.0,
-		Type: PlantType{
+		Type: &PlantType{
 			OptimalSaturation:     0.6,
 			MinSaturation:         0.3,
 			MaxSaturation:         0.7,
@@ -342,7 +342,7 @@ func TestHealthClamps_ClampTo1(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			plant := &Plant{
-				Type: PlantType{
+				Type: &PlantType{
 					MinSaturation:         0.3,
 					MaxSaturation:         0.7,
 					HealthDegradationRate: 0.1,
@@ -384,7 +384,7 @@ func TestHealthClamps_ClampTo0(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			plant := &Plant{
-				Type: PlantType{
+				Type: &PlantType{
 					MinSaturation:         0.3,
 					MaxSaturation:         0.7,
 					HealthDegradationRate: 0.1,
@@ -413,7 +413,7 @@ func TestSaturationClamps_ClampTo0(t *testing.T) {
 
 	plant := &Plant{
 		SoilSaturation: 0.02,
-		Type: PlantType{
+		Type: &PlantType{
 			SaturationDepletion: 0.05,
 		},
 		Health: 0.5,
@@ -431,3 +431,227 @@ func TestSaturationClamps_ClampTo0(t *testing.T) {
 			plant.SoilSaturation)
 	}
 }
+
+func TestAddWater(t *testing.T) {
+	tests := []struct {
+		name               string
+		initialSaturation  float64
+		soil               *SoilType
+		amount             float64
+		expectedSaturation float64
+		expectedRunoff     float64
+	}{
+		{"absorbs all water", 0.4, nil, 0.3, 0.7, 0},
+		{"caps at 1 and returns runoff", 0.8, nil, 0.5, 1.0, 0.3},
+		{"ignores negative amount", 0.4, nil, -0.2, 0.4, 0},
+		{"drains what the soil does not retain", 0.4, &SoilType{Retention: 0.5, Drainage: 1}, 0.4, 0.6, 0},
+		{"returns runoff of the retained water", 0.8, &SoilType{Retention: 0.5, Drainage: 1}, 0.6, 1.0, 0.1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plant := &Plant{SoilSaturation: tt.initialSaturation, Soil: tt.soil}
+
+			runoff := plant.AddWater(tt.amount)
+
+			if !almostEqual(plant.SoilSaturation, tt.expectedSaturation) {
+				t.Errorf("expected saturation %.2f, got %.2f", tt.expectedSaturation, plant.SoilSaturation)
+			}
+			if !almostEqual(runoff, tt.expectedRunoff) {
+				t.Errorf("expected runoff %.2f, got %.2f", tt.expectedRunoff, runoff)
+			}
+		})
+	}
+}
+
+func TestFrost(t *testing.T) {
+	tests := []struct {
+		name           string
+		tolerant       bool
+		alive          bool
+		health         float64
+		expectedHealth float64
+		expectedAlive  bool
+	}{
+		{"damages intolerant plant", false, true, 0.5, 0.3, true},
+		{"kills intolerant plant", false, true, 0.15, 0, false},
+		{"spares tolerant plant", true, true, 0.5, 0.5, true},
+		{"ignores dead plant", false, false, 0.5, 0.5, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plant := &Plant{Type: &PlantType{FrostTolerance: tt.tolerant}, Health: tt.health, Alive: tt.alive}
+
+			plant.Frost(0.2)
+
+			if !almostEqual(plant.Health, tt.expectedHealth) || plant.Alive != tt.expectedAlive {
+				t.Errorf("expected health %.2f and alive %v, got %.2f and %v", tt.expectedHealth, tt.expectedAlive, plant.Health, plant.Alive)
+			}
+		})
+	}
+}
+
+func TestEvaporate(t *testing.T) {
+	plant := &Plant{Type: &PlantType{SaturationDepletion: 0.1}, SoilSaturation: 0.5, Alive: true}
+	plant.Evaporate(2)
+	if !almostEqual(plant.SoilSaturation, 0.3) {
+		t.Errorf("expected saturation 0.30, got %.2f", plant.SoilSaturation)
+	}
+	plant.Evaporate(5)
+	if plant.SoilSaturation != 0 {
+		t.Errorf("expected saturation to stop at 0, got %.2f", plant.SoilSaturation)
+	}
+}
+
+func TestBoostGrowth(t *testing.T) {
+	tests := []struct {
+		name           string
+		health         float64
+		alive          bool
+		growth         float64
+		expectedGrowth float64
+	}{
+		{"boosts growing plant", 0.8, true, 0.5, 0.52},
+		{"caps at 1", 0.8, true, 0.99, 1},
+		{"skips unhealthy plant", 0.2, true, 0.5, 0.5},
+		{"skips dead plant", 0.8, false, 0.5, 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plant := &Plant{Type: &PlantType{BaseGrowthRate: 0.1}, Health: tt.health, Alive: tt.alive, GrowthStage: tt.growth}
+
+			plant.BoostGrowth(0.2)
+
+			if !almostEqual(plant.GrowthStage, tt.expectedGrowth) {
+				t.Errorf("expected growth stage %.2f, got %.2f", tt.expectedGrowth, plant.GrowthStage)
+			}
+		})
+	}
+}
+
+func TestSoilDrainage(t *testing.T) {
+	tests := []struct {
+		name               string
+		soil               *SoilType
+		expectedSaturation float64
+	}{
+		{"plain soil", nil, 0.4},
+		{"fast draining soil", &SoilType{Retention: 1, Drainage: 2}, 0.3},
+		{"slow draining soil", &SoilType{Retention: 1, Drainage: 0.5}, 0.45},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plant := &Plant{
+				Type:           &PlantType{MinSaturation: 0.3, MaxSaturation: 0.7, SaturationDepletion: 0.1},
+				Health:         1.0,
+				SoilSaturation: 0.5,
+				Alive:          true,
+				Soil:           tt.soil,
+			}
+
+			plant.OnTick()
+
+			if !almostEqual(plant.SoilSaturation, tt.expectedSaturation) {
+				t.Errorf("expected saturation %.2f, got %.2f", tt.expectedSaturation, plant.SoilSaturation)
+			}
+		})
+	}
+}
+
+func TestClone_SharesNoState(t *testing.T) {
+	plant := &Plant{ID: "plant-1", SoilSaturation: 0.4, Tags: []string{"north"}, Soil: &SoilType{Name: "Sand", Retention: 0.7, Drainage: 1.6}}
+
+	clone := plant.Clone()
+	clone.SoilSaturation = 0.9
+	clone.Tags[0] = "south"
+	clone.Soil.Drainage = 2
+
+	if plant.SoilSaturation != 0.4 || plant.Tags[0] != "north" || plant.Soil.Drainage != 1.6 {
+		t.Errorf("expected original plant to be unchanged, got saturation %.2f, tags %v and soil %+v", plant.SoilSaturation, plant.Tags, plant.Soil)
+	}
+}
+
+func TestPresetPlantTypes_AreValid(t *testing.T) {
+	types := PresetPlantTypes()
+	if len(types) == 0 {
+		t.Fatal("expected a non-empty preset catalog")
+	}
+	for i, plantType := range types {
+		if err := plantType.Validate(); err != nil {
+			t.Errorf("%s: expected a valid preset, got %v", plantType.Name, err)
+		}
+		if i > 0 && types[i-1].Name >= plantType.Name {
+			t.Errorf("expected presets ordered by name, got %s before %s", types[i-1].Name, plantType.Name)
+		}
+		if preset, ok := PresetPlantType(plantType.Name); !ok || preset != plantType {
+			t.Errorf("%s: expected the preset to be found by name", plantType.Name)
+		}
+	}
+	if _, ok := PresetPlantType("Cactus"); ok {
+		t.Error("expected no preset for an unknown name")
+	}
+}
+
+func TestPlant_ThermalShock(t *testing.T) {
+	tests := []struct {
+		name       string
+		tolerance  float64
+		difference float64
+		health     float64
+		expected   float64
+	}{
+		{"within the tolerance", 5, 5, 0.8, 0.8},
+		{"above the tolerance", 5, 15, 0.8, 0.7},
+		{"no tolerance", 0, 30, 0.8, 0.8},
+		{"health runs out", 5, 200, 0.8, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plantType := PlantType{ThermalShockTolerance: tt.tolerance}
+			plant := &Plant{Health: tt.health, Type: &plantType, Alive: true}
+			plant.ThermalShock(tt.difference, 0.01)
+			if math.Abs(plant.Health-tt.expected) > 1e-9 {
+				t.Errorf("expected health %.3f, got %.3f", tt.expected, plant.Health)
+			}
+		})
+	}
+}
+
+func TestDeath_Cause(t *testing.T) {
+	plantType := PlantType{
+		OptimalSaturation:     0.6,
+		MinSaturation:         0.3,
+		MaxSaturation:         0.7,
+		HealthDegradationRate: 0.08,
+		GerminationTicks:      1,
+		GerminationFailure:    1,
+		SalinityTolerance:     0.2,
+		ThermalShockTolerance: 5,
+	}
+	tests := []struct {
+		name     string
+		kill     func(p *Plant)
+		expected DeathCause
+	}{
+		{"drought", func(p *Plant) { p.SoilSaturation = 0; p.OnTick() }, DeathByDrought},
+		{"waterlogging", func(p *Plant) { p.SoilSaturation = 1; p.OnTick() }, DeathByWaterlogging},
+		{"frost", func(p *Plant) { p.Frost(0.5) }, DeathByFrost},
+		{"disease", func(p *Plant) { p.Disease = Disease{Stage: Symptomatic}; p.Sicken(0, 0.5, 0) }, DeathByDisease},
+		{"germination", func(p *Plant) { p.Germination = &Germination{Ticks: 1}; p.Sprout(0) }, DeathByGermination},
+		{"salinity", func(p *Plant) { p.Salt(0.6, 0.1) }, DeathBySalinity},
+		{"thermal shock", func(p *Plant) { p.ThermalShock(15, 0.01) }, DeathByThermalShock},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plant := &Plant{Health: 0.01, Type: &plantType, Alive: true}
+			tt.kill(plant)
+			if plant.Alive || plant.DeathCause != tt.expected {
+				t.Errorf("expected the plant to die of %s, got alive=%v and cause %q", tt.expected, plant.Alive, plant.DeathCause)
+			}
+		})
+	}
+}
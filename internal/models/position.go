@@ -0,0 +1,51 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Position is where a plant or a sensor stands within its section, X along
+// its width and Y along its depth from one corner, in the unit of the
+// section's Dimensions.
+type Position struct {
+	X float64
+	Y float64
+}
+
+// Distance returns the Euclidean distance between two positions.
+func (p Position) Distance(q Position) float64 {
+	return math.Hypot(p.X-q.X, p.Y-q.Y)
+}
+
+// Dimensions is the size of a section. The zero value is a section without
+// dimensions, whose positions are only bound to be non-negative.
+type Dimensions struct {
+	Width float64
+	Depth float64
+}
+
+// Validate checks that the width and depth are not negative, and that both
+// or neither are set.
+func (d Dimensions) Validate() error {
+	if d.Width < 0 || d.Depth < 0 {
+		return errors.New("section dimensions cannot be negative")
+	}
+	if (d.Width == 0) != (d.Depth == 0) {
+		return errors.New("section dimensions need both a width and a depth")
+	}
+	return nil
+}
+
+// Contains checks that a position falls within the section, edges included.
+// Returns an error if it does not.
+func (d Dimensions) Contains(p Position) error {
+	if p.X < 0 || p.Y < 0 {
+		return fmt.Errorf("position (%g, %g) cannot be negative", p.X, p.Y)
+	}
+	if d != (Dimensions{}) && (p.X > d.Width || p.Y > d.Depth) {
+		return fmt.Errorf("position (%g, %g) is outside the %g by %g section", p.X, p.Y, d.Width, d.Depth)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package models
+
+import "testing"
+
+func TestDimensions_Contains(t *testing.T) {
+	tests := []struct {
+		name       string
+		dimensions Dimensions
+		position   Position
+		err        string
+	}{
+		{"inside", Dimensions{Width: 10, Depth: 5}, Position{X: 4, Y: 2}, ""},
+		{"on the corner", Dimensions{Width: 10, Depth: 5}, Position{X: 10, Y: 5}, ""},
+		{"on the origin", Dimensions{Width: 10, Depth: 5}, Position{}, ""},
+		{"past the width", Dimensions{Width: 10, Depth: 5}, Position{X: 10.5, Y: 2}, "position (10.5, 2) is outside the 10 by 5 section"},
+		{"past the depth", Dimensions{Width: 10, Depth: 5}, Position{X: 1, Y: 6}, "position (1, 6) is outside the 10 by 5 section"},
+		{"negative", Dimensions{Width: 10, Depth: 5}, Position{X: -1, Y: 2}, "position (-1, 2) cannot be negative"},
+		{"no dimensions", Dimensions{}, Position{X: 1000, Y: 1000}, ""},
+		{"negative without dimensions", Dimensions{}, Position{X: 1, Y: -0.5}, "position (1, -0.5) cannot be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.dimensions.Contains(tt.position)
+			if tt.err == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.err != "" && (err == nil || err.Error() != tt.err) {
+				t.Fatalf("expected error %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestDimensions_Validate(t *testing.T) {
+	tests := []struct {
+		dimensions Dimensions
+		err        string
+	}{
+		{Dimensions{}, ""},
+		{Dimensions{Width: 2, Depth: 3}, ""},
+		{Dimensions{Width: -2, Depth: 3}, "section dimensions cannot be negative"},
+		{Dimensions{Width: 2}, "section dimensions need both a width and a depth"},
+	}
+	for _, tt := range tests {
+		err := tt.dimensions.Validate()
+		if tt.err == "" && err != nil {
+			t.Errorf("%+v: unexpected error: %v", tt.dimensions, err)
+		}
+		if tt.err != "" && (err == nil || err.Error() != tt.err) {
+			t.Errorf("%+v: expected error %q, got %v", tt.dimensions, tt.err, err)
+		}
+	}
+}
+
+func TestPosition_Distance(t *testing.T) {
+	if d := (Position{X: 1, Y: 1}).Distance(Position{X: 4, Y: 5}); d != 5 {
+		t.Errorf("expected a distance of 5, got %v", d)
+	}
+}
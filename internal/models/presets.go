@@ -0,0 +1,111 @@
+package models
+
+import (
+	"maps"
+	"slices"
+)
+
+// presets is the built-in plant type catalog. Configs can use these types
+// by name without defining them, or extend them with overrides.
+var presets = map[string]PlantType{
+	"Basil": {
+		Name:                  "Basil",
+		OptimalSaturation:     0.6,
+		MinSaturation:         0.35,
+		MaxSaturation:         0.8,
+		BaseGrowthRate:        0.06,
+		SaturationDepletion:   0.05,
+		HealthDegradationRate: 0.07,
+		HealthEnhancementRate: 0.03,
+		MinTemperature:        10,
+		MaxTemperature:        35,
+	},
+	"Kale": {
+		Name:                  "Kale",
+		OptimalSaturation:     0.65,
+		MinSaturation:         0.35,
+		MaxSaturation:         0.85,
+		BaseGrowthRate:        0.05,
+		SaturationDepletion:   0.04,
+		HealthDegradationRate: 0.05,
+		HealthEnhancementRate: 0.04,
+		FrostTolerance:        true,
+		MinTemperature:        -10,
+		MaxTemperature:        28,
+	},
+	"Lettuce": {
+		Name:                  "Lettuce",
+		OptimalSaturation:     0.7,
+		MinSaturation:         0.4,
+		MaxSaturation:         0.9,
+		BaseGrowthRate:        0.08,
+		SaturationDepletion:   0.05,
+		HealthDegradationRate: 0.06,
+		HealthEnhancementRate: 0.04,
+		MinTemperature:        2,
+		MaxTemperature:        28,
+	},
+	"Mint": {
+		Name:                  "Mint",
+		OptimalSaturation:     0.75,
+		MinSaturation:         0.45,
+		MaxSaturation:         0.95,
+		BaseGrowthRate:        0.07,
+		SaturationDepletion:   0.06,
+		HealthDegradationRate: 0.05,
+		HealthEnhancementRate: 0.04,
+		MinTemperature:        2,
+		MaxTemperature:        32,
+	},
+	"Pepper": {
+		Name:                  "Pepper",
+		OptimalSaturation:     0.55,
+		MinSaturation:         0.3,
+		MaxSaturation:         0.75,
+		BaseGrowthRate:        0.04,
+		SaturationDepletion:   0.03,
+		HealthDegradationRate: 0.06,
+		HealthEnhancementRate: 0.02,
+		MinTemperature:        12,
+		MaxTemperature:        35,
+	},
+	"Strawberry": {
+		Name:                  "Strawberry",
+		OptimalSaturation:     0.65,
+		MinSaturation:         0.4,
+		MaxSaturation:         0.85,
+		BaseGrowthRate:        0.05,
+		SaturationDepletion:   0.04,
+		HealthDegradationRate: 0.07,
+		HealthEnhancementRate: 0.03,
+		MinTemperature:        2,
+		MaxTemperature:        30,
+	},
+	"Tomato": {
+		Name:                  "Tomato",
+		OptimalSaturation:     0.6,
+		MinSaturation:         0.3,
+		MaxSaturation:         0.8,
+		BaseGrowthRate:        0.05,
+		SaturationDepletion:   0.04,
+		HealthDegradationRate: 0.08,
+		HealthEnhancementRate: 0.03,
+		MinTemperature:        10,
+		MaxTemperature:        35,
+	},
+}
+
+// PresetPlantType returns the built-in plant type with the given name.
+func PresetPlantType(name string) (PlantType, bool) {
+	t, ok := presets[name]
+	return t, ok
+}
+
+// PresetPlantTypes returns the built-in plant types, ordered by name.
+func PresetPlantTypes() []PlantType {
+	types := make([]PlantType, 0, len(presets))
+	for _, name := range slices.Sorted(maps.Keys(presets)) {
+		types = append(types, presets[name])
+	}
+	return types
+}
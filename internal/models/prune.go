@@ -0,0 +1,93 @@
+package models
+
+import (
+	"errors"
+	"slices"
+)
+
+// MaxPruneFraction bounds the fraction of its growth a plant can be pruned
+// back by.
+const MaxPruneFraction = 0.5
+
+// Pruned is the Source of the modifier pruning puts on a plant.
+const Pruned = "pruned"
+
+// Modifier temporarily multiplies a plant's saturation depletion by
+// Depletion and its health enhancement rate by Enhancement, for the plant's
+// next Ticks updates. Source names what put it on the plant.
+type Modifier struct {
+	Source      string
+	Depletion   float64
+	Enhancement float64
+	Ticks       int
+}
+
+// PruneEffect is the modifier pruning puts on a plant: for Ticks ticks, its
+// saturation depletion is multiplied by Depletion and its health enhancement
+// rate by Enhancement.
+type PruneEffect struct {
+	Ticks       int
+	Depletion   float64
+	Enhancement float64
+}
+
+// DefaultPruneEffect is the effect of pruning when none is configured: a
+// day's worth of hourly ticks of 20% less depletion and 50% more recovery.
+var DefaultPruneEffect = PruneEffect{Ticks: 24, Depletion: 0.8, Enhancement: 1.5}
+
+// Validate checks that the effect lasts at least a tick, that Depletion is
+// between 0.0 and 1.0 and that Enhancement is at least 1.0, so that pruning
+// only ever helps the plant in exchange for its growth.
+func (e PruneEffect) Validate() error {
+	if e.Ticks < 1 {
+		return errors.New("prune effect must last at least 1 tick")
+	}
+	if e.Depletion < 0 || e.Depletion > 1 {
+		return errors.New("prune depletion factor must be between 0.0 and 1.0")
+	}
+	if e.Enhancement < 1 {
+		return errors.New("prune enhancement factor must be at least 1.0")
+	}
+	return nil
+}
+
+// Prune cuts the plant's growth stage back by fraction of itself and, in
+// exchange, puts the modifier of effect on it, replacing that of an earlier
+// pruning. Returns an error if:
+// - fraction is outside 0.0-MaxPruneFraction
+// - the plant is dead or still at the seed stage
+func (p *Plant) Prune(fraction float64, effect PruneEffect) error {
+	if fraction < 0 || fraction > MaxPruneFraction {
+		return errors.New("prune fraction must be between 0.0 and 0.5")
+	}
+	if !p.Alive {
+		return errors.New("cannot prune a dead plant: " + p.ID)
+	}
+	if p.GrowthStage == 0 {
+		return errors.New("cannot prune a plant at the seed stage: " + p.ID)
+	}
+	p.GrowthStage -= p.GrowthStage * fraction
+	p.Modifiers = slices.DeleteFunc(p.Modifiers, func(m Modifier) bool { return m.Source == Pruned })
+	p.Modifiers = append(p.Modifiers, Modifier{
+		Source:      Pruned,
+		Depletion:   effect.Depletion,
+		Enhancement: effect.Enhancement,
+		Ticks:       effect.Ticks,
+	})
+	return nil
+}
+
+// expireModifiers counts an update off every modifier and drops those that
+// have run out.
+func (p *Plant) expireModifiers() {
+	if len(p.Modifiers) == 0 {
+		return
+	}
+	for i := range p.Modifiers {
+		p.Modifiers[i].Ticks--
+	}
+	p.Modifiers = slices.DeleteFunc(p.Modifiers, func(m Modifier) bool { return m.Ticks <= 0 })
+	if len(p.Modifiers) == 0 {
+		p.Modifiers = nil
+	}
+}
@@ -0,0 +1,94 @@
+package models
+
+import "testing"
+
+func TestPlant_Prune(t *testing.T) {
+	effect := PruneEffect{Ticks: 2, Depletion: 0.5, Enhancement: 2}
+	plant := &Plant{
+		Type:           &PlantType{MinSaturation: 0.2, MaxSaturation: 0.9, SaturationDepletion: 0.04, HealthEnhancementRate: 0.01},
+		Health:         0.5,
+		GrowthStage:    0.4,
+		SoilSaturation: 0.6,
+		Alive:          true,
+	}
+	if err := plant.Prune(0.25, effect); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !almostEqual(plant.GrowthStage, 0.3) {
+		t.Errorf("expected a quarter of the 0.4 growth cut back, got %.3f", plant.GrowthStage)
+	}
+
+	// Half the depletion and twice the recovery, for 2 ticks.
+	plant.OnTick()
+	if !almostEqual(plant.SoilSaturation, 0.58) || !almostEqual(plant.Health, 0.52) {
+		t.Errorf("expected 0.58 saturation and 0.52 health, got %+v", plant)
+	}
+	if len(plant.Modifiers) != 1 || plant.Modifiers[0].Ticks != 1 {
+		t.Errorf("expected the modifier to have a tick left, got %+v", plant.Modifiers)
+	}
+	plant.OnTick()
+	if plant.Modifiers != nil {
+		t.Errorf("expected the modifier to run out after 2 ticks, got %+v", plant.Modifiers)
+	}
+	plant.OnTick()
+	if !almostEqual(plant.SoilSaturation, 0.52) || !almostEqual(plant.Health, 0.55) {
+		t.Errorf("expected the plant's own rates back, got %+v", plant)
+	}
+
+	// Pruning again replaces the modifier instead of stacking it.
+	plant.Prune(0.1, effect)
+	plant.Prune(0.1, effect)
+	if len(plant.Modifiers) != 1 || plant.Modifiers[0].Ticks != 2 {
+		t.Errorf("expected a single fresh modifier, got %+v", plant.Modifiers)
+	}
+	if clone := plant.Clone(); &clone.Modifiers[0] == &plant.Modifiers[0] {
+		t.Error("expected the clone not to share the modifiers")
+	}
+}
+
+func TestPlant_PruneRefused(t *testing.T) {
+	tests := []struct {
+		name     string
+		plant    Plant
+		fraction float64
+		errorMsg string
+	}{
+		{"fraction above 0.5", Plant{ID: "p", GrowthStage: 0.5, Alive: true}, 0.6, "prune fraction must be between 0.0 and 0.5"},
+		{"negative fraction", Plant{ID: "p", GrowthStage: 0.5, Alive: true}, -0.1, "prune fraction must be between 0.0 and 0.5"},
+		{"dead plant", Plant{ID: "p", GrowthStage: 0.5}, 0.2, "cannot prune a dead plant: p"},
+		{"seed", Plant{ID: "p", Alive: true}, 0.2, "cannot prune a plant at the seed stage: p"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.plant.Prune(tt.fraction, DefaultPruneEffect)
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+			if tt.plant.Modifiers != nil {
+				t.Errorf("expected a refused pruning to leave no modifier, got %+v", tt.plant.Modifiers)
+			}
+		})
+	}
+}
+
+func TestPruneEffect_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		effect  PruneEffect
+		wantErr bool
+	}{
+		{"default", DefaultPruneEffect, false},
+		{"no ticks", PruneEffect{Depletion: 0.8, Enhancement: 1.5}, true},
+		{"depletion above 1", PruneEffect{Ticks: 5, Depletion: 1.2, Enhancement: 1.5}, true},
+		{"enhancement below 1", PruneEffect{Ticks: 5, Depletion: 0.8, Enhancement: 0.5}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.effect.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
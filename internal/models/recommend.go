@@ -0,0 +1,55 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// RecommendSchedule proposes a watering schedule for the plants of a type,
+// selected by PlantType, that keeps their soil within the saturation range of
+// the type:
+//   - TargetSaturation is the OptimalSaturation of the type
+//   - CheckInterval is the most ticks such that one tick more of
+//     SaturationDepletion fits both below the target, above MinSaturation, and
+//     above it, below MaxSaturation
+//   - WaterAmount is that many ticks plus one of depletion for a single plant,
+//     so that soil below the target climbs back to it instead of settling
+//     where it is, and never floods past MaxSaturation
+//   - each event waters over a single tick of tickInterval
+//
+// The schedule assumes plain soil and plants without modifiers; soils that
+// drain faster and modifiers that raise the depletion may dry out between
+// checks. A schedule for several plants needs their WaterAmount added up,
+// since events split it across the plants they water. Returns an error if:
+// - the plant type is invalid, see PlantType.Validate
+// - the tick interval is not positive
+// - the type does not deplete its soil, or depletes it too fast for two
+// ticks of depletion to fit in its saturation range around the optimum
+func RecommendSchedule(pt PlantType, tickInterval time.Duration) (WateringSchedule, error) {
+	if err := pt.Validate(); err != nil {
+		return WateringSchedule{}, err
+	}
+	if tickInterval <= 0 {
+		return WateringSchedule{}, errors.New("tick interval must be positive")
+	}
+	depletion := pt.SaturationDepletion
+	if depletion <= 0 {
+		return WateringSchedule{}, errors.New("plant type must deplete its soil to recommend a schedule: " + pt.Name)
+	}
+	headroom := min(pt.OptimalSaturation-pt.MinSaturation, pt.MaxSaturation-pt.OptimalSaturation)
+	// The tolerance keeps a range of exactly so many ticks, such as 0.2 for
+	// 0.04, from rounding down a tick.
+	ticks := int(math.Floor(headroom/depletion + 1e-9))
+	if ticks < 2 {
+		return WateringSchedule{}, errors.New("plant type depletes its soil too fast to recommend a schedule: " + pt.Name)
+	}
+	return WateringSchedule{
+		PlantType:        pt.Name,
+		TargetSaturation: pt.OptimalSaturation,
+		CheckInterval:    ticks - 1,
+		WaterAmount:      float64(ticks) * depletion,
+		Duration:         tickInterval,
+		Enabled:          true,
+	}, nil
+}
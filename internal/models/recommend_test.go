@@ -0,0 +1,95 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecommendSchedule_Tomato(t *testing.T) {
+	tomato, _ := PresetPlantType("Tomato")
+	schedule, err := RecommendSchedule(tomato, 4*time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 0.2 of headroom above the optimum is 5 ticks of 0.04.
+	expected := WateringSchedule{PlantType: "Tomato", TargetSaturation: 0.6, CheckInterval: 4, WaterAmount: 0.2, Duration: 4 * time.Second, Enabled: true}
+	if schedule.PlantType != expected.PlantType || schedule.TargetSaturation != expected.TargetSaturation ||
+		schedule.CheckInterval != expected.CheckInterval || !almostEqual(schedule.WaterAmount, expected.WaterAmount) ||
+		schedule.Duration != expected.Duration || !schedule.Enabled {
+		t.Errorf("expected %+v, got %+v", expected, schedule)
+	}
+}
+
+// TestRecommendSchedule_KeepsSaturationInRange waters a plant of every preset
+// the way a watering controller runs its recommended schedule, checking on
+// every CheckInterval ticks after the plant drew its water and topping it up
+// when below the target.
+func TestRecommendSchedule_KeepsSaturationInRange(t *testing.T) {
+	for _, plantType := range PresetPlantTypes() {
+		schedule, err := RecommendSchedule(plantType, time.Second)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", plantType.Name, err)
+		}
+		starts := map[string]float64{
+			"optimal":   plantType.OptimalSaturation,
+			"dry":       (plantType.MinSaturation + plantType.OptimalSaturation) / 2,
+			"saturated": plantType.MaxSaturation,
+		}
+		for name, start := range starts {
+			t.Run(plantType.Name+"/"+name, func(t *testing.T) {
+				plant, err := NewPlant("plant", plantType, "section-A", start)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				lowest := start
+				for tick := range 1000 {
+					plant.OnTick()
+					lowest = min(lowest, plant.SoilSaturation)
+					if tick%schedule.CheckInterval == 0 && plant.SoilSaturation < schedule.TargetSaturation {
+						plant.AddWater(schedule.WaterAmount)
+					}
+					if plant.SoilSaturation > plantType.MaxSaturation+1e-9 {
+						t.Fatalf("tick %d: expected the soil not to flood past %.2f, got %.3f", tick, plantType.MaxSaturation, plant.SoilSaturation)
+					}
+				}
+				if lowest < plantType.MinSaturation {
+					t.Errorf("expected the soil never to drop below %.2f, got down to %.3f", plantType.MinSaturation, lowest)
+				}
+				if !plant.Alive || plant.Health != 1 {
+					t.Errorf("expected a healthy plant, got %v", plant)
+				}
+			})
+		}
+	}
+}
+
+func TestRecommendSchedule_Errors(t *testing.T) {
+	tomato, _ := PresetPlantType("Tomato")
+	dry := tomato
+	dry.SaturationDepletion = 0
+	thirsty := tomato
+	thirsty.SaturationDepletion = 0.15
+	invalid := tomato
+	invalid.OptimalSaturation = 1.5
+
+	tests := []struct {
+		name         string
+		plantType    PlantType
+		tickInterval time.Duration
+		errorMsg     string
+	}{
+		{"invalid type", invalid, time.Second, "plant type optimal saturation must be between 0.0 and 1.0"},
+		{"no tick interval", tomato, 0, "tick interval must be positive"},
+		{"no depletion", dry, time.Second, "plant type must deplete its soil to recommend a schedule: Tomato"},
+		{"fast depletion", thirsty, time.Second, "plant type depletes its soil too fast to recommend a schedule: Tomato"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := RecommendSchedule(tt.plantType, tt.tickInterval)
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
@@ -1,6 +1,12 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
 
 // SensorType represents the different types of sensors available in the system.
 type SensorType string
@@ -14,19 +20,333 @@ const (
 	Light SensorType = "light"
 	// Humidity sensors measure relative humidity (0.0 to 1.0).
 	Humidity SensorType = "humidity"
+	// CO2 sensors measure the CO2 level in ppm.
+	CO2 SensorType = "co2"
+	// Salinity sensors measure the soil salinity (0.0 to 1.0).
+	Salinity SensorType = "salinity"
+	// SoilTemperature sensors measure the root-zone temperature in Celsius.
+	SoilTemperature SensorType = "soil_temperature"
+	// WaterTemperature sensors measure the irrigation water temperature in
+	// Celsius.
+	WaterTemperature SensorType = "water_temperature"
+)
+
+// Validate checks that the sensor type is one of the known types.
+func (t SensorType) Validate() error {
+	switch t {
+	case SoilMoisture, Temperature, Light, Humidity, CO2, Salinity, SoilTemperature, WaterTemperature:
+		return nil
+	}
+	return errors.New("sensor type must be soil_moisture, temperature, light, humidity, co2, salinity, soil_temperature or water_temperature: " + string(t))
+}
+
+// SoilDepth is the soil layer a soil moisture sensor reads.
+type SoilDepth string
+
+const (
+	// Surface sensors read the surface layer, the only one of a single
+	// layered soil.
+	Surface SoilDepth = "surface"
+	// Deep sensors read the deep layer of a layered soil, and the surface
+	// of a single layered one.
+	Deep SoilDepth = "deep"
 )
 
+// Validate checks that the depth is surface, deep or empty, for the surface.
+func (d SoilDepth) Validate() error {
+	if d != "" && d != Surface && d != Deep {
+		return errors.New("soil depth must be surface or deep: " + string(d))
+	}
+	return nil
+}
+
 // Sensor represents a physical sensor device in the greenhouse.
 // Each sensor monitors a specific section and measures one environmental factor.
+// Noise is the standard deviation of the normally distributed error added to
+// its readings; a zero Noise reads exactly. Depth is the soil layer a soil
+// moisture sensor reads, the surface when empty, and Filter the plants of
+// the section it reads, all of them when empty. A wireless sensor has a
+// Battery; a nil Battery is a wired sensor that never runs out. The
+// simulation samples the sensor every SampleInterval ticks, every tick when
+// zero. RateWindow is the number of ticks the rate of change of its readings
+// is smoothed over, DefaultRateWindow when zero, see SensorReading. A sensor
+// with WindowTicks reads the time-weighted mean of what it measured over the
+// last WindowTicks ticks rather than what it measures at the moment of the
+// sample, so that what happened between two samples still shows. Position
+// is where the sensor stands in its section, nil when it is not placed.
 type Sensor struct {
-	ID        string
-	Type      SensorType
-	SectionID string
+	ID             string
+	Type           SensorType
+	SectionID      string
+	Noise          float64
+	Depth          SoilDepth
+	Filter         PlantFilter
+	Battery        *Battery
+	SampleInterval int
+	RateWindow     int
+	WindowTicks    int
+	Position       *Position
+}
+
+// DefaultRateWindow is the number of ticks the rate of change of the
+// readings of a sensor is smoothed over when Sensor.RateWindow is zero.
+const DefaultRateWindow = 5
+
+// MaxRateWindow bounds Sensor.RateWindow.
+const MaxRateWindow = 100
+
+// MaxWindowTicks bounds Sensor.WindowTicks.
+const MaxWindowTicks = 100
+
+// Window returns the number of ticks the rate of change of the readings is
+// smoothed over, see RateWindow.
+func (s *Sensor) Window() int {
+	if s.RateWindow == 0 {
+		return DefaultRateWindow
+	}
+	return s.RateWindow
+}
+
+// Clone returns a copy of the sensor that shares nothing with it.
+func (s *Sensor) Clone() *Sensor {
+	clone := *s
+	clone.Filter.PlantIDs = slices.Clone(s.Filter.PlantIDs)
+	if s.Battery != nil {
+		battery := *s.Battery
+		clone.Battery = &battery
+	}
+	if s.Position != nil {
+		position := *s.Position
+		clone.Position = &position
+	}
+	return &clone
+}
+
+// Battery is the battery of a wireless sensor. Level is its charge, from 1.0
+// when full to 0.0 when depleted, and Drain the charge every sample takes.
+type Battery struct {
+	Level float64
+	Drain float64
+}
+
+// batteryEpsilon is the charge below which a battery counts as depleted, so
+// that the rounding of repeated drains does not leave a last sliver.
+const batteryEpsilon = 1e-9
+
+// Sample takes the charge of a sample off the battery, down to 0.0.
+func (b *Battery) Sample() {
+	b.Level -= b.Drain
+	if b.Level < batteryEpsilon {
+		b.Level = 0
+	}
+}
+
+// Depleted reports whether the battery has no charge left.
+func (b *Battery) Depleted() bool {
+	return b.Level <= 0
+}
+
+// SamplesLeft returns the number of samples the battery lasts before it is
+// depleted.
+func (b *Battery) SamplesLeft() int {
+	if b.Drain <= 0 || b.Depleted() {
+		return 0
+	}
+	return int(math.Ceil(b.Level/b.Drain - batteryEpsilon))
+}
+
+// PlantFilter narrows the plants a soil moisture sensor reads to those of a
+// plant type, carrying a tag or listed by ID. A plant must match every
+// criterion that is set, so an empty filter matches every plant.
+type PlantFilter struct {
+	Type     string
+	Tag      string
+	PlantIDs []string
+}
+
+// Empty reports whether the filter sets no criterion.
+func (f PlantFilter) Empty() bool {
+	return f.Type == "" && f.Tag == "" && len(f.PlantIDs) == 0
+}
+
+// Matches reports whether the plant matches every criterion of the filter.
+func (f PlantFilter) Matches(p *Plant) bool {
+	if f.Type != "" && p.Type.Name != f.Type {
+		return false
+	}
+	if f.Tag != "" && !p.HasTag(f.Tag) {
+		return false
+	}
+	return len(f.PlantIDs) == 0 || slices.Contains(f.PlantIDs, p.ID)
+}
+
+// Check returns an error naming the plant type, tag or first plant ID of the
+// filter that none of the plants has.
+func (f PlantFilter) Check(plants []*Plant) error {
+	if f.Type != "" && !slices.ContainsFunc(plants, func(p *Plant) bool { return p.Type.Name == f.Type }) {
+		return errors.New("filter refers to an unknown plant type: " + f.Type)
+	}
+	if f.Tag != "" && !slices.ContainsFunc(plants, func(p *Plant) bool { return p.HasTag(f.Tag) }) {
+		return errors.New("filter refers to an unknown tag: " + f.Tag)
+	}
+	for _, id := range f.PlantIDs {
+		if !slices.ContainsFunc(plants, func(p *Plant) bool { return p.ID == id }) {
+			return errors.New("filter refers to an unknown plant: " + id)
+		}
+	}
+	return nil
+}
+
+// SensorOption sets an optional field of a sensor built by NewSensor.
+type SensorOption func(*Sensor)
+
+// WithNoise sets the standard deviation of the noise added to the readings.
+func WithNoise(noise float64) SensorOption {
+	return func(s *Sensor) { s.Noise = noise }
+}
+
+// WithDepth sets the soil layer a soil moisture sensor reads.
+func WithDepth(depth SoilDepth) SensorOption {
+	return func(s *Sensor) { s.Depth = depth }
+}
+
+// WithPlantFilter sets the plants of the section a soil moisture sensor
+// reads.
+func WithPlantFilter(filter PlantFilter) SensorOption {
+	return func(s *Sensor) { s.Filter = filter }
+}
+
+// WithBattery makes the sensor wireless, with a battery at level that drains
+// by drain every sample.
+func WithBattery(level, drain float64) SensorOption {
+	return func(s *Sensor) { s.Battery = &Battery{Level: level, Drain: drain} }
+}
+
+// WithSampleInterval sets the number of ticks between the samples of the
+// sensor.
+func WithSampleInterval(ticks int) SensorOption {
+	return func(s *Sensor) { s.SampleInterval = ticks }
+}
+
+// WithRateWindow sets the number of ticks the rate of change of the readings
+// is smoothed over.
+func WithRateWindow(ticks int) SensorOption {
+	return func(s *Sensor) { s.RateWindow = ticks }
+}
+
+// WithWindowTicks sets the number of ticks the readings are averaged over.
+func WithWindowTicks(ticks int) SensorOption {
+	return func(s *Sensor) { s.WindowTicks = ticks }
+}
+
+// WithPosition places the sensor in its section.
+func WithPosition(position Position) SensorOption {
+	return func(s *Sensor) { s.Position = &position }
+}
+
+// NewSensor creates a sensor of the given type watching a section, with the
+// options applied, and validates it, see Sensor.Validate.
+func NewSensor(id string, sensorType SensorType, sectionID string, opts ...SensorOption) (*Sensor, error) {
+	sensor := &Sensor{ID: id, Type: sensorType, SectionID: sectionID}
+	for _, opt := range opts {
+		opt(sensor)
+	}
+	if err := sensor.Validate(); err != nil {
+		return nil, err
+	}
+	return sensor, nil
+}
+
+// Validate checks the sensor. Returns an error if:
+// - the ID or the section ID is empty
+// - the type is unknown, see SensorType.Validate
+// - the noise is negative
+// - the depth is unknown, see SoilDepth.Validate, or set on a sensor that
+// does not measure soil moisture
+// - the filter lists an empty plant ID, or is set on a sensor that does not
+// measure soil moisture
+// - the battery level is not between 0.0 and 1.0, or its drain is not above
+// 0.0 and at most 1.0
+// - the sample interval is negative
+// - the rate window is negative or above MaxRateWindow
+// - the reading window is negative or above MaxWindowTicks
+// - the position is negative
+func (s *Sensor) Validate() error {
+	if s.ID == "" {
+		return errors.New("sensor ID cannot be empty")
+	}
+	if s.SectionID == "" {
+		return errors.New("sensor section ID cannot be empty")
+	}
+	if err := s.Type.Validate(); err != nil {
+		return fmt.Errorf("sensor %s: %w", s.ID, err)
+	}
+	if s.Noise < 0 {
+		return errors.New("sensor noise cannot be negative: " + s.ID)
+	}
+	if err := s.Depth.Validate(); err != nil {
+		return fmt.Errorf("sensor %s: %w", s.ID, err)
+	}
+	if s.Depth != "" && s.Type != SoilMoisture {
+		return errors.New("only soil moisture sensors have a depth: " + s.ID)
+	}
+	if slices.Contains(s.Filter.PlantIDs, "") {
+		return errors.New("sensor filter plant ID cannot be empty: " + s.ID)
+	}
+	if !s.Filter.Empty() && s.Type != SoilMoisture {
+		return errors.New("only soil moisture sensors have a plant filter: " + s.ID)
+	}
+	if s.Battery != nil {
+		if s.Battery.Level < 0 || s.Battery.Level > 1 {
+			return errors.New("sensor battery level must be between 0.0 and 1.0: " + s.ID)
+		}
+		if s.Battery.Drain <= 0 || s.Battery.Drain > 1 {
+			return errors.New("sensor battery drain must be above 0.0 and at most 1.0: " + s.ID)
+		}
+	}
+	if s.SampleInterval < 0 {
+		return errors.New("sensor sample interval cannot be negative: " + s.ID)
+	}
+	if s.RateWindow < 0 || s.RateWindow > MaxRateWindow {
+		return fmt.Errorf("sensor rate window must be between 0 and %d ticks: %s", MaxRateWindow, s.ID)
+	}
+	if s.WindowTicks < 0 || s.WindowTicks > MaxWindowTicks {
+		return fmt.Errorf("sensor window must be between 0 and %d ticks: %s", MaxWindowTicks, s.ID)
+	}
+	if s.Position != nil {
+		if err := (Dimensions{}).Contains(*s.Position); err != nil {
+			return fmt.Errorf("sensor %s: %w", s.ID, err)
+		}
+	}
+	return nil
+}
+
+// Samples reports whether the simulation samples the sensor on tick.
+func (s *Sensor) Samples(tick int) bool {
+	return s.SampleInterval <= 1 || tick%s.SampleInterval == 0
 }
 
-// SensorReading represents a single measurement taken by a sensor.
+// SensorReading represents a single measurement taken by a sensor on Tick.
+// Battery is the level the battery of a wireless sensor was left at by the
+// sample, nil for a wired sensor. DeltaPerTick is how much Value changed per
+// tick since the previous sample, and Rate the same over the rate window of
+// the sensor, from its oldest sample in the window, see Sensor.RateWindow.
+// Both are in units per simulated tick, so they do not depend on the speed
+// of the simulation. The first sample of a sensor has nothing to compare to:
+// its HasDelta is false and its DeltaPerTick and Rate are zero. The Value of
+// a sensor with a window is the time-weighted mean over the window, see
+// Sensor.WindowTicks, and Instant what it measured on Tick, nil for the other
+// sensors; PartialWindow is set when the sensor has not measured for as long
+// as the window yet, and the mean covers the ticks it has.
 type SensorReading struct {
-	SensorID  string
-	Timestamp time.Time
-	Value     float64
+	SensorID      string
+	Tick          int
+	Timestamp     time.Time
+	Value         float64
+	Battery       *float64
+	HasDelta      bool
+	DeltaPerTick  float64
+	Rate          float64
+	Instant       *float64
+	PartialWindow bool
 }
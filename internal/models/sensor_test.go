@@ -0,0 +1,112 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewSensor(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		sensorType SensorType
+		sectionID  string
+		opts       []SensorOption
+		errorMsg   string
+	}{
+		{"valid sensor", "sensor-1", SoilMoisture, "section-A", nil, ""},
+		{"with noise and depth", "sensor-1", SoilMoisture, "section-A", []SensorOption{WithNoise(0.1), WithDepth(Deep)}, ""},
+		{"empty sensor ID", "", SoilMoisture, "section-A", nil, "sensor ID cannot be empty"},
+		{"empty section ID", "sensor-1", SoilMoisture, "", nil, "sensor section ID cannot be empty"},
+		{"unknown type", "sensor-1", "pressure", "section-A", nil, "sensor sensor-1: sensor type must be soil_moisture, temperature, light, humidity, co2, salinity, soil_temperature or water_temperature: pressure"},
+		{"negative noise", "sensor-1", SoilMoisture, "section-A", []SensorOption{WithNoise(-0.1)}, "sensor noise cannot be negative: sensor-1"},
+		{"unknown depth", "sensor-1", SoilMoisture, "section-A", []SensorOption{WithDepth("bedrock")}, "sensor sensor-1: soil depth must be surface or deep: bedrock"},
+		{"depth on another type", "sensor-1", Temperature, "section-A", []SensorOption{WithDepth(Deep)}, "only soil moisture sensors have a depth: sensor-1"},
+		{"with a plant filter", "sensor-1", SoilMoisture, "section-A", []SensorOption{WithPlantFilter(PlantFilter{Type: "Tomato", PlantIDs: []string{"tomato-1"}})}, ""},
+		{"empty filter plant ID", "sensor-1", SoilMoisture, "section-A", []SensorOption{WithPlantFilter(PlantFilter{PlantIDs: []string{""}})}, "sensor filter plant ID cannot be empty: sensor-1"},
+		{"filter on another type", "sensor-1", Humidity, "section-A", []SensorOption{WithPlantFilter(PlantFilter{Tag: "bed"})}, "only soil moisture sensors have a plant filter: sensor-1"},
+		{"with a battery", "sensor-1", Temperature, "section-A", []SensorOption{WithBattery(1, 0.01), WithSampleInterval(5)}, ""},
+		{"battery above full", "sensor-1", Temperature, "section-A", []SensorOption{WithBattery(1.2, 0.01)}, "sensor battery level must be between 0.0 and 1.0: sensor-1"},
+		{"battery without drain", "sensor-1", Temperature, "section-A", []SensorOption{WithBattery(1, 0)}, "sensor battery drain must be above 0.0 and at most 1.0: sensor-1"},
+		{"negative sample interval", "sensor-1", Temperature, "section-A", []SensorOption{WithSampleInterval(-1)}, "sensor sample interval cannot be negative: sensor-1"},
+		{"rate window too long", "sensor-1", SoilMoisture, "section-A", []SensorOption{WithRateWindow(101)}, "sensor rate window must be between 0 and 100 ticks: sensor-1"},
+		{"negative window", "sensor-1", SoilMoisture, "section-A", []SensorOption{WithWindowTicks(-1)}, "sensor window must be between 0 and 100 ticks: sensor-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sensor, err := NewSensor(tt.id, tt.sensorType, tt.sectionID, tt.opts...)
+			if tt.errorMsg != "" {
+				if err == nil || err.Error() != tt.errorMsg {
+					t.Fatalf("expected error '%s', got %v", tt.errorMsg, err)
+				}
+				if sensor != nil {
+					t.Errorf("expected no sensor on error, got %+v", sensor)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sensor.ID != tt.id || sensor.Type != tt.sensorType || sensor.SectionID != tt.sectionID {
+				t.Errorf("expected the sensor to keep its fields, got %+v", sensor)
+			}
+		})
+	}
+}
+
+func TestBattery_Drain(t *testing.T) {
+	tests := []struct {
+		name    string
+		battery Battery
+		samples int
+		level   float64
+	}{
+		{"full", Battery{Level: 1, Drain: 0.1}, 3, 0.7},
+		{"exactly depleted", Battery{Level: 1, Drain: 0.1}, 10, 0},
+		{"partly charged", Battery{Level: 0.5, Drain: 0.02}, 20, 0.1},
+		{"drained past empty", Battery{Level: 0.25, Drain: 0.1}, 5, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			battery := tt.battery
+			left := battery.SamplesLeft()
+			for range tt.samples {
+				battery.Sample()
+			}
+			if math.Abs(battery.Level-tt.level) > 1e-9 {
+				t.Errorf("expected level %.2f after %d samples, got %v", tt.level, tt.samples, battery.Level)
+			}
+			if depleted := tt.level == 0; battery.Depleted() != depleted {
+				t.Errorf("expected depleted %v, got %v", depleted, battery.Depleted())
+			}
+			if expected := max(0, left-tt.samples); battery.SamplesLeft() != expected {
+				t.Errorf("expected %d samples left, got %d", expected, battery.SamplesLeft())
+			}
+		})
+	}
+}
+
+func TestSensor_Samples(t *testing.T) {
+	every := &Sensor{ID: "sensor-1"}
+	fifth := &Sensor{ID: "sensor-2", SampleInterval: 5}
+	for tick := range 11 {
+		if !every.Samples(tick) {
+			t.Errorf("expected a sample on tick %d", tick)
+		}
+		if fifth.Samples(tick) != (tick%5 == 0) {
+			t.Errorf("expected a sample every 5 ticks, got %v on tick %d", fifth.Samples(tick), tick)
+		}
+	}
+}
+
+func TestNewSensor_AppliesOptions(t *testing.T) {
+	sensor, err := NewSensor("sensor-1", SoilMoisture, "section-A", WithNoise(0.2), WithDepth(Deep))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sensor.Noise != 0.2 || sensor.Depth != Deep {
+		t.Errorf("expected noise 0.2 at deep soil, got %+v", sensor)
+	}
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"errors"
+	"maps"
+	"slices"
+)
+
+// MaxDrainage bounds SoilType.Drainage.
+const MaxDrainage = 5.0
+
+// SoilType describes how the soil of a section holds water. Retention is the
+// fraction of the water applied that the soil absorbs, the rest draining away
+// at once; Drainage multiplies how fast the saturation depletes through
+// evaporation and drainage. Loam retains all water and drains at the plant's
+// own depletion rate, like plants without a soil type.
+//
+// A soil with a Percolation has two layers: the plant's SoilSaturation is the
+// surface layer, which watering fills and evaporation dries, and its
+// DeepSaturation the deep layer. Every tick, Percolation of the surface
+// layer's water seeps down into the deep layer, as far as it can hold it.
+type SoilType struct {
+	Name        string
+	Retention   float64 // 0.0 (exclusive) to 1.0
+	Drainage    float64 // 0.0 (exclusive) to MaxDrainage
+	Percolation float64 // 0.0 for a single layer, up to 1.0
+}
+
+// soilPresets is the built-in soil type catalog.
+var soilPresets = map[string]SoilType{
+	"Sand": {Name: "Sand", Retention: 0.7, Drainage: 1.6},
+	"Loam": {Name: "Loam", Retention: 1, Drainage: 1},
+	"Clay": {Name: "Clay", Retention: 1, Drainage: 0.6},
+}
+
+// PresetSoilType returns the built-in soil type with the given name.
+func PresetSoilType(name string) (SoilType, bool) {
+	s, ok := soilPresets[name]
+	return s, ok
+}
+
+// PresetSoilTypes returns the built-in soil types, ordered by name.
+func PresetSoilTypes() []SoilType {
+	soils := make([]SoilType, 0, len(soilPresets))
+	for _, name := range slices.Sorted(maps.Keys(soilPresets)) {
+		soils = append(soils, soilPresets[name])
+	}
+	return soils
+}
+
+// Layered reports whether the soil has a surface and a deep layer.
+func (s SoilType) Layered() bool {
+	return s.Percolation > 0
+}
+
+// Validate checks that the soil type has a name, a retention above 0.0 and
+// up to 1.0, a drainage above 0.0 and up to MaxDrainage and a percolation
+// between 0.0 and 1.0.
+func (s SoilType) Validate() error {
+	if s.Name == "" {
+		return errors.New("soil type must have a name")
+	}
+	if s.Retention <= 0 || s.Retention > 1 {
+		return errors.New("soil retention must be above 0.0 and at most 1.0: " + s.Name)
+	}
+	if s.Drainage <= 0 || s.Drainage > MaxDrainage {
+		return errors.New("soil drainage must be above 0.0 and at most 5.0: " + s.Name)
+	}
+	if s.Percolation < 0 || s.Percolation > 1 {
+		return errors.New("soil percolation must be between 0.0 and 1.0: " + s.Name)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package models
+
+import "testing"
+
+func TestPresetSoilTypes_AreValid(t *testing.T) {
+	soils := PresetSoilTypes()
+	if len(soils) != 3 {
+		t.Fatalf("expected sand, loam and clay, got %v", soils)
+	}
+	for i, soil := range soils {
+		if err := soil.Validate(); err != nil {
+			t.Errorf("%s: expected a valid preset, got %v", soil.Name, err)
+		}
+		if i > 0 && soils[i-1].Name >= soil.Name {
+			t.Errorf("expected presets ordered by name, got %s before %s", soils[i-1].Name, soil.Name)
+		}
+		if preset, ok := PresetSoilType(soil.Name); !ok || preset != soil {
+			t.Errorf("%s: expected the preset to be found by name", soil.Name)
+		}
+	}
+	if loam, _ := PresetSoilType("Loam"); loam.Retention != 1 || loam.Drainage != 1 {
+		t.Errorf("expected loam to behave like plain soil, got %+v", loam)
+	}
+}
+
+func TestSoilType_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		soil    SoilType
+		wantErr bool
+	}{
+		{"valid", SoilType{Name: "Peat", Retention: 0.9, Drainage: 0.8}, false},
+		{"bounds are inclusive", SoilType{Name: "Peat", Retention: 1, Drainage: MaxDrainage}, false},
+		{"no name", SoilType{Retention: 0.9, Drainage: 0.8}, true},
+		{"zero retention", SoilType{Name: "Peat", Drainage: 0.8}, true},
+		{"retention above 1", SoilType{Name: "Peat", Retention: 1.1, Drainage: 0.8}, true},
+		{"zero drainage", SoilType{Name: "Peat", Retention: 0.9}, true},
+		{"negative drainage", SoilType{Name: "Peat", Retention: 0.9, Drainage: -1}, true},
+		{"drainage above the maximum", SoilType{Name: "Peat", Retention: 0.9, Drainage: MaxDrainage + 0.1}, true},
+		{"layered", SoilType{Name: "Peat", Retention: 0.9, Drainage: 0.8, Percolation: 1}, false},
+		{"percolation above 1", SoilType{Name: "Peat", Retention: 0.9, Drainage: 0.8, Percolation: 1.5}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.soil.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSoilLayers(t *testing.T) {
+	soil := &SoilType{Retention: 1, Drainage: 1, Percolation: 0.5}
+	plant := &Plant{
+		Type:           &PlantType{MinSaturation: 0.3, MaxSaturation: 0.7, SaturationDepletion: 0.1, RootDepth: 0.8},
+		Health:         1,
+		GrowthStage:    0.5,
+		SoilSaturation: 0.2,
+		DeepSaturation: 0.6,
+		Alive:          true,
+		Soil:           soil,
+	}
+	// Roots halfway grown reach 0.4 into the deep layer.
+	if !almostEqual(plant.RootShare(), 0.4) || !almostEqual(plant.RootSaturation(), 0.36) {
+		t.Errorf("expected a 0.4 root share at 0.36 saturation, got %.2f at %.2f", plant.RootShare(), plant.RootSaturation())
+	}
+	if plant.SaturationAt(Surface) != 0.2 || plant.SaturationAt(Deep) != 0.6 {
+		t.Errorf("expected the layers at 0.2 and 0.6, got %.2f and %.2f", plant.SaturationAt(Surface), plant.SaturationAt(Deep))
+	}
+
+	// 0.06 drawn from the surface and 0.04 from the deep layer, then half
+	// of the 0.14 left on the surface seeps down.
+	plant.OnTick()
+	if !almostEqual(plant.SoilSaturation, 0.07) || !almostEqual(plant.DeepSaturation, 0.63) {
+		t.Errorf("expected the layers at 0.07 and 0.63, got %.3f and %.3f", plant.SoilSaturation, plant.DeepSaturation)
+	}
+
+	plant.Soil = &SoilType{Retention: 1, Drainage: 1}
+	if plant.RootShare() != 0 || plant.RootSaturation() != plant.SoilSaturation || plant.SaturationAt(Deep) != plant.SoilSaturation {
+		t.Error("expected a single layered soil to only have its surface")
+	}
+}
+
+func TestSoilDepth_Validate(t *testing.T) {
+	for _, depth := range []SoilDepth{"", Surface, Deep} {
+		if err := depth.Validate(); err != nil {
+			t.Errorf("%q: unexpected error: %v", depth, err)
+		}
+	}
+	if err := SoilDepth("bedrock").Validate(); err == nil || err.Error() != "soil depth must be surface or deep: bedrock" {
+		t.Errorf("expected an unknown depth to be refused, got %v", err)
+	}
+}
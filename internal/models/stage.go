@@ -0,0 +1,101 @@
+package models
+
+import "fmt"
+
+// PlantStage is the stage of life of a plant, as the plant journal reports
+// it, see Plant.Stage.
+type PlantStage string
+
+const (
+	// StageSeed is a plant still germinating.
+	StageSeed PlantStage = "seed"
+	// StageSeedling is a sprouted plant below SeedlingGrowth.
+	StageSeedling PlantStage = "seedling"
+	// StageVegetative is a plant growing from SeedlingGrowth to maturity.
+	StageVegetative PlantStage = "vegetative"
+	// StageMature is a fully grown plant.
+	StageMature PlantStage = "mature"
+)
+
+// SeedlingGrowth is the growth stage a seedling becomes a vegetative plant
+// at.
+const SeedlingGrowth = 0.25
+
+// HealthBands are the health levels that split the health of plants into
+// the bands the plant journal reports, see Plant.HealthBand.
+var HealthBands = [...]float64{0.25, 0.5, 0.75}
+
+// Stress is what a plant suffers from while its roots are outside the
+// saturation range of its type, see Plant.Stress.
+type Stress string
+
+const (
+	// StressDrought is the stress of roots below the minimum saturation.
+	StressDrought Stress = "drought"
+	// StressWaterlogging is the stress of roots above the maximum saturation.
+	StressWaterlogging Stress = "waterlogging"
+)
+
+// PlantChange is the payload of the events of a significant change of a
+// single plant: what changed From and To, and the value that goes with it,
+// such as the health for a health band change.
+type PlantChange struct {
+	From  string
+	To    string
+	Value float64
+}
+
+// Stage returns the stage of life of the plant: StageSeed while it
+// germinates, then StageSeedling, StageVegetative once its growth stage
+// reaches SeedlingGrowth and StageMature once it reaches 1.0.
+func (p *Plant) Stage() PlantStage {
+	switch {
+	case p.Germination != nil:
+		return StageSeed
+	case p.GrowthStage >= 1:
+		return StageMature
+	case p.GrowthStage >= SeedlingGrowth:
+		return StageVegetative
+	}
+	return StageSeedling
+}
+
+// HealthBand returns the health band of the plant: the number of
+// HealthBands its health is at or above, 0 to len(HealthBands).
+func (p *Plant) HealthBand() int {
+	band := 0
+	for _, level := range HealthBands {
+		if p.Health >= level {
+			band++
+		}
+	}
+	return band
+}
+
+// HealthBandLabel names a health band, see Plant.HealthBand, by the health
+// range it covers, e.g. "0.50-0.75".
+func HealthBandLabel(band int) string {
+	switch {
+	case band <= 0:
+		return fmt.Sprintf("below %.2f", HealthBands[0])
+	case band >= len(HealthBands):
+		return fmt.Sprintf("%.2f and above", HealthBands[len(HealthBands)-1])
+	}
+	return fmt.Sprintf("%.2f-%.2f", HealthBands[band-1], HealthBands[band])
+}
+
+// Stress returns the stress of a living, sprouted plant whose roots are
+// outside the saturation range of its type, which costs it health every
+// tick, and "" otherwise.
+func (p *Plant) Stress() Stress {
+	if !p.Alive || p.Germination != nil {
+		return ""
+	}
+	switch saturation := p.RootSaturation(); {
+	case saturation < p.Type.MinSaturation:
+		return StressDrought
+	case saturation > p.Type.MaxSaturation:
+		return StressWaterlogging
+	}
+	return ""
+}
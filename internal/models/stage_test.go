@@ -0,0 +1,43 @@
+package models
+
+import "testing"
+
+func TestPlant_StageBandAndStress(t *testing.T) {
+	plantType := PlantType{Name: "Bean", OptimalSaturation: 0.6, MinSaturation: 0.2, MaxSaturation: 0.9}
+	tests := []struct {
+		name   string
+		change func(p *Plant)
+		stage  PlantStage
+		band   int
+		stress Stress
+	}{
+		{"new plant", func(p *Plant) {}, StageSeedling, 3, ""},
+		{"germinating", func(p *Plant) { p.Germination = &Germination{}; p.SoilSaturation = 0.1 }, StageSeed, 3, ""},
+		{"vegetative", func(p *Plant) { p.GrowthStage = SeedlingGrowth }, StageVegetative, 3, ""},
+		{"mature", func(p *Plant) { p.GrowthStage = 1 }, StageMature, 3, ""},
+		{"on a band edge", func(p *Plant) { p.Health = 0.5 }, StageSeedling, 2, ""},
+		{"weak and dry", func(p *Plant) { p.Health, p.SoilSaturation = 0.1, 0.1 }, StageSeedling, 0, StressDrought},
+		{"waterlogged", func(p *Plant) { p.SoilSaturation = 1 }, StageSeedling, 3, StressWaterlogging},
+		{"dead", func(p *Plant) { p.die(DeathByDrought); p.SoilSaturation = 0 }, StageSeedling, 3, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plant, err := NewPlant("bean", plantType, "section-A", 0.6)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.change(plant)
+			if stage, band, stress := plant.Stage(), plant.HealthBand(), plant.Stress(); stage != tt.stage || band != tt.band || stress != tt.stress {
+				t.Errorf("expected %s, band %d and stress %q, got %s, band %d and stress %q", tt.stage, tt.band, tt.stress, stage, band, stress)
+			}
+		})
+	}
+}
+
+func TestHealthBandLabel(t *testing.T) {
+	for band, expected := range []string{"below 0.25", "0.25-0.50", "0.50-0.75", "0.75 and above"} {
+		if got := HealthBandLabel(band); got != expected {
+			t.Errorf("band %d: expected %q, got %q", band, expected, got)
+		}
+	}
+}
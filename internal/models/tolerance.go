@@ -0,0 +1,68 @@
+package models
+
+import "fmt"
+
+// DefaultMinTemperature and DefaultMaxTemperature are the temperature range
+// Normalize gives plant types without one.
+const (
+	DefaultMinTemperature = 10.0
+	DefaultMaxTemperature = 30.0
+)
+
+// Normalize fills in the documented defaults of the optional fields of a
+// plant type left unset, and returns a warning describing each default it
+// filled in:
+//   - a type without a temperature range, both MinTemperature and
+//     MaxTemperature 0, gets DefaultMinTemperature to DefaultMaxTemperature
+//   - a germinating type without a germination saturation range, both
+//     GerminationMinSaturation and GerminationMaxSaturation 0, germinates
+//     between its MinSaturation and MaxSaturation, rather than only in
+//     bone-dry soil
+//
+// A type with every field set is left as it is, without warnings.
+func (t *PlantType) Normalize() []string {
+	var warnings []string
+	if t.MinTemperature == 0 && t.MaxTemperature == 0 {
+		t.MinTemperature, t.MaxTemperature = DefaultMinTemperature, DefaultMaxTemperature
+		warnings = append(warnings, fmt.Sprintf("no temperature range, defaulting to %g to %g C", t.MinTemperature, t.MaxTemperature))
+	}
+	if t.GerminationTicks > 0 && t.GerminationMinSaturation == 0 && t.GerminationMaxSaturation == 0 {
+		t.GerminationMinSaturation, t.GerminationMaxSaturation = t.MinSaturation, t.MaxSaturation
+		warnings = append(warnings, fmt.Sprintf("no germination saturation range, defaulting to the saturation range %g to %g", t.MinSaturation, t.MaxSaturation))
+	}
+	return warnings
+}
+
+// EnvironmentProfile sums up the climate of a greenhouse for
+// CompatibleWithEnvironment.
+type EnvironmentProfile struct {
+	// Name names the environment in the reasons, such as "winter".
+	Name string
+	// Temperature is the daily mean temperature in Celsius, nil when the
+	// environment does not set one.
+	Temperature *float64
+	// FrostChance is the chance of a day starting with a frost.
+	FrostChance float64
+}
+
+// CompatibleWithEnvironment reports whether plants of the type can plausibly
+// survive env, and if not, why: frosts kill plants that are not frost
+// tolerant, and a daily mean temperature outside the temperature range of
+// the type is more than they survive. A type without a temperature range,
+// see Normalize, and an environment without a temperature skip the
+// temperature check.
+func (t PlantType) CompatibleWithEnvironment(env EnvironmentProfile) (bool, []string) {
+	var reasons []string
+	if env.FrostChance > 0 && !t.FrostTolerance {
+		reasons = append(reasons, fmt.Sprintf("the %s environment has frosts on %g%% of days and the type is not frost tolerant", env.Name, env.FrostChance*100))
+	}
+	if env.Temperature != nil && (t.MinTemperature != 0 || t.MaxTemperature != 0) {
+		switch temperature := *env.Temperature; {
+		case temperature < t.MinTemperature:
+			reasons = append(reasons, fmt.Sprintf("the %s environment averages %g C, below the %g C minimum of the type", env.Name, temperature, t.MinTemperature))
+		case temperature > t.MaxTemperature:
+			reasons = append(reasons, fmt.Sprintf("the %s environment averages %g C, above the %g C maximum of the type", env.Name, temperature, t.MaxTemperature))
+		}
+	}
+	return len(reasons) == 0, reasons
+}
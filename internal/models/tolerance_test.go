@@ -0,0 +1,112 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	base := PlantType{Name: "Bean", OptimalSaturation: 0.6, MinSaturation: 0.2, MaxSaturation: 0.9, MinTemperature: 12, MaxTemperature: 30}
+	tests := []struct {
+		name     string
+		change   func(t *PlantType)
+		expected func(t *PlantType)
+		warnings []string
+	}{
+		{"complete", func(t *PlantType) {}, func(t *PlantType) {}, nil},
+		{"no temperature range", func(t *PlantType) { t.MinTemperature, t.MaxTemperature = 0, 0 },
+			func(t *PlantType) { t.MinTemperature, t.MaxTemperature = DefaultMinTemperature, DefaultMaxTemperature }, []string{"no temperature range, defaulting to 10 to 30 C"}},
+		{"cold-hardy range down to 0", func(t *PlantType) { t.MinTemperature = 0 },
+			func(t *PlantType) { t.MinTemperature = 0 }, nil},
+		{"germination without range", func(t *PlantType) { t.GerminationTicks = 5 },
+			func(t *PlantType) {
+				t.GerminationTicks, t.GerminationMinSaturation, t.GerminationMaxSaturation = 5, 0.2, 0.9
+			},
+			[]string{"no germination saturation range, defaulting to the saturation range 0.2 to 0.9"}},
+		{"germination with range", func(t *PlantType) {
+			t.GerminationTicks, t.GerminationMinSaturation, t.GerminationMaxSaturation = 5, 0.5, 0.8
+		},
+			func(t *PlantType) {
+				t.GerminationTicks, t.GerminationMinSaturation, t.GerminationMaxSaturation = 5, 0.5, 0.8
+			}, nil},
+		{"both", func(t *PlantType) { t.MinTemperature, t.MaxTemperature, t.GerminationTicks = 0, 0, 5 },
+			func(t *PlantType) {
+				t.MinTemperature, t.MaxTemperature = 10, 30
+				t.GerminationTicks, t.GerminationMinSaturation, t.GerminationMaxSaturation = 5, 0.2, 0.9
+			},
+			[]string{"no temperature range, defaulting to 10 to 30 C", "no germination saturation range, defaulting to the saturation range 0.2 to 0.9"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plantType, expected := base, base
+			tt.change(&plantType)
+			tt.expected(&expected)
+			warnings := plantType.Normalize()
+			if !reflect.DeepEqual(warnings, tt.warnings) {
+				t.Errorf("expected warnings %q, got %q", tt.warnings, warnings)
+			}
+			if plantType != expected {
+				t.Errorf("expected %+v, got %+v", expected, plantType)
+			}
+			if err := plantType.Validate(); err != nil {
+				t.Errorf("expected a valid type, got %v", err)
+			}
+			if again := plantType.Normalize(); again != nil {
+				t.Errorf("expected a normalized type to stay as it is, got %q", again)
+			}
+		})
+	}
+}
+
+func TestPresets_AreNormalized(t *testing.T) {
+	for _, preset := range PresetPlantTypes() {
+		if warnings := preset.Normalize(); warnings != nil {
+			t.Errorf("expected preset %s to set every field, got %q", preset.Name, warnings)
+		}
+	}
+}
+
+func TestValidate_TemperatureRange(t *testing.T) {
+	plantType := PlantType{Name: "Bean", MinTemperature: 20, MaxTemperature: 20}
+	if err := plantType.Validate(); err == nil || err.Error() != "plant type min temperature must be below max temperature" {
+		t.Errorf("expected a temperature range error, got %v", err)
+	}
+}
+
+func TestCompatibleWithEnvironment(t *testing.T) {
+	temperature := func(c float64) *float64 { return &c }
+	basil, _ := PresetPlantType("Basil")
+	kale, _ := PresetPlantType("Kale")
+	lettuce, _ := PresetPlantType("Lettuce")
+	unset := PlantType{Name: "Bean"}
+	winter := EnvironmentProfile{Name: "winter", Temperature: temperature(8)}
+	tests := []struct {
+		name      string
+		plantType PlantType
+		env       EnvironmentProfile
+		reasons   []string
+	}{
+		{"basil in winter", basil, winter, []string{"the winter environment averages 8 C, below the 10 C minimum of the type"}},
+		{"kale in winter", kale, winter, nil},
+		{"basil in a heat", basil, EnvironmentProfile{Name: "desert", Temperature: temperature(38)}, []string{"the desert environment averages 38 C, above the 35 C maximum of the type"}},
+		{"basil with frosts", basil, EnvironmentProfile{Name: "configured", FrostChance: 0.05}, []string{"the configured environment has frosts on 5% of days and the type is not frost tolerant"}},
+		{"kale with frosts", kale, EnvironmentProfile{Name: "configured", Temperature: temperature(2), FrostChance: 0.05}, nil},
+		{"basil with frosts in winter", basil, EnvironmentProfile{Name: "winter", Temperature: temperature(8), FrostChance: 0.5},
+			[]string{"the winter environment has frosts on 50% of days and the type is not frost tolerant", "the winter environment averages 8 C, below the 10 C minimum of the type"}},
+		{"no temperature", lettuce, EnvironmentProfile{Name: "configured"}, nil},
+		{"no temperature range", unset, winter, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, reasons := tt.plantType.CompatibleWithEnvironment(tt.env)
+			if ok != (tt.reasons == nil) {
+				t.Errorf("expected compatible=%v, got %v", tt.reasons == nil, ok)
+			}
+			if !reflect.DeepEqual(reasons, tt.reasons) {
+				t.Errorf("expected reasons %q, got %q", tt.reasons, reasons)
+			}
+		})
+	}
+}
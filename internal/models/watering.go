@@ -6,21 +6,138 @@ import "time"
 // Water is applied gradually over the specified Duration to simulate realistic
 // irrigation behavior. Events can be triggered either manually or by the automated
 // watering schedule.
+//
+// Irrigation is driven by simulation ticks only: when the event starts its
+// Duration is converted into DurationTicks with the tick interval of the
+// time, and the event then delivers an equal share of its water on each of
+// those ticks. An event therefore freezes while the simulation is paused, and
+// a later change of the tick interval does not stretch or shorten it.
+//
+// Amount is the total volume delivered by the event, expressed in saturation
+// units and split across the targeted plants. When PlantID is set the event
+// targets that single plant instead of the whole section.
 type WateringEvent struct {
+	ID        string
 	SectionID string
+	PlantID   string
 	Amount    float64
 	StartTime time.Time
 	Duration  time.Duration
-	IsManual  bool
+	// DurationTicks is the number of ticks the event delivers its water
+	// over, at least one, set once the event has started.
+	DurationTicks int
+	IsManual      bool
+	Method        IrrigationMethod
+	// Distribution decides how the water is split across the targeted plants.
+	Distribution DistributionStrategy
+	// ScheduleID is the schedule that triggered the event, empty for manual events.
+	ScheduleID string
+	// QueuedTick is the simulation tick current when the event was queued and
+	// StartedTick the tick it started delivering water, valid once started.
+	// They differ when the event waited for the tank or a free irrigation slot.
+	QueuedTick  int
+	StartedTick int
+	// Label is a free text note on a manual event, such as "pre-vacation
+	// soak", empty for scheduled events.
+	Label string
 }
 
 // WateringSchedule defines the automated watering configuration for a garden section.
 // The schedule monitors soil saturation at regular intervals and triggers watering
 // events when saturation drops below the target threshold.
+//
+// A schedule selects the plants it waters with SectionID, ZoneID, Tag and
+// PlantType; at least one must be set and every set selector must match.
+// ZoneID selects the plants of every section of the zone, see Zone. Both the
+// saturation check and the triggered watering only consider selected plants.
+// ID identifies the schedule and defaults to one derived from its selectors.
+//
+// By default a triggered event delivers the fixed WaterAmount. When Proportional
+// is set the amount is instead computed from the distance to the target on every
+// check, and WaterAmount is ignored.
 type WateringSchedule struct {
+	ID               string
 	SectionID        string
+	ZoneID           string
+	Tag              string
+	PlantType        string // PlantType.Name
 	TargetSaturation float64
 	CheckInterval    int // in ticks
 	WaterAmount      float64
+	Duration         time.Duration // how long each triggered event lasts, zero means a single tick
 	Enabled          bool
+	Proportional     *ProportionalControl
+	Method           IrrigationMethod     // how triggered events deliver water, empty means MethodDrip
+	Distribution     DistributionStrategy // how triggered events split water, empty means DistributeEven
+	// AllowedWindows restricts when triggered events may run. When set, an
+	// event only starts if its whole duration fits inside one window; a due
+	// check outside the windows is deferred until an event fits. Empty means
+	// watering is allowed at any time.
+	AllowedWindows []TimeWindow
+}
+
+// IrrigationMethod is the way a watering event delivers water to the plants.
+type IrrigationMethod string
+
+const (
+	// MethodDrip delivers water straight to the root zone.
+	MethodDrip IrrigationMethod = "drip"
+	// MethodOverhead sprays water from above; some of it evaporates or misses
+	// the pots and the section becomes slightly more humid.
+	MethodOverhead IrrigationMethod = "overhead"
+	// MethodMisting releases a fine mist that mostly raises the air humidity
+	// and moves little water into the soil.
+	MethodMisting IrrigationMethod = "misting"
+)
+
+// DistributionStrategy is the way a watering event splits its water across
+// the plants it targets. The smart strategies measure each plant's deficit
+// against the schedule's target saturation, or the plant type's optimal
+// saturation for manual events, and pass the runoff of plants that fill up
+// on to the others instead of losing it.
+type DistributionStrategy string
+
+const (
+	// DistributeEven gives every plant the same share; runoff is lost.
+	DistributeEven DistributionStrategy = "even"
+	// DistributeDeficit gives each plant a share proportional to how far it
+	// is below its target.
+	DistributeDeficit DistributionStrategy = "deficit_proportional"
+	// DistributeDriestFirst brings the driest plants up to their target
+	// before any water goes to wetter plants.
+	DistributeDriestFirst DistributionStrategy = "driest_first"
+)
+
+// TimeWindow is a span of the simulated day expressed as fractions, where 0.0
+// is midnight and 0.5 is noon. A window whose Start is after its End wraps
+// around midnight, so {0.9, 0.1} covers the night.
+type TimeWindow struct {
+	Start float64
+	End   float64
+}
+
+// Contains reports whether a time of day falls inside the window, including
+// Start and excluding End.
+func (w TimeWindow) Contains(timeOfDay float64) bool {
+	if w.Start <= w.End {
+		return timeOfDay >= w.Start && timeOfDay < w.End
+	}
+	return timeOfDay >= w.Start || timeOfDay < w.End
+}
+
+// ProportionalControl configures a proportional-integral watering response.
+// On every check the schedule computes
+//
+//	error  = TargetSaturation - measured saturation
+//	output = Gain*error + IntegralGain*sum(error)
+//
+// and, when output is positive, starts an event whose amount is output times
+// the number of watered plants, clamped to MinAmount..MaxAmount. A Gain of 1
+// therefore closes the whole gap in one event regardless of how many plants
+// share it. A zero IntegralGain gives a pure proportional controller.
+type ProportionalControl struct {
+	Gain         float64
+	IntegralGain float64
+	MinAmount    float64
+	MaxAmount    float64
 }
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"errors"
+	"slices"
+)
+
+// Zone groups sections of the greenhouse, such as the sections of one wing,
+// so they can be looked at, alerted on and watered together. A section
+// belongs to at most one zone; the greenhouse checks that across its zones.
+type Zone struct {
+	ID         string
+	Name       string
+	SectionIDs []string
+}
+
+// Clone returns a copy of the zone that shares nothing with it.
+func (z *Zone) Clone() *Zone {
+	clone := *z
+	clone.SectionIDs = slices.Clone(z.SectionIDs)
+	return &clone
+}
+
+// Has reports whether a section is a member of the zone.
+func (z *Zone) Has(sectionID string) bool {
+	return slices.Contains(z.SectionIDs, sectionID)
+}
+
+// Validate checks the zone on its own. Returns an error if:
+// - the ID is empty
+// - the zone has no sections
+// - a section ID is empty or listed twice
+func (z *Zone) Validate() error {
+	if z.ID == "" {
+		return errors.New("zone ID cannot be empty")
+	}
+	if len(z.SectionIDs) == 0 {
+		return errors.New("zone must have at least one section: " + z.ID)
+	}
+	for i, sectionID := range z.SectionIDs {
+		if sectionID == "" {
+			return errors.New("zone section ID cannot be empty: " + z.ID)
+		}
+		if slices.Contains(z.SectionIDs[:i], sectionID) {
+			return errors.New("duplicate section in zone " + z.ID + ": " + sectionID)
+		}
+	}
+	return nil
+}
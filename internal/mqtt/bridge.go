@@ -0,0 +1,271 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/greenhouse"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Reading is the JSON payload published on
+// {prefix}/{greenhouse}/{section}/{sensor_id}/reading.
+type Reading struct {
+	SensorID  string    `json:"sensor_id"`
+	SectionID string    `json:"section"`
+	Tick      int       `json:"tick"`
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// StatsMessage is the JSON payload published on {prefix}/{greenhouse}/stats
+// after every tick.
+type StatsMessage struct {
+	Tick      int       `json:"tick"`
+	Timestamp time.Time `json:"timestamp"`
+	greenhouse.Stats
+}
+
+// AlertMessage is the JSON payload published on {prefix}/{greenhouse}/alerts,
+// or the topic config.MQTTConfig.AlertTopics routes the severity of the alert
+// to, when an alert fires or is notified again, with type alert, or is
+// resolved, with type alert_resolved.
+type AlertMessage struct {
+	Type      events.Type `json:"type"`
+	Tick      int         `json:"tick"`
+	Timestamp time.Time   `json:"timestamp"`
+	greenhouse.Alert
+}
+
+// WaterCommand is the JSON payload expected on
+// {prefix}/{greenhouse}/watering/start.
+type WaterCommand struct {
+	SectionID string          `json:"section"`
+	Amount    float64         `json:"amount"`
+	Duration  config.Duration `json:"duration,omitempty"`
+}
+
+// Bridge publishes greenhouse readings, stats and alerts to an MQTT broker and runs
+// the commands it receives. Messages are buffered as the bridge handles
+// readings and ticks, once registered with the exporters of the greenhouse,
+// and published by Run.
+type Bridge interface {
+	greenhouse.Exporter
+	// Run connects to the broker and publishes until stop is closed.
+	Run(stop <-chan struct{})
+	// Dropped returns how many messages were dropped because the buffer was full.
+	Dropped() int
+}
+
+type message struct {
+	topic   string
+	payload []byte
+}
+
+type bridge struct {
+	g       greenhouse.Greenhouse
+	client  Client
+	cfg     config.MQTTConfig
+	logger  *slog.Logger
+	wake    chan struct{}
+	buffer  []message
+	dropped int
+	mu      sync.Mutex
+}
+
+// NewBridge returns a bridge between g and the broker client connects to,
+// configured by cfg.
+func NewBridge(g greenhouse.Greenhouse, client Client, cfg config.MQTTConfig, logger *slog.Logger) Bridge {
+	return &bridge{
+		g:      g,
+		client: client,
+		cfg:    cfg.WithDefaults(),
+		logger: logger,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Run connects to the broker and publishes a message for every sensor sample,
+// tick and alert the bridge handled, until stop is closed.
+// On the command topics it accepts:
+//
+//	{prefix}/{greenhouse}/watering/start     water a section, see WaterCommand
+//	{prefix}/{greenhouse}/simulator/pause    pause the simulation
+//	{prefix}/{greenhouse}/simulator/resume   resume the simulation
+//
+// When the connection fails or is lost, Run reconnects with a delay doubling
+// from ReconnectMin up to ReconnectMax. Messages are buffered meanwhile; once
+// BufferSize messages wait, the oldest are dropped. Commands that fail are
+// logged.
+func (b *bridge) Run(stop <-chan struct{}) {
+	connected := false
+	delay := time.Duration(b.cfg.ReconnectMin)
+	for {
+		if !connected {
+			if err := b.connect(); err != nil {
+				b.logger.Warn("mqtt connection failed", "error", err, "retry_in", delay)
+				select {
+				case <-stop:
+					return
+				case <-time.After(delay):
+				}
+				delay = min(2*delay, time.Duration(b.cfg.ReconnectMax))
+				continue
+			}
+			b.logger.Info("connected to the mqtt broker", "broker", b.cfg.Broker)
+			connected = true
+			delay = time.Duration(b.cfg.ReconnectMin)
+		}
+		if err := b.flush(); err != nil {
+			b.logger.Warn("mqtt publish failed, reconnecting", "error", err)
+			b.client.Disconnect()
+			connected = false
+			continue
+		}
+		select {
+		case <-stop:
+			b.client.Disconnect()
+			return
+		case <-b.wake:
+		}
+	}
+}
+
+// Dropped returns how many messages were dropped because the buffer was full.
+// This method is safe for concurrent use.
+func (b *bridge) Dropped() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropped
+}
+
+// connect connects the client and subscribes to the command topics, which
+// brokers forget when the session ends.
+func (b *bridge) connect() error {
+	if err := b.client.Connect(); err != nil {
+		return err
+	}
+	commands := map[string]func([]byte) error{
+		"watering/start":   b.water,
+		"simulator/pause":  func([]byte) error { return b.g.Pause() },
+		"simulator/resume": func([]byte) error { return b.g.Resume() },
+	}
+	for name, run := range commands {
+		err := b.client.Subscribe(b.topic(name), b.cfg.QoS, func(topic string, payload []byte) {
+			if err := run(payload); err != nil {
+				b.logger.Warn("mqtt command failed", "topic", topic, "error", err)
+			}
+		})
+		if err != nil {
+			b.client.Disconnect()
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *bridge) water(payload []byte) error {
+	var command WaterCommand
+	if err := json.Unmarshal(payload, &command); err != nil {
+		return err
+	}
+	return b.g.Watering().WaterSection(command.SectionID, command.Amount, time.Duration(command.Duration))
+}
+
+// HandleReading queues a reading message.
+func (b *bridge) HandleReading(r greenhouse.ExportedReading) error {
+	b.enqueue(b.topic(r.SectionID, r.Reading.SensorID, "reading"), Reading{
+		SensorID:  r.Reading.SensorID,
+		SectionID: r.SectionID,
+		Tick:      r.Tick,
+		Timestamp: r.Reading.Timestamp,
+		Value:     r.Reading.Value,
+	})
+	return nil
+}
+
+// HandleTick queues a stats message.
+func (b *bridge) HandleTick(t greenhouse.ExportedTick) error {
+	b.enqueue(b.topic("stats"), StatsMessage{Tick: t.Tick, Timestamp: t.Timestamp, Stats: t.Stats})
+	return nil
+}
+
+// HandleEvent queues an alert message for the alert events and ignores the
+// others.
+func (b *bridge) HandleEvent(e events.Event) error {
+	alert, ok := e.Payload.(greenhouse.Alert)
+	if !ok || (e.Type != events.Alert && e.Type != events.AlertResolved) {
+		return nil
+	}
+	topic := b.topic("alerts")
+	if routed, ok := b.cfg.AlertTopics[alert.Severity]; ok {
+		topic = b.topic(routed)
+	}
+	b.enqueue(topic, AlertMessage{Type: e.Type, Tick: e.Tick, Timestamp: e.Timestamp, Alert: alert})
+	return nil
+}
+
+// Flush does nothing: Run publishes as messages are queued.
+func (b *bridge) Flush() error { return nil }
+
+// Close does nothing: Run disconnects once stopped.
+func (b *bridge) Close() error { return nil }
+
+func (b *bridge) enqueue(topic string, v any) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	b.mu.Lock()
+	b.buffer = append(b.buffer, message{topic: topic, payload: payload})
+	if excess := len(b.buffer) - b.cfg.BufferSize; excess > 0 {
+		if b.dropped == 0 {
+			b.logger.Warn("mqtt buffer full, dropping the oldest messages", "size", b.cfg.BufferSize)
+		}
+		b.buffer = b.buffer[excess:]
+		b.dropped += excess
+	}
+	b.mu.Unlock()
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
+
+// flush publishes the buffered messages in order. A message that fails to
+// publish stays at the front of the buffer unless newer messages pushed it
+// out meanwhile.
+func (b *bridge) flush() error {
+	for {
+		b.mu.Lock()
+		if len(b.buffer) == 0 {
+			b.mu.Unlock()
+			return nil
+		}
+		next := b.buffer[0]
+		b.buffer = b.buffer[1:]
+		b.mu.Unlock()
+
+		if err := b.client.Publish(next.topic, b.cfg.QoS, next.payload); err != nil {
+			b.mu.Lock()
+			if len(b.buffer) < b.cfg.BufferSize {
+				b.buffer = append([]message{next}, b.buffer...)
+			} else {
+				b.dropped++
+			}
+			b.mu.Unlock()
+			return err
+		}
+	}
+}
+
+// topic joins the topic prefix, the greenhouse name and levels with slashes.
+func (b *bridge) topic(levels ...string) string {
+	topic := b.cfg.TopicPrefix + "/" + b.cfg.Greenhouse
+	for _, level := range levels {
+		topic += "/" + level
+	}
+	return topic
+}
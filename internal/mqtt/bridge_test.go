@@ -0,0 +1,281 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"errors"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/greenhouse"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeBroker is a Client that records what is published and lets tests drop
+// the connection and send commands.
+type fakeBroker struct {
+	connected     bool
+	refuse        bool
+	connects      int
+	published     []message
+	subscriptions map[string]func(topic string, payload []byte)
+	mu            sync.Mutex
+}
+
+func newFakeBroker() *fakeBroker {
+	return &fakeBroker{subscriptions: map[string]func(string, []byte){}}
+}
+
+func (f *fakeBroker) Connect() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.connects++
+	if f.refuse {
+		return errors.New("connection refused")
+	}
+	f.connected = true
+	return nil
+}
+
+func (f *fakeBroker) Publish(topic string, qos byte, payload []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.connected {
+		return errors.New("not connected")
+	}
+	f.published = append(f.published, message{topic: topic, payload: payload})
+	return nil
+}
+
+func (f *fakeBroker) Subscribe(topic string, qos byte, handler func(topic string, payload []byte)) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.connected {
+		return errors.New("not connected")
+	}
+	f.subscriptions[topic] = handler
+	return nil
+}
+
+func (f *fakeBroker) Disconnect() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.connected = false
+	f.subscriptions = map[string]func(string, []byte){}
+}
+
+// drop loses the connection and refuses new ones until restore.
+func (f *fakeBroker) drop() {
+	f.Disconnect()
+	f.mu.Lock()
+	f.refuse = true
+	f.mu.Unlock()
+}
+
+func (f *fakeBroker) restore() {
+	f.mu.Lock()
+	f.refuse = false
+	f.mu.Unlock()
+}
+
+func (f *fakeBroker) send(t *testing.T, topic, payload string) {
+	t.Helper()
+	f.mu.Lock()
+	handler := f.subscriptions[topic]
+	f.mu.Unlock()
+	if handler == nil {
+		t.Fatalf("no subscription to %s", topic)
+	}
+	handler(topic, []byte(payload))
+}
+
+func (f *fakeBroker) messages() []message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]message(nil), f.published...)
+}
+
+// eventually polls until condition holds, failing the test after a second.
+func eventually(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// startBridge runs a bridge for the demo greenhouse against a fake broker
+// until the test ends.
+func startBridge(t *testing.T, cfg config.MQTTConfig) (greenhouse.Greenhouse, *fakeBroker, Bridge) {
+	t.Helper()
+	gcfg := config.Default()
+	gcfg.TickInterval = config.Duration(time.Hour)
+	g, err := greenhouse.New(gcfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	broker := newFakeBroker()
+	bridge := NewBridge(g, broker, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err := g.Exporters().Register("mqtt", bridge, 0); err != nil {
+		t.Fatalf("failed to register bridge: %v", err)
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		bridge.Run(stop)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		g.Exporters().Close(time.Second)
+		close(stop)
+		<-done
+	})
+	eventually(t, "the bridge to connect", broker.subscribed)
+	return g, broker, bridge
+}
+
+// subscribed reports whether the bridge is connected and listening to its
+// three command topics.
+func (f *fakeBroker) subscribed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.connected && len(f.subscriptions) == 3
+}
+
+func TestBridge_PublishesReadingsAndStats(t *testing.T) {
+	g, broker, _ := startBridge(t, config.MQTTConfig{Broker: "tcp://broker:1883", Greenhouse: "north"})
+	sensorCount := len(g.Sensors().ListSensors())
+
+	g.Simulator().Step()
+	eventually(t, "the tick messages", func() bool { return len(broker.messages()) == sensorCount+1 })
+
+	messages := broker.messages()
+	for _, m := range messages[:sensorCount] {
+		var reading Reading
+		if err := json.Unmarshal(m.payload, &reading); err != nil {
+			t.Fatalf("invalid reading payload %s: %v", m.payload, err)
+		}
+		expected := "greenhouse/north/" + reading.SectionID + "/" + reading.SensorID + "/reading"
+		if m.topic != expected || reading.Tick != 0 || reading.SensorID == "" {
+			t.Errorf("expected a tick 0 reading on %s, got %s on %s", expected, m.payload, m.topic)
+		}
+	}
+	stats := messages[sensorCount]
+	var payload map[string]any
+	if err := json.Unmarshal(stats.payload, &payload); err != nil {
+		t.Fatalf("invalid stats payload %s: %v", stats.payload, err)
+	}
+	if stats.topic != "greenhouse/north/stats" || payload["tick"] != 0.0 || payload["plants"] == nil {
+		t.Errorf("expected tick 0 stats on greenhouse/north/stats, got %s on %s", stats.payload, stats.topic)
+	}
+}
+
+func TestBridge_PublishesAlerts(t *testing.T) {
+	g, broker, _ := startBridge(t, config.MQTTConfig{Broker: "tcp://broker:1883", Greenhouse: "north"})
+
+	alert := greenhouse.Alert{Rule: config.AlertTankEmpty, Severity: config.SeverityCritical, Message: "water tank is empty", Since: 4}
+	g.Bus().Publish(events.Event{Type: events.Alert, Tick: 4, Payload: alert})
+	g.Bus().Publish(events.Event{Type: events.AlertResolved, Tick: 6, Payload: alert})
+	g.Bus().Publish(events.Event{Type: events.LowWater, Tick: 6})
+	eventually(t, "the alert messages", func() bool { return len(broker.messages()) == 2 })
+
+	for i, m := range broker.messages() {
+		var message AlertMessage
+		if err := json.Unmarshal(m.payload, &message); err != nil {
+			t.Fatalf("invalid alert payload %s: %v", m.payload, err)
+		}
+		expected := []events.Type{events.Alert, events.AlertResolved}[i]
+		if m.topic != "greenhouse/north/alerts" || message.Type != expected || message.Alert != alert {
+			t.Errorf("expected a %s message on greenhouse/north/alerts, got %s on %s", expected, m.payload, m.topic)
+		}
+	}
+}
+
+func TestBridge_RoutesAlertsBySeverity(t *testing.T) {
+	g, broker, _ := startBridge(t, config.MQTTConfig{Broker: "tcp://broker:1883", AlertTopics: map[string]string{config.SeverityCritical: "alerts/critical"}})
+
+	alert := greenhouse.Alert{Rule: config.AlertSaturationDrop, Severity: config.SeverityWarning, Since: 2, LastNotified: 2, Notifications: 1}
+	g.Bus().Publish(events.Event{Type: events.Alert, Tick: 2, Payload: alert})
+	alert.Severity, alert.LastNotified, alert.Notifications = config.SeverityCritical, 42, 2
+	g.Bus().Publish(events.Event{Type: events.Alert, Tick: 42, Payload: alert})
+	g.Bus().Publish(events.Event{Type: events.AlertResolved, Tick: 50, Payload: alert})
+	eventually(t, "the alert messages", func() bool { return len(broker.messages()) == 3 })
+
+	for i, m := range broker.messages() {
+		expected := []string{"greenhouse/default/alerts", "greenhouse/default/alerts/critical", "greenhouse/default/alerts/critical"}[i]
+		if m.topic != expected {
+			t.Errorf("expected message %d on %s, got %s", i, expected, m.topic)
+		}
+	}
+}
+
+func TestBridge_Commands(t *testing.T) {
+	g, broker, _ := startBridge(t, config.MQTTConfig{Broker: "tcp://broker:1883", TopicPrefix: "site/gh"})
+	sim := g.Simulator()
+	go sim.Start()
+	defer sim.Stop()
+	for sim.State() != engine.Running {
+		time.Sleep(time.Millisecond)
+	}
+
+	broker.send(t, "site/gh/default/simulator/pause", "")
+	if !g.Paused() {
+		t.Fatal("expected the pause command to pause the simulation")
+	}
+	broker.send(t, "site/gh/default/simulator/resume", "")
+	if g.Paused() {
+		t.Fatal("expected the resume command to resume the simulation")
+	}
+	// Failing commands are logged, not fatal.
+	broker.send(t, "site/gh/default/simulator/resume", "")
+	broker.send(t, "site/gh/default/watering/start", `{"section": "section-Z", "amount": 1}`)
+	broker.send(t, "site/gh/default/watering/start", `not json`)
+
+	broker.send(t, "site/gh/default/watering/start", `{"section": "section-A", "amount": 2, "duration": "1m"}`)
+	if active := g.Watering().GetActiveEvents(); len(active) != 1 || active[0].SectionID != "section-A" {
+		t.Errorf("expected a watering of section-A, got %+v", active)
+	}
+}
+
+func TestBridge_BuffersWhileDisconnected(t *testing.T) {
+	g, broker, bridge := startBridge(t, config.MQTTConfig{
+		Broker:       "tcp://broker:1883",
+		BufferSize:   5,
+		ReconnectMin: config.Duration(time.Millisecond),
+		ReconnectMax: config.Duration(2 * time.Millisecond),
+	})
+	sensorCount := len(g.Sensors().ListSensors())
+	perTick := sensorCount + 1
+
+	broker.drop()
+	for range 3 {
+		g.Simulator().Step()
+	}
+	g.Exporters().Drain()
+	eventually(t, "the bridge to retry", func() bool {
+		broker.mu.Lock()
+		defer broker.mu.Unlock()
+		return broker.connects >= 3
+	})
+	if len(broker.messages()) != 0 {
+		t.Fatalf("expected nothing published while disconnected, got %d messages", len(broker.messages()))
+	}
+	if dropped := bridge.Dropped(); dropped != 3*perTick-5 {
+		t.Errorf("expected %d dropped messages, got %d", 3*perTick-5, dropped)
+	}
+
+	broker.restore()
+	eventually(t, "the buffer to flush", func() bool { return len(broker.messages()) == 5 })
+	messages := broker.messages()
+	if last := messages[4]; last.topic != "greenhouse/default/stats" || !strings.Contains(string(last.payload), `"tick":2`) {
+		t.Errorf("expected the newest messages to survive, ending with the tick 2 stats, got %s on %s", last.payload, last.topic)
+	}
+	eventually(t, "the commands to be resubscribed", broker.subscribed)
+}
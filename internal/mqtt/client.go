@@ -0,0 +1,72 @@
+// Package mqtt bridges a running greenhouse to an MQTT broker: sensor readings
+// and greenhouse stats are published after every tick, and commands received
+// on the command topics drive the simulation.
+package mqtt
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/config"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+)
+
+// Client is the part of an MQTT client the bridge uses, so that tests can
+// run against a fake broker. Publish and Subscribe return an error when the
+// client is not connected; the bridge then reconnects with Connect.
+type Client interface {
+	Connect() error
+	Publish(topic string, qos byte, payload []byte) error
+	Subscribe(topic string, qos byte, handler func(topic string, payload []byte)) error
+	Disconnect()
+}
+
+// operationTimeout bounds how long the paho client waits for the broker.
+const operationTimeout = 10 * time.Second
+
+type pahoClient struct {
+	client paho.Client
+}
+
+// NewClient returns a Client backed by the Eclipse Paho library, connecting
+// to the broker of cfg with its credentials and client ID. Reconnecting is
+// left to the bridge.
+func NewClient(cfg config.MQTTConfig) Client {
+	cfg = cfg.WithDefaults()
+	options := paho.NewClientOptions().
+		AddBroker(cfg.Broker).
+		SetClientID(cfg.ClientID).
+		SetUsername(cfg.Username).
+		SetPassword(cfg.Password).
+		SetAutoReconnect(false).
+		SetConnectTimeout(operationTimeout)
+	return &pahoClient{client: paho.NewClient(options)}
+}
+
+func (c *pahoClient) Connect() error {
+	return wait(c.client.Connect())
+}
+
+func (c *pahoClient) Publish(topic string, qos byte, payload []byte) error {
+	if !c.client.IsConnectionOpen() {
+		return errors.New("mqtt client is not connected")
+	}
+	return wait(c.client.Publish(topic, qos, false, payload))
+}
+
+func (c *pahoClient) Subscribe(topic string, qos byte, handler func(topic string, payload []byte)) error {
+	return wait(c.client.Subscribe(topic, qos, func(_ paho.Client, message paho.Message) {
+		handler(message.Topic(), message.Payload())
+	}))
+}
+
+func (c *pahoClient) Disconnect() {
+	c.client.Disconnect(250)
+}
+
+func wait(token paho.Token) error {
+	if !token.WaitTimeout(operationTimeout) {
+		return errors.New("mqtt operation timed out")
+	}
+	return token.Error()
+}
@@ -0,0 +1,251 @@
+package repl
+
+import (
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/greenhouse"
+	"greenhouse-simulator/internal/models"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// command is a command of the prompt. run is called with between minArgs
+// and maxArgs arguments and returns the output of the command.
+type command struct {
+	name    string
+	usage   string
+	summary string
+	minArgs int
+	maxArgs int
+	run     func(s *session, args []string) (string, error)
+}
+
+// commandList holds the commands in the order help lists them, and commands
+// indexes them by name. They are set up by init, as help refers to them.
+var (
+	commandList []command
+	commands    map[string]command
+)
+
+func init() {
+	commandList = []command{
+		{"tick", "tick [n]", "run n ticks, 1 by default, while the simulation is not running", 0, 1, runTick},
+		{"status", "status", "show the tick, the plant totals, the water used and the conditions", 0, 0, runStatus},
+		{"plants", "plants [section]", "list the plants, of one section or of all", 0, 1, runPlants},
+		{"water", "water <section> <amount> [duration]", "water a section from the next tick, over duration if given", 2, 3, runWater},
+		{"pause", "pause", "hold a running simulation so that tick steps it again", 0, 0, runPause},
+		{"resume", "resume", "run the simulation in real time", 0, 0, runResume},
+		{"sensor", "sensor <id>", "read a sensor", 1, 1, runSensor},
+		{"snapshot", "snapshot <file>", "save the greenhouse as a config file that load resumes from", 1, 1, runSnapshot},
+		{"load", "load <file>", "replace the greenhouse with the one of a config file, at tick 0", 1, 1, runLoad},
+		{"help", "help", "list the commands", 0, 0, runHelp},
+		{"quit", "quit", "stop the simulation and leave", 0, 0, runQuit},
+	}
+	commands = make(map[string]command, len(commandList))
+	for _, cmd := range commandList {
+		commands[cmd.name] = cmd
+	}
+}
+
+// runTick steps the simulator n times and reports the tick reached. The
+// simulation must not be running, or the steps would race its loop.
+func runTick(s *session, args []string) (string, error) {
+	n := 1
+	if len(args) == 1 {
+		var err error
+		if n, err = strconv.Atoi(args[0]); err != nil || n < 1 {
+			return "", errors.New("ticks must be a positive number: " + args[0])
+		}
+	}
+	sim := s.g.Simulator()
+	if sim.State() == engine.Running {
+		return "", errRunning
+	}
+	for range n {
+		sim.Step()
+	}
+	return fmt.Sprintf("tick %d\n", sim.GetCurrentTick()), nil
+}
+
+// runStatus shows the state of the simulation and its totals.
+func runStatus(s *session, _ []string) (string, error) {
+	sim := s.g.Simulator()
+	stats := s.g.Stats()
+	conditions := s.g.Conditions()
+	weather := string(conditions.Weather)
+	if conditions.Extreme != "" {
+		weather += ", " + string(conditions.Extreme)
+	}
+	rows := [][]string{
+		{"tick", strconv.Itoa(sim.GetCurrentTick())},
+		{"state", stateName(sim.State())},
+		{"tick interval", sim.GetTickInterval().String()},
+		{"plants", fmt.Sprintf("%d, %d alive", stats.Plants, stats.AlivePlants)},
+		{"average health", fmt.Sprintf("%.3f", stats.AverageHealth)},
+		{"average saturation", fmt.Sprintf("%.3f", stats.AverageSaturation)},
+		{"water used", fmt.Sprintf("%.3f, %.3f wasted", stats.WaterUsed, stats.WaterWasted)},
+	}
+	if stats.TankRemaining != nil {
+		rows = append(rows, []string{"tank", fmt.Sprintf("%.3f", *stats.TankRemaining)})
+	}
+	rows = append(rows,
+		[]string{"temperature", fmt.Sprintf("%.1f C", conditions.Temperature)},
+		[]string{"humidity", fmt.Sprintf("%.0f%%", conditions.Humidity*100)},
+		[]string{"weather", weather},
+	)
+	return table(nil, rows), nil
+}
+
+// stateName names the state of the simulator for status: a simulator that
+// was never started is stepped by hand.
+func stateName(state engine.State) string {
+	if state == engine.Created {
+		return "manual"
+	}
+	return string(state)
+}
+
+// runPlants lists the plants ordered by ID.
+func runPlants(s *session, args []string) (string, error) {
+	sim := s.g.Simulator()
+	var plants []*models.Plant
+	if len(args) == 1 {
+		if plants = sim.GetPlantsBySectionID(args[0]); len(plants) == 0 {
+			return "", errors.New("no plants in section: " + args[0])
+		}
+	} else if plants = sim.GetAllPlants(); len(plants) == 0 {
+		return "no plants\n", nil
+	}
+	slices.SortFunc(plants, func(a, b *models.Plant) int { return strings.Compare(a.ID, b.ID) })
+	rows := make([][]string, len(plants))
+	for i, plant := range plants {
+		alive := "yes"
+		if !plant.Alive {
+			alive = "no"
+		}
+		rows[i] = []string{
+			plant.ID, plant.SectionID, plant.Type.Name,
+			fmt.Sprintf("%.3f", plant.Health),
+			fmt.Sprintf("%.3f", plant.GrowthStage),
+			fmt.Sprintf("%.3f", plant.SoilSaturation),
+			alive,
+		}
+	}
+	return table([]string{"plant", "section", "type", "health", "growth", "saturation", "alive"}, rows), nil
+}
+
+// runWater queues a manual watering of a section, see
+// watering.Controller.WaterSection.
+func runWater(s *session, args []string) (string, error) {
+	section := args[0]
+	amount, err := strconv.ParseFloat(args[1], 64)
+	if err != nil {
+		return "", errors.New("invalid water amount: " + args[1])
+	}
+	var duration time.Duration
+	if len(args) == 3 {
+		if duration, err = time.ParseDuration(args[2]); err != nil {
+			return "", errors.New("invalid watering duration: " + args[2])
+		}
+	}
+	if err := s.g.Watering().WaterSection(section, amount, duration); err != nil {
+		return "", err
+	}
+	if duration == 0 {
+		return fmt.Sprintf("watering %s with %g on the next tick\n", section, amount), nil
+	}
+	return fmt.Sprintf("watering %s with %g over %s from the next tick\n", section, amount, duration), nil
+}
+
+// runPause holds a running simulation.
+func runPause(s *session, _ []string) (string, error) {
+	sim := s.g.Simulator()
+	if sim.State() == engine.Created {
+		return "", errNotRunning
+	}
+	if err := s.g.Pause(); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("paused at tick %d\n", sim.GetCurrentTick()), nil
+}
+
+// runResume runs the simulation in real time, starting its loop the first
+// time.
+func runResume(s *session, _ []string) (string, error) {
+	sim := s.g.Simulator()
+	var err error
+	if sim.State() == engine.Created {
+		err = s.start()
+	} else {
+		err = s.g.Resume()
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("running from tick %d, a tick every %s\n", sim.GetCurrentTick(), sim.GetTickInterval()), nil
+}
+
+// runSensor reads a sensor.
+func runSensor(s *session, args []string) (string, error) {
+	sensors := s.g.Sensors()
+	reading, err := sensors.GetReading(args[0])
+	if err != nil {
+		return "", err
+	}
+	row := []string{args[0], "", "", fmt.Sprintf("%.3f", reading.Value)}
+	for _, sensor := range sensors.ListSensors() {
+		if sensor.ID == args[0] {
+			row[1], row[2] = string(sensor.Type), sensor.SectionID
+			break
+		}
+	}
+	return table([]string{"sensor", "type", "section", "value"}, [][]string{row}), nil
+}
+
+// runSnapshot saves the greenhouse as a config file with every plant as it
+// is, see greenhouse.Greenhouse.ExportScenario.
+func runSnapshot(s *session, args []string) (string, error) {
+	cfg, err := s.g.ExportScenario(config.ExportOptions{ExactResume: true})
+	if err != nil {
+		return "", err
+	}
+	if err := config.SaveConfig(cfg, args[0]); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("saved %d plants at tick %d to %s\n", len(cfg.Plants), s.g.Simulator().GetCurrentTick(), args[0]), nil
+}
+
+// runLoad replaces the greenhouse with the one of a config file, stopping the
+// current simulation. The new one starts at tick 0 in manual-tick mode.
+func runLoad(s *session, args []string) (string, error) {
+	cfg, err := config.LoadConfig(args[0])
+	if err != nil {
+		return "", err
+	}
+	g, err := greenhouse.New(cfg)
+	if err != nil {
+		return "", err
+	}
+	if err := s.g.Simulator().Stop(); err != nil && !errors.Is(err, engine.ErrAlreadyStopped) {
+		return "", err
+	}
+	s.g = g
+	return fmt.Sprintf("loaded %s: %d plants, %d sensors\n", args[0], len(cfg.Plants), len(cfg.Sensors)), nil
+}
+
+// runHelp lists the commands.
+func runHelp(*session, []string) (string, error) {
+	rows := make([][]string, len(commandList))
+	for i, cmd := range commandList {
+		rows[i] = []string{cmd.usage, cmd.summary}
+	}
+	return table(nil, rows), nil
+}
+
+func runQuit(*session, []string) (string, error) {
+	return "", ErrQuit
+}
@@ -0,0 +1,205 @@
+package repl
+
+import (
+	"errors"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// execute runs the lines in s and returns the output of the last one,
+// failing the test on any error.
+func execute(t *testing.T, s Session, lines ...string) string {
+	t.Helper()
+	var out string
+	for _, line := range lines {
+		var err error
+		if out, err = s.Execute(line); err != nil {
+			t.Fatalf("%s: unexpected error: %v", line, err)
+		}
+	}
+	return out
+}
+
+func TestTick(t *testing.T) {
+	s := newTestSession(t)
+	if out := execute(t, s, "tick"); out != "tick 1\n" {
+		t.Errorf("expected one tick, got %q", out)
+	}
+	if out := execute(t, s, "tick 5"); out != "tick 6\n" {
+		t.Errorf("expected five more ticks, got %q", out)
+	}
+	for _, line := range []string{"tick 0", "tick -1", "tick many"} {
+		if _, err := s.Execute(line); err == nil || !strings.HasPrefix(err.Error(), "ticks must be a positive number") {
+			t.Errorf("%s: expected a tick count error, got %v", line, err)
+		}
+	}
+	if tick := s.Greenhouse().Simulator().GetCurrentTick(); tick != 6 {
+		t.Errorf("expected failed commands not to tick, got tick %d", tick)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	s := newTestSession(t)
+	out := execute(t, s, "tick 3", "status")
+	for _, expected := range []string{"tick                3\n", "state               manual\n", "plants              3, 3 alive\n", "temperature"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in the status, got:\n%s", expected, out)
+		}
+	}
+}
+
+func TestPlants(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected []string
+		errorMsg string
+	}{
+		{"all", "plants", []string{"plant", "lettuce-1", "tomato-1", "tomato-2"}, ""},
+		{"section", "plants section-A", []string{"plant", "tomato-1", "tomato-2"}, ""},
+		{"unknown section", "plants section-Z", nil, "no plants in section: section-Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSession(t)
+			out, err := s.Execute(tt.line)
+			if tt.errorMsg != "" {
+				if err == nil || err.Error() != tt.errorMsg {
+					t.Fatalf("expected error message '%s', got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+			if len(lines) != len(tt.expected) {
+				t.Fatalf("expected %d lines, got:\n%s", len(tt.expected), out)
+			}
+			for i, line := range lines {
+				if first := strings.Fields(line)[0]; first != tt.expected[i] {
+					t.Errorf("expected line %d to start with %s, got %q", i, tt.expected[i], line)
+				}
+			}
+		})
+	}
+}
+
+func TestWater(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected string
+		errorMsg string
+	}{
+		{"next tick", "water section-A 2", "watering section-A with 2 on the next tick\n", ""},
+		{"over a duration", "water section-A 2 5s", "watering section-A with 2 over 5s from the next tick\n", ""},
+		{"invalid amount", "water section-A lots", "", "invalid water amount: lots"},
+		{"invalid duration", "water section-A 2 soon", "", "invalid watering duration: soon"},
+		{"empty section", "water section-Z 2", "", "no plants in section: section-Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSession(t)
+			out, err := s.Execute(tt.line)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("expected error message '%s', got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out != tt.expected {
+				t.Errorf("expected output %q, got %q", tt.expected, out)
+			}
+			execute(t, s, "tick")
+			if used := s.Greenhouse().Stats().WaterUsed; used <= 0 {
+				t.Errorf("expected the watering to start on the next tick, got %v water used", used)
+			}
+		})
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	s := newTestSession(t)
+	if _, err := s.Execute("pause"); !errors.Is(err, errNotRunning) {
+		t.Fatalf("expected pausing a manual simulation to fail, got %v", err)
+	}
+	execute(t, s, "resume")
+	if out := execute(t, s, "status"); !strings.Contains(out, "running") {
+		t.Errorf("expected a running simulation, got:\n%s", out)
+	}
+	if _, err := s.Execute("tick"); !errors.Is(err, errRunning) {
+		t.Fatalf("expected ticking a running simulation to fail, got %v", err)
+	}
+	execute(t, s, "pause")
+	tick := s.Greenhouse().Simulator().GetCurrentTick()
+	if out := execute(t, s, "tick 2"); out != "tick "+strconv.Itoa(tick+2)+"\n" {
+		t.Errorf("expected to step a paused simulation, got %q", out)
+	}
+	if _, err := s.Execute("pause"); err == nil {
+		t.Error("expected pausing a paused simulation to fail")
+	}
+	execute(t, s, "resume")
+}
+
+func TestSensor(t *testing.T) {
+	s := newTestSession(t)
+	out := execute(t, s, "sensor sensor-1")
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a header and a reading, got:\n%s", out)
+	}
+	fields := strings.Fields(lines[1])
+	if len(fields) != 4 || fields[0] != "sensor-1" || fields[1] != "soil_moisture" || fields[2] != "section-B" {
+		t.Errorf("expected the reading of sensor-1, got %q", lines[1])
+	}
+	if _, err := s.Execute("sensor sensor-9"); err == nil {
+		t.Error("expected an error for an unknown sensor")
+	}
+}
+
+func TestSnapshotLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.yaml")
+	s := newTestSession(t)
+	plants := execute(t, s, "tick 20", "plants")
+	if out := execute(t, s, "snapshot "+path); out != "saved 3 plants at tick 20 to "+path+"\n" {
+		t.Errorf("unexpected snapshot output %q", out)
+	}
+	old := s.Greenhouse()
+	if out := execute(t, s, "load "+path); out != "loaded "+path+": 3 plants, 1 sensors\n" {
+		t.Errorf("unexpected load output %q", out)
+	}
+	if s.Greenhouse() == old {
+		t.Fatal("expected load to replace the greenhouse")
+	}
+	if out := execute(t, s, "plants"); out != plants {
+		t.Errorf("expected the loaded plants to match the saved ones, got:\n%s\nwant:\n%s", out, plants)
+	}
+	if out := execute(t, s, "tick"); out != "tick 1\n" {
+		t.Errorf("expected the loaded greenhouse to start at tick 0, got %q", out)
+	}
+
+	if _, err := s.Execute("snapshot " + filepath.Join(t.TempDir(), "snapshot.txt")); err == nil {
+		t.Error("expected an error for an unsupported snapshot format")
+	}
+	if _, err := s.Execute("load missing.yaml"); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestHelp(t *testing.T) {
+	s := newTestSession(t)
+	out := execute(t, s, "help")
+	for _, cmd := range commandList {
+		if !strings.Contains(out, cmd.usage) {
+			t.Errorf("expected %q in the help, got:\n%s", cmd.usage, out)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// Package repl is the command layer of the interactive greenhouse prompt. A
+// Session runs command lines such as "tick 10" or "plants section-A" against
+// a greenhouse and returns their output as text, so that the prompt itself is
+// a thin loop around Execute and the commands can be driven from tests.
+//
+// The simulation runs in manual-tick mode: its loop is not started, and time
+// only advances when the tick command steps it. resume starts it running in
+// real time and pause holds it again, after which tick steps it once more.
+package repl
+
+import (
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/greenhouse"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// ErrQuit is returned by Execute for the quit command. The caller should
+// close the session and end the prompt.
+var ErrQuit = errors.New("quit")
+
+var (
+	errUnknownCommand = errors.New("unknown command")
+	errRunning        = errors.New("simulation is running: pause it to step ticks")
+	errNotRunning     = errors.New("simulation is not running: resume starts it")
+)
+
+// Session runs the commands of the prompt against a greenhouse.
+type Session interface {
+	// Execute runs one command line and returns its output. Empty lines do
+	// nothing. Returns ErrQuit for the quit command.
+	Execute(line string) (string, error)
+	// Greenhouse returns the greenhouse the commands run against, which the
+	// load command replaces.
+	Greenhouse() greenhouse.Greenhouse
+	// Close stops the simulation.
+	Close() error
+}
+
+type session struct {
+	g  greenhouse.Greenhouse
+	mu sync.Mutex
+}
+
+// NewSession returns a session on g, whose simulator must not have been
+// started: the session steps it until the resume command starts its loop.
+func NewSession(g greenhouse.Greenhouse) Session {
+	return &session{g: g}
+}
+
+// Execute splits line into words and runs the command named by the first
+// one with the others as its arguments.
+// Returns an error if:
+// - the command is unknown
+// - it is given too few or too many arguments
+// - the command fails
+// This method is safe for concurrent use.
+func (s *session) Execute(line string) (string, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", nil
+	}
+	name, args := fields[0], fields[1:]
+	cmd, ok := commands[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s (try help)", errUnknownCommand, name)
+	}
+	if len(args) < cmd.minArgs || len(args) > cmd.maxArgs {
+		return "", errors.New("usage: " + cmd.usage)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return cmd.run(s, args)
+}
+
+// Greenhouse returns the current greenhouse.
+// This method is safe for concurrent use.
+func (s *session) Greenhouse() greenhouse.Greenhouse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.g
+}
+
+// Close stops the simulator of the current greenhouse. Closing a session
+// twice does nothing.
+// This method is safe for concurrent use.
+func (s *session) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.g.Simulator().Stop(); err != nil && !errors.Is(err, engine.ErrAlreadyStopped) {
+		return err
+	}
+	return nil
+}
+
+// start starts the loop of the simulator and waits until it runs.
+func (s *session) start() error {
+	sim := s.g.Simulator()
+	started := make(chan error, 1)
+	go func() { started <- sim.Start() }()
+	for sim.State() == engine.Created {
+		select {
+		case err := <-started:
+			return err
+		case <-time.After(time.Millisecond):
+		}
+	}
+	return nil
+}
+
+// table formats rows as aligned columns, under header unless it is nil.
+func table(header []string, rows [][]string) string {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	if header != nil {
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+	}
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	tw.Flush()
+	return b.String()
+}
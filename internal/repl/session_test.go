@@ -0,0 +1,80 @@
+package repl
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/greenhouse"
+	"testing"
+)
+
+// newTestSession returns a session on the default greenhouse, closed at the
+// end of the test.
+func newTestSession(t *testing.T) Session {
+	t.Helper()
+	g, err := greenhouse.New(config.Default())
+	if err != nil {
+		t.Fatalf("failed to create greenhouse: %v", err)
+	}
+	s := NewSession(g)
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestExecute_Parsing(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected string
+		errorMsg string
+	}{
+		{"empty line", "", "", ""},
+		{"blank line", "  \t ", "", ""},
+		{"extra spaces", "  tick   2 ", "tick 2\n", ""},
+		{"unknown command", "grow", "", "unknown command: grow (try help)"},
+		{"too few arguments", "water section-A", "", "usage: water <section> <amount> [duration]"},
+		{"too many arguments", "tick 1 2", "", "usage: tick [n]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSession(t)
+			out, err := s.Execute(tt.line)
+			if tt.errorMsg != "" {
+				if err == nil || err.Error() != tt.errorMsg {
+					t.Fatalf("expected error message '%s', got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out != tt.expected {
+				t.Errorf("expected output %q, got %q", tt.expected, out)
+			}
+		})
+	}
+}
+
+func TestExecute_Quit(t *testing.T) {
+	s := newTestSession(t)
+	if _, err := s.Execute("quit"); !errors.Is(err, ErrQuit) {
+		t.Fatalf("expected ErrQuit, got %v", err)
+	}
+}
+
+func TestClose(t *testing.T) {
+	s := newTestSession(t)
+	if _, err := s.Execute("resume"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state := s.Greenhouse().Simulator().State(); state != engine.Stopped {
+		t.Errorf("expected the simulator to be stopped, got %s", state)
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("expected closing twice to do nothing, got %v", err)
+	}
+}
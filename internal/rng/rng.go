@@ -0,0 +1,97 @@
+// Package rng derives every random number of a simulation from the config
+// seed. Each subsystem gets its own stream, split off the root source by
+// name, so adding draws to one subsystem does not shift the numbers another
+// one sees, and the same config and seed always replay the same run.
+package rng
+
+import "math/rand/v2"
+
+// The streams split off the root source, one per subsystem.
+const (
+	// Sensors is the stream of the sensor reading noise.
+	Sensors = "sensors"
+	// Weather is the stream of the daily weather draws.
+	Weather = "weather"
+	// Pests is the stream of pest and disease outbreaks.
+	Pests = "pests"
+	// Germination is the stream of the seeds sprouting or failing.
+	Germination = "germination"
+	// Chaos is the stream of the faults chaos mode injects.
+	Chaos = "chaos"
+)
+
+// Source is a deterministic stream of random numbers that can be split into
+// independent child streams. A child depends only on its parent's key and
+// its name or number, never on what was drawn from the parent, so children
+// can be split off in any order and at any time.
+//
+// Split and SplitN are safe for concurrent use; the draws are not, so
+// concurrent users should each split off their own child.
+type Source interface {
+	// Float64 returns a number in [0.0, 1.0).
+	Float64() float64
+	// NormFloat64 returns a normally distributed number with mean 0 and
+	// standard deviation 1.
+	NormFloat64() float64
+	// IntN returns a number in [0, n). It panics if n <= 0.
+	IntN(n int) int
+	// Split returns the child stream with the given name.
+	Split(name string) Source
+	// SplitN returns the child stream with the given number, for draws
+	// keyed by a tick, a day or an index.
+	SplitN(n int) Source
+}
+
+type source struct {
+	key uint64
+	pcg rand.PCG
+	r   *rand.Rand
+}
+
+// New returns the root source of a seed.
+func New(seed int64) Source {
+	return newSource(mix(uint64(seed)))
+}
+
+func newSource(key uint64) *source {
+	s := &source{key: key}
+	s.pcg.Seed(key, mix(key))
+	return s
+}
+
+// rand returns the generator, created on the first draw so that sources only
+// split further cost no more than their key.
+func (s *source) rand() *rand.Rand {
+	if s.r == nil {
+		s.r = rand.New(&s.pcg)
+	}
+	return s.r
+}
+
+func (s *source) Float64() float64     { return s.rand().Float64() }
+func (s *source) NormFloat64() float64 { return s.rand().NormFloat64() }
+func (s *source) IntN(n int) int       { return s.rand().IntN(n) }
+
+func (s *source) Split(name string) Source {
+	// FNV-1a of the name.
+	h := uint64(14695981039346656037)
+	for i := 0; i < len(name); i++ {
+		h ^= uint64(name[i])
+		h *= 1099511628211
+	}
+	return newSource(mix(s.key ^ h))
+}
+
+func (s *source) SplitN(n int) Source {
+	return newSource(mix(s.key ^ mix(uint64(n)+0x9e3779b97f4a7c15)))
+}
+
+// mix is the SplitMix64 finalizer, spreading every input bit over the key.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,84 @@
+package rng
+
+import (
+	"math"
+	"testing"
+)
+
+func draws(s Source, n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = s.Float64()
+	}
+	return values
+}
+
+func equal(a, b []float64) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
+
+func TestNew_SameSeedSameStream(t *testing.T) {
+	if !equal(draws(New(42), 100), draws(New(42), 100)) {
+		t.Error("expected the same seed to give the same numbers")
+	}
+	if equal(draws(New(42), 100), draws(New(43), 100)) {
+		t.Error("expected another seed to give other numbers")
+	}
+}
+
+func TestSplit_IndependentOfParentDraws(t *testing.T) {
+	fresh := New(7)
+	used := New(7)
+	draws(used, 50)
+	if !equal(draws(fresh.Split(Sensors), 20), draws(used.Split(Sensors), 20)) {
+		t.Error("expected a child not to depend on what was drawn from its parent")
+	}
+	if !equal(draws(fresh.SplitN(3), 20), draws(used.SplitN(3), 20)) {
+		t.Error("expected a numbered child not to depend on what was drawn from its parent")
+	}
+
+	children := map[string][]float64{
+		Sensors:  draws(fresh.Split(Sensors), 20),
+		Weather:  draws(fresh.Split(Weather), 20),
+		Pests:    draws(fresh.Split(Pests), 20),
+		"0":      draws(fresh.SplitN(0), 20),
+		"1":      draws(fresh.SplitN(1), 20),
+		"parent": draws(New(7), 20),
+	}
+	for a, first := range children {
+		for b, second := range children {
+			if a < b && equal(first, second) {
+				t.Errorf("expected the %s and %s streams to differ", a, b)
+			}
+		}
+	}
+}
+
+func TestSource_Distributions(t *testing.T) {
+	s := New(1).Split(Weather)
+	sum, squares := 0.0, 0.0
+	counts := make([]int, 4)
+	const n = 10000
+	for range n {
+		if f := s.Float64(); f < 0 || f >= 1 {
+			t.Fatalf("expected a number in [0, 1), got %v", f)
+		}
+		v := s.NormFloat64()
+		sum += v
+		squares += v * v
+		counts[s.IntN(4)]++
+	}
+	if mean, stddev := sum/n, math.Sqrt(squares/n); math.Abs(mean) > 0.05 || math.Abs(stddev-1) > 0.05 {
+		t.Errorf("expected a standard normal distribution, got mean %v and deviation %v", mean, stddev)
+	}
+	for i, count := range counts {
+		if math.Abs(float64(count)-n/4) > 300 {
+			t.Errorf("expected about %d draws of %d, got %d", n/4, i, count)
+		}
+	}
+}
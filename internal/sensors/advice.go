@@ -0,0 +1,110 @@
+package sensors
+
+import (
+	"fmt"
+	"greenhouse-simulator/internal/models"
+	"maps"
+	"slices"
+)
+
+// DefaultMaxPlantsPerSensor is the number of plants a soil moisture sensor
+// can stand for before AdviseSensorPlacement calls its section crowded.
+const DefaultMaxPlantsPerSensor = 10
+
+// AdviceCode identifies the rule an Advice comes from.
+type AdviceCode string
+
+const (
+	// AdviceUnmonitoredSection is a section with living plants and no soil
+	// moisture sensor.
+	AdviceUnmonitoredSection AdviceCode = "unmonitored_section"
+	// AdviceCrowdedSection is a section with more living plants per soil
+	// moisture sensor than AdvicePolicy.MaxPlantsPerSensor.
+	AdviceCrowdedSection AdviceCode = "crowded_section"
+	// AdviceMissingSensorType is a sensor type a feature of the greenhouse
+	// relies on, of which the greenhouse has no sensor.
+	AdviceMissingSensorType AdviceCode = "missing_sensor_type"
+)
+
+// Advice is a suggestion of where a sensor is missing. Code is the rule it
+// comes from, SectionID the section it is about, if any, SensorType the type
+// of sensor to add and Message the same advice for a person to read.
+type Advice struct {
+	Code       AdviceCode        `json:"code"`
+	SectionID  string            `json:"section,omitempty"`
+	SensorType models.SensorType `json:"sensor_type"`
+	Message    string            `json:"message"`
+}
+
+// SensorRequirement is a sensor type a feature of the greenhouse relies on,
+// such as a thermostat on temperature sensors.
+type SensorRequirement struct {
+	Feature string
+	Type    models.SensorType
+}
+
+// AdvicePolicy configures AdviseSensorPlacement. MaxPlantsPerSensor bounds
+// the living plants of a section per soil moisture sensor; zero leaves
+// crowded sections alone. Required lists the sensor types the features in
+// use rely on.
+type AdvicePolicy struct {
+	MaxPlantsPerSensor int
+	Required           []SensorRequirement
+}
+
+// AdviseSensorPlacement suggests where sensors are missing, with an Advice
+// for every section with living plants and no soil moisture sensor, every
+// section with more living plants per soil moisture sensor than the policy
+// allows, ordered by section ID, and then every required sensor type the
+// greenhouse has no sensor of, in the order of the policy. Dead plants need
+// no watering and are left out. Returns nil when nothing is missing.
+func AdviseSensorPlacement(plants []*models.Plant, sensors []*models.Sensor, policy AdvicePolicy) []Advice {
+	living := map[string]int{}
+	for _, p := range plants {
+		if p.Alive {
+			living[p.SectionID]++
+		}
+	}
+	moisture := map[string]int{}
+	types := map[models.SensorType]bool{}
+	for _, s := range sensors {
+		types[s.Type] = true
+		if s.Type == models.SoilMoisture {
+			moisture[s.SectionID]++
+		}
+	}
+
+	var advice []Advice
+	for _, sectionID := range slices.Sorted(maps.Keys(living)) {
+		plantCount, sensorCount := living[sectionID], moisture[sectionID]
+		switch {
+		case sensorCount == 0:
+			advice = append(advice, Advice{
+				Code:       AdviceUnmonitoredSection,
+				SectionID:  sectionID,
+				SensorType: models.SoilMoisture,
+				Message:    fmt.Sprintf("section %s has %d plants but no soil moisture sensor", sectionID, plantCount),
+			})
+		case policy.MaxPlantsPerSensor > 0 && plantCount > policy.MaxPlantsPerSensor*sensorCount:
+			advice = append(advice, Advice{
+				Code:       AdviceCrowdedSection,
+				SectionID:  sectionID,
+				SensorType: models.SoilMoisture,
+				Message: fmt.Sprintf("section %s has %d plants for %d soil moisture sensors, more than %d per sensor",
+					sectionID, plantCount, sensorCount, policy.MaxPlantsPerSensor),
+			})
+		}
+	}
+	for _, required := range policy.Required {
+		if types[required.Type] {
+			continue
+		}
+		types[required.Type] = true
+		advice = append(advice, Advice{
+			Code:       AdviceMissingSensorType,
+			SensorType: required.Type,
+			Message:    fmt.Sprintf("%s relies on %s sensors but the greenhouse has none", required.Feature, required.Type),
+		})
+	}
+	return advice
+}
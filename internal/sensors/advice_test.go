@@ -0,0 +1,68 @@
+package sensors
+
+import (
+	"greenhouse-simulator/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestAdviseSensorPlacement(t *testing.T) {
+	plant := func(sectionID string, alive bool) *models.Plant {
+		return &models.Plant{ID: "plant", SectionID: sectionID, Alive: alive}
+	}
+	sensor := func(sensorType models.SensorType, sectionID string) *models.Sensor {
+		return &models.Sensor{ID: "sensor", Type: sensorType, SectionID: sectionID}
+	}
+	crowded := []*models.Plant{plant("section-A", true), plant("section-A", true), plant("section-A", true)}
+	thermostat := []SensorRequirement{{Feature: "the thermostat", Type: models.Temperature}}
+
+	tests := []struct {
+		name     string
+		plants   []*models.Plant
+		sensors  []*models.Sensor
+		policy   AdvicePolicy
+		expected []Advice
+	}{
+		{"monitored section", []*models.Plant{plant("section-A", true)}, []*models.Sensor{sensor(models.SoilMoisture, "section-A")}, AdvicePolicy{}, nil},
+		{"unmonitored section", []*models.Plant{plant("section-A", true), plant("section-A", true)}, nil, AdvicePolicy{}, []Advice{{
+			Code: AdviceUnmonitoredSection, SectionID: "section-A", SensorType: models.SoilMoisture,
+			Message: "section section-A has 2 plants but no soil moisture sensor",
+		}}},
+		{"section watched by another type", []*models.Plant{plant("section-A", true)}, []*models.Sensor{sensor(models.Temperature, "section-A")}, AdvicePolicy{}, []Advice{{
+			Code: AdviceUnmonitoredSection, SectionID: "section-A", SensorType: models.SoilMoisture,
+			Message: "section section-A has 1 plants but no soil moisture sensor",
+		}}},
+		{"section of dead plants", []*models.Plant{plant("section-A", false)}, nil, AdvicePolicy{}, nil},
+		{"sensor without plants", nil, []*models.Sensor{sensor(models.SoilMoisture, "section-A")}, AdvicePolicy{}, nil},
+		{"crowded section", crowded, []*models.Sensor{sensor(models.SoilMoisture, "section-A")}, AdvicePolicy{MaxPlantsPerSensor: 2}, []Advice{{
+			Code: AdviceCrowdedSection, SectionID: "section-A", SensorType: models.SoilMoisture,
+			Message: "section section-A has 3 plants for 1 soil moisture sensors, more than 2 per sensor",
+		}}},
+		{"crowded section with enough sensors", crowded, []*models.Sensor{sensor(models.SoilMoisture, "section-A"), sensor(models.SoilMoisture, "section-A")}, AdvicePolicy{MaxPlantsPerSensor: 2}, nil},
+		{"section at the limit", crowded, []*models.Sensor{sensor(models.SoilMoisture, "section-A")}, AdvicePolicy{MaxPlantsPerSensor: 3}, nil},
+		{"crowding left alone", crowded, []*models.Sensor{sensor(models.SoilMoisture, "section-A")}, AdvicePolicy{}, nil},
+		{"missing sensor type", nil, []*models.Sensor{sensor(models.SoilMoisture, "section-A")}, AdvicePolicy{Required: thermostat}, []Advice{{
+			Code: AdviceMissingSensorType, SensorType: models.Temperature,
+			Message: "the thermostat relies on temperature sensors but the greenhouse has none",
+		}}},
+		{"required type in another section", nil, []*models.Sensor{sensor(models.Temperature, "section-B")}, AdvicePolicy{Required: thermostat}, nil},
+		{"type required twice", nil, nil, AdvicePolicy{Required: append(thermostat, SensorRequirement{Feature: "the heater", Type: models.Temperature})}, []Advice{{
+			Code: AdviceMissingSensorType, SensorType: models.Temperature,
+			Message: "the thermostat relies on temperature sensors but the greenhouse has none",
+		}}},
+		{"advice ordered by section", []*models.Plant{plant("section-B", true), plant("section-A", true)}, nil, AdvicePolicy{Required: thermostat}, []Advice{
+			{Code: AdviceUnmonitoredSection, SectionID: "section-A", SensorType: models.SoilMoisture, Message: "section section-A has 1 plants but no soil moisture sensor"},
+			{Code: AdviceUnmonitoredSection, SectionID: "section-B", SensorType: models.SoilMoisture, Message: "section section-B has 1 plants but no soil moisture sensor"},
+			{Code: AdviceMissingSensorType, SensorType: models.Temperature, Message: "the thermostat relies on temperature sensors but the greenhouse has none"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			advice := AdviseSensorPlacement(tt.plants, tt.sensors, tt.policy)
+			if !reflect.DeepEqual(advice, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, advice)
+			}
+		})
+	}
+}
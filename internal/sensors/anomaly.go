@@ -0,0 +1,200 @@
+package sensors
+
+import (
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/models"
+	"slices"
+)
+
+// ErrSensorDropout is returned when reading a sensor during an injected
+// dropout, see AnomalyDropout.
+var ErrSensorDropout = errors.New("sensor reading dropped out")
+
+// MaxAnomalyLabels is the number of anomaly labels kept for each sensor,
+// see SensorManager.GetAnomalyLabels; older ones are dropped.
+const MaxAnomalyLabels = 10_000
+
+// AnomalyKind is the shape of an anomaly injected into the readings of a
+// sensor, see SensorManager.InjectAnomaly.
+type AnomalyKind string
+
+const (
+	// AnomalySpike adds the magnitude to every reading.
+	AnomalySpike AnomalyKind = "spike"
+	// AnomalyFlatline repeats the first reading of the anomaly, as a stuck
+	// sensor does.
+	AnomalyFlatline AnomalyKind = "flatline"
+	// AnomalyDrift adds the magnitude once for every tick the anomaly has
+	// lasted, its first reading included, so the readings slowly walk away
+	// from the true value.
+	AnomalyDrift AnomalyKind = "drift"
+	// AnomalyDropout fails every reading with ErrSensorDropout.
+	AnomalyDropout AnomalyKind = "dropout"
+)
+
+// Anomaly is the spec of an anomaly: its Kind, the Magnitude of a spike or
+// of the drift per tick, and the number of Ticks it lasts.
+type Anomaly struct {
+	Kind      AnomalyKind `json:"kind"`
+	Magnitude float64     `json:"magnitude,omitempty"`
+	Ticks     int         `json:"ticks"`
+}
+
+// Validate checks that the anomaly is of a known kind, lasts at least one
+// tick, and that a spike or a drift has a magnitude.
+func (a Anomaly) Validate() error {
+	switch a.Kind {
+	case AnomalySpike, AnomalyDrift:
+		if a.Magnitude == 0 {
+			return fmt.Errorf("%s anomaly requires a magnitude", a.Kind)
+		}
+	case AnomalyFlatline, AnomalyDropout:
+	default:
+		return errors.New("anomaly must be spike, flatline, drift or dropout: " + string(a.Kind))
+	}
+	if a.Ticks < 1 {
+		return errors.New("anomaly ticks must be at least 1")
+	}
+	return nil
+}
+
+// AnomalyLabel is the ground truth of a reading: the Tick of a reading of the
+// sensor, or of a reading that dropped out, and the Kind of the anomaly that
+// changed it.
+type AnomalyLabel struct {
+	Tick int         `json:"tick"`
+	Kind AnomalyKind `json:"kind"`
+}
+
+// injectedAnomaly is an anomaly applied to the readings of a sensor from tick
+// start on. held is the reading a flatline repeats, once it has one.
+type injectedAnomaly struct {
+	Anomaly
+	start int
+	held  *float64
+}
+
+// active reports whether the anomaly applies to the readings of tick.
+func (a *injectedAnomaly) active(tick int) bool {
+	return tick >= a.start && tick < a.start+a.Ticks
+}
+
+// InjectAnomaly applies an anomaly to the readings of target, a sensor ID or
+// else a section whose sensors all get it, from the current tick on, on top
+// of the reading pipeline: after the noise, before the readings are kept in
+// the history. Anomalies of a sensor that overlap apply in the order they were
+// injected. The readings themselves are not marked; GetAnomalyLabels tells
+// which ones were changed. Returns an error if:
+// - the anomaly is invalid, see Anomaly.Validate
+// - target is neither a sensor nor a section with sensors (ErrSensorNotFound)
+//
+// This method is safe for concurrent use.
+func (s *sensorManager) InjectAnomaly(target string, anomaly Anomaly) error {
+	if err := anomaly.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var sensors []*models.Sensor
+	if sensor := s.sensorsByID[target]; sensor != nil {
+		sensors = []*models.Sensor{sensor}
+	} else if sensors = s.sensorsBySection[target]; len(sensors) == 0 {
+		return fmt.Errorf("%w: %s", ErrSensorNotFound, target)
+	}
+	tick := s.plantData.GetCurrentTick()
+	s.anomalyMu.Lock()
+	defer s.anomalyMu.Unlock()
+	for _, sensor := range sensors {
+		s.anomalies[sensor.ID] = append(s.anomalies[sensor.ID], &injectedAnomaly{Anomaly: anomaly, start: tick})
+	}
+	return nil
+}
+
+// GetAnomalyLabels returns the labels of the readings of a sensor an injected
+// anomaly changed or dropped, oldest first, at most MaxAnomalyLabels, for
+// scoring anomaly detectors. A tick has one label, the kind of the last
+// anomaly applied on it. Returns an error if no sensor has that ID.
+//
+// This method is safe for concurrent use.
+func (s *sensorManager) GetAnomalyLabels(sensorID string) ([]AnomalyLabel, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.sensorsByID[sensorID] == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSensorNotFound, sensorID)
+	}
+	s.anomalyMu.Lock()
+	defer s.anomalyMu.Unlock()
+	return slices.Clone(s.labels[sensorID]), nil
+}
+
+// dropped returns ErrSensorDropout when a dropout of the sensor applies on
+// tick, and labels the tick. Callers must hold s.mu.
+func (s *sensorManager) dropped(sensor *models.Sensor, tick int) error {
+	s.anomalyMu.Lock()
+	defer s.anomalyMu.Unlock()
+	for _, anomaly := range s.anomalies[sensor.ID] {
+		if anomaly.Kind == AnomalyDropout && anomaly.active(tick) {
+			s.label(sensor.ID, tick, AnomalyDropout)
+			return fmt.Errorf("%w: %s", ErrSensorDropout, sensor.ID)
+		}
+	}
+	return nil
+}
+
+// distort applies the anomalies of a sensor that are active on tick to the
+// value it read, labels the tick when one applied, and forgets the anomalies
+// that are over. Callers must hold s.mu.
+func (s *sensorManager) distort(sensor *models.Sensor, tick int, value float64) (float64, bool) {
+	s.anomalyMu.Lock()
+	defer s.anomalyMu.Unlock()
+	anomalies := s.anomalies[sensor.ID]
+	if len(anomalies) == 0 {
+		return value, false
+	}
+	var applied AnomalyKind
+	for _, anomaly := range anomalies {
+		if !anomaly.active(tick) {
+			continue
+		}
+		switch anomaly.Kind {
+		case AnomalySpike:
+			value += anomaly.Magnitude
+		case AnomalyDrift:
+			value += anomaly.Magnitude * float64(tick-anomaly.start+1)
+		case AnomalyFlatline:
+			if anomaly.held == nil {
+				held := value
+				anomaly.held = &held
+			}
+			value = *anomaly.held
+		}
+		applied = anomaly.Kind
+	}
+	s.anomalies[sensor.ID] = slices.DeleteFunc(anomalies, func(anomaly *injectedAnomaly) bool {
+		return tick >= anomaly.start+anomaly.Ticks
+	})
+	if len(s.anomalies[sensor.ID]) == 0 {
+		delete(s.anomalies, sensor.ID)
+	}
+	if applied == "" {
+		return value, false
+	}
+	s.label(sensor.ID, tick, applied)
+	return value, true
+}
+
+// label records that an anomaly of kind applied to the reading of tick.
+// Callers must hold s.anomalyMu.
+func (s *sensorManager) label(sensorID string, tick int, kind AnomalyKind) {
+	labels := s.labels[sensorID]
+	if n := len(labels); n > 0 && labels[n-1].Tick == tick {
+		labels[n-1].Kind = kind
+		return
+	}
+	labels = append(labels, AnomalyLabel{Tick: tick, Kind: kind})
+	if excess := len(labels) - MaxAnomalyLabels; excess > 0 {
+		labels = slices.Delete(labels, 0, excess)
+	}
+	s.labels[sensorID] = labels
+}
@@ -0,0 +1,126 @@
+package sensors
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/models"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestInjectAnomaly_Shapes(t *testing.T) {
+	// The true reading of tick n is 0.02n; the anomaly covers ticks 10 to 12.
+	tests := []struct {
+		anomaly  Anomaly
+		expected map[int]float64 // readings of the ticks it changes, NaN for none
+	}{
+		{Anomaly{Kind: AnomalySpike, Magnitude: 0.2, Ticks: 3}, map[int]float64{10: 0.4, 11: 0.42, 12: 0.44}},
+		{Anomaly{Kind: AnomalyFlatline, Ticks: 3}, map[int]float64{10: 0.2, 11: 0.2, 12: 0.2}},
+		{Anomaly{Kind: AnomalyDrift, Magnitude: 0.01, Ticks: 3}, map[int]float64{10: 0.21, 11: 0.24, 12: 0.27}},
+		{Anomaly{Kind: AnomalyDropout, Ticks: 3}, map[int]float64{10: math.NaN(), 11: math.NaN(), 12: math.NaN()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.anomaly.Kind), func(t *testing.T) {
+			plant := createTestPlant("plant-1", "section-A", 0)
+			data := &mockPlantDataSource{plantsBySectionID: map[string][]*models.Plant{"section-A": {plant}}}
+			manager := NewSensorManager(data, nil, nil)
+			if err := manager.AddSensor(&models.Sensor{ID: "sensor-1", Type: models.SoilMoisture, SectionID: "section-A"}); err != nil {
+				t.Fatalf("failed to add sensor: %v", err)
+			}
+
+			for tick := 8; tick < 16; tick++ {
+				data.tick = tick
+				plant.SoilSaturation = 0.02 * float64(tick)
+				if tick == 10 {
+					if err := manager.InjectAnomaly("sensor-1", tt.anomaly); err != nil {
+						t.Fatalf("failed to inject anomaly: %v", err)
+					}
+				}
+				expected, changed := tt.expected[tick]
+				if !changed {
+					expected = plant.SoilSaturation
+				}
+				reading, err := manager.GetReading("sensor-1")
+				if math.IsNaN(expected) {
+					if !errors.Is(err, ErrSensorDropout) {
+						t.Errorf("tick %d: expected the reading to drop out, got %v", tick, err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("tick %d: unexpected error: %v", tick, err)
+				}
+				if math.Abs(reading.Value-expected) > 1e-9 {
+					t.Errorf("tick %d: expected %.3f, got %.3f", tick, expected, reading.Value)
+				}
+			}
+
+			labels, err := manager.GetAnomalyLabels("sensor-1")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			kind := tt.anomaly.Kind
+			expected := []AnomalyLabel{{10, kind}, {11, kind}, {12, kind}}
+			if !reflect.DeepEqual(labels, expected) {
+				t.Errorf("expected labels %v, got %v", expected, labels)
+			}
+		})
+	}
+}
+
+func TestInjectAnomaly_Section(t *testing.T) {
+	data := &mockPlantDataSource{plantsBySectionID: map[string][]*models.Plant{"section-A": {createTestPlant("plant-1", "section-A", 0.9)}}}
+	manager := NewSensorManager(data, nil, nil)
+	for _, id := range []string{"sensor-1", "sensor-2"} {
+		if err := manager.AddSensor(&models.Sensor{ID: id, Type: models.SoilMoisture, SectionID: "section-A"}); err != nil {
+			t.Fatalf("failed to add sensor: %v", err)
+		}
+	}
+	if err := manager.InjectAnomaly("section-A", Anomaly{Kind: AnomalySpike, Magnitude: 0.5, Ticks: 1}); err != nil {
+		t.Fatalf("failed to inject anomaly: %v", err)
+	}
+
+	readings, err := manager.GetSectionReadings("section-A")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, reading := range readings {
+		// The spike cannot take the saturation above 1.0.
+		if reading.Value != 1 {
+			t.Errorf("expected %s to spike to 1.0, got %.3f", reading.SensorID, reading.Value)
+		}
+	}
+	data.tick++
+	if reading, err := manager.GetReading("sensor-1"); err != nil || reading.Value != 0.9 {
+		t.Errorf("expected the spike to be over, got %v (%v)", reading, err)
+	}
+}
+
+func TestInjectAnomaly_Errors(t *testing.T) {
+	data := &mockPlantDataSource{plantsBySectionID: map[string][]*models.Plant{}}
+	manager := NewSensorManager(data, nil, nil)
+	if err := manager.AddSensor(&models.Sensor{ID: "sensor-1", Type: models.SoilMoisture, SectionID: "section-A"}); err != nil {
+		t.Fatalf("failed to add sensor: %v", err)
+	}
+	tests := []struct {
+		name     string
+		target   string
+		anomaly  Anomaly
+		errorMsg string
+	}{
+		{"unknown kind", "sensor-1", Anomaly{Kind: "glitch", Ticks: 1}, "anomaly must be spike, flatline, drift or dropout: glitch"},
+		{"drift without magnitude", "sensor-1", Anomaly{Kind: AnomalyDrift, Ticks: 1}, "drift anomaly requires a magnitude"},
+		{"no ticks", "sensor-1", Anomaly{Kind: AnomalyDropout}, "anomaly ticks must be at least 1"},
+		{"unknown target", "section-B", Anomaly{Kind: AnomalyDropout, Ticks: 1}, "no sensor found for the provided ID: section-B"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := manager.InjectAnomaly(tt.target, tt.anomaly)
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package sensors
+
+import (
+	"greenhouse-simulator/internal/models"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// ReadLatencyBuckets are the upper bounds of the buckets of the read latency
+// histogram, see Diagnostics. A last bucket counts the reads slower than all
+// of them.
+var ReadLatencyBuckets = []time.Duration{
+	10 * time.Microsecond,
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+}
+
+// Diagnostics summarizes the sensor reads since the manager was created.
+// Latency is the histogram of the read durations, with one bucket per
+// ReadLatencyBuckets bound plus the slower reads, Reads their total, and
+// SlowReads counts the reads that took longer than the slow read threshold.
+// Reads of failed sensors are refused without measuring and are left out.
+// Sensors lists the registered sensors, ordered by ID; a removed sensor takes
+// its counters with it.
+type Diagnostics struct {
+	Reads     int64               `json:"reads"`
+	SlowReads int64               `json:"slow_reads"`
+	Latency   []LatencyBucket     `json:"latency"`
+	Sensors   []SensorDiagnostics `json:"sensors"`
+}
+
+// LatencyBucket counts the reads that took longer than the bound of the
+// previous bucket and at most UpTo, in nanoseconds. UpTo is zero for the
+// last bucket, which has no bound.
+type LatencyBucket struct {
+	UpTo  time.Duration `json:"up_to_ns"`
+	Count int64         `json:"count"`
+}
+
+// SensorDiagnostics counts the reads of a sensor. CacheHits are the soil
+// moisture reads answered with the sample the sensor last took, see
+// SectionActivitySource, and CacheHitRate their share of Reads. Errors
+// counts the reads that failed, including those of a failed sensor.
+type SensorDiagnostics struct {
+	ID           string  `json:"id"`
+	Reads        int64   `json:"reads"`
+	Errors       int64   `json:"errors"`
+	CacheHits    int64   `json:"cache_hits"`
+	CacheHitRate float64 `json:"cache_hit_rate"`
+}
+
+// readStats are the read counters of a sensor. They are registered with the
+// sensor and only incremented afterwards, so readers holding s.mu for
+// reading update them without further locking.
+type readStats struct {
+	reads     atomic.Int64
+	errors    atomic.Int64
+	cacheHits atomic.Int64
+}
+
+// readMetrics are the counters of every read.
+type readMetrics struct {
+	latency   [6]atomic.Int64 // one per ReadLatencyBuckets bound, then the slower reads
+	slowReads atomic.Int64
+	threshold atomic.Int64 // in nanoseconds, zero when slow reads are not counted
+	logger    atomic.Pointer[slog.Logger]
+}
+
+// record counts a read of sensor that took d and failed with err, and logs
+// it when it was slow. Callers must hold s.mu.
+func (s *sensorManager) record(sensor *models.Sensor, d time.Duration, err error) {
+	bucket, _ := slices.BinarySearch(ReadLatencyBuckets, d)
+	s.metrics.latency[bucket].Add(1)
+	if stats := s.stats[sensor.ID]; stats != nil {
+		stats.reads.Add(1)
+		if err != nil {
+			stats.errors.Add(1)
+		}
+	}
+	threshold := time.Duration(s.metrics.threshold.Load())
+	if threshold <= 0 || d <= threshold {
+		return
+	}
+	s.metrics.slowReads.Add(1)
+	logger := s.metrics.logger.Load()
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Warn("slow sensor read", "sensor", sensor.ID, "section", sensor.SectionID,
+		"plants", len(s.plantData.GetPlantsBySectionID(sensor.SectionID)), "duration", d)
+}
+
+// SetSlowReadThreshold logs a warning with the sensor ID, section, number of
+// plants in the section and duration of every read that takes longer than
+// threshold, on logger, or slog.Default when logger is nil, and counts it in
+// Diagnostics.SlowReads. A zero threshold turns both off.
+//
+// This method is safe for concurrent use.
+func (s *sensorManager) SetSlowReadThreshold(threshold time.Duration, logger *slog.Logger) {
+	s.metrics.logger.Store(logger)
+	s.metrics.threshold.Store(int64(threshold))
+}
+
+// GetDiagnostics returns the read counters since the manager was created.
+//
+// This method is safe for concurrent use.
+func (s *sensorManager) GetDiagnostics() Diagnostics {
+	var d Diagnostics
+	for i := range s.metrics.latency {
+		bucket := LatencyBucket{Count: s.metrics.latency[i].Load()}
+		if i < len(ReadLatencyBuckets) {
+			bucket.UpTo = ReadLatencyBuckets[i]
+		}
+		d.Latency = append(d.Latency, bucket)
+		d.Reads += bucket.Count
+	}
+	d.SlowReads = s.metrics.slowReads.Load()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	d.Sensors = make([]SensorDiagnostics, 0, len(s.stats))
+	for _, id := range slices.Sorted(maps.Keys(s.stats)) {
+		stats := s.stats[id]
+		sensor := SensorDiagnostics{
+			ID:        id,
+			Reads:     stats.reads.Load(),
+			Errors:    stats.errors.Load(),
+			CacheHits: stats.cacheHits.Load(),
+		}
+		if sensor.Reads > 0 {
+			sensor.CacheHitRate = float64(sensor.CacheHits) / float64(sensor.Reads)
+		}
+		d.Sensors = append(d.Sensors, sensor)
+	}
+	return d
+}
@@ -0,0 +1,130 @@
+package sensors
+
+import (
+	"bytes"
+	"greenhouse-simulator/internal/models"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// slowPlantDataSource takes delay to list the plants of a section.
+type slowPlantDataSource struct {
+	*mockPlantDataSource
+	delay time.Duration
+}
+
+func (s *slowPlantDataSource) GetPlantsBySectionID(sectionID string) []*models.Plant {
+	time.Sleep(s.delay)
+	return s.mockPlantDataSource.GetPlantsBySectionID(sectionID)
+}
+
+func TestGetDiagnostics_CountsReads(t *testing.T) {
+	sim, manager := newActivitySimulator(t)
+	if err := manager.AddSensor(&models.Sensor{ID: "sensor-C", Type: models.SoilMoisture, SectionID: "section-C"}); err != nil {
+		t.Fatalf("failed to add sensor: %v", err)
+	}
+	if err := manager.AddSensor(&models.Sensor{ID: "sensor-D", Type: models.SoilMoisture, SectionID: "section-A"}); err != nil {
+		t.Fatalf("failed to add sensor: %v", err)
+	}
+	if err := manager.FailSensor("sensor-D"); err != nil {
+		t.Fatalf("failed to fail sensor: %v", err)
+	}
+
+	// The dead section-B does not change after tick 0, so sensor-B measures
+	// it once on a later tick and is answered from its sample after.
+	sim.Step()
+	manager.GetReading("sensor-B")
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			manager.GetReading("sensor-B")
+		}()
+	}
+	wg.Wait()
+	manager.GetReading("sensor-C")
+	manager.GetReading("sensor-D")
+
+	d := manager.GetDiagnostics()
+	expected := []SensorDiagnostics{
+		{ID: "sensor-A"},
+		{ID: "sensor-B", Reads: 5, CacheHits: 4, CacheHitRate: 0.8},
+		{ID: "sensor-C", Reads: 1, Errors: 1},
+		{ID: "sensor-D", Reads: 1, Errors: 1},
+	}
+	if len(d.Sensors) != len(expected) {
+		t.Fatalf("expected %d sensors, got %+v", len(expected), d.Sensors)
+	}
+	for i, want := range expected {
+		if d.Sensors[i] != want {
+			t.Errorf("expected %+v, got %+v", want, d.Sensors[i])
+		}
+	}
+
+	// The refused read of the failed sensor is not timed.
+	if d.Reads != 6 || len(d.Latency) != len(ReadLatencyBuckets)+1 {
+		t.Fatalf("expected 6 timed reads in %d buckets, got %+v", len(ReadLatencyBuckets)+1, d)
+	}
+	total := int64(0)
+	for i, bucket := range d.Latency {
+		total += bucket.Count
+		if i < len(ReadLatencyBuckets) && bucket.UpTo != ReadLatencyBuckets[i] || i == len(ReadLatencyBuckets) && bucket.UpTo != 0 {
+			t.Errorf("unexpected bound for bucket %d: %v", i, bucket.UpTo)
+		}
+	}
+	if total != d.Reads || d.SlowReads != 0 {
+		t.Errorf("expected the buckets to add up to the reads and no slow reads, got %+v", d)
+	}
+
+	if err := manager.RemoveSensor("sensor-C"); err != nil {
+		t.Fatalf("failed to remove sensor: %v", err)
+	}
+	if d := manager.GetDiagnostics(); len(d.Sensors) != 3 || d.Reads != 6 {
+		t.Errorf("expected the removed sensor to take its counters only, got %+v", d)
+	}
+}
+
+func TestSetSlowReadThreshold_LogsSlowReads(t *testing.T) {
+	data := &slowPlantDataSource{
+		mockPlantDataSource: &mockPlantDataSource{plantsBySectionID: map[string][]*models.Plant{
+			"section-A": {createTestPlant("plant-1", "section-A", 0.5), createTestPlant("plant-2", "section-A", 0.7)},
+		}},
+		delay: 20 * time.Millisecond,
+	}
+	manager := NewSensorManager(data, nil, nil)
+	if err := manager.AddSensor(&models.Sensor{ID: "sensor-1", Type: models.SoilMoisture, SectionID: "section-A"}); err != nil {
+		t.Fatalf("failed to add sensor: %v", err)
+	}
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+	// Without a threshold nothing is slow.
+	if _, err := manager.GetReading("sensor-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	manager.SetSlowReadThreshold(5*time.Millisecond, logger)
+	if _, err := manager.GetReading("sensor-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data.delay = 0
+	if _, err := manager.GetReading("sensor-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if d := manager.GetDiagnostics(); d.SlowReads != 1 || d.Reads != 3 {
+		t.Errorf("expected 1 slow read out of 3, got %+v", d)
+	}
+	out := logs.String()
+	if strings.Count(out, "slow sensor read") != 1 {
+		t.Fatalf("expected one slow read warning, got %q", out)
+	}
+	for _, attr := range []string{"level=WARN", "sensor=sensor-1", "section=section-A", "plants=2", "duration="} {
+		if !strings.Contains(out, attr) {
+			t.Errorf("expected the warning to carry %s, got %q", attr, out)
+		}
+	}
+}
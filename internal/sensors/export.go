@@ -0,0 +1,19 @@
+package sensors
+
+import (
+	"encoding/json"
+	"greenhouse-simulator/internal/models"
+	"io"
+)
+
+// ExportReadings writes readings to w as JSON Lines, one reading per line,
+// so they can be stored alongside the exported watering history.
+func ExportReadings(w io.Writer, readings []*models.SensorReading) error {
+	encoder := json.NewEncoder(w)
+	for _, reading := range readings {
+		if err := encoder.Encode(reading); err != nil {
+			return err
+		}
+	}
+	return nil
+}
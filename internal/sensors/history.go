@@ -0,0 +1,152 @@
+package sensors
+
+import (
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/models"
+	"slices"
+)
+
+// HistorySize is the number of samples kept for each sensor, enough to
+// cover the longest rate window when the sensor is sampled every tick.
+const HistorySize = models.MaxRateWindow + 1
+
+var (
+	// ErrTickBeforeHistory is returned when reading a sensor as of a tick
+	// older than its oldest sample still held.
+	ErrTickBeforeHistory = errors.New("tick predates the sensor history")
+	// ErrTickInFuture is returned when reading a sensor as of a tick the
+	// simulation has not reached.
+	ErrTickInFuture = errors.New("tick is in the future")
+	// ErrNoSampleAtTick is returned, with HistoryLookupExact, when reading a
+	// sensor as of a tick it was not sampled on.
+	ErrNoSampleAtTick = errors.New("no sensor sample at tick")
+)
+
+// HistoryLookup is which sample GetReadingAt returns for a tick.
+type HistoryLookup string
+
+const (
+	// HistoryLookupNearestBefore returns the sample of the tick, or else the
+	// last one taken before it. It is the default.
+	HistoryLookupNearestBefore HistoryLookup = "nearest_before"
+	// HistoryLookupExact returns only the sample of the tick, failing with
+	// ErrNoSampleAtTick when there is none.
+	HistoryLookupExact HistoryLookup = "exact"
+)
+
+// remember adds a reading to the history of its sensor and fills in how fast
+// the value changes, see models.SensorReading. A sensor read again on the
+// tick of its last sample replaces that sample, so the extra reads do not
+// shorten the deltas. Callers must hold s.mu.
+func (s *sensorManager) remember(sensor *models.Sensor, reading *models.SensorReading) {
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+	history := s.history[sensor.ID]
+	if n := len(history); n > 0 && history[n-1].Tick >= reading.Tick {
+		// The ticks went back, for a restored snapshot: start over.
+		if history[n-1].Tick > reading.Tick {
+			history = nil
+		} else {
+			history = history[:n-1]
+		}
+	}
+	if n := len(history); n > 0 {
+		previous := history[n-1]
+		reading.HasDelta = true
+		reading.DeltaPerTick = (reading.Value - previous.Value) / float64(reading.Tick-previous.Tick)
+		oldest := previous
+		for _, sample := range history {
+			if reading.Tick-sample.Tick <= sensor.Window() {
+				oldest = sample
+				break
+			}
+		}
+		reading.Rate = (reading.Value - oldest.Value) / float64(reading.Tick-oldest.Tick)
+	}
+	history = append(history, *reading)
+	if excess := len(history) - HistorySize; excess > 0 {
+		history = slices.Delete(history, 0, excess)
+	}
+	s.history[sensor.ID] = history
+}
+
+// GetHistory returns up to lastN of the most recent samples of a sensor,
+// oldest first, with their deltas and rates. A non-positive lastN returns
+// every sample still held, at most HistorySize. Returns an error if no sensor
+// has that ID.
+//
+// This method is safe for concurrent use.
+func (s *sensorManager) GetHistory(sensorID string, lastN int) ([]*models.SensorReading, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.sensorsByID[sensorID] == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSensorNotFound, sensorID)
+	}
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+	history := s.history[sensorID]
+	if lastN > 0 && len(history) > lastN {
+		history = history[len(history)-lastN:]
+	}
+	readings := make([]*models.SensorReading, 0, len(history))
+	for _, sample := range history {
+		readings = append(readings, &sample)
+	}
+	return readings, nil
+}
+
+// SetHistoryLookup sets which sample GetReadingAt returns for a tick from the
+// next call on. Returns an error if the lookup is neither
+// HistoryLookupNearestBefore nor HistoryLookupExact.
+//
+// This method is safe for concurrent use.
+func (s *sensorManager) SetHistoryLookup(lookup HistoryLookup) error {
+	if lookup != HistoryLookupNearestBefore && lookup != HistoryLookupExact {
+		return fmt.Errorf("history lookup must be nearest_before or exact: %s", lookup)
+	}
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+	s.lookup = lookup
+	return nil
+}
+
+// GetReadingAt returns the sample a sensor took on tick, with its delta and
+// rate, or the last one it took before, see SetHistoryLookup. Only the
+// samples still in the history can be looked up. Returns an error if:
+// - no sensor has that ID (ErrSensorNotFound)
+// - tick is after the current tick (ErrTickInFuture)
+// - tick is before the oldest sample held, or the sensor has none
+// (ErrTickBeforeHistory)
+// - the sensor took no sample on tick, with HistoryLookupExact
+// (ErrNoSampleAtTick)
+//
+// This method is safe for concurrent use.
+func (s *sensorManager) GetReadingAt(sensorID string, tick int) (*models.SensorReading, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.sensorsByID[sensorID] == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSensorNotFound, sensorID)
+	}
+	if current := s.plantData.GetCurrentTick(); tick > current {
+		return nil, fmt.Errorf("%w: %d, the current tick is %d", ErrTickInFuture, tick, current)
+	}
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+	history := s.history[sensorID]
+	if len(history) == 0 || tick < history[0].Tick {
+		return nil, fmt.Errorf("%w: %s has no sample as old as tick %d", ErrTickBeforeHistory, sensorID, tick)
+	}
+	// The samples are ordered by tick, each tick at most once.
+	i, found := slices.BinarySearchFunc(history, tick, func(sample models.SensorReading, tick int) int {
+		return sample.Tick - tick
+	})
+	if !found {
+		if s.lookup == HistoryLookupExact {
+			return nil, fmt.Errorf("%w: %s at tick %d", ErrNoSampleAtTick, sensorID, tick)
+		}
+		i--
+	}
+	reading := history[i]
+	return &reading, nil
+}
@@ -2,47 +2,205 @@ package sensors
 
 import (
 	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/engine"
 	"greenhouse-simulator/internal/models"
+	"greenhouse-simulator/internal/rng"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 )
 
+var (
+	// ErrSensorNotFound is returned for a sensor ID the manager does not know.
+	ErrSensorNotFound = errors.New("no sensor found for the provided ID")
+	// ErrSensorExists is returned when adding a sensor whose ID is taken.
+	ErrSensorExists = errors.New("sensor with ID already exists")
+	// ErrSensorFailed is returned when reading a sensor marked as failed,
+	// or whose battery is depleted.
+	ErrSensorFailed = errors.New("sensor has failed")
+	// ErrBatteryDepleted is returned, with ErrSensorFailed, when reading a
+	// sensor whose battery is depleted.
+	ErrBatteryDepleted = errors.New("sensor battery is depleted")
+	// ErrNoBattery is returned when replacing the battery of a wired sensor.
+	ErrNoBattery = errors.New("sensor has no battery")
+	// ErrNoPlantsInSection is returned when reading a sensor whose section
+	// has no plants.
+	ErrNoPlantsInSection = errors.New("no plants in section")
+	// ErrSectionDead is returned when reading a soil moisture sensor whose
+	// section's plants are all dead, with DeadSectionError.
+	ErrSectionDead = errors.New("all plants in section are dead")
+	// ErrNoSensorsInSection is returned when reading a section without
+	// sensors.
+	ErrNoSensorsInSection = errors.New("no sensors in section")
+	// ErrNoConditions is returned when reading a temperature, humidity,
+	// light, CO2, salinity or soil temperature sensor without a source of
+	// air conditions.
+	ErrNoConditions = errors.New("no air conditions to read")
+)
+
+// DeadSectionPolicy is what soil moisture sensors read in a section whose
+// plants are all dead, as far as the plant data source can tell, see
+// SectionActivitySource.
+type DeadSectionPolicy string
+
+const (
+	// DeadSectionHold keeps reporting the value the sensors last measured:
+	// dead plants do not change, so the section is not measured again until
+	// a plant is added, removed or moved. It is the default.
+	DeadSectionHold DeadSectionPolicy = "hold"
+	// DeadSectionError fails the readings with ErrSectionDead.
+	DeadSectionError DeadSectionPolicy = "error"
+)
+
 // SensorManager manages all sensors in the greenhouse and provides
 // real-time readings grouped by plant sections.
 type SensorManager interface {
 	// AddSensor registers a new sensor in the system.
 	AddSensor(sensor *models.Sensor) error
+	// RemoveSensor unregisters a sensor.
+	RemoveSensor(sensorID string) error
+	// FailSensor marks a sensor as failed so it stops returning readings.
+	FailSensor(sensorID string) error
+	// ReplaceBattery puts a full battery in a wireless sensor.
+	ReplaceBattery(sensorID string) error
+	// GetStatus returns whether a sensor reads.
+	GetStatus(sensorID string) (SensorStatus, error)
+	// ListSensors returns every registered sensor, ordered by ID.
+	ListSensors() []*models.Sensor
 	// GetReading returns the current reading for a specific sensor.
 	GetReading(sensorID string) (*models.SensorReading, error)
 	// GetSectionReadings returns all sensor readings for a plant section.
 	GetSectionReadings(sectionID string) ([]*models.SensorReading, error)
 	// GetAverageSaturation calculates the average soil moisture for all sensors in a section.
 	GetAverageSaturation(sectionID string) (float64, error)
+	// SetDeadSectionPolicy sets what soil moisture sensors read in a
+	// section whose plants are all dead.
+	SetDeadSectionPolicy(policy DeadSectionPolicy) error
+	// SetStrictFilters sets whether AddSensor checks the plant filters of
+	// the sensors against the plants.
+	SetStrictFilters(strict bool)
+	// SetSlowReadThreshold sets the read duration above which a read is
+	// logged as slow.
+	SetSlowReadThreshold(threshold time.Duration, logger *slog.Logger)
+	// GetDiagnostics returns the read latencies and per-sensor counters.
+	GetDiagnostics() Diagnostics
+	// GetHistory returns the most recent samples of a sensor.
+	GetHistory(sensorID string, lastN int) ([]*models.SensorReading, error)
+	// GetReadingAt returns the sample a sensor took on a past tick, or the
+	// last one before it.
+	GetReadingAt(sensorID string, tick int) (*models.SensorReading, error)
+	// SetHistoryLookup sets whether GetReadingAt falls back to the last
+	// sample before a tick.
+	SetHistoryLookup(lookup HistoryLookup) error
+	// InjectAnomaly applies an anomaly to the readings of a sensor, or of
+	// every sensor of a section.
+	InjectAnomaly(target string, anomaly Anomaly) error
+	// GetAnomalyLabels returns which readings of a sensor an injected
+	// anomaly changed.
+	GetAnomalyLabels(sensorID string) ([]AnomalyLabel, error)
+	// ObserveWindows measures the sensors with a window on the current
+	// tick.
+	ObserveWindows()
+	// AssignNearestSensor returns the sensor of a plant's section nearest
+	// to it.
+	AssignNearestSensor(plantID string) (*models.Sensor, error)
+	// GetPlantsNearSensor returns the plants of a sensor's section within a
+	// radius of it.
+	GetPlantsNearSensor(sensorID string, radius float64) ([]*models.Plant, error)
 }
 
 type sensorManager struct {
 	sensorsBySection map[string][]*models.Sensor
 	sensorsByID      map[string]*models.Sensor
+	failed           map[string]bool
 	plantData        PlantDataSource
+	activity         SectionActivitySource // nil unless plantData is one
+	conditions       ConditionsSource
+	random           rng.Source
+	deadSections     DeadSectionPolicy
+	strictFilters    bool
+	stats            map[string]*readStats // by sensor ID, updated atomically
+	metrics          readMetrics
 	mu               sync.RWMutex
+	// samples holds the last soil moisture measured by each sensor. Readers
+	// share s.mu, so samplesMu guards it.
+	samples   map[string]sample
+	samplesMu sync.Mutex
+	// batteryMu guards the batteries of the sensors, which readers drain,
+	// and drainedAt, the tick each battery was last drained on.
+	batteryMu sync.Mutex
+	drainedAt map[string]int
+	// history holds the recent samples of each sensor, oldest first, see
+	// GetHistory, and lookup how GetReadingAt searches it. historyMu guards
+	// both.
+	history   map[string][]models.SensorReading
+	lookup    HistoryLookup
+	historyMu sync.Mutex
+	// anomalies holds the anomalies injected into the readings of each
+	// sensor that are not over, and labels the ticks they applied on, see
+	// InjectAnomaly. anomalyMu guards both.
+	anomalies map[string][]*injectedAnomaly
+	labels    map[string][]AnomalyLabel
+	anomalyMu sync.Mutex
+	// windows holds what each sensor with a window measured on the ticks
+	// of its window, oldest first, see ObserveWindows, and windowed the
+	// number of those sensors. windowMu guards windows.
+	windows  map[string][]sample
+	windowed int
+	windowMu sync.Mutex
+}
+
+// sample is a soil moisture measured while GetCurrentTick returned tick.
+type sample struct {
+	value float64
+	tick  int
 }
 
 // NewSensorManager creates and returns a new SensorManager instance.
 // The returned manager is initialized with empty maps for tracking sensors
-// by section and by ID, and is safe for concurrent use.
-func NewSensorManager(plantData PlantDataSource) SensorManager {
+// by section and by ID, and is safe for concurrent use. Soil moisture sensors
+// read plantData, and temperature, humidity and light sensors read
+// conditions, which may be nil when there are none. The noise of the
+// readings is drawn from random, usually the rng.Sensors stream of the
+// simulation; with a nil random, readings carry no noise. When plantData is
+// a SectionActivitySource, soil moisture is measured again only once the
+// plants of the section changed, and sections whose plants are all dead
+// follow the DeadSectionHold policy until SetDeadSectionPolicy changes it.
+// GetReadingAt falls back to the last sample before a tick until
+// SetHistoryLookup changes it.
+func NewSensorManager(plantData PlantDataSource, conditions ConditionsSource, random rng.Source) SensorManager {
+	activity, _ := plantData.(SectionActivitySource)
 	return &sensorManager{
 		sensorsBySection: make(map[string][]*models.Sensor),
 		sensorsByID:      map[string]*models.Sensor{},
+		failed:           map[string]bool{},
+		stats:            map[string]*readStats{},
 		plantData:        plantData,
+		activity:         activity,
+		conditions:       conditions,
+		random:           random,
+		deadSections:     DeadSectionHold,
+		samples:          map[string]sample{},
+		drainedAt:        map[string]int{},
+		history:          map[string][]models.SensorReading{},
+		lookup:           HistoryLookupNearestBefore,
+		anomalies:        map[string][]*injectedAnomaly{},
+		labels:           map[string][]AnomalyLabel{},
+		windows:          map[string][]sample{},
 	}
 }
 
 // AddSensor registers a new sensor in the system and associates it with a plant section.
-// The sensor must have a valid ID and SectionID. Returns an error if:
+// Sensors built by models.NewSensor are valid, but as the fields can change
+// after that, the sensor is validated again. Returns an error if:
 // - sensor is nil
-// - sensor ID is empty
-// - sensor section ID is empty
+// - sensor is invalid, see models.Sensor.Validate
+// - with strict filters, its plant filter refers to a plant type, tag or
+// plant that no plant has, see models.PlantFilter.Check
 // - a sensor with the same ID already exists
 //
 // This method is safe for concurrent use.
@@ -50,29 +208,209 @@ func (s *sensorManager) AddSensor(sensor *models.Sensor) error {
 	if sensor == nil {
 		return errors.New("sensor cannot be nil")
 	}
-	if sensor.ID == "" {
-		return errors.New("sensor ID cannot be empty")
-	}
-	if sensor.SectionID == "" {
-		return errors.New("sensor section ID cannot be empty")
+	if err := sensor.Validate(); err != nil {
+		return err
 	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if s.strictFilters && !sensor.Filter.Empty() {
+		if err := sensor.Filter.Check(s.plantData.GetAllPlants()); err != nil {
+			return fmt.Errorf("sensor %s: %w", sensor.ID, err)
+		}
+	}
 	if exists := s.sensorsByID[sensor.ID]; exists != nil {
-		return errors.New("sensor with ID already exists: " + sensor.ID)
+		return fmt.Errorf("%w: %s", ErrSensorExists, sensor.ID)
 	}
 
+	// The manager drains the battery of its own copy.
+	sensor = sensor.Clone()
 	s.sensorsByID[sensor.ID] = sensor
+	s.stats[sensor.ID] = &readStats{}
 	s.sensorsBySection[sensor.SectionID] = append(s.sensorsBySection[sensor.SectionID], sensor)
+	if sensor.WindowTicks > 0 {
+		s.windowed++
+	}
+
+	return nil
+}
+
+// RemoveSensor unregisters the sensor with the given ID.
+// Returns an error if no sensor has that ID.
+//
+// This method is safe for concurrent use.
+func (s *sensorManager) RemoveSensor(sensorID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
+	sensor := s.sensorsByID[sensorID]
+	if sensor == nil {
+		return fmt.Errorf("%w: %s", ErrSensorNotFound, sensorID)
+	}
+	delete(s.sensorsByID, sensorID)
+	delete(s.failed, sensorID)
+	delete(s.stats, sensorID)
+	delete(s.samples, sensorID)
+	delete(s.drainedAt, sensorID)
+	s.historyMu.Lock()
+	delete(s.history, sensorID)
+	s.historyMu.Unlock()
+	s.anomalyMu.Lock()
+	delete(s.anomalies, sensorID)
+	delete(s.labels, sensorID)
+	s.anomalyMu.Unlock()
+	if sensor.WindowTicks > 0 {
+		s.windowed--
+		s.windowMu.Lock()
+		delete(s.windows, sensorID)
+		s.windowMu.Unlock()
+	}
+	s.sensorsBySection[sensor.SectionID] = slices.DeleteFunc(s.sensorsBySection[sensor.SectionID], func(other *models.Sensor) bool {
+		return other.ID == sensorID
+	})
+	if len(s.sensorsBySection[sensor.SectionID]) == 0 {
+		delete(s.sensorsBySection, sensor.SectionID)
+	}
 	return nil
 }
 
+// FailSensor marks the sensor with the given ID as failed. A failed sensor
+// stays registered but GetReading returns an error for it until it is
+// removed. Returns an error if no sensor has that ID.
+//
+// This method is safe for concurrent use.
+func (s *sensorManager) FailSensor(sensorID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sensorsByID[sensorID] == nil {
+		return fmt.Errorf("%w: %s", ErrSensorNotFound, sensorID)
+	}
+	s.failed[sensorID] = true
+	return nil
+}
+
+// ReplaceBattery puts a full battery in the sensor with the given ID. A
+// sensor that stopped reading because its battery was depleted reads again;
+// one marked as failed stays failed. Returns an error if no sensor has that
+// ID, or it has no battery (ErrNoBattery).
+//
+// This method is safe for concurrent use.
+func (s *sensorManager) ReplaceBattery(sensorID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sensor := s.sensorsByID[sensorID]
+	if sensor == nil {
+		return fmt.Errorf("%w: %s", ErrSensorNotFound, sensorID)
+	}
+	if sensor.Battery == nil {
+		return fmt.Errorf("%w: %s", ErrNoBattery, sensorID)
+	}
+	s.batteryMu.Lock()
+	defer s.batteryMu.Unlock()
+	sensor.Battery.Level = 1
+	return nil
+}
+
+// SensorStatus is whether a sensor reads, see SensorManager.GetStatus.
+type SensorStatus string
+
+const (
+	// SensorOK is a sensor that reads, though an injected dropout may drop
+	// some of its readings.
+	SensorOK SensorStatus = "ok"
+	// SensorFailed is a sensor marked as failed, see FailSensor.
+	SensorFailed SensorStatus = "failed"
+	// SensorBatteryDepleted is a wireless sensor whose battery is depleted,
+	// see ReplaceBattery.
+	SensorBatteryDepleted SensorStatus = "battery_depleted"
+)
+
+// GetStatus returns whether the sensor with the given ID reads, as
+// GetReading would find it, without reading it. Returns an error if no
+// sensor has that ID.
+//
+// This method is safe for concurrent use.
+func (s *sensorManager) GetStatus(sensorID string) (SensorStatus, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sensor := s.sensorsByID[sensorID]
+	switch {
+	case sensor == nil:
+		return "", fmt.Errorf("%w: %s", ErrSensorNotFound, sensorID)
+	case s.failed[sensorID]:
+		return SensorFailed, nil
+	case sensor.Battery == nil:
+		return SensorOK, nil
+	}
+	s.batteryMu.Lock()
+	defer s.batteryMu.Unlock()
+	last, ok := s.drainedAt[sensorID]
+	if sensor.Battery.Depleted() && (!ok || last != s.plantData.GetCurrentTick()) {
+		return SensorBatteryDepleted, nil
+	}
+	return SensorOK, nil
+}
+
+// SetDeadSectionPolicy sets what soil moisture sensors read in a section
+// whose plants are all dead from the next reading on. It has no effect unless
+// the plant data source is a SectionActivitySource. Returns an error if the
+// policy is neither DeadSectionHold nor DeadSectionError.
+//
+// This method is safe for concurrent use.
+func (s *sensorManager) SetDeadSectionPolicy(policy DeadSectionPolicy) error {
+	if policy != DeadSectionHold && policy != DeadSectionError {
+		return fmt.Errorf("dead section policy must be hold or error: %s", policy)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deadSections = policy
+	return nil
+}
+
+// SetStrictFilters sets whether AddSensor checks the plant filters of the
+// sensors added from now on against the current plants. Strict filters are
+// off by default.
+//
+// This method is safe for concurrent use.
+func (s *sensorManager) SetStrictFilters(strict bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.strictFilters = strict
+}
+
+// ListSensors returns copies of every registered sensor, ordered by ID.
+//
+// This method is safe for concurrent use.
+func (s *sensorManager) ListSensors() []*models.Sensor {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	s.batteryMu.Lock()
+	defer s.batteryMu.Unlock()
+	sensors := make([]*models.Sensor, 0, len(s.sensorsByID))
+	for _, id := range slices.Sorted(maps.Keys(s.sensorsByID)) {
+		sensors = append(sensors, s.sensorsByID[id].Clone())
+	}
+	return sensors
+}
+
 // GetReading retrieves the current sensor reading for the specified sensor ID.
-// It calculates the reading value by averaging the soil saturation of all plants
-// in the sensor's associated section.
+// For a soil moisture sensor it calculates the reading value by averaging the
+// soil saturation of the plants in the sensor's associated section that match
+// its plant filter, at the sensor's depth; the other sensors read the current air conditions. A noisy
+// sensor adds its noise, drawn for the sensor and the current tick, so
+// reading it again before the next tick gives the same value and extra reads
+// do not change later ones. The soil of a section that did not change since
+// the sensor last measured it is not measured again, see
+// SectionActivitySource. The first reading of a wireless sensor on a tick
+// is its sample and drains its battery; once depleted, the sensor reads
+// nothing until ReplaceBattery. Every reading is kept as the sample of its
+// tick and compared to the previous samples of the sensor for its delta and
+// rate, see GetHistory.
 //
 // Parameters:
 //   - sensorID: The unique identifier of the sensor to get a reading from
@@ -80,41 +418,267 @@ func (s *sensorManager) AddSensor(sensor *models.Sensor) error {
 // Returns:
 //   - *models.SensorReading: A reading containing the sensor ID, current timestamp,
 //     and the calculated average soil saturation value
-//   - error: An error if the sensor ID is not found or if there are no plants
-//     in the sensor's section
+//   - error: An error if the sensor ID is not found, has failed, its
+//     battery is depleted or an injected anomaly drops the reading out, see
+//     InjectAnomaly, if no plant of a soil
+//     moisture sensor's section matches its filter, or only dead ones with
+//     DeadSectionError, or if there are no air conditions for the other
+//     sensors
 //
 // The method is safe for concurrent use as it acquires a read lock during execution.
 // The returned reading's Value field represents the average soil saturation percentage
-// across all plants in the sensor's section.
+// across all plants in the sensor's section, the temperature in Celsius, or the
+// humidity or light from 0.0 to 1.0.
 func (s *sensorManager) GetReading(sensorID string) (*models.SensorReading, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	sensor := s.sensorsByID[sensorID]
 	if sensor == nil {
-		return nil, errors.New("no sensor found for the provided ID: " + sensorID)
+		return nil, fmt.Errorf("%w: %s", ErrSensorNotFound, sensorID)
+	}
+	if err := s.broken(sensor); err != nil {
+		stats := s.stats[sensorID]
+		stats.reads.Add(1)
+		stats.errors.Add(1)
+		return nil, err
 	}
 
-	plants := s.plantData.GetPlantsBySectionID(sensor.SectionID)
-	if len(plants) == 0 {
-		return nil, errors.New("no plants in section: " + sensor.SectionID)
+	return s.read(sensor)
+}
+
+// broken returns the error reading a failed sensor, one in an injected
+// dropout, or one whose battery is depleted, gives. The sample that depleted
+// a battery can still be read on its tick. Callers must hold s.mu.
+func (s *sensorManager) broken(sensor *models.Sensor) error {
+	if s.failed[sensor.ID] {
+		return fmt.Errorf("%w: %s", ErrSensorFailed, sensor.ID)
 	}
-	total := 0.0
-	for _, plant := range plants {
-		total += plant.SoilSaturation
+	if err := s.dropped(sensor, s.plantData.GetCurrentTick()); err != nil {
+		return err
+	}
+	if sensor.Battery == nil {
+		return nil
+	}
+	s.batteryMu.Lock()
+	defer s.batteryMu.Unlock()
+	last, ok := s.drainedAt[sensor.ID]
+	if sensor.Battery.Depleted() && (!ok || last != s.plantData.GetCurrentTick()) {
+		return fmt.Errorf("%w: %w: %s", ErrSensorFailed, ErrBatteryDepleted, sensor.ID)
+	}
+	return nil
+}
+
+// read computes the reading of a sensor and records how long it took, see
+// GetDiagnostics. Callers must hold s.mu.
+func (s *sensorManager) read(sensor *models.Sensor) (*models.SensorReading, error) {
+	start := time.Now()
+	reading, err := s.take(sensor)
+	s.record(sensor, time.Since(start), err)
+	return reading, err
+}
+
+// take computes the reading of a sensor. Callers must hold s.mu.
+func (s *sensorManager) take(sensor *models.Sensor) (*models.SensorReading, error) {
+	tick := s.plantData.GetCurrentTick()
+	value, err := s.measure(sensor, tick)
+	if err != nil {
+		return nil, err
+	}
+	instant, partial := value, false
+	if sensor.WindowTicks > 0 {
+		s.observe(sensor, tick, value)
+		value, partial = s.windowMean(sensor, tick)
+	}
+	noisy := sensor.Noise > 0 && s.random != nil
+	if noisy {
+		noise := sensor.Noise * s.random.Split(sensor.ID).SplitN(tick).NormFloat64()
+		value += noise
+		instant += noise
+	}
+	value, distorted := s.distort(sensor, tick, value)
+	if noisy || distorted {
+		value, instant = clamp(sensor.Type, value), clamp(sensor.Type, instant)
+	}
+
+	reading := &models.SensorReading{
+		SensorID:      sensor.ID,
+		Tick:          tick,
+		Timestamp:     time.Now(),
+		Value:         value,
+		Battery:       s.drain(sensor, tick),
+		PartialWindow: partial,
+	}
+	if sensor.WindowTicks > 0 {
+		reading.Instant = &instant
 	}
-	average := total / float64(len(plants))
+	s.remember(sensor, reading)
+	return reading, nil
+}
 
-	return &models.SensorReading{
-		SensorID:  sensor.ID,
-		Timestamp: time.Now(),
-		Value:     average,
-	}, nil
+// clamp bounds a value read by a sensor of the given type to what the
+// sensor can read: CO2 cannot be negative, temperatures are not bounded, and
+// the humidity, light, soil moisture and salinity are between 0.0 and 1.0.
+func clamp(sensorType models.SensorType, value float64) float64 {
+	switch sensorType {
+	case models.Temperature, models.SoilTemperature, models.WaterTemperature:
+		return value
+	case models.CO2:
+		return max(0, value)
+	}
+	return min(1, max(0, value))
 }
 
+// drain drains the battery of a wireless sensor for its sample of tick,
+// unless it was drained on tick already, and returns the level it is left
+// at, nil for a wired sensor. Callers must hold s.mu.
+func (s *sensorManager) drain(sensor *models.Sensor, tick int) *float64 {
+	if sensor.Battery == nil {
+		return nil
+	}
+	s.batteryMu.Lock()
+	defer s.batteryMu.Unlock()
+	if last, ok := s.drainedAt[sensor.ID]; !ok || last != tick {
+		sensor.Battery.Sample()
+		s.drainedAt[sensor.ID] = tick
+	}
+	level := sensor.Battery.Level
+	return &level
+}
+
+// GetSectionReadings returns the current reading of every working sensor in
+// the section, ordered by sensor ID. Failed sensors, those whose battery
+// is depleted and those in an injected dropout are left out. Returns an
+// error if:
+// - no sensor is registered in the section (ErrNoSensorsInSection)
+// - the section has no plants (ErrNoPlantsInSection)
+// - the section's plants are all dead, with DeadSectionError (ErrSectionDead)
+//
+// This method is safe for concurrent use.
 func (s *sensorManager) GetSectionReadings(sectionID string) ([]*models.SensorReading, error) {
-	return nil, errors.New("not implemented")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sensors := s.sensorsBySection[sectionID]
+	if len(sensors) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoSensorsInSection, sectionID)
+	}
+
+	readings := []*models.SensorReading{}
+	for _, sensor := range sensors {
+		if s.broken(sensor) != nil {
+			continue
+		}
+		reading, err := s.read(sensor)
+		if err != nil {
+			return nil, err
+		}
+		readings = append(readings, reading)
+	}
+	slices.SortFunc(readings, func(a, b *models.SensorReading) int { return strings.Compare(a.SensorID, b.SensorID) })
+	return readings, nil
 }
 
+// measure returns the exact value a sensor reads on tick. Callers must hold
+// s.mu.
+func (s *sensorManager) measure(sensor *models.Sensor, tick int) (float64, error) {
+	switch sensor.Type {
+	case models.Temperature, models.Humidity, models.Light, models.CO2, models.Salinity, models.SoilTemperature, models.WaterTemperature:
+		if s.conditions == nil {
+			return 0, fmt.Errorf("%w: %s", ErrNoConditions, sensor.ID)
+		}
+		conditions := s.conditions.SectionConditions(sensor.SectionID)
+		switch sensor.Type {
+		case models.Temperature:
+			return conditions.Temperature, nil
+		case models.Humidity:
+			return conditions.Humidity, nil
+		case models.CO2:
+			return conditions.CO2, nil
+		case models.Salinity:
+			return conditions.Salinity, nil
+		case models.SoilTemperature:
+			return conditions.SoilTemperature, nil
+		case models.WaterTemperature:
+			return conditions.WaterTemperature, nil
+		}
+		return conditions.Light, nil
+	}
+	return s.soilMoisture(sensor, tick)
+}
+
+// soilMoisture returns the average soil saturation of the plants in the
+// section of a soil moisture sensor that match its filter, at its depth.
+// With a SectionActivitySource, it returns the sample the sensor last took
+// instead when the section did not change since, and applies the dead
+// section policy, to the plants that match the filter.
+// Callers must hold s.mu.
+func (s *sensorManager) soilMoisture(sensor *models.Sensor, tick int) (float64, error) {
+	tracked := false
+	if s.activity != nil {
+		var activity engine.SectionActivity
+		activity, tracked = s.activity.SectionActivity(sensor.SectionID)
+		if tracked && activity.Dead && s.deadSections == DeadSectionError {
+			return 0, fmt.Errorf("%w: %s", ErrSectionDead, sensor.SectionID)
+		}
+		s.samplesMu.Lock()
+		last, ok := s.samples[sensor.ID]
+		s.samplesMu.Unlock()
+		if tracked && ok && activity.ChangedAt < last.tick {
+			s.stats[sensor.ID].cacheHits.Add(1)
+			return last.value, nil
+		}
+	}
+	plants := s.plantData.GetPlantsBySectionID(sensor.SectionID)
+	if !sensor.Filter.Empty() {
+		plants = slices.DeleteFunc(slices.Clone(plants), func(plant *models.Plant) bool { return !sensor.Filter.Matches(plant) })
+		// The other plants of the section may keep it alive.
+		if tracked && s.deadSections == DeadSectionError && len(plants) > 0 && !slices.ContainsFunc(plants, func(plant *models.Plant) bool { return plant.Alive }) {
+			return 0, fmt.Errorf("%w: %s", ErrSectionDead, sensor.SectionID)
+		}
+	}
+	if len(plants) == 0 {
+		return 0, fmt.Errorf("%w: %s", ErrNoPlantsInSection, sensor.SectionID)
+	}
+	total := 0.0
+	for _, plant := range plants {
+		total += plant.SaturationAt(sensor.Depth)
+	}
+	value := total / float64(len(plants))
+	if tracked {
+		s.samplesMu.Lock()
+		if last, ok := s.samples[sensor.ID]; !ok || last.tick <= tick {
+			s.samples[sensor.ID] = sample{value: value, tick: tick}
+		}
+		s.samplesMu.Unlock()
+	}
+	return value, nil
+}
+
+// GetAverageSaturation returns the average value of the working soil
+// moisture sensors of a section, the mean over their window for the sensors
+// with one, see models.Sensor.WindowTicks. Reading them takes their sample of
+// the tick, as GetReading does. Returns an error if:
+// - the section has no soil moisture sensors, or none that works
+// (ErrNoSensorsInSection)
+// - one of them cannot be read, see GetReading
+//
+// This method is safe for concurrent use.
 func (s *sensorManager) GetAverageSaturation(sectionID string) (float64, error) {
-	return 0, errors.New("not implemented")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	total, n := 0.0, 0
+	for _, sensor := range s.sensorsBySection[sectionID] {
+		if sensor.Type != models.SoilMoisture || s.broken(sensor) != nil {
+			continue
+		}
+		reading, err := s.read(sensor)
+		if err != nil {
+			return 0, err
+		}
+		total += reading.Value
+		n++
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("%w: %s", ErrNoSensorsInSection, sectionID)
+	}
+	return total / float64(n), nil
 }
@@ -1,7 +1,13 @@
 package sensors
 
 import (
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/environment"
 	"greenhouse-simulator/internal/models"
+	"greenhouse-simulator/internal/rng"
+	"math"
 	"testing"
 	"time"
 )
@@ -10,6 +16,7 @@ import (
 type mockPlantDataSource struct {
 	plantsBySectionID map[string][]*models.Plant
 	allPlants         []*models.Plant
+	tick              int
 }
 
 func (m *mockPlantDataSource) GetPlantsBySectionID(sectionID string) []*models.Plant {
@@ -20,6 +27,10 @@ func (m *mockPlantDataSource) GetAllPlants() []*models.Plant {
 	return m.allPlants
 }
 
+func (m *mockPlantDataSource) GetCurrentTick() int {
+	return m.tick
+}
+
 // Helper function to create a test plant
 func createTestPlant(id, sectionID string, soilSaturation float64) *models.Plant {
 	plantType := models.PlantType{
@@ -41,7 +52,7 @@ func TestNewSensorManager(t *testing.T) {
 		plantsBySectionID: make(map[string][]*models.Plant),
 	}
 
-	manager := NewSensorManager(mockData)
+	manager := NewSensorManager(mockData, nil, nil)
 
 	if manager == nil {
 		t.Fatal("NewSensorManager returned nil")
@@ -90,6 +101,38 @@ func TestAddSensor(t *testing.T) {
 			expectError: true,
 			errorMsg:    "sensor section ID cannot be empty",
 		},
+		{
+			name: "unknown type",
+			sensor: &models.Sensor{
+				ID:        "sensor-1",
+				Type:      "pressure",
+				SectionID: "section-A",
+			},
+			expectError: true,
+			errorMsg:    "sensor sensor-1: sensor type must be soil_moisture, temperature, light, humidity, co2, salinity, soil_temperature or water_temperature: pressure",
+		},
+		{
+			name: "negative noise",
+			sensor: &models.Sensor{
+				ID:        "sensor-1",
+				Type:      models.SoilMoisture,
+				SectionID: "section-A",
+				Noise:     -0.1,
+			},
+			expectError: true,
+			errorMsg:    "sensor noise cannot be negative: sensor-1",
+		},
+		{
+			name: "unknown depth",
+			sensor: &models.Sensor{
+				ID:        "sensor-1",
+				Type:      models.SoilMoisture,
+				SectionID: "section-A",
+				Depth:     "bedrock",
+			},
+			expectError: true,
+			errorMsg:    "sensor sensor-1: soil depth must be surface or deep: bedrock",
+		},
 	}
 
 	for _, tt := range tests {
@@ -97,7 +140,7 @@ func TestAddSensor(t *testing.T) {
 			mockData := &mockPlantDataSource{
 				plantsBySectionID: make(map[string][]*models.Plant),
 			}
-			manager := NewSensorManager(mockData)
+			manager := NewSensorManager(mockData, nil, nil)
 
 			err := manager.AddSensor(tt.sensor)
 
@@ -120,7 +163,7 @@ func TestAddSensor_DuplicateID(t *testing.T) {
 	mockData := &mockPlantDataSource{
 		plantsBySectionID: make(map[string][]*models.Plant),
 	}
-	manager := NewSensorManager(mockData)
+	manager := NewSensorManager(mockData, nil, nil)
 
 	sensor1 := &models.Sensor{
 		ID:        "sensor-1",
@@ -160,7 +203,7 @@ func TestGetReading(t *testing.T) {
 		},
 	}
 
-	manager := NewSensorManager(mockData)
+	manager := NewSensorManager(mockData, nil, nil)
 
 	sensor := &models.Sensor{
 		ID:        "sensor-1",
@@ -206,7 +249,7 @@ func TestGetReading_SensorNotFound(t *testing.T) {
 	mockData := &mockPlantDataSource{
 		plantsBySectionID: make(map[string][]*models.Plant),
 	}
-	manager := NewSensorManager(mockData)
+	manager := NewSensorManager(mockData, nil, nil)
 
 	_, err := manager.GetReading("nonexistent-sensor")
 	if err == nil {
@@ -223,7 +266,7 @@ func TestGetReading_NoPlants(t *testing.T) {
 		},
 	}
 
-	manager := NewSensorManager(mockData)
+	manager := NewSensorManager(mockData, nil, nil)
 
 	sensor := &models.Sensor{
 		ID:        "sensor-1",
@@ -242,6 +285,784 @@ func TestGetReading_NoPlants(t *testing.T) {
 	}
 }
 
-// TODO: Add tests for GetSectionReadings once implemented
-// TODO: Add tests for GetAverageSaturation once implemented
+func TestRemoveSensor(t *testing.T) {
+	mockData := &mockPlantDataSource{
+		plantsBySectionID: map[string][]*models.Plant{
+			"section-A": {createTestPlant("plant-1", "section-A", 0.5)},
+		},
+	}
+	manager := NewSensorManager(mockData, nil, nil)
+	sensor := &models.Sensor{ID: "sensor-1", Type: models.SoilMoisture, SectionID: "section-A"}
+	if err := manager.AddSensor(sensor); err != nil {
+		t.Fatalf("failed to add sensor: %v", err)
+	}
+
+	if err := manager.RemoveSensor("sensor-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := manager.GetReading("sensor-1"); err == nil {
+		t.Error("expected removed sensor to have no reading, got nil error")
+	}
+	if err := manager.RemoveSensor("sensor-1"); err == nil {
+		t.Error("expected error when removing an unknown sensor, got nil")
+	}
+	// The ID is free again once removed
+	if err := manager.AddSensor(sensor); err != nil {
+		t.Errorf("expected sensor to be re-added, got %v", err)
+	}
+}
+
+func TestFailSensor(t *testing.T) {
+	mockData := &mockPlantDataSource{
+		plantsBySectionID: map[string][]*models.Plant{
+			"section-A": {createTestPlant("plant-1", "section-A", 0.5)},
+		},
+	}
+	manager := NewSensorManager(mockData, nil, nil)
+	sensor := &models.Sensor{ID: "sensor-1", Type: models.SoilMoisture, SectionID: "section-A"}
+	if err := manager.AddSensor(sensor); err != nil {
+		t.Fatalf("failed to add sensor: %v", err)
+	}
+
+	if err := manager.FailSensor("sensor-2"); err == nil {
+		t.Error("expected error when failing an unknown sensor, got nil")
+	}
+	if err := manager.FailSensor("sensor-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := manager.GetReading("sensor-1"); err == nil || err.Error() != "sensor has failed: sensor-1" {
+		t.Errorf("expected a failed sensor error, got %v", err)
+	}
+	if status, err := manager.GetStatus("sensor-1"); err != nil || status != SensorFailed {
+		t.Errorf("expected the sensor status to be failed, got %q, %v", status, err)
+	}
+	if _, err := manager.GetStatus("sensor-2"); !errors.Is(err, ErrSensorNotFound) {
+		t.Errorf("expected ErrSensorNotFound for the status of an unknown sensor, got %v", err)
+	}
+	// A replaced sensor starts working again
+	manager.RemoveSensor("sensor-1")
+	manager.AddSensor(sensor)
+	if _, err := manager.GetReading("sensor-1"); err != nil {
+		t.Errorf("expected the replaced sensor to read, got %v", err)
+	}
+}
+
+func TestBattery_DrainsPerSample(t *testing.T) {
+	mockData := &mockPlantDataSource{
+		plantsBySectionID: map[string][]*models.Plant{
+			"section-A": {createTestPlant("plant-1", "section-A", 0.5)},
+		},
+	}
+	manager := NewSensorManager(mockData, nil, nil)
+	if err := manager.AddSensor(&models.Sensor{ID: "sensor-1", Type: models.SoilMoisture, SectionID: "section-A", Battery: &models.Battery{Level: 1, Drain: 0.25}}); err != nil {
+		t.Fatalf("failed to add sensor: %v", err)
+	}
+	if err := manager.AddSensor(&models.Sensor{ID: "sensor-2", Type: models.SoilMoisture, SectionID: "section-A"}); err != nil {
+		t.Fatalf("failed to add sensor: %v", err)
+	}
+
+	// Every tick drains the battery once, however often it is read.
+	for tick, expected := range []float64{0.75, 0.5, 0.25, 0} {
+		mockData.tick = tick
+		for range 2 {
+			reading, err := manager.GetReading("sensor-1")
+			if err != nil {
+				t.Fatalf("tick %d: unexpected error: %v", tick, err)
+			}
+			if reading.Battery == nil || *reading.Battery != expected {
+				t.Fatalf("tick %d: expected the battery at %.2f, got %v", tick, expected, reading.Battery)
+			}
+		}
+		if level := manager.ListSensors()[0].Battery.Level; level != expected {
+			t.Errorf("tick %d: expected the listed battery at %.2f, got %v", tick, expected, level)
+		}
+	}
+	if reading, err := manager.GetReading("sensor-2"); err != nil || reading.Battery != nil {
+		t.Errorf("expected a wired sensor to read without a battery, got %+v, %v", reading, err)
+	}
+
+	if status, _ := manager.GetStatus("sensor-1"); status != SensorOK {
+		t.Errorf("expected the sample that depleted the battery to leave the sensor ok on its tick, got %q", status)
+	}
+
+	// The depleted sensor is dead from the next tick on.
+	mockData.tick = 4
+	if status, _ := manager.GetStatus("sensor-1"); status != SensorBatteryDepleted {
+		t.Errorf("expected the sensor status to be battery_depleted, got %q", status)
+	}
+	_, err := manager.GetReading("sensor-1")
+	if !errors.Is(err, ErrSensorFailed) || !errors.Is(err, ErrBatteryDepleted) {
+		t.Fatalf("expected a depleted battery, got %v", err)
+	}
+	if err.Error() != "sensor has failed: sensor battery is depleted: sensor-1" {
+		t.Errorf("unexpected error message: %v", err)
+	}
+	readings, err := manager.GetSectionReadings("section-A")
+	if err != nil || len(readings) != 1 || readings[0].SensorID != "sensor-2" {
+		t.Errorf("expected only the wired sensor in the section readings, got %v, %v", readings, err)
+	}
+
+	if err := manager.ReplaceBattery("sensor-2"); !errors.Is(err, ErrNoBattery) {
+		t.Errorf("expected ErrNoBattery for a wired sensor, got %v", err)
+	}
+	if err := manager.ReplaceBattery("sensor-9"); !errors.Is(err, ErrSensorNotFound) {
+		t.Errorf("expected ErrSensorNotFound, got %v", err)
+	}
+	if err := manager.ReplaceBattery("sensor-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status, _ := manager.GetStatus("sensor-1"); status != SensorOK {
+		t.Errorf("expected the sensor status to be ok with a new battery, got %q", status)
+	}
+	reading, err := manager.GetReading("sensor-1")
+	if err != nil || reading.Value != 0.5 || *reading.Battery != 0.75 {
+		t.Errorf("expected the replaced battery to read again, got %+v, %v", reading, err)
+	}
+}
+
+func TestBattery_FailedSensorStaysFailed(t *testing.T) {
+	mockData := &mockPlantDataSource{
+		plantsBySectionID: map[string][]*models.Plant{
+			"section-A": {createTestPlant("plant-1", "section-A", 0.5)},
+		},
+	}
+	manager := NewSensorManager(mockData, nil, nil)
+	if err := manager.AddSensor(&models.Sensor{ID: "sensor-1", Type: models.SoilMoisture, SectionID: "section-A", Battery: &models.Battery{Level: 0, Drain: 0.1}}); err != nil {
+		t.Fatalf("failed to add sensor: %v", err)
+	}
+	if _, err := manager.GetReading("sensor-1"); !errors.Is(err, ErrBatteryDepleted) {
+		t.Fatalf("expected a sensor added with a depleted battery to be dead, got %v", err)
+	}
+	if err := manager.FailSensor("sensor-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := manager.ReplaceBattery("sensor-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := manager.GetReading("sensor-1"); !errors.Is(err, ErrSensorFailed) || errors.Is(err, ErrBatteryDepleted) {
+		t.Errorf("expected the sensor to stay failed, got %v", err)
+	}
+}
+
+func TestListSensors(t *testing.T) {
+	manager := NewSensorManager(&mockPlantDataSource{}, nil, nil)
+	for _, id := range []string{"sensor-2", "sensor-1"} {
+		if err := manager.AddSensor(&models.Sensor{ID: id, Type: models.SoilMoisture, SectionID: "section-A"}); err != nil {
+			t.Fatalf("failed to add sensor: %v", err)
+		}
+	}
+
+	sensors := manager.ListSensors()
+	if len(sensors) != 2 || sensors[0].ID != "sensor-1" || sensors[1].ID != "sensor-2" {
+		t.Fatalf("expected sensors ordered by ID, got %v", sensors)
+	}
+	// The returned sensors are copies
+	sensors[0].SectionID = "section-B"
+	if manager.ListSensors()[0].SectionID != "section-A" {
+		t.Error("expected listed sensors not to alias the registered ones")
+	}
+}
+
+func TestGetSectionReadings(t *testing.T) {
+	plants := []*models.Plant{
+		createTestPlant("plant-1", "section-A", 0.4),
+		createTestPlant("plant-2", "section-A", 0.6),
+	}
+	manager := NewSensorManager(&mockPlantDataSource{plantsBySectionID: map[string][]*models.Plant{"section-A": plants}}, nil, nil)
+	for _, sensor := range []*models.Sensor{
+		{ID: "sensor-3", Type: models.SoilMoisture, SectionID: "section-A"},
+		{ID: "sensor-1", Type: models.SoilMoisture, SectionID: "section-A"},
+		{ID: "sensor-2", Type: models.SoilMoisture, SectionID: "section-A"},
+		{ID: "sensor-4", Type: models.SoilMoisture, SectionID: "section-B"},
+	} {
+		if err := manager.AddSensor(sensor); err != nil {
+			t.Fatalf("failed to add sensor: %v", err)
+		}
+	}
+	manager.FailSensor("sensor-2")
+
+	readings, err := manager.GetSectionReadings("section-A")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(readings) != 2 || readings[0].SensorID != "sensor-1" || readings[1].SensorID != "sensor-3" {
+		t.Fatalf("expected the working sensors ordered by ID, got %v", readings)
+	}
+	if readings[0].Value != 0.5 {
+		t.Errorf("expected reading 0.5, got %f", readings[0].Value)
+	}
+
+	if _, err := manager.GetSectionReadings("section-B"); !errors.Is(err, ErrNoPlantsInSection) {
+		t.Errorf("expected ErrNoPlantsInSection, got %v", err)
+	}
+	if _, err := manager.GetSectionReadings("section-C"); !errors.Is(err, ErrNoSensorsInSection) {
+		t.Errorf("expected ErrNoSensorsInSection, got %v", err)
+	}
+}
+
+func TestGetReading_Noise(t *testing.T) {
+	data := &mockPlantDataSource{plantsBySectionID: map[string][]*models.Plant{
+		"section-A": {createTestPlant("plant-1", "section-A", 0.5)},
+	}}
+	read := func(manager SensorManager, id string) float64 {
+		t.Helper()
+		reading, err := manager.GetReading(id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return reading.Value
+	}
+	newManager := func(seed int64) SensorManager {
+		manager := NewSensorManager(data, nil, rng.New(seed).Split(rng.Sensors))
+		for _, sensor := range []*models.Sensor{
+			{ID: "exact", Type: models.SoilMoisture, SectionID: "section-A"},
+			{ID: "noisy", Type: models.SoilMoisture, SectionID: "section-A", Noise: 0.05},
+			{ID: "other", Type: models.SoilMoisture, SectionID: "section-A", Noise: 0.05},
+		} {
+			if err := manager.AddSensor(sensor); err != nil {
+				t.Fatalf("failed to add sensor: %v", err)
+			}
+		}
+		return manager
+	}
+
+	manager, same, other := newManager(1), newManager(1), newManager(2)
+	values := map[float64]bool{}
+	for tick := range 20 {
+		data.tick = tick
+		if value := read(manager, "exact"); value != 0.5 {
+			t.Fatalf("expected an exact sensor to read 0.5, got %v", value)
+		}
+		value := read(manager, "noisy")
+		// Reading again within the tick, or reading another sensor, does
+		// not move the stream.
+		read(manager, "other")
+		if again := read(manager, "noisy"); again != value {
+			t.Fatalf("tick %d: expected the same reading within a tick, got %v and %v", tick, value, again)
+		}
+		if value < 0.3 || value > 0.7 {
+			t.Errorf("tick %d: expected the noise to stay near 0.5, got %v", tick, value)
+		}
+		if got := read(same, "noisy"); got != value {
+			t.Errorf("tick %d: expected the same seed to read %v, got %v", tick, value, got)
+		}
+		values[value] = true
+		values[read(other, "noisy")] = true
+		values[read(manager, "other")] = true
+	}
+	if len(values) < 55 {
+		t.Errorf("expected noise to differ between ticks, sensors and seeds, got %d distinct values", len(values))
+	}
+}
+
+// conditionsFunc adapts a function to ConditionsSource.
+type conditionsFunc func() environment.Conditions
+
+func (f conditionsFunc) SectionConditions(string) environment.Conditions { return f() }
+
+func TestGetReading_AirConditions(t *testing.T) {
+	conditions := environment.Conditions{Temperature: -4, Humidity: 0.7, Light: 0.2, CO2: 650, Salinity: 0.3}
+	sensorsOf := func(manager SensorManager) {
+		t.Helper()
+		for _, sensor := range []*models.Sensor{
+			{ID: "temp", Type: models.Temperature, SectionID: "section-A"},
+			{ID: "humidity", Type: models.Humidity, SectionID: "section-A"},
+			{ID: "light", Type: models.Light, SectionID: "section-A"},
+			{ID: "co2", Type: models.CO2, SectionID: "section-A"},
+			{ID: "salinity", Type: models.Salinity, SectionID: "section-A"},
+		} {
+			if err := manager.AddSensor(sensor); err != nil {
+				t.Fatalf("failed to add sensor: %v", err)
+			}
+		}
+	}
+
+	// Air sensors read the conditions even where there are no plants.
+	manager := NewSensorManager(&mockPlantDataSource{}, conditionsFunc(func() environment.Conditions { return conditions }), nil)
+	sensorsOf(manager)
+	for id, expected := range map[string]float64{"temp": -4, "humidity": 0.7, "light": 0.2, "co2": 650, "salinity": 0.3} {
+		reading, err := manager.GetReading(id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if reading.Value != expected {
+			t.Errorf("expected %s to read %v, got %v", id, expected, reading.Value)
+		}
+	}
+	conditions.Temperature = 30
+	if reading, _ := manager.GetReading("temp"); reading.Value != 30 {
+		t.Errorf("expected the reading to follow the conditions, got %v", reading.Value)
+	}
+
+	without := NewSensorManager(&mockPlantDataSource{}, nil, nil)
+	sensorsOf(without)
+	if _, err := without.GetReading("temp"); !errors.Is(err, ErrNoConditions) {
+		t.Errorf("expected ErrNoConditions, got %v", err)
+	}
+}
+
+// newActivitySimulator returns a simulator with a living plant in section-A
+// and a dead one in section-B, and a manager with a soil moisture sensor in
+// each, sensor-A and sensor-B.
+func newActivitySimulator(t *testing.T) (engine.Simulator, SensorManager) {
+	t.Helper()
+	sim := engine.NewSimulator(time.Hour)
+	dead := createTestPlant("plant-2", "section-B", 0.4)
+	dead.Alive = false
+	for _, plant := range []*models.Plant{createTestPlant("plant-1", "section-A", 0.6), dead} {
+		if err := sim.AddPlant(plant); err != nil {
+			t.Fatalf("failed to add plant: %v", err)
+		}
+	}
+	manager := NewSensorManager(sim, nil, nil)
+	for _, sectionID := range []string{"section-A", "section-B"} {
+		if err := manager.AddSensor(&models.Sensor{ID: "sensor-" + sectionID[len(sectionID)-1:], Type: models.SoilMoisture, SectionID: sectionID}); err != nil {
+			t.Fatalf("failed to add sensor: %v", err)
+		}
+	}
+	return sim, manager
+}
+
+func TestGetReading_ChangedSections(t *testing.T) {
+	sim, manager := newActivitySimulator(t)
+	read := func(sensorID string) float64 {
+		t.Helper()
+		reading, err := manager.GetReading(sensorID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return reading.Value
+	}
+
+	// Ticks dry the soil of living plants out.
+	before := read("sensor-A")
+	sim.Step()
+	if after := read("sensor-A"); after >= before {
+		t.Errorf("expected the reading to follow the plants of a changed section, got %v then %v", before, after)
+	}
+
+	// A section that did not change is not measured again: what the
+	// simulator cannot see is held.
+	if got := read("sensor-B"); got != 0.4 {
+		t.Fatalf("expected 0.4, got %v", got)
+	}
+	sim.GetPlantsBySectionID("section-B")[0].SoilSaturation = 0.9
+	sim.Step()
+	if got := read("sensor-B"); got != 0.4 {
+		t.Errorf("expected the unchanged section to hold 0.4, got %v", got)
+	}
+
+	// What the simulator changes is measured.
+	plant := createTestPlant("plant-3", "section-B", 0.3)
+	plant.Alive = false
+	if err := sim.AddPlant(plant); err != nil {
+		t.Fatalf("failed to add plant: %v", err)
+	}
+	if got := read("sensor-B"); got != 0.6 {
+		t.Errorf("expected the added plant to be measured with the other, 0.6, got %v", got)
+	}
+	if err := sim.RemovePlant("plant-3"); err != nil {
+		t.Fatalf("failed to remove plant: %v", err)
+	}
+	if got := read("sensor-B"); got != 0.9 {
+		t.Errorf("expected the removal to be measured, 0.9, got %v", got)
+	}
+	if err := sim.TransplantPlant("plant-1", "section-B"); err != nil {
+		t.Fatalf("failed to transplant: %v", err)
+	}
+	if _, err := manager.GetReading("sensor-A"); !errors.Is(err, ErrNoPlantsInSection) {
+		t.Errorf("expected ErrNoPlantsInSection once section-A is empty, got %v", err)
+	}
+	if got := read("sensor-B"); got >= 0.9 {
+		t.Errorf("expected the moved in plant to be measured, got %v", got)
+	}
+}
+
+func TestDeadSectionPolicy(t *testing.T) {
+	tests := []struct {
+		policy      DeadSectionPolicy
+		expectError error
+	}{
+		{DeadSectionHold, nil},
+		{DeadSectionError, ErrSectionDead},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			sim, manager := newActivitySimulator(t)
+			if err := manager.SetDeadSectionPolicy(tt.policy); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			sim.Step()
+			reading, err := manager.GetReading("sensor-B")
+			if !errors.Is(err, tt.expectError) {
+				t.Fatalf("expected error %v, got %v", tt.expectError, err)
+			}
+			if err == nil && reading.Value != 0.4 {
+				t.Errorf("expected the dead section to read 0.4, got %v", reading.Value)
+			}
+			if _, err := manager.GetReading("sensor-A"); err != nil {
+				t.Errorf("expected the living section to be read, got %v", err)
+			}
+		})
+	}
+
+	_, manager := newActivitySimulator(t)
+	if err := manager.SetDeadSectionPolicy("guess"); err == nil || err.Error() != "dead section policy must be hold or error: guess" {
+		t.Errorf("expected an unknown policy to be refused, got %v", err)
+	}
+}
+
+// newMixedSection returns a data source with two tagged tomatoes at 0.8 and
+// 0.6 and a basil at 0.1 in section-A.
+func newMixedSection() *mockPlantDataSource {
+	plant := func(id, typeName string, saturation float64, tags ...string) *models.Plant {
+		p := createTestPlant(id, "section-A", saturation)
+		plantType := *p.Type
+		plantType.Name = typeName
+		p.Type = &plantType
+		p.Tags = tags
+		return p
+	}
+	plants := []*models.Plant{plant("tomato-1", "Tomato", 0.8, "bed"), plant("tomato-2", "Tomato", 0.6, "bed"), plant("basil-1", "Basil", 0.1)}
+	return &mockPlantDataSource{plantsBySectionID: map[string][]*models.Plant{"section-A": plants}, allPlants: plants}
+}
+
+func TestGetReading_PlantFilter(t *testing.T) {
+	tests := []struct {
+		name        string
+		filter      models.PlantFilter
+		expected    float64
+		expectError error
+	}{
+		{"no filter", models.PlantFilter{}, 0.5, nil},
+		{"by type", models.PlantFilter{Type: "Tomato"}, 0.7, nil},
+		{"by tag", models.PlantFilter{Tag: "bed"}, 0.7, nil},
+		{"by ID", models.PlantFilter{PlantIDs: []string{"tomato-1", "basil-1"}}, 0.45, nil},
+		{"every criterion", models.PlantFilter{Type: "Tomato", Tag: "bed", PlantIDs: []string{"tomato-2", "basil-1"}}, 0.6, nil},
+		{"no match", models.PlantFilter{Type: "Basil", Tag: "bed"}, 0, ErrNoPlantsInSection},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewSensorManager(newMixedSection(), nil, nil)
+			if err := manager.AddSensor(&models.Sensor{ID: "sensor-1", Type: models.SoilMoisture, SectionID: "section-A", Filter: tt.filter}); err != nil {
+				t.Fatalf("failed to add sensor: %v", err)
+			}
+			reading, err := manager.GetReading("sensor-1")
+			if !errors.Is(err, tt.expectError) {
+				t.Fatalf("expected error %v, got %v", tt.expectError, err)
+			}
+			if err == nil && math.Abs(reading.Value-tt.expected) > 1e-9 {
+				t.Errorf("expected %v, got %v", tt.expected, reading.Value)
+			}
+		})
+	}
+}
+
+func TestAddSensor_StrictFilters(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   models.PlantFilter
+		errorMsg string
+	}{
+		{"known", models.PlantFilter{Type: "Tomato", Tag: "bed", PlantIDs: []string{"basil-1"}}, ""},
+		{"unknown type", models.PlantFilter{Type: "Pepper"}, "sensor sensor-1: filter refers to an unknown plant type: Pepper"},
+		{"unknown tag", models.PlantFilter{Tag: "pots"}, "sensor sensor-1: filter refers to an unknown tag: pots"},
+		{"unknown plant", models.PlantFilter{PlantIDs: []string{"tomato-9"}}, "sensor sensor-1: filter refers to an unknown plant: tomato-9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sensor := &models.Sensor{ID: "sensor-1", Type: models.SoilMoisture, SectionID: "section-A", Filter: tt.filter}
+			manager := NewSensorManager(newMixedSection(), nil, nil)
+			manager.SetStrictFilters(true)
+			err := manager.AddSensor(sensor)
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+			}
+
+			// Without strict filters, the sensor reads no plants instead.
+			manager = NewSensorManager(newMixedSection(), nil, nil)
+			if err := manager.AddSensor(sensor); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, err := manager.GetReading("sensor-1"); !errors.Is(err, ErrNoPlantsInSection) {
+				t.Errorf("expected ErrNoPlantsInSection, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDeadSectionPolicy_PlantFilter(t *testing.T) {
+	sim := engine.NewSimulator(time.Hour)
+	dead := createTestPlant("plant-2", "section-A", 0.4)
+	dead.Alive = false
+	for _, plant := range []*models.Plant{createTestPlant("plant-1", "section-A", 0.6), dead} {
+		if err := sim.AddPlant(plant); err != nil {
+			t.Fatalf("failed to add plant: %v", err)
+		}
+	}
+	manager := NewSensorManager(sim, nil, nil)
+	if err := manager.SetDeadSectionPolicy(DeadSectionError); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for id, plantID := range map[string]string{"sensor-dead": "plant-2", "sensor-living": "plant-1"} {
+		if err := manager.AddSensor(&models.Sensor{ID: id, Type: models.SoilMoisture, SectionID: "section-A", Filter: models.PlantFilter{PlantIDs: []string{plantID}}}); err != nil {
+			t.Fatalf("failed to add sensor: %v", err)
+		}
+	}
+	sim.Step()
+
+	// The living plant keeps the section alive, but not the filtered plants.
+	if _, err := manager.GetReading("sensor-dead"); !errors.Is(err, ErrSectionDead) {
+		t.Errorf("expected ErrSectionDead for the dead plant, got %v", err)
+	}
+	if _, err := manager.GetReading("sensor-living"); err != nil {
+		t.Errorf("expected the living plant to be read, got %v", err)
+	}
+	if err := manager.SetDeadSectionPolicy(DeadSectionHold); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reading, err := manager.GetReading("sensor-dead"); err != nil || reading.Value != 0.4 {
+		t.Errorf("expected the dead plant to read 0.4 with the hold policy, got %v, %v", reading, err)
+	}
+}
+
+// BenchmarkGetReading_SparseChanges reads 5000 soil moisture sensors, 5 in
+// each of 1000 sections of 20 plants, after every tick, with the plants of
+// only 5% of the sections alive and so changing. Eager hides the section
+// activity of the simulator from the manager, which then measures every
+// section on every reading.
+func BenchmarkGetReading_SparseChanges(b *testing.B) {
+	sim := engine.NewSimulator(time.Hour)
+	var sensors []*models.Sensor
+	for section := range 1000 {
+		sectionID := fmt.Sprintf("section-%d", section)
+		for i := range 20 {
+			plant := createTestPlant(fmt.Sprintf("plant-%d-%d", section, i), sectionID, 0.6)
+			plant.Alive = section%20 == 0
+			if err := sim.AddPlant(plant); err != nil {
+				b.Fatalf("failed to add plant: %v", err)
+			}
+		}
+		for i := range 5 {
+			sensors = append(sensors, &models.Sensor{ID: fmt.Sprintf("sensor-%d-%d", section, i), Type: models.SoilMoisture, SectionID: sectionID})
+		}
+	}
+	for _, bench := range []struct {
+		name string
+		data PlantDataSource
+	}{
+		{"lazy", sim},
+		{"eager", struct{ PlantDataSource }{sim}},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			manager := NewSensorManager(bench.data, nil, nil)
+			for _, sensor := range sensors {
+				if err := manager.AddSensor(sensor); err != nil {
+					b.Fatalf("failed to add sensor: %v", err)
+				}
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				b.StopTimer()
+				sim.Step()
+				b.StartTimer()
+				for _, sensor := range sensors {
+					if _, err := manager.GetReading(sensor.ID); err != nil {
+						b.Fatalf("unexpected error: %v", err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGetAverageSaturation(t *testing.T) {
+	plant := createTestPlant("plant-1", "section-A", 0.4)
+	mockData := &mockPlantDataSource{plantsBySectionID: map[string][]*models.Plant{"section-A": {plant}}}
+	manager := NewSensorManager(mockData, conditionsFunc(func() environment.Conditions { return environment.Conditions{Temperature: 20} }), nil)
+	for _, sensor := range []*models.Sensor{
+		{ID: "instant", Type: models.SoilMoisture, SectionID: "section-A"},
+		{ID: "windowed", Type: models.SoilMoisture, SectionID: "section-A", WindowTicks: 2},
+		{ID: "failed", Type: models.SoilMoisture, SectionID: "section-A"},
+		{ID: "thermometer", Type: models.Temperature, SectionID: "section-A"},
+		{ID: "thermometer-B", Type: models.Temperature, SectionID: "section-B"},
+	} {
+		if err := manager.AddSensor(sensor); err != nil {
+			t.Fatalf("failed to add sensor: %v", err)
+		}
+	}
+	if err := manager.FailSensor("failed"); err != nil {
+		t.Fatalf("failed to fail sensor: %v", err)
+	}
+	manager.ObserveWindows()
+	mockData.tick, plant.SoilSaturation = 1, 0.8
+
+	// The windowed sensor reads (0.4 + 0.8) / 2, the other one 0.8.
+	average, err := manager.GetAverageSaturation("section-A")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(average-0.7) > 1e-9 {
+		t.Errorf("expected an average of 0.7, got %.3f", average)
+	}
+	for _, sectionID := range []string{"section-B", "section-C"} {
+		if _, err := manager.GetAverageSaturation(sectionID); !errors.Is(err, ErrNoSensorsInSection) {
+			t.Errorf("%s: expected ErrNoSensorsInSection, got %v", sectionID, err)
+		}
+	}
+}
+
 // TODO: Consider adding concurrent access tests to verify thread-safety
+
+func TestGetHistory_RateOfChange(t *testing.T) {
+	plant := createTestPlant("plant-1", "section-A", 0.75)
+	mockData := &mockPlantDataSource{
+		plantsBySectionID: map[string][]*models.Plant{"section-A": {plant}},
+	}
+	manager := NewSensorManager(mockData, nil, nil)
+	if err := manager.AddSensor(&models.Sensor{ID: "sensor-1", Type: models.SoilMoisture, SectionID: "section-A", RateWindow: 2}); err != nil {
+		t.Fatalf("failed to add sensor: %v", err)
+	}
+
+	// The saturations are exact in binary, so are the deltas. Tick 5 is not
+	// sampled, so the sample of tick 6 is compared to that of tick 4.
+	tests := []struct {
+		tick       int
+		saturation float64
+		hasDelta   bool
+		delta      float64
+		rate       float64
+	}{
+		{0, 0.75, false, 0, 0},
+		{1, 0.625, true, -0.125, -0.125},
+		{2, 0.5, true, -0.125, -0.125},
+		{3, 0.5, true, 0, -0.0625},
+		{4, 0.25, true, -0.25, -0.125},
+		{6, 0.125, true, -0.0625, -0.0625},
+	}
+	for _, tt := range tests {
+		mockData.tick = tt.tick
+		plant.SoilSaturation = tt.saturation
+		// Reading again on the same tick gives the same delta.
+		for range 2 {
+			reading, err := manager.GetReading("sensor-1")
+			if err != nil {
+				t.Fatalf("tick %d: unexpected error: %v", tt.tick, err)
+			}
+			if reading.Tick != tt.tick || reading.HasDelta != tt.hasDelta || reading.DeltaPerTick != tt.delta || reading.Rate != tt.rate {
+				t.Errorf("tick %d: expected a delta of %v and a rate of %v (has delta %t), got %+v",
+					tt.tick, tt.delta, tt.rate, tt.hasDelta, reading)
+			}
+		}
+	}
+
+	history, err := manager.GetHistory("sensor-1", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(history) != 2 || history[0].Tick != 4 || history[1].Tick != 6 || history[1].DeltaPerTick != -0.0625 {
+		t.Errorf("expected the samples of ticks 4 and 6, got %+v", history)
+	}
+	if all, _ := manager.GetHistory("sensor-1", 0); len(all) != len(tests) {
+		t.Errorf("expected %d samples, got %d", len(tests), len(all))
+	}
+	if _, err := manager.GetHistory("sensor-9", 0); !errors.Is(err, ErrSensorNotFound) {
+		t.Errorf("expected ErrSensorNotFound, got %v", err)
+	}
+
+	// A removed sensor added again starts over.
+	if err := manager.RemoveSensor("sensor-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := manager.AddSensor(&models.Sensor{ID: "sensor-1", Type: models.SoilMoisture, SectionID: "section-A"}); err != nil {
+		t.Fatalf("failed to add sensor: %v", err)
+	}
+	if reading, err := manager.GetReading("sensor-1"); err != nil || reading.HasDelta || reading.DeltaPerTick != 0 {
+		t.Errorf("expected a first sample without a delta, got %+v, %v", reading, err)
+	}
+}
+
+func TestGetReadingAt(t *testing.T) {
+	plant := createTestPlant("plant-1", "section-A", 0.75)
+	mockData := &mockPlantDataSource{
+		plantsBySectionID: map[string][]*models.Plant{"section-A": {plant}},
+	}
+	manager := NewSensorManager(mockData, nil, nil)
+	for _, id := range []string{"sensor-1", "sensor-2"} {
+		if err := manager.AddSensor(&models.Sensor{ID: id, Type: models.SoilMoisture, SectionID: "section-A"}); err != nil {
+			t.Fatalf("failed to add sensor: %v", err)
+		}
+	}
+	// sensor-1 samples ticks 2, 3, 5 and 7; sensor-2 is never read.
+	for _, tick := range []int{2, 3, 5, 7} {
+		mockData.tick = tick
+		plant.SoilSaturation = float64(tick) / 10
+		if _, err := manager.GetReading("sensor-1"); err != nil {
+			t.Fatalf("tick %d: unexpected error: %v", tick, err)
+		}
+	}
+	mockData.tick = 8
+
+	tests := []struct {
+		name     string
+		lookup   HistoryLookup
+		sensorID string
+		tick     int
+		expected int
+		err      error
+	}{
+		{"exact hit", HistoryLookupNearestBefore, "sensor-1", 3, 3, nil},
+		{"nearest before", HistoryLookupNearestBefore, "sensor-1", 4, 3, nil},
+		{"nearest before the current tick", HistoryLookupNearestBefore, "sensor-1", 8, 7, nil},
+		{"exact only hit", HistoryLookupExact, "sensor-1", 5, 5, nil},
+		{"exact only miss", HistoryLookupExact, "sensor-1", 6, 0, ErrNoSampleAtTick},
+		{"before the history", HistoryLookupNearestBefore, "sensor-1", 1, 0, ErrTickBeforeHistory},
+		{"no samples", HistoryLookupNearestBefore, "sensor-2", 8, 0, ErrTickBeforeHistory},
+		{"future tick", HistoryLookupNearestBefore, "sensor-1", 9, 0, ErrTickInFuture},
+		{"unknown sensor", HistoryLookupNearestBefore, "sensor-9", 3, 0, ErrSensorNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := manager.SetHistoryLookup(tt.lookup); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			reading, err := manager.GetReadingAt(tt.sensorID, tt.tick)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("expected %v, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if reading.Tick != tt.expected || reading.Value != float64(tt.expected)/10 {
+				t.Errorf("expected the sample of tick %d, got %+v", tt.expected, reading)
+			}
+		})
+	}
+
+	if err := manager.SetHistoryLookup("latest"); err == nil || err.Error() != "history lookup must be nearest_before or exact: latest" {
+		t.Errorf("expected an invalid lookup error, got %v", err)
+	}
+
+	// Samples pushed out of the history can no longer be looked up.
+	for tick := 8; tick < 8+HistorySize; tick++ {
+		mockData.tick = tick
+		if _, err := manager.GetReading("sensor-1"); err != nil {
+			t.Fatalf("tick %d: unexpected error: %v", tick, err)
+		}
+	}
+	if _, err := manager.GetReadingAt("sensor-1", 7); !errors.Is(err, ErrTickBeforeHistory) {
+		t.Errorf("expected ErrTickBeforeHistory for a trimmed sample, got %v", err)
+	}
+}
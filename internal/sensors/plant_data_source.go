@@ -1,8 +1,30 @@
 package sensors
 
-import "greenhouse-simulator/internal/models"
+import (
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/environment"
+	"greenhouse-simulator/internal/models"
+)
 
+// PlantDataSource is what the sensors read: the plants and the current tick,
+// which keys the noise of the readings.
 type PlantDataSource interface {
 	GetPlantsBySectionID(sectionID string) []*models.Plant
 	GetAllPlants() []*models.Plant
+	GetCurrentTick() int
+}
+
+// SectionActivitySource is implemented by plant data sources that tell when
+// the plants of a section last changed, as engine simulators do. A sensor
+// manager reading one measures the soil of a section again only once its
+// plants changed, and tells sections whose plants are all dead without
+// reading them. With other sources every reading measures the soil.
+type SectionActivitySource interface {
+	SectionActivity(sectionID string) (engine.SectionActivity, bool)
+}
+
+// ConditionsSource provides the conditions that temperature, humidity,
+// light, CO2 and salinity sensors read in their section.
+type ConditionsSource interface {
+	SectionConditions(sectionID string) environment.Conditions
 }
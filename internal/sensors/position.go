@@ -0,0 +1,111 @@
+package sensors
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/models"
+	"slices"
+	"strings"
+)
+
+// ErrNoPosition is returned when a spatial query needs the position of a
+// plant or sensor that is not placed in its section.
+var ErrNoPosition = errors.New("no position")
+
+// positionEpsilon absorbs the rounding of distances, so that a plant on the
+// circle of a radius is within it.
+const positionEpsilon = 1e-9
+
+// AssignNearestSensor returns a copy of the sensor of the plant's section
+// nearest to it, by Euclidean distance, the one with the lowest ID between
+// sensors as near as each other. Sensors without a position are left out,
+// and failed ones are not. Returns an error wrapping:
+// - engine.ErrPlantNotFound if no plant has the given ID
+// - ErrNoPosition if the plant is not placed
+// - ErrNoSensorsInSection if no sensor of its section is placed
+//
+// This method is safe for concurrent use.
+func (s *sensorManager) AssignNearestSensor(plantID string) (*models.Sensor, error) {
+	plants := s.plantData.GetAllPlants()
+	i := slices.IndexFunc(plants, func(p *models.Plant) bool { return p.ID == plantID })
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %s", engine.ErrPlantNotFound, plantID)
+	}
+	plant := plants[i]
+	if plant.Position == nil {
+		return nil, fmt.Errorf("%w for plant: %s", ErrNoPosition, plantID)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var nearest *models.Sensor
+	distance := 0.0
+	for _, sensor := range s.sensorsBySection[plant.SectionID] {
+		if sensor.Position == nil {
+			continue
+		}
+		d := sensor.Position.Distance(*plant.Position)
+		if nearest == nil || d < distance || d == distance && sensor.ID < nearest.ID {
+			nearest, distance = sensor, d
+		}
+	}
+	if nearest == nil {
+		return nil, fmt.Errorf("%w with a position: %s", ErrNoSensorsInSection, plant.SectionID)
+	}
+	s.batteryMu.Lock()
+	defer s.batteryMu.Unlock()
+	return nearest.Clone(), nil
+}
+
+// GetPlantsNearSensor returns the plants of the sensor's section within
+// radius of it, by Euclidean distance, edge included, nearest first and by
+// ID between plants as near as each other. Plants without a position are
+// left out. The plants are those of the plant data source, not copies.
+// Returns an error if the radius is negative, or an error wrapping:
+// - ErrSensorNotFound if no sensor has the given ID
+// - ErrNoPosition if the sensor is not placed
+//
+// This method is safe for concurrent use.
+func (s *sensorManager) GetPlantsNearSensor(sensorID string, radius float64) ([]*models.Plant, error) {
+	if radius < 0 {
+		return nil, errors.New("radius cannot be negative")
+	}
+	s.mu.RLock()
+	sensor, ok := s.sensorsByID[sensorID]
+	var position *models.Position
+	var sectionID string
+	if ok {
+		position, sectionID = sensor.Position, sensor.SectionID
+	}
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSensorNotFound, sensorID)
+	}
+	if position == nil {
+		return nil, fmt.Errorf("%w for sensor: %s", ErrNoPosition, sensorID)
+	}
+
+	type near struct {
+		plant    *models.Plant
+		distance float64
+	}
+	var found []near
+	for _, plant := range s.plantData.GetPlantsBySectionID(sectionID) {
+		if plant.Position == nil {
+			continue
+		}
+		if d := position.Distance(*plant.Position); d <= radius+positionEpsilon {
+			found = append(found, near{plant, d})
+		}
+	}
+	slices.SortFunc(found, func(a, b near) int {
+		return cmp.Or(cmp.Compare(a.distance, b.distance), strings.Compare(a.plant.ID, b.plant.ID))
+	})
+	plants := make([]*models.Plant, len(found))
+	for i, n := range found {
+		plants[i] = n.plant
+	}
+	return plants, nil
+}
@@ -0,0 +1,124 @@
+package sensors
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/models"
+	"slices"
+	"testing"
+)
+
+// newPlacedSection has plants at (0, 0), (3, 4), (0.3, 0.4) and (6, 0) in
+// section-A, one without a position, and another placed in section-B.
+func newPlacedSection() *mockPlantDataSource {
+	placed := func(id, sectionID string, x, y float64) *models.Plant {
+		plant := createTestPlant(id, sectionID, 0.5)
+		plant.Position = &models.Position{X: x, Y: y}
+		return plant
+	}
+	sectionA := []*models.Plant{
+		placed("origin", "section-A", 0, 0),
+		placed("edge", "section-A", 3, 4),
+		placed("small-edge", "section-A", 0.3, 0.4),
+		placed("far", "section-A", 6, 0),
+		createTestPlant("unplaced", "section-A", 0.5),
+	}
+	sectionB := []*models.Plant{placed("other-section", "section-B", 0, 0)}
+	return &mockPlantDataSource{
+		plantsBySectionID: map[string][]*models.Plant{"section-A": sectionA, "section-B": sectionB},
+		allPlants:         append(slices.Clone(sectionA), sectionB...),
+	}
+}
+
+func addPlacedSensor(t *testing.T, manager SensorManager, id, sectionID string, position *models.Position) {
+	t.Helper()
+	sensor := &models.Sensor{ID: id, Type: models.SoilMoisture, SectionID: sectionID, Position: position}
+	if err := manager.AddSensor(sensor); err != nil {
+		t.Fatalf("failed to add sensor: %v", err)
+	}
+}
+
+func TestGetPlantsNearSensor(t *testing.T) {
+	manager := NewSensorManager(newPlacedSection(), nil, nil)
+	addPlacedSensor(t, manager, "at-origin", "section-A", &models.Position{})
+	addPlacedSensor(t, manager, "unplaced", "section-A", nil)
+
+	tests := []struct {
+		name     string
+		radius   float64
+		expected []string
+	}{
+		{"zero radius keeps the plant on the sensor", 0, []string{"origin"}},
+		{"plant on a small circle", 0.5, []string{"origin", "small-edge"}},
+		{"plant on the circle", 5, []string{"origin", "small-edge", "edge"}},
+		{"just inside the circle", 4.999, []string{"origin", "small-edge"}},
+		{"whole section", 100, []string{"origin", "small-edge", "edge", "far"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plants, err := manager.GetPlantsNearSensor("at-origin", tt.radius)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var ids []string
+			for _, plant := range plants {
+				ids = append(ids, plant.ID)
+			}
+			if !slices.Equal(ids, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+
+	if _, err := manager.GetPlantsNearSensor("unplaced", 5); !errors.Is(err, ErrNoPosition) {
+		t.Errorf("expected ErrNoPosition for a sensor without a position, got %v", err)
+	}
+	if _, err := manager.GetPlantsNearSensor("missing", 5); !errors.Is(err, ErrSensorNotFound) {
+		t.Errorf("expected ErrSensorNotFound, got %v", err)
+	}
+	if _, err := manager.GetPlantsNearSensor("at-origin", -1); err == nil || err.Error() != "radius cannot be negative" {
+		t.Errorf("expected a negative radius error, got %v", err)
+	}
+}
+
+func TestAssignNearestSensor(t *testing.T) {
+	manager := NewSensorManager(newPlacedSection(), nil, nil)
+	addPlacedSensor(t, manager, "sensor-b", "section-A", &models.Position{X: 3, Y: 0})
+	addPlacedSensor(t, manager, "sensor-a", "section-A", &models.Position{X: 3, Y: 8})
+	addPlacedSensor(t, manager, "sensor-c", "section-A", &models.Position{X: 6, Y: 1})
+	// An unplaced sensor is nowhere, and a sensor of another section is
+	// never the nearest.
+	addPlacedSensor(t, manager, "unplaced", "section-A", nil)
+	addPlacedSensor(t, manager, "sensor-other", "section-B", &models.Position{})
+
+	tests := []struct {
+		plantID  string
+		expected string
+		err      error
+	}{
+		{"origin", "sensor-b", nil},
+		// 4 away from sensor-a and sensor-b: the lowest ID wins.
+		{"edge", "sensor-a", nil},
+		{"far", "sensor-c", nil},
+		{"unplaced", "", ErrNoPosition},
+		{"missing", "", engine.ErrPlantNotFound},
+		{"other-section", "sensor-other", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.plantID, func(t *testing.T) {
+			sensor, err := manager.AssignNearestSensor(tt.plantID)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if err == nil && sensor.ID != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, sensor.ID)
+			}
+		})
+	}
+
+	unplaced := NewSensorManager(newPlacedSection(), nil, nil)
+	addPlacedSensor(t, unplaced, "unplaced", "section-A", nil)
+	if _, err := unplaced.AssignNearestSensor("origin"); !errors.Is(err, ErrNoSensorsInSection) {
+		t.Errorf("expected ErrNoSensorsInSection without placed sensors, got %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package sensors
+
+import (
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/models"
+	"strconv"
+	"strings"
+)
+
+// Template describes sensors that are alike but for their ID and section,
+// to register many at once, see ExpandTemplate. IDPattern builds the ID of
+// each sensor from the placeholders {section}, the ID of its section,
+// {zone}, the zone of that section, and {n}, the number of the sensor in
+// the expansion from 1, as in "moist-{section}".
+type Template struct {
+	IDPattern string
+	Type      models.SensorType
+	Options   []models.SensorOption
+}
+
+// TemplateSection is a section a template expands into, with the ID of its
+// zone, empty for a section in no zone.
+type TemplateSection struct {
+	ID   string
+	Zone string
+}
+
+// placeholder is a part of an ID pattern: literal text, or the name of a
+// placeholder without its braces.
+type placeholder struct {
+	text string
+	name string
+}
+
+// parsePattern splits an ID pattern into its literal text and placeholders.
+// Returns an error if the pattern is empty, has a brace that does not open
+// or close a placeholder, or names an unknown placeholder.
+func parsePattern(pattern string) ([]placeholder, error) {
+	if pattern == "" {
+		return nil, errors.New("sensor id pattern cannot be empty")
+	}
+	var parts []placeholder
+	for rest := pattern; rest != ""; {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			parts = append(parts, placeholder{text: rest})
+			break
+		}
+		if rest[open] == '}' {
+			return nil, errors.New("sensor id pattern has an unopened placeholder: " + pattern)
+		}
+		if open > 0 {
+			parts = append(parts, placeholder{text: rest[:open]})
+		}
+		length := strings.IndexAny(rest[open+1:], "{}")
+		if length < 0 || rest[open+1+length] != '}' {
+			return nil, errors.New("sensor id pattern has an unclosed placeholder: " + pattern)
+		}
+		name := rest[open+1 : open+1+length]
+		if name != "section" && name != "zone" && name != "n" {
+			return nil, fmt.Errorf("sensor id pattern has an unknown placeholder {%s}: %s", name, pattern)
+		}
+		parts = append(parts, placeholder{name: name})
+		rest = rest[open+2+length:]
+	}
+	return parts, nil
+}
+
+// ValidatePattern checks a template ID pattern. Returns an error if it is
+// empty, has a brace that does not open or close a placeholder, or names a
+// placeholder other than {section}, {zone} and {n}.
+func ValidatePattern(pattern string) error {
+	_, err := parsePattern(pattern)
+	return err
+}
+
+// ExpandTemplate creates a sensor of the template in each of the sections,
+// in their order. Returns an error if:
+// - the ID pattern is invalid, see ValidatePattern
+// - the pattern has {zone} and a section is in no zone
+// - two sensors get the same ID
+// - a sensor is invalid, see models.NewSensor
+func ExpandTemplate(tpl Template, sections []TemplateSection) ([]*models.Sensor, error) {
+	parts, err := parsePattern(tpl.IDPattern)
+	if err != nil {
+		return nil, err
+	}
+	ids := map[string]bool{}
+	expanded := make([]*models.Sensor, 0, len(sections))
+	for i, section := range sections {
+		var id strings.Builder
+		for _, part := range parts {
+			switch part.name {
+			case "":
+				id.WriteString(part.text)
+			case "section":
+				id.WriteString(section.ID)
+			case "zone":
+				if section.Zone == "" {
+					return nil, fmt.Errorf("sensor id pattern %s: section %s is in no zone", tpl.IDPattern, section.ID)
+				}
+				id.WriteString(section.Zone)
+			case "n":
+				id.WriteString(strconv.Itoa(i + 1))
+			}
+		}
+		if ids[id.String()] {
+			return nil, fmt.Errorf("sensor id pattern %s gives a duplicate sensor ID: %s", tpl.IDPattern, id.String())
+		}
+		ids[id.String()] = true
+		sensor, err := models.NewSensor(id.String(), tpl.Type, section.ID, tpl.Options...)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, sensor)
+	}
+	return expanded, nil
+}
@@ -0,0 +1,64 @@
+package sensors
+
+import (
+	"greenhouse-simulator/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	sections := []TemplateSection{{ID: "section-A", Zone: "west"}, {ID: "section-B", Zone: "west"}, {ID: "section-C", Zone: "east"}}
+	tests := []struct {
+		name     string
+		pattern  string
+		expected []string
+	}{
+		{"section", "moist-{section}", []string{"moist-section-A", "moist-section-B", "moist-section-C"}},
+		{"zone and number", "{zone}/moist-{n}", []string{"west/moist-1", "west/moist-2", "east/moist-3"}},
+		{"every placeholder", "{n}{zone}{section}", []string{"1westsection-A", "2westsection-B", "3eastsection-C"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tpl := Template{IDPattern: tt.pattern, Type: models.SoilMoisture, Options: []models.SensorOption{models.WithNoise(0.02)}}
+			expanded, err := ExpandTemplate(tpl, sections)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var ids []string
+			for i, sensor := range expanded {
+				ids = append(ids, sensor.ID)
+				if sensor.SectionID != sections[i].ID || sensor.Type != models.SoilMoisture || sensor.Noise != 0.02 {
+					t.Errorf("expected a soil moisture sensor with noise 0.02 in %s, got %+v", sections[i].ID, sensor)
+				}
+			}
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("expected IDs %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+}
+
+func TestExpandTemplate_Errors(t *testing.T) {
+	sections := []TemplateSection{{ID: "section-A", Zone: "west"}, {ID: "section-B"}}
+	tests := []struct {
+		name     string
+		tpl      Template
+		errorMsg string
+	}{
+		{"empty pattern", Template{Type: models.Light}, "sensor id pattern cannot be empty"},
+		{"unclosed placeholder", Template{IDPattern: "light-{section", Type: models.Light}, "sensor id pattern has an unclosed placeholder: light-{section"},
+		{"nested placeholder", Template{IDPattern: "light-{{n}}", Type: models.Light}, "sensor id pattern has an unclosed placeholder: light-{{n}}"},
+		{"unopened placeholder", Template{IDPattern: "light-n}", Type: models.Light}, "sensor id pattern has an unopened placeholder: light-n}"},
+		{"unknown placeholder", Template{IDPattern: "light-{row}", Type: models.Light}, "sensor id pattern has an unknown placeholder {row}: light-{row}"},
+		{"section in no zone", Template{IDPattern: "light-{zone}", Type: models.Light}, "sensor id pattern light-{zone}: section section-B is in no zone"},
+		{"duplicate ID", Template{IDPattern: "light", Type: models.Light}, "sensor id pattern light gives a duplicate sensor ID: light"},
+		{"invalid sensor", Template{IDPattern: "light-{n}", Type: models.Light, Options: []models.SensorOption{models.WithNoise(-1)}}, "sensor noise cannot be negative: light-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ExpandTemplate(tt.tpl, sections); err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
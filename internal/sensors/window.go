@@ -0,0 +1,83 @@
+package sensors
+
+import (
+	"greenhouse-simulator/internal/models"
+)
+
+// ObserveWindows measures every sensor with a window that can be read on the
+// current tick, so that its window holds what happened between its samples,
+// see models.Sensor.WindowTicks. The simulation calls it every tick; a
+// sensor read on the tick already is not measured again.
+//
+// This method is safe for concurrent use.
+func (s *sensorManager) ObserveWindows() {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.windowed == 0 {
+		return
+	}
+	tick := s.plantData.GetCurrentTick()
+	for _, sensor := range s.sensorsByID {
+		if sensor.WindowTicks == 0 || s.broken(sensor) != nil {
+			continue
+		}
+		s.windowMu.Lock()
+		observations := s.windows[sensor.ID]
+		observed := len(observations) > 0 && observations[len(observations)-1].tick == tick
+		s.windowMu.Unlock()
+		if observed {
+			continue
+		}
+		if value, err := s.measure(sensor, tick); err == nil {
+			s.observe(sensor, tick, value)
+		}
+	}
+}
+
+// observe records what a sensor with a window measured on tick, and forgets
+// what no longer counts towards its window. Callers must hold s.mu.
+func (s *sensorManager) observe(sensor *models.Sensor, tick int, value float64) {
+	s.windowMu.Lock()
+	defer s.windowMu.Unlock()
+	observations := s.windows[sensor.ID]
+	if n := len(observations); n > 0 && observations[n-1].tick >= tick {
+		// The ticks went back, for a restored snapshot: start over.
+		if observations[n-1].tick > tick {
+			observations = observations[:0]
+		} else {
+			observations = observations[:n-1]
+		}
+	}
+	observations = append(observations, sample{value: value, tick: tick})
+	// An observation counts until the next one, so the last one before the
+	// window still covers its first ticks.
+	start := tick - sensor.WindowTicks + 1
+	drop := 0
+	for drop+1 < len(observations) && observations[drop+1].tick <= start {
+		drop++
+	}
+	s.windows[sensor.ID] = append(observations[:0], observations[drop:]...)
+}
+
+// windowMean returns the time-weighted mean of what a sensor measured over
+// its window ending on tick, each measure counting for the ticks until the
+// next one, and whether it measured for fewer ticks than the window. Callers
+// must hold s.mu, and have observed the sensor on tick.
+func (s *sensorManager) windowMean(sensor *models.Sensor, tick int) (float64, bool) {
+	s.windowMu.Lock()
+	defer s.windowMu.Unlock()
+	observations := s.windows[sensor.ID]
+	start := tick - sensor.WindowTicks + 1
+	total, ticks := 0.0, 0
+	for i, observation := range observations {
+		from, to := max(observation.tick, start), tick
+		if i+1 < len(observations) {
+			to = observations[i+1].tick - 1
+		}
+		if to >= from {
+			total += observation.value * float64(to-from+1)
+			ticks += to - from + 1
+		}
+	}
+	return total / float64(ticks), ticks < sensor.WindowTicks
+}
@@ -0,0 +1,100 @@
+package sensors
+
+import (
+	"greenhouse-simulator/internal/models"
+	"math"
+	"testing"
+)
+
+func TestGetReading_Window(t *testing.T) {
+	// The soil is at 0.5 but for a spike to 0.9 on tick 7, between two
+	// samples of the sensor, which reads every 5 ticks.
+	plant := createTestPlant("plant-1", "section-A", 0.5)
+	data := &mockPlantDataSource{plantsBySectionID: map[string][]*models.Plant{"section-A": {plant}}}
+	manager := NewSensorManager(data, nil, nil)
+	for _, sensor := range []*models.Sensor{
+		{ID: "instant", Type: models.SoilMoisture, SectionID: "section-A", SampleInterval: 5},
+		{ID: "windowed", Type: models.SoilMoisture, SectionID: "section-A", SampleInterval: 5, WindowTicks: 5},
+	} {
+		if err := manager.AddSensor(sensor); err != nil {
+			t.Fatalf("failed to add sensor: %v", err)
+		}
+	}
+
+	tests := []struct {
+		tick     int
+		expected float64
+		partial  bool
+	}{
+		{0, 0.5, true},
+		{5, 0.5, false},
+		// The spike covers one tick of the five of the window.
+		{10, 0.58, false},
+		{15, 0.5, false},
+	}
+	readings := map[int][2]*models.SensorReading{}
+	for tick := range 16 {
+		data.tick = tick
+		plant.SoilSaturation = 0.5
+		if tick == 7 {
+			plant.SoilSaturation = 0.9
+		}
+		manager.ObserveWindows()
+		if tick%5 != 0 {
+			continue
+		}
+		instant, err := manager.GetReading("instant")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		windowed, err := manager.GetReading("windowed")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		readings[tick] = [2]*models.SensorReading{instant, windowed}
+	}
+
+	for _, tt := range tests {
+		instant, windowed := readings[tt.tick][0], readings[tt.tick][1]
+		if instant.Value != 0.5 || instant.Instant != nil {
+			t.Errorf("tick %d: expected the instant sensor to miss the spike, got %+v", tt.tick, instant)
+		}
+		if math.Abs(windowed.Value-tt.expected) > 1e-9 || windowed.PartialWindow != tt.partial {
+			t.Errorf("tick %d: expected %.2f with a partial window %t, got %.3f and %t", tt.tick, tt.expected, tt.partial, windowed.Value, windowed.PartialWindow)
+		}
+		if windowed.Instant == nil || *windowed.Instant != 0.5 {
+			t.Errorf("tick %d: expected the instant value 0.5 alongside, got %v", tt.tick, windowed.Instant)
+		}
+	}
+	// The rate of change follows the windowed values.
+	if rate := readings[10][1].DeltaPerTick; math.Abs(rate-0.016) > 1e-9 {
+		t.Errorf("expected the delta of the windowed readings, got %.4f", rate)
+	}
+}
+
+func TestGetReading_WindowBetweenObservations(t *testing.T) {
+	// Without ObserveWindows, each measure counts until the next one.
+	plant := createTestPlant("plant-1", "section-A", 0.2)
+	data := &mockPlantDataSource{plantsBySectionID: map[string][]*models.Plant{"section-A": {plant}}}
+	manager := NewSensorManager(data, nil, nil)
+	if err := manager.AddSensor(&models.Sensor{ID: "windowed", Type: models.SoilMoisture, SectionID: "section-A", WindowTicks: 4}); err != nil {
+		t.Fatalf("failed to add sensor: %v", err)
+	}
+	for _, step := range []struct {
+		tick       int
+		saturation float64
+	}{{0, 0.2}, {3, 0.6}, {5, 1}} {
+		data.tick, plant.SoilSaturation = step.tick, step.saturation
+		if _, err := manager.GetReading("windowed"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// Ticks 2 to 5: 0.2 on tick 2, 0.6 on ticks 3 and 4, 1.0 on tick 5.
+	reading, err := manager.GetReading("windowed")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(reading.Value-0.6) > 1e-9 || reading.PartialWindow {
+		t.Errorf("expected a full window averaging 0.6, got %+v", reading)
+	}
+}
@@ -0,0 +1,479 @@
+// Package service is the transport-independent layer behind the HTTP and
+// gRPC APIs: every operation they expose is implemented once here, on top of
+// a running greenhouse. Errors wrap the sentinel errors of the underlying
+// packages so each transport can map them to its own status codes.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/environment"
+	"greenhouse-simulator/internal/greenhouse"
+	"greenhouse-simulator/internal/models"
+	"greenhouse-simulator/internal/sensors"
+	"greenhouse-simulator/internal/watering"
+	"sync/atomic"
+	"time"
+)
+
+// ErrReadOnly is returned by the APIs for calls that would change the
+// greenhouse while the service is read-only, see Service.SetReadOnly.
+var ErrReadOnly = errors.New("the greenhouse is read-only")
+
+// Status is a summary of the running simulation. Timing is how long its
+// ticks take, see engine.TickTiming.
+type Status struct {
+	Tick         int
+	Paused       bool
+	TickInterval time.Duration
+	Plants       int
+	AlivePlants  int
+	Sensors      int
+	Water        watering.WaterStats
+	Timing       engine.TickTiming
+}
+
+// Service is what the APIs can do with a running greenhouse.
+type Service interface {
+	// Plants returns snapshots of every plant, ordered by ID.
+	Plants() []*models.Plant
+	// QueryPlants returns a page of the plants matching a query, ordered by
+	// ID, see engine.Simulator.QueryPlants.
+	QueryPlants(query engine.PlantQuery) engine.PlantPage
+	// Plant returns a plant by ID.
+	Plant(plantID string) (*models.Plant, error)
+	// AddPlant adds a plant built from its config.
+	AddPlant(plant config.PlantConfig) (*models.Plant, error)
+	// RemovePlant removes a plant.
+	RemovePlant(plantID string) error
+	// SetPlantFlags excludes a plant from watering or quarantines it, or
+	// undoes either, and returns the plant.
+	SetPlantFlags(plantID string, flags models.PlantFlags) (*models.Plant, error)
+	// PlantForecast projects a plant left unwatered under the current
+	// conditions.
+	PlantForecast(plantID string) (*models.PlantForecast, error)
+	// PlantDiagnosis returns the health a plant lost to each factor on the
+	// last tick.
+	PlantDiagnosis(plantID string) (models.HealthDiagnosis, error)
+	// PlantJournal returns the significant changes of a plant, oldest
+	// first.
+	PlantJournal(plantID string) ([]greenhouse.JournalEntry, error)
+	// Sensors returns every sensor, ordered by ID.
+	Sensors() []*models.Sensor
+	// AddSensor adds a sensor built from its config.
+	AddSensor(sensor config.SensorConfig) (*models.Sensor, error)
+	// ReplaceBattery puts a full battery in a wireless sensor.
+	ReplaceBattery(sensorID string) (*models.Sensor, error)
+	// Reading reads a sensor.
+	Reading(sensorID string) (*models.SensorReading, error)
+	// SectionReadings reads the working sensors of a section.
+	SectionReadings(sectionID string) ([]*models.SensorReading, error)
+	// ReadingHistory returns the most recent samples of a sensor.
+	ReadingHistory(sensorID string, lastN int) ([]*models.SensorReading, error)
+	// ReadingAt returns the sample a sensor took on a past tick, or the last
+	// one before it.
+	ReadingAt(sensorID string, tick int) (*models.SensorReading, error)
+	// SensorDiagnostics returns the read latencies and per-sensor counters.
+	SensorDiagnostics() sensors.Diagnostics
+	// ExporterDiagnostics returns the queue depth and counters of every exporter.
+	ExporterDiagnostics() []greenhouse.ExporterStats
+	// FlushExporters waits until every exporter has caught up and flushed.
+	FlushExporters(ctx context.Context) error
+	// SensorAdvice suggests where sensors are missing.
+	SensorAdvice() []sensors.Advice
+	// InjectAnomaly applies an anomaly to the readings of a sensor, or of
+	// every sensor of a section.
+	InjectAnomaly(target string, anomaly sensors.Anomaly) error
+	// AnomalyLabels returns which readings of a sensor an injected anomaly
+	// changed.
+	AnomalyLabels(sensorID string) ([]sensors.AnomalyLabel, error)
+	// Water waters a section manually and returns the queued event, see
+	// watering.Controller.WaterSectionWith.
+	Water(sectionID string, amount float64, duration time.Duration, options watering.ManualOptions) (models.WateringEvent, error)
+	// SetLights switches the grow lights of a section and returns their new
+	// state.
+	SetLights(sectionID string, on bool, intensity float64) (environment.Lamp, error)
+	// SetSectionClimateOffset sets the microclimate of a section and returns
+	// it.
+	SetSectionClimateOffset(sectionID string, offset environment.ClimateOffset) (environment.ClimateOffset, error)
+	// SetActuator switches the heater or the vent and returns the new state
+	// of the climate control.
+	SetActuator(actuator environment.Actuator, mode environment.ActuatorMode) (environment.HVACState, error)
+	// WaterTemperature returns the temperature of the irrigation water.
+	WaterTemperature() greenhouse.WaterTemperature
+	// HeatWater holds the irrigation water at a temperature and returns its
+	// new state.
+	HeatWater(temperature float64) (greenhouse.WaterTemperature, error)
+	// StopHeatingWater switches off the heater of the tank and returns the
+	// new state of the water.
+	StopHeatingWater() greenhouse.WaterTemperature
+	// Pause pauses the simulation and returns the new status.
+	Pause() (Status, error)
+	// Resume resumes the simulation and returns the new status.
+	Resume() (Status, error)
+	// Status returns the current status.
+	Status() Status
+	// Costs returns the cost ledger of the run so far.
+	Costs() greenhouse.CostLedger
+	// ListActiveAlerts returns the alerts that fired and are not resolved yet.
+	ListActiveAlerts() []greenhouse.Alert
+	// WorldState describes the whole greenhouse, for external visualizers.
+	WorldState() (*greenhouse.WorldState, error)
+	// Zones returns every zone, ordered by ID.
+	Zones() []*models.Zone
+	// AddZone groups sections into a new zone.
+	AddZone(zone config.ZoneConfig) (*models.Zone, error)
+	// ZoneStats summarises the sections of a zone.
+	ZoneStats(zoneID string) (greenhouse.ZoneStats, error)
+	// SectionStats summarises the plants of a section.
+	SectionStats(sectionID string) (greenhouse.SectionStats, error)
+	// Rotations returns the rotation plans with their progress, ordered by section.
+	Rotations() []greenhouse.RotationStatus
+	// SetRotation sets the rotation plan of a section.
+	SetRotation(plan config.RotationConfig) (greenhouse.RotationStatus, error)
+	// RemoveRotation drops the rotation plan of a section.
+	RemoveRotation(sectionID string) error
+	// PauseRotation pauses the rotation plan of a section.
+	PauseRotation(sectionID string) error
+	// ResumeRotation resumes a paused rotation plan.
+	ResumeRotation(sectionID string) error
+	// Watch streams the greenhouse events matching filter.
+	Watch(filter Filter, buffer int) *Subscription
+	// Touch records that a consumer used the simulation, see
+	// greenhouse.Greenhouse.Touch. The APIs call it once per request.
+	Touch()
+	// ReadOnly reports whether the APIs refuse every call that would change
+	// the greenhouse.
+	ReadOnly() bool
+	// SetReadOnly makes the APIs refuse every call that would change the
+	// greenhouse with ErrReadOnly, or accept them again. Queries and streams
+	// are served either way.
+	SetReadOnly(readOnly bool)
+}
+
+type service struct {
+	g        greenhouse.Greenhouse
+	readOnly atomic.Bool
+}
+
+// New returns the service of a running greenhouse, read-only when its server
+// config says so, see config.ServerConfig.
+func New(g greenhouse.Greenhouse) Service {
+	s := &service{g: g}
+	if server := g.Config().Server; server != nil {
+		s.readOnly.Store(server.ReadOnly)
+	}
+	return s
+}
+
+// Plants returns snapshots of every plant, see
+// engine.Simulator.QueryPlants, which the APIs may encode while the
+// simulation ticks.
+func (s *service) Plants() []*models.Plant {
+	return s.g.Simulator().QueryPlants(engine.PlantQuery{}).Plants
+}
+
+func (s *service) QueryPlants(query engine.PlantQuery) engine.PlantPage {
+	return s.g.Simulator().QueryPlants(query)
+}
+
+// Plant returns a snapshot of a plant by ID, see engine.Simulator.GetPlant.
+// Returns an error wrapping engine.ErrPlantNotFound if there is no such
+// plant.
+func (s *service) Plant(plantID string) (*models.Plant, error) {
+	return s.g.Simulator().GetPlant(plantID)
+}
+
+func (s *service) AddPlant(plant config.PlantConfig) (*models.Plant, error) {
+	return s.g.AddPlant(plant)
+}
+
+func (s *service) RemovePlant(plantID string) error {
+	return s.g.RemovePlant(plantID)
+}
+
+// SetPlantFlags sets the flags of a plant, see
+// engine.Simulator.SetPlantFlags, and returns a snapshot of it. Returns an
+// error wrapping engine.ErrPlantNotFound if there is no such plant.
+func (s *service) SetPlantFlags(plantID string, flags models.PlantFlags) (*models.Plant, error) {
+	if err := s.g.Simulator().SetPlantFlags(plantID, flags); err != nil {
+		return nil, err
+	}
+	return s.g.Simulator().GetPlant(plantID)
+}
+
+// PlantForecast projects a plant, see greenhouse.Greenhouse.PlantForecast.
+// Returns an error wrapping engine.ErrPlantNotFound if there is no such
+// plant.
+func (s *service) PlantForecast(plantID string) (*models.PlantForecast, error) {
+	return s.g.PlantForecast(plantID)
+}
+
+// PlantDiagnosis returns the diagnosis of a plant, see
+// engine.Simulator.GetPlantDiagnosis. Returns an error wrapping
+// engine.ErrPlantNotFound if there is no such plant.
+func (s *service) PlantDiagnosis(plantID string) (models.HealthDiagnosis, error) {
+	return s.g.Simulator().GetPlantDiagnosis(plantID)
+}
+
+// PlantJournal returns the journal of a plant, see
+// greenhouse.Greenhouse.GetPlantJournal. Returns an error wrapping
+// engine.ErrPlantNotFound if there is no such plant nor a journal of a
+// removed one, and greenhouse.ErrNoJournal if the greenhouse keeps none.
+func (s *service) PlantJournal(plantID string) ([]greenhouse.JournalEntry, error) {
+	return s.g.GetPlantJournal(plantID)
+}
+
+func (s *service) Sensors() []*models.Sensor {
+	return s.g.Sensors().ListSensors()
+}
+
+// AddSensor adds a sensor built from its config. Returns an error wrapping
+// sensors.ErrSensorExists if the ID is taken, or an error if the sensor is
+// invalid, see models.NewSensor.
+func (s *service) AddSensor(sensor config.SensorConfig) (*models.Sensor, error) {
+	added, err := sensor.Sensor()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.g.Sensors().AddSensor(added); err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// ReplaceBattery puts a full battery in a wireless sensor, see
+// sensors.SensorManager.ReplaceBattery, and returns the sensor. Returns an
+// error wrapping sensors.ErrSensorNotFound if no sensor has the ID, or
+// sensors.ErrNoBattery if it is wired.
+func (s *service) ReplaceBattery(sensorID string) (*models.Sensor, error) {
+	if err := s.g.Sensors().ReplaceBattery(sensorID); err != nil {
+		return nil, err
+	}
+	for _, sensor := range s.g.Sensors().ListSensors() {
+		if sensor.ID == sensorID {
+			return sensor, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", sensors.ErrSensorNotFound, sensorID)
+}
+
+func (s *service) Reading(sensorID string) (*models.SensorReading, error) {
+	return s.g.Sensors().GetReading(sensorID)
+}
+
+func (s *service) SectionReadings(sectionID string) ([]*models.SensorReading, error) {
+	return s.g.Sensors().GetSectionReadings(sectionID)
+}
+
+// ReadingHistory returns up to lastN of the most recent samples of a sensor,
+// oldest first, see sensors.SensorManager.GetHistory.
+func (s *service) ReadingHistory(sensorID string, lastN int) ([]*models.SensorReading, error) {
+	return s.g.Sensors().GetHistory(sensorID, lastN)
+}
+
+// ReadingAt returns the sample a sensor took on tick, or the last one before
+// it, see sensors.SensorManager.GetReadingAt.
+func (s *service) ReadingAt(sensorID string, tick int) (*models.SensorReading, error) {
+	return s.g.Sensors().GetReadingAt(sensorID, tick)
+}
+
+// SensorDiagnostics returns the read latencies and per-sensor counters
+// since the start, see sensors.SensorManager.GetDiagnostics.
+func (s *service) SensorDiagnostics() sensors.Diagnostics {
+	return s.g.Sensors().GetDiagnostics()
+}
+
+// ExporterDiagnostics returns the queue depth, high-water mark, counters
+// and last flush duration of every exporter, in registration order, see
+// greenhouse.ExporterStats.
+func (s *service) ExporterDiagnostics() []greenhouse.ExporterStats {
+	return s.g.Exporters().Stats()
+}
+
+// FlushExporters waits until every exporter has handled what is queued for
+// it and flushed, see greenhouse.ExportRegistry.Flush. Returns an error
+// wrapping the error of ctx if one did not catch up before ctx was done.
+func (s *service) FlushExporters(ctx context.Context) error {
+	return s.g.Exporters().Flush(ctx)
+}
+
+// SensorAdvice suggests where sensors are missing for the plants and the
+// features of the greenhouse, see sensors.AdviseSensorPlacement and
+// config.GreenhouseConfig.SensorAdvicePolicy.
+func (s *service) SensorAdvice() []sensors.Advice {
+	return sensors.AdviseSensorPlacement(s.g.Simulator().GetAllPlants(), s.g.Sensors().ListSensors(), s.g.Config().SensorAdvicePolicy())
+}
+
+// InjectAnomaly applies an anomaly to the readings of target, a sensor or a
+// section, see sensors.SensorManager.InjectAnomaly.
+func (s *service) InjectAnomaly(target string, anomaly sensors.Anomaly) error {
+	return s.g.Sensors().InjectAnomaly(target, anomaly)
+}
+
+// AnomalyLabels returns the ground truth of the readings of a sensor, see
+// sensors.SensorManager.GetAnomalyLabels.
+func (s *service) AnomalyLabels(sensorID string) ([]sensors.AnomalyLabel, error) {
+	return s.g.Sensors().GetAnomalyLabels(sensorID)
+}
+
+func (s *service) Water(sectionID string, amount float64, duration time.Duration, options watering.ManualOptions) (models.WateringEvent, error) {
+	return s.g.Watering().WaterSectionWith(sectionID, amount, duration, options)
+}
+
+// SetLights switches the grow lights of a section, see
+// greenhouse.Greenhouse.SetLights.
+func (s *service) SetLights(sectionID string, on bool, intensity float64) (environment.Lamp, error) {
+	if err := s.g.SetLights(sectionID, on, intensity); err != nil {
+		return environment.Lamp{}, err
+	}
+	return s.g.Lights().Get(sectionID), nil
+}
+
+// SetSectionClimateOffset sets the microclimate of a section, see
+// greenhouse.Greenhouse.SetSectionClimateOffset.
+func (s *service) SetSectionClimateOffset(sectionID string, offset environment.ClimateOffset) (environment.ClimateOffset, error) {
+	if err := s.g.SetSectionClimateOffset(sectionID, offset); err != nil {
+		return environment.ClimateOffset{}, err
+	}
+	return s.g.SectionClimateOffset(sectionID), nil
+}
+
+// SetActuator switches the heater or the vent, see
+// greenhouse.Greenhouse.SetActuator.
+func (s *service) SetActuator(actuator environment.Actuator, mode environment.ActuatorMode) (environment.HVACState, error) {
+	if err := s.g.SetActuator(actuator, mode); err != nil {
+		return environment.HVACState{}, err
+	}
+	return s.g.HVAC().State(), nil
+}
+
+// WaterTemperature returns the temperature of the irrigation water, see
+// greenhouse.Greenhouse.WaterTemperature.
+func (s *service) WaterTemperature() greenhouse.WaterTemperature {
+	return s.g.WaterTemperature()
+}
+
+// HeatWater switches on the heater of the tank, see
+// greenhouse.Greenhouse.HeatWater.
+func (s *service) HeatWater(temperature float64) (greenhouse.WaterTemperature, error) {
+	if err := s.g.HeatWater(temperature); err != nil {
+		return greenhouse.WaterTemperature{}, err
+	}
+	return s.g.WaterTemperature(), nil
+}
+
+// StopHeatingWater switches off the heater of the tank, see
+// greenhouse.Greenhouse.StopHeatingWater.
+func (s *service) StopHeatingWater() greenhouse.WaterTemperature {
+	s.g.StopHeatingWater()
+	return s.g.WaterTemperature()
+}
+
+// Costs returns the cost ledger of the run so far, see
+// greenhouse.CostLedger.
+func (s *service) Costs() greenhouse.CostLedger {
+	return s.g.Costs()
+}
+
+// ListActiveAlerts returns the alerts that fired and are not resolved yet,
+// with their escalation, see greenhouse.Alert.
+func (s *service) ListActiveAlerts() []greenhouse.Alert {
+	return s.g.ActiveAlerts()
+}
+
+// WorldState describes the greenhouse between two ticks, see
+// greenhouse.WorldState.
+func (s *service) WorldState() (*greenhouse.WorldState, error) {
+	return s.g.BuildWorldState()
+}
+
+func (s *service) Zones() []*models.Zone {
+	return s.g.Zones()
+}
+
+func (s *service) AddZone(zone config.ZoneConfig) (*models.Zone, error) {
+	return s.g.AddZone(zone)
+}
+
+// ZoneStats summarises a zone, see greenhouse.ZoneStats. Returns an error
+// wrapping greenhouse.ErrZoneNotFound if there is no such zone.
+func (s *service) ZoneStats(zoneID string) (greenhouse.ZoneStats, error) {
+	return s.g.ZoneStats(zoneID)
+}
+
+// SectionStats summarises a section, see greenhouse.SectionStats. Returns an
+// error wrapping greenhouse.ErrSectionNotFound if the section has no plants.
+func (s *service) SectionStats(sectionID string) (greenhouse.SectionStats, error) {
+	return s.g.SectionStats(sectionID)
+}
+
+func (s *service) Rotations() []greenhouse.RotationStatus {
+	return s.g.Rotations()
+}
+
+// SetRotation sets the rotation plan of a section, see
+// greenhouse.Greenhouse.SetRotation. Returns an error if the plan is invalid.
+func (s *service) SetRotation(plan config.RotationConfig) (greenhouse.RotationStatus, error) {
+	return s.g.SetRotation(plan)
+}
+
+func (s *service) RemoveRotation(sectionID string) error {
+	return s.g.RemoveRotation(sectionID)
+}
+
+// PauseRotation pauses the rotation plan of a section, see
+// greenhouse.Greenhouse.PauseRotation. Returns an error wrapping
+// greenhouse.ErrRotationNotFound if the section has no plan.
+func (s *service) PauseRotation(sectionID string) error {
+	return s.g.PauseRotation(sectionID)
+}
+
+func (s *service) ResumeRotation(sectionID string) error {
+	return s.g.ResumeRotation(sectionID)
+}
+
+func (s *service) Touch() {
+	s.g.Touch()
+}
+
+func (s *service) ReadOnly() bool {
+	return s.readOnly.Load()
+}
+
+func (s *service) SetReadOnly(readOnly bool) {
+	s.readOnly.Store(readOnly)
+}
+
+// Pause pauses the simulation, see greenhouse.Greenhouse.Pause.
+func (s *service) Pause() (Status, error) {
+	if err := s.g.Pause(); err != nil {
+		return Status{}, err
+	}
+	return s.Status(), nil
+}
+
+// Resume resumes the simulation, see greenhouse.Greenhouse.Resume.
+func (s *service) Resume() (Status, error) {
+	if err := s.g.Resume(); err != nil {
+		return Status{}, err
+	}
+	return s.Status(), nil
+}
+
+func (s *service) Status() Status {
+	status := s.g.Simulator().Status()
+	return Status{
+		Tick:         status.Tick,
+		Paused:       status.Paused,
+		TickInterval: status.TickInterval,
+		Plants:       status.Plants,
+		AlivePlants:  status.AlivePlants,
+		Sensors:      len(s.g.Sensors().ListSensors()),
+		Water:        s.g.Watering().GetWaterStats(),
+		Timing:       status.Timing,
+	}
+}
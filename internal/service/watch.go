@@ -0,0 +1,86 @@
+package service
+
+import (
+	"greenhouse-simulator/internal/events"
+	"sync"
+)
+
+// Filter selects greenhouse events. Empty fields let everything through.
+type Filter struct {
+	// Types keeps only the events of these types.
+	Types []events.Type
+	// SectionID drops events about other sections; greenhouse-wide events
+	// such as ticks are kept.
+	SectionID string
+}
+
+// Match reports whether the filter lets e through.
+func (f Filter) Match(e events.Event) bool {
+	if len(f.Types) > 0 {
+		matched := false
+		for _, t := range f.Types {
+			matched = matched || t == e.Type
+		}
+		if !matched {
+			return false
+		}
+	}
+	return f.SectionID == "" || e.SectionID == "" || e.SectionID == f.SectionID
+}
+
+// Subscription delivers the events matching a filter without ever blocking
+// the simulation: a subscriber that falls buffer events behind is cut off.
+type Subscription struct {
+	events      chan events.Event
+	overflowed  chan struct{}
+	overflow    sync.Once
+	unsubscribe func()
+	touch       func()
+}
+
+// Watch subscribes to the events matching filter, queueing up to buffer of
+// them. Close the subscription when done.
+func (s *service) Watch(filter Filter, buffer int) *Subscription {
+	sub := &Subscription{
+		events:     make(chan events.Event, buffer),
+		overflowed: make(chan struct{}),
+		touch:      s.g.Touch,
+	}
+	sub.unsubscribe = s.g.Bus().Subscribe(func(e events.Event) {
+		if !filter.Match(e) {
+			return
+		}
+		select {
+		case sub.events <- e:
+		default:
+			sub.overflow.Do(func() { close(sub.overflowed) })
+		}
+	})
+	return sub
+}
+
+// Events returns the queued events.
+func (s *Subscription) Events() <-chan events.Event {
+	return s.events
+}
+
+// Overflowed is closed once the subscriber fell too far behind. Events still
+// queued by then are stale and should not be delivered.
+func (s *Subscription) Overflowed() <-chan struct{} {
+	return s.overflowed
+}
+
+// Delivered records that the subscriber got e, as a consumption of the
+// simulation, see greenhouse.Greenhouse.Touch. The IdlePaused event and the
+// state changes of the simulator do not count, so that delivering the news
+// of an idle pause does not undo it.
+func (s *Subscription) Delivered(e events.Event) {
+	if e.Type != events.IdlePaused && e.Type != events.SimulatorStateChanged {
+		s.touch()
+	}
+}
+
+// Close stops the subscription.
+func (s *Subscription) Close() {
+	s.unsubscribe()
+}
@@ -0,0 +1,404 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/greenhouse"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyncPolicy is when an EventLog forces what it wrote to stable storage.
+type SyncPolicy string
+
+const (
+	// SyncNever leaves it to the operating system. The entries are still
+	// handed to it once the log has caught up after a tick, so a crash of
+	// the process alone loses nothing written before.
+	SyncNever SyncPolicy = "never"
+	// SyncTick syncs once the log has caught up after a tick. It is the
+	// default.
+	SyncTick SyncPolicy = "tick"
+	// SyncEvent syncs after every entry, which is the safest and slowest.
+	SyncEvent SyncPolicy = "event"
+)
+
+// EventLogEntry is a line of an event log. Seq numbers the entries of a log
+// from 1, without gaps. SimTime is the simulated time at the start of the
+// tick, and Timestamp the wall-clock time the event was published at.
+// Payload is the JSON of the event payload: an EventLogTick for ticks, a
+// models.SensorReading for sensor samples and the payload the event was
+// published with for the others. PayloadError says why the payload could not
+// be written, which leaves it out.
+type EventLogEntry struct {
+	Seq          uint64          `json:"seq"`
+	Type         events.Type     `json:"type"`
+	Tick         int             `json:"tick"`
+	SimTime      float64         `json:"sim_time"`
+	Timestamp    time.Time       `json:"timestamp"`
+	SectionID    string          `json:"section,omitempty"`
+	PlantID      string          `json:"plant,omitempty"`
+	Payload      json.RawMessage `json:"payload,omitempty"`
+	PayloadError string          `json:"payload_error,omitempty"`
+}
+
+// EventLogTick is the payload of a tick entry: the greenhouse stats and the
+// state of every plant after the tick, ordered by ID.
+type EventLogTick struct {
+	Stats  greenhouse.Stats        `json:"stats"`
+	Plants []greenhouse.PlantState `json:"plants"`
+}
+
+// EventLogOptions configures an EventLog.
+type EventLogOptions struct {
+	// Sync is when the log syncs what it wrote, SyncTick when empty. Only
+	// writers with a Sync method, such as files, can be synced.
+	Sync SyncPolicy
+}
+
+// EventLog is an Exporter appending everything a greenhouse publishes to an
+// append-only JSON Lines log, an EventLogEntry per line, in the order the
+// exporters are handed it: for every tick, its readings and other events,
+// then the tick itself. Events dropped by a full exporter queue are missing
+// from the log, see greenhouse.ExportRegistry.
+type EventLog interface {
+	greenhouse.Exporter
+	// Seq returns the sequence number of the last entry written, 0 when
+	// there is none.
+	Seq() uint64
+}
+
+// syncer is a writer that can force what was written to stable storage.
+type syncer interface {
+	Sync() error
+}
+
+type eventLog struct {
+	interval time.Duration
+	sync     SyncPolicy
+	buffer   *bufio.Writer
+	syncer   syncer    // nil unless the writer can sync
+	file     io.Closer // the file OpenEventLog opened, nil otherwise
+	seq      uint64
+	err      error
+	closed   bool
+	mu       sync.Mutex
+}
+
+// NewEventLog returns an event log writing to w the events of g once
+// registered with g.Exporters(), numbering its entries from 1. w is not
+// closed.
+// Returns an error if the sync policy is unknown.
+func NewEventLog(g greenhouse.Greenhouse, w io.Writer, opts EventLogOptions) (EventLog, error) {
+	return newEventLog(g, w, opts, 0)
+}
+
+func newEventLog(g greenhouse.Greenhouse, w io.Writer, opts EventLogOptions, seq uint64) (*eventLog, error) {
+	if opts.Sync == "" {
+		opts.Sync = SyncTick
+	}
+	if opts.Sync != SyncNever && opts.Sync != SyncTick && opts.Sync != SyncEvent {
+		return nil, fmt.Errorf("event log sync must be never, tick or event: %s", opts.Sync)
+	}
+	l := &eventLog{
+		interval: g.Simulator().GetTickInterval(),
+		sync:     opts.Sync,
+		buffer:   bufio.NewWriter(w),
+		seq:      seq,
+	}
+	l.syncer, _ = w.(syncer)
+	return l, nil
+}
+
+// OpenEventLog opens the event log at path for g, creating it if needed. An
+// existing log is appended to: its entries are read to carry on their
+// sequence, and a final line cut short by a crash is cut off, or ended when
+// only its line break is missing. The file is closed with the log.
+// Returns an error if the sync policy is unknown, or the file cannot be
+// opened, read or truncated, or does not hold an event log.
+func OpenEventLog(g greenhouse.Greenhouse, path string, opts EventLogOptions) (EventLog, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	reader := newEventLogReader(file, EventLogFilter{})
+	for {
+		if _, err = reader.Next(); err != nil {
+			break
+		}
+	}
+	if err != io.EOF {
+		file.Close()
+		return nil, fmt.Errorf("event log %s: %w", path, err)
+	}
+	if err := file.Truncate(reader.read); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(reader.read, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if reader.unterminated {
+		if _, err := file.Write([]byte{'\n'}); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	l, err := newEventLog(g, file, opts, reader.seq)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	l.file = file
+	return l, nil
+}
+
+// HandleTick writes a tick entry with the stats and plants of the tick.
+func (l *eventLog) HandleTick(t greenhouse.ExportedTick) error {
+	tick := EventLogTick{Stats: t.Stats, Plants: make([]greenhouse.PlantState, len(t.Plants))}
+	for i, plant := range t.Plants {
+		tick.Plants[i] = greenhouse.PlantState{
+			ID:         plant.ID,
+			SectionID:  plant.SectionID,
+			Type:       plant.Type.Name,
+			Health:     plant.Health,
+			Growth:     plant.GrowthStage,
+			Saturation: plant.SoilSaturation,
+			Alive:      plant.Alive,
+		}
+	}
+	return l.write(EventLogEntry{Type: events.Tick, Tick: t.Tick, Timestamp: t.Timestamp}, tick)
+}
+
+// HandleReading writes a sensor sample entry.
+func (l *eventLog) HandleReading(r greenhouse.ExportedReading) error {
+	return l.write(EventLogEntry{Type: events.SensorSample, Tick: r.Tick, Timestamp: r.Reading.Timestamp, SectionID: r.SectionID}, r.Reading)
+}
+
+// HandleEvent writes an entry for any other event.
+func (l *eventLog) HandleEvent(e events.Event) error {
+	return l.write(EventLogEntry{Type: e.Type, Tick: e.Tick, Timestamp: e.Timestamp, SectionID: e.SectionID, PlantID: e.PlantID}, e.Payload)
+}
+
+// write numbers entry and writes it with payload. After a write error
+// nothing more is written and Close reports the error.
+func (l *eventLog) write(entry EventLogEntry, payload any) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed || l.err != nil {
+		return l.err
+	}
+	if payload != nil {
+		if data, err := json.Marshal(payload); err != nil {
+			entry.PayloadError = err.Error()
+		} else if string(data) != "null" {
+			entry.Payload = data
+		}
+	}
+	entry.Seq = l.seq + 1
+	entry.SimTime = (time.Duration(entry.Tick) * l.interval).Seconds()
+	line, err := json.Marshal(entry)
+	if err != nil {
+		l.err = err
+		return err
+	}
+	if _, err := l.buffer.Write(append(line, '\n')); err != nil {
+		l.err = err
+		return err
+	}
+	l.seq++
+	if l.sync == SyncEvent {
+		return l.flush(true)
+	}
+	return nil
+}
+
+// flush hands the buffered entries to the writer, and syncs it when sync is
+// true. The caller must hold l.mu.
+func (l *eventLog) flush(sync bool) error {
+	if err := l.buffer.Flush(); err != nil {
+		l.err = err
+		return err
+	}
+	if sync && l.syncer != nil {
+		if err := l.syncer.Sync(); err != nil {
+			l.err = err
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush hands the entries written to the writer, syncing it with SyncTick.
+func (l *eventLog) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed || l.err != nil {
+		return l.err
+	}
+	return l.flush(l.sync == SyncTick)
+}
+
+// Close flushes and syncs the entries, and closes the file OpenEventLog
+// opened. Returns the first error met while writing. Calling Close again
+// does nothing.
+// This method is safe for concurrent use.
+func (l *eventLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	if l.err == nil {
+		l.flush(l.sync != SyncNever)
+	}
+	if l.file != nil {
+		if err := l.file.Close(); err != nil && l.err == nil {
+			l.err = err
+		}
+	}
+	return l.err
+}
+
+// Seq returns the sequence number of the last entry written.
+// This method is safe for concurrent use.
+func (l *eventLog) Seq() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.seq
+}
+
+// EventLogFilter selects entries of an event log. The zero filter selects
+// them all.
+type EventLogFilter struct {
+	// Types keeps only the entries of these types.
+	Types []events.Type
+	// FromTick and UntilTick keep only the entries of ticks from FromTick
+	// on and before UntilTick; UntilTick 0 sets no end.
+	FromTick  int
+	UntilTick int
+}
+
+// Match reports whether the filter selects entry.
+func (f EventLogFilter) Match(entry EventLogEntry) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, entry.Type) {
+		return false
+	}
+	return entry.Tick >= f.FromTick && (f.UntilTick == 0 || entry.Tick < f.UntilTick)
+}
+
+// EventLogReader reads the entries of an event log in order.
+type EventLogReader interface {
+	// Next returns the next entry the filter selects, or io.EOF after the
+	// last one.
+	Next() (EventLogEntry, error)
+}
+
+type eventLogReader struct {
+	reader *bufio.Reader
+	filter EventLogFilter
+	line   int
+	seq    uint64
+	// read is the length of the entries read so far, and unterminated
+	// whether the last of them misses its line break.
+	read         int64
+	unterminated bool
+}
+
+// NewEventLogReader returns a reader of the event log r holds, keeping the
+// entries filter selects. A final line without its line break is what a
+// crash in the middle of a write leaves: unless it holds a whole entry, it
+// is skipped.
+// Next returns an error if a line is not an entry or its sequence number
+// does not follow the one before.
+func NewEventLogReader(r io.Reader, filter EventLogFilter) EventLogReader {
+	return newEventLogReader(r, filter)
+}
+
+func newEventLogReader(r io.Reader, filter EventLogFilter) *eventLogReader {
+	return &eventLogReader{reader: bufio.NewReader(r), filter: filter}
+}
+
+func (r *eventLogReader) Next() (EventLogEntry, error) {
+	for {
+		line, err := r.reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return EventLogEntry{}, err
+		}
+		if len(line) == 0 {
+			return EventLogEntry{}, io.EOF
+		}
+		r.line++
+		complete := err == nil
+		var entry EventLogEntry
+		if err := json.Unmarshal(bytes.TrimSpace(line), &entry); err != nil {
+			if !complete {
+				return EventLogEntry{}, io.EOF
+			}
+			return EventLogEntry{}, fmt.Errorf("event log line %d: %w", r.line, err)
+		}
+		if entry.Seq <= r.seq {
+			return EventLogEntry{}, fmt.Errorf("event log line %d: sequence %d does not follow %d", r.line, entry.Seq, r.seq)
+		}
+		r.seq = entry.Seq
+		r.read += int64(len(line))
+		r.unterminated = !complete
+		if r.filter.Match(entry) {
+			return entry, nil
+		}
+	}
+}
+
+// ReadEventLog reads the entries filter selects from the event log r holds,
+// see NewEventLogReader.
+func ReadEventLog(r io.Reader, filter EventLogFilter) ([]EventLogEntry, error) {
+	reader := NewEventLogReader(r, filter)
+	var entries []EventLogEntry
+	for {
+		entry, err := reader.Next()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// ReadEventLogRecording reads the plant states of the tick entries of the
+// event log r holds as a recording to replay with greenhouse.NewReplay.
+// Returns an error if the log cannot be read or a tick payload is invalid.
+func ReadEventLogRecording(r io.Reader) (*greenhouse.Recording, error) {
+	reader := NewEventLogReader(r, EventLogFilter{Types: []events.Type{events.Tick}})
+	recording := &greenhouse.Recording{}
+	for {
+		entry, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var tick EventLogTick
+		if err := json.Unmarshal(entry.Payload, &tick); err != nil {
+			return nil, fmt.Errorf("event log entry %d: %w", entry.Seq, err)
+		}
+		slices.SortFunc(tick.Plants, func(a, b greenhouse.PlantState) int { return strings.Compare(a.ID, b.ID) })
+		recording.Frames = append(recording.Frames, greenhouse.Frame{Tick: entry.Tick, Plants: tick.Plants})
+	}
+	if !slices.IsSortedFunc(recording.Frames, func(a, b greenhouse.Frame) int { return a.Tick - b.Tick }) {
+		return nil, errors.New("event log ticks are out of order")
+	}
+	return recording, nil
+}
@@ -0,0 +1,354 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/environment"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/greenhouse"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptedConfig is the default greenhouse, ticking every minute, with a
+// timeline that waters, removes a plant and brings a frost.
+func scriptedConfig() *config.GreenhouseConfig {
+	cfg := config.Default()
+	cfg.TickInterval = config.Duration(time.Minute)
+	cfg.Timeline = []config.ActionConfig{
+		{Tick: 2, Action: config.ActionWater, SectionID: "section-A", Amount: 0.2},
+		{Tick: 4, Action: config.ActionRemovePlant, PlantID: "tomato-2"},
+		{Tick: 6, Action: config.ActionWeatherEvent, Extreme: environment.Frost, Ticks: 2},
+	}
+	return cfg
+}
+
+// logScriptedRun runs scriptedConfig for ticks ticks, logging it to w, and
+// returns the greenhouse and how many events of each type it published.
+func logScriptedRun(t *testing.T, w io.Writer, ticks int) (greenhouse.Greenhouse, map[events.Type]int) {
+	t.Helper()
+	g, err := greenhouse.New(scriptedConfig())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	log, err := NewEventLog(g, w, EventLogOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := g.Exporters().Register("log", log, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	published := map[events.Type]int{}
+	g.Bus().Subscribe(func(e events.Event) { published[e.Type]++ })
+	for range ticks {
+		g.Simulator().Step()
+	}
+	if err := g.Exporters().Remove("log"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return g, published
+}
+
+func TestEventLog_ScriptedRun(t *testing.T) {
+	var buffer bytes.Buffer
+	_, published := logScriptedRun(t, &buffer, 10)
+
+	entries, err := ReadEventLog(&buffer, EventLogFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	logged := map[events.Type]int{}
+	var pending []EventLogEntry
+	ticks := 0
+	for i, entry := range entries {
+		logged[entry.Type]++
+		if entry.Seq != uint64(i+1) {
+			t.Fatalf("expected entry %d to have sequence %d, got %d", i, i+1, entry.Seq)
+		}
+		if entry.Type != events.Tick {
+			pending = append(pending, entry)
+			continue
+		}
+		// The events of a tick come before it. Those published by its
+		// listeners, such as the timeline, count the next tick already.
+		if entry.Tick != ticks {
+			t.Fatalf("expected tick %d, got %+v", ticks, entry)
+		}
+		for _, event := range pending {
+			if event.Tick != entry.Tick && event.Tick != entry.Tick+1 {
+				t.Errorf("expected the events before tick %d to be from it, got %+v", entry.Tick, event)
+			}
+		}
+		if entry.SimTime != float64(entry.Tick*60) {
+			t.Errorf("expected tick %d at %d seconds, got %v", entry.Tick, entry.Tick*60, entry.SimTime)
+		}
+		pending = nil
+		ticks++
+	}
+	if ticks != 10 || len(pending) != 0 {
+		t.Errorf("expected 10 ticks and nothing after the last, got %d and %+v", ticks, pending)
+	}
+	if !reflect.DeepEqual(logged, published) {
+		t.Errorf("expected every published event to be logged\npublished: %v\nlogged:    %v", published, logged)
+	}
+	for _, expected := range []events.Type{events.WateringStarted, events.PlantRemoved, events.ExtremeWeatherStarted, events.SensorSample} {
+		if logged[expected] == 0 {
+			t.Errorf("expected the run to log a %s event, got %v", expected, logged)
+		}
+	}
+}
+
+func TestReadEventLog_Filter(t *testing.T) {
+	var buffer bytes.Buffer
+	logScriptedRun(t, &buffer, 10)
+
+	filter := EventLogFilter{Types: []events.Type{events.Tick, events.WateringStarted}, FromTick: 2, UntilTick: 5}
+	entries, err := ReadEventLog(&buffer, filter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var ticks []int
+	watered := false
+	for _, entry := range entries {
+		switch {
+		case entry.Tick < 2 || entry.Tick >= 5:
+			t.Errorf("expected ticks 2 to 4, got %+v", entry)
+		case entry.Type == events.Tick:
+			ticks = append(ticks, entry.Tick)
+		case entry.Type == events.WateringStarted:
+			watered = true
+		default:
+			t.Errorf("expected ticks and watering starts only, got %+v", entry)
+		}
+	}
+	if !reflect.DeepEqual(ticks, []int{2, 3, 4}) || !watered {
+		t.Errorf("expected ticks 2 to 4 and the watering of tick 2, got %v and %v", ticks, watered)
+	}
+}
+
+func TestReadEventLog_Damaged(t *testing.T) {
+	tests := []struct {
+		name     string
+		log      string
+		expected []uint64
+		errorMsg string
+	}{
+		{
+			name:     "truncated final line",
+			log:      `{"seq":1,"type":"tick","tick":0}` + "\n" + `{"seq":2,"type":"tick","tick":1}` + "\n" + `{"seq":3,"ty`,
+			expected: []uint64{1, 2},
+		},
+		{
+			name:     "final line without line break",
+			log:      `{"seq":1,"type":"tick","tick":0}` + "\n" + `{"seq":2,"type":"tick","tick":1}`,
+			expected: []uint64{1, 2},
+		},
+		{
+			name:     "damaged line",
+			log:      `{"seq":1,"type":"tick","tick":0}` + "\n" + `{"seq":2,"ty` + "\n" + `{"seq":3,"type":"tick","tick":1}` + "\n",
+			errorMsg: "event log line 2: unexpected end of JSON input",
+		},
+		{
+			name:     "sequence going back",
+			log:      `{"seq":1,"type":"tick","tick":0}` + "\n" + `{"seq":1,"type":"tick","tick":1}` + "\n",
+			errorMsg: "event log line 2: sequence 1 does not follow 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := ReadEventLog(strings.NewReader(tt.log), EventLogFilter{})
+			if tt.errorMsg != "" {
+				if err == nil || err.Error() != tt.errorMsg {
+					t.Errorf("expected error message '%s', got '%v'", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var seqs []uint64
+			for _, entry := range entries {
+				seqs = append(seqs, entry.Seq)
+			}
+			if !reflect.DeepEqual(seqs, tt.expected) {
+				t.Errorf("expected entries %v, got %v", tt.expected, seqs)
+			}
+		})
+	}
+}
+
+func TestOpenEventLog_AfterCrash(t *testing.T) {
+	tests := []struct {
+		name string
+		tail string
+		kept uint64
+	}{
+		{"truncated final line", `{"seq":3,"type":"sensor_sa`, 2},
+		{"final line without line break", `{"seq":3,"type":"tick","tick":1}`, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "events.jsonl")
+			previous := `{"seq":1,"type":"tick","tick":0}` + "\n" + `{"seq":2,"type":"sensor_sample","tick":1}` + "\n" + tt.tail
+			if err := os.WriteFile(path, []byte(previous), 0o644); err != nil {
+				t.Fatalf("failed to write log: %v", err)
+			}
+			g := newTestGreenhouse(t)
+			log, err := OpenEventLog(g, path, EventLogOptions{Sync: SyncEvent})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if log.Seq() != tt.kept {
+				t.Fatalf("expected the log to carry on from entry %d, got %d", tt.kept, log.Seq())
+			}
+			if err := g.Exporters().Register("log", log, 0); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			g.Simulator().Step()
+			if err := g.Exporters().Remove("log"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			file, err := os.Open(path)
+			if err != nil {
+				t.Fatalf("failed to open log: %v", err)
+			}
+			defer file.Close()
+			entries, err := ReadEventLog(file, EventLogFilter{})
+			if err != nil {
+				t.Fatalf("expected the log to read back, got %v", err)
+			}
+			if uint64(len(entries)) != log.Seq() || log.Seq() <= tt.kept {
+				t.Fatalf("expected the entries %d to %d, got %d entries", 1, log.Seq(), len(entries))
+			}
+			for i, entry := range entries {
+				if entry.Seq != uint64(i+1) {
+					t.Errorf("expected entry %d to have sequence %d, got %d", i, i+1, entry.Seq)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenEventLog_NotAnEventLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plants.csv")
+	if err := os.WriteFile(path, []byte("tick,plant_id\n0,tomato-1\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := OpenEventLog(newTestGreenhouse(t), path, EventLogOptions{}); err == nil || !strings.HasPrefix(err.Error(), "event log "+path+": event log line 1: ") {
+		t.Errorf("expected the file to be refused, got %v", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "tick,plant_id\n0,tomato-1\n" {
+		t.Errorf("expected the file to be left alone, got %q", content)
+	}
+}
+
+// syncBuffer is a writer counting its syncs.
+type syncBuffer struct {
+	bytes.Buffer
+	syncs int
+}
+
+func (b *syncBuffer) Sync() error {
+	b.syncs++
+	return nil
+}
+
+func TestEventLog_Sync(t *testing.T) {
+	tests := []struct {
+		policy   SyncPolicy
+		expected int
+	}{
+		{SyncNever, 0},
+		{"", 1},
+		{SyncTick, 1},
+		{SyncEvent, 3},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			var w syncBuffer
+			log, err := NewEventLog(newTestGreenhouse(t), &w, EventLogOptions{Sync: tt.policy})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for range 3 {
+				if err := log.HandleEvent(events.Event{Type: events.LowWater}); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if err := log.Flush(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if w.syncs != tt.expected {
+				t.Errorf("expected %d syncs, got %d", tt.expected, w.syncs)
+			}
+			if lines := strings.Count(w.String(), "\n"); lines != 3 {
+				t.Errorf("expected 3 entries written on flush, got %d", lines)
+			}
+		})
+	}
+
+	if _, err := NewEventLog(newTestGreenhouse(t), io.Discard, EventLogOptions{Sync: "sometimes"}); err == nil || err.Error() != "event log sync must be never, tick or event: sometimes" {
+		t.Errorf("expected an unknown policy to be refused, got %v", err)
+	}
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestEventLog_WriteError(t *testing.T) {
+	log, err := NewEventLog(newTestGreenhouse(t), failingWriter{}, EventLogOptions{Sync: SyncEvent})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := log.HandleEvent(events.Event{Type: events.LowWater}); err == nil || err.Error() != "disk full" {
+		t.Errorf("expected the write error, got %v", err)
+	}
+	if err := log.HandleEvent(events.Event{Type: events.LowWater}); err == nil {
+		t.Error("expected nothing more to be written after a write error")
+	}
+	if err := log.Close(); err == nil || err.Error() != "disk full" {
+		t.Errorf("expected Close to report the write error, got %v", err)
+	}
+}
+
+func TestReadEventLogRecording(t *testing.T) {
+	var buffer bytes.Buffer
+	g, _ := logScriptedRun(t, &buffer, 10)
+
+	recording, err := ReadEventLogRecording(&buffer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recording.Frames) != 10 || recording.Frames[0].Tick != 0 || recording.Frames[9].Tick != 9 {
+		t.Fatalf("expected a frame per tick, got %+v", recording.Frames)
+	}
+	var expected []greenhouse.PlantState
+	for _, plant := range g.Simulator().GetAllPlants() {
+		expected = append(expected, greenhouse.PlantState{
+			ID:         plant.ID,
+			SectionID:  plant.SectionID,
+			Type:       plant.Type.Name,
+			Health:     plant.Health,
+			Growth:     plant.GrowthStage,
+			Saturation: plant.SoilSaturation,
+			Alive:      plant.Alive,
+		})
+	}
+	slices.SortFunc(expected, func(a, b greenhouse.PlantState) int { return strings.Compare(a.ID, b.ID) })
+	if last := recording.Frames[9].Plants; !reflect.DeepEqual(last, expected) {
+		t.Errorf("expected the last frame to hold the final plants\nexpected: %+v\ngot:      %+v", expected, last)
+	}
+	if _, err := greenhouse.NewReplay(scriptedConfig(), recording, greenhouse.ReplayOptions{}); err != nil {
+		t.Errorf("expected the recording to replay, got %v", err)
+	}
+}
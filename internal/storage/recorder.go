@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/greenhouse"
+	"greenhouse-simulator/internal/models"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultPlantSampleInterval is the number of ticks between plant state
+// samples when Config.PlantSampleInterval is left at zero.
+const DefaultPlantSampleInterval = 10
+
+// Config configures a Recorder.
+type Config struct {
+	// PlantSampleInterval is the number of ticks between plant state samples.
+	PlantSampleInterval int
+	// Tracer, when set, traces every write as a FlushSpan, a child of the
+	// span of the last tick it stores, see engine.Simulator.TickContext.
+	Tracer trace.Tracer
+}
+
+// FlushSpan is the span of a write when Config.Tracer is set.
+const FlushSpan = "storage.flush"
+
+// Recorder is an Exporter writing the history of a greenhouse to a Store:
+// sensor samples, watering events, plant lifecycle events and, every
+// PlantSampleInterval ticks, the state of every plant. It writes what it
+// holds whenever it is flushed, so ticks never wait for the disk. Write
+// errors are logged.
+type Recorder interface {
+	greenhouse.Exporter
+}
+
+// batch is what the recorder stores in one go.
+type batch struct {
+	readings []Reading
+	watering []WateringRecord
+	plants   []PlantEvent
+	samples  []PlantSample
+	// tick is the span context of the tick the batch comes from, when traced.
+	tick trace.SpanContext
+}
+
+type recorder struct {
+	store   Store
+	cfg     Config
+	logger  *slog.Logger
+	pending batch
+}
+
+// NewRecorder returns a recorder writing to store once registered with the
+// exporters of a greenhouse.
+func NewRecorder(store Store, cfg Config, logger *slog.Logger) Recorder {
+	if cfg.PlantSampleInterval == 0 {
+		cfg.PlantSampleInterval = DefaultPlantSampleInterval
+	}
+	return &recorder{store: store, cfg: cfg, logger: logger}
+}
+
+// HandleReading holds a sensor sample.
+func (r *recorder) HandleReading(reading greenhouse.ExportedReading) error {
+	r.pending.readings = append(r.pending.readings, Reading{
+		SensorID:  reading.Reading.SensorID,
+		SectionID: reading.SectionID,
+		Tick:      reading.Tick,
+		Timestamp: reading.Reading.Timestamp,
+		Value:     reading.Reading.Value,
+	})
+	return nil
+}
+
+// HandleEvent holds watering and plant lifecycle events.
+func (r *recorder) HandleEvent(e events.Event) error {
+	switch e.Type {
+	case events.WateringStarted, events.WateringCompleted, events.WateringCancelled,
+		events.WateringPaused, events.WateringResumed, events.WateringSkipped:
+		event := e.Payload.(models.WateringEvent)
+		r.pending.watering = append(r.pending.watering, WateringRecord{
+			Type:       e.Type,
+			Tick:       e.Tick,
+			Timestamp:  e.Timestamp,
+			EventID:    event.ID,
+			SectionID:  event.SectionID,
+			PlantID:    event.PlantID,
+			Amount:     event.Amount,
+			Manual:     event.IsManual,
+			Method:     event.Method,
+			ScheduleID: event.ScheduleID,
+			Label:      event.Label,
+		})
+	case events.PlantAdded, events.PlantRemoved, events.PlantDied:
+		r.pending.plants = append(r.pending.plants, PlantEvent{Type: e.Type, Tick: e.Tick, Timestamp: e.Timestamp, PlantID: e.PlantID, SectionID: e.SectionID})
+		// A dead plant removed by the retention policy leaves its final
+		// state, which the next plant samples would miss.
+		if plant, ok := e.Payload.(*models.Plant); ok && e.Type == events.PlantRemoved {
+			r.pending.samples = append(r.pending.samples, sampleOf(plant, e.Tick, e.Timestamp))
+		}
+	}
+	return nil
+}
+
+// HandleTick samples every plant every PlantSampleInterval ticks, and makes
+// the tick the parent of the next write span.
+func (r *recorder) HandleTick(t greenhouse.ExportedTick) error {
+	if t.Span.IsValid() {
+		r.pending.tick = t.Span
+	}
+	if t.Tick%r.cfg.PlantSampleInterval != 0 {
+		return nil
+	}
+	for i := range t.Plants {
+		r.pending.samples = append(r.pending.samples, sampleOf(&t.Plants[i], t.Tick, t.Timestamp))
+	}
+	return nil
+}
+
+// sampleOf returns the sample of plant taken on tick.
+func sampleOf(plant *models.Plant, tick int, at time.Time) PlantSample {
+	return PlantSample{
+		PlantID:        plant.ID,
+		SectionID:      plant.SectionID,
+		Tick:           tick,
+		Timestamp:      at,
+		SoilSaturation: plant.SoilSaturation,
+		Health:         plant.Health,
+		GrowthStage:    plant.GrowthStage,
+		Alive:          plant.Alive,
+	}
+}
+
+// Flush writes what the recorder holds.
+func (r *recorder) Flush() error {
+	b := r.pending
+	r.pending = batch{}
+	return r.write(b)
+}
+
+// Close writes what the recorder holds. The store is not closed.
+func (r *recorder) Close() error {
+	return r.Flush()
+}
+
+func (r *recorder) write(b batch) error {
+	if len(b.readings)+len(b.watering)+len(b.plants)+len(b.samples) == 0 {
+		return nil
+	}
+	if r.cfg.Tracer != nil {
+		_, span := r.cfg.Tracer.Start(trace.ContextWithSpanContext(context.Background(), b.tick), FlushSpan, trace.WithAttributes(
+			attribute.Int("greenhouse.storage.readings", len(b.readings)),
+			attribute.Int("greenhouse.storage.watering_records", len(b.watering)),
+			attribute.Int("greenhouse.storage.plant_events", len(b.plants)),
+			attribute.Int("greenhouse.storage.plant_samples", len(b.samples)),
+		))
+		defer span.End()
+	}
+	err := errors.Join(
+		r.store.SaveReadings(b.readings),
+		r.store.SaveWateringRecords(b.watering),
+		r.store.SavePlantEvents(b.plants),
+		r.store.SavePlantSamples(b.samples),
+	)
+	if err != nil {
+		r.logger.Warn("storing simulation history failed", "error", err)
+	}
+	return err
+}
@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/greenhouse"
+	"greenhouse-simulator/internal/models"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestGreenhouse(t *testing.T) greenhouse.Greenhouse {
+	t.Helper()
+	cfg := config.Default()
+	cfg.TickInterval = config.Duration(time.Hour)
+	g, err := greenhouse.New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	return g
+}
+
+func TestRecorder_StoresSimulationHistory(t *testing.T) {
+	g := newTestGreenhouse(t)
+	store := openTestStore(t, filepath.Join(t.TempDir(), "history.db"))
+	defer store.Close()
+	recorder := NewRecorder(store, Config{PlantSampleInterval: 2}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err := g.Exporters().Register("store", recorder, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for range 5 {
+		g.Simulator().Step()
+	}
+	if err := g.Watering().WaterSection("section-A", 0.2, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g.Simulator().Step()
+	if err := g.RemovePlant("tomato-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dropped := g.Exporters().Stats()[0].Dropped; dropped != 0 {
+		t.Errorf("expected nothing dropped, got %d", dropped)
+	}
+	if err := g.Exporters().Remove("store"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sensor := g.Sensors().ListSensors()[0]
+	readings, err := store.Readings(sensor.ID, time.Time{}, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("failed to query readings: %v", err)
+	}
+	if len(readings) != 6 || readings[0].Tick != 0 || readings[5].Tick != 5 || readings[0].SectionID != sensor.SectionID {
+		t.Errorf("expected a %s reading per tick, got %+v", sensor.ID, readings)
+	}
+	samples, err := store.PlantHistory("tomato-2", 0, 100)
+	if err != nil {
+		t.Fatalf("failed to query plant history: %v", err)
+	}
+	if len(samples) != 3 || samples[0].Tick != 0 || samples[1].Tick != 2 || samples[2].Tick != 4 {
+		t.Errorf("expected tomato-2 samples every 2 ticks, got %+v", samples)
+	}
+	watering, err := store.WateringRecords("section-A", 0, 100)
+	if err != nil {
+		t.Fatalf("failed to query watering records: %v", err)
+	}
+	var manual []WateringRecord
+	for _, record := range watering {
+		if record.Manual {
+			manual = append(manual, record)
+		}
+	}
+	if len(manual) != 2 || manual[0].Type != events.WateringStarted || manual[1].Type != events.WateringCompleted || manual[0].Tick != 5 || manual[0].Amount != 0.2 {
+		t.Errorf("expected the manual watering to run on tick 5, got %+v", watering)
+	}
+	plantEvents, err := store.PlantEvents("tomato-1")
+	if err != nil {
+		t.Fatalf("failed to query plant events: %v", err)
+	}
+	if len(plantEvents) != 1 || plantEvents[0].Type != events.PlantRemoved || plantEvents[0].Tick != 6 {
+		t.Errorf("expected tomato-1 to be removed on tick 6, got %+v", plantEvents)
+	}
+}
+
+func TestRecorder_ArchivesRemovedDeadPlants(t *testing.T) {
+	cfg := config.Default()
+	cfg.TickInterval = config.Duration(time.Hour)
+	cfg.Plants[0].State = &config.PlantStateConfig{GrowthStage: 0.4, DeathCause: models.DeathByFrost}
+	cfg.DeadPlants = &config.DeadPlantsConfig{Retention: config.RemoveDeadPlants, After: 2}
+	g, err := greenhouse.New(cfg)
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	store := openTestStore(t, filepath.Join(t.TempDir(), "history.db"))
+	defer store.Close()
+	recorder := NewRecorder(store, Config{PlantSampleInterval: 10}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err := g.Exporters().Register("store", recorder, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for range 4 {
+		g.Simulator().Step()
+	}
+	if err := g.Exporters().Remove("store"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	samples, err := store.PlantHistory("tomato-1", 0, 100)
+	if err != nil {
+		t.Fatalf("failed to query plant history: %v", err)
+	}
+	// The sample of tick 0 and the final state archived on removal.
+	if len(samples) != 2 || samples[1].Tick != 2 || samples[1].Alive || samples[1].GrowthStage != 0.4 {
+		t.Errorf("expected the final state of tomato-1 archived on tick 2, got %+v", samples)
+	}
+	plantEvents, err := store.PlantEvents("tomato-1")
+	if err != nil {
+		t.Fatalf("failed to query plant events: %v", err)
+	}
+	if len(plantEvents) != 1 || plantEvents[0].Type != events.PlantRemoved || plantEvents[0].Tick != 2 {
+		t.Errorf("expected tomato-1 to be removed on tick 2, got %+v", plantEvents)
+	}
+}
+
+// blockingStore blocks every save until release is closed.
+type blockingStore struct {
+	Store
+	release chan struct{}
+}
+
+func (s blockingStore) SaveReadings([]Reading) error {
+	<-s.release
+	return nil
+}
+
+func (s blockingStore) SaveWateringRecords([]WateringRecord) error { return nil }
+func (s blockingStore) SavePlantEvents([]PlantEvent) error         { return nil }
+func (s blockingStore) SavePlantSamples([]PlantSample) error       { return nil }
+
+func TestRecorder_SlowStoreDoesNotBlockTicks(t *testing.T) {
+	g := newTestGreenhouse(t)
+	store := blockingStore{release: make(chan struct{})}
+	recorder := NewRecorder(store, Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err := g.Exporters().Register("store", recorder, 4); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stepped := make(chan struct{})
+	go func() {
+		for range 50 {
+			g.Simulator().Step()
+		}
+		close(stepped)
+	}()
+	select {
+	case <-stepped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ticks blocked on the store")
+	}
+	if g.Exporters().Stats()[0].Dropped == 0 {
+		t.Error("expected events to be dropped while the store blocks")
+	}
+	close(store.release)
+	if err := g.Exporters().Close(time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
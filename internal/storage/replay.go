@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"greenhouse-simulator/internal/greenhouse"
+	"math"
+)
+
+// LoadRecording reads every plant sample of store as a recording to replay
+// with greenhouse.NewReplay. Samples are only taken every few ticks, see
+// Config.PlantSampleInterval, so the recording has gaps, and they do not keep
+// the plant types, which the replay takes from its config.
+// Returns an error if the samples cannot be read.
+func LoadRecording(store PlantSampleStore) (*greenhouse.Recording, error) {
+	samples, err := store.PlantSamples(math.MinInt, math.MaxInt)
+	if err != nil {
+		return nil, err
+	}
+	recording := &greenhouse.Recording{}
+	for _, sample := range samples {
+		if n := len(recording.Frames); n == 0 || recording.Frames[n-1].Tick != sample.Tick {
+			recording.Frames = append(recording.Frames, greenhouse.Frame{Tick: sample.Tick})
+		}
+		frame := &recording.Frames[len(recording.Frames)-1]
+		frame.Plants = append(frame.Plants, greenhouse.PlantState{
+			ID:         sample.PlantID,
+			SectionID:  sample.SectionID,
+			Health:     sample.Health,
+			Growth:     sample.GrowthStage,
+			Saturation: sample.SoilSaturation,
+			Alive:      sample.Alive,
+		})
+	}
+	return recording, nil
+}
@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"greenhouse-simulator/internal/greenhouse"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadRecording(t *testing.T) {
+	store := openTestStore(t, filepath.Join(t.TempDir(), "history.db"))
+	defer store.Close()
+	at := time.Now()
+	samples := []PlantSample{
+		{PlantID: "tomato-2", SectionID: "section-A", Tick: 0, Timestamp: at, SoilSaturation: 0.4, Health: 1, Alive: true},
+		{PlantID: "tomato-1", SectionID: "section-A", Tick: 0, Timestamp: at, SoilSaturation: 0.5, Health: 1, Alive: true},
+		{PlantID: "tomato-1", SectionID: "section-A", Tick: 10, Timestamp: at, SoilSaturation: 0.3, Health: 0.8, GrowthStage: 0.1, Alive: true},
+	}
+	if err := store.SavePlantSamples(samples); err != nil {
+		t.Fatalf("failed to save plant samples: %v", err)
+	}
+
+	recording, err := LoadRecording(store)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := &greenhouse.Recording{Frames: []greenhouse.Frame{
+		{Tick: 0, Plants: []greenhouse.PlantState{
+			{ID: "tomato-1", SectionID: "section-A", Health: 1, Saturation: 0.5, Alive: true},
+			{ID: "tomato-2", SectionID: "section-A", Health: 1, Saturation: 0.4, Alive: true},
+		}},
+		{Tick: 10, Plants: []greenhouse.PlantState{
+			{ID: "tomato-1", SectionID: "section-A", Health: 0.8, Growth: 0.1, Saturation: 0.3, Alive: true},
+		}},
+	}}
+	if !reflect.DeepEqual(recording, expected) {
+		t.Errorf("expected %+v, got %+v", expected, recording)
+	}
+}
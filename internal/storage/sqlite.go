@@ -0,0 +1,243 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// migrations bring the schema from one version to the next: migrations[i]
+// upgrades version i to i+1. The version of a database is kept in its
+// user_version pragma. Released migrations must never change; new ones are
+// appended.
+var migrations = []string{
+	`CREATE TABLE readings (
+		sensor_id TEXT NOT NULL,
+		section_id TEXT NOT NULL,
+		tick INTEGER NOT NULL,
+		timestamp INTEGER NOT NULL,
+		value REAL NOT NULL
+	);
+	CREATE INDEX readings_by_sensor ON readings (sensor_id, timestamp);
+	CREATE TABLE watering_records (
+		type TEXT NOT NULL,
+		tick INTEGER NOT NULL,
+		timestamp INTEGER NOT NULL,
+		event_id TEXT NOT NULL,
+		section_id TEXT NOT NULL,
+		plant_id TEXT NOT NULL,
+		amount REAL NOT NULL,
+		manual INTEGER NOT NULL,
+		method TEXT NOT NULL,
+		schedule_id TEXT NOT NULL
+	);
+	CREATE INDEX watering_records_by_section ON watering_records (section_id, tick);
+	CREATE TABLE plant_events (
+		type TEXT NOT NULL,
+		tick INTEGER NOT NULL,
+		timestamp INTEGER NOT NULL,
+		plant_id TEXT NOT NULL,
+		section_id TEXT NOT NULL
+	);
+	CREATE INDEX plant_events_by_plant ON plant_events (plant_id, tick);
+	CREATE TABLE plant_samples (
+		plant_id TEXT NOT NULL,
+		section_id TEXT NOT NULL,
+		tick INTEGER NOT NULL,
+		timestamp INTEGER NOT NULL,
+		soil_saturation REAL NOT NULL,
+		health REAL NOT NULL,
+		growth_stage REAL NOT NULL,
+		alive INTEGER NOT NULL
+	);
+	CREATE INDEX plant_samples_by_plant ON plant_samples (plant_id, tick);`,
+	`ALTER TABLE watering_records ADD COLUMN label TEXT NOT NULL DEFAULT '';`,
+}
+
+type sqliteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens the SQLite database at path, creating it if needed, and
+// migrates its schema to the current version. Timestamps are stored with
+// nanosecond precision. Returns an error if:
+// - the database cannot be opened
+// - its schema is newer than this version of the simulator knows
+// - a migration fails
+func OpenSQLite(path string) (Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// One connection serialises writes and keeps the pragmas below in force.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec("PRAGMA journal_mode = WAL; PRAGMA busy_timeout = 5000"); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &sqliteStore{db: db}, nil
+}
+
+func migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than the supported version %d", version, len(migrations))
+	}
+	for ; version < len(migrations); version++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[version]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migrating the database schema to version %d: %w", version+1, err)
+		}
+		// Pragmas do not take placeholders.
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *sqliteStore) SaveReadings(readings []Reading) error {
+	return insert(s.db, "INSERT INTO readings VALUES (?, ?, ?, ?, ?)", readings, func(r Reading) []any {
+		return []any{r.SensorID, r.SectionID, r.Tick, r.Timestamp.UnixNano(), r.Value}
+	})
+}
+
+func (s *sqliteStore) Readings(sensorID string, from, to time.Time) ([]Reading, error) {
+	return query(s.db, `SELECT sensor_id, section_id, tick, timestamp, value FROM readings
+		WHERE sensor_id = ? AND timestamp >= ? AND timestamp < ? ORDER BY timestamp, rowid`,
+		[]any{sensorID, from.UnixNano(), to.UnixNano()},
+		func(rows *sql.Rows) (Reading, error) {
+			var r Reading
+			var timestamp int64
+			err := rows.Scan(&r.SensorID, &r.SectionID, &r.Tick, &timestamp, &r.Value)
+			r.Timestamp = time.Unix(0, timestamp)
+			return r, err
+		})
+}
+
+func (s *sqliteStore) SaveWateringRecords(records []WateringRecord) error {
+	return insert(s.db, "INSERT INTO watering_records VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", records, func(r WateringRecord) []any {
+		return []any{string(r.Type), r.Tick, r.Timestamp.UnixNano(), r.EventID, r.SectionID, r.PlantID, r.Amount, r.Manual, string(r.Method), r.ScheduleID, r.Label}
+	})
+}
+
+func (s *sqliteStore) WateringRecords(sectionID string, fromTick, toTick int) ([]WateringRecord, error) {
+	return query(s.db, `SELECT type, tick, timestamp, event_id, section_id, plant_id, amount, manual, method, schedule_id, label
+		FROM watering_records WHERE section_id = ? AND tick BETWEEN ? AND ? ORDER BY tick, rowid`,
+		[]any{sectionID, fromTick, toTick},
+		func(rows *sql.Rows) (WateringRecord, error) {
+			var r WateringRecord
+			var timestamp int64
+			err := rows.Scan((*string)(&r.Type), &r.Tick, &timestamp, &r.EventID, &r.SectionID, &r.PlantID, &r.Amount, &r.Manual, (*string)(&r.Method), &r.ScheduleID, &r.Label)
+			r.Timestamp = time.Unix(0, timestamp)
+			return r, err
+		})
+}
+
+func (s *sqliteStore) SavePlantEvents(events []PlantEvent) error {
+	return insert(s.db, "INSERT INTO plant_events VALUES (?, ?, ?, ?, ?)", events, func(e PlantEvent) []any {
+		return []any{string(e.Type), e.Tick, e.Timestamp.UnixNano(), e.PlantID, e.SectionID}
+	})
+}
+
+func (s *sqliteStore) PlantEvents(plantID string) ([]PlantEvent, error) {
+	return query(s.db, `SELECT type, tick, timestamp, plant_id, section_id FROM plant_events
+		WHERE plant_id = ? ORDER BY tick, rowid`,
+		[]any{plantID},
+		func(rows *sql.Rows) (PlantEvent, error) {
+			var e PlantEvent
+			var timestamp int64
+			err := rows.Scan((*string)(&e.Type), &e.Tick, &timestamp, &e.PlantID, &e.SectionID)
+			e.Timestamp = time.Unix(0, timestamp)
+			return e, err
+		})
+}
+
+func (s *sqliteStore) SavePlantSamples(samples []PlantSample) error {
+	return insert(s.db, "INSERT INTO plant_samples VALUES (?, ?, ?, ?, ?, ?, ?, ?)", samples, func(p PlantSample) []any {
+		return []any{p.PlantID, p.SectionID, p.Tick, p.Timestamp.UnixNano(), p.SoilSaturation, p.Health, p.GrowthStage, p.Alive}
+	})
+}
+
+func (s *sqliteStore) PlantHistory(plantID string, fromTick, toTick int) ([]PlantSample, error) {
+	return query(s.db, `SELECT plant_id, section_id, tick, timestamp, soil_saturation, health, growth_stage, alive
+		FROM plant_samples WHERE plant_id = ? AND tick BETWEEN ? AND ? ORDER BY tick, rowid`,
+		[]any{plantID, fromTick, toTick}, scanPlantSample)
+}
+
+func (s *sqliteStore) PlantSamples(fromTick, toTick int) ([]PlantSample, error) {
+	return query(s.db, `SELECT plant_id, section_id, tick, timestamp, soil_saturation, health, growth_stage, alive
+		FROM plant_samples WHERE tick BETWEEN ? AND ? ORDER BY tick, plant_id`,
+		[]any{fromTick, toTick}, scanPlantSample)
+}
+
+func scanPlantSample(rows *sql.Rows) (PlantSample, error) {
+	var p PlantSample
+	var timestamp int64
+	err := rows.Scan(&p.PlantID, &p.SectionID, &p.Tick, &timestamp, &p.SoilSaturation, &p.Health, &p.GrowthStage, &p.Alive)
+	p.Timestamp = time.Unix(0, timestamp)
+	return p, err
+}
+
+// insert runs statement once per item, with the arguments args returns, in
+// one transaction.
+func insert[T any](db *sql.DB, statement string, items []T, args func(T) []any) error {
+	if len(items) == 0 {
+		return nil
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.Prepare(statement)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, item := range items {
+		if _, err := stmt.Exec(args(item)...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// query runs statement and scans every row with scan.
+func query[T any](db *sql.DB, statement string, args []any, scan func(*sql.Rows) (T, error)) ([]T, error) {
+	rows, err := db.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []T
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
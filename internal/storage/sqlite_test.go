@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func openTestStore(t *testing.T, path string) Store {
+	t.Helper()
+	store, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	return store
+}
+
+func TestSQLite_ReadingsSurviveReopening(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	start := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	var readings []Reading
+	for i := range 10000 {
+		readings = append(readings, Reading{
+			SensorID:  fmt.Sprintf("sensor-%d", i%4),
+			SectionID: "section-A",
+			Tick:      i / 4,
+			Timestamp: start.Add(time.Duration(i/4) * time.Second),
+			Value:     float64(i) / 10000,
+		})
+	}
+
+	store := openTestStore(t, path)
+	if err := store.SaveReadings(readings); err != nil {
+		t.Fatalf("failed to save readings: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("failed to close store: %v", err)
+	}
+
+	store = openTestStore(t, path)
+	defer store.Close()
+	got, err := store.Readings("sensor-1", start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("failed to query readings: %v", err)
+	}
+	if len(got) != 2500 {
+		t.Fatalf("expected 2500 readings of sensor-1, got %d", len(got))
+	}
+	for i, reading := range got {
+		expected := readings[4*i+1]
+		if !reading.Timestamp.Equal(expected.Timestamp) {
+			t.Fatalf("reading %d: expected timestamp %v, got %v", i, expected.Timestamp, reading.Timestamp)
+		}
+		reading.Timestamp = expected.Timestamp
+		if reading != expected {
+			t.Fatalf("reading %d: expected %+v, got %+v", i, expected, reading)
+		}
+	}
+
+	// The upper bound is excluded.
+	got, err = store.Readings("sensor-1", start.Add(10*time.Second), start.Add(20*time.Second))
+	if err != nil {
+		t.Fatalf("failed to query readings: %v", err)
+	}
+	if len(got) != 10 || got[0].Tick != 10 || got[9].Tick != 19 {
+		t.Errorf("expected ticks 10 to 19, got %d readings: %+v", len(got), got)
+	}
+}
+
+func TestSQLite_EventsAndPlantHistory(t *testing.T) {
+	store := openTestStore(t, filepath.Join(t.TempDir(), "history.db"))
+	defer store.Close()
+	at := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+
+	watering := []WateringRecord{
+		{Type: events.WateringStarted, Tick: 3, Timestamp: at, EventID: "watering-1", SectionID: "section-A", Amount: 0.4, Method: models.MethodDrip, ScheduleID: "section-A"},
+		{Type: events.WateringCompleted, Tick: 5, Timestamp: at, EventID: "watering-1", SectionID: "section-A", Amount: 0.4, Method: models.MethodDrip, ScheduleID: "section-A"},
+		{Type: events.WateringStarted, Tick: 4, Timestamp: at, EventID: "watering-2", SectionID: "section-B", PlantID: "basil-1", Amount: 0.1, Manual: true, Method: models.MethodMisting, Label: "rescue"},
+	}
+	plants := []PlantEvent{
+		{Type: events.PlantAdded, Tick: 2, Timestamp: at, PlantID: "basil-1", SectionID: "section-B"},
+		{Type: events.PlantDied, Tick: 9, Timestamp: at, PlantID: "basil-1", SectionID: "section-B"},
+		{Type: events.PlantAdded, Tick: 2, Timestamp: at, PlantID: "mint-1", SectionID: "section-B"},
+	}
+	var samples []PlantSample
+	for tick := 0; tick < 50; tick += 10 {
+		samples = append(samples, PlantSample{PlantID: "basil-1", SectionID: "section-B", Tick: tick, Timestamp: at, SoilSaturation: 0.5, Health: 1 - float64(tick)/100, GrowthStage: float64(tick) / 100, Alive: true})
+	}
+	if err := store.SaveWateringRecords(watering); err != nil {
+		t.Fatalf("failed to save watering records: %v", err)
+	}
+	if err := store.SavePlantEvents(plants); err != nil {
+		t.Fatalf("failed to save plant events: %v", err)
+	}
+	mint := PlantSample{PlantID: "mint-1", SectionID: "section-B", Tick: 20, Timestamp: at, SoilSaturation: 0.3, Health: 1, Alive: true}
+	if err := store.SavePlantSamples(append([]PlantSample{mint}, samples...)); err != nil {
+		t.Fatalf("failed to save plant samples: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    func() (any, error)
+		expected any
+	}{
+		{"watering of a section", func() (any, error) { return store.WateringRecords("section-A", 0, 10) }, watering[:2]},
+		{"watering in a tick range", func() (any, error) { return store.WateringRecords("section-A", 4, 5) }, watering[1:2]},
+		{"manual watering of a plant", func() (any, error) { return store.WateringRecords("section-B", 0, 10) }, watering[2:]},
+		{"plant events", func() (any, error) { return store.PlantEvents("basil-1") }, plants[:2]},
+		{"plant history", func() (any, error) { return store.PlantHistory("basil-1", 10, 30) }, samples[1:4]},
+		{"unknown plant", func() (any, error) { return store.PlantHistory("fern-1", 0, 100) }, []PlantSample(nil)},
+		{"every plant in a tick range", func() (any, error) { return store.PlantSamples(10, 20) }, []PlantSample{samples[1], samples[2], mint}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.query()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// Timestamps come back in the local time zone.
+			if !reflect.DeepEqual(utc(got), tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func utc(v any) any {
+	switch records := v.(type) {
+	case []WateringRecord:
+		for i := range records {
+			records[i].Timestamp = records[i].Timestamp.UTC()
+		}
+	case []PlantEvent:
+		for i := range records {
+			records[i].Timestamp = records[i].Timestamp.UTC()
+		}
+	case []PlantSample:
+		for i := range records {
+			records[i].Timestamp = records[i].Timestamp.UTC()
+		}
+	}
+	return v
+}
+
+func TestSQLite_Migrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	openTestStore(t, path).Close()
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		t.Fatalf("failed to read the schema version: %v", err)
+	}
+	if version != len(migrations) {
+		t.Errorf("expected schema version %d, got %d", len(migrations), version)
+	}
+	// Reopening an up-to-date database runs no migration.
+	openTestStore(t, path).Close()
+
+	if _, err := db.Exec(fmt.Sprintf("PRAGMA user_version = %d", len(migrations)+1)); err != nil {
+		t.Fatalf("failed to set the schema version: %v", err)
+	}
+	db.Close()
+	_, err = OpenSQLite(path)
+	if err == nil || !strings.Contains(err.Error(), "newer than the supported version") {
+		t.Errorf("expected a newer schema to be refused, got %v", err)
+	}
+}
+
+// TestSQLite_MigratesReleasedSchemas opens a database of every released
+// schema version and reads back what it held. Add a dump with each new
+// migration.
+func TestSQLite_MigratesReleasedSchemas(t *testing.T) {
+	dumps, err := filepath.Glob("testdata/history-v*.sql")
+	if err != nil {
+		t.Fatalf("failed to list the dumps: %v", err)
+	}
+	if len(dumps) != len(migrations) {
+		t.Fatalf("expected a dump for each of the %d schema versions, got %v", len(migrations), dumps)
+	}
+
+	for _, dump := range dumps {
+		t.Run(filepath.Base(dump), func(t *testing.T) {
+			statements, err := os.ReadFile(dump)
+			if err != nil {
+				t.Fatalf("failed to read the dump: %v", err)
+			}
+			path := filepath.Join(t.TempDir(), "history.db")
+			db, err := sql.Open("sqlite", path)
+			if err != nil {
+				t.Fatalf("failed to open database: %v", err)
+			}
+			if _, err := db.Exec(string(statements)); err != nil {
+				t.Fatalf("failed to restore the dump: %v", err)
+			}
+			db.Close()
+
+			store := openTestStore(t, path)
+			defer store.Close()
+			at := func(tick int) time.Time { return time.Unix(1700000000+int64(tick), 0) }
+			readings, err := store.Readings("sensor-1", at(0), at(10))
+			if err != nil || len(readings) != 1 || readings[0].Value != 0.42 || !readings[0].Timestamp.Equal(at(3)) {
+				t.Errorf("expected the reading of tick 3, got %+v, %v", readings, err)
+			}
+			records, err := store.WateringRecords("section-A", 0, 10)
+			if err != nil || len(records) != 1 || records[0].Type != events.WateringCompleted || !records[0].Manual {
+				t.Errorf("expected the completed manual watering, got %+v, %v", records, err)
+			}
+			plantEvents, err := store.PlantEvents("basil-1")
+			if err != nil || len(plantEvents) != 1 || plantEvents[0].Type != events.PlantDied {
+				t.Errorf("expected the death of basil-1, got %+v, %v", plantEvents, err)
+			}
+			samples, err := store.PlantHistory("basil-1", 0, 10)
+			if err != nil || len(samples) != 1 || samples[0].Alive || samples[0].GrowthStage != 0.3 {
+				t.Errorf("expected the last sample of basil-1, got %+v, %v", samples, err)
+			}
+		})
+	}
+}
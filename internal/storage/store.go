@@ -0,0 +1,108 @@
+// Package storage persists what happens in a simulation so that it outlives
+// the process: sensor readings, watering events, plant lifecycle events and
+// periodic plant state samples. A Store writes and queries them, and a
+// Recorder feeds a Store from a running greenhouse without blocking its ticks.
+// An EventLog appends the same to a JSON Lines file instead, in the order it
+// happened, for other tools to read back.
+package storage
+
+import (
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"time"
+)
+
+// Reading is a stored sensor reading.
+type Reading struct {
+	SensorID  string
+	SectionID string
+	Tick      int
+	Timestamp time.Time
+	Value     float64
+}
+
+// WateringRecord is a stored watering event: one row per started, completed,
+// cancelled, paused, resumed or skipped notification.
+type WateringRecord struct {
+	Type       events.Type
+	Tick       int
+	Timestamp  time.Time
+	EventID    string
+	SectionID  string
+	PlantID    string
+	Amount     float64
+	Manual     bool
+	Method     models.IrrigationMethod
+	ScheduleID string
+	Label      string
+}
+
+// PlantEvent is a stored plant lifecycle event: a plant added, removed or
+// found dead.
+type PlantEvent struct {
+	Type      events.Type
+	Tick      int
+	Timestamp time.Time
+	PlantID   string
+	SectionID string
+}
+
+// PlantSample is the state of a plant on a tick.
+type PlantSample struct {
+	PlantID        string
+	SectionID      string
+	Tick           int
+	Timestamp      time.Time
+	SoilSaturation float64
+	Health         float64
+	GrowthStage    float64
+	Alive          bool
+}
+
+// ReadingStore persists sensor readings.
+type ReadingStore interface {
+	// SaveReadings stores readings.
+	SaveReadings(readings []Reading) error
+	// Readings returns the readings of a sensor taken from from up to, but
+	// not including, to, oldest first.
+	Readings(sensorID string, from, to time.Time) ([]Reading, error)
+}
+
+// WateringStore persists watering events.
+type WateringStore interface {
+	// SaveWateringRecords stores watering records.
+	SaveWateringRecords(records []WateringRecord) error
+	// WateringRecords returns the records of a section between two ticks,
+	// both included, oldest first.
+	WateringRecords(sectionID string, fromTick, toTick int) ([]WateringRecord, error)
+}
+
+// PlantEventStore persists plant lifecycle events.
+type PlantEventStore interface {
+	// SavePlantEvents stores plant lifecycle events.
+	SavePlantEvents(events []PlantEvent) error
+	// PlantEvents returns the lifecycle events of a plant, oldest first.
+	PlantEvents(plantID string) ([]PlantEvent, error)
+}
+
+// PlantSampleStore persists plant state samples.
+type PlantSampleStore interface {
+	// SavePlantSamples stores plant state samples.
+	SavePlantSamples(samples []PlantSample) error
+	// PlantHistory returns the samples of a plant between two ticks, both
+	// included, oldest first.
+	PlantHistory(plantID string, fromTick, toTick int) ([]PlantSample, error)
+	// PlantSamples returns the samples of every plant between two ticks, both
+	// included, ordered by tick and then plant ID.
+	PlantSamples(fromTick, toTick int) ([]PlantSample, error)
+}
+
+// Store persists everything a Recorder records.
+type Store interface {
+	ReadingStore
+	WateringStore
+	PlantEventStore
+	PlantSampleStore
+	// Close releases the store. Stores must not be used once closed.
+	Close() error
+}
@@ -0,0 +1,47 @@
+-- A history database of schema version 1, as OpenSQLite created it. Keep
+-- one dump per released schema version; released dumps must never change.
+CREATE TABLE readings (
+	sensor_id TEXT NOT NULL,
+	section_id TEXT NOT NULL,
+	tick INTEGER NOT NULL,
+	timestamp INTEGER NOT NULL,
+	value REAL NOT NULL
+);
+CREATE INDEX readings_by_sensor ON readings (sensor_id, timestamp);
+CREATE TABLE watering_records (
+	type TEXT NOT NULL,
+	tick INTEGER NOT NULL,
+	timestamp INTEGER NOT NULL,
+	event_id TEXT NOT NULL,
+	section_id TEXT NOT NULL,
+	plant_id TEXT NOT NULL,
+	amount REAL NOT NULL,
+	manual INTEGER NOT NULL,
+	method TEXT NOT NULL,
+	schedule_id TEXT NOT NULL
+);
+CREATE INDEX watering_records_by_section ON watering_records (section_id, tick);
+CREATE TABLE plant_events (
+	type TEXT NOT NULL,
+	tick INTEGER NOT NULL,
+	timestamp INTEGER NOT NULL,
+	plant_id TEXT NOT NULL,
+	section_id TEXT NOT NULL
+);
+CREATE INDEX plant_events_by_plant ON plant_events (plant_id, tick);
+CREATE TABLE plant_samples (
+	plant_id TEXT NOT NULL,
+	section_id TEXT NOT NULL,
+	tick INTEGER NOT NULL,
+	timestamp INTEGER NOT NULL,
+	soil_saturation REAL NOT NULL,
+	health REAL NOT NULL,
+	growth_stage REAL NOT NULL,
+	alive INTEGER NOT NULL
+);
+CREATE INDEX plant_samples_by_plant ON plant_samples (plant_id, tick);
+INSERT INTO readings VALUES ('sensor-1', 'section-A', 3, 1700000003000000000, 0.42);
+INSERT INTO watering_records VALUES ('watering_completed', 4, 1700000004000000000, 'watering-1', 'section-A', '', 0.5, 1, 'drip', '');
+INSERT INTO plant_events VALUES ('plant_died', 5, 1700000005000000000, 'basil-1', 'section-A');
+INSERT INTO plant_samples VALUES ('basil-1', 'section-A', 5, 1700000005000000000, 0.1, 0, 0.3, 0);
+PRAGMA user_version = 1;
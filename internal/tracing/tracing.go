@@ -0,0 +1,64 @@
+// Package tracing sets up the OpenTelemetry tracing a config's tracing section
+// turns on: the tick spans of package engine, the write spans of package
+// storage and a span per request to the HTTP and gRPC APIs. Nothing here runs
+// when tracing is off, and the simulator then starts no spans at all.
+package tracing
+
+import (
+	"context"
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/greenhouse"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+// TracerName is the name of the tracer of the simulation spans.
+const TracerName = "greenhouse-simulator"
+
+// HTTPOperation is the name of the server span of an HTTP API request.
+const HTTPOperation = "greenhouse.api"
+
+// NewProvider returns a tracer provider that samples cfg.SampleRatio of the
+// traces and exports them in the background over OTLP/HTTP. Nothing is sent
+// until the first span ends; Shutdown flushes the spans still queued.
+// Returns an error if the exporter cannot be set up.
+func NewProvider(cfg config.TracingConfig) (*sdktrace.TracerProvider, error) {
+	cfg = cfg.WithDefaults()
+	var opts []otlptracehttp.Option
+	if cfg.Endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+	}
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", cfg.ServiceName))),
+	), nil
+}
+
+// Instrument traces every tick of g from the next one on with tp.
+func Instrument(g greenhouse.Greenhouse, tp trace.TracerProvider) {
+	g.Simulator().SetTracer(tp.Tracer(TracerName))
+}
+
+// HTTPHandler wraps h so that every request is traced with tp, continuing
+// the trace of the caller when the request carries one.
+func HTTPHandler(h http.Handler, tp trace.TracerProvider) http.Handler {
+	return otelhttp.NewHandler(h, HTTPOperation, otelhttp.WithTracerProvider(tp))
+}
+
+// GRPCServerOption traces every call to a gRPC server with tp.
+func GRPCServerOption(tp trace.TracerProvider) grpc.ServerOption {
+	return grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithTracerProvider(tp)))
+}
@@ -0,0 +1,112 @@
+package tracing
+
+import (
+	"greenhouse-simulator/internal/config"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/greenhouse"
+	"greenhouse-simulator/internal/storage"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestProvider() (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	return sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)), exporter
+}
+
+func newTestGreenhouse(t *testing.T) greenhouse.Greenhouse {
+	t.Helper()
+	g, err := greenhouse.New(config.Default())
+	if err != nil {
+		t.Fatalf("failed to build greenhouse: %v", err)
+	}
+	return g
+}
+
+func TestInstrument_TraceOfOneTick(t *testing.T) {
+	g := newTestGreenhouse(t)
+	provider, exporter := newTestProvider()
+	Instrument(g, provider)
+	store, err := storage.OpenSQLite(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+	recorder := storage.NewRecorder(store, storage.Config{Tracer: provider.Tracer(TracerName)}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err := g.Exporters().Register("store", recorder, 0); err != nil {
+		t.Fatalf("failed to register recorder: %v", err)
+	}
+
+	g.Simulator().Step()
+	if err := g.Exporters().Remove("store"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	var tick tracetest.SpanStub
+	for _, span := range spans {
+		if span.Name == engine.TickSpan {
+			tick = span
+		}
+	}
+	if tick.Parent.IsValid() {
+		t.Fatalf("expected a root tick span, got %+v", spans)
+	}
+	var children []string
+	for _, span := range spans {
+		if span.Parent.SpanID() == tick.SpanContext.SpanID() && span.SpanContext.TraceID() == tick.SpanContext.TraceID() {
+			children = append(children, span.Name)
+		}
+	}
+	expected := []string{engine.PlantUpdatePhase, "rotations", "lights", "weather", "hvac", "humidity", "water_temperature", "watering.schedule", "costs", "alerts", "sensors.sample", storage.FlushSpan}
+	if !reflect.DeepEqual(children, expected) {
+		t.Errorf("expected the child spans %v, got %v", expected, children)
+	}
+
+	attributes := map[attribute.Key]attribute.Value{}
+	for _, kv := range tick.Attributes {
+		attributes[kv.Key] = kv.Value
+	}
+	if attributes[engine.TickAttribute].AsInt64() != 0 || attributes[engine.PlantsAttribute].AsInt64() != 3 {
+		t.Errorf("expected tick 0 with 3 plants, got %v", tick.Attributes)
+	}
+	for _, phase := range expected[:4] {
+		if _, ok := attributes[attribute.Key(engine.PhaseDurationAttribute(phase))]; !ok {
+			t.Errorf("expected the duration of %s, got %v", phase, tick.Attributes)
+		}
+	}
+}
+
+func TestInstrument_OffByDefault(t *testing.T) {
+	g := newTestGreenhouse(t)
+	_, exporter := newTestProvider()
+	g.Simulator().Step()
+	if spans := exporter.GetSpans(); len(spans) != 0 {
+		t.Errorf("expected no spans without a tracer, got %v", spans)
+	}
+	if g.Simulator().TickContext() == nil {
+		t.Error("expected a background context between ticks")
+	}
+}
+
+func TestHTTPHandler(t *testing.T) {
+	provider, exporter := newTestProvider()
+	handler := HTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), provider)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/plants", nil))
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != HTTPOperation {
+		t.Errorf("expected one %s span, got %v", HTTPOperation, spans)
+	}
+}
@@ -0,0 +1,175 @@
+// Package units converts sensor readings from the units the model uses to
+// those a UI shows, and formats them. The model reads temperatures in
+// Celsius, CO2 in ppm and everything else as a fraction from 0.0 to 1.0;
+// each sensor type declares that canonical unit and the units its readings
+// convert to, so that converting a light reading to Fahrenheit fails rather
+// than showing nonsense.
+package units
+
+import (
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/models"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Unit is a unit a reading can be shown in.
+type Unit string
+
+const (
+	// Celsius is the canonical unit of temperatures.
+	Celsius Unit = "celsius"
+	// Fahrenheit is a temperature in degrees Fahrenheit.
+	Fahrenheit Unit = "fahrenheit"
+	// Fraction is the canonical unit of moisture, humidity, light and
+	// salinity, from 0.0 to 1.0.
+	Fraction Unit = "fraction"
+	// Percent is a fraction times 100.
+	Percent Unit = "percent"
+	// PPM is the canonical unit of CO2, in parts per million.
+	PPM Unit = "ppm"
+)
+
+// Symbol returns the symbol a formatted reading ends with, empty for a
+// Fraction.
+func (u Unit) Symbol() string {
+	switch u {
+	case Celsius:
+		return "°C"
+	case Fahrenheit:
+		return "°F"
+	case Percent:
+		return "%"
+	case PPM:
+		return "ppm"
+	}
+	return ""
+}
+
+// ErrUnsupportedConversion is returned when a reading cannot be shown in a
+// unit, because the unit is unknown or not one of its sensor type.
+var ErrUnsupportedConversion = errors.New("unsupported unit conversion")
+
+// sensorUnits is the registry of the units of each sensor type, canonical
+// unit first.
+var sensorUnits = map[models.SensorType][]Unit{
+	models.SoilMoisture:     {Fraction, Percent},
+	models.Temperature:      {Celsius, Fahrenheit},
+	models.Light:            {Fraction, Percent},
+	models.Humidity:         {Fraction, Percent},
+	models.CO2:              {PPM},
+	models.Salinity:         {Fraction, Percent},
+	models.SoilTemperature:  {Celsius, Fahrenheit},
+	models.WaterTemperature: {Celsius, Fahrenheit},
+}
+
+// Canonical returns the unit the model reads a sensor type in.
+// Returns an error if the sensor type is unknown.
+func Canonical(sensorType models.SensorType) (Unit, error) {
+	units, err := Allowed(sensorType)
+	if err != nil {
+		return "", err
+	}
+	return units[0], nil
+}
+
+// Allowed returns the units the readings of a sensor type convert to, the
+// canonical one first. Returns an error if the sensor type is unknown.
+func Allowed(sensorType models.SensorType) ([]Unit, error) {
+	units, ok := sensorUnits[sensorType]
+	if !ok {
+		return nil, sensorType.Validate()
+	}
+	return slices.Clone(units), nil
+}
+
+// SensorReading is a reading with the type of the sensor that took it, which
+// says what unit its value is in, see Canonical.
+type SensorReading struct {
+	models.SensorReading
+	Type models.SensorType
+}
+
+// Convert returns the value of the reading in target. Returns an error
+// wrapping ErrUnsupportedConversion if target is not one of the units of the
+// sensor type, see Allowed, or an error if the sensor type is unknown.
+func (r SensorReading) Convert(target Unit) (float64, error) {
+	units, err := Allowed(r.Type)
+	if err != nil {
+		return 0, err
+	}
+	if !slices.Contains(units, target) {
+		return 0, fmt.Errorf("%w: %s reading to %s", ErrUnsupportedConversion, r.Type, target)
+	}
+	return FromCanonical(r.Value, units[0], target)
+}
+
+// FromCanonical converts a value from a canonical unit to target: Celsius to
+// Fahrenheit or Fraction to Percent, or any unit to itself. Returns an error
+// wrapping ErrUnsupportedConversion for any other pair.
+func FromCanonical(value float64, canonical, target Unit) (float64, error) {
+	switch {
+	case canonical == target:
+		return value, nil
+	case canonical == Celsius && target == Fahrenheit:
+		return value*9/5 + 32, nil
+	case canonical == Fraction && target == Percent:
+		return value * 100, nil
+	}
+	return 0, fmt.Errorf("%w: %s to %s", ErrUnsupportedConversion, canonical, target)
+}
+
+// ToCanonical is the inverse of FromCanonical: it converts a value in unit
+// back to the canonical unit, for a value a user entered.
+func ToCanonical(value float64, unit, canonical Unit) (float64, error) {
+	switch {
+	case unit == canonical:
+		return value, nil
+	case unit == Fahrenheit && canonical == Celsius:
+		return (value - 32) * 5 / 9, nil
+	case unit == Percent && canonical == Fraction:
+		return value / 100, nil
+	}
+	return 0, fmt.Errorf("%w: %s to %s", ErrUnsupportedConversion, unit, canonical)
+}
+
+// FormatOptions configures FormatReading. Unit is the unit to show the
+// reading in, its canonical unit when empty, Precision the number of
+// decimals, and DecimalComma writes a decimal comma, as many locales do,
+// rather than a point.
+type FormatOptions struct {
+	Unit         Unit
+	Precision    int
+	DecimalComma bool
+}
+
+// FormatReading formats the value of a reading with the symbol of its unit,
+// such as "62.4 %" or "18.3 °C". Returns an error if the reading cannot be
+// converted, see SensorReading.Convert, or the precision is negative.
+func FormatReading(r SensorReading, opts FormatOptions) (string, error) {
+	if opts.Precision < 0 {
+		return "", errors.New("format precision cannot be negative")
+	}
+	unit := opts.Unit
+	if unit == "" {
+		canonical, err := Canonical(r.Type)
+		if err != nil {
+			return "", err
+		}
+		unit = canonical
+	}
+	value, err := r.Convert(unit)
+	if err != nil {
+		return "", err
+	}
+	text := strconv.FormatFloat(value, 'f', opts.Precision, 64)
+	if opts.DecimalComma {
+		text = strings.Replace(text, ".", ",", 1)
+	}
+	if symbol := unit.Symbol(); symbol != "" {
+		text += " " + symbol
+	}
+	return text, nil
+}
@@ -0,0 +1,135 @@
+package units
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/models"
+	"math"
+	"testing"
+)
+
+func reading(sensorType models.SensorType, value float64) SensorReading {
+	return SensorReading{SensorReading: models.SensorReading{SensorID: "sensor-1", Value: value}, Type: sensorType}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		reading  SensorReading
+		target   Unit
+		expected float64
+		err      error
+	}{
+		{"celsius to fahrenheit", reading(models.Temperature, 18.5), Fahrenheit, 65.3, nil},
+		{"freezing to fahrenheit", reading(models.Temperature, 0), Fahrenheit, 32, nil},
+		{"below zero to fahrenheit", reading(models.SoilTemperature, -40), Fahrenheit, -40, nil},
+		{"celsius to celsius", reading(models.Temperature, 18.5), Celsius, 18.5, nil},
+		{"moisture to percent", reading(models.SoilMoisture, 0.624), Percent, 62.4, nil},
+		{"humidity to percent", reading(models.Humidity, 1), Percent, 100, nil},
+		{"light to percent", reading(models.Light, 0.25), Percent, 25, nil},
+		{"salinity to fraction", reading(models.Salinity, 0.1), Fraction, 0.1, nil},
+		{"co2 in ppm", reading(models.CO2, 420), PPM, 420, nil},
+		{"light to fahrenheit", reading(models.Light, 0.5), Fahrenheit, 0, ErrUnsupportedConversion},
+		{"temperature to percent", reading(models.Temperature, 20), Percent, 0, ErrUnsupportedConversion},
+		{"co2 to percent", reading(models.CO2, 420), Percent, 0, ErrUnsupportedConversion},
+		{"unknown unit", reading(models.Humidity, 0.5), Unit("kelvin"), 0, ErrUnsupportedConversion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.reading.Convert(tt.target)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	if _, err := reading("pressure", 1).Convert(Celsius); err == nil || err.Error() != "sensor type must be soil_moisture, temperature, light, humidity, co2, salinity, soil_temperature or water_temperature: pressure" {
+		t.Errorf("expected an unknown sensor type error, got %v", err)
+	}
+}
+
+func TestConvert_RoundTrip(t *testing.T) {
+	tests := []struct {
+		sensorType models.SensorType
+		unit       Unit
+		value      float64
+	}{
+		{models.Temperature, Fahrenheit, 21.7},
+		{models.Temperature, Fahrenheit, -12.25},
+		{models.SoilTemperature, Celsius, 14},
+		{models.SoilMoisture, Percent, 0.333},
+		{models.Humidity, Percent, 0},
+		{models.Light, Fraction, 0.8},
+		{models.CO2, PPM, 1200},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.sensorType)+" in "+string(tt.unit), func(t *testing.T) {
+			converted, err := reading(tt.sensorType, tt.value).Convert(tt.unit)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			canonical, err := Canonical(tt.sensorType)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			back, err := ToCanonical(converted, tt.unit, canonical)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(back-tt.value) > 1e-9 {
+				t.Errorf("expected %v back, got %v", tt.value, back)
+			}
+		})
+	}
+
+	if _, err := ToCanonical(50, Percent, Celsius); !errors.Is(err, ErrUnsupportedConversion) {
+		t.Errorf("expected an unsupported conversion, got %v", err)
+	}
+}
+
+func TestFormatReading(t *testing.T) {
+	tests := []struct {
+		name     string
+		reading  SensorReading
+		opts     FormatOptions
+		expected string
+		err      string
+	}{
+		{"percent", reading(models.SoilMoisture, 0.624), FormatOptions{Unit: Percent, Precision: 1}, "62.4 %", ""},
+		{"canonical celsius", reading(models.Temperature, 18.26), FormatOptions{Precision: 1}, "18.3 °C", ""},
+		{"fahrenheit", reading(models.Temperature, 18.5), FormatOptions{Unit: Fahrenheit, Precision: 2}, "65.30 °F", ""},
+		{"fraction has no symbol", reading(models.Humidity, 0.5), FormatOptions{Precision: 2}, "0.50", ""},
+		{"no decimals", reading(models.CO2, 419.6), FormatOptions{}, "420 ppm", ""},
+		{"decimal comma", reading(models.Light, 0.755), FormatOptions{Unit: Percent, Precision: 1, DecimalComma: true}, "75,5 %", ""},
+		{"unsupported unit", reading(models.Light, 0.5), FormatOptions{Unit: Fahrenheit}, "", "unsupported unit conversion: light reading to fahrenheit"},
+		{"negative precision", reading(models.Light, 0.5), FormatOptions{Precision: -1}, "", "format precision cannot be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatReading(tt.reading, tt.opts)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestAllowed_EverySensorType(t *testing.T) {
+	for _, sensorType := range []models.SensorType{models.SoilMoisture, models.Temperature, models.Light, models.Humidity, models.CO2, models.Salinity, models.SoilTemperature} {
+		units, err := Allowed(sensorType)
+		if err != nil || len(units) == 0 {
+			t.Errorf("expected units for %s, got %v, %v", sensorType, units, err)
+		}
+	}
+}
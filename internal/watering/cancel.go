@@ -0,0 +1,111 @@
+package watering
+
+import (
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/events"
+)
+
+// ErrNoActiveWatering is returned when cancelling, pausing or resuming the
+// watering of a section that has no matching active event.
+var ErrNoActiveWatering = errors.New("no active watering")
+
+// CancelWatering aborts every queued, running or paused watering event that
+// waters a section: the events of the section, and those of schedules
+// selecting plants of the section by tag, type or zone. Each cancelled event
+// is recorded in the history with the water it applied so far, and the
+// schedules watering the section are held idle for the cancel cooldown so
+// they do not immediately restart the watering.
+// Returns an error wrapping ErrNoActiveWatering if nothing is watering the section.
+//
+// This method is safe for concurrent use.
+func (c *controller) CancelWatering(sectionID string) error {
+	c.mu.Lock()
+	var cancelled []*activeEvent
+	remaining := c.active[:0]
+	for _, a := range c.active {
+		if !c.watersSection(a, sectionID) {
+			remaining = append(remaining, a)
+			continue
+		}
+		cancelled = append(cancelled, a)
+		c.record(a, c.lastTick, true)
+		c.publish(events.WateringCancelled, c.lastTick, a.event)
+		c.publishWatered(a, c.lastTick)
+	}
+	c.active = remaining
+	if len(cancelled) > 0 {
+		c.cooldowns[sectionID] = c.lastTick + c.cancelCooldown(sectionID, cancelled)
+	}
+	published := c.flush()
+	c.mu.Unlock()
+
+	if len(cancelled) == 0 {
+		return fmt.Errorf("%w for section: %s", ErrNoActiveWatering, sectionID)
+	}
+	c.deliver(published)
+	return nil
+}
+
+// PauseWatering suspends every running watering event that waters a section,
+// as CancelWatering finds them. Paused
+// events keep their remaining amount and duration, and the section's schedule
+// does not start new events while they are paused.
+// Returns an error wrapping ErrNoActiveWatering if no event can be paused.
+//
+// This method is safe for concurrent use.
+func (c *controller) PauseWatering(sectionID string) error {
+	return c.setPaused(sectionID, true, events.WateringPaused)
+}
+
+// ResumeWatering continues every paused watering event of a section from
+// where it stopped.
+// Returns an error wrapping ErrNoActiveWatering if no event is paused.
+//
+// This method is safe for concurrent use.
+func (c *controller) ResumeWatering(sectionID string) error {
+	return c.setPaused(sectionID, false, events.WateringResumed)
+}
+
+func (c *controller) setPaused(sectionID string, paused bool, eventType events.Type) error {
+	c.mu.Lock()
+	changed := false
+	for _, a := range c.active {
+		if a.paused == paused || !c.watersSection(a, sectionID) {
+			continue
+		}
+		changed = true
+		a.paused = paused
+		c.publish(eventType, c.lastTick, a.event)
+	}
+	published := c.flush()
+	c.mu.Unlock()
+
+	if !changed {
+		return fmt.Errorf("%w for section: %s", ErrNoActiveWatering, sectionID)
+	}
+	c.deliver(published)
+	return nil
+}
+
+// cancelCooldown returns the number of ticks the schedules watering a section
+// stay idle after the cancellation of events: the configured cooldown, or
+// else the longest check interval among the section's schedules and those of
+// the events. Callers must hold c.mu.
+func (c *controller) cancelCooldown(sectionID string, cancelled []*activeEvent) int {
+	if c.config.CancelCooldown > 0 {
+		return c.config.CancelCooldown
+	}
+	cooldown := 0
+	for _, schedule := range c.schedules {
+		if schedule.SectionID == sectionID {
+			cooldown = max(cooldown, schedule.CheckInterval)
+		}
+	}
+	for _, a := range cancelled {
+		if schedule := c.schedules[a.event.ScheduleID]; schedule != nil {
+			cooldown = max(cooldown, schedule.CheckInterval)
+		}
+	}
+	return cooldown
+}
@@ -0,0 +1,230 @@
+package watering
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"testing"
+	"time"
+)
+
+func TestCancelWatering_MidEvent(t *testing.T) {
+	controller, mockData, published := newTestController(Config{TickInterval: time.Second})
+
+	// 0.4 over 4 ticks = +0.05 per plant per tick
+	if err := controller.WaterSection("section-A", 0.4, 4*time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	controller.OnTick(0)
+	controller.OnTick(1)
+
+	if err := controller.CancelWatering("section-A"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	controller.OnTick(2)
+
+	for _, plant := range mockData.plantsBySectionID["section-A"] {
+		if !almostEqual(plant.SoilSaturation, 0.3) {
+			t.Errorf("expected watering to stop at 0.3, got %.2f", plant.SoilSaturation)
+		}
+	}
+	if len(controller.GetActiveEvents()) != 0 {
+		t.Errorf("expected no active events after cancel")
+	}
+
+	history := controller.GetWateringHistory("section-A", 0)
+	if len(history) != 1 {
+		t.Fatalf("expected one history entry, got %d", len(history))
+	}
+	if !history[0].Cancelled || !almostEqual(history[0].Applied, 0.2) || history[0].EndTick != 1 {
+		t.Errorf("expected cancelled entry applying 0.2 ending at tick 1, got %+v", history[0])
+	}
+	if countEvents(*published, events.WateringCancelled) != 1 || countEvents(*published, events.WateringCompleted) != 0 {
+		t.Errorf("expected one cancelled and no completed event")
+	}
+}
+
+func TestCancelWatering_NothingActive(t *testing.T) {
+	controller, _, _ := newTestController(Config{})
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"cancel", func() error { return controller.CancelWatering("section-A") }},
+		{"pause", func() error { return controller.PauseWatering("section-A") }},
+		{"resume", func() error { return controller.ResumeWatering("section-A") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if !errors.Is(err, ErrNoActiveWatering) {
+				t.Errorf("expected ErrNoActiveWatering, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPauseResumeWatering_PreservesRemainingAmount(t *testing.T) {
+	controller, mockData, _ := newTestController(Config{TickInterval: time.Second})
+
+	if err := controller.WaterSection("section-A", 0.4, 4*time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	controller.OnTick(0)
+
+	if err := controller.PauseWatering("section-A"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := controller.PauseWatering("section-A"); !errors.Is(err, ErrNoActiveWatering) {
+		t.Errorf("expected pausing twice to report nothing to pause, got %v", err)
+	}
+	for tick := 1; tick <= 5; tick++ {
+		controller.OnTick(tick)
+	}
+	plants := mockData.plantsBySectionID["section-A"]
+	if !almostEqual(plants[0].SoilSaturation, 0.25) {
+		t.Errorf("expected no water while paused, got %.2f", plants[0].SoilSaturation)
+	}
+
+	if err := controller.ResumeWatering("section-A"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for tick := 6; tick <= 8; tick++ {
+		controller.OnTick(tick)
+	}
+
+	for _, plant := range plants {
+		if !almostEqual(plant.SoilSaturation, 0.4) {
+			t.Errorf("expected full amount after resume, got %.2f", plant.SoilSaturation)
+		}
+	}
+	history := controller.GetWateringHistory("section-A", 0)
+	if len(history) != 1 || history[0].EndTick != 8 || !almostEqual(history[0].Applied, 0.4) {
+		t.Errorf("expected completion at tick 8 applying 0.4, got %+v", history)
+	}
+}
+
+func TestPauseWatering_BlocksSchedule(t *testing.T) {
+	controller, _, _ := newTestController(Config{TickInterval: time.Second})
+	if err := controller.AddSchedule(thirstySchedule); err != nil {
+		t.Fatalf("failed to add schedule: %v", err)
+	}
+	if err := controller.WaterSection("section-A", 0.1, 10*time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := controller.PauseWatering("section-A"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	controller.OnTick(0)
+
+	if active := controller.GetActiveEvents(); len(active) != 1 {
+		t.Errorf("expected the schedule to wait for the paused event, got %d active events", len(active))
+	}
+}
+
+func TestCancelWatering_Cooldown(t *testing.T) {
+	tests := []struct {
+		name            string
+		config          Config
+		checkInterval   int
+		expectedRestart int
+	}{
+		// Cancelled at tick 0, checks every tick: idle through tick 3
+		{"configured cooldown", Config{CancelCooldown: 3}, 1, 4},
+		// Cancelled at tick 0, checks every 2 ticks: the tick 2 check is in the cooldown window
+		{"defaults to one check interval", Config{}, 2, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.TickInterval = time.Second
+			controller, _, published := newTestController(config)
+			schedule := thirstySchedule
+			schedule.CheckInterval = tt.checkInterval
+			schedule.Duration = time.Hour
+			if err := controller.AddSchedule(schedule); err != nil {
+				t.Fatalf("failed to add schedule: %v", err)
+			}
+
+			controller.OnTick(0)
+			if err := controller.CancelWatering("section-A"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			restartTick := -1
+			for tick := 1; tick <= 10 && restartTick < 0; tick++ {
+				controller.OnTick(tick)
+				if countEvents(*published, events.WateringStarted) == 2 {
+					restartTick = tick
+				}
+			}
+			if restartTick != tt.expectedRestart {
+				t.Errorf("expected schedule to restart at tick %d, got %d", tt.expectedRestart, restartTick)
+			}
+		})
+	}
+}
+
+func TestCancelWatering_OnlyAffectsSection(t *testing.T) {
+	controller, mockData, _ := newTestController(Config{})
+	mockData.plantsBySectionID["section-B"] = []*models.Plant{createTestPlant("plant-3", "section-B", 0.2)}
+
+	if err := controller.WaterSection("section-A", 0.2, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := controller.WaterSection("section-B", 0.2, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := controller.CancelWatering("section-A"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	active := controller.GetActiveEvents()
+	if len(active) != 1 || active[0].SectionID != "section-B" {
+		t.Errorf("expected only the section-B event to remain, got %+v", active)
+	}
+}
+
+func TestCancelWatering_TaggedSchedule(t *testing.T) {
+	controller, mockData, published := newTestController(Config{TickInterval: time.Second})
+	mockData.plantsBySectionID["section-A"][0].Tags = []string{"trial"}
+	schedule := models.WateringSchedule{Tag: "trial", TargetSaturation: 1.0, CheckInterval: 2, WaterAmount: 0.4, Duration: time.Hour, Enabled: true}
+	if err := controller.AddSchedule(schedule); err != nil {
+		t.Fatalf("failed to add schedule: %v", err)
+	}
+
+	controller.OnTick(0)
+	if active := controller.GetActiveEvents(); len(active) != 1 || active[0].SectionID != "" {
+		t.Fatalf("expected the tag schedule to start an event without a section, got %+v", active)
+	}
+
+	// The event waters a plant of section-A, so it pauses and cancels with it.
+	if err := controller.PauseWatering("section-A"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := controller.PauseWatering("section-A"); !errors.Is(err, ErrNoActiveWatering) {
+		t.Errorf("expected pausing twice to report nothing to pause, got %v", err)
+	}
+	if err := controller.CancelWatering("section-A"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if active := controller.GetActiveEvents(); len(active) != 0 {
+		t.Fatalf("expected the event to be cancelled, got %+v", active)
+	}
+
+	// Cancelled at tick 0, checks every 2 ticks: held idle through tick 2.
+	restartTick := -1
+	for tick := 1; tick <= 10 && restartTick < 0; tick++ {
+		controller.OnTick(tick)
+		if countEvents(*published, events.WateringStarted) == 2 {
+			restartTick = tick
+		}
+	}
+	if restartTick != 4 {
+		t.Errorf("expected the schedule to restart at tick 4, got %d", restartTick)
+	}
+}
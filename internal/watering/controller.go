@@ -0,0 +1,895 @@
+package watering
+
+import (
+	"errors"
+	"fmt"
+	"greenhouse-simulator/internal/environment"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultMaxManualAmount is the per-call cap applied to manual watering when
+// Config.MaxManualAmount is left at zero.
+const DefaultMaxManualAmount = 5.0
+
+// ErrNoPlantsInSection is returned when manually watering a section that has
+// no plants.
+var ErrNoPlantsInSection = errors.New("no plants in section")
+
+// Config holds the tunable settings of a watering controller.
+type Config struct {
+	// TickInterval is the simulation tick interval, used to convert event
+	// durations into a number of ticks. Zero applies every event in one tick.
+	TickInterval time.Duration
+	// MaxManualAmount caps the amount of a single manual watering call.
+	// Zero means DefaultMaxManualAmount.
+	MaxManualAmount float64
+	// Supply is the tank every watering event draws from. Nil means an
+	// unlimited supply.
+	Supply *WaterSupply
+	// HistorySize bounds the number of finished events kept in the watering
+	// history. Zero means DefaultHistorySize.
+	HistorySize int
+	// CancelCooldown is the number of ticks a section's schedule stays idle
+	// after its watering was cancelled. Zero means one check interval of the
+	// section's schedule.
+	CancelCooldown int
+	// DayCycle maps ticks onto simulated days. It is required by schedules
+	// with AllowedWindows.
+	DayCycle environment.DayCycle
+	// Methods overrides the profiles of irrigation methods. Methods left out
+	// use DefaultDripProfile, DefaultOverheadProfile and DefaultMistingProfile.
+	Methods map[models.IrrigationMethod]MethodProfile
+	// Humidity receives the humidity raised by overhead and misting
+	// irrigation. Nil disables the side effect.
+	Humidity environment.Humidity
+	// MaxConcurrentEvents caps the number of events in progress at once;
+	// further events wait in the pending queue. Zero means no cap.
+	MaxConcurrentEvents int
+	// MaxFlowPerTick caps the water delivered by all events in one tick.
+	// When demand exceeds it the flow is shared fairly across sections and
+	// throttled events take longer to finish. Zero means no cap.
+	MaxFlowPerTick float64
+	// Zones resolves the zones of schedules selecting a zone. Nil means no
+	// zones, and schedules selecting one are rejected.
+	Zones Zones
+	// PlantEvents publishes a PlantWatered event for each plant an event
+	// watered once the event completes or is cancelled.
+	PlantEvents bool
+	// DedupWindow is the number of ticks a manual watering request ID is
+	// remembered, see ManualOptions.RequestID. Zero means
+	// DefaultDedupWindow.
+	DedupWindow int
+	// ThermalShock is called for every plant an event waters on the first
+	// tick it applies water, so once per event, for the temperature of the
+	// water to shock it, see models.Plant.ThermalShock. Nil disables the
+	// side effect.
+	ThermalShock func(plant *models.Plant)
+}
+
+// Zones tells a controller which zone each section belongs to.
+type Zones interface {
+	// SectionZone returns the ID of the zone of a section, empty for a
+	// section in no zone.
+	SectionZone(sectionID string) string
+	// HasZone reports whether a zone exists.
+	HasZone(zoneID string) bool
+}
+
+// WaterStats summarizes the controller's water consumption.
+type WaterStats struct {
+	Used      float64 // total water drawn for watering events
+	Wasted    float64 // water lost to method inefficiency or runoff
+	Remaining float64 // water left in the tank, zero when Unlimited
+	Unlimited bool    // true when the controller has no tank configured
+	ByMethod  map[models.IrrigationMethod]MethodStats
+	// BySection splits Used between the sections of the watered plants.
+	BySection map[string]float64
+}
+
+// ManualOptions tunes a manual section watering. Zero values mean drip
+// irrigation split evenly across the section.
+//
+// RequestID makes the watering idempotent: a request repeating the ID of one
+// made within Config.DedupWindow ticks queues nothing and gets the event of
+// the first one back, so that clients can safely retry. Label is a note
+// carried by the event, see models.WateringEvent.
+type ManualOptions struct {
+	Method       models.IrrigationMethod
+	Distribution models.DistributionStrategy
+	RequestID    string
+	Label        string
+}
+
+// Controller manages scheduled and manual watering of the greenhouse.
+// Water is applied gradually on each simulation tick, so the controller
+// must be driven by calling OnTick once per tick.
+type Controller interface {
+	// AddSchedule registers an automated watering schedule for a section.
+	AddSchedule(schedule models.WateringSchedule) error
+	// UpdateSchedule replaces the parameters of an existing schedule.
+	UpdateSchedule(schedule models.WateringSchedule) error
+	// RemoveSchedule deletes a schedule.
+	RemoveSchedule(scheduleID string) error
+	// WaterSection manually waters every plant in a section over the given duration.
+	WaterSection(sectionID string, amount float64, duration time.Duration) error
+	// WaterSectionWith is WaterSection with a chosen irrigation method,
+	// distribution, request ID and label, returning the queued event.
+	WaterSectionWith(sectionID string, amount float64, duration time.Duration, options ManualOptions) (models.WateringEvent, error)
+	// WaterPlant manually waters a single plant over one tick.
+	WaterPlant(plantID string, amount float64) error
+	// CancelWatering aborts every active watering event of a section.
+	CancelWatering(sectionID string) error
+	// PauseWatering suspends every active watering event of a section.
+	PauseWatering(sectionID string) error
+	// ResumeWatering continues the paused watering events of a section.
+	ResumeWatering(sectionID string) error
+	// GetActiveEvents returns the watering events that have not completed yet.
+	GetActiveEvents() []models.WateringEvent
+	// ListPendingEvents returns the queued events that have not started yet.
+	ListPendingEvents() []models.WateringEvent
+	// GetWaterStats returns the water used, wasted and remaining so far.
+	GetWaterStats() WaterStats
+	// GetWateringHistory returns the most recent finished events for a section.
+	GetWateringHistory(sectionID string, lastN int) []HistoryEntry
+	// GetWaterUsage totals the water applied to a section over a tick range.
+	GetWaterUsage(sectionID string, fromTick, toTick int) WaterUsage
+	// GetControlState returns the last computation of a proportional schedule.
+	GetControlState(scheduleID string) (ControlState, error)
+	// EvaluateSchedule forecasts a candidate schedule without touching the live state.
+	EvaluateSchedule(schedule models.WateringSchedule, horizonTicks int) (*ScheduleForecast, error)
+	// Snapshot returns a copy of the controller's runtime state.
+	Snapshot() State
+	// Restore replaces the controller's runtime state with a snapshot.
+	Restore(state State)
+	// SetTickInterval changes the interval used to convert the duration of
+	// events that have not started yet into ticks.
+	SetTickInterval(interval time.Duration)
+	// OnTick evaluates schedules and applies one tick's worth of water.
+	OnTick(tick int)
+}
+
+type activeEvent struct {
+	event     models.WateringEvent
+	done      float64 // ticks' worth of water applied, fractional when throttled
+	startTick int
+	started   bool
+	waiting   bool // queued until the tank can cover the event
+	paused    bool
+	delivered float64
+	applied   float64
+	// plants is what each watered plant took, by plant ID, kept with
+	// Config.PlantEvents only.
+	plants map[string]*plantWater
+}
+
+// plantWater is the water the soil of a plant took from an event.
+type plantWater struct {
+	sectionID string
+	amount    float64
+}
+
+type controller struct {
+	plantData PlantDataSource
+	bus       events.Bus
+	config    Config
+	schedules map[string]*models.WateringSchedule
+	active    []*activeEvent
+	nextID    int
+	used      float64
+	wasted    float64
+	sections  map[string]float64 // water used by section
+	history   []HistoryEntry
+	control   map[string]*ControlState
+	cooldowns map[string]int // section ID to the last tick its schedule stays idle
+	deferred  map[string]bool
+	methods   map[models.IrrigationMethod]*MethodStats
+	lastTick  int
+	published []events.Event
+	requests  []request // oldest first
+	mu        sync.Mutex
+}
+
+// NewController creates a watering controller reading plants from plantData
+// and publishing watering events to bus. A nil bus disables event publishing.
+func NewController(plantData PlantDataSource, bus events.Bus, config Config) Controller {
+	if config.MaxManualAmount == 0 {
+		config.MaxManualAmount = DefaultMaxManualAmount
+	}
+	if config.HistorySize == 0 {
+		config.HistorySize = DefaultHistorySize
+	}
+	if config.DedupWindow == 0 {
+		config.DedupWindow = DefaultDedupWindow
+	}
+	config.Methods = defaultMethodProfiles(config.Methods)
+	return &controller{
+		plantData: plantData,
+		bus:       bus,
+		config:    config,
+		schedules: map[string]*models.WateringSchedule{},
+		control:   map[string]*ControlState{},
+		cooldowns: map[string]int{},
+		deferred:  map[string]bool{},
+		methods:   map[models.IrrigationMethod]*MethodStats{},
+		sections:  map[string]float64{},
+	}
+}
+
+// AddSchedule registers an automated watering schedule. When several enabled
+// schedules select the same plant, only the most specific one waters it (see
+// WateringSchedule). Returns an error if:
+// - the schedule sets none of section ID, zone ID, tag or plant type
+// - the zone is unknown to Config.Zones
+// - the check interval is less than one tick
+// - the target saturation is outside 0.0-1.0
+// - the water amount is not positive
+// - an allowed window is invalid, or windows are set without a day cycle
+// - the irrigation method or distribution strategy is unknown
+// - a schedule with the same ID already exists
+//
+// This method is safe for concurrent use.
+func (c *controller) AddSchedule(schedule models.WateringSchedule) error {
+	if err := c.validateSchedule(&schedule); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if exists := c.schedules[schedule.ID]; exists != nil {
+		return errors.New("schedule already exists: " + schedule.ID)
+	}
+	schedule = cloneSchedule(schedule)
+	c.schedules[schedule.ID] = &schedule
+	delete(c.control, schedule.ID)
+	return nil
+}
+
+// UpdateSchedule replaces the parameters of an existing schedule, identified
+// by its ID, and takes effect at the schedule's next check. Events already in
+// progress keep their amount. The proportional control state is reset when
+// the control parameters change. Returns the same validation errors as
+// AddSchedule, or an error if no schedule has the ID.
+//
+// This method is safe for concurrent use.
+func (c *controller) UpdateSchedule(schedule models.WateringSchedule) error {
+	if err := c.validateSchedule(&schedule); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	existing := c.schedules[schedule.ID]
+	if existing == nil {
+		return errors.New("no schedule found for the provided ID: " + schedule.ID)
+	}
+	if !reflect.DeepEqual(existing.Proportional, schedule.Proportional) {
+		delete(c.control, schedule.ID)
+	}
+	schedule = cloneSchedule(schedule)
+	c.schedules[schedule.ID] = &schedule
+	return nil
+}
+
+// RemoveSchedule deletes a schedule. Events it already started run to
+// completion over the schedule's section.
+// Returns an error if no schedule has the ID.
+//
+// This method is safe for concurrent use.
+func (c *controller) RemoveSchedule(scheduleID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.schedules[scheduleID] == nil {
+		return errors.New("no schedule found for the provided ID: " + scheduleID)
+	}
+	delete(c.schedules, scheduleID)
+	delete(c.control, scheduleID)
+	delete(c.deferred, scheduleID)
+	return nil
+}
+
+// validateSchedule checks a schedule and fills in its derived ID, method and
+// distribution.
+func (c *controller) validateSchedule(schedule *models.WateringSchedule) error {
+	if schedule.SectionID == "" && schedule.ZoneID == "" && schedule.Tag == "" && schedule.PlantType == "" {
+		return errors.New("schedule must select a section, zone, tag or plant type")
+	}
+	if schedule.ZoneID != "" && (c.config.Zones == nil || !c.config.Zones.HasZone(schedule.ZoneID)) {
+		return errors.New("schedule selects an unknown zone: " + schedule.ZoneID)
+	}
+	if schedule.CheckInterval < 1 {
+		return errors.New("schedule check interval must be at least one tick")
+	}
+	if schedule.TargetSaturation < 0 || schedule.TargetSaturation > 1 {
+		return errors.New("schedule target saturation must be between 0.0 and 1.0")
+	}
+	if pc := schedule.Proportional; pc != nil {
+		if err := validateProportional(*pc); err != nil {
+			return err
+		}
+	} else if schedule.WaterAmount <= 0 {
+		return errors.New("schedule water amount must be positive")
+	}
+	if len(schedule.AllowedWindows) > 0 {
+		if !c.config.DayCycle.Enabled() {
+			return errors.New("schedule watering windows require a day cycle")
+		}
+		if err := validateWindows(schedule.AllowedWindows); err != nil {
+			return err
+		}
+	}
+	method, err := c.resolveMethod(schedule.Method)
+	if err != nil {
+		return err
+	}
+	distribution, err := resolveDistribution(schedule.Distribution)
+	if err != nil {
+		return err
+	}
+	schedule.ID = scheduleID(*schedule)
+	schedule.Method = method
+	schedule.Distribution = distribution
+	return nil
+}
+
+// WaterSection queues a manual watering event that spreads amount evenly over
+// the plants of a section during duration. The event starts on the next tick,
+// and runs alongside any scheduled event already active in the section.
+//
+// This method is safe for concurrent use.
+func (c *controller) WaterSection(sectionID string, amount float64, duration time.Duration) error {
+	_, err := c.WaterSectionWith(sectionID, amount, duration, ManualOptions{})
+	return err
+}
+
+// WaterSectionWith queues a manual section watering like WaterSection, using
+// the irrigation method and distribution strategy in options. Smart
+// strategies fill plants toward their plant type's optimal saturation. It
+// returns the queued event, or the event of the earlier request with the
+// same request ID, whatever the other arguments. Returns an error for an
+// unknown method or strategy.
+//
+// This method is safe for concurrent use.
+func (c *controller) WaterSectionWith(sectionID string, amount float64, duration time.Duration, options ManualOptions) (models.WateringEvent, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if event, ok := c.requested(options.RequestID); ok {
+		return event, nil
+	}
+	if err := c.validateManualAmount(amount); err != nil {
+		return models.WateringEvent{}, err
+	}
+	if duration < 0 {
+		return models.WateringEvent{}, errors.New("duration cannot be negative")
+	}
+	method, err := c.resolveMethod(options.Method)
+	if err != nil {
+		return models.WateringEvent{}, err
+	}
+	distribution, err := resolveDistribution(options.Distribution)
+	if err != nil {
+		return models.WateringEvent{}, err
+	}
+	if len(c.plantData.GetPlantsBySectionID(sectionID)) == 0 {
+		return models.WateringEvent{}, fmt.Errorf("%w: %s", ErrNoPlantsInSection, sectionID)
+	}
+
+	a := c.queue(models.WateringEvent{
+		SectionID:    sectionID,
+		Amount:       amount,
+		StartTime:    time.Now(),
+		Duration:     duration,
+		IsManual:     true,
+		Method:       method,
+		Distribution: distribution,
+		Label:        options.Label,
+	})
+	c.remember(options.RequestID, a.event)
+	return a.event, nil
+}
+
+// WaterPlant queues a manual watering event for a single plant. The whole
+// amount is applied on the next tick.
+//
+// This method is safe for concurrent use.
+func (c *controller) WaterPlant(plantID string, amount float64) error {
+	if err := c.validateManualAmount(amount); err != nil {
+		return err
+	}
+	plant := c.findPlant(plantID)
+	if plant == nil {
+		return errors.New("no plant found for the provided ID: " + plantID)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.queue(models.WateringEvent{
+		SectionID: plant.SectionID,
+		PlantID:   plant.ID,
+		Amount:    amount,
+		StartTime: time.Now(),
+		IsManual:  true,
+		Method:    models.MethodDrip,
+	})
+	return nil
+}
+
+// GetActiveEvents returns a copy of every queued or in-progress watering event.
+// This method is safe for concurrent use.
+func (c *controller) GetActiveEvents() []models.WateringEvent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	active := make([]models.WateringEvent, 0, len(c.active))
+	for _, a := range c.active {
+		active = append(active, a.event)
+	}
+	return active
+}
+
+// GetWaterStats returns the controller's water accounting.
+// This method is safe for concurrent use.
+func (c *controller) GetWaterStats() WaterStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := WaterStats{
+		Used:      c.used,
+		Wasted:    c.wasted,
+		Unlimited: c.config.Supply == nil,
+	}
+	if c.config.Supply != nil {
+		stats.Remaining = c.config.Supply.Level()
+	}
+	if len(c.methods) > 0 {
+		stats.ByMethod = map[models.IrrigationMethod]MethodStats{}
+		for method, methodStats := range c.methods {
+			stats.ByMethod[method] = *methodStats
+		}
+	}
+	if len(c.sections) > 0 {
+		stats.BySection = maps.Clone(c.sections)
+	}
+	return stats
+}
+
+// SetTickInterval changes the tick interval used to convert the duration of
+// events into ticks. Events that already started keep their DurationTicks,
+// so their remaining water is delivered over their remaining ticks; queued
+// events are converted with the new interval when they start.
+// This method is safe for concurrent use.
+func (c *controller) SetTickInterval(interval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.config.TickInterval = interval
+}
+
+// TickPhase names the irrigation step in tick traces, mostly spent
+// evaluating the schedules.
+func (c *controller) TickPhase() string { return "watering.schedule" }
+
+// OnTick runs one irrigation step:
+// 1. Refill the tank by its per-tick rate
+// 2. Check every enabled schedule whose interval elapsed this tick and start a
+// scheduled event if the section's average saturation is below target and no
+// other event is watering the section, applying the tank's shortage policy
+// 3. Start pending events, round-robin across sections, while fewer than
+// MaxConcurrentEvents are in progress
+// 4. Apply one tick's share, 1/DurationTicks, of every running event, manual and scheduled
+// alike, drawing the water from the tank and throttling to MaxFlowPerTick
+// 5. Retire events that have applied all of their water
+func (c *controller) OnTick(tick int) {
+	c.mu.Lock()
+	c.lastTick = tick
+	if c.config.Supply != nil {
+		c.config.Supply.refillTick()
+	}
+	c.checkSchedules(tick)
+
+	inProgress := 0
+	for _, a := range c.active {
+		if a.started {
+			inProgress++
+		}
+	}
+	var running []*activeEvent
+	for _, a := range fairOrder(c.active, tick) {
+		if a.paused {
+			continue
+		}
+		if a.waiting {
+			if !c.config.Supply.covers(a.event.Amount) {
+				continue
+			}
+			a.waiting = false
+		}
+		if !a.started {
+			if limit := c.config.MaxConcurrentEvents; limit > 0 && inProgress >= limit {
+				continue
+			}
+			inProgress++
+			a.started = true
+			a.startTick = tick
+			a.event.StartedTick = tick
+			a.event.DurationTicks = c.durationTicks(a.event.Duration)
+			c.publish(events.WateringStarted, tick, a.event)
+		}
+		running = append(running, a)
+	}
+
+	shares := c.allocateFlow(running)
+	for _, a := range running {
+		if share := shares[a]; share > 0 {
+			c.apply(a, tick, share)
+			a.done += share
+		}
+	}
+
+	remaining := c.active[:0]
+	for _, a := range c.active {
+		if a.started && a.done >= float64(a.event.DurationTicks)-flowEpsilon {
+			c.record(a, tick, false)
+			c.publish(events.WateringCompleted, tick, a.event)
+			c.publishWatered(a, tick)
+			continue
+		}
+		remaining = append(remaining, a)
+	}
+	c.active = remaining
+	published := c.flush()
+	c.mu.Unlock()
+
+	c.deliver(published)
+}
+
+func (c *controller) validateManualAmount(amount float64) error {
+	if amount <= 0 {
+		return errors.New("amount must be positive")
+	}
+	if amount > c.config.MaxManualAmount {
+		return fmt.Errorf("amount %.2f exceeds the manual watering maximum of %.2f", amount, c.config.MaxManualAmount)
+	}
+	return nil
+}
+
+func (c *controller) findPlant(plantID string) *models.Plant {
+	for _, plant := range c.plantData.GetAllPlants() {
+		if plant.ID == plantID {
+			return plant
+		}
+	}
+	return nil
+}
+
+// queue adds an event to the active list. Callers must hold c.mu.
+func (c *controller) queue(event models.WateringEvent) *activeEvent {
+	c.nextID++
+	event.ID = "watering-" + strconv.Itoa(c.nextID)
+	event.QueuedTick = c.lastTick
+	a := &activeEvent{event: event}
+	c.active = append(c.active, a)
+	return a
+}
+
+// checkSchedules starts scheduled events that are due. Callers must hold c.mu.
+func (c *controller) checkSchedules(tick int) {
+	for _, id := range sortedKeys(c.schedules) {
+		schedule := c.schedules[id]
+		if !schedule.Enabled || (tick%schedule.CheckInterval != 0 && !c.deferred[id]) {
+			continue
+		}
+		if !c.fitsWindow(schedule, tick) {
+			c.deferred[id] = true
+			continue
+		}
+		delete(c.deferred, id)
+		if c.scheduleBeingWatered(schedule) {
+			continue
+		}
+		targets := c.targets(schedule)
+		if c.coolingDown(schedule, targets, tick) {
+			continue
+		}
+		plants := watered(targets)
+		if len(plants) == 0 {
+			continue
+		}
+		measured := averageSaturation(plants)
+		amount := schedule.WaterAmount
+		if schedule.Proportional != nil {
+			amount = c.proportionalAmount(schedule, measured, len(targets), tick)
+			if amount <= 0 {
+				continue
+			}
+		} else if measured >= schedule.TargetSaturation {
+			continue
+		}
+		event := models.WateringEvent{
+			SectionID:    schedule.SectionID,
+			Amount:       amount,
+			StartTime:    time.Now(),
+			Duration:     schedule.Duration,
+			Method:       schedule.Method,
+			Distribution: schedule.Distribution,
+			ScheduleID:   schedule.ID,
+		}
+		c.startScheduled(event, tick)
+	}
+}
+
+// startScheduled queues a scheduled event, applying the tank's shortage
+// policy when the tank cannot cover it. Callers must hold c.mu.
+func (c *controller) startScheduled(event models.WateringEvent, tick int) {
+	supply := c.config.Supply
+	if supply == nil || supply.covers(event.Amount) {
+		c.queue(event)
+		return
+	}
+
+	switch supply.Policy() {
+	case ShortagePartial:
+		if available := supply.Level(); available > 0 {
+			event.Amount = available
+			c.queue(event)
+			return
+		}
+	case ShortageQueue:
+		c.queue(event).waiting = true
+		return
+	}
+	c.publish(events.WateringSkipped, tick, event)
+}
+
+// scheduleBeingWatered reports whether an event of the schedule is still
+// active, or a section-wide event is active in the schedule's section.
+// Callers must hold c.mu.
+func (c *controller) scheduleBeingWatered(schedule *models.WateringSchedule) bool {
+	for _, a := range c.active {
+		if a.event.ScheduleID == schedule.ID {
+			return true
+		}
+		if schedule.SectionID != "" && a.event.SectionID == schedule.SectionID &&
+			a.event.PlantID == "" && a.event.ScheduleID == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// eventPlants returns the plants an event waters: its plant, the targets of
+// its schedule or the plants of its section. Callers must hold c.mu.
+func (c *controller) eventPlants(a *activeEvent) []*models.Plant {
+	if a.event.PlantID != "" {
+		if plant := c.findPlant(a.event.PlantID); plant != nil {
+			return []*models.Plant{plant}
+		}
+		return nil
+	}
+	if schedule := c.schedules[a.event.ScheduleID]; schedule != nil {
+		return c.targets(schedule)
+	}
+	return c.plantData.GetPlantsBySectionID(a.event.SectionID)
+}
+
+// watersSection reports whether an event waters a section: whether it is
+// the section of the event, or that of one of the plants it waters, as for
+// the events of schedules selecting plants by tag or type. Callers must hold
+// c.mu.
+func (c *controller) watersSection(a *activeEvent, sectionID string) bool {
+	if a.event.SectionID == sectionID {
+		return true
+	}
+	return slices.ContainsFunc(c.eventPlants(a), func(p *models.Plant) bool { return p.SectionID == sectionID })
+}
+
+// coolingDown reports whether a schedule is held idle on tick by the cancel
+// cooldown of its section or, for a schedule without one, of the section of
+// one of its targets. Expired cooldowns are forgotten. Callers must hold
+// c.mu.
+func (c *controller) coolingDown(schedule *models.WateringSchedule, targets []*models.Plant, tick int) bool {
+	var sections []string
+	if schedule.SectionID != "" {
+		sections = []string{schedule.SectionID}
+	} else {
+		for _, plant := range targets {
+			sections = append(sections, plant.SectionID)
+		}
+	}
+	cooling := false
+	for _, sectionID := range sections {
+		until, ok := c.cooldowns[sectionID]
+		switch {
+		case !ok:
+		case tick <= until:
+			cooling = true
+		default:
+			delete(c.cooldowns, sectionID)
+		}
+	}
+	return cooling
+}
+
+// updatePlants runs fn, which changes plants of the data source, under the
+// lock of a plantUpdater. Callers must hold c.mu.
+func (c *controller) updatePlants(fn func()) {
+	if updater, ok := c.plantData.(plantUpdater); ok {
+		updater.UpdatePlants(fn)
+		return
+	}
+	fn()
+}
+
+// apply draws share of one tick's worth of an event from the tank and delivers it to
+// the event's target plants following its distribution strategy. Only the
+// method's efficiency share reaches the plants, and the method raises the
+// humidity of the watered sections. Plants excluded from watering are passed
+// by: an even split withholds their share, leaving it in the tank, while the
+// smart strategies hand it to the other plants.
+// Callers must hold c.mu.
+func (c *controller) apply(a *activeEvent, tick int, share float64) {
+	plants := c.eventPlants(a)
+	kept := watered(plants)
+	if len(kept) == 0 {
+		return
+	}
+	amount := a.event.Amount / float64(a.event.DurationTicks) * share
+	if even := a.event.Distribution == "" || a.event.Distribution == models.DistributeEven; even && len(kept) < len(plants) {
+		amount *= float64(len(kept)) / float64(len(plants))
+	}
+	plants = kept
+	if supply := c.config.Supply; supply != nil {
+		drawn, lowWater := supply.draw(amount)
+		if lowWater {
+			c.publish(events.LowWater, tick, a.event)
+		}
+		amount = drawn
+	}
+	shock := c.config.ThermalShock != nil && a.delivered == 0 && amount > 0
+	c.used += amount
+	a.delivered += amount
+	perPlant := amount / float64(len(plants))
+	for _, plant := range plants {
+		c.sections[plant.SectionID] += perPlant
+	}
+
+	method := a.event.Method
+	if method == "" {
+		method = models.MethodDrip
+	}
+	profile := c.config.Methods[method]
+	stats := c.methodStats(method)
+	stats.Delivered += amount
+
+	if c.config.Humidity != nil && profile.HumidityPerUnit > 0 {
+		for _, plant := range plants {
+			c.config.Humidity.Add(plant.SectionID, perPlant*profile.HumidityPerUnit)
+		}
+	}
+
+	reaching := amount * profile.Efficiency
+	var gained []float64
+	var applied, runoff float64
+	c.updatePlants(func() {
+		if shock {
+			for _, plant := range plants {
+				c.config.ThermalShock(plant)
+			}
+		}
+		var before []float64
+		if c.config.PlantEvents {
+			before = make([]float64, len(plants))
+			for i, plant := range plants {
+				before[i] = plant.SoilSaturation
+			}
+		}
+		applied, runoff = distribute(plants, reaching, a.event.Distribution, c.distributionTarget(a.event))
+		if before != nil {
+			gained = make([]float64, len(plants))
+			for i, plant := range plants {
+				gained[i] = plant.SoilSaturation - before[i]
+			}
+		}
+	})
+	if gained != nil {
+		if a.plants == nil {
+			a.plants = map[string]*plantWater{}
+		}
+		for i, plant := range plants {
+			water := a.plants[plant.ID]
+			if water == nil {
+				water = &plantWater{sectionID: plant.SectionID}
+				a.plants[plant.ID] = water
+			}
+			water.amount += gained[i]
+		}
+	}
+	wasted := amount - reaching + runoff
+	c.wasted += wasted
+	stats.Wasted += wasted
+	stats.Applied += applied
+	a.applied += applied
+}
+
+func (c *controller) durationTicks(duration time.Duration) int {
+	if c.config.TickInterval <= 0 || duration <= 0 {
+		return 1
+	}
+	return max(1, int(math.Ceil(float64(duration)/float64(c.config.TickInterval))))
+}
+
+// flush takes the buffered events for delivery. Callers must hold c.mu.
+func (c *controller) flush() []events.Event {
+	published := c.published
+	c.published = nil
+	return published
+}
+
+// deliver publishes flushed events to the bus. Callers must not hold c.mu, so
+// that subscribers may call back into the controller.
+func (c *controller) deliver(published []events.Event) {
+	if c.bus == nil {
+		return
+	}
+	for _, e := range published {
+		c.bus.Publish(e)
+	}
+}
+
+// publish buffers an event to be delivered once c.mu is released.
+// Callers must hold c.mu.
+func (c *controller) publish(t events.Type, tick int, event models.WateringEvent) {
+	c.published = append(c.published, events.Event{
+		Type:      t,
+		Tick:      tick,
+		Timestamp: time.Now(),
+		SectionID: event.SectionID,
+		PlantID:   event.PlantID,
+		Payload:   event,
+	})
+}
+
+// publishWatered buffers a PlantWatered event for each plant an event
+// watered, ordered by plant ID. Callers must hold c.mu.
+func (c *controller) publishWatered(a *activeEvent, tick int) {
+	for _, plantID := range sortedKeys(a.plants) {
+		water := a.plants[plantID]
+		c.published = append(c.published, events.Event{
+			Type:      events.PlantWatered,
+			Tick:      tick,
+			Timestamp: time.Now(),
+			SectionID: water.sectionID,
+			PlantID:   plantID,
+			Payload:   models.PlantChange{To: a.event.ID, Value: water.amount},
+		})
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+// watered returns the plants watering reaches: plants without those
+// excluded from watering.
+func watered(plants []*models.Plant) []*models.Plant {
+	excluded := func(p *models.Plant) bool { return p.ExcludeFromWatering }
+	if !slices.ContainsFunc(plants, excluded) {
+		return plants
+	}
+	return slices.DeleteFunc(slices.Clone(plants), excluded)
+}
+
+func averageSaturation(plants []*models.Plant) float64 {
+	total := 0.0
+	for _, plant := range plants {
+		total += plant.SoilSaturation
+	}
+	return total / float64(len(plants))
+}
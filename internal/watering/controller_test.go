@@ -0,0 +1,527 @@
+package watering
+
+import (
+	"fmt"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const floatTolerance = 0.0001
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < floatTolerance
+}
+
+// mockPlantDataSource is a test mock implementation of PlantDataSource
+type mockPlantDataSource struct {
+	plantsBySectionID map[string][]*models.Plant
+}
+
+func (m *mockPlantDataSource) GetPlantsBySectionID(sectionID string) []*models.Plant {
+	return m.plantsBySectionID[sectionID]
+}
+
+func (m *mockPlantDataSource) GetAllPlants() []*models.Plant {
+	var all []*models.Plant
+	for _, plants := range m.plantsBySectionID {
+		all = append(all, plants...)
+	}
+	return all
+}
+
+// Helper function to create a test plant
+func createTestPlant(id, sectionID string, soilSaturation float64) *models.Plant {
+	plantType := models.PlantType{
+		Name:                  "TestPlant",
+		OptimalSaturation:     0.7,
+		MinSaturation:         0.3,
+		MaxSaturation:         0.9,
+		BaseGrowthRate:        0.01,
+		SaturationDepletion:   0.02,
+		HealthDegradationRate: 0.05,
+		HealthEnhancementRate: 0.03,
+	}
+	plant, _ := models.NewPlant(id, plantType, sectionID, soilSaturation)
+	return plant
+}
+
+// newTestController builds a controller over two plants in section-A and
+// records every published event.
+func newTestController(config Config) (Controller, *mockPlantDataSource, *[]events.Event) {
+	mockData := &mockPlantDataSource{
+		plantsBySectionID: map[string][]*models.Plant{
+			"section-A": {
+				createTestPlant("plant-1", "section-A", 0.2),
+				createTestPlant("plant-2", "section-A", 0.2),
+			},
+		},
+	}
+	bus := events.NewBus()
+	var published []events.Event
+	bus.Subscribe(func(e events.Event) {
+		published = append(published, e)
+	})
+	return NewController(mockData, bus, config), mockData, &published
+}
+
+func TestWaterSection_Validation(t *testing.T) {
+	tests := []struct {
+		name      string
+		sectionID string
+		amount    float64
+		duration  time.Duration
+		errorMsg  string
+	}{
+		{"zero amount", "section-A", 0, 0, "amount must be positive"},
+		{"negative amount", "section-A", -0.1, 0, "amount must be positive"},
+		{"above maximum", "section-A", 100, 0, "amount 100.00 exceeds the manual watering maximum of 1.00"},
+		{"negative duration", "section-A", 0.5, -time.Second, "duration cannot be negative"},
+		{"unknown section", "section-Z", 0.5, 0, "no plants in section: section-Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, _, _ := newTestController(Config{MaxManualAmount: 1})
+
+			err := controller.WaterSection(tt.sectionID, tt.amount, tt.duration)
+
+			if err == nil {
+				t.Fatal("expected error but got nil")
+			}
+			if err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+			}
+			if len(controller.GetActiveEvents()) != 0 {
+				t.Errorf("expected no queued events after a rejected call")
+			}
+		})
+	}
+}
+
+func TestWaterPlant_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		plantID  string
+		amount   float64
+		errorMsg string
+	}{
+		{"zero amount", "plant-1", 0, "amount must be positive"},
+		{"above default maximum", "plant-1", 100, "amount 100.00 exceeds the manual watering maximum of 5.00"},
+		{"unknown plant", "plant-9", 0.1, "no plant found for the provided ID: plant-9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, _, _ := newTestController(Config{})
+
+			err := controller.WaterPlant(tt.plantID, tt.amount)
+
+			if err == nil {
+				t.Fatal("expected error but got nil")
+			}
+			if err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestWaterSection_AppliesGradually(t *testing.T) {
+	controller, mockData, published := newTestController(Config{TickInterval: time.Second})
+
+	if err := controller.WaterSection("section-A", 0.4, 4*time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 0.4 over 4 ticks split across 2 plants = +0.05 per plant per tick
+	for tick := range 4 {
+		controller.OnTick(tick)
+		for _, plant := range mockData.plantsBySectionID["section-A"] {
+			expected := 0.2 + 0.05*float64(tick+1)
+			if !almostEqual(plant.SoilSaturation, expected) {
+				t.Errorf("tick %d: expected saturation %.2f, got %.2f", tick, expected, plant.SoilSaturation)
+			}
+		}
+	}
+
+	if len(controller.GetActiveEvents()) != 0 {
+		t.Errorf("expected event to be complete after 4 ticks")
+	}
+	if len(*published) != 2 {
+		t.Fatalf("expected started and completed events, got %d events", len(*published))
+	}
+	if (*published)[0].Type != events.WateringStarted || (*published)[0].Tick != 0 {
+		t.Errorf("expected watering_started at tick 0, got %s at tick %d", (*published)[0].Type, (*published)[0].Tick)
+	}
+	if (*published)[1].Type != events.WateringCompleted || (*published)[1].Tick != 3 {
+		t.Errorf("expected watering_completed at tick 3, got %s at tick %d", (*published)[1].Type, (*published)[1].Tick)
+	}
+	event := (*published)[1].Payload.(models.WateringEvent)
+	if !event.IsManual {
+		t.Errorf("expected manual event payload")
+	}
+}
+
+func TestWaterPlant_AppliesOnNextTick(t *testing.T) {
+	controller, mockData, _ := newTestController(Config{TickInterval: time.Second})
+
+	if err := controller.WaterPlant("plant-1", 0.3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	controller.OnTick(0)
+
+	plants := mockData.plantsBySectionID["section-A"]
+	if !almostEqual(plants[0].SoilSaturation, 0.5) {
+		t.Errorf("expected watered plant saturation 0.5, got %.2f", plants[0].SoilSaturation)
+	}
+	if !almostEqual(plants[1].SoilSaturation, 0.2) {
+		t.Errorf("expected other plant to be untouched, got %.2f", plants[1].SoilSaturation)
+	}
+}
+
+// TestWaterSection_WhilePlantsAreRead waters plants of a simulator on its
+// ticks while they are read, which must not race, see go test -race.
+func TestWaterSection_WhilePlantsAreRead(t *testing.T) {
+	sim := engine.NewSimulator(time.Second)
+	plantType := &models.PlantType{Name: "Basil", OptimalSaturation: 0.5, MinSaturation: 0.2, MaxSaturation: 0.8, SaturationDepletion: 0.01}
+	for _, id := range []string{"basil-1", "basil-2"} {
+		if err := sim.AddPlant(&models.Plant{ID: id, Type: plantType, SectionID: "section-A", SoilSaturation: 0.3, Health: 1, Alive: true}); err != nil {
+			t.Fatalf("failed to add plant: %v", err)
+		}
+	}
+	controller := NewController(sim, nil, Config{TickInterval: time.Second, ThermalShock: func(*models.Plant) {}})
+	sim.AddTickListener(controller)
+	if err := controller.WaterSection("section-A", 0.4, 2000*time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			sim.QueryPlants(engine.PlantQuery{})
+			sim.GetPlant("basil-1")
+		}
+	}()
+	for range 2000 {
+		sim.Step()
+	}
+	close(stop)
+	<-done
+
+	history := controller.GetWateringHistory("section-A", 0)
+	if len(history) != 1 || !almostEqual(history[0].Applied, 0.4) {
+		t.Errorf("expected the watering to complete applying 0.4, got %+v", history)
+	}
+}
+
+func TestWaterStats_BySection(t *testing.T) {
+	controller, mockData, _ := newTestController(Config{TickInterval: time.Second})
+	mockData.plantsBySectionID["section-B"] = []*models.Plant{createTestPlant("plant-3", "section-B", 0.2)}
+
+	if err := controller.WaterSection("section-A", 0.4, 2*time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := controller.WaterPlant("plant-3", 0.25); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for tick := range 2 {
+		controller.OnTick(tick)
+	}
+
+	stats := controller.GetWaterStats()
+	if !almostEqual(stats.BySection["section-A"], 0.4) || !almostEqual(stats.BySection["section-B"], 0.25) {
+		t.Errorf("expected 0.4 used in section-A and 0.25 in section-B, got %v", stats.BySection)
+	}
+	if !almostEqual(stats.Used, 0.65) {
+		t.Errorf("expected the sections to add up to 0.65 used, got %.2f", stats.Used)
+	}
+}
+
+func TestPlantEvents(t *testing.T) {
+	tests := []struct {
+		name        string
+		plantEvents bool
+		cancel      bool
+		expected    []string
+	}{
+		{"off", false, false, nil},
+		{"completed", true, false, []string{"1 plant-1 0.20", "1 plant-2 0.20"}},
+		{"cancelled", true, true, []string{"0 plant-1 0.10", "0 plant-2 0.10"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, _, published := newTestController(Config{TickInterval: time.Second, PlantEvents: tt.plantEvents})
+			if err := controller.WaterSection("section-A", 0.4, 2*time.Second); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			controller.OnTick(0)
+			if tt.cancel {
+				if err := controller.CancelWatering("section-A"); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			controller.OnTick(1)
+
+			var watered []string
+			for _, e := range *published {
+				if e.Type != events.PlantWatered {
+					continue
+				}
+				change := e.Payload.(models.PlantChange)
+				if change.To != "watering-1" || e.SectionID != "section-A" {
+					t.Errorf("expected the watering of watering-1 in section-A, got %+v", e)
+				}
+				watered = append(watered, fmt.Sprintf("%d %s %.2f", e.Tick, e.PlantID, change.Value))
+			}
+			if !reflect.DeepEqual(watered, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, watered)
+			}
+		})
+	}
+}
+
+func TestManualWatering_StacksWithScheduledEvent(t *testing.T) {
+	controller, mockData, published := newTestController(Config{TickInterval: time.Second})
+
+	err := controller.AddSchedule(models.WateringSchedule{
+		SectionID:        "section-A",
+		TargetSaturation: 0.5,
+		CheckInterval:    1,
+		WaterAmount:      0.4,
+		Duration:         4 * time.Second,
+		Enabled:          true,
+	})
+	if err != nil {
+		t.Fatalf("failed to add schedule: %v", err)
+	}
+
+	// Tick 0 starts the scheduled event (+0.05 per plant per tick)
+	controller.OnTick(0)
+
+	// A manual event arriving mid-schedule runs alongside it (+0.1 per plant)
+	if err := controller.WaterSection("section-A", 0.2, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	controller.OnTick(1)
+
+	for _, plant := range mockData.plantsBySectionID["section-A"] {
+		if !almostEqual(plant.SoilSaturation, 0.4) {
+			t.Errorf("expected stacked saturation 0.4, got %.2f", plant.SoilSaturation)
+		}
+	}
+
+	var scheduled, manual int
+	for _, e := range *published {
+		if e.Type != events.WateringStarted {
+			continue
+		}
+		if e.Payload.(models.WateringEvent).IsManual {
+			manual++
+		} else {
+			scheduled++
+		}
+	}
+	if scheduled != 1 || manual != 1 {
+		t.Errorf("expected one scheduled and one manual start event, got %d and %d", scheduled, manual)
+	}
+}
+
+func TestSchedule_DoesNotRetriggerWhileWatering(t *testing.T) {
+	controller, _, _ := newTestController(Config{TickInterval: time.Second})
+
+	err := controller.AddSchedule(models.WateringSchedule{
+		SectionID:        "section-A",
+		TargetSaturation: 0.9,
+		CheckInterval:    1,
+		WaterAmount:      0.2,
+		Duration:         3 * time.Second,
+		Enabled:          true,
+	})
+	if err != nil {
+		t.Fatalf("failed to add schedule: %v", err)
+	}
+
+	controller.OnTick(0)
+	controller.OnTick(1)
+
+	if active := controller.GetActiveEvents(); len(active) != 1 {
+		t.Errorf("expected a single active scheduled event, got %d", len(active))
+	}
+}
+
+func TestAddSchedule_Validation(t *testing.T) {
+	valid := models.WateringSchedule{
+		SectionID:        "section-A",
+		TargetSaturation: 0.5,
+		CheckInterval:    5,
+		WaterAmount:      0.2,
+		Enabled:          true,
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(s *models.WateringSchedule)
+		errorMsg string
+	}{
+		{"no selector", func(s *models.WateringSchedule) { s.SectionID = "" }, "schedule must select a section, zone, tag or plant type"},
+		{"zero interval", func(s *models.WateringSchedule) { s.CheckInterval = 0 }, "schedule check interval must be at least one tick"},
+		{"target above 1", func(s *models.WateringSchedule) { s.TargetSaturation = 1.2 }, "schedule target saturation must be between 0.0 and 1.0"},
+		{"zero amount", func(s *models.WateringSchedule) { s.WaterAmount = 0 }, "schedule water amount must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, _, _ := newTestController(Config{})
+			schedule := valid
+			tt.modify(&schedule)
+
+			err := controller.AddSchedule(schedule)
+
+			if err == nil {
+				t.Fatal("expected error but got nil")
+			}
+			if err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+			}
+		})
+	}
+
+	t.Run("duplicate section", func(t *testing.T) {
+		controller, _, _ := newTestController(Config{})
+		if err := controller.AddSchedule(valid); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := controller.AddSchedule(valid); err == nil {
+			t.Error("expected error when adding a second schedule for the same section, got nil")
+		}
+	})
+}
+
+func TestUpdateSchedule_TakesEffectOnNextCheck(t *testing.T) {
+	controller, _, published := newTestController(Config{})
+	schedule := models.WateringSchedule{
+		SectionID:        "section-A",
+		TargetSaturation: 0.1,
+		CheckInterval:    2,
+		WaterAmount:      0.2,
+		Enabled:          true,
+	}
+	if err := controller.AddSchedule(schedule); err != nil {
+		t.Fatalf("failed to add schedule: %v", err)
+	}
+
+	// 0.2 saturation is above the 0.1 target
+	controller.OnTick(0)
+	schedule.TargetSaturation = 0.5
+	if err := controller.UpdateSchedule(schedule); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	controller.OnTick(1)
+	if countEvents(*published, events.WateringStarted) != 0 {
+		t.Fatalf("expected no watering before the next check")
+	}
+	controller.OnTick(2)
+	if countEvents(*published, events.WateringStarted) != 1 {
+		t.Errorf("expected the updated target to trigger watering at the next check")
+	}
+
+	schedule.SectionID = "section-B"
+	if err := controller.UpdateSchedule(schedule); err == nil {
+		t.Error("expected error when updating an unknown schedule, got nil")
+	}
+}
+
+func TestRemoveSchedule(t *testing.T) {
+	controller, _, published := newTestController(Config{})
+	if err := controller.AddSchedule(thirstySchedule); err != nil {
+		t.Fatalf("failed to add schedule: %v", err)
+	}
+
+	if err := controller.RemoveSchedule("section-A"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	controller.OnTick(0)
+
+	if countEvents(*published, events.WateringStarted) != 0 {
+		t.Errorf("expected a removed schedule not to trigger")
+	}
+	if err := controller.RemoveSchedule("section-A"); err == nil {
+		t.Error("expected error when removing an unknown schedule, got nil")
+	}
+}
+
+func TestWaterSection_TickDriven(t *testing.T) {
+	tests := []struct {
+		name  string
+		pause func(controller Controller)
+	}{
+		{"uninterrupted", func(Controller) {}},
+		// A paused simulation stops calling OnTick while wall time passes.
+		{"paused mid-event", func(Controller) { time.Sleep(50 * time.Millisecond) }},
+		// The event started with 4 ticks keeps them at the new interval.
+		{"tick interval changed mid-event", func(controller Controller) {
+			controller.SetTickInterval(time.Millisecond)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, _, published := newTestController(Config{TickInterval: 10 * time.Millisecond})
+			if err := controller.WaterSection("section-A", 0.4, 40*time.Millisecond); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for tick := range 6 {
+				if tick == 2 {
+					tt.pause(controller)
+				}
+				controller.OnTick(tick)
+				if tick == 0 {
+					if ticks := controller.GetActiveEvents()[0].DurationTicks; ticks != 4 {
+						t.Fatalf("expected the event to last 4 ticks, got %d", ticks)
+					}
+				}
+			}
+
+			if used := controller.GetWaterStats().Used; !almostEqual(used, 0.4) {
+				t.Errorf("expected 0.4 delivered, got %.4f", used)
+			}
+			last := (*published)[len(*published)-1]
+			if last.Type != events.WateringCompleted || last.Tick != 3 {
+				t.Errorf("expected watering_completed at tick 3, got %s at tick %d", last.Type, last.Tick)
+			}
+		})
+	}
+}
+
+func TestSetTickInterval_AppliesToEventsNotStarted(t *testing.T) {
+	controller, _, published := newTestController(Config{TickInterval: time.Second})
+	if err := controller.WaterSection("section-A", 0.4, 4*time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	controller.SetTickInterval(2 * time.Second)
+
+	for tick := range 3 {
+		controller.OnTick(tick)
+	}
+
+	last := (*published)[len(*published)-1]
+	if last.Type != events.WateringCompleted || last.Tick != 1 {
+		t.Errorf("expected the event converted at start to complete at tick 1, got %s at tick %d", last.Type, last.Tick)
+	}
+	if event := last.Payload.(models.WateringEvent); event.DurationTicks != 2 {
+		t.Errorf("expected 2 duration ticks, got %d", event.DurationTicks)
+	}
+}
@@ -0,0 +1,108 @@
+package watering
+
+import (
+	"cmp"
+	"errors"
+	"greenhouse-simulator/internal/models"
+	"math"
+	"slices"
+)
+
+// spillEpsilon is the amount of leftover water below which redistribution stops.
+const spillEpsilon = 1e-12
+
+func resolveDistribution(strategy models.DistributionStrategy) (models.DistributionStrategy, error) {
+	switch strategy {
+	case "":
+		return models.DistributeEven, nil
+	case models.DistributeEven, models.DistributeDeficit, models.DistributeDriestFirst:
+		return strategy, nil
+	}
+	return "", errors.New("unknown distribution strategy: " + string(strategy))
+}
+
+// distribute splits water across plants following strategy and returns how
+// much the soil absorbed and how much ran off. target gives the saturation
+// each plant is filled toward by the smart strategies.
+func distribute(plants []*models.Plant, water float64, strategy models.DistributionStrategy, target func(*models.Plant) float64) (applied, runoff float64) {
+	switch strategy {
+	case models.DistributeDeficit:
+		deficits := make([]float64, len(plants))
+		total := 0.0
+		for i, plant := range plants {
+			deficits[i] = math.Max(0, target(plant)-plant.SoilSaturation)
+			total += deficits[i]
+		}
+		if total == 0 {
+			return spill(plants, water)
+		}
+		for i, plant := range plants {
+			share := water * deficits[i] / total
+			overflow := plant.AddWater(share)
+			applied += share - overflow
+			runoff += overflow
+		}
+		more, lost := spill(plants, runoff)
+		return applied + more, lost
+
+	case models.DistributeDriestFirst:
+		driest := slices.Clone(plants)
+		slices.SortStableFunc(driest, func(a, b *models.Plant) int {
+			return cmp.Compare(a.SoilSaturation, b.SoilSaturation)
+		})
+		for _, plant := range driest {
+			if water <= spillEpsilon {
+				break
+			}
+			share := math.Min(water, math.Max(0, target(plant)-plant.SoilSaturation))
+			plant.AddWater(share)
+			applied += share
+			water -= share
+		}
+		more, lost := spill(plants, water)
+		return applied + more, lost
+	}
+
+	share := water / float64(len(plants))
+	for _, plant := range plants {
+		overflow := plant.AddWater(share)
+		applied += share - overflow
+		runoff += overflow
+	}
+	return applied, runoff
+}
+
+// spill spreads water evenly over the plants that are not saturated yet,
+// passing the runoff of plants that fill up on to the rest. Water only runs
+// off once every plant is saturated.
+func spill(plants []*models.Plant, water float64) (applied, runoff float64) {
+	for water > spillEpsilon {
+		var open []*models.Plant
+		for _, plant := range plants {
+			if plant.SoilSaturation < 1 {
+				open = append(open, plant)
+			}
+		}
+		if len(open) == 0 {
+			return applied, water
+		}
+		share := water / float64(len(open))
+		water = 0
+		for _, plant := range open {
+			overflow := plant.AddWater(share)
+			applied += share - overflow
+			water += overflow
+		}
+	}
+	return applied, 0
+}
+
+// distributionTarget returns the saturation an event's smart distribution
+// fills plants toward: the triggering schedule's target, or each plant
+// type's optimal saturation for manual events. Callers must hold c.mu.
+func (c *controller) distributionTarget(event models.WateringEvent) func(*models.Plant) float64 {
+	if schedule := c.schedules[event.ScheduleID]; schedule != nil {
+		return func(*models.Plant) float64 { return schedule.TargetSaturation }
+	}
+	return func(plant *models.Plant) float64 { return plant.Type.OptimalSaturation }
+}
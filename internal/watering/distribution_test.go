@@ -0,0 +1,162 @@
+package watering
+
+import (
+	"greenhouse-simulator/internal/models"
+	"strconv"
+	"testing"
+)
+
+// newUnevenController builds a controller over section-A with one plant per
+// given saturation.
+func newUnevenController(config Config, saturations ...float64) (Controller, []*models.Plant) {
+	controller, mockData, _ := newTestController(config)
+	var plants []*models.Plant
+	for i, saturation := range saturations {
+		plants = append(plants, createTestPlant("plant-"+strconv.Itoa(i+1), "section-A", saturation))
+	}
+	mockData.plantsBySectionID["section-A"] = plants
+	return controller, plants
+}
+
+func TestDistribution_ScheduledStrategies(t *testing.T) {
+	tests := []struct {
+		strategy        models.DistributionStrategy
+		expected        []float64
+		expectedApplied float64
+	}{
+		// 0.6 over three plants at 0.1, 0.4 and 0.9 with a 0.6 target
+		{models.DistributeEven, []float64{0.3, 0.6, 1.0}, 0.5},
+		{models.DistributeDeficit, []float64{0.1 + 0.6*5/7, 0.4 + 0.6*2/7, 0.9}, 0.6},
+		{models.DistributeDriestFirst, []float64{0.6, 0.5, 0.9}, 0.6},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			controller, plants := newUnevenController(Config{}, 0.1, 0.4, 0.9)
+			err := controller.AddSchedule(models.WateringSchedule{
+				SectionID:        "section-A",
+				TargetSaturation: 0.6,
+				CheckInterval:    1,
+				WaterAmount:      0.6,
+				Enabled:          true,
+				Distribution:     tt.strategy,
+			})
+			if err != nil {
+				t.Fatalf("failed to add schedule: %v", err)
+			}
+
+			controller.OnTick(0)
+
+			for i, plant := range plants {
+				if !almostEqual(plant.SoilSaturation, tt.expected[i]) {
+					t.Errorf("%s: expected saturation %.4f, got %.4f", plant.ID, tt.expected[i], plant.SoilSaturation)
+				}
+			}
+			if got := controller.GetWaterStats().Used - controller.GetWaterStats().Wasted; !almostEqual(got, tt.expectedApplied) {
+				t.Errorf("expected %.2f applied, got %.4f", tt.expectedApplied, got)
+			}
+		})
+	}
+}
+
+func TestDistribution_RedistributesRunoff(t *testing.T) {
+	controller, plants := newUnevenController(Config{}, 0.2, 0.5)
+	err := controller.AddSchedule(models.WateringSchedule{
+		SectionID:        "section-A",
+		TargetSaturation: 0.6,
+		CheckInterval:    1,
+		WaterAmount:      1.2,
+		Enabled:          true,
+		Distribution:     models.DistributeDeficit,
+	})
+	if err != nil {
+		t.Fatalf("failed to add schedule: %v", err)
+	}
+
+	controller.OnTick(0)
+
+	// Deficits 0.4 and 0.1 split 1.2 as 0.96 and 0.24; the first plant's 0.16
+	// overflow goes to the second one
+	if !almostEqual(plants[0].SoilSaturation, 1.0) || !almostEqual(plants[1].SoilSaturation, 0.9) {
+		t.Errorf("expected saturations 1.00 and 0.90, got %.2f and %.2f", plants[0].SoilSaturation, plants[1].SoilSaturation)
+	}
+	if wasted := controller.GetWaterStats().Wasted; wasted != 0 {
+		t.Errorf("expected no water lost while a plant had room, got %.4f", wasted)
+	}
+}
+
+func TestDistribution_ManualDriestFirst(t *testing.T) {
+	controller, plants := newUnevenController(Config{}, 0.6, 0.3)
+
+	_, err := controller.WaterSectionWith("section-A", 0.5, 0, ManualOptions{Distribution: models.DistributeDriestFirst})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	controller.OnTick(0)
+
+	// The test plant type's optimal saturation is 0.7: the dry plant takes 0.4
+	// and the remaining 0.1 tops up the other one
+	if !almostEqual(plants[1].SoilSaturation, 0.7) || !almostEqual(plants[0].SoilSaturation, 0.7) {
+		t.Errorf("expected both plants at 0.70, got %.2f and %.2f", plants[0].SoilSaturation, plants[1].SoilSaturation)
+	}
+
+	if _, err := controller.WaterSectionWith("section-A", 0.5, 0, ManualOptions{Distribution: "random"}); err == nil {
+		t.Error("expected error for an unknown distribution strategy, got nil")
+	}
+}
+
+func TestDistribution_PassesExcludedPlantsBy(t *testing.T) {
+	tests := []struct {
+		strategy     models.DistributionStrategy
+		expected     []float64
+		expectedUsed float64
+	}{
+		// 0.6 over three plants at 0.2 with a 0.6 target, the second excluded:
+		// the even split keeps its 0.2 in the tank, the others hand it on.
+		{models.DistributeEven, []float64{0.4, 0.2, 0.4}, 0.4},
+		{models.DistributeDeficit, []float64{0.5, 0.2, 0.5}, 0.6},
+		{models.DistributeDriestFirst, []float64{0.6, 0.2, 0.4}, 0.6},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			controller, plants := newUnevenController(Config{}, 0.2, 0.2, 0.2)
+			plants[1].ExcludeFromWatering = true
+			err := controller.AddSchedule(models.WateringSchedule{
+				SectionID:        "section-A",
+				TargetSaturation: 0.6,
+				CheckInterval:    1,
+				WaterAmount:      0.6,
+				Enabled:          true,
+				Distribution:     tt.strategy,
+			})
+			if err != nil {
+				t.Fatalf("failed to add schedule: %v", err)
+			}
+
+			controller.OnTick(0)
+
+			for i, plant := range plants {
+				if !almostEqual(plant.SoilSaturation, tt.expected[i]) {
+					t.Errorf("%s: expected saturation %.4f, got %.4f", plant.ID, tt.expected[i], plant.SoilSaturation)
+				}
+			}
+			if used := controller.GetWaterStats().Used; !almostEqual(used, tt.expectedUsed) {
+				t.Errorf("expected %.2f used, got %.4f", tt.expectedUsed, used)
+			}
+		})
+	}
+
+	// A section of excluded plants is not watered at all.
+	controller, plants := newUnevenController(Config{}, 0.2, 0.2)
+	for _, plant := range plants {
+		plant.ExcludeFromWatering = true
+	}
+	if err := controller.WaterSection("section-A", 0.6, 0); err != nil {
+		t.Fatalf("failed to water: %v", err)
+	}
+	controller.OnTick(0)
+	if used := controller.GetWaterStats().Used; used != 0 || plants[0].SoilSaturation != 0.2 {
+		t.Errorf("expected no water used, got %.4f", used)
+	}
+}
@@ -0,0 +1,95 @@
+package watering
+
+import (
+	"cmp"
+	"greenhouse-simulator/internal/models"
+	"math"
+	"slices"
+)
+
+// flowEpsilon absorbs rounding when summing the fractional progress of
+// throttled events.
+const flowEpsilon = 1e-9
+
+// ListPendingEvents returns a copy of every queued event that has not
+// started yet, whether it waits for a free irrigation slot or for the tank.
+// This method is safe for concurrent use.
+func (c *controller) ListPendingEvents() []models.WateringEvent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var pending []models.WateringEvent
+	for _, a := range c.active {
+		if !a.started {
+			pending = append(pending, a.event)
+		}
+	}
+	return pending
+}
+
+// fairOrder interleaves events round-robin across sections, keeping queue
+// order within a section. The section going first rotates with the tick so
+// no section is always served first.
+func fairOrder(active []*activeEvent, tick int) []*activeEvent {
+	bySection := map[string][]*activeEvent{}
+	for _, a := range active {
+		bySection[a.event.SectionID] = append(bySection[a.event.SectionID], a)
+	}
+	if len(bySection) < 2 {
+		return active
+	}
+	sections := sortedKeys(bySection)
+	offset := tick % len(sections)
+	sections = append(sections[offset:], sections[:offset]...)
+
+	ordered := make([]*activeEvent, 0, len(active))
+	for len(ordered) < len(active) {
+		for _, sectionID := range sections {
+			if queue := bySection[sectionID]; len(queue) > 0 {
+				ordered = append(ordered, queue[0])
+				bySection[sectionID] = queue[1:]
+			}
+		}
+	}
+	return ordered
+}
+
+// allocateFlow returns the fraction of its per-tick amount each running event
+// may deliver this tick. Without a flow cap, or when demand fits under it,
+// every event gets what it has left, up to its full per-tick amount. Otherwise the cap is split max-min fairly
+// across sections: sections demanding less than an equal share are served in
+// full and the rest is shared by the others. Within a section, events are
+// served in queue order. Callers must hold c.mu.
+func (c *controller) allocateFlow(running []*activeEvent) map[*activeEvent]float64 {
+	shares := make(map[*activeEvent]float64, len(running))
+	demand := map[string]float64{}
+	total := 0.0
+	for _, a := range running {
+		shares[a] = math.Min(1, float64(a.event.DurationTicks)-a.done)
+		perTick := a.event.Amount / float64(a.event.DurationTicks) * shares[a]
+		demand[a.event.SectionID] += perTick
+		total += perTick
+	}
+	limit := c.config.MaxFlowPerTick
+	if limit <= 0 || total <= limit {
+		return shares
+	}
+
+	sections := sortedKeys(demand)
+	slices.SortStableFunc(sections, func(a, b string) int {
+		return cmp.Compare(demand[a], demand[b])
+	})
+	granted := map[string]float64{}
+	left := limit
+	for i, sectionID := range sections {
+		granted[sectionID] = math.Min(demand[sectionID], left/float64(len(sections)-i))
+		left -= granted[sectionID]
+	}
+
+	for _, a := range running {
+		perTick := a.event.Amount / float64(a.event.DurationTicks)
+		given := math.Min(perTick*shares[a], granted[a.event.SectionID])
+		granted[a.event.SectionID] -= given
+		shares[a] = given / perTick
+	}
+	return shares
+}
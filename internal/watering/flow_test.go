@@ -0,0 +1,112 @@
+package watering
+
+import (
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// newMultiSectionController builds a controller over sections section-0 to
+// section-<n-1>, each holding one plant at 0.2 saturation, with one schedule
+// per section that triggers on tick 0.
+func newMultiSectionController(t *testing.T, config Config, amounts ...float64) (Controller, *mockPlantDataSource, *[]events.Event) {
+	t.Helper()
+	controller, mockData, published := newTestController(config)
+	mockData.plantsBySectionID = map[string][]*models.Plant{}
+	for i, amount := range amounts {
+		sectionID := "section-" + strconv.Itoa(i)
+		mockData.plantsBySectionID[sectionID] = []*models.Plant{createTestPlant("plant-"+strconv.Itoa(i), sectionID, 0.2)}
+		err := controller.AddSchedule(models.WateringSchedule{
+			SectionID:        sectionID,
+			TargetSaturation: 0.5,
+			CheckInterval:    100,
+			WaterAmount:      amount,
+			Duration:         2 * time.Second,
+			Enabled:          true,
+		})
+		if err != nil {
+			t.Fatalf("failed to add schedule: %v", err)
+		}
+	}
+	return controller, mockData, published
+}
+
+func TestFlow_ConcurrencyCapRunsInWaves(t *testing.T) {
+	amounts := make([]float64, 10)
+	for i := range amounts {
+		amounts[i] = 0.4
+	}
+	controller, mockData, published := newMultiSectionController(t, Config{TickInterval: time.Second, MaxConcurrentEvents: 3}, amounts...)
+
+	controller.OnTick(0)
+	if pending := controller.ListPendingEvents(); len(pending) != 7 {
+		t.Fatalf("expected 7 pending events after the first wave started, got %d", len(pending))
+	}
+	for tick := 1; tick < 8; tick++ {
+		controller.OnTick(tick)
+	}
+
+	// Each event takes 2 ticks, so waves of 3, 3, 3 and 1 start every other tick
+	startsByTick := map[int]int{}
+	for _, e := range *published {
+		if e.Type != events.WateringStarted {
+			continue
+		}
+		startsByTick[e.Tick]++
+		event := e.Payload.(models.WateringEvent)
+		if event.QueuedTick != 0 || event.StartedTick != e.Tick {
+			t.Errorf("%s: expected queued at tick 0 and started at %d, got %d and %d", event.ID, e.Tick, event.QueuedTick, event.StartedTick)
+		}
+	}
+	expected := map[int]int{0: 3, 2: 3, 4: 3, 6: 1}
+	for tick, count := range expected {
+		if startsByTick[tick] != count {
+			t.Errorf("tick %d: expected %d events to start, got %d", tick, count, startsByTick[tick])
+		}
+	}
+
+	if len(controller.GetActiveEvents()) != 0 {
+		t.Errorf("expected every event to complete, %d still active", len(controller.GetActiveEvents()))
+	}
+	if used := controller.GetWaterStats().Used; !almostEqual(used, 4.0) {
+		t.Errorf("expected 4.0 delivered in total, got %.4f", used)
+	}
+	for sectionID, plants := range mockData.plantsBySectionID {
+		if !almostEqual(plants[0].SoilSaturation, 0.6) {
+			t.Errorf("%s: expected saturation 0.6, got %.4f", sectionID, plants[0].SoilSaturation)
+		}
+	}
+}
+
+func TestFlow_ThrottlesFairlyAcrossSections(t *testing.T) {
+	// section-0 asks for 0.05 per tick and section-1 for 0.3 per tick
+	controller, mockData, published := newMultiSectionController(t, Config{TickInterval: time.Second, MaxFlowPerTick: 0.2}, 0.1, 0.6)
+
+	controller.OnTick(0)
+
+	// section-0 is served in full and section-1 takes the remaining 0.15
+	small := mockData.plantsBySectionID["section-0"][0]
+	large := mockData.plantsBySectionID["section-1"][0]
+	if !almostEqual(small.SoilSaturation, 0.25) || !almostEqual(large.SoilSaturation, 0.35) {
+		t.Errorf("expected saturations 0.25 and 0.35, got %.4f and %.4f", small.SoilSaturation, large.SoilSaturation)
+	}
+
+	// section-1 needs 4 ticks instead of 2 once section-0 is done
+	completedAt := map[string]int{}
+	for tick := 1; tick < 6; tick++ {
+		controller.OnTick(tick)
+	}
+	for _, e := range *published {
+		if e.Type == events.WateringCompleted {
+			completedAt[e.SectionID] = e.Tick
+		}
+	}
+	if completedAt["section-0"] != 1 || completedAt["section-1"] != 3 {
+		t.Errorf("expected completion at ticks 1 and 3, got %d and %d", completedAt["section-0"], completedAt["section-1"])
+	}
+	if used := controller.GetWaterStats().Used; !almostEqual(used, 0.7) {
+		t.Errorf("expected the full 0.7 to be delivered, got %.4f", used)
+	}
+}
@@ -0,0 +1,143 @@
+package watering
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/models"
+	"math"
+	"slices"
+	"strings"
+)
+
+// ScheduleForecast is the projected outcome of running a candidate schedule
+// for a number of ticks. Saturation figures and deaths cover the plants the
+// schedule selects; water figures cover the whole irrigation system.
+type ScheduleForecast struct {
+	StartTick     int
+	EndTick       int
+	Plants        int     // plants selected by the schedule
+	WaterUsed     float64 // water drawn by every watering event during the forecast
+	WaterWasted   float64
+	MinSaturation float64 // lowest saturation of any selected plant at the end of a tick
+	AvgSaturation float64 // mean saturation of the selected plants over every tick
+	Deaths        []string
+}
+
+// plantSet is an in-memory PlantDataSource over a fixed set of plants.
+type plantSet struct {
+	plants []*models.Plant
+}
+
+func (p *plantSet) GetPlantsBySectionID(sectionID string) []*models.Plant {
+	var plants []*models.Plant
+	for _, plant := range p.plants {
+		if plant.SectionID == sectionID {
+			plants = append(plants, plant)
+		}
+	}
+	return plants
+}
+
+func (p *plantSet) GetAllPlants() []*models.Plant {
+	return slices.Clone(p.plants)
+}
+
+// snapshotPlants copies the plants of the data source, ordered by ID. A
+// plantSnapshotSource copies them itself, between ticks; the plants of any
+// other source are cloned as they are.
+func (c *controller) snapshotPlants() []*models.Plant {
+	if source, ok := c.plantData.(plantSnapshotSource); ok {
+		var page engine.PlantPage
+		source.BetweenTicks(func() {
+			page = source.QueryPlants(engine.PlantQuery{})
+		})
+		return page.Plants
+	}
+	var plants []*models.Plant
+	for _, plant := range c.plantData.GetAllPlants() {
+		plants = append(plants, plant.Clone())
+	}
+	slices.SortFunc(plants, func(a, b *models.Plant) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return plants
+}
+
+// EvaluateSchedule forecasts what the candidate schedule would do over the
+// next horizonTicks ticks. It copies the plants and the controller state, adds
+// the candidate (replacing any schedule with the same ID) and steps the copy
+// forward the way the simulator would: plants first, then irrigation. The live
+// plants, controller and tank are left untouched, no events are published and
+// humidity side effects are not simulated. Returns an error if:
+// - horizonTicks is less than one
+// - the candidate schedule is invalid
+// - the schedule selects no plants
+//
+// The plants are copied between ticks when the data source allows it, see
+// plantSnapshotSource, so tick hooks and listeners must not call it.
+// This method is safe for concurrent use.
+func (c *controller) EvaluateSchedule(schedule models.WateringSchedule, horizonTicks int) (*ScheduleForecast, error) {
+	if horizonTicks < 1 {
+		return nil, errors.New("forecast horizon must be at least one tick")
+	}
+
+	state := c.Snapshot()
+	id := scheduleID(schedule)
+	state.Schedules = slices.DeleteFunc(state.Schedules, func(s models.WateringSchedule) bool {
+		return scheduleID(s) == id
+	})
+
+	plants := c.snapshotPlants()
+
+	config := c.config
+	if config.Supply != nil {
+		config.Supply = config.Supply.clone()
+	}
+	config.Humidity = nil
+	forecast := NewController(&plantSet{plants: plants}, nil, config).(*controller)
+	forecast.Restore(state)
+	if err := forecast.AddSchedule(schedule); err != nil {
+		return nil, err
+	}
+
+	var selected []*models.Plant
+	for _, plant := range plants {
+		if forecast.selects(&schedule, plant) {
+			selected = append(selected, plant)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, errors.New("schedule selects no plants: " + id)
+	}
+	alive := map[string]bool{}
+	for _, plant := range selected {
+		alive[plant.ID] = plant.Alive
+	}
+
+	result := &ScheduleForecast{
+		StartTick:     state.LastTick + 1,
+		EndTick:       state.LastTick + horizonTicks,
+		Plants:        len(selected),
+		MinSaturation: math.Inf(1),
+	}
+	total := 0.0
+	for tick := result.StartTick; tick <= result.EndTick; tick++ {
+		for _, plant := range plants {
+			plant.OnTick()
+		}
+		forecast.OnTick(tick)
+		for _, plant := range selected {
+			result.MinSaturation = math.Min(result.MinSaturation, plant.SoilSaturation)
+			total += plant.SoilSaturation
+		}
+	}
+	result.AvgSaturation = total / float64(horizonTicks*len(selected))
+	result.WaterUsed = forecast.used - state.Used
+	result.WaterWasted = forecast.wasted - state.Wasted
+	for _, plant := range selected {
+		if alive[plant.ID] && !plant.Alive {
+			result.Deaths = append(result.Deaths, plant.ID)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,163 @@
+package watering
+
+import (
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/models"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func clonePlants(plants []*models.Plant) []models.Plant {
+	var clones []models.Plant
+	for _, plant := range plants {
+		clones = append(clones, *plant.Clone())
+	}
+	return clones
+}
+
+func TestEvaluateSchedule_LeavesLiveStateUntouched(t *testing.T) {
+	supply := newTestSupply(t, SupplyConfig{Capacity: 2, InitialLevel: 2, RefillPerTick: 0.01})
+	controller, mockData, published := newTestController(Config{TickInterval: time.Second, Supply: supply})
+	if err := controller.AddSchedule(models.WateringSchedule{
+		SectionID:        "section-A",
+		TargetSaturation: 0.3,
+		CheckInterval:    2,
+		WaterAmount:      0.2,
+		Duration:         3 * time.Second,
+		Enabled:          true,
+	}); err != nil {
+		t.Fatalf("failed to add schedule: %v", err)
+	}
+	controller.OnTick(0)
+	controller.OnTick(1)
+
+	plantsBefore := clonePlants(mockData.plantsBySectionID["section-A"])
+	stateBefore := controller.Snapshot()
+	levelBefore := supply.Level()
+	eventsBefore := len(*published)
+
+	// The candidate replaces the live section-A schedule inside the forecast
+	_, err := controller.EvaluateSchedule(models.WateringSchedule{
+		SectionID:        "section-A",
+		TargetSaturation: 0.8,
+		CheckInterval:    1,
+		WaterAmount:      0.4,
+		Enabled:          true,
+	}, 50)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := clonePlants(mockData.plantsBySectionID["section-A"]); !reflect.DeepEqual(got, plantsBefore) {
+		t.Errorf("expected live plants to be unchanged, got %+v", got)
+	}
+	if got := controller.Snapshot(); !reflect.DeepEqual(got, stateBefore) {
+		t.Errorf("expected live controller state to be unchanged")
+	}
+	if supply.Level() != levelBefore {
+		t.Errorf("expected tank level %.4f, got %.4f", levelBefore, supply.Level())
+	}
+	if len(*published) != eventsBefore {
+		t.Errorf("expected no events to be published by a forecast, got %d", len(*published)-eventsBefore)
+	}
+}
+
+func TestEvaluateSchedule_WhileTicking(t *testing.T) {
+	sim := engine.NewSimulator(time.Second)
+	plantType := &models.PlantType{Name: "Basil", OptimalSaturation: 0.5, MinSaturation: 0.2, MaxSaturation: 0.8, SaturationDepletion: 0.01}
+	for _, id := range []string{"basil-2", "basil-1"} {
+		if err := sim.AddPlant(&models.Plant{ID: id, Type: plantType, SectionID: "section-A", SoilSaturation: 0.3, Health: 1, Alive: true}); err != nil {
+			t.Fatalf("failed to add plant: %v", err)
+		}
+	}
+	controller := NewController(sim, nil, Config{TickInterval: time.Second})
+	sim.AddTickListener(controller)
+	if err := controller.WaterSection("section-A", 2, 20*time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 100 {
+			sim.Step()
+		}
+	}()
+	schedule := models.WateringSchedule{SectionID: "section-A", TargetSaturation: 0.4, CheckInterval: 1, WaterAmount: 0.2, Enabled: true}
+	for range 50 {
+		forecast, err := controller.EvaluateSchedule(schedule, 5)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if forecast.Plants != 2 {
+			t.Fatalf("expected both plants in the forecast, got %d", forecast.Plants)
+		}
+	}
+	<-done
+}
+
+func TestEvaluateSchedule_Projections(t *testing.T) {
+	controller, _, _ := newTestController(Config{TickInterval: time.Second})
+
+	// A target of zero never triggers: the plants dry out and die within 30
+	// ticks (health drops by 0.05 per tick below 0.3 saturation)
+	idle, err := controller.EvaluateSchedule(models.WateringSchedule{
+		SectionID:     "section-A",
+		CheckInterval: 1,
+		WaterAmount:   0.4,
+		Enabled:       true,
+	}, 30)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if idle.WaterUsed != 0 {
+		t.Errorf("expected no water used, got %.2f", idle.WaterUsed)
+	}
+	if !slices.Equal(idle.Deaths, []string{"plant-1", "plant-2"}) {
+		t.Errorf("expected both plants to die, got %v", idle.Deaths)
+	}
+
+	watered, err := controller.EvaluateSchedule(models.WateringSchedule{
+		SectionID:        "section-A",
+		TargetSaturation: 0.6,
+		CheckInterval:    1,
+		WaterAmount:      0.4,
+		Enabled:          true,
+	}, 30)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(watered.Deaths) != 0 {
+		t.Errorf("expected no deaths with watering, got %v", watered.Deaths)
+	}
+	if watered.WaterUsed <= 0 {
+		t.Errorf("expected water to be used, got %.2f", watered.WaterUsed)
+	}
+	if watered.MinSaturation < 0.2 || watered.AvgSaturation <= idle.AvgSaturation {
+		t.Errorf("expected watering to keep saturation up, got min %.2f and avg %.2f (idle avg %.2f)",
+			watered.MinSaturation, watered.AvgSaturation, idle.AvgSaturation)
+	}
+	if watered.StartTick != 1 || watered.EndTick != 30 || watered.Plants != 2 {
+		t.Errorf("expected ticks 1-30 over 2 plants, got %d-%d over %d", watered.StartTick, watered.EndTick, watered.Plants)
+	}
+}
+
+func TestEvaluateSchedule_Validation(t *testing.T) {
+	controller, _, _ := newTestController(Config{})
+
+	if _, err := controller.EvaluateSchedule(thirstySchedule, 0); err == nil {
+		t.Error("expected error for a zero horizon, got nil")
+	}
+	invalid := thirstySchedule
+	invalid.CheckInterval = 0
+	if _, err := controller.EvaluateSchedule(invalid, 10); err == nil {
+		t.Error("expected error for an invalid schedule, got nil")
+	}
+	empty := thirstySchedule
+	empty.SectionID = "section-Z"
+	if _, err := controller.EvaluateSchedule(empty, 10); err == nil {
+		t.Error("expected error for a schedule selecting no plants, got nil")
+	}
+}
@@ -0,0 +1,271 @@
+package watering
+
+import (
+	"encoding/json"
+	"greenhouse-simulator/internal/models"
+	"io"
+	"maps"
+	"slices"
+)
+
+// DefaultHistorySize is the number of history entries kept when
+// Config.HistorySize is left at zero.
+const DefaultHistorySize = 1000
+
+// HistoryEntry records a finished watering event. Delivered is the water drawn
+// for the event and Applied the part the soil absorbed; both can be lower than
+// the requested Event.Amount because of tank shortages and runoff.
+type HistoryEntry struct {
+	Event     models.WateringEvent
+	StartTick int
+	EndTick   int
+	Delivered float64
+	Applied   float64
+	Cancelled bool
+}
+
+// WaterUsage aggregates the history entries of a section over a tick range.
+// ByMethod splits the delivered water by irrigation method, where Wasted is
+// the part of it the soil never absorbed.
+type WaterUsage struct {
+	Applied          float64
+	Events           int
+	ManualApplied    float64
+	ManualEvents     int
+	ScheduledApplied float64
+	ScheduledEvents  int
+	ByMethod         map[models.IrrigationMethod]MethodStats
+}
+
+// State is a serializable copy of a controller's runtime state, used to
+// snapshot and restore the irrigation system together with the simulation.
+type State struct {
+	Schedules     []models.WateringSchedule
+	Control       map[string]ControlState
+	Cooldowns     map[string]int
+	Deferred      []string // schedules waiting for an allowed window
+	Active        []EventState
+	History       []HistoryEntry
+	NextID        int
+	LastTick      int
+	Used          float64
+	Wasted        float64
+	UsedBySection map[string]float64
+	Methods       map[models.IrrigationMethod]MethodStats
+	SupplyLevel   float64
+}
+
+// EventState is the progress of a watering event that has not finished yet.
+type EventState struct {
+	Event     models.WateringEvent
+	DoneTicks float64
+	StartTick int
+	Started   bool
+	Waiting   bool
+	Paused    bool
+	Delivered float64
+	Applied   float64
+}
+
+// GetWateringHistory returns up to lastN of the most recent history entries for
+// a section, oldest first. An empty sectionID matches every section and a
+// non-positive lastN returns all matching entries still held in the history.
+// This method is safe for concurrent use.
+func (c *controller) GetWateringHistory(sectionID string, lastN int) []HistoryEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var entries []HistoryEntry
+	for i := len(c.history) - 1; i >= 0; i-- {
+		if lastN > 0 && len(entries) == lastN {
+			break
+		}
+		if sectionID == "" || c.history[i].Event.SectionID == sectionID {
+			entries = append(entries, c.history[i])
+		}
+	}
+	slices.Reverse(entries)
+	return entries
+}
+
+// GetWaterUsage totals the water applied to a section by events that ended
+// between fromTick and toTick inclusive. An empty sectionID matches every section.
+// Only entries still held in the bounded history are counted.
+// This method is safe for concurrent use.
+func (c *controller) GetWaterUsage(sectionID string, fromTick, toTick int) WaterUsage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var usage WaterUsage
+	for _, entry := range c.history {
+		if sectionID != "" && entry.Event.SectionID != sectionID {
+			continue
+		}
+		if entry.EndTick < fromTick || entry.EndTick > toTick {
+			continue
+		}
+		usage.Applied += entry.Applied
+		usage.Events++
+		if entry.Event.IsManual {
+			usage.ManualApplied += entry.Applied
+			usage.ManualEvents++
+		} else {
+			usage.ScheduledApplied += entry.Applied
+			usage.ScheduledEvents++
+		}
+		method := entry.Event.Method
+		if method == "" {
+			method = models.MethodDrip
+		}
+		if usage.ByMethod == nil {
+			usage.ByMethod = map[models.IrrigationMethod]MethodStats{}
+		}
+		stats := usage.ByMethod[method]
+		stats.Delivered += entry.Delivered
+		stats.Applied += entry.Applied
+		stats.Wasted += entry.Delivered - entry.Applied
+		usage.ByMethod[method] = stats
+	}
+	return usage
+}
+
+// Snapshot returns a deep copy of the controller's schedules, in-progress
+// events, history and water accounting.
+// This method is safe for concurrent use.
+func (c *controller) Snapshot() State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	state := State{
+		History:  slices.Clone(c.history),
+		NextID:   c.nextID,
+		LastTick: c.lastTick,
+		Used:     c.used,
+		Wasted:   c.wasted,
+	}
+	if len(c.sections) > 0 {
+		state.UsedBySection = maps.Clone(c.sections)
+	}
+	for _, id := range sortedKeys(c.schedules) {
+		state.Schedules = append(state.Schedules, cloneSchedule(*c.schedules[id]))
+	}
+	if len(c.control) > 0 {
+		state.Control = map[string]ControlState{}
+		for sectionID, control := range c.control {
+			state.Control[sectionID] = *control
+		}
+	}
+	if len(c.cooldowns) > 0 {
+		state.Cooldowns = maps.Clone(c.cooldowns)
+	}
+	state.Deferred = sortedKeys(c.deferred)
+	if len(c.methods) > 0 {
+		state.Methods = map[models.IrrigationMethod]MethodStats{}
+		for method, stats := range c.methods {
+			state.Methods[method] = *stats
+		}
+	}
+	for _, a := range c.active {
+		state.Active = append(state.Active, EventState{
+			Event:     a.event,
+			DoneTicks: a.done,
+			StartTick: a.startTick,
+			Started:   a.started,
+			Waiting:   a.waiting,
+			Paused:    a.paused,
+			Delivered: a.delivered,
+			Applied:   a.applied,
+		})
+	}
+	if c.config.Supply != nil {
+		state.SupplyLevel = c.config.Supply.Level()
+	}
+	return state
+}
+
+// Restore replaces the controller's runtime state with a previously taken
+// snapshot. The tank level is only restored when a supply is configured, and
+// the manual watering request IDs remembered so far are forgotten.
+// This method is safe for concurrent use.
+func (c *controller) Restore(state State) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.schedules = map[string]*models.WateringSchedule{}
+	for _, schedule := range state.Schedules {
+		schedule = cloneSchedule(schedule)
+		schedule.ID = scheduleID(schedule)
+		c.schedules[schedule.ID] = &schedule
+	}
+	c.control = map[string]*ControlState{}
+	for sectionID, control := range state.Control {
+		c.control[sectionID] = &control
+	}
+	c.cooldowns = map[string]int{}
+	maps.Copy(c.cooldowns, state.Cooldowns)
+	c.deferred = map[string]bool{}
+	for _, id := range state.Deferred {
+		c.deferred[id] = true
+	}
+	c.methods = map[models.IrrigationMethod]*MethodStats{}
+	for method, stats := range state.Methods {
+		c.methods[method] = &stats
+	}
+	c.requests = nil
+	c.active = nil
+	for _, e := range state.Active {
+		c.active = append(c.active, &activeEvent{
+			event:     e.Event,
+			done:      e.DoneTicks,
+			startTick: e.StartTick,
+			started:   e.Started,
+			waiting:   e.Waiting,
+			paused:    e.Paused,
+			delivered: e.Delivered,
+			applied:   e.Applied,
+		})
+	}
+	c.history = slices.Clone(state.History)
+	c.nextID = state.NextID
+	c.lastTick = state.LastTick
+	c.used = state.Used
+	c.wasted = state.Wasted
+	c.sections = map[string]float64{}
+	maps.Copy(c.sections, state.UsedBySection)
+	if c.config.Supply != nil {
+		c.config.Supply.setLevel(state.SupplyLevel)
+	}
+}
+
+// cloneSchedule returns a copy of schedule that shares no pointers with it.
+func cloneSchedule(schedule models.WateringSchedule) models.WateringSchedule {
+	if schedule.Proportional != nil {
+		control := *schedule.Proportional
+		schedule.Proportional = &control
+	}
+	return schedule
+}
+
+// record appends a finished event to the bounded history, dropping the oldest
+// entry when full. Callers must hold c.mu.
+func (c *controller) record(a *activeEvent, tick int, cancelled bool) {
+	if len(c.history) == c.config.HistorySize {
+		c.history = slices.Delete(c.history, 0, 1)
+	}
+	c.history = append(c.history, HistoryEntry{
+		Event:     a.event,
+		StartTick: a.startTick,
+		EndTick:   tick,
+		Delivered: a.delivered,
+		Applied:   a.applied,
+		Cancelled: cancelled,
+	})
+}
+
+// ExportHistory writes entries to w as JSON Lines, one entry per line, the
+// same format used for exported sensor readings.
+func ExportHistory(w io.Writer, entries []HistoryEntry) error {
+	encoder := json.NewEncoder(w)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package watering
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"greenhouse-simulator/internal/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// runScriptedSequence drives a controller through a fixed mix of scheduled and
+// manual events on a section with plants starting at 0.2 saturation:
+//   - tick 0: the schedule starts 0.4 over 2 ticks (completes at tick 1)
+//   - tick 2: a manual 0.2 section watering (completes at tick 2)
+//   - tick 3: a manual 0.1 watering of plant-1 (completes at tick 3)
+//   - tick 4: the schedule checks again but saturation (0.6/0.5) is above target
+func runScriptedSequence(t *testing.T, controller Controller) {
+	t.Helper()
+	err := controller.AddSchedule(models.WateringSchedule{
+		SectionID:        "section-A",
+		TargetSaturation: 0.3,
+		CheckInterval:    4,
+		WaterAmount:      0.4,
+		Duration:         2 * time.Second,
+		Enabled:          true,
+	})
+	if err != nil {
+		t.Fatalf("failed to add schedule: %v", err)
+	}
+
+	for tick := range 5 {
+		switch tick {
+		case 2:
+			if err := controller.WaterSection("section-A", 0.2, 0); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		case 3:
+			if err := controller.WaterPlant("plant-1", 0.1); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		controller.OnTick(tick)
+	}
+}
+
+func TestWateringHistory_RecordsEvents(t *testing.T) {
+	controller, _, _ := newTestController(Config{TickInterval: time.Second})
+	runScriptedSequence(t, controller)
+
+	history := controller.GetWateringHistory("section-A", 0)
+	if len(history) != 3 {
+		t.Fatalf("expected 3 history entries, got %d", len(history))
+	}
+
+	expected := []struct {
+		manual    bool
+		plantID   string
+		startTick int
+		endTick   int
+		applied   float64
+	}{
+		{false, "", 0, 1, 0.4},
+		{true, "", 2, 2, 0.2},
+		{true, "plant-1", 3, 3, 0.1},
+	}
+	for i, want := range expected {
+		got := history[i]
+		if got.Event.IsManual != want.manual || got.Event.PlantID != want.plantID {
+			t.Errorf("entry %d: expected manual=%v plant=%q, got manual=%v plant=%q",
+				i, want.manual, want.plantID, got.Event.IsManual, got.Event.PlantID)
+		}
+		if got.StartTick != want.startTick || got.EndTick != want.endTick {
+			t.Errorf("entry %d: expected ticks %d-%d, got %d-%d",
+				i, want.startTick, want.endTick, got.StartTick, got.EndTick)
+		}
+		if !almostEqual(got.Applied, want.applied) {
+			t.Errorf("entry %d: expected applied %.2f, got %.2f", i, want.applied, got.Applied)
+		}
+	}
+
+	last := controller.GetWateringHistory("section-A", 1)
+	if len(last) != 1 || last[0].Event.PlantID != "plant-1" {
+		t.Errorf("expected lastN=1 to return only the most recent entry, got %v", last)
+	}
+	if other := controller.GetWateringHistory("section-Z", 0); len(other) != 0 {
+		t.Errorf("expected no history for another section, got %d entries", len(other))
+	}
+}
+
+func TestWaterUsage_ManualScheduledSplit(t *testing.T) {
+	controller, _, _ := newTestController(Config{TickInterval: time.Second})
+	runScriptedSequence(t, controller)
+
+	usage := controller.GetWaterUsage("section-A", 0, 10)
+	if usage.Events != 3 || usage.ManualEvents != 2 || usage.ScheduledEvents != 1 {
+		t.Errorf("expected 3 events (2 manual, 1 scheduled), got %d (%d manual, %d scheduled)",
+			usage.Events, usage.ManualEvents, usage.ScheduledEvents)
+	}
+	if !almostEqual(usage.Applied, 0.7) {
+		t.Errorf("expected 0.7 applied, got %.2f", usage.Applied)
+	}
+	if !almostEqual(usage.ManualApplied, 0.3) || !almostEqual(usage.ScheduledApplied, 0.4) {
+		t.Errorf("expected 0.3 manual and 0.4 scheduled, got %.2f and %.2f", usage.ManualApplied, usage.ScheduledApplied)
+	}
+
+	windowed := controller.GetWaterUsage("section-A", 2, 2)
+	if windowed.Events != 1 || !almostEqual(windowed.Applied, 0.2) {
+		t.Errorf("expected only the tick 2 event, got %d events applying %.2f", windowed.Events, windowed.Applied)
+	}
+}
+
+func TestWateringHistory_AppliedExcludesRunoff(t *testing.T) {
+	controller, mockData, _ := newTestController(Config{})
+	for _, plant := range mockData.plantsBySectionID["section-A"] {
+		plant.SoilSaturation = 0.9
+	}
+
+	if err := controller.WaterSection("section-A", 0.4, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	controller.OnTick(0)
+
+	entry := controller.GetWateringHistory("section-A", 1)[0]
+	if !almostEqual(entry.Delivered, 0.4) || !almostEqual(entry.Applied, 0.2) {
+		t.Errorf("expected 0.4 delivered and 0.2 applied, got %.2f and %.2f", entry.Delivered, entry.Applied)
+	}
+}
+
+func TestWateringHistory_IsBounded(t *testing.T) {
+	controller, _, _ := newTestController(Config{HistorySize: 2})
+
+	for tick := range 3 {
+		if err := controller.WaterPlant("plant-1", 0.01); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		controller.OnTick(tick)
+	}
+
+	history := controller.GetWateringHistory("", 0)
+	if len(history) != 2 {
+		t.Fatalf("expected history bounded to 2 entries, got %d", len(history))
+	}
+	if history[0].EndTick != 1 {
+		t.Errorf("expected the oldest entry to be dropped, first entry ends at tick %d", history[0].EndTick)
+	}
+}
+
+func TestWateringHistory_SurvivesSnapshotRestore(t *testing.T) {
+	controller, _, _ := newTestController(Config{TickInterval: time.Second})
+	runScriptedSequence(t, controller)
+
+	state := controller.Snapshot()
+
+	// Round-trip through JSON as a saved snapshot would
+	data, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("failed to marshal state: %v", err)
+	}
+	var decoded State
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal state: %v", err)
+	}
+
+	restored, _, _ := newTestController(Config{TickInterval: time.Second})
+	restored.Restore(decoded)
+
+	if got := restored.GetWaterUsage("", 0, 10); !reflect.DeepEqual(got, controller.GetWaterUsage("", 0, 10)) {
+		t.Errorf("expected restored usage %+v, got %+v", controller.GetWaterUsage("", 0, 10), got)
+	}
+	if got := restored.GetWaterStats(); !reflect.DeepEqual(got, controller.GetWaterStats()) {
+		t.Errorf("expected restored stats %+v, got %+v", controller.GetWaterStats(), got)
+	}
+	if err := restored.AddSchedule(models.WateringSchedule{
+		SectionID: "section-A", TargetSaturation: 0.5, CheckInterval: 1, WaterAmount: 0.1,
+	}); err == nil {
+		t.Error("expected restored controller to keep the section-A schedule")
+	}
+}
+
+func TestExportHistory(t *testing.T) {
+	controller, _, _ := newTestController(Config{TickInterval: time.Second})
+	runScriptedSequence(t, controller)
+
+	var buf bytes.Buffer
+	if err := ExportHistory(&buf, controller.GetWateringHistory("", 0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := 0
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("line %d is not a valid entry: %v", lines+1, err)
+		}
+		lines++
+	}
+	if lines != 3 {
+		t.Errorf("expected 3 exported lines, got %d", lines)
+	}
+}
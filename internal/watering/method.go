@@ -0,0 +1,73 @@
+package watering
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/models"
+	"maps"
+	"math"
+)
+
+// MethodProfile describes how an irrigation method behaves.
+type MethodProfile struct {
+	// Efficiency is the fraction of the delivered water that reaches the
+	// plants, between 0.0 and 1.0. The rest is counted as waste.
+	Efficiency float64
+	// HumidityPerUnit is the rise in section humidity per unit of water
+	// delivered. It only has an effect when Config.Humidity is set.
+	HumidityPerUnit float64
+}
+
+// Default irrigation method profiles. Drip emitters feed the root zone
+// directly, overhead sprinklers lose some water to evaporation and misting
+// mostly humidifies the air.
+var (
+	DefaultDripProfile     = MethodProfile{Efficiency: 1.0}
+	DefaultOverheadProfile = MethodProfile{Efficiency: 0.8, HumidityPerUnit: 0.05}
+	DefaultMistingProfile  = MethodProfile{Efficiency: 0.2, HumidityPerUnit: 0.5}
+)
+
+// MethodStats is the water accounting of one irrigation method.
+type MethodStats struct {
+	Delivered float64 // water drawn for events using the method
+	Applied   float64 // water absorbed by the soil
+	Wasted    float64 // water lost to inefficiency or runoff
+}
+
+// defaultMethodProfiles returns the default profiles overridden by any
+// profile set in overrides, with efficiencies clamped to 0.0-1.0.
+func defaultMethodProfiles(overrides map[models.IrrigationMethod]MethodProfile) map[models.IrrigationMethod]MethodProfile {
+	profiles := map[models.IrrigationMethod]MethodProfile{
+		models.MethodDrip:     DefaultDripProfile,
+		models.MethodOverhead: DefaultOverheadProfile,
+		models.MethodMisting:  DefaultMistingProfile,
+	}
+	maps.Copy(profiles, overrides)
+	for method, profile := range profiles {
+		profile.Efficiency = math.Max(0, math.Min(1, profile.Efficiency))
+		profiles[method] = profile
+	}
+	return profiles
+}
+
+// resolveMethod returns the method to use for an event, mapping the empty
+// method to MethodDrip. Returns an error for methods without a profile.
+func (c *controller) resolveMethod(method models.IrrigationMethod) (models.IrrigationMethod, error) {
+	if method == "" {
+		return models.MethodDrip, nil
+	}
+	if _, ok := c.config.Methods[method]; !ok {
+		return "", errors.New("unknown irrigation method: " + string(method))
+	}
+	return method, nil
+}
+
+// methodStats returns the accounting of a method, creating it on first use.
+// Callers must hold c.mu.
+func (c *controller) methodStats(method models.IrrigationMethod) *MethodStats {
+	stats := c.methods[method]
+	if stats == nil {
+		stats = &MethodStats{}
+		c.methods[method] = stats
+	}
+	return stats
+}
@@ -0,0 +1,115 @@
+package watering
+
+import (
+	"greenhouse-simulator/internal/environment"
+	"greenhouse-simulator/internal/models"
+	"testing"
+)
+
+func TestMethods_EfficiencyAndHumidity(t *testing.T) {
+	tests := []struct {
+		method             models.IrrigationMethod
+		expectedSaturation float64
+		expectedHumidity   float64
+	}{
+		// 0.4 split across 2 plants is 0.2 per plant before efficiency
+		{models.MethodDrip, 0.4, 0.5},
+		{models.MethodOverhead, 0.36, 0.52},
+		{models.MethodMisting, 0.24, 0.7},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			humidity, err := environment.NewHumidity(0.5, 0)
+			if err != nil {
+				t.Fatalf("failed to create humidity: %v", err)
+			}
+			controller, mockData, _ := newTestController(Config{Humidity: humidity})
+			schedule := thirstySchedule
+			schedule.Method = tt.method
+			if err := controller.AddSchedule(schedule); err != nil {
+				t.Fatalf("failed to add schedule: %v", err)
+			}
+
+			controller.OnTick(0)
+
+			for _, plant := range mockData.plantsBySectionID["section-A"] {
+				if !almostEqual(plant.SoilSaturation, tt.expectedSaturation) {
+					t.Errorf("expected saturation %.2f, got %.2f", tt.expectedSaturation, plant.SoilSaturation)
+				}
+			}
+			if got := humidity.Get("section-A"); !almostEqual(got, tt.expectedHumidity) {
+				t.Errorf("expected humidity %.2f, got %.2f", tt.expectedHumidity, got)
+			}
+		})
+	}
+}
+
+func TestMethods_DripOutperformsOverhead(t *testing.T) {
+	delta := func(method models.IrrigationMethod) float64 {
+		controller, mockData, _ := newTestController(Config{})
+		schedule := thirstySchedule
+		schedule.Method = method
+		if err := controller.AddSchedule(schedule); err != nil {
+			t.Fatalf("failed to add schedule: %v", err)
+		}
+		controller.OnTick(0)
+		return mockData.plantsBySectionID["section-A"][0].SoilSaturation - 0.2
+	}
+
+	// Overhead loses 20% of the 0.2 each plant receives
+	if diff := delta(models.MethodDrip) - delta(models.MethodOverhead); !almostEqual(diff, 0.04) {
+		t.Errorf("expected drip to raise saturation 0.04 more than overhead, got %.4f", diff)
+	}
+}
+
+func TestMethods_StatsSplitByMethod(t *testing.T) {
+	controller, _, _ := newTestController(Config{
+		Methods: map[models.IrrigationMethod]MethodProfile{
+			models.MethodOverhead: {Efficiency: 0.5},
+		},
+	})
+	schedule := thirstySchedule
+	schedule.Method = models.MethodOverhead
+	if err := controller.AddSchedule(schedule); err != nil {
+		t.Fatalf("failed to add schedule: %v", err)
+	}
+	controller.OnTick(0)
+	if err := controller.WaterSection("section-A", 0.2, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	controller.OnTick(1)
+
+	stats := controller.GetWaterStats()
+	overhead := stats.ByMethod[models.MethodOverhead]
+	if !almostEqual(overhead.Delivered, 0.4) || !almostEqual(overhead.Applied, 0.2) || !almostEqual(overhead.Wasted, 0.2) {
+		t.Errorf("expected overhead 0.4 delivered, 0.2 applied and 0.2 wasted, got %+v", overhead)
+	}
+	drip := stats.ByMethod[models.MethodDrip]
+	if !almostEqual(drip.Delivered, 0.2) || !almostEqual(drip.Applied, 0.2) || drip.Wasted != 0 {
+		t.Errorf("expected manual drip watering to apply all 0.2, got %+v", drip)
+	}
+	if !almostEqual(stats.Wasted, 0.2) {
+		t.Errorf("expected 0.2 total wasted, got %.2f", stats.Wasted)
+	}
+
+	usage := controller.GetWaterUsage("section-A", 0, 10)
+	if usage.ByMethod[models.MethodOverhead] != overhead || usage.ByMethod[models.MethodDrip] != drip {
+		t.Errorf("expected usage report to match stats by method, got %+v", usage.ByMethod)
+	}
+}
+
+func TestMethods_UnknownMethod(t *testing.T) {
+	controller, _, _ := newTestController(Config{})
+	schedule := thirstySchedule
+	schedule.Method = "flood"
+
+	err := controller.AddSchedule(schedule)
+
+	if err == nil {
+		t.Fatal("expected error but got nil")
+	}
+	if err.Error() != "unknown irrigation method: flood" {
+		t.Errorf("expected unknown method error, got '%s'", err.Error())
+	}
+}
@@ -0,0 +1,26 @@
+package watering
+
+import (
+	"greenhouse-simulator/internal/engine"
+	"greenhouse-simulator/internal/models"
+)
+
+type PlantDataSource interface {
+	GetPlantsBySectionID(sectionID string) []*models.Plant
+	GetAllPlants() []*models.Plant
+}
+
+// plantSnapshotSource is a PlantDataSource that copies its plants under its
+// own lock and between ticks, such as an engine.Simulator, so that a copy
+// taken while the simulation runs is neither torn nor racing a tick.
+type plantSnapshotSource interface {
+	BetweenTicks(fn func())
+	QueryPlants(query engine.PlantQuery) engine.PlantPage
+}
+
+// plantUpdater is a PlantDataSource whose plants are read while the
+// controller waters them, such as an engine.Simulator, which lends its lock
+// to the changes so that the readers never see them half made.
+type plantUpdater interface {
+	UpdatePlants(fn func())
+}
@@ -0,0 +1,71 @@
+package watering
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/models"
+	"math"
+)
+
+// ControlState exposes the internals of a proportional schedule for debugging.
+type ControlState struct {
+	LastTick   int
+	LastError  float64 // target minus measured saturation at the last check
+	Integral   float64 // accumulated error, bounded to prevent windup
+	LastOutput float64 // event amount requested at the last check, zero when none
+}
+
+func validateProportional(pc models.ProportionalControl) error {
+	if pc.Gain < 0 || pc.IntegralGain < 0 {
+		return errors.New("proportional gains cannot be negative")
+	}
+	if pc.Gain == 0 && pc.IntegralGain == 0 {
+		return errors.New("proportional control needs a positive gain")
+	}
+	if pc.MinAmount < 0 || pc.MaxAmount <= 0 || pc.MinAmount > pc.MaxAmount {
+		return errors.New("proportional amounts must satisfy 0 <= min <= max and max > 0")
+	}
+	return nil
+}
+
+// GetControlState returns the last error and output computed by a
+// proportional schedule; a schedule selecting only a section is identified by
+// its section ID. Returns an error if there is no such proportional schedule
+// or it has not been checked yet.
+// This method is safe for concurrent use.
+func (c *controller) GetControlState(scheduleID string) (ControlState, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	state := c.control[scheduleID]
+	if state == nil {
+		return ControlState{}, errors.New("no proportional control state for schedule: " + scheduleID)
+	}
+	return *state, nil
+}
+
+// proportionalAmount updates the control state of a schedule with a new
+// measurement over plantCount plants and returns the amount to water, or zero
+// when no watering is needed. Callers must hold c.mu.
+func (c *controller) proportionalAmount(schedule *models.WateringSchedule, measured float64, plantCount int, tick int) float64 {
+	pc := schedule.Proportional
+	state := c.control[schedule.ID]
+	if state == nil {
+		state = &ControlState{}
+		c.control[schedule.ID] = state
+	}
+
+	state.LastTick = tick
+	state.LastError = schedule.TargetSaturation - measured
+	if pc.IntegralGain > 0 {
+		// Bound the integral so its contribution alone never exceeds MaxAmount.
+		limit := pc.MaxAmount / pc.IntegralGain
+		state.Integral = math.Max(-limit, math.Min(state.Integral+state.LastError, limit))
+	}
+
+	output := pc.Gain*state.LastError + pc.IntegralGain*state.Integral
+	if output <= 0 {
+		state.LastOutput = 0
+		return 0
+	}
+	state.LastOutput = math.Max(pc.MinAmount, math.Min(output*float64(plantCount), pc.MaxAmount))
+	return state.LastOutput
+}
@@ -0,0 +1,169 @@
+package watering
+
+import (
+	"greenhouse-simulator/internal/models"
+	"math"
+	"testing"
+)
+
+// runClosedLoop simulates plants depleting and the controller responding for
+// the given number of ticks, returning the largest deviation from target of the
+// section's average saturation as the schedule measures it (after depletion,
+// before watering) once the initial transient has passed.
+func runClosedLoop(t *testing.T, schedule models.WateringSchedule, ticks int) (Controller, float64) {
+	t.Helper()
+	controller, mockData, _ := newTestController(Config{})
+	if err := controller.AddSchedule(schedule); err != nil {
+		t.Fatalf("failed to add schedule: %v", err)
+	}
+
+	plants := mockData.plantsBySectionID["section-A"]
+	maxDeviation := 0.0
+	for tick := range ticks {
+		for _, plant := range plants {
+			plant.OnTick()
+		}
+		if tick >= 50 {
+			deviation := math.Abs(averageSaturation(plants) - schedule.TargetSaturation)
+			maxDeviation = math.Max(maxDeviation, deviation)
+		}
+		controller.OnTick(tick)
+	}
+	return controller, maxDeviation
+}
+
+func TestProportionalControl_HoldsTargetBetterThanFixedAmount(t *testing.T) {
+	fixed := models.WateringSchedule{
+		SectionID:        "section-A",
+		TargetSaturation: 0.6,
+		CheckInterval:    10,
+		WaterAmount:      0.6,
+		Enabled:          true,
+	}
+	proportional := models.WateringSchedule{
+		SectionID:        "section-A",
+		TargetSaturation: 0.6,
+		CheckInterval:    1,
+		Enabled:          true,
+		Proportional: &models.ProportionalControl{
+			Gain:         0.8,
+			IntegralGain: 0.2,
+			MinAmount:    0.01,
+			MaxAmount:    0.5,
+		},
+	}
+
+	_, fixedDeviation := runClosedLoop(t, fixed, 500)
+	_, proportionalDeviation := runClosedLoop(t, proportional, 500)
+
+	if proportionalDeviation > 0.05 {
+		t.Errorf("expected proportional mode within ±0.05 of target, max deviation %.3f", proportionalDeviation)
+	}
+	if fixedDeviation <= proportionalDeviation {
+		t.Errorf("expected fixed mode (%.3f) to oscillate more widely than proportional mode (%.3f)",
+			fixedDeviation, proportionalDeviation)
+	}
+}
+
+func TestProportionalControl_ExposesState(t *testing.T) {
+	controller, _, _ := newTestController(Config{})
+	err := controller.AddSchedule(models.WateringSchedule{
+		SectionID:        "section-A",
+		TargetSaturation: 0.5,
+		CheckInterval:    1,
+		Enabled:          true,
+		Proportional:     &models.ProportionalControl{Gain: 1, MinAmount: 0.1, MaxAmount: 1},
+	})
+	if err != nil {
+		t.Fatalf("failed to add schedule: %v", err)
+	}
+
+	if _, err := controller.GetControlState("section-A"); err == nil {
+		t.Error("expected error before the first check, got nil")
+	}
+
+	// Plants start at 0.2: error 0.3, amount 1*0.3 for each of the 2 plants = 0.6
+	controller.OnTick(0)
+
+	state, err := controller.GetControlState("section-A")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !almostEqual(state.LastError, 0.3) || !almostEqual(state.LastOutput, 0.6) {
+		t.Errorf("expected error 0.3 and output 0.6, got %.2f and %.2f", state.LastError, state.LastOutput)
+	}
+
+	// Saturation is now 0.5: error 0 and no watering
+	controller.OnTick(1)
+	state, _ = controller.GetControlState("section-A")
+	if state.LastOutput != 0 {
+		t.Errorf("expected no output at target, got %.2f", state.LastOutput)
+	}
+	if len(controller.GetWateringHistory("section-A", 0)) != 1 {
+		t.Errorf("expected a single watering event")
+	}
+}
+
+func TestProportionalControl_ClampsOutput(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         float64
+		expectedOutput float64
+	}{
+		{"clamps to max", 1.0, 0.5},  // 2 * 0.8 * 2 plants = 3.2
+		{"clamps to min", 0.22, 0.1}, // 2 * 0.02 * 2 plants = 0.08
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, _, _ := newTestController(Config{})
+			err := controller.AddSchedule(models.WateringSchedule{
+				SectionID:        "section-A",
+				TargetSaturation: tt.target,
+				CheckInterval:    1,
+				Enabled:          true,
+				Proportional:     &models.ProportionalControl{Gain: 2, MinAmount: 0.1, MaxAmount: 0.5},
+			})
+			if err != nil {
+				t.Fatalf("failed to add schedule: %v", err)
+			}
+
+			controller.OnTick(0)
+
+			state, _ := controller.GetControlState("section-A")
+			if !almostEqual(state.LastOutput, tt.expectedOutput) {
+				t.Errorf("expected output %.2f, got %.2f", tt.expectedOutput, state.LastOutput)
+			}
+		})
+	}
+}
+
+func TestProportionalControl_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		control  models.ProportionalControl
+		errorMsg string
+	}{
+		{"negative gain", models.ProportionalControl{Gain: -1, MaxAmount: 1}, "proportional gains cannot be negative"},
+		{"no gain", models.ProportionalControl{MaxAmount: 1}, "proportional control needs a positive gain"},
+		{"min above max", models.ProportionalControl{Gain: 1, MinAmount: 2, MaxAmount: 1}, "proportional amounts must satisfy 0 <= min <= max and max > 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, _, _ := newTestController(Config{})
+			control := tt.control
+			err := controller.AddSchedule(models.WateringSchedule{
+				SectionID:     "section-A",
+				CheckInterval: 1,
+				Proportional:  &control,
+			})
+			if err == nil {
+				t.Fatal("expected error but got nil")
+			}
+			if err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package watering
+
+import (
+	"greenhouse-simulator/internal/models"
+	"slices"
+)
+
+// DefaultDedupWindow is the number of ticks a manual watering request ID is
+// remembered when Config.DedupWindow is left at zero.
+const DefaultDedupWindow = 60
+
+// MaxRequestIDs bounds the number of manual watering request IDs remembered
+// at once, whatever the dedup window; the oldest are forgotten first.
+const MaxRequestIDs = 1000
+
+// request is a manual watering request ID, with the event it queued and the
+// tick it was made on.
+type request struct {
+	id    string
+	event models.WateringEvent
+	tick  int
+}
+
+// requested returns the event queued by the request with the same ID, if it
+// was made within the dedup window. An empty ID never matches. Callers must
+// hold c.mu.
+func (c *controller) requested(id string) (models.WateringEvent, bool) {
+	if id == "" {
+		return models.WateringEvent{}, false
+	}
+	c.forgetRequests()
+	i := slices.IndexFunc(c.requests, func(r request) bool { return r.id == id })
+	if i < 0 {
+		return models.WateringEvent{}, false
+	}
+	return c.requests[i].event, true
+}
+
+// remember records the event a request queued, forgetting the oldest request
+// when MaxRequestIDs are remembered already. Callers must hold c.mu.
+func (c *controller) remember(id string, event models.WateringEvent) {
+	if id == "" {
+		return
+	}
+	if len(c.requests) == MaxRequestIDs {
+		c.requests = slices.Delete(c.requests, 0, 1)
+	}
+	c.requests = append(c.requests, request{id: id, event: event, tick: c.lastTick})
+}
+
+// forgetRequests drops the requests made before the dedup window. Callers
+// must hold c.mu.
+func (c *controller) forgetRequests() {
+	expired := 0
+	for expired < len(c.requests) && c.lastTick-c.requests[expired].tick > c.config.DedupWindow {
+		expired++
+	}
+	c.requests = slices.Delete(c.requests, 0, expired)
+}
@@ -0,0 +1,106 @@
+package watering
+
+import (
+	"bytes"
+	"encoding/json"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"strconv"
+	"testing"
+)
+
+func TestWaterSectionWith_RequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		first    string
+		second   string
+		ticks    int // ticks between the two requests
+		expected int // events queued
+	}{
+		{"duplicate request", "req-1", "req-1", 0, 1},
+		{"duplicate within the window", "req-1", "req-1", 5, 1},
+		{"distinct requests", "req-1", "req-2", 0, 2},
+		{"duplicate after the window", "req-1", "req-1", 6, 2},
+		{"no request IDs", "", "", 0, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, _, _ := newTestController(Config{DedupWindow: 5})
+			first, err := controller.WaterSectionWith("section-A", 0.2, 0, ManualOptions{RequestID: tt.first})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for tick := range tt.ticks {
+				controller.OnTick(tick + 1)
+			}
+			second, err := controller.WaterSectionWith("section-A", 0.4, 0, ManualOptions{RequestID: tt.second})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.expected == 1 && second != first {
+				t.Errorf("expected the retry to return the first event %+v, got %+v", first, second)
+			}
+			if tt.expected == 2 && second.ID == first.ID {
+				t.Errorf("expected a second event, got %s twice", first.ID)
+			}
+			queued := len(controller.GetActiveEvents()) + len(controller.GetWateringHistory("", 0))
+			if queued != tt.expected {
+				t.Errorf("expected %d events, got %d", tt.expected, queued)
+			}
+		})
+	}
+}
+
+func TestWaterSectionWith_RequestIDsBounded(t *testing.T) {
+	c, _, _ := newTestController(Config{})
+	first, err := c.WaterSectionWith("section-A", 0.1, 0, ManualOptions{RequestID: "req-0"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 1; i <= MaxRequestIDs; i++ {
+		if _, err := c.WaterSectionWith("section-A", 0.1, 0, ManualOptions{RequestID: "req-" + strconv.Itoa(i)}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if remembered := len(c.(*controller).requests); remembered != MaxRequestIDs {
+		t.Errorf("expected %d request IDs remembered, got %d", MaxRequestIDs, remembered)
+	}
+	again, err := c.WaterSectionWith("section-A", 0.1, 0, ManualOptions{RequestID: "req-0"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again.ID == first.ID {
+		t.Error("expected the oldest request ID to be forgotten")
+	}
+}
+
+func TestWaterSectionWith_Label(t *testing.T) {
+	controller, _, published := newTestController(Config{})
+	event, err := controller.WaterSectionWith("section-A", 0.2, 0, ManualOptions{Label: "pre-vacation soak"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event.Label != "pre-vacation soak" {
+		t.Errorf("expected the queued event to carry the label, got %+v", event)
+	}
+	controller.OnTick(0)
+
+	for _, e := range *published {
+		if e.Type == events.WateringCompleted && e.Payload.(models.WateringEvent).Label != "pre-vacation soak" {
+			t.Errorf("expected the completed event to carry the label, got %+v", e.Payload)
+		}
+	}
+	var buf bytes.Buffer
+	if err := ExportHistory(&buf, controller.GetWateringHistory("", 0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var entry HistoryEntry
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid export: %v", err)
+	}
+	if entry.Event.Label != "pre-vacation soak" || entry.Event.ID != event.ID {
+		t.Errorf("expected the label to round-trip through the export, got %+v", entry.Event)
+	}
+}
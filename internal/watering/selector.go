@@ -0,0 +1,111 @@
+package watering
+
+import (
+	"greenhouse-simulator/internal/models"
+	"strings"
+)
+
+// scheduleID returns the schedule's ID, deriving one from its selectors when
+// unset. A schedule selecting only a section is identified by the section ID.
+func scheduleID(schedule models.WateringSchedule) string {
+	if schedule.ID != "" {
+		return schedule.ID
+	}
+	if schedule.ZoneID == "" && schedule.Tag == "" && schedule.PlantType == "" {
+		return schedule.SectionID
+	}
+	var parts []string
+	if schedule.SectionID != "" {
+		parts = append(parts, schedule.SectionID)
+	}
+	if schedule.ZoneID != "" {
+		parts = append(parts, "zone:"+schedule.ZoneID)
+	}
+	if schedule.PlantType != "" {
+		parts = append(parts, "type:"+schedule.PlantType)
+	}
+	if schedule.Tag != "" {
+		parts = append(parts, "tag:"+schedule.Tag)
+	}
+	return strings.Join(parts, "/")
+}
+
+// selects reports whether every selector set on the schedule matches the
+// plant. A zone never matches without c.config.Zones.
+func (c *controller) selects(schedule *models.WateringSchedule, plant *models.Plant) bool {
+	if schedule.SectionID != "" && plant.SectionID != schedule.SectionID {
+		return false
+	}
+	if schedule.ZoneID != "" && (c.config.Zones == nil || c.config.Zones.SectionZone(plant.SectionID) != schedule.ZoneID) {
+		return false
+	}
+	if schedule.PlantType != "" && plant.Type.Name != schedule.PlantType {
+		return false
+	}
+	if schedule.Tag != "" && !plant.HasTag(schedule.Tag) {
+		return false
+	}
+	return true
+}
+
+// specificity ranks schedules for precedence: schedules setting more selectors
+// are more specific, and on equal counts a tag beats a plant type, which beats
+// a section, which beats a zone.
+func specificity(schedule *models.WateringSchedule) int {
+	count, weight := 0, 0
+	if schedule.ZoneID != "" {
+		count++
+		weight += 1
+	}
+	if schedule.SectionID != "" {
+		count++
+		weight += 2
+	}
+	if schedule.PlantType != "" {
+		count++
+		weight += 4
+	}
+	if schedule.Tag != "" {
+		count++
+		weight += 8
+	}
+	return count*16 + weight
+}
+
+// owner returns the enabled schedule responsible for watering a plant: the most
+// specific one selecting it, with ties broken by the lowest schedule ID. ids
+// are the sorted IDs of c.schedules, sorted once by the caller rather than for
+// every plant. Returns nil when no enabled schedule selects the plant. Callers
+// must hold c.mu.
+func (c *controller) owner(ids []string, plant *models.Plant) *models.WateringSchedule {
+	var best *models.WateringSchedule
+	for _, id := range ids {
+		schedule := c.schedules[id]
+		if !schedule.Enabled || !c.selects(schedule, plant) {
+			continue
+		}
+		if best == nil || specificity(schedule) > specificity(best) {
+			best = schedule
+		}
+	}
+	return best
+}
+
+// targets returns the plants a schedule waters: those it selects and for which
+// no more specific schedule takes precedence. Callers must hold c.mu.
+func (c *controller) targets(schedule *models.WateringSchedule) []*models.Plant {
+	var candidates []*models.Plant
+	if schedule.SectionID != "" {
+		candidates = c.plantData.GetPlantsBySectionID(schedule.SectionID)
+	} else {
+		candidates = c.plantData.GetAllPlants()
+	}
+	ids := sortedKeys(c.schedules)
+	plants := make([]*models.Plant, 0, len(candidates))
+	for _, plant := range candidates {
+		if c.selects(schedule, plant) && c.owner(ids, plant) == schedule {
+			plants = append(plants, plant)
+		}
+	}
+	return plants
+}
@@ -0,0 +1,246 @@
+package watering
+
+import (
+	"greenhouse-simulator/internal/models"
+	"math"
+	"testing"
+)
+
+func createTypedPlant(id, sectionID string, plantType models.PlantType, saturation float64, tags ...string) *models.Plant {
+	plant, _ := models.NewPlant(id, plantType, sectionID, saturation)
+	plant.Tags = tags
+	return plant
+}
+
+var (
+	lettuceType = models.PlantType{
+		Name:                  "Lettuce",
+		OptimalSaturation:     0.7,
+		MinSaturation:         0.4,
+		MaxSaturation:         0.9,
+		BaseGrowthRate:        0.08,
+		SaturationDepletion:   0.05,
+		HealthDegradationRate: 0.06,
+		HealthEnhancementRate: 0.04,
+	}
+	tomatoType = models.PlantType{
+		Name:                  "Tomato",
+		OptimalSaturation:     0.5,
+		MinSaturation:         0.3,
+		MaxSaturation:         0.8,
+		BaseGrowthRate:        0.05,
+		SaturationDepletion:   0.04,
+		HealthDegradationRate: 0.08,
+		HealthEnhancementRate: 0.03,
+	}
+)
+
+func newMixedSection() *mockPlantDataSource {
+	return &mockPlantDataSource{
+		plantsBySectionID: map[string][]*models.Plant{
+			"section-A": {
+				createTypedPlant("lettuce-1", "section-A", lettuceType, 0.3),
+				createTypedPlant("lettuce-2", "section-A", lettuceType, 0.3),
+				createTypedPlant("tomato-1", "section-A", tomatoType, 0.3, "trial"),
+				createTypedPlant("tomato-2", "section-A", tomatoType, 0.3),
+			},
+		},
+	}
+}
+
+func averageOfType(plants []*models.Plant, typeName string) float64 {
+	total, count := 0.0, 0
+	for _, plant := range plants {
+		if plant.Type.Name == typeName {
+			total += plant.SoilSaturation
+			count++
+		}
+	}
+	return total / float64(count)
+}
+
+func TestTypeSchedules_HoldEachTypeNearItsTarget(t *testing.T) {
+	mockData := newMixedSection()
+	controller := NewController(mockData, nil, Config{})
+
+	for _, schedule := range []models.WateringSchedule{
+		{SectionID: "section-A", PlantType: "Lettuce", TargetSaturation: 0.75, CheckInterval: 1,
+			Enabled: true, Proportional: &models.ProportionalControl{Gain: 0.8, IntegralGain: 0.2, MaxAmount: 1}},
+		{SectionID: "section-A", PlantType: "Tomato", TargetSaturation: 0.45, CheckInterval: 1,
+			Enabled: true, Proportional: &models.ProportionalControl{Gain: 0.8, IntegralGain: 0.2, MaxAmount: 1}},
+	} {
+		if err := controller.AddSchedule(schedule); err != nil {
+			t.Fatalf("failed to add schedule: %v", err)
+		}
+	}
+
+	// Compare as the schedules measure: after depletion, before watering
+	plants := mockData.plantsBySectionID["section-A"]
+	for tick := range 300 {
+		controller.OnTick(tick)
+		for _, plant := range plants {
+			plant.OnTick()
+		}
+	}
+
+	if lettuce := averageOfType(plants, "Lettuce"); math.Abs(lettuce-0.75) > 0.05 {
+		t.Errorf("expected lettuce near 0.75, got %.3f", lettuce)
+	}
+	if tomato := averageOfType(plants, "Tomato"); math.Abs(tomato-0.45) > 0.05 {
+		t.Errorf("expected tomatoes near 0.45, got %.3f", tomato)
+	}
+}
+
+func TestSchedulePrecedence_MostSpecificWins(t *testing.T) {
+	mockData := newMixedSection()
+	controller := NewController(mockData, nil, Config{})
+
+	schedules := []models.WateringSchedule{
+		{SectionID: "section-A", TargetSaturation: 0.9, CheckInterval: 1, WaterAmount: 0.4, Enabled: true},
+		{PlantType: "Tomato", TargetSaturation: 0.9, CheckInterval: 1, WaterAmount: 0.2, Enabled: true},
+		{Tag: "trial", TargetSaturation: 0.9, CheckInterval: 1, WaterAmount: 0.1, Enabled: true},
+	}
+	for _, schedule := range schedules {
+		if err := controller.AddSchedule(schedule); err != nil {
+			t.Fatalf("failed to add schedule: %v", err)
+		}
+	}
+
+	controller.OnTick(0)
+
+	// section schedule: 0.4 over the 2 lettuces, type schedule: 0.2 to tomato-2 only,
+	// tag schedule: 0.1 to tomato-1 only. No plant is watered twice.
+	expected := map[string]float64{
+		"lettuce-1": 0.5,
+		"lettuce-2": 0.5,
+		"tomato-1":  0.4,
+		"tomato-2":  0.5,
+	}
+	for _, plant := range mockData.plantsBySectionID["section-A"] {
+		if !almostEqual(plant.SoilSaturation, expected[plant.ID]) {
+			t.Errorf("%s: expected saturation %.2f, got %.2f", plant.ID, expected[plant.ID], plant.SoilSaturation)
+		}
+	}
+}
+
+func TestSchedulePrecedence_DisabledScheduleDoesNotClaimPlants(t *testing.T) {
+	mockData := newMixedSection()
+	controller := NewController(mockData, nil, Config{})
+
+	for _, schedule := range []models.WateringSchedule{
+		{SectionID: "section-A", TargetSaturation: 0.9, CheckInterval: 1, WaterAmount: 0.4, Enabled: true},
+		{PlantType: "Tomato", TargetSaturation: 0.9, CheckInterval: 1, WaterAmount: 0.2, Enabled: false},
+	} {
+		if err := controller.AddSchedule(schedule); err != nil {
+			t.Fatalf("failed to add schedule: %v", err)
+		}
+	}
+
+	controller.OnTick(0)
+
+	for _, plant := range mockData.plantsBySectionID["section-A"] {
+		if !almostEqual(plant.SoilSaturation, 0.4) {
+			t.Errorf("%s: expected the section schedule to water every plant, got %.2f", plant.ID, plant.SoilSaturation)
+		}
+	}
+}
+
+func TestScheduleTrigger_AveragesOnlySelectedPlants(t *testing.T) {
+	mockData := newMixedSection()
+	for _, plant := range mockData.plantsBySectionID["section-A"] {
+		if plant.Type.Name == "Lettuce" {
+			plant.SoilSaturation = 0.9
+		}
+	}
+	controller := NewController(mockData, nil, Config{})
+
+	// Section average is 0.6, above target, but the tomatoes alone (0.3) are below it
+	err := controller.AddSchedule(models.WateringSchedule{
+		SectionID: "section-A", PlantType: "Tomato", TargetSaturation: 0.5, CheckInterval: 1, WaterAmount: 0.2, Enabled: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to add schedule: %v", err)
+	}
+
+	controller.OnTick(0)
+
+	history := controller.GetWateringHistory("section-A", 0)
+	if len(history) != 1 || history[0].Event.ScheduleID != "section-A/type:Tomato" {
+		t.Fatalf("expected one event from the tomato schedule, got %+v", history)
+	}
+}
+
+// mockZones maps sections to their zones.
+type mockZones map[string]string
+
+func (z mockZones) SectionZone(sectionID string) string { return z[sectionID] }
+
+func (z mockZones) HasZone(zoneID string) bool {
+	for _, id := range z {
+		if id == zoneID {
+			return true
+		}
+	}
+	return false
+}
+
+func TestZoneSchedule_WatersTheSectionsOfTheZone(t *testing.T) {
+	mockData := newMixedSection()
+	mockData.plantsBySectionID["section-B"] = []*models.Plant{createTypedPlant("lettuce-3", "section-B", lettuceType, 0.3)}
+	mockData.plantsBySectionID["section-C"] = []*models.Plant{createTypedPlant("lettuce-4", "section-C", lettuceType, 0.3)}
+	controller := NewController(mockData, nil, Config{Zones: mockZones{"section-A": "west", "section-B": "west", "section-C": "east"}})
+
+	for _, schedule := range []models.WateringSchedule{
+		{ZoneID: "west", TargetSaturation: 0.9, CheckInterval: 1, WaterAmount: 0.3, Enabled: true},
+		{SectionID: "section-A", PlantType: "Tomato", TargetSaturation: 0.9, CheckInterval: 1, WaterAmount: 0.2, Enabled: true},
+	} {
+		if err := controller.AddSchedule(schedule); err != nil {
+			t.Fatalf("failed to add schedule: %v", err)
+		}
+	}
+	if err := controller.AddSchedule(models.WateringSchedule{ZoneID: "north", TargetSaturation: 0.9, CheckInterval: 1, WaterAmount: 0.3, Enabled: true}); err == nil ||
+		err.Error() != "schedule selects an unknown zone: north" {
+		t.Errorf("expected an unknown zone to be rejected, got %v", err)
+	}
+
+	controller.OnTick(0)
+
+	// zone schedule: 0.3 over the 3 lettuces of the zone, type schedule: 0.2
+	// over the tomatoes. The lettuce of the other zone is left dry.
+	expected := map[string]float64{
+		"lettuce-1": 0.4,
+		"lettuce-2": 0.4,
+		"lettuce-3": 0.4,
+		"lettuce-4": 0.3,
+		"tomato-1":  0.4,
+		"tomato-2":  0.4,
+	}
+	for _, plant := range mockData.GetAllPlants() {
+		if !almostEqual(plant.SoilSaturation, expected[plant.ID]) {
+			t.Errorf("%s: expected saturation %.2f, got %.2f", plant.ID, expected[plant.ID], plant.SoilSaturation)
+		}
+	}
+}
+
+func TestScheduleID(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule models.WateringSchedule
+		expected string
+	}{
+		{"explicit", models.WateringSchedule{ID: "night", SectionID: "section-A"}, "night"},
+		{"section only", models.WateringSchedule{SectionID: "section-A"}, "section-A"},
+		{"type in section", models.WateringSchedule{SectionID: "section-A", PlantType: "Tomato"}, "section-A/type:Tomato"},
+		{"tag only", models.WateringSchedule{Tag: "trial"}, "tag:trial"},
+		{"zone only", models.WateringSchedule{ZoneID: "west"}, "zone:west"},
+		{"type in zone", models.WateringSchedule{ZoneID: "west", PlantType: "Tomato"}, "zone:west/type:Tomato"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scheduleID(tt.schedule); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
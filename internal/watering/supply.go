@@ -0,0 +1,157 @@
+package watering
+
+import (
+	"errors"
+	"math"
+	"sync"
+)
+
+// ShortagePolicy decides what the scheduler does when the tank cannot cover
+// the full amount of a scheduled watering event.
+type ShortagePolicy string
+
+const (
+	// ShortageSkip drops the scheduled event; the schedule will check again at its next interval.
+	ShortageSkip ShortagePolicy = "skip"
+	// ShortagePartial starts the event with whatever water is left in the tank.
+	ShortagePartial ShortagePolicy = "partial"
+	// ShortageQueue holds the event until the tank has refilled enough to cover it.
+	ShortageQueue ShortagePolicy = "queue"
+)
+
+// SupplyConfig describes a water tank.
+type SupplyConfig struct {
+	Capacity          float64
+	InitialLevel      float64 // starting level, must not exceed Capacity
+	RefillPerTick     float64 // water added back to the tank on every tick
+	LowWaterThreshold float64 // a LowWater event fires when the level drops below this
+	ShortagePolicy    ShortagePolicy
+}
+
+// WaterSupply is the tank every watering event draws from.
+// It is safe for concurrent use.
+type WaterSupply struct {
+	config     SupplyConfig
+	level      float64
+	lowAlerted bool
+	mu         sync.Mutex
+}
+
+// NewWaterSupply creates a tank from config. An empty ShortagePolicy defaults
+// to ShortageSkip. Returns an error if:
+// - capacity is not positive
+// - the initial level is outside 0 to capacity
+// - the refill rate is negative
+// - the low water threshold is outside 0 to capacity
+// - the shortage policy is unknown
+func NewWaterSupply(config SupplyConfig) (*WaterSupply, error) {
+	if config.Capacity <= 0 {
+		return nil, errors.New("water supply capacity must be positive")
+	}
+	if config.InitialLevel < 0 || config.InitialLevel > config.Capacity {
+		return nil, errors.New("water supply initial level must be between 0 and capacity")
+	}
+	if config.RefillPerTick < 0 {
+		return nil, errors.New("water supply refill rate cannot be negative")
+	}
+	if config.LowWaterThreshold < 0 || config.LowWaterThreshold > config.Capacity {
+		return nil, errors.New("water supply low water threshold must be between 0 and capacity")
+	}
+	switch config.ShortagePolicy {
+	case "":
+		config.ShortagePolicy = ShortageSkip
+	case ShortageSkip, ShortagePartial, ShortageQueue:
+	default:
+		return nil, errors.New("unknown water shortage policy: " + string(config.ShortagePolicy))
+	}
+
+	return &WaterSupply{
+		config: config,
+		level:  config.InitialLevel,
+	}, nil
+}
+
+// Level returns the amount of water currently in the tank.
+func (w *WaterSupply) Level() float64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.level
+}
+
+// Capacity returns the maximum amount of water the tank holds.
+func (w *WaterSupply) Capacity() float64 {
+	return w.config.Capacity
+}
+
+// Policy returns the tank's shortage policy.
+func (w *WaterSupply) Policy() ShortagePolicy {
+	return w.config.ShortagePolicy
+}
+
+// Refill adds water to the tank, capping the level at capacity.
+// Returns an error if amount is not positive.
+func (w *WaterSupply) Refill(amount float64) error {
+	if amount <= 0 {
+		return errors.New("refill amount must be positive")
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.add(amount)
+	return nil
+}
+
+// refillTick applies the per-tick refill rate.
+func (w *WaterSupply) refillTick() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.add(w.config.RefillPerTick)
+}
+
+func (w *WaterSupply) add(amount float64) {
+	w.level = math.Min(w.level+amount, w.config.Capacity)
+	if w.level >= w.config.LowWaterThreshold {
+		w.lowAlerted = false
+	}
+}
+
+// clone returns an independent tank with the same configuration and level.
+func (w *WaterSupply) clone() *WaterSupply {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return &WaterSupply{
+		config:     w.config,
+		level:      w.level,
+		lowAlerted: w.lowAlerted,
+	}
+}
+
+// setLevel overwrites the tank level, clamped to 0 to capacity. Used when
+// restoring a snapshot.
+func (w *WaterSupply) setLevel(level float64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.level = math.Max(0, math.Min(level, w.config.Capacity))
+	w.lowAlerted = w.level < w.config.LowWaterThreshold
+}
+
+// covers reports whether the tank currently holds at least amount.
+func (w *WaterSupply) covers(amount float64) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.level >= amount
+}
+
+// draw takes up to amount from the tank and returns how much was actually
+// drawn. lowWater is true the first time the level drops below the low
+// water threshold; it re-arms once the tank is refilled above it.
+func (w *WaterSupply) draw(amount float64) (drawn float64, lowWater bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	drawn = math.Min(amount, w.level)
+	w.level -= drawn
+	if w.level < w.config.LowWaterThreshold && !w.lowAlerted {
+		w.lowAlerted = true
+		lowWater = true
+	}
+	return drawn, lowWater
+}
@@ -0,0 +1,189 @@
+package watering
+
+import (
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"testing"
+	"time"
+)
+
+func newTestSupply(t *testing.T, config SupplyConfig) *WaterSupply {
+	t.Helper()
+	supply, err := NewWaterSupply(config)
+	if err != nil {
+		t.Fatalf("failed to create water supply: %v", err)
+	}
+	return supply
+}
+
+func countEvents(published []events.Event, eventType events.Type) int {
+	count := 0
+	for _, e := range published {
+		if e.Type == eventType {
+			count++
+		}
+	}
+	return count
+}
+
+// thirstySchedule triggers every tick because its target can never be reached.
+var thirstySchedule = models.WateringSchedule{
+	SectionID:        "section-A",
+	TargetSaturation: 1.0,
+	CheckInterval:    1,
+	WaterAmount:      0.4,
+	Enabled:          true,
+}
+
+func TestNewWaterSupply_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   SupplyConfig
+		errorMsg string
+	}{
+		{"zero capacity", SupplyConfig{}, "water supply capacity must be positive"},
+		{"level above capacity", SupplyConfig{Capacity: 1, InitialLevel: 2}, "water supply initial level must be between 0 and capacity"},
+		{"negative refill", SupplyConfig{Capacity: 1, RefillPerTick: -1}, "water supply refill rate cannot be negative"},
+		{"threshold above capacity", SupplyConfig{Capacity: 1, LowWaterThreshold: 2}, "water supply low water threshold must be between 0 and capacity"},
+		{"unknown policy", SupplyConfig{Capacity: 1, ShortagePolicy: "borrow"}, "unknown water shortage policy: borrow"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewWaterSupply(tt.config)
+			if err == nil {
+				t.Fatal("expected error but got nil")
+			}
+			if err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestSupply_ScheduleExhaustsTank(t *testing.T) {
+	supply := newTestSupply(t, SupplyConfig{
+		Capacity:          1.0,
+		InitialLevel:      1.0,
+		LowWaterThreshold: 0.3,
+		ShortagePolicy:    ShortageSkip,
+	})
+	controller, _, published := newTestController(Config{TickInterval: time.Second, Supply: supply})
+	if err := controller.AddSchedule(thirstySchedule); err != nil {
+		t.Fatalf("failed to add schedule: %v", err)
+	}
+
+	for tick := range 5 {
+		controller.OnTick(tick)
+	}
+
+	// Two full events (0.8) fit in the tank; the remaining 0.2 never covers another
+	if countEvents(*published, events.WateringCompleted) != 2 {
+		t.Errorf("expected 2 completed events, got %d", countEvents(*published, events.WateringCompleted))
+	}
+	if countEvents(*published, events.WateringSkipped) != 3 {
+		t.Errorf("expected 3 skipped events, got %d", countEvents(*published, events.WateringSkipped))
+	}
+	if countEvents(*published, events.LowWater) != 1 {
+		t.Errorf("expected a single low water alert, got %d", countEvents(*published, events.LowWater))
+	}
+
+	stats := controller.GetWaterStats()
+	if !almostEqual(stats.Used, 0.8) || !almostEqual(stats.Remaining, 0.2) {
+		t.Errorf("expected 0.8 used and 0.2 remaining, got %.2f and %.2f", stats.Used, stats.Remaining)
+	}
+}
+
+func TestSupply_PartialWatering(t *testing.T) {
+	supply := newTestSupply(t, SupplyConfig{
+		Capacity:       1.0,
+		InitialLevel:   0.3,
+		ShortagePolicy: ShortagePartial,
+	})
+	controller, mockData, _ := newTestController(Config{TickInterval: time.Second, Supply: supply})
+	if err := controller.AddSchedule(thirstySchedule); err != nil {
+		t.Fatalf("failed to add schedule: %v", err)
+	}
+
+	controller.OnTick(0)
+
+	// Only 0.3 of the requested 0.4 is available, split across 2 plants
+	for _, plant := range mockData.plantsBySectionID["section-A"] {
+		if !almostEqual(plant.SoilSaturation, 0.35) {
+			t.Errorf("expected saturation 0.35, got %.2f", plant.SoilSaturation)
+		}
+	}
+	if supply.Level() != 0 {
+		t.Errorf("expected empty tank, got %.2f", supply.Level())
+	}
+}
+
+func TestSupply_QueuedEventWaitsForRefill(t *testing.T) {
+	supply := newTestSupply(t, SupplyConfig{
+		Capacity:       1.0,
+		InitialLevel:   0.1,
+		RefillPerTick:  0.1,
+		ShortagePolicy: ShortageQueue,
+	})
+	controller, mockData, published := newTestController(Config{TickInterval: time.Second, Supply: supply})
+	if err := controller.AddSchedule(thirstySchedule); err != nil {
+		t.Fatalf("failed to add schedule: %v", err)
+	}
+
+	// Refill reaches 0.2 at tick 0, 0.3 at tick 1 and covers 0.4 at tick 2
+	controller.OnTick(0)
+	controller.OnTick(1)
+	if countEvents(*published, events.WateringStarted) != 0 {
+		t.Fatalf("expected queued event not to start before the tank covers it")
+	}
+	if len(controller.GetActiveEvents()) != 1 {
+		t.Fatalf("expected the queued event to be listed as active")
+	}
+
+	controller.OnTick(2)
+	if countEvents(*published, events.WateringStarted) != 1 {
+		t.Errorf("expected queued event to start once refilled")
+	}
+	for _, plant := range mockData.plantsBySectionID["section-A"] {
+		if !almostEqual(plant.SoilSaturation, 0.4) {
+			t.Errorf("expected saturation 0.4, got %.2f", plant.SoilSaturation)
+		}
+	}
+}
+
+func TestSupply_StatsTrackRunoff(t *testing.T) {
+	controller, mockData, _ := newTestController(Config{})
+	for _, plant := range mockData.plantsBySectionID["section-A"] {
+		plant.SoilSaturation = 0.9
+	}
+
+	if err := controller.WaterSection("section-A", 0.4, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	controller.OnTick(0)
+
+	stats := controller.GetWaterStats()
+	if !almostEqual(stats.Used, 0.4) {
+		t.Errorf("expected 0.4 used, got %.2f", stats.Used)
+	}
+	if !almostEqual(stats.Wasted, 0.2) {
+		t.Errorf("expected 0.2 wasted, got %.2f", stats.Wasted)
+	}
+	if !stats.Unlimited {
+		t.Errorf("expected unlimited supply without a tank")
+	}
+}
+
+func TestSupply_Refill(t *testing.T) {
+	supply := newTestSupply(t, SupplyConfig{Capacity: 1.0, InitialLevel: 0.5})
+
+	if err := supply.Refill(0); err == nil {
+		t.Error("expected error when refilling a non-positive amount, got nil")
+	}
+	if err := supply.Refill(0.8); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if supply.Level() != 1.0 {
+		t.Errorf("expected refill to cap at capacity, got %.2f", supply.Level())
+	}
+}
@@ -0,0 +1,42 @@
+package watering
+
+import (
+	"errors"
+	"greenhouse-simulator/internal/models"
+)
+
+func validateWindows(windows []models.TimeWindow) error {
+	for _, w := range windows {
+		if w.Start < 0 || w.Start > 1 || w.End < 0 || w.End > 1 {
+			return errors.New("watering window bounds must be between 0.0 and 1.0")
+		}
+		if w.Start == w.End {
+			return errors.New("watering window cannot be empty")
+		}
+	}
+	return nil
+}
+
+// fitsWindow reports whether an event of the schedule starting at tick would
+// run entirely inside one of its allowed windows. Schedules without windows
+// always fit. Callers must hold c.mu.
+func (c *controller) fitsWindow(schedule *models.WateringSchedule, tick int) bool {
+	if len(schedule.AllowedWindows) == 0 {
+		return true
+	}
+	cycle := c.config.DayCycle
+	ticks := c.durationTicks(schedule.Duration)
+	for _, w := range schedule.AllowedWindows {
+		fits := true
+		for offset := range ticks {
+			if !w.Contains(cycle.TimeOfDay(tick + offset)) {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package watering
+
+import (
+	"greenhouse-simulator/internal/environment"
+	"greenhouse-simulator/internal/events"
+	"greenhouse-simulator/internal/models"
+	"testing"
+	"time"
+)
+
+// hourlyDay gives one tick per simulated hour.
+var hourlyDay = environment.DayCycle{TicksPerDay: 24}
+
+func hour(h float64) float64 {
+	return h / 24
+}
+
+func firstStartTick(published []events.Event) int {
+	for _, e := range published {
+		if e.Type == events.WateringStarted {
+			return e.Tick
+		}
+	}
+	return -1
+}
+
+func TestWindows_DefersUntilEventFits(t *testing.T) {
+	tests := []struct {
+		name      string
+		window    models.TimeWindow
+		duration  time.Duration
+		firstTick int
+		wantStart int
+	}{
+		{"noon trigger waits for evening", models.TimeWindow{Start: hour(18), End: hour(22)}, 2 * time.Second, 12, 18},
+		{"event must end inside window", models.TimeWindow{Start: hour(18), End: hour(22)}, 2 * time.Second, 21, 42},
+		{"window wrapping midnight", models.TimeWindow{Start: hour(22), End: hour(2)}, 3 * time.Second, 12, 22},
+		{"trigger inside window", models.TimeWindow{Start: hour(6), End: hour(10)}, 2 * time.Second, 7, 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, _, published := newTestController(Config{TickInterval: time.Second, DayCycle: hourlyDay})
+			err := controller.AddSchedule(models.WateringSchedule{
+				SectionID:        "section-A",
+				TargetSaturation: 0.5,
+				CheckInterval:    100,
+				WaterAmount:      0.2,
+				Duration:         tt.duration,
+				Enabled:          true,
+				AllowedWindows:   []models.TimeWindow{tt.window},
+			})
+			if err != nil {
+				t.Fatalf("failed to add schedule: %v", err)
+			}
+
+			// The first check is forced by starting on a multiple of the interval
+			controller.OnTick(0)
+			for tick := tt.firstTick; tick <= tt.wantStart; tick++ {
+				controller.OnTick(tick)
+			}
+
+			if got := firstStartTick(*published); got != tt.wantStart {
+				t.Errorf("expected watering to start at tick %d, got %d", tt.wantStart, got)
+			}
+		})
+	}
+}
+
+func TestWindows_NoWindowsStartsImmediately(t *testing.T) {
+	controller, _, published := newTestController(Config{TickInterval: time.Second, DayCycle: hourlyDay})
+	if err := controller.AddSchedule(thirstySchedule); err != nil {
+		t.Fatalf("failed to add schedule: %v", err)
+	}
+
+	controller.OnTick(12)
+
+	if got := firstStartTick(*published); got != 12 {
+		t.Errorf("expected watering to start at tick 12, got %d", got)
+	}
+}
+
+func TestWindows_SurviveSnapshot(t *testing.T) {
+	controller, _, _ := newTestController(Config{TickInterval: time.Second, DayCycle: hourlyDay})
+	schedule := thirstySchedule
+	schedule.CheckInterval = 100
+	schedule.AllowedWindows = []models.TimeWindow{{Start: hour(18), End: hour(22)}}
+	if err := controller.AddSchedule(schedule); err != nil {
+		t.Fatalf("failed to add schedule: %v", err)
+	}
+	controller.OnTick(0)
+
+	restored, _, published := newTestController(Config{TickInterval: time.Second, DayCycle: hourlyDay})
+	restored.Restore(controller.Snapshot())
+	restored.OnTick(18)
+
+	if got := firstStartTick(*published); got != 18 {
+		t.Errorf("expected the deferred check to carry over and start at tick 18, got %d", got)
+	}
+}
+
+func TestWindows_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		cycle    environment.DayCycle
+		window   models.TimeWindow
+		errorMsg string
+	}{
+		{"no day cycle", environment.DayCycle{}, models.TimeWindow{Start: 0.2, End: 0.4}, "schedule watering windows require a day cycle"},
+		{"start out of range", hourlyDay, models.TimeWindow{Start: -0.1, End: 0.4}, "watering window bounds must be between 0.0 and 1.0"},
+		{"end out of range", hourlyDay, models.TimeWindow{Start: 0.2, End: 1.5}, "watering window bounds must be between 0.0 and 1.0"},
+		{"empty window", hourlyDay, models.TimeWindow{Start: 0.3, End: 0.3}, "watering window cannot be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, _, _ := newTestController(Config{DayCycle: tt.cycle})
+			schedule := thirstySchedule
+			schedule.AllowedWindows = []models.TimeWindow{tt.window}
+
+			err := controller.AddSchedule(schedule)
+
+			if err == nil {
+				t.Fatal("expected error but got nil")
+			}
+			if err.Error() != tt.errorMsg {
+				t.Errorf("expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+			}
+		})
+	}
+}